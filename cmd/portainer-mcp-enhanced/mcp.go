@@ -5,7 +5,11 @@ package main
 
 import (
 	"flag"
+	"os"
+	"strings"
 
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/config"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/k8sutil"
 	"github.com/jmrplens/portainer-mcp-enhanced/internal/mcp"
 	"github.com/jmrplens/portainer-mcp-enhanced/internal/tooldef"
 	"github.com/rs/zerolog/log"
@@ -30,18 +34,78 @@ func main() {
 		Str("commit", Commit).
 		Msg("Portainer MCP server")
 
+	configFlag := flag.String("config", "", "Path to a YAML or JSON configuration file setting any of these flags by name; flags given on the command line take precedence")
 	serverFlag := flag.String("server", "", "The Portainer server URL")
 	tokenFlag := flag.String("token", "", "The authentication token for the Portainer server")
 	toolsFlag := flag.String("tools", "", "The path to the tools YAML file")
 	readOnlyFlag := flag.Bool("read-only", false, "Run in read-only mode")
 	granularToolsFlag := flag.Bool("granular-tools", false, "Register all individual tools instead of grouped meta-tools")
-	disableVersionCheckFlag := flag.Bool("disable-version-check", false, "Disable Portainer server version check")
+	versionCheckFlag := flag.String("version-check", mcp.VersionCheckStrict, "Portainer server version check: strict (refuse unsupported versions), warn (log a warning and start anyway) or off")
+	disableVersionCheckFlag := flag.Bool("disable-version-check", false, "Disable Portainer server version check (deprecated, use -version-check=off)")
 	skipTLSVerifyFlag := flag.Bool("skip-tls-verify", false, "Skip TLS certificate verification (insecure, use only for self-signed certs)")
+	stackHistorySizeFlag := flag.Int("stack-history-size", 10, "Number of stack file versions to keep per stack (0 disables the history)")
+	stackHistoryDirFlag := flag.String("stack-history-dir", "", "Directory where the stack file history is persisted (in memory when empty)")
+	deleteJournalSizeFlag := flag.Int("delete-journal-size", 50, "Number of deleted resources kept in the delete journal (0 disables the journal)")
+	deleteJournalDirFlag := flag.String("delete-journal-dir", "", "Directory where the delete journal is persisted (in memory when empty)")
+	maintenanceDirFlag := flag.String("maintenance-dir", "", "Directory where the environments in maintenance mode and their stopped stacks are persisted, so that they can be resumed after a restart (in memory when empty)")
+	trendIntervalFlag := flag.Duration("trend-interval", mcp.DefaultTrendInterval, "Delay between two samples of the container, stack, image and volume counts of every environment recorded for the getTrends tool (0 disables the trend history)")
+	trendHistorySizeFlag := flag.Int("trend-history-size", mcp.DefaultTrendHistorySize, "Number of samples kept in the trend history (0 disables the trend history)")
+	trendHistoryDirFlag := flag.String("trend-history-dir", "", "Directory where the trend history is persisted (in memory when empty)")
+
+	redactionRulesFlag := flag.String("redaction-rules", "", "Path to a YAML or JSON file with redaction rules applied to tool results")
+	maxWriteOpsFlag := flag.Int("max-write-ops", 0, "Maximum write operations per session before operator reset is required (0 = unlimited)")
+	maxDestructiveOpsFlag := flag.Int("max-destructive-ops", 0, "Maximum destructive operations per session before operator reset is required (0 = unlimited)")
+	toolTimeoutsFlag := flag.String("tool-timeouts", "", "Per-tool execution timeouts as pattern=duration pairs, e.g. \"installHelmChart=5m,list*=15s\"")
+	maxRetriesFlag := flag.Int("max-retries", mcp.DefaultMaxRetries, "Number of automatic retries of the reads and idempotent updates failing because Portainer or the environment is unavailable (0 disables retries)")
+	k8sStripFieldsFlag := flag.String("k8s-strip-fields", strings.Join(k8sutil.DefaultStripFields, ","), "Comma-separated fields removed from Kubernetes resources by the stripped Kubernetes proxy tool (e.g. metadata.managedFields,status)")
+	skipProxyValidationFlag := flag.Bool("skip-proxy-validation", false, "Skip validation of Docker and Kubernetes proxy paths against the known API routes")
+	proxyRulesFlag := flag.String("proxy-rules", "", "Path to a YAML or JSON file with allow/deny rules for the Docker and Kubernetes proxy tools")
+	policyFlag := flag.String("policy", "", "Path to a YAML or JSON file with rules allowing or denying write tool calls, such as deleting the environments named prod-*")
+	auditLogFlag := flag.String("audit-log", "", "File where the policy decisions are appended as JSON lines (written to the server log when empty)")
+	notifyWebhookFlag := flag.String("notify-webhook", "", "Webhook URL (generic or Slack-compatible) notified whenever a destructive tool call succeeds")
+	notifySecretFlag := flag.String("notify-secret", "", "Secret signing the notification payloads with HMAC-SHA256 so that the webhook receiver can verify them (unsigned when empty)")
+	rbacFilterFlag := flag.Bool("rbac-filter", false, "Hide the tools and actions that the API token's Portainer role and team memberships cannot call")
+	scopeEnvironmentsFlag := flag.Bool("scope-environments", false, "Reject tool calls of a standard user token that target environments the user cannot access")
+	maxResultSizeFlag := flag.Int("max-result-size", mcp.DefaultMaxResultSize, "Size in bytes above which tool results are delivered in chunks readable as MCP resources (0 disables chunking)")
+	outputFormatFlag := flag.String("output-format", "json", "Format of the tool results: json, yaml, table or summary (a tool call can choose another one with the outputFormat field of its _meta)")
+	transportFlag := flag.String("transport", mcp.TransportStdio, "Transport of the MCP protocol: stdio for a single local client, or sse or http (Streamable HTTP) to serve remote clients over HTTP")
+	listenFlag := flag.String("listen", mcp.DefaultListenAddr, "Address where the sse and http transports listen, such as 127.0.0.1:8084")
+	httpAuthTokenFlag := flag.String("http-auth-token", "", "Bearer token that the clients of the sse and http transports must send in the Authorization header (unprotected when empty)")
+	principalsFlag := flag.String("principals", "", "Path to a YAML or JSON file mapping bearer tokens of the sse and http transports to the Portainer API keys the clients act with")
+	keepAliveIntervalFlag := flag.Duration("keepalive-interval", mcp.DefaultKeepAliveInterval, "Delay of client silence after which the client is pinged over stdio, or interval of the pings of the SSE streams (0 disables keep-alive and stall detection)")
+	stallTimeoutFlag := flag.Duration("stall-timeout", mcp.DefaultStallTimeout, "Delay of client silence after which the server stops, so that no orphaned process keeps its Portainer session (0 disables stall detection)")
+	sessionStateDirFlag := flag.String("session-state-dir", "", "Directory where the tool budget usage and pending plans of each session are persisted, so that reconnecting clients resume them (in memory when empty)")
+	scheduledTasksFlag := flag.String("scheduled-tasks", "", "Path to a YAML or JSON file with read-only tools to run on cron schedules; their latest results are exposed as MCP resources")
+	metricsAddrFlag := flag.String("metrics-addr", "", "Address, such as 127.0.0.1:9464, where per-tool call, error and latency metrics are served in the Prometheus format at /metrics (disabled when empty)")
+	clientLogLevelFlag := flag.String("client-log-level", mcp.DefaultClientLogLevel, "Level from which the retries and failures of the tool calls of a client, and the version check warnings, are sent to it as MCP log notifications: debug, info, notice, warning, error, critical, alert, emergency or off (clients can change it with logging/setLevel)")
+	instancesFlag := flag.String("instances", "", "Path to a YAML or JSON file with additional Portainer servers that multi-instance tools such as compareInstances can reach")
 
 	flag.Parse()
 
+	env, err := config.LoadEnv(os.Environ())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to read environment variables")
+	}
+	if err := env.Apply(flag.CommandLine); err != nil {
+		log.Fatal().Err(err).Msg("failed to apply environment variables")
+	}
+	if len(env.Settings) > 0 {
+		log.Info().Strs("variables", env.Vars()).Msg("configuration read from environment variables")
+	}
+
+	if *configFlag != "" {
+		cfg, err := config.Load(*configFlag)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load configuration file")
+		}
+		if err := cfg.Apply(flag.CommandLine); err != nil {
+			log.Fatal().Err(err).Msg("failed to apply configuration file")
+		}
+		log.Info().Str("path", *configFlag).Int("settings", len(cfg.Settings)).Msg("configuration file loaded")
+	}
+
 	if *serverFlag == "" || *tokenFlag == "" {
-		log.Fatal().Msg("Both -server and -token flags are required")
+		log.Fatal().Msg("Both -server and -token flags are required, on the command line, in the environment (PORTAINER_MCP_HOST and PORTAINER_MCP_API_KEY) or in the configuration file")
 	}
 
 	toolsPath := *toolsFlag
@@ -67,46 +131,71 @@ func main() {
 		Str("tools-path", toolsPath).
 		Bool("read-only", *readOnlyFlag).
 		Bool("granular-tools", *granularToolsFlag).
+		Str("version-check", *versionCheckFlag).
 		Bool("disable-version-check", *disableVersionCheckFlag).
 		Bool("skip-tls-verify", *skipTLSVerifyFlag).
+		Int("stack-history-size", *stackHistorySizeFlag).
+		Str("stack-history-dir", *stackHistoryDirFlag).
+		Int("delete-journal-size", *deleteJournalSizeFlag).
+		Str("delete-journal-dir", *deleteJournalDirFlag).
+		Str("maintenance-dir", *maintenanceDirFlag).
+		Dur("trend-interval", *trendIntervalFlag).
+		Int("trend-history-size", *trendHistorySizeFlag).
+		Str("trend-history-dir", *trendHistoryDirFlag).
+		Str("redaction-rules", *redactionRulesFlag).
+		Int("max-write-ops", *maxWriteOpsFlag).
+		Int("max-destructive-ops", *maxDestructiveOpsFlag).
+		Str("tool-timeouts", *toolTimeoutsFlag).
+		Int("max-retries", *maxRetriesFlag).
+		Str("k8s-strip-fields", *k8sStripFieldsFlag).
+		Bool("skip-proxy-validation", *skipProxyValidationFlag).
+		Str("proxy-rules", *proxyRulesFlag).
+		Str("policy", *policyFlag).
+		Str("audit-log", *auditLogFlag).
+		Bool("notify-webhook", *notifyWebhookFlag != ""). // the URL may embed a secret token
+		Bool("notify-secret", *notifySecretFlag != "").
+		Bool("rbac-filter", *rbacFilterFlag).
+		Bool("scope-environments", *scopeEnvironmentsFlag).
+		Str("instances", *instancesFlag).
+		Int("max-result-size", *maxResultSizeFlag).
+		Str("output-format", *outputFormatFlag).
+		Str("transport", *transportFlag).
+		Str("listen", *listenFlag).
+		Bool("http-auth-token", *httpAuthTokenFlag != "").
+		Str("principals", *principalsFlag).
+		Dur("keepalive-interval", *keepAliveIntervalFlag).
+		Dur("stall-timeout", *stallTimeoutFlag).
+		Str("session-state-dir", *sessionStateDirFlag).
+		Str("metrics-addr", *metricsAddrFlag).
+		Str("scheduled-tasks", *scheduledTasksFlag).
+		Str("client-log-level", *clientLogLevelFlag).
 		Msg("starting MCP server")
 
-	server, err := mcp.NewPortainerMCPServer(*serverFlag, *tokenFlag, toolsPath, mcp.WithReadOnly(*readOnlyFlag), mcp.WithGranularTools(*granularToolsFlag), mcp.WithDisableVersionCheck(*disableVersionCheckFlag), mcp.WithSkipTLSVerify(*skipTLSVerifyFlag))
+	server, err := mcp.NewPortainerMCPServer(*serverFlag, *tokenFlag, toolsPath, mcp.WithReadOnly(*readOnlyFlag), mcp.WithGranularTools(*granularToolsFlag), mcp.WithVersionCheck(*versionCheckFlag), mcp.WithDisableVersionCheck(*disableVersionCheckFlag), mcp.WithSkipTLSVerify(*skipTLSVerifyFlag), mcp.WithStackHistory(*stackHistoryDirFlag, *stackHistorySizeFlag), mcp.WithDeleteJournal(*deleteJournalDirFlag, *deleteJournalSizeFlag), mcp.WithMaintenanceState(*maintenanceDirFlag), mcp.WithTrendHistory(*trendHistoryDirFlag, *trendIntervalFlag, *trendHistorySizeFlag), mcp.WithRedactionRules(*redactionRulesFlag), mcp.WithToolBudget(*maxWriteOpsFlag, *maxDestructiveOpsFlag), mcp.WithToolTimeouts(*toolTimeoutsFlag), mcp.WithRetries(*maxRetriesFlag), mcp.WithKubernetesStripFields(splitList(*k8sStripFieldsFlag)), mcp.WithSkipProxyValidation(*skipProxyValidationFlag), mcp.WithProxyRules(*proxyRulesFlag), mcp.WithNotifications(*notifyWebhookFlag), mcp.WithNotificationSecret(*notifySecretFlag), mcp.WithRBACFilter(*rbacFilterFlag), mcp.WithEnvironmentScope(*scopeEnvironmentsFlag), mcp.WithInstances(*instancesFlag), mcp.WithMaxResultSize(*maxResultSizeFlag), mcp.WithKeepAlive(*keepAliveIntervalFlag, *stallTimeoutFlag), mcp.WithSessionState(*sessionStateDirFlag), mcp.WithMetricsAddr(*metricsAddrFlag), mcp.WithScheduledTasks(*scheduledTasksFlag), mcp.WithOutputFormat(*outputFormatFlag), mcp.WithPolicy(*policyFlag), mcp.WithAuditLog(*auditLogFlag), mcp.WithTransport(*transportFlag, *listenFlag), mcp.WithHTTPAuthToken(*httpAuthTokenFlag), mcp.WithPrincipals(*principalsFlag), mcp.WithClientLogLevel(*clientLogLevelFlag))
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to create server")
 	}
 
-	if *granularToolsFlag {
-		server.AddEnvironmentFeatures()
-		server.AddEnvironmentGroupFeatures()
-		server.AddTagFeatures()
-		server.AddStackFeatures()
-		server.AddSettingsFeatures()
-		server.AddSSLFeatures()
-		server.AddUserFeatures()
-		server.AddTeamFeatures()
-		server.AddAccessGroupFeatures()
-		server.AddDockerProxyFeatures()
-		server.AddKubernetesProxyFeatures()
-		server.AddKubernetesNativeFeatures()
-		server.AddSystemFeatures()
-		server.AddWebhookFeatures()
-		server.AddCustomTemplateFeatures()
-		server.AddRegistryFeatures()
-		server.AddBackupFeatures()
-		server.AddRoleFeatures()
-		server.AddMotdFeatures()
-		server.AddAuthFeatures()
-		server.AddEdgeJobFeatures()
-		server.AddEdgeUpdateScheduleFeatures()
-		server.AddAppTemplateFeatures()
-		server.AddHelmFeatures()
-	} else {
-		server.RegisterMetaTools()
-	}
+	server.RegisterTools()
+	server.AddStackHistoryResources()
+	server.AddFileResources()
+	server.AddPrompts()
+	server.AddResultResources()
+	server.AddScheduledTaskResources()
 
 	err = server.Start()
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to start server")
 	}
 }
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
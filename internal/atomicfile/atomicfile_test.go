@@ -0,0 +1,43 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteFile verifies that files are created and replaced without leaving temporary
+// files behind.
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	require.NoError(t, WriteFile(path, []byte(`{"a":1}`)))
+	require.NoError(t, WriteFile(path, []byte(`{"a":2}`)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":2}`, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left")
+
+	assert.Error(t, WriteFile(filepath.Join(dir, "missing", "state.json"), nil))
+}
+
+// TestSyncDir verifies that directories are synced, and that a missing directory is an
+// error.
+func TestSyncDir(t *testing.T) {
+	assert.NoError(t, syncDir(t.TempDir()))
+	if runtime.GOOS != "windows" {
+		assert.Error(t, syncDir(filepath.Join(t.TempDir(), "missing")))
+	}
+}
@@ -0,0 +1,69 @@
+// Package audit records security-relevant decisions of the server, such as the policy
+// decisions on write tool calls, in an append-only file of JSON lines, so that operators
+// can review who was allowed or denied what.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry is one audit record.
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Event is the kind of decision recorded, such as "policy".
+	Event   string `json:"event"`
+	Session string `json:"session,omitempty"`
+	Tool    string `json:"tool"`
+	Action  string `json:"action,omitempty"`
+	// Resource lists the identifying arguments of the call, such as "id=3".
+	Resource string `json:"resource,omitempty"`
+	// Decision is "allow" or "deny".
+	Decision string `json:"decision"`
+	// Rule is the name of the deciding rule, empty when no rule matched.
+	Rule   string `json:"rule,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Log appends entries to an audit file. It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens an audit file for appending, creating it when it does not exist.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &Log{file: file}, nil
+}
+
+// Record appends an entry as one JSON line. The time is set when it is zero.
+func (l *Log) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the audit file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
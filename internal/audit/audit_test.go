@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecord verifies that entries are appended as JSON lines, across reopenings.
+func TestRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	at := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	log, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, log.Record(Entry{Time: at, Event: "policy", Tool: "deleteEnvironment", Resource: "id=3", Decision: "deny", Rule: "protect-prod"}))
+	require.NoError(t, log.Close())
+
+	log, err = Open(path)
+	require.NoError(t, err)
+	require.NoError(t, log.Record(Entry{Event: "policy", Tool: "createTag", Decision: "allow"}))
+	require.NoError(t, log.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{Time: at, Event: "policy", Tool: "deleteEnvironment", Resource: "id=3", Decision: "deny", Rule: "protect-prod"}, entries[0])
+	assert.Equal(t, "createTag", entries[1].Tool)
+	assert.False(t, entries[1].Time.IsZero())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+// TestOpenInvalidPath verifies that an audit file that cannot be created is reported.
+func TestOpenInvalidPath(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing", "audit.log"))
+	assert.ErrorContains(t, err, "failed to open audit log")
+}
@@ -0,0 +1,288 @@
+// Package backuparchive inspects Portainer backup archives. A backup is a gzipped tar of
+// the Portainer data directory which, when created with a password, is encrypted with
+// AES-256-GCM in 1 MiB blocks under a key derived from the password with Argon2id.
+package backuparchive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Archive formats reported by Inspect.
+const (
+	FormatTarGz  = "tar.gz"
+	FormatAESGCM = "aes256-gcm"
+	// FormatUnrecognized is either an archive encrypted by Portainer before 2.19, which
+	// has no header, or a file that is not a Portainer backup.
+	FormatUnrecognized = "unrecognized"
+)
+
+const (
+	// gcmHeader starts the archives encrypted by Portainer 2.19 and later.
+	gcmHeader = "AES256-GCM"
+	// gcmBlockSize is the size of the plaintext blocks encrypted with their own nonce.
+	gcmBlockSize = 1024 * 1024
+	gcmSaltSize  = 16
+
+	// Argon2id parameters used by Portainer to derive the encryption key.
+	argon2Time    = 3
+	argon2Memory  = 12 * 1024
+	argon2Threads = 1
+	argon2KeyLen  = 32
+
+	// databaseFile is the Portainer database, which holds the schema version.
+	databaseFile = "portainer.db"
+	// encryptedDatabaseFile is the database of instances with database encryption enabled.
+	encryptedDatabaseFile = "portainer.edb"
+	// versionScanOverlap is kept between database chunks so that a version record split
+	// across two reads is still found.
+	versionScanOverlap = 512
+)
+
+// ErrWrongPassword is returned when an encrypted archive cannot be decrypted with the
+// given password.
+var ErrWrongPassword = errors.New("the archive cannot be decrypted: wrong password or corrupted archive")
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// versionRecord matches the version record of the Portainer database.
+var versionRecord = regexp.MustCompile(`\{"SchemaVersion":"([^"]+)"(?:,"MigratorCount":\d+)?,"Edition":(\d+)`)
+
+// editions are the names of the Portainer software editions stored in the database.
+var editions = map[int]string{1: "CE", 2: "BE", 3: "EE"}
+
+// Info describes the content of a backup archive.
+type Info struct {
+	Format    string `json:"format"`
+	Encrypted bool   `json:"encrypted"`
+	// Decrypted tells whether the password of an encrypted archive was accepted.
+	Decrypted bool `json:"decrypted"`
+	// Entries are the top-level files and directories of the archive.
+	Entries    []string `json:"entries,omitempty"`
+	EntryCount int      `json:"entryCount"`
+	// PortainerVersion is the schema version of the database, which is the version of the
+	// Portainer instance that created the backup.
+	PortainerVersion string `json:"portainerVersion,omitempty"`
+	Edition          string `json:"edition,omitempty"`
+	// DatabaseEncrypted tells that the database is encrypted, so that its version is unknown.
+	DatabaseEncrypted bool `json:"databaseEncrypted,omitempty"`
+}
+
+// Inspect reads a whole backup archive and checks its integrity: every encrypted block is
+// authenticated, the gzip checksum is verified and every tar entry is read. Encrypted
+// archives are only opened when a password is given; otherwise only their format is
+// reported. Archives encrypted before Portainer 2.19 are reported as FormatUnrecognized.
+func Inspect(r io.Reader, password string) (info Info, err error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(gcmHeader))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Info{}, fmt.Errorf("failed to read the archive: %w", err)
+	}
+
+	var content io.Reader = br
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		info.Format = FormatTarGz
+	case string(header) == gcmHeader:
+		info.Format = FormatAESGCM
+		info.Encrypted = true
+		if password == "" {
+			_, err := io.Copy(io.Discard, br)
+			return info, err
+		}
+		if _, err := br.Discard(len(gcmHeader)); err != nil {
+			return info, err
+		}
+		gcm, err := newGCMReader(br, password)
+		if err != nil {
+			return info, err
+		}
+		defer func() { info.Decrypted = gcm.opened }()
+		content = gcm
+	case len(header) == 0:
+		return info, fmt.Errorf("the archive is empty")
+	default:
+		info.Format = FormatUnrecognized
+		_, err := io.Copy(io.Discard, br)
+		return info, err
+	}
+
+	err = readTarGz(content, &info)
+	return info, err
+}
+
+// readTarGz reads every entry of a gzipped tar and records the archive content in info.
+func readTarGz(r io.Reader, info *Info) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		if errors.Is(err, ErrWrongPassword) {
+			return err
+		}
+		return fmt.Errorf("the archive is not a valid gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("the archive is corrupted after %d entries: %w", info.EntryCount, err)
+		}
+		info.EntryCount++
+
+		name := path.Clean(hdr.Name)
+		top, _, _ := strings.Cut(name, "/")
+		if top != "." && !slices.Contains(info.Entries, top) {
+			info.Entries = append(info.Entries, top)
+		}
+
+		switch name {
+		case databaseFile:
+			if err := readVersion(tr, info); err != nil {
+				return fmt.Errorf("the archive is corrupted in %s: %w", databaseFile, err)
+			}
+			continue
+		case encryptedDatabaseFile:
+			info.DatabaseEncrypted = true
+		}
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			return fmt.Errorf("the archive is corrupted in %s: %w", hdr.Name, err)
+		}
+	}
+	slices.Sort(info.Entries)
+
+	// Reading past the tar end-of-archive marker makes gzip verify its checksum.
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return fmt.Errorf("the archive is corrupted: %w", err)
+	}
+	return nil
+}
+
+// readVersion scans the Portainer database for its version record.
+func readVersion(r io.Reader, info *Info) error {
+	buf := make([]byte, 64*1024)
+	var window []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 && info.PortainerVersion == "" {
+			window = append(window, buf[:n]...)
+			if m := versionRecord.FindSubmatch(window); m != nil {
+				info.PortainerVersion = string(m[1])
+				if edition, convErr := strconv.Atoi(string(m[2])); convErr == nil {
+					info.Edition = editions[edition]
+				}
+			} else if len(window) > versionScanOverlap {
+				window = append(window[:0], window[len(window)-versionScanOverlap:]...)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// gcmReader decrypts an archive encrypted by Portainer with AES-256-GCM. Each 1 MiB
+// plaintext block is sealed with its own nonce, the previous nonce incremented as a
+// big-endian counter.
+type gcmReader struct {
+	src   *bufio.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	block []byte
+	buf   []byte
+	done  bool
+	// opened is set once a block has been authenticated, which proves the password.
+	opened bool
+}
+
+// newGCMReader reads the salt and nonce that follow the header and derives the key.
+func newGCMReader(src *bufio.Reader, password string) (*gcmReader, error) {
+	salt := make([]byte, gcmSaltSize)
+	if _, err := io.ReadFull(src, salt); err != nil {
+		return nil, fmt.Errorf("the encrypted archive is truncated: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(src, nonce); err != nil {
+		return nil, fmt.Errorf("the encrypted archive is truncated: %w", err)
+	}
+
+	return &gcmReader{
+		src:   src,
+		aead:  aead,
+		nonce: nonce,
+		block: make([]byte, gcmBlockSize+aead.Overhead()),
+	}, nil
+}
+
+// Read returns the decrypted content, authenticating each block before returning it.
+func (g *gcmReader) Read(p []byte) (int, error) {
+	for len(g.buf) == 0 {
+		if g.done {
+			return 0, io.EOF
+		}
+		n, err := io.ReadFull(g.src, g.block)
+		switch {
+		case errors.Is(err, io.EOF):
+			g.done = true
+			continue
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			g.done = true
+		case err != nil:
+			return 0, err
+		}
+
+		plain, err := g.aead.Open(g.block[:0], g.nonce, g.block[:n], nil)
+		if err != nil {
+			return 0, ErrWrongPassword
+		}
+		g.buf = plain
+		g.opened = true
+		incrementNonce(g.nonce)
+	}
+
+	n := copy(p, g.buf)
+	g.buf = g.buf[n:]
+	return n, nil
+}
+
+// incrementNonce adds one to a nonce read as a big-endian number.
+func incrementNonce(nonce []byte) {
+	for i := len(nonce) - 1; i >= 0; i-- {
+		nonce[i]++
+		if nonce[i] != 0 {
+			return
+		}
+	}
+}
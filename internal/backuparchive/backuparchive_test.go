@@ -0,0 +1,166 @@
+package backuparchive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+)
+
+// backupArchive builds a gzipped tar with the given files.
+func backupArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content))}))
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// encrypt encrypts an archive the way Portainer does when a backup password is set.
+func encrypt(t *testing.T, plain []byte, password string) []byte {
+	t.Helper()
+	salt := make([]byte, gcmSaltSize)
+	_, err := rand.Read(salt)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	out := append([]byte(gcmHeader), salt...)
+	out = append(out, nonce...)
+	for len(plain) > 0 {
+		n := min(len(plain), gcmBlockSize)
+		out = aead.Seal(out, nonce, plain[:n], nil)
+		plain = plain[n:]
+		incrementNonce(nonce)
+	}
+	return out
+}
+
+// TestInspect verifies the format detection, decryption and integrity checks.
+func TestInspect(t *testing.T) {
+	database := append(bytes.Repeat([]byte{0}, 100_000), []byte(`{"SchemaVersion":"2.27.1","MigratorCount":0,"Edition":1,"InstanceID":"abc"}`)...)
+	noise := make([]byte, 3*gcmBlockSize)
+	_, err := rand.Read(noise)
+	require.NoError(t, err)
+	archive := backupArchive(t, map[string][]byte{
+		"portainer.db":         database,
+		"certs/cert.pem":       []byte("cert"),
+		"compose/1/stack.yml":  []byte("services: {}"),
+		"portainer.key":        []byte("key"),
+		"custom_templates/big": noise,
+	})
+	require.Greater(t, len(archive), 2*gcmBlockSize, "the archive spans several encrypted blocks")
+	encrypted := encrypt(t, archive, "s3cret")
+
+	tests := []struct {
+		name          string
+		data          []byte
+		password      string
+		expectedError string
+		expected      Info
+	}{
+		{
+			name: "plain archive",
+			data: archive,
+			expected: Info{
+				Format:           FormatTarGz,
+				Entries:          []string{"certs", "compose", "custom_templates", "portainer.db", "portainer.key"},
+				EntryCount:       5,
+				PortainerVersion: "2.27.1",
+				Edition:          "CE",
+			},
+		},
+		{
+			name:     "encrypted archive with the password",
+			data:     encrypted,
+			password: "s3cret",
+			expected: Info{
+				Format:           FormatAESGCM,
+				Encrypted:        true,
+				Decrypted:        true,
+				Entries:          []string{"certs", "compose", "custom_templates", "portainer.db", "portainer.key"},
+				EntryCount:       5,
+				PortainerVersion: "2.27.1",
+				Edition:          "CE",
+			},
+		},
+		{
+			name:     "encrypted archive without password",
+			data:     encrypted,
+			expected: Info{Format: FormatAESGCM, Encrypted: true},
+		},
+		{
+			name:          "wrong password",
+			data:          encrypted,
+			password:      "wrong",
+			expectedError: ErrWrongPassword.Error(),
+			expected:      Info{Format: FormatAESGCM, Encrypted: true},
+		},
+		{
+			name:          "truncated archive",
+			data:          archive[:len(archive)/2],
+			expectedError: "corrupted",
+		},
+		{
+			name:          "tampered encrypted block",
+			data:          append(append([]byte{}, encrypted[:len(encrypted)-10]...), bytes.Repeat([]byte{0xff}, 10)...),
+			password:      "s3cret",
+			expectedError: "corrupted",
+		},
+		{
+			name:     "unrecognized file",
+			data:     []byte("not a backup archive"),
+			expected: Info{Format: FormatUnrecognized},
+		},
+		{
+			name:          "empty file",
+			expectedError: "the archive is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Inspect(bytes.NewReader(tt.data), tt.password)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expected.Format != "" {
+					assert.Equal(t, tt.expected, info)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+// TestInspectEncryptedDatabase verifies that archives of instances with database encryption
+// are reported without a version.
+func TestInspectEncryptedDatabase(t *testing.T) {
+	archive := backupArchive(t, map[string][]byte{"portainer.edb": []byte("encrypted")})
+
+	info, err := Inspect(bytes.NewReader(archive), "")
+	require.NoError(t, err)
+	assert.True(t, info.DatabaseEncrypted)
+	assert.Empty(t, info.PortainerVersion)
+}
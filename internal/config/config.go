@@ -0,0 +1,188 @@
+// Package config loads the configuration file of the server: a YAML or JSON file whose
+// keys are the names of the command-line flags, such as server, read-only or
+// tool-timeouts, so that a deployment does not pass everything on the command line.
+//
+// The API token can be referenced instead of written in the file, with token-file (a file
+// holding the token) or token-env (an environment variable holding it).
+//
+// The flags can also be set by PORTAINER_MCP_ environment variables, so that a container
+// does not hold secrets in its arguments. Flags given on the command line take precedence
+// over the environment variables, which take precedence over the file.
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of the settings that reference the API token instead of holding it.
+const (
+	// KeyTokenFile is the path of a file holding the API token, relative to the
+	// configuration file.
+	KeyTokenFile = "token-file"
+	// KeyTokenEnv is the name of an environment variable holding the API token.
+	KeyTokenEnv = "token-env"
+
+	// tokenFlag is the flag set by the token references.
+	tokenFlag = "token"
+	// configFlag is the flag naming the configuration file, which the file cannot set.
+	configFlag = "config"
+)
+
+// Setting is the value of a flag set by a configuration file.
+type Setting struct {
+	Flag  string
+	Value string
+}
+
+// File is a loaded configuration file.
+type File struct {
+	Path string
+	// Settings are the flag values of the file, in file order.
+	Settings []Setting
+}
+
+// Load reads a configuration file (YAML or JSON). Values are scalars, lists (joined with
+// commas, as k8s-strip-fields expects) or mappings (joined as key=value pairs in file
+// order, as tool-timeouts expects). Token references are resolved to the token.
+func Load(file string) (*File, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %s: %w", file, err)
+	}
+
+	cfg := &File{Path: file}
+	if len(doc.Content) == 0 {
+		return cfg, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration file %s must map flag names to values", file)
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < len(root.Content); i += 2 {
+		key, node := root.Content[i].Value, root.Content[i+1]
+		if seen[key] {
+			return nil, fmt.Errorf("configuration file %s sets %s twice", file, key)
+		}
+		seen[key] = true
+
+		value, err := settingValue(node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in configuration file %s: %w", key, file, err)
+		}
+		cfg.Settings = append(cfg.Settings, Setting{Flag: key, Value: value})
+	}
+
+	if err := cfg.resolveToken(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// settingValue returns the flag value of a setting.
+func settingValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", fmt.Errorf("missing value")
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("list items must be scalars")
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	case yaml.MappingNode:
+		pairs := make([]string, 0, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i+1].Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("mapping values must be scalars")
+			}
+			pairs = append(pairs, node.Content[i].Value+"="+node.Content[i+1].Value)
+		}
+		return strings.Join(pairs, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value")
+}
+
+// resolveToken replaces a token reference with the token it references. At most one of
+// token, token-file and token-env can be set.
+func (f *File) resolveToken() error {
+	var refs []string
+	index := -1
+	for i, setting := range f.Settings {
+		switch setting.Flag {
+		case tokenFlag, KeyTokenFile, KeyTokenEnv:
+			refs = append(refs, setting.Flag)
+			index = i
+		}
+	}
+	if len(refs) > 1 {
+		return fmt.Errorf("configuration file %s sets %s: set only one of %s, %s and %s", f.Path, strings.Join(refs, " and "), tokenFlag, KeyTokenFile, KeyTokenEnv)
+	}
+	if index < 0 {
+		return nil
+	}
+
+	setting := &f.Settings[index]
+	switch setting.Flag {
+	case KeyTokenFile:
+		path := setting.Value
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(f.Path), path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the token file of configuration file %s: %w", f.Path, err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return fmt.Errorf("token file %s is empty", path)
+		}
+		*setting = Setting{Flag: tokenFlag, Value: token}
+	case KeyTokenEnv:
+		token := strings.TrimSpace(os.Getenv(setting.Value))
+		if token == "" {
+			return fmt.Errorf("environment variable %s, referenced by configuration file %s, is not set", setting.Value, f.Path)
+		}
+		*setting = Setting{Flag: tokenFlag, Value: token}
+	}
+	return nil
+}
+
+// Apply sets the flags of a parsed flag set from the settings of the file, except those
+// given on the command line. It fails on a setting that names no flag or whose value the
+// flag rejects.
+func (f *File) Apply(fs *flag.FlagSet) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(fl *flag.Flag) { explicit[fl.Name] = true })
+
+	for _, setting := range f.Settings {
+		if setting.Flag == configFlag || fs.Lookup(setting.Flag) == nil {
+			return fmt.Errorf("unknown setting %s in configuration file %s: use the name of a command-line flag, such as server or read-only", setting.Flag, f.Path)
+		}
+		if explicit[setting.Flag] {
+			continue
+		}
+		if err := fs.Set(setting.Flag, setting.Value); err != nil {
+			return fmt.Errorf("invalid %s in configuration file %s: %w", setting.Flag, f.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig writes a configuration file in a temporary directory and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "portainer-mcp.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// testFlags returns a flag set with flags of every kind.
+func testFlags() (*flag.FlagSet, *string, *bool, *time.Duration, *string) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	server := fs.String("server", "", "")
+	fs.String("token", "", "")
+	fs.String("config", "", "")
+	readOnly := fs.Bool("read-only", false, "")
+	stall := fs.Duration("stall-timeout", time.Minute, "")
+	timeouts := fs.String("tool-timeouts", "", "")
+	fs.String("k8s-strip-fields", "", "")
+	return fs, server, readOnly, stall, timeouts
+}
+
+// TestLoad verifies the conversion of the values of a configuration file to flag values.
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+server: https://portainer.example.com:9443
+read-only: true
+max-write-ops: 20
+k8s-strip-fields:
+  - metadata.managedFields
+  - status
+tool-timeouts:
+  installHelmChart: 5m
+  "list*": 15s
+`)
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Setting{
+		{Flag: "server", Value: "https://portainer.example.com:9443"},
+		{Flag: "read-only", Value: "true"},
+		{Flag: "max-write-ops", Value: "20"},
+		{Flag: "k8s-strip-fields", Value: "metadata.managedFields,status"},
+		{Flag: "tool-timeouts", Value: "installHelmChart=5m,list*=15s"},
+	}, cfg.Settings)
+
+	cfg, err = Load(writeConfig(t, `{"server": "https://portainer.example.com", "granular-tools": true}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Setting{{Flag: "server", Value: "https://portainer.example.com"}, {Flag: "granular-tools", Value: "true"}}, cfg.Settings)
+
+	cfg, err = Load(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Settings)
+}
+
+// TestLoadToken verifies the resolution of the token references.
+func TestLoadToken(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("ptr_file\n"), 0o600))
+	path := filepath.Join(dir, "portainer-mcp.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("token-file: token\n"), 0o600))
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Setting{{Flag: "token", Value: "ptr_file"}}, cfg.Settings)
+
+	t.Setenv("TEST_PORTAINER_TOKEN", "ptr_env")
+	cfg, err = Load(writeConfig(t, "token-env: TEST_PORTAINER_TOKEN\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Setting{{Flag: "token", Value: "ptr_env"}}, cfg.Settings)
+
+	_, err = Load(writeConfig(t, "token-env: TEST_PORTAINER_UNSET_TOKEN\n"))
+	assert.ErrorContains(t, err, "environment variable TEST_PORTAINER_UNSET_TOKEN")
+
+	_, err = Load(writeConfig(t, "token: ptr_abc\ntoken-env: TEST_PORTAINER_TOKEN\n"))
+	assert.ErrorContains(t, err, "set only one of token, token-file and token-env")
+}
+
+// TestLoadErrors verifies the rejected configuration files.
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "not a mapping", content: "- server\n", want: "must map flag names to values"},
+		{name: "invalid YAML", content: "server: [", want: "failed to parse configuration file"},
+		{name: "duplicate", content: "server: a\nserver: b\n", want: "sets server twice"},
+		{name: "missing value", content: "server:\n", want: "invalid server"},
+		{name: "nested list", content: "k8s-strip-fields: [[a]]\n", want: "list items must be scalars"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read configuration file")
+}
+
+// TestApply verifies that the file sets the flags not given on the command line.
+func TestApply(t *testing.T) {
+	cfg := &File{Path: "portainer-mcp.yaml", Settings: []Setting{
+		{Flag: "server", Value: "https://file.example.com"},
+		{Flag: "read-only", Value: "true"},
+		{Flag: "stall-timeout", Value: "5m"},
+		{Flag: "tool-timeouts", Value: "list*=15s"},
+	}}
+
+	fs, server, readOnly, stall, timeouts := testFlags()
+	require.NoError(t, fs.Parse([]string{"-server", "https://flag.example.com"}))
+	require.NoError(t, cfg.Apply(fs))
+	assert.Equal(t, "https://flag.example.com", *server, "command-line flags take precedence")
+	assert.True(t, *readOnly)
+	assert.Equal(t, 5*time.Minute, *stall)
+	assert.Equal(t, "list*=15s", *timeouts)
+
+	fs, _, _, _, _ = testFlags()
+	require.NoError(t, fs.Parse(nil))
+	err := (&File{Path: "portainer-mcp.yaml", Settings: []Setting{{Flag: "readonly", Value: "true"}}}).Apply(fs)
+	assert.ErrorContains(t, err, "unknown setting readonly")
+
+	err = (&File{Path: "portainer-mcp.yaml", Settings: []Setting{{Flag: "config", Value: "other.yaml"}}}).Apply(fs)
+	assert.ErrorContains(t, err, "unknown setting config")
+
+	err = (&File{Path: "portainer-mcp.yaml", Settings: []Setting{{Flag: "stall-timeout", Value: "soon"}}}).Apply(fs)
+	assert.ErrorContains(t, err, "invalid stall-timeout in configuration file portainer-mcp.yaml")
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvPrefix is the prefix of the environment variables setting the flags: the flag name in
+// upper case with dashes replaced by underscores follows it, such as PORTAINER_MCP_READ_ONLY
+// for read-only.
+const EnvPrefix = "PORTAINER_MCP_"
+
+// Aliases of the environment variables of the connection flags, named as container images
+// usually name them.
+const (
+	// EnvHost sets the server flag, like PORTAINER_MCP_SERVER.
+	EnvHost = EnvPrefix + "HOST"
+	// EnvAPIKey sets the token flag, like PORTAINER_MCP_TOKEN.
+	EnvAPIKey = EnvPrefix + "API_KEY"
+)
+
+// envAliases maps the alias environment variables to the flag they set.
+var envAliases = map[string]string{
+	EnvHost:   "server",
+	EnvAPIKey: tokenFlag,
+}
+
+// EnvVar returns the name of the environment variable setting a flag.
+func EnvVar(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// EnvSetting is the value of a flag set by an environment variable.
+type EnvSetting struct {
+	Var string
+	Setting
+}
+
+// Env is the flag values set by the environment variables.
+type Env struct {
+	// Settings are the flag values, sorted by variable name.
+	Settings []EnvSetting
+}
+
+// LoadEnv reads the flag values from the PORTAINER_MCP_ variables of environ, in the
+// format of os.Environ. Variables set to an empty value are ignored. The API token can be
+// read from a file with PORTAINER_MCP_TOKEN_FILE, as with the token-file setting of a
+// configuration file; a relative path is relative to the working directory.
+func LoadEnv(environ []string) (*Env, error) {
+	env := &Env{}
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) || value == "" {
+			continue
+		}
+		flagName, ok := envAliases[name]
+		if !ok {
+			flagName = strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, EnvPrefix), "_", "-"))
+		}
+		env.Settings = append(env.Settings, EnvSetting{Var: name, Setting: Setting{Flag: flagName, Value: value}})
+	}
+	sort.Slice(env.Settings, func(i, j int) bool { return env.Settings[i].Var < env.Settings[j].Var })
+
+	seen := map[string]string{}
+	for _, setting := range env.Settings {
+		flagName := setting.Flag
+		if flagName == KeyTokenFile {
+			flagName = tokenFlag
+		}
+		if other, ok := seen[flagName]; ok {
+			return nil, fmt.Errorf("environment variables %s and %s both set %s: set only one of them", other, setting.Var, flagName)
+		}
+		seen[flagName] = setting.Var
+	}
+
+	if err := env.resolveTokenFile(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// resolveTokenFile replaces PORTAINER_MCP_TOKEN_FILE with the token of the file it names.
+func (e *Env) resolveTokenFile() error {
+	for i, setting := range e.Settings {
+		if setting.Flag != KeyTokenFile {
+			continue
+		}
+		data, err := os.ReadFile(setting.Value)
+		if err != nil {
+			return fmt.Errorf("failed to read the token file of environment variable %s: %w", setting.Var, err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return fmt.Errorf("token file %s, referenced by environment variable %s, is empty", setting.Value, setting.Var)
+		}
+		e.Settings[i].Setting = Setting{Flag: tokenFlag, Value: token}
+	}
+	return nil
+}
+
+// Vars returns the names of the environment variables that set flags.
+func (e *Env) Vars() []string {
+	vars := make([]string, 0, len(e.Settings))
+	for _, setting := range e.Settings {
+		vars = append(vars, setting.Var)
+	}
+	return vars
+}
+
+// Apply sets the flags of a parsed flag set from the environment variables, except those
+// given on the command line. The flags it sets count as given, so a configuration file
+// applied afterwards does not override them. It fails on a variable that names no flag or
+// whose value the flag rejects.
+func (e *Env) Apply(fs *flag.FlagSet) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(fl *flag.Flag) { explicit[fl.Name] = true })
+
+	for _, setting := range e.Settings {
+		if fs.Lookup(setting.Flag) == nil {
+			return fmt.Errorf("unknown environment variable %s: use %s followed by the name of a command-line flag, such as %s", setting.Var, EnvPrefix, EnvVar("read-only"))
+		}
+		if explicit[setting.Flag] {
+			continue
+		}
+		if err := fs.Set(setting.Flag, setting.Value); err != nil {
+			return fmt.Errorf("invalid environment variable %s: %w", setting.Var, err)
+		}
+	}
+	return nil
+}
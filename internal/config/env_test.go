@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEnvVar verifies the names of the environment variables setting the flags.
+func TestEnvVar(t *testing.T) {
+	assert.Equal(t, "PORTAINER_MCP_READ_ONLY", EnvVar("read-only"))
+	assert.Equal(t, "PORTAINER_MCP_K8S_STRIP_FIELDS", EnvVar("k8s-strip-fields"))
+}
+
+// TestLoadEnv verifies the conversion of the environment variables to flag values.
+func TestLoadEnv(t *testing.T) {
+	env, err := LoadEnv([]string{
+		"HOME=/root",
+		"PORTAINER_MCP_READ_ONLY=true",
+		"PORTAINER_MCP_HOST=https://portainer.example.com",
+		"PORTAINER_MCP_API_KEY=ptr_env",
+		"PORTAINER_MCP_TOOL_TIMEOUTS=list*=15s",
+		"PORTAINER_MCP_POLICY=",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []EnvSetting{
+		{Var: EnvAPIKey, Setting: Setting{Flag: "token", Value: "ptr_env"}},
+		{Var: EnvHost, Setting: Setting{Flag: "server", Value: "https://portainer.example.com"}},
+		{Var: "PORTAINER_MCP_READ_ONLY", Setting: Setting{Flag: "read-only", Value: "true"}},
+		{Var: "PORTAINER_MCP_TOOL_TIMEOUTS", Setting: Setting{Flag: "tool-timeouts", Value: "list*=15s"}},
+	}, env.Settings)
+	assert.Equal(t, []string{EnvAPIKey, EnvHost, "PORTAINER_MCP_READ_ONLY", "PORTAINER_MCP_TOOL_TIMEOUTS"}, env.Vars())
+
+	path := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(path, []byte("ptr_file\n"), 0o600))
+	env, err = LoadEnv([]string{"PORTAINER_MCP_TOKEN_FILE=" + path})
+	require.NoError(t, err)
+	assert.Equal(t, []EnvSetting{{Var: "PORTAINER_MCP_TOKEN_FILE", Setting: Setting{Flag: "token", Value: "ptr_file"}}}, env.Settings)
+
+	_, err = LoadEnv([]string{"PORTAINER_MCP_HOST=https://a.example.com", "PORTAINER_MCP_SERVER=https://b.example.com"})
+	assert.ErrorContains(t, err, "environment variables PORTAINER_MCP_HOST and PORTAINER_MCP_SERVER both set server")
+
+	_, err = LoadEnv([]string{"PORTAINER_MCP_API_KEY=ptr_env", "PORTAINER_MCP_TOKEN_FILE=" + path})
+	assert.ErrorContains(t, err, "both set token")
+
+	_, err = LoadEnv([]string{"PORTAINER_MCP_TOKEN_FILE=" + filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorContains(t, err, "failed to read the token file of environment variable PORTAINER_MCP_TOKEN_FILE")
+}
+
+// TestEnvApply verifies the precedence of the command-line flags over the environment
+// variables, and of the environment variables over the configuration file.
+func TestEnvApply(t *testing.T) {
+	env, err := LoadEnv([]string{
+		"PORTAINER_MCP_HOST=https://env.example.com",
+		"PORTAINER_MCP_READ_ONLY=1",
+		"PORTAINER_MCP_STALL_TIMEOUT=5m",
+	})
+	require.NoError(t, err)
+	cfg := &File{Path: "portainer-mcp.yaml", Settings: []Setting{
+		{Flag: "read-only", Value: "false"},
+		{Flag: "stall-timeout", Value: "10m"},
+		{Flag: "tool-timeouts", Value: "list*=15s"},
+	}}
+
+	fs, server, readOnly, stall, timeouts := testFlags()
+	require.NoError(t, fs.Parse([]string{"-server", "https://flag.example.com"}))
+	require.NoError(t, env.Apply(fs))
+	require.NoError(t, cfg.Apply(fs))
+	assert.Equal(t, "https://flag.example.com", *server, "command-line flags take precedence")
+	assert.True(t, *readOnly, "environment variables take precedence over the file")
+	assert.Equal(t, 5*time.Minute, *stall)
+	assert.Equal(t, "list*=15s", *timeouts)
+
+	fs, _, _, _, _ = testFlags()
+	require.NoError(t, fs.Parse(nil))
+	err = (&Env{Settings: []EnvSetting{{Var: "PORTAINER_MCP_READONLY", Setting: Setting{Flag: "readonly", Value: "true"}}}}).Apply(fs)
+	assert.ErrorContains(t, err, "unknown environment variable PORTAINER_MCP_READONLY")
+
+	err = (&Env{Settings: []EnvSetting{{Var: "PORTAINER_MCP_READ_ONLY", Setting: Setting{Flag: "read-only", Value: "yes"}}}}).Apply(fs)
+	assert.ErrorContains(t, err, "invalid environment variable PORTAINER_MCP_READ_ONLY")
+}
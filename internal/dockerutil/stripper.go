@@ -0,0 +1,91 @@
+// Package dockerutil provides utilities for processing Docker Engine API responses.
+// It includes functions to strip verbose fields (such as GraphDriver or the full
+// container environment) from JSON payloads to reduce response size.
+package dockerutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// DefaultStripFields are the fields removed from Docker API objects by StripResponse.
+// Config.Env is removed because it is verbose and frequently holds credentials.
+var DefaultStripFields = []string{"GraphDriver", "Config.Env"}
+
+// KeepAll is the keep value that disables stripping for a single response.
+const KeepAll = "*"
+
+// StripResponse removes the given dotted fields from a JSON object or from every
+// object of a JSON array, and returns the modified JSON. Fields listed in keep, fields
+// below them and fields containing them are not removed; a keep entry of KeepAll
+// disables stripping. Bodies that are not JSON objects or arrays are returned unchanged.
+func StripResponse(body []byte, fields, keep []string) ([]byte, error) {
+	fields = fieldsToStrip(fields, keep)
+	if len(fields) == 0 {
+		return body, nil
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return body, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return body, nil
+	}
+
+	switch v := doc.(type) {
+	case map[string]any:
+		stripObject(v, fields)
+	case []any:
+		for _, item := range v {
+			if obj, ok := item.(map[string]any); ok {
+				stripObject(obj, fields)
+			}
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// fieldsToStrip returns the fields that are not excluded by keep.
+func fieldsToStrip(fields, keep []string) []string {
+	var result []string
+	for _, f := range fields {
+		kept := false
+		for _, k := range keep {
+			if k == KeepAll || f == k || strings.HasPrefix(f, k+".") || strings.HasPrefix(k, f+".") {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// stripObject removes the dotted fields from an object. Missing fields and fields
+// below non-object values are ignored.
+func stripObject(obj map[string]any, fields []string) {
+	for _, f := range fields {
+		keys := strings.Split(f, ".")
+		current := obj
+		for _, key := range keys[:len(keys)-1] {
+			next, ok := current[key].(map[string]any)
+			if !ok {
+				current = nil
+				break
+			}
+			current = next
+		}
+		if current != nil {
+			delete(current, keys[len(keys)-1])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package dockerutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStripResponse verifies stripping of Docker API objects and arrays.
+func TestStripResponse(t *testing.T) {
+	inspect := `{"Id":"abc","GraphDriver":{"Name":"overlay2"},"Config":{"Image":"nginx","Env":["PASSWORD=x"]},"SizeRw":12345678901}`
+
+	tests := []struct {
+		name     string
+		body     string
+		keep     []string
+		expected string
+		raw      bool
+	}{
+		{
+			name:     "container inspect",
+			body:     inspect,
+			expected: `{"Config":{"Image":"nginx"},"Id":"abc","SizeRw":12345678901}`,
+		},
+		{
+			name:     "keep env",
+			body:     inspect,
+			keep:     []string{"Config.Env"},
+			expected: `{"Config":{"Image":"nginx","Env":["PASSWORD=x"]},"Id":"abc","SizeRw":12345678901}`,
+		},
+		{
+			name:     "keep parent",
+			body:     inspect,
+			keep:     []string{"Config"},
+			expected: `{"Config":{"Image":"nginx","Env":["PASSWORD=x"]},"Id":"abc","SizeRw":12345678901}`,
+		},
+		{
+			name:     "keep child of stripped field",
+			body:     inspect,
+			keep:     []string{"GraphDriver.Name"},
+			expected: `{"Config":{"Image":"nginx"},"GraphDriver":{"Name":"overlay2"},"Id":"abc","SizeRw":12345678901}`,
+		},
+		{
+			name: "keep all",
+			body: inspect,
+			keep: []string{KeepAll},
+			raw:  true,
+		},
+		{
+			name:     "array of objects",
+			body:     `[{"Id":"a","GraphDriver":{}},{"Id":"b","Config":"not-an-object"},"text"]`,
+			expected: `[{"Id":"a"},{"Config":"not-an-object","Id":"b"},"text"]`,
+		},
+		{
+			name: "non-JSON body",
+			body: "OK",
+			raw:  true,
+		},
+		{
+			name: "invalid JSON",
+			body: "{not json",
+			raw:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := StripResponse([]byte(tt.body), DefaultStripFields, tt.keep)
+			require.NoError(t, err)
+			if tt.raw {
+				assert.Equal(t, tt.body, string(result))
+				return
+			}
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
@@ -0,0 +1,182 @@
+// Package format renders the JSON payload of tool results in the output format chosen by
+// the operator or the MCP client, so that every tool gains the formats without code of
+// its own:
+//   - json: the payload unchanged
+//   - yaml: the same data as a YAML document, keeping the order of the fields
+//   - table: compact aligned text columns, one row per item of a list
+//   - summary: item counts and the names of the items, without their details
+//
+// Object fields keep the order of the JSON payload in every format.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the output formats
+const (
+	JSON    = "json"
+	YAML    = "yaml"
+	Table   = "table"
+	Summary = "summary"
+)
+
+// MIME types of the formatted payloads
+const (
+	MIMETypeJSON      = "application/json"
+	MIMETypeYAML      = "text/x-yaml"
+	MIMETypePlainText = "text/plain"
+)
+
+// Formatter renders a JSON payload as text.
+type Formatter interface {
+	Format(data []byte) (string, error)
+	// MIMEType is the MIME type of the formatted text.
+	MIMEType() string
+}
+
+var formatters = map[string]Formatter{
+	JSON:    jsonFormatter{},
+	YAML:    yamlFormatter{},
+	Table:   tableFormatter{},
+	Summary: summaryFormatter{},
+}
+
+// Names returns the names of the output formats, json first.
+func Names() []string {
+	return []string{JSON, YAML, Table, Summary}
+}
+
+// Get returns the formatter of an output format. The name is case-insensitive.
+func Get(name string) (Formatter, error) {
+	formatter, ok := formatters[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (expected one of: %s)", name, strings.Join(Names(), ", "))
+	}
+	return formatter, nil
+}
+
+// jsonFormatter returns the payload unchanged.
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(data []byte) (string, error) {
+	return string(data), nil
+}
+
+func (jsonFormatter) MIMEType() string {
+	return MIMETypeJSON
+}
+
+// yamlFormatter renders the payload as a YAML document.
+type yamlFormatter struct{}
+
+func (yamlFormatter) Format(data []byte) (string, error) {
+	root, err := parse(data)
+	if err != nil {
+		return "", err
+	}
+	resetStyle(root)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (yamlFormatter) MIMEType() string {
+	return MIMETypeYAML
+}
+
+// parse decodes a JSON payload into a YAML node tree, which keeps the order of the object
+// fields. JSON is valid YAML, so the YAML decoder reads it as is.
+func parse(data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("payload is empty")
+	}
+	return doc.Content[0], nil
+}
+
+// resetStyle drops the flow and quoting styles of the JSON syntax, so that the encoder
+// writes block YAML and only quotes the strings that need it.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// scalarText returns the text of a scalar node, "-" for null.
+func scalarText(node *yaml.Node) string {
+	if node.Tag == "!!null" {
+		return "-"
+	}
+	return node.Value
+}
+
+// fields returns the key and value nodes of a mapping node, in order.
+func fields(node *yaml.Node) ([]string, []*yaml.Node) {
+	keys := make([]string, 0, len(node.Content)/2)
+	values := make([]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+		values = append(values, node.Content[i+1])
+	}
+	return keys, values
+}
+
+// field returns the value of a key of a mapping node, or nil.
+func field(node *yaml.Node, key string) *yaml.Node {
+	keys, values := fields(node)
+	if i := slices.Index(keys, key); i >= 0 {
+		return values[i]
+	}
+	return nil
+}
+
+// isRecordList reports whether a node is a non-empty list of objects.
+func isRecordList(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// countText describes the size of a list or an object.
+func countText(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		return plural(len(node.Content), "item")
+	case yaml.MappingNode:
+		return plural(len(node.Content)/2, "field")
+	default:
+		return scalarText(node)
+	}
+}
+
+// plural returns "<n> <noun>" with the noun in the plural unless n is 1.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,97 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormat verifies every output format against objects, lists and scalars.
+func TestFormat(t *testing.T) {
+	const stacks = `[{"id":1,"name":"web","status":"active","env":["A=1","B=2"],"labels":{"team":"ops"}},{"id":12,"name":"monitoring-with-a-rather-long-stack-name","status":null,"extra":true}]`
+	const report = `{"since":"2025-06-01T10:00:00Z","samples":3,"notes":[],"environments":[{"id":4,"name":"edge"},{"id":2,"name":"prod"}]}`
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   string
+	}{
+		{name: "json unchanged", format: "json", input: `{"b":1,"a":2}`, want: `{"b":1,"a":2}`},
+		{
+			name:   "yaml keeps field order",
+			format: "yaml",
+			input:  `{"zeta":"x: y","alpha":[1,2.5,true,null],"nested":{"empty":[],"text":"line"}}`,
+			want:   "zeta: 'x: y'\nalpha:\n  - 1\n  - 2.5\n  - true\n  - null\nnested:\n  empty: []\n  text: line",
+		},
+		{
+			name:   "table of a list",
+			format: "table",
+			input:  stacks,
+			want: "id  name                                      status  env      labels   extra\n" +
+				"1   web                                       active  A=1,B=2  1 field  -\n" +
+				"12  monitoring-with-a-rather-long-stack-name  -       -        -        true",
+		},
+		{
+			name:   "table of an object",
+			format: "table",
+			input:  report,
+			want:   "since    2025-06-01T10:00:00Z\nsamples  3\nnotes    0 items\n\nenvironments:\nid  name\n4   edge\n2   prod",
+		},
+		{name: "table of an empty list", format: "TABLE", input: `[]`, want: "(no items)"},
+		{name: "table cuts long cells", format: "table", input: `{"message":"` + "0123456789012345678901234567890123456789abc" + `"}`, want: "message  012345678901234567890123456789012345678…"},
+		{
+			name:   "summary of a list",
+			format: "summary",
+			input:  stacks,
+			want:   "2 items\nfields: id, name, status, env, labels\n- web (1)\n- monitoring-with-a-rather-long-stack-name (12)",
+		},
+		{
+			name:   "summary of an object",
+			format: "summary",
+			input:  report,
+			want:   "since: 2025-06-01T10:00:00Z\nsamples: 3\nnotes: 0 items\nenvironments: 2 items (edge (4), prod (2))",
+		},
+		{name: "summary of a scalar", format: "summary", input: `"done"`, want: "done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := Get(tt.format)
+			require.NoError(t, err)
+			got, err := formatter.Format([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestSummaryTruncatesLists verifies that the summary of a long list names its first items.
+func TestSummaryTruncatesLists(t *testing.T) {
+	got, err := summaryFormatter{}.Format([]byte(`["a","b","c","d","e","f","g","h","i","j","k","l"]`))
+	require.NoError(t, err)
+	assert.Equal(t, "12 items\n- a\n- b\n- c\n- d\n- e\n- f\n- g\n- h\n- i\n- j\n- … and 2 more items", got)
+}
+
+// TestGet verifies the lookup of the output formats.
+func TestGet(t *testing.T) {
+	for _, name := range Names() {
+		_, err := Get(name)
+		assert.NoError(t, err, name)
+	}
+	_, err := Get("xml")
+	assert.ErrorContains(t, err, `unknown output format "xml" (expected one of: json, yaml, table, summary)`)
+}
+
+// TestFormatInvalidPayload verifies that a payload that is not JSON is rejected.
+func TestFormatInvalidPayload(t *testing.T) {
+	for _, name := range []string{YAML, Table, Summary} {
+		formatter, err := Get(name)
+		require.NoError(t, err)
+		_, err = formatter.Format([]byte(`{"a":`))
+		assert.Error(t, err, name)
+		_, err = formatter.Format([]byte(``))
+		assert.Error(t, err, name)
+	}
+}
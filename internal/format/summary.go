@@ -0,0 +1,126 @@
+package format
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// maxSummaryItems is the number of items named by the summary of a list.
+	maxSummaryItems = 10
+	// maxInlineItems is the number of items named by the summary of a nested list.
+	maxInlineItems = 5
+	// maxSummaryValueWidth is the number of characters above which a value is cut.
+	maxSummaryValueWidth = 80
+)
+
+// Fields naming the items of a list in a summary, by order of preference
+var (
+	labelNameFields = []string{"name", "Name", "title", "Title", "username", "Username"}
+	labelIDFields   = []string{"id", "Id", "ID"}
+)
+
+// summaryFormatter renders an overview of the payload: the size of the lists and objects
+// and the names of their items, but not the items themselves. The scalar fields of an
+// object are kept.
+type summaryFormatter struct{}
+
+func (summaryFormatter) Format(data []byte) (string, error) {
+	root, err := parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	switch root.Kind {
+	case yaml.SequenceNode:
+		lines = append(lines, countText(root))
+		if isRecordList(root) {
+			keys, _ := fields(root.Content[0])
+			lines = append(lines, "fields: "+strings.Join(keys, ", "))
+		}
+		labels := itemLabels(root, maxSummaryItems)
+		for _, label := range labels {
+			lines = append(lines, "- "+label)
+		}
+		if more := len(root.Content) - len(labels); len(labels) > 0 && more > 0 {
+			lines = append(lines, "- … and "+plural(more, "more item"))
+		}
+	case yaml.MappingNode:
+		keys, values := fields(root)
+		for i, key := range keys {
+			lines = append(lines, key+": "+valueSummary(values[i]))
+		}
+	default:
+		lines = append(lines, scalarText(root))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (summaryFormatter) MIMEType() string {
+	return MIMETypePlainText
+}
+
+// valueSummary summarizes the value of an object field on one line.
+func valueSummary(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		summary := countText(node)
+		if labels := itemLabels(node, maxInlineItems); len(labels) > 0 {
+			if len(node.Content) > len(labels) {
+				labels = append(labels, "…")
+			}
+			summary += " (" + strings.Join(labels, ", ") + ")"
+		}
+		return summary
+	case yaml.MappingNode:
+		return countText(node)
+	default:
+		return truncate(strings.Join(strings.Fields(scalarText(node)), " "), maxSummaryValueWidth)
+	}
+}
+
+// itemLabels returns the labels of up to limit items of a list: the scalars themselves,
+// or the name and ID of the objects. It returns nothing when an item has no label.
+func itemLabels(node *yaml.Node, limit int) []string {
+	var labels []string
+	for _, item := range node.Content[:min(limit, len(node.Content))] {
+		label := itemLabel(item)
+		if label == "" {
+			return nil
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// itemLabel returns the label of a list item, or "" when it has none.
+func itemLabel(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return truncate(scalarText(node), maxCellWidth)
+	case yaml.MappingNode:
+		name := firstScalar(node, labelNameFields)
+		id := firstScalar(node, labelIDFields)
+		switch {
+		case name != "" && id != "":
+			return truncate(name, maxCellWidth) + " (" + id + ")"
+		case name != "":
+			return truncate(name, maxCellWidth)
+		default:
+			return id
+		}
+	}
+	return ""
+}
+
+// firstScalar returns the value of the first of the keys holding a non-empty scalar.
+func firstScalar(node *yaml.Node, keys []string) string {
+	for _, key := range keys {
+		if value := field(node, key); value != nil && value.Kind == yaml.ScalarNode && value.Tag != "!!null" && value.Value != "" {
+			return value.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package format
+
+import (
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxCellWidth is the number of characters above which a table cell is cut.
+const maxCellWidth = 40
+
+// tableFormatter renders the payload as aligned text columns. A list of objects becomes
+// one row per object, with a column per field. An object becomes one row per field,
+// followed by a table for each of its lists of objects. Nested values are reduced to their
+// size, or to their items when they are lists of scalars.
+type tableFormatter struct{}
+
+func (tableFormatter) Format(data []byte) (string, error) {
+	root, err := parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	writeTable(&buf, root)
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (tableFormatter) MIMEType() string {
+	return MIMETypePlainText
+}
+
+// writeTable writes the table of a node.
+func writeTable(buf *strings.Builder, node *yaml.Node) {
+	switch {
+	case isRecordList(node):
+		writeRecords(buf, node.Content)
+	case node.Kind == yaml.SequenceNode && len(node.Content) == 0:
+		buf.WriteString("(no items)\n")
+	case node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			buf.WriteString(cellText(item) + "\n")
+		}
+	case node.Kind == yaml.MappingNode:
+		keys, values := fields(node)
+		w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+		for i, key := range keys {
+			if !isRecordList(values[i]) {
+				w.Write([]byte(key + "\t" + cellText(values[i]) + "\n"))
+			}
+		}
+		w.Flush()
+		for i, key := range keys {
+			if isRecordList(values[i]) {
+				buf.WriteString("\n" + key + ":\n")
+				writeRecords(buf, values[i].Content)
+			}
+		}
+	default:
+		buf.WriteString(scalarText(node) + "\n")
+	}
+}
+
+// writeRecords writes a list of objects with a column for every field found in any of
+// them, in the order they first appear.
+func writeRecords(buf *strings.Builder, records []*yaml.Node) {
+	var columns []string
+	seen := map[string]bool{}
+	for _, record := range records {
+		keys, _ := fields(record)
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	w.Write([]byte(strings.Join(columns, "\t") + "\n"))
+	for _, record := range records {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = "-"
+			if value := field(record, column); value != nil {
+				cells[i] = cellText(value)
+			}
+		}
+		w.Write([]byte(strings.Join(cells, "\t") + "\n"))
+	}
+	w.Flush()
+}
+
+// cellText returns the text of a value in a single table cell.
+func cellText(node *yaml.Node) string {
+	text := countText(node)
+	if node.Kind == yaml.SequenceNode && len(node.Content) > 0 && !containsCollections(node) {
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			items[i] = scalarText(item)
+		}
+		text = strings.Join(items, ",")
+	}
+	return truncate(strings.Join(strings.Fields(text), " "), maxCellWidth)
+}
+
+// containsCollections reports whether a list holds lists or objects.
+func containsCollections(node *yaml.Node) bool {
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate cuts a text to width characters, ending it with an ellipsis when cut.
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
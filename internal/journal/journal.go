@@ -0,0 +1,169 @@
+// Package journal keeps a bounded local record of the resources deleted through the
+// MCP server. Before a delete, the server captures the resource's JSON (and its file
+// for stacks and templates) together with an undo recipe, the tool call that recreates
+// it, so that an accidental deletion can be reconstructed. The journal lives in memory
+// and can optionally be persisted as a JSON file in a directory.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/atomicfile"
+)
+
+// Kinds of resources recorded in the journal.
+const (
+	KindStack          = "stack"
+	KindTag            = "tag"
+	KindWebhook        = "webhook"
+	KindCustomTemplate = "customTemplate"
+	KindEdgeJob        = "edgeJob"
+)
+
+// fileName is the name of the journal file in the journal directory.
+const fileName = "delete-journal.json"
+
+// now returns the current time. It is a variable so tests can pin the clock.
+var now = time.Now
+
+// Undo is the tool call that recreates a deleted resource.
+type Undo struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	// Notes lists what the tool call cannot restore, such as IDs or secrets.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Entry is one deleted resource.
+type Entry struct {
+	ID         int             `json:"id"`
+	DeletedAt  time.Time       `json:"deleted_at"`
+	Tool       string          `json:"tool"`
+	Kind       string          `json:"kind"`
+	ResourceID int             `json:"resource_id"`
+	Name       string          `json:"name,omitempty"`
+	Resource   json.RawMessage `json:"resource,omitempty"`
+	Undo       Undo            `json:"undo"`
+}
+
+// Journal is a concurrency-safe list of deleted resources. It keeps at most limit
+// entries; the oldest entries are dropped first.
+type Journal struct {
+	mu      sync.Mutex
+	path    string
+	limit   int
+	entries []Entry
+}
+
+// New creates a Journal keeping up to limit entries. When dir is not empty, the journal
+// is loaded from and persisted to that directory, which is created if needed.
+func New(dir string, limit int) (*Journal, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("delete journal limit must be at least 1, got %d", limit)
+	}
+
+	j := &Journal{limit: limit}
+	if dir == "" {
+		return j, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create delete journal directory: %w", err)
+	}
+	j.path = filepath.Join(dir, fileName)
+
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delete journal: %w", err)
+	}
+	if err := json.Unmarshal(data, &j.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse delete journal %s: %w", j.path, err)
+	}
+	j.trim()
+	return j, nil
+}
+
+// Record adds a deleted resource to the journal. The ID and deletion time of the entry
+// are assigned by the journal. It returns the recorded entry.
+func (j *Journal) Record(e Entry) (Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e.ID = 1
+	if n := len(j.entries); n > 0 {
+		e.ID = j.entries[n-1].ID + 1
+	}
+	e.DeletedAt = now().UTC()
+
+	entries := append(j.entries, e)
+	if err := j.save(entries); err != nil {
+		return Entry{}, err
+	}
+	j.entries = entries
+	j.trim()
+	return e, nil
+}
+
+// List returns the entries of the journal, oldest first, without the captured resources.
+func (j *Journal) List() []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	list := make([]Entry, len(j.entries))
+	for i, e := range j.entries {
+		e.Resource = nil
+		list[i] = e
+	}
+	return list
+}
+
+// Get returns a single entry, including the captured resource.
+// The boolean is false when the entry is unknown or no longer retained.
+func (j *Journal) Get(id int) (Entry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, e := range j.entries {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// trim drops the oldest entries beyond the limit. The caller must hold j.mu.
+func (j *Journal) trim() {
+	if len(j.entries) > j.limit {
+		j.entries = j.entries[len(j.entries)-j.limit:]
+	}
+}
+
+// save persists the entries when a directory is configured, keeping only the newest
+// limit entries and replacing the previous file atomically. The caller must hold j.mu.
+func (j *Journal) save(entries []Entry) error {
+	if j.path == "" {
+		return nil
+	}
+	if len(entries) > j.limit {
+		entries = entries[len(entries)-j.limit:]
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal delete journal: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(j.path, data); err != nil {
+		return fmt.Errorf("failed to write delete journal: %w", err)
+	}
+	return nil
+}
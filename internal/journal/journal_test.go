@@ -0,0 +1,89 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew verifies journal creation, limit validation and corrupt files.
+func TestNew(t *testing.T) {
+	_, err := New("", 0)
+	assert.Error(t, err)
+
+	dir := filepath.Join(t.TempDir(), "nested", "journal")
+	j, err := New(dir, 5)
+	require.NoError(t, err)
+	assert.NotNil(t, j)
+	assert.DirExists(t, dir)
+
+	corrupt := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(corrupt, fileName), []byte("not json"), 0o600))
+	_, err = New(corrupt, 5)
+	assert.Error(t, err)
+}
+
+// TestRecord verifies entry numbering, retention and that List omits the resources.
+func TestRecord(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	original := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = original })
+
+	j, err := New("", 2)
+	require.NoError(t, err)
+
+	for _, name := range []string{"prod", "staging", "dev"} {
+		_, err := j.Record(Entry{
+			Tool:     "deleteEnvironmentTag",
+			Kind:     KindTag,
+			Name:     name,
+			Resource: json.RawMessage(`{"name":"` + name + `"}`),
+			Undo:     Undo{Tool: "createEnvironmentTag", Arguments: map[string]any{"name": name}},
+		})
+		require.NoError(t, err)
+	}
+
+	entries := j.List()
+	require.Len(t, entries, 2, "oldest entries beyond the limit are dropped")
+	assert.Equal(t, 2, entries[0].ID)
+	assert.Equal(t, 3, entries[1].ID)
+	assert.Equal(t, fixed, entries[1].DeletedAt)
+	assert.Nil(t, entries[1].Resource, "List must not include resources")
+
+	_, found := j.Get(1)
+	assert.False(t, found)
+
+	got, found := j.Get(3)
+	require.True(t, found)
+	assert.Equal(t, "dev", got.Name)
+	assert.JSONEq(t, `{"name":"dev"}`, string(got.Resource))
+	assert.Equal(t, "createEnvironmentTag", got.Undo.Tool)
+}
+
+// TestPersistence verifies that the journal survives a new journal on the same directory.
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := New(dir, 10)
+	require.NoError(t, err)
+	_, err = j.Record(Entry{Tool: "deleteWebhook", Kind: KindWebhook, ResourceID: 4, Undo: Undo{Tool: "createWebhook"}})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, fileName))
+
+	reopened, err := New(dir, 10)
+	require.NoError(t, err)
+	got, found := reopened.Get(1)
+	require.True(t, found)
+	assert.Equal(t, KindWebhook, got.Kind)
+	assert.Equal(t, 4, got.ResourceID)
+
+	next, err := reopened.Record(Entry{Tool: "deleteWebhook", Kind: KindWebhook, ResourceID: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.ID)
+}
@@ -0,0 +1,738 @@
+package k8sutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// lastAppliedAnnotation is the annotation in which kubectl stores the last applied
+// manifest. It duplicates the resource and is left out of descriptions.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// ResourceKind is a Kubernetes resource kind that can be described.
+type ResourceKind struct {
+	// Kind is the kind of the resource, e.g. "Deployment".
+	Kind string
+	// Resource is the plural resource name used in API paths, e.g. "deployments".
+	Resource string
+	// GroupVersion is the API prefix of the resource, e.g. "/apis/apps/v1".
+	GroupVersion string
+	// Namespaced reports whether the resource lives in a namespace.
+	Namespaced bool
+	// aliases are the other accepted names, such as kubectl short names.
+	aliases []string
+}
+
+// describableKinds are the resource kinds supported by Describe.
+var describableKinds = []ResourceKind{
+	{Kind: "Pod", Resource: "pods", GroupVersion: "/api/v1", Namespaced: true, aliases: []string{"po"}},
+	{Kind: "Service", Resource: "services", GroupVersion: "/api/v1", Namespaced: true, aliases: []string{"svc"}},
+	{Kind: "ConfigMap", Resource: "configmaps", GroupVersion: "/api/v1", Namespaced: true, aliases: []string{"cm"}},
+	{Kind: "Secret", Resource: "secrets", GroupVersion: "/api/v1", Namespaced: true},
+	{Kind: "ServiceAccount", Resource: "serviceaccounts", GroupVersion: "/api/v1", Namespaced: true, aliases: []string{"sa"}},
+	{Kind: "PersistentVolumeClaim", Resource: "persistentvolumeclaims", GroupVersion: "/api/v1", Namespaced: true, aliases: []string{"pvc"}},
+	{Kind: "PersistentVolume", Resource: "persistentvolumes", GroupVersion: "/api/v1", aliases: []string{"pv"}},
+	{Kind: "Node", Resource: "nodes", GroupVersion: "/api/v1", aliases: []string{"no"}},
+	{Kind: "Namespace", Resource: "namespaces", GroupVersion: "/api/v1", aliases: []string{"ns"}},
+	{Kind: "Deployment", Resource: "deployments", GroupVersion: "/apis/apps/v1", Namespaced: true, aliases: []string{"deploy"}},
+	{Kind: "StatefulSet", Resource: "statefulsets", GroupVersion: "/apis/apps/v1", Namespaced: true, aliases: []string{"sts"}},
+	{Kind: "DaemonSet", Resource: "daemonsets", GroupVersion: "/apis/apps/v1", Namespaced: true, aliases: []string{"ds"}},
+	{Kind: "ReplicaSet", Resource: "replicasets", GroupVersion: "/apis/apps/v1", Namespaced: true, aliases: []string{"rs"}},
+	{Kind: "Job", Resource: "jobs", GroupVersion: "/apis/batch/v1", Namespaced: true},
+	{Kind: "CronJob", Resource: "cronjobs", GroupVersion: "/apis/batch/v1", Namespaced: true, aliases: []string{"cj"}},
+	{Kind: "Ingress", Resource: "ingresses", GroupVersion: "/apis/networking.k8s.io/v1", Namespaced: true, aliases: []string{"ing"}},
+	{Kind: "HorizontalPodAutoscaler", Resource: "horizontalpodautoscalers", GroupVersion: "/apis/autoscaling/v2", Namespaced: true, aliases: []string{"hpa"}},
+}
+
+// LookupKind resolves a kind from its name, its plural resource name or its kubectl
+// short name, case insensitively.
+func LookupKind(name string) (ResourceKind, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, kind := range describableKinds {
+		if name == strings.ToLower(kind.Kind) || name == kind.Resource {
+			return kind, true
+		}
+		for _, alias := range kind.aliases {
+			if name == alias {
+				return kind, true
+			}
+		}
+	}
+	return ResourceKind{}, false
+}
+
+// DescribableKinds returns the kinds supported by Describe.
+func DescribableKinds() []string {
+	kinds := make([]string, len(describableKinds))
+	for i, kind := range describableKinds {
+		kinds[i] = kind.Kind
+	}
+	return kinds
+}
+
+// Path returns the API path of the named resource. The namespace is ignored for
+// cluster-scoped kinds.
+func (k ResourceKind) Path(namespace, name string) string {
+	if k.Namespaced {
+		return fmt.Sprintf("%s/namespaces/%s/%s/%s", k.GroupVersion, url.PathEscape(namespace), k.Resource, url.PathEscape(name))
+	}
+	return fmt.Sprintf("%s/%s/%s", k.GroupVersion, k.Resource, url.PathEscape(name))
+}
+
+// EventsPath returns the API path listing the events that can refer to a resource of
+// this kind. Events about cluster-scoped resources may be recorded in any namespace.
+func (k ResourceKind) EventsPath(namespace string) string {
+	if k.Namespaced {
+		return fmt.Sprintf("/api/v1/namespaces/%s/events", url.PathEscape(namespace))
+	}
+	return "/api/v1/events"
+}
+
+// EventsFieldSelector returns the field selector matching the events of a resource. The
+// UID is used when known so that events of a deleted resource with the same name are
+// not reported.
+func (k ResourceKind) EventsFieldSelector(name, uid string) string {
+	if uid != "" {
+		return "involvedObject.uid=" + uid
+	}
+	return fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", k.Kind, name)
+}
+
+// Description is a structured, kubectl describe-like summary of a resource.
+type Description struct {
+	Kind        string            `json:"kind"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Created     string            `json:"created,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Owners      []string          `json:"owners,omitempty"`
+	Details     map[string]any    `json:"details,omitempty"`
+	Conditions  []Condition       `json:"conditions,omitempty"`
+	Events      []Event           `json:"events"`
+}
+
+// Condition is a status condition of a resource.
+type Condition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+}
+
+// Event is a Kubernetes event about a resource.
+type Event struct {
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Count     int    `json:"count"`
+	FirstSeen string `json:"firstSeen,omitempty"`
+	LastSeen  string `json:"lastSeen,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+// Describe builds the description of a resource from its object and the events that
+// refer to it. Secret and ConfigMap values are never included, only their keys and sizes.
+// Events are sorted from the oldest to the most recent, like kubectl describe.
+func Describe(kind ResourceKind, object map[string]any, events []map[string]any) Description {
+	desc := Description{
+		Kind:      kind.Kind,
+		Name:      str(object, "metadata", "name"),
+		Namespace: str(object, "metadata", "namespace"),
+		Created:   str(object, "metadata", "creationTimestamp"),
+		Labels:    stringMap(object, "metadata", "labels"),
+		Details:   map[string]any{},
+		Events:    []Event{},
+	}
+
+	if annotations := stringMap(object, "metadata", "annotations"); len(annotations) > 0 {
+		delete(annotations, lastAppliedAnnotation)
+		if len(annotations) > 0 {
+			desc.Annotations = annotations
+		}
+	}
+
+	for _, owner := range slice(object, "metadata", "ownerReferences") {
+		if m, ok := owner.(map[string]any); ok {
+			desc.Owners = append(desc.Owners, str(m, "kind")+"/"+str(m, "name"))
+		}
+	}
+
+	for _, condition := range slice(object, "status", "conditions") {
+		if m, ok := condition.(map[string]any); ok {
+			desc.Conditions = append(desc.Conditions, Condition{
+				Type:               str(m, "type"),
+				Status:             str(m, "status"),
+				Reason:             str(m, "reason"),
+				Message:            str(m, "message"),
+				LastTransitionTime: str(m, "lastTransitionTime"),
+			})
+		}
+	}
+
+	if describe, ok := kindDetails[kind.Kind]; ok {
+		describe(object, desc.Details)
+	}
+	prune(desc.Details)
+	if len(desc.Details) == 0 {
+		desc.Details = nil
+	}
+
+	for _, event := range events {
+		desc.Events = append(desc.Events, convertEvent(event))
+	}
+	sort.SliceStable(desc.Events, func(i, j int) bool {
+		return desc.Events[i].LastSeen < desc.Events[j].LastSeen
+	})
+
+	return desc
+}
+
+// convertEvent extracts the fields of an event. Events created through the events.k8s.io
+// API only set the newer series and eventTime fields, which are used as fallbacks.
+func convertEvent(event map[string]any) Event {
+	e := Event{
+		Type:      str(event, "type"),
+		Reason:    str(event, "reason"),
+		Message:   str(event, "message"),
+		Count:     integer(event, "count"),
+		FirstSeen: str(event, "firstTimestamp"),
+		LastSeen:  str(event, "lastTimestamp"),
+		Source:    str(event, "source", "component"),
+	}
+	if e.Count == 0 {
+		e.Count = integer(event, "series", "count")
+	}
+	if e.Count == 0 {
+		e.Count = 1
+	}
+	if e.FirstSeen == "" {
+		e.FirstSeen = str(event, "eventTime")
+	}
+	if e.LastSeen == "" {
+		e.LastSeen = str(event, "series", "lastObservedTime")
+	}
+	if e.LastSeen == "" {
+		e.LastSeen = e.FirstSeen
+	}
+	if e.Source == "" {
+		e.Source = str(event, "reportingComponent")
+	}
+	return e
+}
+
+// kindDetails adds the kind-specific fields of a resource to its details.
+var kindDetails = map[string]func(object, details map[string]any){
+	"Pod":                     describePod,
+	"Deployment":              describeDeployment,
+	"StatefulSet":             describeStatefulSet,
+	"DaemonSet":               describeDaemonSet,
+	"ReplicaSet":              describeReplicaSet,
+	"Job":                     describeJob,
+	"CronJob":                 describeCronJob,
+	"Service":                 describeService,
+	"Ingress":                 describeIngress,
+	"ConfigMap":               describeConfigMap,
+	"Secret":                  describeSecret,
+	"ServiceAccount":          describeServiceAccount,
+	"PersistentVolumeClaim":   describePersistentVolumeClaim,
+	"PersistentVolume":        describePersistentVolume,
+	"Node":                    describeNode,
+	"Namespace":               describeNamespace,
+	"HorizontalPodAutoscaler": describeHorizontalPodAutoscaler,
+}
+
+func describePod(object, details map[string]any) {
+	details["status"] = str(object, "status", "phase")
+	details["reason"] = str(object, "status", "reason")
+	details["message"] = str(object, "status", "message")
+	details["node"] = str(object, "spec", "nodeName")
+	details["ip"] = str(object, "status", "podIP")
+	details["startTime"] = str(object, "status", "startTime")
+	details["qosClass"] = str(object, "status", "qosClass")
+	details["serviceAccount"] = str(object, "spec", "serviceAccountName")
+
+	statuses := map[string]map[string]any{}
+	for _, key := range []string{"initContainerStatuses", "containerStatuses"} {
+		for _, status := range slice(object, "status", key) {
+			if m, ok := status.(map[string]any); ok {
+				statuses[str(m, "name")] = m
+			}
+		}
+	}
+	details["initContainers"] = podContainers(slice(object, "spec", "initContainers"), statuses)
+	details["containers"] = podContainers(slice(object, "spec", "containers"), statuses)
+}
+
+// podContainers describes the containers of a pod with their runtime status.
+func podContainers(containers []any, statuses map[string]map[string]any) []map[string]any {
+	var result []map[string]any
+	for _, container := range containers {
+		spec, ok := container.(map[string]any)
+		if !ok {
+			continue
+		}
+		c := describeContainer(spec)
+		if status, ok := statuses[str(spec, "name")]; ok {
+			c["ready"] = boolean(status, "ready")
+			c["restartCount"] = integer(status, "restartCount")
+			c["state"] = containerState(mapValue(status, "state"))
+			c["lastState"] = containerState(mapValue(status, "lastState"))
+		}
+		prune(c)
+		result = append(result, c)
+	}
+	return result
+}
+
+// describeContainer describes the specification of a container.
+func describeContainer(spec map[string]any) map[string]any {
+	c := map[string]any{
+		"name":     str(spec, "name"),
+		"image":    str(spec, "image"),
+		"requests": stringMap(spec, "resources", "requests"),
+		"limits":   stringMap(spec, "resources", "limits"),
+	}
+
+	var ports []string
+	for _, port := range slice(spec, "ports") {
+		if m, ok := port.(map[string]any); ok {
+			ports = append(ports, fmt.Sprintf("%d/%s", integer(m, "containerPort"), orDefault(str(m, "protocol"), "TCP")))
+		}
+	}
+	c["ports"] = ports
+	return c
+}
+
+// containerState renders a container state like kubectl describe, e.g.
+// "Waiting: CrashLoopBackOff" or "Terminated: Error (exit code 1)".
+func containerState(state map[string]any) string {
+	if m := mapValue(state, "running"); m != nil {
+		if startedAt := str(m, "startedAt"); startedAt != "" {
+			return "Running since " + startedAt
+		}
+		return "Running"
+	}
+	if m := mapValue(state, "waiting"); m != nil {
+		return joinState("Waiting", str(m, "reason"), str(m, "message"))
+	}
+	if m := mapValue(state, "terminated"); m != nil {
+		return joinState("Terminated", fmt.Sprintf("%s (exit code %d)", orDefault(str(m, "reason"), "Unknown"), integer(m, "exitCode")), str(m, "message"))
+	}
+	return ""
+}
+
+// joinState joins a state with its reason and message.
+func joinState(state, reason, message string) string {
+	for _, part := range []string{reason, message} {
+		if part != "" {
+			state += ": " + part
+		}
+	}
+	return state
+}
+
+// describeTemplate adds the selector and the containers of a pod template.
+func describeTemplate(object, details map[string]any) {
+	details["selector"] = stringMap(object, "spec", "selector", "matchLabels")
+	details["containers"] = templateContainers(slice(object, "spec", "template", "spec", "containers"))
+}
+
+// templateContainers describes the containers of a pod template.
+func templateContainers(containers []any) []map[string]any {
+	var result []map[string]any
+	for _, container := range containers {
+		if spec, ok := container.(map[string]any); ok {
+			c := describeContainer(spec)
+			prune(c)
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func describeDeployment(object, details map[string]any) {
+	details["replicas"] = map[string]any{
+		"desired":     replicas(object),
+		"updated":     integer(object, "status", "updatedReplicas"),
+		"ready":       integer(object, "status", "readyReplicas"),
+		"available":   integer(object, "status", "availableReplicas"),
+		"unavailable": integer(object, "status", "unavailableReplicas"),
+	}
+	details["strategy"] = str(object, "spec", "strategy", "type")
+	details["paused"] = boolean(object, "spec", "paused")
+	describeTemplate(object, details)
+}
+
+func describeStatefulSet(object, details map[string]any) {
+	details["replicas"] = map[string]any{
+		"desired": replicas(object),
+		"current": integer(object, "status", "currentReplicas"),
+		"updated": integer(object, "status", "updatedReplicas"),
+		"ready":   integer(object, "status", "readyReplicas"),
+	}
+	details["serviceName"] = str(object, "spec", "serviceName")
+	details["updateStrategy"] = str(object, "spec", "updateStrategy", "type")
+	describeTemplate(object, details)
+}
+
+func describeDaemonSet(object, details map[string]any) {
+	details["pods"] = map[string]any{
+		"desired":      integer(object, "status", "desiredNumberScheduled"),
+		"current":      integer(object, "status", "currentNumberScheduled"),
+		"updated":      integer(object, "status", "updatedNumberScheduled"),
+		"ready":        integer(object, "status", "numberReady"),
+		"available":    integer(object, "status", "numberAvailable"),
+		"misscheduled": integer(object, "status", "numberMisscheduled"),
+	}
+	details["updateStrategy"] = str(object, "spec", "updateStrategy", "type")
+	describeTemplate(object, details)
+}
+
+func describeReplicaSet(object, details map[string]any) {
+	details["replicas"] = map[string]any{
+		"desired":   replicas(object),
+		"current":   integer(object, "status", "replicas"),
+		"ready":     integer(object, "status", "readyReplicas"),
+		"available": integer(object, "status", "availableReplicas"),
+	}
+	describeTemplate(object, details)
+}
+
+func describeJob(object, details map[string]any) {
+	details["completions"] = integer(object, "spec", "completions")
+	details["parallelism"] = integer(object, "spec", "parallelism")
+	details["active"] = integer(object, "status", "active")
+	details["succeeded"] = integer(object, "status", "succeeded")
+	details["failed"] = integer(object, "status", "failed")
+	details["startTime"] = str(object, "status", "startTime")
+	details["completionTime"] = str(object, "status", "completionTime")
+	describeTemplate(object, details)
+}
+
+func describeCronJob(object, details map[string]any) {
+	details["schedule"] = str(object, "spec", "schedule")
+	details["suspend"] = boolean(object, "spec", "suspend")
+	details["lastScheduleTime"] = str(object, "status", "lastScheduleTime")
+	details["lastSuccessfulTime"] = str(object, "status", "lastSuccessfulTime")
+	details["activeJobs"] = len(slice(object, "status", "active"))
+	details["containers"] = templateContainers(slice(object, "spec", "jobTemplate", "spec", "template", "spec", "containers"))
+}
+
+func describeService(object, details map[string]any) {
+	details["type"] = str(object, "spec", "type")
+	details["clusterIP"] = str(object, "spec", "clusterIP")
+	details["externalIPs"] = stringSlice(object, "spec", "externalIPs")
+	details["externalName"] = str(object, "spec", "externalName")
+	details["selector"] = stringMap(object, "spec", "selector")
+	details["sessionAffinity"] = str(object, "spec", "sessionAffinity")
+
+	var ports []string
+	for _, port := range slice(object, "spec", "ports") {
+		m, ok := port.(map[string]any)
+		if !ok {
+			continue
+		}
+		p := fmt.Sprintf("%d/%s", integer(m, "port"), orDefault(str(m, "protocol"), "TCP"))
+		if target := scalar(m, "targetPort"); target != "" {
+			p += " -> " + target
+		}
+		if nodePort := integer(m, "nodePort"); nodePort != 0 {
+			p += fmt.Sprintf(" (nodePort %d)", nodePort)
+		}
+		if name := str(m, "name"); name != "" {
+			p = name + " " + p
+		}
+		ports = append(ports, p)
+	}
+	details["ports"] = ports
+	details["loadBalancerIngress"] = loadBalancerIngress(object)
+}
+
+func describeIngress(object, details map[string]any) {
+	details["ingressClass"] = str(object, "spec", "ingressClassName")
+
+	var rules []string
+	for _, rule := range slice(object, "spec", "rules") {
+		r, ok := rule.(map[string]any)
+		if !ok {
+			continue
+		}
+		host := orDefault(str(r, "host"), "*")
+		for _, path := range slice(r, "http", "paths") {
+			p, ok := path.(map[string]any)
+			if !ok {
+				continue
+			}
+			backend := str(p, "backend", "service", "name")
+			if port := orDefault(scalar(mapValue(p, "backend", "service", "port"), "number"), str(p, "backend", "service", "port", "name")); port != "" {
+				backend += ":" + port
+			}
+			rules = append(rules, fmt.Sprintf("%s%s -> %s", host, orDefault(str(p, "path"), "/"), backend))
+		}
+	}
+	details["rules"] = rules
+
+	var tlsHosts []string
+	for _, tls := range slice(object, "spec", "tls") {
+		if m, ok := tls.(map[string]any); ok {
+			tlsHosts = append(tlsHosts, stringSlice(m, "hosts")...)
+		}
+	}
+	details["tlsHosts"] = tlsHosts
+	details["loadBalancerIngress"] = loadBalancerIngress(object)
+}
+
+// loadBalancerIngress returns the IPs or host names assigned by a load balancer.
+func loadBalancerIngress(object map[string]any) []string {
+	var addresses []string
+	for _, ingress := range slice(object, "status", "loadBalancer", "ingress") {
+		if m, ok := ingress.(map[string]any); ok {
+			addresses = append(addresses, orDefault(str(m, "ip"), str(m, "hostname")))
+		}
+	}
+	return addresses
+}
+
+func describeConfigMap(object, details map[string]any) {
+	details["data"] = keySizes(mapValue(object, "data"), false)
+	details["binaryData"] = keySizes(mapValue(object, "binaryData"), true)
+}
+
+func describeSecret(object, details map[string]any) {
+	details["type"] = str(object, "type")
+	details["data"] = keySizes(mapValue(object, "data"), true)
+}
+
+// keySizes maps the keys of a data map to the size of their value in bytes, without the
+// values. Base64 encoded values are measured decoded.
+func keySizes(data map[string]any, base64Encoded bool) map[string]string {
+	if len(data) == 0 {
+		return nil
+	}
+	sizes := make(map[string]string, len(data))
+	for key, value := range data {
+		s, _ := value.(string)
+		size := len(s)
+		if base64Encoded {
+			if decoded, err := base64.StdEncoding.DecodeString(s); err == nil {
+				size = len(decoded)
+			}
+		}
+		sizes[key] = fmt.Sprintf("%d bytes", size)
+	}
+	return sizes
+}
+
+func describeServiceAccount(object, details map[string]any) {
+	details["secrets"] = namedReferences(slice(object, "secrets"))
+	details["imagePullSecrets"] = namedReferences(slice(object, "imagePullSecrets"))
+}
+
+// namedReferences returns the names of a list of object references.
+func namedReferences(references []any) []string {
+	var names []string
+	for _, reference := range references {
+		if m, ok := reference.(map[string]any); ok {
+			names = append(names, str(m, "name"))
+		}
+	}
+	return names
+}
+
+func describePersistentVolumeClaim(object, details map[string]any) {
+	details["status"] = str(object, "status", "phase")
+	details["volume"] = str(object, "spec", "volumeName")
+	details["storageClass"] = str(object, "spec", "storageClassName")
+	details["requested"] = str(object, "spec", "resources", "requests", "storage")
+	details["capacity"] = str(object, "status", "capacity", "storage")
+	details["accessModes"] = stringSlice(object, "spec", "accessModes")
+	details["volumeMode"] = str(object, "spec", "volumeMode")
+}
+
+func describePersistentVolume(object, details map[string]any) {
+	details["status"] = str(object, "status", "phase")
+	details["reason"] = str(object, "status", "reason")
+	details["capacity"] = str(object, "spec", "capacity", "storage")
+	details["accessModes"] = stringSlice(object, "spec", "accessModes")
+	details["reclaimPolicy"] = str(object, "spec", "persistentVolumeReclaimPolicy")
+	details["storageClass"] = str(object, "spec", "storageClassName")
+	if claim := str(object, "spec", "claimRef", "name"); claim != "" {
+		details["claim"] = str(object, "spec", "claimRef", "namespace") + "/" + claim
+	}
+}
+
+func describeNode(object, details map[string]any) {
+	details["unschedulable"] = boolean(object, "spec", "unschedulable")
+	details["podCIDR"] = str(object, "spec", "podCIDR")
+	details["capacity"] = stringMap(object, "status", "capacity")
+	details["allocatable"] = stringMap(object, "status", "allocatable")
+	details["kubeletVersion"] = str(object, "status", "nodeInfo", "kubeletVersion")
+	details["containerRuntime"] = str(object, "status", "nodeInfo", "containerRuntimeVersion")
+	details["osImage"] = str(object, "status", "nodeInfo", "osImage")
+	details["architecture"] = str(object, "status", "nodeInfo", "architecture")
+
+	var taints []string
+	for _, taint := range slice(object, "spec", "taints") {
+		if m, ok := taint.(map[string]any); ok {
+			t := str(m, "key")
+			if value := str(m, "value"); value != "" {
+				t += "=" + value
+			}
+			taints = append(taints, t+":"+str(m, "effect"))
+		}
+	}
+	details["taints"] = taints
+
+	var addresses []string
+	for _, address := range slice(object, "status", "addresses") {
+		if m, ok := address.(map[string]any); ok {
+			addresses = append(addresses, str(m, "type")+": "+str(m, "address"))
+		}
+	}
+	details["addresses"] = addresses
+}
+
+func describeNamespace(object, details map[string]any) {
+	details["status"] = str(object, "status", "phase")
+}
+
+func describeHorizontalPodAutoscaler(object, details map[string]any) {
+	details["target"] = str(object, "spec", "scaleTargetRef", "kind") + "/" + str(object, "spec", "scaleTargetRef", "name")
+	details["minReplicas"] = integer(object, "spec", "minReplicas")
+	details["maxReplicas"] = integer(object, "spec", "maxReplicas")
+	details["currentReplicas"] = integer(object, "status", "currentReplicas")
+	details["desiredReplicas"] = integer(object, "status", "desiredReplicas")
+}
+
+// replicas returns the desired replicas of a workload, which default to 1.
+func replicas(object map[string]any) int {
+	if value(object, "spec", "replicas") == nil {
+		return 1
+	}
+	return integer(object, "spec", "replicas")
+}
+
+// value returns the value at the given path, or nil if the path does not exist.
+func value(object map[string]any, path ...string) any {
+	var current any = object
+	for _, key := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+	return current
+}
+
+// str returns the string at the given path, or "" if it is not a string.
+func str(object map[string]any, path ...string) string {
+	s, _ := value(object, path...).(string)
+	return s
+}
+
+// scalar returns the string or number at the given path as a string.
+func scalar(object map[string]any, path ...string) string {
+	switch v := value(object, path...).(type) {
+	case string:
+		return v
+	case float64, int, int64:
+		return fmt.Sprintf("%v", v)
+	}
+	return ""
+}
+
+// integer returns the number at the given path, or 0 if it is not a number. Objects decoded
+// with encoding/json hold float64 values and unstructured objects hold int64 values.
+func integer(object map[string]any, path ...string) int {
+	switch v := value(object, path...).(type) {
+	case float64:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// boolean returns the boolean at the given path, or false if it is not a boolean.
+func boolean(object map[string]any, path ...string) bool {
+	b, _ := value(object, path...).(bool)
+	return b
+}
+
+// slice returns the list at the given path, or nil if it is not a list.
+func slice(object map[string]any, path ...string) []any {
+	s, _ := value(object, path...).([]any)
+	return s
+}
+
+// mapValue returns the object at the given path, or nil if it is not an object.
+func mapValue(object map[string]any, path ...string) map[string]any {
+	m, _ := value(object, path...).(map[string]any)
+	return m
+}
+
+// stringMap returns the string values of the object at the given path, or nil if it is
+// empty. Non-string values such as resource quantities decoded as numbers are formatted.
+func stringMap(object map[string]any, path ...string) map[string]string {
+	m := mapValue(object, path...)
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for key := range m {
+		result[key] = scalar(m, key)
+	}
+	return result
+}
+
+// stringSlice returns the strings of the list at the given path.
+func stringSlice(object map[string]any, path ...string) []string {
+	var result []string
+	for _, item := range slice(object, path...) {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// orDefault returns s, or fallback if s is empty.
+func orDefault(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// prune removes the empty values of a details map so that descriptions only list the
+// fields that are set. false and 0 are kept for counters and flags.
+func prune(details map[string]any) {
+	for key, v := range details {
+		switch v := v.(type) {
+		case string:
+			if v == "" {
+				delete(details, key)
+			}
+		case []string:
+			if len(v) == 0 {
+				delete(details, key)
+			}
+		case map[string]string:
+			if len(v) == 0 {
+				delete(details, key)
+			}
+		case []map[string]any:
+			if len(v) == 0 {
+				delete(details, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package k8sutil
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeObject decodes a JSON object like the responses of the Kubernetes API.
+func decodeObject(t *testing.T, data string) map[string]any {
+	t.Helper()
+	var object map[string]any
+	require.NoError(t, json.Unmarshal([]byte(data), &object))
+	return object
+}
+
+// TestLookupKind verifies the resolution of kinds from names, plurals and short names.
+func TestLookupKind(t *testing.T) {
+	for _, name := range []string{"Deployment", "deployments", "deploy", " DEPLOY "} {
+		kind, ok := LookupKind(name)
+		require.True(t, ok, name)
+		assert.Equal(t, "Deployment", kind.Kind)
+	}
+
+	_, ok := LookupKind("widget")
+	assert.False(t, ok)
+	assert.Contains(t, DescribableKinds(), "HorizontalPodAutoscaler")
+}
+
+// TestResourceKindPaths verifies the API paths of namespaced and cluster-scoped resources.
+func TestResourceKindPaths(t *testing.T) {
+	deployment, _ := LookupKind("deploy")
+	assert.Equal(t, "/apis/apps/v1/namespaces/web/deployments/api", deployment.Path("web", "api"))
+	assert.Equal(t, "/api/v1/namespaces/web/events", deployment.EventsPath("web"))
+	assert.Equal(t, "involvedObject.uid=1234", deployment.EventsFieldSelector("api", "1234"))
+	assert.Equal(t, "involvedObject.kind=Deployment,involvedObject.name=api", deployment.EventsFieldSelector("api", ""))
+
+	node, _ := LookupKind("node")
+	assert.Equal(t, "/api/v1/nodes/worker-1", node.Path("web", "worker-1"))
+	assert.Equal(t, "/api/v1/events", node.EventsPath("web"))
+}
+
+// TestDescribePod verifies the description of a pod and its events.
+func TestDescribePod(t *testing.T) {
+	pod := decodeObject(t, `{
+		"metadata": {
+			"name": "api-7d9f", "namespace": "web", "creationTimestamp": "2024-05-01T10:00:00Z",
+			"labels": {"app": "api"},
+			"annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			"ownerReferences": [{"kind": "ReplicaSet", "name": "api-5c6b"}]
+		},
+		"spec": {
+			"nodeName": "worker-1",
+			"containers": [{"name": "api", "image": "api:1.2", "ports": [{"containerPort": 8080}], "resources": {"limits": {"memory": "256Mi"}}}]
+		},
+		"status": {
+			"phase": "Running", "podIP": "10.0.0.5",
+			"conditions": [{"type": "Ready", "status": "False", "reason": "ContainersNotReady"}],
+			"containerStatuses": [{
+				"name": "api", "ready": false, "restartCount": 3,
+				"state": {"waiting": {"reason": "CrashLoopBackOff", "message": "back-off 40s"}},
+				"lastState": {"terminated": {"reason": "Error", "exitCode": 1}}
+			}]
+		}
+	}`)
+	events := []map[string]any{
+		decodeObject(t, `{"type": "Warning", "reason": "BackOff", "message": "Back-off restarting", "count": 5, "firstTimestamp": "2024-05-01T10:01:00Z", "lastTimestamp": "2024-05-01T10:05:00Z", "source": {"component": "kubelet"}}`),
+		decodeObject(t, `{"type": "Normal", "reason": "Scheduled", "message": "Assigned to worker-1", "eventTime": "2024-05-01T10:00:00Z", "reportingComponent": "default-scheduler"}`),
+	}
+
+	kind, _ := LookupKind("pod")
+	desc := Describe(kind, pod, events)
+
+	assert.Equal(t, "Pod", desc.Kind)
+	assert.Equal(t, "api-7d9f", desc.Name)
+	assert.Equal(t, "web", desc.Namespace)
+	assert.Nil(t, desc.Annotations, "the last applied configuration is left out")
+	assert.Equal(t, []string{"ReplicaSet/api-5c6b"}, desc.Owners)
+	assert.Equal(t, []Condition{{Type: "Ready", Status: "False", Reason: "ContainersNotReady"}}, desc.Conditions)
+	assert.Equal(t, "worker-1", desc.Details["node"])
+	assert.NotContains(t, desc.Details, "initContainers")
+	assert.Equal(t, []map[string]any{{
+		"name":         "api",
+		"image":        "api:1.2",
+		"ports":        []string{"8080/TCP"},
+		"limits":       map[string]string{"memory": "256Mi"},
+		"ready":        false,
+		"restartCount": 3,
+		"state":        "Waiting: CrashLoopBackOff: back-off 40s",
+		"lastState":    "Terminated: Error (exit code 1)",
+	}}, desc.Details["containers"])
+
+	require.Len(t, desc.Events, 2)
+	assert.Equal(t, Event{Type: "Normal", Reason: "Scheduled", Message: "Assigned to worker-1", Count: 1, FirstSeen: "2024-05-01T10:00:00Z", LastSeen: "2024-05-01T10:00:00Z", Source: "default-scheduler"}, desc.Events[0])
+	assert.Equal(t, "BackOff", desc.Events[1].Reason)
+	assert.Equal(t, 5, desc.Events[1].Count)
+}
+
+// TestDescribeKinds verifies the kind-specific details of other resources.
+func TestDescribeKinds(t *testing.T) {
+	t.Run("secret values are not exposed", func(t *testing.T) {
+		kind, _ := LookupKind("secret")
+		desc := Describe(kind, decodeObject(t, `{"metadata": {"name": "db"}, "type": "Opaque", "data": {"password": "c2VjcmV0"}}`), nil)
+		assert.Equal(t, map[string]string{"password": "6 bytes"}, desc.Details["data"])
+		assert.Equal(t, "Opaque", desc.Details["type"])
+		assert.Equal(t, []Event{}, desc.Events)
+	})
+
+	t.Run("deployment replicas default to one", func(t *testing.T) {
+		kind, _ := LookupKind("deployment")
+		desc := Describe(kind, decodeObject(t, `{
+			"metadata": {"name": "api"},
+			"spec": {"selector": {"matchLabels": {"app": "api"}}, "strategy": {"type": "RollingUpdate"},
+				"template": {"spec": {"containers": [{"name": "api", "image": "api:1.2"}]}}},
+			"status": {"readyReplicas": 1, "updatedReplicas": 1, "availableReplicas": 1}
+		}`), nil)
+		assert.Equal(t, map[string]any{"desired": 1, "updated": 1, "ready": 1, "available": 1, "unavailable": 0}, desc.Details["replicas"])
+		assert.Equal(t, map[string]string{"app": "api"}, desc.Details["selector"])
+		assert.Equal(t, []map[string]any{{"name": "api", "image": "api:1.2"}}, desc.Details["containers"])
+	})
+
+	t.Run("service ports", func(t *testing.T) {
+		kind, _ := LookupKind("svc")
+		desc := Describe(kind, decodeObject(t, `{
+			"metadata": {"name": "api"},
+			"spec": {"type": "NodePort", "clusterIP": "10.96.0.10", "selector": {"app": "api"},
+				"ports": [{"name": "http", "port": 80, "targetPort": "http", "nodePort": 30080, "protocol": "TCP"}]}
+		}`), nil)
+		assert.Equal(t, []string{"http 80/TCP -> http (nodePort 30080)"}, desc.Details["ports"])
+		assert.NotContains(t, desc.Details, "loadBalancerIngress")
+	})
+
+	t.Run("ingress rules", func(t *testing.T) {
+		kind, _ := LookupKind("ing")
+		desc := Describe(kind, decodeObject(t, `{
+			"metadata": {"name": "api"},
+			"spec": {"rules": [{"host": "api.example.com", "http": {"paths": [{"path": "/v1", "backend": {"service": {"name": "api", "port": {"number": 80}}}}]}}],
+				"tls": [{"hosts": ["api.example.com"]}]}
+		}`), nil)
+		assert.Equal(t, []string{"api.example.com/v1 -> api:80"}, desc.Details["rules"])
+		assert.Equal(t, []string{"api.example.com"}, desc.Details["tlsHosts"])
+	})
+
+	t.Run("node taints", func(t *testing.T) {
+		kind, _ := LookupKind("node")
+		desc := Describe(kind, decodeObject(t, `{
+			"metadata": {"name": "worker-1"},
+			"spec": {"unschedulable": true, "taints": [{"key": "dedicated", "value": "gpu", "effect": "NoSchedule"}]},
+			"status": {"nodeInfo": {"kubeletVersion": "v1.30.1"}, "conditions": [{"type": "Ready", "status": "True"}]}
+		}`), nil)
+		assert.Equal(t, true, desc.Details["unschedulable"])
+		assert.Equal(t, []string{"dedicated=gpu:NoSchedule"}, desc.Details["taints"])
+		assert.Equal(t, "v1.30.1", desc.Details["kubeletVersion"])
+		assert.Len(t, desc.Conditions, 1)
+	})
+}
@@ -0,0 +1,99 @@
+package k8sutil
+
+import (
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CoreGroup is the name used for the core API group, which has an empty name in the
+// Kubernetes API and is served under /api/v1.
+const CoreGroup = "core"
+
+// APIResource is a resource served by the Kubernetes API, as listed by the discovery
+// endpoints.
+type APIResource struct {
+	Kind         string   `json:"kind"`
+	Resource     string   `json:"resource"`
+	SingularName string   `json:"singularName,omitempty"`
+	Group        string   `json:"group"`
+	Version      string   `json:"version"`
+	Namespaced   bool     `json:"namespaced"`
+	Verbs        []string `json:"verbs"`
+	ShortNames   []string `json:"shortNames,omitempty"`
+	Subresources []string `json:"subresources,omitempty"`
+	// Path is the API path of the resource collection, to use with the Kubernetes proxy.
+	// Append /{name} to address a single resource.
+	Path string `json:"path"`
+}
+
+// GroupVersionPath returns the API path prefix of a group version, such as "/api/v1" for
+// the core group or "/apis/apps/v1".
+func GroupVersionPath(groupVersion string) string {
+	if !strings.Contains(groupVersion, "/") {
+		return "/api/" + groupVersion
+	}
+	return "/apis/" + groupVersion
+}
+
+// FindResource returns the resource of a discovery list named by its kind, its plural or
+// singular resource name, or one of its short names, ignoring case, as kubectl resolves
+// resource names.
+func FindResource(resources []APIResource, name string) (APIResource, bool) {
+	for _, r := range resources {
+		if strings.EqualFold(r.Kind, name) || strings.EqualFold(r.Resource, name) || strings.EqualFold(r.SingularName, name) {
+			return r, true
+		}
+		for _, short := range r.ShortNames {
+			if strings.EqualFold(short, name) {
+				return r, true
+			}
+		}
+	}
+	return APIResource{}, false
+}
+
+// ResourcesFromList converts a discovery resource list into APIResources sorted by kind.
+// Subresources such as "pods/log" are attached to their parent resource instead of being
+// listed on their own.
+func ResourcesFromList(list metav1.APIResourceList) []APIResource {
+	group, version := CoreGroup, list.GroupVersion
+	if g, v, found := strings.Cut(list.GroupVersion, "/"); found {
+		group, version = g, v
+	}
+	prefix := GroupVersionPath(list.GroupVersion)
+
+	var resources []APIResource
+	subresources := map[string][]string{}
+	for _, r := range list.APIResources {
+		if parent, sub, found := strings.Cut(r.Name, "/"); found {
+			subresources[parent] = append(subresources[parent], sub)
+			continue
+		}
+
+		path := prefix + "/" + r.Name
+		if r.Namespaced {
+			path = prefix + "/namespaces/{namespace}/" + r.Name
+		}
+		resources = append(resources, APIResource{
+			Kind:         r.Kind,
+			Resource:     r.Name,
+			SingularName: r.SingularName,
+			Group:        group,
+			Version:      version,
+			Namespaced:   r.Namespaced,
+			Verbs:        r.Verbs,
+			ShortNames:   r.ShortNames,
+			Path:         path,
+		})
+	}
+
+	for i := range resources {
+		resources[i].Subresources = subresources[resources[i].Resource]
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].Kind < resources[j].Kind
+	})
+	return resources
+}
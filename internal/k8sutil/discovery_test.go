@@ -0,0 +1,73 @@
+package k8sutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestGroupVersionPath verifies the API paths of the core and named groups.
+func TestGroupVersionPath(t *testing.T) {
+	assert.Equal(t, "/api/v1", GroupVersionPath("v1"))
+	assert.Equal(t, "/apis/cert-manager.io/v1", GroupVersionPath("cert-manager.io/v1"))
+}
+
+// TestResourcesFromList verifies the conversion of discovery resource lists.
+func TestResourcesFromList(t *testing.T) {
+	t.Run("core group", func(t *testing.T) {
+		resources := ResourcesFromList(metav1.APIResourceList{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"get", "list"}, ShortNames: []string{"po"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "pods/exec", Kind: "PodExecOptions", Namespaced: true, Verbs: []string{"create"}},
+				{Name: "nodes", Kind: "Node", Verbs: []string{"get", "list"}},
+			},
+		})
+
+		assert.Equal(t, []APIResource{
+			{Kind: "Node", Resource: "nodes", Group: CoreGroup, Version: "v1", Verbs: []string{"get", "list"}, Path: "/api/v1/nodes"},
+			{Kind: "Pod", Resource: "pods", Group: CoreGroup, Version: "v1", Namespaced: true, Verbs: []string{"get", "list"}, ShortNames: []string{"po"},
+				Subresources: []string{"log", "exec"}, Path: "/api/v1/namespaces/{namespace}/pods"},
+		}, resources)
+	})
+
+	t.Run("custom resource group", func(t *testing.T) {
+		resources := ResourcesFromList(metav1.APIResourceList{
+			GroupVersion: "cert-manager.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "certificates", Kind: "Certificate", Namespaced: true, Verbs: []string{"get", "list", "create"}, ShortNames: []string{"cert"}},
+			},
+		})
+
+		assert.Len(t, resources, 1)
+		assert.Equal(t, "cert-manager.io", resources[0].Group)
+		assert.Equal(t, "v1", resources[0].Version)
+		assert.Equal(t, "/apis/cert-manager.io/v1/namespaces/{namespace}/certificates", resources[0].Path)
+	})
+}
+
+// TestFindResource verifies the resolution of kinds, resource names and short names.
+func TestFindResource(t *testing.T) {
+	resources := ResourcesFromList(metav1.APIResourceList{
+		GroupVersion: "cert-manager.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "certificates", SingularName: "certificate", Kind: "Certificate", Namespaced: true, ShortNames: []string{"cert", "certs"}},
+			{Name: "clusterissuers", SingularName: "clusterissuer", Kind: "ClusterIssuer"},
+		},
+	})
+
+	for _, name := range []string{"Certificate", "certificates", "certificate", "CERT", "certs"} {
+		r, ok := FindResource(resources, name)
+		assert.True(t, ok, name)
+		assert.Equal(t, "certificates", r.Resource, name)
+	}
+
+	r, ok := FindResource(resources, "clusterissuer")
+	assert.True(t, ok)
+	assert.False(t, r.Namespaced)
+
+	_, ok = FindResource(resources, "issuer")
+	assert.False(t, ok)
+}
@@ -1,6 +1,7 @@
 // Package k8sutil provides utilities for processing Kubernetes API responses.
-// It includes functions to strip verbose metadata fields (such as managedFields)
-// from JSON payloads to reduce response size.
+// It includes functions to strip verbose fields (such as managedFields, annotations
+// or status subtrees) from JSON payloads to reduce response size, and to summarize
+// resources and their events like kubectl describe.
 package k8sutil
 
 import (
@@ -8,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -46,10 +48,62 @@ func removeManagedFieldsFromUnstructuredObject(obj *unstructured.Unstructured) e
 	return nil
 }
 
+// DefaultStripFields are the fields removed from Kubernetes resources when no other
+// fields are configured.
+var DefaultStripFields = []string{"metadata.managedFields"}
+
+// KeepAll is the keep value that disables stripping for a single response.
+const KeepAll = "*"
+
+// Stripper removes a configurable set of fields from Kubernetes API responses.
+// Fields are dotted paths relative to each resource, such as "metadata.managedFields"
+// or "status". Keys containing dots are written in brackets, for example
+// "metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]".
+type Stripper struct {
+	fields []fieldPath
+}
+
+// fieldPath is a parsed field to strip.
+type fieldPath struct {
+	raw  string
+	keys []string
+}
+
+// NewStripper creates a Stripper removing the given fields.
+func NewStripper(fields []string) (*Stripper, error) {
+	s := &Stripper{}
+	for _, f := range fields {
+		keys, err := parseFieldPath(f)
+		if err != nil {
+			return nil, err
+		}
+		s.fields = append(s.fields, fieldPath{raw: f, keys: keys})
+	}
+	return s, nil
+}
+
+// Fields returns the fields removed by the stripper.
+func (s *Stripper) Fields() []string {
+	fields := make([]string, len(s.fields))
+	for i, f := range s.fields {
+		fields[i] = f.raw
+	}
+	return fields
+}
+
 // ProcessRawKubernetesAPIResponse takes an HTTP response, processes the JSON body,
-// removes managedFields (and potentially other verbose metadata) from any Kubernetes resource(s) found,
+// removes managedFields from any Kubernetes resource(s) found,
 // and returns the modified JSON bytes.
 func ProcessRawKubernetesAPIResponse(httpResp *http.Response) ([]byte, error) {
+	s, _ := NewStripper(DefaultStripFields)
+	return s.Process(httpResp, nil)
+}
+
+// Process reads the JSON body of a Kubernetes API response, removes the configured
+// fields from the resource or from every item of a list, and returns the modified JSON.
+// Fields listed in keep, fields below them and fields containing them are not removed
+// for this response; a keep entry of KeepAll disables stripping.
+func (s *Stripper) Process(httpResp *http.Response, keep []string) ([]byte, error) {
 	if httpResp == nil {
 		return nil, fmt.Errorf("http response is nil")
 	}
@@ -81,6 +135,8 @@ func ProcessRawKubernetesAPIResponse(httpResp *http.Response) ([]byte, error) {
 		return nil, fmt.Errorf("failed to unmarshal JSON into Unstructured: %w. Body: %s", err, string(bodyBytes))
 	}
 
+	fields := s.fieldsToStrip(keep)
+
 	if uObj.IsList() {
 		list, err := uObj.ToList()
 		if err != nil {
@@ -88,8 +144,10 @@ func ProcessRawKubernetesAPIResponse(httpResp *http.Response) ([]byte, error) {
 		}
 
 		for i := range list.Items {
-			if err := removeManagedFieldsFromUnstructuredObject(&list.Items[i]); err != nil {
-				return nil, fmt.Errorf("failed to remove managedFields from item %d in list: %w", i, err)
+			for _, f := range fields {
+				if err := stripField(&list.Items[i], f); err != nil {
+					return nil, fmt.Errorf("failed to remove %s from item %d in list: %w", f.name(), i, err)
+				}
 			}
 		}
 		return json.Marshal(list)
@@ -97,9 +155,104 @@ func ProcessRawKubernetesAPIResponse(httpResp *http.Response) ([]byte, error) {
 		if len(uObj.Object) == 0 {
 			return bodyBytes, nil // Empty object, nothing to process
 		}
-		if err := removeManagedFieldsFromUnstructuredObject(uObj); err != nil {
-			return nil, fmt.Errorf("failed to remove managedFields from single object: %w", err)
+		for _, f := range fields {
+			if err := stripField(uObj, f); err != nil {
+				return nil, fmt.Errorf("failed to remove %s from single object: %w", f.name(), err)
+			}
 		}
 		return json.Marshal(uObj)
 	}
 }
+
+// StripObject removes the configured fields from a decoded resource, such as an item of a
+// list decoded by the caller.
+func (s *Stripper) StripObject(object map[string]any) error {
+	obj := &unstructured.Unstructured{Object: object}
+	for _, f := range s.fields {
+		if err := stripField(obj, f); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", f.raw, err)
+		}
+	}
+	return nil
+}
+
+// fieldsToStrip returns the configured fields that are not excluded by keep.
+func (s *Stripper) fieldsToStrip(keep []string) []fieldPath {
+	var fields []fieldPath
+	for _, f := range s.fields {
+		kept := false
+		for _, k := range keep {
+			if k == KeepAll || f.raw == k || strings.HasPrefix(f.raw, k+".") || strings.HasPrefix(k, f.raw+".") || strings.HasPrefix(k, f.raw+"[") {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// stripField removes a field from an object. Missing fields are ignored; a parent
+// that exists but is not an object is an error.
+func stripField(obj *unstructured.Unstructured, f fieldPath) error {
+	current := obj.Object
+	for i, key := range f.keys[:len(f.keys)-1] {
+		value, found := current[key]
+		if !found {
+			return nil
+		}
+		next, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s for object %s (%s) is not in the expected map format", strings.Join(f.keys[:i+1], "."), obj.GetName(), obj.GetKind())
+		}
+		current = next
+	}
+	delete(current, f.keys[len(f.keys)-1])
+	return nil
+}
+
+// name returns the last key of the field, used in error messages.
+func (f fieldPath) name() string {
+	return f.keys[len(f.keys)-1]
+}
+
+// parseFieldPath splits a dotted field path into keys, honoring bracketed keys.
+func parseFieldPath(field string) ([]string, error) {
+	var keys []string
+	rest := field
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 2 {
+				return nil, fmt.Errorf("invalid field path %q: bad bracketed key", field)
+			}
+			keys = append(keys, rest[1:end])
+			rest = rest[end+1:]
+			rest = strings.TrimPrefix(rest, ".")
+			continue
+		}
+
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("invalid field path %q: empty key", field)
+		}
+		keys = append(keys, rest[:end])
+		rest = rest[end:]
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("invalid field path %q: empty key", field)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("invalid field path %q: empty path", field)
+	}
+	return keys, nil
+}
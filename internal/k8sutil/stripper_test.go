@@ -1372,3 +1372,100 @@ func TestUnmarshalErrorHandling(t *testing.T) {
 		assert.Equal(t, "[]", string(result))
 	})
 }
+
+// TestStripperConfiguredFields verifies stripping of configured fields and per-call keep overrides.
+func TestStripperConfiguredFields(t *testing.T) {
+	body := `{
+		"apiVersion": "v1",
+		"kind": "Pod",
+		"metadata": {
+			"name": "web",
+			"annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}", "team": "a"},
+			"managedFields": [{"manager": "kubectl"}]
+		},
+		"spec": {"containers": [{"name": "web"}]},
+		"status": {"phase": "Running"}
+	}`
+
+	stripper, err := NewStripper([]string{
+		"metadata.managedFields",
+		"metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]",
+		"status",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"metadata.managedFields",
+		"metadata.annotations[kubectl.kubernetes.io/last-applied-configuration]",
+		"status",
+	}, stripper.Fields())
+
+	tests := []struct {
+		name     string
+		keep     []string
+		expected string
+	}{
+		{
+			name:     "all configured fields",
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":{"team":"a"},"name":"web"},"spec":{"containers":[{"name":"web"}]}}`,
+		},
+		{
+			name:     "keep status",
+			keep:     []string{"status"},
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":{"team":"a"},"name":"web"},"spec":{"containers":[{"name":"web"}]},"status":{"phase":"Running"}}`,
+		},
+		{
+			name:     "keep parent keeps nested fields",
+			keep:     []string{"metadata"},
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","team":"a"},"managedFields":[{"manager":"kubectl"}],"name":"web"},"spec":{"containers":[{"name":"web"}]}}`,
+		},
+		{
+			name:     "keep child keeps containing field",
+			keep:     []string{"status.phase"},
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":{"team":"a"},"name":"web"},"spec":{"containers":[{"name":"web"}]},"status":{"phase":"Running"}}`,
+		},
+		{
+			name:     "keep all",
+			keep:     []string{KeepAll},
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","team":"a"},"managedFields":[{"manager":"kubectl"}],"name":"web"},"spec":{"containers":[{"name":"web"}]},"status":{"phase":"Running"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader([]byte(body)))}
+			result, err := stripper.Process(resp, tt.keep)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
+
+// TestNewStripperInvalidFields verifies field path validation.
+func TestNewStripperInvalidFields(t *testing.T) {
+	for _, field := range []string{"", ".status", "status.", "metadata..name", "metadata.annotations[]", "metadata.annotations[x"} {
+		_, err := NewStripper([]string{field})
+		assert.Error(t, err, fmt.Sprintf("field %q", field))
+	}
+
+	_, err := NewStripper([]string{"metadata.annotations[a.b/c].x"})
+	assert.NoError(t, err)
+}
+
+// TestStripObject verifies the removal of the configured fields from a decoded resource.
+func TestStripObject(t *testing.T) {
+	stripper, err := NewStripper([]string{"metadata.managedFields", "status"})
+	require.NoError(t, err)
+
+	object := map[string]any{
+		"metadata": map[string]any{"name": "web", "managedFields": []any{map[string]any{"manager": "kubectl"}}},
+		"spec":     map[string]any{"replicas": float64(2)},
+		"status":   map[string]any{"ready": true},
+	}
+	require.NoError(t, stripper.StripObject(object))
+	assert.Equal(t, map[string]any{
+		"metadata": map[string]any{"name": "web"},
+		"spec":     map[string]any{"replicas": float64(2)},
+	}, object)
+
+	assert.ErrorContains(t, stripper.StripObject(map[string]any{"metadata": "web"}), "failed to remove metadata.managedFields")
+}
@@ -0,0 +1,159 @@
+// Package maintenance keeps the environments put in maintenance mode through the MCP
+// server, with the stacks stopped to quiesce each of them in stop order, so that resuming
+// an environment restarts exactly those stacks, in reverse order. The records live in
+// memory and can optionally be persisted as a JSON file in a directory, so that an
+// environment can be resumed after a server restart.
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/atomicfile"
+)
+
+// fileName is the name of the maintenance file in the maintenance directory.
+const fileName = "maintenance.json"
+
+// now returns the current time. It is a variable so tests can pin the clock.
+var now = time.Now
+
+// Stack is a stack stopped to quiesce an environment.
+type Stack struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Record is an environment in maintenance mode.
+type Record struct {
+	EnvironmentID   int       `json:"environment_id"`
+	EnvironmentName string    `json:"environment_name"`
+	Reason          string    `json:"reason,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	// Stacks are the stacks stopped for the maintenance, in stop order.
+	Stacks []Stack `json:"stacks"`
+}
+
+// Store is a concurrency-safe set of maintenance records, by environment ID.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	records map[int]Record
+}
+
+// New creates a Store. When dir is not empty, the records are loaded from and persisted
+// to that directory, which is created if needed.
+func New(dir string) (*Store, error) {
+	s := &Store{records: map[int]Record{}}
+	if dir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create maintenance directory: %w", err)
+	}
+	s.path = filepath.Join(dir, fileName)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance records: %w", err)
+	}
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance records %s: %w", s.path, err)
+	}
+	for _, r := range records {
+		s.records[r.EnvironmentID] = r
+	}
+	return s, nil
+}
+
+// Get returns the maintenance record of an environment. The boolean is false when the
+// environment is not in maintenance mode.
+func (s *Store) Get(environmentID int) (Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[environmentID]
+	return r, ok
+}
+
+// List returns the maintenance records, by environment ID.
+func (s *Store) List() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return sortedRecords(s.records)
+}
+
+// Put records an environment in maintenance mode, replacing its previous record. The
+// start time is set when the record has none.
+func (s *Store) Put(r Record) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.StartedAt.IsZero() {
+		r.StartedAt = now().UTC()
+	}
+	if r.Stacks == nil {
+		r.Stacks = []Stack{}
+	}
+
+	records := maps.Clone(s.records)
+	records[r.EnvironmentID] = r
+	if err := s.save(records); err != nil {
+		return Record{}, err
+	}
+	s.records = records
+	return r, nil
+}
+
+// Delete removes the maintenance record of an environment.
+func (s *Store) Delete(environmentID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := maps.Clone(s.records)
+	delete(records, environmentID)
+	if err := s.save(records); err != nil {
+		return err
+	}
+	s.records = records
+	return nil
+}
+
+// sortedRecords returns the records sorted by environment ID.
+func sortedRecords(records map[int]Record) []Record {
+	list := make([]Record, 0, len(records))
+	list = slices.AppendSeq(list, maps.Values(records))
+	slices.SortFunc(list, func(a, b Record) int { return a.EnvironmentID - b.EnvironmentID })
+	return list
+}
+
+// save persists the records when a directory is configured, replacing the previous file
+// atomically. The caller must hold s.mu.
+func (s *Store) save(records map[int]Record) error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(sortedRecords(records), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal maintenance records: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.path, data); err != nil {
+		return fmt.Errorf("failed to write maintenance records: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package maintenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew verifies store creation and corrupt files.
+func TestNew(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "maintenance")
+	s, err := New(dir)
+	require.NoError(t, err)
+	assert.Empty(t, s.List())
+	assert.DirExists(t, dir)
+
+	corrupt := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(corrupt, fileName), []byte("not json"), 0o600))
+	_, err = New(corrupt)
+	assert.Error(t, err)
+}
+
+// TestPutGetDelete verifies that records keep their start time when replaced and are
+// listed by environment ID.
+func TestPutGetDelete(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	original := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = original })
+
+	s, err := New("")
+	require.NoError(t, err)
+
+	r, err := s.Put(Record{EnvironmentID: 3, EnvironmentName: "staging"})
+	require.NoError(t, err)
+	assert.Equal(t, fixed, r.StartedAt)
+	assert.Equal(t, []Stack{}, r.Stacks)
+
+	now = func() time.Time { return fixed.Add(time.Hour) }
+	r.Stacks = append(r.Stacks, Stack{ID: 7, Name: "api"})
+	_, err = s.Put(r)
+	require.NoError(t, err)
+	_, err = s.Put(Record{EnvironmentID: 1, EnvironmentName: "prod"})
+	require.NoError(t, err)
+
+	got, ok := s.Get(3)
+	require.True(t, ok)
+	assert.Equal(t, fixed, got.StartedAt, "replacing a record keeps its start time")
+	assert.Equal(t, []Stack{{ID: 7, Name: "api"}}, got.Stacks)
+
+	list := s.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, 1, list[0].EnvironmentID)
+	assert.Equal(t, 3, list[1].EnvironmentID)
+
+	require.NoError(t, s.Delete(3))
+	_, ok = s.Get(3)
+	assert.False(t, ok)
+}
+
+// TestPersistence verifies that the records survive a new store on the same directory.
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := New(dir)
+	require.NoError(t, err)
+	_, err = s.Put(Record{EnvironmentID: 2, EnvironmentName: "prod", Reason: "kernel upgrade", Stacks: []Stack{{ID: 4, Name: "web"}, {ID: 3, Name: "db"}}})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, fileName))
+
+	reopened, err := New(dir)
+	require.NoError(t, err)
+	got, ok := reopened.Get(2)
+	require.True(t, ok)
+	assert.Equal(t, "kernel upgrade", got.Reason)
+	assert.Equal(t, []Stack{{ID: 4, Name: "web"}, {ID: 3, Name: "db"}}, got.Stacks)
+
+	require.NoError(t, reopened.Delete(2))
+	again, err := New(dir)
+	require.NoError(t, err)
+	assert.Empty(t, again.List())
+}
@@ -8,9 +8,11 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -25,6 +27,7 @@ func (s *PortainerMCPServer) AddAccessGroupFeatures() {
 		s.addToolIfExists(ToolUpdateAccessGroupTeamAccesses, s.HandleUpdateAccessGroupTeamAccesses())
 		s.addToolIfExists(ToolAddEnvironmentToAccessGroup, s.HandleAddEnvironmentToAccessGroup())
 		s.addToolIfExists(ToolRemoveEnvironmentFromAccessGroup, s.HandleRemoveEnvironmentFromAccessGroup())
+		s.addToolIfExists(ToolSetAccessGroupEnvironments, s.HandleSetAccessGroupEnvironments())
 	}
 }
 
@@ -104,7 +107,7 @@ func (s *PortainerMCPServer) HandleUpdateAccessGroupUserAccesses() server.ToolHa
 			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
 		}
 
-		userAccesses, err := parser.GetArrayOfObjects("userAccesses", true)
+		userAccesses, err := parser.GetArrayOfObjectMaps("userAccesses", true)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid userAccesses parameter", err), nil
 		}
@@ -133,7 +136,7 @@ func (s *PortainerMCPServer) HandleUpdateAccessGroupTeamAccesses() server.ToolHa
 			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
 		}
 
-		teamAccesses, err := parser.GetArrayOfObjects("teamAccesses", true)
+		teamAccesses, err := parser.GetArrayOfObjectMaps("teamAccesses", true)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid teamAccesses parameter", err), nil
 		}
@@ -199,3 +202,96 @@ func (s *PortainerMCPServer) HandleRemoveEnvironmentFromAccessGroup() server.Too
 		return mcp.NewToolResultText("Environment removed from access group successfully"), nil
 	}
 }
+
+// accessGroupMembershipFailure describes an environment whose membership change failed.
+type accessGroupMembershipFailure struct {
+	EnvironmentID int    `json:"environment_id"`
+	Operation     string `json:"operation"`
+	Error         string `json:"error"`
+}
+
+// accessGroupSyncReport is the result of HandleSetAccessGroupEnvironments.
+type accessGroupSyncReport struct {
+	AccessGroupID   int                            `json:"access_group_id"`
+	AccessGroupName string                         `json:"access_group_name"`
+	Added           []int                          `json:"added"`
+	Removed         []int                          `json:"removed"`
+	Unchanged       []int                          `json:"unchanged"`
+	Failures        []accessGroupMembershipFailure `json:"failures,omitempty"`
+}
+
+// HandleSetAccessGroupEnvironments returns an MCP tool handler that sets the environments
+// of an access group to the given list, adding and removing only the environments that
+// differ from the current membership. Removed environments go back to the Unassigned group.
+func (s *PortainerMCPServer) HandleSetAccessGroupEnvironments() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, environmentId := range environmentIds {
+			if err := validatePositiveID("environmentIds", environmentId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		groups, err := s.cli.GetAccessGroups()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get access groups", err), nil
+		}
+		idx := slices.IndexFunc(groups, func(g models.AccessGroup) bool { return g.ID == id })
+		if idx < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("access group %d not found", id)), nil
+		}
+		group := groups[idx]
+
+		report := accessGroupSyncReport{
+			AccessGroupID:   group.ID,
+			AccessGroupName: group.Name,
+			Added:           []int{},
+			Removed:         []int{},
+			Unchanged:       []int{},
+		}
+
+		desired := slices.Clone(environmentIds)
+		slices.Sort(desired)
+		desired = slices.Compact(desired)
+		current := slices.Clone(group.EnvironmentIds)
+		slices.Sort(current)
+
+		for _, environmentId := range desired {
+			if slices.Contains(current, environmentId) {
+				report.Unchanged = append(report.Unchanged, environmentId)
+				continue
+			}
+			if err := s.cli.AddEnvironmentToAccessGroup(id, environmentId); err != nil {
+				report.Failures = append(report.Failures, accessGroupMembershipFailure{EnvironmentID: environmentId, Operation: "add", Error: err.Error()})
+				continue
+			}
+			report.Added = append(report.Added, environmentId)
+		}
+
+		for _, environmentId := range current {
+			if slices.Contains(desired, environmentId) {
+				continue
+			}
+			if err := s.cli.RemoveEnvironmentFromAccessGroup(id, environmentId); err != nil {
+				report.Failures = append(report.Failures, accessGroupMembershipFailure{EnvironmentID: environmentId, Operation: "remove", Error: err.Error()})
+				continue
+			}
+			report.Removed = append(report.Removed, environmentId)
+		}
+
+		return jsonResult(report, "failed to marshal access group sync report")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestHandleGetAccessGroups verifies the HandleGetAccessGroups MCP tool handler.
@@ -789,3 +790,103 @@ func TestHandleRemoveEnvironmentFromAccessGroup(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleSetAccessGroupEnvironments verifies that HandleSetAccessGroupEnvironments only
+// applies the delta between the current and the desired environments of the group.
+func TestHandleSetAccessGroupEnvironments(t *testing.T) {
+	groups := []models.AccessGroup{
+		{ID: 1, Name: "production", EnvironmentIds: []int{3, 1, 2}},
+		{ID: 2, Name: "staging", EnvironmentIds: []int{}},
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+		expected    accessGroupSyncReport
+	}{
+		{
+			name: "adds and removes the difference",
+			args: map[string]any{"id": float64(1), "environmentIds": []any{float64(4), float64(2), float64(1), float64(4)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(groups, nil)
+				m.On("AddEnvironmentToAccessGroup", 1, 4).Return(nil)
+				m.On("RemoveEnvironmentFromAccessGroup", 1, 3).Return(nil)
+			},
+			expected: accessGroupSyncReport{AccessGroupID: 1, AccessGroupName: "production", Added: []int{4}, Removed: []int{3}, Unchanged: []int{1, 2}},
+		},
+		{
+			name: "empty list removes all environments",
+			args: map[string]any{"id": float64(1), "environmentIds": []any{}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(groups, nil)
+				m.On("RemoveEnvironmentFromAccessGroup", 1, 1).Return(nil)
+				m.On("RemoveEnvironmentFromAccessGroup", 1, 2).Return(nil)
+				m.On("RemoveEnvironmentFromAccessGroup", 1, 3).Return(nil)
+			},
+			expected: accessGroupSyncReport{AccessGroupID: 1, AccessGroupName: "production", Added: []int{}, Removed: []int{1, 2, 3}, Unchanged: []int{}},
+		},
+		{
+			name: "failures are reported per environment",
+			args: map[string]any{"id": float64(2), "environmentIds": []any{float64(5), float64(6)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(groups, nil)
+				m.On("AddEnvironmentToAccessGroup", 2, 5).Return(fmt.Errorf("environment not found"))
+				m.On("AddEnvironmentToAccessGroup", 2, 6).Return(nil)
+			},
+			expected: accessGroupSyncReport{
+				AccessGroupID:   2,
+				AccessGroupName: "staging",
+				Added:           []int{6},
+				Removed:         []int{},
+				Unchanged:       []int{},
+				Failures:        []accessGroupMembershipFailure{{EnvironmentID: 5, Operation: "add", Error: "environment not found"}},
+			},
+		},
+		{
+			name: "unknown access group",
+			args: map[string]any{"id": float64(9), "environmentIds": []any{float64(1)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(groups, nil)
+			},
+			expectError: "access group 9 not found",
+		},
+		{
+			name: "access groups error",
+			args: map[string]any{"id": float64(1), "environmentIds": []any{float64(1)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(nil, fmt.Errorf("forbidden"))
+			},
+			expectError: "failed to get access groups",
+		},
+		{name: "missing environmentIds", args: map[string]any{"id": float64(1)}, expectError: "environmentIds"},
+		{name: "invalid environment id", args: map[string]any{"id": float64(1), "environmentIds": []any{float64(0)}}, expectError: "environmentIds"},
+		{name: "invalid id", args: map[string]any{"id": float64(0), "environmentIds": []any{}}, expectError: "id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleSetAccessGroupEnvironments()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var report accessGroupSyncReport
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			assert.Equal(t, tt.expected, report)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
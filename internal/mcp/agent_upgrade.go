@@ -0,0 +1,322 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/mod/semver"
+)
+
+// edgeUpdateScheduledTimeLayout is the layout of the scheduled time of edge update schedules.
+const edgeUpdateScheduledTimeLayout = "2006-01-02 15:04:05"
+
+// agentEnvironment is an environment running a Portainer agent.
+type agentEnvironment struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+}
+
+// agentUpgradeGroup is an edge group whose update schedule would update outdated edge agents.
+type agentUpgradeGroup struct {
+	EdgeGroupID   int    `json:"edge_group_id"`
+	EdgeGroupName string `json:"edge_group_name"`
+	// Outdated lists the outdated edge agents updated through this group.
+	Outdated []int `json:"outdated"`
+	// Environments is the number of environments of the group, up to date or not.
+	Environments int `json:"environments"`
+}
+
+// agentVersionReport is the result of HandleGetAgentVersionReport.
+type agentVersionReport struct {
+	ServerVersion string             `json:"server_version"`
+	Agents        int                `json:"agents"`
+	UpToDate      int                `json:"up_to_date"`
+	Versions      []versionSpread    `json:"versions"`
+	Outdated      []agentEnvironment `json:"outdated"`
+	// Newer lists the agents running a more recent version than the server.
+	Newer []agentEnvironment `json:"newer,omitempty"`
+	// Unknown lists the agent environments whose agent has not reported a valid version.
+	Unknown []agentEnvironment `json:"unknown,omitempty"`
+	// UpgradePlan lists the edge groups to give an update schedule so that every outdated
+	// edge agent is updated.
+	UpgradePlan []agentUpgradeGroup `json:"upgrade_plan"`
+	// ManualUpgrades lists the outdated agents that edge update schedules cannot update:
+	// standard agents, Kubernetes edge agents and edge agents outside of any edge group.
+	ManualUpgrades []int    `json:"manual_upgrades,omitempty"`
+	Notes          []string `json:"notes,omitempty"`
+}
+
+// agentUpgradeScheduleResult is the result of HandleScheduleAgentUpgrades.
+type agentUpgradeScheduleResult struct {
+	ScheduleID   int    `json:"schedule_id"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	EdgeGroupIDs []int  `json:"edge_group_ids"`
+	// ScheduledTime is empty when the update starts immediately.
+	ScheduledTime string `json:"scheduled_time,omitempty"`
+	// Outdated lists the outdated edge agents of the scheduled edge groups.
+	Outdated       []int `json:"outdated"`
+	ManualUpgrades []int `json:"manual_upgrades,omitempty"`
+}
+
+// HandleGetAgentVersionReport returns an MCP tool handler that lists the agent versions of
+// the environments, the agents older than the Portainer server and the edge groups whose
+// update schedule would update the outdated edge agents.
+func (s *PortainerMCPServer) HandleGetAgentVersionReport() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		report, err := s.buildAgentVersionReport(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to build agent version report", err), nil
+		}
+
+		return jsonResult(report, "failed to marshal agent version report")
+	}
+}
+
+// HandleScheduleAgentUpgrades returns an MCP tool handler that creates an edge update
+// schedule updating the outdated edge agents to the version of the Portainer server. By
+// default, the schedule targets the edge groups of the upgrade plan of getAgentVersionReport.
+func (s *PortainerMCPServer) HandleScheduleAgentUpgrades() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		edgeGroupIds, err := parser.GetArrayOfIntegers("edgeGroupIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid edgeGroupIds parameter", err), nil
+		}
+		for _, id := range edgeGroupIds {
+			if err := validatePositiveID("edgeGroupIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		scheduledTime, err := parser.GetString("scheduledTime", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid scheduledTime parameter", err), nil
+		}
+		if scheduledTime != "" {
+			if _, err := time.Parse(edgeUpdateScheduledTimeLayout, scheduledTime); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid scheduledTime %q: expected YYYY-MM-DD HH:MM:SS", scheduledTime)), nil
+			}
+		}
+
+		report, err := s.buildAgentVersionReport(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to build agent version report", err), nil
+		}
+
+		result := agentUpgradeScheduleResult{
+			Version:        report.ServerVersion,
+			ScheduledTime:  scheduledTime,
+			Outdated:       []int{},
+			ManualUpgrades: report.ManualUpgrades,
+		}
+
+		if len(edgeGroupIds) == 0 {
+			for _, group := range report.UpgradePlan {
+				result.EdgeGroupIDs = append(result.EdgeGroupIDs, group.EdgeGroupID)
+				result.Outdated = append(result.Outdated, group.Outdated...)
+			}
+			if len(result.EdgeGroupIDs) == 0 {
+				return mcp.NewToolResultError("no outdated edge agent can be updated by an edge update schedule; use getAgentVersionReport to see the agent versions"), nil
+			}
+		} else {
+			groups, err := s.cli.GetEnvironmentGroups()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get environment groups", err), nil
+			}
+			outdated := map[int]bool{}
+			for _, env := range report.Outdated {
+				outdated[env.ID] = env.Type == models.EnvironmentTypeDockerEdgeAgent
+			}
+			for _, id := range edgeGroupIds {
+				idx := slices.IndexFunc(groups, func(g models.Group) bool { return g.ID == id })
+				if idx < 0 {
+					return mcp.NewToolResultError(fmt.Sprintf("edge group %d not found", id)), nil
+				}
+				for _, envID := range groups[idx].EnvironmentIds {
+					if outdated[envID] && !slices.Contains(result.Outdated, envID) {
+						result.Outdated = append(result.Outdated, envID)
+					}
+				}
+			}
+			result.EdgeGroupIDs = edgeGroupIds
+			sort.Ints(result.Outdated)
+		}
+
+		result.Name = name
+		if result.Name == "" {
+			result.Name = fmt.Sprintf("Update agents to %s (%s)", report.ServerVersion, time.Now().UTC().Format("2006-01-02 15:04"))
+		}
+
+		id, err := s.cli.CreateEdgeUpdateSchedule(result.Name, result.EdgeGroupIDs, scheduledTime)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to create edge update schedule", err), nil
+		}
+		result.ScheduleID = id
+
+		return jsonResult(result, "failed to marshal edge update schedule")
+	}
+}
+
+// buildAgentVersionReport compares the agent version of every agent environment with the
+// version of the Portainer server, and plans the edge update schedules of the outdated
+// edge agents.
+func (s *PortainerMCPServer) buildAgentVersionReport(ctx context.Context) (agentVersionReport, error) {
+	serverVersion, err := s.cli.GetVersion()
+	if err != nil {
+		return agentVersionReport{}, err
+	}
+	if !semver.IsValid(canonicalVersion(serverVersion)) {
+		return agentVersionReport{}, fmt.Errorf("server version %q is not a semantic version", serverVersion)
+	}
+
+	environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{})
+	if err != nil {
+		return agentVersionReport{}, err
+	}
+
+	report := agentVersionReport{
+		ServerVersion: serverVersion,
+		Outdated:      []agentEnvironment{},
+		UpgradePlan:   []agentUpgradeGroup{},
+	}
+	versions := map[string][]int{}
+	updatable := map[int]bool{}
+
+	for _, env := range environments {
+		if !isAgentEnvironment(env) {
+			continue
+		}
+		report.Agents++
+		agent := agentEnvironment{ID: env.ID, Name: env.Name, Type: env.Type, Status: env.Status, Version: env.AgentVersion}
+		if !semver.IsValid(canonicalVersion(env.AgentVersion)) {
+			report.Unknown = append(report.Unknown, agent)
+			continue
+		}
+		versions[env.AgentVersion] = append(versions[env.AgentVersion], env.ID)
+
+		switch c := semver.Compare(canonicalVersion(env.AgentVersion), canonicalVersion(serverVersion)); {
+		case c < 0:
+			report.Outdated = append(report.Outdated, agent)
+			if env.Type == models.EnvironmentTypeDockerEdgeAgent {
+				updatable[env.ID] = true
+			} else {
+				report.ManualUpgrades = append(report.ManualUpgrades, env.ID)
+			}
+		case c > 0:
+			report.Newer = append(report.Newer, agent)
+		default:
+			report.UpToDate++
+		}
+	}
+	report.Versions = buildVersionSkew(versions).Versions
+
+	if len(updatable) > 0 {
+		groups, err := s.cli.GetEnvironmentGroups()
+		if err != nil {
+			return agentVersionReport{}, err
+		}
+		var uncovered []int
+		report.UpgradePlan, uncovered = planAgentUpgradeGroups(groups, updatable)
+		report.ManualUpgrades = append(report.ManualUpgrades, uncovered...)
+		if len(uncovered) > 0 {
+			report.Notes = append(report.Notes, fmt.Sprintf("edge environments %s are in no edge group: add them to one to schedule their update", joinInts(uncovered)))
+		}
+	}
+	sort.Ints(report.ManualUpgrades)
+	if len(report.Unknown) > 0 {
+		report.Notes = append(report.Notes, "agents without a reported version are left out of the comparison; snapshot their environments to refresh it")
+	}
+
+	return report, nil
+}
+
+// planAgentUpgradeGroups picks the edge groups whose update schedule updates the given
+// outdated edge agents. Groups holding the most outdated agents not yet covered are picked
+// first, the smallest group first on ties. It also returns the agents in no edge group.
+func planAgentUpgradeGroups(groups []models.Group, outdated map[int]bool) ([]agentUpgradeGroup, []int) {
+	covered := map[int]bool{}
+	plan := []agentUpgradeGroup{}
+
+	for {
+		best, bestCount := -1, 0
+		for i, group := range groups {
+			count := 0
+			for _, id := range group.EnvironmentIds {
+				if outdated[id] && !covered[id] {
+					count++
+				}
+			}
+			if count == 0 {
+				continue
+			}
+			if best < 0 || count > bestCount ||
+				(count == bestCount && len(group.EnvironmentIds) < len(groups[best].EnvironmentIds)) {
+				best, bestCount = i, count
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		group := agentUpgradeGroup{
+			EdgeGroupID:   groups[best].ID,
+			EdgeGroupName: groups[best].Name,
+			Outdated:      []int{},
+			Environments:  len(groups[best].EnvironmentIds),
+		}
+		for _, id := range groups[best].EnvironmentIds {
+			if outdated[id] && !covered[id] {
+				covered[id] = true
+				group.Outdated = append(group.Outdated, id)
+			}
+		}
+		sort.Ints(group.Outdated)
+		plan = append(plan, group)
+	}
+
+	var uncovered []int
+	for id := range outdated {
+		if !covered[id] {
+			uncovered = append(uncovered, id)
+		}
+	}
+	sort.Ints(uncovered)
+	return plan, uncovered
+}
+
+// isAgentEnvironment reports whether an environment is reached through a Portainer agent.
+func isAgentEnvironment(env models.Environment) bool {
+	switch env.Type {
+	case models.EnvironmentTypeDockerAgent, models.EnvironmentTypeDockerEdgeAgent,
+		models.EnvironmentTypeKubernetesAgent, models.EnvironmentTypeKubernetesEdgeAgent:
+		return true
+	}
+	return env.AgentVersion != ""
+}
+
+// joinInts formats a list of IDs as a comma-separated list.
+func joinInts(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprint(id)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,204 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// agentFixtureEnvironments and agentFixtureGroups are the environments and edge groups of
+// the agent upgrade tests.
+var (
+	agentFixtureEnvironments = []models.Environment{
+		{ID: 1, Name: "local", Type: models.EnvironmentTypeDockerLocal},
+		{ID: 2, Name: "agent", Type: models.EnvironmentTypeDockerAgent, AgentVersion: "2.19.4"},
+		{ID: 3, Name: "edge-a", Type: models.EnvironmentTypeDockerEdgeAgent, AgentVersion: "2.19.4"},
+		{ID: 4, Name: "edge-b", Type: models.EnvironmentTypeDockerEdgeAgent, AgentVersion: "2.21.0"},
+		{ID: 5, Name: "edge-c", Type: models.EnvironmentTypeDockerEdgeAgent, AgentVersion: "2.31.2"},
+		{ID: 6, Name: "edge-d", Type: models.EnvironmentTypeDockerEdgeAgent, AgentVersion: "2.20.0"},
+		{ID: 7, Name: "k8s-edge", Type: models.EnvironmentTypeKubernetesEdgeAgent, AgentVersion: "2.20.0"},
+		{ID: 8, Name: "new-edge", Type: models.EnvironmentTypeDockerEdgeAgent},
+		{ID: 9, Name: "beta", Type: models.EnvironmentTypeDockerAgent, AgentVersion: "2.32.0-rc1"},
+	}
+	agentFixtureGroups = []models.Group{
+		{ID: 10, Name: "all-edge", EnvironmentIds: []int{3, 4, 5, 8}},
+		{ID: 11, Name: "site-a", EnvironmentIds: []int{3}},
+		{ID: 12, Name: "site-b", EnvironmentIds: []int{4, 5}},
+	}
+)
+
+// TestHandleGetAgentVersionReport verifies the agent versions, outdated agents and
+// upgrade plan reported by the getAgentVersionReport tool.
+func TestHandleGetAgentVersionReport(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetVersion").Return("2.31.2", nil)
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return(agentFixtureEnvironments, nil)
+	mockClient.On("GetEnvironmentGroups").Return(agentFixtureGroups, nil)
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetAgentVersionReport()(context.Background(), CreateMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+
+	var report agentVersionReport
+	require.NoError(t, json.Unmarshal([]byte(text), &report))
+	assert.Equal(t, "2.31.2", report.ServerVersion)
+	assert.Equal(t, 8, report.Agents)
+	assert.Equal(t, 1, report.UpToDate)
+	assert.Equal(t, []versionSpread{
+		{Version: "2.32.0-rc1", Environments: []int{9}},
+		{Version: "2.31.2", Environments: []int{5}},
+		{Version: "2.21.0", Environments: []int{4}},
+		{Version: "2.20.0", Environments: []int{6, 7}},
+		{Version: "2.19.4", Environments: []int{2, 3}},
+	}, report.Versions)
+
+	var outdated []int
+	for _, env := range report.Outdated {
+		outdated = append(outdated, env.ID)
+	}
+	assert.Equal(t, []int{2, 3, 4, 6, 7}, outdated)
+	require.Len(t, report.Newer, 1)
+	assert.Equal(t, 9, report.Newer[0].ID)
+	require.Len(t, report.Unknown, 1)
+	assert.Equal(t, 8, report.Unknown[0].ID)
+
+	assert.Equal(t, []agentUpgradeGroup{{EdgeGroupID: 10, EdgeGroupName: "all-edge", Outdated: []int{3, 4}, Environments: 4}}, report.UpgradePlan)
+	assert.Equal(t, []int{2, 6, 7}, report.ManualUpgrades)
+	require.Len(t, report.Notes, 2)
+	assert.Contains(t, report.Notes[0], "edge environments 6 are in no edge group")
+	mockClient.AssertExpectations(t)
+}
+
+// TestPlanAgentUpgradeGroups verifies that the smallest set of edge groups covering the
+// outdated edge agents is picked.
+func TestPlanAgentUpgradeGroups(t *testing.T) {
+	groups := []models.Group{
+		{ID: 1, Name: "big", EnvironmentIds: []int{1, 2, 3, 4, 5}},
+		{ID: 2, Name: "small", EnvironmentIds: []int{1, 2}},
+		{ID: 3, Name: "other", EnvironmentIds: []int{6}},
+	}
+
+	plan, uncovered := planAgentUpgradeGroups(groups, map[int]bool{1: true, 2: true, 6: true, 7: true})
+	assert.Equal(t, []agentUpgradeGroup{
+		{EdgeGroupID: 2, EdgeGroupName: "small", Outdated: []int{1, 2}, Environments: 2},
+		{EdgeGroupID: 3, EdgeGroupName: "other", Outdated: []int{6}, Environments: 1},
+	}, plan)
+	assert.Equal(t, []int{7}, uncovered)
+}
+
+// TestHandleScheduleAgentUpgrades verifies the edge update schedules created by the
+// scheduleAgentUpgrades tool.
+func TestHandleScheduleAgentUpgrades(t *testing.T) {
+	setupReport := func(m *MockPortainerClient) {
+		m.On("GetVersion").Return("2.31.2", nil)
+		m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(agentFixtureEnvironments, nil)
+		m.On("GetEnvironmentGroups").Return(agentFixtureGroups, nil)
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+		expected    agentUpgradeScheduleResult
+	}{
+		{
+			name: "upgrade plan",
+			args: map[string]any{"name": "nightly", "scheduledTime": "2026-01-02 03:00:00"},
+			setupMock: func(m *MockPortainerClient) {
+				setupReport(m)
+				m.On("CreateEdgeUpdateSchedule", "nightly", []int{10}, "2026-01-02 03:00:00").Return(5, nil)
+			},
+			expected: agentUpgradeScheduleResult{
+				ScheduleID: 5, Name: "nightly", Version: "2.31.2", EdgeGroupIDs: []int{10},
+				ScheduledTime: "2026-01-02 03:00:00", Outdated: []int{3, 4}, ManualUpgrades: []int{2, 6, 7},
+			},
+		},
+		{
+			name: "given edge groups",
+			args: map[string]any{"edgeGroupIds": []any{float64(12)}},
+			setupMock: func(m *MockPortainerClient) {
+				setupReport(m)
+				m.On("CreateEdgeUpdateSchedule", mock.MatchedBy(func(name string) bool { return name != "" }), []int{12}, "").Return(6, nil)
+			},
+			expected: agentUpgradeScheduleResult{
+				ScheduleID: 6, Version: "2.31.2", EdgeGroupIDs: []int{12}, Outdated: []int{4}, ManualUpgrades: []int{2, 6, 7},
+			},
+		},
+		{
+			name: "unknown edge group",
+			args: map[string]any{"edgeGroupIds": []any{float64(99)}},
+			setupMock: func(m *MockPortainerClient) {
+				setupReport(m)
+			},
+			expectError: "edge group 99 not found",
+		},
+		{
+			name: "nothing to update",
+			args: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("2.19.4", nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(agentFixtureEnvironments, nil)
+			},
+			expectError: "no outdated edge agent",
+		},
+		{
+			name: "create error",
+			args: map[string]any{"name": "nightly"},
+			setupMock: func(m *MockPortainerClient) {
+				setupReport(m)
+				m.On("CreateEdgeUpdateSchedule", "nightly", []int{10}, "").Return(0, errors.New("edge compute disabled"))
+			},
+			expectError: "failed to create edge update schedule",
+		},
+		{
+			name: "invalid server version",
+			args: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("develop", nil)
+			},
+			expectError: "not a semantic version",
+		},
+		{name: "invalid scheduledTime", args: map[string]any{"scheduledTime": "tomorrow"}, expectError: "invalid scheduledTime"},
+		{name: "invalid edgeGroupIds", args: map[string]any{"edgeGroupIds": []any{float64(0)}}, expectError: "edgeGroupIds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleScheduleAgentUpgrades()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				mockClient.AssertExpectations(t)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var got agentUpgradeScheduleResult
+			require.NoError(t, json.Unmarshal([]byte(text), &got))
+			if tt.expected.Name == "" {
+				assert.Contains(t, got.Name, "Update agents to 2.31.2")
+				got.Name = ""
+			}
+			assert.Equal(t, tt.expected, got)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// parameterErrorPatterns match the parameter named in the errors of the handlers, such
+// as "invalid environmentId parameter", and of the toolgen getters, such as
+// "environmentId is required" or "tail must be a number"
+var parameterErrorPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`invalid (\w+) parameter`),
+	regexp.MustCompile(`\b(\w+) (?:is required|must )`),
+}
+
+// argumentRulesMiddleware returns a tool handler middleware that applies the parameter
+// defaults and coercion rules of tools.yaml to the arguments of every call, so that the
+// handlers accept numbers and booleans sent as strings. When a call fails because of a
+// parameter, the declared type, description and an example of the parameter are added
+// to the error so that the caller can correct the next call. Calls to meta-tools use the
+// rules of the granular tool behind their action.
+func argumentRulesMiddleware(rules *toolgen.ArgumentRules) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			args := request.GetArguments()
+			name := request.Params.Name
+			if action, ok := args["action"].(string); ok {
+				if a, found := findMetaAction(name, action); found {
+					name = a.tool
+				}
+			}
+
+			request.Params.Arguments = rules.Apply(name, args)
+			result, err := next(ctx, request)
+			if err != nil || result == nil || !result.IsError {
+				return result, err
+			}
+
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					if hints := parameterHints(rules, name, text.Text); len(hints) > 0 {
+						text.Text += "\n\n" + strings.Join(hints, "\n")
+						result.Content[i] = text
+					}
+					break
+				}
+			}
+			return result, nil
+		}
+	}
+}
+
+// parameterHints returns the expected value of each parameter of a tool named in an
+// error message
+func parameterHints(rules *toolgen.ArgumentRules, toolName, message string) []string {
+	var names, hints []string
+	for _, pattern := range parameterErrorPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(message, -1) {
+			if slices.Contains(names, match[1]) {
+				continue
+			}
+			if hint, ok := rules.Describe(toolName, match[1]); ok {
+				names = append(names, match[1])
+				hints = append(hints, hint)
+			}
+		}
+	}
+	return hints
+}
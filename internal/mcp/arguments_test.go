@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestArgumentRulesMiddleware verifies that defaults and coercion apply to granular
+// tools and to the granular tool behind a meta-tool action.
+func TestArgumentRulesMiddleware(t *testing.T) {
+	rules := toolgen.NewArgumentRules([]toolgen.ToolDefinition{
+		{
+			Name: ToolGetContainerLogs,
+			Parameters: []toolgen.ParameterDefinition{
+				{Name: "environmentId", Type: "number", Required: true},
+				{Name: "tail", Type: "number", Default: 100},
+				{Name: "timestamps", Type: "boolean"},
+			},
+		},
+	})
+
+	var received map[string]any
+	handler := argumentRulesMiddleware(rules)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		received = request.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want map[string]any
+	}{
+		{
+			name: "granular tool",
+			tool: ToolGetContainerLogs,
+			args: map[string]any{"environmentId": "1", "timestamps": "true"},
+			want: map[string]any{"environmentId": float64(1), "tail": float64(100), "timestamps": true},
+		},
+		{
+			name: "meta-tool action",
+			tool: "manage_docker",
+			args: map[string]any{"action": "get_container_logs", "environmentId": "2", "tail": "20"},
+			want: map[string]any{"action": "get_container_logs", "environmentId": float64(2), "tail": float64(20)},
+		},
+		{
+			name: "tool without rules",
+			tool: ToolListEnvironments,
+			args: map[string]any{"limit": "5"},
+			want: map[string]any{"limit": "5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			assert.False(t, result.IsError)
+			assert.Equal(t, tt.want, received)
+		})
+	}
+}
+
+// TestArgumentRulesMiddlewareErrorHints verifies that parameter errors include the
+// expected value of the parameters they name.
+func TestArgumentRulesMiddlewareErrorHints(t *testing.T) {
+	rules := toolgen.NewArgumentRules([]toolgen.ToolDefinition{
+		{
+			Name: ToolGetContainerLogs,
+			Parameters: []toolgen.ParameterDefinition{
+				{Name: "environmentId", Type: "number", Required: true, Description: "Environment ID"},
+				{Name: "tail", Type: "number", Description: "Number of lines", Default: 100},
+			},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		want   string
+	}{
+		{
+			name:   "handler parameter error",
+			result: mcp.NewToolResultError("invalid environmentId parameter: environmentId is required"),
+			want:   "invalid environmentId parameter: environmentId is required\n\nExpected environmentId: number, required. Environment ID. Example: 1",
+		},
+		{
+			name:   "validation error naming a parameter",
+			result: mcp.NewToolResultError("tail must not be negative, got -1"),
+			want:   "tail must not be negative, got -1\n\nExpected tail: number, optional. Number of lines. Example: 100",
+		},
+		{
+			name:   "error without parameter",
+			result: mcp.NewToolResultError("failed to get container logs: connection refused"),
+			want:   "failed to get container logs: connection refused",
+		},
+		{
+			name:   "successful result",
+			result: mcp.NewToolResultText("tail must be kept"),
+			want:   "tail must be kept",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := argumentRulesMiddleware(rules)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+			request := CreateMCPRequest(map[string]any{"action": "get_container_logs"})
+			request.Params.Name = "manage_docker"
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+}
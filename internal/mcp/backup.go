@@ -13,6 +13,7 @@ import (
 func (s *PortainerMCPServer) AddBackupFeatures() {
 	s.addToolIfExists(ToolGetBackupStatus, s.HandleGetBackupStatus())
 	s.addToolIfExists(ToolGetBackupS3Settings, s.HandleGetBackupS3Settings())
+	s.addToolIfExists(ToolVerifyBackup, s.HandleVerifyBackup())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolCreateBackup, s.HandleCreateBackup())
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/backuparchive"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/mod/semver"
+)
+
+// backupVerification is the result of HandleVerifyBackup.
+type backupVerification struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Valid is true when no problem was found.
+	Valid bool `json:"valid"`
+	backuparchive.Info
+	// ServerVersion is the version of the connected Portainer server, to compare with the
+	// version of the backup before a restore.
+	ServerVersion string   `json:"serverVersion,omitempty"`
+	Problems      []string `json:"problems,omitempty"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+// HandleVerifyBackup returns an MCP tool handler that checks a backup archive saved on the
+// machine running the MCP server: its size and SHA-256 checksum, the integrity of its
+// content (decrypting it when a password is given) and the Portainer version that created it.
+func (s *PortainerMCPServer) HandleVerifyBackup() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		archivePath, err := parser.GetString("path", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid path parameter", err), nil
+		}
+		if strings.TrimSpace(archivePath) == "" {
+			return mcp.NewToolResultError("path cannot be empty"), nil
+		}
+
+		expectedSHA256, err := parser.GetString("sha256", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid sha256 parameter", err), nil
+		}
+		expectedSHA256 = strings.ToLower(strings.TrimSpace(expectedSHA256))
+		if expectedSHA256 != "" {
+			if decoded, err := hex.DecodeString(expectedSHA256); err != nil || len(decoded) != sha256.Size {
+				return mcp.NewToolResultError("sha256 must be a hex-encoded SHA-256 checksum (64 characters)"), nil
+			}
+		}
+
+		expectedSize, err := parser.GetInt("expectedSize", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid expectedSize parameter", err), nil
+		}
+		if expectedSize < 0 {
+			return mcp.NewToolResultError("expectedSize cannot be negative"), nil
+		}
+
+		password, err := parser.GetString("password", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid password parameter", err), nil
+		}
+
+		file, err := os.Open(archivePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			return mcp.NewToolResultError(fmt.Sprintf("backup archive %s does not exist", archivePath)), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to open backup archive", err), nil
+		}
+		defer file.Close()
+
+		stat, err := file.Stat()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read backup archive", err), nil
+		}
+		if stat.IsDir() {
+			return mcp.NewToolResultError(fmt.Sprintf("%s is a directory, not a backup archive", archivePath)), nil
+		}
+
+		report := backupVerification{Path: archivePath, Size: stat.Size()}
+		hash := sha256.New()
+		info, inspectErr := backuparchive.Inspect(io.TeeReader(file, hash), password)
+		if inspectErr != nil {
+			// Hash the rest of the file, so that the checksum covers the whole archive.
+			if _, err := io.Copy(hash, file); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to read backup archive", err), nil
+			}
+			report.Problems = append(report.Problems, inspectErr.Error())
+		}
+		report.Info = info
+		report.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+		if expectedSHA256 != "" && report.SHA256 != expectedSHA256 {
+			report.Problems = append(report.Problems, fmt.Sprintf("checksum mismatch: expected %s", expectedSHA256))
+		}
+		if expectedSize > 0 && report.Size != int64(expectedSize) {
+			report.Problems = append(report.Problems, fmt.Sprintf("size mismatch: expected %d bytes", expectedSize))
+		}
+
+		switch {
+		case info.Format == backuparchive.FormatUnrecognized:
+			report.Problems = append(report.Problems, "the file is neither a gzipped tar nor an AES256-GCM encrypted archive; archives encrypted before Portainer 2.19 cannot be verified")
+		case info.Encrypted && password == "":
+			report.Warnings = append(report.Warnings, "the archive is encrypted: pass its password to verify its content")
+		case inspectErr == nil && info.DatabaseEncrypted:
+			report.Warnings = append(report.Warnings, "the Portainer database is encrypted, so the version that created the backup is unknown")
+		case inspectErr == nil && info.PortainerVersion == "":
+			report.Problems = append(report.Problems, "no Portainer database version was found in the archive")
+		}
+
+		if info.PortainerVersion != "" {
+			s.compareBackupVersion(&report)
+		}
+
+		report.Valid = len(report.Problems) == 0
+		return jsonResult(report, "failed to marshal backup verification")
+	}
+}
+
+// compareBackupVersion records the version of the Portainer server and warns when the backup
+// was created by a newer version, which the server cannot restore.
+func (s *PortainerMCPServer) compareBackupVersion(report *backupVerification) {
+	serverVersion, err := s.cli.GetVersion()
+	if err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to get the Portainer server version: %v", err))
+		return
+	}
+	report.ServerVersion = serverVersion
+
+	backup, current := "v"+strings.TrimPrefix(report.PortainerVersion, "v"), "v"+strings.TrimPrefix(serverVersion, "v")
+	if semver.IsValid(backup) && semver.IsValid(current) && semver.Compare(backup, current) > 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("the backup was created by Portainer %s, newer than the server (%s), which cannot restore it", report.PortainerVersion, serverVersion))
+	}
+}
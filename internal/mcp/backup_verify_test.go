@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/backuparchive"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBackupArchive writes a gzipped tar holding a Portainer database of the given version
+// and returns its path and checksum.
+func writeBackupArchive(t *testing.T, version string) (string, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	database := []byte(fmt.Sprintf(`{"SchemaVersion":"%s","MigratorCount":0,"Edition":1}`, version))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "portainer.db", Mode: 0o600, Size: int64(len(database))}))
+	_, err := tw.Write(database)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	archivePath := filepath.Join(t.TempDir(), "portainer-backup.tar.gz")
+	require.NoError(t, os.WriteFile(archivePath, buf.Bytes(), 0o600))
+	sum := sha256.Sum256(buf.Bytes())
+	return archivePath, hex.EncodeToString(sum[:])
+}
+
+// TestHandleVerifyBackup verifies the checks of a local backup archive.
+func TestHandleVerifyBackup(t *testing.T) {
+	archivePath, checksum := writeBackupArchive(t, "2.27.1")
+	newerPath, _ := writeBackupArchive(t, "2.40.0")
+	stat, err := os.Stat(archivePath)
+	require.NoError(t, err)
+	corruptPath := filepath.Join(t.TempDir(), "corrupt.tar.gz")
+	content, err := os.ReadFile(archivePath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(corruptPath, content[:len(content)-12], 0o600))
+
+	tests := []struct {
+		name             string
+		inputParams      map[string]any
+		serverVersion    string
+		expectError      string
+		expectValid      bool
+		expectedProblems int
+		expectedWarnings int
+	}{
+		{
+			name:          "valid archive with matching checksum and size",
+			inputParams:   map[string]any{"path": archivePath, "sha256": checksum, "expectedSize": float64(stat.Size())},
+			serverVersion: "2.31.2",
+			expectValid:   true,
+		},
+		{
+			name:             "checksum and size mismatch",
+			inputParams:      map[string]any{"path": archivePath, "sha256": hex.EncodeToString(make([]byte, 32)), "expectedSize": float64(10)},
+			serverVersion:    "2.31.2",
+			expectedProblems: 2,
+		},
+		{
+			name:             "backup newer than the server",
+			inputParams:      map[string]any{"path": newerPath},
+			serverVersion:    "2.31.2",
+			expectValid:      true,
+			expectedWarnings: 1,
+		},
+		{
+			name:             "corrupted archive",
+			inputParams:      map[string]any{"path": corruptPath},
+			serverVersion:    "2.31.2",
+			expectedProblems: 1,
+		},
+		{
+			name:        "missing archive",
+			inputParams: map[string]any{"path": filepath.Join(t.TempDir(), "missing.tar.gz")},
+			expectError: "does not exist",
+		},
+		{
+			name:        "directory",
+			inputParams: map[string]any{"path": t.TempDir()},
+			expectError: "is a directory",
+		},
+		{
+			name:        "invalid checksum",
+			inputParams: map[string]any{"path": archivePath, "sha256": "abc"},
+			expectError: "sha256 must be a hex-encoded SHA-256 checksum",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.serverVersion != "" {
+				mockClient.On("GetVersion").Return(tt.serverVersion, nil)
+			}
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleVerifyBackup()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var report backupVerification
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+			assert.Equal(t, tt.expectValid, report.Valid)
+			assert.Len(t, report.Problems, tt.expectedProblems)
+			assert.Len(t, report.Warnings, tt.expectedWarnings)
+			assert.Equal(t, backuparchive.FormatTarGz, report.Format)
+			if tt.expectValid {
+				assert.Equal(t, checksum == report.SHA256, tt.inputParams["path"] == archivePath)
+				assert.Equal(t, []string{"portainer.db"}, report.Entries)
+				assert.Equal(t, tt.serverVersion, report.ServerVersion)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/sessionstate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// Operation kinds used to classify tool calls.
+const (
+	operationRead        = "read"
+	operationWrite       = "write"
+	operationDestructive = "destructive"
+)
+
+// toolBudget tracks the write and destructive operations performed by each client
+// session against the configured limits. A limit of zero means unlimited.
+type toolBudget struct {
+	mu             sync.Mutex
+	maxWrites      int
+	maxDestructive int
+	sessions       map[string]*budgetUsage
+	// state persists the usage of each session (nil when session state is not persisted).
+	state *sessionstate.Store
+}
+
+// budgetUsage is the number of operations a session has performed.
+type budgetUsage struct {
+	writes      int
+	destructive int
+}
+
+// newToolBudget creates a budget with the given limits.
+func newToolBudget(maxWrites, maxDestructive int) *toolBudget {
+	return &toolBudget{
+		maxWrites:      maxWrites,
+		maxDestructive: maxDestructive,
+		sessions:       map[string]*budgetUsage{},
+	}
+}
+
+// restore loads the usage of the sessions persisted in state, and persists the usage
+// recorded from now on.
+func (b *toolBudget) restore(state *sessionstate.Store) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = state
+	for id, session := range state.Sessions() {
+		if session.Writes > 0 || session.Destructive > 0 {
+			b.sessions[id] = &budgetUsage{writes: session.Writes, destructive: session.Destructive}
+		}
+	}
+}
+
+// persist saves the usage of a session. A failure is logged and does not fail the call.
+// The caller must hold b.mu.
+func (b *toolBudget) persist(session string, usage *budgetUsage) {
+	if b.state == nil {
+		return
+	}
+	err := b.state.Update(session, func(st *sessionstate.Session) {
+		st.Writes = usage.writes
+		st.Destructive = usage.destructive
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("session", session).Msg("failed to persist tool budget")
+	}
+}
+
+// reserve records an operation of the given kind for a session. It returns an error
+// without recording anything when the operation would exceed a limit. Destructive
+// operations count against both the write and the destructive limit.
+func (b *toolBudget) reserve(session, kind string) error {
+	if kind == operationRead {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	usage, ok := b.sessions[session]
+	if !ok {
+		usage = &budgetUsage{}
+		b.sessions[session] = usage
+	}
+
+	if b.maxWrites > 0 && usage.writes >= b.maxWrites {
+		return fmt.Errorf("budget exhausted: this session already performed %d write operations (limit %d), requires operator reset", usage.writes, b.maxWrites)
+	}
+	if kind == operationDestructive && b.maxDestructive > 0 && usage.destructive >= b.maxDestructive {
+		return fmt.Errorf("budget exhausted: this session already performed %d destructive operations (limit %d), requires operator reset", usage.destructive, b.maxDestructive)
+	}
+
+	usage.writes++
+	if kind == operationDestructive {
+		usage.destructive++
+	}
+	b.persist(session, usage)
+	return nil
+}
+
+// release returns an operation reserved with reserve, for calls that failed.
+func (b *toolBudget) release(session, kind string) {
+	if kind == operationRead {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if usage, ok := b.sessions[session]; ok {
+		usage.writes--
+		if kind == operationDestructive {
+			usage.destructive--
+		}
+		b.persist(session, usage)
+	}
+}
+
+// reset clears the usage of all sessions.
+func (b *toolBudget) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sessions = map[string]*budgetUsage{}
+	if b.state != nil {
+		if err := b.state.ResetBudgets(); err != nil {
+			log.Warn().Err(err).Msg("failed to persist tool budget reset")
+		}
+	}
+}
+
+// ResetBudget clears the write and destructive operation counts of all sessions.
+// It does nothing when no budget is configured.
+func (s *PortainerMCPServer) ResetBudget() {
+	if s.budget == nil {
+		return
+	}
+	s.budget.reset()
+	log.Info().Msg("tool budget reset by operator")
+}
+
+// budgetMiddleware rejects write tool calls once the session has exhausted its budget.
+// Calls that return an error result do not consume the budget.
+func (s *PortainerMCPServer) budgetMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		kind := s.operationKind(request)
+		session := sessionID(ctx)
+
+		if err := s.budget.reserve(session, kind); err != nil {
+			log.Warn().Str("tool", request.Params.Name).Str("session", session).Msg(err.Error())
+			return newToolResultErrorWithCode(ErrorCodeForbidden, err.Error()), nil
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			s.budget.release(session, kind)
+		}
+		return result, err
+	}
+}
+
+// operationKind classifies a tool call as read, write or destructive using the
+// annotations of the granular tool it invokes. Meta-tool calls are resolved through
+// their action, plan previews of compound tools are reads, and applyPlan calls are
+// classified as the call they apply. Unknown tools are treated as writes.
+func (s *PortainerMCPServer) operationKind(request mcp.CallToolRequest) string {
+	name := request.Params.Name
+	readOnly := false
+
+	if action, ok := request.GetArguments()["action"].(string); ok {
+		if a, found := findMetaAction(name, action); found {
+			name = a.tool
+			readOnly = a.readOnly
+		}
+	}
+
+	if plan, _ := request.GetArguments()["plan"].(bool); plan && plannableTools[name] {
+		return operationRead
+	}
+	if name == ToolApplyPlan {
+		if id, ok := request.GetArguments()["planId"].(string); ok {
+			if plan, found := s.plans.peek(id); found {
+				return s.operationKind(plan.request)
+			}
+		}
+	}
+
+	tool, ok := s.toolDefinition(name)
+	if !ok {
+		if readOnly {
+			return operationRead
+		}
+		return operationWrite
+	}
+
+	switch {
+	case tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint:
+		return operationRead
+	case tool.Annotations.DestructiveHint != nil && *tool.Annotations.DestructiveHint:
+		return operationDestructive
+	default:
+		return operationWrite
+	}
+}
+
+// sessionID returns the ID of the client session of a request, or an empty string
+// when the request is not bound to a session.
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// budgetTestTools returns tool definitions covering the three operation kinds.
+func budgetTestTools() map[string]mcp.Tool {
+	annotated := func(name string, readOnly, destructive bool) mcp.Tool {
+		return mcp.NewTool(name, mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			ReadOnlyHint:    boolPtr(readOnly),
+			DestructiveHint: boolPtr(destructive),
+		}))
+	}
+	return map[string]mcp.Tool{
+		ToolListStacks:  annotated(ToolListStacks, true, false),
+		ToolStartStack:  annotated(ToolStartStack, false, false),
+		ToolDeleteStack: annotated(ToolDeleteStack, false, true),
+	}
+}
+
+// TestOperationKind verifies classification of granular and meta-tool calls.
+func TestOperationKind(t *testing.T) {
+	s := &PortainerMCPServer{tools: budgetTestTools()}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{name: "granular read", tool: ToolListStacks, want: operationRead},
+		{name: "granular write", tool: ToolStartStack, want: operationWrite},
+		{name: "granular destructive", tool: ToolDeleteStack, want: operationDestructive},
+		{name: "meta read", tool: "manage_stacks", args: map[string]any{"action": "list_stacks"}, want: operationRead},
+		{name: "meta destructive", tool: "manage_stacks", args: map[string]any{"action": "delete_stack"}, want: operationDestructive},
+		{name: "meta read without definition", tool: "manage_stacks", args: map[string]any{"action": "get_stack"}, want: operationRead},
+		{name: "unknown tool", tool: "unknownTool", want: operationWrite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+			assert.Equal(t, tt.want, s.operationKind(request))
+		})
+	}
+}
+
+// TestBudgetMiddleware verifies that write and destructive limits are enforced per
+// session, that failed calls do not consume the budget, and that reset clears usage.
+func TestBudgetMiddleware(t *testing.T) {
+	s := &PortainerMCPServer{tools: budgetTestTools(), budget: newToolBudget(3, 1)}
+
+	fail := false
+	handler := s.budgetMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if fail {
+			return mcp.NewToolResultError("portainer error"), nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	call := func(tool string) *mcp.CallToolResult {
+		request := CreateMCPRequest(nil)
+		request.Params.Name = tool
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.False(t, call(ToolDeleteStack).IsError, "first destructive call is allowed")
+
+	result := call(ToolDeleteStack)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "budget exhausted: this session already performed 1 destructive operations (limit 1), requires operator reset")
+	assert.Equal(t, ErrorCodeForbidden, errorCode(result))
+
+	fail = true
+	assert.True(t, call(ToolStartStack).IsError)
+	fail = false
+
+	assert.False(t, call(ToolStartStack).IsError)
+	assert.False(t, call(ToolStartStack).IsError)
+
+	result = call(ToolStartStack)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "3 write operations (limit 3)")
+
+	for range 5 {
+		assert.False(t, call(ToolListStacks).IsError, "reads are never limited")
+	}
+
+	s.ResetBudget()
+	assert.False(t, call(ToolDeleteStack).IsError)
+}
+
+// TestToolBudgetSessions verifies that sessions have independent budgets.
+func TestToolBudgetSessions(t *testing.T) {
+	b := newToolBudget(1, 0)
+
+	require.NoError(t, b.reserve("a", operationWrite))
+	assert.Error(t, b.reserve("a", operationDestructive))
+	require.NoError(t, b.reserve("b", operationDestructive))
+
+	b.release("a", operationWrite)
+	assert.NoError(t, b.reserve("a", operationWrite))
+	assert.NoError(t, b.reserve("a", operationRead))
+}
+
+// TestResetBudgetWithoutBudget verifies that resetting is a no-op when no budget is set.
+func TestResetBudgetWithoutBudget(t *testing.T) {
+	s := &PortainerMCPServer{}
+	assert.NotPanics(t, s.ResetBudget)
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultClientLogLevel is the default level from which the server logs are forwarded
+	// to the clients.
+	DefaultClientLogLevel = string(mcp.LoggingLevelWarning)
+	// ClientLogLevelOff disables the log notifications.
+	ClientLogLevelOff = "off"
+
+	// clientLoggerName is the logger reported in the log notifications.
+	clientLoggerName = "portainer-mcp"
+)
+
+// clientLogLevels are the MCP log levels, from the most to the least verbose.
+var clientLogLevels = []mcp.LoggingLevel{
+	mcp.LoggingLevelDebug, mcp.LoggingLevelInfo, mcp.LoggingLevelNotice, mcp.LoggingLevelWarning,
+	mcp.LoggingLevelError, mcp.LoggingLevelCritical, mcp.LoggingLevelAlert, mcp.LoggingLevelEmergency,
+}
+
+// validClientLogLevel reports whether level is an MCP log level or ClientLogLevelOff.
+func validClientLogLevel(level string) bool {
+	return level == ClientLogLevelOff || slices.Contains(clientLogLevels, mcp.LoggingLevel(level))
+}
+
+// clientLogs sends log notifications to the clients: the startup warnings, and the
+// retries and failures of the tool calls of each session, only to that session. The
+// server log is not forwarded, as its entries concern every session, some by ID. Each
+// session starts at the configured level, which its client may change with
+// logging/setLevel.
+type clientLogs struct {
+	srv   *server.MCPServer
+	level mcp.LoggingLevel
+	// startup are the warnings logged before any client connected, such as a failed
+	// version check, sent to every client once its session is initialized.
+	startup []map[string]any
+}
+
+// newClientLogs returns the sender of the log notifications, or nil when level is
+// ClientLogLevelOff or empty.
+func newClientLogs(level string) *clientLogs {
+	if level == "" || level == ClientLogLevelOff {
+		return nil
+	}
+	return &clientLogs{level: mcp.LoggingLevel(level)}
+}
+
+// addHooks sets the log level of each session once it is initialized and sends it the
+// startup warnings.
+func (c *clientLogs) addHooks(hooks *server.Hooks) {
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		session := server.ClientSessionFromContext(ctx)
+		logging, ok := session.(server.SessionWithLogging)
+		if !ok {
+			return
+		}
+		// The session resets its level when it is initialized.
+		logging.SetLogLevel(c.level)
+
+		for _, data := range c.startup {
+			_ = c.srv.SendLogMessageToSpecificClient(session.SessionID(), mcp.NewLoggingMessageNotification(mcp.LoggingLevelWarning, clientLoggerName, data))
+		}
+	})
+}
+
+// logToClient sends a log notification to the session of a tool call, when its level
+// reaches the log level of the session. It does nothing when the log notifications are
+// disabled. Delivery failures are ignored, as logging them would loop.
+func (s *PortainerMCPServer) logToClient(ctx context.Context, level mcp.LoggingLevel, data map[string]any) {
+	if s.clientLogs == nil {
+		return
+	}
+	_ = s.srv.SendLogMessageToClient(ctx, mcp.NewLoggingMessageNotification(level, clientLoggerName, data))
+}
+
+// versionCheckWarning returns the warning of a version check run in warn mode that could
+// not read the Portainer version or found it unsupported, or nil.
+func (s *PortainerMCPServer) versionCheckWarning() map[string]any {
+	if s.versionCheck != VersionCheckWarn {
+		return nil
+	}
+	if s.portainerVersion == "" {
+		return map[string]any{zerolog.MessageFieldName: "failed to get Portainer server version, some tools may fail"}
+	}
+	if !isSupportedVersion(s.portainerVersion) {
+		return map[string]any{
+			zerolog.MessageFieldName: "unsupported Portainer server version, some tools may fail",
+			"version":                s.portainerVersion,
+			"supported":              supportedVersionRange(),
+		}
+	}
+	return nil
+}
+
+// clientLogMiddleware sends an error notification to the client of a failed tool call,
+// with its error code, retries and environment, which the text of the result omits. It is
+// registered outside the error code and metadata middlewares so that it sees them.
+func (s *PortainerMCPServer) clientLogMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || !result.IsError {
+			return result, err
+		}
+
+		data := map[string]any{
+			zerolog.MessageFieldName: fmt.Sprintf("tool call %s failed", requestToolName(request)),
+			"tool":                   request.Params.Name,
+			"error":                  resultText(result),
+			"code":                   resultErrorCode(result),
+		}
+		if action, ok := request.GetArguments()["action"].(string); ok {
+			data["action"] = action
+		}
+		for _, key := range []string{metaRetries, metaEnvironmentID, metaDurationMs} {
+			if value := resultMeta(result, key); value != nil {
+				data[key] = value
+			}
+		}
+		s.logToClient(ctx, mcp.LoggingLevelError, data)
+		return result, nil
+	}
+}
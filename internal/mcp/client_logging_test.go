@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logSession is a client session recording the notifications it receives.
+type logSession struct {
+	id            string
+	level         mcp.LoggingLevel
+	initialized   bool
+	notifications chan mcp.JSONRPCNotification
+}
+
+func newLogSession(id string) *logSession {
+	return &logSession{id: id, level: mcp.LoggingLevelError, notifications: make(chan mcp.JSONRPCNotification, 10)}
+}
+
+func (s *logSession) Initialize() {
+	s.initialized = true
+	s.level = mcp.LoggingLevelError
+}
+func (s *logSession) Initialized() bool                                   { return s.initialized }
+func (s *logSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s *logSession) SessionID() string                                   { return s.id }
+func (s *logSession) SetLogLevel(level mcp.LoggingLevel)                  { s.level = level }
+func (s *logSession) GetLogLevel() mcp.LoggingLevel                       { return s.level }
+
+// received returns the notifications received by the session so far.
+func (s *logSession) received() []mcp.JSONRPCNotification {
+	var notifications []mcp.JSONRPCNotification
+	for {
+		select {
+		case notification := <-s.notifications:
+			notifications = append(notifications, notification)
+		default:
+			return notifications
+		}
+	}
+}
+
+// initializeLogSession registers a session with the server and initializes it.
+func initializeLogSession(t *testing.T, s *PortainerMCPServer, id string) (*logSession, context.Context) {
+	t.Helper()
+	session := newLogSession(id)
+	ctx := s.srv.WithContext(context.Background(), session)
+	require.NoError(t, s.srv.RegisterSession(ctx, session))
+	s.srv.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+	return session, ctx
+}
+
+// TestClientLogs verifies that the startup warnings reach every initialized session, and
+// the notifications of a call only its session, at its log level.
+func TestClientLogs(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetVersion").Return("", errors.New("connection refused"))
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(mockClient), WithVersionCheck(VersionCheckWarn), WithClientLogLevel(string(mcp.LoggingLevelInfo)))
+	require.NoError(t, err)
+
+	session, _ := initializeLogSession(t, s, "a")
+	assert.Equal(t, mcp.LoggingLevelInfo, session.level, "the configured level replaces the default one")
+
+	startup := session.received()
+	require.Len(t, startup, 1)
+	assert.Equal(t, "notifications/message", startup[0].Method)
+	assert.Equal(t, mcp.LoggingLevelWarning, startup[0].Params.AdditionalFields["level"])
+	assert.Equal(t, "failed to get Portainer server version, some tools may fail", startup[0].Params.AdditionalFields["data"].(map[string]any)["message"])
+
+	other, _ := initializeLogSession(t, s, "b")
+	other.received()
+
+	log.Warn().Str("session", "b").Msg("server log entries are not forwarded")
+	assert.Empty(t, session.received())
+	assert.Empty(t, other.received())
+
+	ctx := s.srv.WithContext(context.Background(), session)
+	s.logToClient(ctx, mcp.LoggingLevelDebug, map[string]any{"message": "below the session level"})
+	s.logToClient(ctx, mcp.LoggingLevelWarning, map[string]any{"message": "retrying a call failing upstream", "tool": "listStacks"})
+
+	notifications := session.received()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, mcp.LoggingLevelWarning, notifications[0].Params.AdditionalFields["level"])
+	assert.Equal(t, clientLoggerName, notifications[0].Params.AdditionalFields["logger"])
+	assert.Equal(t, map[string]any{"tool": "listStacks", "message": "retrying a call failing upstream"}, notifications[0].Params.AdditionalFields["data"])
+	assert.Empty(t, other.received(), "the notifications of a call only reach its session")
+}
+
+// TestClientLogMiddleware verifies that the client of a failed tool call is sent its error
+// code and metadata.
+func TestClientLogMiddleware(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithClientLogLevel(string(mcp.LoggingLevelWarning)))
+	require.NoError(t, err)
+	_, ctx := initializeLogSession(t, s, "a")
+
+	handler := s.clientLogMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.GetArguments()["fail"] == true {
+			result := mcp.NewToolResultError("failed to list stacks: connection refused")
+			setResultMeta(result, metaRetries, 2)
+			return result, nil
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	request := CreateMCPRequest(map[string]any{"fail": false})
+	request.Params.Name = ToolListStacks
+	_, err = handler(ctx, request)
+	require.NoError(t, err)
+	session := server.ClientSessionFromContext(ctx).(*logSession)
+	assert.Empty(t, session.received())
+
+	request = CreateMCPRequest(map[string]any{"fail": true})
+	request.Params.Name = ToolListStacks
+	result, err := handler(ctx, request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	notifications := session.received()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, mcp.LoggingLevelError, notifications[0].Params.AdditionalFields["level"])
+	data := notifications[0].Params.AdditionalFields["data"].(map[string]any)
+	assert.Equal(t, "tool call listStacks failed", data["message"])
+	assert.Equal(t, "failed to list stacks: connection refused", data["error"])
+	assert.Equal(t, ErrorCodeUpstreamUnavailable, data["code"])
+	assert.Equal(t, 2, data[metaRetries])
+}
+
+// TestWithClientLogLevel verifies the validation of the client log level.
+func TestWithClientLogLevel(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithClientLogLevel(ClientLogLevelOff))
+	require.NoError(t, err)
+	assert.Nil(t, s.clientLogs)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithClientLogLevel("verbose"))
+	assert.ErrorContains(t, err, `invalid client log level "verbose"`)
+}
@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// clientRequestIDPrefix prefixes the IDs of the requests sent by the server to the client,
+// so that their responses can be told apart from client requests.
+const clientRequestIDPrefix = "portainer-mcp-"
+
+// clientInputBuffer is the number of client messages read ahead of mcp-go.
+const clientInputBuffer = 64
+
+// clientResponse is the response of the client to a request of the server
+type clientResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// clientConn carries the stdio transport between mcp-go and the client, and lets the
+// server send its own requests to the client, such as roots/list, which mcp-go cannot
+// send: its only request to the client is sampling. Writes of mcp-go and of the server are
+// serialized line by line, and the responses to the server requests are taken out of the
+// input before mcp-go reads it.
+type clientConn struct {
+	out    io.Writer
+	nextID atomic.Int64
+	// lastReadAt is the time, in Unix nanoseconds, of the last message read from the client.
+	lastReadAt atomic.Int64
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string]chan clientResponse
+}
+
+// newClientConn creates a connection writing to out
+func newClientConn(out io.Writer) *clientConn {
+	c := &clientConn{out: out, pending: map[string]chan clientResponse{}}
+	c.lastReadAt.Store(time.Now().UnixNano())
+	return c
+}
+
+// lastRead returns the time of the last message read from the client, or of the creation
+// of the connection before the first message
+func (c *clientConn) lastRead() time.Time {
+	return time.Unix(0, c.lastReadAt.Load())
+}
+
+// Write writes a message to the client. mcp-go writes each message, with its trailing
+// newline, in a single call.
+func (c *clientConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.out.Write(p)
+}
+
+// input returns the reader given to mcp-go in place of in. The input is read eagerly so
+// that the responses to the server requests are delivered while mcp-go handles a call,
+// since the stdio server of mcp-go handles one message at a time.
+func (c *clientConn) input(in io.Reader) io.Reader {
+	lines := make(chan []byte, clientInputBuffer)
+	input := &clientInput{lines: lines}
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				c.lastReadAt.Store(time.Now().UnixNano())
+				if !c.dispatch(line) {
+					lines <- line
+				}
+			}
+			if err != nil {
+				input.err = err
+				close(lines)
+				return
+			}
+		}
+	}()
+	return input
+}
+
+// request sends a request to the client and decodes the result of its response into
+// result, which may be nil
+func (c *clientConn) request(ctx context.Context, method string, params any, result any) error {
+	id := clientRequestIDPrefix + strconv.FormatInt(c.nextID.Add(1), 10)
+	request := map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "id": id, "method": method}
+	if params != nil {
+		request["params"] = params
+	}
+	message, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	responses := make(chan clientResponse, 1)
+	c.mu.Lock()
+	c.pending[id] = responses
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if _, err := c.Write(append(message, '\n')); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("no response to %s request: %w", method, ctx.Err())
+	case response := <-responses:
+		if response.Error != nil {
+			return fmt.Errorf("%s request failed: %s (code %d)", method, response.Error.Message, response.Error.Code)
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("failed to parse %s response: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// dispatch delivers a response to the pending request it answers. It returns false when
+// the line is not a response to a server request.
+func (c *clientConn) dispatch(line []byte) bool {
+	if !strings.Contains(string(line), clientRequestIDPrefix) {
+		return false
+	}
+
+	var message struct {
+		ID     any     `json:"id"`
+		Method *string `json:"method"`
+		clientResponse
+	}
+	if err := json.Unmarshal(line, &message); err != nil || message.Method != nil {
+		return false
+	}
+	id, ok := message.ID.(string)
+	if !ok || !strings.HasPrefix(id, clientRequestIDPrefix) {
+		return false
+	}
+
+	c.mu.Lock()
+	responses, found := c.pending[id]
+	c.mu.Unlock()
+	if found {
+		responses <- message.clientResponse
+	}
+	return true
+}
+
+// clientInput is the input of mcp-go, without the responses to the server requests
+type clientInput struct {
+	lines <-chan []byte
+	// err is the error that ended the input, set before lines is closed.
+	err error
+	buf []byte
+}
+
+// Read returns the messages of the client that are not responses to server requests
+func (r *clientInput) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		line, ok := <-r.lines
+		if !ok {
+			return 0, r.err
+		}
+		r.buf = line
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
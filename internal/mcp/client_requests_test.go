@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientConnRequest verifies that the server requests are written to the client, that
+// their responses are delivered to the caller without reaching mcp-go, and that the other
+// client messages are passed through.
+func TestClientConnRequest(t *testing.T) {
+	outReader, outWriter := io.Pipe()
+	inReader, inWriter := io.Pipe()
+	conn := newClientConn(outWriter)
+	input := bufio.NewReader(conn.input(inReader))
+
+	type response struct {
+		result map[string]any
+		err    error
+	}
+	responses := make(chan response, 1)
+	go func() {
+		var result map[string]any
+		err := conn.request(context.Background(), "roots/list", nil, &result)
+		responses <- response{result: result, err: err}
+	}()
+
+	line, err := bufio.NewReader(outReader).ReadBytes('\n')
+	require.NoError(t, err)
+	var request map[string]any
+	require.NoError(t, json.Unmarshal(line, &request))
+	assert.Equal(t, "roots/list", request["method"])
+	assert.NotContains(t, request, "params")
+
+	go func() {
+		_, _ = io.WriteString(inWriter, `{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n")
+		_, _ = io.WriteString(inWriter, `{"jsonrpc":"2.0","id":"`+request["id"].(string)+`","result":{"roots":[]}}`+"\n")
+		_ = inWriter.Close()
+	}()
+
+	select {
+	case r := <-responses:
+		require.NoError(t, r.err)
+		assert.Equal(t, map[string]any{"roots": []any{}}, r.result)
+	case <-time.After(time.Second):
+		t.Fatal("no response delivered")
+	}
+
+	forwarded, err := input.ReadString('\n')
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, forwarded)
+	_, err = input.ReadString('\n')
+	assert.Equal(t, io.EOF, err)
+}
+
+// TestClientConnRequestError verifies that error responses and missing responses are
+// reported to the caller.
+func TestClientConnRequestError(t *testing.T) {
+	conn := newClientConn(io.Discard)
+
+	t.Run("error response", func(t *testing.T) {
+		errs := make(chan error, 1)
+		go func() {
+			errs <- conn.request(context.Background(), "roots/list", nil, nil)
+		}()
+
+		// The request is pending once its ID has been allocated and registered.
+		require.Eventually(t, func() bool {
+			conn.mu.Lock()
+			defer conn.mu.Unlock()
+			return len(conn.pending) == 1
+		}, time.Second, time.Millisecond)
+		id := clientRequestIDPrefix + "1"
+		assert.True(t, conn.dispatch([]byte(`{"jsonrpc":"2.0","id":"`+id+`","error":{"code":-32601,"message":"Method not found"}}`)))
+
+		err := <-errs
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Method not found (code -32601)")
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := conn.request(ctx, "roots/list", nil, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("client request with a prefixed ID", func(t *testing.T) {
+		assert.False(t, conn.dispatch([]byte(`{"jsonrpc":"2.0","id":"`+clientRequestIDPrefix+`9","method":"ping"}`)))
+	})
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/format"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// metaContentTypes is the _meta key listing the MIME type and language of every text
+// content of a result, so that clients can render it with syntax highlighting.
+const metaContentTypes = "contentTypes"
+
+// mimeTypeShellScript is the MIME type of the edge job scripts.
+const mimeTypeShellScript = "text/x-shellscript"
+
+// contentLanguages is the syntax highlighting language of the MIME types
+var contentLanguages = map[string]string{
+	format.MIMETypeJSON: "json",
+	format.MIMETypeYAML: "yaml",
+	mimeTypeShellScript: "shell",
+}
+
+// fileContentTypes is the MIME type of the files returned as is by the tools: Compose
+// files, templates and kubeconfigs are YAML, and edge jobs are shell scripts.
+var fileContentTypes = map[string]string{
+	ToolGetStackFile:          format.MIMETypeYAML,
+	ToolInspectStackFile:      format.MIMETypeYAML,
+	ToolGetCustomTemplateFile: format.MIMETypeYAML,
+	ToolGetAppTemplateFile:    format.MIMETypeYAML,
+	ToolGetKubernetesConfig:   format.MIMETypeYAML,
+	ToolGetEdgeJobFile:        mimeTypeShellScript,
+}
+
+// contentType is the entry of a text content in the contentTypes _meta of a result.
+type contentType struct {
+	// Index is the position of the content in the result.
+	Index    int    `json:"index"`
+	MIMEType string `json:"mimeType"`
+	Language string `json:"language,omitempty"`
+}
+
+// newContentType returns the entry of a text content.
+func newContentType(index int, mimeType string) contentType {
+	return contentType{Index: index, MIMEType: mimeType, Language: contentLanguages[mimeType]}
+}
+
+// textMIMEType returns the MIME type of the text content of a tool result that was not
+// reformatted: JSON objects and arrays, the files of the tools returning them, and plain
+// text otherwise.
+func textMIMEType(toolName, text string) string {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if json.Valid([]byte(trimmed)) {
+			return format.MIMETypeJSON
+		}
+	}
+	if mimeType, ok := fileContentTypes[toolName]; ok {
+		return mimeType
+	}
+	return format.MIMETypePlainText
+}
+
+// requestToolName returns the name of the granular tool invoked by a call, resolving the
+// meta-tool calls through their action.
+func requestToolName(request mcp.CallToolRequest) string {
+	name := request.Params.Name
+	if action, ok := request.GetArguments()["action"].(string); ok {
+		if a, found := findMetaAction(name, action); found {
+			name = a.tool
+		}
+	}
+	return name
+}
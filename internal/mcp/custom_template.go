@@ -6,6 +6,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -136,11 +137,15 @@ func (s *PortainerMCPServer) HandleDeleteCustomTemplate() server.ToolHandlerFunc
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		entry := s.captureDeletion(journal.KindCustomTemplate, id, func() (journal.Entry, error) {
+			return s.captureCustomTemplate(id)
+		})
+
 		err = s.cli.DeleteCustomTemplate(id)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to delete custom template", err), nil
 		}
 
-		return mcp.NewToolResultText("Custom template deleted successfully"), nil
+		return s.deletionResult(ToolDeleteCustomTemplate, "Custom template deleted successfully", entry), nil
 	}
 }
@@ -0,0 +1,286 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// deletedStack is the journal record of a deleted regular stack.
+type deletedStack struct {
+	Stack models.RegularStack `json:"stack"`
+	File  string              `json:"file"`
+}
+
+// deletedCustomTemplate is the journal record of a deleted custom template.
+type deletedCustomTemplate struct {
+	Template models.CustomTemplate `json:"template"`
+	File     string                `json:"file"`
+}
+
+// deletedEdgeJob is the journal record of a deleted edge job.
+type deletedEdgeJob struct {
+	Job  models.EdgeJob `json:"job"`
+	File string         `json:"file"`
+}
+
+// captureDeletion captures a resource that is about to be deleted. It returns nil when
+// the journal is disabled or the resource cannot be captured; capture failures are logged
+// and never prevent the deletion.
+func (s *PortainerMCPServer) captureDeletion(kind string, id int, capture func() (journal.Entry, error)) *journal.Entry {
+	if s.deleteJournal == nil {
+		return nil
+	}
+
+	entry, err := capture()
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Int("id", id).Msg("failed to capture resource for the delete journal")
+		return nil
+	}
+	entry.Kind = kind
+	entry.ResourceID = id
+	return &entry
+}
+
+// deletionResult records a captured resource once its deletion succeeded and returns
+// message followed by the undo recipe. Journal failures are logged and only reported in
+// the result, as the deletion itself already succeeded.
+func (s *PortainerMCPServer) deletionResult(tool, message string, entry *journal.Entry) *mcp.CallToolResult {
+	if s.deleteJournal == nil {
+		return mcp.NewToolResultText(message)
+	}
+
+	const unavailable = "\nThe resource could not be captured before deletion; no undo recipe is available."
+	if entry == nil {
+		return mcp.NewToolResultText(message + unavailable)
+	}
+
+	entry.Tool = tool
+	recorded, err := s.deleteJournal.Record(*entry)
+	if err != nil {
+		log.Warn().Err(err).Str("tool", tool).Msg("failed to record delete journal entry")
+		return mcp.NewToolResultText(message + unavailable)
+	}
+
+	undo, err := json.Marshal(recorded.Undo)
+	if err != nil {
+		return mcp.NewToolResultText(message + unavailable)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("%s\nUndo recipe (delete journal entry %d): %s", message, recorded.ID, undo))
+}
+
+// captureStack captures a regular stack and its file before it is deleted.
+func (s *PortainerMCPServer) captureStack(id, endpointID int, removeVolumes bool) (journal.Entry, error) {
+	stack, err := s.cli.InspectStack(id)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to inspect stack: %w", err)
+	}
+	file, err := s.cli.InspectStackFile(id)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get stack file: %w", err)
+	}
+
+	resource, err := json.Marshal(deletedStack{Stack: stack, File: file})
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to marshal stack: %w", err)
+	}
+
+	notes := []string{"the stack environment variables are not captured; pass them again with 'env'"}
+	if stack.Git != nil {
+		notes = append(notes, fmt.Sprintf("the stack was deployed from git repository %s; the recipe recreates it from its last deployed file, without git updates", stack.Git.URL))
+	}
+	if removeVolumes {
+		notes = append(notes, "the stack volumes were removed and cannot be restored")
+	}
+
+	return journal.Entry{
+		Name:     stack.Name,
+		Resource: resource,
+		Undo: journal.Undo{
+			Tool:      ToolDeployStackAndWait,
+			Arguments: map[string]any{"environmentId": endpointID, "name": stack.Name, "file": file},
+			Notes:     notes,
+		},
+	}, nil
+}
+
+// captureEnvironmentTag captures an environment tag before it is deleted.
+func (s *PortainerMCPServer) captureEnvironmentTag(id int) (journal.Entry, error) {
+	tags, err := s.cli.GetEnvironmentTags()
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get environment tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if tag.ID != id {
+			continue
+		}
+
+		resource, err := json.Marshal(tag)
+		if err != nil {
+			return journal.Entry{}, fmt.Errorf("failed to marshal environment tag: %w", err)
+		}
+
+		var notes []string
+		if len(tag.EnvironmentIds) > 0 {
+			notes = append(notes, fmt.Sprintf("assign the new tag again to environments %v with 'updateEnvironmentTags'", tag.EnvironmentIds))
+		}
+
+		return journal.Entry{
+			Name:     tag.Name,
+			Resource: resource,
+			Undo: journal.Undo{
+				Tool:      ToolCreateEnvironmentTag,
+				Arguments: map[string]any{"name": tag.Name},
+				Notes:     notes,
+			},
+		}, nil
+	}
+	return journal.Entry{}, fmt.Errorf("environment tag %d not found", id)
+}
+
+// captureWebhook captures a webhook before it is deleted.
+func (s *PortainerMCPServer) captureWebhook(id int) (journal.Entry, error) {
+	webhooks, err := s.cli.GetWebhooks()
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.ID != id {
+			continue
+		}
+
+		resource, err := json.Marshal(webhook)
+		if err != nil {
+			return journal.Entry{}, fmt.Errorf("failed to marshal webhook: %w", err)
+		}
+
+		notes := []string{"the new webhook gets a new token and URL; update the callers of the deleted webhook"}
+		if webhook.RegistryID != 0 {
+			notes = append(notes, fmt.Sprintf("the webhook used registry %d, which 'createWebhook' does not set", webhook.RegistryID))
+		}
+
+		return journal.Entry{
+			Resource: resource,
+			Undo: journal.Undo{
+				Tool:      ToolCreateWebhook,
+				Arguments: map[string]any{"resourceId": webhook.ResourceID, "endpointId": webhook.EndpointID, "webhookType": webhook.Type},
+				Notes:     notes,
+			},
+		}, nil
+	}
+	return journal.Entry{}, fmt.Errorf("webhook %d not found", id)
+}
+
+// captureCustomTemplate captures a custom template and its file before it is deleted.
+func (s *PortainerMCPServer) captureCustomTemplate(id int) (journal.Entry, error) {
+	template, err := s.cli.GetCustomTemplate(id)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get custom template: %w", err)
+	}
+	file, err := s.cli.GetCustomTemplateFile(id)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get custom template file: %w", err)
+	}
+
+	resource, err := json.Marshal(deletedCustomTemplate{Template: template, File: file})
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to marshal custom template: %w", err)
+	}
+
+	args := map[string]any{
+		"title":       template.Title,
+		"description": template.Description,
+		"fileContent": file,
+		"platform":    template.Platform,
+		"type":        template.Type,
+	}
+	if template.Note != "" {
+		args["note"] = template.Note
+	}
+	if template.Logo != "" {
+		args["logo"] = template.Logo
+	}
+
+	return journal.Entry{
+		Name:     template.Title,
+		Resource: resource,
+		Undo:     journal.Undo{Tool: ToolCreateCustomTemplate, Arguments: args},
+	}, nil
+}
+
+// captureEdgeJob captures an edge job and its script before it is deleted.
+func (s *PortainerMCPServer) captureEdgeJob(id int) (journal.Entry, error) {
+	job, err := s.cli.GetEdgeJob(id)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get edge job: %w", err)
+	}
+	file, err := s.cli.GetEdgeJobFile(id)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to get edge job file: %w", err)
+	}
+
+	resource, err := json.Marshal(deletedEdgeJob{Job: job, File: file})
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("failed to marshal edge job: %w", err)
+	}
+
+	args := map[string]any{
+		"name":           job.Name,
+		"cronExpression": job.CronExpression,
+		"fileContent":    file,
+		"recurring":      job.Recurring,
+	}
+	if len(job.Endpoints) > 0 {
+		args["endpoints"] = job.Endpoints
+	}
+	if len(job.EdgeGroups) > 0 {
+		args["edgeGroups"] = job.EdgeGroups
+	}
+
+	return journal.Entry{
+		Name:     job.Name,
+		Resource: resource,
+		Undo: journal.Undo{
+			Tool:      ToolCreateEdgeJob,
+			Arguments: args,
+			Notes:     []string{"the results of the previous runs of the job are not restored"},
+		},
+	}, nil
+}
+
+// HandleGetDeleteJournal returns an MCP tool handler that lists the resources deleted
+// through the server, or returns one journal entry with the captured resource.
+func (s *PortainerMCPServer) HandleGetDeleteJournal() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.deleteJournal == nil {
+			return mcp.NewToolResultError("the delete journal is disabled (start the server with -delete-journal-size greater than 0)"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		if id == 0 {
+			return jsonResult(s.deleteJournal.List(), "failed to marshal delete journal")
+		}
+
+		entry, found := s.deleteJournal.Get(id)
+		if !found {
+			return mcp.NewToolResultError(fmt.Sprintf("entry %d is not in the delete journal", id)), nil
+		}
+
+		return jsonResult(entry, "failed to marshal delete journal entry")
+	}
+}
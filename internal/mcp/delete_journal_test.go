@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newJournalServer returns a server with an in-memory delete journal.
+func newJournalServer(t *testing.T, cli *MockPortainerClient) *PortainerMCPServer {
+	t.Helper()
+	j, err := journal.New("", 10)
+	require.NoError(t, err)
+	return &PortainerMCPServer{cli: cli, deleteJournal: j}
+}
+
+// parseUndoRecipe splits a delete result into its message and undo recipe.
+func parseUndoRecipe(t *testing.T, result *mcp.CallToolResult) (string, journal.Undo) {
+	t.Helper()
+	require.False(t, result.IsError)
+	message, recipe, found := strings.Cut(result.Content[0].(mcp.TextContent).Text, "\n")
+	require.True(t, found, "the result must contain an undo recipe")
+
+	_, recipe, found = strings.Cut(recipe, ": ")
+	require.True(t, found)
+	var undo journal.Undo
+	require.NoError(t, json.Unmarshal([]byte(recipe), &undo))
+	return message, undo
+}
+
+// TestDeleteJournalUndoRecipes verifies the journal entry and undo recipe of every
+// journaled delete tool.
+func TestDeleteJournalUndoRecipes(t *testing.T) {
+	stackFile := "services:\n  web:\n    image: nginx\n"
+
+	tests := []struct {
+		name     string
+		setup    func(m *MockPortainerClient)
+		handler  func(*PortainerMCPServer) server.ToolHandlerFunc
+		params   map[string]any
+		kind     string
+		message  string
+		undoTool string
+		undoArgs map[string]any
+		notes    int
+	}{
+		{
+			name: "stack",
+			setup: func(m *MockPortainerClient) {
+				m.On("InspectStack", 4).Return(models.RegularStack{ID: 4, Name: "web", EndpointID: 2, Git: &models.StackGitConfig{URL: "https://github.com/acme/web.git"}}, nil)
+				m.On("InspectStackFile", 4).Return(stackFile, nil)
+				m.On("DeleteStack", 4, 2, true).Return(nil)
+			},
+			handler:  (*PortainerMCPServer).HandleDeleteStack,
+			params:   map[string]any{"id": float64(4), "environmentId": float64(2), "removeVolumes": true},
+			kind:     journal.KindStack,
+			message:  "Stack deleted successfully",
+			undoTool: ToolDeployStackAndWait,
+			undoArgs: map[string]any{"environmentId": float64(2), "name": "web", "file": stackFile},
+			notes:    3,
+		},
+		{
+			name: "environment tag",
+			setup: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 1, Name: "dev"}, {ID: 2, Name: "prod", EnvironmentIds: []int{3, 5}}}, nil)
+				m.On("DeleteEnvironmentTag", 2).Return(nil)
+			},
+			handler:  (*PortainerMCPServer).HandleDeleteEnvironmentTag,
+			params:   map[string]any{"id": float64(2)},
+			kind:     journal.KindTag,
+			message:  "Environment tag deleted successfully",
+			undoTool: ToolCreateEnvironmentTag,
+			undoArgs: map[string]any{"name": "prod"},
+			notes:    1,
+		},
+		{
+			name: "webhook",
+			setup: func(m *MockPortainerClient) {
+				m.On("GetWebhooks").Return([]models.Webhook{{ID: 7, EndpointID: 1, ResourceID: "svc", Token: "abc", Type: 1}}, nil)
+				m.On("DeleteWebhook", 7).Return(nil)
+			},
+			handler:  (*PortainerMCPServer).HandleDeleteWebhook,
+			params:   map[string]any{"id": float64(7)},
+			kind:     journal.KindWebhook,
+			message:  "Webhook deleted successfully",
+			undoTool: ToolCreateWebhook,
+			undoArgs: map[string]any{"resourceId": "svc", "endpointId": float64(1), "webhookType": float64(1)},
+			notes:    1,
+		},
+		{
+			name: "custom template",
+			setup: func(m *MockPortainerClient) {
+				m.On("GetCustomTemplate", 9).Return(models.CustomTemplate{ID: 9, Title: "nginx", Description: "Web server", Platform: 1, Type: 2}, nil)
+				m.On("GetCustomTemplateFile", 9).Return(stackFile, nil)
+				m.On("DeleteCustomTemplate", 9).Return(nil)
+			},
+			handler:  (*PortainerMCPServer).HandleDeleteCustomTemplate,
+			params:   map[string]any{"id": float64(9)},
+			kind:     journal.KindCustomTemplate,
+			message:  "Custom template deleted successfully",
+			undoTool: ToolCreateCustomTemplate,
+			undoArgs: map[string]any{"title": "nginx", "description": "Web server", "fileContent": stackFile, "platform": float64(1), "type": float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setup(mockClient)
+			server := newJournalServer(t, mockClient)
+
+			result, err := tt.handler(server)(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+
+			message, undo := parseUndoRecipe(t, result)
+			assert.Equal(t, tt.message, message)
+			assert.Equal(t, tt.undoTool, undo.Tool)
+			assert.Equal(t, tt.undoArgs, undo.Arguments)
+			assert.Len(t, undo.Notes, tt.notes)
+
+			entries := server.deleteJournal.List()
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.kind, entries[0].Kind)
+			assert.Equal(t, int(tt.params["id"].(float64)), entries[0].ResourceID)
+
+			entry, found := server.deleteJournal.Get(entries[0].ID)
+			require.True(t, found)
+			assert.NotEmpty(t, entry.Resource)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestDeleteJournalCaptureFailure verifies that a failed capture does not prevent the
+// deletion and that failed deletions are not journaled.
+func TestDeleteJournalCaptureFailure(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetWebhooks").Return([]models.Webhook{}, nil)
+	mockClient.On("DeleteWebhook", 3).Return(nil)
+	mockClient.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 4, Name: "prod"}}, nil)
+	mockClient.On("DeleteEnvironmentTag", 4).Return(fmt.Errorf("tag in use"))
+	server := newJournalServer(t, mockClient)
+
+	result, err := server.HandleDeleteWebhook()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(3)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "no undo recipe is available")
+
+	result, err = server.HandleDeleteEnvironmentTag()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(4)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	assert.Empty(t, server.deleteJournal.List())
+	mockClient.AssertExpectations(t)
+}
+
+// TestHandleGetDeleteJournal verifies listing and retrieving journal entries.
+func TestHandleGetDeleteJournal(t *testing.T) {
+	server := newJournalServer(t, &MockPortainerClient{})
+	_, err := server.deleteJournal.Record(journal.Entry{
+		Tool:       ToolDeleteEnvironmentTag,
+		Kind:       journal.KindTag,
+		ResourceID: 2,
+		Name:       "prod",
+		Resource:   json.RawMessage(`{"id":2,"name":"prod"}`),
+		Undo:       journal.Undo{Tool: ToolCreateEnvironmentTag, Arguments: map[string]any{"name": "prod"}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		params      map[string]any
+		expectError bool
+		check       func(t *testing.T, text string)
+	}{
+		{
+			name:   "list entries",
+			params: map[string]any{},
+			check: func(t *testing.T, text string) {
+				var entries []journal.Entry
+				require.NoError(t, json.Unmarshal([]byte(text), &entries))
+				require.Len(t, entries, 1)
+				assert.Empty(t, entries[0].Resource)
+				assert.Equal(t, ToolCreateEnvironmentTag, entries[0].Undo.Tool)
+			},
+		},
+		{
+			name:   "get entry",
+			params: map[string]any{"id": float64(1)},
+			check: func(t *testing.T, text string) {
+				var entry journal.Entry
+				require.NoError(t, json.Unmarshal([]byte(text), &entry))
+				assert.JSONEq(t, `{"id":2,"name":"prod"}`, string(entry.Resource))
+			},
+		},
+		{
+			name:        "unknown entry",
+			params:      map[string]any{"id": float64(9)},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.HandleGetDeleteJournal()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+			require.False(t, result.IsError)
+			tt.check(t, result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+
+	t.Run("journal disabled", func(t *testing.T) {
+		disabled := &PortainerMCPServer{cli: &MockPortainerClient{}}
+		result, err := disabled.HandleGetDeleteJournal()(context.Background(), CreateMCPRequest(map[string]any{}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
@@ -2,34 +2,81 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
-	"io"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/dockerutil"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/proxyroutes"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
+const (
+	// defaultContainerLogTail is the number of log lines returned when no tail is given.
+	defaultContainerLogTail = 100
+	// defaultDockerEventWindow is how far back events are listed when no since is given.
+	defaultDockerEventWindow = time.Hour
+	// defaultContainerTopArgs are the ps arguments used when none are given. Unlike the Docker
+	// default (-ef), they include the CPU and memory usage of each process.
+	defaultContainerTopArgs = "aux"
+	// defaultContainerFileMode is the permission bits of written files when no mode is given.
+	defaultContainerFileMode = 0o644
+)
+
 // AddDockerProxyFeatures registers the Docker proxy management tools on the MCP server.
 func (s *PortainerMCPServer) AddDockerProxyFeatures() {
 	s.addToolIfExists(ToolGetDockerDashboard, s.HandleGetDockerDashboard())
+	s.addToolIfExists(ToolListContainers, s.HandleListContainers())
+	s.addToolIfExists(ToolGetContainerLogs, s.HandleGetContainerLogs())
+	s.addToolIfExists(ToolGetContainerTop, s.HandleGetContainerTop())
+	s.addToolIfExists(ToolListDockerEvents, s.HandleListDockerEvents())
+	s.addToolIfExists(ToolDockerProxyGet, s.HandleDockerProxyGet())
+	s.addToolIfExists(ToolGetFleetContainerUsage, s.HandleGetFleetContainerUsage())
+	s.addToolIfExists(ToolSuggestCleanup, s.HandleSuggestCleanup())
+	s.addToolIfExists(ToolAuditRestartPolicies, s.HandleAuditRestartPolicies())
+	s.addToolIfExists(ToolGetPortReport, s.HandleGetPortReport())
+	s.addToolIfExists(ToolCheckImageUpdates, s.HandleCheckImageUpdates())
+	s.addToolIfExists(ToolReadContainerFile, s.HandleReadContainerFile())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolDockerProxy, s.HandleDockerProxy())
+		s.addToolIfExists(ToolWriteContainerFile, s.HandleWriteContainerFile())
+	}
+}
+
+// checkDockerProxyRequest validates a Docker proxy request against the known API routes
+// and the configured proxy rules.
+func (s *PortainerMCPServer) checkDockerProxyRequest(method, apiPath string) error {
+	if !s.skipProxyValidation {
+		if err := proxyroutes.ValidateDocker(method, apiPath); err != nil {
+			return err
+		}
+	}
+	if s.proxyPolicy != nil {
+		return s.proxyPolicy.CheckDocker(method, apiPath)
 	}
+	return nil
 }
 
 // HandleDockerProxy proxies arbitrary Docker API requests to a Portainer environment.
 //
 // SECURITY NOTE: This handler allows the caller to invoke any Docker Engine API endpoint
 // (e.g. /containers, /exec, /volumes, /networks, /swarm) on the target environment.
-// There is no allowlist restricting which API paths are permitted. The only validation
-// performed is that the path starts with "/" and the HTTP method is one of the supported
-// set. Access control relies entirely on the Portainer API token permissions and the
-// read-only mode flag. Operators should be aware that this effectively grants full Docker
-// API access to whoever holds the MCP server's Portainer token.
+// Unless the operator configures proxy rules (WithProxyRules), there is no allowlist
+// restricting which API paths are permitted; the path and method are only checked against
+// the known Docker Engine API routes to catch typos early. Access control otherwise relies
+// on the Portainer API token permissions and the read-only mode flag. Operators should be
+// aware that this effectively grants full Docker API access to whoever holds the MCP
+// server's Portainer token.
 func (s *PortainerMCPServer) HandleDockerProxy() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		parser := toolgen.NewParameterParser(request)
@@ -57,21 +104,16 @@ func (s *PortainerMCPServer) HandleDockerProxy() server.ToolHandlerFunc {
 		if !strings.HasPrefix(dockerAPIPath, "/") {
 			return mcp.NewToolResultError("dockerAPIPath must start with a leading slash"), nil
 		}
-
-		queryParams, err := parser.GetArrayOfObjects("queryParams", false)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid queryParams parameter", err), nil
+		if err := s.checkDockerProxyRequest(method, dockerAPIPath); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		queryParamsMap, err := parseKeyValueMap(queryParams)
+
+		queryParamsMap, err := parser.GetKeyValueMap("queryParams", false)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid query params", err), nil
 		}
 
-		headers, err := parser.GetArrayOfObjects("headers", false)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid headers parameter", err), nil
-		}
-		headersMap, err := parseKeyValueMap(headers)
+		headersMap, err := parser.GetKeyValueMap("headers", false)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid headers", err), nil
 		}
@@ -99,7 +141,7 @@ func (s *PortainerMCPServer) HandleDockerProxy() server.ToolHandlerFunc {
 		}
 		defer response.Body.Close()
 
-		responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxProxyResponseSize))
+		responseBody, err := readProxyResponse(ctx, response.Body)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to read Docker API response", err), nil
 		}
@@ -108,6 +150,69 @@ func (s *PortainerMCPServer) HandleDockerProxy() server.ToolHandlerFunc {
 	}
 }
 
+// HandleDockerProxyGet returns an MCP tool handler that proxies GET requests to the
+// Docker Engine API of an environment and strips verbose fields from the response.
+// It never modifies the environment, so it is available in read-only mode.
+func (s *PortainerMCPServer) HandleDockerProxyGet() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		dockerAPIPath, err := parser.GetString("dockerAPIPath", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid dockerAPIPath parameter", err), nil
+		}
+		if !strings.HasPrefix(dockerAPIPath, "/") {
+			return mcp.NewToolResultError("dockerAPIPath must start with a leading slash"), nil
+		}
+		if err := s.checkDockerProxyRequest("GET", dockerAPIPath); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		queryParamsMap, err := parser.GetKeyValueMap("queryParams", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid query params", err), nil
+		}
+
+		keep, err := parser.GetArrayOfStrings("keep", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid keep parameter", err), nil
+		}
+
+		opts := models.DockerProxyRequestOptions{
+			EnvironmentID: environmentId,
+			Path:          dockerAPIPath,
+			Method:        "GET",
+			QueryParams:   queryParamsMap,
+		}
+
+		response, err := s.cli.ProxyDockerRequest(opts)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to send Docker API request", err), nil
+		}
+		defer response.Body.Close()
+
+		responseBody, err := readProxyResponse(ctx, response.Body)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read Docker API response", err), nil
+		}
+
+		stripped, err := dockerutil.StripResponse(responseBody, dockerutil.DefaultStripFields, keep)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to process Docker API response", err), nil
+		}
+
+		return mcp.NewToolResultText(string(stripped)), nil
+	}
+}
+
 // HandleGetDockerDashboard returns an MCP tool handler that retrieves docker dashboard.
 func (s *PortainerMCPServer) HandleGetDockerDashboard() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -129,3 +234,594 @@ func (s *PortainerMCPServer) HandleGetDockerDashboard() server.ToolHandlerFunc {
 		return jsonResult(dashboard, "failed to marshal docker dashboard")
 	}
 }
+
+// HandleListContainers returns an MCP tool handler that lists the containers of a Docker environment.
+func (s *PortainerMCPServer) HandleListContainers() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		all, err := parser.GetBoolean("all", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid all parameter", err), nil
+		}
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		status, err := parser.GetString("status", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid status parameter", err), nil
+		}
+
+		label, err := parser.GetString("label", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid label parameter", err), nil
+		}
+
+		selectors, err := parser.GetArrayOfStrings("labels", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid labels parameter", err), nil
+		}
+		// Docker only returns the containers matching every label filter.
+		var labels []string
+		labels = append(labels, selectors...)
+		if label != "" {
+			labels = append(labels, label)
+		}
+		for _, selector := range labels {
+			if key, _, _ := strings.Cut(selector, "="); strings.TrimSpace(key) == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid label selector %q: the label key cannot be empty", selector)), nil
+			}
+		}
+
+		composeProject, err := parser.GetString("composeProject", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid composeProject parameter", err), nil
+		}
+		if composeProject != "" {
+			labels = append(labels, composeProjectLabel(composeProject))
+		}
+
+		containers, err := s.cli.GetDockerContainers(environmentId, models.DockerContainerListOptions{
+			All:    all,
+			Name:   name,
+			Status: status,
+			Labels: labels,
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list containers", err), nil
+		}
+
+		return jsonResult(containers, "failed to marshal containers")
+	}
+}
+
+// HandleGetContainerLogs returns an MCP tool handler that reads the logs of a container,
+// optionally restricted to a since/until time range.
+func (s *PortainerMCPServer) HandleGetContainerLogs() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		containerId, err := parser.GetString("containerId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid containerId parameter", err), nil
+		}
+		if strings.TrimSpace(containerId) == "" {
+			return mcp.NewToolResultError("containerId cannot be empty"), nil
+		}
+
+		since, until, err := parser.GetTimeRange()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid time range", err), nil
+		}
+
+		tail, err := parser.GetInt("tail", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tail parameter", err), nil
+		}
+		if tail < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("tail must not be negative, got %d", tail)), nil
+		}
+		if tail == 0 {
+			tail = defaultContainerLogTail
+		}
+
+		timestamps, err := parser.GetBoolean("timestamps", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid timestamps parameter", err), nil
+		}
+
+		logs, err := s.cli.GetDockerContainerLogs(environmentId, containerId, models.DockerContainerLogOptions{
+			Since:      since,
+			Until:      until,
+			Tail:       tail,
+			Timestamps: timestamps,
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get container logs", err), nil
+		}
+		// A tail of as many lines as requested may have left older lines out.
+		if logs != "" && strings.Count(strings.TrimSuffix(logs, "\n"), "\n")+1 >= tail {
+			markTruncated(ctx)
+		}
+
+		return mcp.NewToolResultText(logs), nil
+	}
+}
+
+// HandleGetContainerTop returns an MCP tool handler that lists the processes running in a
+// container. When ps reports a %CPU column, the busiest processes come first.
+func (s *PortainerMCPServer) HandleGetContainerTop() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		containerId, err := parser.GetString("containerId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid containerId parameter", err), nil
+		}
+		if strings.TrimSpace(containerId) == "" {
+			return mcp.NewToolResultError("containerId cannot be empty"), nil
+		}
+
+		psArgs, err := parser.GetString("psArgs", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid psArgs parameter", err), nil
+		}
+		if psArgs == "" {
+			psArgs = defaultContainerTopArgs
+		}
+
+		top, err := s.cli.GetDockerContainerTop(environmentId, containerId, psArgs)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list container processes", err), nil
+		}
+
+		sortProcessesByCPU(top.Processes)
+		return jsonResult(top, "failed to marshal container processes")
+	}
+}
+
+// sortProcessesByCPU sorts processes by decreasing %CPU. Processes are left in ps order
+// when the column is missing.
+func sortProcessesByCPU(processes []map[string]string) {
+	cpu := func(process map[string]string) float64 {
+		value, _ := strconv.ParseFloat(process["%CPU"], 64)
+		return value
+	}
+	sort.SliceStable(processes, func(i, j int) bool {
+		return cpu(processes[i]) > cpu(processes[j])
+	})
+}
+
+// HandleListDockerEvents returns an MCP tool handler that lists the Docker events of an
+// environment within a since/until time range. The range defaults to the last hour.
+func (s *PortainerMCPServer) HandleListDockerEvents() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		since, until, err := parser.GetTimeRange()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid time range", err), nil
+		}
+		if until.IsZero() {
+			until = time.Now()
+		}
+		if since.IsZero() {
+			since = until.Add(-defaultDockerEventWindow)
+		}
+		if since.After(until) {
+			return mcp.NewToolResultError("since must not be after until"), nil
+		}
+
+		eventType, err := parser.GetString("type", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid type parameter", err), nil
+		}
+
+		events, err := s.cli.GetDockerEvents(environmentId, models.DockerEventListOptions{
+			Since: since,
+			Until: until,
+			Type:  eventType,
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list docker events", err), nil
+		}
+
+		return jsonResult(events, "failed to marshal docker events")
+	}
+}
+
+// containerFile is the result of HandleReadContainerFile. Content is the file content as
+// text when it is valid UTF-8, and base64 encoded otherwise.
+type containerFile struct {
+	models.DockerContainerFile
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+// HandleReadContainerFile returns an MCP tool handler that reads a file from the filesystem
+// of a container. The Docker archive endpoint is subject to the proxy rules.
+func (s *PortainerMCPServer) HandleReadContainerFile() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, containerId, filePath, err := parseContainerFileParams(parser)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := s.checkDockerProxyRequest("GET", "/containers/"+containerId+"/archive"); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		file, err := s.cli.GetDockerContainerFile(environmentId, containerId, filePath)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read container file", err), nil
+		}
+
+		result := containerFile{DockerContainerFile: file, Encoding: "utf-8", Content: string(file.Content)}
+		if !utf8.Valid(file.Content) {
+			result.Encoding = "base64"
+			result.Content = base64.StdEncoding.EncodeToString(file.Content)
+		}
+		return jsonResult(result, "failed to marshal container file")
+	}
+}
+
+// HandleWriteContainerFile returns an MCP tool handler that writes a file to the filesystem
+// of a container, replacing it if it exists. The Docker archive endpoint is subject to the
+// proxy rules.
+func (s *PortainerMCPServer) HandleWriteContainerFile() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, containerId, filePath, err := parseContainerFileParams(parser)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		content, err := parser.GetString("content", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid content parameter", err), nil
+		}
+
+		encoding, err := parser.GetEnum("encoding", false, "utf-8", "base64")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid encoding parameter", err), nil
+		}
+		data := []byte(content)
+		if encoding == "base64" {
+			data, err = base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid base64 content", err), nil
+			}
+		}
+
+		modeValue, err := parser.GetString("mode", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid mode parameter", err), nil
+		}
+		mode := int64(defaultContainerFileMode)
+		if modeValue != "" {
+			mode, err = strconv.ParseInt(modeValue, 8, 64)
+			if err != nil || mode < 0 || mode > 0o7777 {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid mode: %s (must be octal permission bits such as 0644)", modeValue)), nil
+			}
+		}
+
+		if err := s.checkDockerProxyRequest("PUT", "/containers/"+containerId+"/archive"); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if err := s.cli.PutDockerContainerFile(environmentId, containerId, filePath, data, mode); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to write container file", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Wrote %d bytes to %s in container %s", len(data), filePath, containerId)), nil
+	}
+}
+
+// parseContainerFileParams parses the environmentId, containerId and path parameters shared
+// by the container file tools. The path must be an absolute file path.
+func parseContainerFileParams(parser *toolgen.ParameterParser) (int, string, string, error) {
+	environmentId, err := parser.GetInt("environmentId", true)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid environmentId parameter: %w", err)
+	}
+	if err := validatePositiveID("environmentId", environmentId); err != nil {
+		return 0, "", "", err
+	}
+
+	containerId, err := parser.GetString("containerId", true)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid containerId parameter: %w", err)
+	}
+	if strings.TrimSpace(containerId) == "" || strings.Contains(containerId, "/") {
+		return 0, "", "", fmt.Errorf("invalid containerId: %q", containerId)
+	}
+
+	filePath, err := parser.GetString("path", true)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid path parameter: %w", err)
+	}
+	if !path.IsAbs(filePath) || path.Clean(filePath) == "/" || strings.HasSuffix(filePath, "/") {
+		return 0, "", "", fmt.Errorf("path must be an absolute file path, got %q", filePath)
+	}
+
+	return environmentId, containerId, path.Clean(filePath), nil
+}
+
+const (
+	// fleetConcurrency is the number of Docker API requests issued in parallel by fleet-wide tools.
+	fleetConcurrency = 8
+	// defaultFleetUsageLimit is the number of top consumers returned when no limit is given.
+	defaultFleetUsageLimit = 10
+	// maxFleetUsageLimit caps the number of top consumers returned.
+	maxFleetUsageLimit = 100
+)
+
+// fleetContainerUsage is the resource usage of one container of the fleet.
+type fleetContainerUsage struct {
+	EnvironmentID   int    `json:"environment_id"`
+	EnvironmentName string `json:"environment_name"`
+	Image           string `json:"image"`
+	models.DockerContainerUsage
+}
+
+// fleetEnvironmentUsage is the total resource usage of the running containers of an environment.
+type fleetEnvironmentUsage struct {
+	EnvironmentID   int     `json:"environment_id"`
+	EnvironmentName string  `json:"environment_name"`
+	Containers      int     `json:"containers"`
+	CPUPercent      float64 `json:"cpu_percent"`
+	MemoryUsage     uint64  `json:"memory_usage_bytes"`
+}
+
+// fleetUsageError records an environment or container that could not be sampled.
+type fleetUsageError struct {
+	EnvironmentID int    `json:"environment_id"`
+	ContainerID   string `json:"container_id,omitempty"`
+	Error         string `json:"error"`
+}
+
+// fleetUsageReport is the result of HandleGetFleetContainerUsage.
+type fleetUsageReport struct {
+	SortBy              string                  `json:"sort_by"`
+	EnvironmentsScanned int                     `json:"environments_scanned"`
+	ContainersScanned   int                     `json:"containers_scanned"`
+	Top                 []fleetContainerUsage   `json:"top"`
+	Environments        []fleetEnvironmentUsage `json:"environments"`
+	Errors              []fleetUsageError       `json:"errors,omitempty"`
+}
+
+// HandleGetFleetContainerUsage returns an MCP tool handler that samples the CPU and memory
+// usage of the running containers of several Docker environments concurrently and returns
+// the top consumers, along with per-environment totals.
+func (s *PortainerMCPServer) HandleGetFleetContainerUsage() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, id := range environmentIds {
+			if err := validatePositiveID("environmentIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		sortBy, err := parser.GetEnum("sortBy", false, "cpu", "memory")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid sortBy parameter", err), nil
+		}
+		if sortBy == "" {
+			sortBy = "memory"
+		}
+
+		limit, err := parser.GetInt("limit", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if limit == 0 {
+			limit = defaultFleetUsageLimit
+		}
+		if limit < 0 || limit > maxFleetUsageLimit {
+			return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d, got %d", maxFleetUsageLimit, limit)), nil
+		}
+
+		environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+
+		report := fleetUsageReport{SortBy: sortBy, Top: []fleetContainerUsage{}}
+		targets := selectFleetEnvironments(environments, environmentIds, &report.Errors)
+		report.EnvironmentsScanned = len(targets)
+
+		// List the running containers of every environment, then sample each container.
+		containers := make([][]models.DockerContainer, len(targets))
+		listErrs := make([]error, len(targets))
+		runConcurrently(ctx, len(targets), func(i int) {
+			containers[i], listErrs[i] = s.cli.GetDockerContainers(targets[i].ID, models.DockerContainerListOptions{})
+		})
+
+		var samples []fleetContainerUsage
+		for i, env := range targets {
+			if listErrs[i] != nil {
+				report.Errors = append(report.Errors, fleetUsageError{EnvironmentID: env.ID, Error: listErrs[i].Error()})
+				continue
+			}
+			for _, c := range containers[i] {
+				samples = append(samples, fleetContainerUsage{
+					EnvironmentID:        env.ID,
+					EnvironmentName:      env.Name,
+					Image:                c.Image,
+					DockerContainerUsage: models.DockerContainerUsage{ContainerID: c.ID, Name: c.Name},
+				})
+			}
+		}
+
+		statsErrs := make([]error, len(samples))
+		runConcurrently(ctx, len(samples), func(i int) {
+			usage, err := s.cli.GetDockerContainerStats(samples[i].EnvironmentID, samples[i].ContainerID)
+			if err != nil {
+				statsErrs[i] = err
+				return
+			}
+			if usage.Name == "" {
+				usage.Name = samples[i].Name
+			}
+			usage.ContainerID = samples[i].ContainerID
+			samples[i].DockerContainerUsage = usage
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("fleet resource usage scan interrupted", err), nil
+		}
+
+		totals := map[int]*fleetEnvironmentUsage{}
+		for _, env := range targets {
+			totals[env.ID] = &fleetEnvironmentUsage{EnvironmentID: env.ID, EnvironmentName: env.Name}
+		}
+		for i, sample := range samples {
+			if statsErrs[i] != nil {
+				report.Errors = append(report.Errors, fleetUsageError{EnvironmentID: sample.EnvironmentID, ContainerID: sample.ContainerID, Error: statsErrs[i].Error()})
+				continue
+			}
+			total := totals[sample.EnvironmentID]
+			total.Containers++
+			total.CPUPercent += sample.CPUPercent
+			total.MemoryUsage += sample.MemoryUsage
+			report.Top = append(report.Top, sample)
+		}
+		report.ContainersScanned = len(report.Top)
+
+		sortFleetUsage(report.Top, sortBy)
+		if len(report.Top) > limit {
+			report.Top = report.Top[:limit]
+		}
+
+		for _, env := range targets {
+			report.Environments = append(report.Environments, *totals[env.ID])
+		}
+		sort.SliceStable(report.Environments, func(i, j int) bool {
+			a, b := report.Environments[i], report.Environments[j]
+			if sortBy == "cpu" {
+				return a.CPUPercent > b.CPUPercent
+			}
+			return a.MemoryUsage > b.MemoryUsage
+		})
+
+		return jsonResult(report, "failed to marshal fleet resource usage")
+	}
+}
+
+// selectFleetEnvironments returns the environments to scan: the requested ones, or every
+// active Docker environment when none is requested. Requested environments that do not
+// exist or are not Docker environments are recorded in errs.
+func selectFleetEnvironments(environments []models.Environment, ids []int, errs *[]fleetUsageError) []models.Environment {
+	if len(ids) == 0 {
+		var targets []models.Environment
+		for _, env := range environments {
+			if isDockerEnvironment(env) && env.Status == models.EnvironmentStatusActive {
+				targets = append(targets, env)
+			}
+		}
+		return targets
+	}
+
+	byID := make(map[int]models.Environment, len(environments))
+	for _, env := range environments {
+		byID[env.ID] = env
+	}
+
+	var targets []models.Environment
+	for _, id := range ids {
+		env, ok := byID[id]
+		switch {
+		case !ok:
+			*errs = append(*errs, fleetUsageError{EnvironmentID: id, Error: "environment not found"})
+		case !isDockerEnvironment(env):
+			*errs = append(*errs, fleetUsageError{EnvironmentID: id, Error: fmt.Sprintf("environment type %s is not a Docker environment", env.Type)})
+		default:
+			targets = append(targets, env)
+		}
+	}
+	return targets
+}
+
+// isDockerEnvironment reports whether an environment runs the Docker engine.
+func isDockerEnvironment(env models.Environment) bool {
+	switch env.Type {
+	case models.EnvironmentTypeDockerLocal, models.EnvironmentTypeDockerAgent, models.EnvironmentTypeDockerEdgeAgent:
+		return true
+	}
+	return false
+}
+
+// sortFleetUsage sorts container samples by decreasing CPU or memory usage.
+func sortFleetUsage(samples []fleetContainerUsage, sortBy string) {
+	sort.SliceStable(samples, func(i, j int) bool {
+		a, b := samples[i], samples[j]
+		if sortBy == "cpu" {
+			return a.CPUPercent > b.CPUPercent
+		}
+		return a.MemoryUsage > b.MemoryUsage
+	})
+}
+
+// runConcurrently calls fn for every index in [0, n) with at most fleetConcurrency calls
+// in flight, and waits for them to return. No new call starts once ctx is done.
+func runConcurrently(ctx context.Context, n int, fn func(i int)) {
+	sem := make(chan struct{}, fleetConcurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+}
@@ -0,0 +1,314 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultCleanupOlderThanDays is the minimum age of the stopped containers suggested
+	// for removal when no olderThanDays is given.
+	defaultCleanupOlderThanDays = 7
+	// maxCleanupOlderThanDays caps the olderThanDays parameter.
+	maxCleanupOlderThanDays = 3650
+	// maxCleanupItems is the number of objects listed in each cleanup action, the largest first.
+	maxCleanupItems = 20
+)
+
+// Kinds of the actions of a cleanup plan.
+const (
+	cleanupKindDanglingImages    = "dangling_images"
+	cleanupKindStoppedContainers = "stopped_containers"
+	cleanupKindUnusedVolumes     = "unused_volumes"
+	cleanupKindBuildCache        = "build_cache"
+)
+
+// Risks of the actions of a cleanup plan.
+const (
+	cleanupRiskLow    = "low"
+	cleanupRiskMedium = "medium"
+	cleanupRiskHigh   = "high"
+)
+
+// cleanupRiskRank orders the risks, the safest first.
+var cleanupRiskRank = map[string]int{cleanupRiskLow: 0, cleanupRiskMedium: 1, cleanupRiskHigh: 2}
+
+// cleanupAction is one step of a cleanup plan: a kind of object to remove from an environment.
+type cleanupAction struct {
+	// Priority is the position of the action in the plan, 1 being the first to run.
+	Priority         int    `json:"priority"`
+	EnvironmentID    int    `json:"environment_id"`
+	EnvironmentName  string `json:"environment_name"`
+	Kind             string `json:"kind"`
+	Count            int    `json:"count"`
+	ReclaimableBytes int64  `json:"reclaimable_bytes"`
+	ReclaimableSize  string `json:"reclaimable_size"`
+	// UnknownSizeCount is the number of objects whose size Docker does not report, such as
+	// volumes of non-local drivers. They are not counted in ReclaimableBytes.
+	UnknownSizeCount int `json:"unknown_size_count,omitempty"`
+	// Items lists the largest objects, at most maxCleanupItems of them.
+	Items          []string `json:"items"`
+	ItemsTruncated bool     `json:"items_truncated,omitempty"`
+	// Command is the Docker CLI command that performs the action on the environment.
+	Command string `json:"command"`
+	Risk    string `json:"risk"`
+	Note    string `json:"note"`
+}
+
+// cleanupEnvironmentUsage is the disk usage of an environment.
+type cleanupEnvironmentUsage struct {
+	EnvironmentID    int    `json:"environment_id"`
+	EnvironmentName  string `json:"environment_name"`
+	ImagesBytes      int64  `json:"images_bytes"`
+	ContainersBytes  int64  `json:"containers_bytes"`
+	VolumesBytes     int64  `json:"volumes_bytes"`
+	BuildCacheBytes  int64  `json:"build_cache_bytes"`
+	ReclaimableBytes int64  `json:"reclaimable_bytes"`
+	ReclaimableSize  string `json:"reclaimable_size"`
+}
+
+// cleanupPlan is the result of HandleSuggestCleanup.
+type cleanupPlan struct {
+	OlderThanDays         int                       `json:"older_than_days"`
+	EnvironmentsScanned   int                       `json:"environments_scanned"`
+	TotalReclaimableBytes int64                     `json:"total_reclaimable_bytes"`
+	TotalReclaimableSize  string                    `json:"total_reclaimable_size"`
+	Actions               []cleanupAction           `json:"actions"`
+	Environments          []cleanupEnvironmentUsage `json:"environments"`
+	Errors                []fleetUsageError         `json:"errors,omitempty"`
+}
+
+// HandleSuggestCleanup returns an MCP tool handler that reads the disk usage of several
+// Docker environments concurrently and suggests a cleanup plan: dangling images, stopped
+// containers older than a given number of days, unused volumes and build cache, ordered by
+// reclaimable space. Nothing is removed.
+func (s *PortainerMCPServer) HandleSuggestCleanup() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, id := range environmentIds {
+			if err := validatePositiveID("environmentIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		olderThanDays := defaultCleanupOlderThanDays
+		if _, ok := request.GetArguments()["olderThanDays"]; ok {
+			olderThanDays, err = parser.GetInt("olderThanDays", false)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid olderThanDays parameter", err), nil
+			}
+		}
+		if olderThanDays < 0 || olderThanDays > maxCleanupOlderThanDays {
+			return mcp.NewToolResultError(fmt.Sprintf("olderThanDays must be between 0 and %d, got %d", maxCleanupOlderThanDays, olderThanDays)), nil
+		}
+
+		environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+
+		plan := cleanupPlan{OlderThanDays: olderThanDays, Actions: []cleanupAction{}, Environments: []cleanupEnvironmentUsage{}}
+		targets := selectFleetEnvironments(environments, environmentIds, &plan.Errors)
+		plan.EnvironmentsScanned = len(targets)
+
+		usages := make([]models.DockerDiskUsage, len(targets))
+		usageErrs := make([]error, len(targets))
+		runConcurrently(ctx, len(targets), func(i int) {
+			usages[i], usageErrs[i] = s.cli.GetDockerDiskUsage(targets[i].ID)
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("disk usage scan interrupted", err), nil
+		}
+
+		cutoff := time.Now().AddDate(0, 0, -olderThanDays)
+		for i, env := range targets {
+			if usageErrs[i] != nil {
+				plan.Errors = append(plan.Errors, fleetUsageError{EnvironmentID: env.ID, Error: usageErrs[i].Error()})
+				continue
+			}
+			actions, usage := buildCleanupActions(env, usages[i], cutoff, olderThanDays)
+			plan.Actions = append(plan.Actions, actions...)
+			plan.Environments = append(plan.Environments, usage)
+			plan.TotalReclaimableBytes += usage.ReclaimableBytes
+		}
+		plan.TotalReclaimableSize = units.HumanSize(float64(plan.TotalReclaimableBytes))
+
+		prioritizeCleanupActions(plan.Actions)
+		sort.SliceStable(plan.Environments, func(i, j int) bool {
+			return plan.Environments[i].ReclaimableBytes > plan.Environments[j].ReclaimableBytes
+		})
+
+		return jsonResult(plan, "failed to marshal cleanup plan")
+	}
+}
+
+// cleanupItem is an object that a cleanup action removes.
+type cleanupItem struct {
+	label string
+	size  int64
+}
+
+// buildCleanupActions returns the cleanup actions of an environment and its disk usage
+// totals. Stopped containers are suggested when they were created before cutoff, which is
+// the semantics of the until filter of docker container prune.
+func buildCleanupActions(env models.Environment, usage models.DockerDiskUsage, cutoff time.Time, olderThanDays int) ([]cleanupAction, cleanupEnvironmentUsage) {
+	totals := cleanupEnvironmentUsage{
+		EnvironmentID:   env.ID,
+		EnvironmentName: env.Name,
+		ImagesBytes:     usage.LayersSize,
+		BuildCacheBytes: usage.BuildCacheSize,
+	}
+
+	var images []cleanupItem
+	for _, img := range usage.Images {
+		if !isDanglingImage(img) || img.Containers > 0 {
+			continue
+		}
+		size := img.Size
+		if img.SharedSize > 0 {
+			size -= img.SharedSize
+		}
+		images = append(images, cleanupItem{label: fmt.Sprintf("%s (%s)", shortImageID(img.ID), units.HumanSize(float64(size))), size: size})
+	}
+
+	var containers []cleanupItem
+	for _, c := range usage.Containers {
+		totals.ContainersBytes += c.SizeRw
+		if !isStoppedContainerState(c.State) || !time.Unix(c.Created, 0).Before(cutoff) {
+			continue
+		}
+		containers = append(containers, cleanupItem{
+			label: fmt.Sprintf("%s (%s, %s, %s)", c.Name, c.Image, c.State, units.HumanSize(float64(c.SizeRw))),
+			size:  c.SizeRw,
+		})
+	}
+
+	var volumes []cleanupItem
+	for _, v := range usage.Volumes {
+		if v.Size > 0 {
+			totals.VolumesBytes += v.Size
+		}
+		if v.RefCount != 0 {
+			continue
+		}
+		size := "size unknown"
+		if v.Size >= 0 {
+			size = units.HumanSize(float64(v.Size))
+		}
+		volumes = append(volumes, cleanupItem{label: fmt.Sprintf("%s (%s)", v.Name, size), size: v.Size})
+	}
+
+	var actions []cleanupAction
+	add := func(action cleanupAction, items []cleanupItem) {
+		if len(items) == 0 {
+			return
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].size > items[j].size })
+		action.EnvironmentID = env.ID
+		action.EnvironmentName = env.Name
+		action.Count = len(items)
+		action.Items = []string{}
+		for i, item := range items {
+			if item.size < 0 {
+				action.UnknownSizeCount++
+			} else {
+				action.ReclaimableBytes += item.size
+			}
+			if i < maxCleanupItems {
+				action.Items = append(action.Items, item.label)
+			}
+		}
+		action.ItemsTruncated = len(items) > maxCleanupItems
+		action.ReclaimableSize = units.HumanSize(float64(action.ReclaimableBytes))
+		totals.ReclaimableBytes += action.ReclaimableBytes
+		actions = append(actions, action)
+	}
+
+	add(cleanupAction{
+		Kind:    cleanupKindDanglingImages,
+		Command: "docker image prune",
+		Risk:    cleanupRiskLow,
+		Note:    "Untagged images not used by any container, usually left behind by rebuilds and pulls of newer tags.",
+	}, images)
+	add(cleanupAction{
+		Kind:    cleanupKindStoppedContainers,
+		Command: fmt.Sprintf("docker container prune --filter until=%dh", olderThanDays*24),
+		Risk:    cleanupRiskMedium,
+		Note:    fmt.Sprintf("Stopped containers created more than %d days ago. Their writable layer and logs are lost, and stacks recreate them on the next deployment.", olderThanDays),
+	}, containers)
+	add(cleanupAction{
+		Kind:    cleanupKindUnusedVolumes,
+		Command: "docker volume prune --all",
+		Risk:    cleanupRiskHigh,
+		Note:    "Volumes not mounted by any container, including stopped ones. Removing them deletes their data permanently: check for backups and for stacks that are only scaled down.",
+	}, volumes)
+	if usage.BuildCacheReclaimable > 0 {
+		add(cleanupAction{
+			Kind:    cleanupKindBuildCache,
+			Command: "docker builder prune",
+			Risk:    cleanupRiskLow,
+			Note:    "Build cache records neither in use nor shared. Later builds are slower until the cache is rebuilt.",
+		}, []cleanupItem{{label: "build cache", size: usage.BuildCacheReclaimable}})
+	}
+
+	totals.ReclaimableSize = units.HumanSize(float64(totals.ReclaimableBytes))
+	return actions, totals
+}
+
+// prioritizeCleanupActions orders cleanup actions by decreasing reclaimable space, the
+// safest first when they free the same space, and numbers them.
+func prioritizeCleanupActions(actions []cleanupAction) {
+	sort.SliceStable(actions, func(i, j int) bool {
+		a, b := actions[i], actions[j]
+		if a.ReclaimableBytes != b.ReclaimableBytes {
+			return a.ReclaimableBytes > b.ReclaimableBytes
+		}
+		return cleanupRiskRank[a.Risk] < cleanupRiskRank[b.Risk]
+	})
+	for i := range actions {
+		actions[i].Priority = i + 1
+	}
+}
+
+// isDanglingImage reports whether an image has no tag.
+func isDanglingImage(img models.DockerDiskUsageImage) bool {
+	for _, tag := range img.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+// isStoppedContainerState reports whether a container in the given state is removed by
+// docker container prune.
+func isStoppedContainerState(state string) bool {
+	switch state {
+	case "exited", "created", "dead":
+		return true
+	}
+	return false
+}
+
+// shortImageID returns the 12-character form of an image ID, as shown by the Docker CLI.
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
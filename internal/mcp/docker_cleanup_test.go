@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildCleanupActions verifies the objects suggested for removal and the disk usage
+// totals of an environment.
+func TestBuildCleanupActions(t *testing.T) {
+	now := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	env := models.Environment{ID: 1, Name: "prod"}
+	usage := models.DockerDiskUsage{
+		LayersSize: 5000,
+		Images: []models.DockerDiskUsageImage{
+			{ID: "sha256:0123456789abcdef", RepoTags: []string{"<none>:<none>"}, Size: 300, SharedSize: 100},
+			{ID: "sha256:fedcba9876543210", Size: 50, SharedSize: -1},
+			{ID: "sha256:aaaa", RepoTags: nil, Size: 900, Containers: 1},
+			{ID: "sha256:bbbb", RepoTags: []string{"nginx:1"}, Size: 700},
+		},
+		Containers: []models.DockerDiskUsageContainer{
+			{Name: "old", Image: "api:1", State: "exited", Created: now.AddDate(0, 0, -10).Unix(), SizeRw: 40},
+			{Name: "recent", Image: "api:2", State: "exited", Created: now.AddDate(0, 0, -2).Unix(), SizeRw: 80},
+			{Name: "web", Image: "nginx:1", State: "running", Created: now.AddDate(0, 0, -30).Unix(), SizeRw: 10},
+		},
+		Volumes: []models.DockerDiskUsageVolume{
+			{Name: "orphan", RefCount: 0, Size: 1000},
+			{Name: "nfs", RefCount: 0, Size: -1},
+			{Name: "data", RefCount: 1, Size: 2000},
+		},
+		BuildCacheSize:        60,
+		BuildCacheReclaimable: 20,
+	}
+
+	actions, totals := buildCleanupActions(env, usage, now.AddDate(0, 0, -7), 7)
+	require.Len(t, actions, 4)
+
+	assert.Equal(t, cleanupKindDanglingImages, actions[0].Kind)
+	assert.Equal(t, 2, actions[0].Count, "tagged images and images used by a container are kept")
+	assert.Equal(t, int64(250), actions[0].ReclaimableBytes, "shared layers are not reclaimed")
+	assert.Equal(t, []string{"0123456789ab (200B)", "fedcba987654 (50B)"}, actions[0].Items)
+
+	assert.Equal(t, cleanupKindStoppedContainers, actions[1].Kind)
+	assert.Equal(t, []string{"old (api:1, exited, 40B)"}, actions[1].Items)
+	assert.Equal(t, "docker container prune --filter until=168h", actions[1].Command)
+
+	assert.Equal(t, cleanupKindUnusedVolumes, actions[2].Kind)
+	assert.Equal(t, int64(1000), actions[2].ReclaimableBytes)
+	assert.Equal(t, 1, actions[2].UnknownSizeCount)
+	assert.Equal(t, []string{"orphan (1kB)", "nfs (size unknown)"}, actions[2].Items)
+	assert.Equal(t, cleanupRiskHigh, actions[2].Risk)
+
+	assert.Equal(t, cleanupKindBuildCache, actions[3].Kind)
+	assert.Equal(t, int64(20), actions[3].ReclaimableBytes)
+
+	assert.Equal(t, cleanupEnvironmentUsage{
+		EnvironmentID:    1,
+		EnvironmentName:  "prod",
+		ImagesBytes:      5000,
+		ContainersBytes:  130,
+		VolumesBytes:     3000,
+		BuildCacheBytes:  60,
+		ReclaimableBytes: 1310,
+		ReclaimableSize:  "1.31kB",
+	}, totals)
+
+	prioritizeCleanupActions(actions)
+	var kinds []string
+	for i, action := range actions {
+		assert.Equal(t, i+1, action.Priority)
+		kinds = append(kinds, action.Kind)
+	}
+	assert.Equal(t, []string{cleanupKindUnusedVolumes, cleanupKindDanglingImages, cleanupKindStoppedContainers, cleanupKindBuildCache}, kinds)
+}
+
+// TestHandleSuggestCleanup verifies the parameters and the errors of the suggestCleanup tool.
+func TestHandleSuggestCleanup(t *testing.T) {
+	environments := []models.Environment{
+		{ID: 1, Name: "prod", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusActive},
+		{ID: 2, Name: "staging", Type: models.EnvironmentTypeDockerLocal, Status: models.EnvironmentStatusActive},
+		{ID: 4, Name: "k8s", Type: models.EnvironmentTypeKubernetesLocal, Status: models.EnvironmentStatusActive},
+	}
+	usage := models.DockerDiskUsage{
+		Volumes: []models.DockerDiskUsageVolume{{Name: "orphan", RefCount: 0, Size: 1000}},
+	}
+
+	tests := []struct {
+		name         string
+		args         map[string]any
+		setupMock    func(m *MockPortainerClient)
+		expectError  string
+		expectedEnvs []int
+		errorCount   int
+	}{
+		{
+			name: "defaults to active docker environments",
+			args: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetDockerDiskUsage", 1).Return(usage, nil)
+				m.On("GetDockerDiskUsage", 2).Return(models.DockerDiskUsage{}, nil)
+			},
+			expectedEnvs: []int{1, 2},
+		},
+		{
+			name: "explicit environments report unknown and non-docker ones",
+			args: map[string]any{"environmentIds": []any{float64(2), float64(4), float64(9)}, "olderThanDays": float64(0)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetDockerDiskUsage", 2).Return(usage, nil)
+			},
+			expectedEnvs: []int{2},
+			errorCount:   2,
+		},
+		{
+			name: "disk usage errors are reported per environment",
+			args: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetDockerDiskUsage", mock.Anything).Return(models.DockerDiskUsage{}, errors.New("environment unreachable"))
+			},
+			errorCount: 2,
+		},
+		{name: "negative age", args: map[string]any{"olderThanDays": float64(-1)}, expectError: "olderThanDays must be between"},
+		{name: "invalid environment id", args: map[string]any{"environmentIds": []any{float64(0)}}, expectError: "environmentIds"},
+		{
+			name: "environments error",
+			args: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get environments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleSuggestCleanup()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			var plan cleanupPlan
+			require.NoError(t, json.Unmarshal([]byte(text), &plan))
+			var envs []int
+			for _, env := range plan.Environments {
+				envs = append(envs, env.EnvironmentID)
+			}
+			assert.Equal(t, tt.expectedEnvs, envs)
+			assert.Len(t, plan.Errors, tt.errorCount)
+			if len(tt.expectedEnvs) > 0 {
+				require.Len(t, plan.Actions, 1)
+				assert.Equal(t, 1, plan.Actions[0].Priority)
+				assert.Equal(t, int64(1000), plan.TotalReclaimableBytes)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/distribution/reference"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Statuses of a container in an image update report.
+const (
+	imageStatusUpToDate = "up_to_date"
+	imageStatusStale    = "stale"
+	imageStatusPinned   = "pinned"
+	imageStatusUnknown  = "unknown"
+)
+
+// imageStatusRank orders the containers of an image update report, stale ones first.
+var imageStatusRank = map[string]int{imageStatusStale: 0, imageStatusUnknown: 1}
+
+// imageUpdateContainer is the image update status of a running container.
+type imageUpdateContainer struct {
+	EnvironmentID   int    `json:"environment_id"`
+	EnvironmentName string `json:"environment_name"`
+	ContainerID     string `json:"container_id"`
+	ContainerName   string `json:"container_name"`
+	// Image is the image reference the container was created from.
+	Image  string `json:"image"`
+	Status string `json:"status"`
+	// LocalDigest is the manifest digest of the image the container runs.
+	LocalDigest string `json:"local_digest,omitempty"`
+	// RegistryDigest is the manifest digest the image reference points to in its registry.
+	RegistryDigest string `json:"registry_digest,omitempty"`
+	Error          string `json:"error,omitempty"`
+
+	// imageID, repository and tagged are the ID of the local image, the normalized repository
+	// of the image reference and the normalized tagged reference queried in the registry.
+	imageID    string
+	repository string
+	tagged     string
+}
+
+// imageUpdateReport is the result of HandleCheckImageUpdates.
+type imageUpdateReport struct {
+	EnvironmentsScanned int `json:"environments_scanned"`
+	ContainersScanned   int `json:"containers_scanned"`
+	Stale               int `json:"stale"`
+	UpToDate            int `json:"up_to_date"`
+	Pinned              int `json:"pinned"`
+	Unknown             int `json:"unknown"`
+	// Containers lists the containers running stale images, then those that could not be checked.
+	Containers []imageUpdateContainer `json:"containers"`
+	Errors     []fleetUsageError      `json:"errors,omitempty"`
+}
+
+// imageKey identifies a local image of an environment.
+type imageKey struct {
+	environmentID int
+	imageID       string
+}
+
+// HandleCheckImageUpdates returns an MCP tool handler that compares the manifest digest of
+// the image of every running container of several Docker environments with the digest its
+// tag currently points to in the registry, and lists the containers running stale images.
+// The Docker engine of each environment queries the registry without pulling anything; every
+// tag is queried once, from the first environment that uses it. Containers created from a
+// digest are pinned and not checked.
+func (s *PortainerMCPServer) HandleCheckImageUpdates() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, id := range environmentIds {
+			if err := validatePositiveID("environmentIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+
+		report := imageUpdateReport{Containers: []imageUpdateContainer{}}
+		targets := selectFleetEnvironments(environments, environmentIds, &report.Errors)
+		report.EnvironmentsScanned = len(targets)
+
+		containers := make([][]models.DockerContainer, len(targets))
+		listErrs := make([]error, len(targets))
+		runConcurrently(ctx, len(targets), func(i int) {
+			containers[i], listErrs[i] = s.cli.GetDockerContainers(targets[i].ID, models.DockerContainerListOptions{})
+		})
+
+		var checks []imageUpdateContainer
+		for i, env := range targets {
+			if listErrs[i] != nil {
+				report.Errors = append(report.Errors, fleetUsageError{EnvironmentID: env.ID, Error: listErrs[i].Error()})
+				continue
+			}
+			for _, c := range containers[i] {
+				checks = append(checks, imageUpdateContainer{EnvironmentID: env.ID, EnvironmentName: env.Name, ContainerID: c.ID, ContainerName: c.Name})
+			}
+		}
+
+		// Read the image reference of every container: the listed image is replaced by the
+		// image ID once the reference points to another image.
+		inspectErrs := make([]error, len(checks))
+		runConcurrently(ctx, len(checks), func(i int) {
+			img, err := s.cli.GetDockerContainerImage(checks[i].EnvironmentID, checks[i].ContainerID)
+			if err != nil {
+				inspectErrs[i] = err
+				return
+			}
+			checks[i].Image, checks[i].imageID = img.Image, img.ImageID
+		})
+
+		var images []imageKey
+		var tags []string
+		tagEnvironment := map[string]int{}
+		for i := range checks {
+			c := &checks[i]
+			if inspectErrs[i] != nil {
+				c.Status, c.Error = imageStatusUnknown, inspectErrs[i].Error()
+				continue
+			}
+			named, err := reference.ParseNormalizedNamed(c.Image)
+			if err != nil {
+				c.Status, c.Error = imageStatusUnknown, "invalid image reference: "+err.Error()
+				continue
+			}
+			if _, ok := named.(reference.Digested); ok {
+				c.Status = imageStatusPinned
+				continue
+			}
+			c.repository, c.tagged = named.Name(), reference.TagNameOnly(named).String()
+
+			key := imageKey{environmentID: c.EnvironmentID, imageID: c.imageID}
+			if !slices.Contains(images, key) {
+				images = append(images, key)
+			}
+			if _, ok := tagEnvironment[c.tagged]; !ok {
+				tagEnvironment[c.tagged] = c.EnvironmentID
+				tags = append(tags, c.tagged)
+			}
+		}
+
+		imageDigests := make([][]string, len(images))
+		imageErrs := make([]error, len(images))
+		runConcurrently(ctx, len(images), func(i int) {
+			imageDigests[i], imageErrs[i] = s.cli.GetDockerImageDigests(images[i].environmentID, images[i].imageID)
+		})
+		registryDigests := make([]string, len(tags))
+		registryErrs := make([]error, len(tags))
+		runConcurrently(ctx, len(tags), func(i int) {
+			registryDigests[i], registryErrs[i] = s.cli.GetDockerDistributionDigest(tagEnvironment[tags[i]], tags[i])
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("image update check interrupted", err), nil
+		}
+
+		for i := range checks {
+			c := &checks[i]
+			if c.Status == "" {
+				imageIdx := slices.Index(images, imageKey{environmentID: c.EnvironmentID, imageID: c.imageID})
+				tagIdx := slices.Index(tags, c.tagged)
+				compareImageDigests(c, imageDigests[imageIdx], imageErrs[imageIdx], registryDigests[tagIdx], registryErrs[tagIdx])
+			}
+
+			report.ContainersScanned++
+			switch c.Status {
+			case imageStatusUpToDate:
+				report.UpToDate++
+				continue
+			case imageStatusPinned:
+				report.Pinned++
+				continue
+			case imageStatusStale:
+				report.Stale++
+			case imageStatusUnknown:
+				report.Unknown++
+			}
+			report.Containers = append(report.Containers, *c)
+		}
+
+		sort.SliceStable(report.Containers, func(i, j int) bool {
+			a, b := report.Containers[i], report.Containers[j]
+			if a.Status != b.Status {
+				return imageStatusRank[a.Status] < imageStatusRank[b.Status]
+			}
+			if a.EnvironmentID != b.EnvironmentID {
+				return a.EnvironmentID < b.EnvironmentID
+			}
+			return a.ContainerName < b.ContainerName
+		})
+
+		return jsonResult(report, "failed to marshal image update report")
+	}
+}
+
+// compareImageDigests sets the status of a container from the repository digests of its
+// local image and the digest its tag points to in the registry. The local image is up to
+// date when one of its digests in the repository of the container image is the registry one.
+func compareImageDigests(c *imageUpdateContainer, localDigests []string, localErr error, registryDigest string, registryErr error) {
+	if localErr != nil {
+		c.Status, c.Error = imageStatusUnknown, localErr.Error()
+		return
+	}
+	if registryErr != nil {
+		c.Status, c.Error = imageStatusUnknown, registryErr.Error()
+		return
+	}
+	c.RegistryDigest = registryDigest
+
+	for _, repoDigest := range localDigests {
+		named, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil || named.Name() != c.repository {
+			continue
+		}
+		digested, ok := named.(reference.Digested)
+		if !ok {
+			continue
+		}
+		if digested.Digest().String() == registryDigest {
+			c.Status, c.LocalDigest = imageStatusUpToDate, registryDigest
+			return
+		}
+		if c.LocalDigest == "" {
+			c.LocalDigest = digested.Digest().String()
+		}
+	}
+
+	if c.LocalDigest == "" {
+		c.Status, c.Error = imageStatusUnknown, "the image has no digest from "+c.repository+": it was built locally or loaded from an archive"
+		return
+	}
+	c.Status = imageStatusStale
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleCheckImageUpdates verifies the HandleCheckImageUpdates MCP tool handler.
+func TestHandleCheckImageUpdates(t *testing.T) {
+	digest := func(c string) string { return "sha256:" + strings.Repeat(c, 64) }
+	environments := []models.Environment{
+		{ID: 1, Name: "prod", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusActive},
+		{ID: 2, Name: "staging", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusActive},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    imageUpdateReport
+	}{
+		{
+			name:  "lists stale and unchecked containers",
+			input: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetDockerContainers", 1, models.DockerContainerListOptions{}).Return([]models.DockerContainer{
+					{ID: "web", Name: "web"}, {ID: "api", Name: "api"}, {ID: "db", Name: "db"}, {ID: "app", Name: "app"},
+				}, nil)
+				m.On("GetDockerContainers", 2, models.DockerContainerListOptions{}).Return([]models.DockerContainer{{ID: "web2", Name: "web"}}, nil)
+
+				m.On("GetDockerContainerImage", 1, "web").Return(models.DockerContainerImage{Image: "nginx", ImageID: "sha256:old"}, nil)
+				m.On("GetDockerContainerImage", 1, "api").Return(models.DockerContainerImage{Image: "ghcr.io/acme/api:1.2", ImageID: "sha256:api"}, nil)
+				m.On("GetDockerContainerImage", 1, "db").Return(models.DockerContainerImage{Image: "postgres@" + digest("c"), ImageID: "sha256:db"}, nil)
+				m.On("GetDockerContainerImage", 1, "app").Return(models.DockerContainerImage{Image: "app:dev", ImageID: "sha256:app"}, nil)
+				m.On("GetDockerContainerImage", 2, "web2").Return(models.DockerContainerImage{Image: "docker.io/library/nginx:latest", ImageID: "sha256:new"}, nil)
+
+				m.On("GetDockerImageDigests", 1, "sha256:old").Return([]string{"nginx@" + digest("a")}, nil)
+				m.On("GetDockerImageDigests", 1, "sha256:api").Return([]string{"ghcr.io/acme/api@" + digest("d")}, nil)
+				m.On("GetDockerImageDigests", 1, "sha256:app").Return([]string{}, nil)
+				m.On("GetDockerImageDigests", 2, "sha256:new").Return([]string{"nginx@" + digest("b")}, nil)
+
+				m.On("GetDockerDistributionDigest", 1, "docker.io/library/nginx:latest").Return(digest("b"), nil)
+				m.On("GetDockerDistributionDigest", 1, "ghcr.io/acme/api:1.2").Return(digest("d"), nil)
+				m.On("GetDockerDistributionDigest", 1, "docker.io/library/app:dev").Return("", fmt.Errorf("pull access denied"))
+			},
+			expected: imageUpdateReport{
+				EnvironmentsScanned: 2,
+				ContainersScanned:   5,
+				Stale:               1,
+				UpToDate:            2,
+				Pinned:              1,
+				Unknown:             1,
+				Containers: []imageUpdateContainer{
+					{EnvironmentID: 1, EnvironmentName: "prod", ContainerID: "web", ContainerName: "web", Image: "nginx", Status: imageStatusStale, LocalDigest: digest("a"), RegistryDigest: digest("b")},
+					{EnvironmentID: 1, EnvironmentName: "prod", ContainerID: "app", ContainerName: "app", Image: "app:dev", Status: imageStatusUnknown, Error: "pull access denied"},
+				},
+			},
+		},
+		{
+			name:  "locally built image and inspect error",
+			input: map[string]any{"environmentIds": []any{float64(2)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetDockerContainers", 2, models.DockerContainerListOptions{}).Return([]models.DockerContainer{{ID: "app", Name: "app"}, {ID: "gone", Name: "gone"}}, nil)
+				m.On("GetDockerContainerImage", 2, "app").Return(models.DockerContainerImage{Image: "registry.local/app:1", ImageID: "sha256:app"}, nil)
+				m.On("GetDockerContainerImage", 2, "gone").Return(models.DockerContainerImage{}, fmt.Errorf("no such container"))
+				m.On("GetDockerImageDigests", 2, "sha256:app").Return([]string{}, nil)
+				m.On("GetDockerDistributionDigest", 2, "registry.local/app:1").Return(digest("e"), nil)
+			},
+			expected: imageUpdateReport{
+				EnvironmentsScanned: 1,
+				ContainersScanned:   2,
+				Unknown:             2,
+				Containers: []imageUpdateContainer{
+					{EnvironmentID: 2, EnvironmentName: "staging", ContainerID: "app", ContainerName: "app", Image: "registry.local/app:1", Status: imageStatusUnknown, RegistryDigest: digest("e"), Error: "the image has no digest from registry.local/app: it was built locally or loaded from an archive"},
+					{EnvironmentID: 2, EnvironmentName: "staging", ContainerID: "gone", ContainerName: "gone", Status: imageStatusUnknown, Error: "no such container"},
+				},
+			},
+		},
+		{
+			name:  "environment listing error",
+			input: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(nil, fmt.Errorf("timeout"))
+			},
+			expectError: "failed to get environments",
+		},
+		{
+			name:        "invalid environment ID",
+			input:       map[string]any{"environmentIds": []any{float64(0)}},
+			expectError: "environmentIds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleCheckImageUpdates()(context.Background(), CreateMCPRequest(tt.input))
+			require.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				require.False(t, result.IsError, textContent.Text)
+				var report imageUpdateReport
+				require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+				assert.Equal(t, tt.expected, report)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// allAddresses is the host address of the ports published on every address of the host.
+const allAddresses = "0.0.0.0"
+
+// sensitivePorts maps the well-known ports of services that should not be reachable from
+// outside the host to the name of the service.
+var sensitivePorts = map[int]string{
+	22:    "SSH",
+	1433:  "SQL Server",
+	2181:  "ZooKeeper",
+	2375:  "Docker API (unencrypted)",
+	2376:  "Docker API",
+	2379:  "etcd",
+	3306:  "MySQL",
+	3389:  "RDP",
+	5432:  "PostgreSQL",
+	5672:  "RabbitMQ",
+	5984:  "CouchDB",
+	6379:  "Redis",
+	8500:  "Consul",
+	9092:  "Kafka",
+	9200:  "Elasticsearch",
+	10250: "Kubelet",
+	11211: "Memcached",
+	27017: "MongoDB",
+}
+
+// publishedPort is a container port published on the host.
+type publishedPort struct {
+	ContainerID   string `json:"container_id"`
+	ContainerName string `json:"container_name"`
+	State         string `json:"state"`
+	// IP is the host address the port is published on, allAddresses for every address.
+	IP          string `json:"ip"`
+	PublicPort  int    `json:"public_port"`
+	PrivatePort int    `json:"private_port"`
+	Protocol    string `json:"protocol"`
+}
+
+// portConflict is a host port published by several containers on overlapping addresses.
+type portConflict struct {
+	PublicPort int             `json:"public_port"`
+	Protocol   string          `json:"protocol"`
+	Containers []publishedPort `json:"containers"`
+	Note       string          `json:"note"`
+}
+
+// exposedPort is a port of a sensitive service published on every address of the host.
+type exposedPort struct {
+	publishedPort
+	Service string `json:"service"`
+}
+
+// portReport is the result of HandleGetPortReport.
+type portReport struct {
+	EnvironmentID     int               `json:"environment_id"`
+	ContainersScanned int               `json:"containers_scanned"`
+	Ports             []publishedPort   `json:"ports"`
+	Conflicts         []portConflict    `json:"conflicts"`
+	Exposed           []exposedPort     `json:"exposed"`
+	Errors            []fleetUsageError `json:"errors,omitempty"`
+}
+
+// HandleGetPortReport returns an MCP tool handler that lists the host ports published by the
+// containers of a Docker environment, and reports the ports published by several containers
+// and the ports of sensitive services published on every address of the host. Docker does
+// not list the ports of stopped containers, so their port bindings are read by inspecting
+// them, concurrently.
+func (s *PortainerMCPServer) HandleGetPortReport() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		containers, err := s.cli.GetDockerContainers(environmentId, models.DockerContainerListOptions{All: true})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list containers", err), nil
+		}
+
+		var stopped []int
+		for i, c := range containers {
+			if c.State != "running" && c.State != "paused" {
+				stopped = append(stopped, i)
+			}
+		}
+		bindings := make([][]models.DockerContainerPort, len(stopped))
+		inspectErrs := make([]error, len(stopped))
+		runConcurrently(ctx, len(stopped), func(i int) {
+			bindings[i], inspectErrs[i] = s.cli.GetDockerContainerPortBindings(environmentId, containers[stopped[i]].ID)
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("port scan interrupted", err), nil
+		}
+
+		report := portReport{EnvironmentID: environmentId, Ports: []publishedPort{}, Conflicts: []portConflict{}, Exposed: []exposedPort{}}
+		for i, idx := range stopped {
+			if inspectErrs[i] != nil {
+				report.Errors = append(report.Errors, fleetUsageError{EnvironmentID: environmentId, ContainerID: containers[idx].ID, Error: inspectErrs[i].Error()})
+				containers[idx].Ports = nil
+				continue
+			}
+			containers[idx].Ports = bindings[i]
+		}
+		report.ContainersScanned = len(containers) - len(report.Errors)
+
+		for _, c := range containers {
+			report.Ports = append(report.Ports, publishedPorts(c)...)
+		}
+		sort.SliceStable(report.Ports, func(i, j int) bool {
+			a, b := report.Ports[i], report.Ports[j]
+			if a.PublicPort != b.PublicPort {
+				return a.PublicPort < b.PublicPort
+			}
+			if a.Protocol != b.Protocol {
+				return a.Protocol < b.Protocol
+			}
+			return a.ContainerName < b.ContainerName
+		})
+
+		report.Conflicts = findPortConflicts(report.Ports)
+		for _, port := range report.Ports {
+			if port.IP != allAddresses {
+				continue
+			}
+			if service, ok := sensitivePortService(port); ok {
+				report.Exposed = append(report.Exposed, exposedPort{publishedPort: port, Service: service})
+			}
+		}
+
+		return jsonResult(report, "failed to marshal port report")
+	}
+}
+
+// publishedPorts returns the ports a container publishes on the host. The wildcard IPv4 and
+// IPv6 addresses are both reported as allAddresses, so that a port published on both is
+// listed once.
+func publishedPorts(c models.DockerContainer) []publishedPort {
+	var ports []publishedPort
+	for _, port := range c.Ports {
+		if port.PublicPort == 0 {
+			continue
+		}
+		ip := port.IP
+		if ip == "" || ip == "::" {
+			ip = allAddresses
+		}
+		published := publishedPort{
+			ContainerID:   c.ID,
+			ContainerName: c.Name,
+			State:         c.State,
+			IP:            ip,
+			PublicPort:    port.PublicPort,
+			PrivatePort:   port.PrivatePort,
+			Protocol:      port.Protocol,
+		}
+		if !slices.Contains(ports, published) {
+			ports = append(ports, published)
+		}
+	}
+	return ports
+}
+
+// findPortConflicts returns the host ports published by several containers on overlapping
+// addresses. ports must be sorted by public port and protocol.
+func findPortConflicts(ports []publishedPort) []portConflict {
+	conflicts := []portConflict{}
+	for start := 0; start < len(ports); {
+		end := start + 1
+		for end < len(ports) && ports[end].PublicPort == ports[start].PublicPort && ports[end].Protocol == ports[start].Protocol {
+			end++
+		}
+
+		group := ports[start:end]
+		var conflicting []publishedPort
+		for i, a := range group {
+			for j, b := range group {
+				if i != j && a.ContainerID != b.ContainerID && (a.IP == b.IP || a.IP == allAddresses || b.IP == allAddresses) {
+					conflicting = append(conflicting, a)
+					break
+				}
+			}
+		}
+		if len(conflicting) > 0 {
+			conflicts = append(conflicts, portConflict{
+				PublicPort: group[0].PublicPort,
+				Protocol:   group[0].Protocol,
+				Containers: conflicting,
+				Note:       portConflictNote(conflicting),
+			})
+		}
+		start = end
+	}
+	return conflicts
+}
+
+// portConflictNote explains the consequence of a port conflict from the states of the
+// containers involved.
+func portConflictNote(ports []publishedPort) string {
+	var running []string
+	for _, p := range ports {
+		if p.State == "running" || p.State == "paused" {
+			running = append(running, p.ContainerName)
+		}
+	}
+
+	switch len(running) {
+	case 0:
+		return "these containers cannot run at the same time"
+	case 1:
+		return fmt.Sprintf("the other containers cannot start while %s publishes this port", running[0])
+	default:
+		return "several running containers publish this port, which is only possible when they run on different nodes of a Swarm cluster"
+	}
+}
+
+// sensitivePortService returns the sensitive service of a published port, identified by the
+// container port first since the service keeps its port when published on another one.
+func sensitivePortService(port publishedPort) (string, bool) {
+	if service, ok := sensitivePorts[port.PrivatePort]; ok {
+		return service, true
+	}
+	service, ok := sensitivePorts[port.PublicPort]
+	return service, ok
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleGetPortReport verifies the HandleGetPortReport MCP tool handler.
+func TestHandleGetPortReport(t *testing.T) {
+	containers := []models.DockerContainer{
+		{ID: "db", Name: "db", State: "running", Ports: []models.DockerContainerPort{
+			{IP: "0.0.0.0", PrivatePort: 5432, PublicPort: 15432, Protocol: "tcp"},
+			{IP: "::", PrivatePort: 5432, PublicPort: 15432, Protocol: "tcp"},
+		}},
+		{ID: "web", Name: "web", State: "running", Ports: []models.DockerContainerPort{
+			{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Protocol: "tcp"},
+			{PrivatePort: 443, Protocol: "tcp"},
+		}},
+		{ID: "cache", Name: "cache", State: "running", Ports: []models.DockerContainerPort{
+			{IP: "127.0.0.1", PrivatePort: 6379, PublicPort: 6379, Protocol: "tcp"},
+		}},
+		{ID: "web-old", Name: "web-old", State: "exited"},
+		{ID: "broken", Name: "broken", State: "created"},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    portReport
+	}{
+		{
+			name:  "reports conflicts with stopped containers and exposed sensitive ports",
+			input: map[string]any{"environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 1, models.DockerContainerListOptions{All: true}).Return(containers, nil)
+				m.On("GetDockerContainerPortBindings", 1, "web-old").Return([]models.DockerContainerPort{
+					{IP: "127.0.0.1", PrivatePort: 80, PublicPort: 8080, Protocol: "tcp"},
+					{PrivatePort: 22, PublicPort: 2222, Protocol: "tcp"},
+				}, nil)
+				m.On("GetDockerContainerPortBindings", 1, "broken").Return(nil, fmt.Errorf("no such container"))
+			},
+			expected: portReport{
+				EnvironmentID:     1,
+				ContainersScanned: 4,
+				Ports: []publishedPort{
+					{ContainerID: "web-old", ContainerName: "web-old", State: "exited", IP: "0.0.0.0", PublicPort: 2222, PrivatePort: 22, Protocol: "tcp"},
+					{ContainerID: "cache", ContainerName: "cache", State: "running", IP: "127.0.0.1", PublicPort: 6379, PrivatePort: 6379, Protocol: "tcp"},
+					{ContainerID: "web", ContainerName: "web", State: "running", IP: "0.0.0.0", PublicPort: 8080, PrivatePort: 80, Protocol: "tcp"},
+					{ContainerID: "web-old", ContainerName: "web-old", State: "exited", IP: "127.0.0.1", PublicPort: 8080, PrivatePort: 80, Protocol: "tcp"},
+					{ContainerID: "db", ContainerName: "db", State: "running", IP: "0.0.0.0", PublicPort: 15432, PrivatePort: 5432, Protocol: "tcp"},
+				},
+				Conflicts: []portConflict{{
+					PublicPort: 8080,
+					Protocol:   "tcp",
+					Containers: []publishedPort{
+						{ContainerID: "web", ContainerName: "web", State: "running", IP: "0.0.0.0", PublicPort: 8080, PrivatePort: 80, Protocol: "tcp"},
+						{ContainerID: "web-old", ContainerName: "web-old", State: "exited", IP: "127.0.0.1", PublicPort: 8080, PrivatePort: 80, Protocol: "tcp"},
+					},
+					Note: "the other containers cannot start while web publishes this port",
+				}},
+				Exposed: []exposedPort{
+					{publishedPort: publishedPort{ContainerID: "web-old", ContainerName: "web-old", State: "exited", IP: "0.0.0.0", PublicPort: 2222, PrivatePort: 22, Protocol: "tcp"}, Service: "SSH"},
+					{publishedPort: publishedPort{ContainerID: "db", ContainerName: "db", State: "running", IP: "0.0.0.0", PublicPort: 15432, PrivatePort: 5432, Protocol: "tcp"}, Service: "PostgreSQL"},
+				},
+				Errors: []fleetUsageError{{EnvironmentID: 1, ContainerID: "broken", Error: "no such container"}},
+			},
+		},
+		{
+			name:  "no published ports",
+			input: map[string]any{"environmentId": float64(2)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 2, models.DockerContainerListOptions{All: true}).Return([]models.DockerContainer{}, nil)
+			},
+			expected: portReport{EnvironmentID: 2, Ports: []publishedPort{}, Conflicts: []portConflict{}, Exposed: []exposedPort{}},
+		},
+		{
+			name:  "container listing error",
+			input: map[string]any{"environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 1, models.DockerContainerListOptions{All: true}).Return(nil, fmt.Errorf("environment unreachable"))
+			},
+			expectError: "failed to list containers",
+		},
+		{
+			name:        "missing environmentId",
+			input:       map[string]any{},
+			expectError: "environmentId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleGetPortReport()(context.Background(), CreateMCPRequest(tt.input))
+			require.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				require.False(t, result.IsError, textContent.Text)
+				var report portReport
+				require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+				assert.Equal(t, tt.expected, report)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestFindPortConflicts verifies that ports published on distinct addresses do not conflict
+// and that several running containers get the Swarm note.
+func TestFindPortConflicts(t *testing.T) {
+	ports := []publishedPort{
+		{ContainerID: "c", ContainerName: "c", State: "running", IP: "0.0.0.0", PublicPort: 53, Protocol: "tcp"},
+		{ContainerID: "d", ContainerName: "d", State: "running", IP: "0.0.0.0", PublicPort: 53, Protocol: "udp"},
+		{ContainerID: "a", ContainerName: "a", State: "running", IP: "10.0.0.1", PublicPort: 80, Protocol: "tcp"},
+		{ContainerID: "b", ContainerName: "b", State: "running", IP: "10.0.0.2", PublicPort: 80, Protocol: "tcp"},
+		{ContainerID: "e", ContainerName: "e", State: "running", IP: "0.0.0.0", PublicPort: 9000, Protocol: "tcp"},
+		{ContainerID: "f", ContainerName: "f", State: "running", IP: "0.0.0.0", PublicPort: 9000, Protocol: "tcp"},
+	}
+
+	conflicts := findPortConflicts(ports)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, 9000, conflicts[0].PublicPort)
+	assert.Len(t, conflicts[0].Containers, 2)
+	assert.Contains(t, conflicts[0].Note, "Swarm")
+	assert.Equal(t, "these containers cannot run at the same time", portConflictNote([]publishedPort{{State: "exited"}, {State: "created"}}))
+}
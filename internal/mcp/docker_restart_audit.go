@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultRestartThreshold is the restart count from which a container is reported when
+	// no restartThreshold is given.
+	defaultRestartThreshold = 5
+	// maxRestartThreshold caps the restartThreshold parameter.
+	maxRestartThreshold = 100000
+	// swarmTaskIDLabel is set by Docker Swarm on the containers of service tasks.
+	swarmTaskIDLabel = "com.docker.swarm.task.id"
+)
+
+// Issues of a container in a restart policy audit.
+const (
+	restartIssueNoPolicy          = "no_restart_policy"
+	restartIssueExcessiveRestarts = "excessive_restarts"
+)
+
+// restartAuditContainer is a container reported by a restart policy audit.
+type restartAuditContainer struct {
+	EnvironmentID   int    `json:"environment_id"`
+	EnvironmentName string `json:"environment_name"`
+	Image           string `json:"image"`
+	models.DockerContainerRestartState
+	Issues []string `json:"issues"`
+}
+
+// restartAudit is the result of HandleAuditRestartPolicies.
+type restartAudit struct {
+	RestartThreshold    int  `json:"restart_threshold"`
+	IncludeStopped      bool `json:"include_stopped"`
+	EnvironmentsScanned int  `json:"environments_scanned"`
+	ContainersScanned   int  `json:"containers_scanned"`
+	// SwarmTasksSkipped is the number of Swarm task containers, whose restarts are handled
+	// by the restart policy of their service.
+	SwarmTasksSkipped int `json:"swarm_tasks_skipped,omitempty"`
+	// Policies is the number of scanned containers of each restart policy.
+	Policies          map[string]int          `json:"policies"`
+	NoRestartPolicy   int                     `json:"no_restart_policy"`
+	ExcessiveRestarts int                     `json:"excessive_restarts"`
+	Containers        []restartAuditContainer `json:"containers"`
+	Errors            []fleetUsageError       `json:"errors,omitempty"`
+}
+
+// HandleAuditRestartPolicies returns an MCP tool handler that inspects the containers of
+// several Docker environments concurrently and reports those without a restart policy and
+// those restarted at least a given number of times. Containers that exited without a
+// restart policy are often one-off jobs, so they are only reported with includeStopped.
+func (s *PortainerMCPServer) HandleAuditRestartPolicies() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, id := range environmentIds {
+			if err := validatePositiveID("environmentIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		threshold := defaultRestartThreshold
+		if _, ok := request.GetArguments()["restartThreshold"]; ok {
+			threshold, err = parser.GetInt("restartThreshold", false)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid restartThreshold parameter", err), nil
+			}
+		}
+		if threshold < 1 || threshold > maxRestartThreshold {
+			return mcp.NewToolResultError(fmt.Sprintf("restartThreshold must be between 1 and %d, got %d", maxRestartThreshold, threshold)), nil
+		}
+
+		includeStopped, err := parser.GetBoolean("includeStopped", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid includeStopped parameter", err), nil
+		}
+
+		environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+
+		audit := restartAudit{
+			RestartThreshold: threshold,
+			IncludeStopped:   includeStopped,
+			Policies:         map[string]int{},
+			Containers:       []restartAuditContainer{},
+		}
+		targets := selectFleetEnvironments(environments, environmentIds, &audit.Errors)
+		audit.EnvironmentsScanned = len(targets)
+
+		// List every container, stopped ones included since a container that exhausted the
+		// retries of its on-failure policy has exited, then inspect each of them.
+		containers := make([][]models.DockerContainer, len(targets))
+		listErrs := make([]error, len(targets))
+		runConcurrently(ctx, len(targets), func(i int) {
+			containers[i], listErrs[i] = s.cli.GetDockerContainers(targets[i].ID, models.DockerContainerListOptions{All: true})
+		})
+
+		var scanned []restartAuditContainer
+		for i, env := range targets {
+			if listErrs[i] != nil {
+				audit.Errors = append(audit.Errors, fleetUsageError{EnvironmentID: env.ID, Error: listErrs[i].Error()})
+				continue
+			}
+			for _, c := range containers[i] {
+				if _, ok := c.Labels[swarmTaskIDLabel]; ok {
+					audit.SwarmTasksSkipped++
+					continue
+				}
+				scanned = append(scanned, restartAuditContainer{
+					EnvironmentID:               env.ID,
+					EnvironmentName:             env.Name,
+					Image:                       c.Image,
+					DockerContainerRestartState: models.DockerContainerRestartState{ContainerID: c.ID, Name: c.Name, State: c.State},
+				})
+			}
+		}
+
+		inspectErrs := make([]error, len(scanned))
+		runConcurrently(ctx, len(scanned), func(i int) {
+			restart, err := s.cli.GetDockerContainerRestartState(scanned[i].EnvironmentID, scanned[i].ContainerID)
+			if err != nil {
+				inspectErrs[i] = err
+				return
+			}
+			restart.ContainerID = scanned[i].ContainerID
+			if restart.Name == "" {
+				restart.Name = scanned[i].Name
+			}
+			scanned[i].DockerContainerRestartState = restart
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("restart policy audit interrupted", err), nil
+		}
+
+		for i, c := range scanned {
+			if inspectErrs[i] != nil {
+				audit.Errors = append(audit.Errors, fleetUsageError{EnvironmentID: c.EnvironmentID, ContainerID: c.ContainerID, Error: inspectErrs[i].Error()})
+				continue
+			}
+			audit.ContainersScanned++
+			audit.Policies[c.Policy]++
+
+			c.Issues = restartIssues(c.DockerContainerRestartState, threshold, includeStopped)
+			if len(c.Issues) == 0 {
+				continue
+			}
+			for _, issue := range c.Issues {
+				switch issue {
+				case restartIssueNoPolicy:
+					audit.NoRestartPolicy++
+				case restartIssueExcessiveRestarts:
+					audit.ExcessiveRestarts++
+				}
+			}
+			audit.Containers = append(audit.Containers, c)
+		}
+
+		sort.SliceStable(audit.Containers, func(i, j int) bool {
+			a, b := audit.Containers[i], audit.Containers[j]
+			if a.RestartCount != b.RestartCount {
+				return a.RestartCount > b.RestartCount
+			}
+			if a.EnvironmentID != b.EnvironmentID {
+				return a.EnvironmentID < b.EnvironmentID
+			}
+			return a.Name < b.Name
+		})
+
+		return jsonResult(audit, "failed to marshal restart policy audit")
+	}
+}
+
+// restartIssues returns the issues of a container: no restart policy, unless the container
+// is stopped and includeStopped is false, and a restart count of at least threshold.
+func restartIssues(restart models.DockerContainerRestartState, threshold int, includeStopped bool) []string {
+	var issues []string
+	if restart.Policy == "no" && (includeStopped || !isStoppedContainerState(restart.State)) {
+		issues = append(issues, restartIssueNoPolicy)
+	}
+	if restart.RestartCount >= threshold {
+		issues = append(issues, restartIssueExcessiveRestarts)
+	}
+	return issues
+}
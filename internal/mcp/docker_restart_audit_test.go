@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleAuditRestartPolicies verifies the HandleAuditRestartPolicies MCP tool handler.
+func TestHandleAuditRestartPolicies(t *testing.T) {
+	environments := []models.Environment{
+		{ID: 1, Name: "prod", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusActive},
+		{ID: 2, Name: "edge", Type: models.EnvironmentTypeDockerEdgeAgent, Status: models.EnvironmentStatusActive},
+		{ID: 3, Name: "k8s", Type: models.EnvironmentTypeKubernetesAgent, Status: models.EnvironmentStatusActive},
+	}
+	prodContainers := []models.DockerContainer{
+		{ID: "web", Name: "web", Image: "nginx", State: "running"},
+		{ID: "job", Name: "job", Image: "busybox", State: "exited"},
+		{ID: "worker", Name: "worker", Image: "app", State: "exited"},
+		{ID: "task", Name: "svc.1", Image: "app", State: "running", Labels: map[string]string{swarmTaskIDLabel: "t1"}},
+	}
+	restartStates := map[string]models.DockerContainerRestartState{
+		"web":    {ContainerID: "web", Name: "web", State: "running", Policy: "no"},
+		"job":    {ContainerID: "job", Name: "job", State: "exited", Policy: "no"},
+		"worker": {ContainerID: "worker", Name: "worker", State: "exited", Policy: "on-failure", MaximumRetryCount: 5, RestartCount: 5},
+	}
+	setupProd := func(m *MockPortainerClient) {
+		m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+		m.On("GetDockerContainers", 1, models.DockerContainerListOptions{All: true}).Return(prodContainers, nil)
+		for id, state := range restartStates {
+			m.On("GetDockerContainerRestartState", 1, id).Return(state, nil)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    restartAudit
+	}{
+		{
+			name:  "reports running containers without a policy and excessive restarts",
+			input: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				setupProd(m)
+				m.On("GetDockerContainers", 2, models.DockerContainerListOptions{All: true}).Return(nil, fmt.Errorf("edge agent offline"))
+			},
+			expected: restartAudit{
+				RestartThreshold:    defaultRestartThreshold,
+				EnvironmentsScanned: 2,
+				ContainersScanned:   3,
+				SwarmTasksSkipped:   1,
+				Policies:            map[string]int{"no": 2, "on-failure": 1},
+				NoRestartPolicy:     1,
+				ExcessiveRestarts:   1,
+				Containers: []restartAuditContainer{
+					{EnvironmentID: 1, EnvironmentName: "prod", Image: "app", DockerContainerRestartState: restartStates["worker"], Issues: []string{restartIssueExcessiveRestarts}},
+					{EnvironmentID: 1, EnvironmentName: "prod", Image: "nginx", DockerContainerRestartState: restartStates["web"], Issues: []string{restartIssueNoPolicy}},
+				},
+				Errors: []fleetUsageError{{EnvironmentID: 2, Error: "edge agent offline"}},
+			},
+		},
+		{
+			name:  "stopped containers and a higher threshold",
+			input: map[string]any{"environmentIds": []any{float64(1), float64(3)}, "restartThreshold": float64(10), "includeStopped": true},
+			setupMock: func(m *MockPortainerClient) {
+				setupProd(m)
+			},
+			expected: restartAudit{
+				RestartThreshold:    10,
+				IncludeStopped:      true,
+				EnvironmentsScanned: 1,
+				ContainersScanned:   3,
+				SwarmTasksSkipped:   1,
+				Policies:            map[string]int{"no": 2, "on-failure": 1},
+				NoRestartPolicy:     2,
+				Containers: []restartAuditContainer{
+					{EnvironmentID: 1, EnvironmentName: "prod", Image: "busybox", DockerContainerRestartState: restartStates["job"], Issues: []string{restartIssueNoPolicy}},
+					{EnvironmentID: 1, EnvironmentName: "prod", Image: "nginx", DockerContainerRestartState: restartStates["web"], Issues: []string{restartIssueNoPolicy}},
+				},
+				Errors: []fleetUsageError{{EnvironmentID: 3, Error: "environment type kubernetes-agent is not a Docker environment"}},
+			},
+		},
+		{
+			name:  "inspect error",
+			input: map[string]any{"environmentIds": []any{float64(2)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetDockerContainers", 2, models.DockerContainerListOptions{All: true}).Return([]models.DockerContainer{{ID: "gone", Name: "gone", State: "running"}}, nil)
+				m.On("GetDockerContainerRestartState", 2, "gone").Return(models.DockerContainerRestartState{}, fmt.Errorf("no such container"))
+			},
+			expected: restartAudit{
+				RestartThreshold:    defaultRestartThreshold,
+				EnvironmentsScanned: 1,
+				Policies:            map[string]int{},
+				Containers:          []restartAuditContainer{},
+				Errors:              []fleetUsageError{{EnvironmentID: 2, ContainerID: "gone", Error: "no such container"}},
+			},
+		},
+		{
+			name:  "environment listing error",
+			input: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(nil, fmt.Errorf("timeout"))
+			},
+			expectError: "failed to get environments",
+		},
+		{
+			name:        "threshold out of range",
+			input:       map[string]any{"restartThreshold": float64(0)},
+			expectError: "restartThreshold must be between 1",
+		},
+		{
+			name:        "invalid environment ID",
+			input:       map[string]any{"environmentIds": []any{float64(-1)}},
+			expectError: "environmentIds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleAuditRestartPolicies()(context.Background(), CreateMCPRequest(tt.input))
+			require.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				require.False(t, result.IsError, textContent.Text)
+				var audit restartAudit
+				require.NoError(t, json.Unmarshal([]byte(textContent.Text), &audit))
+				assert.Equal(t, tt.expected, audit)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -9,11 +9,14 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/proxyroutes"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func createMockHttpResponse(statusCode int, body string) *http.Response {
@@ -104,6 +107,24 @@ func TestHandleDockerProxy_ParameterValidation(t *testing.T) {
 			},
 			expectedErrorMsg: "invalid method: INVALID",
 		},
+		{
+			name: "unknown Docker API path",
+			inputParams: map[string]any{
+				"environmentId": float64(1),
+				"dockerAPIPath": "/container/json",
+				"method":        "GET",
+			},
+			expectedErrorMsg: `unknown Docker API path "/container/json", did you mean "/containers/json"?`,
+		},
+		{
+			name: "method not allowed for path",
+			inputParams: map[string]any{
+				"environmentId": float64(1),
+				"dockerAPIPath": "/containers/json",
+				"method":        "DELETE",
+			},
+			expectedErrorMsg: "method DELETE is not allowed for Docker API path",
+		},
 		{
 			name: "invalid queryParams type (not an array)",
 			inputParams: map[string]any{
@@ -421,3 +442,850 @@ _, err := handler(context.Background(), request)
 assert.NoError(t, err)
 assert.True(t, tc.closed, "response body should be closed after handler returns")
 }
+
+// TestHandleDockerProxyGet verifies the HandleDockerProxyGet MCP tool handler.
+func TestHandleDockerProxyGet(t *testing.T) {
+	inspect := `{"Id":"abc","GraphDriver":{"Name":"overlay2"},"Config":{"Image":"nginx","Env":["PASSWORD=x"]}}`
+
+	tests := []struct {
+		name        string
+		inputParams map[string]any
+		mockBody    string
+		mockError   error
+		expectError string
+		expected    string
+	}{
+		{
+			name:        "strips verbose fields",
+			inputParams: map[string]any{"environmentId": float64(1), "dockerAPIPath": "/containers/abc/json"},
+			mockBody:    inspect,
+			expected:    `{"Config":{"Image":"nginx"},"Id":"abc"}`,
+		},
+		{
+			name: "keeps requested fields",
+			inputParams: map[string]any{
+				"environmentId": float64(1),
+				"dockerAPIPath": "/containers/abc/json",
+				"keep":          []any{"Config.Env"},
+			},
+			mockBody: inspect,
+			expected: `{"Config":{"Env":["PASSWORD=x"],"Image":"nginx"},"Id":"abc"}`,
+		},
+		{
+			name:        "missing environmentId",
+			inputParams: map[string]any{"dockerAPIPath": "/containers/json"},
+			expectError: "environmentId is required",
+		},
+		{
+			name:        "path without leading slash",
+			inputParams: map[string]any{"environmentId": float64(1), "dockerAPIPath": "containers/json"},
+			expectError: "dockerAPIPath must start with a leading slash",
+		},
+		{
+			name:        "unknown path",
+			inputParams: map[string]any{"environmentId": float64(1), "dockerAPIPath": "/containers/abc/jsn"},
+			expectError: `did you mean "/containers/abc/json"?`,
+		},
+		{
+			name:        "write-only path",
+			inputParams: map[string]any{"environmentId": float64(1), "dockerAPIPath": "/containers/create"},
+			expectError: "method GET is not allowed for Docker API path",
+		},
+		{
+			name:        "invalid keep",
+			inputParams: map[string]any{"environmentId": float64(1), "dockerAPIPath": "/containers/json", "keep": "Config.Env"},
+			expectError: "keep must be an array",
+		},
+		{
+			name:        "client error",
+			inputParams: map[string]any{"environmentId": float64(1), "dockerAPIPath": "/containers/json"},
+			mockError:   errors.New("connection refused"),
+			expectError: "failed to send Docker API request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockPortainerClient)
+			if tt.mockBody != "" || tt.mockError != nil {
+				mockClient.On("ProxyDockerRequest", mock.MatchedBy(func(opts models.DockerProxyRequestOptions) bool {
+					return opts.Method == "GET" && opts.EnvironmentID == 1
+				})).Return(createMockHttpResponse(http.StatusOK, tt.mockBody), tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleDockerProxyGet()(context.Background(), CreateMCPRequest(tt.inputParams))
+			assert.NoError(t, err)
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+			} else {
+				assert.False(t, result.IsError)
+				assert.JSONEq(t, tt.expected, text)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleListContainers verifies the HandleListContainers MCP tool handler.
+func TestHandleListContainers(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputParams    map[string]any
+		expectedOpts   models.DockerContainerListOptions
+		mockContainers []models.DockerContainer
+		mockError      error
+		expectError    bool
+		callsClient    bool
+	}{
+		{
+			name:         "list running containers",
+			inputParams:  map[string]any{"environmentId": float64(1)},
+			expectedOpts: models.DockerContainerListOptions{},
+			mockContainers: []models.DockerContainer{
+				{ID: "abc", Name: "web", State: "running"},
+			},
+			callsClient: true,
+		},
+		{
+			name: "filters passed to client",
+			inputParams: map[string]any{
+				"environmentId": float64(1),
+				"all":           true,
+				"name":          "web",
+				"status":        "exited",
+				"label":         "app=web",
+			},
+			expectedOpts:   models.DockerContainerListOptions{All: true, Name: "web", Status: "exited", Labels: []string{"app=web"}},
+			mockContainers: []models.DockerContainer{},
+			callsClient:    true,
+		},
+		{
+			name: "label selectors and compose project combined",
+			inputParams: map[string]any{
+				"environmentId":  float64(1),
+				"labels":         []any{"com.docker.compose.service=api", "tier"},
+				"label":          "app=web",
+				"composeProject": "Shop",
+			},
+			expectedOpts: models.DockerContainerListOptions{Labels: []string{
+				"com.docker.compose.service=api", "tier", "app=web", "com.docker.compose.project=shop",
+			}},
+			mockContainers: []models.DockerContainer{},
+			callsClient:    true,
+		},
+		{
+			name:        "label selector without key",
+			inputParams: map[string]any{"environmentId": float64(1), "labels": []any{"=web"}},
+			expectError: true,
+		},
+		{
+			name:        "invalid labels parameter",
+			inputParams: map[string]any{"environmentId": float64(1), "labels": "app=web"},
+			expectError: true,
+		},
+		{
+			name:         "api error",
+			inputParams:  map[string]any{"environmentId": float64(1)},
+			expectedOpts: models.DockerContainerListOptions{},
+			mockError:    fmt.Errorf("api error"),
+			expectError:  true,
+			callsClient:  true,
+		},
+		{
+			name:        "missing environmentId",
+			inputParams: map[string]any{},
+			expectError: true,
+		},
+		{
+			name:        "invalid all parameter",
+			inputParams: map[string]any{"environmentId": float64(1), "all": "yes"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.callsClient {
+				mockClient.On("GetDockerContainers", 1, tt.expectedOpts).Return(tt.mockContainers, tt.mockError)
+			}
+
+			server := &PortainerMCPServer{
+				cli: mockClient,
+			}
+
+			handler := server.HandleListContainers()
+			result, err := handler(context.Background(), CreateMCPRequest(tt.inputParams))
+
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+			if tt.expectError {
+				assert.True(t, result.IsError, "result.IsError should be true for errors")
+			} else {
+				textContent, ok := result.Content[0].(mcp.TextContent)
+				assert.True(t, ok)
+
+				var containers []models.DockerContainer
+				err = json.Unmarshal([]byte(textContent.Text), &containers)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.mockContainers, containers)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleGetContainerLogs verifies the HandleGetContainerLogs MCP tool handler.
+func TestHandleGetContainerLogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputParams map[string]any
+		checkOpts   func(opts models.DockerContainerLogOptions) bool
+		mockLogs    string
+		mockError   error
+		expectError bool
+		callsClient bool
+	}{
+		{
+			name:        "default tail",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web"},
+			checkOpts: func(opts models.DockerContainerLogOptions) bool {
+				return opts.Tail == defaultContainerLogTail && opts.Since.IsZero() && opts.Until.IsZero()
+			},
+			mockLogs:    "line1\nline2\n",
+			callsClient: true,
+		},
+		{
+			name: "time range and options",
+			inputParams: map[string]any{
+				"environmentId": float64(1),
+				"containerId":   "web",
+				"since":         "2025-01-01T00:00:00Z",
+				"until":         "2025-01-01T01:00:00Z",
+				"tail":          float64(10),
+				"timestamps":    true,
+			},
+			checkOpts: func(opts models.DockerContainerLogOptions) bool {
+				return opts.Tail == 10 && opts.Timestamps &&
+					opts.Since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+					opts.Until.Equal(time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC))
+			},
+			mockLogs:    "line\n",
+			callsClient: true,
+		},
+		{
+			name:        "api error",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web"},
+			checkOpts:   func(opts models.DockerContainerLogOptions) bool { return true },
+			mockError:   fmt.Errorf("no such container"),
+			expectError: true,
+			callsClient: true,
+		},
+		{
+			name:        "missing containerId",
+			inputParams: map[string]any{"environmentId": float64(1)},
+			expectError: true,
+		},
+		{
+			name:        "since after until",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "since": "1h", "until": "2h"},
+			expectError: true,
+		},
+		{
+			name:        "invalid since",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "since": "yesterday"},
+			expectError: true,
+		},
+		{
+			name:        "negative tail",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "tail": float64(-1)},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.callsClient {
+				mockClient.On("GetDockerContainerLogs", 1, "web", mock.MatchedBy(tt.checkOpts)).Return(tt.mockLogs, tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetContainerLogs()(context.Background(), CreateMCPRequest(tt.inputParams))
+
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+			if tt.expectError {
+				assert.True(t, result.IsError, "result.IsError should be true for errors")
+			} else {
+				textContent, ok := result.Content[0].(mcp.TextContent)
+				assert.True(t, ok)
+				assert.Equal(t, tt.mockLogs, textContent.Text)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleGetContainerTop verifies the default ps arguments and the ordering of the
+// processes by CPU usage.
+func TestHandleGetContainerTop(t *testing.T) {
+	top := models.DockerContainerTop{
+		Titles: []string{"PID", "%CPU", "COMMAND"},
+		Processes: []map[string]string{
+			{"PID": "1", "%CPU": "0.1", "COMMAND": "nginx: master process"},
+			{"PID": "29", "%CPU": "87.5", "COMMAND": "nginx: worker process"},
+			{"PID": "30", "%CPU": "3.0", "COMMAND": "nginx: worker process"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		inputParams   map[string]any
+		expectedArgs  string
+		mockError     error
+		expectError   bool
+		expectedOrder []string
+		callsClient   bool
+	}{
+		{
+			name:          "default ps arguments",
+			inputParams:   map[string]any{"environmentId": float64(1), "containerId": "web"},
+			expectedArgs:  defaultContainerTopArgs,
+			expectedOrder: []string{"29", "30", "1"},
+			callsClient:   true,
+		},
+		{
+			name:          "custom ps arguments",
+			inputParams:   map[string]any{"environmentId": float64(1), "containerId": "web", "psArgs": "-eo pid,%cpu,args"},
+			expectedArgs:  "-eo pid,%cpu,args",
+			expectedOrder: []string{"29", "30", "1"},
+			callsClient:   true,
+		},
+		{
+			name:         "api error",
+			inputParams:  map[string]any{"environmentId": float64(1), "containerId": "web"},
+			expectedArgs: defaultContainerTopArgs,
+			mockError:    errors.New("container is not running"),
+			expectError:  true,
+			callsClient:  true,
+		},
+		{
+			name:        "empty containerId",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": " "},
+			expectError: true,
+		},
+		{
+			name:        "invalid environmentId",
+			inputParams: map[string]any{"environmentId": float64(-1), "containerId": "web"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockPortainerClient)
+			if tt.callsClient {
+				processes := append([]map[string]string(nil), top.Processes...)
+				mockClient.On("GetDockerContainerTop", 1, "web", tt.expectedArgs).
+					Return(models.DockerContainerTop{Titles: top.Titles, Processes: processes}, tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleGetContainerTop()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectError, result.IsError)
+
+			if !tt.expectError {
+				var actual models.DockerContainerTop
+				require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &actual))
+				var order []string
+				for _, process := range actual.Processes {
+					order = append(order, process["PID"])
+				}
+				assert.Equal(t, tt.expectedOrder, order)
+			}
+			if tt.callsClient {
+				mockClient.AssertExpectations(t)
+			} else {
+				mockClient.AssertNotCalled(t, "GetDockerContainerTop", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// TestHandleListDockerEvents verifies the HandleListDockerEvents MCP tool handler.
+func TestHandleListDockerEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputParams map[string]any
+		checkOpts   func(opts models.DockerEventListOptions) bool
+		mockEvents  []models.DockerEvent
+		mockError   error
+		expectError bool
+		callsClient bool
+	}{
+		{
+			name:        "default window is the last hour",
+			inputParams: map[string]any{"environmentId": float64(1)},
+			checkOpts: func(opts models.DockerEventListOptions) bool {
+				return !opts.Until.IsZero() && opts.Until.Sub(opts.Since) == defaultDockerEventWindow && opts.Type == ""
+			},
+			mockEvents:  []models.DockerEvent{{Type: "container", Action: "start", ActorID: "abc"}},
+			callsClient: true,
+		},
+		{
+			name: "explicit range and type",
+			inputParams: map[string]any{
+				"environmentId": float64(1),
+				"since":         "2025-01-01T00:00:00Z",
+				"until":         "2025-01-02T00:00:00Z",
+				"type":          "image",
+			},
+			checkOpts: func(opts models.DockerEventListOptions) bool {
+				return opts.Type == "image" &&
+					opts.Since.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+					opts.Until.Equal(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC))
+			},
+			mockEvents:  []models.DockerEvent{},
+			callsClient: true,
+		},
+		{
+			name:        "api error",
+			inputParams: map[string]any{"environmentId": float64(1)},
+			checkOpts:   func(opts models.DockerEventListOptions) bool { return true },
+			mockError:   fmt.Errorf("api error"),
+			expectError: true,
+			callsClient: true,
+		},
+		{
+			name:        "since in the future of default until",
+			inputParams: map[string]any{"environmentId": float64(1), "since": "2999-01-01T00:00:00Z"},
+			expectError: true,
+		},
+		{
+			name:        "invalid until",
+			inputParams: map[string]any{"environmentId": float64(1), "until": "tomorrow"},
+			expectError: true,
+		},
+		{
+			name:        "missing environmentId",
+			inputParams: map[string]any{},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.callsClient {
+				mockClient.On("GetDockerEvents", 1, mock.MatchedBy(tt.checkOpts)).Return(tt.mockEvents, tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleListDockerEvents()(context.Background(), CreateMCPRequest(tt.inputParams))
+
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+			if tt.expectError {
+				assert.True(t, result.IsError, "result.IsError should be true for errors")
+			} else {
+				textContent, ok := result.Content[0].(mcp.TextContent)
+				assert.True(t, ok)
+
+				var events []models.DockerEvent
+				err = json.Unmarshal([]byte(textContent.Text), &events)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.mockEvents, events)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleGetFleetContainerUsage verifies the fleet-wide container usage report.
+func TestHandleGetFleetContainerUsage(t *testing.T) {
+	environments := []models.Environment{
+		{ID: 1, Name: "prod", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusActive},
+		{ID: 2, Name: "staging", Type: models.EnvironmentTypeDockerLocal, Status: models.EnvironmentStatusActive},
+		{ID: 3, Name: "offline", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusInactive},
+		{ID: 4, Name: "k8s", Type: models.EnvironmentTypeKubernetesLocal, Status: models.EnvironmentStatusActive},
+	}
+	containers := map[int][]models.DockerContainer{
+		1: {{ID: "a", Name: "api", Image: "api:1"}, {ID: "b", Name: "db", Image: "postgres:16"}},
+		2: {{ID: "c", Name: "web", Image: "nginx:1"}},
+	}
+	stats := map[string]models.DockerContainerUsage{
+		"a": {CPUPercent: 50, MemoryUsage: 100},
+		"b": {CPUPercent: 5, MemoryUsage: 800},
+		"c": {CPUPercent: 20, MemoryUsage: 300},
+	}
+
+	tests := []struct {
+		name         string
+		inputParams  map[string]any
+		envError     error
+		listError    error
+		statsError   error
+		expectError  bool
+		expectedTop  []string
+		expectedEnvs []int
+		errorCount   int
+	}{
+		{
+			name:         "defaults to active docker environments sorted by memory",
+			inputParams:  map[string]any{},
+			expectedTop:  []string{"b", "c", "a"},
+			expectedEnvs: []int{1, 2},
+		},
+		{
+			name:         "sorted by cpu with limit",
+			inputParams:  map[string]any{"sortBy": "cpu", "limit": float64(2)},
+			expectedTop:  []string{"a", "c"},
+			expectedEnvs: []int{1, 2},
+		},
+		{
+			name:         "explicit environments report unknown and non-docker ones",
+			inputParams:  map[string]any{"environmentIds": []any{float64(2), float64(4), float64(9)}},
+			expectedTop:  []string{"c"},
+			expectedEnvs: []int{2},
+			errorCount:   2,
+		},
+		{
+			name:         "container listing errors are reported per environment",
+			inputParams:  map[string]any{},
+			listError:    fmt.Errorf("environment unreachable"),
+			expectedTop:  []string{},
+			expectedEnvs: []int{1, 2},
+			errorCount:   2,
+		},
+		{
+			name:         "stats errors are reported per container",
+			inputParams:  map[string]any{},
+			statsError:   fmt.Errorf("container stopped"),
+			expectedTop:  []string{},
+			expectedEnvs: []int{1, 2},
+			errorCount:   3,
+		},
+		{
+			name:        "environment listing error",
+			inputParams: map[string]any{},
+			envError:    fmt.Errorf("api error"),
+			expectError: true,
+		},
+		{
+			name:        "invalid sortBy",
+			inputParams: map[string]any{"sortBy": "disk"},
+			expectError: true,
+		},
+		{
+			name:        "limit too large",
+			inputParams: map[string]any{"limit": float64(101)},
+			expectError: true,
+		},
+		{
+			name:        "invalid environment id",
+			inputParams: map[string]any{"environmentIds": []any{float64(0)}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, tt.envError).Maybe()
+			for envID, list := range containers {
+				mockClient.On("GetDockerContainers", envID, models.DockerContainerListOptions{}).Return(list, tt.listError).Maybe()
+				for _, c := range list {
+					mockClient.On("GetDockerContainerStats", envID, c.ID).Return(stats[c.ID], tt.statsError).Maybe()
+				}
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetFleetContainerUsage()(context.Background(), CreateMCPRequest(tt.inputParams))
+
+			assert.NoError(t, err)
+			require.NotNil(t, result)
+			if tt.expectError {
+				assert.True(t, result.IsError, "result.IsError should be true for errors")
+				return
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var report fleetUsageReport
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+
+			top := []string{}
+			for _, c := range report.Top {
+				top = append(top, c.ContainerID)
+			}
+			assert.Equal(t, tt.expectedTop, top)
+
+			envs := []int{}
+			for _, e := range report.Environments {
+				envs = append(envs, e.EnvironmentID)
+			}
+			assert.ElementsMatch(t, tt.expectedEnvs, envs)
+			assert.Len(t, report.Errors, tt.errorCount)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleDockerProxy_SkipProxyValidation verifies unknown paths are proxied when validation is disabled.
+func TestHandleDockerProxy_SkipProxyValidation(t *testing.T) {
+	mockClient := new(MockPortainerClient)
+	mockClient.On("ProxyDockerRequest", mock.MatchedBy(func(opts models.DockerProxyRequestOptions) bool {
+		return opts.Path == "/custom/endpoint"
+	})).Return(createMockHttpResponse(http.StatusOK, "ok"), nil)
+
+	server := &PortainerMCPServer{cli: mockClient, skipProxyValidation: true}
+	result, err := server.HandleDockerProxy()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"dockerAPIPath": "/custom/endpoint",
+		"method":        "GET",
+	}))
+
+	assert.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "ok", result.Content[0].(mcp.TextContent).Text)
+	mockClient.AssertExpectations(t)
+}
+
+// TestHandleDockerProxy_ProxyRules verifies that requests denied by the proxy rules never reach Portainer.
+func TestHandleDockerProxy_ProxyRules(t *testing.T) {
+	policy, err := proxyroutes.NewPolicy(proxyroutes.PolicyConfig{
+		Docker: proxyroutes.Rules{Deny: []string{"POST /containers/*/exec", "/secrets/**"}},
+	})
+	require.NoError(t, err)
+
+	mockClient := new(MockPortainerClient)
+	server := &PortainerMCPServer{cli: mockClient, proxyPolicy: policy}
+
+	result, err := server.HandleDockerProxy()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"dockerAPIPath": "/containers/abc/exec",
+		"method":        "POST",
+	}))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `denied by proxy rule "POST /containers/*/exec"`)
+
+	result, err = server.HandleDockerProxyGet()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1),
+		"dockerAPIPath": "/secrets",
+	}))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `denied by proxy rule "/secrets/**"`)
+
+	mockClient.AssertNotCalled(t, "ProxyDockerRequest", mock.Anything)
+}
+
+// TestHandleReadContainerFile verifies that text files are returned as is, binary files
+// base64 encoded, and invalid paths rejected.
+func TestHandleReadContainerFile(t *testing.T) {
+	tests := []struct {
+		name             string
+		inputParams      map[string]any
+		mockFile         models.DockerContainerFile
+		mockError        error
+		expectError      bool
+		expectedEncoding string
+		expectedContent  string
+	}{
+		{
+			name:             "text file",
+			inputParams:      map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/nginx/../nginx/nginx.conf"},
+			mockFile:         models.DockerContainerFile{Path: "/etc/nginx/nginx.conf", Size: 20, Mode: "0644", Content: []byte("worker_processes 1;\n")},
+			expectedEncoding: "utf-8",
+			expectedContent:  "worker_processes 1;\n",
+		},
+		{
+			name:             "binary file",
+			inputParams:      map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/nginx/nginx.conf"},
+			mockFile:         models.DockerContainerFile{Path: "/etc/nginx/nginx.conf", Size: 3, Mode: "0644", Content: []byte{0xff, 0x00, 0xfe}},
+			expectedEncoding: "base64",
+			expectedContent:  "/wD+",
+		},
+		{
+			name:        "api error",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/nginx/nginx.conf"},
+			mockError:   errors.New("/etc/nginx/nginx.conf is a directory"),
+			expectError: true,
+		},
+		{
+			name:        "relative path",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "nginx.conf"},
+			expectError: true,
+		},
+		{
+			name:        "directory path",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/nginx/"},
+			expectError: true,
+		},
+		{
+			name:        "invalid containerId",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "../images", "path": "/etc/hosts"},
+			expectError: true,
+		},
+		{
+			name:        "invalid environmentId",
+			inputParams: map[string]any{"environmentId": float64(0), "containerId": "web", "path": "/etc/hosts"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockPortainerClient)
+			callsClient := tt.mockError != nil || tt.mockFile.Path != ""
+			if callsClient {
+				mockClient.On("GetDockerContainerFile", 1, "web", "/etc/nginx/nginx.conf").Return(tt.mockFile, tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleReadContainerFile()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectError, result.IsError)
+
+			if !tt.expectError {
+				var file containerFile
+				require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &file))
+				assert.Equal(t, tt.expectedEncoding, file.Encoding)
+				assert.Equal(t, tt.expectedContent, file.Content)
+				assert.Equal(t, "0644", file.Mode)
+			}
+			if callsClient {
+				mockClient.AssertExpectations(t)
+			} else {
+				mockClient.AssertNotCalled(t, "GetDockerContainerFile", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// TestHandleWriteContainerFile verifies the decoding of the content and mode parameters
+// before the file is written.
+func TestHandleWriteContainerFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		inputParams     map[string]any
+		expectedContent []byte
+		expectedMode    int64
+		mockError       error
+		expectError     bool
+	}{
+		{
+			name:            "text content with default mode",
+			inputParams:     map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf", "content": "debug = true\n"},
+			expectedContent: []byte("debug = true\n"),
+			expectedMode:    0o644,
+		},
+		{
+			name: "base64 content with mode",
+			inputParams: map[string]any{
+				"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf",
+				"content": "/wD+", "encoding": "base64", "mode": "0755",
+			},
+			expectedContent: []byte{0xff, 0x00, 0xfe},
+			expectedMode:    0o755,
+		},
+		{
+			name:            "api error",
+			inputParams:     map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf", "content": "x"},
+			expectedContent: []byte("x"),
+			expectedMode:    0o644,
+			mockError:       errors.New("status 404: Could not find the file /etc"),
+			expectError:     true,
+		},
+		{
+			name:        "invalid base64",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf", "content": "%%%", "encoding": "base64"},
+			expectError: true,
+		},
+		{
+			name:        "invalid encoding",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf", "content": "x", "encoding": "hex"},
+			expectError: true,
+		},
+		{
+			name:        "invalid mode",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf", "content": "x", "mode": "rw-r--r--"},
+			expectError: true,
+		},
+		{
+			name:        "missing content",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/etc/app.conf"},
+			expectError: true,
+		},
+		{
+			name:        "root path",
+			inputParams: map[string]any{"environmentId": float64(1), "containerId": "web", "path": "/", "content": "x"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockPortainerClient)
+			if tt.expectedContent != nil {
+				mockClient.On("PutDockerContainerFile", 1, "web", "/etc/app.conf", tt.expectedContent, tt.expectedMode).Return(tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleWriteContainerFile()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectError, result.IsError)
+
+			if !tt.expectError {
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "to /etc/app.conf in container web")
+			}
+			if tt.expectedContent != nil {
+				mockClient.AssertExpectations(t)
+			} else {
+				mockClient.AssertNotCalled(t, "PutDockerContainerFile", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// TestHandleContainerFile_ProxyRules verifies that the container file tools obey the proxy
+// rules on the Docker archive endpoint.
+func TestHandleContainerFile_ProxyRules(t *testing.T) {
+	policy, err := proxyroutes.NewPolicy(proxyroutes.PolicyConfig{
+		Docker: proxyroutes.Rules{Deny: []string{"/containers/*/archive"}},
+	})
+	require.NoError(t, err)
+
+	mockClient := new(MockPortainerClient)
+	server := &PortainerMCPServer{cli: mockClient, proxyPolicy: policy}
+
+	result, err := server.HandleReadContainerFile()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1), "containerId": "web", "path": "/etc/hosts",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `denied by proxy rule "/containers/*/archive"`)
+
+	result, err = server.HandleWriteContainerFile()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId": float64(1), "containerId": "web", "path": "/etc/hosts", "content": "127.0.0.1 localhost\n",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	mockClient.AssertNotCalled(t, "GetDockerContainerFile", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "PutDockerContainerFile", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// driftDiffContext is the number of unchanged lines shown around each change.
+	driftDiffContext = 3
+	// maxDriftDiffSize caps the size of the diff reported for a single stack.
+	maxDriftDiffSize = 16 * 1024
+	// driftTruncatedMarker ends the diffs cut at maxDriftDiffSize.
+	driftTruncatedMarker = "\n... (diff truncated)\n"
+)
+
+// stackDrift describes whether a git-backed stack still matches its git reference.
+type stackDrift struct {
+	StackID        int    `json:"stack_id"`
+	Name           string `json:"name"`
+	EnvironmentID  int    `json:"environment_id"`
+	Repository     string `json:"repository"`
+	Reference      string `json:"reference,omitempty"`
+	File           string `json:"file,omitempty"`
+	DeployedCommit string `json:"deployed_commit,omitempty"`
+	RedeployNeeded bool   `json:"redeploy_needed"`
+	LinesAdded     int    `json:"lines_added"`
+	LinesRemoved   int    `json:"lines_removed"`
+	Diff           string `json:"diff,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// HandleDetectDrift returns an MCP tool handler that compares the compose file deployed for
+// git-backed regular stacks with the file at the head of the git reference they track, and
+// reports whether a redeploy is needed together with a unified diff of the changes.
+func (s *PortainerMCPServer) HandleDetectDrift() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		var stacks []models.RegularStack
+		if id != 0 {
+			if err := validatePositiveID("id", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			stack, err := s.cli.InspectStack(id)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to inspect stack", err), nil
+			}
+			if stack.Git == nil {
+				return mcp.NewToolResultError(fmt.Sprintf("stack %d is not deployed from a git repository", id)), nil
+			}
+			stacks = append(stacks, stack)
+		} else {
+			all, err := s.cli.GetRegularStacks()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get regular stacks", err), nil
+			}
+			for _, stack := range all {
+				if stack.Git != nil {
+					stacks = append(stacks, stack)
+				}
+			}
+		}
+
+		reports := make([]stackDrift, 0, len(stacks))
+		for _, stack := range stacks {
+			report := s.detectStackDrift(stack)
+			if strings.HasSuffix(report.Diff, driftTruncatedMarker) {
+				markTruncated(ctx)
+			}
+			reports = append(reports, report)
+		}
+
+		return jsonResult(reports, "failed to marshal drift report")
+	}
+}
+
+// detectStackDrift compares the deployed file of a git-backed stack with its git reference.
+// Errors are reported in the result so that one unreachable repository does not hide the
+// state of the other stacks.
+func (s *PortainerMCPServer) detectStackDrift(stack models.RegularStack) stackDrift {
+	report := stackDrift{
+		StackID:        stack.ID,
+		Name:           stack.Name,
+		EnvironmentID:  stack.EndpointID,
+		Repository:     stack.Git.URL,
+		Reference:      stack.Git.ReferenceName,
+		File:           stack.Git.ConfigFilePath,
+		DeployedCommit: stack.Git.ConfigHash,
+	}
+
+	deployed, err := s.cli.InspectStackFile(stack.ID)
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to get deployed file: %v", err)
+		return report
+	}
+
+	remote, err := s.cli.GetGitRepositoryFile(*stack.Git)
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to get file from git reference: %v", err)
+		return report
+	}
+
+	deployedLines := difflib.SplitLines(normalizeLineEndings(deployed))
+	remoteLines := difflib.SplitLines(normalizeLineEndings(remote))
+
+	for _, op := range difflib.NewMatcher(deployedLines, remoteLines).GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			report.LinesRemoved += op.I2 - op.I1
+			report.LinesAdded += op.J2 - op.J1
+		case 'd':
+			report.LinesRemoved += op.I2 - op.I1
+		case 'i':
+			report.LinesAdded += op.J2 - op.J1
+		}
+	}
+	report.RedeployNeeded = report.LinesAdded > 0 || report.LinesRemoved > 0
+	if !report.RedeployNeeded {
+		return report
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        deployedLines,
+		B:        remoteLines,
+		FromFile: "deployed",
+		ToFile:   "remote " + report.Reference,
+		Context:  driftDiffContext,
+	})
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to compute diff: %v", err)
+		return report
+	}
+	if len(diff) > maxDriftDiffSize {
+		diff = diff[:maxDriftDiffSize] + driftTruncatedMarker
+	}
+	report.Diff = diff
+
+	return report
+}
+
+// normalizeLineEndings converts CRLF line endings to LF and ensures a single trailing
+// newline, so that files differing only in those respects are not reported as drifted.
+func normalizeLineEndings(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.TrimRight(content, "\n") + "\n"
+}
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleDetectDrift verifies drift detection for git-backed stacks.
+func TestHandleDetectDrift(t *testing.T) {
+	git := &models.StackGitConfig{URL: "https://github.com/acme/deploy.git", ReferenceName: "refs/heads/main", ConfigFilePath: "compose.yml", ConfigHash: "abc123"}
+	gitStack := models.RegularStack{ID: 1, Name: "web", EndpointID: 2, Git: git}
+	otherGit := &models.StackGitConfig{URL: "https://github.com/acme/private.git", ReferenceName: "refs/heads/main"}
+	deployed := "services:\n  web:\n    image: nginx:1.25\n"
+	remote := "services:\n  web:\n    image: nginx:1.27\n    restart: always\n"
+
+	tests := []struct {
+		name        string
+		params      map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError bool
+		check       func(t *testing.T, reports []stackDrift)
+	}{
+		{
+			name:   "single stack drifted",
+			params: map[string]any{"id": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 1).Return(gitStack, nil)
+				m.On("InspectStackFile", 1).Return(deployed, nil)
+				m.On("GetGitRepositoryFile", *git).Return(remote, nil)
+			},
+			check: func(t *testing.T, reports []stackDrift) {
+				require.Len(t, reports, 1)
+				r := reports[0]
+				assert.True(t, r.RedeployNeeded)
+				assert.Equal(t, "abc123", r.DeployedCommit)
+				assert.Equal(t, 2, r.LinesAdded)
+				assert.Equal(t, 1, r.LinesRemoved)
+				assert.Contains(t, r.Diff, "-    image: nginx:1.25")
+				assert.Contains(t, r.Diff, "+    restart: always")
+			},
+		},
+		{
+			name:   "line endings do not count as drift",
+			params: map[string]any{"id": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 1).Return(gitStack, nil)
+				m.On("InspectStackFile", 1).Return(deployed, nil)
+				m.On("GetGitRepositoryFile", *git).Return("services:\r\n  web:\r\n    image: nginx:1.25\r\n\r\n", nil)
+			},
+			check: func(t *testing.T, reports []stackDrift) {
+				require.Len(t, reports, 1)
+				assert.False(t, reports[0].RedeployNeeded)
+				assert.Empty(t, reports[0].Diff)
+			},
+		},
+		{
+			name:   "all git-backed stacks with per-stack errors",
+			params: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRegularStacks").Return([]models.RegularStack{
+					gitStack,
+					{ID: 2, Name: "manual"},
+					{ID: 3, Name: "private", Git: otherGit},
+				}, nil)
+				m.On("InspectStackFile", 1).Return(deployed, nil)
+				m.On("GetGitRepositoryFile", *git).Return(deployed, nil)
+				m.On("InspectStackFile", 3).Return(deployed, nil)
+				m.On("GetGitRepositoryFile", *otherGit).Return("", fmt.Errorf("authentication required"))
+			},
+			check: func(t *testing.T, reports []stackDrift) {
+				require.Len(t, reports, 2)
+				assert.Equal(t, 1, reports[0].StackID)
+				assert.False(t, reports[0].RedeployNeeded)
+				assert.Equal(t, 3, reports[1].StackID)
+				assert.Contains(t, reports[1].Error, "authentication required")
+			},
+		},
+		{
+			name:   "stack not git-backed",
+			params: map[string]any{"id": float64(2)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 2).Return(models.RegularStack{ID: 2, Name: "manual"}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name:   "inspect error",
+			params: map[string]any{"id": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 1).Return(models.RegularStack{}, fmt.Errorf("not found"))
+			},
+			expectError: true,
+		},
+		{
+			name:        "invalid id",
+			params:      map[string]any{"id": float64(-1)},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleDetectDrift()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var reports []stackDrift
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &reports))
+			tt.check(t, reports)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -148,6 +148,11 @@ func isValidCronExpression(expr string) bool {
 // AddEdgeUpdateScheduleFeatures registers the edge job and edge update schedule management tools on the MCP server.
 func (s *PortainerMCPServer) AddEdgeUpdateScheduleFeatures() {
 	s.addToolIfExists(ToolListEdgeUpdateSchedules, s.HandleListEdgeUpdateSchedules())
+	s.addToolIfExists(ToolGetAgentVersionReport, s.HandleGetAgentVersionReport())
+
+	if !s.readOnly {
+		s.addToolIfExists(ToolScheduleAgentUpgrades, s.HandleScheduleAgentUpgrades())
+	}
 }
 
 // HandleListEdgeUpdateSchedules returns an MCP tool handler that lists edge update schedules.
@@ -2,9 +2,12 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -12,12 +15,23 @@ import (
 func (s *PortainerMCPServer) AddEnvironmentFeatures() {
 	s.addToolIfExists(ToolListEnvironments, s.HandleGetEnvironments())
 	s.addToolIfExists(ToolGetEnvironment, s.HandleGetEnvironment())
+	s.addToolIfExists(ToolWhoCanAccessEnvironment, s.HandleWhoCanAccessEnvironment())
+	s.addToolIfExists(ToolGetSnapshotSettings, s.HandleGetSnapshotSettings())
+	s.addToolIfExists(ToolGetGroupCapacity, s.HandleGetGroupCapacity())
+	s.addToolIfExists(ToolGetTrends, s.HandleGetTrends())
+	s.addToolIfExists(ToolBuildTimeline, s.HandleBuildTimeline())
+	s.addToolIfExists(ToolListMaintenanceEnvironments, s.HandleListMaintenanceEnvironments())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolDeleteEnvironment, s.HandleDeleteEnvironment())
+		s.addToolIfExists(ToolOnboardEnvironment, s.HandleOnboardEnvironment())
 		s.addToolIfExists(ToolSnapshotEnvironment, s.HandleSnapshotEnvironment())
 		s.addToolIfExists(ToolSnapshotAllEnvironments, s.HandleSnapshotAllEnvironments())
+		s.addToolIfExists(ToolUpdateSnapshotSettings, s.HandleUpdateSnapshotSettings())
 		s.addToolIfExists(ToolUpdateEnvironmentTags, s.HandleUpdateEnvironmentTags())
+		s.addToolIfExists(ToolRetagEnvironments, s.HandleRetagEnvironments())
+		s.addToolIfExists(ToolEnterMaintenanceMode, s.HandleEnterMaintenanceMode())
+		s.addToolIfExists(ToolExitMaintenanceMode, s.HandleExitMaintenanceMode())
 		s.addToolIfExists(ToolUpdateEnvironmentUserAccesses, s.HandleUpdateEnvironmentUserAccesses())
 		s.addToolIfExists(ToolUpdateEnvironmentTeamAccesses, s.HandleUpdateEnvironmentTeamAccesses())
 	}
@@ -26,12 +40,51 @@ func (s *PortainerMCPServer) AddEnvironmentFeatures() {
 // HandleGetEnvironments returns an MCP tool handler that retrieves environments.
 func (s *PortainerMCPServer) HandleGetEnvironments() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		environments, err := s.cli.GetEnvironments()
+		parser := toolgen.NewParameterParser(request)
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		search, err := parser.GetString("search", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid search parameter", err), nil
+		}
+
+		tagId, err := parser.GetInt("tagId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tagId parameter", err), nil
+		}
+
+		accessGroupId, err := parser.GetInt("accessGroupId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid accessGroupId parameter", err), nil
+		}
+
+		status, err := parser.GetString("status", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid status parameter", err), nil
+		}
+
+		envType, err := parser.GetString("type", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid type parameter", err), nil
+		}
+
+		environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{
+			Name:    name,
+			Search:  search,
+			TagID:   tagId,
+			GroupID: accessGroupId,
+			Status:  status,
+			Type:    envType,
+		})
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
 		}
 
-		return jsonResult(environments, "failed to marshal environments")
+		return listResult(request, environments, "failed to marshal environments")
 	}
 }
 
@@ -57,7 +110,9 @@ func (s *PortainerMCPServer) HandleGetEnvironment() server.ToolHandlerFunc {
 	}
 }
 
-// HandleDeleteEnvironment returns an MCP tool handler that deletes environment.
+// HandleDeleteEnvironment returns an MCP tool handler that deletes environment. Its
+// dependent stacks, webhooks and edge jobs block the deletion, are deleted with it, or are
+// left orphaned, as the onDependents parameter chooses.
 func (s *PortainerMCPServer) HandleDeleteEnvironment() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		parser := toolgen.NewParameterParser(request)
@@ -70,12 +125,56 @@ func (s *PortainerMCPServer) HandleDeleteEnvironment() server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		onDependents, err := parser.GetString("onDependents", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid onDependents parameter", err), nil
+		}
+		switch onDependents {
+		case "":
+			onDependents = dependentsBlock
+		case dependentsBlock, dependentsCascade, dependentsOrphan:
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("invalid onDependents parameter %q: must be block, cascade or orphan", onDependents)), nil
+		}
+
+		dependents, err := s.listEnvironmentDependents(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list the dependents of the environment", err), nil
+		}
+		dependents.OnDependents = onDependents
+
+		if dependents.count() > 0 {
+			switch onDependents {
+			case dependentsBlock:
+				return newToolResultErrorWithCode(ErrorCodeValidation, fmt.Sprintf(
+					"environment %d still has dependents: %s. Set onDependents to cascade to delete them with the environment, or to orphan to leave them behind",
+					id, dependents.summary(),
+				)), nil
+			case dependentsCascade:
+				calls := dependents.cascadeCalls()
+				if err := s.authorizeCascade(ctx, calls); err != nil {
+					return newToolResultErrorWithCode(ErrorCodeForbidden, fmt.Sprintf("environment %d was not deleted: %v", id, err)), nil
+				}
+				if err := s.deleteEnvironmentDependents(dependents); err != nil {
+					s.releaseCascade(ctx, len(calls)-dependents.deleted())
+					report, _ := json.Marshal(dependents)
+					return mcp.NewToolResultError(fmt.Sprintf("environment %d was not deleted: %v. Dependents: %s", id, err, report)), nil
+				}
+			case dependentsOrphan:
+				dependents.markOrphanedDependents()
+			}
+		}
+
 		err = s.cli.DeleteEnvironment(id)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to delete environment", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment deleted successfully"), nil
+		if dependents.count() == 0 {
+			return mcp.NewToolResultText("Environment deleted successfully"), nil
+		}
+		dependents.Deleted = true
+		return jsonResult(dependents, "failed to marshal environment deletion report")
 	}
 }
 
@@ -153,7 +252,7 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentUserAccesses() server.ToolHa
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		userAccesses, err := parser.GetArrayOfObjects("userAccesses", true)
+		userAccesses, err := parser.GetArrayOfObjectMaps("userAccesses", true)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid userAccesses parameter", err), nil
 		}
@@ -185,7 +284,7 @@ func (s *PortainerMCPServer) HandleUpdateEnvironmentTeamAccesses() server.ToolHa
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		teamAccesses, err := parser.GetArrayOfObjects("teamAccesses", true)
+		teamAccesses, err := parser.GetArrayOfObjectMaps("teamAccesses", true)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid teamAccesses parameter", err), nil
 		}
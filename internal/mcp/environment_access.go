@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Sources of the effective access of a user to an environment, in the order in which
+// Portainer resolves them.
+const (
+	userAccessSourceAdministrator   = "administrator"
+	userAccessSourceEnvironmentUser = "environment_user_policy"
+	userAccessSourceAccessGroupUser = "access_group_user_policy"
+	userAccessSourceEnvironmentTeam = "environment_team_policy"
+	userAccessSourceAccessGroupTeam = "access_group_team_policy"
+)
+
+// accessLevelFullAccess is the access level of administrators, who reach every
+// environment whatever its policies.
+const accessLevelFullAccess = "full_access"
+
+// accessLevelPriority is the priority of the default Portainer roles, lower being more
+// privileged. When several teams of a user grant access at the same level of precedence,
+// Portainer applies the most privileged role.
+var accessLevelPriority = map[string]int{
+	"environment_administrator": 1,
+	"operator_user":             2,
+	"helpdesk_user":             3,
+	"standard_user":             4,
+	"readonly_user":             5,
+}
+
+// environmentUserAccess is the effective access of a user to an environment.
+type environmentUserAccess struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	UserRole string `json:"user_role"`
+	// AccessLevel is the environment role of the user, or full_access for administrators.
+	AccessLevel string `json:"access_level"`
+	// Source is the policy that grants the access.
+	Source string `json:"source"`
+	// TeamID and TeamName are the team whose policy grants the access.
+	TeamID   int    `json:"team_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+}
+
+// environmentAccessReport is the result of HandleWhoCanAccessEnvironment.
+type environmentAccessReport struct {
+	EnvironmentID   int                     `json:"environment_id"`
+	EnvironmentName string                  `json:"environment_name"`
+	AccessGroupID   int                     `json:"access_group_id"`
+	AccessGroupName string                  `json:"access_group_name,omitempty"`
+	Users           []environmentUserAccess `json:"users"`
+	// AccessLevels counts the users by effective access level.
+	AccessLevels map[string]int `json:"access_levels"`
+}
+
+// HandleWhoCanAccessEnvironment returns an MCP tool handler that lists the users who can
+// access an environment with their effective role, resolved from the user and team
+// policies of the environment and of its access group.
+func (s *PortainerMCPServer) HandleWhoCanAccessEnvironment() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		environment, err := s.cli.GetEnvironment(environmentId)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environment", err), nil
+		}
+		accessGroups, err := s.cli.GetAccessGroups()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get access groups", err), nil
+		}
+		users, err := s.cli.GetUsers(models.UserListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get users", err), nil
+		}
+		teams, err := s.cli.GetTeams()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get teams", err), nil
+		}
+
+		var group models.AccessGroup
+		if i := slices.IndexFunc(accessGroups, func(g models.AccessGroup) bool { return g.ID == environment.GroupID }); i >= 0 {
+			group = accessGroups[i]
+		}
+
+		return jsonResult(resolveEnvironmentAccess(environment, group, users, teams), "failed to marshal environment access report")
+	}
+}
+
+// resolveEnvironmentAccess resolves the effective access of every user to an environment
+// in the order Portainer applies: administrators have full access, then the user policy of
+// the environment, the user policy of its access group, the policies of the user's teams on
+// the environment and the policies of the user's teams on the access group. Users without
+// any policy are left out.
+func resolveEnvironmentAccess(environment models.Environment, group models.AccessGroup, users []models.User, teams []models.Team) environmentAccessReport {
+	report := environmentAccessReport{
+		EnvironmentID:   environment.ID,
+		EnvironmentName: environment.Name,
+		AccessGroupID:   environment.GroupID,
+		AccessGroupName: group.Name,
+		Users:           []environmentUserAccess{},
+		AccessLevels:    map[string]int{},
+	}
+
+	userTeams := map[int][]models.Team{}
+	for _, team := range teams {
+		for _, userID := range team.MemberIDs {
+			userTeams[userID] = append(userTeams[userID], team)
+		}
+	}
+
+	for _, user := range users {
+		access := environmentUserAccess{UserID: user.ID, Username: user.Username, UserRole: user.Role}
+		switch {
+		case user.Role == models.UserRoleAdmin || user.Role == models.UserRoleEdgeAdmin:
+			access.AccessLevel, access.Source = accessLevelFullAccess, userAccessSourceAdministrator
+		case environment.UserAccesses[user.ID] != "":
+			access.AccessLevel, access.Source = environment.UserAccesses[user.ID], userAccessSourceEnvironmentUser
+		case group.UserAccesses[user.ID] != "":
+			access.AccessLevel, access.Source = group.UserAccesses[user.ID], userAccessSourceAccessGroupUser
+		default:
+			if team, level, ok := strongestTeamAccess(userTeams[user.ID], environment.TeamAccesses); ok {
+				access.AccessLevel, access.Source, access.TeamID, access.TeamName = level, userAccessSourceEnvironmentTeam, team.ID, team.Name
+			} else if team, level, ok := strongestTeamAccess(userTeams[user.ID], group.TeamAccesses); ok {
+				access.AccessLevel, access.Source, access.TeamID, access.TeamName = level, userAccessSourceAccessGroupTeam, team.ID, team.Name
+			}
+		}
+		if access.Source == "" {
+			continue
+		}
+		report.Users = append(report.Users, access)
+		report.AccessLevels[access.AccessLevel]++
+	}
+
+	slices.SortFunc(report.Users, func(a, b environmentUserAccess) int { return a.UserID - b.UserID })
+	return report
+}
+
+// strongestTeamAccess returns the team with the most privileged policy among the given
+// teams, and the access level of that policy.
+func strongestTeamAccess(teams []models.Team, policies map[int]string) (models.Team, string, bool) {
+	var best models.Team
+	bestLevel, found := "", false
+	for _, team := range teams {
+		level, ok := policies[team.ID]
+		if !ok {
+			continue
+		}
+		if !found || accessLevelRank(level) < accessLevelRank(bestLevel) {
+			best, bestLevel, found = team, level, true
+		}
+	}
+	return best, bestLevel, found
+}
+
+// accessLevelRank returns the priority of an access level, unknown levels ranking last.
+func accessLevelRank(level string) int {
+	if priority, ok := accessLevelPriority[level]; ok {
+		return priority
+	}
+	return len(accessLevelPriority) + 1
+}
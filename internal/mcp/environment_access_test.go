@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolveEnvironmentAccess verifies the precedence of the access policies of an
+// environment and of its access group.
+func TestResolveEnvironmentAccess(t *testing.T) {
+	environment := models.Environment{
+		ID:           3,
+		Name:         "prod",
+		GroupID:      2,
+		UserAccesses: map[int]string{2: "readonly_user"},
+		TeamAccesses: map[int]string{10: "standard_user", 11: "operator_user"},
+	}
+	group := models.AccessGroup{
+		ID:           2,
+		Name:         "production",
+		UserAccesses: map[int]string{2: "environment_administrator", 3: "helpdesk_user"},
+		TeamAccesses: map[int]string{12: "environment_administrator"},
+	}
+	users := []models.User{
+		{ID: 1, Username: "admin", Role: models.UserRoleAdmin},
+		{ID: 2, Username: "alice", Role: models.UserRoleUser},
+		{ID: 3, Username: "bob", Role: models.UserRoleUser},
+		{ID: 4, Username: "carol", Role: models.UserRoleUser},
+		{ID: 5, Username: "dave", Role: models.UserRoleUser},
+		{ID: 6, Username: "erin", Role: models.UserRoleUser},
+	}
+	teams := []models.Team{
+		{ID: 10, Name: "devs", MemberIDs: []int{3, 4}},
+		{ID: 11, Name: "ops", MemberIDs: []int{4}},
+		{ID: 12, Name: "dba", MemberIDs: []int{4, 5}},
+	}
+
+	report := resolveEnvironmentAccess(environment, group, users, teams)
+
+	assert.Equal(t, "production", report.AccessGroupName)
+	assert.Equal(t, []environmentUserAccess{
+		{UserID: 1, Username: "admin", UserRole: "admin", AccessLevel: accessLevelFullAccess, Source: userAccessSourceAdministrator},
+		{UserID: 2, Username: "alice", UserRole: "user", AccessLevel: "readonly_user", Source: userAccessSourceEnvironmentUser},
+		{UserID: 3, Username: "bob", UserRole: "user", AccessLevel: "helpdesk_user", Source: userAccessSourceAccessGroupUser},
+		{UserID: 4, Username: "carol", UserRole: "user", AccessLevel: "operator_user", Source: userAccessSourceEnvironmentTeam, TeamID: 11, TeamName: "ops"},
+		{UserID: 5, Username: "dave", UserRole: "user", AccessLevel: "environment_administrator", Source: userAccessSourceAccessGroupTeam, TeamID: 12, TeamName: "dba"},
+	}, report.Users, "erin has no policy and is left out")
+	assert.Equal(t, map[string]int{
+		accessLevelFullAccess:       1,
+		"readonly_user":             1,
+		"helpdesk_user":             1,
+		"operator_user":             1,
+		"environment_administrator": 1,
+	}, report.AccessLevels)
+}
+
+// TestHandleWhoCanAccessEnvironment verifies the parameters and the errors of the
+// whoCanAccessEnvironment tool.
+func TestHandleWhoCanAccessEnvironment(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+	}{
+		{
+			name: "report",
+			args: map[string]any{"environmentId": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 3).Return(models.Environment{ID: 3, Name: "prod", GroupID: 2}, nil)
+				m.On("GetAccessGroups").Return([]models.AccessGroup{{ID: 2, Name: "production", TeamAccesses: map[int]string{10: "standard_user"}}}, nil)
+				m.On("GetUsers", models.UserListOptions{}).Return([]models.User{{ID: 4, Username: "carol", Role: models.UserRoleUser}}, nil)
+				m.On("GetTeams").Return([]models.Team{{ID: 10, Name: "devs", MemberIDs: []int{4}}}, nil)
+			},
+		},
+		{name: "missing environmentId", args: map[string]any{}, expectError: "invalid environmentId parameter"},
+		{
+			name: "unknown environment",
+			args: map[string]any{"environmentId": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 3).Return(models.Environment{}, errors.New("not found"))
+			},
+			expectError: "failed to get environment",
+		},
+		{
+			name: "teams error",
+			args: map[string]any{"environmentId": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 3).Return(models.Environment{ID: 3}, nil)
+				m.On("GetAccessGroups").Return([]models.AccessGroup{}, nil)
+				m.On("GetUsers", models.UserListOptions{}).Return([]models.User{}, nil)
+				m.On("GetTeams").Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get teams",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleWhoCanAccessEnvironment()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			var report environmentAccessReport
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			require.Len(t, report.Users, 1)
+			assert.Equal(t, "standard_user", report.Users[0].AccessLevel)
+			assert.Equal(t, "devs", report.Users[0].TeamName)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// Values of the onDependents parameter of deleteEnvironment.
+const (
+	// dependentsBlock refuses to delete an environment that still has dependents.
+	dependentsBlock = "block"
+	// dependentsCascade deletes the dependents before the environment.
+	dependentsCascade = "cascade"
+	// dependentsOrphan deletes the environment and reports the dependents left behind.
+	dependentsOrphan = "orphan"
+)
+
+// Outcomes of the dependents of a deleted environment.
+const (
+	dependentDeleted  = "deleted"
+	dependentOrphaned = "orphaned"
+	// dependentKept is an edge job that also targets other environments: Portainer
+	// removes the deleted environment from its targets.
+	dependentKept = "kept"
+)
+
+// environmentDependent is a resource that depends on an environment.
+type environmentDependent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Outcome is what happened to the resource when the environment was deleted.
+	Outcome string `json:"outcome,omitempty"`
+	// JournalEntry is the delete journal entry of a deleted resource.
+	JournalEntry int    `json:"journal_entry,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// exclusive is set on edge jobs targeting no other environment, which cascade deletes.
+	exclusive bool
+}
+
+// environmentDependents are the stacks, webhooks and edge jobs depending on an
+// environment, and the result of its deletion.
+type environmentDependents struct {
+	EnvironmentID int                    `json:"environment_id"`
+	OnDependents  string                 `json:"on_dependents"`
+	Deleted       bool                   `json:"deleted"`
+	Stacks        []environmentDependent `json:"stacks"`
+	Webhooks      []environmentDependent `json:"webhooks"`
+	EdgeJobs      []environmentDependent `json:"edge_jobs"`
+}
+
+// count returns the number of dependents.
+func (d *environmentDependents) count() int {
+	return len(d.Stacks) + len(d.Webhooks) + len(d.EdgeJobs)
+}
+
+// summary describes the dependents in one line, such as "stacks web (3); edge jobs
+// cleanup (2)".
+func (d *environmentDependents) summary() string {
+	var parts []string
+	for _, group := range []struct {
+		label      string
+		dependents []environmentDependent
+	}{{"stacks", d.Stacks}, {"webhooks", d.Webhooks}, {"edge jobs", d.EdgeJobs}} {
+		if len(group.dependents) == 0 {
+			continue
+		}
+		names := make([]string, len(group.dependents))
+		for i, dependent := range group.dependents {
+			names[i] = fmt.Sprintf("%s (%d)", dependent.Name, dependent.ID)
+		}
+		parts = append(parts, group.label+" "+strings.Join(names, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// listEnvironmentDependents lists the regular stacks deployed on an environment, the
+// webhooks of its services and containers, and the edge jobs targeting it directly.
+func (s *PortainerMCPServer) listEnvironmentDependents(id int) (*environmentDependents, error) {
+	dependents := &environmentDependents{
+		EnvironmentID: id,
+		Stacks:        []environmentDependent{},
+		Webhooks:      []environmentDependent{},
+		EdgeJobs:      []environmentDependent{},
+	}
+
+	stacks, err := s.cli.GetRegularStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stacks: %w", err)
+	}
+	for _, stack := range stacks {
+		if stack.EndpointID == id {
+			dependents.Stacks = append(dependents.Stacks, environmentDependent{ID: stack.ID, Name: stack.Name})
+		}
+	}
+
+	webhooks, err := s.cli.GetWebhooks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	for _, webhook := range webhooks {
+		if webhook.EndpointID == id {
+			dependents.Webhooks = append(dependents.Webhooks, environmentDependent{ID: webhook.ID, Name: webhook.ResourceID})
+		}
+	}
+
+	jobs, err := s.cli.GetEdgeJobs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge jobs: %w", err)
+	}
+	for _, job := range jobs {
+		if slices.Contains(job.Endpoints, id) {
+			exclusive := len(job.EdgeGroups) == 0 && !slices.ContainsFunc(job.Endpoints, func(e int) bool { return e != id })
+			dependents.EdgeJobs = append(dependents.EdgeJobs, environmentDependent{ID: job.ID, Name: job.Name, exclusive: exclusive})
+		}
+	}
+
+	return dependents, nil
+}
+
+// cascadeCalls returns the tool calls that would delete, one by one, the dependents that a
+// cascade deletes, in the order the cascade deletes them.
+func (d *environmentDependents) cascadeCalls() []mcp.CallToolRequest {
+	var calls []mcp.CallToolRequest
+	call := func(tool string, args map[string]any) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool
+		request.Params.Arguments = args
+		calls = append(calls, request)
+	}
+	for _, stack := range d.Stacks {
+		call(ToolDeleteStack, map[string]any{"id": stack.ID, "environmentId": d.EnvironmentID})
+	}
+	for _, webhook := range d.Webhooks {
+		call(ToolDeleteWebhook, map[string]any{"id": webhook.ID})
+	}
+	for _, job := range d.EdgeJobs {
+		if job.exclusive {
+			call(ToolDeleteEdgeJob, map[string]any{"id": job.ID})
+		}
+	}
+	return calls
+}
+
+// deleted returns the number of dependents deleted by a cascade.
+func (d *environmentDependents) deleted() int {
+	deleted := 0
+	for _, group := range [][]environmentDependent{d.Stacks, d.Webhooks, d.EdgeJobs} {
+		for _, dependent := range group {
+			if dependent.Outcome == dependentDeleted {
+				deleted++
+			}
+		}
+	}
+	return deleted
+}
+
+// authorizeCascade checks each deletion of a cascade against the policy and charges them
+// all to the budget of the session, charging nothing when the cascade is refused.
+func (s *PortainerMCPServer) authorizeCascade(ctx context.Context, calls []mcp.CallToolRequest) error {
+	if s.policy != nil {
+		for _, call := range calls {
+			decision, err := s.evaluatePolicy(call)
+			if err != nil {
+				s.recordPolicyDecision(ctx, call, policy.Decision{Reason: err.Error()})
+				return fmt.Errorf("failed to evaluate the policy rules for %s %v: %w", call.Params.Name, call.GetArguments()["id"], err)
+			}
+			s.recordPolicyDecision(ctx, call, decision)
+			if !decision.Allowed {
+				message := fmt.Sprintf("%s %v is denied by policy rule %q", call.Params.Name, call.GetArguments()["id"], decision.Rule)
+				if decision.Reason != "" {
+					message += ": " + decision.Reason
+				}
+				return errors.New(message)
+			}
+		}
+	}
+
+	if s.budget != nil {
+		for i := range calls {
+			if err := s.budget.reserve(sessionID(ctx), operationDestructive); err != nil {
+				s.releaseCascade(ctx, i)
+				return fmt.Errorf("the cascade deletes %d dependents, more than the remaining budget: %w", len(calls), err)
+			}
+		}
+	}
+	return nil
+}
+
+// releaseCascade returns to the budget of the session the given number of deletions of a
+// cascade that were charged but not performed.
+func (s *PortainerMCPServer) releaseCascade(ctx context.Context, count int) {
+	if s.budget == nil {
+		return
+	}
+	for range count {
+		s.budget.release(sessionID(ctx), operationDestructive)
+	}
+}
+
+// deleteEnvironmentDependents deletes the dependents of an environment before it is
+// deleted: its stacks, without their volumes, its webhooks, and the edge jobs targeting
+// no other environment. Deleted dependents are recorded in the delete journal. It stops
+// at the first failure, which is returned.
+func (s *PortainerMCPServer) deleteEnvironmentDependents(d *environmentDependents) error {
+	for i := range d.Stacks {
+		stack := &d.Stacks[i]
+		entry := s.captureDeletion(journal.KindStack, stack.ID, func() (journal.Entry, error) {
+			return s.captureStack(stack.ID, d.EnvironmentID, false)
+		})
+		if err := s.cli.DeleteStack(stack.ID, d.EnvironmentID, false); err != nil {
+			stack.Error = err.Error()
+			return fmt.Errorf("failed to delete stack %d: %w", stack.ID, err)
+		}
+		stack.Outcome = dependentDeleted
+		stack.JournalEntry = s.recordCascadedDeletion(ToolDeleteStack, entry)
+	}
+
+	for i := range d.Webhooks {
+		webhook := &d.Webhooks[i]
+		entry := s.captureDeletion(journal.KindWebhook, webhook.ID, func() (journal.Entry, error) {
+			return s.captureWebhook(webhook.ID)
+		})
+		if err := s.cli.DeleteWebhook(webhook.ID); err != nil {
+			webhook.Error = err.Error()
+			return fmt.Errorf("failed to delete webhook %d: %w", webhook.ID, err)
+		}
+		webhook.Outcome = dependentDeleted
+		webhook.JournalEntry = s.recordCascadedDeletion(ToolDeleteWebhook, entry)
+	}
+
+	for i := range d.EdgeJobs {
+		job := &d.EdgeJobs[i]
+		if !job.exclusive {
+			job.Outcome = dependentKept
+			continue
+		}
+		entry := s.captureDeletion(journal.KindEdgeJob, job.ID, func() (journal.Entry, error) {
+			return s.captureEdgeJob(job.ID)
+		})
+		if err := s.cli.DeleteEdgeJob(job.ID); err != nil {
+			job.Error = err.Error()
+			return fmt.Errorf("failed to delete edge job %d: %w", job.ID, err)
+		}
+		job.Outcome = dependentDeleted
+		job.JournalEntry = s.recordCascadedDeletion(ToolDeleteEdgeJob, entry)
+	}
+	return nil
+}
+
+// markOrphanedDependents records the outcome of the dependents of an environment deleted
+// without them.
+func (d *environmentDependents) markOrphanedDependents() {
+	for _, group := range [][]environmentDependent{d.Stacks, d.Webhooks} {
+		for i := range group {
+			group[i].Outcome = dependentOrphaned
+		}
+	}
+	for i := range d.EdgeJobs {
+		d.EdgeJobs[i].Outcome = dependentKept
+		if d.EdgeJobs[i].exclusive {
+			d.EdgeJobs[i].Outcome = dependentOrphaned
+		}
+	}
+}
+
+// recordCascadedDeletion records a resource deleted with its environment in the delete
+// journal and returns the ID of its entry, or 0 when it was not recorded.
+func (s *PortainerMCPServer) recordCascadedDeletion(tool string, entry *journal.Entry) int {
+	if s.deleteJournal == nil || entry == nil {
+		return 0
+	}
+	entry.Tool = tool
+	recorded, err := s.deleteJournal.Record(*entry)
+	if err != nil {
+		log.Warn().Err(err).Str("tool", tool).Msg("failed to record delete journal entry")
+		return 0
+	}
+	return recorded.ID
+}
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/policy"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dependentsTestClient returns a client whose environment 3 has a stack, a webhook, an
+// edge job targeting only it and an edge job also targeting environment 4.
+func dependentsTestClient() *MockPortainerClient {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetRegularStacks").Return([]models.RegularStack{
+		{ID: 1, Name: "web", EndpointID: 3},
+		{ID: 2, Name: "db", EndpointID: 4},
+	}, nil)
+	mockClient.On("GetWebhooks").Return([]models.Webhook{
+		{ID: 5, EndpointID: 3, ResourceID: "web_app"},
+		{ID: 6, EndpointID: 4, ResourceID: "db_app"},
+	}, nil)
+	mockClient.On("GetEdgeJobs").Return([]models.EdgeJob{
+		{ID: 7, Name: "cleanup", Endpoints: []int{3}},
+		{ID: 8, Name: "backup", Endpoints: []int{3, 4}},
+		{ID: 9, Name: "unrelated", Endpoints: []int{4}},
+	}, nil)
+	return mockClient
+}
+
+// deleteEnvironment calls deleteEnvironment for environment 3.
+func deleteEnvironment(t *testing.T, mockClient *MockPortainerClient, onDependents string) (*mcp.CallToolResult, string) {
+	t.Helper()
+	args := map[string]any{"id": float64(3)}
+	if onDependents != "" {
+		args["onDependents"] = onDependents
+	}
+	s := &PortainerMCPServer{cli: mockClient}
+	result, err := s.HandleDeleteEnvironment()(context.Background(), CreateMCPRequest(args))
+	require.NoError(t, err)
+	return result, result.Content[0].(mcp.TextContent).Text
+}
+
+// TestHandleDeleteEnvironmentDependents verifies that the dependents of an environment
+// block its deletion, are deleted with it, or are reported as orphaned.
+func TestHandleDeleteEnvironmentDependents(t *testing.T) {
+	t.Run("block", func(t *testing.T) {
+		mockClient := dependentsTestClient()
+		result, text := deleteEnvironment(t, mockClient, "")
+		assert.True(t, result.IsError)
+		assert.Equal(t, ErrorCodeValidation, errorCode(result))
+		assert.Contains(t, text, "stacks web (1); webhooks web_app (5); edge jobs cleanup (7), backup (8)")
+		mockClient.AssertNotCalled(t, "DeleteEnvironment", 3)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		mockClient := dependentsTestClient()
+		mockClient.On("DeleteStack", 1, 3, false).Return(nil)
+		mockClient.On("DeleteWebhook", 5).Return(nil)
+		mockClient.On("DeleteEdgeJob", 7).Return(nil)
+		mockClient.On("DeleteEnvironment", 3).Return(nil)
+
+		result, text := deleteEnvironment(t, mockClient, dependentsCascade)
+		require.False(t, result.IsError, text)
+		var report environmentDependents
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.True(t, report.Deleted)
+		assert.Equal(t, dependentsCascade, report.OnDependents)
+		assert.Equal(t, dependentDeleted, report.Stacks[0].Outcome)
+		assert.Equal(t, dependentDeleted, report.Webhooks[0].Outcome)
+		assert.Equal(t, dependentDeleted, report.EdgeJobs[0].Outcome)
+		assert.Equal(t, dependentKept, report.EdgeJobs[1].Outcome, "jobs targeting other environments are kept")
+		mockClient.AssertExpectations(t)
+		mockClient.AssertNotCalled(t, "DeleteEdgeJob", 8)
+	})
+
+	t.Run("cascade failure", func(t *testing.T) {
+		mockClient := dependentsTestClient()
+		mockClient.On("DeleteStack", 1, 3, false).Return(nil)
+		mockClient.On("DeleteWebhook", 5).Return(fmt.Errorf("webhook locked"))
+
+		result, text := deleteEnvironment(t, mockClient, dependentsCascade)
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "environment 3 was not deleted: failed to delete webhook 5: webhook locked")
+		assert.Contains(t, text, `"outcome":"deleted"`, "the dependents already deleted are reported")
+		mockClient.AssertNotCalled(t, "DeleteEnvironment", 3)
+	})
+
+	t.Run("orphan", func(t *testing.T) {
+		mockClient := dependentsTestClient()
+		mockClient.On("DeleteEnvironment", 3).Return(nil)
+
+		result, text := deleteEnvironment(t, mockClient, dependentsOrphan)
+		require.False(t, result.IsError, text)
+		var report environmentDependents
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.Equal(t, dependentOrphaned, report.Stacks[0].Outcome)
+		assert.Equal(t, dependentOrphaned, report.Webhooks[0].Outcome)
+		assert.Equal(t, dependentOrphaned, report.EdgeJobs[0].Outcome)
+		assert.Equal(t, dependentKept, report.EdgeJobs[1].Outcome)
+		mockClient.AssertNotCalled(t, "DeleteStack", 1, 3, false)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		result, text := deleteEnvironment(t, &MockPortainerClient{}, "force")
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "must be block, cascade or orphan")
+	})
+}
+
+// TestDeleteEnvironmentCascadeChecks verifies that the deletions of a cascade are checked
+// against the policy and charged to the budget one by one, and that they are recorded in
+// the delete journal.
+func TestDeleteEnvironmentCascadeChecks(t *testing.T) {
+	call := func(s *PortainerMCPServer) (*mcp.CallToolResult, string) {
+		result, err := s.HandleDeleteEnvironment()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(3), "onDependents": dependentsCascade}))
+		require.NoError(t, err)
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	t.Run("denied by policy", func(t *testing.T) {
+		engine, err := policy.New([]policy.Rule{{Name: "keep-jobs", Tools: []string{ToolDeleteEdgeJob}, Reason: "edge jobs are managed by the platform team"}})
+		require.NoError(t, err)
+		mockClient := dependentsTestClient()
+
+		result, text := call(&PortainerMCPServer{cli: mockClient, policy: engine})
+		assert.True(t, result.IsError)
+		assert.Equal(t, ErrorCodeForbidden, errorCode(result))
+		assert.Contains(t, text, `environment 3 was not deleted: deleteEdgeJob 7 is denied by policy rule "keep-jobs": edge jobs are managed by the platform team`)
+		mockClient.AssertNotCalled(t, "DeleteStack", 1, 3, false)
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		mockClient := dependentsTestClient()
+		s := &PortainerMCPServer{cli: mockClient, budget: newToolBudget(0, 2)}
+
+		result, text := call(s)
+		assert.True(t, result.IsError)
+		assert.Equal(t, ErrorCodeForbidden, errorCode(result))
+		assert.Contains(t, text, "the cascade deletes 3 dependents, more than the remaining budget")
+		assert.Empty(t, s.budget.sessions[""].writes, "nothing is charged for a refused cascade")
+		mockClient.AssertNotCalled(t, "DeleteStack", 1, 3, false)
+	})
+
+	t.Run("charged and journaled", func(t *testing.T) {
+		j, err := journal.New("", 10)
+		require.NoError(t, err)
+		mockClient := dependentsTestClient()
+		mockClient.On("InspectStack", 1).Return(models.RegularStack{ID: 1, Name: "web", EndpointID: 3}, nil)
+		mockClient.On("InspectStackFile", 1).Return("services: {}", nil)
+		mockClient.On("GetEdgeJob", 7).Return(models.EdgeJob{ID: 7, Name: "cleanup", CronExpression: "0 * * * *", Endpoints: []int{3}}, nil)
+		mockClient.On("GetEdgeJobFile", 7).Return("docker system prune -f", nil)
+		mockClient.On("DeleteStack", 1, 3, false).Return(nil)
+		mockClient.On("DeleteWebhook", 5).Return(fmt.Errorf("webhook locked"))
+		s := &PortainerMCPServer{cli: mockClient, budget: newToolBudget(0, 5), deleteJournal: j}
+
+		result, _ := call(s)
+		assert.True(t, result.IsError)
+		assert.Equal(t, 1, s.budget.sessions[""].destructive, "the dependents that were not deleted are not charged")
+
+		mockClient.On("DeleteWebhook", 5).Unset()
+		mockClient.On("DeleteWebhook", 5).Return(nil)
+		mockClient.On("DeleteStack", 1, 3, false).Return(nil)
+		mockClient.On("DeleteEdgeJob", 7).Return(nil)
+		mockClient.On("DeleteEnvironment", 3).Return(nil)
+
+		result, text := call(s)
+		require.False(t, result.IsError, text)
+		var report environmentDependents
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		require.NotZero(t, report.EdgeJobs[0].JournalEntry)
+		entry, ok := j.Get(report.EdgeJobs[0].JournalEntry)
+		require.True(t, ok)
+		assert.Equal(t, journal.KindEdgeJob, entry.Kind)
+		assert.Equal(t, ToolDeleteEdgeJob, entry.Tool)
+		assert.Equal(t, ToolCreateEdgeJob, entry.Undo.Tool)
+		assert.Equal(t, "docker system prune -f", entry.Undo.Arguments["fileContent"])
+		assert.Equal(t, 4, s.budget.sessions[""].destructive)
+	})
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// environmentGroupMember is an environment selected by the tags of a dynamic environment group.
+type environmentGroupMember struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// MatchedTagIds are the group tags carried by the environment or by its access group.
+	MatchedTagIds []int `json:"matched_tag_ids"`
+}
+
+// environmentGroupPreview is the result of HandlePreviewEnvironmentGroupMembers.
+type environmentGroupPreview struct {
+	TagIds       []int                    `json:"tag_ids"`
+	TagNames     []string                 `json:"tag_names"`
+	PartialMatch bool                     `json:"partial_match"`
+	Environments []environmentGroupMember `json:"environments"`
+	// NonEdgeMatches counts the environments that carry the tags but are not edge
+	// environments, which Portainer never adds to environment groups.
+	NonEdgeMatches int `json:"non_edge_matches"`
+	// GroupID, Added and Removed compare the selection with an existing group.
+	GroupID int   `json:"group_id,omitempty"`
+	Added   []int `json:"added,omitempty"`
+	Removed []int `json:"removed,omitempty"`
+}
+
+// HandlePreviewEnvironmentGroupMembers returns an MCP tool handler that lists the
+// environments a dynamic environment group (Edge Group) would select with the given tags,
+// before the group is created or its tags are changed. When an existing group is given,
+// the environments that would join or leave it are listed too.
+func (s *PortainerMCPServer) HandlePreviewEnvironmentGroupMembers() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		tagIds, err := parser.GetArrayOfIntegers("tagIds", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tagIds parameter", err), nil
+		}
+		if len(tagIds) == 0 {
+			return mcp.NewToolResultError("tagIds cannot be empty: dynamic environment groups select environments by at least one tag"), nil
+		}
+		tagIds = slices.Compact(slices.Sorted(slices.Values(tagIds)))
+
+		partialMatch, err := parser.GetBoolean("partialMatch", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid partialMatch parameter", err), nil
+		}
+
+		groupId, err := parser.GetInt("id", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if _, ok := request.GetArguments()["id"]; ok {
+			if err := validatePositiveID("id", groupId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		tagNames, err := s.resolveEnvironmentTagNames(tagIds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var current []int
+		if groupId != 0 {
+			groups, err := s.cli.GetEnvironmentGroups()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get environment groups", err), nil
+			}
+			i := slices.IndexFunc(groups, func(group models.Group) bool { return group.ID == groupId })
+			if i < 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("environment group %d not found", groupId)), nil
+			}
+			current = groups[i].EnvironmentIds
+		}
+
+		environments, err := s.rootsEnvironments(ctx, models.EnvironmentListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+		accessGroups, err := s.cli.GetAccessGroups()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get access groups", err), nil
+		}
+		groupTags := make(map[int][]int, len(accessGroups))
+		for _, group := range accessGroups {
+			groupTags[group.ID] = group.TagIds
+		}
+
+		preview := environmentGroupPreview{
+			TagIds:       tagIds,
+			TagNames:     tagNames,
+			PartialMatch: partialMatch,
+			Environments: []environmentGroupMember{},
+			GroupID:      groupId,
+		}
+		for _, env := range environments {
+			matched, ok := matchEnvironmentGroupTags(tagIds, append(slices.Clone(env.TagIds), groupTags[env.GroupID]...), partialMatch)
+			if !ok {
+				continue
+			}
+			if !isEdgeEnvironment(env) {
+				preview.NonEdgeMatches++
+				continue
+			}
+			preview.Environments = append(preview.Environments, environmentGroupMember{
+				ID:            env.ID,
+				Name:          env.Name,
+				Type:          env.Type,
+				Status:        env.Status,
+				MatchedTagIds: matched,
+			})
+		}
+
+		if groupId != 0 {
+			for _, member := range preview.Environments {
+				if !slices.Contains(current, member.ID) {
+					preview.Added = append(preview.Added, member.ID)
+				}
+			}
+			for _, id := range current {
+				if !slices.ContainsFunc(preview.Environments, func(member environmentGroupMember) bool { return member.ID == id }) {
+					preview.Removed = append(preview.Removed, id)
+				}
+			}
+		}
+
+		return jsonResult(preview, "failed to marshal environment group preview")
+	}
+}
+
+// matchEnvironmentGroupTags applies the selection rule of dynamic environment groups to the
+// tags of an environment, which include the tags of its access group. With partialMatch an
+// environment is selected when it carries any of the group tags, otherwise it must carry
+// all of them. groupTagIds must not contain duplicates. It returns the group tags the
+// environment carries.
+func matchEnvironmentGroupTags(groupTagIds, environmentTagIds []int, partialMatch bool) ([]int, bool) {
+	matched := []int{}
+	for _, tagId := range groupTagIds {
+		if slices.Contains(environmentTagIds, tagId) {
+			matched = append(matched, tagId)
+		}
+	}
+	if partialMatch {
+		return matched, len(matched) > 0
+	}
+	return matched, len(matched) == len(groupTagIds)
+}
+
+// isEdgeEnvironment reports whether an environment is managed by an edge agent, the only
+// kind of environment that environment groups can hold.
+func isEdgeEnvironment(env models.Environment) bool {
+	return env.Type == models.EnvironmentTypeDockerEdgeAgent || env.Type == models.EnvironmentTypeKubernetesEdgeAgent
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandlePreviewEnvironmentGroupMembers verifies the dynamic group selection rule and the
+// comparison with an existing group.
+func TestHandlePreviewEnvironmentGroupMembers(t *testing.T) {
+	tags := []models.EnvironmentTag{{ID: 1, Name: "prod"}, {ID: 2, Name: "eu"}}
+	environments := []models.Environment{
+		{ID: 1, Name: "edge-prod-eu", Type: models.EnvironmentTypeDockerEdgeAgent, Status: models.EnvironmentStatusActive, TagIds: []int{1, 2}},
+		{ID: 2, Name: "edge-prod", Type: models.EnvironmentTypeKubernetesEdgeAgent, Status: models.EnvironmentStatusActive, TagIds: []int{1}},
+		{ID: 3, Name: "edge-eu-group", Type: models.EnvironmentTypeDockerEdgeAgent, Status: models.EnvironmentStatusInactive, TagIds: []int{1}, GroupID: 5},
+		{ID: 4, Name: "agent-prod-eu", Type: models.EnvironmentTypeDockerAgent, Status: models.EnvironmentStatusActive, TagIds: []int{1, 2}},
+		{ID: 5, Name: "edge-untagged", Type: models.EnvironmentTypeDockerEdgeAgent, Status: models.EnvironmentStatusActive},
+	}
+	accessGroups := []models.AccessGroup{{ID: 1, Name: "Unassigned"}, {ID: 5, Name: "Europe", TagIds: []int{2}}}
+
+	tests := []struct {
+		name            string
+		inputParams     map[string]any
+		setupMock       func(*MockPortainerClient)
+		expectError     string
+		expectedIDs     []int
+		expectedNonEdge int
+		expectedAdded   []int
+		expectedRemoved []int
+	}{
+		{
+			name:        "full match requires every tag, including access group tags",
+			inputParams: map[string]any{"tagIds": []any{float64(2), float64(1)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetAccessGroups").Return(accessGroups, nil)
+			},
+			expectedIDs:     []int{1, 3},
+			expectedNonEdge: 1,
+		},
+		{
+			name:        "partial match selects environments with any tag",
+			inputParams: map[string]any{"tagIds": []any{float64(1), float64(2)}, "partialMatch": true},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetAccessGroups").Return(accessGroups, nil)
+			},
+			expectedIDs:     []int{1, 2, 3},
+			expectedNonEdge: 1,
+		},
+		{
+			name:        "compares with an existing group",
+			inputParams: map[string]any{"tagIds": []any{float64(1), float64(2)}, "id": float64(7)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetEnvironmentGroups").Return([]models.Group{{ID: 7, Name: "prod", EnvironmentIds: []int{1, 2}}}, nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return(environments, nil)
+				m.On("GetAccessGroups").Return(accessGroups, nil)
+			},
+			expectedIDs:     []int{1, 3},
+			expectedNonEdge: 1,
+			expectedAdded:   []int{3},
+			expectedRemoved: []int{2},
+		},
+		{
+			name:        "empty tags",
+			inputParams: map[string]any{"tagIds": []any{}},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "tagIds cannot be empty",
+		},
+		{
+			name:        "unknown tag",
+			inputParams: map[string]any{"tagIds": []any{float64(9)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+			},
+			expectError: "environment tags not found: 9",
+		},
+		{
+			name:        "unknown group",
+			inputParams: map[string]any{"tagIds": []any{float64(1)}, "id": float64(8)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetEnvironmentGroups").Return([]models.Group{}, nil)
+			},
+			expectError: "environment group 8 not found",
+		},
+		{
+			name:        "environments error",
+			inputParams: map[string]any{"tagIds": []any{float64(1)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{}, fmt.Errorf("api error"))
+			},
+			expectError: "failed to get environments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setupMock(mockClient)
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandlePreviewEnvironmentGroupMembers()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var preview environmentGroupPreview
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &preview))
+			var ids []int
+			for _, env := range preview.Environments {
+				ids = append(ids, env.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, []int{1, 2}, preview.TagIds)
+			assert.Equal(t, []string{"eu", "prod"}, preview.TagNames)
+			assert.Equal(t, tt.expectedNonEdge, preview.NonEdgeMatches)
+			assert.Equal(t, tt.expectedAdded, preview.Added)
+			assert.Equal(t, tt.expectedRemoved, preview.Removed)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestMatchEnvironmentGroupTags verifies the full and partial match rules.
+func TestMatchEnvironmentGroupTags(t *testing.T) {
+	matched, ok := matchEnvironmentGroupTags([]int{1, 2}, []int{2, 3}, false)
+	assert.False(t, ok)
+	assert.Equal(t, []int{2}, matched)
+
+	matched, ok = matchEnvironmentGroupTags([]int{1, 2}, []int{2, 3}, true)
+	assert.True(t, ok)
+	assert.Equal(t, []int{2}, matched)
+
+	_, ok = matchEnvironmentGroupTags([]int{1, 2}, []int{3}, true)
+	assert.False(t, ok)
+
+	matched, ok = matchEnvironmentGroupTags([]int{1, 2}, []int{2, 1, 1}, false)
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 2}, matched)
+}
@@ -0,0 +1,255 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/maintenance"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Statuses of a stack in a maintenance report.
+const (
+	maintenanceStatusStopped = "stopped"
+	maintenanceStatusStarted = "started"
+	maintenanceStatusSkipped = "skipped"
+	maintenanceStatusFailed  = "failed"
+)
+
+// maintenanceStack is a stack stopped or started by a maintenance tool.
+type maintenanceStack struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// maintenanceReport is the result of HandleEnterMaintenanceMode and HandleExitMaintenanceMode.
+type maintenanceReport struct {
+	EnvironmentID   int    `json:"environment_id"`
+	EnvironmentName string `json:"environment_name"`
+	// InMaintenance tells whether the environment is left in maintenance mode.
+	InMaintenance bool `json:"in_maintenance"`
+	// Stacks are the stacks handled by the call, in the order they were processed.
+	Stacks        []maintenanceStack `json:"stacks"`
+	Snapshotted   bool               `json:"snapshotted"`
+	SnapshotError string             `json:"snapshot_error,omitempty"`
+}
+
+// HandleEnterMaintenanceMode returns an MCP tool handler that quiesces an environment: it
+// stops the selected running regular stacks one at a time, in the given order, records them
+// in the maintenance store after every stop, and triggers a snapshot of the environment.
+// Without stackIds, every running regular stack of the environment is stopped, newest
+// first. The first stop failure ends the stops; the stacks stopped so far stay recorded so
+// that HandleExitMaintenanceMode restarts them.
+func (s *PortainerMCPServer) HandleEnterMaintenanceMode() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentID, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		stackIds, err := parser.GetArrayOfIntegers("stackIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stackIds parameter", err), nil
+		}
+		for i, id := range stackIds {
+			if err := validatePositiveID("stackIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if slices.Contains(stackIds[:i], id) {
+				return mcp.NewToolResultError(fmt.Sprintf("stackIds lists stack %d more than once", id)), nil
+			}
+		}
+
+		reason, err := parser.GetString("reason", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid reason parameter", err), nil
+		}
+
+		if record, ok := s.maintenance.Get(environmentID); ok {
+			return mcp.NewToolResultError(fmt.Sprintf("environment %d is already in maintenance mode since %s; resume it with exitMaintenanceMode first", environmentID, record.StartedAt.Format(time.RFC3339))), nil
+		}
+
+		environment, err := s.cli.GetEnvironment(environmentID)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environment", err), nil
+		}
+
+		stacks, err := s.cli.GetRegularStacks()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list stacks", err), nil
+		}
+		selected, err := maintenanceStopOrder(stacks, environmentID, stackIds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		record, err := s.maintenance.Put(maintenance.Record{EnvironmentID: environmentID, EnvironmentName: environment.Name, Reason: reason})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to record maintenance mode", err), nil
+		}
+
+		report := maintenanceReport{EnvironmentID: environmentID, EnvironmentName: environment.Name, InMaintenance: true, Stacks: []maintenanceStack{}}
+		var failure string
+		for _, stack := range selected {
+			entry := maintenanceStack{ID: stack.ID, Name: stack.Name}
+			switch {
+			case failure != "":
+				entry.Status = maintenanceStatusSkipped
+				entry.Error = failure
+			case stack.Status != regularStackStatusActive:
+				entry.Status = maintenanceStatusSkipped
+				entry.Error = "stack is not running"
+			default:
+				entry.Status, entry.Error = s.stopMaintenanceStack(ctx, &record, stack)
+				if entry.Status == maintenanceStatusFailed {
+					failure = fmt.Sprintf("not stopped after stack %d failed to stop", stack.ID)
+				}
+			}
+			report.Stacks = append(report.Stacks, entry)
+		}
+
+		report.Snapshotted, report.SnapshotError = s.snapshotMaintenanceEnvironment(ctx, environmentID)
+		return jsonResult(report, "failed to marshal maintenance report")
+	}
+}
+
+// HandleExitMaintenanceMode returns an MCP tool handler that resumes an environment put in
+// maintenance mode: it starts the recorded stacks one at a time, in reverse stop order,
+// and triggers a snapshot of the environment. The maintenance record is updated after
+// every start and removed once every stack is started. The first start failure ends the
+// starts, leaving the environment in maintenance mode with the stacks not yet started.
+func (s *PortainerMCPServer) HandleExitMaintenanceMode() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentID, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		record, ok := s.maintenance.Get(environmentID)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("environment %d is not in maintenance mode", environmentID)), nil
+		}
+
+		report := maintenanceReport{EnvironmentID: environmentID, EnvironmentName: record.EnvironmentName, InMaintenance: true, Stacks: []maintenanceStack{}}
+		for len(record.Stacks) > 0 {
+			stack := record.Stacks[len(record.Stacks)-1]
+			entry := maintenanceStack{ID: stack.ID, Name: stack.Name}
+			entry.Status, entry.Error = s.startMaintenanceStack(ctx, &record, stack)
+			report.Stacks = append(report.Stacks, entry)
+			if entry.Status == maintenanceStatusFailed {
+				break
+			}
+		}
+
+		if len(record.Stacks) == 0 {
+			if err := s.maintenance.Delete(environmentID); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to remove maintenance record", err), nil
+			}
+			report.InMaintenance = false
+		}
+
+		report.Snapshotted, report.SnapshotError = s.snapshotMaintenanceEnvironment(ctx, environmentID)
+		return jsonResult(report, "failed to marshal maintenance report")
+	}
+}
+
+// HandleListMaintenanceEnvironments returns an MCP tool handler that lists the
+// environments in maintenance mode with the stacks stopped for each of them.
+func (s *PortainerMCPServer) HandleListMaintenanceEnvironments() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return jsonResult(s.maintenance.List(), "failed to marshal maintenance records")
+	}
+}
+
+// maintenanceStopOrder returns the regular stacks of an environment to stop for a
+// maintenance, in stop order: the listed stacks in the given order, or every running stack
+// of the environment, newest first, when none is listed.
+func maintenanceStopOrder(stacks []models.RegularStack, environmentID int, stackIds []int) ([]models.RegularStack, error) {
+	stacks = slices.DeleteFunc(slices.Clone(stacks), func(stack models.RegularStack) bool {
+		return stack.EndpointID != environmentID
+	})
+
+	if len(stackIds) == 0 {
+		stacks = slices.DeleteFunc(stacks, func(stack models.RegularStack) bool {
+			return stack.Status != regularStackStatusActive
+		})
+		slices.SortFunc(stacks, func(a, b models.RegularStack) int { return b.ID - a.ID })
+		return stacks, nil
+	}
+
+	selected := make([]models.RegularStack, 0, len(stackIds))
+	for _, id := range stackIds {
+		idx := slices.IndexFunc(stacks, func(stack models.RegularStack) bool { return stack.ID == id })
+		if idx < 0 {
+			return nil, fmt.Errorf("stack %d is not a regular stack of environment %d", id, environmentID)
+		}
+		selected = append(selected, stacks[idx])
+	}
+	return selected, nil
+}
+
+// stopMaintenanceStack stops a stack and appends it to the maintenance record, which is
+// persisted before returning the status and error of the stack.
+func (s *PortainerMCPServer) stopMaintenanceStack(ctx context.Context, record *maintenance.Record, stack models.RegularStack) (string, string) {
+	if err := ctx.Err(); err != nil {
+		return maintenanceStatusFailed, err.Error()
+	}
+	if _, err := s.cli.StopStack(stack.ID, stack.EndpointID); err != nil {
+		return maintenanceStatusFailed, err.Error()
+	}
+
+	record.Stacks = append(record.Stacks, maintenance.Stack{ID: stack.ID, Name: stack.Name})
+	if _, err := s.maintenance.Put(*record); err != nil {
+		return maintenanceStatusFailed, fmt.Sprintf("stack stopped but not recorded: %v", err)
+	}
+	return maintenanceStatusStopped, ""
+}
+
+// startMaintenanceStack starts the last stack of the maintenance record and removes it from
+// the record, which is persisted before returning the status and error of the stack.
+func (s *PortainerMCPServer) startMaintenanceStack(ctx context.Context, record *maintenance.Record, stack maintenance.Stack) (string, string) {
+	if err := ctx.Err(); err != nil {
+		return maintenanceStatusFailed, err.Error()
+	}
+	if _, err := s.cli.StartStack(stack.ID, record.EnvironmentID); err != nil {
+		return maintenanceStatusFailed, err.Error()
+	}
+
+	record.Stacks = record.Stacks[:len(record.Stacks)-1]
+	if len(record.Stacks) == 0 {
+		return maintenanceStatusStarted, ""
+	}
+	if _, err := s.maintenance.Put(*record); err != nil {
+		return maintenanceStatusFailed, fmt.Sprintf("stack started but not recorded: %v", err)
+	}
+	return maintenanceStatusStarted, ""
+}
+
+// snapshotMaintenanceEnvironment triggers a snapshot of an environment once its stacks are
+// stopped or started, and reports whether it succeeded.
+func (s *PortainerMCPServer) snapshotMaintenanceEnvironment(ctx context.Context, environmentID int) (bool, string) {
+	if err := ctx.Err(); err != nil {
+		return false, err.Error()
+	}
+	if err := s.cli.SnapshotEnvironment(environmentID); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
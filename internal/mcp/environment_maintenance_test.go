@@ -0,0 +1,271 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/maintenance"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMaintenanceTestServer creates a server with a mock client and an in-memory
+// maintenance store.
+func newMaintenanceTestServer(t *testing.T, cli *MockPortainerClient) *PortainerMCPServer {
+	t.Helper()
+	store, err := maintenance.New("")
+	require.NoError(t, err)
+	return &PortainerMCPServer{cli: cli, maintenance: store}
+}
+
+// callMaintenanceTool calls a maintenance tool handler and returns its text and error flag.
+func callMaintenanceTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), input map[string]any) (string, bool) {
+	t.Helper()
+	result, err := handler(context.Background(), CreateMCPRequest(input))
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return textContent.Text, result.IsError
+}
+
+// TestHandleEnterMaintenanceMode verifies the HandleEnterMaintenanceMode MCP tool handler.
+func TestHandleEnterMaintenanceMode(t *testing.T) {
+	stacks := []models.RegularStack{
+		{ID: 1, Name: "db", EndpointID: 2, Status: regularStackStatusActive},
+		{ID: 2, Name: "api", EndpointID: 2, Status: regularStackStatusActive},
+		{ID: 3, Name: "batch", EndpointID: 2, Status: regularStackStatusInactive},
+		{ID: 4, Name: "web", EndpointID: 2, Status: regularStackStatusActive},
+		{ID: 5, Name: "other", EndpointID: 7, Status: regularStackStatusActive},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    maintenanceReport
+		recorded    []maintenance.Stack
+	}{
+		{
+			name:  "stops the running stacks newest first",
+			input: map[string]any{"environmentId": float64(2), "reason": "kernel upgrade"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 2).Return(models.Environment{ID: 2, Name: "prod"}, nil)
+				m.On("GetRegularStacks").Return(stacks, nil)
+				m.On("StopStack", 4, 2).Return(stacks[3], nil)
+				m.On("StopStack", 2, 2).Return(stacks[1], nil)
+				m.On("StopStack", 1, 2).Return(stacks[0], nil)
+				m.On("SnapshotEnvironment", 2).Return(nil)
+			},
+			expected: maintenanceReport{
+				EnvironmentID: 2, EnvironmentName: "prod", InMaintenance: true, Snapshotted: true,
+				Stacks: []maintenanceStack{
+					{ID: 4, Name: "web", Status: maintenanceStatusStopped},
+					{ID: 2, Name: "api", Status: maintenanceStatusStopped},
+					{ID: 1, Name: "db", Status: maintenanceStatusStopped},
+				},
+			},
+			recorded: []maintenance.Stack{{ID: 4, Name: "web"}, {ID: 2, Name: "api"}, {ID: 1, Name: "db"}},
+		},
+		{
+			name:  "listed stacks with stop failure",
+			input: map[string]any{"environmentId": float64(2), "stackIds": []any{float64(3), float64(2), float64(4), float64(1)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 2).Return(models.Environment{ID: 2, Name: "prod"}, nil)
+				m.On("GetRegularStacks").Return(stacks, nil)
+				m.On("StopStack", 2, 2).Return(stacks[1], nil)
+				m.On("StopStack", 4, 2).Return(models.RegularStack{}, fmt.Errorf("timeout"))
+				m.On("SnapshotEnvironment", 2).Return(fmt.Errorf("environment unreachable"))
+			},
+			expected: maintenanceReport{
+				EnvironmentID: 2, EnvironmentName: "prod", InMaintenance: true, SnapshotError: "environment unreachable",
+				Stacks: []maintenanceStack{
+					{ID: 3, Name: "batch", Status: maintenanceStatusSkipped, Error: "stack is not running"},
+					{ID: 2, Name: "api", Status: maintenanceStatusStopped},
+					{ID: 4, Name: "web", Status: maintenanceStatusFailed, Error: "timeout"},
+					{ID: 1, Name: "db", Status: maintenanceStatusSkipped, Error: "not stopped after stack 4 failed to stop"},
+				},
+			},
+			recorded: []maintenance.Stack{{ID: 2, Name: "api"}},
+		},
+		{
+			name:  "stack of another environment",
+			input: map[string]any{"environmentId": float64(2), "stackIds": []any{float64(5)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 2).Return(models.Environment{ID: 2, Name: "prod"}, nil)
+				m.On("GetRegularStacks").Return(stacks, nil)
+			},
+			expectError: "stack 5 is not a regular stack of environment 2",
+		},
+		{
+			name:  "environment error",
+			input: map[string]any{"environmentId": float64(2)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 2).Return(models.Environment{}, fmt.Errorf("not found"))
+			},
+			expectError: "failed to get environment",
+		},
+		{
+			name:        "duplicate stack",
+			input:       map[string]any{"environmentId": float64(2), "stackIds": []any{float64(1), float64(1)}},
+			expectError: "stackIds lists stack 1 more than once",
+		},
+		{
+			name:        "invalid environment ID",
+			input:       map[string]any{"environmentId": float64(0)},
+			expectError: "environmentId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := newMaintenanceTestServer(t, mockClient)
+			text, isError := callMaintenanceTool(t, server.HandleEnterMaintenanceMode(), tt.input)
+			if tt.expectError != "" {
+				assert.True(t, isError)
+				assert.Contains(t, text, tt.expectError)
+				assert.Empty(t, server.maintenance.List())
+			} else {
+				require.False(t, isError, text)
+				var report maintenanceReport
+				require.NoError(t, json.Unmarshal([]byte(text), &report))
+				assert.Equal(t, tt.expected, report)
+
+				record, ok := server.maintenance.Get(2)
+				require.True(t, ok)
+				assert.Equal(t, tt.recorded, record.Stacks)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleEnterMaintenanceModeAlreadyInMaintenance verifies that an environment cannot
+// be put in maintenance mode twice.
+func TestHandleEnterMaintenanceModeAlreadyInMaintenance(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	server := newMaintenanceTestServer(t, mockClient)
+	_, err := server.maintenance.Put(maintenance.Record{EnvironmentID: 2, EnvironmentName: "prod"})
+	require.NoError(t, err)
+
+	text, isError := callMaintenanceTool(t, server.HandleEnterMaintenanceMode(), map[string]any{"environmentId": float64(2)})
+	assert.True(t, isError)
+	assert.Contains(t, text, "already in maintenance mode")
+	mockClient.AssertExpectations(t)
+}
+
+// TestHandleExitMaintenanceMode verifies the HandleExitMaintenanceMode MCP tool handler.
+func TestHandleExitMaintenanceMode(t *testing.T) {
+	recorded := []maintenance.Stack{{ID: 4, Name: "web"}, {ID: 2, Name: "api"}, {ID: 1, Name: "db"}}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    maintenanceReport
+		remaining   []maintenance.Stack
+	}{
+		{
+			name:  "starts the stacks in reverse stop order",
+			input: map[string]any{"environmentId": float64(2)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("StartStack", 1, 2).Return(models.RegularStack{ID: 1}, nil)
+				m.On("StartStack", 2, 2).Return(models.RegularStack{ID: 2}, nil)
+				m.On("StartStack", 4, 2).Return(models.RegularStack{ID: 4}, nil)
+				m.On("SnapshotEnvironment", 2).Return(nil)
+			},
+			expected: maintenanceReport{
+				EnvironmentID: 2, EnvironmentName: "prod", Snapshotted: true,
+				Stacks: []maintenanceStack{
+					{ID: 1, Name: "db", Status: maintenanceStatusStarted},
+					{ID: 2, Name: "api", Status: maintenanceStatusStarted},
+					{ID: 4, Name: "web", Status: maintenanceStatusStarted},
+				},
+			},
+		},
+		{
+			name:  "start failure keeps the maintenance",
+			input: map[string]any{"environmentId": float64(2)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("StartStack", 1, 2).Return(models.RegularStack{ID: 1}, nil)
+				m.On("StartStack", 2, 2).Return(models.RegularStack{}, fmt.Errorf("image not found"))
+				m.On("SnapshotEnvironment", 2).Return(nil)
+			},
+			expected: maintenanceReport{
+				EnvironmentID: 2, EnvironmentName: "prod", InMaintenance: true, Snapshotted: true,
+				Stacks: []maintenanceStack{
+					{ID: 1, Name: "db", Status: maintenanceStatusStarted},
+					{ID: 2, Name: "api", Status: maintenanceStatusFailed, Error: "image not found"},
+				},
+			},
+			remaining: []maintenance.Stack{{ID: 4, Name: "web"}, {ID: 2, Name: "api"}},
+		},
+		{
+			name:        "environment not in maintenance",
+			input:       map[string]any{"environmentId": float64(3)},
+			expectError: "environment 3 is not in maintenance mode",
+			remaining:   recorded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := newMaintenanceTestServer(t, mockClient)
+			_, err := server.maintenance.Put(maintenance.Record{EnvironmentID: 2, EnvironmentName: "prod", Stacks: recorded})
+			require.NoError(t, err)
+
+			text, isError := callMaintenanceTool(t, server.HandleExitMaintenanceMode(), tt.input)
+			if tt.expectError != "" {
+				assert.True(t, isError)
+				assert.Contains(t, text, tt.expectError)
+			} else {
+				require.False(t, isError, text)
+				var report maintenanceReport
+				require.NoError(t, json.Unmarshal([]byte(text), &report))
+				assert.Equal(t, tt.expected, report)
+			}
+
+			record, ok := server.maintenance.Get(2)
+			assert.Equal(t, tt.remaining != nil, ok)
+			assert.Equal(t, tt.remaining, record.Stacks)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleListMaintenanceEnvironments verifies the HandleListMaintenanceEnvironments MCP
+// tool handler.
+func TestHandleListMaintenanceEnvironments(t *testing.T) {
+	server := newMaintenanceTestServer(t, &MockPortainerClient{})
+
+	text, isError := callMaintenanceTool(t, server.HandleListMaintenanceEnvironments(), map[string]any{})
+	require.False(t, isError, text)
+	assert.JSONEq(t, "[]", text)
+
+	_, err := server.maintenance.Put(maintenance.Record{EnvironmentID: 2, EnvironmentName: "prod", Reason: "kernel upgrade", Stacks: []maintenance.Stack{{ID: 4, Name: "web"}}})
+	require.NoError(t, err)
+
+	text, isError = callMaintenanceTool(t, server.HandleListMaintenanceEnvironments(), map[string]any{})
+	require.False(t, isError, text)
+	var records []maintenance.Record
+	require.NoError(t, json.Unmarshal([]byte(text), &records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "kernel upgrade", records[0].Reason)
+	assert.Equal(t, []maintenance.Stack{{ID: 4, Name: "web"}}, records[0].Stacks)
+}
@@ -0,0 +1,252 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// onboardingCreationTypes maps the environment types accepted by onboardEnvironment to
+// the Portainer creation types.
+var onboardingCreationTypes = map[string]int{
+	"docker-api": models.EnvironmentCreationTypeDockerAPI,
+	"agent":      models.EnvironmentCreationTypeAgent,
+	"edge-agent": models.EnvironmentCreationTypeEdgeAgent,
+}
+
+// Onboarding step statuses.
+const (
+	onboardingStepDone    = "done"
+	onboardingStepSkipped = "skipped"
+)
+
+// onboardingStep is the outcome of one step of an environment onboarding.
+type onboardingStep struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// onboardingReport is the result of HandleOnboardEnvironment.
+type onboardingReport struct {
+	Environment models.Environment `json:"environment"`
+	// EdgeKey is the key to pass to the edge agent deployment, for edge agent environments.
+	EdgeKey string           `json:"edge_key,omitempty"`
+	Steps   []onboardingStep `json:"steps"`
+}
+
+// HandleOnboardEnvironment returns an MCP tool handler that creates an environment, assigns
+// its tags, adds it to an access group and takes its first snapshot. The tags and the
+// access group are checked before anything is created, and the environment is deleted
+// again when a later step fails, so that a failed onboarding leaves nothing behind.
+func (s *PortainerMCPServer) HandleOnboardEnvironment() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		opts, err := parseOnboardingOptions(parser)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		tagIds, err := parser.GetArrayOfIntegers("tagIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid tagIds parameter", err), nil
+		}
+		for _, tagId := range tagIds {
+			if err := validatePositiveID("tagIds", tagId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		accessGroupId, err := parser.GetInt("accessGroupId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid accessGroupId parameter", err), nil
+		}
+		if _, ok := request.GetArguments()["accessGroupId"]; ok {
+			if err := validatePositiveID("accessGroupId", accessGroupId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+
+		tagNames, err := s.resolveEnvironmentTagNames(tagIds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var group *models.AccessGroup
+		if accessGroupId != 0 {
+			if group, err = s.resolveOnboardingAccessGroup(accessGroupId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		if plan {
+			steps, notes := onboardEnvironmentPlan(opts, tagNames, group)
+			return s.previewPlan(ctx, request, steps, notes, s.HandleOnboardEnvironment())
+		}
+
+		environment, edgeKey, err := s.cli.CreateEnvironment(opts)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to create environment", err), nil
+		}
+		report := onboardingReport{
+			EdgeKey: edgeKey,
+			Steps: []onboardingStep{
+				{Step: "create", Status: onboardingStepDone, Detail: fmt.Sprintf("created environment %d", environment.ID)},
+			},
+		}
+
+		if len(tagIds) > 0 {
+			if err := s.cli.UpdateEnvironmentTags(environment.ID, tagIds); err != nil {
+				return s.rollbackOnboarding(environment.ID, "assign tags", err), nil
+			}
+			environment.TagIds = tagIds
+			report.Steps = append(report.Steps, onboardingStep{Step: "tags", Status: onboardingStepDone, Detail: "assigned " + strings.Join(tagNames, ", ")})
+		} else {
+			report.Steps = append(report.Steps, onboardingStep{Step: "tags", Status: onboardingStepSkipped, Detail: "no tags requested"})
+		}
+
+		if group != nil {
+			if err := s.cli.AddEnvironmentToAccessGroup(group.ID, environment.ID); err != nil {
+				return s.rollbackOnboarding(environment.ID, "add the environment to the access group", err), nil
+			}
+			report.Steps = append(report.Steps, onboardingStep{Step: "access_group", Status: onboardingStepDone, Detail: fmt.Sprintf("added to %q", group.Name)})
+		} else {
+			report.Steps = append(report.Steps, onboardingStep{Step: "access_group", Status: onboardingStepSkipped, Detail: "no access group requested"})
+		}
+
+		if opts.CreationType == models.EnvironmentCreationTypeEdgeAgent {
+			report.Steps = append(report.Steps, onboardingStep{Step: "snapshot", Status: onboardingStepSkipped, Detail: "edge agents send their first snapshot when they check in; deploy the agent with the edge key"})
+		} else {
+			if err := s.cli.SnapshotEnvironment(environment.ID); err != nil {
+				return s.rollbackOnboarding(environment.ID, "take the initial snapshot", err), nil
+			}
+			report.Steps = append(report.Steps, onboardingStep{Step: "snapshot", Status: onboardingStepDone})
+		}
+
+		report.Environment = environment
+		return jsonResult(report, "failed to marshal onboarding report")
+	}
+}
+
+// parseOnboardingOptions reads the settings of the environment to create.
+func parseOnboardingOptions(parser *toolgen.ParameterParser) (models.EnvironmentCreateOptions, error) {
+	var opts models.EnvironmentCreateOptions
+
+	name, err := parser.GetString("name", true)
+	if err != nil {
+		return opts, fmt.Errorf("invalid name parameter: %w", err)
+	}
+	if strings.TrimSpace(name) == "" {
+		return opts, fmt.Errorf("name cannot be empty")
+	}
+
+	envType, err := parser.GetString("type", true)
+	if err != nil {
+		return opts, fmt.Errorf("invalid type parameter: %w", err)
+	}
+	creationType, ok := onboardingCreationTypes[envType]
+	if !ok {
+		return opts, fmt.Errorf("invalid type %q: must be one of agent, docker-api, edge-agent", envType)
+	}
+
+	envURL, err := parser.GetString("url", true)
+	if err != nil {
+		return opts, fmt.Errorf("invalid url parameter: %w", err)
+	}
+	if strings.TrimSpace(envURL) == "" {
+		return opts, fmt.Errorf("url cannot be empty")
+	}
+
+	publicURL, err := parser.GetString("publicUrl", false)
+	if err != nil {
+		return opts, fmt.Errorf("invalid publicUrl parameter: %w", err)
+	}
+
+	tls, err := parser.GetBoolean("tls", false)
+	if err != nil {
+		return opts, fmt.Errorf("invalid tls parameter: %w", err)
+	}
+	tlsSkipVerify, err := parser.GetBoolean("tlsSkipVerify", false)
+	if err != nil {
+		return opts, fmt.Errorf("invalid tlsSkipVerify parameter: %w", err)
+	}
+	if (tls || tlsSkipVerify) && creationType != models.EnvironmentCreationTypeDockerAPI {
+		return opts, fmt.Errorf("tls and tlsSkipVerify only apply to docker-api environments: agents always use TLS")
+	}
+
+	return models.EnvironmentCreateOptions{
+		Name:          name,
+		CreationType:  creationType,
+		URL:           envURL,
+		PublicURL:     publicURL,
+		TLS:           tls,
+		TLSSkipVerify: tlsSkipVerify,
+	}, nil
+}
+
+// resolveOnboardingAccessGroup returns the access group to add the environment to.
+func (s *PortainerMCPServer) resolveOnboardingAccessGroup(id int) (*models.AccessGroup, error) {
+	groups, err := s.cli.GetAccessGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access groups: %v", err)
+	}
+	for _, group := range groups {
+		if group.ID == id {
+			return &group, nil
+		}
+	}
+	return nil, fmt.Errorf("access group %d not found", id)
+}
+
+// rollbackOnboarding deletes an environment whose onboarding failed at step. Deleting the
+// environment also drops its tags and access group membership, so it undoes every
+// completed step. The returned error result tells whether the environment is left behind.
+func (s *PortainerMCPServer) rollbackOnboarding(environmentId int, step string, stepErr error) *mcp.CallToolResult {
+	if err := s.cli.DeleteEnvironment(environmentId); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to %s: %v; rollback failed, environment %d was left in place and must be deleted manually: %v", step, stepErr, environmentId, err))
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("failed to %s: %v; environment %d was deleted to roll back the onboarding", step, stepErr, environmentId))
+}
+
+// onboardEnvironmentPlan returns the Portainer API calls made by HandleOnboardEnvironment.
+// The ID of the new environment is only known once it is created and appears as {id}.
+func onboardEnvironmentPlan(opts models.EnvironmentCreateOptions, tagNames []string, group *models.AccessGroup) ([]planStep, []string) {
+	envType := ""
+	for name, creationType := range onboardingCreationTypes {
+		if creationType == opts.CreationType {
+			envType = name
+		}
+	}
+
+	steps := []planStep{
+		{Method: http.MethodPost, Path: "/api/endpoints", Description: fmt.Sprintf("Create the %s environment %q at %s", envType, opts.Name, opts.URL)},
+	}
+	if len(tagNames) > 0 {
+		steps = append(steps, planStep{Method: http.MethodPut, Path: "/api/endpoints/{id}", Description: "Assign the tags " + strings.Join(tagNames, ", ")})
+	}
+	if group != nil {
+		steps = append(steps, planStep{Method: http.MethodPut, Path: fmt.Sprintf("/api/endpoint_groups/%d/endpoints/{id}", group.ID), Description: fmt.Sprintf("Add the environment to access group %q", group.Name)})
+	}
+
+	var notes []string
+	if opts.CreationType == models.EnvironmentCreationTypeEdgeAgent {
+		notes = append(notes, "no snapshot is taken: edge agents send their first snapshot when they check in")
+	} else {
+		steps = append(steps, planStep{Method: http.MethodPost, Path: "/api/endpoints/{id}/snapshot", Description: "Take the initial snapshot"})
+	}
+	if len(steps) > 1 {
+		notes = append(notes, "if a step after the creation fails, the environment is deleted with DELETE /api/endpoints/{id}")
+	}
+	return steps, notes
+}
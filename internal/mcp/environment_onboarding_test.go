@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleOnboardEnvironment verifies the onboarding steps and the rollback of the
+// created environment when a later step fails.
+func TestHandleOnboardEnvironment(t *testing.T) {
+	tags := []models.EnvironmentTag{{ID: 1, Name: "prod"}, {ID: 2, Name: "eu"}}
+	groups := []models.AccessGroup{{ID: 3, Name: "Production"}}
+	agentOpts := models.EnvironmentCreateOptions{Name: "prod-1", CreationType: models.EnvironmentCreationTypeAgent, URL: "10.0.0.5:9001"}
+	created := models.Environment{ID: 12, Name: "prod-1", Type: models.EnvironmentTypeDockerAgent}
+
+	tests := []struct {
+		name           string
+		inputParams    map[string]any
+		setupMock      func(*MockPortainerClient)
+		expectError    string
+		expectedSteps  []onboardingStep
+		expectedKey    string
+		expectNoCreate bool
+	}{
+		{
+			name: "onboards an agent with tags and access group",
+			inputParams: map[string]any{
+				"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001",
+				"tagIds": []any{float64(2), float64(1)}, "accessGroupId": float64(3),
+			},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetAccessGroups").Return(groups, nil)
+				m.On("CreateEnvironment", agentOpts).Return(created, "", nil)
+				m.On("UpdateEnvironmentTags", 12, []int{2, 1}).Return(nil)
+				m.On("AddEnvironmentToAccessGroup", 3, 12).Return(nil)
+				m.On("SnapshotEnvironment", 12).Return(nil)
+			},
+			expectedSteps: []onboardingStep{
+				{Step: "create", Status: onboardingStepDone, Detail: "created environment 12"},
+				{Step: "tags", Status: onboardingStepDone, Detail: "assigned eu, prod"},
+				{Step: "access_group", Status: onboardingStepDone, Detail: `added to "Production"`},
+				{Step: "snapshot", Status: onboardingStepDone},
+			},
+		},
+		{
+			name:        "edge agent returns its key and skips the snapshot",
+			inputParams: map[string]any{"name": "edge-1", "type": "edge-agent", "url": "https://portainer.example.com"},
+			setupMock: func(m *MockPortainerClient) {
+				opts := models.EnvironmentCreateOptions{Name: "edge-1", CreationType: models.EnvironmentCreationTypeEdgeAgent, URL: "https://portainer.example.com"}
+				m.On("CreateEnvironment", opts).Return(models.Environment{ID: 13, Name: "edge-1"}, "edge-key", nil)
+			},
+			expectedKey: "edge-key",
+			expectedSteps: []onboardingStep{
+				{Step: "create", Status: onboardingStepDone, Detail: "created environment 13"},
+				{Step: "tags", Status: onboardingStepSkipped, Detail: "no tags requested"},
+				{Step: "access_group", Status: onboardingStepSkipped, Detail: "no access group requested"},
+				{Step: "snapshot", Status: onboardingStepSkipped, Detail: "edge agents send their first snapshot when they check in; deploy the agent with the edge key"},
+			},
+		},
+		{
+			name:           "invalid type",
+			inputParams:    map[string]any{"name": "prod-1", "type": "kubeconfig", "url": "10.0.0.5:9001"},
+			setupMock:      func(m *MockPortainerClient) {},
+			expectError:    `invalid type "kubeconfig"`,
+			expectNoCreate: true,
+		},
+		{
+			name:           "tls is rejected for agents",
+			inputParams:    map[string]any{"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001", "tls": true},
+			setupMock:      func(m *MockPortainerClient) {},
+			expectError:    "only apply to docker-api environments",
+			expectNoCreate: true,
+		},
+		{
+			name:        "unknown tag is rejected before creation",
+			inputParams: map[string]any{"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001", "tagIds": []any{float64(1), float64(9)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+			},
+			expectError:    "environment tags not found: 9",
+			expectNoCreate: true,
+		},
+		{
+			name:        "unknown access group is rejected before creation",
+			inputParams: map[string]any{"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001", "accessGroupId": float64(8)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(groups, nil)
+			},
+			expectError:    "access group 8 not found",
+			expectNoCreate: true,
+		},
+		{
+			name:        "create error",
+			inputParams: map[string]any{"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("CreateEnvironment", agentOpts).Return(models.Environment{}, "", fmt.Errorf("unreachable"))
+			},
+			expectError: "failed to create environment",
+		},
+		{
+			name:        "access group failure rolls back the environment",
+			inputParams: map[string]any{"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001", "accessGroupId": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return(groups, nil)
+				m.On("CreateEnvironment", agentOpts).Return(created, "", nil)
+				m.On("AddEnvironmentToAccessGroup", 3, 12).Return(fmt.Errorf("forbidden"))
+				m.On("DeleteEnvironment", 12).Return(nil)
+			},
+			expectError: "environment 12 was deleted to roll back the onboarding",
+		},
+		{
+			name:        "snapshot failure with failed rollback keeps the environment id",
+			inputParams: map[string]any{"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("CreateEnvironment", agentOpts).Return(created, "", nil)
+				m.On("SnapshotEnvironment", 12).Return(fmt.Errorf("agent unreachable"))
+				m.On("DeleteEnvironment", 12).Return(fmt.Errorf("timeout"))
+			},
+			expectError: "rollback failed, environment 12 was left in place",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setupMock(mockClient)
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleOnboardEnvironment()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			if tt.expectNoCreate {
+				mockClient.AssertNotCalled(t, "CreateEnvironment", mock.Anything)
+			}
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+				mockClient.AssertExpectations(t)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var report onboardingReport
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+			assert.Equal(t, tt.expectedSteps, report.Steps)
+			assert.Equal(t, tt.expectedKey, report.EdgeKey)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Statuses of an environment in a retag report.
+const (
+	retagStatusUpdated   = "updated"
+	retagStatusUnchanged = "unchanged"
+	retagStatusFailed    = "failed"
+	retagStatusSkipped   = "skipped"
+)
+
+// retagEnvironment is the tag change of one environment of a retag report.
+type retagEnvironment struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Added   []int  `json:"added,omitempty"`
+	Removed []int  `json:"removed,omitempty"`
+	// TagIDs is the resulting tag list of the environment.
+	TagIDs []int  `json:"tag_ids"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// retagReport is the result of HandleRetagEnvironments.
+type retagReport struct {
+	Matched      int                `json:"matched"`
+	Updated      int                `json:"updated"`
+	Unchanged    int                `json:"unchanged"`
+	Failed       int                `json:"failed"`
+	Environments []retagEnvironment `json:"environments"`
+	Notes        []string           `json:"notes,omitempty"`
+}
+
+// HandleRetagEnvironments returns an MCP tool handler that adds and removes tags across
+// the environments matching a filter. The tag list of every environment is merged with
+// the requested changes, and only the environments whose tags change are updated, with
+// at most fleetConcurrency updates in flight.
+func (s *PortainerMCPServer) HandleRetagEnvironments() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		addTagIds, err := parser.GetArrayOfIntegers("addTagIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid addTagIds parameter", err), nil
+		}
+		removeTagIds, err := parser.GetArrayOfIntegers("removeTagIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid removeTagIds parameter", err), nil
+		}
+		if len(addTagIds) == 0 && len(removeTagIds) == 0 {
+			return mcp.NewToolResultError("at least one of addTagIds and removeTagIds is required"), nil
+		}
+		for _, tagId := range append(slices.Clone(addTagIds), removeTagIds...) {
+			if err := validatePositiveID("tag ID", tagId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if slices.Contains(addTagIds, tagId) && slices.Contains(removeTagIds, tagId) {
+				return mcp.NewToolResultError(fmt.Sprintf("tag %d cannot be both added and removed", tagId)), nil
+			}
+		}
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, id := range environmentIds {
+			if err := validatePositiveID("environmentIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		opts, err := parseRetagFilter(parser)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(environmentIds) == 0 && opts == (models.EnvironmentListOptions{}) {
+			return mcp.NewToolResultError("select the environments with environmentIds or at least one filter (name, search, tagId, accessGroupId, status, type)"), nil
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+
+		// Only the tags being added must exist: removing a deleted tag is harmless
+		if _, err := s.resolveEnvironmentTagNames(addTagIds); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		environments, err := s.rootsEnvironments(ctx, opts)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+
+		report := retagReport{Environments: []retagEnvironment{}}
+		targets := selectRetagEnvironments(environments, environmentIds, &report)
+		report.Matched = len(targets)
+		for _, env := range targets {
+			report.Environments = append(report.Environments, mergeEnvironmentTags(env, addTagIds, removeTagIds))
+		}
+
+		if plan {
+			steps := []planStep{}
+			for _, change := range report.Environments {
+				if change.Status == "" {
+					steps = append(steps, planStep{
+						Method:      http.MethodPut,
+						Path:        fmt.Sprintf("/api/endpoints/%d", change.ID),
+						Description: fmt.Sprintf("Set the tags of environment %q to [%s]", change.Name, joinInts(change.TagIDs)),
+					})
+				}
+			}
+			notes := []string{"the environments are selected again when the plan is applied, so the tag changes are recomputed"}
+			return s.previewPlan(ctx, request, steps, notes, s.HandleRetagEnvironments())
+		}
+
+		pending := make([]int, 0, len(report.Environments))
+		for i, change := range report.Environments {
+			switch change.Status {
+			case "":
+				pending = append(pending, i)
+			case retagStatusUnchanged:
+				report.Unchanged++
+			}
+		}
+
+		errs := make([]error, len(pending))
+		done := make([]bool, len(pending))
+		runConcurrently(ctx, len(pending), func(i int) {
+			change := report.Environments[pending[i]]
+			errs[i] = s.cli.UpdateEnvironmentTags(change.ID, change.TagIDs)
+			done[i] = true
+		})
+
+		for i, idx := range pending {
+			change := &report.Environments[idx]
+			switch {
+			case !done[i]:
+				change.Status, change.Error = retagStatusSkipped, "not updated: the call was interrupted"
+			case errs[i] != nil:
+				change.Status, change.Error = retagStatusFailed, errs[i].Error()
+				report.Failed++
+			default:
+				change.Status = retagStatusUpdated
+				report.Updated++
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			report.Notes = append(report.Notes, "the call was interrupted before every environment was updated: "+err.Error())
+		}
+
+		return jsonResult(report, "failed to marshal retag report")
+	}
+}
+
+// parseRetagFilter parses the environment filters of retagEnvironments, which are the
+// filters of listEnvironments.
+func parseRetagFilter(parser *toolgen.ParameterParser) (models.EnvironmentListOptions, error) {
+	var opts models.EnvironmentListOptions
+	var err error
+
+	if opts.Name, err = parser.GetString("name", false); err != nil {
+		return opts, fmt.Errorf("invalid name parameter: %w", err)
+	}
+	if opts.Search, err = parser.GetString("search", false); err != nil {
+		return opts, fmt.Errorf("invalid search parameter: %w", err)
+	}
+	if opts.TagID, err = parser.GetInt("tagId", false); err != nil {
+		return opts, fmt.Errorf("invalid tagId parameter: %w", err)
+	}
+	if opts.GroupID, err = parser.GetInt("accessGroupId", false); err != nil {
+		return opts, fmt.Errorf("invalid accessGroupId parameter: %w", err)
+	}
+	if opts.Status, err = parser.GetString("status", false); err != nil {
+		return opts, fmt.Errorf("invalid status parameter: %w", err)
+	}
+	if opts.Type, err = parser.GetString("type", false); err != nil {
+		return opts, fmt.Errorf("invalid type parameter: %w", err)
+	}
+	return opts, nil
+}
+
+// selectRetagEnvironments returns the environments to retag: all the environments
+// matching the filters, or those of them listed in ids. Listed environments that do not
+// match the filters are reported as failed.
+func selectRetagEnvironments(environments []models.Environment, ids []int, report *retagReport) []models.Environment {
+	if len(ids) == 0 {
+		return environments
+	}
+
+	var targets []models.Environment
+	for _, id := range ids {
+		idx := slices.IndexFunc(environments, func(env models.Environment) bool { return env.ID == id })
+		if idx < 0 {
+			report.Environments = append(report.Environments, retagEnvironment{
+				ID:     id,
+				TagIDs: []int{},
+				Status: retagStatusFailed,
+				Error:  "environment not found or not matching the filters",
+			})
+			report.Failed++
+			continue
+		}
+		if !slices.ContainsFunc(targets, func(env models.Environment) bool { return env.ID == id }) {
+			targets = append(targets, environments[idx])
+		}
+	}
+	return targets
+}
+
+// mergeEnvironmentTags applies the tag changes to the tags of an environment. Tags that
+// the environment already has, or does not have, are not reported as added or removed.
+// The status is left empty when the environment must be updated, and set to unchanged
+// otherwise.
+func mergeEnvironmentTags(env models.Environment, addTagIds, removeTagIds []int) retagEnvironment {
+	change := retagEnvironment{ID: env.ID, Name: env.Name, TagIDs: []int{}}
+
+	for _, tagId := range env.TagIds {
+		if slices.Contains(removeTagIds, tagId) {
+			if !slices.Contains(change.Removed, tagId) {
+				change.Removed = append(change.Removed, tagId)
+			}
+			continue
+		}
+		if !slices.Contains(change.TagIDs, tagId) {
+			change.TagIDs = append(change.TagIDs, tagId)
+		}
+	}
+	for _, tagId := range addTagIds {
+		if !slices.Contains(change.TagIDs, tagId) {
+			change.TagIDs = append(change.TagIDs, tagId)
+			change.Added = append(change.Added, tagId)
+		}
+	}
+
+	if len(change.Added) == 0 && len(change.Removed) == 0 {
+		change.Status = retagStatusUnchanged
+	}
+	return change
+}
@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleRetagEnvironments verifies the HandleRetagEnvironments MCP tool handler.
+func TestHandleRetagEnvironments(t *testing.T) {
+	tags := []models.EnvironmentTag{{ID: 1, Name: "prod"}, {ID: 2, Name: "eu"}, {ID: 3, Name: "legacy"}}
+	environments := []models.Environment{
+		{ID: 10, Name: "prod-eu-1", TagIds: []int{1, 3}},
+		{ID: 11, Name: "prod-eu-2", TagIds: []int{1, 2}},
+		{ID: 12, Name: "prod-eu-3", TagIds: []int{}},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    retagReport
+	}{
+		{
+			name:  "merges the tags of every environment matching the filter",
+			input: map[string]any{"name": "prod-eu", "addTagIds": []any{float64(2)}, "removeTagIds": []any{float64(3)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{Name: "prod-eu"}).Return(environments, nil)
+				m.On("UpdateEnvironmentTags", 10, []int{1, 2}).Return(nil)
+				m.On("UpdateEnvironmentTags", 12, []int{2}).Return(fmt.Errorf("forbidden"))
+			},
+			expected: retagReport{
+				Matched: 3, Updated: 1, Unchanged: 1, Failed: 1,
+				Environments: []retagEnvironment{
+					{ID: 10, Name: "prod-eu-1", Added: []int{2}, Removed: []int{3}, TagIDs: []int{1, 2}, Status: retagStatusUpdated},
+					{ID: 11, Name: "prod-eu-2", TagIDs: []int{1, 2}, Status: retagStatusUnchanged},
+					{ID: 12, Name: "prod-eu-3", Added: []int{2}, TagIDs: []int{2}, Status: retagStatusFailed, Error: "forbidden"},
+				},
+			},
+		},
+		{
+			name:  "listed environments narrowed by the filters",
+			input: map[string]any{"environmentIds": []any{float64(11), float64(99)}, "tagId": float64(1), "removeTagIds": []any{float64(1)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{TagID: 1}).Return(environments[:2], nil)
+				m.On("UpdateEnvironmentTags", 11, []int{2}).Return(nil)
+			},
+			expected: retagReport{
+				Matched: 1, Updated: 1, Failed: 1,
+				Environments: []retagEnvironment{
+					{ID: 99, TagIDs: []int{}, Status: retagStatusFailed, Error: "environment not found or not matching the filters"},
+					{ID: 11, Name: "prod-eu-2", Removed: []int{1}, TagIDs: []int{2}, Status: retagStatusUpdated},
+				},
+			},
+		},
+		{
+			name:  "unknown tag to add",
+			input: map[string]any{"name": "prod", "addTagIds": []any{float64(7)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return(tags, nil)
+			},
+			expectError: "environment tags not found: 7",
+		},
+		{
+			name:  "environment listing error",
+			input: map[string]any{"name": "prod", "removeTagIds": []any{float64(3)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironments", models.EnvironmentListOptions{Name: "prod"}).Return(nil, fmt.Errorf("timeout"))
+			},
+			expectError: "timeout",
+		},
+		{
+			name:        "no tag changes",
+			input:       map[string]any{"name": "prod"},
+			expectError: "at least one of addTagIds and removeTagIds",
+		},
+		{
+			name:        "tag both added and removed",
+			input:       map[string]any{"name": "prod", "addTagIds": []any{float64(1)}, "removeTagIds": []any{float64(1)}},
+			expectError: "tag 1 cannot be both added and removed",
+		},
+		{
+			name:        "no selection",
+			input:       map[string]any{"addTagIds": []any{float64(1)}},
+			expectError: "select the environments",
+		},
+		{
+			name:        "invalid environment ID",
+			input:       map[string]any{"environmentIds": []any{float64(0)}, "addTagIds": []any{float64(1)}},
+			expectError: "environmentIds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleRetagEnvironments()(context.Background(), CreateMCPRequest(tt.input))
+			require.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				require.False(t, result.IsError, textContent.Text)
+				var report retagReport
+				require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+				assert.Equal(t, tt.expected, report)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRetagEnvironmentsPlan verifies that a retag preview updates nothing and lists one
+// update per environment whose tags change.
+func TestRetagEnvironmentsPlan(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 2, Name: "eu"}}, nil)
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{Status: models.EnvironmentStatusActive}).Return([]models.Environment{
+		{ID: 10, Name: "edge-1", TagIds: []int{1}},
+		{ID: 11, Name: "edge-2", TagIds: []int{2}},
+	}, nil)
+	s := &PortainerMCPServer{cli: mockClient}
+
+	result, err := s.HandleRetagEnvironments()(context.Background(), CreateMCPRequest(map[string]any{
+		"status": "active", "addTagIds": []any{float64(2)}, "plan": true,
+	}))
+	require.NoError(t, err)
+
+	plan := decodePlan(t, result)
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, "PUT /api/endpoints/10", plan.Steps[0].Method+" "+plan.Steps[0].Path)
+	assert.Equal(t, `Set the tags of environment "edge-1" to [1, 2]`, plan.Steps[0].Description)
+	mockClient.AssertNotCalled(t, "UpdateEnvironmentTags", mock.Anything, mock.Anything)
+}
+
+// TestMergeEnvironmentTags verifies that duplicate tags are collapsed and that tags the
+// environment does not carry are not reported as removed.
+func TestMergeEnvironmentTags(t *testing.T) {
+	change := mergeEnvironmentTags(models.Environment{ID: 1, Name: "env", TagIds: []int{4, 4, 5}}, []int{5, 6}, []int{7})
+	assert.Equal(t, []int{4, 5, 6}, change.TagIDs)
+	assert.Equal(t, []int{6}, change.Added)
+	assert.Empty(t, change.Removed)
+	assert.Empty(t, change.Status)
+
+	change = mergeEnvironmentTags(models.Environment{ID: 1, Name: "env", TagIds: []int{4}}, []int{4}, []int{7})
+	assert.Equal(t, retagStatusUnchanged, change.Status)
+}
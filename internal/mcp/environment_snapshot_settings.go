@@ -0,0 +1,209 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// snapshotSettingsReport is the result of HandleGetSnapshotSettings.
+type snapshotSettingsReport struct {
+	models.SnapshotSettings
+	Environment *environmentSnapshotReport `json:"environment,omitempty"`
+}
+
+// environmentSnapshotReport describes the snapshot behavior of an environment.
+type environmentSnapshotReport struct {
+	models.EnvironmentSnapshotSettings
+	// EffectiveSnapshotInterval is the interval between snapshots of the environment once
+	// the global defaults are applied, as a Go duration.
+	EffectiveSnapshotInterval string `json:"effective_snapshot_interval"`
+}
+
+// HandleGetSnapshotSettings returns an MCP tool handler that retrieves the global snapshot
+// schedule and, when an environment is given, its own snapshot behavior.
+func (s *PortainerMCPServer) HandleGetSnapshotSettings() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentID, err := parser.GetInt("environmentId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if _, ok := request.GetArguments()["environmentId"]; ok {
+			if err := validatePositiveID("environmentId", environmentID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		settings, err := s.cli.GetSnapshotSettings()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get snapshot settings", err), nil
+		}
+		report := snapshotSettingsReport{SnapshotSettings: settings}
+
+		if environmentID > 0 {
+			environment, err := s.cli.GetEnvironmentSnapshotSettings(environmentID)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get environment snapshot settings", err), nil
+			}
+			report.Environment = &environmentSnapshotReport{
+				EnvironmentSnapshotSettings: environment,
+				EffectiveSnapshotInterval:   effectiveSnapshotInterval(settings, environment),
+			}
+		}
+
+		return jsonResult(report, "failed to marshal snapshot settings")
+	}
+}
+
+// effectiveSnapshotInterval returns the interval between snapshots of an environment. Edge
+// agents send a snapshot at each check-in, or every async snapshot interval in async mode;
+// the other environments follow the global snapshot interval.
+func effectiveSnapshotInterval(settings models.SnapshotSettings, environment models.EnvironmentSnapshotSettings) string {
+	if !isEdgeEnvironment(models.Environment{Type: environment.Type}) {
+		return settings.SnapshotInterval
+	}
+
+	seconds := environment.EdgeCheckinInterval
+	if seconds <= 0 {
+		seconds = settings.EdgeAgentCheckinInterval
+	}
+	if environment.EdgeAsyncMode {
+		seconds = environment.EdgeSnapshotInterval
+		if seconds <= 0 {
+			seconds = settings.EdgeAsyncSnapshotInterval
+		}
+	}
+	return (time.Duration(seconds) * time.Second).String()
+}
+
+// HandleUpdateSnapshotSettings returns an MCP tool handler that updates the global snapshot
+// schedule and the snapshot behavior of an edge environment. The replaced global values are
+// saved as a settings snapshot, so that revertSettings can restore them.
+func (s *PortainerMCPServer) HandleUpdateSnapshotSettings() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+		args := request.GetArguments()
+
+		global := map[string]any{}
+		if _, ok := args["snapshotInterval"]; ok {
+			interval, err := parser.GetString("snapshotInterval", true)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid snapshotInterval parameter", err), nil
+			}
+			if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("snapshotInterval must be a positive duration such as 5m or 1h, got %q", interval)), nil
+			}
+			global["snapshotInterval"] = interval
+		}
+		if _, ok := args["edgeAgentCheckinInterval"]; ok {
+			interval, err := parser.GetInt("edgeAgentCheckinInterval", true)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid edgeAgentCheckinInterval parameter", err), nil
+			}
+			if interval <= 0 {
+				return mcp.NewToolResultError("edgeAgentCheckinInterval must be a positive number of seconds"), nil
+			}
+			global["edgeAgentCheckinInterval"] = interval
+		}
+
+		var edgeCheckinInterval, edgeSnapshotInterval *int
+		if _, ok := args["edgeCheckinInterval"]; ok {
+			interval, err := parser.GetInt("edgeCheckinInterval", true)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid edgeCheckinInterval parameter", err), nil
+			}
+			if interval < 0 {
+				return mcp.NewToolResultError("edgeCheckinInterval must be a number of seconds, or 0 to use the global default"), nil
+			}
+			edgeCheckinInterval = &interval
+		}
+		if _, ok := args["edgeSnapshotInterval"]; ok {
+			interval, err := parser.GetInt("edgeSnapshotInterval", true)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid edgeSnapshotInterval parameter", err), nil
+			}
+			if interval == 0 || interval < -1 {
+				return mcp.NewToolResultError("edgeSnapshotInterval must be a positive number of seconds, or -1 to use the global default"), nil
+			}
+			edgeSnapshotInterval = &interval
+		}
+
+		environmentID, err := parser.GetInt("environmentId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		_, hasEnvironment := args["environmentId"]
+		hasEnvironmentSettings := edgeCheckinInterval != nil || edgeSnapshotInterval != nil
+		switch {
+		case hasEnvironment && !hasEnvironmentSettings:
+			return mcp.NewToolResultError("environmentId requires edgeCheckinInterval or edgeSnapshotInterval"), nil
+		case hasEnvironmentSettings && !hasEnvironment:
+			return mcp.NewToolResultError("edgeCheckinInterval and edgeSnapshotInterval require environmentId"), nil
+		case !hasEnvironmentSettings && len(global) == 0:
+			return mcp.NewToolResultError("no snapshot setting to update: pass snapshotInterval, edgeAgentCheckinInterval, or environmentId with edgeCheckinInterval or edgeSnapshotInterval"), nil
+		}
+
+		var environment models.EnvironmentSnapshotSettings
+		if hasEnvironment {
+			if err := validatePositiveID("environmentId", environmentID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			environment, err = s.cli.GetEnvironmentSnapshotSettings(environmentID)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get environment snapshot settings", err), nil
+			}
+			if !isEdgeEnvironment(models.Environment{Type: environment.Type}) {
+				return mcp.NewToolResultError(fmt.Sprintf("environment %d is a %s environment: only edge environments have their own snapshot intervals, the others follow snapshotInterval", environmentID, environment.Type)), nil
+			}
+		}
+
+		var changes []string
+		snapshotID := 0
+		if len(global) > 0 {
+			keys := slices.Sorted(maps.Keys(global))
+			snapshot, err := s.snapshotSettings(ToolUpdateSnapshotSettings, keys)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to snapshot current settings", err), nil
+			}
+			if err := s.cli.UpdateSettings(global); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to update snapshot settings", err), nil
+			}
+			snapshotID = s.settingsSnapshots.add(snapshot)
+			for _, key := range keys {
+				changes = append(changes, fmt.Sprintf("%s=%v", key, global[key]))
+			}
+		}
+
+		if hasEnvironment {
+			if err := s.cli.UpdateEnvironmentSnapshotSettings(environmentID, edgeCheckinInterval, edgeSnapshotInterval); err != nil {
+				message := "failed to update environment snapshot settings"
+				if snapshotID > 0 {
+					message += fmt.Sprintf(" (the global settings were updated; use revertSettings with snapshot %d to restore them)", snapshotID)
+				}
+				return mcp.NewToolResultErrorFromErr(message, err), nil
+			}
+			if edgeCheckinInterval != nil {
+				changes = append(changes, fmt.Sprintf("environment %d edgeCheckinInterval=%d (was %d)", environmentID, *edgeCheckinInterval, environment.EdgeCheckinInterval))
+			}
+			if edgeSnapshotInterval != nil {
+				changes = append(changes, fmt.Sprintf("environment %d edgeSnapshotInterval=%d (was %d)", environmentID, *edgeSnapshotInterval, environment.EdgeSnapshotInterval))
+			}
+		}
+
+		message := fmt.Sprintf("Snapshot settings updated: %s.", strings.Join(changes, ", "))
+		if snapshotID > 0 {
+			message += fmt.Sprintf(" Previous global values saved as snapshot %d; use revertSettings to restore them.", snapshotID)
+		}
+		return mcp.NewToolResultText(message), nil
+	}
+}
@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleGetSnapshotSettings verifies the global schedule and the effective interval of
+// an environment.
+func TestHandleGetSnapshotSettings(t *testing.T) {
+	global := models.SnapshotSettings{SnapshotInterval: "5m", EdgeAgentCheckinInterval: 5, EdgeAsyncSnapshotInterval: 60}
+
+	tests := []struct {
+		name              string
+		inputParams       map[string]any
+		environment       models.EnvironmentSnapshotSettings
+		environmentErr    error
+		expectError       string
+		expectedEffective string
+	}{
+		{
+			name:        "global settings only",
+			inputParams: map[string]any{},
+		},
+		{
+			name:              "agent environment follows the global interval",
+			inputParams:       map[string]any{"environmentId": float64(1)},
+			environment:       models.EnvironmentSnapshotSettings{EnvironmentID: 1, Type: models.EnvironmentTypeDockerAgent},
+			expectedEffective: "5m",
+		},
+		{
+			name:              "edge environment with the default check-in interval",
+			inputParams:       map[string]any{"environmentId": float64(2)},
+			environment:       models.EnvironmentSnapshotSettings{EnvironmentID: 2, Type: models.EnvironmentTypeDockerEdgeAgent},
+			expectedEffective: "5s",
+		},
+		{
+			name:              "edge environment with its own check-in interval",
+			inputParams:       map[string]any{"environmentId": float64(2)},
+			environment:       models.EnvironmentSnapshotSettings{EnvironmentID: 2, Type: models.EnvironmentTypeKubernetesEdgeAgent, EdgeCheckinInterval: 30},
+			expectedEffective: "30s",
+		},
+		{
+			name:              "async edge environment with the default snapshot interval",
+			inputParams:       map[string]any{"environmentId": float64(3)},
+			environment:       models.EnvironmentSnapshotSettings{EnvironmentID: 3, Type: models.EnvironmentTypeDockerEdgeAgent, EdgeAsyncMode: true, EdgeSnapshotInterval: -1},
+			expectedEffective: "1m0s",
+		},
+		{
+			name:              "async edge environment with its own snapshot interval",
+			inputParams:       map[string]any{"environmentId": float64(3)},
+			environment:       models.EnvironmentSnapshotSettings{EnvironmentID: 3, Type: models.EnvironmentTypeDockerEdgeAgent, EdgeAsyncMode: true, EdgeSnapshotInterval: 3600},
+			expectedEffective: "1h0m0s",
+		},
+		{
+			name:           "environment error",
+			inputParams:    map[string]any{"environmentId": float64(4)},
+			environment:    models.EnvironmentSnapshotSettings{EnvironmentID: 4},
+			environmentErr: fmt.Errorf("not found"),
+			expectError:    "failed to get environment snapshot settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetSnapshotSettings").Return(global, nil)
+			_, hasEnvironment := tt.inputParams["environmentId"]
+			if hasEnvironment {
+				mockClient.On("GetEnvironmentSnapshotSettings", tt.environment.EnvironmentID).Return(tt.environment, tt.environmentErr)
+			}
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleGetSnapshotSettings()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var report snapshotSettingsReport
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+			assert.Equal(t, global, report.SnapshotSettings)
+			if !hasEnvironment {
+				assert.Nil(t, report.Environment)
+			} else {
+				require.NotNil(t, report.Environment)
+				assert.Equal(t, tt.environment, report.Environment.EnvironmentSnapshotSettings)
+				assert.Equal(t, tt.expectedEffective, report.Environment.EffectiveSnapshotInterval)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleUpdateSnapshotSettings verifies the validation of the intervals, the snapshot of
+// the replaced global settings and the update of edge environments.
+func TestHandleUpdateSnapshotSettings(t *testing.T) {
+	edge := models.EnvironmentSnapshotSettings{EnvironmentID: 3, Type: models.EnvironmentTypeDockerEdgeAgent, EdgeCheckinInterval: 30, EdgeSnapshotInterval: -1}
+	agent := models.EnvironmentSnapshotSettings{EnvironmentID: 4, Type: models.EnvironmentTypeDockerAgent}
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name        string
+		inputParams map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expectText  []string
+	}{
+		{
+			name:        "global intervals",
+			inputParams: map[string]any{"snapshotInterval": "10m", "edgeAgentCheckinInterval": float64(30)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"edgeAgentCheckinInterval", "snapshotInterval"}).Return(map[string]any{"edgeAgentCheckinInterval": float64(5), "snapshotInterval": "5m"}, nil)
+				m.On("UpdateSettings", map[string]any{"snapshotInterval": "10m", "edgeAgentCheckinInterval": 30}).Return(nil)
+			},
+			expectText: []string{"edgeAgentCheckinInterval=30, snapshotInterval=10m", "snapshot 1", "revertSettings"},
+		},
+		{
+			name:        "edge environment intervals",
+			inputParams: map[string]any{"environmentId": float64(3), "edgeCheckinInterval": float64(0), "edgeSnapshotInterval": float64(300)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentSnapshotSettings", 3).Return(edge, nil)
+				m.On("UpdateEnvironmentSnapshotSettings", 3, intPtr(0), intPtr(300)).Return(nil)
+			},
+			expectText: []string{"environment 3 edgeCheckinInterval=0 (was 30)", "environment 3 edgeSnapshotInterval=300 (was -1)"},
+		},
+		{
+			name:        "environment update failure after global update",
+			inputParams: map[string]any{"snapshotInterval": "1h", "environmentId": float64(3), "edgeCheckinInterval": float64(10)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentSnapshotSettings", 3).Return(edge, nil)
+				m.On("GetSettingsValues", []string{"snapshotInterval"}).Return(map[string]any{"snapshotInterval": "5m"}, nil)
+				m.On("UpdateSettings", map[string]any{"snapshotInterval": "1h"}).Return(nil)
+				m.On("UpdateEnvironmentSnapshotSettings", 3, intPtr(10), (*int)(nil)).Return(fmt.Errorf("forbidden"))
+			},
+			expectError: "use revertSettings with snapshot 1",
+		},
+		{
+			name:        "non-edge environment",
+			inputParams: map[string]any{"environmentId": float64(4), "edgeCheckinInterval": float64(10)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentSnapshotSettings", 4).Return(agent, nil)
+			},
+			expectError: "only edge environments have their own snapshot intervals",
+		},
+		{
+			name:        "invalid snapshot interval",
+			inputParams: map[string]any{"snapshotInterval": "often"},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "snapshotInterval must be a positive duration",
+		},
+		{
+			name:        "invalid edge snapshot interval",
+			inputParams: map[string]any{"environmentId": float64(3), "edgeSnapshotInterval": float64(0)},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "edgeSnapshotInterval must be a positive number of seconds, or -1",
+		},
+		{
+			name:        "environment intervals without environment",
+			inputParams: map[string]any{"edgeCheckinInterval": float64(10)},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "require environmentId",
+		},
+		{
+			name:        "environment without intervals",
+			inputParams: map[string]any{"environmentId": float64(3)},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "environmentId requires edgeCheckinInterval or edgeSnapshotInterval",
+		},
+		{
+			name:        "nothing to update",
+			inputParams: map[string]any{},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "no snapshot setting to update",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setupMock(mockClient)
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleUpdateSnapshotSettings()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+			} else {
+				require.False(t, result.IsError, text)
+				for _, expected := range tt.expectText {
+					assert.Contains(t, text, expected)
+				}
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
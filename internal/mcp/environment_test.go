@@ -40,7 +40,7 @@ func TestHandleGetEnvironments(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
-			mockClient.On("GetEnvironments").Return(tt.mockEnvironments, tt.mockError)
+			mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return(tt.mockEnvironments, tt.mockError)
 
 			server := &PortainerMCPServer{
 				cli: mockClient,
@@ -78,6 +78,51 @@ func TestHandleGetEnvironments(t *testing.T) {
 	}
 }
 
+// TestHandleGetEnvironments_Filters verifies that listEnvironments passes its filter
+// parameters to the client.
+func TestHandleGetEnvironments_Filters(t *testing.T) {
+	environments := []models.Environment{{ID: 1, Name: "prod-docker"}}
+
+	tests := []struct {
+		name         string
+		args         map[string]any
+		expectedOpts models.EnvironmentListOptions
+		expectError  bool
+	}{
+		{name: "no filters", args: map[string]any{}},
+		{name: "name", args: map[string]any{"name": "prod"}, expectedOpts: models.EnvironmentListOptions{Name: "prod"}},
+		{name: "search", args: map[string]any{"search": "10.0.0"}, expectedOpts: models.EnvironmentListOptions{Search: "10.0.0"}},
+		{
+			name:         "combined filters",
+			args:         map[string]any{"name": "prod", "tagId": float64(2), "accessGroupId": float64(1), "status": "active", "type": "docker-edge-agent"},
+			expectedOpts: models.EnvironmentListOptions{Name: "prod", TagID: 2, GroupID: 1, Status: "active", Type: "docker-edge-agent"},
+		},
+		{name: "invalid tagId", args: map[string]any{"tagId": "one"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetEnvironments", tt.expectedOpts).Return(environments, nil).Maybe()
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetEnvironments()(context.Background(), CreateMCPRequest(tt.args))
+			assert.NoError(t, err)
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			var got []models.Environment
+			err = json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &got)
+			assert.NoError(t, err)
+			assert.Equal(t, environments, got)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
 // TestHandleGetEnvironment verifies the HandleGetEnvironment MCP tool handler.
 func TestHandleGetEnvironment(t *testing.T) {
 	tests := []struct {
@@ -215,6 +260,9 @@ func TestHandleDeleteEnvironment(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
 			if !tt.expectError || tt.mockError != nil {
+				mockClient.On("GetRegularStacks").Return([]models.RegularStack{}, nil)
+				mockClient.On("GetWebhooks").Return([]models.Webhook{}, nil)
+				mockClient.On("GetEdgeJobs").Return([]models.EdgeJob{}, nil)
 				mockClient.On("DeleteEnvironment", tt.inputID).Return(tt.mockError)
 			}
 
@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Error codes set in the _meta of error results, so that clients can retry, skip or
+// correct a failed call without parsing the error text
+const (
+	// ErrorCodeNotFound means that the resource does not exist
+	ErrorCodeNotFound = "NOT_FOUND"
+	// ErrorCodeForbidden means that the API token, the environment scope or the operation
+	// budget does not allow the call
+	ErrorCodeForbidden = "FORBIDDEN"
+	// ErrorCodeValidation means that the arguments are invalid and the call must be corrected
+	ErrorCodeValidation = "VALIDATION"
+	// ErrorCodeUpstreamUnavailable means that Portainer or the environment could not be
+	// reached in time, and the call may succeed later
+	ErrorCodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	// ErrorCodeReadOnly means that the call modifies resources and the server runs in
+	// read-only mode
+	ErrorCodeReadOnly = "READ_ONLY"
+	// ErrorCodeInternal is set on the errors that match no other code
+	ErrorCodeInternal = "INTERNAL"
+
+	// errorCodeMetaKey is the _meta key of the error code of a result
+	errorCodeMetaKey = "errorCode"
+)
+
+// newToolResultErrorWithCode creates an error result with an error code
+func newToolResultErrorWithCode(code, text string) *mcp.CallToolResult {
+	result := mcp.NewToolResultError(text)
+	setErrorCode(result, code)
+	return result
+}
+
+// setErrorCode sets the error code of a result
+func setErrorCode(result *mcp.CallToolResult, code string) {
+	setResultMeta(result, errorCodeMetaKey, code)
+}
+
+// errorCode returns the error code of a result, or an empty string
+func errorCode(result *mcp.CallToolResult) string {
+	code, _ := resultMeta(result, errorCodeMetaKey).(string)
+	return code
+}
+
+// httpStatusPattern matches the HTTP status in the errors of the Portainer client, such
+// as "[GET /endpoints/{id}][404] endpointInspectNotFound" or "status 503: ..."
+var httpStatusPattern = regexp.MustCompile(`\]\[(\d{3})\]|status (\d{3})\b`)
+
+// upstreamUnavailableMarkers are the error texts of network failures and timeouts
+var upstreamUnavailableMarkers = []string{
+	"connection refused",
+	"connection reset",
+	"no such host",
+	"i/o timeout",
+	"timed out",
+	"deadline exceeded",
+	"unexpected eof",
+	"tls handshake",
+}
+
+// classifyError returns the error code of an error message produced by a handler
+func classifyError(text string) string {
+	lower := strings.ToLower(text)
+
+	if match := httpStatusPattern.FindStringSubmatch(text); match != nil {
+		status, _ := strconv.Atoi(match[1] + match[2])
+		switch {
+		case status == 404:
+			return ErrorCodeNotFound
+		case status == 401 || status == 403:
+			return ErrorCodeForbidden
+		case status == 400 || status == 409 || status == 422:
+			return ErrorCodeValidation
+		case status == 429 || status == 502 || status == 503 || status == 504:
+			return ErrorCodeUpstreamUnavailable
+		}
+	}
+
+	for _, marker := range upstreamUnavailableMarkers {
+		if strings.Contains(lower, marker) {
+			return ErrorCodeUpstreamUnavailable
+		}
+	}
+
+	switch {
+	case strings.Contains(lower, "read-only mode"):
+		return ErrorCodeReadOnly
+	case strings.HasPrefix(lower, "invalid "), strings.HasPrefix(lower, "missing required parameter"), strings.HasPrefix(lower, "unknown action"):
+		return ErrorCodeValidation
+	case strings.Contains(lower, "not found"), strings.Contains(lower, "no such "), strings.Contains(lower, "does not exist"):
+		return ErrorCodeNotFound
+	case strings.Contains(lower, "forbidden"), strings.Contains(lower, "access denied"), strings.Contains(lower, "unauthorized"), strings.Contains(lower, "not accessible"):
+		return ErrorCodeForbidden
+	}
+
+	for _, pattern := range parameterErrorPatterns {
+		if pattern.MatchString(text) {
+			return ErrorCodeValidation
+		}
+	}
+	return ErrorCodeInternal
+}
+
+// errorCodeMiddleware sets the error code of every error result that has none, from its
+// error text, so that each failed call carries a machine-readable code next to the text.
+func errorCodeMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || !result.IsError || errorCode(result) != "" {
+			return result, err
+		}
+
+		setErrorCode(result, resultErrorCode(result))
+		return result, nil
+	}
+}
+
+// resultErrorCode returns the error code of an error result: the code it carries, or
+// else the code derived from its error text
+func resultErrorCode(result *mcp.CallToolResult) string {
+	if code := errorCode(result); code != "" {
+		return code
+	}
+
+	var text strings.Builder
+	for _, content := range result.Content {
+		if t, ok := content.(mcp.TextContent); ok {
+			text.WriteString(t.Text)
+		}
+	}
+	return classifyError(text.String())
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClassifyError verifies the error codes of the errors produced by handlers and the
+// Portainer client.
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "failed to get environment: [GET /endpoints/{id}][404] endpointInspectNotFound", want: ErrorCodeNotFound},
+		{text: "failed to get container logs: status 404: No such container: web", want: ErrorCodeNotFound},
+		{text: "failed to delete user: [DELETE /users/{id}][403] userDeleteForbidden", want: ErrorCodeForbidden},
+		{text: "failed to list stacks: unknown error (status 401): Unauthorized", want: ErrorCodeForbidden},
+		{text: "failed to create tag: [POST /tags][409] tagCreateConflict", want: ErrorCodeValidation},
+		{text: "failed to list docker containers: status 503: Service Unavailable", want: ErrorCodeUpstreamUnavailable},
+		{text: "failed to get environments: dial tcp 10.0.0.1:9443: connect: connection refused", want: ErrorCodeUpstreamUnavailable},
+		{text: "failed to get settings: context deadline exceeded", want: ErrorCodeUpstreamUnavailable},
+		{text: "invalid environmentId parameter: environmentId is required", want: ErrorCodeValidation},
+		{text: "tail must not be negative, got -1", want: ErrorCodeValidation},
+		{text: "stack 7 not found", want: ErrorCodeNotFound},
+		{text: "environment(s) [3] are not accessible to the API token user", want: ErrorCodeForbidden},
+		{text: "action 'delete_stack' of tool 'manage_stacks' modifies resources and is not available in read-only mode", want: ErrorCodeReadOnly},
+		{text: "failed to update settings: status 500: internal error", want: ErrorCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyError(tt.text))
+		})
+	}
+}
+
+// TestErrorCodeMiddleware verifies that error results get an error code and that codes
+// set by handlers and successful results are left alone.
+func TestErrorCodeMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		want   string
+	}{
+		{
+			name:   "classified error",
+			result: mcp.NewToolResultError("failed to get stack: [GET /stacks/{id}][404] stackInspectNotFound"),
+			want:   ErrorCodeNotFound,
+		},
+		{
+			name:   "code set by the handler",
+			result: newToolResultErrorWithCode(ErrorCodeForbidden, "budget exhausted: limit 1"),
+			want:   ErrorCodeForbidden,
+		},
+		{
+			name:   "successful result",
+			result: mcp.NewToolResultText("stack 1 not found in the journal"),
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := errorCodeMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+
+			result, err := handler(context.Background(), CreateMCPRequest(nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, errorCode(result))
+		})
+	}
+}
@@ -3,10 +3,15 @@
 package mcp
 
 import (
+"context"
+"encoding/json"
+"maps"
+"slices"
 "testing"
 
 "github.com/mark3labs/mcp-go/mcp"
 "github.com/mark3labs/mcp-go/server"
+"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 "github.com/stretchr/testify/assert"
 )
 
@@ -17,34 +22,34 @@ func allToolNames() map[string]mcp.Tool {
 names := []string{
 ToolCreateEnvironmentGroup, ToolListEnvironmentGroups,
 ToolCreateAccessGroup, ToolListAccessGroups,
-ToolAddEnvironmentToAccessGroup, ToolRemoveEnvironmentFromAccessGroup,
-ToolListEnvironments, ToolGetEnvironment, ToolDeleteEnvironment,
+ToolAddEnvironmentToAccessGroup, ToolRemoveEnvironmentFromAccessGroup, ToolSetAccessGroupEnvironments,
+ToolListEnvironments, ToolGetEnvironment, ToolWhoCanAccessEnvironment, ToolDeleteEnvironment,
 ToolSnapshotEnvironment, ToolSnapshotAllEnvironments,
 ToolGetStackFile, ToolCreateStack, ToolListStacks, ToolListRegularStacks,
 ToolUpdateStack, ToolGetStack, ToolDeleteStack, ToolInspectStackFile,
-ToolUpdateStackGit, ToolRedeployStackGit, ToolStartStack, ToolStopStack, ToolMigrateStack,
+ToolUpdateStackGit, ToolRedeployStackGit, ToolStartStack, ToolStopStack, ToolMigrateStack, ToolMigrateRegularStackToEdge, ToolDeployStackAndWait, ToolWaitFor, ToolGetStackFileHistory, ToolDetectDrift, ToolAnalyzeStackImages, ToolGetStackResources, ToolWaitForEdgeStackRollout, ToolRedeployStacksForImage,
 ToolCreateEnvironmentTag, ToolDeleteEnvironmentTag, ToolListEnvironmentTags,
 ToolCreateTeam, ToolGetTeam, ToolDeleteTeam, ToolListTeams,
-ToolUpdateTeamName, ToolUpdateTeamMembers,
-ToolListUsers, ToolCreateUser, ToolGetUser, ToolDeleteUser, ToolUpdateUserRole,
+ToolUpdateTeamName, ToolUpdateTeamMembers, ToolAuditTeamAccess, ToolImportTeams,
+ToolListUsers, ToolCreateUser, ToolGetUser, ToolDeleteUser, ToolUpdateUserRole, ToolImportUsers, ToolOffboardUser,
 ToolGetSettings, ToolUpdateSettings, ToolGetPublicSettings,
 ToolGetSSLSettings, ToolUpdateSSLSettings,
 ToolListAppTemplates, ToolGetAppTemplateFile,
 ToolUpdateAccessGroupName, ToolUpdateAccessGroupUserAccesses, ToolUpdateAccessGroupTeamAccesses,
-ToolUpdateEnvironmentTags, ToolUpdateEnvironmentUserAccesses, ToolUpdateEnvironmentTeamAccesses,
+ToolUpdateEnvironmentTags, ToolRetagEnvironments, ToolEnterMaintenanceMode, ToolExitMaintenanceMode, ToolListMaintenanceEnvironments, ToolUpdateEnvironmentUserAccesses, ToolUpdateEnvironmentTeamAccesses,
 ToolUpdateEnvironmentGroupName, ToolUpdateEnvironmentGroupEnvironments, ToolUpdateEnvironmentGroupTags,
-ToolDockerProxy, ToolGetDockerDashboard,
+ToolDockerProxy, ToolDockerProxyGet, ToolGetDockerDashboard, ToolListContainers, ToolGetContainerLogs, ToolListDockerEvents, ToolGetFleetContainerUsage, ToolSuggestCleanup, ToolAuditRestartPolicies, ToolGetPortReport, ToolCheckImageUpdates,
 ToolKubernetesProxy, ToolKubernetesProxyStripped,
 ToolGetKubernetesDashboard, ToolListKubernetesNamespaces, ToolGetKubernetesConfig,
-ToolGetSystemStatus,
+ToolGetSystemStatus, ToolApplyPlan, ToolGetDeleteJournal, ToolRevertSettings, ToolGetHelmReleaseStatus, ToolDescribeKubernetesResource, ToolListKubernetesAPIResources, ToolTopKubernetesNodes, ToolTopKubernetesPods, ToolGetNamespaceUsage, ToolListCustomResources, ToolReadContainerFile, ToolWriteContainerFile, ToolGetContainerTop, ToolOnboardEnvironment, ToolPreviewEnvironmentGroupMembers, ToolVerifyBackup, ToolGetSnapshotSettings, ToolUpdateSnapshotSettings, ToolGetGroupCapacity, ToolGetTrends, ToolBuildTimeline, ToolExportSettings, ToolImportSettings, ToolCompareInstances, ToolBenchmarkLatency, ToolGetServerStats,
 ToolListCustomTemplates, ToolGetCustomTemplate, ToolGetCustomTemplateFile,
 ToolCreateCustomTemplate, ToolDeleteCustomTemplate,
-ToolListRegistries, ToolGetRegistry, ToolCreateRegistry, ToolUpdateRegistry, ToolDeleteRegistry,
+ToolListRegistries, ToolGetRegistry, ToolGetRegistryUsage, ToolCreateRegistry, ToolUpdateRegistry, ToolDeleteRegistry, ToolRotateRegistryCredentials,
 ToolGetBackupStatus, ToolGetBackupS3Settings, ToolCreateBackup, ToolBackupToS3, ToolRestoreFromS3,
-ToolListRoles, ToolGetMOTD,
+ToolListRoles, ToolGetRole, ToolCompareRoles, ToolAssignRole, ToolUnassignRole, ToolGetMOTD, ToolGetLoginBanner, ToolUpdateLoginBanner,
 ToolListWebhooks, ToolCreateWebhook, ToolDeleteWebhook,
 ToolListEdgeJobs, ToolGetEdgeJob, ToolGetEdgeJobFile, ToolCreateEdgeJob, ToolDeleteEdgeJob,
-ToolListEdgeUpdateSchedules,
+ToolListEdgeUpdateSchedules, ToolGetAgentVersionReport, ToolScheduleAgentUpgrades,
 ToolAuthenticate, ToolLogout,
 ToolListHelmRepositories, ToolAddHelmRepository, ToolRemoveHelmRepository,
 ToolSearchHelmCharts, ToolInstallHelmChart, ToolListHelmReleases,
@@ -316,6 +321,18 @@ assert.NotPanics(t, func() { s.AddSystemFeatures() })
 })
 }
 
+// TestAddPlanFeatures verifies tool registration for execution plans.
+func TestAddPlanFeatures(t *testing.T) {
+t.Run("read-write", func(t *testing.T) {
+s := newTestServer(false)
+assert.NotPanics(t, func() { s.AddPlanFeatures() })
+})
+t.Run("read-only", func(t *testing.T) {
+s := newTestServer(true)
+assert.NotPanics(t, func() { s.AddPlanFeatures() })
+})
+}
+
 // TestAddTagFeatures verifies tool registration for tags.
 func TestAddTagFeatures(t *testing.T) {
 t.Run("read-write", func(t *testing.T) {
@@ -434,3 +451,24 @@ WithReadOnly(true),
 assert.NoError(t, err)
 assert.True(t, s.readOnly)
 }
+
+// TestAddAllFeatures verifies that AddAllFeatures registers every known tool.
+func TestAddAllFeatures(t *testing.T) {
+s := newTestServer(false)
+s.AddAllFeatures()
+
+expected := slices.Sorted(maps.Keys(allToolNames()))
+assert.Equal(t, expected, listRegisteredTools(t, s.srv))
+}
+
+// TestHandleMessage verifies that a tools/call request is routed to the tool handler.
+func TestHandleMessage(t *testing.T) {
+s := newTestServer(true)
+s.cli.(*MockPortainerClient).On("GetSystemStatus").Return(models.SystemStatus{Version: "2.31.2"}, nil)
+s.AddSystemFeatures()
+
+resp := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"getSystemStatus","arguments":{}}}`))
+data, err := json.Marshal(resp)
+assert.NoError(t, err)
+assert.Contains(t, string(data), `\"version\":\"2.31.2\"`)
+}
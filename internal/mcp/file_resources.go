@@ -0,0 +1,269 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/stackhistory"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// fileResourceListTTL is how long the listed file resources are reused before
+// resources/list asks Portainer again.
+const fileResourceListTTL = 30 * time.Second
+
+// fileResourceKind is a kind of file that Portainer stores and the server exposes as MCP
+// resources: one resource per object, read with the granular tool returning the file.
+type fileResourceKind struct {
+	// uriTemplate is the URI template of the resources, with an {id} variable.
+	uriTemplate string
+	name        string
+	description string
+	// tool is the granular tool returning the file, and parameter its ID parameter.
+	tool      string
+	parameter string
+	// list returns the objects holding a file, by ID, with their names.
+	list func(s *PortainerMCPServer) (map[int]string, error)
+}
+
+// uri returns the URI of the resource of an object.
+func (k fileResourceKind) uri(id int) string {
+	return strings.Replace(k.uriTemplate, "{id}", strconv.Itoa(id), 1)
+}
+
+// fileResourceKinds are the files exposed as resources. Stack URIs name the kind of the
+// stack, like the stack file history.
+var fileResourceKinds = []fileResourceKind{
+	{
+		uriTemplate: "portainer://stacks/" + stackhistory.KindEdge + "/{id}/file",
+		name:        "Edge stack file",
+		description: "Compose file of an edge stack.",
+		tool:        ToolGetStackFile,
+		parameter:   "id",
+		list: func(s *PortainerMCPServer) (map[int]string, error) {
+			stacks, err := s.cli.GetStacks(models.EdgeStackListOptions{})
+			names := map[int]string{}
+			for _, stack := range stacks {
+				names[stack.ID] = stack.Name
+			}
+			return names, err
+		},
+	},
+	{
+		uriTemplate: "portainer://stacks/" + stackhistory.KindRegular + "/{id}/file",
+		name:        "Stack file",
+		description: "Compose file of a regular (non-edge) stack.",
+		tool:        ToolInspectStackFile,
+		parameter:   "id",
+		list: func(s *PortainerMCPServer) (map[int]string, error) {
+			stacks, err := s.cli.GetRegularStacks()
+			names := map[int]string{}
+			for _, stack := range stacks {
+				names[stack.ID] = stack.Name
+			}
+			return names, err
+		},
+	},
+	{
+		uriTemplate: "portainer://custom-templates/{id}/file",
+		name:        "Custom template file",
+		description: "Compose or manifest file of a custom template.",
+		tool:        ToolGetCustomTemplateFile,
+		parameter:   "id",
+		list: func(s *PortainerMCPServer) (map[int]string, error) {
+			templates, err := s.cli.GetCustomTemplates()
+			names := map[int]string{}
+			for _, template := range templates {
+				names[template.ID] = template.Title
+			}
+			return names, err
+		},
+	},
+	{
+		uriTemplate: "portainer://edge-jobs/{id}/file",
+		name:        "Edge job script",
+		description: "Script run by an edge job on its environments.",
+		tool:        ToolGetEdgeJobFile,
+		parameter:   "id",
+		list: func(s *PortainerMCPServer) (map[int]string, error) {
+			jobs, err := s.cli.GetEdgeJobs()
+			names := map[int]string{}
+			for _, job := range jobs {
+				names[job.ID] = job.Name
+			}
+			return names, err
+		},
+	},
+	{
+		uriTemplate: "portainer://environments/{id}/kubeconfig",
+		name:        "Kubeconfig",
+		description: "Kubeconfig giving kubectl access to a Kubernetes environment through Portainer.",
+		tool:        ToolGetKubernetesConfig,
+		parameter:   "environmentId",
+		list: func(s *PortainerMCPServer) (map[int]string, error) {
+			environments, err := s.cli.GetEnvironments(models.EnvironmentListOptions{})
+			names := map[int]string{}
+			for _, environment := range environments {
+				if isKubernetesEnvironment(environment) {
+					names[environment.ID] = environment.Name
+				}
+			}
+			return names, err
+		},
+	},
+}
+
+// fileResources keeps the file resources listed in resources/list.
+type fileResources struct {
+	mu sync.Mutex
+	// enabled is set once the file resources are registered.
+	enabled bool
+	// listed is when the resources were last listed from Portainer.
+	listed time.Time
+	// uris are the URIs of the listed resources.
+	uris map[string]bool
+}
+
+// AddFileResources registers the stack compose files, custom template files, edge job
+// scripts and kubeconfigs as MCP resource templates, so that clients can read them by
+// URI, such as portainer://stacks/edge/42/file. The files of the existing objects are
+// also listed in resources/list. Only the files whose tool is available to the API token
+// are exposed.
+func (s *PortainerMCPServer) AddFileResources() {
+	for _, p := range s.principals {
+		p.server.AddFileResources()
+	}
+
+	for _, kind := range s.availableFileResourceKinds() {
+		s.srv.AddResourceTemplate(
+			mcp.NewResourceTemplate(kind.uriTemplate, kind.name,
+				mcp.WithTemplateDescription(kind.description+" Same content as the "+kind.tool+" tool."),
+			),
+			s.HandleReadFileResource(kind),
+		)
+	}
+
+	s.files.mu.Lock()
+	s.files.enabled = true
+	s.files.mu.Unlock()
+}
+
+// availableFileResourceKinds returns the kinds of files whose tool is defined and
+// available to the API token.
+func (s *PortainerMCPServer) availableFileResourceKinds() []fileResourceKind {
+	var kinds []fileResourceKind
+	for _, kind := range fileResourceKinds {
+		if _, ok := s.toolDefinition(kind.tool); ok && s.toolAllowed(kind.tool) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// HandleReadFileResource returns an MCP resource handler returning the file of the
+// object identified by the URI.
+func (s *PortainerMCPServer) HandleReadFileResource(kind fileResourceKind) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		id, err := strconv.Atoi(resourceArgument(request, "id"))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid id in %s", request.Params.URI)
+		}
+		return s.readFileResource(ctx, kind, id, request.Params.URI)
+	}
+}
+
+// readFileResource calls the tool returning the file of an object. The call goes through
+// the environment scope and redaction rules, as a tool call would.
+func (s *PortainerMCPServer) readFileResource(ctx context.Context, kind fileResourceKind, id int, uri string) ([]mcp.ResourceContents, error) {
+	newHandler, ok := readOnlyToolHandler(kind.tool)
+	if !ok {
+		return nil, fmt.Errorf("tool %s is not available", kind.tool)
+	}
+	handler := newHandler(s)
+	if s.redaction != nil {
+		handler = redactionMiddleware(s.redaction)(handler)
+	}
+	handler = s.rootsScopeMiddleware(handler)
+	if s.environmentScope != nil {
+		handler = s.environmentScopeMiddleware(handler)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = kind.tool
+	request.Params.Arguments = map[string]any{kind.parameter: float64(id)}
+	result, err := handler(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	text := resultText(result)
+	if result.IsError {
+		return nil, fmt.Errorf("failed to read %s: %s", uri, text)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{URI: uri, MIMEType: textMIMEType(kind.tool, text), Text: text},
+	}, nil
+}
+
+// listFileResources refreshes the file resources listed in resources/list before the
+// server answers it, at most once per fileResourceListTTL. A kind whose objects cannot be
+// listed keeps its previous resources.
+func (s *PortainerMCPServer) listFileResources(ctx context.Context, id any, message *mcp.ListResourcesRequest) {
+	s.files.mu.Lock()
+	defer s.files.mu.Unlock()
+	if !s.files.enabled || time.Since(s.files.listed) < fileResourceListTTL {
+		return
+	}
+	s.files.listed = time.Now()
+
+	uris := map[string]bool{}
+	var resources []server.ServerResource
+	for _, kind := range s.availableFileResourceKinds() {
+		names, err := kind.list(s)
+		if err != nil {
+			log.Warn().Err(err).Str("tool", kind.tool).Msg("failed to list the file resources")
+			prefix, _, _ := strings.Cut(kind.uriTemplate, "{id}")
+			for uri := range s.files.uris {
+				if strings.HasPrefix(uri, prefix) {
+					uris[uri] = true
+				}
+			}
+			continue
+		}
+
+		for objectID, name := range names {
+			if kind.parameter == "environmentId" && s.environmentScope != nil {
+				if denied, err := s.environmentScope.denied([]int{objectID}); err != nil || len(denied) > 0 {
+					continue
+				}
+			}
+			uri := kind.uri(objectID)
+			uris[uri] = true
+			resources = append(resources, server.ServerResource{
+				Resource: mcp.NewResource(uri, fmt.Sprintf("%s: %s", kind.name, name),
+					mcp.WithResourceDescription(kind.description),
+				),
+				Handler: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+					return s.readFileResource(ctx, kind, objectID, request.Params.URI)
+				},
+			})
+		}
+	}
+
+	for uri := range s.files.uris {
+		if !uris[uri] {
+			s.srv.RemoveResource(uri)
+		}
+	}
+	if len(resources) > 0 {
+		s.srv.AddResources(resources...)
+	}
+	s.files.uris = uris
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileResourceServer returns a server with the file resources registered.
+func newFileResourceServer(t *testing.T, cli *MockPortainerClient) *PortainerMCPServer {
+	t.Helper()
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+	s := &PortainerMCPServer{cli: cli, tools: tools}
+	hooks := &server.Hooks{}
+	hooks.AddBeforeListResources(s.listFileResources)
+	s.srv = server.NewMCPServer("Test Server", "1.0.0", server.WithHooks(hooks))
+	s.AddFileResources()
+	return s
+}
+
+// listedResourceURIs returns the URIs returned by resources/list.
+func listedResourceURIs(t *testing.T, s *PortainerMCPServer) []string {
+	t.Helper()
+	s.files.listed = time.Time{}
+	message := s.srv.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", message)
+	result, ok := response.Result.(mcp.ListResourcesResult)
+	require.True(t, ok)
+
+	uris := make([]string, 0, len(result.Resources))
+	for _, resource := range result.Resources {
+		uris = append(uris, resource.URI)
+	}
+	return uris
+}
+
+// TestHandleReadFileResource verifies that the file resources return the file of the
+// object named by the URI.
+func TestHandleReadFileResource(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackFile", 42).Return("services:\n  web:\n    image: nginx\n", nil)
+	mockClient.On("GetEdgeJobFile", 3).Return("", errors.New("edge job not found"))
+	s := newFileResourceServer(t, mockClient)
+
+	read := func(kind fileResourceKind, uri, id string) ([]mcp.ResourceContents, error) {
+		var request mcp.ReadResourceRequest
+		request.Params.URI = uri
+		request.Params.Arguments = map[string]any{"id": []string{id}}
+		return s.HandleReadFileResource(kind)(context.Background(), request)
+	}
+
+	contents, err := read(fileResourceKinds[0], "portainer://stacks/edge/42/file", "42")
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "portainer://stacks/edge/42/file", text.URI)
+	assert.Contains(t, text.Text, "image: nginx")
+
+	_, err = read(fileResourceKinds[3], "portainer://edge-jobs/3/file", "3")
+	assert.ErrorContains(t, err, "edge job not found")
+
+	_, err = read(fileResourceKinds[0], "portainer://stacks/edge/x/file", "x")
+	assert.ErrorContains(t, err, "invalid id")
+}
+
+// TestListFileResources verifies that resources/list lists the files of the existing
+// objects, and keeps the previous files of a kind that cannot be listed.
+func TestListFileResources(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStacks", mock.Anything).Return([]models.Stack{{ID: 1, Name: "web"}}, nil)
+	mockClient.On("GetRegularStacks").Return([]models.RegularStack{{ID: 2, Name: "db"}}, nil)
+	mockClient.On("GetCustomTemplates").Return([]models.CustomTemplate{{ID: 3, Title: "nginx"}}, nil)
+	mockClient.On("GetEdgeJobs").Return([]models.EdgeJob{{ID: 4, Name: "cleanup"}}, nil).Once()
+	mockClient.On("GetEdgeJobs").Return(nil, errors.New("connection refused"))
+	mockClient.On("GetEnvironments", mock.Anything).Return([]models.Environment{
+		{ID: 5, Name: "k8s", Type: models.EnvironmentTypeKubernetesAgent},
+		{ID: 6, Name: "docker", Type: models.EnvironmentTypeDockerAgent},
+	}, nil).Once()
+	mockClient.On("GetEnvironments", mock.Anything).Return([]models.Environment{}, nil)
+	s := newFileResourceServer(t, mockClient)
+
+	assert.ElementsMatch(t, []string{
+		"portainer://stacks/edge/1/file",
+		"portainer://stacks/regular/2/file",
+		"portainer://custom-templates/3/file",
+		"portainer://edge-jobs/4/file",
+		"portainer://environments/5/kubeconfig",
+	}, listedResourceURIs(t, s))
+
+	assert.ElementsMatch(t, []string{
+		"portainer://stacks/edge/1/file",
+		"portainer://stacks/regular/2/file",
+		"portainer://custom-templates/3/file",
+		"portainer://edge-jobs/4/file",
+	}, listedResourceURIs(t, s), "removed environments are no longer listed, and failed lists keep their files")
+}
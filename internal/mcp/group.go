@@ -12,6 +12,7 @@ import (
 // AddEnvironmentGroupFeatures registers the environment group management tools on the MCP server.
 func (s *PortainerMCPServer) AddEnvironmentGroupFeatures() {
 	s.addToolIfExists(ToolListEnvironmentGroups, s.HandleGetEnvironmentGroups())
+	s.addToolIfExists(ToolPreviewEnvironmentGroupMembers, s.HandlePreviewEnvironmentGroupMembers())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolCreateEnvironmentGroup, s.HandleCreateEnvironmentGroup())
@@ -0,0 +1,267 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/mod/semver"
+)
+
+// Kinds of environment groups accepted by HandleGetGroupCapacity.
+const (
+	capacityScopeAccessGroup      = "access_group"
+	capacityScopeEnvironmentGroup = "environment_group"
+	capacityScopeAll              = "all"
+)
+
+// capacityTotals is the sum of the snapshot data of the environments of a group.
+type capacityTotals struct {
+	Containers  int   `json:"containers"`
+	Running     int   `json:"running"`
+	Stopped     int   `json:"stopped"`
+	Healthy     int   `json:"healthy"`
+	Unhealthy   int   `json:"unhealthy"`
+	Images      int   `json:"images"`
+	ImagesSize  int64 `json:"images_size_bytes"`
+	Volumes     int   `json:"volumes"`
+	Stacks      int   `json:"stacks"`
+	Nodes       int   `json:"nodes"`
+	TotalCPU    int   `json:"total_cpu"`
+	TotalMemory int64 `json:"total_memory_bytes"`
+	// ImagesSizeHuman and TotalMemoryHuman are the byte totals in human-readable form.
+	ImagesSizeHuman  string `json:"images_size"`
+	TotalMemoryHuman string `json:"total_memory"`
+}
+
+// versionSpread counts the environments running each version of a component.
+type versionSpread struct {
+	Version      string `json:"version"`
+	Environments []int  `json:"environments"`
+}
+
+// versionSkew describes the versions of a component across the environments of a group.
+type versionSkew struct {
+	Versions []versionSpread `json:"versions"`
+	// Latest is the most recent version found in the group.
+	Latest string `json:"latest,omitempty"`
+	// Skewed is true when the environments do not all run the same version.
+	Skewed bool `json:"skewed"`
+	// Outdated lists the environments running an older version than Latest.
+	Outdated []int `json:"outdated,omitempty"`
+}
+
+// groupCapacityReport is the result of HandleGetGroupCapacity.
+type groupCapacityReport struct {
+	Scope     string `json:"scope"`
+	GroupID   int    `json:"group_id,omitempty"`
+	GroupName string `json:"group_name,omitempty"`
+	// Environments is the number of environments of the group, and WithSnapshot the number
+	// of them whose snapshot data is included in the totals.
+	Environments       int                          `json:"environments"`
+	WithSnapshot       int                          `json:"with_snapshot"`
+	Totals             capacityTotals               `json:"totals"`
+	AgentVersions      versionSkew                  `json:"agent_versions"`
+	DockerVersions     versionSkew                  `json:"docker_versions"`
+	KubernetesVersions versionSkew                  `json:"kubernetes_versions"`
+	Details            []models.EnvironmentSnapshot `json:"environment_details,omitempty"`
+	Notes              []string                     `json:"notes,omitempty"`
+}
+
+// HandleGetGroupCapacity returns an MCP tool handler that aggregates the latest snapshot
+// data of the environments of an access group or environment group (or of every
+// environment) into a capacity report, with the spread of agent and engine versions.
+func (s *PortainerMCPServer) HandleGetGroupCapacity() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		accessGroupId, err := parser.GetInt("accessGroupId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid accessGroupId parameter", err), nil
+		}
+		environmentGroupId, err := parser.GetInt("environmentGroupId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentGroupId parameter", err), nil
+		}
+		if accessGroupId != 0 && environmentGroupId != 0 {
+			return mcp.NewToolResultError("accessGroupId and environmentGroupId cannot be used together"), nil
+		}
+		if accessGroupId != 0 {
+			if err := validatePositiveID("accessGroupId", accessGroupId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+		if environmentGroupId != 0 {
+			if err := validatePositiveID("environmentGroupId", environmentGroupId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		includeEnvironments, err := parser.GetBoolean("includeEnvironments", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid includeEnvironments parameter", err), nil
+		}
+
+		report := groupCapacityReport{Scope: capacityScopeAll}
+		opts := models.EnvironmentListOptions{}
+		var members []int
+
+		switch {
+		case accessGroupId != 0:
+			groups, err := s.cli.GetAccessGroups()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get access groups", err), nil
+			}
+			idx := slices.IndexFunc(groups, func(g models.AccessGroup) bool { return g.ID == accessGroupId })
+			if idx < 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("access group %d not found", accessGroupId)), nil
+			}
+			report.Scope, report.GroupID, report.GroupName = capacityScopeAccessGroup, accessGroupId, groups[idx].Name
+			opts.GroupID = accessGroupId
+		case environmentGroupId != 0:
+			groups, err := s.cli.GetEnvironmentGroups()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get environment groups", err), nil
+			}
+			idx := slices.IndexFunc(groups, func(g models.Group) bool { return g.ID == environmentGroupId })
+			if idx < 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("environment group %d not found", environmentGroupId)), nil
+			}
+			report.Scope, report.GroupID, report.GroupName = capacityScopeEnvironmentGroup, environmentGroupId, groups[idx].Name
+			members = groups[idx].EnvironmentIds
+		}
+
+		snapshots, err := s.cli.GetEnvironmentSnapshots(opts)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environment snapshots", err), nil
+		}
+		allowed, err := s.roots.allowed(ctx)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to check client roots", err), nil
+		}
+		snapshots = slices.DeleteFunc(snapshots, func(snapshot models.EnvironmentSnapshot) bool {
+			if allowed != nil && !allowed[snapshot.EnvironmentID] {
+				return true
+			}
+			return report.Scope == capacityScopeEnvironmentGroup && !slices.Contains(members, snapshot.EnvironmentID)
+		})
+
+		buildGroupCapacity(&report, snapshots)
+		if includeEnvironments {
+			report.Details = snapshots
+		}
+
+		return jsonResult(report, "failed to marshal group capacity report")
+	}
+}
+
+// buildGroupCapacity fills the totals and version spreads of a capacity report from the
+// snapshots of the environments of the group.
+func buildGroupCapacity(report *groupCapacityReport, snapshots []models.EnvironmentSnapshot) {
+	report.Environments = len(snapshots)
+
+	agents := map[string][]int{}
+	dockers := map[string][]int{}
+	kubernetes := map[string][]int{}
+	var withoutSnapshot, unknownImageSize []string
+
+	for _, snapshot := range snapshots {
+		if snapshot.AgentVersion != "" {
+			agents[snapshot.AgentVersion] = append(agents[snapshot.AgentVersion], snapshot.EnvironmentID)
+		}
+		if snapshot.SnapshotTime == 0 {
+			withoutSnapshot = append(withoutSnapshot, snapshotLabel(snapshot))
+			continue
+		}
+		report.WithSnapshot++
+		if snapshot.DockerVersion != "" {
+			dockers[snapshot.DockerVersion] = append(dockers[snapshot.DockerVersion], snapshot.EnvironmentID)
+		}
+		if snapshot.KubernetesVersion != "" {
+			kubernetes[snapshot.KubernetesVersion] = append(kubernetes[snapshot.KubernetesVersion], snapshot.EnvironmentID)
+		}
+
+		t := &report.Totals
+		t.Containers += snapshot.Containers
+		t.Running += snapshot.Running
+		t.Stopped += snapshot.Stopped
+		t.Healthy += snapshot.Healthy
+		t.Unhealthy += snapshot.Unhealthy
+		t.Images += snapshot.Images
+		t.Volumes += snapshot.Volumes
+		t.Stacks += snapshot.Stacks
+		t.Nodes += snapshot.Nodes
+		t.TotalCPU += snapshot.TotalCPU
+		t.TotalMemory += snapshot.TotalMemory
+		switch {
+		case snapshot.ImagesSize >= 0:
+			t.ImagesSize += snapshot.ImagesSize
+		case snapshot.DockerVersion != "":
+			unknownImageSize = append(unknownImageSize, snapshotLabel(snapshot))
+		}
+	}
+	report.Totals.ImagesSizeHuman = units.HumanSize(float64(report.Totals.ImagesSize))
+	report.Totals.TotalMemoryHuman = units.BytesSize(float64(report.Totals.TotalMemory))
+
+	report.AgentVersions = buildVersionSkew(agents)
+	report.DockerVersions = buildVersionSkew(dockers)
+	report.KubernetesVersions = buildVersionSkew(kubernetes)
+
+	if len(withoutSnapshot) > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("no snapshot for %s: not included in the totals", strings.Join(withoutSnapshot, ", ")))
+	}
+	if len(unknownImageSize) > 0 {
+		report.Notes = append(report.Notes, fmt.Sprintf("image sizes missing from the snapshot of %s", strings.Join(unknownImageSize, ", ")))
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.SnapshotTime > 0 && time.Since(time.Unix(snapshot.SnapshotTime, 0)) > 24*time.Hour {
+			report.Notes = append(report.Notes, fmt.Sprintf("snapshot of %s is older than a day (%s)", snapshotLabel(snapshot), time.Unix(snapshot.SnapshotTime, 0).UTC().Format(time.RFC3339)))
+		}
+	}
+}
+
+// snapshotLabel identifies an environment in the notes of a capacity report.
+func snapshotLabel(snapshot models.EnvironmentSnapshot) string {
+	return fmt.Sprintf("environment %d (%s)", snapshot.EnvironmentID, snapshot.Name)
+}
+
+// buildVersionSkew returns the spread of versions, sorted from the most recent, given the
+// environments running each version. Versions that are not semantic versions are sorted
+// last and are never reported as the latest one.
+func buildVersionSkew(environments map[string][]int) versionSkew {
+	skew := versionSkew{Versions: []versionSpread{}, Skewed: len(environments) > 1}
+	for version, ids := range environments {
+		sort.Ints(ids)
+		skew.Versions = append(skew.Versions, versionSpread{Version: version, Environments: ids})
+	}
+	sort.Slice(skew.Versions, func(i, j int) bool {
+		a, b := canonicalVersion(skew.Versions[i].Version), canonicalVersion(skew.Versions[j].Version)
+		if c := semver.Compare(a, b); c != 0 {
+			return c > 0
+		}
+		return skew.Versions[i].Version < skew.Versions[j].Version
+	})
+
+	if len(skew.Versions) == 0 || !semver.IsValid(canonicalVersion(skew.Versions[0].Version)) {
+		return skew
+	}
+	skew.Latest = skew.Versions[0].Version
+	for _, spread := range skew.Versions[1:] {
+		skew.Outdated = append(skew.Outdated, spread.Environments...)
+	}
+	sort.Ints(skew.Outdated)
+	return skew
+}
+
+// canonicalVersion returns a version with the "v" prefix expected by the semver package.
+func canonicalVersion(version string) string {
+	return "v" + strings.TrimPrefix(version, "v")
+}
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleGetGroupCapacity verifies the totals and version spreads reported by the
+// getGroupCapacity tool.
+func TestHandleGetGroupCapacity(t *testing.T) {
+	now := time.Now().Unix()
+	snapshots := []models.EnvironmentSnapshot{
+		{EnvironmentID: 1, Name: "web", AgentVersion: "2.19.4", SnapshotTime: now, DockerVersion: "25.0.3", Containers: 5, Running: 4, Stopped: 1, Healthy: 2, Images: 3, ImagesSize: 1000, Volumes: 2, Stacks: 1, Nodes: 1, TotalCPU: 4, TotalMemory: 2048},
+		{EnvironmentID: 2, Name: "db", AgentVersion: "2.21.0", SnapshotTime: now, DockerVersion: "25.0.3", Containers: 2, Running: 1, Stopped: 1, Unhealthy: 1, Images: 1, ImagesSize: -1, Volumes: 1, Nodes: 1, TotalCPU: 2, TotalMemory: 1024},
+		{EnvironmentID: 3, Name: "cache", AgentVersion: "2.19.4", SnapshotTime: now - 2*86400, DockerVersion: "24.0.7", Containers: 1, Running: 1, Images: 1, ImagesSize: 500, Nodes: 1, TotalCPU: 2, TotalMemory: 1024},
+		{EnvironmentID: 4, Name: "new", AgentVersion: "2.21.0", ImagesSize: -1},
+	}
+
+	t.Run("access group", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetAccessGroups").Return([]models.AccessGroup{{ID: 1, Name: "Unassigned"}, {ID: 2, Name: "production"}}, nil)
+		mockClient.On("GetEnvironmentSnapshots", models.EnvironmentListOptions{GroupID: 2}).Return(snapshots, nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleGetGroupCapacity()(context.Background(), CreateMCPRequest(map[string]any{"accessGroupId": float64(2)}))
+		require.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		require.False(t, result.IsError, text)
+
+		var report groupCapacityReport
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.Equal(t, capacityScopeAccessGroup, report.Scope)
+		assert.Equal(t, "production", report.GroupName)
+		assert.Equal(t, 4, report.Environments)
+		assert.Equal(t, 3, report.WithSnapshot)
+		assert.Equal(t, capacityTotals{
+			Containers: 8, Running: 6, Stopped: 2, Healthy: 2, Unhealthy: 1, Images: 5, ImagesSize: 1500,
+			Volumes: 3, Stacks: 1, Nodes: 3, TotalCPU: 8, TotalMemory: 4096,
+			ImagesSizeHuman: "1.5kB", TotalMemoryHuman: "4KiB",
+		}, report.Totals)
+
+		assert.Equal(t, versionSkew{
+			Versions: []versionSpread{{Version: "2.21.0", Environments: []int{2, 4}}, {Version: "2.19.4", Environments: []int{1, 3}}},
+			Latest:   "2.21.0",
+			Skewed:   true,
+			Outdated: []int{1, 3},
+		}, report.AgentVersions)
+		assert.Equal(t, "25.0.3", report.DockerVersions.Latest)
+		assert.Equal(t, []int{3}, report.DockerVersions.Outdated)
+		assert.False(t, report.KubernetesVersions.Skewed)
+		assert.Empty(t, report.KubernetesVersions.Versions)
+		assert.Nil(t, report.Details)
+
+		require.Len(t, report.Notes, 3)
+		assert.Contains(t, report.Notes[0], "environment 4 (new)")
+		assert.Contains(t, report.Notes[1], "environment 2 (db)")
+		assert.Contains(t, report.Notes[2], "environment 3 (cache) is older than a day")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("environment group with details", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetEnvironmentGroups").Return([]models.Group{{ID: 7, Name: "edge", EnvironmentIds: []int{1, 2}}}, nil)
+		mockClient.On("GetEnvironmentSnapshots", models.EnvironmentListOptions{}).Return(append([]models.EnvironmentSnapshot(nil), snapshots...), nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleGetGroupCapacity()(context.Background(), CreateMCPRequest(map[string]any{"environmentGroupId": float64(7), "includeEnvironments": true}))
+		require.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		require.False(t, result.IsError, text)
+
+		var report groupCapacityReport
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.Equal(t, capacityScopeEnvironmentGroup, report.Scope)
+		assert.Equal(t, 2, report.Environments)
+		assert.Equal(t, 7, report.Totals.Containers)
+		require.Len(t, report.Details, 2)
+		assert.Equal(t, "db", report.Details[1].Name)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("version ordering", func(t *testing.T) {
+		skew := buildVersionSkew(map[string][]int{"2.21.0": {3, 1}})
+		assert.Equal(t, versionSkew{Versions: []versionSpread{{Version: "2.21.0", Environments: []int{1, 3}}}, Latest: "2.21.0"}, skew)
+
+		skew = buildVersionSkew(map[string][]int{"dev": {2}, "v1.30.1": {1}})
+		assert.Equal(t, "v1.30.1", skew.Latest)
+		assert.Equal(t, []int{2}, skew.Outdated)
+	})
+
+	errorTests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+	}{
+		{name: "both groups", args: map[string]any{"accessGroupId": float64(1), "environmentGroupId": float64(2)}, expectError: "cannot be used together"},
+		{name: "invalid accessGroupId", args: map[string]any{"accessGroupId": float64(-1)}, expectError: "accessGroupId"},
+		{
+			name: "unknown access group",
+			args: map[string]any{"accessGroupId": float64(9)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetAccessGroups").Return([]models.AccessGroup{{ID: 1}}, nil)
+			},
+			expectError: "access group 9 not found",
+		},
+		{
+			name: "environment groups error",
+			args: map[string]any{"environmentGroupId": float64(7)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentGroups").Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get environment groups",
+		},
+		{
+			name: "snapshots error",
+			args: map[string]any{},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentSnapshots", models.EnvironmentListOptions{}).Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get environment snapshots",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetGroupCapacity()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
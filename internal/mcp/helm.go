@@ -2,6 +2,8 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -14,6 +16,7 @@ func (s *PortainerMCPServer) AddHelmFeatures() {
 	s.addToolIfExists(ToolSearchHelmCharts, s.HandleSearchHelmCharts())
 	s.addToolIfExists(ToolListHelmReleases, s.HandleListHelmReleases())
 	s.addToolIfExists(ToolGetHelmReleaseHistory, s.HandleGetHelmReleaseHistory())
+	s.addToolIfExists(ToolGetHelmReleaseStatus, s.HandleGetHelmReleaseStatus())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolAddHelmRepository, s.HandleAddHelmRepository())
@@ -23,6 +26,20 @@ func (s *PortainerMCPServer) AddHelmFeatures() {
 	}
 }
 
+// validateHelmRepositoryURL checks a Helm repository URL. Credentials in the URL are
+// rejected: the Portainer API has no credential fields for Helm repositories, so they would
+// be stored, listed and sent to Portainer in clear.
+func validateHelmRepositoryURL(rawURL string) error {
+	if err := validateURL(rawURL); err != nil {
+		return err
+	}
+	u, _ := url.Parse(rawURL)
+	if u.User != nil {
+		return fmt.Errorf("the URL contains credentials, which Portainer would store and list in clear: the Portainer API (up to %s) does not support authenticated Helm repositories", SupportedPortainerVersion)
+	}
+	return nil
+}
+
 // HandleListHelmRepositories returns an MCP tool handler that lists helm repositories.
 func (s *PortainerMCPServer) HandleListHelmRepositories() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -63,7 +80,7 @@ func (s *PortainerMCPServer) HandleAddHelmRepository() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid url parameter", err), nil
 		}
 
-		if err := validateURL(url); err != nil {
+		if err := validateHelmRepositoryURL(url); err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid repository URL", err), nil
 		}
 
@@ -116,7 +133,7 @@ func (s *PortainerMCPServer) HandleSearchHelmCharts() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid repo parameter", err), nil
 		}
 
-		if err := validateURL(repo); err != nil {
+		if err := validateHelmRepositoryURL(repo); err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid repository URL", err), nil
 		}
 
@@ -162,7 +179,7 @@ func (s *PortainerMCPServer) HandleInstallHelmChart() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid repo parameter", err), nil
 		}
 
-		if err := validateURL(repo); err != nil {
+		if err := validateHelmRepositoryURL(repo); err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid repository URL", err), nil
 		}
 
@@ -223,7 +240,7 @@ func (s *PortainerMCPServer) HandleListHelmReleases() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("failed to list helm releases", err), nil
 		}
 
-		return jsonResult(releases, "failed to marshal helm releases")
+		return listResult(request, releases, "failed to marshal helm releases")
 	}
 }
 
@@ -290,3 +307,42 @@ func (s *PortainerMCPServer) HandleGetHelmReleaseHistory() server.ToolHandlerFun
 		return jsonResult(history, "failed to marshal helm release history")
 	}
 }
+
+// HandleGetHelmReleaseStatus returns an MCP tool handler that summarizes the health of a helm
+// release from its last deployment and the readiness of the workloads it deployed.
+func (s *PortainerMCPServer) HandleGetHelmReleaseStatus() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		namespace, err := parser.GetString("namespace", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+
+		release, err := s.cli.GetHelmRelease(environmentId, name, namespace)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get helm release", err), nil
+		}
+		if release.Namespace == "" {
+			release.Namespace = namespace
+		}
+		if release.Namespace == "" {
+			release.Namespace = "default"
+		}
+
+		return jsonResult(s.helmReleaseStatus(environmentId, release), "failed to marshal helm release status")
+	}
+}
@@ -0,0 +1,272 @@
+package mcp
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+)
+
+// helmReleaseNameAnnotation is the annotation that Helm sets on every resource it deploys
+// with the name of the owning release.
+const helmReleaseNameAnnotation = "meta.helm.sh/release-name"
+
+// helmReleaseNamespaceAnnotation is the annotation that Helm sets on every resource it
+// deploys with the namespace of the owning release.
+const helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+
+// helmDeployedStatus is the Helm status of a release whose last deployment succeeded.
+const helmDeployedStatus = "deployed"
+
+// helmWorkloadKinds are the workload kinds whose health is checked, with the Kubernetes
+// API path of their collection in a namespace.
+var helmWorkloadKinds = []struct {
+	kind string
+	path string
+}{
+	{kind: "Deployment", path: "/apis/apps/v1/namespaces/%s/deployments"},
+	{kind: "StatefulSet", path: "/apis/apps/v1/namespaces/%s/statefulsets"},
+	{kind: "DaemonSet", path: "/apis/apps/v1/namespaces/%s/daemonsets"},
+}
+
+// helmReleaseReport summarizes the health of a Helm release and of the workloads it deployed.
+type helmReleaseReport struct {
+	Release        models.HelmReleaseInfo `json:"release"`
+	Healthy        bool                   `json:"healthy"`
+	Summary        string                 `json:"summary"`
+	Workloads      []helmWorkloadHealth   `json:"workloads"`
+	UnhealthyPods  []helmPodHealth        `json:"unhealthyPods,omitempty"`
+	ResourceErrors []string               `json:"resourceErrors,omitempty"`
+}
+
+// helmWorkloadHealth is the replica health of a workload deployed by a Helm release.
+type helmWorkloadHealth struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Desired  int    `json:"desired"`
+	Ready    int    `json:"ready"`
+	Updated  int    `json:"updated"`
+	Restarts int    `json:"restarts"`
+	Healthy  bool   `json:"healthy"`
+}
+
+// helmPodHealth describes a pod of a Helm release that is not running and ready.
+type helmPodHealth struct {
+	Name     string `json:"name"`
+	Workload string `json:"workload"`
+	Phase    string `json:"phase"`
+	Reason   string `json:"reason,omitempty"`
+	Restarts int    `json:"restarts"`
+}
+
+// k8sObjectMeta holds the metadata fields used to match resources to a release.
+type k8sObjectMeta struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// k8sWorkload holds the fields shared by deployments, statefulsets and daemonsets that
+// describe their replica health.
+type k8sWorkload struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Spec     struct {
+		Replicas *int `json:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `json:"matchLabels"`
+		} `json:"selector"`
+	} `json:"spec"`
+	Status struct {
+		ReadyReplicas          int `json:"readyReplicas"`
+		UpdatedReplicas        int `json:"updatedReplicas"`
+		DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+		NumberReady            int `json:"numberReady"`
+		UpdatedNumberScheduled int `json:"updatedNumberScheduled"`
+	} `json:"status"`
+}
+
+// k8sContainerState holds the reason of a waiting or terminated container.
+type k8sContainerState struct {
+	Waiting *struct {
+		Reason string `json:"reason"`
+	} `json:"waiting"`
+	Terminated *struct {
+		Reason string `json:"reason"`
+	} `json:"terminated"`
+}
+
+// k8sPod holds the pod fields used to assess its health.
+type k8sPod struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Status   struct {
+		Phase             string `json:"phase"`
+		Reason            string `json:"reason"`
+		ContainerStatuses []struct {
+			Ready        bool              `json:"ready"`
+			RestartCount int               `json:"restartCount"`
+			State        k8sContainerState `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// helmReleaseStatus checks the workloads deployed by a release and their pods, and
+// summarizes the health of the release. Resources that cannot be listed are reported as
+// errors and make the release unhealthy, since its health cannot be confirmed.
+func (s *PortainerMCPServer) helmReleaseStatus(environmentId int, release models.HelmReleaseInfo) helmReleaseReport {
+	status := helmReleaseReport{Release: release, Workloads: []helmWorkloadHealth{}}
+	namespace := url.PathEscape(release.Namespace)
+
+	var pods []k8sPod
+	if err := s.getKubernetesList(environmentId, fmt.Sprintf("/api/v1/namespaces/%s/pods", namespace), nil, &pods); err != nil {
+		status.ResourceErrors = append(status.ResourceErrors, fmt.Sprintf("failed to list pods: %v", err))
+	}
+
+	for _, workloadKind := range helmWorkloadKinds {
+		var workloads []k8sWorkload
+		if err := s.getKubernetesList(environmentId, fmt.Sprintf(workloadKind.path, namespace), nil, &workloads); err != nil {
+			status.ResourceErrors = append(status.ResourceErrors, fmt.Sprintf("failed to list %ss: %v", strings.ToLower(workloadKind.kind), err))
+			continue
+		}
+
+		for _, workload := range workloads {
+			if !ownedByRelease(workload.Metadata, release) {
+				continue
+			}
+			health := workloadHealth(workloadKind.kind, workload)
+
+			for _, pod := range pods {
+				if !matchesLabels(pod.Metadata.Labels, workload.Spec.Selector.MatchLabels) {
+					continue
+				}
+				podHealth, healthy := assessPod(pod)
+				health.Restarts += podHealth.Restarts
+				if !healthy {
+					podHealth.Workload = workloadKind.kind + "/" + workload.Metadata.Name
+					status.UnhealthyPods = append(status.UnhealthyPods, podHealth)
+				}
+			}
+			status.Workloads = append(status.Workloads, health)
+		}
+	}
+
+	sort.Slice(status.Workloads, func(i, j int) bool {
+		if status.Workloads[i].Kind != status.Workloads[j].Kind {
+			return status.Workloads[i].Kind < status.Workloads[j].Kind
+		}
+		return status.Workloads[i].Name < status.Workloads[j].Name
+	})
+
+	status.Healthy, status.Summary = summarizeHelmRelease(status)
+	return status
+}
+
+// ownedByRelease reports whether a resource was deployed by the given release. Resources
+// without a release namespace annotation were deployed by Helm 3 versions that did not set
+// it and are matched on the release name only.
+func ownedByRelease(meta k8sObjectMeta, release models.HelmReleaseInfo) bool {
+	if meta.Annotations[helmReleaseNameAnnotation] != release.Name {
+		return false
+	}
+	namespace, ok := meta.Annotations[helmReleaseNamespaceAnnotation]
+	return !ok || namespace == release.Namespace
+}
+
+// matchesLabels reports whether labels contain every selector label. An empty selector
+// matches nothing, so that pods are never attributed to a workload without a selector.
+func matchesLabels(labels, selector map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// workloadHealth compares the ready and updated replicas of a workload with the desired
+// replicas.
+func workloadHealth(kind string, workload k8sWorkload) helmWorkloadHealth {
+	health := helmWorkloadHealth{Kind: kind, Name: workload.Metadata.Name}
+
+	if kind == "DaemonSet" {
+		health.Desired = workload.Status.DesiredNumberScheduled
+		health.Ready = workload.Status.NumberReady
+		health.Updated = workload.Status.UpdatedNumberScheduled
+	} else {
+		health.Desired = 1
+		if workload.Spec.Replicas != nil {
+			health.Desired = *workload.Spec.Replicas
+		}
+		health.Ready = workload.Status.ReadyReplicas
+		health.Updated = workload.Status.UpdatedReplicas
+	}
+
+	health.Healthy = health.Ready >= health.Desired && health.Updated >= health.Desired
+	return health
+}
+
+// assessPod reports whether a pod is running (or completed) with all its containers ready,
+// and describes it with the most specific reason available when it is not.
+func assessPod(pod k8sPod) (helmPodHealth, bool) {
+	health := helmPodHealth{Name: pod.Metadata.Name, Phase: pod.Status.Phase, Reason: pod.Status.Reason}
+
+	allReady := true
+	for _, container := range pod.Status.ContainerStatuses {
+		health.Restarts += container.RestartCount
+		if container.Ready {
+			continue
+		}
+		allReady = false
+		if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+			health.Reason = container.State.Waiting.Reason
+		} else if container.State.Terminated != nil && container.State.Terminated.Reason != "" && health.Reason == "" {
+			health.Reason = container.State.Terminated.Reason
+		}
+	}
+
+	switch pod.Status.Phase {
+	case "Succeeded":
+		return health, true
+	case "Running":
+		if allReady {
+			return health, true
+		}
+		if health.Reason == "" {
+			health.Reason = "ContainersNotReady"
+		}
+	}
+	return health, false
+}
+
+// summarizeHelmRelease returns whether a release is healthy and a one-line explanation.
+func summarizeHelmRelease(status helmReleaseReport) (bool, string) {
+	var problems []string
+	if status.Release.Status != helmDeployedStatus {
+		problems = append(problems, fmt.Sprintf("release status is %q", status.Release.Status))
+	}
+
+	unhealthy := 0
+	for _, workload := range status.Workloads {
+		if !workload.Healthy {
+			unhealthy++
+		}
+	}
+	if unhealthy > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d workloads are not fully ready", unhealthy, len(status.Workloads)))
+	}
+	if len(status.UnhealthyPods) > 0 {
+		problems = append(problems, fmt.Sprintf("%d pods are not running and ready", len(status.UnhealthyPods)))
+	}
+	if len(status.ResourceErrors) > 0 {
+		problems = append(problems, "the health of some resources could not be checked")
+	}
+
+	if len(problems) > 0 {
+		return false, "Release is not healthy: " + strings.Join(problems, "; ")
+	}
+	return true, fmt.Sprintf("Release is healthy: revision %d is deployed and all %d workloads are ready", status.Release.Revision, len(status.Workloads))
+}
@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockKubernetesList registers a proxied Kubernetes GET request that returns the given
+// status and body.
+func mockKubernetesList(m *MockPortainerClient, path string, status int, body string) {
+	m.On("ProxyKubernetesRequest", mock.MatchedBy(func(opts models.KubernetesProxyRequestOptions) bool {
+		return opts.Method == "GET" && opts.Path == path
+	})).Return(&http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}, nil).Once()
+}
+
+// TestHandleGetHelmReleaseStatus verifies that the release information is combined with
+// the health of the workloads and pods deployed by the release.
+func TestHandleGetHelmReleaseStatus(t *testing.T) {
+	release := models.HelmReleaseInfo{Name: "web", Namespace: "apps", Revision: 3, Status: "deployed", Notes: "Visit http://web.local"}
+
+	deployments := `{"items":[
+		{"metadata":{"name":"web","annotations":{"meta.helm.sh/release-name":"web","meta.helm.sh/release-namespace":"apps"}},
+		 "spec":{"replicas":2,"selector":{"matchLabels":{"app":"web"}}},
+		 "status":{"readyReplicas":1,"updatedReplicas":2}},
+		{"metadata":{"name":"other","annotations":{"meta.helm.sh/release-name":"other"}},
+		 "spec":{"replicas":1,"selector":{"matchLabels":{"app":"other"}}},
+		 "status":{"readyReplicas":0,"updatedReplicas":0}}
+	]}`
+	statefulsets := `{"items":[
+		{"metadata":{"name":"web-db","annotations":{"meta.helm.sh/release-name":"web"}},
+		 "spec":{"replicas":1,"selector":{"matchLabels":{"app":"web-db"}}},
+		 "status":{"readyReplicas":1,"updatedReplicas":1}}
+	]}`
+	pods := `{"items":[
+		{"metadata":{"name":"web-1","labels":{"app":"web"}},
+		 "status":{"phase":"Running","containerStatuses":[{"ready":true,"restartCount":1}]}},
+		{"metadata":{"name":"web-2","labels":{"app":"web"}},
+		 "status":{"phase":"Running","containerStatuses":[{"ready":false,"restartCount":4,"state":{"waiting":{"reason":"CrashLoopBackOff"}}}]}},
+		{"metadata":{"name":"web-db-0","labels":{"app":"web-db"}},
+		 "status":{"phase":"Running","containerStatuses":[{"ready":true}]}},
+		{"metadata":{"name":"other-1","labels":{"app":"other"}},
+		 "status":{"phase":"Pending"}}
+	]}`
+
+	t.Run("unhealthy release", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetHelmRelease", 1, "web", "apps").Return(release, nil)
+		mockKubernetesList(mockClient, "/api/v1/namespaces/apps/pods", http.StatusOK, pods)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/apps/deployments", http.StatusOK, deployments)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/apps/statefulsets", http.StatusOK, statefulsets)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/apps/daemonsets", http.StatusOK, `{"items":[]}`)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "name": "web", "namespace": "apps"})
+		result, err := server.HandleGetHelmReleaseStatus()(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		var report helmReleaseReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Equal(t, release, report.Release)
+		assert.False(t, report.Healthy)
+		assert.Contains(t, report.Summary, "1 of 2 workloads are not fully ready")
+		assert.Equal(t, []helmWorkloadHealth{
+			{Kind: "Deployment", Name: "web", Desired: 2, Ready: 1, Updated: 2, Restarts: 5},
+			{Kind: "StatefulSet", Name: "web-db", Desired: 1, Ready: 1, Updated: 1, Healthy: true},
+		}, report.Workloads)
+		assert.Equal(t, []helmPodHealth{
+			{Name: "web-2", Workload: "Deployment/web", Phase: "Running", Reason: "CrashLoopBackOff", Restarts: 4},
+		}, report.UnhealthyPods)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("resource errors", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetHelmRelease", 1, "web", "").Return(models.HelmReleaseInfo{Name: "web", Revision: 1, Status: "deployed"}, nil)
+		mockKubernetesList(mockClient, "/api/v1/namespaces/default/pods", http.StatusForbidden, "forbidden")
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/default/deployments", http.StatusOK, `{"items":[]}`)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/default/statefulsets", http.StatusOK, `{"items":[]}`)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/default/daemonsets", http.StatusOK, `{"items":[]}`)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "name": "web"})
+		result, err := server.HandleGetHelmReleaseStatus()(context.Background(), request)
+		require.NoError(t, err)
+
+		var report helmReleaseReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Equal(t, "default", report.Release.Namespace)
+		assert.False(t, report.Healthy)
+		assert.Equal(t, []string{"failed to list pods: unexpected status 403: forbidden"}, report.ResourceErrors)
+	})
+
+	t.Run("release error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetHelmRelease", 1, "missing", "").Return(models.HelmReleaseInfo{}, errors.New("release not found"))
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "name": "missing"})
+		result, err := server.HandleGetHelmReleaseStatus()(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		mockClient.AssertNotCalled(t, "ProxyKubernetesRequest", mock.Anything)
+	})
+
+	t.Run("invalid environmentId", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(0), "name": "web"})
+		result, err := server.HandleGetHelmReleaseStatus()(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+// TestAssessPod verifies the health and reason reported for pods.
+func TestAssessPod(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     string
+		healthy bool
+		reason  string
+	}{
+		{name: "running and ready", pod: `{"status":{"phase":"Running","containerStatuses":[{"ready":true}]}}`, healthy: true},
+		{name: "completed", pod: `{"status":{"phase":"Succeeded","containerStatuses":[{"ready":false,"state":{"terminated":{"reason":"Completed"}}}]}}`, healthy: true},
+		{name: "image pull", pod: `{"status":{"phase":"Pending","containerStatuses":[{"ready":false,"state":{"waiting":{"reason":"ImagePullBackOff"}}}]}}`, reason: "ImagePullBackOff"},
+		{name: "not ready without reason", pod: `{"status":{"phase":"Running","containerStatuses":[{"ready":false}]}}`, reason: "ContainersNotReady"},
+		{name: "evicted", pod: `{"status":{"phase":"Failed","reason":"Evicted"}}`, reason: "Evicted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pod k8sPod
+			require.NoError(t, json.Unmarshal([]byte(tt.pod), &pod))
+			health, healthy := assessPod(pod)
+			assert.Equal(t, tt.healthy, healthy)
+			if !tt.healthy {
+				assert.Equal(t, tt.reason, health.Reason)
+			}
+		})
+	}
+}
+
+// TestOwnedByRelease verifies the matching of resources to a release through the Helm annotations.
+func TestOwnedByRelease(t *testing.T) {
+	release := models.HelmReleaseInfo{Name: "web", Namespace: "apps"}
+
+	assert.True(t, ownedByRelease(k8sObjectMeta{Annotations: map[string]string{helmReleaseNameAnnotation: "web", helmReleaseNamespaceAnnotation: "apps"}}, release))
+	assert.True(t, ownedByRelease(k8sObjectMeta{Annotations: map[string]string{helmReleaseNameAnnotation: "web"}}, release))
+	assert.False(t, ownedByRelease(k8sObjectMeta{Annotations: map[string]string{helmReleaseNameAnnotation: "web", helmReleaseNamespaceAnnotation: "other"}}, release))
+	assert.False(t, ownedByRelease(k8sObjectMeta{Labels: map[string]string{"app.kubernetes.io/instance": "web"}}, release))
+}
+
+// TestSummarizeHelmRelease verifies the health verdict of a release.
+func TestSummarizeHelmRelease(t *testing.T) {
+	healthy, summary := summarizeHelmRelease(helmReleaseReport{
+		Release:   models.HelmReleaseInfo{Revision: 4, Status: "deployed"},
+		Workloads: []helmWorkloadHealth{{Healthy: true}},
+	})
+	assert.True(t, healthy)
+	assert.Equal(t, "Release is healthy: revision 4 is deployed and all 1 workloads are ready", summary)
+
+	healthy, summary = summarizeHelmRelease(helmReleaseReport{Release: models.HelmReleaseInfo{Status: "failed"}})
+	assert.False(t, healthy)
+	assert.Equal(t, `Release is not healthy: release status is "failed"`, summary)
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// paramIdempotencyKey is the optional parameter of the create tools naming a call, so
+	// that a retried call returns the result of the first one instead of creating a
+	// duplicate.
+	paramIdempotencyKey = "idempotencyKey"
+	// metaIdempotentReplay is set in the _meta of results returned for a known key.
+	metaIdempotentReplay = "idempotentReplay"
+	// idempotencyKeyTTL is how long the result of a call is remembered.
+	idempotencyKeyTTL = time.Hour
+	// maxIdempotencyKeys bounds the number of remembered keys; the oldest are forgotten first.
+	maxIdempotencyKeys = 1000
+)
+
+// idempotencyStore remembers the results of the create calls that carried an
+// idempotency key. Keys are scoped to the client session and the tool.
+type idempotencyStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entries map[string]*idempotencyEntry
+}
+
+// idempotencyEntry is a call made with an idempotency key.
+type idempotencyEntry struct {
+	// fingerprint is a hash of the arguments of the call, without the key.
+	fingerprint [sha256.Size]byte
+	// done is closed once the call has completed.
+	done    chan struct{}
+	result  *mcp.CallToolResult
+	expires time.Time
+}
+
+// newIdempotencyStore creates an empty store.
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{now: time.Now, entries: map[string]*idempotencyEntry{}}
+}
+
+// begin returns the entry of a key, and whether the caller created it and must run the
+// call and then complete it. An entry whose call failed or expired is replaced.
+func (st *idempotencyStore) begin(key string, fingerprint [sha256.Size]byte) (*idempotencyEntry, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := st.now()
+	if entry, ok := st.entries[key]; ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		return entry, false
+	}
+
+	st.prune(now)
+	entry := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	st.entries[key] = entry
+	return entry, true
+}
+
+// complete records the result of a call. Failed calls are forgotten, so that a retry
+// with the same key runs again.
+func (st *idempotencyStore) complete(key string, entry *idempotencyEntry, result *mcp.CallToolResult, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if err != nil || result == nil || result.IsError {
+		delete(st.entries, key)
+	} else {
+		// The outer middlewares rewrite the returned result, so a copy is remembered.
+		entry.result = cloneResult(result)
+		entry.expires = st.now().Add(idempotencyKeyTTL)
+	}
+	close(entry.done)
+}
+
+// prune forgets the expired keys and, when the store is full, the keys that expire first.
+// Keys of calls still running are kept. The caller must hold the lock.
+func (st *idempotencyStore) prune(now time.Time) {
+	completed := make([]string, 0, len(st.entries))
+	for key, entry := range st.entries {
+		switch {
+		case entry.expires.IsZero():
+		case !now.Before(entry.expires):
+			delete(st.entries, key)
+		default:
+			completed = append(completed, key)
+		}
+	}
+
+	excess := len(st.entries) - maxIdempotencyKeys + 1
+	if excess <= 0 {
+		return
+	}
+	slices.SortFunc(completed, func(a, b string) int {
+		return st.entries[a].expires.Compare(st.entries[b].expires)
+	})
+	for _, key := range completed[:min(excess, len(completed))] {
+		delete(st.entries, key)
+	}
+}
+
+// idempotencyMiddleware returns the original result when a create call is retried with
+// the idempotency key of an earlier call of the same session, instead of creating the
+// resource again. A retry arriving while the first call still runs waits for its result.
+// Reusing a key with different arguments is rejected, and failed calls are not
+// remembered so that they can be retried.
+func (s *PortainerMCPServer) idempotencyMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name := requestToolName(request)
+		key, ok := request.GetArguments()[paramIdempotencyKey].(string)
+		if !ok || key == "" || !s.acceptsIdempotencyKey(name) {
+			return next(ctx, request)
+		}
+
+		fingerprint, err := argumentsFingerprint(request.GetArguments(), name != request.Params.Name)
+		if err != nil {
+			return next(ctx, request)
+		}
+		storeKey := sessionID(ctx) + "\x00" + name + "\x00" + key
+		entry, created := s.idempotency.begin(storeKey, fingerprint)
+		if created {
+			result, err := next(ctx, request)
+			s.idempotency.complete(storeKey, entry, result, err)
+			return result, err
+		}
+
+		if entry.fingerprint != fingerprint {
+			return newToolResultErrorWithCode(ErrorCodeValidation, fmt.Sprintf(
+				"idempotency key %q was already used by a %s call with different arguments: use a new key for a different call", key, name,
+			)), nil
+		}
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.result == nil {
+			// The first call failed and was forgotten: this call runs it again.
+			return s.idempotencyMiddleware(next)(ctx, request)
+		}
+		markCacheHit(ctx)
+		return replayedResult(entry.result), nil
+	}
+}
+
+// acceptsIdempotencyKey reports whether a granular tool declares the idempotency key
+// parameter.
+func (s *PortainerMCPServer) acceptsIdempotencyKey(name string) bool {
+	tool, ok := s.toolDefinition(name)
+	if !ok {
+		return false
+	}
+	_, ok = tool.InputSchema.Properties[paramIdempotencyKey]
+	return ok
+}
+
+// argumentsFingerprint hashes the arguments of a call, without the idempotency key and,
+// for a meta-tool call, the action, so that a retry may go through either tool. Maps are
+// encoded with sorted keys, so the hash does not depend on their order.
+func argumentsFingerprint(args map[string]any, metaTool bool) ([sha256.Size]byte, error) {
+	args = maps.Clone(args)
+	delete(args, paramIdempotencyKey)
+	if metaTool {
+		delete(args, "action")
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// replayedResult returns a copy of a remembered result flagged as a replay.
+func replayedResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	replay := cloneResult(result)
+	setResultMeta(replay, metaIdempotentReplay, true)
+	return replay
+}
+
+// cloneResult returns a copy of a result with its own content and _meta.
+func cloneResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	clone := *result
+	clone.Content = slices.Clone(result.Content)
+	if result.Meta != nil {
+		clone.Meta = &mcp.Meta{ProgressToken: result.Meta.ProgressToken, AdditionalFields: maps.Clone(result.Meta.AdditionalFields)}
+	}
+	return &clone
+}
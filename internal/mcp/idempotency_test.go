@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotencyTestServer returns a server whose createEnvironmentTag tool accepts an
+// idempotency key, and whose deleteEnvironmentTag tool does not.
+func idempotencyTestServer() *PortainerMCPServer {
+	return &PortainerMCPServer{
+		tools: map[string]mcp.Tool{
+			ToolCreateEnvironmentTag: mcp.NewTool(ToolCreateEnvironmentTag, mcp.WithString("name"), mcp.WithString(paramIdempotencyKey)),
+			ToolDeleteEnvironmentTag: mcp.NewTool(ToolDeleteEnvironmentTag, mcp.WithNumber("id")),
+		},
+		idempotency: newIdempotencyStore(),
+	}
+}
+
+// countingHandler returns a handler counting its calls and returning the call number.
+func countingHandler(calls *atomic.Int32) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(fmt.Sprintf("created %d", calls.Add(1))), nil
+	}
+}
+
+// callIdempotent calls a tool through the idempotency middleware.
+func callIdempotent(t *testing.T, s *PortainerMCPServer, next func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), tool string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	request := CreateMCPRequest(args)
+	request.Params.Name = tool
+	result, err := s.idempotencyMiddleware(next)(context.Background(), request)
+	require.NoError(t, err)
+	return result
+}
+
+// TestIdempotencyMiddleware verifies that a create call retried with the same key
+// returns the original result, and that other calls run normally.
+func TestIdempotencyMiddleware(t *testing.T) {
+	s := idempotencyTestServer()
+	var calls atomic.Int32
+	next := countingHandler(&calls)
+
+	args := map[string]any{"name": "prod", paramIdempotencyKey: "k1"}
+	first := callIdempotent(t, s, next, ToolCreateEnvironmentTag, args)
+	first.Content[0] = mcp.NewTextContent("rewritten by an outer middleware")
+	retry := callIdempotent(t, s, next, ToolCreateEnvironmentTag, map[string]any{"name": "prod", paramIdempotencyKey: "k1"})
+	assert.Equal(t, int32(1), calls.Load(), "the retry does not create the tag again")
+	assert.Equal(t, "created 1", retry.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, true, resultMeta(retry, metaIdempotentReplay))
+	assert.Nil(t, first.Meta, "the original result is not flagged")
+
+	meta := callIdempotent(t, s, next, "manage_environments", map[string]any{"action": "create_environment_tag", "name": "prod", paramIdempotencyKey: "k1"})
+	assert.Equal(t, "created 1", meta.Content[0].(mcp.TextContent).Text, "meta-tool actions share the keys of their tool")
+
+	conflict := callIdempotent(t, s, next, ToolCreateEnvironmentTag, map[string]any{"name": "staging", paramIdempotencyKey: "k1"})
+	assert.True(t, conflict.IsError)
+	assert.Equal(t, ErrorCodeValidation, errorCode(conflict))
+	assert.Contains(t, conflict.Content[0].(mcp.TextContent).Text, `idempotency key "k1" was already used`)
+
+	callIdempotent(t, s, next, ToolCreateEnvironmentTag, map[string]any{"name": "prod", paramIdempotencyKey: "k2"})
+	callIdempotent(t, s, next, ToolCreateEnvironmentTag, map[string]any{"name": "prod"})
+	callIdempotent(t, s, next, ToolDeleteEnvironmentTag, map[string]any{"id": float64(1), paramIdempotencyKey: "k1"})
+	callIdempotent(t, s, next, ToolDeleteEnvironmentTag, map[string]any{"id": float64(1), paramIdempotencyKey: "k1"})
+	assert.Equal(t, int32(5), calls.Load(), "new keys, calls without a key and tools without the parameter run")
+}
+
+// TestIdempotencyMiddlewareFailures verifies that failed calls are not remembered, and
+// that keys expire.
+func TestIdempotencyMiddlewareFailures(t *testing.T) {
+	s := idempotencyTestServer()
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	s.idempotency.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	failing := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls.Add(1)
+		return mcp.NewToolResultError("upstream timeout"), nil
+	}
+	args := map[string]any{"name": "prod", paramIdempotencyKey: "k1"}
+	callIdempotent(t, s, failing, ToolCreateEnvironmentTag, args)
+	callIdempotent(t, s, failing, ToolCreateEnvironmentTag, args)
+	assert.Equal(t, int32(2), calls.Load(), "a failed call runs again")
+
+	next := countingHandler(&calls)
+	callIdempotent(t, s, next, ToolCreateEnvironmentTag, args)
+	callIdempotent(t, s, next, ToolCreateEnvironmentTag, args)
+	assert.Equal(t, int32(3), calls.Load())
+
+	now = now.Add(idempotencyKeyTTL)
+	result := callIdempotent(t, s, next, ToolCreateEnvironmentTag, args)
+	assert.Equal(t, "created 4", result.Content[0].(mcp.TextContent).Text, "an expired key runs the call again")
+}
+
+// TestIdempotencyMiddlewareConcurrentRetry verifies that a retry arriving while the first
+// call still runs waits for its result.
+func TestIdempotencyMiddlewareConcurrentRetry(t *testing.T) {
+	s := idempotencyTestServer()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+	slow := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return countingHandler(&calls)(ctx, request)
+	}
+
+	args := map[string]any{"name": "prod", paramIdempotencyKey: "k1"}
+	firstDone := make(chan *mcp.CallToolResult)
+	go func() {
+		firstDone <- callIdempotent(t, s, slow, ToolCreateEnvironmentTag, args)
+	}()
+	<-started
+
+	retryDone := make(chan *mcp.CallToolResult)
+	go func() {
+		retryDone <- callIdempotent(t, s, slow, ToolCreateEnvironmentTag, args)
+	}()
+	close(release)
+
+	assert.Equal(t, "created 1", (<-firstDone).Content[0].(mcp.TextContent).Text)
+	retry := <-retryDone
+	assert.Equal(t, "created 1", retry.Content[0].(mcp.TextContent).Text)
+	assert.Equal(t, true, resultMeta(retry, metaIdempotentReplay))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestIdempotencyStorePrune verifies that the keys expiring first are forgotten when the
+// store is full.
+func TestIdempotencyStorePrune(t *testing.T) {
+	st := newIdempotencyStore()
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	st.now = func() time.Time { return now }
+
+	for i := range maxIdempotencyKeys {
+		entry, created := st.begin(fmt.Sprint(i), [32]byte{})
+		require.True(t, created)
+		st.complete(fmt.Sprint(i), entry, mcp.NewToolResultText("ok"), nil)
+		now = now.Add(time.Millisecond)
+	}
+	_, created := st.begin("new", [32]byte{})
+	require.True(t, created)
+	assert.Len(t, st.entries, maxIdempotencyKeys)
+	assert.NotContains(t, st.entries, "0")
+	assert.Contains(t, st.entries, "1")
+}
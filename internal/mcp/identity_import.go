@@ -0,0 +1,288 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Outcomes of an imported user or team.
+const (
+	importStatusCreated = "created"
+	importStatusSkipped = "skipped"
+	importStatusFailed  = "failed"
+)
+
+// importItem is the outcome of one user or team definition of an import.
+type importItem struct {
+	Name   string `json:"name"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	// Memberships lists the teams a user joined, or the users added to a team.
+	Memberships []string `json:"memberships_added,omitempty"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// importReport is the result of HandleImportUsers and HandleImportTeams.
+type importReport struct {
+	Created int          `json:"created"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Items   []importItem `json:"items"`
+}
+
+// add records the outcome of an item.
+func (r *importReport) add(item importItem) {
+	switch item.Status {
+	case importStatusCreated:
+		r.Created++
+	case importStatusSkipped:
+		r.Skipped++
+	case importStatusFailed:
+		r.Failed++
+	}
+	r.Items = append(r.Items, item)
+}
+
+// userDefinition is a user to import.
+type userDefinition struct {
+	username string
+	password string
+	role     string
+	teams    []string
+}
+
+// parseUserDefinition reads a user definition of importUsers.
+func parseUserDefinition(object map[string]any) (userDefinition, error) {
+	fields := toolgen.NewObjectParser(object)
+
+	username, err := fields.GetString("username", true)
+	if err != nil {
+		return userDefinition{}, err
+	}
+	if strings.TrimSpace(username) == "" {
+		return userDefinition{}, fmt.Errorf("username cannot be empty")
+	}
+	password, err := fields.GetString("password", false)
+	if err != nil {
+		return userDefinition{}, err
+	}
+	role, err := fields.GetEnum("role", false, AllUserRoles...)
+	if err != nil {
+		return userDefinition{}, err
+	}
+	if role == "" {
+		role = UserRoleUser
+	}
+	teams, err := fields.GetArrayOfStrings("teams", false)
+	if err != nil {
+		return userDefinition{}, err
+	}
+	return userDefinition{username: username, password: password, role: role, teams: teams}, nil
+}
+
+// teamDefinition is a team to import.
+type teamDefinition struct {
+	name    string
+	members []string
+}
+
+// parseTeamDefinition reads a team definition of importTeams.
+func parseTeamDefinition(object map[string]any) (teamDefinition, error) {
+	fields := toolgen.NewObjectParser(object)
+
+	name, err := fields.GetString("name", true)
+	if err != nil {
+		return teamDefinition{}, err
+	}
+	if err := validateName(name); err != nil {
+		return teamDefinition{}, err
+	}
+	members, err := fields.GetArrayOfStrings("members", false)
+	if err != nil {
+		return teamDefinition{}, err
+	}
+	return teamDefinition{name: name, members: members}, nil
+}
+
+// identityDirectory indexes the users and teams of the Portainer instance by their
+// case-insensitive names and keeps track of the ones created by an import.
+type identityDirectory struct {
+	users map[string]int
+	teams map[string]*models.Team
+}
+
+// loadIdentityDirectory reads the users and teams of the Portainer instance.
+func (s *PortainerMCPServer) loadIdentityDirectory() (*identityDirectory, error) {
+	users, err := s.cli.GetUsers(models.UserListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	teams, err := s.cli.GetTeams()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+
+	dir := &identityDirectory{users: map[string]int{}, teams: map[string]*models.Team{}}
+	for _, user := range users {
+		dir.users[strings.ToLower(user.Username)] = user.ID
+	}
+	for _, team := range teams {
+		dir.teams[strings.ToLower(team.Name)] = &team
+	}
+	return dir, nil
+}
+
+// addTeamMember adds a user to a team unless it is already a member. It reports whether
+// the user was added.
+func (s *PortainerMCPServer) addTeamMember(team *models.Team, userID int) (bool, error) {
+	if slices.Contains(team.MemberIDs, userID) {
+		return false, nil
+	}
+	members := append(slices.Clone(team.MemberIDs), userID)
+	if err := s.cli.UpdateTeamMembers(team.ID, members); err != nil {
+		return false, err
+	}
+	team.MemberIDs = members
+	return true, nil
+}
+
+// HandleImportUsers returns an MCP tool handler that creates a list of users and adds them
+// to their teams. Users that already exist are skipped, but still join the listed teams
+// they are not a member of, so that an import can be run again after a partial failure.
+func (s *PortainerMCPServer) HandleImportUsers() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		users, err := parser.GetArrayOfObjectMaps("users", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid users parameter", err), nil
+		}
+
+		dir, err := s.loadIdentityDirectory()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to import users", err), nil
+		}
+
+		report := importReport{Items: []importItem{}}
+		for i, object := range users {
+			def, err := parseUserDefinition(object)
+			if err != nil {
+				name, _ := object["username"].(string)
+				report.add(importItem{Name: name, Status: importStatusFailed, Errors: []string{fmt.Sprintf("user %d: %v", i+1, err)}})
+				continue
+			}
+
+			item := importItem{Name: def.username}
+			if id, ok := dir.users[strings.ToLower(def.username)]; ok {
+				item.ID, item.Status = id, importStatusSkipped
+			} else {
+				if def.password == "" {
+					item.Status = importStatusFailed
+					item.Errors = append(item.Errors, "password is required to create a user")
+					report.add(item)
+					continue
+				}
+				id, err := s.cli.CreateUser(def.username, def.password, def.role)
+				if err != nil {
+					item.Status = importStatusFailed
+					item.Errors = append(item.Errors, fmt.Sprintf("failed to create user: %v", err))
+					report.add(item)
+					continue
+				}
+				dir.users[strings.ToLower(def.username)] = id
+				item.ID, item.Status = id, importStatusCreated
+			}
+
+			for _, name := range def.teams {
+				team, ok := dir.teams[strings.ToLower(name)]
+				if !ok {
+					item.Errors = append(item.Errors, fmt.Sprintf("team %q not found", name))
+					continue
+				}
+				added, err := s.addTeamMember(team, item.ID)
+				if err != nil {
+					item.Errors = append(item.Errors, fmt.Sprintf("failed to add to team %q: %v", team.Name, err))
+					continue
+				}
+				if added {
+					item.Memberships = append(item.Memberships, team.Name)
+				}
+			}
+			report.add(item)
+		}
+
+		return jsonResult(report, "failed to marshal user import report")
+	}
+}
+
+// HandleImportTeams returns an MCP tool handler that creates a list of teams and adds
+// their members. Teams that already exist are skipped, but still receive the listed
+// members they do not have; existing members are never removed.
+func (s *PortainerMCPServer) HandleImportTeams() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		teams, err := parser.GetArrayOfObjectMaps("teams", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid teams parameter", err), nil
+		}
+
+		dir, err := s.loadIdentityDirectory()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to import teams", err), nil
+		}
+
+		report := importReport{Items: []importItem{}}
+		for i, object := range teams {
+			def, err := parseTeamDefinition(object)
+			if err != nil {
+				name, _ := object["name"].(string)
+				report.add(importItem{Name: name, Status: importStatusFailed, Errors: []string{fmt.Sprintf("team %d: %v", i+1, err)}})
+				continue
+			}
+
+			item := importItem{Name: def.name}
+			team, ok := dir.teams[strings.ToLower(def.name)]
+			if ok {
+				item.ID, item.Status = team.ID, importStatusSkipped
+			} else {
+				id, err := s.cli.CreateTeam(def.name)
+				if err != nil {
+					item.Status = importStatusFailed
+					item.Errors = append(item.Errors, fmt.Sprintf("failed to create team: %v", err))
+					report.add(item)
+					continue
+				}
+				team = &models.Team{ID: id, Name: def.name}
+				dir.teams[strings.ToLower(def.name)] = team
+				item.ID, item.Status = id, importStatusCreated
+			}
+
+			for _, username := range def.members {
+				userID, ok := dir.users[strings.ToLower(username)]
+				if !ok {
+					item.Errors = append(item.Errors, fmt.Sprintf("user %q not found", username))
+					continue
+				}
+				added, err := s.addTeamMember(team, userID)
+				if err != nil {
+					item.Errors = append(item.Errors, fmt.Sprintf("failed to add user %q: %v", username, err))
+					continue
+				}
+				if added {
+					item.Memberships = append(item.Memberships, username)
+				}
+			}
+			report.add(item)
+		}
+
+		return jsonResult(report, "failed to marshal team import report")
+	}
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importFixtureUsers and importFixtureTeams are the users and teams of the Portainer instance in the import tests.
+var (
+	importFixtureUsers = []models.User{{ID: 1, Username: "admin", Role: "admin"}, {ID: 2, Username: "Alice", Role: "user"}}
+	importFixtureTeams = []models.Team{{ID: 10, Name: "developers", MemberIDs: []int{2}}, {ID: 11, Name: "ops", MemberIDs: []int{}}}
+)
+
+// TestHandleImportUsers verifies that importUsers creates the missing users, skips the
+// existing ones and adds the missing team memberships of both.
+func TestHandleImportUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+		expected    importReport
+	}{
+		{
+			name: "creates, skips and reports failures per user",
+			args: map[string]any{"users": []any{
+				map[string]any{"username": "bob", "password": "secret", "teams": []any{"Developers", "ops"}},
+				map[string]any{"username": "alice", "teams": []any{"developers", "ops", "qa"}},
+				map[string]any{"username": "carol"},
+				map[string]any{"username": "dave", "password": "secret", "role": "root"},
+				map[string]any{"username": "erin", "password": "secret", "role": "edge_admin"},
+				map[string]any{"username": "bob", "password": "other"},
+			}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetUsers", models.UserListOptions{}).Return(importFixtureUsers, nil)
+				m.On("GetTeams").Return(importFixtureTeams, nil)
+				m.On("CreateUser", "bob", "secret", "user").Return(3, nil)
+				m.On("UpdateTeamMembers", 10, []int{2, 3}).Return(nil)
+				m.On("UpdateTeamMembers", 11, []int{3}).Return(nil)
+				m.On("UpdateTeamMembers", 11, []int{3, 2}).Return(errors.New("forbidden"))
+				m.On("CreateUser", "erin", "secret", "edge_admin").Return(0, errors.New("username already taken"))
+			},
+			expected: importReport{Created: 1, Skipped: 2, Failed: 3, Items: []importItem{
+				{Name: "bob", ID: 3, Status: importStatusCreated, Memberships: []string{"developers", "ops"}},
+				{Name: "alice", ID: 2, Status: importStatusSkipped, Errors: []string{`failed to add to team "ops": forbidden`, `team "qa" not found`}},
+				{Name: "carol", Status: importStatusFailed, Errors: []string{"password is required to create a user"}},
+				{Name: "dave", Status: importStatusFailed, Errors: []string{"user 4: role must be one of: admin, user, edge_admin, got 'root'"}},
+				{Name: "erin", Status: importStatusFailed, Errors: []string{"failed to create user: username already taken"}},
+				{Name: "bob", ID: 3, Status: importStatusSkipped},
+			}},
+		},
+		{
+			name: "users error",
+			args: map[string]any{"users": []any{}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetUsers", models.UserListOptions{}).Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get users",
+		},
+		{name: "missing users", args: map[string]any{}, expectError: "users is required"},
+		{name: "invalid user definition", args: map[string]any{"users": []any{"alice"}}, expectError: "invalid users parameter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleImportUsers()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var report importReport
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			assert.Equal(t, tt.expected, report)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleImportTeams verifies that importTeams creates the missing teams, skips the
+// existing ones and adds the missing members of both.
+func TestHandleImportTeams(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+		expected    importReport
+	}{
+		{
+			name: "creates, skips and reports failures per team",
+			args: map[string]any{"teams": []any{
+				map[string]any{"name": "Developers", "members": []any{"alice", "ADMIN"}},
+				map[string]any{"name": "qa", "members": []any{"alice", "zoe"}},
+				map[string]any{"name": "support"},
+				map[string]any{"members": []any{"alice"}},
+			}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetUsers", models.UserListOptions{}).Return(importFixtureUsers, nil)
+				m.On("GetTeams").Return(importFixtureTeams, nil)
+				m.On("UpdateTeamMembers", 10, []int{2, 1}).Return(nil)
+				m.On("CreateTeam", "qa").Return(12, nil)
+				m.On("UpdateTeamMembers", 12, []int{2}).Return(nil)
+				m.On("CreateTeam", "support").Return(0, errors.New("team already exists"))
+			},
+			expected: importReport{Created: 1, Skipped: 1, Failed: 2, Items: []importItem{
+				{Name: "Developers", ID: 10, Status: importStatusSkipped, Memberships: []string{"ADMIN"}},
+				{Name: "qa", ID: 12, Status: importStatusCreated, Memberships: []string{"alice"}, Errors: []string{`user "zoe" not found`}},
+				{Name: "support", Status: importStatusFailed, Errors: []string{"failed to create team: team already exists"}},
+				{Status: importStatusFailed, Errors: []string{"team 4: name is required"}},
+			}},
+		},
+		{
+			name: "teams error",
+			args: map[string]any{"teams": []any{}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetUsers", models.UserListOptions{}).Return(importFixtureUsers, nil)
+				m.On("GetTeams").Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get teams",
+		},
+		{name: "missing teams", args: map[string]any{}, expectError: "teams is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleImportTeams()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var report importReport
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			assert.Equal(t, tt.expected, report)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/distribution/reference"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// serviceRedeploy describes a Swarm service that runs the rolled out image, and whether its
+// service webhook was triggered.
+type serviceRedeploy struct {
+	EnvironmentID int    `json:"environment_id"`
+	ServiceID     string `json:"service_id"`
+	Name          string `json:"name"`
+	Image         string `json:"image"`
+	WebhookID     int    `json:"webhook_id"`
+	Triggered     bool   `json:"triggered"`
+	Error         string `json:"error,omitempty"`
+}
+
+// imageRolloutReport is the result of HandleRedeployStacksForImage.
+type imageRolloutReport struct {
+	// Image is the normalized reference of the rolled out image.
+	Image    string            `json:"image"`
+	Stacks   []stackRedeploy   `json:"stacks"`
+	Services []serviceRedeploy `json:"services"`
+	Errors   []string          `json:"errors,omitempty"`
+}
+
+// webhookService is a Swarm service that runs the rolled out image and has a service webhook.
+type webhookService struct {
+	environmentID int
+	service       models.DockerService
+	webhook       models.Webhook
+}
+
+// imageRollout is what HandleRedeployStacksForImage redeploys for an image.
+type imageRollout struct {
+	stacks   []composeStack
+	services []webhookService
+	// environments are the environments whose services were listed.
+	environments []int
+	errs         []string
+}
+
+// HandleRedeployStacksForImage returns an MCP tool handler that rolls out a new build of an
+// image: it redeploys, with an image pull, the regular stacks whose Compose files use the
+// image, and triggers the service webhooks of the Swarm services that run it. Services of a
+// redeployed stack are left to the stack redeploy. Only the environments with service
+// webhooks are searched for services.
+func (s *PortainerMCPServer) HandleRedeployStacksForImage() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		image, err := parser.GetString("image", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid image parameter", err), nil
+		}
+		target, err := parseRolloutImage(image)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		environmentIds, err := parser.GetArrayOfIntegers("environmentIds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentIds parameter", err), nil
+		}
+		for _, id := range environmentIds {
+			if err := validatePositiveID("environmentIds", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+
+		rollout, err := s.findImageRollout(ctx, target, environmentIds)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to find the stacks and services using the image", err), nil
+		}
+
+		if plan {
+			steps, notes := imageRolloutPlan(rollout)
+			return s.previewPlan(ctx, request, steps, notes, s.HandleRedeployStacksForImage())
+		}
+
+		report := imageRolloutReport{
+			Image:    target.String(),
+			Stacks:   []stackRedeploy{},
+			Services: []serviceRedeploy{},
+			Errors:   rollout.errs,
+		}
+		for _, match := range rollout.stacks {
+			entry := stackRedeploy{StackID: match.stack.ID, Name: match.stack.Name, EnvironmentID: match.stack.EndpointID, Images: match.images}
+			entry.Redeployed, entry.Error = s.redeployStackWithPull(ctx, match.stack)
+			report.Stacks = append(report.Stacks, entry)
+		}
+		for _, ws := range rollout.services {
+			entry := serviceRedeploy{
+				EnvironmentID: ws.environmentID,
+				ServiceID:     ws.service.ID,
+				Name:          ws.service.Name,
+				Image:         ws.service.Image,
+				WebhookID:     ws.webhook.ID,
+			}
+			if err := ctx.Err(); err != nil {
+				entry.Error = err.Error()
+			} else if err := s.cli.TriggerWebhook(ws.webhook.Token); err != nil {
+				entry.Error = err.Error()
+			} else {
+				entry.Triggered = true
+			}
+			report.Services = append(report.Services, entry)
+		}
+
+		return jsonResult(report, "failed to marshal image rollout report")
+	}
+}
+
+// parseRolloutImage parses the image of a rollout. An image without a tag stands for its
+// latest tag, as with docker pull; digests are rejected since a digest never moves.
+func parseRolloutImage(image string) (reference.NamedTagged, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: %v", image, err)
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return nil, fmt.Errorf("image %q is pinned to a digest: pass the tag to roll out", image)
+	}
+	return reference.TagNameOnly(named).(reference.NamedTagged), nil
+}
+
+// sameImageTag reports whether named refers to the repository and tag of target, an image
+// without a tag standing for its latest tag. Digests are ignored, since Docker pins the
+// image of a Swarm service to the digest of its tag.
+func sameImageTag(named reference.Named, target reference.NamedTagged) bool {
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	return ok && named.Name() == target.Name() && tagged.Tag() == target.Tag()
+}
+
+// findImageRollout returns the regular stacks whose Compose files use target and the Swarm
+// services with a service webhook that run it, restricted to environmentIds when set. The
+// services of the matched stacks are left out. Only a failure to list the stacks or the
+// webhooks is returned as an error; the other failures are reported in the rollout.
+func (s *PortainerMCPServer) findImageRollout(ctx context.Context, target reference.NamedTagged, environmentIds []int) (imageRollout, error) {
+	var rollout imageRollout
+
+	stacks, err := s.cli.GetRegularStacks()
+	if err != nil {
+		return rollout, fmt.Errorf("failed to list stacks: %w", err)
+	}
+	if len(environmentIds) > 0 {
+		stacks = slices.DeleteFunc(stacks, func(stack models.RegularStack) bool {
+			return !slices.Contains(environmentIds, stack.EndpointID)
+		})
+	}
+	rollout.stacks, rollout.errs = s.findComposeStacks(stacks, func(named reference.Named) bool {
+		return sameImageTag(named, target)
+	})
+
+	webhooks, err := s.cli.GetWebhooks()
+	if err != nil {
+		return rollout, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	serviceWebhooks := map[int][]models.Webhook{}
+	for _, webhook := range webhooks {
+		if webhook.Type != WebhookTypeService {
+			continue
+		}
+		if len(environmentIds) > 0 && !slices.Contains(environmentIds, webhook.EndpointID) {
+			continue
+		}
+		if _, ok := serviceWebhooks[webhook.EndpointID]; !ok {
+			rollout.environments = append(rollout.environments, webhook.EndpointID)
+		}
+		serviceWebhooks[webhook.EndpointID] = append(serviceWebhooks[webhook.EndpointID], webhook)
+	}
+	slices.Sort(rollout.environments)
+
+	services := make([][]models.DockerService, len(rollout.environments))
+	listErrs := make([]error, len(rollout.environments))
+	runConcurrently(ctx, len(rollout.environments), func(i int) {
+		services[i], listErrs[i] = s.cli.GetDockerServices(rollout.environments[i])
+	})
+	if err := ctx.Err(); err != nil {
+		return rollout, err
+	}
+
+	for i, envID := range rollout.environments {
+		if listErrs[i] != nil {
+			rollout.errs = append(rollout.errs, fmt.Sprintf("environment %d: %v", envID, listErrs[i]))
+			continue
+		}
+		for _, service := range services[i] {
+			named, err := reference.ParseNormalizedNamed(service.Image)
+			if err != nil || !sameImageTag(named, target) {
+				continue
+			}
+			if slices.ContainsFunc(rollout.stacks, func(match composeStack) bool {
+				return match.stack.EndpointID == envID && match.stack.Name == service.Stack
+			}) {
+				continue
+			}
+			idx := slices.IndexFunc(serviceWebhooks[envID], func(webhook models.Webhook) bool {
+				return webhook.ResourceID == service.ID
+			})
+			if idx < 0 {
+				continue
+			}
+			rollout.services = append(rollout.services, webhookService{environmentID: envID, service: service, webhook: serviceWebhooks[envID][idx]})
+		}
+	}
+	return rollout, nil
+}
+
+// imageRolloutPlan returns the Portainer API calls made by HandleRedeployStacksForImage for
+// a rollout resolved with read-only calls.
+func imageRolloutPlan(rollout imageRollout) ([]planStep, []string) {
+	steps := []planStep{
+		{Method: http.MethodGet, Path: "/api/stacks", Description: "List the regular stacks and read their Compose files to match the image"},
+		{Method: http.MethodGet, Path: "/api/webhooks", Description: "List the webhooks"},
+	}
+	for _, envID := range rollout.environments {
+		steps = append(steps, planStep{
+			Method:      http.MethodGet,
+			Path:        fmt.Sprintf("/api/endpoints/%d/docker/services", envID),
+			Description: fmt.Sprintf("List the Swarm services of environment %d to match the image", envID),
+		})
+	}
+
+	notes := slices.Clone(rollout.errs)
+	for _, match := range rollout.stacks {
+		redeploy, note := stackRedeployPlan(match)
+		steps = append(steps, redeploy...)
+		if note != "" {
+			notes = append(notes, note)
+		}
+	}
+	for _, ws := range rollout.services {
+		steps = append(steps, planStep{
+			Method:      http.MethodPost,
+			Path:        "/api/webhooks/{token}",
+			Description: fmt.Sprintf("Trigger webhook %d to pull the image of service %q of environment %d and update it", ws.webhook.ID, ws.service.Name, ws.environmentID),
+		})
+	}
+	notes = append(notes, "affected stacks and services are matched again when the plan is applied")
+	return steps, notes
+}
@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleRedeployStacksForImage verifies the HandleRedeployStacksForImage MCP tool handler.
+func TestHandleRedeployStacksForImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	fileStack := models.RegularStack{ID: 1, Name: "shop", EndpointID: 2, Status: regularStackStatusActive}
+	gitStack := models.RegularStack{ID: 2, Name: "web", EndpointID: 3, Status: regularStackStatusActive, Git: &models.StackGitConfig{URL: "https://github.com/acme/web.git"}}
+	stoppedStack := models.RegularStack{ID: 3, Name: "batch", EndpointID: 2, Status: regularStackStatusInactive}
+	otherStack := models.RegularStack{ID: 4, Name: "cache", EndpointID: 2, Status: regularStackStatusActive}
+	stacks := []models.RegularStack{fileStack, gitStack, stoppedStack, otherStack}
+	webhooks := []models.Webhook{
+		{ID: 10, EndpointID: 2, ResourceID: "svc-shop-api", Token: "t10", Type: WebhookTypeService},
+		{ID: 11, EndpointID: 2, ResourceID: "svc-api", Token: "t11", Type: WebhookTypeService},
+		{ID: 12, EndpointID: 2, ResourceID: "svc-worker", Token: "t12", Type: WebhookTypeService},
+		{ID: 13, EndpointID: 5, ResourceID: "svc-edge", Token: "t13", Type: WebhookTypeService},
+		{ID: 14, EndpointID: 3, ResourceID: "container-1", Token: "t14", Type: WebhookTypeContainer},
+	}
+	services := []models.DockerService{
+		{ID: "svc-shop-api", Name: "shop_api", Image: "acme/api:1.4@" + digest, Stack: "shop"},
+		{ID: "svc-api", Name: "api", Image: "docker.io/acme/api:1.4@" + digest},
+		{ID: "svc-worker", Name: "worker", Image: "acme/api:1.3@" + digest},
+		{ID: "svc-nohook", Name: "api-canary", Image: "acme/api:1.4"},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    imageRolloutReport
+	}{
+		{
+			name:  "redeploys stacks and triggers service webhooks",
+			input: map[string]any{"image": "acme/api:1.4"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRegularStacks").Return(stacks, nil)
+				m.On("InspectStackFile", 1).Return("services:\n  api:\n    image: acme/api:1.4\n  db:\n    image: postgres:16\n", nil)
+				m.On("InspectStackFile", 2).Return("services:\n  web:\n    image: docker.io/acme/api:1.4\n", nil)
+				m.On("InspectStackFile", 3).Return("services:\n  job:\n    image: acme/api:1.4\n", nil)
+				m.On("InspectStackFile", 4).Return("services:\n  cache:\n    image: acme/api:1.3\n", nil)
+				m.On("GetWebhooks").Return(webhooks, nil)
+				m.On("GetDockerServices", 2).Return(services, nil)
+				m.On("GetDockerServices", 5).Return(nil, fmt.Errorf("This node is not a swarm manager"))
+				m.On("RedeployRegularStack", 1, 2, true).Return(fileStack, nil)
+				m.On("RedeployStackGit", 2, 3, true, false).Return(gitStack, nil)
+				m.On("TriggerWebhook", "t11").Return(nil)
+			},
+			expected: imageRolloutReport{
+				Image: "docker.io/acme/api:1.4",
+				Stacks: []stackRedeploy{
+					{StackID: 1, Name: "shop", EnvironmentID: 2, Images: []string{"acme/api:1.4"}, Redeployed: true},
+					{StackID: 2, Name: "web", EnvironmentID: 3, Images: []string{"docker.io/acme/api:1.4"}, Redeployed: true},
+					{StackID: 3, Name: "batch", EnvironmentID: 2, Images: []string{"acme/api:1.4"}, Error: "stack is not running; redeploy it after starting it"},
+				},
+				Services: []serviceRedeploy{
+					{EnvironmentID: 2, ServiceID: "svc-api", Name: "api", Image: "docker.io/acme/api:1.4@" + digest, WebhookID: 11, Triggered: true},
+				},
+				Errors: []string{"environment 5: This node is not a swarm manager"},
+			},
+		},
+		{
+			name:  "restricted to environments with webhook error",
+			input: map[string]any{"image": "acme/api:1.4", "environmentIds": []any{float64(2)}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRegularStacks").Return([]models.RegularStack{otherStack, gitStack}, nil)
+				m.On("InspectStackFile", 4).Return("", fmt.Errorf("file not found"))
+				m.On("GetWebhooks").Return(webhooks, nil)
+				m.On("GetDockerServices", 2).Return(services, nil)
+				m.On("TriggerWebhook", "t10").Return(fmt.Errorf("service not found"))
+				m.On("TriggerWebhook", "t11").Return(nil)
+			},
+			expected: imageRolloutReport{
+				Image:  "docker.io/acme/api:1.4",
+				Stacks: []stackRedeploy{},
+				Services: []serviceRedeploy{
+					{EnvironmentID: 2, ServiceID: "svc-shop-api", Name: "shop_api", Image: "acme/api:1.4@" + digest, WebhookID: 10, Error: "service not found"},
+					{EnvironmentID: 2, ServiceID: "svc-api", Name: "api", Image: "docker.io/acme/api:1.4@" + digest, WebhookID: 11, Triggered: true},
+				},
+				Errors: []string{"stack 4 (cache): file not found"},
+			},
+		},
+		{
+			name:  "stack listing error",
+			input: map[string]any{"image": "acme/api:1.4"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRegularStacks").Return(nil, fmt.Errorf("timeout"))
+			},
+			expectError: "failed to find the stacks and services using the image",
+		},
+		{
+			name:        "digest image",
+			input:       map[string]any{"image": "acme/api@" + digest},
+			expectError: "pinned to a digest",
+		},
+		{
+			name:        "invalid image",
+			input:       map[string]any{"image": "Acme/API"},
+			expectError: "invalid image reference",
+		},
+		{
+			name:        "missing image",
+			input:       map[string]any{},
+			expectError: "image",
+		},
+		{
+			name:        "invalid environment ID",
+			input:       map[string]any{"image": "acme/api:1.4", "environmentIds": []any{float64(-1)}},
+			expectError: "environmentIds",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleRedeployStacksForImage()(context.Background(), CreateMCPRequest(tt.input))
+			require.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				require.False(t, result.IsError, textContent.Text)
+				var report imageRolloutReport
+				require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+				assert.Equal(t, tt.expected, report)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRedeployStacksForImagePlan verifies that a rollout preview redeploys nothing and lists
+// the stack redeploys and webhook triggers.
+func TestRedeployStacksForImagePlan(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetRegularStacks").Return([]models.RegularStack{
+		{ID: 1, Name: "shop", EndpointID: 2, Status: regularStackStatusActive},
+		{ID: 2, Name: "batch", EndpointID: 2, Status: regularStackStatusInactive},
+	}, nil)
+	mockClient.On("InspectStackFile", 1).Return("services:\n  api:\n    image: acme/api\n", nil)
+	mockClient.On("InspectStackFile", 2).Return("services:\n  job:\n    image: acme/api:latest\n", nil)
+	mockClient.On("GetWebhooks").Return([]models.Webhook{{ID: 7, EndpointID: 2, ResourceID: "svc-api", Token: "secret", Type: WebhookTypeService}}, nil)
+	mockClient.On("GetDockerServices", 2).Return([]models.DockerService{{ID: "svc-api", Name: "api", Image: "acme/api:latest@" + digest}}, nil)
+	s := &PortainerMCPServer{cli: mockClient}
+
+	result, err := s.HandleRedeployStacksForImage()(context.Background(), CreateMCPRequest(map[string]any{"image": "acme/api", "plan": true}))
+	require.NoError(t, err)
+
+	plan := decodePlan(t, result)
+	var calls []string
+	for _, step := range plan.Steps {
+		calls = append(calls, step.Method+" "+step.Path)
+	}
+	assert.Equal(t, []string{
+		"GET /api/stacks",
+		"GET /api/webhooks",
+		"GET /api/endpoints/2/docker/services",
+		"GET /api/stacks/1",
+		"GET /api/stacks/1/file",
+		"PUT /api/stacks/1?endpointId=2",
+		"POST /api/webhooks/{token}",
+	}, calls)
+	assert.Len(t, plan.Notes, 2)
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "secret")
+	mockClient.AssertNotCalled(t, "RedeployRegularStack", mock.Anything, mock.Anything, mock.Anything)
+	mockClient.AssertNotCalled(t, "TriggerWebhook", mock.Anything)
+}
+
+// TestSameImageTag verifies image matching on repository and tag.
+func TestSameImageTag(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	target, err := parseRolloutImage("acme/api")
+	require.NoError(t, err)
+
+	for image, expected := range map[string]bool{
+		"acme/api":                  true,
+		"docker.io/acme/api:latest": true,
+		"acme/api:latest@" + digest: true,
+		"acme/api:1.4":              false,
+		"ghcr.io/acme/api:latest":   false,
+		"acme/api@" + digest:        false,
+	} {
+		named, err := reference.ParseNormalizedNamed(image)
+		require.NoError(t, err)
+		assert.Equal(t, expected, sameImageTag(named, target), image)
+	}
+}
@@ -0,0 +1,303 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Sections compared by compareInstances.
+const (
+	compareSectionSettings   = "settings"
+	compareSectionRegistries = "registries"
+	compareSectionTeams      = "teams"
+	compareSectionTags       = "tags"
+)
+
+var compareSections = []string{compareSectionSettings, compareSectionRegistries, compareSectionTeams, compareSectionTags}
+
+// instanceComparison is the result of HandleCompareInstances.
+type instanceComparison struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// InSync is true when no section has a discrepancy.
+	InSync   bool                `json:"inSync"`
+	Sections []sectionComparison `json:"sections"`
+}
+
+// sectionComparison lists the discrepancies of one section. Resources are matched by name,
+// since their IDs differ between instances.
+type sectionComparison struct {
+	Section      string            `json:"section"`
+	OnlyInSource []string          `json:"onlyInSource,omitempty"`
+	OnlyInTarget []string          `json:"onlyInTarget,omitempty"`
+	Different    []valueDifference `json:"different,omitempty"`
+}
+
+// valueDifference is a value that differs between the two instances.
+type valueDifference struct {
+	Name   string `json:"name"`
+	Source any    `json:"source"`
+	Target any    `json:"target"`
+}
+
+// HandleCompareInstances returns an MCP tool handler that compares the configuration of two
+// configured Portainer servers: their portable settings, registries, teams and tags.
+func (s *PortainerMCPServer) HandleCompareInstances() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		target, err := parser.GetString("target", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid target parameter", err), nil
+		}
+		source, err := parser.GetString("source", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid source parameter", err), nil
+		}
+		if source == "" {
+			source = primaryInstance
+		}
+		if source == target {
+			return mcp.NewToolResultError("source and target must be different instances"), nil
+		}
+
+		sections, err := parser.GetArrayOfEnums("sections", false, compareSections...)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid sections parameter", err), nil
+		}
+		if len(sections) == 0 {
+			sections = compareSections
+		}
+
+		sourceCli, err := s.instanceClient(source)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		targetCli, err := s.instanceClient(target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		report := instanceComparison{Source: source, Target: target, InSync: true}
+		for _, section := range compareSections {
+			if !slices.Contains(sections, section) {
+				continue
+			}
+
+			var comparison sectionComparison
+			switch section {
+			case compareSectionSettings:
+				comparison, err = compareSettings(sourceCli, targetCli)
+			case compareSectionRegistries:
+				comparison, err = compareRegistries(sourceCli, targetCli)
+			case compareSectionTeams:
+				comparison, err = compareTeams(sourceCli, targetCli)
+			case compareSectionTags:
+				comparison, err = compareTags(sourceCli, targetCli)
+			}
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr(fmt.Sprintf("failed to compare %s", section), err), nil
+			}
+
+			comparison.Section = section
+			if len(comparison.OnlyInSource) > 0 || len(comparison.OnlyInTarget) > 0 || len(comparison.Different) > 0 {
+				report.InSync = false
+			}
+			report.Sections = append(report.Sections, comparison)
+		}
+
+		return jsonResult(report, "failed to marshal instance comparison")
+	}
+}
+
+// compareSettings compares the portable settings of two instances, the ones carried by
+// settings bundles, without their secrets.
+func compareSettings(source, target PortainerClient) (sectionComparison, error) {
+	portable := func(cli PortainerClient) (map[string]any, error) {
+		values, err := cli.GetSettingsValues(portableSettings)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range slices.Concat(settingsSecrets, settingsInstanceKeys) {
+			removeSettingsPath(values, path)
+		}
+		return values, nil
+	}
+
+	sourceValues, err := portable(source)
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("source: %w", err)
+	}
+	targetValues, err := portable(target)
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("target: %w", err)
+	}
+
+	var comparison sectionComparison
+	diffValues("", sourceValues, targetValues, &comparison.Different)
+	return comparison, nil
+}
+
+// diffValues records the differences between two JSON values, descending into objects so
+// that each differing field is reported with its path.
+func diffValues(path string, source, target any, out *[]valueDifference) {
+	sourceMap, sourceIsMap := source.(map[string]any)
+	targetMap, targetIsMap := target.(map[string]any)
+	if sourceIsMap && targetIsMap {
+		keys := slices.Collect(maps.Keys(sourceMap))
+		for key := range targetMap {
+			if _, ok := sourceMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			name := key
+			if path != "" {
+				name = path + "." + key
+			}
+			diffValues(name, sourceMap[key], targetMap[key], out)
+		}
+		return
+	}
+
+	sourceJSON, _ := json.Marshal(source)
+	targetJSON, _ := json.Marshal(target)
+	if string(sourceJSON) != string(targetJSON) {
+		*out = append(*out, valueDifference{Name: path, Source: source, Target: target})
+	}
+}
+
+// compareRegistries compares the registries of two instances by name.
+func compareRegistries(source, target PortainerClient) (sectionComparison, error) {
+	sourceRegistries, err := source.GetRegistries()
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("source: %w", err)
+	}
+	targetRegistries, err := target.GetRegistries()
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("target: %w", err)
+	}
+
+	// Only the fields that do not depend on the instance are compared.
+	fields := func(r models.Registry) map[string]any {
+		return map[string]any{"type": r.Type, "url": r.URL, "baseUrl": r.BaseURL, "authentication": r.Authentication, "username": r.Username}
+	}
+	return compareByName(sourceRegistries, targetRegistries, func(r models.Registry) string { return r.Name }, fields), nil
+}
+
+// compareTeams compares the teams of two instances by name, and their members by username.
+func compareTeams(source, target PortainerClient) (sectionComparison, error) {
+	teamMembers := func(cli PortainerClient) ([]models.Team, map[int]string, error) {
+		teams, err := cli.GetTeams()
+		if err != nil {
+			return nil, nil, err
+		}
+		users, err := cli.GetUsers(models.UserListOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		usernames := make(map[int]string, len(users))
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+		return teams, usernames, nil
+	}
+
+	sourceTeams, sourceUsers, err := teamMembers(source)
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("source: %w", err)
+	}
+	targetTeams, targetUsers, err := teamMembers(target)
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("target: %w", err)
+	}
+
+	// User IDs differ between instances, so members are compared by username, each resolved
+	// with the users of its own instance.
+	members := func(team models.Team, usernames map[int]string) map[string]any {
+		names := []string{}
+		for _, id := range team.MemberIDs {
+			if name, ok := usernames[id]; ok {
+				names = append(names, name)
+			} else {
+				names = append(names, fmt.Sprintf("user %d", id))
+			}
+		}
+		slices.Sort(names)
+		return map[string]any{"members": names}
+	}
+
+	teamName := func(team models.Team) string { return team.Name }
+	comparison := compareByName(sourceTeams, targetTeams, teamName, nil)
+	targetByName := make(map[string]models.Team, len(targetTeams))
+	for _, team := range targetTeams {
+		targetByName[team.Name] = team
+	}
+	for _, team := range sortedByName(sourceTeams, teamName) {
+		if other, ok := targetByName[team.Name]; ok {
+			diffValues(team.Name, members(team, sourceUsers), members(other, targetUsers), &comparison.Different)
+		}
+	}
+	return comparison, nil
+}
+
+// compareTags compares the environment tags of two instances by name.
+func compareTags(source, target PortainerClient) (sectionComparison, error) {
+	sourceTags, err := source.GetEnvironmentTags()
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("source: %w", err)
+	}
+	targetTags, err := target.GetEnvironmentTags()
+	if err != nil {
+		return sectionComparison{}, fmt.Errorf("target: %w", err)
+	}
+
+	return compareByName(sourceTags, targetTags, func(t models.EnvironmentTag) string { return t.Name }, nil), nil
+}
+
+// compareByName matches the resources of two instances by name and, when fields is not nil,
+// compares the fields it returns for the resources present on both sides.
+func compareByName[T any](source, target []T, name func(T) string, fields func(T) map[string]any) sectionComparison {
+	var comparison sectionComparison
+
+	targetByName := make(map[string]T, len(target))
+	for _, item := range target {
+		targetByName[name(item)] = item
+	}
+	sourceNames := make(map[string]bool, len(source))
+	for _, item := range sortedByName(source, name) {
+		sourceNames[name(item)] = true
+		other, ok := targetByName[name(item)]
+		if !ok {
+			comparison.OnlyInSource = append(comparison.OnlyInSource, name(item))
+			continue
+		}
+		if fields != nil {
+			diffValues(name(item), fields(item), fields(other), &comparison.Different)
+		}
+	}
+	for _, item := range sortedByName(target, name) {
+		if !sourceNames[name(item)] {
+			comparison.OnlyInTarget = append(comparison.OnlyInTarget, name(item))
+		}
+	}
+
+	return comparison
+}
+
+// sortedByName returns a copy of items sorted by name.
+func sortedByName[T any](items []T, name func(T) string) []T {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b T) int { return strings.Compare(name(a), name(b)) })
+	return sorted
+}
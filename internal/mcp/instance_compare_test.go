@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleCompareInstances verifies the discrepancies reported for each section, matched
+// by name between the two instances.
+func TestHandleCompareInstances(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputParams   map[string]any
+		setupSource   func(*MockPortainerClient)
+		setupTarget   func(*MockPortainerClient)
+		expectError   string
+		expectInSync  bool
+		expectedDrift []sectionComparison
+	}{
+		{
+			name:        "settings without secrets",
+			inputParams: map[string]any{"target": "staging", "sections": []any{"settings"}},
+			setupSource: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", portableSettings).Return(map[string]any{
+					"snapshotInterval": "5m",
+					"ldapsettings":     map[string]any{"URL": "ldap.example.com:389", "Password": "a"},
+					"oauthSettings":    map[string]any{"ClientID": "portainer", "KubeSecretKey": "a"},
+				}, nil)
+			},
+			setupTarget: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", portableSettings).Return(map[string]any{
+					"snapshotInterval": "1h",
+					"ldapsettings":     map[string]any{"URL": "ldap.example.com:636", "Password": "b"},
+					"oauthSettings":    map[string]any{"ClientID": "portainer", "KubeSecretKey": "b"},
+				}, nil)
+			},
+			expectedDrift: []sectionComparison{{
+				Section: "settings",
+				Different: []valueDifference{
+					{Name: "ldapsettings.URL", Source: "ldap.example.com:389", Target: "ldap.example.com:636"},
+					{Name: "snapshotInterval", Source: "5m", Target: "1h"},
+				},
+			}},
+		},
+		{
+			name:        "registries and tags",
+			inputParams: map[string]any{"target": "staging", "sections": []any{"tags", "registries"}},
+			setupSource: func(m *MockPortainerClient) {
+				m.On("GetRegistries").Return([]models.Registry{
+					{ID: 1, Name: "ghcr", Type: 8, URL: "ghcr.io", Authentication: true, Username: "ci"},
+					{ID: 2, Name: "quay", Type: 1, URL: "quay.io"},
+				}, nil)
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 1, Name: "prod"}, {ID: 2, Name: "eu"}}, nil)
+			},
+			setupTarget: func(m *MockPortainerClient) {
+				m.On("GetRegistries").Return([]models.Registry{
+					{ID: 5, Name: "ghcr", Type: 8, URL: "ghcr.io", Authentication: true, Username: "deploy"},
+					{ID: 6, Name: "internal", Type: 3, URL: "registry.example.com"},
+				}, nil)
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 7, Name: "eu"}, {ID: 8, Name: "staging"}}, nil)
+			},
+			expectedDrift: []sectionComparison{
+				{
+					Section:      "registries",
+					OnlyInSource: []string{"quay"},
+					OnlyInTarget: []string{"internal"},
+					Different:    []valueDifference{{Name: "ghcr.username", Source: "ci", Target: "deploy"}},
+				},
+				{Section: "tags", OnlyInSource: []string{"prod"}, OnlyInTarget: []string{"staging"}},
+			},
+		},
+		{
+			name:        "team members by username",
+			inputParams: map[string]any{"source": "staging", "target": "dr", "sections": []any{"teams"}},
+			setupSource: func(m *MockPortainerClient) {
+				m.On("GetTeams").Return([]models.Team{{ID: 1, Name: "ops", MemberIDs: []int{2, 3}}}, nil)
+				m.On("GetUsers", models.UserListOptions{}).Return([]models.User{{ID: 2, Username: "alice"}, {ID: 3, Username: "bob"}}, nil)
+			},
+			setupTarget: func(m *MockPortainerClient) {
+				m.On("GetTeams").Return([]models.Team{{ID: 4, Name: "ops", MemberIDs: []int{7, 9}}}, nil)
+				m.On("GetUsers", models.UserListOptions{}).Return([]models.User{{ID: 7, Username: "bob"}, {ID: 9, Username: "carol"}}, nil)
+			},
+			expectedDrift: []sectionComparison{{
+				Section:   "teams",
+				Different: []valueDifference{{Name: "ops.members", Source: []any{"alice", "bob"}, Target: []any{"bob", "carol"}}},
+			}},
+		},
+		{
+			name:        "in sync",
+			inputParams: map[string]any{"target": "staging", "sections": []any{"tags"}},
+			setupSource: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 1, Name: "prod"}}, nil)
+			},
+			setupTarget: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 3, Name: "prod"}}, nil)
+			},
+			expectInSync:  true,
+			expectedDrift: []sectionComparison{{Section: "tags"}},
+		},
+		{
+			name:        "target error",
+			inputParams: map[string]any{"target": "staging", "sections": []any{"tags"}},
+			setupSource: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag{}, nil)
+			},
+			setupTarget: func(m *MockPortainerClient) {
+				m.On("GetEnvironmentTags").Return([]models.EnvironmentTag(nil), fmt.Errorf("unauthorized"))
+			},
+			expectError: "failed to compare tags",
+		},
+		{
+			name:        "unknown instance",
+			inputParams: map[string]any{"target": "prod"},
+			expectError: `unknown instance "prod"`,
+		},
+		{
+			name:        "same instance",
+			inputParams: map[string]any{"source": "staging", "target": "staging"},
+			expectError: "source and target must be different instances",
+		},
+		{
+			name:        "invalid section",
+			inputParams: map[string]any{"target": "staging", "sections": []any{"users"}},
+			expectError: "sections items must be one of: settings, registries, teams, tags, got 'users'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &MockPortainerClient{}
+			staging := &MockPortainerClient{}
+			dr := &MockPortainerClient{}
+			source, target := primary, staging
+			if tt.inputParams["source"] == "staging" {
+				source, target = staging, dr
+			}
+			if tt.setupSource != nil {
+				tt.setupSource(source)
+			}
+			if tt.setupTarget != nil {
+				tt.setupTarget(target)
+			}
+			s := &PortainerMCPServer{cli: primary, instances: map[string]PortainerClient{"staging": staging, "dr": dr}}
+
+			result, err := s.HandleCompareInstances()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var report instanceComparison
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			assert.Equal(t, tt.expectInSync, report.InSync)
+			assert.Equal(t, tt.expectedDrift, report.Sections)
+			source.AssertExpectations(t)
+			target.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/client"
+	"gopkg.in/yaml.v3"
+)
+
+// primaryInstance is the name of the Portainer server given with -server and -token.
+const primaryInstance = "primary"
+
+// instanceNamePattern restricts instance names to identifiers that are easy to pass as
+// tool parameters.
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// instanceConfig describes an additional Portainer server in an instances file.
+type instanceConfig struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
+	// Token is the API token of the server. TokenEnv names an environment variable holding
+	// it instead, to keep the token out of the file.
+	Token         string `yaml:"token" json:"token"`
+	TokenEnv      string `yaml:"tokenEnv" json:"tokenEnv"`
+	SkipTLSVerify bool   `yaml:"skipTLSVerify" json:"skipTLSVerify"`
+}
+
+// instancesFile is the content of an instances file.
+type instancesFile struct {
+	Instances []instanceConfig `yaml:"instances" json:"instances"`
+}
+
+// loadInstanceConfigs reads an instances file (YAML or JSON), checks the instance names and
+// resolves the tokens read from environment variables.
+func loadInstanceConfigs(path string) ([]instanceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instances file: %w", err)
+	}
+
+	var file instancesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse instances file %s: %w", path, err)
+	}
+	if len(file.Instances) == 0 {
+		return nil, fmt.Errorf("instances file %s defines no instance", path)
+	}
+
+	var names []string
+	for i := range file.Instances {
+		instance := &file.Instances[i]
+		switch {
+		case !instanceNamePattern.MatchString(instance.Name):
+			return nil, fmt.Errorf("invalid instance name %q: use letters, digits, '.', '_' and '-'", instance.Name)
+		case instance.Name == primaryInstance:
+			return nil, fmt.Errorf("instance name %q is reserved for the server given with -server", primaryInstance)
+		case slices.Contains(names, instance.Name):
+			return nil, fmt.Errorf("duplicate instance name %q", instance.Name)
+		case strings.TrimSpace(instance.URL) == "":
+			return nil, fmt.Errorf("instance %q has no url", instance.Name)
+		}
+		names = append(names, instance.Name)
+
+		if instance.TokenEnv != "" {
+			if instance.Token != "" {
+				return nil, fmt.Errorf("instance %q sets both token and tokenEnv", instance.Name)
+			}
+			instance.Token = os.Getenv(instance.TokenEnv)
+		}
+		if instance.Token == "" {
+			return nil, fmt.Errorf("instance %q has no API token (set token, or tokenEnv to a non-empty environment variable)", instance.Name)
+		}
+	}
+
+	return file.Instances, nil
+}
+
+// newInstanceClients creates a Portainer client for each instance of an instances file.
+func newInstanceClients(path string) (map[string]PortainerClient, error) {
+	configs, err := loadInstanceConfigs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]PortainerClient, len(configs))
+	for _, cfg := range configs {
+		clients[cfg.Name] = client.NewPortainerClient(cfg.URL, cfg.Token, client.WithSkipTLSVerify(cfg.SkipTLSVerify))
+	}
+	return clients, nil
+}
+
+// instanceClient returns the client of a configured Portainer server, the server given
+// with -server when name is empty or "primary".
+func (s *PortainerMCPServer) instanceClient(name string) (PortainerClient, error) {
+	if name == "" || name == primaryInstance {
+		return s.cli, nil
+	}
+	if cli, ok := s.instances[name]; ok {
+		return cli, nil
+	}
+
+	names := []string{primaryInstance}
+	for instance := range s.instances {
+		names = append(names, instance)
+	}
+	slices.Sort(names[1:])
+	return nil, fmt.Errorf("unknown instance %q: configured instances are %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadInstanceConfigs verifies the validation of an instances file and the resolution of
+// tokens read from environment variables.
+func TestLoadInstanceConfigs(t *testing.T) {
+	t.Setenv("STAGING_PORTAINER_TOKEN", "ptr_staging")
+
+	tests := []struct {
+		name        string
+		content     string
+		expectError string
+		expected    []instanceConfig
+	}{
+		{
+			name: "token and tokenEnv",
+			content: `instances:
+  - name: staging
+    url: https://staging.example.com
+    tokenEnv: STAGING_PORTAINER_TOKEN
+  - name: dr-site
+    url: https://dr.example.com
+    token: ptr_dr
+    skipTLSVerify: true
+`,
+			expected: []instanceConfig{
+				{Name: "staging", URL: "https://staging.example.com", Token: "ptr_staging", TokenEnv: "STAGING_PORTAINER_TOKEN"},
+				{Name: "dr-site", URL: "https://dr.example.com", Token: "ptr_dr", SkipTLSVerify: true},
+			},
+		},
+		{
+			name:     "JSON file",
+			content:  `{"instances": [{"name": "staging", "url": "https://staging.example.com", "token": "ptr_staging"}]}`,
+			expected: []instanceConfig{{Name: "staging", URL: "https://staging.example.com", Token: "ptr_staging"}},
+		},
+		{
+			name:        "no instance",
+			content:     "instances: []\n",
+			expectError: "defines no instance",
+		},
+		{
+			name:        "reserved name",
+			content:     "instances:\n  - name: primary\n    url: https://a.example.com\n    token: x\n",
+			expectError: `instance name "primary" is reserved`,
+		},
+		{
+			name:        "invalid name",
+			content:     "instances:\n  - name: my instance\n    url: https://a.example.com\n    token: x\n",
+			expectError: `invalid instance name "my instance"`,
+		},
+		{
+			name:        "duplicate name",
+			content:     "instances:\n  - name: a\n    url: https://a.example.com\n    token: x\n  - name: a\n    url: https://b.example.com\n    token: y\n",
+			expectError: `duplicate instance name "a"`,
+		},
+		{
+			name:        "missing url",
+			content:     "instances:\n  - name: a\n    token: x\n",
+			expectError: `instance "a" has no url`,
+		},
+		{
+			name:        "token and tokenEnv together",
+			content:     "instances:\n  - name: a\n    url: https://a.example.com\n    token: x\n    tokenEnv: STAGING_PORTAINER_TOKEN\n",
+			expectError: "sets both token and tokenEnv",
+		},
+		{
+			name:        "unset token variable",
+			content:     "instances:\n  - name: a\n    url: https://a.example.com\n    tokenEnv: UNSET_PORTAINER_TOKEN\n",
+			expectError: `instance "a" has no API token`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "instances.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			configs, err := loadInstanceConfigs(path)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, configs)
+		})
+	}
+}
+
+// TestInstanceClient verifies the lookup of configured instances.
+func TestInstanceClient(t *testing.T) {
+	primary := &MockPortainerClient{}
+	staging := &MockPortainerClient{}
+	s := &PortainerMCPServer{cli: primary, instances: map[string]PortainerClient{"staging": staging, "dr": &MockPortainerClient{}}}
+
+	cli, err := s.instanceClient("")
+	require.NoError(t, err)
+	assert.Same(t, primary, cli)
+
+	cli, err = s.instanceClient(primaryInstance)
+	require.NoError(t, err)
+	assert.Same(t, primary, cli)
+
+	cli, err = s.instanceClient("staging")
+	require.NoError(t, err)
+	assert.Same(t, staging, cli)
+
+	_, err = s.instanceClient("prod")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown instance "prod": configured instances are primary, dr, staging`)
+}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultKeepAliveInterval is the default delay of client silence after which the
+	// server pings the client.
+	DefaultKeepAliveInterval = 30 * time.Second
+	// DefaultStallTimeout is the default delay of client silence after which the server
+	// considers that the client is gone and stops.
+	DefaultStallTimeout = 2 * time.Minute
+
+	methodPing = "ping"
+)
+
+// keepAlive pings the client whenever it has been silent for interval, and calls stalled
+// once no message, ping responses included, has been received from the client for timeout.
+// A timeout of 0 disables stall detection. It returns when ctx is done or the client stalls.
+func (c *clientConn) keepAlive(ctx context.Context, interval, timeout time.Duration, stalled func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		idle := time.Since(c.lastRead())
+		if timeout > 0 && idle >= timeout {
+			log.Error().Dur("idle", idle).Msg("MCP client stopped responding, stopping server")
+			stalled()
+			return
+		}
+		if idle >= interval {
+			go func() {
+				pingCtx, cancel := context.WithTimeout(ctx, interval)
+				defer cancel()
+				if err := c.request(pingCtx, methodPing, nil, nil); err != nil {
+					log.Debug().Err(err).Msg("MCP client did not answer ping")
+				}
+			}()
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientConnKeepAlive verifies that a silent client is pinged, and that the server
+// only stops when the client no longer answers.
+func TestClientConnKeepAlive(t *testing.T) {
+	t.Run("responsive client", func(t *testing.T) {
+		outReader, outWriter := io.Pipe()
+		inReader, inWriter := io.Pipe()
+		conn := newClientConn(outWriter)
+		conn.input(inReader)
+
+		// The client answers every ping.
+		pings := make(chan struct{}, 100)
+		go func() {
+			scanner := bufio.NewScanner(outReader)
+			for scanner.Scan() {
+				var request struct {
+					ID     string `json:"id"`
+					Method string `json:"method"`
+				}
+				if json.Unmarshal(scanner.Bytes(), &request) != nil || request.Method != methodPing {
+					continue
+				}
+				pings <- struct{}{}
+				_, _ = io.WriteString(inWriter, `{"jsonrpc":"2.0","id":"`+request.ID+`","result":{}}`+"\n")
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		stalled := false
+		conn.keepAlive(ctx, 20*time.Millisecond, 150*time.Millisecond, func() { stalled = true })
+
+		assert.False(t, stalled)
+		assert.NotEmpty(t, pings)
+	})
+
+	t.Run("stalled client", func(t *testing.T) {
+		conn := newClientConn(io.Discard)
+
+		stalled := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			conn.keepAlive(context.Background(), 10*time.Millisecond, 50*time.Millisecond, func() { close(stalled) })
+			close(done)
+		}()
+
+		select {
+		case <-stalled:
+		case <-time.After(time.Second):
+			t.Fatal("stalled client not detected")
+		}
+		<-done
+	})
+}
+
+// TestNewPortainerMCPServerKeepAlive verifies that a stall timeout shorter than the
+// keep-alive interval fails server creation.
+func TestNewPortainerMCPServerKeepAlive(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithKeepAlive(30*time.Second, 2*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, s.keepAliveInterval)
+	assert.Equal(t, 2*time.Minute, s.stallTimeout)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithKeepAlive(time.Minute, 30*time.Second))
+	assert.ErrorContains(t, err, "stall timeout 30s is shorter than the keep-alive interval 1m0s")
+}
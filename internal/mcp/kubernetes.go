@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -9,8 +10,10 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/jmrplens/portainer-mcp-enhanced/internal/k8sutil"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/proxyroutes"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // AddKubernetesProxyFeatures registers the Kubernetes proxy and resource management tools on the MCP server.
@@ -22,6 +25,73 @@ func (s *PortainerMCPServer) AddKubernetesProxyFeatures() {
 	}
 }
 
+// checkKubernetesProxyRequest validates a Kubernetes proxy request against the API layout
+// and the configured proxy rules.
+func (s *PortainerMCPServer) checkKubernetesProxyRequest(method, apiPath string) error {
+	if !s.skipProxyValidation {
+		if err := proxyroutes.ValidateKubernetes(method, apiPath); err != nil {
+			return err
+		}
+	}
+	if s.proxyPolicy != nil {
+		return s.proxyPolicy.CheckKubernetes(method, apiPath)
+	}
+	return nil
+}
+
+// kubernetesStatusError is returned when the Kubernetes API answers a proxied request with
+// an unsuccessful status.
+type kubernetesStatusError struct {
+	statusCode int
+	body       string
+}
+
+func (e *kubernetesStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.statusCode, e.body)
+}
+
+// getKubernetesJSON sends a GET request to the Kubernetes API through the Portainer proxy
+// and decodes the JSON response into out. The request is subject to the same validation
+// and proxy rules as the proxy tools.
+func (s *PortainerMCPServer) getKubernetesJSON(environmentId int, apiPath string, queryParams map[string]string, out any) error {
+	if err := s.checkKubernetesProxyRequest("GET", apiPath); err != nil {
+		return err
+	}
+
+	response, err := s.cli.ProxyKubernetesRequest(models.KubernetesProxyRequestOptions{
+		EnvironmentID: environmentId,
+		Method:        "GET",
+		Path:          apiPath,
+		QueryParams:   queryParams,
+	})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxProxyResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if response.StatusCode >= 300 {
+		return &kubernetesStatusError{statusCode: response.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// getKubernetesList sends a GET request for a Kubernetes collection through the
+// Portainer proxy and decodes its items.
+func (s *PortainerMCPServer) getKubernetesList(environmentId int, apiPath string, queryParams map[string]string, items any) error {
+	list := struct {
+		Items any `json:"items"`
+	}{Items: items}
+	return s.getKubernetesJSON(environmentId, apiPath, queryParams, &list)
+}
+
 // HandleKubernetesProxyStripped returns an MCP tool handler that handles kubernetes proxy stripped.
 func (s *PortainerMCPServer) HandleKubernetesProxyStripped() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -42,23 +112,23 @@ func (s *PortainerMCPServer) HandleKubernetesProxyStripped() server.ToolHandlerF
 		if !strings.HasPrefix(kubernetesAPIPath, "/") {
 			return mcp.NewToolResultError("kubernetesAPIPath must start with a leading slash"), nil
 		}
-
-		queryParams, err := parser.GetArrayOfObjects("queryParams", false)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid queryParams parameter", err), nil
+		if err := s.checkKubernetesProxyRequest("GET", kubernetesAPIPath); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		queryParamsMap, err := parseKeyValueMap(queryParams)
+
+		queryParamsMap, err := parser.GetKeyValueMap("queryParams", false)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid query params", err), nil
 		}
 
-		headers, err := parser.GetArrayOfObjects("headers", false)
+		headersMap, err := parser.GetKeyValueMap("headers", false)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid headers parameter", err), nil
+			return mcp.NewToolResultErrorFromErr("invalid headers", err), nil
 		}
-		headersMap, err := parseKeyValueMap(headers)
+
+		keep, err := parser.GetArrayOfStrings("keep", false)
 		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid headers", err), nil
+			return mcp.NewToolResultErrorFromErr("invalid keep parameter", err), nil
 		}
 
 		opts := models.KubernetesProxyRequestOptions{
@@ -74,7 +144,12 @@ func (s *PortainerMCPServer) HandleKubernetesProxyStripped() server.ToolHandlerF
 			return mcp.NewToolResultErrorFromErr("failed to send Kubernetes API request", err), nil
 		}
 
-		responseBody, err := k8sutil.ProcessRawKubernetesAPIResponse(response)
+		stripper := s.k8sStripper
+		if stripper == nil {
+			stripper, _ = k8sutil.NewStripper(k8sutil.DefaultStripFields)
+		}
+
+		responseBody, err := stripper.Process(response, keep)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to process Kubernetes API response", err), nil
 		}
@@ -117,21 +192,16 @@ func (s *PortainerMCPServer) HandleKubernetesProxy() server.ToolHandlerFunc {
 		if !strings.HasPrefix(kubernetesAPIPath, "/") {
 			return mcp.NewToolResultError("kubernetesAPIPath must start with a leading slash"), nil
 		}
-
-		queryParams, err := parser.GetArrayOfObjects("queryParams", false)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid queryParams parameter", err), nil
+		if err := s.checkKubernetesProxyRequest(method, kubernetesAPIPath); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
-		queryParamsMap, err := parseKeyValueMap(queryParams)
+
+		queryParamsMap, err := parser.GetKeyValueMap("queryParams", false)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid query params", err), nil
 		}
 
-		headers, err := parser.GetArrayOfObjects("headers", false)
-		if err != nil {
-			return mcp.NewToolResultErrorFromErr("invalid headers parameter", err), nil
-		}
-		headersMap, err := parseKeyValueMap(headers)
+		headersMap, err := parser.GetKeyValueMap("headers", false)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid headers", err), nil
 		}
@@ -159,7 +229,7 @@ func (s *PortainerMCPServer) HandleKubernetesProxy() server.ToolHandlerFunc {
 		}
 		defer response.Body.Close()
 
-		responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxProxyResponseSize))
+		responseBody, err := readProxyResponse(ctx, response.Body)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to read Kubernetes API response", err), nil
 		}
@@ -173,6 +243,12 @@ func (s *PortainerMCPServer) AddKubernetesNativeFeatures() {
 	s.addToolIfExists(ToolGetKubernetesDashboard, s.HandleGetKubernetesDashboard())
 	s.addToolIfExists(ToolListKubernetesNamespaces, s.HandleListKubernetesNamespaces())
 	s.addToolIfExists(ToolGetKubernetesConfig, s.HandleGetKubernetesConfig())
+	s.addToolIfExists(ToolDescribeKubernetesResource, s.HandleDescribeKubernetesResource())
+	s.addToolIfExists(ToolListKubernetesAPIResources, s.HandleListKubernetesAPIResources())
+	s.addToolIfExists(ToolTopKubernetesNodes, s.HandleTopKubernetesNodes())
+	s.addToolIfExists(ToolTopKubernetesPods, s.HandleTopKubernetesPods())
+	s.addToolIfExists(ToolGetNamespaceUsage, s.HandleGetNamespaceUsage())
+	s.addToolIfExists(ToolListCustomResources, s.HandleListCustomResources())
 }
 
 // HandleGetKubernetesDashboard returns an MCP tool handler that retrieves kubernetes dashboard.
@@ -245,3 +321,339 @@ func (s *PortainerMCPServer) HandleGetKubernetesConfig() server.ToolHandlerFunc
 		}
 	}
 }
+
+// HandleDescribeKubernetesResource returns an MCP tool handler that summarizes a Kubernetes
+// resource and its events like kubectl describe.
+func (s *PortainerMCPServer) HandleDescribeKubernetesResource() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		kindName, err := parser.GetString("kind", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+		kind, ok := k8sutil.LookupKind(kindName)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported kind %q, supported kinds: %s", kindName, strings.Join(k8sutil.DescribableKinds(), ", "))), nil
+		}
+
+		name, err := parser.GetString("name", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if strings.TrimSpace(name) == "" {
+			return mcp.NewToolResultError("name must not be empty"), nil
+		}
+
+		namespace, err := parser.GetString("namespace", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+		if namespace == "" {
+			namespace = "default"
+		}
+
+		var object map[string]any
+		if err := s.getKubernetesJSON(environmentId, kind.Path(namespace, name), nil, &object); err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("failed to get %s %s", kind.Kind, name), err), nil
+		}
+
+		metadata, _ := object["metadata"].(map[string]any)
+		uid, _ := metadata["uid"].(string)
+		var events []map[string]any
+		eventsErr := s.getKubernetesList(environmentId, kind.EventsPath(namespace), map[string]string{"fieldSelector": kind.EventsFieldSelector(name, uid)}, &events)
+
+		result := struct {
+			k8sutil.Description
+			EventsError string `json:"eventsError,omitempty"`
+		}{Description: k8sutil.Describe(kind, object, events)}
+		if eventsErr != nil {
+			result.EventsError = fmt.Sprintf("failed to list events: %v", eventsErr)
+		}
+
+		return jsonResult(result, "failed to marshal resource description")
+	}
+}
+
+// kubernetesAPIGroup is an API group of a Kubernetes environment with its versions.
+type kubernetesAPIGroup struct {
+	Name             string   `json:"name"`
+	PreferredVersion string   `json:"preferredVersion"`
+	Versions         []string `json:"versions"`
+}
+
+// kubernetesAPIResourcesReport is the result of HandleListKubernetesAPIResources.
+type kubernetesAPIResourcesReport struct {
+	Groups    []kubernetesAPIGroup  `json:"groups"`
+	Resources []k8sutil.APIResource `json:"resources"`
+	Errors    []string              `json:"errors,omitempty"`
+}
+
+// HandleListKubernetesAPIResources returns an MCP tool handler that lists the API groups
+// and resources served by a Kubernetes environment, including custom resources, from the
+// discovery endpoints. Resources are listed for the preferred version of each group.
+func (s *PortainerMCPServer) HandleListKubernetesAPIResources() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		group, err := parser.GetString("group", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid group parameter", err), nil
+		}
+
+		report := kubernetesAPIResourcesReport{Groups: []kubernetesAPIGroup{}, Resources: []k8sutil.APIResource{}}
+
+		var groupVersions []string
+		if group == "" || group == k8sutil.CoreGroup {
+			report.Groups = append(report.Groups, kubernetesAPIGroup{Name: k8sutil.CoreGroup, PreferredVersion: "v1", Versions: []string{"v1"}})
+			groupVersions = append(groupVersions, "v1")
+		}
+
+		if group != k8sutil.CoreGroup {
+			var groups metav1.APIGroupList
+			if err := s.getKubernetesJSON(environmentId, "/apis", nil, &groups); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to list Kubernetes API groups", err), nil
+			}
+
+			for _, g := range groups.Groups {
+				if group != "" && g.Name != group {
+					continue
+				}
+				apiGroup := kubernetesAPIGroup{Name: g.Name, PreferredVersion: g.PreferredVersion.Version}
+				for _, v := range g.Versions {
+					apiGroup.Versions = append(apiGroup.Versions, v.Version)
+				}
+				report.Groups = append(report.Groups, apiGroup)
+				groupVersions = append(groupVersions, g.PreferredVersion.GroupVersion)
+			}
+			if group != "" && len(groupVersions) == 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("API group %q is not served by the environment; omit the group parameter to list every group", group)), nil
+			}
+		}
+
+		for _, groupVersion := range groupVersions {
+			var list metav1.APIResourceList
+			if err := s.getKubernetesJSON(environmentId, k8sutil.GroupVersionPath(groupVersion), nil, &list); err != nil {
+				// Aggregated APIs, such as metrics.k8s.io, fail while their backing service is down.
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", groupVersion, err))
+				continue
+			}
+			report.Resources = append(report.Resources, k8sutil.ResourcesFromList(list)...)
+		}
+
+		return jsonResult(report, "failed to marshal Kubernetes API resources")
+	}
+}
+
+// parseTopSortBy parses the sortBy parameter of the top tools, which defaults to cpu.
+func parseTopSortBy(parser *toolgen.ParameterParser) (string, error) {
+	sortBy, err := parser.GetEnum("sortBy", false, "cpu", "memory")
+	if err != nil {
+		return "", err
+	}
+	if sortBy == "" {
+		return "cpu", nil
+	}
+	return sortBy, nil
+}
+
+// HandleTopKubernetesNodes returns an MCP tool handler that reports the CPU and memory
+// usage of the nodes of a Kubernetes environment, like kubectl top nodes.
+func (s *PortainerMCPServer) HandleTopKubernetesNodes() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		sortBy, err := parseTopSortBy(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid sortBy parameter", err), nil
+		}
+
+		report, err := s.topNodes(environmentId, sortBy)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return jsonResult(report, "failed to marshal node metrics")
+	}
+}
+
+// HandleTopKubernetesPods returns an MCP tool handler that reports the pods with the
+// highest CPU or memory usage in a Kubernetes environment, like kubectl top pods.
+func (s *PortainerMCPServer) HandleTopKubernetesPods() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		namespace, err := parser.GetString("namespace", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+
+		sortBy, err := parseTopSortBy(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid sortBy parameter", err), nil
+		}
+
+		limit, err := parser.GetInt("limit", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if limit == 0 {
+			limit = defaultTopLimit
+		}
+		if limit < 0 || limit > maxTopLimit {
+			return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d, got %d", maxTopLimit, limit)), nil
+		}
+
+		containers, err := parser.GetBoolean("containers", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid containers parameter", err), nil
+		}
+
+		report, err := s.topPods(environmentId, namespace, sortBy, limit, containers)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return jsonResult(report, "failed to marshal pod metrics")
+	}
+}
+
+// HandleGetNamespaceUsage returns an MCP tool handler that summarizes the resource usage
+// of a Kubernetes namespace: workload counts, resource requests and limits compared with
+// its quotas, and the pods using the most CPU or memory.
+func (s *PortainerMCPServer) HandleGetNamespaceUsage() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		namespace, err := parser.GetString("namespace", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+		if strings.TrimSpace(namespace) == "" {
+			return mcp.NewToolResultError("namespace must not be empty"), nil
+		}
+
+		sortBy, err := parseTopSortBy(parser)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid sortBy parameter", err), nil
+		}
+
+		limit, err := parser.GetInt("limit", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if limit == 0 {
+			limit = defaultNamespaceTopLimit
+		}
+		if limit < 0 || limit > maxTopLimit {
+			return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d, got %d", maxTopLimit, limit)), nil
+		}
+
+		report, err := s.namespaceUsage(environmentId, namespace, sortBy, limit)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return jsonResult(report, "failed to marshal namespace usage")
+	}
+}
+
+// HandleListCustomResources returns an MCP tool handler that lists the custom resources of
+// a kind, such as the certificates of cert-manager, resolving the kind to its resource
+// name and version from the discovery endpoints.
+func (s *PortainerMCPServer) HandleListCustomResources() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var query customResourceQuery
+		if query.Group, err = parser.GetString("group", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid group parameter", err), nil
+		}
+		if query.Group == "" || query.Group == k8sutil.CoreGroup || strings.Contains(query.Group, "/") {
+			return mcp.NewToolResultError("group must be the name of an API group, such as cert-manager.io; list the core resources with getKubernetesResourceStripped"), nil
+		}
+		if query.Version, err = parser.GetString("version", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid version parameter", err), nil
+		}
+		if query.Kind, err = parser.GetString("kind", true); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+		if strings.TrimSpace(query.Kind) == "" {
+			return mcp.NewToolResultError("kind must not be empty"), nil
+		}
+		if query.Namespace, err = parser.GetString("namespace", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+		if query.LabelSelector, err = parser.GetString("labelSelector", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid labelSelector parameter", err), nil
+		}
+		if query.Continue, err = parser.GetString("continue", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid continue parameter", err), nil
+		}
+
+		if query.Limit, err = parser.GetInt("limit", false); err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultCustomResourceLimit
+		}
+		if query.Limit < 0 || query.Limit > maxCustomResourceLimit {
+			return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d, got %d", maxCustomResourceLimit, query.Limit)), nil
+		}
+
+		report, err := s.listCustomResources(environmentId, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return jsonResult(report, "failed to marshal custom resources")
+	}
+}
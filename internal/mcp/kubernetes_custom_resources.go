@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/k8sutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultCustomResourceLimit is the number of custom resources returned when no limit
+	// is given.
+	defaultCustomResourceLimit = 100
+	// maxCustomResourceLimit caps the number of custom resources returned by a call.
+	maxCustomResourceLimit = 500
+)
+
+// customResourceQuery selects the custom resources listed by HandleListCustomResources.
+type customResourceQuery struct {
+	Group         string
+	Version       string
+	Kind          string
+	Namespace     string
+	LabelSelector string
+	Limit         int
+	Continue      string
+}
+
+// customResourcesReport is the result of HandleListCustomResources.
+type customResourcesReport struct {
+	// Resource is the resolved resource, with its kind, plural name, version and scope.
+	Resource  k8sutil.APIResource `json:"resource"`
+	Namespace string              `json:"namespace,omitempty"`
+	Count     int                 `json:"count"`
+	Items     []map[string]any    `json:"items"`
+	// Continue is the token to pass as continue to get the next page, when there is one.
+	Continue string `json:"continue,omitempty"`
+}
+
+// resolveCustomResource resolves the kind of a query to a resource of its API group from
+// the discovery endpoints, using the preferred version of the group when no version is
+// given.
+func (s *PortainerMCPServer) resolveCustomResource(environmentId int, query customResourceQuery) (k8sutil.APIResource, error) {
+	version := query.Version
+	if version == "" {
+		var group metav1.APIGroup
+		if err := s.getKubernetesJSON(environmentId, "/apis/"+url.PathEscape(query.Group), nil, &group); err != nil {
+			return k8sutil.APIResource{}, discoveryError(query.Group, err)
+		}
+		version = group.PreferredVersion.Version
+	}
+
+	groupVersion := query.Group + "/" + version
+	var list metav1.APIResourceList
+	if err := s.getKubernetesJSON(environmentId, "/apis/"+url.PathEscape(query.Group)+"/"+url.PathEscape(version), nil, &list); err != nil {
+		return k8sutil.APIResource{}, discoveryError(groupVersion, err)
+	}
+	list.GroupVersion = groupVersion
+
+	resources := k8sutil.ResourcesFromList(list)
+	resource, ok := k8sutil.FindResource(resources, query.Kind)
+	if !ok {
+		kinds := make([]string, 0, len(resources))
+		for _, r := range resources {
+			kinds = append(kinds, r.Kind)
+		}
+		return k8sutil.APIResource{}, fmt.Errorf("kind %q is not served by %s; served kinds: %s", query.Kind, groupVersion, strings.Join(kinds, ", "))
+	}
+	if !slices.Contains(resource.Verbs, "list") {
+		return k8sutil.APIResource{}, fmt.Errorf("%s %s cannot be listed", groupVersion, resource.Kind)
+	}
+	return resource, nil
+}
+
+// discoveryError explains a failed discovery request, telling when the group or version is
+// not served.
+func discoveryError(groupVersion string, err error) error {
+	var statusErr *kubernetesStatusError
+	if errors.As(err, &statusErr) && statusErr.statusCode == http.StatusNotFound {
+		return fmt.Errorf("API %s is not served by the environment; use listKubernetesAPIResources to list the served groups and versions", groupVersion)
+	}
+	return fmt.Errorf("failed to discover API %s: %w", groupVersion, err)
+}
+
+// listCustomResources lists the custom resources selected by a query, in a namespace or,
+// for a namespaced kind without namespace, in every namespace. The fields removed by the
+// stripped Kubernetes proxy tool are removed from every item.
+func (s *PortainerMCPServer) listCustomResources(environmentId int, query customResourceQuery) (customResourcesReport, error) {
+	resource, err := s.resolveCustomResource(environmentId, query)
+	if err != nil {
+		return customResourcesReport{}, err
+	}
+
+	prefix := "/apis/" + url.PathEscape(query.Group) + "/" + url.PathEscape(resource.Version)
+	apiPath := prefix + "/" + resource.Resource
+	if query.Namespace != "" {
+		if !resource.Namespaced {
+			return customResourcesReport{}, fmt.Errorf("%s is cluster-scoped; omit the namespace parameter", resource.Kind)
+		}
+		apiPath = prefix + "/namespaces/" + url.PathEscape(query.Namespace) + "/" + resource.Resource
+	}
+
+	params := map[string]string{"limit": strconv.Itoa(query.Limit)}
+	if query.LabelSelector != "" {
+		params["labelSelector"] = query.LabelSelector
+	}
+	if query.Continue != "" {
+		params["continue"] = query.Continue
+	}
+
+	var list struct {
+		Metadata struct {
+			Continue string `json:"continue"`
+		} `json:"metadata"`
+		Items []map[string]any `json:"items"`
+	}
+	if err := s.getKubernetesJSON(environmentId, apiPath, params, &list); err != nil {
+		return customResourcesReport{}, fmt.Errorf("failed to list %s: %w", resource.Resource, err)
+	}
+
+	stripper := s.k8sStripper
+	if stripper == nil {
+		stripper, _ = k8sutil.NewStripper(k8sutil.DefaultStripFields)
+	}
+	for _, item := range list.Items {
+		if err := stripper.StripObject(item); err != nil {
+			return customResourcesReport{}, fmt.Errorf("failed to process %s: %w", resource.Resource, err)
+		}
+	}
+
+	report := customResourcesReport{
+		Resource:  resource,
+		Namespace: query.Namespace,
+		Count:     len(list.Items),
+		Items:     list.Items,
+		Continue:  list.Metadata.Continue,
+	}
+	if report.Items == nil {
+		report.Items = []map[string]any{}
+	}
+	return report, nil
+}
@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// metricsAPIPath is the API path of the resource metrics served by metrics-server.
+	metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+	// defaultTopLimit is the number of top pods returned when no limit is given.
+	defaultTopLimit = 20
+	// maxTopLimit caps the number of top pods returned.
+	maxTopLimit = 200
+)
+
+// k8sUsage is the CPU and memory usage of a metrics.k8s.io item.
+type k8sUsage struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+// k8sNodeMetrics is an item of the metrics.k8s.io NodeMetricsList.
+type k8sNodeMetrics struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Usage    k8sUsage      `json:"usage"`
+}
+
+// k8sPodMetrics is an item of the metrics.k8s.io PodMetricsList.
+type k8sPodMetrics struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Containers []struct {
+		Name  string   `json:"name"`
+		Usage k8sUsage `json:"usage"`
+	} `json:"containers"`
+}
+
+// k8sNodeAllocatable is the allocatable capacity of a node.
+type k8sNodeAllocatable struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Status   struct {
+		Allocatable k8sUsage `json:"allocatable"`
+	} `json:"status"`
+}
+
+// resourceUsage is the CPU and memory usage of a node, pod or container, as numbers and
+// in the Kubernetes quantity notation used by kubectl top.
+type resourceUsage struct {
+	CPU           string `json:"cpu"`
+	CPUMillicores int64  `json:"cpuMillicores"`
+	Memory        string `json:"memory"`
+	MemoryBytes   int64  `json:"memoryBytes"`
+}
+
+// newResourceUsage parses the quantities of a metrics.k8s.io usage. Quantities that
+// cannot be parsed are counted as zero.
+func newResourceUsage(usage k8sUsage) resourceUsage {
+	cpu, _ := resource.ParseQuantity(usage.CPU)
+	memory, _ := resource.ParseQuantity(usage.Memory)
+	return usageFromValues(cpu.MilliValue(), memory.Value())
+}
+
+// usageFromValues formats CPU millicores and memory bytes like kubectl top.
+func usageFromValues(cpuMillicores, memoryBytes int64) resourceUsage {
+	return resourceUsage{
+		CPU:           resource.NewMilliQuantity(cpuMillicores, resource.DecimalSI).String(),
+		CPUMillicores: cpuMillicores,
+		Memory:        resource.NewQuantity(memoryBytes, resource.BinarySI).String(),
+		MemoryBytes:   memoryBytes,
+	}
+}
+
+// nodeTop is the usage of a node, with its share of the allocatable capacity when known.
+type nodeTop struct {
+	Name string `json:"name"`
+	resourceUsage
+	CPUPercent    *float64 `json:"cpuPercent,omitempty"`
+	MemoryPercent *float64 `json:"memoryPercent,omitempty"`
+}
+
+// nodeTopReport is the result of HandleTopKubernetesNodes.
+type nodeTopReport struct {
+	SortBy string    `json:"sortBy"`
+	Nodes  []nodeTop `json:"nodes"`
+	// Warning explains why the percentages of allocatable capacity are missing.
+	Warning string `json:"warning,omitempty"`
+}
+
+// containerTop is the usage of a container of a pod.
+type containerTop struct {
+	Name string `json:"name"`
+	resourceUsage
+}
+
+// podTop is the usage of a pod, which is the sum of its containers.
+type podTop struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	resourceUsage
+	Containers []containerTop `json:"containers,omitempty"`
+}
+
+// podTopReport is the result of HandleTopKubernetesPods.
+type podTopReport struct {
+	SortBy     string        `json:"sortBy"`
+	TotalPods  int           `json:"totalPods"`
+	TotalUsage resourceUsage `json:"totalUsage"`
+	Pods       []podTop      `json:"pods"`
+}
+
+// metricsError turns a failed metrics request into an error that tells whether the
+// metrics API is missing or unavailable, since both are common on clusters without a
+// working metrics-server.
+func metricsError(err error) error {
+	var statusErr *kubernetesStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.statusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("resource metrics are not available on this environment: the metrics.k8s.io API is not served, install metrics-server to enable them")
+		case http.StatusServiceUnavailable:
+			return fmt.Errorf("resource metrics are temporarily unavailable: metrics-server is installed but not ready (%s)", statusErr.body)
+		}
+	}
+	return fmt.Errorf("failed to get resource metrics: %w", err)
+}
+
+// topNodes returns the usage of the nodes of an environment sorted by sortBy. The
+// percentages of allocatable capacity are omitted, with a warning, when the nodes
+// cannot be listed.
+func (s *PortainerMCPServer) topNodes(environmentId int, sortBy string) (nodeTopReport, error) {
+	var metrics []k8sNodeMetrics
+	if err := s.getKubernetesList(environmentId, metricsAPIPath+"/nodes", nil, &metrics); err != nil {
+		return nodeTopReport{}, metricsError(err)
+	}
+
+	report := nodeTopReport{SortBy: sortBy, Nodes: make([]nodeTop, 0, len(metrics))}
+
+	allocatable := map[string]resourceUsage{}
+	var nodes []k8sNodeAllocatable
+	if err := s.getKubernetesList(environmentId, "/api/v1/nodes", nil, &nodes); err != nil {
+		report.Warning = fmt.Sprintf("percentages of allocatable capacity are unavailable: failed to list nodes: %v", err)
+	}
+	for _, node := range nodes {
+		allocatable[node.Metadata.Name] = newResourceUsage(node.Status.Allocatable)
+	}
+
+	for _, m := range metrics {
+		top := nodeTop{Name: m.Metadata.Name, resourceUsage: newResourceUsage(m.Usage)}
+		if capacity, ok := allocatable[m.Metadata.Name]; ok {
+			top.CPUPercent = percentOf(top.CPUMillicores, capacity.CPUMillicores)
+			top.MemoryPercent = percentOf(top.MemoryBytes, capacity.MemoryBytes)
+		}
+		report.Nodes = append(report.Nodes, top)
+	}
+
+	sort.SliceStable(report.Nodes, func(i, j int) bool {
+		return usageLess(report.Nodes[j].resourceUsage, report.Nodes[i].resourceUsage, sortBy)
+	})
+	return report, nil
+}
+
+// topPods returns the pods of a namespace, or of every namespace when namespace is
+// empty, with the highest usage first.
+func (s *PortainerMCPServer) topPods(environmentId int, namespace, sortBy string, limit int, containers bool) (podTopReport, error) {
+	apiPath := metricsAPIPath + "/pods"
+	if namespace != "" {
+		apiPath = fmt.Sprintf("%s/namespaces/%s/pods", metricsAPIPath, url.PathEscape(namespace))
+	}
+
+	var metrics []k8sPodMetrics
+	if err := s.getKubernetesList(environmentId, apiPath, nil, &metrics); err != nil {
+		return podTopReport{}, metricsError(err)
+	}
+
+	report := podTopReport{SortBy: sortBy, TotalPods: len(metrics), Pods: []podTop{}}
+	pods := make([]podTop, 0, len(metrics))
+	var totalCPU, totalMemory int64
+	for _, m := range metrics {
+		pod := podTop{Namespace: m.Metadata.Namespace, Name: m.Metadata.Name}
+		var cpu, memory int64
+		for _, c := range m.Containers {
+			usage := newResourceUsage(c.Usage)
+			cpu += usage.CPUMillicores
+			memory += usage.MemoryBytes
+			if containers {
+				pod.Containers = append(pod.Containers, containerTop{Name: c.Name, resourceUsage: usage})
+			}
+		}
+		pod.resourceUsage = usageFromValues(cpu, memory)
+		totalCPU += cpu
+		totalMemory += memory
+		pods = append(pods, pod)
+	}
+	report.TotalUsage = usageFromValues(totalCPU, totalMemory)
+
+	sort.SliceStable(pods, func(i, j int) bool {
+		return usageLess(pods[j].resourceUsage, pods[i].resourceUsage, sortBy)
+	})
+	if len(pods) > limit {
+		pods = pods[:limit]
+	}
+	report.Pods = append(report.Pods, pods...)
+	return report, nil
+}
+
+// usageLess reports whether a uses less of the sortBy resource than b.
+func usageLess(a, b resourceUsage, sortBy string) bool {
+	if sortBy == "memory" {
+		return a.MemoryBytes < b.MemoryBytes
+	}
+	return a.CPUMillicores < b.CPUMillicores
+}
+
+// percentOf returns value as a percentage of total rounded to one decimal, or nil when
+// total is unknown.
+func percentOf(value, total int64) *float64 {
+	if total <= 0 {
+		return nil
+	}
+	percent := math.Round(float64(value)*1000/float64(total)) / 10
+	return &percent
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewResourceUsage verifies the parsing and formatting of metrics quantities.
+func TestNewResourceUsage(t *testing.T) {
+	usage := newResourceUsage(k8sUsage{CPU: "123456789n", Memory: "204800Ki"})
+	assert.Equal(t, resourceUsage{CPU: "124m", CPUMillicores: 124, Memory: "200Mi", MemoryBytes: 200 * 1024 * 1024}, usage)
+
+	assert.Equal(t, resourceUsage{CPU: "0", Memory: "0"}, newResourceUsage(k8sUsage{CPU: "invalid"}))
+}
+
+// TestPercentOf verifies the rounding of percentages and unknown totals.
+func TestPercentOf(t *testing.T) {
+	assert.Equal(t, 33.3, *percentOf(1, 3))
+	assert.Equal(t, 100.0, *percentOf(4, 4))
+	assert.Nil(t, percentOf(1, 0))
+}
+
+// TestMetricsError verifies that missing and unavailable metrics APIs are explained.
+func TestMetricsError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "not served",
+			err:      &kubernetesStatusError{statusCode: http.StatusNotFound, body: "404 page not found"},
+			expected: "the metrics.k8s.io API is not served",
+		},
+		{
+			name:     "not ready",
+			err:      &kubernetesStatusError{statusCode: http.StatusServiceUnavailable, body: "service unavailable"},
+			expected: "metrics-server is installed but not ready (service unavailable)",
+		},
+		{
+			name:     "other error",
+			err:      errors.New("connection refused"),
+			expected: "failed to get resource metrics: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, metricsError(tt.err).Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// defaultNamespaceTopLimit is the number of top consumers returned when no limit is given.
+const defaultNamespaceTopLimit = 5
+
+// namespaceWorkloadKinds are the workload kinds counted in a namespace, with the
+// Kubernetes API path of their collection in a namespace.
+var namespaceWorkloadKinds = []struct {
+	name string
+	path string
+}{
+	{name: "deployments", path: "/apis/apps/v1/namespaces/%s/deployments"},
+	{name: "statefulSets", path: "/apis/apps/v1/namespaces/%s/statefulsets"},
+	{name: "daemonSets", path: "/apis/apps/v1/namespaces/%s/daemonsets"},
+	{name: "jobs", path: "/apis/batch/v1/namespaces/%s/jobs"},
+	{name: "cronJobs", path: "/apis/batch/v1/namespaces/%s/cronjobs"},
+}
+
+// k8sContainerResources holds the resource requests and limits of a container.
+type k8sContainerResources struct {
+	Requests k8sUsage `json:"requests"`
+	Limits   k8sUsage `json:"limits"`
+}
+
+// k8sPodResources holds the pod fields used to total the resources of a namespace.
+type k8sPodResources struct {
+	Spec struct {
+		Containers []struct {
+			Resources k8sContainerResources `json:"resources"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+// k8sResourceQuota is an item of a ResourceQuotaList.
+type k8sResourceQuota struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Status   struct {
+		Hard map[string]string `json:"hard"`
+		Used map[string]string `json:"used"`
+	} `json:"status"`
+}
+
+// namespacePods counts the pods of a namespace by phase.
+type namespacePods struct {
+	Total   int            `json:"total"`
+	ByPhase map[string]int `json:"byPhase"`
+}
+
+// quotaResource is the use of a resource limited by a resource quota.
+type quotaResource struct {
+	Resource string   `json:"resource"`
+	Hard     string   `json:"hard"`
+	Used     string   `json:"used"`
+	Percent  *float64 `json:"percent,omitempty"`
+}
+
+// namespaceQuota is a resource quota of a namespace and the use of its resources.
+type namespaceQuota struct {
+	Name      string          `json:"name"`
+	Resources []quotaResource `json:"resources"`
+}
+
+// namespaceUsageReport is the result of HandleGetNamespaceUsage.
+type namespaceUsageReport struct {
+	Namespace string `json:"namespace"`
+	IsSystem  bool   `json:"isSystem"`
+	Owner     string `json:"owner,omitempty"`
+	// Workloads counts the workloads of each kind; kinds that cannot be listed are missing.
+	Workloads map[string]int `json:"workloads"`
+	Pods      namespacePods  `json:"pods"`
+	// Requests and Limits total the resources of the containers of the pods that are
+	// neither succeeded nor failed, as resource quotas count them.
+	Requests resourceUsage `json:"requests"`
+	Limits   resourceUsage `json:"limits"`
+	// ContainersWithoutLimits counts the containers without a CPU or memory limit.
+	ContainersWithoutLimits int              `json:"containersWithoutLimits"`
+	Quotas                  []namespaceQuota `json:"quotas"`
+	// Usage is the current usage of the pods and TopConsumers the pods using the most,
+	// both from metrics-server.
+	Usage        *resourceUsage `json:"usage,omitempty"`
+	TopConsumers []podTop       `json:"topConsumers,omitempty"`
+	// Warnings lists the parts of the summary that could not be gathered.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// namespaceUsage summarizes the workloads, resource requests and limits, quotas and top
+// consumers of a namespace. Resources that cannot be listed are reported as warnings, so
+// that the rest of the summary is still returned.
+func (s *PortainerMCPServer) namespaceUsage(environmentId int, namespace, sortBy string, limit int) (namespaceUsageReport, error) {
+	namespaces, err := s.cli.GetKubernetesNamespaces(environmentId)
+	if err != nil {
+		return namespaceUsageReport{}, fmt.Errorf("failed to get kubernetes namespaces: %w", err)
+	}
+	report := namespaceUsageReport{Namespace: namespace, Workloads: map[string]int{}, Quotas: []namespaceQuota{}}
+	found := false
+	for _, ns := range namespaces {
+		if ns.Name == namespace {
+			report.IsSystem = ns.IsSystem
+			report.Owner = ns.NamespaceOwner
+			found = true
+			break
+		}
+	}
+	if !found {
+		return namespaceUsageReport{}, fmt.Errorf("namespace %q not found in environment %d", namespace, environmentId)
+	}
+	escaped := url.PathEscape(namespace)
+
+	for _, kind := range namespaceWorkloadKinds {
+		var items []json.RawMessage
+		if err := s.getKubernetesList(environmentId, fmt.Sprintf(kind.path, escaped), nil, &items); err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list %s: %v", kind.name, err))
+			continue
+		}
+		report.Workloads[kind.name] = len(items)
+	}
+
+	var pods []k8sPodResources
+	if err := s.getKubernetesList(environmentId, fmt.Sprintf("/api/v1/namespaces/%s/pods", escaped), nil, &pods); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list pods: %v", err))
+	}
+	report.Pods, report.Requests, report.Limits, report.ContainersWithoutLimits = podResources(pods)
+
+	var quotas []k8sResourceQuota
+	if err := s.getKubernetesList(environmentId, fmt.Sprintf("/api/v1/namespaces/%s/resourcequotas", escaped), nil, &quotas); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("failed to list resource quotas: %v", err))
+	}
+	for _, quota := range quotas {
+		report.Quotas = append(report.Quotas, quotaUsage(quota))
+	}
+
+	top, err := s.topPods(environmentId, namespace, sortBy, limit, false)
+	if err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	} else {
+		report.Usage = &top.TotalUsage
+		report.TopConsumers = top.Pods
+	}
+	return report, nil
+}
+
+// podResources counts pods by phase and totals the requests and limits of the containers
+// of the pods that still hold resources.
+func podResources(pods []k8sPodResources) (namespacePods, resourceUsage, resourceUsage, int) {
+	counts := namespacePods{Total: len(pods), ByPhase: map[string]int{}}
+	var requestCPU, requestMemory, limitCPU, limitMemory int64
+	withoutLimits := 0
+	for _, pod := range pods {
+		counts.ByPhase[pod.Status.Phase]++
+		if pod.Status.Phase == "Succeeded" || pod.Status.Phase == "Failed" {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			requests := newResourceUsage(c.Resources.Requests)
+			limits := newResourceUsage(c.Resources.Limits)
+			requestCPU += requests.CPUMillicores
+			requestMemory += requests.MemoryBytes
+			limitCPU += limits.CPUMillicores
+			limitMemory += limits.MemoryBytes
+			if c.Resources.Limits.CPU == "" || c.Resources.Limits.Memory == "" {
+				withoutLimits++
+			}
+		}
+	}
+	return counts, usageFromValues(requestCPU, requestMemory), usageFromValues(limitCPU, limitMemory), withoutLimits
+}
+
+// quotaUsage returns the use of the resources of a quota, sorted by resource name.
+func quotaUsage(quota k8sResourceQuota) namespaceQuota {
+	usage := namespaceQuota{Name: quota.Metadata.Name, Resources: []quotaResource{}}
+	for name, hard := range quota.Status.Hard {
+		used := quota.Status.Used[name]
+		if used == "" {
+			used = "0"
+		}
+		item := quotaResource{Resource: name, Hard: hard, Used: used}
+		hardQuantity, errHard := resource.ParseQuantity(hard)
+		usedQuantity, errUsed := resource.ParseQuantity(used)
+		if errHard == nil && errUsed == nil {
+			item.Percent = percentOf(usedQuantity.MilliValue(), hardQuantity.MilliValue())
+		}
+		usage.Resources = append(usage.Resources, item)
+	}
+	sort.Slice(usage.Resources, func(i, j int) bool {
+		return usage.Resources[i].Resource < usage.Resources[j].Resource
+	})
+	return usage
+}
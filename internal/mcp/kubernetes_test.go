@@ -2,15 +2,21 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/k8sutil"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/proxyroutes"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // TestHandleKubernetesProxy_ParameterValidation verifies the HandleKubernetesProxy_ParameterValidation MCP tool handler.
@@ -72,6 +78,24 @@ func TestHandleKubernetesProxy_ParameterValidation(t *testing.T) {
 			},
 			expectedErrorMsg: "invalid method: INVALID",
 		},
+		{
+			name: "unknown Kubernetes resource",
+			inputParams: map[string]any{
+				"environmentId":     float64(1),
+				"kubernetesAPIPath": "/api/v1/namespaces/default/pod",
+				"method":            "GET",
+			},
+			expectedErrorMsg: `unknown resource "pod" in core API v1`,
+		},
+		{
+			name: "method not allowed for path",
+			inputParams: map[string]any{
+				"environmentId":     float64(1),
+				"kubernetesAPIPath": "/api/v1/pods",
+				"method":            "PATCH",
+			},
+			expectedErrorMsg: "method PATCH is not allowed for Kubernetes API path",
+		},
 		{
 			name: "invalid queryParams type (not an array)",
 			inputParams: map[string]any{
@@ -313,6 +337,14 @@ func TestHandleKubernetesProxyStripped_ParameterValidation(t *testing.T) {
 			},
 			expectedErrorMsg: "kubernetesAPIPath must start with a leading slash",
 		},
+		{
+			name: "unknown API group",
+			inputParams: map[string]any{
+				"environmentId":     float64(1),
+				"kubernetesAPIPath": "/apis/app/v1/deployments",
+			},
+			expectedErrorMsg: `unknown API group "app"`,
+		},
 		{
 			name: "invalid queryParams type (not an array)",
 			inputParams: map[string]any{
@@ -349,6 +381,15 @@ func TestHandleKubernetesProxyStripped_ParameterValidation(t *testing.T) {
 			},
 			expectedErrorMsg: "invalid headers: invalid value: <nil>",
 		},
+		{
+			name: "invalid keep type (not an array)",
+			inputParams: map[string]any{
+				"environmentId":     float64(1),
+				"kubernetesAPIPath": "/api/v1/pods",
+				"keep":              "status",
+			},
+			expectedErrorMsg: "keep must be an array",
+		},
 	}
 
 	for _, tt := range tests {
@@ -371,6 +412,51 @@ func TestHandleKubernetesProxyStripped_ParameterValidation(t *testing.T) {
 	}
 }
 
+// TestHandleKubernetesProxyStripped_ConfiguredFields verifies that the configured
+// strip fields are removed and that the keep parameter overrides them per call.
+func TestHandleKubernetesProxyStripped_ConfiguredFields(t *testing.T) {
+	body := `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"web","annotations":{"a":"b"},"managedFields":[{"manager":"kubectl"}]},"status":{"phase":"Running"}}`
+
+	tests := []struct {
+		name     string
+		keep     any
+		expected string
+	}{
+		{
+			name:     "configured fields removed",
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"managedFields":[{"manager":"kubectl"}],"name":"web"}}`,
+		},
+		{
+			name:     "keep overrides fields",
+			keep:     []any{"status", "metadata.annotations"},
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"annotations":{"a":"b"},"managedFields":[{"manager":"kubectl"}],"name":"web"},"status":{"phase":"Running"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stripper, err := k8sutil.NewStripper([]string{"metadata.annotations", "status"})
+			require.NoError(t, err)
+
+			mockClient := new(MockPortainerClient)
+			mockClient.On("ProxyKubernetesRequest", mock.AnythingOfType("models.KubernetesProxyRequestOptions")).
+				Return(createMockHttpResponse(http.StatusOK, body), nil)
+
+			server := &PortainerMCPServer{cli: mockClient, k8sStripper: stripper}
+			params := map[string]any{"environmentId": float64(1), "kubernetesAPIPath": "/api/v1/namespaces/default/pods/web"}
+			if tt.keep != nil {
+				params["keep"] = tt.keep
+			}
+
+			result, err := server.HandleKubernetesProxyStripped()(context.Background(), CreateMCPRequest(params))
+			require.NoError(t, err)
+			require.False(t, result.IsError)
+			assert.JSONEq(t, tt.expected, result.Content[0].(mcp.TextContent).Text)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
 // TestHandleKubernetesProxyStripped_ClientInteraction verifies the HandleKubernetesProxyStripped_ClientInteraction MCP tool handler.
 func TestHandleKubernetesProxyStripped_ClientInteraction(t *testing.T) {
 	type testCase struct {
@@ -857,3 +943,572 @@ _, err := handler(context.Background(), request)
 assert.NoError(t, err)
 assert.True(t, tc.closed, "response body should be closed after handler returns")
 }
+
+// TestHandleKubernetesProxy_ProxyRules verifies that requests outside the proxy allow rules never reach Portainer.
+func TestHandleKubernetesProxy_ProxyRules(t *testing.T) {
+	policy, err := proxyroutes.NewPolicy(proxyroutes.PolicyConfig{
+		Kubernetes: proxyroutes.Rules{
+			Allow: []string{"GET /**"},
+			Deny:  []string{"/api/v1/namespaces/*/secrets/**"},
+		},
+	})
+	require.NoError(t, err)
+
+	mockClient := new(MockPortainerClient)
+	server := &PortainerMCPServer{cli: mockClient, proxyPolicy: policy}
+
+	result, err := server.HandleKubernetesProxy()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId":     float64(1),
+		"kubernetesAPIPath": "/api/v1/namespaces/default/pods/web",
+		"method":            "DELETE",
+	}))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "is not permitted by the proxy allow rules")
+
+	result, err = server.HandleKubernetesProxyStripped()(context.Background(), CreateMCPRequest(map[string]any{
+		"environmentId":     float64(1),
+		"kubernetesAPIPath": "/api/v1/namespaces/default/secrets",
+	}))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "denied by proxy rule")
+
+	mockClient.AssertNotCalled(t, "ProxyKubernetesRequest", mock.Anything)
+}
+
+// TestHandleDescribeKubernetesResource verifies that the resource and its events are
+// fetched through the proxy and summarized.
+func TestHandleDescribeKubernetesResource(t *testing.T) {
+	t.Run("describes a resource with its events", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/web/deployments/api", http.StatusOK,
+			`{"metadata":{"name":"api","namespace":"web","uid":"abc"},"spec":{"replicas":2},"status":{"readyReplicas":1}}`)
+		mockClient.On("ProxyKubernetesRequest", mock.MatchedBy(func(opts models.KubernetesProxyRequestOptions) bool {
+			return opts.Path == "/api/v1/namespaces/web/events" && opts.QueryParams["fieldSelector"] == "involvedObject.uid=abc"
+		})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+			`{"items":[{"type":"Normal","reason":"ScalingReplicaSet","message":"Scaled up","count":1,"lastTimestamp":"2024-05-01T10:00:00Z"}]}`))}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "kind": "deploy", "name": "api", "namespace": "web"})
+		result, err := server.HandleDescribeKubernetesResource()(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		var desc struct {
+			k8sutil.Description
+			EventsError string `json:"eventsError"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &desc))
+		assert.Equal(t, "Deployment", desc.Kind)
+		assert.Equal(t, float64(2), desc.Details["replicas"].(map[string]any)["desired"])
+		require.Len(t, desc.Events, 1)
+		assert.Equal(t, "ScalingReplicaSet", desc.Events[0].Reason)
+		assert.Empty(t, desc.EventsError)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("events error is reported", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/api/v1/nodes/worker-1", http.StatusOK, `{"metadata":{"name":"worker-1"}}`)
+		mockKubernetesList(mockClient, "/api/v1/events", http.StatusForbidden, "forbidden")
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "kind": "node", "name": "worker-1"})
+		result, err := server.HandleDescribeKubernetesResource()(context.Background(), request)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `"eventsError":"failed to list events: unexpected status 403: forbidden"`)
+	})
+
+	t.Run("resource not found", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/api/v1/namespaces/default/pods/missing", http.StatusNotFound, `{"reason":"NotFound"}`)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "kind": "pod", "name": "missing"})
+		result, err := server.HandleDescribeKubernetesResource()(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "failed to get Pod missing")
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "kind": "widget", "name": "x"})
+		result, err := server.HandleDescribeKubernetesResource()(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "supported kinds: Pod, Service")
+	})
+
+	t.Run("proxy rules apply", func(t *testing.T) {
+		policy, err := proxyroutes.NewPolicy(proxyroutes.PolicyConfig{
+			Kubernetes: proxyroutes.Rules{
+				Allow: []string{"GET /**"},
+				Deny:  []string{"/api/v1/namespaces/*/secrets/**"},
+			},
+		})
+		require.NoError(t, err)
+		mockClient := &MockPortainerClient{}
+		server := &PortainerMCPServer{cli: mockClient, proxyPolicy: policy}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "kind": "secret", "name": "db"})
+		result, err := server.HandleDescribeKubernetesResource()(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		mockClient.AssertNotCalled(t, "ProxyKubernetesRequest", mock.Anything)
+	})
+}
+
+// TestHandleListKubernetesAPIResources verifies the discovery of API groups and resources.
+func TestHandleListKubernetesAPIResources(t *testing.T) {
+	groups := `{"groups":[
+		{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}},
+		{"name":"metrics.k8s.io","versions":[{"groupVersion":"metrics.k8s.io/v1beta1","version":"v1beta1"}],"preferredVersion":{"groupVersion":"metrics.k8s.io/v1beta1","version":"v1beta1"}},
+		{"name":"cert-manager.io","versions":[{"groupVersion":"cert-manager.io/v1","version":"v1"},{"groupVersion":"cert-manager.io/v1alpha2","version":"v1alpha2"}],"preferredVersion":{"groupVersion":"cert-manager.io/v1","version":"v1"}}
+	]}`
+	core := `{"groupVersion":"v1","resources":[{"name":"pods","kind":"Pod","namespaced":true,"verbs":["get","list"]}]}`
+	apps := `{"groupVersion":"apps/v1","resources":[{"name":"deployments","kind":"Deployment","namespaced":true,"verbs":["get","list"]}]}`
+	certManager := `{"groupVersion":"cert-manager.io/v1","resources":[{"name":"certificates","kind":"Certificate","namespaced":true,"verbs":["get","list"]}]}`
+
+	call := func(t *testing.T, server *PortainerMCPServer, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := server.HandleListKubernetesAPIResources()(context.Background(), CreateMCPRequest(args))
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("all groups", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/api/v1", http.StatusOK, core)
+		mockKubernetesList(mockClient, "/apis", http.StatusOK, groups)
+		mockKubernetesList(mockClient, "/apis/apps/v1", http.StatusOK, apps)
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1", http.StatusServiceUnavailable, "service unavailable")
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1", http.StatusOK, certManager)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1)})
+		require.False(t, result.IsError)
+
+		var report kubernetesAPIResourcesReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		require.Len(t, report.Groups, 4)
+		assert.Equal(t, kubernetesAPIGroup{Name: "cert-manager.io", PreferredVersion: "v1", Versions: []string{"v1", "v1alpha2"}}, report.Groups[3])
+
+		var kinds []string
+		for _, r := range report.Resources {
+			kinds = append(kinds, r.Kind)
+		}
+		assert.Equal(t, []string{"Pod", "Deployment", "Certificate"}, kinds)
+		assert.Equal(t, []string{"metrics.k8s.io/v1beta1: unexpected status 503: service unavailable"}, report.Errors)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("single group", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis", http.StatusOK, groups)
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1", http.StatusOK, certManager)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "group": "cert-manager.io"})
+		require.False(t, result.IsError)
+
+		var report kubernetesAPIResourcesReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		require.Len(t, report.Resources, 1)
+		assert.Equal(t, "/apis/cert-manager.io/v1/namespaces/{namespace}/certificates", report.Resources[0].Path)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("core group only", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/api/v1", http.StatusOK, core)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "group": "core"})
+		require.False(t, result.IsError)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis", http.StatusOK, groups)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "group": "example.com"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `API group "example.com" is not served`)
+	})
+
+	t.Run("group list error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis", http.StatusForbidden, "forbidden")
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1)})
+		assert.True(t, result.IsError)
+	})
+
+	t.Run("invalid environmentId", func(t *testing.T) {
+		result := call(t, &PortainerMCPServer{cli: &MockPortainerClient{}}, map[string]any{"environmentId": float64(0)})
+		assert.True(t, result.IsError)
+	})
+}
+
+// TestHandleTopKubernetesNodes verifies the node usage ranking, the percentages of
+// allocatable capacity and the errors when metrics are not available.
+func TestHandleTopKubernetesNodes(t *testing.T) {
+	metrics := `{"items":[
+		{"metadata":{"name":"worker-1"},"usage":{"cpu":"250m","memory":"1Gi"}},
+		{"metadata":{"name":"worker-2"},"usage":{"cpu":"1500000000n","memory":"512Mi"}}
+	]}`
+	nodes := `{"items":[
+		{"metadata":{"name":"worker-1"},"status":{"allocatable":{"cpu":"2","memory":"4Gi"}}},
+		{"metadata":{"name":"worker-2"},"status":{"allocatable":{"cpu":"2","memory":"4Gi"}}}
+	]}`
+
+	call := func(t *testing.T, server *PortainerMCPServer, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := server.HandleTopKubernetesNodes()(context.Background(), CreateMCPRequest(args))
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("sorted by cpu with percentages", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/nodes", http.StatusOK, metrics)
+		mockKubernetesList(mockClient, "/api/v1/nodes", http.StatusOK, nodes)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1)})
+		require.False(t, result.IsError)
+
+		var report nodeTopReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		require.Len(t, report.Nodes, 2)
+		assert.Equal(t, "cpu", report.SortBy)
+		assert.Equal(t, "worker-2", report.Nodes[0].Name)
+		assert.Equal(t, "1500m", report.Nodes[0].CPU)
+		assert.Equal(t, 75.0, *report.Nodes[0].CPUPercent)
+		assert.Equal(t, 25.0, *report.Nodes[1].MemoryPercent)
+		assert.Empty(t, report.Warning)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("sorted by memory without node access", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/nodes", http.StatusOK, metrics)
+		mockKubernetesList(mockClient, "/api/v1/nodes", http.StatusForbidden, "forbidden")
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "sortBy": "memory"})
+		require.False(t, result.IsError)
+
+		var report nodeTopReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		require.Len(t, report.Nodes, 2)
+		assert.Equal(t, "worker-1", report.Nodes[0].Name)
+		assert.Nil(t, report.Nodes[0].CPUPercent)
+		assert.Contains(t, report.Warning, "percentages of allocatable capacity are unavailable")
+	})
+
+	t.Run("metrics API not served", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/nodes", http.StatusNotFound, "404 page not found")
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1)})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "install metrics-server")
+	})
+
+	t.Run("invalid sortBy", func(t *testing.T) {
+		result := call(t, &PortainerMCPServer{cli: &MockPortainerClient{}}, map[string]any{"environmentId": float64(1), "sortBy": "disk"})
+		assert.True(t, result.IsError)
+	})
+
+	t.Run("invalid environmentId", func(t *testing.T) {
+		result := call(t, &PortainerMCPServer{cli: &MockPortainerClient{}}, map[string]any{"environmentId": float64(-1)})
+		assert.True(t, result.IsError)
+	})
+}
+
+// TestHandleTopKubernetesPods verifies the pod usage ranking, the limit, the container
+// breakdown and the totals.
+func TestHandleTopKubernetesPods(t *testing.T) {
+	metrics := `{"items":[
+		{"metadata":{"name":"api","namespace":"web"},"containers":[
+			{"name":"app","usage":{"cpu":"100m","memory":"200Mi"}},
+			{"name":"sidecar","usage":{"cpu":"50m","memory":"56Mi"}}]},
+		{"metadata":{"name":"worker","namespace":"web"},"containers":[
+			{"name":"app","usage":{"cpu":"400m","memory":"64Mi"}}]},
+		{"metadata":{"name":"cache","namespace":"web"},"containers":[
+			{"name":"redis","usage":{"cpu":"10m","memory":"1Gi"}}]}
+	]}`
+
+	call := func(t *testing.T, server *PortainerMCPServer, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := server.HandleTopKubernetesPods()(context.Background(), CreateMCPRequest(args))
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("namespace sorted by cpu with containers", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/namespaces/web/pods", http.StatusOK, metrics)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{
+			"environmentId": float64(1), "namespace": "web", "limit": float64(2), "containers": true,
+		})
+		require.False(t, result.IsError)
+
+		var report podTopReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Equal(t, 3, report.TotalPods)
+		assert.Equal(t, int64(560), report.TotalUsage.CPUMillicores)
+		require.Len(t, report.Pods, 2)
+		assert.Equal(t, "worker", report.Pods[0].Name)
+		assert.Equal(t, "api", report.Pods[1].Name)
+		assert.Equal(t, "150m", report.Pods[1].CPU)
+		assert.Equal(t, "256Mi", report.Pods[1].Memory)
+		assert.Len(t, report.Pods[1].Containers, 2)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("all namespaces sorted by memory", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/pods", http.StatusOK, metrics)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "sortBy": "memory"})
+		require.False(t, result.IsError)
+
+		var report podTopReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		require.Len(t, report.Pods, 3)
+		assert.Equal(t, "cache", report.Pods[0].Name)
+		assert.Empty(t, report.Pods[0].Containers)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("metrics-server not ready", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/pods", http.StatusServiceUnavailable, "service unavailable")
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1)})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "metrics-server is installed but not ready")
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		result := call(t, &PortainerMCPServer{cli: &MockPortainerClient{}}, map[string]any{"environmentId": float64(1), "limit": float64(500)})
+		assert.True(t, result.IsError)
+	})
+}
+
+// TestHandleGetNamespaceUsage verifies the workload counts, the resource totals compared
+// with the quotas, the top consumers and the warnings of the namespace summary.
+func TestHandleGetNamespaceUsage(t *testing.T) {
+	namespaces := []models.KubernetesNamespace{{Name: "default", IsDefault: true}, {Name: "web", NamespaceOwner: "alice"}}
+	pods := `{"items":[
+		{"spec":{"containers":[
+			{"resources":{"requests":{"cpu":"100m","memory":"128Mi"},"limits":{"cpu":"500m","memory":"256Mi"}}},
+			{"resources":{"requests":{"cpu":"50m"}}}]},"status":{"phase":"Running"}},
+		{"spec":{"containers":[
+			{"resources":{"requests":{"cpu":"250m","memory":"128Mi"},"limits":{"cpu":"1","memory":"512Mi"}}}]},"status":{"phase":"Pending"}},
+		{"spec":{"containers":[
+			{"resources":{"requests":{"cpu":"2","memory":"1Gi"}}}]},"status":{"phase":"Succeeded"}}
+	]}`
+	quotas := `{"items":[{"metadata":{"name":"compute"},"status":{
+		"hard":{"requests.cpu":"1","limits.memory":"1Gi","pods":"10"},
+		"used":{"requests.cpu":"400m","limits.memory":"768Mi"}}}]}`
+	metrics := `{"items":[
+		{"metadata":{"name":"api","namespace":"web"},"containers":[{"name":"app","usage":{"cpu":"80m","memory":"100Mi"}}]},
+		{"metadata":{"name":"worker","namespace":"web"},"containers":[{"name":"app","usage":{"cpu":"200m","memory":"50Mi"}}]}
+	]}`
+
+	call := func(t *testing.T, server *PortainerMCPServer, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := server.HandleGetNamespaceUsage()(context.Background(), CreateMCPRequest(args))
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("full summary", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetKubernetesNamespaces", 1).Return(namespaces, nil)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/web/deployments", http.StatusOK, `{"items":[{},{}]}`)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/web/statefulsets", http.StatusOK, `{"items":[{}]}`)
+		mockKubernetesList(mockClient, "/apis/apps/v1/namespaces/web/daemonsets", http.StatusOK, `{"items":[]}`)
+		mockKubernetesList(mockClient, "/apis/batch/v1/namespaces/web/jobs", http.StatusOK, `{"items":[{}]}`)
+		mockKubernetesList(mockClient, "/apis/batch/v1/namespaces/web/cronjobs", http.StatusForbidden, "forbidden")
+		mockKubernetesList(mockClient, "/api/v1/namespaces/web/pods", http.StatusOK, pods)
+		mockKubernetesList(mockClient, "/api/v1/namespaces/web/resourcequotas", http.StatusOK, quotas)
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/namespaces/web/pods", http.StatusOK, metrics)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "namespace": "web", "limit": float64(1)})
+		require.False(t, result.IsError)
+
+		var report namespaceUsageReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Equal(t, "alice", report.Owner)
+		assert.Equal(t, map[string]int{"deployments": 2, "statefulSets": 1, "daemonSets": 0, "jobs": 1}, report.Workloads)
+		assert.Equal(t, namespacePods{Total: 3, ByPhase: map[string]int{"Running": 1, "Pending": 1, "Succeeded": 1}}, report.Pods)
+		assert.Equal(t, "400m", report.Requests.CPU)
+		assert.Equal(t, "256Mi", report.Requests.Memory)
+		assert.Equal(t, "1500m", report.Limits.CPU)
+		assert.Equal(t, "768Mi", report.Limits.Memory)
+		assert.Equal(t, 1, report.ContainersWithoutLimits)
+
+		require.Len(t, report.Quotas, 1)
+		assert.Equal(t, "compute", report.Quotas[0].Name)
+		require.Len(t, report.Quotas[0].Resources, 3)
+		assert.Equal(t, "limits.memory", report.Quotas[0].Resources[0].Resource)
+		assert.Equal(t, 75.0, *report.Quotas[0].Resources[0].Percent)
+		assert.Equal(t, quotaResource{Resource: "pods", Hard: "10", Used: "0", Percent: new(float64)}, report.Quotas[0].Resources[1])
+		assert.Equal(t, 40.0, *report.Quotas[0].Resources[2].Percent)
+
+		require.NotNil(t, report.Usage)
+		assert.Equal(t, "280m", report.Usage.CPU)
+		require.Len(t, report.TopConsumers, 1)
+		assert.Equal(t, "worker", report.TopConsumers[0].Name)
+		assert.Equal(t, []string{"failed to list cronJobs: unexpected status 403: forbidden"}, report.Warnings)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("without metrics-server", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetKubernetesNamespaces", 1).Return(namespaces, nil)
+		for _, kind := range namespaceWorkloadKinds {
+			mockKubernetesList(mockClient, fmt.Sprintf(kind.path, "web"), http.StatusOK, `{"items":[]}`)
+		}
+		mockKubernetesList(mockClient, "/api/v1/namespaces/web/pods", http.StatusOK, `{"items":[]}`)
+		mockKubernetesList(mockClient, "/api/v1/namespaces/web/resourcequotas", http.StatusOK, `{"items":[]}`)
+		mockKubernetesList(mockClient, "/apis/metrics.k8s.io/v1beta1/namespaces/web/pods", http.StatusNotFound, "404 page not found")
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "namespace": "web"})
+		require.False(t, result.IsError)
+
+		var report namespaceUsageReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Nil(t, report.Usage)
+		assert.Empty(t, report.Quotas)
+		require.Len(t, report.Warnings, 1)
+		assert.Contains(t, report.Warnings[0], "install metrics-server")
+	})
+
+	t.Run("unknown namespace", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetKubernetesNamespaces", 1).Return(namespaces, nil)
+
+		result := call(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(1), "namespace": "shop"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `namespace "shop" not found in environment 1`)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, args := range []map[string]any{
+			{"environmentId": float64(1)},
+			{"environmentId": float64(1), "namespace": " "},
+			{"environmentId": float64(1), "namespace": "web", "limit": float64(-1)},
+			{"environmentId": float64(0), "namespace": "web"},
+		} {
+			result := call(t, &PortainerMCPServer{cli: &MockPortainerClient{}}, args)
+			assert.True(t, result.IsError, args)
+		}
+	})
+}
+
+// TestHandleListCustomResources verifies the resolution of custom resource kinds from the
+// discovery endpoints and the listing of their items.
+func TestHandleListCustomResources(t *testing.T) {
+	group := `{"name":"cert-manager.io","versions":[{"groupVersion":"cert-manager.io/v1","version":"v1"}],
+		"preferredVersion":{"groupVersion":"cert-manager.io/v1","version":"v1"}}`
+	resources := `{"groupVersion":"cert-manager.io/v1","resources":[
+		{"name":"certificates","singularName":"certificate","kind":"Certificate","namespaced":true,"verbs":["get","list"],"shortNames":["cert"]},
+		{"name":"certificates/status","kind":"Certificate","namespaced":true,"verbs":["get"]},
+		{"name":"clusterissuers","singularName":"clusterissuer","kind":"ClusterIssuer","namespaced":false,"verbs":["get","list"]}]}`
+	certificates := `{"metadata":{"continue":"next-page"},"items":[
+		{"metadata":{"name":"web","namespace":"shop","managedFields":[{"manager":"cert-manager"}]},"spec":{"secretName":"web-tls"}}]}`
+
+	call := func(t *testing.T, mockClient *MockPortainerClient, args map[string]any) (*mcp.CallToolResult, string) {
+		t.Helper()
+		params := map[string]any{"environmentId": float64(1), "group": "cert-manager.io"}
+		for k, v := range args {
+			params[k] = v
+		}
+		result, err := (&PortainerMCPServer{cli: mockClient}).HandleListCustomResources()(context.Background(), CreateMCPRequest(params))
+		require.NoError(t, err)
+		return result, result.Content[0].(mcp.TextContent).Text
+	}
+
+	t.Run("short name in a namespace with the preferred version", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/cert-manager.io", http.StatusOK, group)
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1", http.StatusOK, resources)
+		mockClient.On("ProxyKubernetesRequest", mock.MatchedBy(func(opts models.KubernetesProxyRequestOptions) bool {
+			return opts.Path == "/apis/cert-manager.io/v1/namespaces/shop/certificates" &&
+				opts.QueryParams["limit"] == "100" && opts.QueryParams["labelSelector"] == "app=web"
+		})).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(certificates))}, nil).Once()
+
+		result, text := call(t, mockClient, map[string]any{"kind": "cert", "namespace": "shop", "labelSelector": "app=web"})
+		require.False(t, result.IsError, text)
+
+		var report customResourcesReport
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.Equal(t, "Certificate", report.Resource.Kind)
+		assert.Equal(t, "certificates", report.Resource.Resource)
+		assert.Equal(t, "v1", report.Resource.Version)
+		assert.Equal(t, []string{"status"}, report.Resource.Subresources)
+		assert.Equal(t, 1, report.Count)
+		assert.Equal(t, "next-page", report.Continue)
+		assert.Equal(t, map[string]any{"name": "web", "namespace": "shop"}, report.Items[0]["metadata"])
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("cluster-scoped kind with a version", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1", http.StatusOK, resources)
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1/clusterissuers", http.StatusOK, `{"metadata":{},"items":[]}`)
+
+		result, text := call(t, mockClient, map[string]any{"kind": "ClusterIssuer", "version": "v1"})
+		require.False(t, result.IsError, text)
+
+		var report customResourcesReport
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.False(t, report.Resource.Namespaced)
+		assert.Empty(t, report.Items)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("namespace of a cluster-scoped kind", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1", http.StatusOK, resources)
+
+		result, text := call(t, mockClient, map[string]any{"kind": "clusterissuers", "version": "v1", "namespace": "shop"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "ClusterIssuer is cluster-scoped")
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/cert-manager.io", http.StatusOK, group)
+		mockKubernetesList(mockClient, "/apis/cert-manager.io/v1", http.StatusOK, resources)
+
+		result, text := call(t, mockClient, map[string]any{"kind": "Issuer"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, `kind "Issuer" is not served by cert-manager.io/v1; served kinds: Certificate, ClusterIssuer`)
+	})
+
+	t.Run("group not served", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockKubernetesList(mockClient, "/apis/cert-manager.io", http.StatusNotFound, "404 page not found")
+
+		result, text := call(t, mockClient, map[string]any{"kind": "Certificate"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "API cert-manager.io is not served by the environment")
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, args := range []map[string]any{
+			{"group": "core", "kind": "Pod"},
+			{"group": "cert-manager.io/v1", "kind": "Certificate"},
+			{"kind": " "},
+			{"kind": "Certificate", "limit": float64(501)},
+		} {
+			result, _ := call(t, &MockPortainerClient{}, args)
+			assert.True(t, result.IsError, args)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	defaultBenchmarkIterations = 5
+	maxBenchmarkIterations     = 20
+)
+
+// latencyProbe is a Portainer API endpoint measured by benchmarkLatency.
+type latencyProbe struct {
+	name string
+	path string
+}
+
+// latencyProbes are the endpoints measured by default: the lists the tools read most, from
+// small responses (system status) to responses that grow with the instance (environments).
+var latencyProbes = []latencyProbe{
+	{name: "system_status", path: "/system/status"},
+	{name: "environments", path: "/endpoints"},
+	{name: "environment_groups", path: "/endpoint_groups"},
+	{name: "tags", path: "/tags"},
+	{name: "stacks", path: "/stacks"},
+	{name: "users", path: "/users"},
+	{name: "teams", path: "/teams"},
+	{name: "registries", path: "/registries"},
+	{name: "settings", path: "/settings"},
+}
+
+// environmentProbe is added to the probes when an environment is given.
+const environmentProbe = "environment"
+
+// latencyReport is the result of HandleBenchmarkLatency.
+type latencyReport struct {
+	Iterations int            `json:"iterations"`
+	Probes     []probeLatency `json:"probes"`
+	// Slowest is the probe with the highest p95 latency.
+	Slowest string  `json:"slowest,omitempty"`
+	TotalMs float64 `json:"totalMs"`
+}
+
+// probeLatency holds the latency statistics of one endpoint, in milliseconds, over its
+// successful requests.
+type probeLatency struct {
+	Name      string  `json:"name"`
+	Path      string  `json:"path"`
+	Samples   int     `json:"samples"`
+	Errors    int     `json:"errors,omitempty"`
+	LastError string  `json:"lastError,omitempty"`
+	MinMs     float64 `json:"minMs"`
+	P50Ms     float64 `json:"p50Ms"`
+	P95Ms     float64 `json:"p95Ms"`
+	MaxMs     float64 `json:"maxMs"`
+	// Bytes is the size of the last successful response body.
+	Bytes int64 `json:"bytes"`
+}
+
+// HandleBenchmarkLatency returns an MCP tool handler that measures the round-trip latency
+// and the response sizes of a representative set of Portainer API endpoints.
+func (s *PortainerMCPServer) HandleBenchmarkLatency() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		iterations, err := parser.GetInt("iterations", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid iterations parameter", err), nil
+		}
+		if iterations == 0 {
+			iterations = defaultBenchmarkIterations
+		}
+		if iterations < 0 || iterations > maxBenchmarkIterations {
+			return mcp.NewToolResultError(fmt.Sprintf("iterations must be between 1 and %d, got %d", maxBenchmarkIterations, iterations)), nil
+		}
+
+		environmentId, err := parser.GetInt("environmentId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if _, ok := request.GetArguments()["environmentId"]; ok {
+			if err := validatePositiveID("environmentId", environmentId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		available := slices.Clone(latencyProbes)
+		if environmentId != 0 {
+			available = append(available, latencyProbe{name: environmentProbe, path: fmt.Sprintf("/endpoints/%d", environmentId)})
+		}
+
+		names, err := parser.GetArrayOfStrings("probes", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid probes parameter", err), nil
+		}
+		probes := available
+		if len(names) > 0 {
+			probes = nil
+			for _, probe := range available {
+				if slices.Contains(names, probe.name) {
+					probes = append(probes, probe)
+				}
+			}
+			for _, name := range names {
+				if !slices.ContainsFunc(probes, func(p latencyProbe) bool { return p.name == name }) {
+					if name == environmentProbe {
+						return mcp.NewToolResultError("the environment probe requires environmentId"), nil
+					}
+					return mcp.NewToolResultError(fmt.Sprintf("unknown probe %q: expected one of %s", name, strings.Join(latencyProbeNames(available), ", "))), nil
+				}
+			}
+		}
+
+		report := latencyReport{Iterations: iterations, Probes: make([]probeLatency, 0, len(probes))}
+		start := time.Now()
+		for _, probe := range probes {
+			if err := ctx.Err(); err != nil {
+				return mcp.NewToolResultErrorFromErr("benchmark cancelled", err), nil
+			}
+			report.Probes = append(report.Probes, s.measureProbe(probe, iterations))
+		}
+		report.TotalMs = milliseconds(time.Since(start))
+
+		slowest := -1.0
+		for _, probe := range report.Probes {
+			if probe.Samples > 0 && probe.P95Ms > slowest {
+				slowest = probe.P95Ms
+				report.Slowest = probe.Name
+			}
+		}
+
+		return jsonResult(report, "failed to marshal latency report")
+	}
+}
+
+// measureProbe requests an endpoint sequentially, so that the requests do not compete with
+// each other, and computes the latency statistics of the successful ones.
+func (s *PortainerMCPServer) measureProbe(probe latencyProbe, iterations int) probeLatency {
+	result := probeLatency{Name: probe.name, Path: probe.path}
+
+	var durations []time.Duration
+	for range iterations {
+		start := time.Now()
+		size, err := s.cli.ProbeAPI(probe.path)
+		elapsed := time.Since(start)
+		if err != nil {
+			result.Errors++
+			result.LastError = err.Error()
+			continue
+		}
+		durations = append(durations, elapsed)
+		result.Bytes = size
+	}
+
+	result.Samples = len(durations)
+	if len(durations) == 0 {
+		return result
+	}
+	slices.Sort(durations)
+	result.MinMs = milliseconds(durations[0])
+	result.P50Ms = milliseconds(percentile(durations, 50))
+	result.P95Ms = milliseconds(percentile(durations, 95))
+	result.MaxMs = milliseconds(durations[len(durations)-1])
+	return result
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// milliseconds converts a duration to milliseconds, rounded to a tenth.
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*10) / 10
+}
+
+// latencyProbeNames returns the names of probes.
+func latencyProbeNames(probes []latencyProbe) []string {
+	names := make([]string, len(probes))
+	for i, probe := range probes {
+		names[i] = probe.name
+	}
+	return names
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleBenchmarkLatency verifies the selection of the probes, the number of requests
+// and the accounting of response sizes and errors. Latencies depend on the machine and are
+// only checked for consistency.
+func TestHandleBenchmarkLatency(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputParams   map[string]any
+		setupMock     func(*MockPortainerClient)
+		expectError   string
+		expectedProbe []probeLatency
+	}{
+		{
+			name:        "selected probes",
+			inputParams: map[string]any{"iterations": float64(3), "probes": []any{"settings", "environments"}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("ProbeAPI", "/endpoints").Return(int64(52480), nil).Times(3)
+				m.On("ProbeAPI", "/settings").Return(int64(0), fmt.Errorf("unexpected status 403")).Times(3)
+			},
+			expectedProbe: []probeLatency{
+				{Name: "environments", Path: "/endpoints", Samples: 3, Bytes: 52480},
+				{Name: "settings", Path: "/settings", Errors: 3, LastError: "unexpected status 403"},
+			},
+		},
+		{
+			name:        "environment probe",
+			inputParams: map[string]any{"iterations": float64(1), "probes": []any{"environment"}, "environmentId": float64(4)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("ProbeAPI", "/endpoints/4").Return(int64(1024), nil).Once()
+			},
+			expectedProbe: []probeLatency{{Name: "environment", Path: "/endpoints/4", Samples: 1, Bytes: 1024}},
+		},
+		{
+			name:        "environment probe without environment",
+			inputParams: map[string]any{"probes": []any{"environment"}},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "the environment probe requires environmentId",
+		},
+		{
+			name:        "unknown probe",
+			inputParams: map[string]any{"probes": []any{"containers"}},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: `unknown probe "containers"`,
+		},
+		{
+			name:        "too many iterations",
+			inputParams: map[string]any{"iterations": float64(50)},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "iterations must be between 1 and 20",
+		},
+		{
+			name:        "invalid environment",
+			inputParams: map[string]any{"environmentId": float64(-1)},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "environmentId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setupMock(mockClient)
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleBenchmarkLatency()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var report latencyReport
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			require.Len(t, report.Probes, len(tt.expectedProbe))
+			for i, probe := range report.Probes {
+				assert.LessOrEqual(t, probe.MinMs, probe.P50Ms)
+				assert.LessOrEqual(t, probe.P50Ms, probe.P95Ms)
+				assert.LessOrEqual(t, probe.P95Ms, probe.MaxMs)
+				probe.MinMs, probe.P50Ms, probe.P95Ms, probe.MaxMs = 0, 0, 0, 0
+				assert.Equal(t, tt.expectedProbe[i], probe)
+			}
+			assert.Equal(t, tt.expectedProbe[0].Name, report.Slowest)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestPercentile verifies the nearest-rank percentiles used by benchmarkLatency.
+func TestPercentile(t *testing.T) {
+	durations := make([]time.Duration, 20)
+	for i := range durations {
+		durations[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 10*time.Millisecond, percentile(durations, 50))
+	assert.Equal(t, 19*time.Millisecond, percentile(durations, 95))
+	assert.Equal(t, 20*time.Millisecond, percentile(durations, 100))
+	assert.Equal(t, 7*time.Millisecond, percentile([]time.Duration{7 * time.Millisecond}, 95))
+	assert.Equal(t, 1.5, milliseconds(1500*time.Microsecond))
+}
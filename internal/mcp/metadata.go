@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// _meta keys of the execution metadata attached to tool results
+const (
+	metaPortainerVersion = "portainerVersion"
+	metaEnvironmentID    = "environmentId"
+	metaEnvironmentName  = "environmentName"
+	metaDurationMs       = "durationMs"
+	metaCacheHit         = "cacheHit"
+	metaTruncated        = "truncated"
+)
+
+// callMetadata collects the execution metadata reported by a handler during a call
+type callMetadata struct {
+	mu        sync.Mutex
+	cacheHit  bool
+	truncated bool
+}
+
+type callMetadataKey struct{}
+
+// markTruncated reports that the result of the current call was truncated. It does
+// nothing outside a call handled by the metadata middleware.
+func markTruncated(ctx context.Context) {
+	if m, ok := ctx.Value(callMetadataKey{}).(*callMetadata); ok {
+		m.mu.Lock()
+		m.truncated = true
+		m.mu.Unlock()
+	}
+}
+
+// markCacheHit reports that the result of the current call was served from a cache. It
+// does nothing outside a call handled by the metadata middleware.
+func markCacheHit(ctx context.Context) {
+	if m, ok := ctx.Value(callMetadataKey{}).(*callMetadata); ok {
+		m.mu.Lock()
+		m.cacheHit = true
+		m.mu.Unlock()
+	}
+}
+
+// readProxyResponse reads a proxied API response up to maxProxyResponseSize, and
+// reports the result as truncated when the response is larger
+func readProxyResponse(ctx context.Context, body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxProxyResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxProxyResponseSize {
+		markTruncated(ctx)
+		data = data[:maxProxyResponseSize]
+	}
+	return data, nil
+}
+
+// environmentNames caches the names of the environments targeted by tool calls, so that
+// the metadata of a result names its environment without an API call per call
+type environmentNames struct {
+	mu    sync.Mutex
+	names map[int]string
+}
+
+// forget drops the cached name of an environment, after a call that may have renamed or
+// removed it
+func (e *environmentNames) forget(id int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.names, id)
+}
+
+// lookup returns the name of an environment, or an empty string when it cannot be read.
+// The environment is read without the lock held, so that a slow Portainer response does
+// not hold up the metadata of the other calls.
+func (e *environmentNames) lookup(cli PortainerClient, id int) string {
+	e.mu.Lock()
+	name, ok := e.names[id]
+	e.mu.Unlock()
+	if ok {
+		return name
+	}
+
+	environment, err := cli.GetEnvironment(id)
+	if err != nil {
+		return ""
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.names == nil {
+		e.names = map[int]string{}
+	}
+	e.names[id] = environment.Name
+	return environment.Name
+}
+
+// metadataMiddleware attaches execution metadata to the _meta field of every tool
+// result: the Portainer version, the environment targeted by a call on a single
+// environment, the duration of the call, and
+// whether the result came from a cache or was truncated. Clients can show where a result
+// comes from without parsing its text. The environment name is looked up only for
+// successful calls, and looked up again after calls that modify resources.
+func (s *PortainerMCPServer) metadataMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		collected := &callMetadata{}
+		ctx = context.WithValue(ctx, callMetadataKey{}, collected)
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		if err != nil || result == nil {
+			return result, err
+		}
+
+		setResultMeta(result, metaDurationMs, time.Since(start).Milliseconds())
+		if s.portainerVersion != "" {
+			setResultMeta(result, metaPortainerVersion, s.portainerVersion)
+		}
+
+		if ids := requestEnvironmentIDs(request); len(ids) == 1 {
+			environmentID := ids[0]
+			setResultMeta(result, metaEnvironmentID, environmentID)
+			if !result.IsError {
+				if s.operationKind(request) != operationRead {
+					s.environmentNames.forget(environmentID)
+				}
+				if name := s.environmentNames.lookup(s.cli, environmentID); name != "" {
+					setResultMeta(result, metaEnvironmentName, name)
+				}
+			}
+		}
+
+		collected.mu.Lock()
+		setResultMeta(result, metaCacheHit, collected.cacheHit)
+		setResultMeta(result, metaTruncated, collected.truncated)
+		collected.mu.Unlock()
+
+		return result, nil
+	}
+}
+
+// setResultMeta sets a field of the _meta object of a tool result.
+func setResultMeta(result *mcp.CallToolResult, key string, value any) {
+	if result.Meta == nil {
+		result.Meta = &mcp.Meta{}
+	}
+	if result.Meta.AdditionalFields == nil {
+		result.Meta.AdditionalFields = map[string]any{}
+	}
+	result.Meta.AdditionalFields[key] = value
+}
+
+// resultMeta returns a field of the _meta object of a tool result, or nil.
+func resultMeta(result *mcp.CallToolResult, key string) any {
+	if result.Meta == nil {
+		return nil
+	}
+	return result.Meta.AdditionalFields[key]
+}
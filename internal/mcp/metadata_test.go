@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetadataMiddleware verifies the execution metadata attached to tool results.
+func TestMetadataMiddleware(t *testing.T) {
+	mockClient := new(MockPortainerClient)
+	mockClient.On("GetEnvironment", 3).Return(models.Environment{ID: 3, Name: "production"}, nil).Once()
+	s := &PortainerMCPServer{cli: mockClient, portainerVersion: "2.31.2"}
+
+	truncating := s.metadataMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		markTruncated(ctx)
+		return mcp.NewToolResultText("partial"), nil
+	})
+	request := CreateMCPRequest(map[string]any{"action": "list_containers", "environmentId": float64(3)})
+	request.Params.Name = "manage_docker"
+
+	for range 2 {
+		result, err := truncating(context.Background(), request)
+		require.NoError(t, err)
+		assert.Equal(t, "2.31.2", resultMeta(result, metaPortainerVersion))
+		assert.Equal(t, 3, resultMeta(result, metaEnvironmentID))
+		assert.Equal(t, "production", resultMeta(result, metaEnvironmentName), "The name is cached after the first call")
+		assert.Equal(t, true, resultMeta(result, metaTruncated))
+		assert.Equal(t, false, resultMeta(result, metaCacheHit))
+		assert.Contains(t, result.Meta.AdditionalFields, metaDurationMs)
+	}
+
+	failing := s.metadataMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return newToolResultErrorWithCode(ErrorCodeNotFound, "environment not found"), nil
+	})
+	request = CreateMCPRequest(map[string]any{"environmentId": float64(4)})
+	request.Params.Name = ToolListContainers
+
+	result, err := failing(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, ErrorCodeNotFound, errorCode(result), "Existing metadata is kept")
+	assert.Equal(t, 4, resultMeta(result, metaEnvironmentID))
+	assert.NotContains(t, result.Meta.AdditionalFields, metaEnvironmentName, "Names are not looked up for failed calls")
+	assert.Equal(t, false, resultMeta(result, metaTruncated))
+
+	mockClient.AssertNotCalled(t, "GetEnvironment", 4)
+	mockClient.AssertExpectations(t)
+}
+
+// TestReadProxyResponse verifies that oversized proxy responses are cut and reported as
+// truncated.
+func TestReadProxyResponse(t *testing.T) {
+	collected := &callMetadata{}
+	ctx := context.WithValue(context.Background(), callMetadataKey{}, collected)
+
+	data, err := readProxyResponse(ctx, bytes.NewReader(make([]byte, maxProxyResponseSize)))
+	require.NoError(t, err)
+	assert.Len(t, data, maxProxyResponseSize)
+	assert.False(t, collected.truncated)
+
+	data, err = readProxyResponse(ctx, bytes.NewReader(make([]byte, maxProxyResponseSize+10)))
+	require.NoError(t, err)
+	assert.Len(t, data, maxProxyResponseSize)
+	assert.True(t, collected.truncated)
+}
+
+// TestMetadataCacheHitAndTruncation verifies that idempotent replays are reported as cache
+// hits, and that chunked results and full log tails are reported as truncated.
+func TestMetadataCacheHitAndTruncation(t *testing.T) {
+	s := idempotencyTestServer()
+	s.results = newResultStore(10)
+	call := func(handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), tool string, args map[string]any) *mcp.CallToolResult {
+		request := CreateMCPRequest(args)
+		request.Params.Name = tool
+		result, err := s.metadataMiddleware(handler)(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	var calls atomic.Int32
+	create := s.idempotencyMiddleware(countingHandler(&calls))
+	args := map[string]any{"name": "prod", paramIdempotencyKey: "k1"}
+	assert.Equal(t, false, resultMeta(call(create, ToolCreateEnvironmentTag, args), metaCacheHit))
+	assert.Equal(t, true, resultMeta(call(create, ToolCreateEnvironmentTag, args), metaCacheHit), "The replay is a cache hit")
+
+	chunked := s.chunkingMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("0123456789abcdefghij"), nil
+	})
+	assert.Equal(t, true, resultMeta(call(chunked, ToolListStacks, nil), metaTruncated))
+
+	mockClient := new(MockPortainerClient)
+	mockClient.On("GetDockerContainerLogs", 1, "web", models.DockerContainerLogOptions{Tail: 2}).Return("one\ntwo\n", nil).Once()
+	mockClient.On("GetDockerContainerLogs", 1, "web", models.DockerContainerLogOptions{Tail: 3}).Return("one\ntwo\n", nil).Once()
+	mockClient.On("GetEnvironment", 1).Return(models.Environment{ID: 1, Name: "local"}, nil)
+	s.cli = mockClient
+
+	result := call(s.HandleGetContainerLogs(), ToolGetContainerLogs, map[string]any{"environmentId": float64(1), "containerId": "web", "tail": float64(2)})
+	assert.Equal(t, true, resultMeta(result, metaTruncated), "A full tail may have left older lines out")
+	result = call(s.HandleGetContainerLogs(), ToolGetContainerLogs, map[string]any{"environmentId": float64(1), "containerId": "web", "tail": float64(3)})
+	assert.Equal(t, false, resultMeta(result, metaTruncated))
+	mockClient.AssertExpectations(t)
+}
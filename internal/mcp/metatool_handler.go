@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -11,7 +12,8 @@ import (
 
 // RegisterMetaTools builds and registers all meta-tools on the MCP server.
 // In read-only mode, write actions are excluded from the action enum and
-// their handlers are not registered. If a meta-tool has no available
+// their handlers are not registered. With RBAC filtering, actions the API
+// token cannot call are excluded the same way. If a meta-tool has no available
 // actions after filtering (e.g. all are write-only and read-only is on),
 // it is silently skipped.
 func (s *PortainerMCPServer) RegisterMetaTools() {
@@ -22,12 +24,19 @@ func (s *PortainerMCPServer) RegisterMetaTools() {
 }
 
 // registerOneMetaTool builds a single meta-tool from its definition,
-// filtering actions by read-only mode, and registers it.
+// filtering actions by read-only mode and token access, and registers it.
 func (s *PortainerMCPServer) registerOneMetaTool(def metaToolDef) {
-	// Filter actions based on read-only mode
+	// Filter actions based on read-only mode and token access. Filtered actions are
+	// remembered so that calling them returns an error with the reason.
 	available := make([]metaAction, 0, len(def.actions))
+	unavailable := map[string]string{}
 	for _, a := range def.actions {
 		if s.readOnly && !a.readOnly {
+			unavailable[a.name] = ErrorCodeReadOnly
+			continue
+		}
+		if !s.toolAllowed(a.tool) {
+			unavailable[a.name] = ErrorCodeForbidden
 			continue
 		}
 		available = append(available, a)
@@ -60,6 +69,20 @@ func (s *PortainerMCPServer) registerOneMetaTool(def metaToolDef) {
 		annotation.DestructiveHint = boolPtr(false)
 	}
 
+	// Likewise, the meta-tool is idempotent when the tools.yaml annotations of all the
+	// remaining actions are, so that clients may retry it.
+	allIdempotent := true
+	for _, a := range available {
+		tool, ok := s.toolDefinition(a.tool)
+		if !ok || tool.Annotations.IdempotentHint == nil || !*tool.Annotations.IdempotentHint {
+			allIdempotent = false
+			break
+		}
+	}
+	if allIdempotent {
+		annotation.IdempotentHint = boolPtr(true)
+	}
+
 	// Build the MCP tool programmatically
 	tool := mcp.NewTool(def.name,
 		mcp.WithDescription(def.description),
@@ -72,21 +95,33 @@ func (s *PortainerMCPServer) registerOneMetaTool(def metaToolDef) {
 	)
 
 	// Register the meta-tool with a routing handler
-	s.srv.AddTool(tool, makeMetaHandler(def.name, handlers))
+	s.registerTool(tool, makeMetaHandler(def.name, handlers, unavailable))
 }
 
 // makeMetaHandler creates a ToolHandlerFunc that routes to the correct
-// sub-handler based on the "action" parameter.
-func makeMetaHandler(metaToolName string, handlers map[string]server.ToolHandlerFunc) server.ToolHandlerFunc {
+// sub-handler based on the "action" parameter. Unavailable maps the actions
+// filtered out of the meta-tool to the error code returned when they are called.
+func makeMetaHandler(metaToolName string, handlers map[string]server.ToolHandlerFunc, unavailable map[string]string) server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		actionRaw, ok := request.GetArguments()["action"]
 		if !ok {
-			return mcp.NewToolResultError("missing required parameter: action"), nil
+			return newToolResultErrorWithCode(ErrorCodeValidation, "missing required parameter: action"), nil
 		}
 
 		action, ok := actionRaw.(string)
 		if !ok || action == "" {
-			return mcp.NewToolResultError("parameter 'action' must be a non-empty string"), nil
+			return newToolResultErrorWithCode(ErrorCodeValidation, "parameter 'action' must be a non-empty string"), nil
+		}
+
+		switch unavailable[action] {
+		case ErrorCodeReadOnly:
+			return newToolResultErrorWithCode(ErrorCodeReadOnly, fmt.Sprintf(
+				"action '%s' of tool '%s' modifies resources and is not available in read-only mode", action, metaToolName,
+			)), nil
+		case ErrorCodeForbidden:
+			return newToolResultErrorWithCode(ErrorCodeForbidden, fmt.Sprintf(
+				"action '%s' of tool '%s' is not allowed for the API token user", action, metaToolName,
+			)), nil
 		}
 
 		handler, ok := handlers[action]
@@ -95,7 +130,7 @@ func makeMetaHandler(metaToolName string, handlers map[string]server.ToolHandler
 			for k := range handlers {
 				available = append(available, k)
 			}
-			return mcp.NewToolResultError(fmt.Sprintf(
+			return newToolResultErrorWithCode(ErrorCodeValidation, fmt.Sprintf(
 				"unknown action '%s' for tool '%s'. Available actions: %s",
 				action, metaToolName, strings.Join(available, ", "),
 			)), nil
@@ -104,3 +139,31 @@ func makeMetaHandler(metaToolName string, handlers map[string]server.ToolHandler
 		return handler(ctx, request)
 	}
 }
+
+var (
+	metaActionIndexOnce sync.Once
+	metaActionIndexMap  map[string]map[string]metaAction
+)
+
+// metaActionIndex maps meta-tool names and action names to their definitions. It is
+// built on first use by a function rather than a package variable, since handlers of the
+// meta-tool definitions resolve calls through it.
+func metaActionIndex() map[string]map[string]metaAction {
+	metaActionIndexOnce.Do(func() {
+		metaActionIndexMap = map[string]map[string]metaAction{}
+		for _, def := range metaToolDefinitions() {
+			actions := make(map[string]metaAction, len(def.actions))
+			for _, a := range def.actions {
+				actions[a.name] = a
+			}
+			metaActionIndexMap[def.name] = actions
+		}
+	})
+	return metaActionIndexMap
+}
+
+// findMetaAction returns the definition of an action of a meta-tool.
+func findMetaAction(metaToolName, action string) (metaAction, bool) {
+	a, ok := metaActionIndex()[metaToolName][action]
+	return a, ok
+}
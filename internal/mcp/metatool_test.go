@@ -9,6 +9,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -60,7 +61,7 @@ func listRegisteredTools(t *testing.T, srv *server.MCPServer) []string {
 }
 
 // TestMetaToolDefinitionsCount verifies that metaToolDefinitions returns
-// exactly 15 groups with 98 total actions.
+// exactly 15 groups with 162 total actions.
 func TestMetaToolDefinitionsCount(t *testing.T) {
 	defs := metaToolDefinitions()
 	assert.Equal(t, 15, len(defs), "expected 15 meta-tool groups")
@@ -69,7 +70,7 @@ func TestMetaToolDefinitionsCount(t *testing.T) {
 	for _, def := range defs {
 		totalActions += len(def.actions)
 	}
-	assert.Equal(t, 98, totalActions, "expected 98 total actions across all meta-tools")
+	assert.Equal(t, 162, totalActions, "expected 162 total actions across all meta-tools")
 }
 
 // TestMetaToolUniqueActionNames verifies that all action names within each
@@ -248,6 +249,36 @@ func TestMetaToolReadOnlyAnnotation(t *testing.T) {
 	}
 }
 
+// TestMetaToolIdempotentAnnotation verifies that a meta-tool is annotated as idempotent
+// only when the tools.yaml annotations of all its remaining actions are.
+func TestMetaToolIdempotentAnnotation(t *testing.T) {
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+
+	for _, readOnly := range []bool{true, false} {
+		s := newTestMetaServer(readOnly)
+		s.tools = tools
+		s.RegisterMetaTools()
+
+		reqJSON := `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`
+		respBytes, err := json.Marshal(s.srv.HandleMessage(context.Background(), json.RawMessage(reqJSON)))
+		require.NoError(t, err)
+		var rpcResp struct {
+			Result struct {
+				Tools []mcp.Tool `json:"tools"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(respBytes, &rpcResp))
+
+		idempotent := map[string]bool{}
+		for _, tool := range rpcResp.Result.Tools {
+			idempotent[tool.Name] = tool.Annotations.IdempotentHint != nil && *tool.Annotations.IdempotentHint
+		}
+		assert.Equal(t, readOnly, idempotent["manage_stacks"],
+			"manage_stacks is idempotent only without its create action (read-only: %v)", readOnly)
+	}
+}
+
 // TestMakeMetaHandlerRouting verifies that makeMetaHandler correctly routes
 // to the appropriate sub-handler based on the action parameter.
 func TestMakeMetaHandlerRouting(t *testing.T) {
@@ -266,7 +297,11 @@ func TestMakeMetaHandlerRouting(t *testing.T) {
 		"action_two": handler2,
 	}
 
-	metaHandler := makeMetaHandler("test_tool", handlers)
+	unavailable := map[string]string{
+		"write_action": ErrorCodeReadOnly,
+		"admin_action": ErrorCodeForbidden,
+	}
+	metaHandler := makeMetaHandler("test_tool", handlers, unavailable)
 
 	tests := []struct {
 		name           string
@@ -274,6 +309,7 @@ func TestMakeMetaHandlerRouting(t *testing.T) {
 		expectedAction string
 		expectError    bool
 		errorContains  string
+		errorCode      string
 	}{
 		{
 			name:           "routes to action_one",
@@ -290,6 +326,7 @@ func TestMakeMetaHandlerRouting(t *testing.T) {
 			args:          map[string]interface{}{},
 			expectError:   true,
 			errorContains: "missing required parameter: action",
+			errorCode:     ErrorCodeValidation,
 		},
 		{
 			name:          "empty action",
@@ -302,6 +339,21 @@ func TestMakeMetaHandlerRouting(t *testing.T) {
 			args:          map[string]interface{}{"action": "nonexistent"},
 			expectError:   true,
 			errorContains: "unknown action 'nonexistent'",
+			errorCode:     ErrorCodeValidation,
+		},
+		{
+			name:          "action hidden in read-only mode",
+			args:          map[string]interface{}{"action": "write_action"},
+			expectError:   true,
+			errorContains: "action 'write_action' of tool 'test_tool' modifies resources and is not available in read-only mode",
+			errorCode:     ErrorCodeReadOnly,
+		},
+		{
+			name:          "action not allowed for the token",
+			args:          map[string]interface{}{"action": "admin_action"},
+			expectError:   true,
+			errorContains: "is not allowed for the API token user",
+			errorCode:     ErrorCodeForbidden,
 		},
 		{
 			name:          "non-string action",
@@ -333,6 +385,9 @@ func TestMakeMetaHandlerRouting(t *testing.T) {
 				textContent, ok := result.Content[0].(mcp.TextContent)
 				assert.True(t, ok)
 				assert.Contains(t, textContent.Text, tt.errorContains)
+				if tt.errorCode != "" {
+					assert.Equal(t, tt.errorCode, errorCode(result))
+				}
 			} else {
 				assert.False(t, result.IsError)
 				assert.Equal(t, tt.expectedAction, calledAction)
@@ -348,7 +403,7 @@ func TestMetaToolHandlerIntegration(t *testing.T) {
 
 	// Mock the GetUsers method since we'll call manage_users with action "list_users"
 	mockClient := s.cli.(*MockPortainerClient)
-	mockClient.On("GetUsers").Return([]models.User{}, nil)
+	mockClient.On("GetUsers", models.UserListOptions{}).Return([]models.User{}, nil)
 
 	s.RegisterMetaTools()
 
@@ -424,3 +479,22 @@ func TestBoolPtr(t *testing.T) {
 	assert.True(t, *truePtr)
 	assert.False(t, *falsePtr)
 }
+
+// TestMetaActionToolsMatchDefinitions verifies that every meta-tool action refers to a
+// granular tool defined in tools.yaml with a matching read-only annotation.
+func TestMetaActionToolsMatchDefinitions(t *testing.T) {
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+
+	for _, def := range metaToolDefinitions() {
+		for _, a := range def.actions {
+			tool, ok := tools[a.tool]
+			if !assert.True(t, ok, "action '%s' in meta-tool '%s' refers to unknown tool '%s'", a.name, def.name, a.tool) {
+				continue
+			}
+			require.NotNil(t, tool.Annotations.ReadOnlyHint, "tool '%s' has no readOnlyHint", a.tool)
+			assert.Equal(t, *tool.Annotations.ReadOnlyHint, a.readOnly,
+				"action '%s' in meta-tool '%s' disagrees with the readOnlyHint of '%s'", a.name, def.name, a.tool)
+		}
+	}
+}
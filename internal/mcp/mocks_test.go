@@ -66,8 +66,8 @@ func (m *MockPortainerClient) DeleteEnvironmentTag(id int) error {
 
 // Environment methods
 
-func (m *MockPortainerClient) GetEnvironments() ([]models.Environment, error) {
-	args := m.Called()
+func (m *MockPortainerClient) GetEnvironments(opts models.EnvironmentListOptions) ([]models.Environment, error) {
+	args := m.Called(opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -79,6 +79,11 @@ func (m *MockPortainerClient) GetEnvironment(id int) (models.Environment, error)
 	return args.Get(0).(models.Environment), args.Error(1)
 }
 
+func (m *MockPortainerClient) CreateEnvironment(opts models.EnvironmentCreateOptions) (models.Environment, string, error) {
+	args := m.Called(opts)
+	return args.Get(0).(models.Environment), args.String(1), args.Error(2)
+}
+
 func (m *MockPortainerClient) DeleteEnvironment(id int) error {
 	args := m.Called(id)
 	return args.Error(0)
@@ -89,6 +94,27 @@ func (m *MockPortainerClient) SnapshotEnvironment(id int) error {
 	return args.Error(0)
 }
 
+func (m *MockPortainerClient) GetEnvironmentSnapshotSettings(id int) (models.EnvironmentSnapshotSettings, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return models.EnvironmentSnapshotSettings{}, args.Error(1)
+	}
+	return args.Get(0).(models.EnvironmentSnapshotSettings), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetEnvironmentSnapshots(opts models.EnvironmentListOptions) ([]models.EnvironmentSnapshot, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.EnvironmentSnapshot), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateEnvironmentSnapshotSettings(id int, edgeCheckinInterval, edgeSnapshotInterval *int) error {
+	args := m.Called(id, edgeCheckinInterval, edgeSnapshotInterval)
+	return args.Error(0)
+}
+
 func (m *MockPortainerClient) SnapshotAllEnvironments() error {
 	args := m.Called()
 	return args.Error(0)
@@ -181,14 +207,24 @@ func (m *MockPortainerClient) RemoveEnvironmentFromAccessGroup(id int, environme
 
 // Stack methods
 
-func (m *MockPortainerClient) GetStacks() ([]models.Stack, error) {
-	args := m.Called()
+func (m *MockPortainerClient) GetStacks(opts models.EdgeStackListOptions) ([]models.Stack, error) {
+	args := m.Called(opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.Stack), args.Error(1)
 }
 
+func (m *MockPortainerClient) GetEdgeStackStatus(id int) (models.EdgeStackStatus, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.EdgeStackStatus), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetGitRepositoryFile(git models.StackGitConfig) (string, error) {
+	args := m.Called(git)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockPortainerClient) GetRegularStacks() ([]models.RegularStack, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -207,6 +243,11 @@ func (m *MockPortainerClient) CreateStack(name string, file string, environmentG
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockPortainerClient) CreateStackWithEnv(name string, file string, environmentGroupIds []int, env map[string]string) (int, error) {
+	args := m.Called(name, file, environmentGroupIds, env)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockPortainerClient) UpdateStack(id int, file string, environmentGroupIds []int) error {
 	args := m.Called(id, file, environmentGroupIds)
 	return args.Error(0)
@@ -270,6 +311,38 @@ func (m *MockPortainerClient) MigrateStack(id int, endpointID int, targetEndpoin
 	return args.Get(0).(models.RegularStack), args.Error(1)
 }
 
+func (m *MockPortainerClient) CreateRegularStack(endpointID int, name string, file string, env map[string]string) (models.RegularStack, error) {
+	args := m.Called(endpointID, name, file, env)
+	if args.Get(0) == nil {
+		return models.RegularStack{}, args.Error(1)
+	}
+	return args.Get(0).(models.RegularStack), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateRegularStack(id int, endpointID int, file string, env map[string]string, pullImage bool, prune bool) (models.RegularStack, error) {
+	args := m.Called(id, endpointID, file, env, pullImage, prune)
+	if args.Get(0) == nil {
+		return models.RegularStack{}, args.Error(1)
+	}
+	return args.Get(0).(models.RegularStack), args.Error(1)
+}
+
+func (m *MockPortainerClient) RedeployRegularStack(id int, endpointID int, pullImage bool) (models.RegularStack, error) {
+	args := m.Called(id, endpointID, pullImage)
+	if args.Get(0) == nil {
+		return models.RegularStack{}, args.Error(1)
+	}
+	return args.Get(0).(models.RegularStack), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetRegularStackEnv(id int) (map[string]string, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 // Team methods
 
 func (m *MockPortainerClient) CreateTeam(name string) (int, error) {
@@ -308,16 +381,26 @@ func (m *MockPortainerClient) UpdateTeamMembers(id int, userIds []int) error {
 	return args.Error(0)
 }
 
+func (m *MockPortainerClient) RemoveTeamMember(teamID, userID int) error {
+	args := m.Called(teamID, userID)
+	return args.Error(0)
+}
+
 // User methods
 
-func (m *MockPortainerClient) GetUsers() ([]models.User, error) {
-	args := m.Called()
+func (m *MockPortainerClient) GetUsers(opts models.UserListOptions) ([]models.User, error) {
+	args := m.Called(opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]models.User), args.Error(1)
 }
 
+func (m *MockPortainerClient) HasAPITokens(id int) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockPortainerClient) UpdateUserRole(id int, role string) error {
 	args := m.Called(id, role)
 	return args.Error(0)
@@ -336,11 +419,55 @@ func (m *MockPortainerClient) GetUser(id int) (models.User, error) {
 	return args.Get(0).(models.User), args.Error(1)
 }
 
+func (m *MockPortainerClient) GetCurrentUser() (models.User, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return models.User{}, args.Error(1)
+	}
+	return args.Get(0).(models.User), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetUserMemberships(id int) ([]models.TeamMembership, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.TeamMembership), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetUserAPITokens(id int) ([]models.APIToken, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIToken), args.Error(1)
+}
+
+func (m *MockPortainerClient) RevokeUserAPIToken(userID, tokenID int) error {
+	args := m.Called(userID, tokenID)
+	return args.Error(0)
+}
+
 func (m *MockPortainerClient) DeleteUser(id int) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// Resource control methods
+
+func (m *MockPortainerClient) GetResourceControls() ([]models.ResourceControl, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ResourceControl), args.Error(1)
+}
+
+func (m *MockPortainerClient) UpdateResourceControl(control models.ResourceControl) error {
+	args := m.Called(control)
+	return args.Error(0)
+}
+
 // System methods
 
 func (m *MockPortainerClient) GetSystemStatus() (models.SystemStatus, error) {
@@ -351,6 +478,11 @@ func (m *MockPortainerClient) GetSystemStatus() (models.SystemStatus, error) {
 	return args.Get(0).(models.SystemStatus), args.Error(1)
 }
 
+func (m *MockPortainerClient) ProbeAPI(path string) (int64, error) {
+	args := m.Called(path)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // Settings methods
 
 func (m *MockPortainerClient) GetSettings() (models.PortainerSettings, error) {
@@ -366,6 +498,22 @@ func (m *MockPortainerClient) UpdateSettings(settings map[string]interface{}) er
 	return args.Error(0)
 }
 
+func (m *MockPortainerClient) GetSettingsValues(keys []string) (map[string]any, error) {
+	args := m.Called(keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]any), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetSnapshotSettings() (models.SnapshotSettings, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return models.SnapshotSettings{}, args.Error(1)
+	}
+	return args.Get(0).(models.SnapshotSettings), args.Error(1)
+}
+
 func (m *MockPortainerClient) GetPublicSettings() (models.PublicSettings, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -422,6 +570,91 @@ func (m *MockPortainerClient) GetDockerDashboard(environmentId int) (models.Dock
 	return args.Get(0).(models.DockerDashboard), args.Error(1)
 }
 
+func (m *MockPortainerClient) GetDockerContainers(environmentId int, opts models.DockerContainerListOptions) ([]models.DockerContainer, error) {
+	args := m.Called(environmentId, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DockerContainer), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerLogs(environmentId int, containerId string, opts models.DockerContainerLogOptions) (string, error) {
+	args := m.Called(environmentId, containerId, opts)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerEvents(environmentId int, opts models.DockerEventListOptions) ([]models.DockerEvent, error) {
+	args := m.Called(environmentId, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DockerEvent), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerStats(environmentId int, containerId string) (models.DockerContainerUsage, error) {
+	args := m.Called(environmentId, containerId)
+	return args.Get(0).(models.DockerContainerUsage), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerTop(environmentId int, containerId, psArgs string) (models.DockerContainerTop, error) {
+	args := m.Called(environmentId, containerId, psArgs)
+	return args.Get(0).(models.DockerContainerTop), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerRestartState(environmentId int, containerId string) (models.DockerContainerRestartState, error) {
+	args := m.Called(environmentId, containerId)
+	return args.Get(0).(models.DockerContainerRestartState), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerPortBindings(environmentId int, containerId string) ([]models.DockerContainerPort, error) {
+	args := m.Called(environmentId, containerId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DockerContainerPort), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerImage(environmentId int, containerId string) (models.DockerContainerImage, error) {
+	args := m.Called(environmentId, containerId)
+	return args.Get(0).(models.DockerContainerImage), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerImageDigests(environmentId int, imageId string) ([]string, error) {
+	args := m.Called(environmentId, imageId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerDistributionDigest(environmentId int, imageRef string) (string, error) {
+	args := m.Called(environmentId, imageRef)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerServices(environmentId int) ([]models.DockerService, error) {
+	args := m.Called(environmentId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.DockerService), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerDiskUsage(environmentId int) (models.DockerDiskUsage, error) {
+	args := m.Called(environmentId)
+	return args.Get(0).(models.DockerDiskUsage), args.Error(1)
+}
+
+func (m *MockPortainerClient) GetDockerContainerFile(environmentId int, containerId, filePath string) (models.DockerContainerFile, error) {
+	args := m.Called(environmentId, containerId, filePath)
+	return args.Get(0).(models.DockerContainerFile), args.Error(1)
+}
+
+func (m *MockPortainerClient) PutDockerContainerFile(environmentId int, containerId, filePath string, content []byte, mode int64) error {
+	args := m.Called(environmentId, containerId, filePath, content, mode)
+	return args.Error(0)
+}
+
 // Kubernetes Proxy methods
 func (m *MockPortainerClient) ProxyKubernetesRequest(opts models.KubernetesProxyRequestOptions) (*http.Response, error) {
 	args := m.Called(opts)
@@ -499,6 +732,11 @@ func (m *MockPortainerClient) DeleteWebhook(id int) error {
 	return args.Error(0)
 }
 
+func (m *MockPortainerClient) TriggerWebhook(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
 // Registry methods
 
 func (m *MockPortainerClient) GetRegistries() ([]models.Registry, error) {
@@ -529,6 +767,11 @@ func (m *MockPortainerClient) DeleteRegistry(id int) error {
 	return args.Error(0)
 }
 
+func (m *MockPortainerClient) RemoveRegistryTeamAccess(id int, environmentId int, teamId int) error {
+	args := m.Called(id, environmentId, teamId)
+	return args.Error(0)
+}
+
 // Backup methods
 
 func (m *MockPortainerClient) GetBackupStatus() (models.BackupStatus, error) {
@@ -572,6 +815,16 @@ func (m *MockPortainerClient) GetRoles() ([]models.Role, error) {
 	return args.Get(0).([]models.Role), args.Error(1)
 }
 
+func (m *MockPortainerClient) AssignEnvironmentRole(id int, assignee string, assigneeID int, roleID int) (int, error) {
+	args := m.Called(id, assignee, assigneeID, roleID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockPortainerClient) AssignAccessGroupRole(id int, assignee string, assigneeID int, roleID int) (int, error) {
+	args := m.Called(id, assignee, assigneeID, roleID)
+	return args.Int(0), args.Error(1)
+}
+
 // MOTD methods
 
 func (m *MockPortainerClient) GetMOTD() (models.MOTD, error) {
@@ -625,6 +878,11 @@ func (m *MockPortainerClient) GetEdgeUpdateSchedules() ([]models.EdgeUpdateSched
 	return args.Get(0).([]models.EdgeUpdateSchedule), args.Error(1)
 }
 
+func (m *MockPortainerClient) CreateEdgeUpdateSchedule(name string, edgeGroupIds []int, scheduledTime string) (int, error) {
+	args := m.Called(name, edgeGroupIds, scheduledTime)
+	return args.Int(0), args.Error(1)
+}
+
 // Auth methods
 
 func (m *MockPortainerClient) AuthenticateUser(username, password string) (models.AuthResponse, error) {
@@ -684,3 +942,8 @@ func (m *MockPortainerClient) GetHelmReleaseHistory(environmentId int, name, nam
 	}
 	return args.Get(0).([]models.HelmReleaseDetails), args.Error(1)
 }
+
+func (m *MockPortainerClient) GetHelmRelease(environmentId int, name, namespace string) (models.HelmReleaseInfo, error) {
+	args := m.Called(environmentId, name, namespace)
+	return args.Get(0).(models.HelmReleaseInfo), args.Error(1)
+}
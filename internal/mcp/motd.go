@@ -2,14 +2,33 @@ package mcp
 
 import (
 	"context"
+	"strings"
 
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// AddMotdFeatures registers the message of the day (MOTD) management tools on the MCP server.
+// customLoginBannerField is the settings update field of the login page banner.
+const customLoginBannerField = "customLoginBanner"
+
+// loginBanner is the result of HandleGetLoginBanner and HandleUpdateLoginBanner.
+type loginBanner struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+	// SnapshotID is the settings snapshot holding the previous banner, set by updates only.
+	SnapshotID int    `json:"snapshot_id,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// AddMotdFeatures registers the message of the day (MOTD) and login banner tools on the MCP server.
 func (s *PortainerMCPServer) AddMotdFeatures() {
 	s.addToolIfExists(ToolGetMOTD, s.HandleGetMOTD())
+	s.addToolIfExists(ToolGetLoginBanner, s.HandleGetLoginBanner())
+
+	if !s.readOnly {
+		s.addToolIfExists(ToolUpdateLoginBanner, s.HandleUpdateLoginBanner())
+	}
 }
 
 // HandleGetMOTD returns an MCP tool handler that retrieves m o t d.
@@ -23,3 +42,62 @@ func (s *PortainerMCPServer) HandleGetMOTD() server.ToolHandlerFunc {
 		return jsonResult(motd, "failed to marshal MOTD")
 	}
 }
+
+// HandleGetLoginBanner returns an MCP tool handler that retrieves the custom banner shown
+// on the Portainer login page.
+func (s *PortainerMCPServer) HandleGetLoginBanner() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		settings, err := s.cli.GetPublicSettings()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get public settings", err), nil
+		}
+
+		banner := loginBanner{Enabled: settings.CustomLoginBanner != "", Message: settings.CustomLoginBanner}
+		if !banner.Enabled {
+			banner.Note = "no login banner is set; login banners are a Portainer Business Edition feature"
+		}
+		return jsonResult(banner, "failed to marshal login banner")
+	}
+}
+
+// HandleUpdateLoginBanner returns an MCP tool handler that sets or clears the custom banner
+// shown on the Portainer login page. The previous banner is saved as a settings snapshot
+// for revertSettings. Servers that do not support login banners ignore the update, which
+// is detected by reading the banner back.
+func (s *PortainerMCPServer) HandleUpdateLoginBanner() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		message, err := parser.GetString("message", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid message parameter", err), nil
+		}
+		message = strings.TrimSpace(message)
+
+		snapshot, err := s.snapshotSettings(ToolUpdateLoginBanner, []string{customLoginBannerField})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to snapshot current settings", err), nil
+		}
+
+		if err := s.cli.UpdateSettings(map[string]any{customLoginBannerField: message}); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to update login banner", err), nil
+		}
+		snapshotID := s.settingsSnapshots.add(snapshot)
+
+		settings, err := s.cli.GetPublicSettings()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to read back the login banner", err), nil
+		}
+		if settings.CustomLoginBanner != message {
+			return mcp.NewToolResultError("the Portainer server ignored the login banner: login banners are a Portainer Business Edition feature"), nil
+		}
+
+		banner := loginBanner{
+			Enabled:    message != "",
+			Message:    message,
+			SnapshotID: snapshotID,
+			Note:       "previous banner saved; use revertSettings with the snapshot ID to restore it",
+		}
+		return jsonResult(banner, "failed to marshal login banner")
+	}
+}
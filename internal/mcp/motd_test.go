@@ -6,9 +6,10 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestHandleGetMOTD verifies the HandleGetMOTD MCP tool handler.
@@ -70,3 +71,127 @@ func TestHandleGetMOTD(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleGetLoginBanner verifies the HandleGetLoginBanner MCP tool handler.
+func TestHandleGetLoginBanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.PublicSettings
+		expected loginBanner
+	}{
+		{
+			name:     "banner set",
+			settings: models.PublicSettings{CustomLoginBanner: "Authorized use only"},
+			expected: loginBanner{Enabled: true, Message: "Authorized use only"},
+		},
+		{
+			name:     "no banner",
+			expected: loginBanner{Note: "no login banner is set; login banners are a Portainer Business Edition feature"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetPublicSettings").Return(tt.settings, nil)
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetLoginBanner()(context.Background(), CreateMCPRequest(map[string]any{}))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			require.False(t, result.IsError, text)
+
+			var banner loginBanner
+			require.NoError(t, json.Unmarshal([]byte(text), &banner))
+			assert.Equal(t, tt.expected, banner)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleUpdateLoginBanner verifies that HandleUpdateLoginBanner snapshots the previous
+// banner, updates it and detects servers that ignore the update.
+func TestHandleUpdateLoginBanner(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+		// expectSnapshot is true when a failed call has already updated the settings.
+		expectSnapshot bool
+		expected       loginBanner
+	}{
+		{
+			name: "set banner",
+			args: map[string]any{"message": " Maintenance on Sunday "},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"customLoginBanner"}).Return(map[string]any{"customLoginBanner": "old"}, nil)
+				m.On("UpdateSettings", map[string]any{"customLoginBanner": "Maintenance on Sunday"}).Return(nil)
+				m.On("GetPublicSettings").Return(models.PublicSettings{CustomLoginBanner: "Maintenance on Sunday"}, nil)
+			},
+			expected: loginBanner{Enabled: true, Message: "Maintenance on Sunday", SnapshotID: 1},
+		},
+		{
+			name: "clear banner",
+			args: map[string]any{"message": ""},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"customLoginBanner"}).Return(map[string]any{"customLoginBanner": "old"}, nil)
+				m.On("UpdateSettings", map[string]any{"customLoginBanner": ""}).Return(nil)
+				m.On("GetPublicSettings").Return(models.PublicSettings{}, nil)
+			},
+			expected: loginBanner{SnapshotID: 1},
+		},
+		{
+			name: "banner ignored by the server",
+			args: map[string]any{"message": "hello"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"customLoginBanner"}).Return(map[string]any{}, nil)
+				m.On("UpdateSettings", map[string]any{"customLoginBanner": "hello"}).Return(nil)
+				m.On("GetPublicSettings").Return(models.PublicSettings{}, nil)
+			},
+			expectError:    "Business Edition feature",
+			expectSnapshot: true,
+		},
+		{
+			name: "update error",
+			args: map[string]any{"message": "hello"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"customLoginBanner"}).Return(map[string]any{}, nil)
+				m.On("UpdateSettings", map[string]any{"customLoginBanner": "hello"}).Return(fmt.Errorf("forbidden"))
+			},
+			expectError: "failed to update login banner",
+		},
+		{name: "missing message", args: map[string]any{}, expectError: "message is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleUpdateLoginBanner()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				_, ok := server.settingsSnapshots.get(1)
+				assert.Equal(t, tt.expectSnapshot, ok)
+				return
+			}
+
+			require.False(t, result.IsError, text)
+			var banner loginBanner
+			require.NoError(t, json.Unmarshal([]byte(text), &banner))
+			banner.Note = ""
+			assert.Equal(t, tt.expected, banner)
+			snapshot, ok := server.settingsSnapshots.get(banner.SnapshotID)
+			require.True(t, ok)
+			assert.Equal(t, map[string]any{"customLoginBanner": "old"}, snapshot.Settings)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
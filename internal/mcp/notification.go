@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/notify"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// maxNotificationSummary caps the length of the result excerpt sent in a notification.
+const maxNotificationSummary = 200
+
+// notificationMiddleware posts a notification for every destructive tool call that
+// succeeds. Notifications are delivered in the background so that a slow or unreachable
+// webhook never delays the tool result; delivery failures are only logged.
+func (s *PortainerMCPServer) notificationMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		if s.operationKind(request) != operationDestructive {
+			return result, nil
+		}
+
+		action, _ := request.GetArguments()["action"].(string)
+		event := notify.Event{
+			Tool:     request.Params.Name,
+			Action:   action,
+			Resource: describeResource(request.GetArguments()),
+			Session:  sessionID(ctx),
+			Summary:  resultSummary(result),
+			Time:     time.Now().UTC(),
+		}
+
+		s.notifications.Add(1)
+		go func() {
+			defer s.notifications.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), notify.DefaultTimeout)
+			defer cancel()
+			if err := s.notifier.Notify(ctx, event); err != nil {
+				log.Warn().Err(err).Str("tool", event.Tool).Msg("failed to send destructive action notification")
+			}
+		}()
+
+		return result, nil
+	}
+}
+
+// describeResource lists the identifying arguments of a tool call, such as "id=3" or
+// "environmentId=1 name=web", sorted by name. Other arguments are left out because they
+// may hold file contents or secrets.
+func describeResource(args map[string]any) string {
+	var parts []string
+	for name, value := range args {
+		if !isResourceArgument(name) {
+			continue
+		}
+		switch value.(type) {
+		case string, float64, int, bool, []any:
+			parts = append(parts, fmt.Sprintf("%s=%v", name, value))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// isResourceArgument reports whether an argument name identifies the target of a call.
+func isResourceArgument(name string) bool {
+	switch name {
+	case "id", "ids", "name", "namespace", "release":
+		return true
+	}
+	return strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "Ids") || strings.HasSuffix(name, "ID")
+}
+
+// resultSummary returns the first line of the first text content of a result, truncated
+// to maxNotificationSummary characters.
+func resultSummary(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		summary, _, _ := strings.Cut(strings.TrimSpace(text.Text), "\n")
+		if runes := []rune(summary); len(runes) > maxNotificationSummary {
+			summary = string(runes[:maxNotificationSummary]) + "..."
+		}
+		return summary
+	}
+	return ""
+}
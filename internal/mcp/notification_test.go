@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/notify"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNotificationMiddleware verifies that only successful destructive calls are announced.
+func TestNotificationMiddleware(t *testing.T) {
+	received := make(chan map[string]any, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		received <- body
+	}))
+	defer webhook.Close()
+
+	notifier, err := notify.New(webhook.URL, "")
+	require.NoError(t, err)
+	s := &PortainerMCPServer{tools: budgetTestTools(), notifier: notifier}
+
+	fail := false
+	handler := s.notificationMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if fail {
+			return mcp.NewToolResultError("portainer error"), nil
+		}
+		return mcp.NewToolResultText("Stack deleted successfully\nsecond line"), nil
+	})
+
+	call := func(tool string, args map[string]any) {
+		request := CreateMCPRequest(args)
+		request.Params.Name = tool
+		_, err := handler(context.Background(), request)
+		require.NoError(t, err)
+	}
+
+	call(ToolListStacks, map[string]any{"id": float64(1)})
+	call(ToolStartStack, map[string]any{"id": float64(1), "environmentId": float64(2)})
+	fail = true
+	call(ToolDeleteStack, map[string]any{"id": float64(3)})
+	fail = false
+	call(ToolDeleteStack, map[string]any{"id": float64(5), "environmentId": float64(2), "removeVolumes": true})
+	call("manage_stacks", map[string]any{"action": "delete_stack", "id": float64(6), "environmentId": float64(2)})
+	s.notifications.Wait()
+
+	close(received)
+	var bodies []map[string]any
+	for body := range received {
+		bodies = append(bodies, body)
+	}
+	require.Len(t, bodies, 2)
+
+	byTool := map[string]map[string]any{}
+	for _, body := range bodies {
+		byTool[body["tool"].(string)] = body
+	}
+
+	granular := byTool[ToolDeleteStack]
+	require.NotNil(t, granular)
+	assert.Equal(t, "environmentId=2 id=5", granular["resource"])
+	assert.Equal(t, "Stack deleted successfully", granular["summary"])
+	assert.Equal(t, "Destructive action `deleteStack` succeeded on environmentId=2 id=5: Stack deleted successfully", granular["text"])
+
+	meta := byTool["manage_stacks"]
+	require.NotNil(t, meta)
+	assert.Equal(t, "delete_stack", meta["action"])
+	assert.Equal(t, "environmentId=2 id=6", meta["resource"])
+}
+
+// TestDescribeResource verifies that only identifying arguments are reported.
+func TestDescribeResource(t *testing.T) {
+	args := map[string]any{
+		"action":        "delete_helm_release",
+		"environmentId": float64(1),
+		"release":       "web",
+		"namespace":     "prod",
+		"userIds":       []any{float64(2), float64(3)},
+		"password":      "hunter2",
+		"file":          "services: {}",
+	}
+
+	assert.Equal(t, "environmentId=1 namespace=prod release=web userIds=[2 3]", describeResource(args))
+	assert.Empty(t, describeResource(nil))
+}
+
+// TestResultSummary verifies that summaries keep the first line and are truncated.
+func TestResultSummary(t *testing.T) {
+	assert.Equal(t, "done", resultSummary(mcp.NewToolResultText("  done\nmore")))
+	assert.Equal(t, strings.Repeat("é", maxNotificationSummary)+"...", resultSummary(mcp.NewToolResultText(strings.Repeat("é", maxNotificationSummary+5))))
+	assert.Empty(t, resultSummary(&mcp.CallToolResult{}))
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/format"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// metaOutputFormat is the _meta key of a tool call choosing the output format of its
+// result, and of the result telling the format it was rendered in.
+const metaOutputFormat = "outputFormat"
+
+// requestOutputFormat returns the output format requested in the _meta of a tool call,
+// or "" when the call does not choose one.
+func requestOutputFormat(request mcp.CallToolRequest) (string, error) {
+	if request.Params.Meta == nil {
+		return "", nil
+	}
+	value, ok := request.Params.Meta.AdditionalFields[metaOutputFormat]
+	if !ok || value == nil {
+		return "", nil
+	}
+	name, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("_meta.%s must be a string, got %T", metaOutputFormat, value)
+	}
+	return name, nil
+}
+
+// outputFormatMiddleware renders the JSON text content of successful tool results, as
+// returned by jsonResult, in the output format requested by the call, or else in the
+// server output format. Text that is not JSON and error results are left unchanged.
+// The MIME type and language of every text content of a successful result are listed
+// in its _meta.
+func (s *PortainerMCPServer) outputFormatMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, err := requestOutputFormat(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if name == "" {
+			name = s.outputFormat
+		}
+		if name == "" {
+			name = format.JSON
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		formatter, err := format.Get(name)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid output format", err), nil
+		}
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		toolName := requestToolName(request)
+		formatted := false
+		var contentTypes []contentType
+		for i, content := range result.Content {
+			text, ok := content.(mcp.TextContent)
+			if !ok {
+				continue
+			}
+			mimeType := textMIMEType(toolName, text.Text)
+			if mimeType == format.MIMETypeJSON {
+				if rendered, err := formatter.Format([]byte(text.Text)); err != nil {
+					log.Debug().Err(err).Str("tool", request.Params.Name).Msg("failed to format tool result, returning JSON")
+				} else {
+					text.Text = rendered
+					result.Content[i] = text
+					mimeType = formatter.MIMEType()
+					formatted = true
+				}
+			}
+			contentTypes = append(contentTypes, newContentType(i, mimeType))
+		}
+
+		if len(contentTypes) > 0 {
+			setResultMeta(result, metaContentTypes, contentTypes)
+			if formatted {
+				setResultMeta(result, metaOutputFormat, name)
+			}
+		}
+		return result, nil
+	}
+}
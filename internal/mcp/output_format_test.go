@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOutputFormatMiddleware verifies that JSON results are rendered in the server output
+// format, or in the one requested in the _meta of the call.
+func TestOutputFormatMiddleware(t *testing.T) {
+	next := func(result *mcp.CallToolResult) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return result, nil
+		}
+	}
+	stacks := `[{"id":1,"name":"web"},{"id":2,"name":"db"}]`
+
+	tests := []struct {
+		name      string
+		server    string
+		meta      map[string]any
+		result    *mcp.CallToolResult
+		want      string
+		wantMeta  any
+		wantError string
+	}{
+		{name: "default json", result: mcp.NewToolResultText(stacks), want: stacks, wantMeta: "json"},
+		{name: "server format", server: "yaml", result: mcp.NewToolResultText(stacks), want: "- id: 1\n  name: web\n- id: 2\n  name: db", wantMeta: "yaml"},
+		{name: "call format", server: "yaml", meta: map[string]any{"outputFormat": "Table"}, result: mcp.NewToolResultText(stacks), want: "id  name\n1   web\n2   db", wantMeta: "table"},
+		{name: "summary", meta: map[string]any{"outputFormat": "summary"}, result: mcp.NewToolResultText(stacks), want: "2 items\nfields: id, name\n- web (1)\n- db (2)", wantMeta: "summary"},
+		{name: "plain text", server: "yaml", result: mcp.NewToolResultText("Stack deleted successfully"), want: "Stack deleted successfully"},
+		{name: "error result", server: "yaml", result: mcp.NewToolResultError(`{"message":"not found"}`), want: `{"message":"not found"}`},
+		{name: "unknown format", meta: map[string]any{"outputFormat": "xml"}, wantError: `unknown output format "xml"`},
+		{name: "format not a string", meta: map[string]any{"outputFormat": 3}, wantError: "_meta.outputFormat must be a string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &PortainerMCPServer{outputFormat: tt.server}
+			request := CreateMCPRequest(map[string]any{})
+			if tt.meta != nil {
+				request.Params.Meta = &mcp.Meta{AdditionalFields: tt.meta}
+			}
+
+			result, err := s.outputFormatMiddleware(next(tt.result))(context.Background(), request)
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.wantError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.wantError)
+				return
+			}
+			assert.Equal(t, tt.want, text)
+			assert.Equal(t, tt.wantMeta, resultMeta(result, metaOutputFormat))
+		})
+	}
+}
+
+// TestOutputFormatMiddlewareContentTypes verifies the MIME type and language listed for
+// the text contents of a result.
+func TestOutputFormatMiddlewareContentTypes(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		tool   string
+		args   map[string]any
+		result *mcp.CallToolResult
+		want   any
+	}{
+		{
+			name:   "json",
+			tool:   ToolListStacks,
+			result: mcp.NewToolResultText(`[{"id":1}]`),
+			want:   []contentType{{Index: 0, MIMEType: "application/json", Language: "json"}},
+		},
+		{
+			name:   "formatted",
+			server: "table",
+			tool:   ToolListStacks,
+			result: mcp.NewToolResultText(`[{"id":1}]`),
+			want:   []contentType{{Index: 0, MIMEType: "text/plain"}},
+		},
+		{
+			name:   "compose file",
+			server: "yaml",
+			tool:   ToolGetStackFile,
+			result: mcp.NewToolResultText("services:\n  web:\n    image: nginx\n"),
+			want:   []contentType{{Index: 0, MIMEType: "text/x-yaml", Language: "yaml"}},
+		},
+		{
+			name:   "edge job script through a meta-tool",
+			tool:   "manage_edge",
+			args:   map[string]any{"action": "get_edge_job_file"},
+			result: mcp.NewToolResultText("#!/bin/sh\n[ -d /tmp ] && echo ok\n"),
+			want:   []contentType{{Index: 0, MIMEType: "text/x-shellscript", Language: "shell"}},
+		},
+		{
+			name: "several contents",
+			tool: ToolListStacks,
+			result: &mcp.CallToolResult{Content: []mcp.Content{
+				mcp.NewTextContent("Stack list:"),
+				mcp.NewImageContent("", "image/png"),
+				mcp.NewTextContent(`{"id":1}`),
+			}},
+			want: []contentType{{Index: 0, MIMEType: "text/plain"}, {Index: 2, MIMEType: "application/json", Language: "json"}},
+		},
+		{
+			name:   "error result",
+			tool:   ToolListStacks,
+			result: mcp.NewToolResultError(`{"message":"not found"}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &PortainerMCPServer{outputFormat: tt.server}
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+
+			result, err := s.outputFormatMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resultMeta(result, metaContentTypes))
+		})
+	}
+}
+
+// TestNewPortainerMCPServerOutputFormat verifies the validation of the server output format.
+func TestNewPortainerMCPServerOutputFormat(t *testing.T) {
+	server, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithOutputFormat("YAML"))
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", server.outputFormat)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithOutputFormat("xml"))
+	assert.ErrorContains(t, err, "invalid output format")
+}
@@ -0,0 +1,264 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/sessionstate"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// planTTL is how long a previewed plan can be applied.
+	planTTL = 15 * time.Minute
+	// maxPendingPlans caps the number of plans waiting to be applied.
+	maxPendingPlans = 100
+)
+
+// plannableTools lists the compound tools that accept the "plan" parameter. Only these
+// calls are treated as read-only previews when "plan" is set.
+var plannableTools = map[string]bool{
+	ToolDeployStackAndWait:        true,
+	ToolRotateRegistryCredentials: true,
+	ToolOnboardEnvironment:        true,
+	ToolRetagEnvironments:         true,
+	ToolRedeployStacksForImage:    true,
+	ToolOffboardUser:              true,
+	ToolMigrateRegularStackToEdge: true,
+}
+
+// planStep is one Portainer API call of an execution plan.
+type planStep struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// executionPlan is the result of a compound tool called with "plan": the ordered API
+// calls it would perform, and the ID to pass to applyPlan to perform them.
+type executionPlan struct {
+	PlanID    string     `json:"plan_id"`
+	Tool      string     `json:"tool"`
+	Action    string     `json:"action,omitempty"`
+	Steps     []planStep `json:"steps"`
+	Notes     []string   `json:"notes,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+// pendingPlan is a previewed call waiting for applyPlan.
+type pendingPlan struct {
+	session string
+	request mcp.CallToolRequest
+	handler server.ToolHandlerFunc
+	expires time.Time
+}
+
+// planStore keeps previewed calls until they are applied or expire. The zero value is
+// ready to use and it is safe for concurrent use.
+type planStore struct {
+	mu    sync.Mutex
+	plans map[string]*pendingPlan
+	// state persists the pending plans of each session (nil when session state is not
+	// persisted).
+	state *sessionstate.Store
+}
+
+// add stores a pending plan and returns its ID.
+func (p *planStore) add(plan *pendingPlan) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.plans == nil {
+		p.plans = map[string]*pendingPlan{}
+	}
+	now := time.Now()
+	maps.DeleteFunc(p.plans, func(_ string, plan *pendingPlan) bool { return now.After(plan.expires) })
+	if len(p.plans) >= maxPendingPlans {
+		return "", fmt.Errorf("too many pending plans (limit %d): apply or let some expire first", maxPendingPlans)
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate plan ID: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+	p.plans[id] = plan
+	p.persist(plan.session)
+	return id, nil
+}
+
+// peek returns a pending plan without removing it.
+func (p *planStore) peek(id string) (*pendingPlan, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	plan, ok := p.plans[id]
+	if !ok || time.Now().After(plan.expires) {
+		return nil, false
+	}
+	return plan, true
+}
+
+// take removes and returns a pending plan created by the given session.
+func (p *planStore) take(id, session string) (*pendingPlan, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	plan, ok := p.plans[id]
+	if !ok || time.Now().After(plan.expires) {
+		delete(p.plans, id)
+		return nil, fmt.Errorf("plan %s not found or expired", id)
+	}
+	if plan.session != session {
+		return nil, fmt.Errorf("plan %s was created by another session", id)
+	}
+	delete(p.plans, id)
+	p.persist(session)
+	return plan, nil
+}
+
+// persist saves the pending plans of a session. A failure is logged and does not fail
+// the call. The caller must hold p.mu.
+func (p *planStore) persist(session string) {
+	if p.state == nil {
+		return
+	}
+
+	var plans []sessionstate.Plan
+	for id, plan := range p.plans {
+		if plan.session == session {
+			plans = append(plans, sessionstate.Plan{
+				ID:        id,
+				Tool:      plan.request.Params.Name,
+				Arguments: plan.request.GetArguments(),
+				ExpiresAt: plan.expires,
+			})
+		}
+	}
+	if err := p.state.Update(session, func(st *sessionstate.Session) { st.Plans = plans }); err != nil {
+		log.Warn().Err(err).Str("session", session).Msg("failed to persist pending plans")
+	}
+}
+
+// restorePlans loads the pending plans persisted in state, so that a session can apply
+// the plans it previewed before a reconnection or a restart, and persists the plans
+// stored from now on.
+func (s *PortainerMCPServer) restorePlans(state *sessionstate.Store) {
+	s.plans.mu.Lock()
+	defer s.plans.mu.Unlock()
+
+	s.plans.state = state
+	if s.plans.plans == nil {
+		s.plans.plans = map[string]*pendingPlan{}
+	}
+	for session, st := range state.Sessions() {
+		for _, stored := range st.Plans {
+			var request mcp.CallToolRequest
+			request.Params.Name = stored.Tool
+			request.Params.Arguments = stored.Arguments
+
+			handler, ok := s.planHandler(request)
+			if !ok {
+				log.Warn().Str("tool", stored.Tool).Str("plan", stored.ID).Msg("ignoring persisted plan of an unknown tool")
+				continue
+			}
+			s.plans.plans[stored.ID] = &pendingPlan{session: session, request: request, handler: handler, expires: stored.ExpiresAt}
+		}
+	}
+}
+
+// planHandler returns the handler that applies the plans of a plannable tool call.
+// Meta-tool calls are resolved through their action.
+func (s *PortainerMCPServer) planHandler(request mcp.CallToolRequest) (server.ToolHandlerFunc, bool) {
+	name := request.Params.Name
+	if action, ok := request.GetArguments()["action"].(string); ok {
+		if a, found := findMetaAction(name, action); found {
+			name = a.tool
+		}
+	}
+
+	switch name {
+	case ToolDeployStackAndWait:
+		return s.HandleDeployStackAndWait(), true
+	case ToolRotateRegistryCredentials:
+		return s.HandleRotateRegistryCredentials(), true
+	case ToolOnboardEnvironment:
+		return s.HandleOnboardEnvironment(), true
+	case ToolRetagEnvironments:
+		return s.HandleRetagEnvironments(), true
+	case ToolRedeployStacksForImage:
+		return s.HandleRedeployStacksForImage(), true
+	case ToolOffboardUser:
+		return s.HandleOffboardUser(), true
+	case ToolMigrateRegularStackToEdge:
+		return s.HandleMigrateRegularStackToEdge(), true
+	default:
+		return nil, false
+	}
+}
+
+// isPlanRequest reports whether a call asks for an execution plan instead of executing.
+func isPlanRequest(request mcp.CallToolRequest) (bool, error) {
+	return toolgen.NewParameterParser(request).GetBoolean("plan", false)
+}
+
+// previewPlan stores a call for a later applyPlan and returns its execution plan. The
+// stored call is replayed through handler without the "plan" parameter.
+func (s *PortainerMCPServer) previewPlan(ctx context.Context, request mcp.CallToolRequest, steps []planStep, notes []string, handler server.ToolHandlerFunc) (*mcp.CallToolResult, error) {
+	args := maps.Clone(request.GetArguments())
+	delete(args, "plan")
+	replay := request
+	replay.Params.Arguments = args
+
+	expires := time.Now().Add(planTTL)
+	id, err := s.plans.add(&pendingPlan{session: sessionID(ctx), request: replay, handler: handler, expires: expires})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to store plan", err), nil
+	}
+
+	action, _ := args["action"].(string)
+	plan := executionPlan{
+		PlanID:    id,
+		Tool:      request.Params.Name,
+		Action:    action,
+		Steps:     steps,
+		Notes:     notes,
+		ExpiresAt: expires.UTC(),
+	}
+	return jsonResult(plan, "failed to marshal plan")
+}
+
+// AddPlanFeatures registers the tool that applies previewed execution plans.
+func (s *PortainerMCPServer) AddPlanFeatures() {
+	if !s.readOnly {
+		s.addToolIfExists(ToolApplyPlan, s.HandleApplyPlan())
+	}
+}
+
+// HandleApplyPlan returns an MCP tool handler that executes a call previously previewed
+// with "plan". A plan can be applied once, by the session that created it, until it expires.
+func (s *PortainerMCPServer) HandleApplyPlan() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		planID, err := parser.GetString("planId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid planId parameter", err), nil
+		}
+
+		plan, err := s.plans.take(planID, sessionID(ctx))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return plan.handler(ctx, plan.request)
+	}
+}
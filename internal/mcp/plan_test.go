@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// decodePlan unmarshals the execution plan returned by a preview call.
+func decodePlan(t *testing.T, result *mcp.CallToolResult) executionPlan {
+	t.Helper()
+	require.False(t, result.IsError, "preview should succeed")
+	textContent, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+
+	var plan executionPlan
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &plan))
+	return plan
+}
+
+// TestDeployStackAndWaitPlan verifies that a deploy preview performs no write and that
+// applying it runs the deployment once.
+func TestDeployStackAndWaitPlan(t *testing.T) {
+	composeFile := "services:\n  app:\n    image: nginx\n"
+	running := []models.DockerContainer{{ID: "a1", Name: "web-app-1", State: "running", Status: "Up 3 seconds"}}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Name: "Web"}, nil)
+	s := &PortainerMCPServer{cli: mockClient}
+
+	request := CreateMCPRequest(map[string]any{"environmentId": float64(1), "stackId": float64(5), "file": composeFile, "pullImage": true, "plan": true})
+	request.Params.Name = ToolDeployStackAndWait
+	result, err := s.HandleDeployStackAndWait()(context.Background(), request)
+	require.NoError(t, err)
+
+	plan := decodePlan(t, result)
+	assert.Equal(t, ToolDeployStackAndWait, plan.Tool)
+	assert.Len(t, plan.PlanID, 32)
+	assert.WithinDuration(t, time.Now().Add(planTTL), plan.ExpiresAt, time.Minute)
+	require.Len(t, plan.Steps, 3)
+	assert.Equal(t, planStep{
+		Method:      http.MethodPut,
+		Path:        "/api/stacks/5?endpointId=1",
+		Description: `Replace the Compose file of stack "Web" and its environment with 0 variables (pullImage=true, prune=false)`,
+	}, plan.Steps[0])
+	assert.Contains(t, plan.Steps[1].Path, "com.docker.compose.project=web")
+	mockClient.AssertNotCalled(t, "UpdateRegularStack", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockClient.On("UpdateRegularStack", 5, 1, composeFile, map[string]string{}, true, false).Return(models.RegularStack{ID: 5, Name: "web"}, nil).Once()
+	mockClient.On("GetDockerContainers", 1, models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=web"}}).Return(running, nil)
+
+	apply := s.HandleApplyPlan()
+	result, err = apply(context.Background(), CreateMCPRequest(map[string]any{"planId": plan.PlanID}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var report deployStackAndWaitReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+	assert.Equal(t, waitOutcomeReady, report.Outcome)
+
+	result, err = apply(context.Background(), CreateMCPRequest(map[string]any{"planId": plan.PlanID}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "a plan can only be applied once")
+	mockClient.AssertExpectations(t)
+}
+
+// TestRotateRegistryCredentialsPlan verifies the steps of a credential rotation preview.
+func TestRotateRegistryCredentialsPlan(t *testing.T) {
+	registry := models.Registry{ID: 3, Name: "ghcr", URL: "ghcr.io"}
+	stacks := []models.RegularStack{
+		{ID: 1, Name: "api", EndpointID: 2, Status: regularStackStatusActive},
+		{ID: 2, Name: "web", EndpointID: 2, Status: regularStackStatusActive, Git: &models.StackGitConfig{URL: "https://github.com/acme/web.git"}},
+		{ID: 3, Name: "batch", EndpointID: 4, Status: regularStackStatusInactive},
+		{ID: 4, Name: "cache", EndpointID: 2, Status: regularStackStatusActive},
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetRegistry", 3).Return(registry, nil)
+	mockClient.On("GetRegularStacks").Return(stacks, nil)
+	mockClient.On("InspectStackFile", 1).Return("services:\n  api:\n    image: ghcr.io/acme/api\n", nil)
+	mockClient.On("InspectStackFile", 2).Return("services:\n  web:\n    image: ghcr.io/acme/web\n", nil)
+	mockClient.On("InspectStackFile", 3).Return("services:\n  job:\n    image: ghcr.io/acme/batch\n", nil)
+	mockClient.On("InspectStackFile", 4).Return("", fmt.Errorf("file not found"))
+	s := &PortainerMCPServer{cli: mockClient}
+
+	result, err := s.HandleRotateRegistryCredentials()(context.Background(), CreateMCPRequest(map[string]any{
+		"id": float64(3), "password": "new-token", "redeployStacks": true, "plan": true,
+	}))
+	require.NoError(t, err)
+
+	plan := decodePlan(t, result)
+	var calls []string
+	for _, step := range plan.Steps {
+		calls = append(calls, step.Method+" "+step.Path)
+	}
+	assert.Equal(t, []string{
+		"GET /api/registries/3",
+		"PUT /api/registries/3",
+		"GET /api/stacks",
+		"GET /api/stacks/1/file",
+		"GET /api/stacks/2/file",
+		"GET /api/stacks/3/file",
+		"GET /api/stacks/4/file",
+		"GET /api/stacks/1",
+		"GET /api/stacks/1/file",
+		"PUT /api/stacks/1?endpointId=2",
+		"PUT /api/stacks/2/git/redeploy?endpointId=2",
+	}, calls)
+	assert.Len(t, plan.Notes, 3)
+	mockClient.AssertNotCalled(t, "UpdateRegistry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestOnboardEnvironmentPlan verifies that an onboarding preview creates nothing and
+// lists the steps with the ID of the new environment as a placeholder.
+func TestOnboardEnvironmentPlan(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 1, Name: "prod"}}, nil)
+	mockClient.On("GetAccessGroups").Return([]models.AccessGroup{{ID: 3, Name: "Production"}}, nil)
+	s := &PortainerMCPServer{cli: mockClient}
+
+	result, err := s.HandleOnboardEnvironment()(context.Background(), CreateMCPRequest(map[string]any{
+		"name": "prod-1", "type": "agent", "url": "10.0.0.5:9001", "tagIds": []any{float64(1)}, "accessGroupId": float64(3), "plan": true,
+	}))
+	require.NoError(t, err)
+
+	plan := decodePlan(t, result)
+	var calls []string
+	for _, step := range plan.Steps {
+		calls = append(calls, step.Method+" "+step.Path)
+	}
+	assert.Equal(t, []string{
+		"POST /api/endpoints",
+		"PUT /api/endpoints/{id}",
+		"PUT /api/endpoint_groups/3/endpoints/{id}",
+		"POST /api/endpoints/{id}/snapshot",
+	}, calls)
+	assert.Equal(t, `Create the agent environment "prod-1" at 10.0.0.5:9001`, plan.Steps[0].Description)
+	assert.Len(t, plan.Notes, 1)
+	mockClient.AssertNotCalled(t, "CreateEnvironment", mock.Anything)
+}
+
+// TestApplyPlanErrors verifies that plans cannot be applied from another session, after
+// they expire, or when they do not exist.
+func TestApplyPlanErrors(t *testing.T) {
+	s := &PortainerMCPServer{}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("applied"), nil
+	}
+
+	otherSession, err := s.plans.add(&pendingPlan{session: "other", handler: handler, expires: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	expired, err := s.plans.add(&pendingPlan{handler: handler, expires: time.Now().Add(-time.Second)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		planID any
+		errMsg string
+	}{
+		{name: "other session", planID: otherSession, errMsg: "created by another session"},
+		{name: "expired", planID: expired, errMsg: "not found or expired"},
+		{name: "unknown", planID: "missing", errMsg: "not found or expired"},
+		{name: "missing planId", errMsg: "planId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			if tt.planID != nil {
+				args["planId"] = tt.planID
+			}
+			result, err := s.HandleApplyPlan()(context.Background(), CreateMCPRequest(args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.errMsg)
+		})
+	}
+}
+
+// TestPlanStoreLimit verifies that the number of pending plans is bounded and that expired
+// plans free their slot.
+func TestPlanStoreLimit(t *testing.T) {
+	var store planStore
+	for range maxPendingPlans - 1 {
+		_, err := store.add(&pendingPlan{expires: time.Now().Add(time.Minute)})
+		require.NoError(t, err)
+	}
+	_, err := store.add(&pendingPlan{expires: time.Now().Add(-time.Second)})
+	require.NoError(t, err)
+
+	_, err = store.add(&pendingPlan{expires: time.Now().Add(time.Minute)})
+	assert.NoError(t, err, "the expired plan is evicted")
+
+	_, err = store.add(&pendingPlan{expires: time.Now().Add(time.Minute)})
+	assert.ErrorContains(t, err, "too many pending plans")
+}
+
+// TestOperationKindPlans verifies that previews are reads and that applyPlan calls are
+// classified as the call they apply.
+func TestOperationKindPlans(t *testing.T) {
+	tools := budgetTestTools()
+	tools[ToolDeployStackAndWait] = mcp.NewTool(ToolDeployStackAndWait, mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: boolPtr(false), DestructiveHint: boolPtr(false)}))
+	tools[ToolApplyPlan] = mcp.NewTool(ToolApplyPlan, mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: boolPtr(false), DestructiveHint: boolPtr(true)}))
+	s := &PortainerMCPServer{tools: tools}
+
+	deploy := CreateMCPRequest(map[string]any{"environmentId": float64(1)})
+	deploy.Params.Name = ToolDeployStackAndWait
+	id, err := s.plans.add(&pendingPlan{request: deploy, expires: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want string
+	}{
+		{name: "plan preview", tool: ToolDeployStackAndWait, args: map[string]any{"plan": true}, want: operationRead},
+		{name: "meta plan preview", tool: "manage_stacks", args: map[string]any{"action": "deploy_stack_and_wait", "plan": true}, want: operationRead},
+		{name: "plan ignored by other tools", tool: ToolDeleteStack, args: map[string]any{"plan": true}, want: operationDestructive},
+		{name: "apply known plan", tool: ToolApplyPlan, args: map[string]any{"planId": id}, want: operationWrite},
+		{name: "apply through meta-tool", tool: "manage_system", args: map[string]any{"action": "apply_plan", "planId": id}, want: operationWrite},
+		{name: "apply unknown plan", tool: ToolApplyPlan, args: map[string]any{"planId": "missing"}, want: operationDestructive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+			assert.Equal(t, tt.want, s.operationKind(request))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/audit"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/policy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// auditEventPolicy is the audit event of the policy decisions.
+const auditEventPolicy = "policy"
+
+// policyAttributeEnvironmentName is the policy attribute holding the name of the
+// environment targeted by a call.
+const policyAttributeEnvironmentName = "environmentName"
+
+var (
+	toolActionNamesOnce sync.Once
+	toolActionNamesMap  map[string][]string
+)
+
+// toolActionNames maps every granular tool to the names of its meta-tool actions: the
+// action alone and the meta-tool action joined by a dot. Like metaActionIndex, it is
+// built on first use by a function rather than a package variable.
+func toolActionNames() map[string][]string {
+	toolActionNamesOnce.Do(func() {
+		toolActionNamesMap = map[string][]string{}
+		for _, def := range metaToolDefinitions() {
+			for _, a := range def.actions {
+				toolActionNamesMap[a.tool] = append(toolActionNamesMap[a.tool], a.name, def.name+"."+a.name)
+			}
+		}
+	})
+	return toolActionNamesMap
+}
+
+// policyMiddleware evaluates the policy rules before any write tool call runs, and
+// rejects the calls they deny. Meta-tool calls are resolved through their action, and
+// applyPlan calls are evaluated as the call they apply. Every decision is recorded in
+// the audit log.
+func (s *PortainerMCPServer) policyMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.operationKind(request) == operationRead {
+			return next(ctx, request)
+		}
+
+		call := request
+		if requestToolName(request) == ToolApplyPlan {
+			if id, ok := request.GetArguments()["planId"].(string); ok {
+				if plan, found := s.plans.peek(id); found {
+					call = plan.request
+				}
+			}
+		}
+
+		decision, err := s.evaluatePolicy(call)
+		if err != nil {
+			s.recordPolicyDecision(ctx, call, policy.Decision{Reason: err.Error()})
+			return mcp.NewToolResultErrorFromErr("failed to evaluate the policy rules", err), nil
+		}
+		s.recordPolicyDecision(ctx, call, decision)
+		if !decision.Allowed {
+			message := fmt.Sprintf("denied by policy rule %q", decision.Rule)
+			if decision.Reason != "" {
+				message += ": " + decision.Reason
+			}
+			return newToolResultErrorWithCode(ErrorCodeForbidden, message), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// evaluatePolicy evaluates the policy rules for a tool call. When the rules match on the
+// environment name, a call targeting several environments is evaluated once for each of
+// them and is denied if any evaluation denies it. It fails when the name of a targeted
+// environment cannot be read, rather than evaluating the call without it.
+func (s *PortainerMCPServer) evaluatePolicy(request mcp.CallToolRequest) (policy.Decision, error) {
+	call := policyCall(request)
+	ids := requestEnvironmentIDs(request)
+	if len(ids) == 0 || !s.policy.UsesAttribute(policyAttributeEnvironmentName) {
+		return s.policy.Evaluate(call), nil
+	}
+
+	// The name is read again for every call, so that a renamed environment cannot
+	// escape a rule through a cached name.
+	var decision policy.Decision
+	for i, id := range ids {
+		environment, err := s.cli.GetEnvironment(id)
+		if err != nil {
+			return policy.Decision{}, fmt.Errorf("failed to get the name of environment %d: %w", id, err)
+		}
+		attributes := maps.Clone(call.Attributes)
+		attributes[policyAttributeEnvironmentName] = environment.Name
+		d := s.policy.Evaluate(policy.Call{Names: call.Names, Attributes: attributes})
+		if !d.Allowed {
+			return d, nil
+		}
+		if i == 0 {
+			decision = d
+		}
+	}
+	return decision, nil
+}
+
+// policyCall describes a tool call to the policy engine: its names and its scalar
+// arguments as text.
+func policyCall(request mcp.CallToolRequest) policy.Call {
+	name := requestToolName(request)
+	call := policy.Call{
+		Names:      append([]string{name}, toolActionNames()[name]...),
+		Attributes: map[string]string{},
+	}
+
+	for key, value := range request.GetArguments() {
+		switch v := value.(type) {
+		case string:
+			call.Attributes[key] = v
+		case float64:
+			call.Attributes[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			call.Attributes[key] = strconv.Itoa(v)
+		case bool:
+			call.Attributes[key] = strconv.FormatBool(v)
+		}
+	}
+	return call
+}
+
+// recordPolicyDecision records a policy decision in the audit log, or in the server log
+// when no audit log is configured.
+func (s *PortainerMCPServer) recordPolicyDecision(ctx context.Context, request mcp.CallToolRequest, decision policy.Decision) {
+	action, _ := request.GetArguments()["action"].(string)
+	entry := audit.Entry{
+		Event:    auditEventPolicy,
+		Session:  sessionID(ctx),
+		Tool:     request.Params.Name,
+		Action:   action,
+		Resource: describeResource(request.GetArguments()),
+		Decision: policy.EffectAllow,
+		Rule:     decision.Rule,
+		Reason:   decision.Reason,
+	}
+	if !decision.Allowed {
+		entry.Decision = policy.EffectDeny
+	}
+
+	if s.auditLog == nil {
+		log.Info().Str("tool", entry.Tool).Str("action", entry.Action).Str("resource", entry.Resource).
+			Str("decision", entry.Decision).Str("rule", entry.Rule).Msg("policy decision")
+		return
+	}
+	if err := s.auditLog.Record(entry); err != nil {
+		log.Warn().Err(err).Str("tool", entry.Tool).Msg("failed to record policy decision in the audit log")
+	}
+}
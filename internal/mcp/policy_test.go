@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/audit"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/policy"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolicyMiddleware verifies that the policy rules are evaluated before write calls,
+// and that their decisions are recorded in the audit log.
+func TestPolicyMiddleware(t *testing.T) {
+	engine, err := policy.New([]policy.Rule{
+		{Name: "protect-prod", Tools: []string{"delete_environment"}, Where: map[string]string{"environmentName": "prod-*"}, Reason: "production environments cannot be deleted"},
+		{Name: "no-force", Tools: []string{"manage_stacks.*"}, Where: map[string]string{"force": "true"}},
+		{Name: "no-prod-jobs", Tools: []string{ToolCreateEdgeJob}, Where: map[string]string{"environmentName": "prod-*"}},
+	})
+	require.NoError(t, err)
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := audit.Open(auditPath)
+	require.NoError(t, err)
+	defer auditLog.Close()
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironment", 3).Return(models.Environment{ID: 3, Name: "prod-eu"}, nil)
+	mockClient.On("GetEnvironment", 4).Return(models.Environment{ID: 4, Name: "staging"}, nil)
+	mockClient.On("GetEnvironment", 5).Return(models.Environment{}, errors.New("connection refused"))
+
+	tools := budgetTestTools()
+	tools[ToolDeleteEnvironment] = mcp.NewTool(ToolDeleteEnvironment, mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: boolPtr(false), DestructiveHint: boolPtr(true)}))
+	s := &PortainerMCPServer{cli: mockClient, tools: tools, policy: engine, auditLog: auditLog}
+
+	calls := 0
+	handler := s.policyMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name      string
+		tool      string
+		args      map[string]any
+		wantError string
+		wantCode  string
+	}{
+		{name: "denied granular call", tool: ToolDeleteEnvironment, args: map[string]any{"id": float64(3)}, wantError: `denied by policy rule "protect-prod": production environments cannot be deleted`},
+		{name: "denied meta-tool call", tool: "manage_environments", args: map[string]any{"action": "delete_environment", "id": float64(3)}, wantError: `denied by policy rule "protect-prod"`},
+		{name: "allowed environment", tool: ToolDeleteEnvironment, args: map[string]any{"id": float64(4)}},
+		{name: "denied argument", tool: "manage_stacks", args: map[string]any{"action": "delete_stack", "id": float64(7), "force": true}, wantError: `denied by policy rule "no-force"`},
+		{name: "allowed write", tool: ToolStartStack, args: map[string]any{"id": float64(7)}},
+		{name: "read not evaluated", tool: ToolListStacks},
+		{name: "denied environment among several", tool: ToolCreateEdgeJob, args: map[string]any{"endpoints": []any{float64(4), float64(3)}}, wantError: `denied by policy rule "no-prod-jobs"`},
+		{name: "allowed environments", tool: ToolCreateEdgeJob, args: map[string]any{"endpoints": []any{float64(4)}}},
+		{name: "unreadable environment name", tool: ToolDeleteEnvironment, args: map[string]any{"id": float64(5)}, wantError: "failed to get the name of environment 5", wantCode: ErrorCodeUpstreamUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			if tt.wantError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.wantError)
+				wantCode := ErrorCodeForbidden
+				if tt.wantCode != "" {
+					wantCode = tt.wantCode
+				}
+				assert.Equal(t, wantCode, resultErrorCode(result))
+				assert.Zero(t, calls, "the handler of a denied call does not run")
+				return
+			}
+			assert.False(t, result.IsError)
+			assert.Equal(t, 1, calls)
+		})
+	}
+
+	file, err := os.Open(auditPath)
+	require.NoError(t, err)
+	defer file.Close()
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry audit.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, entries, 8, "every write call is recorded, reads are not")
+	assert.Equal(t, "policy", entries[0].Event)
+	assert.Equal(t, ToolDeleteEnvironment, entries[0].Tool)
+	assert.Equal(t, "id=3", entries[0].Resource)
+	assert.Equal(t, "deny", entries[0].Decision)
+	assert.Equal(t, "protect-prod", entries[0].Rule)
+	assert.Equal(t, "delete_environment", entries[1].Action)
+	assert.Equal(t, "allow", entries[2].Decision)
+	assert.Empty(t, entries[2].Rule)
+	assert.Equal(t, "no-force", entries[3].Rule)
+	assert.Equal(t, "allow", entries[4].Decision)
+	assert.Equal(t, "no-prod-jobs", entries[5].Rule)
+	assert.Equal(t, "allow", entries[6].Decision)
+	assert.Equal(t, "deny", entries[7].Decision, "a call whose environment name cannot be read is denied")
+	assert.Contains(t, entries[7].Reason, "failed to get the name of environment 5")
+}
+
+// TestNewPortainerMCPServerPolicy verifies loading the policy and opening the audit log
+// at startup.
+func TestNewPortainerMCPServerPolicy(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("rules:\n  - tools: [delete_environment]\n    where:\n      environmentName: prod-*\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("rules:\n  - effect: maybe\n"), 0o600))
+
+	server, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithPolicy(valid), WithAuditLog(filepath.Join(dir, "audit.log")))
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.policy.Len())
+	require.NotNil(t, server.auditLog)
+	require.NoError(t, server.auditLog.Close())
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithPolicy(invalid))
+	assert.ErrorContains(t, err, "failed to load policy")
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithAuditLog(filepath.Join(dir, "missing", "audit.log")))
+	assert.ErrorContains(t, err, "failed to open audit log")
+}
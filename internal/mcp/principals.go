@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/client"
+	"gopkg.in/yaml.v3"
+)
+
+// principalConfig describes a client of the HTTP transports in a principals file: the
+// bearer token it authenticates with, and the Portainer API key of the user it acts as.
+type principalConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Token is the bearer token of the client. TokenEnv names an environment variable
+	// holding it instead, to keep the token out of the file.
+	Token    string `yaml:"token" json:"token"`
+	TokenEnv string `yaml:"tokenEnv" json:"tokenEnv"`
+	// APIKey is the Portainer API key of the user. APIKeyEnv names an environment variable
+	// holding it instead.
+	APIKey    string `yaml:"apiKey" json:"apiKey"`
+	APIKeyEnv string `yaml:"apiKeyEnv" json:"apiKeyEnv"`
+}
+
+// principalsFile is the content of a principals file.
+type principalsFile struct {
+	Principals []principalConfig `yaml:"principals" json:"principals"`
+}
+
+// principal is a client of the HTTP transports acting as its own Portainer user, and the
+// server making its tool calls with the API key of that user.
+type principal struct {
+	name   string
+	token  string
+	server *PortainerMCPServer
+}
+
+// newPrincipalClient creates the Portainer client of a principal. It is a variable so
+// tests can replace the clients of the principals.
+var newPrincipalClient = func(serverURL, apiKey string, skipTLSVerify bool) PortainerClient {
+	return client.NewPortainerClient(serverURL, apiKey, client.WithSkipTLSVerify(skipTLSVerify))
+}
+
+// resolveSecret returns a secret of a principals file, given inline or by the name of an
+// environment variable.
+func resolveSecret(principal, field, value, env string) (string, error) {
+	if env != "" {
+		if value != "" {
+			return "", fmt.Errorf("principal %q sets both %s and %sEnv", principal, field, field)
+		}
+		value = os.Getenv(env)
+	}
+	if value == "" {
+		return "", fmt.Errorf("principal %q has no %s (set %s, or %sEnv to a non-empty environment variable)", principal, field, field, field)
+	}
+	return value, nil
+}
+
+// loadPrincipalConfigs reads a principals file (YAML or JSON), checks the principal names
+// and tokens, and resolves the secrets read from environment variables.
+func loadPrincipalConfigs(path string) ([]principalConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read principals file: %w", err)
+	}
+
+	var file principalsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse principals file %s: %w", path, err)
+	}
+	if len(file.Principals) == 0 {
+		return nil, fmt.Errorf("principals file %s defines no principal", path)
+	}
+
+	var names, tokens []string
+	for i := range file.Principals {
+		p := &file.Principals[i]
+		switch {
+		case !instanceNamePattern.MatchString(p.Name):
+			return nil, fmt.Errorf("invalid principal name %q: use letters, digits, '.', '_' and '-'", p.Name)
+		case slices.Contains(names, p.Name):
+			return nil, fmt.Errorf("duplicate principal name %q", p.Name)
+		}
+		names = append(names, p.Name)
+
+		if p.Token, err = resolveSecret(p.Name, "token", p.Token, p.TokenEnv); err != nil {
+			return nil, err
+		}
+		if p.APIKey, err = resolveSecret(p.Name, "apiKey", p.APIKey, p.APIKeyEnv); err != nil {
+			return nil, err
+		}
+		if slices.Contains(tokens, p.Token) {
+			return nil, fmt.Errorf("principal %q uses the token of another principal", p.Name)
+		}
+		tokens = append(tokens, p.Token)
+	}
+
+	return file.Principals, nil
+}
+
+// newPrincipals creates the server of each principal of the principals file, with the
+// options of the primary server and the API key of the principal. The servers keep their
+// own sessions, plans, budgets and session state, the latter in a subdirectory of the
+// session state directory, so that a principal can never adopt the session of another.
+// They share the stack history, delete journal, maintenance records, trend history, audit
+// log and statistics of the primary server, which alone runs the scheduled tasks, the
+// trend sampler and the metrics endpoint.
+func (s *PortainerMCPServer) newPrincipals(serverURL, toolsPath string, opts serverOptions) ([]principal, error) {
+	if opts.transport == TransportStdio {
+		return nil, fmt.Errorf("principals require the %s or %s transport", TransportSSE, TransportStreamableHTTP)
+	}
+
+	configs, err := loadPrincipalConfigs(opts.principalsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	principals := make([]principal, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Token == opts.httpAuthToken {
+			return nil, fmt.Errorf("principal %q uses the HTTP auth token of the server", cfg.Name)
+		}
+
+		principalOpts := opts
+		principalOpts.principalsPath = ""
+		principalOpts.client = newPrincipalClient(serverURL, cfg.APIKey, opts.skipTLSVerify)
+		principalOpts.stackHistoryDir = ""
+		principalOpts.deleteJournalDir = ""
+		principalOpts.maintenanceDir = ""
+		principalOpts.trendInterval = 0
+		principalOpts.auditLogPath = ""
+		principalOpts.metricsAddr = ""
+		principalOpts.scheduledTasksPath = ""
+		if opts.sessionStateDir != "" {
+			principalOpts.sessionStateDir = filepath.Join(opts.sessionStateDir, "principals", cfg.Name)
+		}
+
+		principalServer, err := newPortainerMCPServer(serverURL, cfg.APIKey, toolsPath, &principalOpts)
+		if err != nil {
+			return nil, fmt.Errorf("principal %q: %w", cfg.Name, err)
+		}
+		principalServer.stackHistory = s.stackHistory
+		principalServer.deleteJournal = s.deleteJournal
+		principalServer.maintenance = s.maintenance
+		principalServer.trends = s.trends
+		principalServer.trendInterval = s.trendInterval
+		principalServer.auditLog = s.auditLog
+		principalServer.stats = s.stats
+
+		principals = append(principals, principal{name: cfg.Name, token: cfg.Token, server: principalServer})
+	}
+	return principals, nil
+}
@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePrincipals writes a principals file and returns its path.
+func writePrincipals(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "principals.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// TestLoadPrincipalConfigs verifies the parsing and validation of principals files.
+func TestLoadPrincipalConfigs(t *testing.T) {
+	t.Setenv("ALICE_API_KEY", "ptr_alice")
+	configs, err := loadPrincipalConfigs(writePrincipals(t, `
+principals:
+  - name: alice
+    token: alice-token
+    apiKeyEnv: ALICE_API_KEY
+  - name: bob
+    token: bob-token
+    apiKey: ptr_bob
+`))
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	assert.Equal(t, "ptr_alice", configs[0].APIKey)
+	assert.Equal(t, "bob-token", configs[1].Token)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "no principal", content: "principals: []", wantErr: "defines no principal"},
+		{name: "invalid name", content: "principals: [{name: 'a b', token: t, apiKey: k}]", wantErr: `invalid principal name "a b"`},
+		{name: "duplicate name", content: "principals: [{name: a, token: t1, apiKey: k}, {name: a, token: t2, apiKey: k}]", wantErr: `duplicate principal name "a"`},
+		{name: "missing token", content: "principals: [{name: a, apiKey: k}]", wantErr: `principal "a" has no token`},
+		{name: "token and tokenEnv", content: "principals: [{name: a, token: t, tokenEnv: T, apiKey: k}]", wantErr: "sets both token and tokenEnv"},
+		{name: "missing API key", content: "principals: [{name: a, token: t, apiKeyEnv: UNSET_PRINCIPAL_KEY}]", wantErr: `principal "a" has no apiKey`},
+		{name: "shared token", content: "principals: [{name: a, token: t, apiKey: k1}, {name: b, token: t, apiKey: k2}]", wantErr: `principal "b" uses the token of another principal`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadPrincipalConfigs(writePrincipals(t, tt.content))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err = loadPrincipalConfigs(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read principals file")
+}
+
+// TestPrincipals verifies that each principal is served by its own server, making its
+// calls with its own API key, and that the sessions of a principal cannot be used with the
+// token of another.
+func TestPrincipals(t *testing.T) {
+	clients := map[string]*MockPortainerClient{}
+	original := newPrincipalClient
+	t.Cleanup(func() { newPrincipalClient = original })
+	newPrincipalClient = func(serverURL, apiKey string, skipTLSVerify bool) PortainerClient {
+		cli := &MockPortainerClient{}
+		cli.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 1, Name: "tag-of-" + apiKey}}, nil)
+		clients[apiKey] = cli
+		return cli
+	}
+
+	path := writePrincipals(t, `
+principals:
+  - name: alice
+    token: alice-token
+    apiKey: ptr_alice
+  - name: bob
+    token: bob-token
+    apiKey: ptr_bob
+`)
+	primary := &MockPortainerClient{}
+	primary.On("GetEnvironmentTags").Return([]models.EnvironmentTag{{ID: 1, Name: "tag-of-server"}}, nil)
+	options := []ServerOption{WithClient(primary), WithDisableVersionCheck(true), WithGranularTools(true), WithTransport(TransportStreamableHTTP, ""), WithHTTPAuthToken("s3cret")}
+
+	_, err := NewPortainerMCPServer("https://portainer.example.com", "token", "../tooldef/tools.yaml", WithClient(primary), WithDisableVersionCheck(true), WithPrincipals(path))
+	assert.ErrorContains(t, err, "principals require the sse or http transport")
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "../tooldef/tools.yaml", append(options, WithHTTPAuthToken("bob-token"), WithPrincipals(path))...)
+	assert.ErrorContains(t, err, `principal "bob" uses the HTTP auth token of the server`)
+
+	stateDir := t.TempDir()
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "../tooldef/tools.yaml", append(options, WithPrincipals(path), WithSessionState(stateDir))...)
+	require.NoError(t, err)
+	require.Len(t, s.principals, 2)
+	assert.Same(t, s.stats, s.principals[0].server.stats, "the statistics are shared")
+	s.RegisterTools()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serveStreamableHTTP(ctx, listener)
+	}()
+	defer func() {
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(2 * httpShutdownTimeout):
+			t.Fatal("Streamable HTTP transport not stopped")
+		}
+	}()
+	endpoint := "http://" + listener.Addr().String() + httpEndpointPath
+
+	send := func(token, session, body string) (*http.Response, string) {
+		t.Helper()
+		request, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		if session != "" {
+			request.Header.Set("Mcp-Session-Id", session)
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response, string(data)
+	}
+	const initialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	const listTags = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"listEnvironmentTags","arguments":{}}}`
+
+	sessions := map[string]string{}
+	for _, token := range []string{"s3cret", "alice-token", "bob-token"} {
+		response, body := send(token, "", initialize)
+		require.Equal(t, http.StatusOK, response.StatusCode, body)
+		sessions[token] = response.Header.Get("Mcp-Session-Id")
+	}
+
+	_, body := send("alice-token", sessions["alice-token"], listTags)
+	assert.Contains(t, body, "tag-of-ptr_alice")
+	_, body = send("bob-token", sessions["bob-token"], listTags)
+	assert.Contains(t, body, "tag-of-ptr_bob")
+	_, body = send("s3cret", sessions["s3cret"], listTags)
+	assert.Contains(t, body, "tag-of-server")
+	clients["ptr_alice"].AssertNumberOfCalls(t, "GetEnvironmentTags", 1)
+	clients["ptr_bob"].AssertNumberOfCalls(t, "GetEnvironmentTags", 1)
+	primary.AssertNumberOfCalls(t, "GetEnvironmentTags", 1)
+
+	response, _ := send("bob-token", sessions["alice-token"], listTags)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode, "the session of a principal is unknown to the others")
+	response, _ = send("eve-token", "", initialize)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.DirExists(t, filepath.Join(stateDir, "principals", "alice"))
+}
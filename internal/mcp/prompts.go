@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// workflowPrompt is an MCP prompt giving the steps of a common Portainer workflow, with
+// the tools to call at each step.
+type workflowPrompt struct {
+	name        string
+	description string
+	arguments   []promptArgument
+	// tools are the tools the workflow needs: the prompt is only listed when they are all
+	// available.
+	tools []string
+	// instructions returns the steps of the workflow for the given arguments.
+	instructions func(args map[string]string) string
+}
+
+// promptArgument is an argument of a workflow prompt.
+type promptArgument struct {
+	name        string
+	description string
+	required    bool
+	// numeric arguments are resource IDs.
+	numeric bool
+}
+
+// workflowPrompts are the prompts of the common Portainer workflows.
+var workflowPrompts = []workflowPrompt{
+	{
+		name:        "deploy_compose_stack",
+		description: "Deploy a Docker Compose stack to an environment, review its file first and check that its containers become healthy.",
+		arguments: []promptArgument{
+			{name: "environmentId", description: "Numeric ID of the Docker environment to deploy to", required: true, numeric: true},
+			{name: "name", description: "Name of the stack", required: true},
+			{name: "file", description: "Docker Compose file content (asked for when not given)"},
+		},
+		tools: []string{ToolGetEnvironment, ToolListRegularStacks, ToolDeployStackAndWait, ToolGetContainerLogs},
+		instructions: func(args map[string]string) string {
+			file := "Ask me for the Docker Compose file before going further."
+			if args["file"] != "" {
+				file = "The Docker Compose file is:\n\n```yaml\n" + args["file"] + "\n```"
+			}
+			return fmt.Sprintf(`Deploy the Docker Compose stack %q to environment %s.
+
+%s
+
+1. Call %s for environment %s and check that it is an active Docker environment.
+2. Call %s and check whether a stack named %q already exists on the environment. If it does, ask me whether to update it, and pass its ID as stackId.
+3. Review the Compose file: flag images without a pinned tag or using latest, secrets written in clear text instead of environment variables, and published ports that may conflict with other stacks.
+4. Call %s with plan set to true, show me the planned calls, and wait for my confirmation before running it again without plan.
+5. Report the outcome. When it is failed or timeout, call %s for the containers that are not ready and explain the likely cause.`,
+				args["name"], args["environmentId"], file,
+				toolReference(ToolGetEnvironment), args["environmentId"],
+				toolReference(ToolListRegularStacks), args["name"],
+				toolReference(ToolDeployStackAndWait),
+				toolReference(ToolGetContainerLogs))
+		},
+	},
+	{
+		name:        "triage_environment",
+		description: "Find out why an environment is failing from its status, recent events and container logs, without changing anything.",
+		arguments: []promptArgument{
+			{name: "environmentId", description: "Numeric ID of the failing environment", required: true, numeric: true},
+		},
+		tools: []string{ToolGetEnvironment, ToolBuildTimeline, ToolListContainers, ToolGetContainerLogs},
+		instructions: func(args map[string]string) string {
+			return fmt.Sprintf(`Triage environment %s, which is reported as failing. Only read: do not change anything without asking me.
+
+1. Call %s for environment %s and note its status, type and the time of its last snapshot. A down environment usually means the agent or the network is the problem.
+2. Call %s for the last hours and list the entries flagged as warnings: containers dying with a non-zero exit code, OOM kills, failing health checks.
+3. For a Docker environment, call %s with the exited or unhealthy containers, then %s for the ones involved in the warnings. For a Kubernetes environment, use %s and %s on the failing pods instead.
+4. Summarize the probable cause with the evidence supporting it, the affected workloads, and the actions you recommend, most likely fix first.`,
+				args["environmentId"],
+				toolReference(ToolGetEnvironment), args["environmentId"],
+				toolReference(ToolBuildTimeline),
+				toolReference(ToolListContainers), toolReference(ToolGetContainerLogs),
+				toolReference(ToolTopKubernetesPods), toolReference(ToolDescribeKubernetesResource))
+		},
+	},
+	{
+		name:        "rotate_registry_credentials",
+		description: "Rotate the password or access token of a registry and redeploy the stacks pulling images from it.",
+		arguments: []promptArgument{
+			{name: "registryId", description: "Numeric ID of the registry", required: true, numeric: true},
+		},
+		tools: []string{ToolGetRegistry, ToolRotateRegistryCredentials, ToolApplyPlan},
+		instructions: func(args map[string]string) string {
+			return fmt.Sprintf(`Rotate the credentials of registry %s.
+
+1. Call %s for registry %s and confirm its name, URL and current username with me.
+2. Ask me for the new password or access token, and a new username if it changes. Never repeat the password back.
+3. Call %s with plan set to true and redeployStacks set to true. Show me the stacks that pull images from the registry and will be redeployed, and the planned calls.
+4. Once I confirm, call %s with the plan ID.
+5. Report the stacks that were redeployed, and those that could not be, such as stacks whose images use variable interpolation, so that I can redeploy them by hand.`,
+				args["registryId"],
+				toolReference(ToolGetRegistry), args["registryId"],
+				toolReference(ToolRotateRegistryCredentials),
+				toolReference(ToolApplyPlan))
+		},
+	},
+}
+
+// AddPrompts registers the prompts of the common Portainer workflows, whose steps name the
+// tools to call, so that clients can discover the recommended workflows. A prompt is only
+// registered when all its tools are available: defined, allowed to the API token and,
+// in read-only mode, read-only.
+func (s *PortainerMCPServer) AddPrompts() {
+	for _, p := range s.principals {
+		p.server.AddPrompts()
+	}
+
+	for _, p := range workflowPrompts {
+		if !s.promptAvailable(p) {
+			continue
+		}
+
+		opts := []mcp.PromptOption{mcp.WithPromptDescription(p.description)}
+		for _, arg := range p.arguments {
+			argOpts := []mcp.ArgumentOption{mcp.ArgumentDescription(arg.description)}
+			if arg.required {
+				argOpts = append(argOpts, mcp.RequiredArgument())
+			}
+			opts = append(opts, mcp.WithArgument(arg.name, argOpts...))
+		}
+		s.srv.AddPrompt(mcp.NewPrompt(p.name, opts...), s.HandleGetWorkflowPrompt(p))
+	}
+}
+
+// promptAvailable reports whether all the tools of a workflow prompt are available.
+func (s *PortainerMCPServer) promptAvailable(p workflowPrompt) bool {
+	for _, tool := range p.tools {
+		if _, ok := s.toolDefinition(tool); !ok || !s.toolAllowed(tool) {
+			return false
+		}
+		if _, readOnly := readOnlyToolHandler(tool); s.readOnly && !readOnly {
+			return false
+		}
+	}
+	return true
+}
+
+// HandleGetWorkflowPrompt returns an MCP prompt handler returning the steps of a workflow
+// for the given arguments.
+func (s *PortainerMCPServer) HandleGetWorkflowPrompt(p workflowPrompt) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := map[string]string{}
+		for _, arg := range p.arguments {
+			value := strings.TrimSpace(request.Params.Arguments[arg.name])
+			if value == "" {
+				if arg.required {
+					return nil, fmt.Errorf("missing required argument %q", arg.name)
+				}
+				continue
+			}
+			if id, err := strconv.Atoi(value); arg.numeric && (err != nil || id <= 0) {
+				return nil, fmt.Errorf("invalid argument %q: %q is not a positive ID", arg.name, value)
+			}
+			args[arg.name] = value
+		}
+
+		return mcp.NewGetPromptResult(p.description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(p.instructions(args))),
+		}), nil
+	}
+}
+
+// toolReference names a tool in the instructions of a prompt, with the meta-tool action
+// running it, so that the instructions hold in both tool registration modes.
+func toolReference(tool string) string {
+	for _, def := range metaToolDefinitions() {
+		for _, action := range def.actions {
+			if action.tool == tool {
+				return fmt.Sprintf("`%s` (`%s` action `%s`)", tool, def.name, action.name)
+			}
+		}
+	}
+	return fmt.Sprintf("`%s`", tool)
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listedPromptNames registers the prompts on a server and returns the names listed by
+// prompts/list.
+func listedPromptNames(t *testing.T, s *PortainerMCPServer) []string {
+	t.Helper()
+	s.srv = server.NewMCPServer("Test Server", "1.0.0")
+	s.AddPrompts()
+	message := s.srv.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`))
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", message)
+	result, ok := response.Result.(mcp.ListPromptsResult)
+	require.True(t, ok)
+
+	names := make([]string, 0, len(result.Prompts))
+	for _, prompt := range result.Prompts {
+		names = append(names, prompt.Name)
+	}
+	return names
+}
+
+// TestAddPrompts verifies that the prompts are only listed when their tools are available.
+func TestAddPrompts(t *testing.T) {
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+	for _, p := range workflowPrompts {
+		for _, tool := range p.tools {
+			assert.Contains(t, tools, tool, "prompt %s", p.name)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"deploy_compose_stack", "triage_environment", "rotate_registry_credentials"},
+		listedPromptNames(t, &PortainerMCPServer{tools: tools}))
+	assert.ElementsMatch(t, []string{"triage_environment"},
+		listedPromptNames(t, &PortainerMCPServer{tools: tools, readOnly: true}), "write workflows are hidden in read-only mode")
+
+	delete(tools, ToolBuildTimeline)
+	assert.NotContains(t, listedPromptNames(t, &PortainerMCPServer{tools: tools}), "triage_environment")
+}
+
+// TestHandleGetWorkflowPrompt verifies the instructions of a prompt and the validation of
+// its arguments.
+func TestHandleGetWorkflowPrompt(t *testing.T) {
+	s := &PortainerMCPServer{}
+	get := func(name string, args map[string]string) (*mcp.GetPromptResult, error) {
+		for _, p := range workflowPrompts {
+			if p.name == name {
+				var request mcp.GetPromptRequest
+				request.Params.Name = name
+				request.Params.Arguments = args
+				return s.HandleGetWorkflowPrompt(p)(context.Background(), request)
+			}
+		}
+		t.Fatalf("unknown prompt %s", name)
+		return nil, nil
+	}
+
+	result, err := get("deploy_compose_stack", map[string]string{"environmentId": "3", "name": "web", "file": "services:\n  web:\n    image: nginx:1.27"})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, result.Messages[0].Role)
+	text := result.Messages[0].Content.(mcp.TextContent).Text
+	assert.Contains(t, text, `Deploy the Docker Compose stack "web" to environment 3.`)
+	assert.Contains(t, text, "image: nginx:1.27")
+	assert.Contains(t, text, "`deployStackAndWait` (`manage_stacks` action `deploy_stack_and_wait`)")
+
+	result, err = get("deploy_compose_stack", map[string]string{"environmentId": "3", "name": "web"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Messages[0].Content.(mcp.TextContent).Text, "Ask me for the Docker Compose file")
+
+	_, err = get("triage_environment", map[string]string{})
+	assert.ErrorContains(t, err, `missing required argument "environmentId"`)
+
+	_, err = get("rotate_registry_credentials", map[string]string{"registryId": "docker-hub"})
+	assert.ErrorContains(t, err, "is not a positive ID")
+}
+
+// TestToolReference verifies that the tools are named with their meta-tool action.
+func TestToolReference(t *testing.T) {
+	assert.Equal(t, "`getRegistry` (`manage_registries` action `get_registry`)", toolReference(ToolGetRegistry))
+	assert.Equal(t, "`unknownTool`", toolReference("unknownTool"))
+}
@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+)
+
+// toolAccess is the Portainer access that a tool requires beyond an authenticated user.
+type toolAccess int
+
+const (
+	// accessAdmin requires an administrator token.
+	accessAdmin toolAccess = iota + 1
+	// accessAdminOrTeamLeader requires an administrator token or the leader of a team.
+	accessAdminOrTeamLeader
+)
+
+// restrictedTools lists the tools that call Portainer endpoints restricted to administrators
+// (or team leaders), following the access policies of the Portainer API. Tools that are not
+// listed are available to every authenticated user, and Portainer still applies its
+// resource-level access control when they are called.
+var restrictedTools = map[string]toolAccess{
+	// Edge groups
+	ToolListEnvironmentGroups:              accessAdmin,
+	ToolPreviewEnvironmentGroupMembers:     accessAdmin,
+	ToolCreateEnvironmentGroup:             accessAdmin,
+	ToolUpdateEnvironmentGroupName:         accessAdmin,
+	ToolUpdateEnvironmentGroupEnvironments: accessAdmin,
+	ToolUpdateEnvironmentGroupTags:         accessAdmin,
+
+	// Endpoint groups
+	ToolCreateAccessGroup:                accessAdmin,
+	ToolUpdateAccessGroupName:            accessAdmin,
+	ToolUpdateAccessGroupUserAccesses:    accessAdmin,
+	ToolUpdateAccessGroupTeamAccesses:    accessAdmin,
+	ToolAddEnvironmentToAccessGroup:      accessAdmin,
+	ToolRemoveEnvironmentFromAccessGroup: accessAdmin,
+	ToolSetAccessGroupEnvironments:       accessAdmin,
+
+	// Environments
+	ToolWhoCanAccessEnvironment:       accessAdmin,
+	ToolDeleteEnvironment:             accessAdmin,
+	ToolOnboardEnvironment:            accessAdmin,
+	ToolSnapshotEnvironment:           accessAdmin,
+	ToolSnapshotAllEnvironments:       accessAdmin,
+	ToolGetSnapshotSettings:           accessAdmin,
+	ToolUpdateSnapshotSettings:        accessAdmin,
+	ToolUpdateEnvironmentTags:         accessAdmin,
+	ToolRetagEnvironments:             accessAdmin,
+	ToolUpdateEnvironmentUserAccesses: accessAdmin,
+	ToolUpdateEnvironmentTeamAccesses: accessAdmin,
+
+	// Edge stacks
+	ToolListStacks:                accessAdmin,
+	ToolGetStackFile:              accessAdmin,
+	ToolCreateStack:               accessAdmin,
+	ToolUpdateStack:               accessAdmin,
+	ToolWaitForEdgeStackRollout:   accessAdmin,
+	ToolMigrateRegularStackToEdge: accessAdmin,
+
+	// Tags
+	ToolCreateEnvironmentTag: accessAdmin,
+	ToolDeleteEnvironmentTag: accessAdmin,
+
+	// Teams and users
+	ToolGetTeam:           accessAdminOrTeamLeader,
+	ToolAuditTeamAccess:   accessAdminOrTeamLeader,
+	ToolCreateTeam:        accessAdmin,
+	ToolDeleteTeam:        accessAdmin,
+	ToolUpdateTeamName:    accessAdmin,
+	ToolUpdateTeamMembers: accessAdmin,
+	ToolImportTeams:       accessAdmin,
+	ToolCreateUser:        accessAdminOrTeamLeader,
+	ToolDeleteUser:        accessAdmin,
+	ToolUpdateUserRole:    accessAdmin,
+	ToolImportUsers:       accessAdmin,
+	ToolOffboardUser:      accessAdmin,
+	ToolListRoles:         accessAdmin,
+	ToolGetRole:           accessAdmin,
+	ToolCompareRoles:      accessAdmin,
+	ToolAssignRole:        accessAdmin,
+	ToolUnassignRole:      accessAdmin,
+
+	// Settings
+	ToolGetSettings:       accessAdmin,
+	ToolUpdateSettings:    accessAdmin,
+	ToolGetSSLSettings:    accessAdmin,
+	ToolUpdateSSLSettings: accessAdmin,
+	ToolRevertSettings:    accessAdmin,
+	ToolUpdateLoginBanner: accessAdmin,
+	ToolExportSettings:    accessAdmin,
+	ToolImportSettings:    accessAdmin,
+	ToolCompareInstances:  accessAdmin,
+
+	// Registries
+	ToolCreateRegistry:            accessAdmin,
+	ToolUpdateRegistry:            accessAdmin,
+	ToolDeleteRegistry:            accessAdmin,
+	ToolRotateRegistryCredentials: accessAdmin,
+
+	// Backups
+	ToolGetBackupS3Settings: accessAdmin,
+	ToolCreateBackup:        accessAdmin,
+	ToolBackupToS3:          accessAdmin,
+	ToolRestoreFromS3:       accessAdmin,
+
+	// Edge jobs and update schedules
+	ToolListEdgeJobs:            accessAdmin,
+	ToolGetEdgeJob:              accessAdmin,
+	ToolGetEdgeJobFile:          accessAdmin,
+	ToolCreateEdgeJob:           accessAdmin,
+	ToolDeleteEdgeJob:           accessAdmin,
+	ToolListEdgeUpdateSchedules: accessAdmin,
+	ToolGetAgentVersionReport:   accessAdmin,
+	ToolScheduleAgentUpgrades:   accessAdmin,
+}
+
+// tokenAccess describes what the Portainer API token used by the server is allowed to do.
+type tokenAccess struct {
+	user       models.User
+	teamLeader bool
+}
+
+// admin reports whether the token belongs to an administrator or an edge administrator.
+func (a *tokenAccess) admin() bool {
+	return a.user.Role == models.UserRoleAdmin || a.user.Role == models.UserRoleEdgeAdmin
+}
+
+// allows reports whether the token may call the given tool. Administrators and edge
+// administrators may call every tool.
+func (a *tokenAccess) allows(tool string) bool {
+	if a.admin() {
+		return true
+	}
+
+	switch restrictedTools[tool] {
+	case accessAdmin:
+		return false
+	case accessAdminOrTeamLeader:
+		return a.teamLeader
+	default:
+		return true
+	}
+}
+
+// loadTokenAccess queries the role and team memberships of the user that owns the API token.
+func loadTokenAccess(cli PortainerClient) (*tokenAccess, error) {
+	user, err := cli.GetCurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the token user: %w", err)
+	}
+
+	access := &tokenAccess{user: user}
+	if access.admin() {
+		return access, nil
+	}
+
+	memberships, err := cli.GetUserMemberships(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the team memberships of the token user: %w", err)
+	}
+	for _, membership := range memberships {
+		if membership.Role == models.TeamMembershipRoleLeader {
+			access.teamLeader = true
+			break
+		}
+	}
+
+	return access, nil
+}
+
+// toolAllowed reports whether a tool should be registered. Every tool is allowed unless
+// RBAC filtering is enabled and the token cannot call it.
+func (s *PortainerMCPServer) toolAllowed(tool string) bool {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	return s.access == nil || s.access.allows(tool)
+}
@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRestrictedToolsAreKnown verifies that every restricted tool is a defined tool.
+func TestRestrictedToolsAreKnown(t *testing.T) {
+	known := allToolNames()
+	for tool := range restrictedTools {
+		assert.Contains(t, known, tool)
+	}
+}
+
+// TestTokenAccessAllows verifies which tools each kind of token may call.
+func TestTokenAccessAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		access  tokenAccess
+		allowed map[string]bool
+	}{
+		{
+			name:    "administrator",
+			access:  tokenAccess{user: models.User{Role: models.UserRoleAdmin}},
+			allowed: map[string]bool{ToolDeleteUser: true, ToolGetSettings: true, ToolGetTeam: true, ToolListEnvironments: true},
+		},
+		{
+			name:    "edge administrator",
+			access:  tokenAccess{user: models.User{Role: models.UserRoleEdgeAdmin}},
+			allowed: map[string]bool{ToolDeleteUser: true, ToolCreateEdgeJob: true},
+		},
+		{
+			name:    "standard user",
+			access:  tokenAccess{user: models.User{Role: models.UserRoleUser}},
+			allowed: map[string]bool{ToolDeleteUser: false, ToolGetSettings: false, ToolGetTeam: false, ToolCreateUser: false, ToolListEnvironments: true, ToolDeleteStack: true},
+		},
+		{
+			name:    "team leader",
+			access:  tokenAccess{user: models.User{Role: models.UserRoleUser}, teamLeader: true},
+			allowed: map[string]bool{ToolDeleteUser: false, ToolUpdateTeamMembers: false, ToolGetTeam: true, ToolCreateUser: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for tool, allowed := range tt.allowed {
+				assert.Equal(t, allowed, tt.access.allows(tool), tool)
+			}
+		})
+	}
+}
+
+// TestLoadTokenAccess verifies the queries made to determine the token access.
+func TestLoadTokenAccess(t *testing.T) {
+	t.Run("administrator skips memberships", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetCurrentUser").Return(models.User{ID: 1, Username: "admin", Role: models.UserRoleAdmin}, nil)
+
+		access, err := loadTokenAccess(mockClient)
+		require.NoError(t, err)
+		assert.Equal(t, "admin", access.user.Username)
+		mockClient.AssertNotCalled(t, "GetUserMemberships", 1)
+	})
+
+	t.Run("team leader", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetCurrentUser").Return(models.User{ID: 4, Role: models.UserRoleUser}, nil)
+		mockClient.On("GetUserMemberships", 4).Return([]models.TeamMembership{
+			{TeamID: 1, UserID: 4, Role: models.TeamMembershipRoleMember},
+			{TeamID: 2, UserID: 4, Role: models.TeamMembershipRoleLeader},
+		}, nil)
+
+		access, err := loadTokenAccess(mockClient)
+		require.NoError(t, err)
+		assert.True(t, access.teamLeader)
+	})
+
+	t.Run("current user error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetCurrentUser").Return(models.User{}, errors.New("unauthorized"))
+
+		_, err := loadTokenAccess(mockClient)
+		assert.ErrorContains(t, err, "failed to get the token user")
+	})
+
+	t.Run("memberships error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetCurrentUser").Return(models.User{ID: 4, Role: models.UserRoleUser}, nil)
+		mockClient.On("GetUserMemberships", 4).Return(nil, errors.New("forbidden"))
+
+		_, err := loadTokenAccess(mockClient)
+		assert.ErrorContains(t, err, "failed to get the team memberships")
+	})
+}
+
+// TestRBACFilterRegistration verifies that tools and meta-tool actions that a standard
+// user token cannot call are not registered.
+func TestRBACFilterRegistration(t *testing.T) {
+	standardUser := &tokenAccess{user: models.User{ID: 4, Role: models.UserRoleUser}}
+
+	t.Run("granular tools", func(t *testing.T) {
+		s := newTestServer(false)
+		s.access = standardUser
+		s.AddUserFeatures()
+		s.AddSettingsFeatures()
+
+		tools := listRegisteredTools(t, s.srv)
+		assert.Contains(t, tools, ToolListUsers)
+		assert.Contains(t, tools, ToolGetPublicSettings)
+		assert.NotContains(t, tools, ToolDeleteUser)
+		assert.NotContains(t, tools, ToolCreateUser)
+		assert.NotContains(t, tools, ToolGetSettings)
+	})
+
+	t.Run("meta-tools", func(t *testing.T) {
+		s := newTestMetaServer(false)
+		s.access = standardUser
+		s.RegisterMetaTools()
+
+		tools := listRegisteredTools(t, s.srv)
+		assert.NotContains(t, tools, "manage_edge", "every edge action requires an administrator")
+		assert.Contains(t, tools, "manage_users")
+
+		resp := s.srv.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{}}`))
+		respBytes, err := json.Marshal(resp)
+		require.NoError(t, err)
+		var rpcResp struct {
+			Result struct {
+				Tools []mcp.Tool `json:"tools"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(respBytes, &rpcResp))
+
+		for _, tool := range rpcResp.Result.Tools {
+			if tool.Name != "manage_users" {
+				continue
+			}
+			actions := tool.InputSchema.Properties["action"].(map[string]any)["enum"]
+			assert.ElementsMatch(t, []any{"list_users", "get_user"}, actions)
+		}
+	})
+}
+
+// TestNewPortainerMCPServerWithRBACFilter verifies that the token access is queried at
+// startup only when RBAC filtering is enabled.
+func TestNewPortainerMCPServerWithRBACFilter(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetCurrentUser").Return(models.User{ID: 4, Username: "dev", Role: models.UserRoleUser}, nil)
+	mockClient.On("GetUserMemberships", 4).Return([]models.TeamMembership{}, nil)
+
+	s, err := NewPortainerMCPServer("https://example.com", "tok", "testdata/valid_tools.yaml",
+		WithClient(mockClient), WithDisableVersionCheck(true), WithRBACFilter(true))
+	require.NoError(t, err)
+	require.NotNil(t, s.access)
+	assert.Equal(t, "dev", s.access.user.Username)
+
+	failing := &MockPortainerClient{}
+	failing.On("GetCurrentUser").Return(models.User{}, errors.New("unauthorized"))
+	_, err = NewPortainerMCPServer("https://example.com", "tok", "testdata/valid_tools.yaml",
+		WithClient(failing), WithDisableVersionCheck(true), WithRBACFilter(true))
+	assert.ErrorContains(t, err, "failed to determine the API token access")
+
+	s, err = NewPortainerMCPServer("https://example.com", "tok", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true))
+	require.NoError(t, err)
+	assert.Nil(t, s.access)
+}
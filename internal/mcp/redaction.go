@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/redact"
+)
+
+// redactionMiddleware returns a tool handler middleware that applies the redaction rules
+// to the text content of every tool result, including error results.
+func redactionMiddleware(engine *redact.Engine) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			action, _ := request.GetArguments()["action"].(string)
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					text.Text = engine.Apply(request.Params.Name, action, text.Text)
+					result.Content[i] = text
+				}
+			}
+			return result, nil
+		}
+	}
+}
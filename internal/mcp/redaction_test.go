@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedactionMiddleware verifies that rules are applied to tool results by tool and action.
+func TestRedactionMiddleware(t *testing.T) {
+	engine, err := redact.New([]redact.Rule{
+		{Tools: []string{"getSettings", "manage_settings.get_settings"}, Paths: []string{"$..password"}},
+		{Pattern: `ptr_[A-Za-z0-9]+`},
+	})
+	require.NoError(t, err)
+
+	next := func(text string, isError bool) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if isError {
+				return mcp.NewToolResultError(text), nil
+			}
+			return mcp.NewToolResultText(text), nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		text    string
+		isError bool
+		want    string
+	}{
+		{
+			name: "granular tool",
+			tool: "getSettings",
+			text: `{"ldap":{"password":"x"}}`,
+			want: `{"ldap":{"password":"[REDACTED]"}}`,
+		},
+		{
+			name: "meta-tool action",
+			tool: "manage_settings",
+			args: map[string]any{"action": "get_settings"},
+			text: `{"password":"x"}`,
+			want: `{"password":"[REDACTED]"}`,
+		},
+		{
+			name: "rule for another tool",
+			tool: "listUsers",
+			text: `{"password":"x"}`,
+			want: `{"password":"x"}`,
+		},
+		{
+			name:    "error result",
+			tool:    "listUsers",
+			text:    "invalid token ptr_abc123",
+			isError: true,
+			want:    "invalid token [REDACTED]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := redactionMiddleware(engine)(next(tt.text, tt.isError))
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.isError, result.IsError)
+			assert.Equal(t, tt.want, result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+}
+
+// TestNewPortainerMCPServerRedactionRules verifies loading redaction rules at startup.
+func TestNewPortainerMCPServerRedactionRules(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("rules:\n  - paths: [\"$..Password\"]\n"), 0o600))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("rules:\n  - pattern: \"(\"\n"), 0o600))
+
+	server, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithRedactionRules(valid))
+	require.NoError(t, err)
+	assert.NotNil(t, server)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithRedactionRules(invalid))
+	assert.ErrorContains(t, err, "failed to load redaction rules")
+}
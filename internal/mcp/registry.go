@@ -14,11 +14,13 @@ import (
 func (s *PortainerMCPServer) AddRegistryFeatures() {
 	s.addToolIfExists(ToolListRegistries, s.HandleListRegistries())
 	s.addToolIfExists(ToolGetRegistry, s.HandleGetRegistry())
+	s.addToolIfExists(ToolGetRegistryUsage, s.HandleGetRegistryUsage())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolCreateRegistry, s.HandleCreateRegistry())
 		s.addToolIfExists(ToolUpdateRegistry, s.HandleUpdateRegistry())
 		s.addToolIfExists(ToolDeleteRegistry, s.HandleDeleteRegistry())
+		s.addToolIfExists(ToolRotateRegistryCredentials, s.HandleRotateRegistryCredentials())
 	}
 }
 
@@ -0,0 +1,324 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// dockerHubDomain is the canonical domain of Docker Hub image references.
+const dockerHubDomain = "docker.io"
+
+// stackRedeploy describes a regular stack that uses the affected images, and whether it was
+// redeployed to pull them again.
+type stackRedeploy struct {
+	StackID       int      `json:"stack_id"`
+	Name          string   `json:"name"`
+	EnvironmentID int      `json:"environment_id"`
+	Images        []string `json:"images"`
+	Redeployed    bool     `json:"redeployed"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// registryRotationReport is the result of HandleRotateRegistryCredentials.
+type registryRotationReport struct {
+	RegistryID   int             `json:"registry_id"`
+	RegistryName string          `json:"registry_name"`
+	Username     string          `json:"username,omitempty"`
+	Stacks       []stackRedeploy `json:"stacks"`
+	Errors       []string        `json:"errors,omitempty"`
+}
+
+// HandleRotateRegistryCredentials returns an MCP tool handler that replaces the password or
+// token of a registry, lists the regular stacks whose Compose images come from that registry
+// and, on request, redeploys them with an image pull so that they use the new credentials.
+func (s *PortainerMCPServer) HandleRotateRegistryCredentials() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		password, err := parser.GetString("password", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid password parameter", err), nil
+		}
+		if password == "" {
+			return mcp.NewToolResultError("password cannot be empty"), nil
+		}
+
+		var username *string
+		if _, ok := request.GetArguments()["username"]; ok {
+			v, err := parser.GetString("username", false)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid username parameter", err), nil
+			}
+			username = &v
+		}
+
+		redeployStacks, err := parser.GetBoolean("redeployStacks", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid redeployStacks parameter", err), nil
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+
+		registry, err := s.cli.GetRegistry(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get registry", err), nil
+		}
+
+		if plan {
+			steps, notes, err := s.rotateRegistryCredentialsPlan(registry, username, redeployStacks)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to plan registry credential rotation", err), nil
+			}
+			return s.previewPlan(ctx, request, steps, notes, s.HandleRotateRegistryCredentials())
+		}
+
+		authentication := true
+		if err := s.cli.UpdateRegistry(id, nil, nil, &authentication, username, &password, nil); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to update registry credentials", err), nil
+		}
+
+		report := registryRotationReport{
+			RegistryID:   registry.ID,
+			RegistryName: registry.Name,
+			Username:     registry.Username,
+			Stacks:       []stackRedeploy{},
+		}
+		if username != nil {
+			report.Username = *username
+		}
+
+		// The credentials are already rotated at this point: failures past this line are
+		// reported alongside the affected stacks instead of failing the whole call.
+		stacks, err := s.cli.GetRegularStacks()
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to list stacks: %v", err))
+			return jsonResult(report, "failed to marshal registry rotation report")
+		}
+
+		matches, errs := s.findRegistryStacks(stacks, registry.URL)
+		report.Errors = append(report.Errors, errs...)
+		for _, match := range matches {
+			entry := stackRedeploy{StackID: match.stack.ID, Name: match.stack.Name, EnvironmentID: match.stack.EndpointID, Images: match.images}
+			if redeployStacks {
+				entry.Redeployed, entry.Error = s.redeployStackWithPull(ctx, match.stack)
+			}
+			report.Stacks = append(report.Stacks, entry)
+		}
+
+		return jsonResult(report, "failed to marshal registry rotation report")
+	}
+}
+
+// composeStack is a regular stack whose Compose file uses some images.
+type composeStack struct {
+	stack  models.RegularStack
+	images []string
+}
+
+// findRegistryStacks returns the stacks whose Compose images are pulled from the registry
+// at registryURL, and an error message for each stack whose file cannot be read.
+func (s *PortainerMCPServer) findRegistryStacks(stacks []models.RegularStack, registryURL string) ([]composeStack, []string) {
+	domain, prefix := splitRegistryURL(registryURL)
+	return s.findComposeStacks(stacks, func(named reference.Named) bool {
+		return imageFromRegistry(named, domain, prefix)
+	})
+}
+
+// findComposeStacks returns the stacks whose Compose files use images for which match
+// returns true, and an error message for each stack whose file cannot be read.
+func (s *PortainerMCPServer) findComposeStacks(stacks []models.RegularStack, match func(reference.Named) bool) ([]composeStack, []string) {
+	var matches []composeStack
+	var errs []string
+	for _, stack := range stacks {
+		file, err := s.cli.InspectStackFile(stack.ID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("stack %d (%s): %v", stack.ID, stack.Name, err))
+			continue
+		}
+		images, err := composeImages(file, match)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("stack %d (%s): %v", stack.ID, stack.Name, err))
+			continue
+		}
+		if len(images) > 0 {
+			matches = append(matches, composeStack{stack: stack, images: images})
+		}
+	}
+	return matches, errs
+}
+
+// rotateRegistryCredentialsPlan returns the Portainer API calls made by
+// HandleRotateRegistryCredentials, resolving the affected stacks with read-only calls.
+func (s *PortainerMCPServer) rotateRegistryCredentialsPlan(registry models.Registry, username *string, redeployStacks bool) ([]planStep, []string, error) {
+	update := fmt.Sprintf("Set the password of registry %q", registry.Name)
+	if username != nil {
+		update += fmt.Sprintf(" and its username to %q", *username)
+	}
+	steps := []planStep{
+		{Method: http.MethodGet, Path: fmt.Sprintf("/api/registries/%d", registry.ID), Description: fmt.Sprintf("Read registry %q", registry.Name)},
+		{Method: http.MethodPut, Path: fmt.Sprintf("/api/registries/%d", registry.ID), Description: update},
+		{Method: http.MethodGet, Path: "/api/stacks", Description: "List the regular stacks"},
+	}
+
+	stacks, err := s.cli.GetRegularStacks()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list stacks: %w", err)
+	}
+	for _, stack := range stacks {
+		steps = append(steps, planStep{
+			Method:      http.MethodGet,
+			Path:        fmt.Sprintf("/api/stacks/%d/file", stack.ID),
+			Description: fmt.Sprintf("Read the Compose file of stack %q to match its images", stack.Name),
+		})
+	}
+
+	matches, notes := s.findRegistryStacks(stacks, registry.URL)
+	for _, match := range matches {
+		if !redeployStacks {
+			notes = append(notes, fmt.Sprintf("stack %d (%s) uses %s and is not redeployed", match.stack.ID, match.stack.Name, strings.Join(match.images, ", ")))
+			continue
+		}
+		redeploy, note := stackRedeployPlan(match)
+		steps = append(steps, redeploy...)
+		if note != "" {
+			notes = append(notes, note)
+		}
+	}
+	notes = append(notes, "affected stacks are matched again when the plan is applied")
+	return steps, notes, nil
+}
+
+// stackRedeployPlan returns the Portainer API calls made by redeployStackWithPull for a
+// stack, or a note when the stack is not running and is left as is.
+func stackRedeployPlan(match composeStack) ([]planStep, string) {
+	stack := match.stack
+	images := strings.Join(match.images, ", ")
+	switch {
+	case stack.Status != regularStackStatusActive:
+		return nil, fmt.Sprintf("stack %d (%s) uses %s but is not running and is not redeployed", stack.ID, stack.Name, images)
+	case stack.Git != nil:
+		return []planStep{{
+			Method:      http.MethodPut,
+			Path:        fmt.Sprintf("/api/stacks/%d/git/redeploy?endpointId=%d", stack.ID, stack.EndpointID),
+			Description: fmt.Sprintf("Redeploy git stack %q from its repository, pulling %s", stack.Name, images),
+		}}, ""
+	default:
+		return []planStep{
+			{Method: http.MethodGet, Path: fmt.Sprintf("/api/stacks/%d", stack.ID), Description: fmt.Sprintf("Read the environment variables of stack %q", stack.Name)},
+			{Method: http.MethodGet, Path: fmt.Sprintf("/api/stacks/%d/file", stack.ID), Description: fmt.Sprintf("Read the Compose file of stack %q", stack.Name)},
+			{
+				Method:      http.MethodPut,
+				Path:        fmt.Sprintf("/api/stacks/%d?endpointId=%d", stack.ID, stack.EndpointID),
+				Description: fmt.Sprintf("Redeploy stack %q with its current file and environment, pulling %s", stack.Name, images),
+			},
+		}, ""
+	}
+}
+
+// redeployStackWithPull redeploys a regular stack with an image pull, through the git
+// redeploy endpoint for git-backed stacks and the stack update endpoint otherwise.
+// Stopped stacks are left stopped.
+func (s *PortainerMCPServer) redeployStackWithPull(ctx context.Context, stack models.RegularStack) (bool, string) {
+	if err := ctx.Err(); err != nil {
+		return false, err.Error()
+	}
+	if stack.Status != regularStackStatusActive {
+		return false, "stack is not running; redeploy it after starting it"
+	}
+
+	var err error
+	if stack.Git != nil {
+		_, err = s.cli.RedeployStackGit(stack.ID, stack.EndpointID, true, false)
+	} else {
+		_, err = s.cli.RedeployRegularStack(stack.ID, stack.EndpointID, true)
+	}
+	if err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// composeImagesFromRegistry returns the sorted, distinct images of a Compose file that are
+// pulled from the registry at registryURL.
+func composeImagesFromRegistry(content, registryURL string) ([]string, error) {
+	domain, prefix := splitRegistryURL(registryURL)
+	return composeImages(content, func(named reference.Named) bool {
+		return imageFromRegistry(named, domain, prefix)
+	})
+}
+
+// composeImages returns the sorted, distinct images of the services of a Compose file for
+// which match returns true. Images that use variable interpolation cannot be resolved and
+// are ignored.
+func composeImages(content string, match func(reference.Named) bool) ([]string, error) {
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &compose); err != nil {
+		return nil, fmt.Errorf("invalid compose file: %w", err)
+	}
+
+	var images []string
+	for _, service := range compose.Services {
+		named, err := reference.ParseNormalizedNamed(service.Image)
+		if err != nil || !match(named) {
+			continue
+		}
+		if !slices.Contains(images, service.Image) {
+			images = append(images, service.Image)
+		}
+	}
+	slices.Sort(images)
+	return images, nil
+}
+
+// imageFromRegistry reports whether an image is pulled from the registry with the given
+// domain and optional repository prefix, as returned by splitRegistryURL.
+func imageFromRegistry(named reference.Named, domain, prefix string) bool {
+	if !strings.EqualFold(reference.Domain(named), domain) {
+		return false
+	}
+	repository := reference.Path(named)
+	return prefix == "" || repository == prefix || strings.HasPrefix(repository, prefix+"/")
+}
+
+// splitRegistryURL splits a Portainer registry URL such as "https://ghcr.io/acme" into the
+// registry domain and an optional repository prefix. Docker Hub aliases are normalized to
+// the domain used in image references.
+func splitRegistryURL(registryURL string) (string, string) {
+	u := registryURL
+	if i := strings.Index(u, "://"); i >= 0 {
+		u = u[i+3:]
+	}
+	u = strings.Trim(u, "/")
+
+	domain, prefix, _ := strings.Cut(u, "/")
+	switch strings.ToLower(domain) {
+	case "", "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		domain = dockerHubDomain
+	}
+	return domain, prefix
+}
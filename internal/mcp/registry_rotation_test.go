@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleRotateRegistryCredentials verifies credential rotation and stack redeployment.
+func TestHandleRotateRegistryCredentials(t *testing.T) {
+	registry := models.Registry{ID: 3, Name: "ghcr", URL: "https://ghcr.io", Authentication: true, Username: "bot"}
+	fileStack := models.RegularStack{ID: 1, Name: "api", EndpointID: 2, Status: regularStackStatusActive}
+	gitStack := models.RegularStack{ID: 2, Name: "web", EndpointID: 2, Status: regularStackStatusActive, Git: &models.StackGitConfig{URL: "https://github.com/acme/web.git"}}
+	stoppedStack := models.RegularStack{ID: 3, Name: "batch", EndpointID: 4, Status: regularStackStatusInactive}
+	hubStack := models.RegularStack{ID: 4, Name: "cache", EndpointID: 2, Status: regularStackStatusActive}
+	files := map[int]string{
+		1: "services:\n  api:\n    image: ghcr.io/acme/api:1.2\n  db:\n    image: postgres:16\n",
+		2: "services:\n  web:\n    image: ghcr.io/acme/web:latest\n",
+		3: "services:\n  job:\n    image: ghcr.io/acme/batch\n",
+		4: "services:\n  cache:\n    image: redis:7\n",
+	}
+	stacks := []models.RegularStack{fileStack, gitStack, stoppedStack, hubStack}
+	deployer := "deployer"
+
+	tests := []struct {
+		name           string
+		inputParams    map[string]any
+		username       *string
+		updateError    error
+		stacksError    error
+		redeployError  error
+		expectUpdate   bool
+		expectRedeploy bool
+		expectError    bool
+		expectedStacks []stackRedeploy
+		expectedErrors int
+	}{
+		{
+			name:         "rotates credentials and lists affected stacks",
+			inputParams:  map[string]any{"id": float64(3), "password": "new-token"},
+			expectUpdate: true,
+			expectedStacks: []stackRedeploy{
+				{StackID: 1, Name: "api", EnvironmentID: 2, Images: []string{"ghcr.io/acme/api:1.2"}},
+				{StackID: 2, Name: "web", EnvironmentID: 2, Images: []string{"ghcr.io/acme/web:latest"}},
+				{StackID: 3, Name: "batch", EnvironmentID: 4, Images: []string{"ghcr.io/acme/batch"}},
+			},
+		},
+		{
+			name:           "redeploys running stacks",
+			inputParams:    map[string]any{"id": float64(3), "password": "new-token", "username": "deployer", "redeployStacks": true},
+			username:       &deployer,
+			expectUpdate:   true,
+			expectRedeploy: true,
+			expectedStacks: []stackRedeploy{
+				{StackID: 1, Name: "api", EnvironmentID: 2, Images: []string{"ghcr.io/acme/api:1.2"}, Redeployed: true},
+				{StackID: 2, Name: "web", EnvironmentID: 2, Images: []string{"ghcr.io/acme/web:latest"}, Redeployed: true},
+				{StackID: 3, Name: "batch", EnvironmentID: 4, Images: []string{"ghcr.io/acme/batch"}, Error: "stack is not running; redeploy it after starting it"},
+			},
+		},
+		{
+			name:           "redeploy errors are reported per stack",
+			inputParams:    map[string]any{"id": float64(3), "password": "new-token", "redeployStacks": true},
+			redeployError:  fmt.Errorf("pull access denied"),
+			expectUpdate:   true,
+			expectRedeploy: true,
+			expectedStacks: []stackRedeploy{
+				{StackID: 1, Name: "api", EnvironmentID: 2, Images: []string{"ghcr.io/acme/api:1.2"}, Error: "pull access denied"},
+				{StackID: 2, Name: "web", EnvironmentID: 2, Images: []string{"ghcr.io/acme/web:latest"}, Error: "pull access denied"},
+				{StackID: 3, Name: "batch", EnvironmentID: 4, Images: []string{"ghcr.io/acme/batch"}, Error: "stack is not running; redeploy it after starting it"},
+			},
+		},
+		{
+			name:           "stack listing error is reported",
+			inputParams:    map[string]any{"id": float64(3), "password": "new-token"},
+			stacksError:    fmt.Errorf("api error"),
+			expectUpdate:   true,
+			expectedStacks: []stackRedeploy{},
+			expectedErrors: 1,
+		},
+		{
+			name:         "update error",
+			inputParams:  map[string]any{"id": float64(3), "password": "new-token"},
+			updateError:  fmt.Errorf("forbidden"),
+			expectUpdate: true,
+			expectError:  true,
+		},
+		{
+			name:        "missing password",
+			inputParams: map[string]any{"id": float64(3)},
+			expectError: true,
+		},
+		{
+			name:        "empty password",
+			inputParams: map[string]any{"id": float64(3), "password": ""},
+			expectError: true,
+		},
+		{
+			name:        "invalid id",
+			inputParams: map[string]any{"id": float64(0), "password": "new-token"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.expectUpdate {
+				mockClient.On("GetRegistry", 3).Return(registry, nil)
+				mockClient.On("UpdateRegistry", 3, (*string)(nil), (*string)(nil), mock.MatchedBy(func(v *bool) bool { return v != nil && *v }),
+					tt.username, mock.MatchedBy(func(v *string) bool { return v != nil && *v == "new-token" }), (*string)(nil)).Return(tt.updateError)
+			}
+			if tt.expectUpdate && tt.updateError == nil {
+				mockClient.On("GetRegularStacks").Return(stacks, tt.stacksError)
+				for id, file := range files {
+					mockClient.On("InspectStackFile", id).Return(file, nil).Maybe()
+				}
+			}
+			if tt.expectRedeploy {
+				mockClient.On("RedeployRegularStack", 1, 2, true).Return(fileStack, tt.redeployError)
+				mockClient.On("RedeployStackGit", 2, 2, true, false).Return(gitStack, tt.redeployError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleRotateRegistryCredentials()(context.Background(), CreateMCPRequest(tt.inputParams))
+
+			assert.NoError(t, err)
+			require.NotNil(t, result)
+			if tt.expectError {
+				assert.True(t, result.IsError, "result.IsError should be true for errors")
+				mockClient.AssertExpectations(t)
+				return
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var report registryRotationReport
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+			assert.Equal(t, 3, report.RegistryID)
+			assert.Equal(t, tt.expectedStacks, sortedRotationStacks(report.Stacks))
+			assert.Len(t, report.Errors, tt.expectedErrors)
+			if tt.username != nil {
+				assert.Equal(t, *tt.username, report.Username)
+			} else {
+				assert.Equal(t, "bot", report.Username)
+			}
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// sortedRotationStacks orders a rotation report's stacks by ID.
+func sortedRotationStacks(stacks []stackRedeploy) []stackRedeploy {
+	sorted := slices.Clone(stacks)
+	slices.SortFunc(sorted, func(a, b stackRedeploy) int { return a.StackID - b.StackID })
+	return sorted
+}
+
+// TestComposeImagesFromRegistry verifies matching Compose images to a registry URL.
+func TestComposeImagesFromRegistry(t *testing.T) {
+	compose := `services:
+  api:
+    image: ghcr.io/acme/api:1.2
+  worker:
+    image: ghcr.io/other/worker@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+  db:
+    image: postgres:16
+  cache:
+    image: library/redis
+  mirror:
+    image: registry.example.com:5000/team/app
+  templated:
+    image: ${REGISTRY}/app:${TAG}
+  built:
+    build: .
+`
+
+	tests := []struct {
+		name          string
+		registryURL   string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:        "domain only",
+			registryURL: "ghcr.io",
+			expected: []string{
+				"ghcr.io/acme/api:1.2",
+				"ghcr.io/other/worker@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+		},
+		{
+			name:        "scheme and repository prefix",
+			registryURL: "https://ghcr.io/acme/",
+			expected:    []string{"ghcr.io/acme/api:1.2"},
+		},
+		{
+			name:        "docker hub",
+			registryURL: "https://index.docker.io",
+			expected:    []string{"library/redis", "postgres:16"},
+		},
+		{
+			name:        "registry with port",
+			registryURL: "registry.example.com:5000",
+			expected:    []string{"registry.example.com:5000/team/app"},
+		},
+		{
+			name:        "no match",
+			registryURL: "quay.io",
+		},
+		{
+			name:          "invalid compose file",
+			registryURL:   "ghcr.io",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := compose
+			if tt.expectedError {
+				content = "services: ["
+			}
+
+			images, err := composeImagesFromRegistry(content, tt.registryURL)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, images)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registryConsumer is a stack, edge stack or custom template whose Compose file pulls
+// images from a registry.
+type registryConsumer struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Images []string `json:"images"`
+}
+
+// registryUsage lists the resources that reference images of a registry.
+type registryUsage struct {
+	RegistryID      int                `json:"registry_id"`
+	RegistryName    string             `json:"registry_name"`
+	URL             string             `json:"url"`
+	Stacks          []registryConsumer `json:"stacks"`
+	EdgeStacks      []registryConsumer `json:"edge_stacks"`
+	CustomTemplates []registryConsumer `json:"custom_templates"`
+}
+
+// registryUsageReport is the result of HandleGetRegistryUsage.
+type registryUsageReport struct {
+	Registries []registryUsage `json:"registries"`
+	Errors     []string        `json:"errors,omitempty"`
+}
+
+// composeFile is the Compose file of a stack, edge stack or custom template, reduced to
+// the images of its services.
+type composeFile struct {
+	id     int
+	name   string
+	images []string
+}
+
+// HandleGetRegistryUsage returns an MCP tool handler that maps each registry, or the given
+// one, to the regular stacks, edge stacks and custom templates whose Compose files pull
+// images from it.
+func (s *PortainerMCPServer) HandleGetRegistryUsage() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		var registries []models.Registry
+		if id != 0 {
+			if err := validatePositiveID("id", id); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			registry, err := s.cli.GetRegistry(id)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get registry", err), nil
+			}
+			registries = []models.Registry{registry}
+		} else {
+			registries, err = s.cli.GetRegistries()
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to list registries", err), nil
+			}
+		}
+
+		report := registryUsageReport{Registries: []registryUsage{}}
+		stacks, edgeStacks, templates, errs := s.readComposeFiles(ctx)
+		report.Errors = errs
+
+		for _, registry := range registries {
+			domain, prefix := splitRegistryURL(registry.URL)
+			report.Registries = append(report.Registries, registryUsage{
+				RegistryID:      registry.ID,
+				RegistryName:    registry.Name,
+				URL:             registry.URL,
+				Stacks:          registryConsumers(stacks, domain, prefix),
+				EdgeStacks:      registryConsumers(edgeStacks, domain, prefix),
+				CustomTemplates: registryConsumers(templates, domain, prefix),
+			})
+		}
+
+		return jsonResult(report, "failed to marshal registry usage report")
+	}
+}
+
+// readComposeFiles reads the Compose files of the regular stacks, edge stacks and custom
+// templates, and returns an error message for each list or file that cannot be read.
+// Kubernetes custom templates are skipped, as they have no Compose services.
+func (s *PortainerMCPServer) readComposeFiles(ctx context.Context) (stacks, edgeStacks, templates []composeFile, errs []string) {
+	read := func(kind string, id int, name string, file func(int) (string, error)) (composeFile, bool) {
+		if err := ctx.Err(); err != nil {
+			return composeFile{}, false
+		}
+		content, err := file(id)
+		if err == nil {
+			var images []string
+			images, err = composeImages(content, func(reference.Named) bool { return true })
+			if err == nil {
+				return composeFile{id: id, name: name, images: images}, true
+			}
+		}
+		errs = append(errs, fmt.Sprintf("%s %d (%s): %v", kind, id, name, err))
+		return composeFile{}, false
+	}
+
+	regularStacks, err := s.cli.GetRegularStacks()
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("failed to list stacks: %v", err))
+	}
+	for _, stack := range regularStacks {
+		if file, ok := read("stack", stack.ID, stack.Name, s.cli.InspectStackFile); ok {
+			stacks = append(stacks, file)
+		}
+	}
+
+	allEdgeStacks, err := s.cli.GetStacks(models.EdgeStackListOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("failed to list edge stacks: %v", err))
+	}
+	for _, stack := range allEdgeStacks {
+		if file, ok := read("edge stack", stack.ID, stack.Name, s.cli.GetStackFile); ok {
+			edgeStacks = append(edgeStacks, file)
+		}
+	}
+
+	customTemplates, err := s.cli.GetCustomTemplates()
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("failed to list custom templates: %v", err))
+	}
+	for _, template := range customTemplates {
+		if template.Type == TemplateTypeKubernetes {
+			continue
+		}
+		if file, ok := read("custom template", template.ID, template.Title, s.cli.GetCustomTemplateFile); ok {
+			templates = append(templates, file)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	return stacks, edgeStacks, templates, errs
+}
+
+// registryConsumers returns the Compose files with images pulled from the registry with
+// the given domain and repository prefix, as returned by splitRegistryURL.
+func registryConsumers(files []composeFile, domain, prefix string) []registryConsumer {
+	consumers := []registryConsumer{}
+	for _, file := range files {
+		var images []string
+		for _, image := range file.images {
+			named, err := reference.ParseNormalizedNamed(image)
+			if err == nil && imageFromRegistry(named, domain, prefix) {
+				images = append(images, image)
+			}
+		}
+		if len(images) > 0 {
+			consumers = append(consumers, registryConsumer{ID: file.id, Name: file.name, Images: images})
+		}
+	}
+	return consumers
+}
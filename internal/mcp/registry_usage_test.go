@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registryUsageTestClient returns a client with a stack, an edge stack and custom templates
+// pulling images from ghcr.io/acme and Docker Hub.
+func registryUsageTestClient() *MockPortainerClient {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetRegularStacks").Return([]models.RegularStack{{ID: 1, Name: "web"}, {ID: 2, Name: "broken"}}, nil)
+	mockClient.On("InspectStackFile", 1).Return("services:\n  api:\n    image: ghcr.io/acme/api:1.2\n  db:\n    image: postgres:16\n", nil)
+	mockClient.On("InspectStackFile", 2).Return("", fmt.Errorf("not found"))
+	mockClient.On("GetStacks", models.EdgeStackListOptions{}).Return([]models.Stack{{ID: 3, Name: "edge-agent"}}, nil)
+	mockClient.On("GetStackFile", 3).Return("services:\n  agent:\n    image: ghcr.io/other/agent\n", nil)
+	mockClient.On("GetCustomTemplates").Return([]models.CustomTemplate{
+		{ID: 4, Title: "worker", Type: TemplateTypeCompose},
+		{ID: 5, Title: "k8s", Type: TemplateTypeKubernetes},
+	}, nil)
+	mockClient.On("GetCustomTemplateFile", 4).Return("services:\n  worker:\n    image: ghcr.io/acme/worker:latest\n", nil)
+	return mockClient
+}
+
+// getRegistryUsage calls getRegistryUsage and decodes its report.
+func getRegistryUsage(t *testing.T, mockClient *MockPortainerClient, args map[string]any) registryUsageReport {
+	t.Helper()
+	s := &PortainerMCPServer{cli: mockClient}
+	result, err := s.HandleGetRegistryUsage()(context.Background(), CreateMCPRequest(args))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	var report registryUsageReport
+	require.NoError(t, json.Unmarshal([]byte(text), &report))
+	return report
+}
+
+// TestHandleGetRegistryUsage verifies the resources matched to each registry.
+func TestHandleGetRegistryUsage(t *testing.T) {
+	t.Run("all registries", func(t *testing.T) {
+		mockClient := registryUsageTestClient()
+		mockClient.On("GetRegistries").Return([]models.Registry{
+			{ID: 1, Name: "acme", URL: "ghcr.io/acme"},
+			{ID: 2, Name: "hub", URL: "docker.io"},
+			{ID: 3, Name: "quay", URL: "quay.io"},
+		}, nil)
+
+		report := getRegistryUsage(t, mockClient, map[string]any{})
+		require.Len(t, report.Registries, 3)
+
+		acme := report.Registries[0]
+		assert.Equal(t, []registryConsumer{{ID: 1, Name: "web", Images: []string{"ghcr.io/acme/api:1.2"}}}, acme.Stacks)
+		assert.Empty(t, acme.EdgeStacks, "images of other ghcr.io namespaces do not match")
+		assert.Equal(t, []registryConsumer{{ID: 4, Name: "worker", Images: []string{"ghcr.io/acme/worker:latest"}}}, acme.CustomTemplates)
+
+		hub := report.Registries[1]
+		assert.Equal(t, []registryConsumer{{ID: 1, Name: "web", Images: []string{"postgres:16"}}}, hub.Stacks)
+
+		quay := report.Registries[2]
+		assert.Empty(t, quay.Stacks)
+		assert.Empty(t, quay.EdgeStacks)
+		assert.Empty(t, quay.CustomTemplates)
+
+		assert.Equal(t, []string{"stack 2 (broken): not found"}, report.Errors)
+		mockClient.AssertNotCalled(t, "GetCustomTemplateFile", 5)
+	})
+
+	t.Run("single registry", func(t *testing.T) {
+		mockClient := registryUsageTestClient()
+		mockClient.On("GetRegistry", 1).Return(models.Registry{ID: 1, Name: "acme", URL: "https://ghcr.io"}, nil)
+
+		report := getRegistryUsage(t, mockClient, map[string]any{"id": float64(1)})
+		require.Len(t, report.Registries, 1)
+		assert.Equal(t, []registryConsumer{{ID: 3, Name: "edge-agent", Images: []string{"ghcr.io/other/agent"}}}, report.Registries[0].EdgeStacks)
+		mockClient.AssertNotCalled(t, "GetRegistries")
+	})
+
+	t.Run("listing failure", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetRegistries").Return([]models.Registry{{ID: 1, Name: "acme", URL: "ghcr.io"}}, nil)
+		mockClient.On("GetRegularStacks").Return(nil, fmt.Errorf("unavailable"))
+		mockClient.On("GetStacks", models.EdgeStackListOptions{}).Return([]models.Stack{}, nil)
+		mockClient.On("GetCustomTemplates").Return([]models.CustomTemplate{}, nil)
+
+		report := getRegistryUsage(t, mockClient, map[string]any{})
+		assert.Equal(t, []string{"failed to list stacks: unavailable"}, report.Errors)
+		assert.Empty(t, report.Registries[0].Stacks)
+	})
+}
@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/format"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultMaxResultSize is the default size, in bytes, above which tool results are
+	// delivered in chunks.
+	DefaultMaxResultSize = 256 * 1024
+
+	// resultURITemplate is the URI template of the full payload of a chunked result.
+	resultURITemplate = "portainer://results/{id}"
+	// resultChunkURITemplate is the URI template of one chunk of a chunked result.
+	resultChunkURITemplate = "portainer://results/{id}/chunks/{chunk}"
+
+	// maxStoredResults is the number of chunked results kept; the oldest is dropped first.
+	maxStoredResults = 20
+	// storedResultTTL is how long a chunked result can be read after the call.
+	storedResultTTL = time.Hour
+	// metaChunkedResults is the _meta key describing the chunked contents of a result.
+	metaChunkedResults = "chunkedResults"
+)
+
+// storedResult is the full payload of an oversized result, split in chunks
+type storedResult struct {
+	id       string
+	size     int
+	chunks   []string
+	mimeType string
+	expires  time.Time
+}
+
+// uri returns the URI of the full payload
+func (r storedResult) uri() string {
+	return "portainer://results/" + r.id
+}
+
+// chunkURI returns the URI of a chunk, numbered from 1
+func (r storedResult) chunkURI(chunk int) string {
+	return fmt.Sprintf("%s/chunks/%d", r.uri(), chunk)
+}
+
+// resultStore keeps the oversized tool results so that clients can page through them
+// as MCP resources
+type resultStore struct {
+	chunkSize int
+
+	mu      sync.Mutex
+	results []storedResult
+}
+
+// newResultStore creates a store splitting results in chunks of chunkSize bytes
+func newResultStore(chunkSize int) *resultStore {
+	return &resultStore{chunkSize: chunkSize}
+}
+
+// add stores a payload of the given MIME type and returns it split in chunks
+func (r *resultStore) add(text, mimeType string) (storedResult, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return storedResult{}, fmt.Errorf("failed to generate result ID: %w", err)
+	}
+	stored := storedResult{
+		id:       hex.EncodeToString(buf),
+		size:     len(text),
+		chunks:   splitChunks(text, r.chunkSize),
+		mimeType: mimeType,
+		expires:  time.Now().Add(storedResultTTL),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropExpired()
+	if len(r.results) >= maxStoredResults {
+		r.results = r.results[len(r.results)-maxStoredResults+1:]
+	}
+	r.results = append(r.results, stored)
+	return stored, nil
+}
+
+// get returns a stored result by ID
+func (r *resultStore) get(id string) (storedResult, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dropExpired()
+	for _, stored := range r.results {
+		if stored.id == id {
+			return stored, true
+		}
+	}
+	return storedResult{}, false
+}
+
+// dropExpired removes the expired results. It must be called with the lock held.
+func (r *resultStore) dropExpired() {
+	now := time.Now()
+	kept := r.results[:0]
+	for _, stored := range r.results {
+		if now.Before(stored.expires) {
+			kept = append(kept, stored)
+		}
+	}
+	r.results = kept
+}
+
+// splitChunks splits a text in chunks of at most size bytes, without splitting UTF-8
+// characters
+func splitChunks(text string, size int) []string {
+	var chunks []string
+	for len(text) > size {
+		cut := size
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		if cut == 0 {
+			cut = size
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	return append(chunks, text)
+}
+
+// chunkingMiddleware delivers the text contents larger than the chunk size of the store
+// in chunks: the content is replaced by its first chunk and instructions to read the
+// others, and the full payload is kept as an MCP resource. Error results are left alone.
+func (s *PortainerMCPServer) chunkingMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		var chunked []map[string]any
+		for i, content := range result.Content {
+			text, ok := content.(mcp.TextContent)
+			if !ok || len(text.Text) <= s.results.chunkSize {
+				continue
+			}
+
+			stored, err := s.results.add(text.Text, resultContentType(result, i))
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to store oversized result", err), nil
+			}
+			text.Text = stored.chunks[0] + "\n\n" + chunkInstructions(stored)
+			result.Content[i] = text
+			chunked = append(chunked, map[string]any{
+				"uri":        stored.uri(),
+				"chunks":     len(stored.chunks),
+				"totalBytes": stored.size,
+				"mimeType":   stored.mimeType,
+			})
+		}
+
+		if len(chunked) > 0 {
+			markTruncated(ctx)
+			setResultMeta(result, metaChunkedResults, chunked)
+			// The structured content would hold the whole result again.
+			result.StructuredContent = nil
+		}
+		return result, nil
+	}
+}
+
+// resultContentType returns the MIME type of a text content of a result, as listed in
+// its contentTypes _meta, or plain text.
+func resultContentType(result *mcp.CallToolResult, index int) string {
+	contentTypes, _ := resultMeta(result, metaContentTypes).([]contentType)
+	for _, ct := range contentTypes {
+		if ct.Index == index {
+			return ct.MIMEType
+		}
+	}
+	return format.MIMETypePlainText
+}
+
+// chunkInstructions tells the caller how to read the rest of a chunked result
+func chunkInstructions(stored storedResult) string {
+	return fmt.Sprintf(
+		"[Result too large: showing chunk 1 of %d (%d of %d bytes). Read the next chunks from the MCP resources %s to %s, or the full result from %s. The result is kept for %s.]",
+		len(stored.chunks), len(stored.chunks[0]), stored.size,
+		stored.chunkURI(2), stored.chunkURI(len(stored.chunks)), stored.uri(), storedResultTTL,
+	)
+}
+
+// AddResultResources registers the chunked tool results as MCP resource templates.
+// It does nothing when chunked delivery is disabled.
+func (s *PortainerMCPServer) AddResultResources() {
+	for _, p := range s.principals {
+		p.server.AddResultResources()
+	}
+
+	if s.results == nil {
+		return
+	}
+
+	s.srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(resultURITemplate, "Tool result",
+			mcp.WithTemplateDescription("Full payload of a tool result that was too large to return at once."),
+			mcp.WithTemplateMIMEType("text/plain"),
+		),
+		s.HandleReadResult(),
+	)
+	s.srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(resultChunkURITemplate, "Tool result chunk",
+			mcp.WithTemplateDescription("One chunk of a tool result that was too large to return at once, numbered from 1."),
+			mcp.WithTemplateMIMEType("text/plain"),
+		),
+		s.HandleReadResultChunk(),
+	)
+}
+
+// HandleReadResult returns an MCP resource handler returning the full payload of a
+// chunked tool result.
+func (s *PortainerMCPServer) HandleReadResult() server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		stored, found := s.results.get(resourceArgument(request, "id"))
+		if !found {
+			return nil, fmt.Errorf("result %s does not exist or has expired", request.Params.URI)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: stored.mimeType, Text: strings.Join(stored.chunks, "")},
+		}, nil
+	}
+}
+
+// HandleReadResultChunk returns an MCP resource handler returning one chunk of a chunked
+// tool result.
+func (s *PortainerMCPServer) HandleReadResultChunk() server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		stored, found := s.results.get(resourceArgument(request, "id"))
+		if !found {
+			return nil, fmt.Errorf("result %s does not exist or has expired", request.Params.URI)
+		}
+		chunk, err := strconv.Atoi(resourceArgument(request, "chunk"))
+		if err != nil || chunk < 1 || chunk > len(stored.chunks) {
+			return nil, fmt.Errorf("invalid chunk in %s, expected 1 to %d", request.Params.URI, len(stored.chunks))
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: stored.mimeType, Text: stored.chunks[chunk-1]},
+		}, nil
+	}
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSplitChunks verifies that texts are split in chunks of at most the chunk size
+// without splitting UTF-8 characters.
+func TestSplitChunks(t *testing.T) {
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, splitChunks("abcdefghij", 4))
+	assert.Equal(t, []string{"abc"}, splitChunks("abc", 4))
+	assert.Equal(t, []string{"ab", "éc", "d"}, splitChunks("abécd", 3), "é is two bytes")
+	assert.Equal(t, strings.Repeat("é", 50), strings.Join(splitChunks(strings.Repeat("é", 50), 7), ""))
+}
+
+// TestResultStore verifies that the oldest results are dropped once the store is full.
+func TestResultStore(t *testing.T) {
+	store := newResultStore(4)
+
+	first, err := store.add("first result", "text/plain")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"firs", "t re", "sult"}, first.chunks)
+	assert.Equal(t, 12, first.size)
+
+	for range maxStoredResults {
+		_, err := store.add("another result", "text/plain")
+		require.NoError(t, err)
+	}
+	_, found := store.get(first.id)
+	assert.False(t, found, "The oldest result is dropped")
+	assert.Len(t, store.results, maxStoredResults)
+}
+
+// TestChunkingMiddleware verifies that oversized results are replaced by their first chunk
+// and can be read back as MCP resources.
+func TestChunkingMiddleware(t *testing.T) {
+	s := &PortainerMCPServer{results: newResultStore(10)}
+	payload := "0123456789abcdefghijKLMNO"
+
+	handler := s.chunkingMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText(payload)
+		result.Meta = &mcp.Meta{AdditionalFields: map[string]any{metaContentTypes: []contentType{newContentType(0, "application/json")}}}
+		result.StructuredContent = map[string]any{"payload": payload}
+		return result, nil
+	})
+	result, err := handler(context.Background(), CreateMCPRequest(nil))
+	require.NoError(t, err)
+
+	require.Len(t, s.results.results, 1)
+	stored := s.results.results[0]
+	uri := "portainer://results/" + stored.id
+
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, "0123456789\n\n[Result too large: showing chunk 1 of 3 (10 of 25 bytes)."))
+	assert.Contains(t, text, uri+"/chunks/2 to "+uri+"/chunks/3")
+	assert.Equal(t, []map[string]any{{"uri": uri, "chunks": 3, "totalBytes": 25, "mimeType": "application/json"}}, resultMeta(result, metaChunkedResults))
+	assert.Nil(t, result.StructuredContent, "Chunked results carry no structured content")
+
+	readRequest := func(uri string, args map[string]any) mcp.ReadResourceRequest {
+		var req mcp.ReadResourceRequest
+		req.Params.URI = uri
+		req.Params.Arguments = args
+		return req
+	}
+
+	contents, err := s.HandleReadResult()(context.Background(), readRequest(uri, map[string]any{"id": []string{stored.id}}))
+	require.NoError(t, err)
+	assert.Equal(t, payload, contents[0].(mcp.TextResourceContents).Text)
+	assert.Equal(t, "application/json", contents[0].(mcp.TextResourceContents).MIMEType)
+
+	contents, err = s.HandleReadResultChunk()(context.Background(), readRequest(uri+"/chunks/3", map[string]any{"id": []string{stored.id}, "chunk": []string{"3"}}))
+	require.NoError(t, err)
+	assert.Equal(t, "KLMNO", contents[0].(mcp.TextResourceContents).Text)
+
+	_, err = s.HandleReadResultChunk()(context.Background(), readRequest(uri+"/chunks/4", map[string]any{"id": []string{stored.id}, "chunk": []string{"4"}}))
+	assert.ErrorContains(t, err, "expected 1 to 3")
+
+	_, err = s.HandleReadResult()(context.Background(), readRequest("portainer://results/missing", map[string]any{"id": []string{"missing"}}))
+	assert.ErrorContains(t, err, "does not exist or has expired")
+
+	small := s.chunkingMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("short"), nil
+	})
+	result, err = small(context.Background(), CreateMCPRequest(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "short", result.Content[0].(mcp.TextContent).Text)
+	assert.Nil(t, result.Meta)
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultMaxRetries is the default number of automatic retries of a retry-safe call.
+	DefaultMaxRetries = 2
+	// metaRetries is the _meta key of the number of retries a call needed.
+	metaRetries = "retries"
+)
+
+// retryBaseDelay is the delay before the first retry; it doubles before each next one.
+var retryBaseDelay = 500 * time.Millisecond
+
+// proxyMethodTools are the tools forwarding a request whose HTTP method, rather than
+// their annotations, decides whether they can be retried.
+var proxyMethodTools = map[string]bool{
+	ToolDockerProxy:     true,
+	ToolKubernetesProxy: true,
+}
+
+// retrySafe reports whether a call may be retried automatically after a transient
+// failure, from the tools.yaml annotations of its tool, which meta-tool actions share:
+//   - reads, plan previews and proxied GET or HEAD requests are safe
+//   - updates annotated as idempotent, which send the same payload again, are safe
+//   - creates and other non-idempotent writes are never retried, since the first
+//     attempt may have succeeded before the failure
+//   - deletes are never retried, since a retry would report the deleted resource missing
+func (s *PortainerMCPServer) retrySafe(request mcp.CallToolRequest) bool {
+	name := requestToolName(request)
+	if proxyMethodTools[name] {
+		method, _ := request.GetArguments()["method"].(string)
+		method = strings.ToUpper(method)
+		return method == http.MethodGet || method == http.MethodHead
+	}
+
+	switch s.operationKind(request) {
+	case operationRead:
+		return true
+	case operationDestructive:
+		return false
+	}
+	if name == ToolApplyPlan {
+		return false
+	}
+	tool, ok := s.toolDefinition(name)
+	return ok && tool.Annotations.IdempotentHint != nil && *tool.Annotations.IdempotentHint
+}
+
+// retryMiddleware retries the retry-safe calls that fail because Portainer or the
+// environment is unavailable, up to the configured number of times with an exponential
+// backoff. The number of retries is set in the _meta of the result. Retries stop when
+// the call context ends, so they stay within the tool timeout.
+func (s *PortainerMCPServer) retryMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if !retryable(result, err) || !s.retrySafe(request) {
+			return result, err
+		}
+
+		delay := retryBaseDelay
+		retries := 0
+		for retries < s.maxRetries && retryable(result, err) {
+			log.Debug().Str("tool", request.Params.Name).Int("retry", retries+1).Dur("delay", delay).Msg("retrying a call failing upstream")
+			s.logToClient(ctx, mcp.LoggingLevelWarning, map[string]any{
+				zerolog.MessageFieldName: "retrying a call failing upstream",
+				"tool":                   request.Params.Name,
+				"retry":                  retries + 1,
+				"delay":                  delay.String(),
+			})
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return result, err
+			}
+			result, err = next(ctx, request)
+			retries++
+			delay *= 2
+		}
+
+		if err == nil && result != nil {
+			setResultMeta(result, metaRetries, retries)
+		}
+		return result, err
+	}
+}
+
+// retryable reports whether a call failed because Portainer or the environment was
+// unavailable, so that it may succeed later.
+func retryable(result *mcp.CallToolResult, err error) bool {
+	return err == nil && result != nil && result.IsError && resultErrorCode(result) == ErrorCodeUpstreamUnavailable
+}
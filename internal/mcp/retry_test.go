@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRetrySafe verifies the retry classification of the tools defined in tools.yaml.
+func TestRetrySafe(t *testing.T) {
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+	s := &PortainerMCPServer{tools: tools}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want bool
+	}{
+		{name: "read", tool: ToolListStacks, want: true},
+		{name: "meta read", tool: "manage_stacks", args: map[string]any{"action": "list_stacks"}, want: true},
+		{name: "idempotent update", tool: ToolUpdateStack, want: true},
+		{name: "create", tool: ToolCreateStack, want: false},
+		{name: "meta create", tool: "manage_stacks", args: map[string]any{"action": "create_stack"}, want: false},
+		{name: "delete", tool: ToolDeleteStack, want: false},
+		{name: "plan preview", tool: ToolRedeployStacksForImage, args: map[string]any{"plan": true}, want: true},
+		{name: "proxy GET", tool: ToolDockerProxy, args: map[string]any{"method": "get"}, want: true},
+		{name: "proxy POST", tool: ToolDockerProxy, args: map[string]any{"method": "POST"}, want: false},
+		{name: "apply plan", tool: ToolApplyPlan, args: map[string]any{"planId": "unknown"}, want: false},
+		{name: "unknown tool", tool: "unknownTool", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+			assert.Equal(t, tt.want, s.retrySafe(request))
+		})
+	}
+
+	for name := range tools {
+		if strings.HasPrefix(name, "create") {
+			request := CreateMCPRequest(nil)
+			request.Params.Name = name
+			assert.False(t, s.retrySafe(request), "create tool %s must never be retried", name)
+		}
+	}
+}
+
+// TestRetryMiddleware verifies that retry-safe calls failing upstream are retried with a
+// backoff, and that other failures and calls are not.
+func TestRetryMiddleware(t *testing.T) {
+	defaultDelay := retryBaseDelay
+	retryBaseDelay = 5 * time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = defaultDelay })
+
+	s := &PortainerMCPServer{tools: budgetTestTools(), maxRetries: 2}
+	s.tools[ToolCreateStack] = mcp.NewTool(ToolCreateStack, mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		ReadOnlyHint: boolPtr(false), DestructiveHint: boolPtr(false), IdempotentHint: boolPtr(false),
+	}))
+
+	call := func(tool string, failures int, message string) (*mcp.CallToolResult, int) {
+		calls := 0
+		handler := s.retryMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls++
+			if calls <= failures {
+				return mcp.NewToolResultError(message), nil
+			}
+			return mcp.NewToolResultText("ok"), nil
+		})
+		request := CreateMCPRequest(nil)
+		request.Params.Name = tool
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result, calls
+	}
+
+	start := time.Now()
+	result, calls := call(ToolListStacks, 2, "failed to list stacks: dial tcp: connection refused")
+	assert.False(t, result.IsError)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, resultMeta(result, metaRetries))
+	assert.GreaterOrEqual(t, time.Since(start), 3*retryBaseDelay, "the delay doubles before each retry")
+
+	result, calls = call(ToolListStacks, 5, "failed to list stacks: [GET /stacks][503] unavailable")
+	assert.True(t, result.IsError, "the last failure is returned once the retries are spent")
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, resultMeta(result, metaRetries))
+
+	result, calls = call(ToolListStacks, 1, "failed to get stack: [GET /stacks/{id}][404] stackInspectNotFound")
+	assert.True(t, result.IsError)
+	assert.Equal(t, 1, calls, "only upstream failures are retried")
+	assert.Nil(t, result.Meta)
+
+	_, calls = call(ToolCreateStack, 1, "failed to create stack: i/o timeout")
+	assert.Equal(t, 1, calls, "creates are never retried")
+
+	_, calls = call(ToolDeleteStack, 1, "failed to delete stack: i/o timeout")
+	assert.Equal(t, 1, calls, "deletes are never retried")
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		handler := s.retryMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls++
+			cancel()
+			return mcp.NewToolResultError("context deadline exceeded"), nil
+		})
+		request := CreateMCPRequest(nil)
+		request.Params.Name = ToolListStacks
+		result, err := handler(ctx, request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, 1, calls)
+	})
+}
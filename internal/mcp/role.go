@@ -2,14 +2,78 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// roleDetail is the result of HandleGetRole.
+type roleDetail struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+	// Authorizations lists the authorizations granted by the role, sorted by name.
+	Authorizations []string `json:"authorizations"`
+}
+
+// roleSummary identifies a role in a roleComparison.
+type roleSummary struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	Priority       int    `json:"priority"`
+	Authorizations int    `json:"authorizations"`
+}
+
+// roleComparison is the result of HandleCompareRoles.
+type roleComparison struct {
+	Role      roleSummary `json:"role"`
+	OtherRole roleSummary `json:"other_role"`
+	// OnlyInRole lists the authorizations granted by the role but not by the other role.
+	OnlyInRole []string `json:"only_in_role"`
+	// OnlyInOtherRole lists the authorizations granted by the other role but not by the role.
+	OnlyInOtherRole []string `json:"only_in_other_role"`
+	Shared          []string `json:"shared"`
+	Identical       bool     `json:"identical"`
+}
+
+// Scopes of a role assignment.
+const (
+	roleScopeEnvironment = "environment"
+	roleScopeAccessGroup = "access_group"
+)
+
+// roleAssignment is the result of HandleAssignRole and HandleUnassignRole.
+type roleAssignment struct {
+	Scope      string `json:"scope"`
+	ScopeID    int    `json:"scope_id"`
+	Assignee   string `json:"assignee"`
+	AssigneeID int    `json:"assignee_id"`
+	// RoleID is 0 when the assignment was removed.
+	RoleID   int    `json:"role_id"`
+	RoleName string `json:"role_name,omitempty"`
+	// PreviousRoleID is 0 when the assignee had no role on the scope.
+	PreviousRoleID   int    `json:"previous_role_id"`
+	PreviousRoleName string `json:"previous_role_name,omitempty"`
+	Note             string `json:"note,omitempty"`
+}
+
 // AddRoleFeatures registers the role management tools on the MCP server.
 func (s *PortainerMCPServer) AddRoleFeatures() {
 	s.addToolIfExists(ToolListRoles, s.HandleListRoles())
+	s.addToolIfExists(ToolGetRole, s.HandleGetRole())
+	s.addToolIfExists(ToolCompareRoles, s.HandleCompareRoles())
+
+	if !s.readOnly {
+		s.addToolIfExists(ToolAssignRole, s.HandleAssignRole())
+		s.addToolIfExists(ToolUnassignRole, s.HandleUnassignRole())
+	}
 }
 
 // HandleListRoles returns an MCP tool handler that lists roles.
@@ -23,3 +87,266 @@ func (s *PortainerMCPServer) HandleListRoles() server.ToolHandlerFunc {
 		return jsonResult(roles, "failed to marshal roles")
 	}
 }
+
+// HandleGetRole returns an MCP tool handler that retrieves a role with the sorted list of
+// the authorizations it grants.
+func (s *PortainerMCPServer) HandleGetRole() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		roles, err := s.cli.GetRoles()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list roles", err), nil
+		}
+
+		role, err := findRole(roles, id)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		detail := roleDetail{
+			ID:             role.ID,
+			Name:           role.Name,
+			Description:    role.Description,
+			Priority:       role.Priority,
+			Authorizations: grantedAuthorizations(role),
+		}
+		return jsonResult(detail, "failed to marshal role")
+	}
+}
+
+// HandleCompareRoles returns an MCP tool handler that diffs the authorizations granted by
+// two roles.
+func (s *PortainerMCPServer) HandleCompareRoles() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		roleID, err := parser.GetInt("roleId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid roleId parameter", err), nil
+		}
+		if err := validatePositiveID("roleId", roleID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		otherRoleID, err := parser.GetInt("otherRoleId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid otherRoleId parameter", err), nil
+		}
+		if err := validatePositiveID("otherRoleId", otherRoleID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		roles, err := s.cli.GetRoles()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list roles", err), nil
+		}
+
+		role, err := findRole(roles, roleID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		otherRole, err := findRole(roles, otherRoleID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return jsonResult(compareRoles(role, otherRole), "failed to marshal role comparison")
+	}
+}
+
+// compareRoles splits the authorizations granted by two roles into the ones granted by a
+// single role and the shared ones.
+func compareRoles(role, otherRole models.Role) roleComparison {
+	granted := grantedAuthorizations(role)
+	otherGranted := grantedAuthorizations(otherRole)
+
+	comparison := roleComparison{
+		Role:            roleSummary{ID: role.ID, Name: role.Name, Priority: role.Priority, Authorizations: len(granted)},
+		OtherRole:       roleSummary{ID: otherRole.ID, Name: otherRole.Name, Priority: otherRole.Priority, Authorizations: len(otherGranted)},
+		OnlyInRole:      []string{},
+		OnlyInOtherRole: []string{},
+		Shared:          []string{},
+	}
+	for _, name := range granted {
+		if otherRole.Authorizations[name] {
+			comparison.Shared = append(comparison.Shared, name)
+		} else {
+			comparison.OnlyInRole = append(comparison.OnlyInRole, name)
+		}
+	}
+	for _, name := range otherGranted {
+		if !role.Authorizations[name] {
+			comparison.OnlyInOtherRole = append(comparison.OnlyInOtherRole, name)
+		}
+	}
+	comparison.Identical = len(comparison.OnlyInRole) == 0 && len(comparison.OnlyInOtherRole) == 0
+
+	return comparison
+}
+
+// findRole returns the role with the given ID.
+func findRole(roles []models.Role, id int) (models.Role, error) {
+	idx := slices.IndexFunc(roles, func(r models.Role) bool { return r.ID == id })
+	if idx < 0 {
+		return models.Role{}, fmt.Errorf("role %d not found", id)
+	}
+	return roles[idx], nil
+}
+
+// grantedAuthorizations returns the sorted names of the authorizations granted by a role.
+func grantedAuthorizations(role models.Role) []string {
+	granted := []string{}
+	for name, allowed := range role.Authorizations {
+		if allowed {
+			granted = append(granted, name)
+		}
+	}
+	sort.Strings(granted)
+	return granted
+}
+
+// HandleAssignRole returns an MCP tool handler that assigns a role, built-in or custom, to
+// a user or team on an environment or access group. The other access policies of the
+// environment or group are kept.
+func (s *PortainerMCPServer) HandleAssignRole() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		roleID, err := parser.GetInt("roleId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid roleId parameter", err), nil
+		}
+		if err := validatePositiveID("roleId", roleID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		assignment, err := parseRoleAssignment(parser)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		roles, err := s.cli.GetRoles()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list roles", err), nil
+		}
+		role, err := findRole(roles, roleID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		previous, err := s.assignRole(assignment, roleID)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to assign role", err), nil
+		}
+
+		assignment.RoleID, assignment.RoleName = role.ID, role.Name
+		setPreviousRole(&assignment, roles, previous)
+		return jsonResult(assignment, "failed to marshal role assignment")
+	}
+}
+
+// HandleUnassignRole returns an MCP tool handler that removes the role of a user or team
+// on an environment or access group.
+func (s *PortainerMCPServer) HandleUnassignRole() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		assignment, err := parseRoleAssignment(parser)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		previous, err := s.assignRole(assignment, 0)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to remove role assignment", err), nil
+		}
+
+		if previous == 0 {
+			assignment.Note = fmt.Sprintf("the %s had no role on the %s", assignment.Assignee, strings.ReplaceAll(assignment.Scope, "_", " "))
+		} else {
+			// The role name is informative only, so a failed listing does not fail the removal
+			roles, _ := s.cli.GetRoles()
+			setPreviousRole(&assignment, roles, previous)
+		}
+		return jsonResult(assignment, "failed to marshal role assignment")
+	}
+}
+
+// assignRole sets the role of the assignee on the scope of a role assignment, removing
+// the assignment when roleID is 0. It returns the previous role ID.
+func (s *PortainerMCPServer) assignRole(assignment roleAssignment, roleID int) (int, error) {
+	if assignment.Scope == roleScopeAccessGroup {
+		return s.cli.AssignAccessGroupRole(assignment.ScopeID, assignment.Assignee, assignment.AssigneeID, roleID)
+	}
+	return s.cli.AssignEnvironmentRole(assignment.ScopeID, assignment.Assignee, assignment.AssigneeID, roleID)
+}
+
+// parseRoleAssignment parses the scope (environmentId or accessGroupId) and the assignee
+// (userId or teamId) of a role assignment. Exactly one of each pair must be given.
+func parseRoleAssignment(parser *toolgen.ParameterParser) (roleAssignment, error) {
+	var assignment roleAssignment
+
+	environmentId, err := parser.GetInt("environmentId", false)
+	if err != nil {
+		return assignment, fmt.Errorf("invalid environmentId parameter: %w", err)
+	}
+	accessGroupId, err := parser.GetInt("accessGroupId", false)
+	if err != nil {
+		return assignment, fmt.Errorf("invalid accessGroupId parameter: %w", err)
+	}
+	switch {
+	case parser.Has("environmentId") == parser.Has("accessGroupId"):
+		return assignment, fmt.Errorf("exactly one of environmentId and accessGroupId is required")
+	case parser.Has("environmentId"):
+		assignment.Scope, assignment.ScopeID = roleScopeEnvironment, environmentId
+		err = validatePositiveID("environmentId", environmentId)
+	default:
+		assignment.Scope, assignment.ScopeID = roleScopeAccessGroup, accessGroupId
+		err = validatePositiveID("accessGroupId", accessGroupId)
+	}
+	if err != nil {
+		return assignment, err
+	}
+
+	userId, err := parser.GetInt("userId", false)
+	if err != nil {
+		return assignment, fmt.Errorf("invalid userId parameter: %w", err)
+	}
+	teamId, err := parser.GetInt("teamId", false)
+	if err != nil {
+		return assignment, fmt.Errorf("invalid teamId parameter: %w", err)
+	}
+	switch {
+	case parser.Has("userId") == parser.Has("teamId"):
+		return assignment, fmt.Errorf("exactly one of userId and teamId is required")
+	case parser.Has("userId"):
+		assignment.Assignee, assignment.AssigneeID = models.RoleAssigneeUser, userId
+		err = validatePositiveID("userId", userId)
+	default:
+		assignment.Assignee, assignment.AssigneeID = models.RoleAssigneeTeam, teamId
+		err = validatePositiveID("teamId", teamId)
+	}
+	if err != nil {
+		return assignment, err
+	}
+
+	return assignment, nil
+}
+
+// setPreviousRole records the previous role of a role assignment, with its name when the
+// role is found.
+func setPreviousRole(assignment *roleAssignment, roles []models.Role, previous int) {
+	assignment.PreviousRoleID = previous
+	if role, err := findRole(roles, previous); err == nil {
+		assignment.PreviousRoleName = role.Name
+	}
+}
@@ -78,3 +78,318 @@ func TestHandleListRoles(t *testing.T) {
 		})
 	}
 }
+
+// TestHandleGetRole verifies the HandleGetRole MCP tool handler.
+func TestHandleGetRole(t *testing.T) {
+	roles := []models.Role{
+		{
+			ID:          1,
+			Name:        "Environment administrator",
+			Description: "Full control of all resources in an environment",
+			Priority:    1,
+			Authorizations: map[string]bool{
+				"OperationDockerContainerList":   true,
+				"OperationDockerContainerDelete": true,
+				"OperationDockerImageBuild":      false,
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		mockRoles   []models.Role
+		mockError   error
+		expectError string
+		expected    roleDetail
+	}{
+		{
+			name:      "returns the sorted granted authorizations",
+			input:     map[string]any{"id": float64(1)},
+			mockRoles: roles,
+			expected: roleDetail{
+				ID:             1,
+				Name:           "Environment administrator",
+				Description:    "Full control of all resources in an environment",
+				Priority:       1,
+				Authorizations: []string{"OperationDockerContainerDelete", "OperationDockerContainerList"},
+			},
+		},
+		{
+			name:        "unknown role",
+			input:       map[string]any{"id": float64(9)},
+			mockRoles:   roles,
+			expectError: "role 9 not found",
+		},
+		{
+			name:        "api error",
+			input:       map[string]any{"id": float64(1)},
+			mockError:   fmt.Errorf("api error"),
+			expectError: "api error",
+		},
+		{
+			name:        "missing id",
+			input:       map[string]any{},
+			expectError: "id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.mockRoles != nil || tt.mockError != nil {
+				mockClient.On("GetRoles").Return(tt.mockRoles, tt.mockError)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			request := CreateMCPRequest(tt.input)
+			result, err := server.HandleGetRole()(context.Background(), request)
+			assert.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			assert.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				assert.False(t, result.IsError)
+				var detail roleDetail
+				assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &detail))
+				assert.Equal(t, tt.expected, detail)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleCompareRoles verifies the HandleCompareRoles MCP tool handler.
+func TestHandleCompareRoles(t *testing.T) {
+	roles := []models.Role{
+		{
+			ID:       1,
+			Name:     "Environment administrator",
+			Priority: 1,
+			Authorizations: map[string]bool{
+				"OperationDockerContainerList":   true,
+				"OperationDockerContainerDelete": true,
+				"OperationDockerImageList":       true,
+			},
+		},
+		{
+			ID:       4,
+			Name:     "Read-only user",
+			Priority: 4,
+			Authorizations: map[string]bool{
+				"OperationDockerContainerList":      true,
+				"OperationDockerContainerDelete":    false,
+				"OperationDockerImageList":          true,
+				"OperationPortainerUserMemberships": true,
+			},
+		},
+	}
+
+	t.Run("diffs the granted authorizations", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetRoles").Return(roles, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"roleId": float64(1), "otherRoleId": float64(4)})
+		result, err := server.HandleCompareRoles()(context.Background(), request)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+
+		var comparison roleComparison
+		assert.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &comparison))
+		assert.Equal(t, roleSummary{ID: 1, Name: "Environment administrator", Priority: 1, Authorizations: 3}, comparison.Role)
+		assert.Equal(t, roleSummary{ID: 4, Name: "Read-only user", Priority: 4, Authorizations: 3}, comparison.OtherRole)
+		assert.Equal(t, []string{"OperationDockerContainerDelete"}, comparison.OnlyInRole)
+		assert.Equal(t, []string{"OperationPortainerUserMemberships"}, comparison.OnlyInOtherRole)
+		assert.Equal(t, []string{"OperationDockerContainerList", "OperationDockerImageList"}, comparison.Shared)
+		assert.False(t, comparison.Identical)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("a role compared with itself is identical", func(t *testing.T) {
+		comparison := compareRoles(roles[0], roles[0])
+		assert.True(t, comparison.Identical)
+		assert.Empty(t, comparison.OnlyInRole)
+		assert.Empty(t, comparison.OnlyInOtherRole)
+		assert.Len(t, comparison.Shared, 3)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetRoles").Return(roles, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"roleId": float64(1), "otherRoleId": float64(7)})
+		result, err := server.HandleCompareRoles()(context.Background(), request)
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "role 7 not found")
+	})
+
+	t.Run("invalid otherRoleId", func(t *testing.T) {
+		server := &PortainerMCPServer{cli: &MockPortainerClient{}}
+		request := CreateMCPRequest(map[string]any{"roleId": float64(1), "otherRoleId": float64(0)})
+		result, err := server.HandleCompareRoles()(context.Background(), request)
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "otherRoleId")
+	})
+}
+
+// TestHandleAssignRole verifies the HandleAssignRole MCP tool handler.
+func TestHandleAssignRole(t *testing.T) {
+	roles := []models.Role{
+		{ID: 3, Name: "Standard user"},
+		{ID: 4, Name: "Read-only user"},
+		{ID: 8, Name: "Deployer"},
+	}
+
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+		expected    roleAssignment
+	}{
+		{
+			name:  "custom role for a user on an environment",
+			input: map[string]any{"roleId": float64(8), "environmentId": float64(2), "userId": float64(5)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRoles").Return(roles, nil)
+				m.On("AssignEnvironmentRole", 2, models.RoleAssigneeUser, 5, 8).Return(3, nil)
+			},
+			expected: roleAssignment{
+				Scope: roleScopeEnvironment, ScopeID: 2, Assignee: models.RoleAssigneeUser, AssigneeID: 5,
+				RoleID: 8, RoleName: "Deployer", PreviousRoleID: 3, PreviousRoleName: "Standard user",
+			},
+		},
+		{
+			name:  "role for a team on an access group",
+			input: map[string]any{"roleId": float64(4), "accessGroupId": float64(1), "teamId": float64(6)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRoles").Return(roles, nil)
+				m.On("AssignAccessGroupRole", 1, models.RoleAssigneeTeam, 6, 4).Return(0, nil)
+			},
+			expected: roleAssignment{
+				Scope: roleScopeAccessGroup, ScopeID: 1, Assignee: models.RoleAssigneeTeam, AssigneeID: 6,
+				RoleID: 4, RoleName: "Read-only user",
+			},
+		},
+		{
+			name:  "unknown role",
+			input: map[string]any{"roleId": float64(9), "environmentId": float64(2), "userId": float64(5)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRoles").Return(roles, nil)
+			},
+			expectError: "role 9 not found",
+		},
+		{
+			name:  "assignment error",
+			input: map[string]any{"roleId": float64(8), "environmentId": float64(2), "userId": float64(5)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetRoles").Return(roles, nil)
+				m.On("AssignEnvironmentRole", 2, models.RoleAssigneeUser, 5, 8).Return(0, fmt.Errorf("forbidden"))
+			},
+			expectError: "forbidden",
+		},
+		{
+			name:        "environment and access group",
+			input:       map[string]any{"roleId": float64(8), "environmentId": float64(2), "accessGroupId": float64(1), "userId": float64(5)},
+			expectError: "exactly one of environmentId and accessGroupId",
+		},
+		{
+			name:        "no assignee",
+			input:       map[string]any{"roleId": float64(8), "environmentId": float64(2)},
+			expectError: "exactly one of userId and teamId",
+		},
+		{
+			name:        "invalid teamId",
+			input:       map[string]any{"roleId": float64(8), "environmentId": float64(2), "teamId": float64(-1)},
+			expectError: "teamId",
+		},
+		{
+			name:        "missing roleId",
+			input:       map[string]any{"environmentId": float64(2), "userId": float64(5)},
+			expectError: "roleId",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient}
+			result, err := server.HandleAssignRole()(context.Background(), CreateMCPRequest(tt.input))
+			assert.NoError(t, err)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			assert.True(t, ok)
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+			} else {
+				assert.False(t, result.IsError)
+				var assignment roleAssignment
+				assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &assignment))
+				assert.Equal(t, tt.expected, assignment)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleUnassignRole verifies the HandleUnassignRole MCP tool handler.
+func TestHandleUnassignRole(t *testing.T) {
+	t.Run("removes the role of a team", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("AssignEnvironmentRole", 2, models.RoleAssigneeTeam, 6, 0).Return(4, nil)
+		mockClient.On("GetRoles").Return([]models.Role{{ID: 4, Name: "Read-only user"}}, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"environmentId": float64(2), "teamId": float64(6)})
+		result, err := server.HandleUnassignRole()(context.Background(), request)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+
+		var assignment roleAssignment
+		assert.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &assignment))
+		assert.Equal(t, roleAssignment{
+			Scope: roleScopeEnvironment, ScopeID: 2, Assignee: models.RoleAssigneeTeam, AssigneeID: 6,
+			PreviousRoleID: 4, PreviousRoleName: "Read-only user",
+		}, assignment)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("no role assigned", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("AssignAccessGroupRole", 1, models.RoleAssigneeUser, 5, 0).Return(0, nil)
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"accessGroupId": float64(1), "userId": float64(5)})
+		result, err := server.HandleUnassignRole()(context.Background(), request)
+		assert.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "the user had no role on the access group")
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("removal error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("AssignAccessGroupRole", 1, models.RoleAssigneeUser, 5, 0).Return(0, fmt.Errorf("access group 1 not found"))
+
+		server := &PortainerMCPServer{cli: mockClient}
+		request := CreateMCPRequest(map[string]any{"accessGroupId": float64(1), "userId": float64(5)})
+		result, err := server.HandleUnassignRole()(context.Background(), request)
+		assert.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "access group 1 not found")
+	})
+}
@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// environmentRootPrefix is the prefix of the client roots that name an environment,
+	// as in portainer://environments/3.
+	environmentRootPrefix = "portainer://environments/"
+
+	// rootsRequestTimeout is how long the server waits for the client to list its roots.
+	rootsRequestTimeout = 10 * time.Second
+
+	methodListRoots                = "roots/list"
+	methodNotificationInitialized  = "notifications/initialized"
+	methodNotificationRootsChanged = "notifications/roots/list_changed"
+)
+
+// rootsScope keeps the environments that the client roots allow the session to address.
+// The zero value allows every environment.
+type rootsScope struct {
+	mu sync.Mutex
+	// ids are the environments named by the client roots (nil when no root names an
+	// environment).
+	ids map[int]bool
+	// loaded is closed once the first roots list is known (nil when the client does not
+	// support roots).
+	loaded chan struct{}
+}
+
+// expect records that the client supports roots, so that calls wait for its first roots
+// list before they are checked.
+func (r *rootsScope) expect() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.loaded == nil {
+		r.loaded = make(chan struct{})
+	}
+}
+
+// set replaces the environments allowed by the client roots, and releases the calls
+// waiting for the first roots list.
+func (r *rootsScope) set(ids map[int]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ids = ids
+	r.release()
+}
+
+// release releases the calls waiting for the first roots list, when the roots cannot be
+// listed. It must be called with the lock held.
+func (r *rootsScope) release() {
+	if r.loaded == nil {
+		return
+	}
+	select {
+	case <-r.loaded:
+	default:
+		close(r.loaded)
+	}
+}
+
+// allowed returns the environments that the client roots allow, once the first roots
+// list is known, or nil when every environment is allowed.
+func (r *rootsScope) allowed(ctx context.Context) (map[int]bool, error) {
+	r.mu.Lock()
+	loaded := r.loaded
+	r.mu.Unlock()
+
+	if loaded != nil {
+		select {
+		case <-loaded:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.ids, nil
+}
+
+// denied returns the given environment IDs that the client roots do not name, once the
+// first roots list is known.
+func (r *rootsScope) denied(ctx context.Context, ids []int) ([]int, error) {
+	allowed, err := r.allowed(ctx)
+	if err != nil || allowed == nil {
+		return nil, err
+	}
+	var denied []int
+	for _, id := range ids {
+		if !allowed[id] && !slices.Contains(denied, id) {
+			denied = append(denied, id)
+		}
+	}
+	return denied, nil
+}
+
+// rootEnvironments returns the environments named by the portainer://environments/{id}
+// roots, or nil when no root names an environment. Roots of other schemes, such as the
+// file:// roots of a workspace, are ignored. A malformed environment root still restricts
+// the session, so that a typo never widens it.
+func rootEnvironments(roots []mcp.Root) map[int]bool {
+	var ids map[int]bool
+	for _, root := range roots {
+		value, ok := strings.CutPrefix(root.URI, environmentRootPrefix)
+		if !ok {
+			continue
+		}
+		if ids == nil {
+			ids = map[int]bool{}
+		}
+		id, err := strconv.Atoi(strings.TrimSuffix(value, "/"))
+		if err != nil || id <= 0 {
+			log.Warn().Str("root", root.URI).Msg("ignoring invalid environment root")
+			continue
+		}
+		ids[id] = true
+	}
+	return ids
+}
+
+// rootsHooks returns the hooks recording whether the client supports roots
+func (s *PortainerMCPServer) rootsHooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddAfterInitialize(func(ctx context.Context, id any, message *mcp.InitializeRequest, result *mcp.InitializeResult) {
+		if s.client != nil && message.Params.Capabilities.Roots != nil {
+			s.roots.expect()
+		}
+	})
+	return hooks
+}
+
+// addRootsHandlers lists the client roots once the session is initialized, and again
+// each time the client reports that they changed.
+func (s *PortainerMCPServer) addRootsHandlers() {
+	refresh := func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		// The stdio server handles one message at a time, and the response to roots/list
+		// is read while the server handles the next messages.
+		go s.refreshRoots()
+	}
+	s.srv.AddNotificationHandler(methodNotificationInitialized, func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		s.roots.mu.Lock()
+		supported := s.roots.loaded != nil
+		s.roots.mu.Unlock()
+		if supported {
+			refresh(ctx, notification)
+		}
+	})
+	s.srv.AddNotificationHandler(methodNotificationRootsChanged, refresh)
+}
+
+// refreshRoots lists the client roots and narrows the session to the environments they
+// name. When the client does not answer, the previous roots are kept.
+func (s *PortainerMCPServer) refreshRoots() {
+	if s.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rootsRequestTimeout)
+	defer cancel()
+
+	var result mcp.ListRootsResult
+	if err := s.client.request(ctx, methodListRoots, nil, &result); err != nil {
+		log.Warn().Err(err).Msg("failed to list client roots")
+		s.roots.mu.Lock()
+		s.roots.release()
+		s.roots.mu.Unlock()
+		return
+	}
+
+	ids := rootEnvironments(result.Roots)
+	if ids != nil {
+		environments := make([]int, 0, len(ids))
+		for id := range ids {
+			environments = append(environments, id)
+		}
+		slices.Sort(environments)
+		log.Info().Ints("environments", environments).Msg("session narrowed to the environments of the client roots")
+	}
+	s.roots.set(ids)
+}
+
+// rootsEnvironments lists the environments matching the options, without those outside
+// the client roots. Tools that resolve their environments from a filter or from the whole
+// fleet use it, since the roots middleware only checks the environment IDs of a call.
+func (s *PortainerMCPServer) rootsEnvironments(ctx context.Context, opts models.EnvironmentListOptions) ([]models.Environment, error) {
+	environments, err := s.cli.GetEnvironments(opts)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.roots.allowed(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if allowed == nil {
+		return environments, nil
+	}
+	return slices.DeleteFunc(environments, func(environment models.Environment) bool {
+		return !allowed[environment.ID]
+	}), nil
+}
+
+// rootsScopeMiddleware rejects tool calls that target environments outside the
+// portainer://environments/{id} roots declared by the client. It complements the
+// environment scope of the API token: the client can only narrow the environments
+// the session may address, never widen them.
+func (s *PortainerMCPServer) rootsScopeMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ids := requestEnvironmentIDs(request)
+		if len(ids) == 0 {
+			return next(ctx, request)
+		}
+
+		denied, err := s.roots.denied(ctx, ids)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to check client roots", err), nil
+		}
+		if len(denied) > 0 {
+			log.Warn().Str("tool", request.Params.Name).Ints("environments", denied).Msg("tool call outside the client roots rejected")
+			return newToolResultErrorWithCode(ErrorCodeForbidden, fmt.Sprintf("environment(s) %v are outside the roots declared by the client; add portainer://environments/{id} roots to address them", denied)), nil
+		}
+
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRootEnvironments verifies the environments named by the client roots.
+func TestRootEnvironments(t *testing.T) {
+	tests := []struct {
+		name     string
+		roots    []mcp.Root
+		expected map[int]bool
+	}{
+		{
+			name: "no roots",
+		},
+		{
+			name:  "workspace roots only",
+			roots: []mcp.Root{{URI: "file:///home/user/project"}},
+		},
+		{
+			name: "environment roots",
+			roots: []mcp.Root{
+				{URI: "file:///home/user/project"},
+				{URI: "portainer://environments/1"},
+				{URI: "portainer://environments/3/"},
+			},
+			expected: map[int]bool{1: true, 3: true},
+		},
+		{
+			name:     "malformed environment root",
+			roots:    []mcp.Root{{URI: "portainer://environments/production"}},
+			expected: map[int]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rootEnvironments(tt.roots))
+		})
+	}
+}
+
+// TestRootsScopeMiddleware verifies that calls outside the client roots are rejected
+// before reaching the handler, and that every environment is allowed without roots.
+func TestRootsScopeMiddleware(t *testing.T) {
+	s := &PortainerMCPServer{}
+
+	called := 0
+	handler := s.rootsScopeMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("ok"), nil
+	})
+	call := func(args map[string]any) *mcp.CallToolResult {
+		request := CreateMCPRequest(args)
+		request.Params.Name = ToolListContainers
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		return result
+	}
+
+	result := call(map[string]any{"environmentId": float64(2)})
+	assert.False(t, result.IsError)
+
+	s.roots.set(map[int]bool{1: true})
+
+	result = call(map[string]any{"environmentId": float64(1)})
+	assert.False(t, result.IsError)
+	result = call(map[string]any{"id": float64(5)})
+	assert.False(t, result.IsError)
+
+	result = call(map[string]any{"environmentId": float64(2)})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "environment(s) [2] are outside the roots declared by the client")
+	assert.Equal(t, ErrorCodeForbidden, errorCode(result))
+	assert.Equal(t, 3, called)
+}
+
+// TestRootsScopeWaitsForRoots verifies that calls wait for the first roots list of a
+// client that supports roots.
+func TestRootsScopeWaitsForRoots(t *testing.T) {
+	var scope rootsScope
+	scope.expect()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := scope.denied(ctx, []int{1})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go scope.set(map[int]bool{2: true})
+	denied, err := scope.denied(context.Background(), []int{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, denied)
+}
+
+// TestRefreshRoots verifies that the server lists the client roots over the client
+// connection and narrows the session to them.
+func TestRefreshRoots(t *testing.T) {
+	outReader, outWriter := io.Pipe()
+	inReader, inWriter := io.Pipe()
+	s := &PortainerMCPServer{client: newClientConn(outWriter)}
+	s.client.input(inReader)
+	s.roots.expect()
+
+	done := make(chan struct{})
+	go func() {
+		s.refreshRoots()
+		close(done)
+	}()
+
+	line, err := bufio.NewReader(outReader).ReadBytes('\n')
+	require.NoError(t, err)
+	var request struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+	}
+	require.NoError(t, json.Unmarshal(line, &request))
+	assert.Equal(t, methodListRoots, request.Method)
+
+	_, err = io.WriteString(inWriter, `{"jsonrpc":"2.0","id":"`+request.ID+`","result":{"roots":[{"uri":"portainer://environments/4"}]}}`+"\n")
+	require.NoError(t, err)
+	<-done
+
+	denied, err := s.roots.denied(context.Background(), []int{4, 5})
+	require.NoError(t, err)
+	assert.Equal(t, []int{5}, denied)
+}
+
+// TestRootsNarrowFleetTools verifies that the tools resolving their environments from a
+// filter or from the whole fleet skip the environments outside the client roots.
+func TestRootsNarrowFleetTools(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{
+		{ID: 1, Name: "production"},
+		{ID: 2, Name: "staging"},
+		{ID: 3, Name: "development"},
+	}, nil)
+	mockClient.On("GetEnvironmentSnapshots", models.EnvironmentListOptions{}).Return([]models.EnvironmentSnapshot{
+		{EnvironmentID: 1, Name: "production"},
+		{EnvironmentID: 2, Name: "staging"},
+	}, nil)
+
+	s := &PortainerMCPServer{cli: mockClient}
+	s.roots.set(map[int]bool{1: true, 3: true})
+
+	result, err := s.HandleGetEnvironments()(context.Background(), CreateMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var environments []models.Environment
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &environments))
+	assert.Equal(t, []models.Environment{{ID: 1, Name: "production"}, {ID: 3, Name: "development"}}, environments)
+
+	result, err = s.HandleGetGroupCapacity()(context.Background(), CreateMCPRequest(map[string]any{"includeEnvironments": true}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var report groupCapacityReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+	assert.Equal(t, 1, report.Environments)
+	require.Len(t, report.Details, 1)
+	assert.Equal(t, 1, report.Details[0].EnvironmentID)
+}
@@ -0,0 +1,282 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/redact"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/scheduler"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// scheduledTasksURI is the URI of the resource listing the scheduled tasks.
+	scheduledTasksURI = "portainer://tasks"
+	// scheduledTaskURITemplate is the URI template of the latest report of a scheduled task.
+	scheduledTaskURITemplate = "portainer://tasks/{name}"
+)
+
+// scheduledTask is a task of the internal scheduler with the handler of its tool.
+type scheduledTask struct {
+	task    scheduler.Task
+	handler server.ToolHandlerFunc
+}
+
+// taskReport is the latest result of a scheduled task.
+type taskReport struct {
+	Task       string    `json:"task"`
+	Tool       string    `json:"tool"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	IsError    bool      `json:"is_error"`
+	// Result is the tool result: its JSON document, or its text when it is not JSON.
+	Result json.RawMessage `json:"result"`
+}
+
+// taskSummary describes a scheduled task in the task list resource.
+type taskSummary struct {
+	Name      string         `json:"name"`
+	Tool      string         `json:"tool"`
+	Schedule  string         `json:"schedule"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// URI is the resource holding the latest report of the task.
+	URI     string     `json:"uri"`
+	NextRun *time.Time `json:"next_run,omitempty"`
+	LastRun *time.Time `json:"last_run,omitempty"`
+	// LastIsError tells whether the latest run returned an error result.
+	LastIsError bool `json:"last_is_error,omitempty"`
+}
+
+// taskReportStore keeps the latest report of each scheduled task.
+type taskReportStore struct {
+	mu      sync.Mutex
+	reports map[string]taskReport
+}
+
+// get returns the latest report of a task. The boolean is false when the task has not run yet.
+func (r *taskReportStore) get(name string) (taskReport, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report, ok := r.reports[name]
+	return report, ok
+}
+
+// put replaces the latest report of a task.
+func (r *taskReportStore) put(report taskReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reports == nil {
+		r.reports = map[string]taskReport{}
+	}
+	r.reports[report.Task] = report
+}
+
+// newScheduledTasks resolves the tool of every task to its handler. Only read-only tools
+// can be scheduled. The handlers get the argument rules of the tools and, when an engine
+// is set, the redaction rules, so that the reports hold what a client would receive.
+func (s *PortainerMCPServer) newScheduledTasks(tasks []scheduler.Task, rules *toolgen.ArgumentRules, engine *redact.Engine) ([]scheduledTask, error) {
+	scheduled := make([]scheduledTask, 0, len(tasks))
+	for _, task := range tasks {
+		if _, exists := s.toolDefinition(task.Tool); !exists {
+			return nil, fmt.Errorf("scheduled task %s: unknown tool %s", task.Name, task.Tool)
+		}
+		if !s.toolAllowed(task.Tool) {
+			return nil, fmt.Errorf("scheduled task %s: tool %s is not available to the API token", task.Name, task.Tool)
+		}
+		newHandler, ok := readOnlyToolHandler(task.Tool)
+		if !ok {
+			return nil, fmt.Errorf("scheduled task %s: tool %s is not read-only and cannot be scheduled", task.Name, task.Tool)
+		}
+
+		handler := argumentRulesMiddleware(rules)(newHandler(s))
+		if engine != nil {
+			handler = redactionMiddleware(engine)(handler)
+		}
+		scheduled = append(scheduled, scheduledTask{task: task, handler: handler})
+	}
+	return scheduled, nil
+}
+
+// readOnlyToolHandler returns the handler constructor of a read-only granular tool.
+func readOnlyToolHandler(tool string) (func(*PortainerMCPServer) server.ToolHandlerFunc, bool) {
+	for _, def := range metaToolDefinitions() {
+		for _, action := range def.actions {
+			if action.tool == tool && action.readOnly {
+				return action.handler, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// runScheduledTasks runs the scheduled tasks until ctx is canceled.
+func (s *PortainerMCPServer) runScheduledTasks(ctx context.Context) {
+	tasks := make([]scheduler.Task, len(s.scheduledTasks))
+	for i, scheduled := range s.scheduledTasks {
+		tasks[i] = scheduled.task
+	}
+	log.Info().Int("tasks", len(tasks)).Msg("starting the scheduled tasks")
+	scheduler.Run(ctx, tasks, s.runScheduledTask)
+}
+
+// runScheduledTask calls the tool of a scheduled task and keeps the result as the latest
+// report of the task.
+func (s *PortainerMCPServer) runScheduledTask(ctx context.Context, task scheduler.Task) {
+	var handler server.ToolHandlerFunc
+	for _, scheduled := range s.scheduledTasks {
+		if scheduled.task.Name == task.Name {
+			handler = scheduled.handler
+		}
+	}
+	if handler == nil {
+		return
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = task.Tool
+	request.Params.Arguments = maps.Clone(task.Arguments)
+
+	report := taskReport{Task: task.Name, Tool: task.Tool, StartedAt: time.Now().UTC()}
+	result, err := handler(ctx, request)
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+
+	var text string
+	switch {
+	case err != nil:
+		report.IsError = true
+		text = err.Error()
+	case result == nil:
+		report.IsError = true
+		text = "the tool returned no result"
+	default:
+		report.IsError = result.IsError
+		text = resultText(result)
+	}
+	report.Result = taskResultJSON(text)
+
+	if report.IsError {
+		log.Warn().Str("task", task.Name).Str("tool", task.Tool).Str("error", text).Msg("scheduled task failed")
+	} else {
+		log.Debug().Str("task", task.Name).Str("tool", task.Tool).Int64("duration_ms", report.DurationMs).Msg("scheduled task completed")
+	}
+	s.taskReports.put(report)
+}
+
+// resultText joins the text contents of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// taskResultJSON returns the text of a tool result as a JSON document, quoting it when
+// it is not JSON.
+func taskResultJSON(text string) json.RawMessage {
+	if json.Valid([]byte(text)) {
+		return json.RawMessage(text)
+	}
+	quoted, _ := json.Marshal(text)
+	return quoted
+}
+
+// AddScheduledTaskResources registers the scheduled tasks and their latest reports as MCP
+// resources. It does nothing when no task is scheduled.
+func (s *PortainerMCPServer) AddScheduledTaskResources() {
+	if len(s.scheduledTasks) == 0 {
+		return
+	}
+
+	s.srv.AddResource(
+		mcp.NewResource(scheduledTasksURI, "Scheduled tasks",
+			mcp.WithResourceDescription("Tasks run periodically by this server, with their schedule, next run and latest run."),
+			mcp.WithMIMEType("application/json"),
+		),
+		s.HandleReadScheduledTasks(),
+	)
+	s.srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(scheduledTaskURITemplate, "Scheduled task report",
+			mcp.WithTemplateDescription("Latest result of a scheduled task, with its start time and duration."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.HandleReadScheduledTaskReport(),
+	)
+}
+
+// HandleReadScheduledTasks returns an MCP resource handler listing the scheduled tasks.
+func (s *PortainerMCPServer) HandleReadScheduledTasks() server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		summaries := make([]taskSummary, 0, len(s.scheduledTasks))
+		for _, scheduled := range s.scheduledTasks {
+			task := scheduled.task
+			summary := taskSummary{
+				Name:      task.Name,
+				Tool:      task.Tool,
+				Schedule:  task.Schedule,
+				Arguments: task.Arguments,
+				URI:       strings.Replace(scheduledTaskURITemplate, "{name}", task.Name, 1),
+			}
+			if next := task.Next(time.Now()); !next.IsZero() {
+				next = next.UTC()
+				summary.NextRun = &next
+			}
+			if report, ok := s.taskReports.get(task.Name); ok {
+				summary.LastRun = &report.StartedAt
+				summary.LastIsError = report.IsError
+			}
+			summaries = append(summaries, summary)
+		}
+
+		data, err := json.Marshal(summaries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal scheduled tasks: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "application/json", Text: string(data)},
+		}, nil
+	}
+}
+
+// HandleReadScheduledTaskReport returns an MCP resource handler returning the latest
+// report of a scheduled task.
+func (s *PortainerMCPServer) HandleReadScheduledTaskReport() server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		name := resourceArgument(request, "name")
+
+		known := false
+		for _, scheduled := range s.scheduledTasks {
+			known = known || scheduled.task.Name == name
+		}
+		if !known {
+			return nil, fmt.Errorf("scheduled task %s does not exist", name)
+		}
+
+		report, ok := s.taskReports.get(name)
+		if !ok {
+			return nil, fmt.Errorf("scheduled task %s has not run yet", name)
+		}
+
+		data, err := json.Marshal(report)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal scheduled task report: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "application/json", Text: string(data)},
+		}, nil
+	}
+}
@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/redact"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/scheduler"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScheduledTaskServer creates a server with the embedded tool definitions and the given
+// scheduled tasks.
+func newScheduledTaskServer(t *testing.T, cli *MockPortainerClient, engine *redact.Engine, tasks ...scheduler.Task) (*PortainerMCPServer, error) {
+	t.Helper()
+	defs, err := toolgen.LoadToolDefinitionsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+	tasks, err = scheduler.New(tasks)
+	require.NoError(t, err)
+
+	s := &PortainerMCPServer{cli: cli, tools: toolgen.ConvertToolDefinitions(defs)}
+	s.scheduledTasks, err = s.newScheduledTasks(tasks, toolgen.NewArgumentRules(defs), engine)
+	return s, err
+}
+
+// readTaskResource reads a scheduled task resource and returns its text.
+func readTaskResource(t *testing.T, s *PortainerMCPServer, uri, name string) (string, error) {
+	t.Helper()
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+
+	var contents []mcp.ResourceContents
+	var err error
+	if name == "" {
+		contents, err = s.HandleReadScheduledTasks()(context.Background(), request)
+	} else {
+		request.Params.Arguments = map[string]any{"name": []string{name}}
+		contents, err = s.HandleReadScheduledTaskReport()(context.Background(), request)
+	}
+	if err != nil {
+		return "", err
+	}
+	require.Len(t, contents, 1)
+	return contents[0].(mcp.TextResourceContents).Text, nil
+}
+
+// TestNewScheduledTasks verifies that only existing read-only tools can be scheduled.
+func TestNewScheduledTasks(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        scheduler.Task
+		expectError string
+	}{
+		{name: "read-only tool", task: scheduler.Task{Name: "drift", Tool: ToolDetectDrift, Schedule: "@hourly"}},
+		{name: "unknown tool", task: scheduler.Task{Name: "x", Tool: "fleetOverview", Schedule: "@hourly"}, expectError: "unknown tool fleetOverview"},
+		{name: "write tool", task: scheduler.Task{Name: "x", Tool: ToolDeleteStack, Schedule: "@hourly"}, expectError: "is not read-only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newScheduledTaskServer(t, &MockPortainerClient{}, nil, tt.task)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, s.scheduledTasks, 1)
+		})
+	}
+}
+
+// TestRunScheduledTask verifies that a run keeps the redacted tool result as the latest
+// report of the task, and that the reports are exposed as resources.
+func TestRunScheduledTask(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{Name: "prod"}).Return([]models.Environment{{ID: 1, Name: "prod", AgentVersion: "2.19.4"}}, nil).Once()
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{Name: "prod"}).Return(nil, fmt.Errorf("unauthorized")).Once()
+	engine, err := redact.New([]redact.Rule{{Paths: []string{"$..agent_version"}}})
+	require.NoError(t, err)
+
+	s, err := newScheduledTaskServer(t, mockClient, engine,
+		scheduler.Task{Name: "environments", Tool: ToolListEnvironments, Schedule: "*/5 * * * *", Arguments: map[string]any{"name": "prod"}},
+	)
+	require.NoError(t, err)
+	task := s.scheduledTasks[0].task
+
+	_, err = readTaskResource(t, s, "portainer://tasks/environments", "environments")
+	assert.ErrorContains(t, err, "has not run yet")
+
+	s.runScheduledTask(context.Background(), task)
+	text, err := readTaskResource(t, s, "portainer://tasks/environments", "environments")
+	require.NoError(t, err)
+	var report taskReport
+	require.NoError(t, json.Unmarshal([]byte(text), &report))
+	assert.Equal(t, "environments", report.Task)
+	assert.Equal(t, ToolListEnvironments, report.Tool)
+	assert.False(t, report.IsError)
+	assert.Contains(t, string(report.Result), `"name":"prod"`)
+	assert.Contains(t, string(report.Result), redact.DefaultReplacement)
+	assert.NotContains(t, string(report.Result), "2.19.4")
+
+	s.runScheduledTask(context.Background(), task)
+	text, err = readTaskResource(t, s, "portainer://tasks/environments", "environments")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(text), &report))
+	assert.True(t, report.IsError)
+	assert.Contains(t, string(report.Result), "unauthorized")
+
+	text, err = readTaskResource(t, s, scheduledTasksURI, "")
+	require.NoError(t, err)
+	var summaries []taskSummary
+	require.NoError(t, json.Unmarshal([]byte(text), &summaries))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "portainer://tasks/environments", summaries[0].URI)
+	assert.Equal(t, map[string]any{"name": "prod"}, summaries[0].Arguments)
+	assert.NotNil(t, summaries[0].NextRun)
+	assert.NotNil(t, summaries[0].LastRun)
+	assert.True(t, summaries[0].LastIsError)
+
+	_, err = readTaskResource(t, s, "portainer://tasks/unknown", "unknown")
+	assert.ErrorContains(t, err, "does not exist")
+	mockClient.AssertExpectations(t)
+}
+
+// TestNewPortainerMCPServerScheduledTasks verifies loading the scheduled tasks at startup.
+func TestNewPortainerMCPServerScheduledTasks(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "tasks.yaml")
+	require.NoError(t, os.WriteFile(valid, []byte("tasks:\n  - name: drift\n    tool: detectDrift\n    schedule: \"0 * * * *\"\n"), 0o600))
+	write := filepath.Join(dir, "write.yaml")
+	require.NoError(t, os.WriteFile(write, []byte("tasks:\n  - name: cleanup\n    tool: deleteStack\n    schedule: \"@daily\"\n"), 0o600))
+
+	server, err := NewPortainerMCPServer("https://portainer.example.com", "token", "../tooldef/tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithScheduledTasks(valid))
+	require.NoError(t, err)
+	require.Len(t, server.scheduledTasks, 1)
+	assert.NotPanics(t, server.AddScheduledTaskResources)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "../tooldef/tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithScheduledTasks(write))
+	assert.ErrorContains(t, err, "failed to load scheduled tasks")
+}
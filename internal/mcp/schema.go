@@ -6,15 +6,24 @@ import "slices"
 const (
 	ToolCreateEnvironmentGroup             = "createEnvironmentGroup"
 	ToolListEnvironmentGroups              = "listEnvironmentGroups"
+	ToolPreviewEnvironmentGroupMembers     = "previewEnvironmentGroupMembers"
 	ToolCreateAccessGroup                  = "createAccessGroup"
 	ToolListAccessGroups                   = "listAccessGroups"
 	ToolAddEnvironmentToAccessGroup        = "addEnvironmentToAccessGroup"
 	ToolRemoveEnvironmentFromAccessGroup   = "removeEnvironmentFromAccessGroup"
+	ToolSetAccessGroupEnvironments         = "setAccessGroupEnvironments"
 	ToolListEnvironments                   = "listEnvironments"
 	ToolGetEnvironment                     = "getEnvironment"
+	ToolWhoCanAccessEnvironment            = "whoCanAccessEnvironment"
 	ToolDeleteEnvironment                  = "deleteEnvironment"
+	ToolOnboardEnvironment                 = "onboardEnvironment"
 	ToolSnapshotEnvironment                = "snapshotEnvironment"
 	ToolSnapshotAllEnvironments            = "snapshotAllEnvironments"
+	ToolGetSnapshotSettings                = "getSnapshotSettings"
+	ToolUpdateSnapshotSettings             = "updateSnapshotSettings"
+	ToolGetGroupCapacity                   = "getGroupCapacity"
+	ToolGetTrends                          = "getTrends"
+	ToolBuildTimeline                      = "buildTimeline"
 	ToolGetStackFile                       = "getStackFile"
 	ToolCreateStack                        = "createStack"
 	ToolListStacks                         = "listStacks"
@@ -28,6 +37,15 @@ const (
 	ToolStartStack                         = "startStack"
 	ToolStopStack                          = "stopStack"
 	ToolMigrateStack                       = "migrateStack"
+	ToolMigrateRegularStackToEdge          = "migrateRegularStackToEdge"
+	ToolDeployStackAndWait                 = "deployStackAndWait"
+	ToolWaitFor                            = "waitFor"
+	ToolGetStackFileHistory                = "getStackFileHistory"
+	ToolDetectDrift                        = "detectDrift"
+	ToolAnalyzeStackImages                 = "analyzeStackImages"
+	ToolGetStackResources                  = "getStackResources"
+	ToolWaitForEdgeStackRollout            = "waitForEdgeStackRollout"
+	ToolRedeployStacksForImage             = "redeployStacksForImage"
 	ToolCreateEnvironmentTag               = "createEnvironmentTag"
 	ToolDeleteEnvironmentTag               = "deleteEnvironmentTag"
 	ToolListEnvironmentTags                = "listEnvironmentTags"
@@ -37,22 +55,34 @@ const (
 	ToolListTeams                          = "listTeams"
 	ToolUpdateTeamName                     = "updateTeamName"
 	ToolUpdateTeamMembers                  = "updateTeamMembers"
+	ToolAuditTeamAccess                    = "auditTeamAccess"
+	ToolImportTeams                        = "importTeams"
 	ToolListUsers                          = "listUsers"
 	ToolCreateUser                         = "createUser"
 	ToolGetUser                            = "getUser"
 	ToolDeleteUser                         = "deleteUser"
 	ToolUpdateUserRole                     = "updateUserRole"
+	ToolImportUsers                        = "importUsers"
+	ToolOffboardUser                       = "offboardUser"
 	ToolGetSettings                        = "getSettings"
 	ToolUpdateSettings                     = "updateSettings"
 	ToolGetPublicSettings                  = "getPublicSettings"
 	ToolGetSSLSettings                     = "getSSLSettings"
 	ToolUpdateSSLSettings                  = "updateSSLSettings"
+	ToolRevertSettings                     = "revertSettings"
+	ToolExportSettings                     = "exportSettings"
+	ToolImportSettings                     = "importSettings"
+	ToolCompareInstances                   = "compareInstances"
 	ToolListAppTemplates                   = "listAppTemplates"
 	ToolGetAppTemplateFile                 = "getAppTemplateFile"
 	ToolUpdateAccessGroupName              = "updateAccessGroupName"
 	ToolUpdateAccessGroupUserAccesses      = "updateAccessGroupUserAccesses"
 	ToolUpdateAccessGroupTeamAccesses      = "updateAccessGroupTeamAccesses"
 	ToolUpdateEnvironmentTags              = "updateEnvironmentTags"
+	ToolRetagEnvironments                  = "retagEnvironments"
+	ToolEnterMaintenanceMode               = "enterMaintenanceMode"
+	ToolExitMaintenanceMode                = "exitMaintenanceMode"
+	ToolListMaintenanceEnvironments        = "listMaintenanceEnvironments"
 	ToolUpdateEnvironmentUserAccesses      = "updateEnvironmentUserAccesses"
 	ToolUpdateEnvironmentTeamAccesses      = "updateEnvironmentTeamAccesses"
 	ToolUpdateEnvironmentGroupName         = "updateEnvironmentGroupName"
@@ -60,12 +90,34 @@ const (
 	ToolUpdateEnvironmentGroupTags         = "updateEnvironmentGroupTags"
 	ToolDockerProxy                        = "dockerProxy"
 	ToolGetDockerDashboard                 = "getDockerDashboard"
+	ToolListContainers                     = "listContainers"
+	ToolGetContainerLogs                   = "getContainerLogs"
+	ToolGetContainerTop                    = "getContainerTop"
+	ToolListDockerEvents                   = "listDockerEvents"
 	ToolKubernetesProxy                    = "kubernetesProxy"
 	ToolKubernetesProxyStripped            = "getKubernetesResourceStripped"
+	ToolDockerProxyGet                     = "dockerProxyGet"
+	ToolGetFleetContainerUsage             = "getFleetContainerUsage"
+	ToolSuggestCleanup                     = "suggestCleanup"
+	ToolAuditRestartPolicies               = "auditRestartPolicies"
+	ToolGetPortReport                      = "getPortReport"
+	ToolCheckImageUpdates                  = "checkImageUpdates"
+	ToolReadContainerFile                  = "readContainerFile"
+	ToolWriteContainerFile                 = "writeContainerFile"
 	ToolGetKubernetesDashboard             = "getKubernetesDashboard"
 	ToolListKubernetesNamespaces           = "listKubernetesNamespaces"
 	ToolGetKubernetesConfig                = "getKubernetesConfig"
+	ToolDescribeKubernetesResource         = "describeKubernetesResource"
+	ToolListKubernetesAPIResources         = "listKubernetesAPIResources"
+	ToolTopKubernetesNodes                 = "topKubernetesNodes"
+	ToolTopKubernetesPods                  = "topKubernetesPods"
+	ToolGetNamespaceUsage                  = "getNamespaceUsage"
+	ToolListCustomResources                = "listCustomResources"
 	ToolGetSystemStatus                    = "getSystemStatus"
+	ToolBenchmarkLatency                   = "benchmarkLatency"
+	ToolGetServerStats                     = "getServerStats"
+	ToolApplyPlan                          = "applyPlan"
+	ToolGetDeleteJournal                   = "getDeleteJournal"
 	ToolListCustomTemplates                = "listCustomTemplates"
 	ToolGetCustomTemplate                  = "getCustomTemplate"
 	ToolGetCustomTemplateFile              = "getCustomTemplateFile"
@@ -73,16 +125,25 @@ const (
 	ToolDeleteCustomTemplate               = "deleteCustomTemplate"
 	ToolListRegistries                     = "listRegistries"
 	ToolGetRegistry                        = "getRegistry"
+	ToolGetRegistryUsage                   = "getRegistryUsage"
 	ToolCreateRegistry                     = "createRegistry"
 	ToolUpdateRegistry                     = "updateRegistry"
 	ToolDeleteRegistry                     = "deleteRegistry"
+	ToolRotateRegistryCredentials          = "rotateRegistryCredentials"
 	ToolGetBackupStatus                    = "getBackupStatus"
 	ToolGetBackupS3Settings                = "getBackupS3Settings"
 	ToolCreateBackup                       = "createBackup"
 	ToolBackupToS3                         = "backupToS3"
 	ToolRestoreFromS3                      = "restoreFromS3"
+	ToolVerifyBackup                       = "verifyBackup"
 	ToolListRoles                          = "listRoles"
+	ToolGetRole                            = "getRole"
+	ToolCompareRoles                       = "compareRoles"
+	ToolAssignRole                         = "assignRole"
+	ToolUnassignRole                       = "unassignRole"
 	ToolGetMOTD                            = "getMOTD"
+	ToolGetLoginBanner                     = "getLoginBanner"
+	ToolUpdateLoginBanner                  = "updateLoginBanner"
 	ToolListWebhooks                       = "listWebhooks"
 	ToolCreateWebhook                      = "createWebhook"
 	ToolDeleteWebhook                      = "deleteWebhook"
@@ -92,6 +153,8 @@ const (
 	ToolCreateEdgeJob                      = "createEdgeJob"
 	ToolDeleteEdgeJob                      = "deleteEdgeJob"
 	ToolListEdgeUpdateSchedules            = "listEdgeUpdateSchedules"
+	ToolGetAgentVersionReport              = "getAgentVersionReport"
+	ToolScheduleAgentUpgrades              = "scheduleAgentUpgrades"
 	ToolAuthenticate                       = "authenticate"
 	ToolLogout                             = "logout"
 	ToolListHelmRepositories               = "listHelmRepositories"
@@ -102,6 +165,7 @@ const (
 	ToolListHelmReleases                   = "listHelmReleases"
 	ToolDeleteHelmRelease                  = "deleteHelmRelease"
 	ToolGetHelmReleaseHistory              = "getHelmReleaseHistory"
+	ToolGetHelmReleaseStatus               = "getHelmReleaseStatus"
 )
 
 // Access levels for users and teams
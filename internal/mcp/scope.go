@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/rs/zerolog/log"
+)
+
+// environmentScopeRefresh is the minimum delay between two reloads of the accessible
+// environments, so that unknown IDs cannot be used to flood Portainer with requests.
+const environmentScopeRefresh = 30 * time.Second
+
+// environmentParams are the tool parameters that hold environment IDs, as a number or a
+// list of numbers.
+var environmentParams = []string{"environmentId", "endpointId", "targetEnvironmentId", "environmentIds", "endpoints"}
+
+// environmentIDTools are the tools whose "id" parameter is an environment ID.
+var environmentIDTools = map[string]bool{
+	ToolGetEnvironment:                true,
+	ToolDeleteEnvironment:             true,
+	ToolSnapshotEnvironment:           true,
+	ToolUpdateEnvironmentTags:         true,
+	ToolUpdateEnvironmentUserAccesses: true,
+	ToolUpdateEnvironmentTeamAccesses: true,
+}
+
+// environmentScope keeps the IDs of the environments that the API token user can access.
+// Portainer only lists the environments a standard user has access to, so the list is
+// resolved through the environment listing.
+type environmentScope struct {
+	mu       sync.Mutex
+	cli      PortainerClient
+	ids      map[int]bool
+	loadedAt time.Time
+	// now returns the current time; it is replaced in tests.
+	now func() time.Time
+}
+
+// newEnvironmentScope loads the environments accessible to the API token user.
+func newEnvironmentScope(cli PortainerClient) (*environmentScope, error) {
+	scope := &environmentScope{cli: cli, now: time.Now}
+	if err := scope.load(); err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+// load replaces the accessible environments with the current environment listing.
+// The caller must hold the lock, except during construction.
+func (sc *environmentScope) load() error {
+	environments, err := sc.cli.GetEnvironments(models.EnvironmentListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list accessible environments: %w", err)
+	}
+
+	sc.ids = make(map[int]bool, len(environments))
+	for _, environment := range environments {
+		sc.ids[environment.ID] = true
+	}
+	sc.loadedAt = sc.now()
+	return nil
+}
+
+// denied returns the given environment IDs that the token user cannot access. When an ID
+// is unknown and the list is older than environmentScopeRefresh, the list is reloaded
+// first so that environments granted after startup become usable.
+func (sc *environmentScope) denied(ids []int) ([]int, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	unknown := sc.unknown(ids)
+	if len(unknown) > 0 && sc.now().Sub(sc.loadedAt) >= environmentScopeRefresh {
+		if err := sc.load(); err != nil {
+			return nil, err
+		}
+		unknown = sc.unknown(ids)
+	}
+	return unknown, nil
+}
+
+// unknown returns the IDs that are not in the accessible environments.
+func (sc *environmentScope) unknown(ids []int) []int {
+	var unknown []int
+	for _, id := range ids {
+		if !sc.ids[id] && !slices.Contains(unknown, id) {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
+
+// environmentScopeMiddleware rejects tool calls that target environments the API token
+// user cannot access, before they reach Portainer.
+func (s *PortainerMCPServer) environmentScopeMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ids := requestEnvironmentIDs(request)
+		if len(ids) == 0 {
+			return next(ctx, request)
+		}
+
+		denied, err := s.environmentScope.denied(ids)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to check environment access", err), nil
+		}
+		if len(denied) > 0 {
+			log.Warn().Str("tool", request.Params.Name).Ints("environments", denied).Msg("tool call outside the accessible environments rejected")
+			return newToolResultErrorWithCode(ErrorCodeForbidden, fmt.Sprintf("environment(s) %v are not accessible to the API token user; use listEnvironments to see the accessible environments", denied)), nil
+		}
+
+		return next(ctx, request)
+	}
+}
+
+// requestEnvironmentIDs returns the environment IDs referenced by the arguments of a tool
+// call. Meta-tool calls are resolved through their action. Values that are not valid IDs
+// are ignored and left to the validation of the tool handler.
+func requestEnvironmentIDs(request mcp.CallToolRequest) []int {
+	args := request.GetArguments()
+
+	name := request.Params.Name
+	if action, ok := args["action"].(string); ok {
+		if a, found := findMetaAction(name, action); found {
+			name = a.tool
+		}
+	}
+
+	var ids []int
+	for _, param := range environmentParams {
+		ids = appendEnvironmentIDs(ids, args[param])
+	}
+	if environmentIDTools[name] {
+		ids = appendEnvironmentIDs(ids, args["id"])
+	}
+	return ids
+}
+
+// appendEnvironmentIDs appends the environment IDs held by an argument value.
+func appendEnvironmentIDs(ids []int, value any) []int {
+	switch v := value.(type) {
+	case float64:
+		if v > 0 && v == float64(int(v)) {
+			ids = append(ids, int(v))
+		}
+	case int:
+		if v > 0 {
+			ids = append(ids, v)
+		}
+	case string:
+		if id, err := strconv.Atoi(v); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	case []any:
+		for _, item := range v {
+			ids = appendEnvironmentIDs(ids, item)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,196 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestEnvironmentIDs verifies the extraction of environment IDs from tool arguments.
+func TestRequestEnvironmentIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]any
+		expected []int
+	}{
+		{
+			name:     "environmentId",
+			tool:     ToolListContainers,
+			args:     map[string]any{"environmentId": float64(3)},
+			expected: []int{3},
+		},
+		{
+			name:     "migration source and target",
+			tool:     ToolMigrateStack,
+			args:     map[string]any{"id": float64(9), "environmentId": float64(1), "targetEnvironmentId": float64(2)},
+			expected: []int{1, 2},
+		},
+		{
+			name:     "environment list",
+			tool:     ToolGetFleetContainerUsage,
+			args:     map[string]any{"environmentIds": []any{float64(4), "5", "invalid"}},
+			expected: []int{4, 5},
+		},
+		{
+			name:     "environment tool id",
+			tool:     ToolGetEnvironment,
+			args:     map[string]any{"id": float64(7)},
+			expected: []int{7},
+		},
+		{
+			name:     "meta-tool action id",
+			tool:     "manage_environments",
+			args:     map[string]any{"action": "get_environment", "id": float64(8)},
+			expected: []int{8},
+		},
+		{
+			name: "id of another resource",
+			tool: ToolGetStack,
+			args: map[string]any{"id": float64(7)},
+		},
+		{
+			name: "unset optional environment",
+			tool: ToolListContainers,
+			args: map[string]any{"environmentId": float64(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+			assert.Equal(t, tt.expected, requestEnvironmentIDs(request))
+		})
+	}
+}
+
+// TestEnvironmentScopeDenied verifies the access checks and the rate-limited reload of
+// the accessible environments.
+func TestEnvironmentScopeDenied(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{{ID: 1}, {ID: 2}}, nil).Once()
+
+	scope, err := newEnvironmentScope(mockClient)
+	require.NoError(t, err)
+	current := scope.loadedAt
+	scope.now = func() time.Time { return current }
+
+	denied, err := scope.denied([]int{1, 3, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, denied, "unknown IDs are not reloaded before the refresh delay")
+
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{{ID: 1}, {ID: 2}, {ID: 3}}, nil).Once()
+	current = current.Add(environmentScopeRefresh)
+	denied, err = scope.denied([]int{1, 3})
+	require.NoError(t, err)
+	assert.Empty(t, denied, "environments granted after startup become usable")
+
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return(nil, errors.New("unavailable")).Once()
+	current = current.Add(environmentScopeRefresh)
+	_, err = scope.denied([]int{4})
+	assert.Error(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+// TestEnvironmentScopeMiddleware verifies that calls outside the accessible environments
+// are rejected before reaching the handler.
+func TestEnvironmentScopeMiddleware(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{{ID: 1}}, nil).Once()
+	scope, err := newEnvironmentScope(mockClient)
+	require.NoError(t, err)
+	s := &PortainerMCPServer{cli: mockClient, environmentScope: scope}
+
+	called := 0
+	handler := s.environmentScopeMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called++
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		expectError bool
+	}{
+		{name: "accessible environment", args: map[string]any{"environmentId": float64(1)}},
+		{name: "no environment", args: map[string]any{"id": float64(5)}},
+		{name: "inaccessible environment", args: map[string]any{"environmentId": float64(2)}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := called
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = ToolListContainers
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectError, result.IsError)
+			if tt.expectError {
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "environment(s) [2] are not accessible")
+				assert.Equal(t, ErrorCodeForbidden, errorCode(result))
+				assert.Equal(t, before, called)
+			} else {
+				assert.Equal(t, before+1, called)
+			}
+		})
+	}
+}
+
+// TestEnvironmentScopeWhoCanAccessEnvironment verifies that the users of an environment
+// outside the accessible environments cannot be listed.
+func TestEnvironmentScopeWhoCanAccessEnvironment(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{{ID: 1}}, nil)
+	scope, err := newEnvironmentScope(mockClient)
+	require.NoError(t, err)
+	s := &PortainerMCPServer{cli: mockClient, environmentScope: scope}
+	handler := s.environmentScopeMiddleware(s.HandleWhoCanAccessEnvironment())
+
+	request := CreateMCPRequest(map[string]any{"environmentId": float64(2)})
+	request.Params.Name = ToolWhoCanAccessEnvironment
+	result, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "environment(s) [2] are not accessible")
+	mockClient.AssertNotCalled(t, "GetEnvironment", 2)
+
+	request = CreateMCPRequest(map[string]any{"action": "who_can_access_environment", "environmentId": float64(2)})
+	request.Params.Name = "manage_environments"
+	result, err = handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+// TestNewPortainerMCPServerWithEnvironmentScope verifies that only standard user tokens
+// are scoped to their environments.
+func TestNewPortainerMCPServerWithEnvironmentScope(t *testing.T) {
+	user := &MockPortainerClient{}
+	user.On("GetCurrentUser").Return(models.User{ID: 4, Role: models.UserRoleUser}, nil)
+	user.On("GetUserMemberships", 4).Return([]models.TeamMembership{}, nil)
+	user.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{{ID: 2}}, nil)
+
+	s, err := NewPortainerMCPServer("https://example.com", "tok", "testdata/valid_tools.yaml",
+		WithClient(user), WithDisableVersionCheck(true), WithEnvironmentScope(true))
+	require.NoError(t, err)
+	require.NotNil(t, s.environmentScope)
+	assert.Equal(t, map[int]bool{2: true}, s.environmentScope.ids)
+	assert.Nil(t, s.access, "tools are only filtered with RBAC filtering")
+
+	admin := &MockPortainerClient{}
+	admin.On("GetCurrentUser").Return(models.User{ID: 1, Role: models.UserRoleAdmin}, nil)
+
+	s, err = NewPortainerMCPServer("https://example.com", "tok", "testdata/valid_tools.yaml",
+		WithClient(admin), WithDisableVersionCheck(true), WithEnvironmentScope(true))
+	require.NoError(t, err)
+	assert.Nil(t, s.environmentScope)
+	admin.AssertNotCalled(t, "GetEnvironments", mock.Anything)
+}
@@ -2,15 +2,32 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/audit"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/format"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/k8sutil"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/maintenance"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/notify"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/policy"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/proxyroutes"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/redact"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/scheduler"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/sessionstate"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/stackhistory"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/trends"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/client"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
@@ -21,8 +38,11 @@ const (
 	// MinimumToolsVersion is the minimum supported version of the tools.yaml file.
 	// This uses the same "v{major}.{minor}" format as tools.yaml version strings.
 	MinimumToolsVersion = "v1.0"
-	// SupportedPortainerVersion is the version of Portainer that is supported by this tool
+	// SupportedPortainerVersion is the newest version of Portainer supported by this tool, the
+	// version of the API client. Later patch releases of its minor version are supported too.
 	SupportedPortainerVersion = "2.31.2"
+	// MinimumPortainerVersion is the oldest version of Portainer supported by this tool.
+	MinimumPortainerVersion = "2.27.0"
 	// maxProxyResponseSize is the maximum allowed response body size (10MB) for Docker/K8s proxy calls
 	maxProxyResponseSize = 10 * 1024 * 1024
 )
@@ -50,11 +70,15 @@ type PortainerClient interface {
 	DeleteEnvironmentTag(id int) error
 
 	// Environment methods
-	GetEnvironments() ([]models.Environment, error)
+	GetEnvironments(opts models.EnvironmentListOptions) ([]models.Environment, error)
 	GetEnvironment(id int) (models.Environment, error)
+	CreateEnvironment(opts models.EnvironmentCreateOptions) (models.Environment, string, error)
 	DeleteEnvironment(id int) error
 	SnapshotEnvironment(id int) error
 	SnapshotAllEnvironments() error
+	GetEnvironmentSnapshotSettings(id int) (models.EnvironmentSnapshotSettings, error)
+	GetEnvironmentSnapshots(opts models.EnvironmentListOptions) ([]models.EnvironmentSnapshot, error)
+	UpdateEnvironmentSnapshotSettings(id int, edgeCheckinInterval, edgeSnapshotInterval *int) error
 	UpdateEnvironmentTags(id int, tagIds []int) error
 	UpdateEnvironmentUserAccesses(id int, userAccesses map[int]string) error
 	UpdateEnvironmentTeamAccesses(id int, teamAccesses map[int]string) error
@@ -76,9 +100,11 @@ type PortainerClient interface {
 	RemoveEnvironmentFromAccessGroup(id int, environmentId int) error
 
 	// Stack methods
-	GetStacks() ([]models.Stack, error)
+	GetStacks(opts models.EdgeStackListOptions) ([]models.Stack, error)
+	GetEdgeStackStatus(id int) (models.EdgeStackStatus, error)
 	GetStackFile(id int) (string, error)
 	CreateStack(name string, file string, environmentGroupIds []int) (int, error)
+	CreateStackWithEnv(name string, file string, environmentGroupIds []int, env map[string]string) (int, error)
 	UpdateStack(id int, file string, environmentGroupIds []int) error
 
 	// Regular stack methods
@@ -91,6 +117,11 @@ type PortainerClient interface {
 	StartStack(id int, endpointID int) (models.RegularStack, error)
 	StopStack(id int, endpointID int) (models.RegularStack, error)
 	MigrateStack(id int, endpointID int, targetEndpointID int, name string) (models.RegularStack, error)
+	CreateRegularStack(endpointID int, name string, file string, env map[string]string) (models.RegularStack, error)
+	UpdateRegularStack(id int, endpointID int, file string, env map[string]string, pullImage bool, prune bool) (models.RegularStack, error)
+	RedeployRegularStack(id int, endpointID int, pullImage bool) (models.RegularStack, error)
+	GetRegularStackEnv(id int) (map[string]string, error)
+	GetGitRepositoryFile(git models.StackGitConfig) (string, error)
 
 	// Team methods
 	CreateTeam(name string) (int, error)
@@ -99,17 +130,29 @@ type PortainerClient interface {
 	DeleteTeam(id int) error
 	UpdateTeamName(id int, name string) error
 	UpdateTeamMembers(id int, userIds []int) error
+	RemoveTeamMember(teamID, userID int) error
 
 	// User methods
 	CreateUser(username, password, role string) (int, error)
 	GetUser(id int) (models.User, error)
-	GetUsers() ([]models.User, error)
+	GetUsers(opts models.UserListOptions) ([]models.User, error)
+	HasAPITokens(id int) (bool, error)
 	DeleteUser(id int) error
 	UpdateUserRole(id int, role string) error
+	GetCurrentUser() (models.User, error)
+	GetUserMemberships(id int) ([]models.TeamMembership, error)
+	GetUserAPITokens(id int) ([]models.APIToken, error)
+	RevokeUserAPIToken(userID, tokenID int) error
+
+	// Resource control methods
+	GetResourceControls() ([]models.ResourceControl, error)
+	UpdateResourceControl(control models.ResourceControl) error
 
 	// Settings methods
 	GetSettings() (models.PortainerSettings, error)
 	UpdateSettings(settingsJSON map[string]interface{}) error
+	GetSettingsValues(keys []string) (map[string]any, error)
+	GetSnapshotSettings() (models.SnapshotSettings, error)
 	GetPublicSettings() (models.PublicSettings, error)
 
 	// SSL methods
@@ -126,6 +169,20 @@ type PortainerClient interface {
 	// Docker Proxy methods
 	ProxyDockerRequest(opts models.DockerProxyRequestOptions) (*http.Response, error)
 	GetDockerDashboard(environmentId int) (models.DockerDashboard, error)
+	GetDockerContainers(environmentId int, opts models.DockerContainerListOptions) ([]models.DockerContainer, error)
+	GetDockerContainerLogs(environmentId int, containerId string, opts models.DockerContainerLogOptions) (string, error)
+	GetDockerEvents(environmentId int, opts models.DockerEventListOptions) ([]models.DockerEvent, error)
+	GetDockerContainerStats(environmentId int, containerId string) (models.DockerContainerUsage, error)
+	GetDockerContainerTop(environmentId int, containerId, psArgs string) (models.DockerContainerTop, error)
+	GetDockerContainerRestartState(environmentId int, containerId string) (models.DockerContainerRestartState, error)
+	GetDockerContainerPortBindings(environmentId int, containerId string) ([]models.DockerContainerPort, error)
+	GetDockerContainerImage(environmentId int, containerId string) (models.DockerContainerImage, error)
+	GetDockerImageDigests(environmentId int, imageId string) ([]string, error)
+	GetDockerDistributionDigest(environmentId int, imageRef string) (string, error)
+	GetDockerServices(environmentId int) ([]models.DockerService, error)
+	GetDockerDiskUsage(environmentId int) (models.DockerDiskUsage, error)
+	GetDockerContainerFile(environmentId int, containerId, filePath string) (models.DockerContainerFile, error)
+	PutDockerContainerFile(environmentId int, containerId, filePath string, content []byte, mode int64) error
 
 	// Kubernetes Proxy methods
 	ProxyKubernetesRequest(opts models.KubernetesProxyRequestOptions) (*http.Response, error)
@@ -138,9 +195,11 @@ type PortainerClient interface {
 	GetWebhooks() ([]models.Webhook, error)
 	CreateWebhook(resourceId string, endpointId int, webhookType int) (int, error)
 	DeleteWebhook(id int) error
+	TriggerWebhook(token string) error
 
 	// System methods
 	GetSystemStatus() (models.SystemStatus, error)
+	ProbeAPI(path string) (int64, error)
 
 	// Custom Template methods
 	GetCustomTemplates() ([]models.CustomTemplate, error)
@@ -155,6 +214,7 @@ type PortainerClient interface {
 	CreateRegistry(name string, registryType int, url string, authentication bool, username string, password string, baseURL string) (int, error)
 	UpdateRegistry(id int, name *string, url *string, authentication *bool, username *string, password *string, baseURL *string) error
 	DeleteRegistry(id int) error
+	RemoveRegistryTeamAccess(id int, environmentId int, teamId int) error
 
 	// Backup methods
 	GetBackupStatus() (models.BackupStatus, error)
@@ -165,6 +225,8 @@ type PortainerClient interface {
 
 	// Role methods
 	GetRoles() ([]models.Role, error)
+	AssignEnvironmentRole(id int, assignee string, assigneeID int, roleID int) (int, error)
+	AssignAccessGroupRole(id int, assignee string, assigneeID int, roleID int) (int, error)
 
 	// MOTD methods
 	GetMOTD() (models.MOTD, error)
@@ -178,6 +240,7 @@ type PortainerClient interface {
 
 	// Edge Update Schedule methods
 	GetEdgeUpdateSchedules() ([]models.EdgeUpdateSchedule, error)
+	CreateEdgeUpdateSchedule(name string, edgeGroupIds []int, scheduledTime string) (int, error)
 
 	// Auth methods
 	AuthenticateUser(username, password string) (models.AuthResponse, error)
@@ -192,6 +255,7 @@ type PortainerClient interface {
 	GetHelmReleases(environmentId int, namespace, filter, selector string) ([]models.HelmRelease, error)
 	DeleteHelmRelease(environmentId int, release, namespace string) error
 	GetHelmReleaseHistory(environmentId int, name, namespace string) ([]models.HelmReleaseDetails, error)
+	GetHelmRelease(environmentId int, name, namespace string) (models.HelmReleaseInfo, error)
 }
 
 // PortainerMCPServer is the main MCP server that bridges AI assistants and the
@@ -204,6 +268,119 @@ type PortainerMCPServer struct {
 	cli      PortainerClient
 	tools    map[string]mcp.Tool
 	readOnly bool
+	// pollInterval overrides the delay between status checks of waiting tools (defaultPollInterval when zero).
+	pollInterval time.Duration
+	// stackHistory keeps the stack file versions written through the server (nil when disabled).
+	stackHistory *stackhistory.Store
+	// deleteJournal records the resources deleted through the server (nil when disabled).
+	deleteJournal *journal.Journal
+	// maintenance keeps the environments in maintenance mode and the stacks stopped for them.
+	maintenance *maintenance.Store
+	// budget limits the write and destructive operations per session (nil when unlimited).
+	budget *toolBudget
+	// k8sStripper removes verbose fields from stripped Kubernetes proxy responses.
+	k8sStripper *k8sutil.Stripper
+	// skipProxyValidation disables the checks of proxy paths against the known API routes.
+	skipProxyValidation bool
+	// proxyPolicy restricts the requests of the proxy tools (nil when unrestricted).
+	proxyPolicy *proxyroutes.Policy
+	// notifier announces successful destructive tool calls (nil when disabled).
+	notifier *notify.Notifier
+	// notifications tracks the notifications being delivered.
+	notifications sync.WaitGroup
+	// plans keeps the execution plans previewed by compound tools until they are applied.
+	plans planStore
+	// settingsSnapshots keeps the settings replaced by updates until they are reverted.
+	settingsSnapshots snapshotStore
+	// access describes what the API token may do, used to hide the tools it cannot call
+	// (nil when RBAC filtering is disabled).
+	access *tokenAccess
+	// environmentScope restricts tool calls to the environments the API token user can
+	// access (nil when environment scoping is disabled or the token is an administrator).
+	environmentScope *environmentScope
+	// instances are the additional Portainer servers, by name, that multi-instance tools
+	// such as compareInstances can reach (nil when no instances file is given).
+	instances map[string]PortainerClient
+	// portainerVersion is the version of the Portainer server reported in the metadata
+	// of tool results (empty when the version check is off).
+	portainerVersion string
+	// versionCheck is the mode of the Portainer server version check run at startup.
+	versionCheck string
+	// environmentNames caches the environment names reported in the metadata of tool results.
+	environmentNames environmentNames
+	// results keeps the oversized tool results delivered in chunks (nil when chunked
+	// delivery is disabled).
+	results *resultStore
+	// client sends the server requests to the client over stdio (nil until Start).
+	client *clientConn
+	// roots narrows the session to the environments named by the client roots.
+	roots rootsScope
+	// keepAliveInterval is the delay of client silence after which the client is pinged
+	// (0 when keep-alive is disabled).
+	keepAliveInterval time.Duration
+	// stallTimeout is the delay of client silence after which the server stops (0 when
+	// stall detection is disabled).
+	stallTimeout time.Duration
+	// stats records the calls, errors and latency of every tool.
+	stats *toolStats
+	// metricsAddr is the address of the Prometheus metrics endpoint (empty when disabled).
+	metricsAddr string
+	// scheduledTasks are the read-only tools run periodically by the internal scheduler.
+	scheduledTasks []scheduledTask
+	// taskReports keeps the latest result of every scheduled task.
+	taskReports taskReportStore
+	// trends keeps the periodic samples of the fleet counts (nil when disabled).
+	trends *trends.Store
+	// trendInterval is the delay between two samples of the trend history.
+	trendInterval time.Duration
+	// redaction masks sensitive values in the tool results (nil when no rules are loaded).
+	redaction *redact.Engine
+	// files keeps the stack, template and edge job files and kubeconfigs listed as
+	// resources.
+	files fileResources
+	// outputFormat is the format of the tool results when the call does not choose one.
+	outputFormat string
+	// idempotency remembers the results of the create calls made with an idempotency key.
+	idempotency *idempotencyStore
+	// maxRetries is the number of automatic retries of a retry-safe call failing upstream
+	// (0 disables them).
+	maxRetries int
+	// transport is the transport of the MCP protocol, stdio or sse.
+	transport string
+	// listenAddr is the address of the HTTP transports.
+	listenAddr string
+	// httpAuthToken is the bearer token required by the HTTP transports (none when empty).
+	httpAuthToken string
+	// sessionState persists the state of the client sessions (nil when it is not persisted).
+	sessionState *sessionstate.Store
+	// policy decides whether write tool calls may run (nil when no policy is configured).
+	policy *policy.Engine
+	// auditLog records the policy decisions (nil when they go to the server log).
+	auditLog *audit.Log
+	// clientLogs sends log notifications to the clients (nil when disabled).
+	clientLogs *clientLogs
+	// principals are the HTTP clients that act as their own Portainer user, each served
+	// by its own server (nil when no principals file is given).
+	principals []principal
+
+	// toolsMu guards the tool definitions, the token access and the registered tools,
+	// which ReloadTools replaces while calls are served.
+	toolsMu sync.RWMutex
+	// registered are the tools registered on the MCP server, by name.
+	registered map[string]server.ServerTool
+	// pending collects the tools registered during a reload, before they replace
+	// registered (nil outside of a reload).
+	pending map[string]server.ServerTool
+	// reloadMu serializes the reloads of the tools.
+	reloadMu sync.Mutex
+	// toolsPath is the tool definitions file, read again by ReloadTools.
+	toolsPath string
+	// granularTools registers the granular tools instead of the meta-tools.
+	granularTools bool
+	// rbacFilter hides the tools the API token cannot call; ReloadTools reloads its access.
+	rbacFilter bool
+	// argumentRules are the parameter rules of the tool definitions.
+	argumentRules *toolgen.ArgumentRules
 }
 
 // ServerOption is a functional option for configuring a [PortainerMCPServer].
@@ -216,7 +393,43 @@ type serverOptions struct {
 	readOnly            bool
 	granularTools       bool
 	disableVersionCheck bool
+	versionCheck        string
 	skipTLSVerify       bool
+	stackHistoryDir     string
+	stackHistorySize    int
+	deleteJournalDir    string
+	deleteJournalSize   int
+	maintenanceDir      string
+	redactionRulesPath  string
+	maxWriteOps         int
+	maxDestructiveOps   int
+	toolTimeouts        string
+	k8sStripFields      []string
+	skipProxyValidation bool
+	proxyRulesPath      string
+	notifyWebhookURL    string
+	notifySecret        string
+	rbacFilter          bool
+	scopeEnvironments   bool
+	instancesPath       string
+	maxResultSize       int
+	keepAliveInterval   time.Duration
+	stallTimeout        time.Duration
+	sessionStateDir     string
+	metricsAddr         string
+	scheduledTasksPath  string
+	trendInterval       time.Duration
+	trendHistorySize    int
+	trendHistoryDir     string
+	outputFormat        string
+	policyPath          string
+	auditLogPath        string
+	transport           string
+	listenAddr          string
+	httpAuthToken       string
+	maxRetries          int
+	clientLogLevel      string
+	principalsPath      string
 }
 
 // WithClient sets a custom client for the server.
@@ -235,7 +448,7 @@ func WithReadOnly(readOnly bool) ServerOption {
 	}
 }
 
-// WithGranularTools enables granular tool mode, registering all ~98 individual
+// WithGranularTools enables granular tool mode, registering all ~162 individual
 // tools instead of the default ~15 grouped meta-tools.
 func WithGranularTools(granular bool) ServerOption {
 	return func(opts *serverOptions) {
@@ -245,12 +458,24 @@ func WithGranularTools(granular bool) ServerOption {
 
 // WithDisableVersionCheck disables the Portainer server version check.
 // This allows connecting to unsupported Portainer versions.
+//
+// Deprecated: use WithVersionCheck(VersionCheckOff).
 func WithDisableVersionCheck(disable bool) ServerOption {
 	return func(opts *serverOptions) {
 		opts.disableVersionCheck = disable
 	}
 }
 
+// WithVersionCheck sets the mode of the Portainer server version check run at startup:
+// VersionCheckStrict (the default) refuses unsupported versions, VersionCheckWarn logs a
+// warning and VersionCheckOff skips the check. WithDisableVersionCheck(true) takes
+// precedence.
+func WithVersionCheck(mode string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.versionCheck = mode
+	}
+}
+
 // WithSkipTLSVerify skips TLS certificate verification when connecting to Portainer.
 // This should only be used for development/testing with self-signed certificates.
 func WithSkipTLSVerify(skip bool) ServerOption {
@@ -259,6 +484,268 @@ func WithSkipTLSVerify(skip bool) ServerOption {
 	}
 }
 
+// WithStackHistory keeps up to size versions of each stack file created or updated
+// through the server. When dir is not empty, the history is persisted there and
+// survives restarts; otherwise it is kept in memory. A size of 0 disables the history.
+func WithStackHistory(dir string, size int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.stackHistoryDir = dir
+		opts.stackHistorySize = size
+	}
+}
+
+// WithDeleteJournal records up to size resources deleted through the server, with the
+// tool call that recreates each of them. When dir is not empty, the journal is persisted
+// there and survives restarts; otherwise it is kept in memory. A size of 0 disables it.
+func WithDeleteJournal(dir string, size int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.deleteJournalDir = dir
+		opts.deleteJournalSize = size
+	}
+}
+
+// WithMaintenanceState persists the environments put in maintenance mode, and the stacks
+// stopped for each of them, in a directory, so that they can be resumed after a restart.
+// An empty directory keeps them in memory only.
+func WithMaintenanceState(dir string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maintenanceDir = dir
+	}
+}
+
+// WithTrendHistory samples the container, stack, image and volume counts of every
+// environment every interval and keeps up to size samples for the getTrends tool. When
+// dir is not empty, the samples are persisted there and survive restarts; otherwise they
+// are kept in memory. An interval or size of 0 disables the history.
+func WithTrendHistory(dir string, interval time.Duration, size int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.trendHistoryDir = dir
+		opts.trendInterval = interval
+		opts.trendHistorySize = size
+	}
+}
+
+// WithRedactionRules loads redaction rules from a YAML or JSON file. The rules mask
+// sensitive values in tool results before they are returned to the client.
+// An empty path disables redaction.
+func WithRedactionRules(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.redactionRulesPath = path
+	}
+}
+
+// WithToolBudget limits the number of write operations and destructive operations a
+// single client session may perform. Once a limit is reached, further calls of that
+// kind are rejected until the operator resets the budget. Zero means unlimited.
+func WithToolBudget(maxWriteOps, maxDestructiveOps int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxWriteOps = maxWriteOps
+		opts.maxDestructiveOps = maxDestructiveOps
+	}
+}
+
+// WithToolTimeouts sets execution timeouts per tool as a comma-separated list of
+// pattern=duration entries (e.g. "installHelmChart=5m,list*=15s"). Patterns are globs
+// matched against granular tool names and meta-tool actions ("manage_helm.install_helm_chart");
+// the first matching entry applies. Tools without a matching entry have no timeout.
+func WithToolTimeouts(spec string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.toolTimeouts = spec
+	}
+}
+
+// WithKubernetesStripFields sets the fields removed from Kubernetes resources by the
+// stripped Kubernetes proxy tool, as dotted paths such as "metadata.managedFields" or
+// "status". When not set, k8sutil.DefaultStripFields is used.
+func WithKubernetesStripFields(fields []string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.k8sStripFields = fields
+	}
+}
+
+// WithSkipProxyValidation disables the validation of Docker and Kubernetes proxy paths
+// and methods against the known API routes. Use it when the target environments expose
+// endpoints that are missing from the embedded route tables.
+func WithSkipProxyValidation(skip bool) ServerOption {
+	return func(opts *serverOptions) {
+		opts.skipProxyValidation = skip
+	}
+}
+
+// WithProxyRules loads allow and deny rules for the Docker and Kubernetes proxy tools
+// from a YAML or JSON file. Requests that the rules do not permit are rejected before
+// they reach Portainer. An empty path leaves the proxies unrestricted.
+func WithProxyRules(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.proxyRulesPath = path
+	}
+}
+
+// WithNotifications posts a message to a webhook whenever a destructive tool call
+// succeeds. The payload has a "text" field compatible with Slack incoming webhooks and
+// the structured details of the call. An empty URL disables notifications.
+func WithNotifications(webhookURL string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.notifyWebhookURL = webhookURL
+	}
+}
+
+// WithNotificationSecret signs the notification payloads with HMAC-SHA256 using the
+// given secret, so that the webhook receiver can verify that they come from the server.
+// An empty secret sends unsigned payloads.
+func WithNotificationSecret(secret string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.notifySecret = secret
+	}
+}
+
+// WithRBACFilter queries the role and team memberships of the API token user at startup
+// and hides the tools and meta-tool actions that the token is not allowed to call, instead
+// of letting them fail with 403 errors.
+func WithRBACFilter(enabled bool) ServerOption {
+	return func(opts *serverOptions) {
+		opts.rbacFilter = enabled
+	}
+}
+
+// WithEnvironmentScope restricts the tool calls of a standard user token to the
+// environments that the user can access in Portainer. Calls that reference any other
+// environment are rejected before they reach Portainer. Administrator tokens are not
+// restricted.
+func WithEnvironmentScope(enabled bool) ServerOption {
+	return func(opts *serverOptions) {
+		opts.scopeEnvironments = enabled
+	}
+}
+
+// WithInstances loads additional Portainer servers from a YAML or JSON file, so that
+// multi-instance tools such as compareInstances can reach them by name. The server given
+// to NewPortainerMCPServer is named "primary". An empty path configures no instance.
+func WithInstances(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.instancesPath = path
+	}
+}
+
+// WithMaxResultSize sets the size, in bytes, above which tool results are delivered in
+// chunks: the result holds the first chunk and the full payload is kept as an MCP
+// resource. A size of 0 disables chunked delivery.
+func WithMaxResultSize(size int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxResultSize = size
+	}
+}
+
+// WithKeepAlive sets the delay of client silence after which the server pings the client
+// over stdio, and the delay after which a silent client is considered gone and the server
+// stops, so that no orphaned process keeps its Portainer session. An interval of 0
+// disables both, and a timeout of 0 disables only the stall detection.
+func WithKeepAlive(interval, timeout time.Duration) ServerOption {
+	return func(opts *serverOptions) {
+		opts.keepAliveInterval = interval
+		opts.stallTimeout = timeout
+	}
+}
+
+// WithSessionState persists the state of the client sessions, the tool budget usage and
+// the plans waiting for applyPlan, in a directory, so that a client reconnecting with
+// the same session ID, or talking to a restarted server, resumes where it left off. An
+// empty directory keeps the state in memory only.
+func WithSessionState(dir string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.sessionStateDir = dir
+	}
+}
+
+// WithMetricsAddr serves the per-tool call, error and latency metrics in the Prometheus
+// text format at /metrics on the given address, such as "127.0.0.1:9464". An empty
+// address disables the endpoint; the getServerStats tool reports the same metrics.
+func WithMetricsAddr(addr string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.metricsAddr = addr
+	}
+}
+
+// WithScheduledTasks loads the tasks of the internal scheduler from a YAML or JSON file.
+// Each task runs a read-only tool on a cron schedule, and its latest result is exposed
+// as an MCP resource. An empty path disables the scheduler.
+func WithScheduledTasks(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.scheduledTasksPath = path
+	}
+}
+
+// WithOutputFormat sets the format of the tool results: json (the default), yaml, table
+// or summary. A tool call can choose another format with the outputFormat field of its
+// _meta.
+func WithOutputFormat(name string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.outputFormat = name
+	}
+}
+
+// WithRetries sets the number of times a retry-safe call failing because Portainer or
+// the environment is unavailable is retried automatically. Zero disables the retries.
+func WithRetries(maxRetries int) ServerOption {
+	return func(opts *serverOptions) {
+		opts.maxRetries = maxRetries
+	}
+}
+
+// WithTransport sets the transport of the MCP protocol: stdio (the default) for a single
+// local client, or sse or http (Streamable HTTP) to serve remote clients over HTTP on
+// listenAddr (DefaultListenAddr when empty).
+func WithTransport(transport, listenAddr string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.transport = transport
+		opts.listenAddr = listenAddr
+	}
+}
+
+// WithHTTPAuthToken sets the bearer token that the clients of the HTTP transports must
+// send in the Authorization header. An empty token leaves the endpoints unprotected.
+func WithHTTPAuthToken(token string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.httpAuthToken = token
+	}
+}
+
+// WithPrincipals loads from a YAML or JSON file the clients of the HTTP transports that
+// act as their own Portainer user: each authenticates with its own bearer token, and its
+// tool calls are made with the Portainer API key of its user. An empty path configures
+// no principal.
+func WithPrincipals(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.principalsPath = path
+	}
+}
+
+// WithPolicy loads the rules deciding whether write tool calls may run from a YAML or
+// JSON file. An empty path disables the policy.
+func WithPolicy(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.policyPath = path
+	}
+}
+
+// WithAuditLog appends the policy decisions to a file of JSON lines. When the path is
+// empty, the decisions are written to the server log.
+func WithAuditLog(path string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.auditLogPath = path
+	}
+}
+
+// WithClientLogLevel sets the level from which the server logs, such as retries and a
+// failed version check, are forwarded to the clients as MCP log notifications, along with
+// the details of the failed tool calls. A client can change it with logging/setLevel. An
+// empty level or "off" disables the notifications.
+func WithClientLogLevel(level string) ServerOption {
+	return func(opts *serverOptions) {
+		opts.clientLogLevel = level
+	}
+}
+
 // NewPortainerMCPServer creates a new Portainer MCP server.
 //
 // This server provides an implementation of the MCP protocol for Portainer,
@@ -285,10 +772,48 @@ func NewPortainerMCPServer(serverURL, token, toolsPath string, options ...Server
 		option(opts)
 	}
 
-	tools, err := toolgen.LoadToolsFromYAML(toolsPath, MinimumToolsVersion)
+	s, err := newPortainerMCPServer(serverURL, token, toolsPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.principalsPath != "" {
+		s.principals, err = s.newPrincipals(serverURL, toolsPath, *opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load principals: %w", err)
+		}
+		log.Info().Int("principals", len(s.principals)).Str("path", opts.principalsPath).Msg("principals loaded")
+	}
+	return s, nil
+}
+
+// newPortainerMCPServer creates a server from applied options: the primary server, and
+// the server of each principal from a copy of the options of the primary server.
+func newPortainerMCPServer(serverURL, token, toolsPath string, opts *serverOptions) (*PortainerMCPServer, error) {
+	if opts.transport == "" {
+		opts.transport = TransportStdio
+	}
+	if !validTransport(opts.transport) {
+		return nil, fmt.Errorf("invalid transport %q: must be %s, %s or %s", opts.transport, TransportStdio, TransportSSE, TransportStreamableHTTP)
+	}
+	if opts.maxRetries < 0 {
+		return nil, fmt.Errorf("max retries must not be negative")
+	}
+	if opts.listenAddr == "" {
+		opts.listenAddr = DefaultListenAddr
+	}
+	if opts.clientLogLevel != "" && !validClientLogLevel(opts.clientLogLevel) {
+		return nil, fmt.Errorf("invalid client log level %q: must be an MCP log level, such as %s or %s, or %s", opts.clientLogLevel, mcp.LoggingLevelInfo, mcp.LoggingLevelWarning, ClientLogLevelOff)
+	}
+
+	if opts.keepAliveInterval > 0 && opts.stallTimeout > 0 && opts.stallTimeout < opts.keepAliveInterval {
+		return nil, fmt.Errorf("stall timeout %s is shorter than the keep-alive interval %s", opts.stallTimeout, opts.keepAliveInterval)
+	}
+
+	defs, err := toolgen.LoadToolDefinitionsFromYAML(toolsPath, MinimumToolsVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load tools: %w", err)
 	}
+	tools := toolgen.ConvertToolDefinitions(defs)
 
 	var portainerClient PortainerClient
 	if opts.client != nil {
@@ -297,71 +822,428 @@ func NewPortainerMCPServer(serverURL, token, toolsPath string, options ...Server
 		portainerClient = client.NewPortainerClient(serverURL, token, client.WithSkipTLSVerify(opts.skipTLSVerify))
 	}
 
-	if !opts.disableVersionCheck {
-		version, err := portainerClient.GetVersion()
+	versionCheck := opts.versionCheck
+	if versionCheck == "" {
+		versionCheck = VersionCheckStrict
+	}
+	if opts.disableVersionCheck {
+		versionCheck = VersionCheckOff
+	}
+	if !validVersionCheckMode(versionCheck) {
+		return nil, fmt.Errorf("invalid version check mode %q: must be %s, %s or %s", versionCheck, VersionCheckStrict, VersionCheckWarn, VersionCheckOff)
+	}
+	portainerVersion, err := checkPortainerVersion(portainerClient, versionCheck)
+	if err != nil {
+		return nil, err
+	}
+
+	var access *tokenAccess
+	if opts.rbacFilter || opts.scopeEnvironments {
+		access, err = loadTokenAccess(portainerClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine the API token access: %w", err)
+		}
+	}
+	if opts.rbacFilter {
+		log.Info().Str("user", access.user.Username).Str("role", access.user.Role).Bool("team-leader", access.teamLeader).Msg("filtering tools by API token access")
+	}
+
+	var scope *environmentScope
+	if opts.scopeEnvironments && !access.admin() {
+		scope, err = newEnvironmentScope(portainerClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize environment scope: %w", err)
+		}
+		log.Info().Str("user", access.user.Username).Int("environments", len(scope.ids)).Msg("restricting tool calls to the environments accessible to the API token user")
+	}
+
+	var history *stackhistory.Store
+	if opts.stackHistorySize > 0 {
+		history, err = stackhistory.New(opts.stackHistoryDir, opts.stackHistorySize)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get Portainer server version: %w", err)
+			return nil, fmt.Errorf("failed to initialize stack history: %w", err)
 		}
+	}
 
-		if !isCompatibleVersion(version, SupportedPortainerVersion) {
-			return nil, fmt.Errorf("unsupported Portainer server version: %s, only version %s.x is supported", version, SupportedPortainerVersion)
+	var deleteJournal *journal.Journal
+	if opts.deleteJournalSize > 0 {
+		deleteJournal, err = journal.New(opts.deleteJournalDir, opts.deleteJournalSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize delete journal: %w", err)
 		}
 	}
 
-	return &PortainerMCPServer{
-		srv: server.NewMCPServer(
-			"Portainer MCP Server",
-			"0.5.1",
-			server.WithToolCapabilities(true),
-			server.WithLogging(),
-		),
-		cli:      portainerClient,
-		tools:    tools,
-		readOnly: opts.readOnly,
-	}, nil
+	maintenanceStore, err := maintenance.New(opts.maintenanceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize maintenance records: %w", err)
+	}
+
+	outputFormat := strings.ToLower(strings.TrimSpace(opts.outputFormat))
+	if outputFormat == "" {
+		outputFormat = format.JSON
+	}
+	if _, err := format.Get(outputFormat); err != nil {
+		return nil, fmt.Errorf("invalid output format: %w", err)
+	}
+
+	var trendHistory *trends.Store
+	if opts.trendInterval > 0 && opts.trendHistorySize > 0 {
+		trendHistory, err = trends.New(opts.trendHistoryDir, opts.trendHistorySize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize trend history: %w", err)
+		}
+	}
+
+	stripFields := opts.k8sStripFields
+	if stripFields == nil {
+		stripFields = k8sutil.DefaultStripFields
+	}
+	stripper, err := k8sutil.NewStripper(stripFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes strip fields: %w", err)
+	}
+
+	s := &PortainerMCPServer{
+		cli:                 portainerClient,
+		tools:               tools,
+		readOnly:            opts.readOnly,
+		stackHistory:        history,
+		deleteJournal:       deleteJournal,
+		maintenance:         maintenanceStore,
+		trends:              trendHistory,
+		trendInterval:       opts.trendInterval,
+		k8sStripper:         stripper,
+		skipProxyValidation: opts.skipProxyValidation,
+		environmentScope:    scope,
+		portainerVersion:    portainerVersion,
+		versionCheck:        versionCheck,
+		keepAliveInterval:   opts.keepAliveInterval,
+		stallTimeout:        opts.stallTimeout,
+		stats:               newToolStats(),
+		metricsAddr:         opts.metricsAddr,
+		outputFormat:        outputFormat,
+		idempotency:         newIdempotencyStore(),
+		maxRetries:          opts.maxRetries,
+		transport:           opts.transport,
+		listenAddr:          opts.listenAddr,
+		httpAuthToken:       opts.httpAuthToken,
+		toolsPath:           toolsPath,
+		granularTools:       opts.granularTools,
+		rbacFilter:          opts.rbacFilter,
+	}
+	if opts.rbacFilter {
+		s.access = access
+	}
+	s.clientLogs = newClientLogs(opts.clientLogLevel)
+
+	argumentRules := toolgen.NewArgumentRules(defs)
+	s.argumentRules = argumentRules
+	hooks := s.rootsHooks()
+	hooks.AddBeforeListResources(s.listFileResources)
+	if s.clientLogs != nil {
+		if warning := s.versionCheckWarning(); warning != nil {
+			s.clientLogs.startup = append(s.clientLogs.startup, warning)
+		}
+		s.clientLogs.addHooks(hooks)
+	}
+	serverOpts := []server.ServerOption{
+		server.WithToolCapabilities(true),
+		server.WithLogging(),
+		// Registered first so that the statistics cover the other middlewares and see the
+		// error codes.
+		server.WithToolHandlerMiddleware(s.statsMiddleware),
+		// Registered next so that every other middleware sees the coerced arguments, and
+		// every error result gets an error code.
+		server.WithToolHandlerMiddleware(errorCodeMiddleware),
+		server.WithToolHandlerMiddleware(argumentRulesMiddleware(argumentRules)),
+		// Registered before the timeout, scope and budget middlewares so that the duration
+		// covers them and the results they reject carry metadata too.
+		server.WithToolHandlerMiddleware(s.metadataMiddleware),
+		server.WithHooks(hooks),
+	}
+	if s.clientLogs != nil {
+		// Registered outside the error code and metadata middlewares so that the failed
+		// calls are reported with their error code and metadata.
+		serverOpts = append([]server.ServerOption{server.WithToolHandlerMiddleware(s.clientLogMiddleware)}, serverOpts...)
+	}
+
+	if opts.maxResultSize > 0 {
+		s.results = newResultStore(opts.maxResultSize)
+		// Registered before the redaction middleware so that the stored payloads are redacted.
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.chunkingMiddleware))
+	}
+
+	// Registered after the chunking middleware so that the stored payloads are formatted,
+	// and before the redaction middleware so that the redaction rules see JSON.
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.outputFormatMiddleware))
+	// Registered after the output format middleware so that the structured content is
+	// built from JSON, and before the redaction middleware so that it is built from the
+	// redacted text.
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(structuredOutputMiddleware))
+
+	var redaction *redact.Engine
+	if opts.redactionRulesPath != "" {
+		redaction, err = redact.Load(opts.redactionRulesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redaction rules: %w", err)
+		}
+		log.Info().Int("rules", redaction.Len()).Str("path", opts.redactionRulesPath).Msg("redaction rules loaded")
+		s.redaction = redaction
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(redactionMiddleware(redaction)))
+	}
+
+	if opts.scheduledTasksPath != "" {
+		tasks, err := scheduler.Load(opts.scheduledTasksPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scheduled tasks: %w", err)
+		}
+		s.scheduledTasks, err = s.newScheduledTasks(tasks, argumentRules, redaction)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scheduled tasks: %w", err)
+		}
+		log.Info().Int("tasks", len(s.scheduledTasks)).Str("path", opts.scheduledTasksPath).Msg("scheduled tasks loaded")
+	}
+
+	if opts.toolTimeouts != "" {
+		timeouts, err := parseToolTimeouts(opts.toolTimeouts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tool timeouts: %w", err)
+		}
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(timeoutMiddleware(timeouts)))
+	}
+
+	if opts.proxyRulesPath != "" {
+		policy, err := proxyroutes.LoadPolicy(opts.proxyRulesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load proxy rules: %w", err)
+		}
+		log.Info().Int("rules", policy.Len()).Str("path", opts.proxyRulesPath).Msg("proxy rules loaded")
+		s.proxyPolicy = policy
+	}
+
+	if opts.instancesPath != "" {
+		instances, err := newInstanceClients(opts.instancesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load instances: %w", err)
+		}
+		log.Info().Int("instances", len(instances)).Str("path", opts.instancesPath).Msg("additional Portainer instances loaded")
+		s.instances = instances
+	}
+
+	if opts.notifySecret != "" && opts.notifyWebhookURL == "" {
+		return nil, fmt.Errorf("failed to configure notifications: a notification secret requires a webhook URL")
+	}
+	if opts.notifyWebhookURL != "" {
+		notifier, err := notify.New(opts.notifyWebhookURL, opts.notifySecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure notifications: %w", err)
+		}
+		s.notifier = notifier
+		// Registered first so that it runs outermost and only sees redacted results.
+		serverOpts = append([]server.ServerOption{server.WithToolHandlerMiddleware(s.notificationMiddleware)}, serverOpts...)
+	}
+
+	if s.environmentScope != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.environmentScopeMiddleware))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.rootsScopeMiddleware))
+	// Registered before the policy and budget middlewares so that replayed calls are
+	// neither evaluated nor charged again.
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.idempotencyMiddleware))
+
+	if opts.policyPath != "" {
+		s.policy, err = policy.Load(opts.policyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load policy: %w", err)
+		}
+		log.Info().Int("rules", s.policy.Len()).Str("path", opts.policyPath).Msg("policy loaded")
+		// Registered before the budget middleware so that denied calls do not consume
+		// the budget.
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.policyMiddleware))
+	}
+
+	if opts.auditLogPath != "" {
+		s.auditLog, err = audit.Open(opts.auditLogPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var state *sessionstate.Store
+	if opts.sessionStateDir != "" {
+		state, err = sessionstate.New(opts.sessionStateDir, sessionstate.DefaultTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session state: %w", err)
+		}
+		s.sessionState = state
+		log.Info().Int("sessions", len(state.Sessions())).Str("path", opts.sessionStateDir).Msg("session state loaded")
+		s.restorePlans(state)
+	}
+
+	if opts.maxWriteOps > 0 || opts.maxDestructiveOps > 0 {
+		s.budget = newToolBudget(opts.maxWriteOps, opts.maxDestructiveOps)
+		if state != nil {
+			s.budget.restore(state)
+		}
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.budgetMiddleware))
+	}
+
+	if opts.maxRetries > 0 {
+		// Registered last so that it runs innermost: a retried call is checked and charged
+		// once, and its retries stay within the tool timeout.
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(s.retryMiddleware))
+	}
+
+	s.srv = server.NewMCPServer(
+		"Portainer MCP Server",
+		"0.5.1",
+		serverOpts...,
+	)
+	s.addRootsHandlers()
+	if s.clientLogs != nil {
+		s.clientLogs.srv = s.srv
+	}
+
+	return s, nil
 }
 
-// Start begins listening for MCP protocol messages on standard input/output.
-// It handles SIGINT and SIGTERM for graceful shutdown.
+// Start begins listening for MCP protocol messages on the configured transport: standard
+// input/output, or SSE or Streamable HTTP on the listen address for remote clients.
+// It handles SIGINT and SIGTERM for graceful shutdown, and SIGHUP to reset the tool budget
+// and reload the tools.
+// When keep-alive is enabled, a silent client is pinged, and over stdio the server stops
+// once the client has not sent anything for the stall timeout.
+// The scheduled tasks and the trend sampler run until it returns, and the server logs are
+// forwarded to the clients when client logging is enabled.
+// Pending destructive action notifications are delivered before it returns. With
+// principals, the HTTP transports route the requests of each principal to its server,
+// whose budget and tools are reset and reloaded on SIGHUP too.
 func (s *PortainerMCPServer) Start() error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	defer s.notifications.Wait()
+	for _, p := range s.principals {
+		defer p.server.notifications.Wait()
+	}
+	if s.auditLog != nil {
+		defer s.auditLog.Close()
+	}
 
-	errCh := make(chan error, 1)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 	go func() {
-		errCh <- server.ServeStdio(s.srv)
+		for range hup {
+			s.ResetBudget()
+			if _, err := s.ReloadTools(); err != nil {
+				log.Error().Err(err).Msg("failed to reload tools, keeping the registered tools")
+			}
+			for _, p := range s.principals {
+				p.server.ResetBudget()
+				if _, err := p.server.ReloadTools(); err != nil {
+					log.Error().Err(err).Str("principal", p.name).Msg("failed to reload tools, keeping the registered tools")
+				}
+			}
+		}
 	}()
 
-	select {
-	case err := <-errCh:
-		return err
-	case <-ctx.Done():
-		log.Info().Msg("Received shutdown signal, stopping server")
-		return nil
+	if s.metricsAddr != "" {
+		if err := s.serveMetrics(ctx, s.metricsAddr); err != nil {
+			return err
+		}
 	}
-}
 
-// addToolIfExists adds a tool to the server if it exists in the tools map
-func (s *PortainerMCPServer) addToolIfExists(toolName string, handler server.ToolHandlerFunc) {
-	if tool, exists := s.tools[toolName]; exists {
-		s.srv.AddTool(tool, handler)
-	} else {
-		log.Warn().Str("tool", toolName).Msg("Tool not found, will not be registered for MCP usage")
+	if len(s.scheduledTasks) > 0 {
+		schedulerCtx, stopScheduler := context.WithCancel(ctx)
+		schedulerDone := make(chan struct{})
+		go func() {
+			defer close(schedulerDone)
+			s.runScheduledTasks(schedulerCtx)
+		}()
+		defer func() {
+			stopScheduler()
+			<-schedulerDone
+		}()
+	}
+
+	if s.trends != nil {
+		samplerCtx, stopSampler := context.WithCancel(ctx)
+		samplerDone := make(chan struct{})
+		go func() {
+			defer close(samplerDone)
+			s.runTrendSampler(samplerCtx)
+		}()
+		defer func() {
+			stopSampler()
+			<-samplerDone
+		}()
 	}
+
+	if s.transport == TransportStdio {
+		return s.serveStdio(ctx)
+	}
+
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.listenAddr, err)
+	}
+	if s.httpAuthToken == "" && len(s.principals) == 0 {
+		log.Warn().Msg("the HTTP transport does not authenticate its clients: anyone reaching the listen address can use the Portainer API token of the server")
+	}
+	if s.transport == TransportSSE {
+		return s.serveSSE(ctx, listener)
+	}
+	return s.serveStreamableHTTP(ctx, listener)
 }
 
-// isCompatibleVersion checks if the actual version is compatible with the supported version.
-// It compares only the major.minor components, allowing patch version differences.
-func isCompatibleVersion(actual, supported string) bool {
-	return majorMinor(actual) == majorMinor(supported)
+// AddAllFeatures registers every granular tool on the MCP server, as -granular-tools does.
+func (s *PortainerMCPServer) AddAllFeatures() {
+	s.AddEnvironmentFeatures()
+	s.AddEnvironmentGroupFeatures()
+	s.AddTagFeatures()
+	s.AddStackFeatures()
+	s.AddSettingsFeatures()
+	s.AddSSLFeatures()
+	s.AddUserFeatures()
+	s.AddTeamFeatures()
+	s.AddAccessGroupFeatures()
+	s.AddDockerProxyFeatures()
+	s.AddKubernetesProxyFeatures()
+	s.AddKubernetesNativeFeatures()
+	s.AddSystemFeatures()
+	s.AddPlanFeatures()
+	s.AddWebhookFeatures()
+	s.AddCustomTemplateFeatures()
+	s.AddRegistryFeatures()
+	s.AddBackupFeatures()
+	s.AddRoleFeatures()
+	s.AddMotdFeatures()
+	s.AddAuthFeatures()
+	s.AddEdgeJobFeatures()
+	s.AddEdgeUpdateScheduleFeatures()
+	s.AddAppTemplateFeatures()
+	s.AddHelmFeatures()
 }
 
-// majorMinor extracts the "major.minor" prefix from a version string.
-// For example, "2.31.2" returns "2.31" and "2.31" returns "2.31".
-func majorMinor(version string) string {
-	parts := strings.SplitN(version, ".", 3)
-	if len(parts) < 2 {
-		return version
+// HandleMessage processes a single JSON-RPC message, such as a tools/call request, as the
+// stdio transport does, and returns the response. End-to-end tests use it to exercise the
+// registered tools, their schemas and the tool middlewares without a transport.
+func (s *PortainerMCPServer) HandleMessage(ctx context.Context, message json.RawMessage) mcp.JSONRPCMessage {
+	return s.srv.HandleMessage(ctx, message)
+}
+
+// addToolIfExists adds a tool to the server if it exists in the tools map
+func (s *PortainerMCPServer) addToolIfExists(toolName string, handler server.ToolHandlerFunc) {
+	if !s.toolAllowed(toolName) {
+		log.Debug().Str("tool", toolName).Msg("Tool not allowed for the API token, will not be registered")
+		return
+	}
+	if tool, exists := s.toolDefinition(toolName); exists {
+		if output, ok := toolOutputs[toolName]; ok {
+			tool.RawOutputSchema = output.schema
+		}
+		s.registerTool(tool, handler)
+	} else {
+		log.Warn().Str("tool", toolName).Msg("Tool not found, will not be registered for MCP usage")
 	}
-	return parts[0] + "." + parts[1]
 }
@@ -22,9 +22,11 @@ func TestNewPortainerMCPServer(t *testing.T) {
 		serverURL     string
 		token         string
 		toolsPath     string
+		versionCheck  string
 		mockSetup     func(*MockPortainerClient)
 		expectError   bool
 		errorContains string
+		expectVersion string
 	}{
 		{
 			name:      "successful initialization with supported version",
@@ -34,7 +36,19 @@ func TestNewPortainerMCPServer(t *testing.T) {
 			mockSetup: func(m *MockPortainerClient) {
 				m.On("GetVersion").Return(SupportedPortainerVersion, nil)
 			},
-			expectError: false,
+			expectError:   false,
+			expectVersion: SupportedPortainerVersion,
+		},
+		{
+			name:      "successful initialization with older supported version",
+			serverURL: "https://portainer.example.com",
+			token:     "valid-token",
+			toolsPath: validToolsPath,
+			mockSetup: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("2.27.4", nil)
+			},
+			expectError:   false,
+			expectVersion: "2.27.4",
 		},
 		{
 			name:          "invalid tools path",
@@ -76,6 +90,48 @@ func TestNewPortainerMCPServer(t *testing.T) {
 			expectError:   true,
 			errorContains: "unsupported Portainer server version",
 		},
+		{
+			name:         "unsupported Portainer version in warn mode",
+			serverURL:    "https://portainer.example.com",
+			token:        "valid-token",
+			toolsPath:    validToolsPath,
+			versionCheck: VersionCheckWarn,
+			mockSetup: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("2.33.0", nil)
+			},
+			expectError:   false,
+			expectVersion: "2.33.0",
+		},
+		{
+			name:         "API communication error in warn mode",
+			serverURL:    "https://portainer.example.com",
+			token:        "valid-token",
+			toolsPath:    validToolsPath,
+			versionCheck: VersionCheckWarn,
+			mockSetup: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("", errors.New("connection error"))
+			},
+			expectError: false,
+		},
+		{
+			name:         "version check off",
+			serverURL:    "https://portainer.example.com",
+			token:        "valid-token",
+			toolsPath:    validToolsPath,
+			versionCheck: VersionCheckOff,
+			mockSetup:    func(m *MockPortainerClient) {},
+			expectError:  false,
+		},
+		{
+			name:          "invalid version check mode",
+			serverURL:     "https://portainer.example.com",
+			token:         "valid-token",
+			toolsPath:     validToolsPath,
+			versionCheck:  "lenient",
+			mockSetup:     func(m *MockPortainerClient) {},
+			expectError:   true,
+			errorContains: "invalid version check mode",
+		},
 		{
 			name:      "unsupported version with disabled version check",
 			serverURL: "https://portainer.example.com",
@@ -102,6 +158,9 @@ func TestNewPortainerMCPServer(t *testing.T) {
 			if tt.name == "unsupported version with disabled version check" {
 				options = append(options, WithDisableVersionCheck(true))
 			}
+			if tt.versionCheck != "" {
+				options = append(options, WithVersionCheck(tt.versionCheck))
+			}
 
 			server, err := NewPortainerMCPServer(
 				tt.serverURL,
@@ -122,6 +181,7 @@ func TestNewPortainerMCPServer(t *testing.T) {
 				assert.NotNil(t, server.srv)
 				assert.NotNil(t, server.cli)
 				assert.NotNil(t, server.tools)
+				assert.Equal(t, tt.expectVersion, server.portainerVersion)
 			}
 
 			// Verify that all expected methods were called
@@ -130,6 +190,33 @@ func TestNewPortainerMCPServer(t *testing.T) {
 	}
 }
 
+// TestIsSupportedVersion verifies the supported range of Portainer versions.
+func TestIsSupportedVersion(t *testing.T) {
+	tests := []struct {
+		version   string
+		supported bool
+	}{
+		{"2.27.0", true},
+		{"2.29.1", true},
+		{SupportedPortainerVersion, true},
+		{"2.31.9", true},
+		{"v2.31.2", true},
+		{"2.26.9", false},
+		{"2.27.0-rc1", false},
+		{"2.32.0", false},
+		{"3.0.0", false},
+		{"", false},
+		{"latest", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.supported, isSupportedVersion(tt.version))
+		})
+	}
+	assert.Equal(t, "2.27.0 - 2.31.x", supportedVersionRange())
+}
+
 // TestAddToolIfExists verifies add tool if exists behavior.
 func TestAddToolIfExists(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/sessionstate"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolBudgetPersistence verifies that the budget usage of a session survives a
+// restart and that an operator reset clears the persisted usage.
+func TestToolBudgetPersistence(t *testing.T) {
+	dir := t.TempDir()
+	state, err := sessionstate.New(dir, sessionstate.DefaultTTL)
+	require.NoError(t, err)
+
+	b := newToolBudget(2, 0)
+	b.restore(state)
+	require.NoError(t, b.reserve("a", operationWrite))
+	require.NoError(t, b.reserve("a", operationWrite))
+	require.NoError(t, b.reserve("b", operationWrite))
+	b.release("b", operationWrite)
+
+	restarted, err := sessionstate.New(dir, sessionstate.DefaultTTL)
+	require.NoError(t, err)
+	b = newToolBudget(2, 0)
+	b.restore(restarted)
+	assert.ErrorContains(t, b.reserve("a", operationWrite), "budget exhausted")
+	assert.NoError(t, b.reserve("b", operationWrite), "released operations are not persisted")
+
+	b.reset()
+	assert.Empty(t, restarted.Sessions())
+}
+
+// TestPlanPersistence verifies that a plan previewed before a restart can be applied by
+// the same session after it, and that persisted plans of unknown tools are ignored.
+func TestPlanPersistence(t *testing.T) {
+	composeFile := "services:\n  app:\n    image: nginx\n"
+	dir := t.TempDir()
+	state, err := sessionstate.New(dir, sessionstate.DefaultTTL)
+	require.NoError(t, err)
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Name: "web"}, nil)
+	s := &PortainerMCPServer{cli: mockClient}
+	s.restorePlans(state)
+
+	request := CreateMCPRequest(map[string]any{"action": "deploy_stack_and_wait", "environmentId": float64(1), "stackId": float64(5), "file": composeFile, "plan": true})
+	request.Params.Name = "manage_stacks"
+	result, err := s.HandleDeployStackAndWait()(context.Background(), request)
+	require.NoError(t, err)
+	plan := decodePlan(t, result)
+
+	require.NoError(t, state.Update("", func(session *sessionstate.Session) {
+		session.Plans = append(session.Plans, sessionstate.Plan{ID: "unknown", Tool: "removedTool", ExpiresAt: time.Now().Add(time.Minute)})
+	}))
+
+	restarted, err := sessionstate.New(dir, sessionstate.DefaultTTL)
+	require.NoError(t, err)
+	mockClient = &MockPortainerClient{}
+	mockClient.On("UpdateRegularStack", 5, 1, composeFile, map[string]string{}, false, false).Return(models.RegularStack{ID: 5, Name: "web"}, nil).Once()
+	mockClient.On("GetDockerContainers", 1, models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=web"}}).
+		Return([]models.DockerContainer{{ID: "a1", Name: "web-app-1", State: "running", Status: "Up 3 seconds"}}, nil)
+	s = &PortainerMCPServer{cli: mockClient}
+	s.restorePlans(restarted)
+
+	_, found := s.plans.peek("unknown")
+	assert.False(t, found)
+
+	result, err = s.HandleApplyPlan()(context.Background(), CreateMCPRequest(map[string]any{"planId": plan.PlanID}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].(mcp.TextContent).Text)
+	mockClient.AssertExpectations(t)
+
+	assert.Empty(t, restarted.Sessions(), "applied plans are removed from the session state")
+}
@@ -3,6 +3,9 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -13,9 +16,13 @@ import (
 func (s *PortainerMCPServer) AddSettingsFeatures() {
 	s.addToolIfExists(ToolGetSettings, s.HandleGetSettings())
 	s.addToolIfExists(ToolGetPublicSettings, s.HandleGetPublicSettings())
+	s.addToolIfExists(ToolExportSettings, s.HandleExportSettings())
+	s.addToolIfExists(ToolCompareInstances, s.HandleCompareInstances())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolUpdateSettings, s.HandleUpdateSettings())
+		s.addToolIfExists(ToolRevertSettings, s.HandleRevertSettings())
+		s.addToolIfExists(ToolImportSettings, s.HandleImportSettings())
 	}
 }
 
@@ -54,11 +61,17 @@ func (s *PortainerMCPServer) HandleUpdateSettings() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("failed to parse settings JSON", err), nil
 		}
 
+		snapshot, err := s.snapshotSettings(ToolUpdateSettings, slices.Sorted(maps.Keys(settingsMap)))
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to snapshot current settings", err), nil
+		}
+
 		if err := s.cli.UpdateSettings(settingsMap); err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to update settings", err), nil
 		}
 
-		return mcp.NewToolResultText("Settings updated successfully"), nil
+		id := s.settingsSnapshots.add(snapshot)
+		return mcp.NewToolResultText(fmt.Sprintf("Settings updated successfully. Previous values saved as snapshot %d; use revertSettings to restore them.", id)), nil
 	}
 }
 
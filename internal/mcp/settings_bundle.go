@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// settingsBundleFormat and settingsBundleVersion identify the JSON bundles written by
+// exportSettings.
+const (
+	settingsBundleFormat  = "portainer-settings"
+	settingsBundleVersion = 1
+)
+
+// portableSettings are the settings update fields carried by a settings bundle. Fields tied
+// to the instance, such as the edge tunnel and Portainer URLs, are left out, so that a
+// bundle can be imported on another instance.
+var portableSettings = []string{
+	"authenticationMethod",
+	"blackListedLabels",
+	"customLoginBanner",
+	"disableKubeRolesSync",
+	"disableKubeShell",
+	"disableKubeconfigDownload",
+	"edgeAgentCheckinInterval",
+	"enableEdgeComputeFeatures",
+	"enableTelemetry",
+	"enforceEdgeID",
+	"globalDeploymentOptions",
+	"helmRepositoryURL",
+	"internalAuthSettings",
+	"kubeconfigExpiry",
+	"kubectlShellImage",
+	"ldapsettings",
+	"logoURL",
+	"oauthSettings",
+	"snapshotInterval",
+	"templatesURL",
+	"trustOnFirstConnect",
+	"userSessionTimeout",
+}
+
+// settingsSecrets are the secret fields of the portable settings, as "field.Key" paths.
+// They are removed on export and can be given again on import; Portainer keeps the current
+// secret of the target instance when none is sent.
+var settingsSecrets = []string{"ldapsettings.Password", "oauthSettings.ClientSecret"}
+
+// settingsInstanceKeys are nested fields specific to an instance, removed on export.
+var settingsInstanceKeys = []string{"oauthSettings.KubeSecretKey"}
+
+// settingsBundle is the JSON document produced by exportSettings and read by importSettings.
+type settingsBundle struct {
+	Format           string         `json:"format"`
+	Version          int            `json:"version"`
+	ExportedAt       time.Time      `json:"exportedAt"`
+	PortainerVersion string         `json:"portainerVersion,omitempty"`
+	Settings         map[string]any `json:"settings"`
+	// ExcludedSecrets lists the secrets removed from Settings, to pass again with the
+	// secrets parameter of importSettings.
+	ExcludedSecrets []string `json:"excludedSecrets,omitempty"`
+}
+
+// settingsImportReport is the result of HandleImportSettings.
+type settingsImportReport struct {
+	Imported []string `json:"imported"`
+	Skipped  []string `json:"skipped,omitempty"`
+	// SnapshotID is the settings snapshot holding the replaced values, for revertSettings.
+	SnapshotID int      `json:"snapshotId"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// HandleExportSettings returns an MCP tool handler that exports the portable Portainer
+// settings as a JSON bundle, without secrets.
+func (s *PortainerMCPServer) HandleExportSettings() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		values, err := s.cli.GetSettingsValues(portableSettings)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get settings", err), nil
+		}
+
+		bundle := settingsBundle{
+			Format:     settingsBundleFormat,
+			Version:    settingsBundleVersion,
+			ExportedAt: time.Now().UTC(),
+			Settings:   values,
+		}
+		// The version is informative, so a failure to get it does not fail the export.
+		if version, err := s.cli.GetVersion(); err == nil {
+			bundle.PortainerVersion = version
+		}
+
+		for _, path := range settingsSecrets {
+			if removeSettingsPath(values, path) {
+				bundle.ExcludedSecrets = append(bundle.ExcludedSecrets, path)
+			}
+		}
+		for _, path := range settingsInstanceKeys {
+			removeSettingsPath(values, path)
+		}
+
+		return jsonResult(bundle, "failed to marshal settings bundle")
+	}
+}
+
+// HandleImportSettings returns an MCP tool handler that applies a bundle written by
+// exportSettings. The replaced values are saved as a settings snapshot, so that
+// revertSettings can restore them.
+func (s *PortainerMCPServer) HandleImportSettings() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		bundleJSON, err := parser.GetString("bundle", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid bundle parameter", err), nil
+		}
+		var bundle settingsBundle
+		if err := json.Unmarshal([]byte(bundleJSON), &bundle); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to parse settings bundle", err), nil
+		}
+		if bundle.Format != settingsBundleFormat || bundle.Version != settingsBundleVersion {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported settings bundle: expected format %q version %d, as written by exportSettings", settingsBundleFormat, settingsBundleVersion)), nil
+		}
+		if len(bundle.Settings) == 0 {
+			return mcp.NewToolResultError("the settings bundle holds no settings"), nil
+		}
+
+		secretsJSON, err := parser.GetString("secrets", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid secrets parameter", err), nil
+		}
+		secrets := map[string]string{}
+		if strings.TrimSpace(secretsJSON) != "" {
+			if err := json.Unmarshal([]byte(secretsJSON), &secrets); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to parse secrets JSON", err), nil
+			}
+		}
+		for path := range secrets {
+			if !slices.Contains(settingsSecrets, path) {
+				return mcp.NewToolResultError(fmt.Sprintf("unknown secret %q: expected one of %s", path, strings.Join(settingsSecrets, ", "))), nil
+			}
+		}
+
+		fields, err := parser.GetArrayOfStrings("fields", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid fields parameter", err), nil
+		}
+
+		report := settingsImportReport{}
+		update := map[string]any{}
+		for _, key := range slices.Sorted(maps.Keys(bundle.Settings)) {
+			switch {
+			case !slices.Contains(portableSettings, key):
+				report.Skipped = append(report.Skipped, key)
+			case len(fields) == 0 || slices.Contains(fields, key):
+				update[key] = bundle.Settings[key]
+			}
+		}
+		for _, field := range fields {
+			if _, ok := update[field]; !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("field %q is not a portable setting of the bundle", field)), nil
+			}
+		}
+
+		for _, path := range settingsInstanceKeys {
+			removeSettingsPath(update, path)
+		}
+		for _, path := range settingsSecrets {
+			field, key, _ := strings.Cut(path, ".")
+			secret, given := secrets[path]
+			if !removeSettingsPath(update, path) {
+				if given {
+					return mcp.NewToolResultError(fmt.Sprintf("secret %s is given but %s is not imported", path, field)), nil
+				}
+				continue
+			}
+			if given {
+				update[field].(map[string]any)[key] = secret
+			} else {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s was not given: the instance keeps its current value", path))
+			}
+		}
+
+		if bundle.PortainerVersion != "" {
+			if version, err := s.cli.GetVersion(); err == nil && version != bundle.PortainerVersion {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("the bundle was exported from Portainer %s and is imported into Portainer %s", bundle.PortainerVersion, version))
+			}
+		}
+
+		report.Imported = slices.Sorted(maps.Keys(update))
+		snapshot, err := s.snapshotSettings(ToolImportSettings, report.Imported)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to snapshot current settings", err), nil
+		}
+		if err := s.cli.UpdateSettings(update); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to import settings", err), nil
+		}
+		report.SnapshotID = s.settingsSnapshots.add(snapshot)
+
+		return jsonResult(report, "failed to marshal settings import report")
+	}
+}
+
+// removeSettingsPath removes a "field.Key" path from settings values, matching the key
+// case-insensitively as Portainer does. It reports whether the field holds an object, in
+// which case the key is absent on return.
+func removeSettingsPath(values map[string]any, path string) bool {
+	field, key, _ := strings.Cut(path, ".")
+	section, ok := values[field].(map[string]any)
+	if !ok {
+		return false
+	}
+	for name := range section {
+		if strings.EqualFold(name, key) {
+			delete(section, name)
+		}
+	}
+	return true
+}
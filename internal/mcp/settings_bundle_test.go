@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleExportSettings verifies that secrets and instance-specific keys are removed from
+// the exported bundle.
+func TestHandleExportSettings(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetSettingsValues", portableSettings).Return(map[string]any{
+		"authenticationMethod": float64(2),
+		"ldapsettings":         map[string]any{"URL": "ldap.example.com:389", "password": "s3cret"},
+		"oauthSettings":        map[string]any{"ClientID": "portainer", "ClientSecret": "", "KubeSecretKey": []any{float64(1)}},
+		"snapshotInterval":     "5m",
+	}, nil)
+	mockClient.On("GetVersion").Return("2.31.2", nil)
+	s := &PortainerMCPServer{cli: mockClient}
+
+	result, err := s.HandleExportSettings()(context.Background(), CreateMCPRequest(map[string]any{}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var bundle settingsBundle
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &bundle))
+	assert.Equal(t, settingsBundleFormat, bundle.Format)
+	assert.Equal(t, settingsBundleVersion, bundle.Version)
+	assert.Equal(t, "2.31.2", bundle.PortainerVersion)
+	assert.Equal(t, []string{"ldapsettings.Password", "oauthSettings.ClientSecret"}, bundle.ExcludedSecrets)
+	assert.Equal(t, map[string]any{
+		"authenticationMethod": float64(2),
+		"ldapsettings":         map[string]any{"URL": "ldap.example.com:389"},
+		"oauthSettings":        map[string]any{"ClientID": "portainer"},
+		"snapshotInterval":     "5m",
+	}, bundle.Settings)
+	mockClient.AssertExpectations(t)
+}
+
+// TestHandleImportSettings verifies the selection of the imported fields, the secrets given
+// again and the snapshot of the replaced values.
+func TestHandleImportSettings(t *testing.T) {
+	bundle := func(settings map[string]any) string {
+		data, err := json.Marshal(map[string]any{
+			"format":           settingsBundleFormat,
+			"version":          settingsBundleVersion,
+			"portainerVersion": "2.27.1",
+			"settings":         settings,
+		})
+		require.NoError(t, err)
+		return string(data)
+	}
+	exported := map[string]any{
+		"authenticationMethod": float64(2),
+		"ldapsettings":         map[string]any{"URL": "ldap.example.com:389"},
+		"snapshotInterval":     "5m",
+		"edgePortainerURL":     "https://portainer.example.com",
+	}
+
+	tests := []struct {
+		name             string
+		inputParams      map[string]any
+		setupMock        func(*MockPortainerClient)
+		expectError      string
+		expectedImported []string
+		expectedSkipped  []string
+		expectedWarnings int
+	}{
+		{
+			name:        "all portable settings with the LDAP password",
+			inputParams: map[string]any{"bundle": bundle(exported), "secrets": `{"ldapsettings.Password": "s3cret"}`},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("2.27.1", nil)
+				m.On("GetSettingsValues", []string{"authenticationMethod", "ldapsettings", "snapshotInterval"}).Return(map[string]any{}, nil)
+				m.On("UpdateSettings", map[string]any{
+					"authenticationMethod": float64(2),
+					"ldapsettings":         map[string]any{"URL": "ldap.example.com:389", "Password": "s3cret"},
+					"snapshotInterval":     "5m",
+				}).Return(nil)
+			},
+			expectedImported: []string{"authenticationMethod", "ldapsettings", "snapshotInterval"},
+			expectedSkipped:  []string{"edgePortainerURL"},
+		},
+		{
+			name:        "selected fields without secret from another version",
+			inputParams: map[string]any{"bundle": bundle(exported), "fields": []any{"ldapsettings"}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("2.31.2", nil)
+				m.On("GetSettingsValues", []string{"ldapsettings"}).Return(map[string]any{}, nil)
+				m.On("UpdateSettings", map[string]any{"ldapsettings": map[string]any{"URL": "ldap.example.com:389"}}).Return(nil)
+			},
+			expectedImported: []string{"ldapsettings"},
+			expectedSkipped:  []string{"edgePortainerURL"},
+			expectedWarnings: 2,
+		},
+		{
+			name:        "secret for a section that is not imported",
+			inputParams: map[string]any{"bundle": bundle(exported), "fields": []any{"snapshotInterval"}, "secrets": `{"ldapsettings.Password": "s3cret"}`},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "secret ldapsettings.Password is given but ldapsettings is not imported",
+		},
+		{
+			name:        "unknown secret",
+			inputParams: map[string]any{"bundle": bundle(exported), "secrets": `{"apiKey": "x"}`},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: `unknown secret "apiKey"`,
+		},
+		{
+			name:        "field missing from the bundle",
+			inputParams: map[string]any{"bundle": bundle(exported), "fields": []any{"logoURL"}},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: `field "logoURL" is not a portable setting of the bundle`,
+		},
+		{
+			name:        "not a settings bundle",
+			inputParams: map[string]any{"bundle": `{"settings": {"logoURL": ""}}`},
+			setupMock:   func(m *MockPortainerClient) {},
+			expectError: "unsupported settings bundle",
+		},
+		{
+			name:        "update error",
+			inputParams: map[string]any{"bundle": bundle(map[string]any{"logoURL": "https://example.com/logo.png"})},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetVersion").Return("2.27.1", nil)
+				m.On("GetSettingsValues", []string{"logoURL"}).Return(map[string]any{"logoURL": ""}, nil)
+				m.On("UpdateSettings", map[string]any{"logoURL": "https://example.com/logo.png"}).Return(fmt.Errorf("forbidden"))
+			},
+			expectError: "failed to import settings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setupMock(mockClient)
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleImportSettings()(context.Background(), CreateMCPRequest(tt.inputParams))
+			require.NoError(t, err)
+
+			if tt.expectError != "" {
+				require.True(t, result.IsError)
+				assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+				mockClient.AssertExpectations(t)
+				return
+			}
+
+			require.False(t, result.IsError, result.Content[0].(mcp.TextContent).Text)
+			var report settingsImportReport
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+			assert.Equal(t, tt.expectedImported, report.Imported)
+			assert.Equal(t, tt.expectedSkipped, report.Skipped)
+			assert.Len(t, report.Warnings, tt.expectedWarnings)
+			assert.Equal(t, 1, report.SnapshotID)
+
+			snapshot, found := s.settingsSnapshots.get(report.SnapshotID)
+			require.True(t, found)
+			assert.Equal(t, ToolImportSettings, snapshot.Tool)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// maxSettingsSnapshots caps the number of settings snapshots kept for revertSettings.
+const maxSettingsSnapshots = 50
+
+// settingsSnapshot holds the values that a settings update replaced.
+type settingsSnapshot struct {
+	ID      int
+	Tool    string
+	TakenAt time.Time
+	// Settings holds the previous value of every field of an updateSettings call.
+	Settings map[string]any
+	// SSL holds the previous SSL settings of an updateSSLSettings call.
+	SSL *models.SSLSettings
+	// CertificateReplaced reports whether the SSL update uploaded a new certificate or key,
+	// which cannot be restored because Portainer does not return their content.
+	CertificateReplaced bool
+}
+
+// snapshotStore keeps the most recent settings snapshots. The zero value is ready to use
+// and it is safe for concurrent use.
+type snapshotStore struct {
+	mu        sync.Mutex
+	next      int
+	snapshots []settingsSnapshot
+}
+
+// add stores a snapshot and returns its ID.
+func (st *snapshotStore) add(snapshot settingsSnapshot) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.next++
+	snapshot.ID = st.next
+	snapshot.TakenAt = time.Now().UTC()
+	st.snapshots = append(st.snapshots, snapshot)
+	if len(st.snapshots) > maxSettingsSnapshots {
+		st.snapshots = st.snapshots[len(st.snapshots)-maxSettingsSnapshots:]
+	}
+	return snapshot.ID
+}
+
+// get returns a snapshot by ID. The boolean is false when it is unknown or no longer kept.
+func (st *snapshotStore) get(id int) (settingsSnapshot, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	for _, snapshot := range st.snapshots {
+		if snapshot.ID == id {
+			return snapshot, true
+		}
+	}
+	return settingsSnapshot{}, false
+}
+
+// snapshotSettings returns a snapshot of the current value of the given settings fields.
+func (s *PortainerMCPServer) snapshotSettings(tool string, keys []string) (settingsSnapshot, error) {
+	values, err := s.cli.GetSettingsValues(keys)
+	if err != nil {
+		return settingsSnapshot{}, err
+	}
+	return settingsSnapshot{Tool: tool, Settings: values}, nil
+}
+
+// snapshotSSLSettings returns a snapshot of the current SSL settings.
+func (s *PortainerMCPServer) snapshotSSLSettings(tool string, certificateReplaced bool) (settingsSnapshot, error) {
+	ssl, err := s.cli.GetSSLSettings()
+	if err != nil {
+		return settingsSnapshot{}, err
+	}
+	return settingsSnapshot{Tool: tool, SSL: &ssl, CertificateReplaced: certificateReplaced}, nil
+}
+
+// HandleRevertSettings returns an MCP tool handler that restores the values replaced by an
+// updateSettings or updateSSLSettings call. The revert is itself snapshotted, so it can be
+// undone with another revert.
+func (s *PortainerMCPServer) HandleRevertSettings() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("snapshotId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid snapshotId parameter", err), nil
+		}
+
+		snapshot, found := s.settingsSnapshots.get(id)
+		if !found {
+			return mcp.NewToolResultError(fmt.Sprintf("settings snapshot %d not found (only the last %d snapshots of this server session are kept)", id, maxSettingsSnapshots)), nil
+		}
+
+		if snapshot.SSL != nil {
+			current, err := s.snapshotSSLSettings(ToolRevertSettings, false)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to snapshot current SSL settings", err), nil
+			}
+			if err := s.cli.UpdateSSLSettings("", "", &snapshot.SSL.HTTPEnabled); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to revert SSL settings", err), nil
+			}
+
+			message := fmt.Sprintf("SSL settings reverted to snapshot %d (httpEnabled=%t). Snapshot %d holds the values before the revert.", id, snapshot.SSL.HTTPEnabled, s.settingsSnapshots.add(current))
+			if snapshot.CertificateReplaced {
+				message += " The certificate and key uploaded by the update cannot be restored; upload the previous ones with updateSSLSettings."
+			}
+			return mcp.NewToolResultText(message), nil
+		}
+
+		keys := slices.Sorted(maps.Keys(snapshot.Settings))
+		current, err := s.snapshotSettings(ToolRevertSettings, keys)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to snapshot current settings", err), nil
+		}
+		if err := s.cli.UpdateSettings(snapshot.Settings); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to revert settings", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Settings %v reverted to snapshot %d. Snapshot %d holds the values before the revert.", keys, id, s.settingsSnapshots.add(current))), nil
+	}
+}
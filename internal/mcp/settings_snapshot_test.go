@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callText calls a handler and returns the text of its successful result.
+func callText(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) string {
+	t.Helper()
+	result, err := handler(context.Background(), CreateMCPRequest(args))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+	return text
+}
+
+// TestRevertSettings verifies that an updateSettings call can be reverted, and that the
+// revert can itself be reverted.
+func TestRevertSettings(t *testing.T) {
+	keys := []string{"enableTelemetry", "logoURL"}
+	before := map[string]any{"enableTelemetry": false, "logoURL": ""}
+	after := map[string]any{"enableTelemetry": true, "logoURL": "https://example.com/logo.png"}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetSettingsValues", keys).Return(before, nil).Once()
+	mockClient.On("UpdateSettings", after).Return(nil).Once()
+	s := &PortainerMCPServer{cli: mockClient}
+
+	text := callText(t, s.HandleUpdateSettings(), map[string]any{"settings": `{"logoURL":"https://example.com/logo.png","enableTelemetry":true}`})
+	assert.Contains(t, text, "snapshot 1")
+
+	mockClient.On("GetSettingsValues", keys).Return(after, nil).Once()
+	mockClient.On("UpdateSettings", before).Return(nil).Once()
+	text = callText(t, s.HandleRevertSettings(), map[string]any{"snapshotId": float64(1)})
+	assert.Equal(t, "Settings [enableTelemetry logoURL] reverted to snapshot 1. Snapshot 2 holds the values before the revert.", text)
+
+	mockClient.On("GetSettingsValues", keys).Return(before, nil).Once()
+	mockClient.On("UpdateSettings", after).Return(nil).Once()
+	text = callText(t, s.HandleRevertSettings(), map[string]any{"snapshotId": float64(2)})
+	assert.Contains(t, text, "reverted to snapshot 2")
+	mockClient.AssertExpectations(t)
+}
+
+// TestRevertSSLSettings verifies that reverting an SSL update restores httpEnabled and
+// reports that a replaced certificate cannot be restored.
+func TestRevertSSLSettings(t *testing.T) {
+	cert, key := generateTestCertAndKey(t)
+	disabled, enabled := false, true
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetSSLSettings").Return(models.SSLSettings{HTTPEnabled: true}, nil).Once()
+	mockClient.On("UpdateSSLSettings", cert, key, &disabled).Return(nil).Once()
+	s := &PortainerMCPServer{cli: mockClient}
+
+	text := callText(t, s.HandleUpdateSSLSettings(), map[string]any{"cert": cert, "key": key, "httpEnabled": false})
+	assert.Contains(t, text, "snapshot 1")
+
+	mockClient.On("GetSSLSettings").Return(models.SSLSettings{HTTPEnabled: false}, nil).Once()
+	mockClient.On("UpdateSSLSettings", "", "", &enabled).Return(nil).Once()
+	text = callText(t, s.HandleRevertSettings(), map[string]any{"snapshotId": float64(1)})
+	assert.Contains(t, text, "httpEnabled=true")
+	assert.Contains(t, text, "certificate and key uploaded by the update cannot be restored")
+	mockClient.AssertExpectations(t)
+}
+
+// TestSettingsSnapshotErrors verifies that updates are refused when the current values
+// cannot be snapshotted, and that unknown snapshots are reported.
+func TestSettingsSnapshotErrors(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetSettingsValues", []string{"enableTelemetry"}).Return(nil, fmt.Errorf("forbidden"))
+	s := &PortainerMCPServer{cli: mockClient}
+
+	result, err := s.HandleUpdateSettings()(context.Background(), CreateMCPRequest(map[string]any{"settings": `{"enableTelemetry":true}`}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "failed to snapshot current settings")
+	mockClient.AssertNotCalled(t, "UpdateSettings", map[string]any{"enableTelemetry": true})
+
+	result, err = s.HandleRevertSettings()(context.Background(), CreateMCPRequest(map[string]any{"snapshotId": float64(7)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "settings snapshot 7 not found")
+}
+
+// TestSnapshotStoreLimit verifies that only the most recent snapshots are kept.
+func TestSnapshotStoreLimit(t *testing.T) {
+	var store snapshotStore
+	for range maxSettingsSnapshots + 1 {
+		store.add(settingsSnapshot{})
+	}
+
+	_, found := store.get(1)
+	assert.False(t, found)
+	_, found = store.get(maxSettingsSnapshots + 1)
+	assert.True(t, found)
+}
@@ -115,6 +115,7 @@ func TestHandleUpdateSettings(t *testing.T) {
 				},
 			},
 			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"enableEdgeComputeFeatures"}).Return(map[string]any{"enableEdgeComputeFeatures": true}, nil)
 				m.On("UpdateSettings", map[string]interface{}{"enableEdgeComputeFeatures": true}).Return(nil)
 			},
 			expectError: false,
@@ -153,6 +154,7 @@ func TestHandleUpdateSettings(t *testing.T) {
 				},
 			},
 			setupMock: func(m *MockPortainerClient) {
+				m.On("GetSettingsValues", []string{"enableEdgeComputeFeatures"}).Return(map[string]any{"enableEdgeComputeFeatures": true}, nil)
 				m.On("UpdateSettings", map[string]interface{}{"enableEdgeComputeFeatures": false}).Return(assert.AnError)
 			},
 			expectError:   true,
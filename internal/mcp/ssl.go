@@ -48,14 +48,12 @@ func (s *PortainerMCPServer) HandleUpdateSSLSettings() server.ToolHandlerFunc {
 		}
 
 		var httpEnabled *bool
-		if args := request.GetArguments(); args != nil {
-			if val, ok := args["httpEnabled"]; ok && val != nil {
-				boolVal, ok := val.(bool)
-				if !ok {
-					return mcp.NewToolResultErrorFromErr("invalid httpEnabled parameter", fmt.Errorf("httpEnabled must be a boolean")), nil
-				}
-				httpEnabled = &boolVal
+		if parser.Has("httpEnabled") {
+			enabled, err := parser.GetBoolean("httpEnabled", true)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("invalid httpEnabled parameter", err), nil
 			}
+			httpEnabled = &enabled
 		}
 
 		if cert != "" {
@@ -75,10 +73,16 @@ func (s *PortainerMCPServer) HandleUpdateSSLSettings() server.ToolHandlerFunc {
 			}
 		}
 
+		snapshot, err := s.snapshotSSLSettings(ToolUpdateSSLSettings, cert != "" || key != "")
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to snapshot current SSL settings", err), nil
+		}
+
 		if err := s.cli.UpdateSSLSettings(cert, key, httpEnabled); err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to update SSL settings", err), nil
 		}
 
-		return mcp.NewToolResultText("SSL settings updated successfully"), nil
+		id := s.settingsSnapshots.add(snapshot)
+		return mcp.NewToolResultText(fmt.Sprintf("SSL settings updated successfully. Previous values saved as snapshot %d; use revertSettings to restore them.", id)), nil
 	}
 }
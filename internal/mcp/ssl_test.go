@@ -136,6 +136,7 @@ Arguments: map[string]any{
 },
 },
 setupMock: func(m *MockPortainerClient) {
+m.On("GetSSLSettings").Return(models.SSLSettings{}, nil)
 m.On("UpdateSSLSettings", testCert, testKey, &httpEnabled).Return(nil)
 },
 expectError: false,
@@ -151,6 +152,7 @@ Arguments: map[string]any{
 },
 },
 setupMock: func(m *MockPortainerClient) {
+m.On("GetSSLSettings").Return(models.SSLSettings{}, nil)
 m.On("UpdateSSLSettings", testCert, testKey, (*bool)(nil)).Return(nil)
 },
 expectError: false,
@@ -166,6 +168,7 @@ Arguments: map[string]any{
 },
 },
 setupMock: func(m *MockPortainerClient) {
+m.On("GetSSLSettings").Return(models.SSLSettings{}, nil)
 m.On("UpdateSSLSettings", testCert, testKey, (*bool)(nil)).Return(assert.AnError)
 },
 expectError:   true,
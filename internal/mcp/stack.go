@@ -3,9 +3,14 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/stackhistory"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -16,6 +21,12 @@ func (s *PortainerMCPServer) AddStackFeatures() {
 	s.addToolIfExists(ToolGetStackFile, s.HandleGetStackFile())
 	s.addToolIfExists(ToolGetStack, s.HandleInspectStack())
 	s.addToolIfExists(ToolInspectStackFile, s.HandleInspectStackFile())
+	s.addToolIfExists(ToolWaitFor, s.HandleWaitFor())
+	s.addToolIfExists(ToolGetStackFileHistory, s.HandleGetStackFileHistory())
+	s.addToolIfExists(ToolDetectDrift, s.HandleDetectDrift())
+	s.addToolIfExists(ToolAnalyzeStackImages, s.HandleAnalyzeStackImages())
+	s.addToolIfExists(ToolGetStackResources, s.HandleGetStackResources())
+	s.addToolIfExists(ToolWaitForEdgeStackRollout, s.HandleWaitForEdgeStackRollout())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolCreateStack, s.HandleCreateStack())
@@ -26,30 +37,131 @@ func (s *PortainerMCPServer) AddStackFeatures() {
 		s.addToolIfExists(ToolStartStack, s.HandleStartStack())
 		s.addToolIfExists(ToolStopStack, s.HandleStopStack())
 		s.addToolIfExists(ToolMigrateStack, s.HandleMigrateStack())
+		s.addToolIfExists(ToolMigrateRegularStackToEdge, s.HandleMigrateRegularStackToEdge())
+		s.addToolIfExists(ToolDeployStackAndWait, s.HandleDeployStackAndWait())
+		s.addToolIfExists(ToolRedeployStacksForImage, s.HandleRedeployStacksForImage())
 	}
 }
 
-// HandleGetStacks returns an MCP tool handler that retrieves stacks.
+// edgeStackPage is the result of HandleGetStacks when offset or limit is set.
+type edgeStackPage struct {
+	Stacks []models.Stack `json:"stacks"`
+	// Total is the number of stacks matching the filters, across all pages.
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	// NextOffset is the offset of the next page, omitted on the last page.
+	NextOffset int `json:"next_offset,omitempty"`
+}
+
+// HandleGetStacks returns an MCP tool handler that retrieves edge stacks, optionally
+// filtered and paginated.
 func (s *PortainerMCPServer) HandleGetStacks() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		stacks, err := s.cli.GetStacks()
+		parser := toolgen.NewParameterParser(request)
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		environmentGroupId, err := parser.GetInt("environmentGroupId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentGroupId parameter", err), nil
+		}
+
+		status, err := parser.GetEnum("status", false, models.StackStatusDeployed, models.StackStatusDeploying, models.StackStatusFailed)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid status parameter", err), nil
+		}
+
+		offset, err := parser.GetInt("offset", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid offset parameter", err), nil
+		}
+		if offset < 0 {
+			return mcp.NewToolResultError("invalid offset parameter: must not be negative"), nil
+		}
+
+		limit, err := parser.GetInt("limit", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if limit < 0 {
+			return mcp.NewToolResultError("invalid limit parameter: must not be negative"), nil
+		}
+
+		summarize, err := parser.GetBoolean("summarize", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid summarize parameter", err), nil
+		}
+
+		stacks, err := s.cli.GetStacks(models.EdgeStackListOptions{Name: name, EnvironmentGroupID: environmentGroupId, Status: status})
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to get stacks", err), nil
 		}
 
-		return jsonResult(stacks, "failed to marshal stacks")
+		// A summary covers every matching stack, so it ignores the pagination.
+		if summarize || (offset == 0 && limit == 0) {
+			return listResult(request, stacks, "failed to marshal stacks")
+		}
+
+		page := edgeStackPage{Total: len(stacks), Offset: offset}
+		end := len(stacks)
+		if limit > 0 {
+			end = min(offset+limit, len(stacks))
+		}
+		page.Stacks = stacks[min(offset, len(stacks)):end]
+		if end < len(stacks) {
+			page.NextOffset = end
+		}
+		return jsonResult(page, "failed to marshal stacks")
 	}
 }
 
 // HandleListRegularStacks returns an MCP tool handler that lists regular stacks.
 func (s *PortainerMCPServer) HandleListRegularStacks() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		environmentId, err := parser.GetInt("environmentId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+
+		status, err := parser.GetString("status", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid status parameter", err), nil
+		}
+		if status != "" && status != "active" && status != "inactive" {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid status: %s", status)), nil
+		}
+
 		stacks, err := s.cli.GetRegularStacks()
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to list regular stacks", err), nil
 		}
 
-		return jsonResult(stacks, "failed to marshal regular stacks")
+		filtered := make([]models.RegularStack, 0, len(stacks))
+		for _, stack := range stacks {
+			if !containsFold(stack.Name, name) {
+				continue
+			}
+			if environmentId != 0 && stack.EndpointID != environmentId {
+				continue
+			}
+			if status != "" && regularStackStatusName(stack.Status) != status {
+				continue
+			}
+			filtered = append(filtered, stack)
+		}
+		stacks = filtered
+
+		return listResult(request, stacks, "failed to marshal regular stacks")
 	}
 }
 
@@ -105,6 +217,7 @@ func (s *PortainerMCPServer) HandleCreateStack() server.ToolHandlerFunc {
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("error creating stack", err), nil
 		}
+		s.recordStackFile(stackhistory.KindEdge, id, ToolCreateStack, file)
 
 		return mcp.NewToolResultText(fmt.Sprintf("Stack created successfully with ID: %d", id)), nil
 	}
@@ -136,10 +249,12 @@ func (s *PortainerMCPServer) HandleUpdateStack() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid environmentGroupIds parameter", err), nil
 		}
 
+		s.captureStackBaseline(stackhistory.KindEdge, id)
 		err = s.cli.UpdateStack(id, file, environmentGroupIds)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to update stack", err), nil
 		}
+		s.recordStackFile(stackhistory.KindEdge, id, ToolUpdateStack, file)
 
 		return mcp.NewToolResultText("Stack updated successfully"), nil
 	}
@@ -193,12 +308,16 @@ func (s *PortainerMCPServer) HandleDeleteStack() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid removeVolumes parameter", err), nil
 		}
 
+		entry := s.captureDeletion(journal.KindStack, id, func() (journal.Entry, error) {
+			return s.captureStack(id, endpointID, removeVolumes)
+		})
+
 		err = s.cli.DeleteStack(id, endpointID, removeVolumes)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to delete stack", err), nil
 		}
 
-		return mcp.NewToolResultText("Stack deleted successfully"), nil
+		return s.deletionResult(ToolDeleteStack, "Stack deleted successfully", entry), nil
 	}
 }
 
@@ -255,10 +374,12 @@ func (s *PortainerMCPServer) HandleUpdateStackGit() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid prune parameter", err), nil
 		}
 
+		s.captureStackBaseline(stackhistory.KindRegular, id)
 		stack, err := s.cli.UpdateStackGit(id, endpointID, referenceName, prune)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to update stack git", err), nil
 		}
+		s.recordDeployedStackFile(id, ToolUpdateStackGit)
 
 		return jsonResult(stack, "failed to marshal stack")
 	}
@@ -295,10 +416,12 @@ func (s *PortainerMCPServer) HandleRedeployStackGit() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid prune parameter", err), nil
 		}
 
+		s.captureStackBaseline(stackhistory.KindRegular, id)
 		stack, err := s.cli.RedeployStackGit(id, endpointID, pullImage, prune)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to redeploy stack", err), nil
 		}
+		s.recordDeployedStackFile(id, ToolRedeployStackGit)
 
 		return jsonResult(stack, "failed to marshal stack")
 	}
@@ -406,3 +529,147 @@ func (s *PortainerMCPServer) HandleMigrateStack() server.ToolHandlerFunc {
 		return jsonResult(stack, "failed to marshal stack")
 	}
 }
+
+// deployStackAndWaitReport is the final status report of HandleDeployStackAndWait.
+type deployStackAndWaitReport struct {
+	Stack models.RegularStack `json:"stack"`
+	stackContainersReport
+}
+
+// HandleDeployStackAndWait returns an MCP tool handler that creates or updates a regular
+// Compose stack and then waits until its containers are running and healthy, have failed,
+// or the timeout elapses. The report includes the logs of containers that are not ready.
+func (s *PortainerMCPServer) HandleDeployStackAndWait() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		endpointID, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", endpointID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		file, err := parser.GetString("file", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid file parameter", err), nil
+		}
+		if err := validateComposeYAML(file); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		stackID, err := parser.GetInt("stackId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stackId parameter", err), nil
+		}
+		if stackID != 0 {
+			if err := validatePositiveID("stackId", stackID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		name, err := parser.GetString("name", stackID == 0)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if stackID == 0 {
+			if err := validateName(name); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		env, err := parser.GetKeyValueMap("env", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid env parameter", err), nil
+		}
+
+		pullImage, err := parser.GetBoolean("pullImage", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid pullImage parameter", err), nil
+		}
+
+		prune, err := parser.GetBoolean("prune", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid prune parameter", err), nil
+		}
+
+		timeoutSeconds, err := parser.GetInt("timeoutSeconds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid timeoutSeconds parameter", err), nil
+		}
+		timeout, err := parseWaitTimeout(timeoutSeconds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+		if plan {
+			if stackID != 0 {
+				current, err := s.cli.InspectStack(stackID)
+				if err != nil {
+					return mcp.NewToolResultErrorFromErr("failed to inspect stack", err), nil
+				}
+				name = current.Name
+			}
+			steps := deployStackAndWaitPlan(endpointID, stackID, name, len(env), pullImage, prune, timeout)
+			return s.previewPlan(ctx, request, steps, nil, s.HandleDeployStackAndWait())
+		}
+
+		var stack models.RegularStack
+		if stackID == 0 {
+			stack, err = s.cli.CreateRegularStack(endpointID, name, file, env)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to create stack", err), nil
+			}
+		} else {
+			s.captureStackBaseline(stackhistory.KindRegular, stackID)
+			stack, err = s.cli.UpdateRegularStack(stackID, endpointID, file, env, pullImage, prune)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to update stack", err), nil
+			}
+		}
+		s.recordStackFile(stackhistory.KindRegular, stack.ID, ToolDeployStackAndWait, file)
+
+		containers, err := s.waitForStackContainers(ctx, endpointID, stack.Name, timeout)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("stack %d deployed but waiting for its containers failed", stack.ID), err), nil
+		}
+
+		return jsonResult(deployStackAndWaitReport{Stack: stack, stackContainersReport: containers}, "failed to marshal deploy report")
+	}
+}
+
+// deployStackAndWaitPlan returns the Portainer API calls made by HandleDeployStackAndWait.
+func deployStackAndWaitPlan(endpointID, stackID int, name string, envCount int, pullImage, prune bool, timeout time.Duration) []planStep {
+	var steps []planStep
+	if stackID == 0 {
+		steps = append(steps, planStep{
+			Method:      http.MethodPost,
+			Path:        fmt.Sprintf("/api/stacks/create/standalone/string?endpointId=%d", endpointID),
+			Description: fmt.Sprintf("Create stack %q from the Compose file with %d environment variables", name, envCount),
+		})
+	} else {
+		steps = append(steps, planStep{
+			Method:      http.MethodPut,
+			Path:        fmt.Sprintf("/api/stacks/%d?endpointId=%d", stackID, endpointID),
+			Description: fmt.Sprintf("Replace the Compose file of stack %q and its environment with %d variables (pullImage=%t, prune=%t)", name, envCount, pullImage, prune),
+		})
+	}
+
+	return append(steps,
+		planStep{
+			Method:      http.MethodGet,
+			Path:        fmt.Sprintf("/api/endpoints/%d/docker/containers/json?all=1&filters={\"label\":[\"%s\"]}", endpointID, composeProjectLabel(name)),
+			Description: fmt.Sprintf("Poll the stack containers until they are running and healthy, for at most %s", timeout),
+		},
+		planStep{
+			Method:      http.MethodGet,
+			Path:        fmt.Sprintf("/api/endpoints/%d/docker/containers/{id}/logs?tail=%d", endpointID, failingContainerLogTail),
+			Description: "Read the logs of each container that is not ready when the wait ends",
+		},
+	)
+}
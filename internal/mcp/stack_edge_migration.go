@@ -0,0 +1,197 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/stackhistory"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Outcomes of the regular stack migrated to an edge stack.
+const (
+	migratedStackKept    = "kept"
+	migratedStackRemoved = "removed"
+)
+
+// edgeStackMigration is the result of HandleMigrateRegularStackToEdge.
+type edgeStackMigration struct {
+	StackID             int    `json:"stack_id"`
+	StackName           string `json:"stack_name"`
+	EnvironmentID       int    `json:"environment_id"`
+	EdgeStackID         int    `json:"edge_stack_id"`
+	EdgeStackName       string `json:"edge_stack_name"`
+	EnvironmentGroupIDs []int  `json:"environment_group_ids"`
+	// EnvVars is the number of environment variables of the stack copied to the edge stack.
+	EnvVars int `json:"env_vars"`
+	// Rollout is the rollout of the edge stack, waited for before removing the stack.
+	Rollout *edgeStackRolloutReport `json:"rollout,omitempty"`
+	// Stack is what happened to the regular stack: kept or removed.
+	Stack        string   `json:"stack"`
+	JournalEntry int      `json:"journal_entry,omitempty"`
+	Notes        []string `json:"notes,omitempty"`
+}
+
+// HandleMigrateRegularStackToEdge returns an MCP tool handler that creates an edge stack
+// from the Compose file and environment variables of a regular stack, deployed to the
+// given edge groups. With removeOriginal, it waits until every target environment runs the
+// edge stack and then deletes the regular stack, keeping its volumes; the regular stack is
+// kept when the rollout fails or times out.
+func (s *PortainerMCPServer) HandleMigrateRegularStackToEdge() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("stackId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stackId parameter", err), nil
+		}
+		if err := validatePositiveID("stackId", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		groupIDs, err := parser.GetArrayOfIntegers("environmentGroupIds", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentGroupIds parameter", err), nil
+		}
+		if len(groupIDs) == 0 {
+			return mcp.NewToolResultError("environmentGroupIds must contain at least one edge group ID"), nil
+		}
+		for _, groupID := range groupIDs {
+			if err := validatePositiveID("environmentGroupIds", groupID); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+		if _, ok := request.GetArguments()["name"]; ok {
+			if err := validateName(name); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		removeOriginal, err := parser.GetBoolean("removeOriginal", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid removeOriginal parameter", err), nil
+		}
+
+		timeoutSeconds, err := parser.GetInt("timeoutSeconds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid timeoutSeconds parameter", err), nil
+		}
+		timeout, err := parseWaitTimeout(timeoutSeconds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+
+		stack, err := s.cli.InspectStack(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack", err), nil
+		}
+		if stack.Type != regularStackTypeSwarm && stack.Type != regularStackTypeCompose {
+			return mcp.NewToolResultError(fmt.Sprintf("stack %d is not a Docker stack (type %d)", stack.ID, stack.Type)), nil
+		}
+		if name == "" {
+			name = stack.Name
+		}
+
+		file, err := s.cli.InspectStackFile(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack file", err), nil
+		}
+		env, err := s.cli.GetRegularStackEnv(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack environment variables", err), nil
+		}
+
+		migration := edgeStackMigration{
+			StackID:             stack.ID,
+			StackName:           stack.Name,
+			EnvironmentID:       stack.EndpointID,
+			EdgeStackName:       name,
+			EnvironmentGroupIDs: groupIDs,
+			EnvVars:             len(env),
+			Stack:               migratedStackKept,
+		}
+		if stack.Git != nil {
+			migration.Notes = append(migration.Notes, fmt.Sprintf("the stack was deployed from git repository %s; the edge stack is created from its last deployed file, without git updates", stack.Git.URL))
+		}
+		if !removeOriginal {
+			migration.Notes = append(migration.Notes, "the regular stack keeps running: services publishing the same ports or container names fail on an environment running both stacks")
+		}
+
+		if plan {
+			return s.previewPlan(ctx, request, migrateRegularStackToEdgePlan(migration, removeOriginal, timeout), migration.Notes, s.HandleMigrateRegularStackToEdge())
+		}
+
+		migration.EdgeStackID, err = s.cli.CreateStackWithEnv(name, file, groupIDs, env)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to create edge stack", err), nil
+		}
+		s.recordStackFile(stackhistory.KindEdge, migration.EdgeStackID, ToolMigrateRegularStackToEdge, file)
+		if !removeOriginal {
+			return jsonResult(migration, "failed to marshal stack migration")
+		}
+
+		start := time.Now()
+		status, rollout, err := s.waitForEdgeStackRollout(ctx, migration.EdgeStackID, 0, timeout)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("edge stack %d created but waiting for its rollout failed; stack %d was kept", migration.EdgeStackID, stack.ID), err), nil
+		}
+		report := s.newEdgeStackRolloutReport(migration.EdgeStackID, status, rollout, start)
+		migration.Rollout = &report
+		if rollout.outcome != waitOutcomeMet {
+			migration.Notes = append(migration.Notes, fmt.Sprintf("the rollout of the edge stack ended with outcome %s: the regular stack was kept", rollout.outcome))
+			return jsonResult(migration, "failed to marshal stack migration")
+		}
+
+		entry := s.captureDeletion(journal.KindStack, stack.ID, func() (journal.Entry, error) {
+			return s.captureStack(stack.ID, stack.EndpointID, false)
+		})
+		if err := s.cli.DeleteStack(stack.ID, stack.EndpointID, false); err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("edge stack %d deployed but failed to delete stack %d", migration.EdgeStackID, stack.ID), err), nil
+		}
+		migration.Stack = migratedStackRemoved
+		migration.JournalEntry = s.recordCascadedDeletion(ToolDeleteStack, entry)
+
+		return jsonResult(migration, "failed to marshal stack migration")
+	}
+}
+
+// migrateRegularStackToEdgePlan returns the Portainer API calls made by
+// HandleMigrateRegularStackToEdge.
+func migrateRegularStackToEdgePlan(migration edgeStackMigration, removeOriginal bool, timeout time.Duration) []planStep {
+	steps := []planStep{{
+		Method:      http.MethodPost,
+		Path:        "/api/edge_stacks/create/string",
+		Description: fmt.Sprintf("Create edge stack %q from the Compose file of stack %q with %d environment variables, deployed to edge groups %v", migration.EdgeStackName, migration.StackName, migration.EnvVars, migration.EnvironmentGroupIDs),
+	}}
+	if !removeOriginal {
+		return steps
+	}
+
+	return append(steps,
+		planStep{
+			Method:      http.MethodGet,
+			Path:        "/api/edge_stacks/{id}",
+			Description: fmt.Sprintf("Poll the edge stack status until every target environment runs it, for at most %s", timeout),
+		},
+		planStep{
+			Method:      http.MethodDelete,
+			Path:        fmt.Sprintf("/api/stacks/%d?endpointId=%d", migration.StackID, migration.EnvironmentID),
+			Description: fmt.Sprintf("Delete stack %q, keeping its volumes, once the rollout is complete", migration.StackName),
+		},
+	)
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// edgeMigrationTestClient returns a client with a Compose stack 5 on environment 2, with
+// two environment variables.
+func edgeMigrationTestClient() *MockPortainerClient {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Name: "shop", Type: regularStackTypeCompose, EndpointID: 2}, nil)
+	mockClient.On("InspectStackFile", 5).Return("services: {}", nil)
+	mockClient.On("GetRegularStackEnv", 5).Return(map[string]string{"TAG": "1.2", "PORT": "80"}, nil)
+	return mockClient
+}
+
+// edgeMigrationCall calls HandleMigrateRegularStackToEdge for stack 5 and edge group 4.
+func edgeMigrationCall(t *testing.T, mockClient *MockPortainerClient, params map[string]any) (*mcp.CallToolResult, string) {
+	t.Helper()
+	args := map[string]any{"stackId": float64(5), "environmentGroupIds": []any{float64(4)}}
+	for k, v := range params {
+		args[k] = v
+	}
+	s := &PortainerMCPServer{cli: mockClient, pollInterval: time.Millisecond}
+	result, err := s.HandleMigrateRegularStackToEdge()(context.Background(), CreateMCPRequest(args))
+	require.NoError(t, err)
+	return result, result.Content[0].(mcp.TextContent).Text
+}
+
+// TestHandleMigrateRegularStackToEdge verifies the creation of the edge stack and the
+// removal of the regular stack depending on the rollout.
+func TestHandleMigrateRegularStackToEdge(t *testing.T) {
+	env := map[string]string{"TAG": "1.2", "PORT": "80"}
+
+	t.Run("keeps the stack", func(t *testing.T) {
+		mockClient := edgeMigrationTestClient()
+		mockClient.On("CreateStackWithEnv", "shop-edge", "services: {}", []int{4}, env).Return(9, nil)
+
+		result, text := edgeMigrationCall(t, mockClient, map[string]any{"name": "shop-edge"})
+		require.False(t, result.IsError, text)
+		var migration edgeStackMigration
+		require.NoError(t, json.Unmarshal([]byte(text), &migration))
+		assert.Equal(t, 9, migration.EdgeStackID)
+		assert.Equal(t, "shop-edge", migration.EdgeStackName)
+		assert.Equal(t, 2, migration.EnvVars)
+		assert.Equal(t, migratedStackKept, migration.Stack)
+		assert.Nil(t, migration.Rollout)
+		assert.NotEmpty(t, migration.Notes)
+		mockClient.AssertNotCalled(t, "GetEdgeStackStatus", 9)
+	})
+
+	t.Run("removes the stack after the rollout", func(t *testing.T) {
+		mockClient := edgeMigrationTestClient()
+		mockClient.On("CreateStackWithEnv", "shop", "services: {}", []int{4}, env).Return(9, nil)
+		mockClient.On("GetEdgeStackStatus", 9).Return(models.EdgeStackStatus{ID: 9, Name: "shop", Environments: []models.EdgeStackEnvironmentStatus{
+			{EnvironmentID: 7, Status: models.EdgeStackStatusRunning},
+		}}, nil)
+		mockClient.On("DeleteStack", 5, 2, false).Return(nil)
+
+		result, text := edgeMigrationCall(t, mockClient, map[string]any{"removeOriginal": true})
+		require.False(t, result.IsError, text)
+		var migration edgeStackMigration
+		require.NoError(t, json.Unmarshal([]byte(text), &migration))
+		assert.Equal(t, migratedStackRemoved, migration.Stack)
+		require.NotNil(t, migration.Rollout)
+		assert.Equal(t, waitOutcomeMet, migration.Rollout.Outcome)
+		mockClient.AssertCalled(t, "DeleteStack", 5, 2, false)
+	})
+
+	t.Run("keeps the stack when the rollout fails", func(t *testing.T) {
+		mockClient := edgeMigrationTestClient()
+		mockClient.On("CreateStackWithEnv", "shop", "services: {}", []int{4}, env).Return(9, nil)
+		mockClient.On("GetEdgeStackStatus", 9).Return(models.EdgeStackStatus{ID: 9, Environments: []models.EdgeStackEnvironmentStatus{
+			{EnvironmentID: 7, Status: models.EdgeStackStatusError, Error: "port is already allocated"},
+		}}, nil)
+		mockClient.On("GetEnvironment", 7).Return(models.Environment{ID: 7, Name: "edge-1"}, nil)
+
+		result, text := edgeMigrationCall(t, mockClient, map[string]any{"removeOriginal": true})
+		require.False(t, result.IsError, text)
+		var migration edgeStackMigration
+		require.NoError(t, json.Unmarshal([]byte(text), &migration))
+		assert.Equal(t, migratedStackKept, migration.Stack)
+		assert.Equal(t, waitOutcomeFailed, migration.Rollout.Outcome)
+		assert.Equal(t, "edge-1", migration.Rollout.Failed[0].EnvironmentName)
+		mockClient.AssertNotCalled(t, "DeleteStack", 5, 2, false)
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		mockClient := edgeMigrationTestClient()
+		mockClient.On("InspectStack", 5).Unset()
+		mockClient.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Name: "shop", Type: regularStackTypeSwarm, EndpointID: 2, Git: &models.StackGitConfig{URL: "https://git.example.com/shop.git"}}, nil)
+
+		result, text := edgeMigrationCall(t, mockClient, map[string]any{"removeOriginal": true, "plan": true})
+		require.False(t, result.IsError, text)
+		var plan executionPlan
+		require.NoError(t, json.Unmarshal([]byte(text), &plan))
+		require.Len(t, plan.Steps, 3)
+		assert.Equal(t, "/api/edge_stacks/create/string", plan.Steps[0].Path)
+		assert.Equal(t, planStep{Method: "DELETE", Path: "/api/stacks/5?endpointId=2", Description: `Delete stack "shop", keeping its volumes, once the rollout is complete`}, plan.Steps[2])
+		assert.Contains(t, plan.Notes[0], "https://git.example.com/shop.git")
+		mockClient.AssertNotCalled(t, "CreateStackWithEnv", "shop", "services: {}", []int{4}, env)
+	})
+}
+
+// TestHandleMigrateRegularStackToEdgeErrors verifies the calls that cannot be migrated.
+func TestHandleMigrateRegularStackToEdgeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]any
+		setup  func(*MockPortainerClient)
+		want   string
+	}{
+		{
+			name:   "no edge group",
+			params: map[string]any{"environmentGroupIds": []any{}},
+			setup:  func(m *MockPortainerClient) {},
+			want:   "environmentGroupIds must contain at least one edge group ID",
+		},
+		{
+			name:   "blank name",
+			params: map[string]any{"name": " "},
+			setup:  func(m *MockPortainerClient) {},
+			want:   "name cannot be empty",
+		},
+		{
+			name: "kubernetes stack",
+			setup: func(m *MockPortainerClient) {
+				m.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Type: 3}, nil)
+			},
+			want: "stack 5 is not a Docker stack (type 3)",
+		},
+		{
+			name: "create failure",
+			setup: func(m *MockPortainerClient) {
+				m.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Name: "shop", Type: regularStackTypeCompose, EndpointID: 2}, nil)
+				m.On("InspectStackFile", 5).Return("services: {}", nil)
+				m.On("GetRegularStackEnv", 5).Return(nil, nil)
+				m.On("CreateStackWithEnv", "shop", "services: {}", []int{4}, map[string]string(nil)).Return(0, fmt.Errorf("name already used"))
+			},
+			want: "failed to create edge stack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setup(mockClient)
+			result, text := edgeMigrationCall(t, mockClient, tt.params)
+			assert.True(t, result.IsError)
+			assert.Contains(t, text, tt.want)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/stackhistory"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// stackHistoryURITemplate lists the captured versions of a stack file.
+	stackHistoryURITemplate = "portainer://stacks/{kind}/{id}/history"
+	// stackHistoryVersionURITemplate returns the content of one captured version.
+	stackHistoryVersionURITemplate = "portainer://stacks/{kind}/{id}/history/{version}"
+)
+
+// captureStackBaseline records the current file of a stack before the server modifies it,
+// so the history also contains the version that was deployed before the first update made
+// through the MCP server. It does nothing when history is disabled or already started.
+func (s *PortainerMCPServer) captureStackBaseline(kind string, id int) {
+	if s.stackHistory == nil {
+		return
+	}
+
+	has, err := s.stackHistory.Has(kind, id)
+	if err != nil || has {
+		return
+	}
+
+	var content string
+	if kind == stackhistory.KindEdge {
+		content, err = s.cli.GetStackFile(id)
+	} else {
+		content, err = s.cli.InspectStackFile(id)
+	}
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Int("stack", id).Msg("failed to capture stack file baseline")
+		return
+	}
+
+	s.recordStackFile(kind, id, "baseline", content)
+}
+
+// recordStackFile records a stack file version in the history. Failures are logged and
+// never fail the calling tool, as the stack operation itself already succeeded.
+func (s *PortainerMCPServer) recordStackFile(kind string, id int, source, content string) {
+	if s.stackHistory == nil {
+		return
+	}
+
+	if _, err := s.stackHistory.Record(kind, id, source, content); err != nil {
+		log.Warn().Err(err).Str("kind", kind).Int("stack", id).Msg("failed to record stack file history")
+	}
+}
+
+// recordDeployedStackFile records the file Portainer deployed for a regular stack, for
+// operations such as git redeploys where the content is not known in advance.
+func (s *PortainerMCPServer) recordDeployedStackFile(id int, source string) {
+	if s.stackHistory == nil {
+		return
+	}
+
+	content, err := s.cli.InspectStackFile(id)
+	if err != nil {
+		log.Warn().Err(err).Int("stack", id).Msg("failed to capture deployed stack file")
+		return
+	}
+
+	s.recordStackFile(stackhistory.KindRegular, id, source, content)
+}
+
+// HandleGetStackFileHistory returns an MCP tool handler that lists the stack file versions
+// captured by the server, or returns the content of a single version.
+func (s *PortainerMCPServer) HandleGetStackFileHistory() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if s.stackHistory == nil {
+			return mcp.NewToolResultError("stack file history is disabled (start the server with -stack-history-size greater than 0)"), nil
+		}
+
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		kind, err := parser.GetString("kind", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid kind parameter", err), nil
+		}
+
+		version, err := parser.GetInt("version", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid version parameter", err), nil
+		}
+
+		if version == 0 {
+			versions, err := s.stackHistory.List(kind, id)
+			if err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get stack file history", err), nil
+			}
+			return jsonResult(versions, "failed to marshal stack file history")
+		}
+
+		v, found, err := s.stackHistory.Get(kind, id, version)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack file version", err), nil
+		}
+		if !found {
+			return mcp.NewToolResultError(fmt.Sprintf("version %d of %s stack %d is not in the history", version, kind, id)), nil
+		}
+
+		return jsonResult(v, "failed to marshal stack file version")
+	}
+}
+
+// AddStackHistoryResources registers the stack file history as MCP resource templates.
+// It does nothing when history is disabled.
+func (s *PortainerMCPServer) AddStackHistoryResources() {
+	for _, p := range s.principals {
+		p.server.AddStackHistoryResources()
+	}
+
+	if s.stackHistory == nil {
+		return
+	}
+
+	s.srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(stackHistoryURITemplate, "Stack file history",
+			mcp.WithTemplateDescription("Versions of a stack file captured by this server when it created or updated the stack. kind is 'edge' or 'regular'."),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		s.HandleReadStackHistory(),
+	)
+	s.srv.AddResourceTemplate(
+		mcp.NewResourceTemplate(stackHistoryVersionURITemplate, "Stack file version",
+			mcp.WithTemplateDescription("Content of one captured version of a stack file. kind is 'edge' or 'regular'."),
+			mcp.WithTemplateMIMEType("application/yaml"),
+		),
+		s.HandleReadStackHistoryVersion(),
+	)
+}
+
+// HandleReadStackHistory returns an MCP resource handler listing the captured versions of a stack file.
+func (s *PortainerMCPServer) HandleReadStackHistory() server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		kind := resourceArgument(request, "kind")
+		id, err := strconv.Atoi(resourceArgument(request, "id"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid stack id in %s", request.Params.URI)
+		}
+
+		versions, err := s.stackHistory.List(kind, id)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(versions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal stack file history: %w", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "application/json", Text: string(data)},
+		}, nil
+	}
+}
+
+// HandleReadStackHistoryVersion returns an MCP resource handler returning the content of
+// one captured version of a stack file.
+func (s *PortainerMCPServer) HandleReadStackHistoryVersion() server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		kind := resourceArgument(request, "kind")
+		id, err := strconv.Atoi(resourceArgument(request, "id"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid stack id in %s", request.Params.URI)
+		}
+		version, err := strconv.Atoi(resourceArgument(request, "version"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version in %s", request.Params.URI)
+		}
+
+		v, found, err := s.stackHistory.Get(kind, id, version)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("version %d of %s stack %d is not in the history", version, kind, id)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: request.Params.URI, MIMEType: "application/yaml", Text: v.Content},
+		}, nil
+	}
+}
+
+// resourceArgument returns a variable matched from a resource URI template.
+// mcp-go passes template variables as string slices.
+func resourceArgument(request mcp.ReadResourceRequest, name string) string {
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/stackhistory"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHistoryServer returns a server with an in-memory stack history.
+func newHistoryServer(t *testing.T, cli *MockPortainerClient) *PortainerMCPServer {
+	t.Helper()
+	store, err := stackhistory.New("", 10)
+	require.NoError(t, err)
+	return &PortainerMCPServer{cli: cli, stackHistory: store}
+}
+
+// TestStackUpdateRecordsHistory verifies that updating an edge stack captures the
+// previous file as a baseline and the new file as the next version.
+func TestStackUpdateRecordsHistory(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStackFile", 3).Return("services:\n  web:\n    image: nginx:1.25\n", nil).Once()
+	mockClient.On("UpdateStack", 3, "services:\n  web:\n    image: nginx:1.27\n", []int{1}).Return(nil)
+
+	server := newHistoryServer(t, mockClient)
+	update := func(file string) {
+		result, err := server.HandleUpdateStack()(context.Background(), CreateMCPRequest(map[string]any{
+			"id": float64(3), "file": file, "environmentGroupIds": []any{float64(1)},
+		}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+	}
+	update("services:\n  web:\n    image: nginx:1.27\n")
+	update("services:\n  web:\n    image: nginx:1.27\n")
+
+	versions, err := server.stackHistory.List(stackhistory.KindEdge, 3)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "baseline", versions[0].Source)
+	assert.Equal(t, ToolUpdateStack, versions[1].Source)
+	mockClient.AssertExpectations(t)
+}
+
+// TestStackUpdateBaselineFailure verifies that a failed baseline capture does not fail the update.
+func TestStackUpdateBaselineFailure(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("InspectStackFile", 5).Return("", fmt.Errorf("not found")).Once()
+	mockClient.On("RedeployStackGit", 5, 1, false, false).Return(models.RegularStack{ID: 5, Name: "app", EndpointID: 1}, nil)
+	mockClient.On("InspectStackFile", 5).Return("services: {}", nil)
+
+	server := newHistoryServer(t, mockClient)
+	result, err := server.HandleRedeployStackGit()(context.Background(), CreateMCPRequest(map[string]any{
+		"id": float64(5), "environmentId": float64(1),
+	}))
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	versions, err := server.stackHistory.List(stackhistory.KindRegular, 5)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	assert.Equal(t, ToolRedeployStackGit, versions[0].Source)
+}
+
+// TestHandleGetStackFileHistory verifies listing and retrieving captured versions.
+func TestHandleGetStackFileHistory(t *testing.T) {
+	server := newHistoryServer(t, &MockPortainerClient{})
+	_, err := server.stackHistory.Record(stackhistory.KindRegular, 2, "baseline", "v1")
+	require.NoError(t, err)
+	_, err = server.stackHistory.Record(stackhistory.KindRegular, 2, ToolDeployStackAndWait, "v2")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		params      map[string]any
+		expectError bool
+		check       func(t *testing.T, text string)
+	}{
+		{
+			name:   "list versions",
+			params: map[string]any{"id": float64(2), "kind": "regular"},
+			check: func(t *testing.T, text string) {
+				var versions []stackhistory.Version
+				require.NoError(t, json.Unmarshal([]byte(text), &versions))
+				require.Len(t, versions, 2)
+				assert.Empty(t, versions[1].Content)
+			},
+		},
+		{
+			name:   "get version",
+			params: map[string]any{"id": float64(2), "kind": "regular", "version": float64(2)},
+			check: func(t *testing.T, text string) {
+				var v stackhistory.Version
+				require.NoError(t, json.Unmarshal([]byte(text), &v))
+				assert.Equal(t, "v2", v.Content)
+			},
+		},
+		{
+			name:   "empty history",
+			params: map[string]any{"id": float64(2), "kind": "edge"},
+			check: func(t *testing.T, text string) {
+				assert.Equal(t, "[]", text)
+			},
+		},
+		{
+			name:        "unknown version",
+			params:      map[string]any{"id": float64(2), "kind": "regular", "version": float64(9)},
+			expectError: true,
+		},
+		{
+			name:        "invalid kind",
+			params:      map[string]any{"id": float64(2), "kind": "swarm"},
+			expectError: true,
+		},
+		{
+			name:        "missing id",
+			params:      map[string]any{"kind": "regular"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.HandleGetStackFileHistory()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+			require.False(t, result.IsError)
+			tt.check(t, result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+
+	t.Run("history disabled", func(t *testing.T) {
+		disabled := &PortainerMCPServer{cli: &MockPortainerClient{}}
+		result, err := disabled.HandleGetStackFileHistory()(context.Background(), CreateMCPRequest(map[string]any{"id": float64(2), "kind": "regular"}))
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+	})
+}
+
+// TestStackHistoryResources verifies the stack history resource handlers.
+func TestStackHistoryResources(t *testing.T) {
+	server := newHistoryServer(t, &MockPortainerClient{})
+	_, err := server.stackHistory.Record(stackhistory.KindEdge, 7, ToolCreateStack, "services: {}")
+	require.NoError(t, err)
+
+	readRequest := func(uri string, args map[string]any) mcp.ReadResourceRequest {
+		var req mcp.ReadResourceRequest
+		req.Params.URI = uri
+		req.Params.Arguments = args
+		return req
+	}
+
+	contents, err := server.HandleReadStackHistory()(context.Background(), readRequest(
+		"portainer://stacks/edge/7/history",
+		map[string]any{"kind": []string{"edge"}, "id": []string{"7"}},
+	))
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	text := contents[0].(mcp.TextResourceContents)
+	assert.Equal(t, "application/json", text.MIMEType)
+	assert.Contains(t, text.Text, `"version":1`)
+
+	contents, err = server.HandleReadStackHistoryVersion()(context.Background(), readRequest(
+		"portainer://stacks/edge/7/history/1",
+		map[string]any{"kind": []string{"edge"}, "id": []string{"7"}, "version": []string{"1"}},
+	))
+	require.NoError(t, err)
+	require.Len(t, contents, 1)
+	assert.Equal(t, "services: {}", contents[0].(mcp.TextResourceContents).Text)
+
+	_, err = server.HandleReadStackHistoryVersion()(context.Background(), readRequest(
+		"portainer://stacks/edge/7/history/2",
+		map[string]any{"kind": []string{"edge"}, "id": []string{"7"}, "version": []string{"2"}},
+	))
+	assert.Error(t, err)
+
+	_, err = server.HandleReadStackHistory()(context.Background(), readRequest(
+		"portainer://stacks/edge/x/history",
+		map[string]any{"kind": []string{"edge"}, "id": []string{"x"}},
+	))
+	assert.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
+)
+
+// Pinning statuses of the image of a Compose service.
+const (
+	// imagePinningDigest is an image pinned to a manifest digest.
+	imagePinningDigest = "pinned"
+	// imagePinningLatest is an image using the latest tag, explicitly or by omitting the tag.
+	imagePinningLatest = "latest"
+	// imagePinningTag is an image using another tag, without a digest.
+	imagePinningTag = "tag_only"
+	// imagePinningUnresolved is an image reference that cannot be parsed, such as one that
+	// uses variable interpolation.
+	imagePinningUnresolved = "unresolved"
+)
+
+// serviceImagePinning is the pinning status of the image of a Compose service, with the
+// digest-pinned reference suggested to replace it.
+type serviceImagePinning struct {
+	Service string `json:"service"`
+	Image   string `json:"image"`
+	Status  string `json:"status"`
+	// Digest is the manifest digest the tag of the image currently points to in its registry.
+	Digest string `json:"digest,omitempty"`
+	// Suggestion is the image reference pinned to Digest.
+	Suggestion string `json:"suggestion,omitempty"`
+	Note       string `json:"note,omitempty"`
+	Error      string `json:"error,omitempty"`
+
+	// tagged is the normalized tagged reference queried in the registry.
+	tagged string
+}
+
+// stackImageAnalysis is the result of HandleAnalyzeStackImages.
+type stackImageAnalysis struct {
+	StackID       int                   `json:"stack_id"`
+	StackName     string                `json:"stack_name"`
+	EnvironmentID int                   `json:"environment_id"`
+	Pinned        int                   `json:"pinned"`
+	Unpinned      int                   `json:"unpinned"`
+	Unresolved    int                   `json:"unresolved"`
+	Services      []serviceImagePinning `json:"services"`
+}
+
+// HandleAnalyzeStackImages returns an MCP tool handler that flags the images of the Compose
+// file of a regular stack that use the latest tag or no digest, and suggests the references
+// pinned to the digests their tags currently point to. The Docker engine of the environment
+// of the stack queries the registries without pulling anything; every tag is queried once.
+func (s *PortainerMCPServer) HandleAnalyzeStackImages() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("stackId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stackId parameter", err), nil
+		}
+		if err := validatePositiveID("stackId", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		stack, err := s.cli.InspectStack(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack", err), nil
+		}
+		if stack.Type != regularStackTypeSwarm && stack.Type != regularStackTypeCompose {
+			return mcp.NewToolResultError(fmt.Sprintf("stack %d is not a Docker stack (type %d)", stack.ID, stack.Type)), nil
+		}
+
+		file, err := s.cli.InspectStackFile(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get stack file", err), nil
+		}
+		services, err := composeServiceImages(file)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to parse stack file", err), nil
+		}
+
+		analysis := stackImageAnalysis{StackID: stack.ID, StackName: stack.Name, EnvironmentID: stack.EndpointID, Services: services}
+		var tags []string
+		for i := range analysis.Services {
+			classifyImagePinning(&analysis.Services[i])
+			if tagged := analysis.Services[i].tagged; tagged != "" && !slices.Contains(tags, tagged) {
+				tags = append(tags, tagged)
+			}
+		}
+
+		digests := make([]string, len(tags))
+		errs := make([]error, len(tags))
+		runConcurrently(ctx, len(tags), func(i int) {
+			digests[i], errs[i] = s.cli.GetDockerDistributionDigest(stack.EndpointID, tags[i])
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("image analysis interrupted", err), nil
+		}
+
+		for i := range analysis.Services {
+			service := &analysis.Services[i]
+			switch service.Status {
+			case imagePinningDigest:
+				analysis.Pinned++
+				continue
+			case imagePinningUnresolved:
+				analysis.Unresolved++
+				continue
+			}
+
+			analysis.Unpinned++
+			idx := slices.Index(tags, service.tagged)
+			if errs[idx] != nil {
+				service.Error = errs[idx].Error()
+				continue
+			}
+			named, _ := reference.ParseNormalizedNamed(service.tagged)
+			service.Digest = digests[idx]
+			service.Suggestion = reference.FamiliarString(named) + "@" + digests[idx]
+		}
+
+		return jsonResult(analysis, "failed to marshal stack image analysis")
+	}
+}
+
+// classifyImagePinning sets the pinning status of the image of a service, and the tagged
+// reference to query in the registry for the images that are not pinned.
+func classifyImagePinning(service *serviceImagePinning) {
+	named, err := reference.ParseNormalizedNamed(service.Image)
+	if err != nil {
+		service.Status = imagePinningUnresolved
+		if strings.Contains(service.Image, "$") {
+			service.Error = "the image uses variable interpolation"
+		} else {
+			service.Error = "invalid image reference: " + err.Error()
+		}
+		return
+	}
+	if _, ok := named.(reference.Digested); ok {
+		service.Status = imagePinningDigest
+		return
+	}
+
+	tagged := reference.TagNameOnly(named).(reference.NamedTagged)
+	service.tagged = tagged.String()
+	if tagged.Tag() == "latest" {
+		service.Status = imagePinningLatest
+		service.Note = "the latest tag moves with every release: pin a version tag as well as its digest"
+		return
+	}
+	service.Status = imagePinningTag
+}
+
+// composeServiceImages returns the services of a Compose file that set an image, sorted by
+// name. Services built from source without an image are skipped.
+func composeServiceImages(content string) ([]serviceImagePinning, error) {
+	var compose struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &compose); err != nil {
+		return nil, fmt.Errorf("invalid compose file: %w", err)
+	}
+
+	services := []serviceImagePinning{}
+	for name, service := range compose.Services {
+		if service.Image != "" {
+			services = append(services, serviceImagePinning{Service: name, Image: service.Image})
+		}
+	}
+	slices.SortFunc(services, func(a, b serviceImagePinning) int { return strings.Compare(a.Service, b.Service) })
+	return services, nil
+}
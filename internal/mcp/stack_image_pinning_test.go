@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleAnalyzeStackImages verifies the pinning status of the images of a stack and the
+// suggested pinned references.
+func TestHandleAnalyzeStackImages(t *testing.T) {
+	const file = `services:
+  web:
+    image: nginx
+  api:
+    image: ghcr.io/acme/api:1.2
+  worker:
+    image: ghcr.io/acme/api:1.2
+  db:
+    image: postgres:16@sha256:0000000000000000000000000000000000000000000000000000000000000001
+  app:
+    image: ${REGISTRY}/app:${TAG}
+  private:
+    image: registry.acme.io/tool:2
+  builder:
+    build: .
+`
+	mockClient := &MockPortainerClient{}
+	mockClient.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Name: "shop", Type: regularStackTypeCompose, EndpointID: 2}, nil)
+	mockClient.On("InspectStackFile", 5).Return(file, nil)
+	mockClient.On("GetDockerDistributionDigest", 2, "docker.io/library/nginx:latest").Return("sha256:aaa", nil)
+	mockClient.On("GetDockerDistributionDigest", 2, "ghcr.io/acme/api:1.2").Return("sha256:bbb", nil)
+	mockClient.On("GetDockerDistributionDigest", 2, "registry.acme.io/tool:2").Return("", fmt.Errorf("unauthorized"))
+
+	s := &PortainerMCPServer{cli: mockClient}
+	result, err := s.HandleAnalyzeStackImages()(context.Background(), CreateMCPRequest(map[string]any{"stackId": float64(5)}))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	require.False(t, result.IsError, text)
+
+	var analysis stackImageAnalysis
+	require.NoError(t, json.Unmarshal([]byte(text), &analysis))
+	assert.Equal(t, 1, analysis.Pinned)
+	assert.Equal(t, 4, analysis.Unpinned)
+	assert.Equal(t, 1, analysis.Unresolved)
+
+	services := map[string]serviceImagePinning{}
+	for _, service := range analysis.Services {
+		services[service.Service] = service
+	}
+	assert.NotContains(t, services, "builder", "services without an image are skipped")
+
+	assert.Equal(t, imagePinningLatest, services["web"].Status)
+	assert.Equal(t, "nginx:latest@sha256:aaa", services["web"].Suggestion)
+	assert.NotEmpty(t, services["web"].Note)
+
+	assert.Equal(t, imagePinningTag, services["api"].Status)
+	assert.Equal(t, "ghcr.io/acme/api:1.2@sha256:bbb", services["api"].Suggestion)
+	assert.Equal(t, "sha256:bbb", services["worker"].Digest)
+
+	assert.Equal(t, imagePinningDigest, services["db"].Status)
+	assert.Empty(t, services["db"].Suggestion)
+
+	assert.Equal(t, imagePinningUnresolved, services["app"].Status)
+	assert.Equal(t, "the image uses variable interpolation", services["app"].Error)
+
+	assert.Equal(t, imagePinningTag, services["private"].Status)
+	assert.Contains(t, services["private"].Error, "unauthorized")
+	assert.Empty(t, services["private"].Suggestion)
+
+	mockClient.AssertNumberOfCalls(t, "GetDockerDistributionDigest", 3)
+}
+
+// TestHandleAnalyzeStackImagesErrors verifies the stacks that cannot be analyzed.
+func TestHandleAnalyzeStackImagesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*MockPortainerClient)
+		want  string
+	}{
+		{
+			name: "kubernetes stack",
+			setup: func(m *MockPortainerClient) {
+				m.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Type: 3}, nil)
+			},
+			want: "stack 5 is not a Docker stack (type 3)",
+		},
+		{
+			name: "invalid file",
+			setup: func(m *MockPortainerClient) {
+				m.On("InspectStack", 5).Return(models.RegularStack{ID: 5, Type: regularStackTypeSwarm}, nil)
+				m.On("InspectStackFile", 5).Return("services: [", nil)
+			},
+			want: "failed to parse stack file",
+		},
+		{
+			name: "stack not found",
+			setup: func(m *MockPortainerClient) {
+				m.On("InspectStack", 5).Return(models.RegularStack{}, fmt.Errorf("not found"))
+			},
+			want: "failed to get stack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			tt.setup(mockClient)
+			s := &PortainerMCPServer{cli: mockClient}
+			result, err := s.HandleAnalyzeStackImages()(context.Background(), CreateMCPRequest(map[string]any{"stackId": float64(5)}))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.want)
+		})
+	}
+}
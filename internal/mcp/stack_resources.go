@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Regular stack type values as used by the Portainer API
+const (
+	regularStackTypeSwarm   = 1
+	regularStackTypeCompose = 2
+)
+
+// Docker labels identifying the stack and the service of a container.
+const (
+	swarmStackNamespaceLabel = "com.docker.stack.namespace"
+	swarmServiceNameLabel    = "com.docker.swarm.service.name"
+	composeServiceLabel      = "com.docker.compose.service"
+)
+
+// stackResourceContainer is a container of a stack with its current resource usage.
+type stackResourceContainer struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Service string `json:"service,omitempty"`
+	Image   string `json:"image"`
+	State   string `json:"state"`
+	Status  string `json:"status"`
+	// Usage is only sampled for running containers.
+	Usage *models.DockerContainerUsage `json:"usage,omitempty"`
+	Error string                       `json:"error,omitempty"`
+}
+
+// stackResourceService is the total resource usage of the containers of a stack service.
+type stackResourceService struct {
+	Name        string  `json:"name"`
+	Containers  int     `json:"containers"`
+	Running     int     `json:"running"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemoryUsage uint64  `json:"memory_usage_bytes"`
+}
+
+// stackResourcesReport is the result of HandleGetStackResources.
+type stackResourcesReport struct {
+	StackID       int                      `json:"stack_id"`
+	StackName     string                   `json:"stack_name"`
+	EnvironmentID int                      `json:"environment_id"`
+	Type          string                   `json:"type"`
+	CPUPercent    float64                  `json:"cpu_percent"`
+	MemoryUsage   uint64                   `json:"memory_usage_bytes"`
+	Services      []stackResourceService   `json:"services"`
+	Containers    []stackResourceContainer `json:"containers"`
+}
+
+// HandleGetStackResources returns an MCP tool handler that lists the containers of a
+// regular stack, matched by their Compose project or Swarm stack label, with their state,
+// image and current CPU and memory usage, and totals per service.
+func (s *PortainerMCPServer) HandleGetStackResources() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		stackId, err := parser.GetInt("stackId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid stackId parameter", err), nil
+		}
+		if err := validatePositiveID("stackId", stackId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		environmentId, err := parser.GetInt("environmentId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if environmentId != 0 {
+			if err := validatePositiveID("environmentId", environmentId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		stack, err := s.cli.InspectStack(stackId)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to inspect stack", err), nil
+		}
+		if environmentId == 0 {
+			environmentId = stack.EndpointID
+		}
+
+		report := stackResourcesReport{
+			StackID:       stack.ID,
+			StackName:     stack.Name,
+			EnvironmentID: environmentId,
+			Services:      []stackResourceService{},
+			Containers:    []stackResourceContainer{},
+		}
+
+		var label string
+		switch stack.Type {
+		case regularStackTypeSwarm:
+			report.Type = "swarm"
+			label = swarmStackNamespaceLabel + "=" + stack.Name
+		case regularStackTypeCompose:
+			report.Type = "compose"
+			label = composeProjectLabel(stack.Name)
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("stack %d is not a Docker stack (type %d)", stack.ID, stack.Type)), nil
+		}
+
+		containers, err := s.cli.GetDockerContainers(environmentId, models.DockerContainerListOptions{All: true, Labels: []string{label}})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list stack containers", err), nil
+		}
+
+		for _, c := range containers {
+			report.Containers = append(report.Containers, stackResourceContainer{
+				ID:      c.ID,
+				Name:    c.Name,
+				Service: stackServiceName(stack.Name, c.Labels),
+				Image:   c.Image,
+				State:   c.State,
+				Status:  c.Status,
+			})
+		}
+
+		runConcurrently(ctx, len(report.Containers), func(i int) {
+			c := &report.Containers[i]
+			if c.State != "running" {
+				return
+			}
+			usage, err := s.cli.GetDockerContainerStats(environmentId, c.ID)
+			if err != nil {
+				c.Error = fmt.Sprintf("failed to get stats: %v", err)
+				return
+			}
+			usage.ContainerID, usage.Name = c.ID, c.Name
+			c.Usage = &usage
+		})
+		if err := ctx.Err(); err != nil {
+			return mcp.NewToolResultErrorFromErr("stack resource usage sampling interrupted", err), nil
+		}
+
+		services := map[string]*stackResourceService{}
+		for _, c := range report.Containers {
+			service, ok := services[c.Service]
+			if !ok {
+				service = &stackResourceService{Name: c.Service}
+				services[c.Service] = service
+			}
+			service.Containers++
+			if c.State == "running" {
+				service.Running++
+			}
+			if c.Usage != nil {
+				service.CPUPercent += c.Usage.CPUPercent
+				service.MemoryUsage += c.Usage.MemoryUsage
+				report.CPUPercent += c.Usage.CPUPercent
+				report.MemoryUsage += c.Usage.MemoryUsage
+			}
+		}
+		for _, service := range services {
+			report.Services = append(report.Services, *service)
+		}
+		sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].Name < report.Services[j].Name })
+		sort.SliceStable(report.Containers, func(i, j int) bool {
+			a, b := report.Containers[i], report.Containers[j]
+			if a.Service != b.Service {
+				return a.Service < b.Service
+			}
+			return a.Name < b.Name
+		})
+
+		return jsonResult(report, "failed to marshal stack resources")
+	}
+}
+
+// stackServiceName returns the service of a stack container from its Compose or Swarm
+// labels. Swarm service names are prefixed with the stack name, which is removed.
+func stackServiceName(stackName string, labels map[string]string) string {
+	if service, ok := labels[composeServiceLabel]; ok {
+		return service
+	}
+	if service, ok := labels[swarmServiceNameLabel]; ok {
+		return strings.TrimPrefix(service, stackName+"_")
+	}
+	return ""
+}
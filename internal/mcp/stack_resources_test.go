@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandleGetStackResources verifies the containers, usage and totals reported by the
+// getStackResources tool.
+func TestHandleGetStackResources(t *testing.T) {
+	composeStack := models.RegularStack{ID: 1, Name: "Shop", Type: regularStackTypeCompose, EndpointID: 3}
+	swarmStack := models.RegularStack{ID: 2, Name: "monitoring", Type: regularStackTypeSwarm, EndpointID: 4}
+
+	t.Run("compose stack", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("InspectStack", 1).Return(composeStack, nil)
+		mockClient.On("GetDockerContainers", 3, models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=shop"}}).Return([]models.DockerContainer{
+			{ID: "c2", Name: "shop-web-2", Image: "nginx:1", State: "running", Labels: map[string]string{composeServiceLabel: "web"}},
+			{ID: "c1", Name: "shop-web-1", Image: "nginx:1", State: "running", Labels: map[string]string{composeServiceLabel: "web"}},
+			{ID: "c3", Name: "shop-db-1", Image: "postgres:16", State: "running", Labels: map[string]string{composeServiceLabel: "db"}},
+			{ID: "c4", Name: "shop-migrate-1", Image: "shop:1", State: "exited", Status: "Exited (0)", Labels: map[string]string{composeServiceLabel: "migrate"}},
+		}, nil)
+		mockClient.On("GetDockerContainerStats", 3, "c1").Return(models.DockerContainerUsage{CPUPercent: 1.5, MemoryUsage: 100}, nil)
+		mockClient.On("GetDockerContainerStats", 3, "c2").Return(models.DockerContainerUsage{CPUPercent: 2.5, MemoryUsage: 200}, nil)
+		mockClient.On("GetDockerContainerStats", 3, "c3").Return(models.DockerContainerUsage{}, errors.New("container not found"))
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleGetStackResources()(context.Background(), CreateMCPRequest(map[string]any{"stackId": float64(1)}))
+		require.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		require.False(t, result.IsError, text)
+
+		var report stackResourcesReport
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.Equal(t, "compose", report.Type)
+		assert.Equal(t, 3, report.EnvironmentID)
+		assert.Equal(t, 4.0, report.CPUPercent)
+		assert.Equal(t, uint64(300), report.MemoryUsage)
+
+		var names []string
+		for _, c := range report.Containers {
+			names = append(names, c.Name)
+		}
+		assert.Equal(t, []string{"shop-db-1", "shop-migrate-1", "shop-web-1", "shop-web-2"}, names)
+		assert.Equal(t, "failed to get stats: container not found", report.Containers[0].Error)
+		assert.Nil(t, report.Containers[1].Usage, "stopped containers are not sampled")
+		require.NotNil(t, report.Containers[2].Usage)
+		assert.Equal(t, "c1", report.Containers[2].Usage.ContainerID)
+
+		assert.Equal(t, []stackResourceService{
+			{Name: "db", Containers: 1, Running: 1},
+			{Name: "migrate", Containers: 1},
+			{Name: "web", Containers: 2, Running: 2, CPUPercent: 4, MemoryUsage: 300},
+		}, report.Services)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("swarm stack on another environment", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("InspectStack", 2).Return(swarmStack, nil)
+		mockClient.On("GetDockerContainers", 5, models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.stack.namespace=monitoring"}}).Return([]models.DockerContainer{
+			{ID: "s1", Name: "monitoring_grafana.1.abc", Image: "grafana:11", State: "running", Labels: map[string]string{swarmServiceNameLabel: "monitoring_grafana"}},
+		}, nil)
+		mockClient.On("GetDockerContainerStats", 5, "s1").Return(models.DockerContainerUsage{MemoryUsage: 50}, nil)
+		server := &PortainerMCPServer{cli: mockClient}
+
+		result, err := server.HandleGetStackResources()(context.Background(), CreateMCPRequest(map[string]any{"stackId": float64(2), "environmentId": float64(5)}))
+		require.NoError(t, err)
+		text := result.Content[0].(mcp.TextContent).Text
+		require.False(t, result.IsError, text)
+
+		var report stackResourcesReport
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+		assert.Equal(t, "swarm", report.Type)
+		assert.Equal(t, 5, report.EnvironmentID)
+		assert.Equal(t, []stackResourceService{{Name: "grafana", Containers: 1, Running: 1, MemoryUsage: 50}}, report.Services)
+		mockClient.AssertExpectations(t)
+	})
+
+	errorTests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+	}{
+		{name: "missing stackId", args: map[string]any{}, expectError: "stackId is required"},
+		{name: "invalid environmentId", args: map[string]any{"stackId": float64(1), "environmentId": float64(-1)}, expectError: "environmentId"},
+		{
+			name: "kubernetes stack",
+			args: map[string]any{"stackId": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 3).Return(models.RegularStack{ID: 3, Name: "k8s", Type: 3}, nil)
+			},
+			expectError: "not a Docker stack",
+		},
+		{
+			name: "inspect error",
+			args: map[string]any{"stackId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 1).Return(models.RegularStack{}, errors.New("not found"))
+			},
+			expectError: "failed to inspect stack",
+		},
+		{
+			name: "containers error",
+			args: map[string]any{"stackId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 1).Return(composeStack, nil)
+				m.On("GetDockerContainers", 3, models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=shop"}}).Return(nil, errors.New("environment unreachable"))
+			},
+			expectError: "failed to list stack containers",
+		},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetStackResources()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.expectError)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
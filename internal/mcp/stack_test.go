@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
@@ -39,7 +40,7 @@ func TestHandleGetStacks(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
-			mockClient.On("GetStacks").Return(tt.mockStacks, tt.mockError)
+			mockClient.On("GetStacks", models.EdgeStackListOptions{}).Return(tt.mockStacks, tt.mockError)
 
 			server := &PortainerMCPServer{
 				cli: mockClient,
@@ -478,6 +479,137 @@ mockClient.AssertExpectations(t)
 }
 }
 
+// TestHandleGetStacks_Filters verifies that listStacks passes its filter parameters to
+// the client.
+func TestHandleGetStacks_Filters(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         map[string]any
+		expectedOpts models.EdgeStackListOptions
+		expectError  bool
+	}{
+		{name: "no filters", args: map[string]any{}},
+		{name: "name filter", args: map[string]any{"name": "WEB"}, expectedOpts: models.EdgeStackListOptions{Name: "WEB"}},
+		{name: "environment group filter", args: map[string]any{"environmentGroupId": float64(2)}, expectedOpts: models.EdgeStackListOptions{EnvironmentGroupID: 2}},
+		{name: "status filter", args: map[string]any{"status": "failed"}, expectedOpts: models.EdgeStackListOptions{Status: models.StackStatusFailed}},
+		{
+			name:         "combined filters",
+			args:         map[string]any{"name": "front", "environmentGroupId": float64(2), "status": "deployed"},
+			expectedOpts: models.EdgeStackListOptions{Name: "front", EnvironmentGroupID: 2, Status: models.StackStatusDeployed},
+		},
+		{name: "invalid environmentGroupId", args: map[string]any{"environmentGroupId": "two"}, expectError: true},
+		{name: "invalid status", args: map[string]any{"status": "running"}, expectError: true},
+		{name: "negative offset", args: map[string]any{"offset": float64(-1)}, expectError: true},
+		{name: "negative limit", args: map[string]any{"limit": float64(-1)}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStacks", tt.expectedOpts).Return([]models.Stack{{ID: 1, Name: "web-frontend"}}, nil).Maybe()
+
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleGetStacks()(context.Background(), CreateMCPRequest(tt.args))
+			assert.NoError(t, err)
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+			assert.False(t, result.IsError, result.Content[0].(mcp.TextContent).Text)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleGetStacks_Pagination verifies the pages returned when offset or limit is set.
+func TestHandleGetStacks_Pagination(t *testing.T) {
+	stacks := []models.Stack{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		expected edgeStackPage
+	}{
+		{name: "first page", args: map[string]any{"limit": float64(2)}, expected: edgeStackPage{Stacks: stacks[:2], Total: 5, NextOffset: 2}},
+		{name: "middle page", args: map[string]any{"offset": float64(2), "limit": float64(2)}, expected: edgeStackPage{Stacks: stacks[2:4], Total: 5, Offset: 2, NextOffset: 4}},
+		{name: "last page", args: map[string]any{"offset": float64(4), "limit": float64(2)}, expected: edgeStackPage{Stacks: stacks[4:], Total: 5, Offset: 4}},
+		{name: "offset only", args: map[string]any{"offset": float64(3)}, expected: edgeStackPage{Stacks: stacks[3:], Total: 5, Offset: 3}},
+		{name: "offset past the end", args: map[string]any{"offset": float64(9), "limit": float64(2)}, expected: edgeStackPage{Stacks: []models.Stack{}, Total: 5, Offset: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetStacks", models.EdgeStackListOptions{}).Return(stacks, nil)
+
+			s := &PortainerMCPServer{cli: mockClient}
+			result, err := s.HandleGetStacks()(context.Background(), CreateMCPRequest(tt.args))
+			assert.NoError(t, err)
+			assert.False(t, result.IsError)
+
+			var page edgeStackPage
+			assert.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page))
+			assert.Equal(t, tt.expected, page)
+		})
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetStacks", models.EdgeStackListOptions{}).Return(stacks, nil)
+	s := &PortainerMCPServer{cli: mockClient}
+	result, err := s.HandleGetStacks()(context.Background(), CreateMCPRequest(map[string]any{"limit": float64(2), "summarize": true}))
+	assert.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "5 edge stacks", "summaries cover every matching stack")
+}
+
+// TestHandleListRegularStacks_Filters verifies that listRegularStacks applies its filter parameters.
+func TestHandleListRegularStacks_Filters(t *testing.T) {
+	stacks := []models.RegularStack{
+		{ID: 1, Name: "web-app", Status: regularStackStatusActive, EndpointID: 1},
+		{ID: 2, Name: "web-db", Status: regularStackStatusInactive, EndpointID: 1},
+		{ID: 3, Name: "metrics", Status: regularStackStatusActive, EndpointID: 2},
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		expectedIDs []int
+		expectError bool
+	}{
+		{name: "no filters", args: map[string]any{}, expectedIDs: []int{1, 2, 3}},
+		{name: "name filter", args: map[string]any{"name": "web"}, expectedIDs: []int{1, 2}},
+		{name: "environment filter", args: map[string]any{"environmentId": float64(2)}, expectedIDs: []int{3}},
+		{name: "status filter", args: map[string]any{"status": "inactive"}, expectedIDs: []int{2}},
+		{name: "invalid status", args: map[string]any{"status": "paused"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetRegularStacks").Return(stacks, nil).Maybe()
+
+			s := &PortainerMCPServer{cli: mockClient}
+
+			result, err := s.HandleListRegularStacks()(context.Background(), CreateMCPRequest(tt.args))
+			assert.NoError(t, err)
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			var got []models.RegularStack
+			err = json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &got)
+			assert.NoError(t, err)
+
+			ids := make([]int, 0, len(got))
+			for _, stack := range got {
+				ids = append(ids, stack.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
 // TestHandleInspectStack verifies the HandleInspectStack MCP tool handler.
 func TestHandleInspectStack(t *testing.T) {
 tests := []struct {
@@ -1035,3 +1167,109 @@ mockClient.AssertExpectations(t)
 })
 }
 }
+
+// TestHandleDeployStackAndWait verifies the deploy stack and wait handler.
+func TestHandleDeployStackAndWait(t *testing.T) {
+	composeFile := "services:\n  app:\n    image: nginx\n"
+	opts := models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=web"}}
+	running := []models.DockerContainer{{ID: "a1", Name: "web-app-1", State: "running", Status: "Up 3 seconds"}}
+
+	tests := []struct {
+		name          string
+		params        map[string]any
+		setupMock     func(*MockPortainerClient)
+		expectError   bool
+		expectOutcome string
+	}{
+		{
+			name:   "create and wait",
+			params: map[string]any{"environmentId": float64(1), "name": "web", "file": composeFile, "env": []any{map[string]any{"key": "TAG", "value": "1"}}},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("CreateRegularStack", 1, "web", composeFile, map[string]string{"TAG": "1"}).Return(models.RegularStack{ID: 5, Name: "web"}, nil)
+				m.On("GetDockerContainers", 1, opts).Return(running, nil)
+			},
+			expectOutcome: waitOutcomeReady,
+		},
+		{
+			name:   "update and wait",
+			params: map[string]any{"environmentId": float64(1), "stackId": float64(5), "file": composeFile, "pullImage": true},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("UpdateRegularStack", 5, 1, composeFile, map[string]string{}, true, false).Return(models.RegularStack{ID: 5, Name: "web"}, nil)
+				m.On("GetDockerContainers", 1, opts).Return(running, nil)
+			},
+			expectOutcome: waitOutcomeReady,
+		},
+		{
+			name:        "missing name without stackId",
+			params:      map[string]any{"environmentId": float64(1), "file": composeFile},
+			expectError: true,
+		},
+		{
+			name:        "missing environmentId",
+			params:      map[string]any{"name": "web", "file": composeFile},
+			expectError: true,
+		},
+		{
+			name:        "invalid compose file",
+			params:      map[string]any{"environmentId": float64(1), "name": "web", "file": "not: [valid"},
+			expectError: true,
+		},
+		{
+			name:        "timeout out of range",
+			params:      map[string]any{"environmentId": float64(1), "name": "web", "file": composeFile, "timeoutSeconds": float64(3600)},
+			expectError: true,
+		},
+		{
+			name:        "invalid env",
+			params:      map[string]any{"environmentId": float64(1), "name": "web", "file": composeFile, "env": []any{"TAG=1"}},
+			expectError: true,
+		},
+		{
+			name:   "create error",
+			params: map[string]any{"environmentId": float64(1), "name": "web", "file": composeFile},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("CreateRegularStack", 1, "web", composeFile, map[string]string{}).Return(models.RegularStack{}, fmt.Errorf("conflict"))
+			},
+			expectError: true,
+		},
+		{
+			name:   "container listing error",
+			params: map[string]any{"environmentId": float64(1), "name": "web", "file": composeFile},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("CreateRegularStack", 1, "web", composeFile, map[string]string{}).Return(models.RegularStack{ID: 5, Name: "web"}, nil)
+				m.On("GetDockerContainers", 1, opts).Return([]models.DockerContainer(nil), fmt.Errorf("proxy error"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient, pollInterval: time.Millisecond}
+
+			result, err := server.HandleDeployStackAndWait()(context.Background(), CreateMCPRequest(tt.params))
+			assert.NoError(t, err)
+			assert.NotNil(t, result)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+			} else {
+				assert.False(t, result.IsError)
+				textContent, ok := result.Content[0].(mcp.TextContent)
+				assert.True(t, ok)
+
+				var report deployStackAndWaitReport
+				assert.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+				assert.Equal(t, tt.expectOutcome, report.Outcome)
+				assert.Equal(t, 5, report.Stack.ID)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultServerStatsLimit = 20
+
+// latencyBucketsMs are the upper bounds, in milliseconds, of the latency histogram of
+// each tool; the last bucket is unbounded.
+var latencyBucketsMs = []float64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+// statsKey identifies the calls of a tool, or of a meta-tool action.
+type statsKey struct {
+	tool   string
+	action string
+}
+
+// callStats are the latency and error counters of a tool.
+type callStats struct {
+	calls   int64
+	errors  int64
+	totalMs float64
+	maxMs   float64
+	// buckets counts the calls per latency bucket, with one more bucket than
+	// latencyBucketsMs for the slower calls.
+	buckets []int64
+	// errorCodes counts the failed calls per error code.
+	errorCodes map[string]int64
+}
+
+// quantile estimates a latency quantile from the histogram, as the upper bound of the
+// bucket holding it, capped by the slowest call.
+func (c *callStats) quantile(q float64) float64 {
+	rank := int64(math.Ceil(q * float64(c.calls)))
+	var seen int64
+	for i, count := range c.buckets {
+		seen += count
+		if seen >= rank && i < len(latencyBucketsMs) {
+			return math.Min(latencyBucketsMs[i], c.maxMs)
+		}
+	}
+	return c.maxMs
+}
+
+// toolStats records the latency and errors of every tool call since the server started.
+// It is safe for concurrent use.
+type toolStats struct {
+	started time.Time
+
+	mu    sync.Mutex
+	calls map[statsKey]*callStats
+}
+
+// newToolStats creates empty statistics
+func newToolStats() *toolStats {
+	return &toolStats{started: time.Now(), calls: map[statsKey]*callStats{}}
+}
+
+// record adds a call to the statistics of its tool. code is the error code of a failed
+// call, and empty for successful calls.
+func (st *toolStats) record(key statsKey, duration time.Duration, code string) {
+	ms := float64(duration.Microseconds()) / 1000
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	stats, ok := st.calls[key]
+	if !ok {
+		stats = &callStats{buckets: make([]int64, len(latencyBucketsMs)+1), errorCodes: map[string]int64{}}
+		st.calls[key] = stats
+	}
+	stats.calls++
+	stats.totalMs += ms
+	stats.maxMs = math.Max(stats.maxMs, ms)
+	bucket, _ := slices.BinarySearch(latencyBucketsMs, ms)
+	stats.buckets[bucket]++
+	if code != "" {
+		stats.errors++
+		stats.errorCodes[code]++
+	}
+}
+
+// serverStats is the result of HandleGetServerStats.
+type serverStats struct {
+	UptimeSeconds int64      `json:"uptimeSeconds"`
+	Calls         int64      `json:"calls"`
+	Errors        int64      `json:"errors"`
+	TotalMs       float64    `json:"totalMs"`
+	Tools         []toolStat `json:"tools"`
+	// Omitted is the number of tools left out of Tools by the limit.
+	Omitted int `json:"omitted,omitempty"`
+}
+
+// toolStat are the statistics of one tool or meta-tool action. Latencies are in
+// milliseconds; the percentiles are estimated from a histogram.
+type toolStat struct {
+	Tool       string           `json:"tool"`
+	Action     string           `json:"action,omitempty"`
+	Calls      int64            `json:"calls"`
+	Errors     int64            `json:"errors"`
+	ErrorRate  float64          `json:"errorRate"`
+	TotalMs    float64          `json:"totalMs"`
+	TimeShare  float64          `json:"timeShare"`
+	AvgMs      float64          `json:"avgMs"`
+	P50Ms      float64          `json:"p50Ms"`
+	P95Ms      float64          `json:"p95Ms"`
+	MaxMs      float64          `json:"maxMs"`
+	ErrorCodes map[string]int64 `json:"errorCodes,omitempty"`
+}
+
+// snapshot returns the statistics of every tool, sorted by total time, the tools that
+// dominate the session time first.
+func (st *toolStats) snapshot() serverStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	result := serverStats{UptimeSeconds: int64(time.Since(st.started).Seconds()), Tools: []toolStat{}}
+	for _, stats := range st.calls {
+		result.Calls += stats.calls
+		result.Errors += stats.errors
+		result.TotalMs += stats.totalMs
+	}
+
+	for key, stats := range st.calls {
+		stat := toolStat{
+			Tool:      key.tool,
+			Action:    key.action,
+			Calls:     stats.calls,
+			Errors:    stats.errors,
+			ErrorRate: roundTo(float64(stats.errors)/float64(stats.calls), 3),
+			TotalMs:   roundTo(stats.totalMs, 1),
+			AvgMs:     roundTo(stats.totalMs/float64(stats.calls), 1),
+			P50Ms:     roundTo(stats.quantile(0.5), 1),
+			P95Ms:     roundTo(stats.quantile(0.95), 1),
+			MaxMs:     roundTo(stats.maxMs, 1),
+		}
+		if result.TotalMs > 0 {
+			stat.TimeShare = roundTo(stats.totalMs/result.TotalMs, 3)
+		}
+		if len(stats.errorCodes) > 0 {
+			stat.ErrorCodes = maps.Clone(stats.errorCodes)
+		}
+		result.Tools = append(result.Tools, stat)
+	}
+	result.TotalMs = roundTo(result.TotalMs, 1)
+
+	slices.SortFunc(result.Tools, func(a, b toolStat) int {
+		return cmp.Or(
+			cmp.Compare(b.TotalMs, a.TotalMs),
+			cmp.Compare(a.Tool, b.Tool),
+			cmp.Compare(a.Action, b.Action),
+		)
+	})
+	return result
+}
+
+// roundTo rounds a value to the given number of decimals
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
+
+// writePrometheus writes the statistics in the Prometheus text exposition format: a call
+// counter, an error counter per error code and a latency histogram per tool and action.
+func (st *toolStats) writePrometheus(w io.Writer) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	keys := slices.SortedFunc(maps.Keys(st.calls), func(a, b statsKey) int {
+		return cmp.Or(cmp.Compare(a.tool, b.tool), cmp.Compare(a.action, b.action))
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP portainer_mcp_tool_calls_total Tool calls handled by the server.\n")
+	b.WriteString("# TYPE portainer_mcp_tool_calls_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "portainer_mcp_tool_calls_total{%s} %d\n", key.labels(), st.calls[key].calls)
+	}
+
+	b.WriteString("# HELP portainer_mcp_tool_errors_total Tool calls that returned an error, by error code.\n")
+	b.WriteString("# TYPE portainer_mcp_tool_errors_total counter\n")
+	for _, key := range keys {
+		stats := st.calls[key]
+		for _, code := range slices.Sorted(maps.Keys(stats.errorCodes)) {
+			fmt.Fprintf(&b, "portainer_mcp_tool_errors_total{%s,code=%q} %d\n", key.labels(), code, stats.errorCodes[code])
+		}
+	}
+
+	b.WriteString("# HELP portainer_mcp_tool_duration_seconds Duration of the tool calls.\n")
+	b.WriteString("# TYPE portainer_mcp_tool_duration_seconds histogram\n")
+	for _, key := range keys {
+		stats := st.calls[key]
+		var cumulative int64
+		for i, bound := range latencyBucketsMs {
+			cumulative += stats.buckets[i]
+			fmt.Fprintf(&b, "portainer_mcp_tool_duration_seconds_bucket{%s,le=%q} %d\n", key.labels(), strconv.FormatFloat(bound/1000, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "portainer_mcp_tool_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", key.labels(), stats.calls)
+		fmt.Fprintf(&b, "portainer_mcp_tool_duration_seconds_sum{%s} %s\n", key.labels(), strconv.FormatFloat(stats.totalMs/1000, 'g', -1, 64))
+		fmt.Fprintf(&b, "portainer_mcp_tool_duration_seconds_count{%s} %d\n", key.labels(), stats.calls)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labels returns the Prometheus labels of a tool
+func (k statsKey) labels() string {
+	return fmt.Sprintf("tool=%q,action=%q", k.tool, k.action)
+}
+
+// statsMiddleware records the duration and the outcome of every tool call, per tool and
+// meta-tool action. It is registered outermost so that the duration covers the other
+// middlewares and the error codes are set.
+func (s *PortainerMCPServer) statsMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		key := statsKey{tool: request.Params.Name}
+		if action, ok := request.GetArguments()["action"].(string); ok {
+			if _, found := findMetaAction(key.tool, action); found {
+				key.action = action
+			}
+		}
+
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		code := ""
+		switch {
+		case err != nil:
+			code = ErrorCodeInternal
+		case result != nil && result.IsError:
+			code = cmp.Or(errorCode(result), ErrorCodeInternal)
+		}
+		s.stats.record(key, time.Since(start), code)
+		return result, err
+	}
+}
+
+// serveMetrics serves the tool statistics in the Prometheus text format at /metrics on
+// addr, until ctx is done.
+func (s *PortainerMCPServer) serveMetrics(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := s.stats.writePrometheus(w); err != nil {
+			log.Warn().Err(err).Msg("failed to write metrics")
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("metrics endpoint stopped")
+		}
+	}()
+
+	log.Info().Str("address", listener.Addr().String()).Msg("serving metrics at /metrics")
+	return nil
+}
+
+// HandleGetServerStats returns an MCP tool handler that reports the number of calls, the
+// error rate and the latency of every tool called since the server started.
+func (s *PortainerMCPServer) HandleGetServerStats() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		limit, err := parser.GetInt("limit", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if limit < 0 {
+			return mcp.NewToolResultError("invalid limit parameter: must not be negative"), nil
+		}
+		if limit == 0 {
+			limit = defaultServerStatsLimit
+		}
+
+		stats := s.stats.snapshot()
+		if len(stats.Tools) > limit {
+			stats.Omitted = len(stats.Tools) - limit
+			stats.Tools = stats.Tools[:limit]
+		}
+		return jsonResult(stats, "failed to marshal server stats")
+	}
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolStatsSnapshot verifies the counters, the estimated percentiles and the sorting
+// by total call time.
+func TestToolStatsSnapshot(t *testing.T) {
+	st := newToolStats()
+	for range 9 {
+		st.record(statsKey{tool: "manage_stacks", action: "list_stacks"}, 20*time.Millisecond, "")
+	}
+	st.record(statsKey{tool: "manage_stacks", action: "list_stacks"}, 800*time.Millisecond, ErrorCodeUpstreamUnavailable)
+	st.record(statsKey{tool: ToolListEnvironments}, 5*time.Millisecond, "")
+
+	stats := st.snapshot()
+	assert.Equal(t, int64(11), stats.Calls)
+	assert.Equal(t, int64(1), stats.Errors)
+	assert.Equal(t, 985.0, stats.TotalMs)
+	require.Len(t, stats.Tools, 2)
+
+	assert.Equal(t, toolStat{
+		Tool:       "manage_stacks",
+		Action:     "list_stacks",
+		Calls:      10,
+		Errors:     1,
+		ErrorRate:  0.1,
+		TotalMs:    980,
+		TimeShare:  0.995,
+		AvgMs:      98,
+		P50Ms:      25,
+		P95Ms:      800,
+		MaxMs:      800,
+		ErrorCodes: map[string]int64{ErrorCodeUpstreamUnavailable: 1},
+	}, stats.Tools[0])
+	assert.Equal(t, ToolListEnvironments, stats.Tools[1].Tool)
+	assert.Equal(t, 5.0, stats.Tools[1].P95Ms, "percentiles are capped by the slowest call")
+}
+
+// TestStatsMiddleware verifies that calls are recorded per tool and meta-tool action, with
+// the error code of failed calls.
+func TestStatsMiddleware(t *testing.T) {
+	s := &PortainerMCPServer{stats: newToolStats()}
+	handler := s.statsMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch request.Params.Name {
+		case ToolGetStack:
+			return newToolResultErrorWithCode(ErrorCodeNotFound, "stack not found"), nil
+		case ToolDeleteStack:
+			return nil, errors.New("handler failure")
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{tool: "manage_stacks", args: map[string]any{"action": "list_stacks"}},
+		{tool: "manage_stacks", args: map[string]any{"action": "unknown"}},
+		{tool: ToolGetStack},
+		{tool: ToolDeleteStack},
+	}
+	for _, call := range calls {
+		request := CreateMCPRequest(call.args)
+		request.Params.Name = call.tool
+		_, _ = handler(context.Background(), request)
+	}
+
+	s.stats.mu.Lock()
+	defer s.stats.mu.Unlock()
+	assert.Equal(t, int64(1), s.stats.calls[statsKey{tool: "manage_stacks", action: "list_stacks"}].calls)
+	assert.Equal(t, int64(1), s.stats.calls[statsKey{tool: "manage_stacks"}].calls, "unknown actions are recorded under the meta-tool")
+	assert.Equal(t, map[string]int64{ErrorCodeNotFound: 1}, s.stats.calls[statsKey{tool: ToolGetStack}].errorCodes)
+	assert.Equal(t, map[string]int64{ErrorCodeInternal: 1}, s.stats.calls[statsKey{tool: ToolDeleteStack}].errorCodes)
+}
+
+// TestHandleGetServerStats verifies the limit of the reported tools.
+func TestHandleGetServerStats(t *testing.T) {
+	s := &PortainerMCPServer{stats: newToolStats()}
+	s.stats.record(statsKey{tool: ToolListStacks}, 30*time.Millisecond, "")
+	s.stats.record(statsKey{tool: ToolListUsers}, 10*time.Millisecond, "")
+
+	result, err := s.HandleGetServerStats()(context.Background(), CreateMCPRequest(map[string]any{"limit": float64(1)}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var stats serverStats
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &stats))
+	require.Len(t, stats.Tools, 1)
+	assert.Equal(t, ToolListStacks, stats.Tools[0].Tool)
+	assert.Equal(t, 1, stats.Omitted)
+
+	result, err = s.HandleGetServerStats()(context.Background(), CreateMCPRequest(map[string]any{"limit": float64(-1)}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+// TestServeMetrics verifies the Prometheus exposition of the statistics.
+func TestServeMetrics(t *testing.T) {
+	s := &PortainerMCPServer{stats: newToolStats()}
+	s.stats.record(statsKey{tool: "manage_stacks", action: "list_stacks"}, 20*time.Millisecond, "")
+	s.stats.record(statsKey{tool: "manage_stacks", action: "list_stacks"}, 300*time.Millisecond, ErrorCodeNotFound)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, s.serveMetrics(ctx, addr))
+	assert.Error(t, s.serveMetrics(ctx, addr), "the address is in use")
+
+	response, err := http.Get("http://" + addr + "/metrics")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, strings.HasPrefix(response.Header.Get("Content-Type"), "text/plain; version=0.0.4"))
+	for _, line := range []string{
+		`portainer_mcp_tool_calls_total{tool="manage_stacks",action="list_stacks"} 2`,
+		`portainer_mcp_tool_errors_total{tool="manage_stacks",action="list_stacks",code="NOT_FOUND"} 1`,
+		`portainer_mcp_tool_duration_seconds_bucket{tool="manage_stacks",action="list_stacks",le="0.025"} 1`,
+		`portainer_mcp_tool_duration_seconds_bucket{tool="manage_stacks",action="list_stacks",le="0.5"} 2`,
+		`portainer_mcp_tool_duration_seconds_bucket{tool="manage_stacks",action="list_stacks",le="+Inf"} 2`,
+		`portainer_mcp_tool_duration_seconds_sum{tool="manage_stacks",action="list_stacks"} 0.32`,
+		`portainer_mcp_tool_duration_seconds_count{tool="manage_stacks",action="list_stacks"} 2`,
+	} {
+		assert.Contains(t, string(body), line+"\n")
+	}
+}
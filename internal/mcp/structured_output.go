@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// toolOutput is the typed result of a tool, declared as its output schema.
+type toolOutput struct {
+	schema json.RawMessage
+	// list is set on the tools returning a JSON array, whose structured content holds the
+	// array in items, or the summary returned instead when the call sets summarize.
+	list bool
+}
+
+// toolOutputs are the typed results of the tools, generated from the models they return.
+var toolOutputs = map[string]toolOutput{
+	ToolListEnvironments:      listOutput[models.Environment](),
+	ToolGetEnvironment:        objectOutput[models.Environment](),
+	ToolListRegularStacks:     listOutput[models.RegularStack](),
+	ToolGetStack:              objectOutput[models.RegularStack](),
+	ToolListUsers:             listOutput[models.User](),
+	ToolGetUser:               objectOutput[models.User](),
+	ToolListHelmReleases:      listOutput[models.HelmRelease](),
+	ToolGetHelmReleaseHistory: listOutput[models.HelmReleaseDetails](),
+}
+
+// objectOutput returns the typed result of a tool returning a T.
+func objectOutput[T any]() toolOutput {
+	return toolOutput{schema: mustMarshalSchema(toolgen.TypeSchema[T]())}
+}
+
+// listOutput returns the typed result of a tool returning a list of T.
+func listOutput[T any]() toolOutput {
+	return toolOutput{list: true, schema: mustMarshalSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"items":   map[string]any{"type": "array", "items": toolgen.TypeSchema[T]()},
+			"summary": map[string]any{"type": "string"},
+		},
+	})}
+}
+
+// mustMarshalSchema encodes a schema generated from a type, which cannot fail.
+func mustMarshalSchema(schema map[string]any) json.RawMessage {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// structure returns the structured content of a result from its first text content, or
+// nil when the text does not match the output.
+func (o toolOutput) structure(result *mcp.CallToolResult) map[string]any {
+	for _, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+
+		var doc any
+		if err := json.Unmarshal([]byte(text.Text), &doc); err != nil {
+			if o.list {
+				return map[string]any{"summary": text.Text}
+			}
+			return nil
+		}
+		switch doc := doc.(type) {
+		case map[string]any:
+			if !o.list {
+				return doc
+			}
+		case []any:
+			if o.list {
+				return map[string]any{"items": doc}
+			}
+		case nil:
+			if o.list {
+				return map[string]any{"items": []any{}}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// structuredOutputMiddleware sets the structured content of the successful results of
+// the tools with a typed result, from the JSON text returned by their handler, so that
+// clients can rely on the output schema of the tool instead of parsing the text. The
+// text content is kept for the clients that do not read structured content. Meta-tool
+// actions get the structured content of their tool, but the meta-tools declare no output
+// schema, since their actions return different types.
+func structuredOutputMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError || result.StructuredContent != nil {
+			return result, err
+		}
+
+		toolName := requestToolName(request)
+		output, ok := toolOutputs[toolName]
+		if !ok {
+			return result, nil
+		}
+		if structured := output.structure(result); structured != nil {
+			result.StructuredContent = structured
+		} else {
+			log.Debug().Str("tool", toolName).Msg("tool result does not match its output schema, returning text only")
+		}
+		return result, nil
+	}
+}
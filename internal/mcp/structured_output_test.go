@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolOutputs verifies that the typed tools exist and that their schemas are objects.
+func TestToolOutputs(t *testing.T) {
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+	for name, output := range toolOutputs {
+		assert.Contains(t, tools, name)
+		var schema map[string]any
+		require.NoError(t, json.Unmarshal(output.schema, &schema), name)
+		assert.Equal(t, "object", schema["type"], "the output schema of %s must describe an object", name)
+	}
+}
+
+// TestStructuredOutputMiddleware verifies the structured content set on the results of
+// the typed tools.
+func TestStructuredOutputMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		tool   string
+		args   map[string]any
+		result *mcp.CallToolResult
+		want   any
+	}{
+		{
+			name:   "list",
+			tool:   ToolListEnvironments,
+			result: mcp.NewToolResultText(`[{"id":1,"name":"local"}]`),
+			want:   map[string]any{"items": []any{map[string]any{"id": float64(1), "name": "local"}}},
+		},
+		{
+			name:   "empty list",
+			tool:   ToolListUsers,
+			result: mcp.NewToolResultText(`null`),
+			want:   map[string]any{"items": []any{}},
+		},
+		{
+			name:   "summary",
+			tool:   ToolListHelmReleases,
+			result: mcp.NewToolResultText("3 Helm releases: 3 deployed"),
+			want:   map[string]any{"summary": "3 Helm releases: 3 deployed"},
+		},
+		{
+			name:   "object",
+			tool:   ToolGetUser,
+			result: mcp.NewToolResultText(`{"id":2,"username":"alice","role":"admin"}`),
+			want:   map[string]any{"id": float64(2), "username": "alice", "role": "admin"},
+		},
+		{
+			name:   "meta-tool action",
+			tool:   "manage_environments",
+			args:   map[string]any{"action": "get_environment"},
+			result: mcp.NewToolResultText(`{"id":3}`),
+			want:   map[string]any{"id": float64(3)},
+		},
+		{
+			name:   "object expected",
+			tool:   ToolGetEnvironment,
+			result: mcp.NewToolResultText(`[]`),
+		},
+		{
+			name:   "error result",
+			tool:   ToolGetEnvironment,
+			result: mcp.NewToolResultError(`{"id":3}`),
+		},
+		{
+			name:   "untyped tool",
+			tool:   ToolListTeams,
+			result: mcp.NewToolResultText(`[]`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := CreateMCPRequest(tt.args)
+			request.Params.Name = tt.tool
+			handler := structuredOutputMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return tt.result, nil
+			})
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			if tt.want == nil {
+				assert.Nil(t, result.StructuredContent)
+				return
+			}
+			assert.Equal(t, tt.want, result.StructuredContent)
+			assert.NotEmpty(t, result.Content, "the text content is kept")
+		})
+	}
+}
+
+// TestAddToolOutputSchema verifies that the typed tools declare their output schema.
+func TestAddToolOutputSchema(t *testing.T) {
+	tools, err := toolgen.LoadToolsFromYAML("../tooldef/tools.yaml", MinimumToolsVersion)
+	require.NoError(t, err)
+	s := &PortainerMCPServer{srv: server.NewMCPServer("Test Server", "1.0.0"), tools: tools}
+	s.addToolIfExists(ToolGetEnvironment, s.HandleGetEnvironment())
+	s.addToolIfExists(ToolListTeams, s.HandleGetTeams())
+
+	message := s.srv.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	response, ok := message.(mcp.JSONRPCResponse)
+	require.True(t, ok, "unexpected response %#v", message)
+	data, err := json.Marshal(response.Result)
+	require.NoError(t, err)
+
+	var listed struct {
+		Tools []struct {
+			Name         string         `json:"name"`
+			OutputSchema map[string]any `json:"outputSchema"`
+		} `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(data, &listed))
+	schemas := map[string]map[string]any{}
+	for _, tool := range listed.Tools {
+		schemas[tool.Name] = tool.OutputSchema
+	}
+
+	require.NotNil(t, schemas[ToolGetEnvironment])
+	assert.ElementsMatch(t, []any{"id", "name", "status", "type", "group_id", "tag_ids", "user_accesses", "team_accesses"},
+		schemas[ToolGetEnvironment]["required"])
+	assert.Nil(t, schemas[ToolListTeams], "untyped tools declare no output schema")
+
+	// The JSON of an environment has every required property.
+	environment, err := json.Marshal(models.Environment{})
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(environment, &fields))
+	for _, property := range schemas[ToolGetEnvironment]["required"].([]any) {
+		assert.Contains(t, fields, property)
+	}
+}
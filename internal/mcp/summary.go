@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+)
+
+// maxSummaryNames caps how many resource names are listed in a single summary line.
+const maxSummaryNames = 10
+
+// Regular stack status values as used by the Portainer API
+const (
+	regularStackStatusActive   = 1
+	regularStackStatusInactive = 2
+)
+
+// listResult returns obj as JSON, or as a concise natural-language summary when the
+// request sets summarize=true and a summarizer exists for the object's type.
+func listResult(request mcp.CallToolRequest, obj any, errMsg string) (*mcp.CallToolResult, error) {
+	parser := toolgen.NewParameterParser(request)
+
+	summarize, err := parser.GetBoolean("summarize", false)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("invalid summarize parameter", err), nil
+	}
+
+	if summarize {
+		if summary, ok := summarizeResult(obj); ok {
+			return mcp.NewToolResultText(summary), nil
+		}
+	}
+
+	return jsonResult(obj, errMsg)
+}
+
+// summarizeResult builds a human-readable summary for the supported typed models.
+// It returns false when no summarizer is available for the given type.
+func summarizeResult(obj any) (string, bool) {
+	switch v := obj.(type) {
+	case []models.Environment:
+		return summarizeEnvironments(v), true
+	case []models.Stack:
+		return summarizeEdgeStacks(v), true
+	case []models.RegularStack:
+		return summarizeRegularStacks(v), true
+	case []models.User:
+		return summarizeUsers(v), true
+	case []models.Team:
+		return summarizeTeams(v), true
+	case []models.HelmRelease:
+		return summarizeHelmReleases(v), true
+	default:
+		return "", false
+	}
+}
+
+// summarizeEnvironments reports environment counts by status and type, and names
+// every environment that is not active.
+func summarizeEnvironments(envs []models.Environment) string {
+	byStatus := map[string]int{}
+	byType := map[string]int{}
+	var notActive []string
+	for _, env := range envs {
+		byStatus[env.Status]++
+		byType[env.Type]++
+		if env.Status != models.EnvironmentStatusActive {
+			notActive = append(notActive, fmt.Sprintf("%s (ID %d, %s)", env.Name, env.ID, env.Status))
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d environments", len(envs))
+	if len(envs) > 0 {
+		fmt.Fprintf(&sb, " — status: %s; types: %s", formatCounts(byStatus), formatCounts(byType))
+	}
+	sb.WriteString(".")
+	if len(notActive) > 0 {
+		fmt.Fprintf(&sb, "\nNot active: %s.", joinLimited(notActive))
+	}
+	return sb.String()
+}
+
+// summarizeEdgeStacks reports the edge stack count, and names the stacks that failed to
+// deploy and those that target no edge group.
+func summarizeEdgeStacks(stacks []models.Stack) string {
+	var failed, untargeted []string
+	for _, st := range stacks {
+		if st.Status == models.StackStatusFailed {
+			failed = append(failed, fmt.Sprintf("%s (ID %d)", st.Name, st.ID))
+		}
+		if len(st.EnvironmentGroupIds) == 0 {
+			untargeted = append(untargeted, fmt.Sprintf("%s (ID %d)", st.Name, st.ID))
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d edge stacks.", len(stacks))
+	if len(failed) > 0 {
+		fmt.Fprintf(&sb, "\nFailed to deploy: %s.", joinLimited(failed))
+	}
+	if len(untargeted) > 0 {
+		fmt.Fprintf(&sb, "\nNot targeting any edge group: %s.", joinLimited(untargeted))
+	}
+	return sb.String()
+}
+
+// summarizeRegularStacks reports regular stack counts by status and environment, and
+// names every inactive stack.
+func summarizeRegularStacks(stacks []models.RegularStack) string {
+	byStatus := map[string]int{}
+	byEnvironment := map[string]int{}
+	var inactive []string
+	for _, st := range stacks {
+		status := regularStackStatusName(st.Status)
+		byStatus[status]++
+		byEnvironment[fmt.Sprintf("environment %d", st.EndpointID)]++
+		if st.Status != regularStackStatusActive {
+			inactive = append(inactive, fmt.Sprintf("%s (ID %d, environment %d)", st.Name, st.ID, st.EndpointID))
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d regular stacks", len(stacks))
+	if len(stacks) > 0 {
+		fmt.Fprintf(&sb, " — status: %s; by environment: %s", formatCounts(byStatus), formatCounts(byEnvironment))
+	}
+	sb.WriteString(".")
+	if len(inactive) > 0 {
+		fmt.Fprintf(&sb, "\nNot active: %s.", joinLimited(inactive))
+	}
+	return sb.String()
+}
+
+// summarizeUsers reports user counts by role, names the administrators and, when they
+// were looked up, the users owning API tokens.
+func summarizeUsers(users []models.User) string {
+	byRole := map[string]int{}
+	var admins, withTokens []string
+	tokensKnown := false
+	for _, u := range users {
+		byRole[u.Role]++
+		if u.Role == models.UserRoleAdmin {
+			admins = append(admins, u.Username)
+		}
+		if u.HasAPITokens != nil {
+			tokensKnown = true
+			if *u.HasAPITokens {
+				withTokens = append(withTokens, u.Username)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d users", len(users))
+	if len(users) > 0 {
+		fmt.Fprintf(&sb, " — roles: %s", formatCounts(byRole))
+	}
+	sb.WriteString(".")
+	if len(admins) > 0 {
+		fmt.Fprintf(&sb, "\nAdministrators: %s.", joinLimited(admins))
+	}
+	if tokensKnown {
+		fmt.Fprintf(&sb, "\nWith API tokens: %d", len(withTokens))
+		if len(withTokens) > 0 {
+			fmt.Fprintf(&sb, " (%s)", joinLimited(withTokens))
+		}
+		sb.WriteString(".")
+	}
+	return sb.String()
+}
+
+// summarizeTeams reports team counts, total memberships, and names empty teams.
+func summarizeTeams(teams []models.Team) string {
+	members := 0
+	var empty []string
+	for _, t := range teams {
+		members += len(t.MemberIDs)
+		if len(t.MemberIDs) == 0 {
+			empty = append(empty, t.Name)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d teams with %d memberships in total.", len(teams), members)
+	if len(empty) > 0 {
+		fmt.Fprintf(&sb, "\nTeams without members: %s.", joinLimited(empty))
+	}
+	return sb.String()
+}
+
+// summarizeHelmReleases reports release counts by status and names every release
+// that is not in the deployed state.
+func summarizeHelmReleases(releases []models.HelmRelease) string {
+	byStatus := map[string]int{}
+	var notDeployed []string
+	for _, r := range releases {
+		byStatus[r.Status]++
+		if r.Status != "deployed" {
+			notDeployed = append(notDeployed, fmt.Sprintf("%s/%s (%s)", r.Namespace, r.Name, r.Status))
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d helm releases", len(releases))
+	if len(releases) > 0 {
+		fmt.Fprintf(&sb, " — status: %s", formatCounts(byStatus))
+	}
+	sb.WriteString(".")
+	if len(notDeployed) > 0 {
+		fmt.Fprintf(&sb, "\nNot deployed: %s.", joinLimited(notDeployed))
+	}
+	return sb.String()
+}
+
+// regularStackStatusName converts a regular stack status code to a readable name.
+func regularStackStatusName(status int) string {
+	switch status {
+	case regularStackStatusActive:
+		return "active"
+	case regularStackStatusInactive:
+		return "inactive"
+	default:
+		return "unknown"
+	}
+}
+
+// formatCounts renders a count map as "key: n, key: n" sorted by descending count,
+// then by key, so the output is deterministic.
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s: %d", k, counts[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinLimited joins names with commas, listing at most maxSummaryNames of them.
+func joinLimited(names []string) string {
+	if len(names) <= maxSummaryNames {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxSummaryNames], ", "), len(names)-maxSummaryNames)
+}
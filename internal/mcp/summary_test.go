@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSummarizeResult verifies the natural-language summaries for each supported type.
+func TestSummarizeResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+		ok       bool
+	}{
+		{
+			name: "environments with inactive entries",
+			input: []models.Environment{
+				{ID: 1, Name: "prod", Status: models.EnvironmentStatusActive, Type: models.EnvironmentTypeDockerAgent},
+				{ID: 2, Name: "edge-1", Status: models.EnvironmentStatusInactive, Type: models.EnvironmentTypeDockerEdgeAgent},
+				{ID: 3, Name: "k8s", Status: models.EnvironmentStatusActive, Type: models.EnvironmentTypeKubernetesAgent},
+			},
+			expected: "3 environments — status: active: 2, inactive: 1; types: docker-agent: 1, docker-edge-agent: 1, kubernetes-agent: 1.\n" +
+				"Not active: edge-1 (ID 2, inactive).",
+			ok: true,
+		},
+		{
+			name:     "no environments",
+			input:    []models.Environment{},
+			expected: "0 environments.",
+			ok:       true,
+		},
+		{
+			name: "edge stacks without targets",
+			input: []models.Stack{
+				{ID: 1, Name: "web", EnvironmentGroupIds: []int{1}, Status: models.StackStatusDeployed},
+				{ID: 2, Name: "orphan"},
+				{ID: 3, Name: "api", EnvironmentGroupIds: []int{1}, Status: models.StackStatusFailed},
+			},
+			expected: "3 edge stacks.\nFailed to deploy: api (ID 3).\nNot targeting any edge group: orphan (ID 2).",
+			ok:       true,
+		},
+		{
+			name: "regular stacks",
+			input: []models.RegularStack{
+				{ID: 1, Name: "app", Status: regularStackStatusActive, EndpointID: 1},
+				{ID: 2, Name: "db", Status: regularStackStatusInactive, EndpointID: 1},
+				{ID: 3, Name: "cache", Status: regularStackStatusActive, EndpointID: 2},
+			},
+			expected: "3 regular stacks — status: active: 2, inactive: 1; by environment: environment 1: 2, environment 2: 1.\n" +
+				"Not active: db (ID 2, environment 1).",
+			ok: true,
+		},
+		{
+			name: "users",
+			input: []models.User{
+				{ID: 1, Username: "admin", Role: models.UserRoleAdmin},
+				{ID: 2, Username: "alice", Role: models.UserRoleUser},
+				{ID: 3, Username: "bob", Role: models.UserRoleUser},
+			},
+			expected: "3 users — roles: user: 2, admin: 1.\nAdministrators: admin.",
+			ok:       true,
+		},
+		{
+			name: "users with API tokens looked up",
+			input: []models.User{
+				{ID: 1, Username: "admin", Role: models.UserRoleAdmin, HasAPITokens: new(bool)},
+				{ID: 2, Username: "ci", Role: models.UserRoleUser, HasAPITokens: func() *bool { b := true; return &b }()},
+			},
+			expected: "2 users — roles: admin: 1, user: 1.\nAdministrators: admin.\nWith API tokens: 1 (ci).",
+			ok:       true,
+		},
+		{
+			name: "teams",
+			input: []models.Team{
+				{ID: 1, Name: "devs", MemberIDs: []int{1, 2}},
+				{ID: 2, Name: "empty"},
+			},
+			expected: "2 teams with 2 memberships in total.\nTeams without members: empty.",
+			ok:       true,
+		},
+		{
+			name: "helm releases",
+			input: []models.HelmRelease{
+				{Name: "nginx", Namespace: "default", Status: "deployed"},
+				{Name: "redis", Namespace: "cache", Status: "failed"},
+			},
+			expected: "2 helm releases — status: deployed: 1, failed: 1.\nNot deployed: cache/redis (failed).",
+			ok:       true,
+		},
+		{
+			name:  "unsupported type",
+			input: []models.EnvironmentTag{{ID: 1, Name: "tag"}},
+			ok:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, ok := summarizeResult(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, summary)
+		})
+	}
+}
+
+// TestJoinLimited verifies that long name lists are truncated.
+func TestJoinLimited(t *testing.T) {
+	names := make([]string, maxSummaryNames+3)
+	for i := range names {
+		names[i] = fmt.Sprintf("n%d", i)
+	}
+
+	assert.Equal(t, "a, b", joinLimited([]string{"a", "b"}))
+	assert.Equal(t, "n0, n1, n2, n3, n4, n5, n6, n7, n8, n9 and 3 more", joinLimited(names))
+}
+
+// TestListResultSummarize verifies that list handlers honour the summarize parameter.
+func TestListResultSummarize(t *testing.T) {
+	users := []models.User{
+		{ID: 1, Username: "admin", Role: models.UserRoleAdmin},
+	}
+
+	tests := []struct {
+		name          string
+		args          map[string]any
+		expectError   bool
+		expectSummary bool
+	}{
+		{
+			name:          "summary requested",
+			args:          map[string]any{"summarize": true},
+			expectSummary: true,
+		},
+		{
+			name: "default returns JSON",
+			args: map[string]any{},
+		},
+		{
+			name:        "invalid summarize type",
+			args:        map[string]any{"summarize": "yes"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetUsers", models.UserListOptions{}).Return(users, nil)
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetUsers()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+			if tt.expectSummary {
+				assert.Equal(t, "1 users — roles: admin: 1.\nAdministrators: admin.", textContent.Text)
+			} else {
+				assert.JSONEq(t, `[{"id":1,"username":"admin","role":"admin"}]`, textContent.Text)
+			}
+		})
+	}
+}
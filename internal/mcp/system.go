@@ -3,16 +3,27 @@ package mcp
 import (
 	"context"
 
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// AddSystemFeatures registers the system status management tools on the MCP server.
+// AddSystemFeatures registers the system status, latency benchmark, server statistics and delete journal tools on the MCP server.
 func (s *PortainerMCPServer) AddSystemFeatures() {
 	s.addToolIfExists(ToolGetSystemStatus, s.HandleGetSystemStatus())
+	s.addToolIfExists(ToolBenchmarkLatency, s.HandleBenchmarkLatency())
+	s.addToolIfExists(ToolGetServerStats, s.HandleGetServerStats())
+	s.addToolIfExists(ToolGetDeleteJournal, s.HandleGetDeleteJournal())
 }
 
-// HandleGetSystemStatus returns an MCP tool handler that retrieves system status.
+// systemStatusReport is the result of HandleGetSystemStatus.
+type systemStatusReport struct {
+	models.SystemStatus
+	VersionCheck versionCheckStatus `json:"versionCheck"`
+}
+
+// HandleGetSystemStatus returns an MCP tool handler that retrieves system status, along
+// with the version check mode of the server and whether the Portainer version is supported.
 func (s *PortainerMCPServer) HandleGetSystemStatus() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		status, err := s.cli.GetSystemStatus()
@@ -20,6 +31,15 @@ func (s *PortainerMCPServer) HandleGetSystemStatus() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("failed to get system status", err), nil
 		}
 
-		return jsonResult(status, "failed to marshal system status")
+		report := systemStatusReport{
+			SystemStatus: status,
+			VersionCheck: versionCheckStatus{
+				Mode:            s.versionCheck,
+				SupportedRange:  supportedVersionRange(),
+				DetectedVersion: s.portainerVersion,
+				Supported:       isSupportedVersion(status.Version),
+			},
+		}
+		return jsonResult(report, "failed to marshal system status")
 	}
 }
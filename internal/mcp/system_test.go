@@ -15,12 +15,23 @@ import (
 func TestHandleGetSystemStatus(t *testing.T) {
 	tests := []struct {
 		name        string
-		mockStatus  models.SystemStatus
-		mockError   error
-		expectError bool
+		mockStatus      models.SystemStatus
+		mockError       error
+		expectError     bool
+		expectSupported bool
 	}{
 		{
 			name: "successful status retrieval",
+			mockStatus: models.SystemStatus{
+				Version:    "2.31.3",
+				InstanceID: "abc-123-def",
+			},
+			mockError:       nil,
+			expectError:     false,
+			expectSupported: true,
+		},
+		{
+			name: "unsupported version",
 			mockStatus: models.SystemStatus{
 				Version:    "2.24.1",
 				InstanceID: "abc-123-def",
@@ -42,7 +53,9 @@ func TestHandleGetSystemStatus(t *testing.T) {
 			mockClient.On("GetSystemStatus").Return(tt.mockStatus, tt.mockError)
 
 			server := &PortainerMCPServer{
-				cli: mockClient,
+				cli:              mockClient,
+				versionCheck:     VersionCheckWarn,
+				portainerVersion: "2.31.2",
 			}
 
 			handler := server.HandleGetSystemStatus()
@@ -62,10 +75,16 @@ func TestHandleGetSystemStatus(t *testing.T) {
 				textContent, ok := result.Content[0].(mcp.TextContent)
 				assert.True(t, ok)
 
-				var status models.SystemStatus
-				err = json.Unmarshal([]byte(textContent.Text), &status)
+				var report systemStatusReport
+				err = json.Unmarshal([]byte(textContent.Text), &report)
 				assert.NoError(t, err)
-				assert.Equal(t, tt.mockStatus, status)
+				assert.Equal(t, tt.mockStatus, report.SystemStatus)
+				assert.Equal(t, versionCheckStatus{
+					Mode:            VersionCheckWarn,
+					SupportedRange:  "2.27.0 - 2.31.x",
+					DetectedVersion: "2.31.2",
+					Supported:       tt.expectSupported,
+				}, report.VersionCheck)
 			}
 
 			mockClient.AssertExpectations(t)
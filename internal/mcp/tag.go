@@ -3,9 +3,14 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -63,11 +68,43 @@ func (s *PortainerMCPServer) HandleDeleteEnvironmentTag() server.ToolHandlerFunc
 			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
 		}
 
+		entry := s.captureDeletion(journal.KindTag, id, func() (journal.Entry, error) {
+			return s.captureEnvironmentTag(id)
+		})
+
 		err = s.cli.DeleteEnvironmentTag(id)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to delete environment tag", err), nil
 		}
 
-		return mcp.NewToolResultText("Environment tag deleted successfully"), nil
+		return s.deletionResult(ToolDeleteEnvironmentTag, "Environment tag deleted successfully", entry), nil
+	}
+}
+
+// resolveEnvironmentTagNames returns the sorted names of environment tags, or an error
+// naming the tags that do not exist.
+func (s *PortainerMCPServer) resolveEnvironmentTagNames(tagIds []int) ([]string, error) {
+	if len(tagIds) == 0 {
+		return nil, nil
+	}
+	tags, err := s.cli.GetEnvironmentTags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment tags: %v", err)
+	}
+
+	names := make([]string, 0, len(tagIds))
+	var missing []string
+	for _, tagId := range tagIds {
+		i := slices.IndexFunc(tags, func(tag models.EnvironmentTag) bool { return tag.ID == tagId })
+		if i < 0 {
+			missing = append(missing, fmt.Sprint(tagId))
+			continue
+		}
+		names = append(names, tags[i].Name)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment tags not found: %s", strings.Join(missing, ", "))
 	}
+	sort.Strings(names)
+	return names, nil
 }
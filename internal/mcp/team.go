@@ -2,6 +2,7 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -13,12 +14,14 @@ import (
 func (s *PortainerMCPServer) AddTeamFeatures() {
 	s.addToolIfExists(ToolListTeams, s.HandleGetTeams())
 	s.addToolIfExists(ToolGetTeam, s.HandleGetTeam())
+	s.addToolIfExists(ToolAuditTeamAccess, s.HandleAuditTeamAccess())
 
 	if !s.readOnly {
 		s.addToolIfExists(ToolCreateTeam, s.HandleCreateTeam())
 		s.addToolIfExists(ToolDeleteTeam, s.HandleDeleteTeam())
 		s.addToolIfExists(ToolUpdateTeamName, s.HandleUpdateTeamName())
 		s.addToolIfExists(ToolUpdateTeamMembers, s.HandleUpdateTeamMembers())
+		s.addToolIfExists(ToolImportTeams, s.HandleImportTeams())
 	}
 }
 
@@ -52,7 +55,7 @@ func (s *PortainerMCPServer) HandleGetTeams() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("failed to get teams", err), nil
 		}
 
-		return jsonResult(teams, "failed to marshal teams")
+		return listResult(request, teams, "failed to marshal teams")
 	}
 }
 
@@ -75,7 +78,9 @@ func (s *PortainerMCPServer) HandleGetTeam() server.ToolHandlerFunc {
 	}
 }
 
-// HandleDeleteTeam returns an MCP tool handler that deletes team.
+// HandleDeleteTeam returns an MCP tool handler that deletes team. It reports the access
+// groups, environments and registries giving access to the team, and removes the team
+// from them first when cascade is set. With preview set, it only reports them.
 func (s *PortainerMCPServer) HandleDeleteTeam() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		parser := toolgen.NewParameterParser(request)
@@ -84,13 +89,57 @@ func (s *PortainerMCPServer) HandleDeleteTeam() server.ToolHandlerFunc {
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
 		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		preview, err := parser.GetBoolean("preview", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid preview parameter", err), nil
+		}
+
+		cascade, err := parser.GetBoolean("cascade", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid cascade parameter", err), nil
+		}
+
+		team, err := s.cli.GetTeam(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get team", err), nil
+		}
+
+		impact, err := s.listTeamReferences(team)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list the references to the team", err), nil
+		}
+		impact.Cascade = cascade
+
+		if preview {
+			impact.Preview = true
+			return jsonResult(impact, "failed to marshal team deletion impact")
+		}
+
+		if impact.count() > 0 {
+			if cascade {
+				if err := s.removeTeamReferences(impact); err != nil {
+					report, _ := json.Marshal(impact)
+					return mcp.NewToolResultError(fmt.Sprintf("team %d was not deleted: %v. References: %s", id, err, report)), nil
+				}
+			} else {
+				impact.markTeamReferencesLeft()
+			}
+		}
 
 		err = s.cli.DeleteTeam(id)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to delete team", err), nil
 		}
 
-		return mcp.NewToolResultText("Team deleted successfully"), nil
+		if impact.count() == 0 {
+			return mcp.NewToolResultText("Team deleted successfully"), nil
+		}
+		impact.Deleted = true
+		return jsonResult(impact, "failed to marshal team deletion impact")
 	}
 }
 
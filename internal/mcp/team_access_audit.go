@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Sources of the access of a team to an environment.
+const (
+	teamAccessSourceEnvironment = "environment"
+	teamAccessSourceAccessGroup = "access_group"
+)
+
+// teamAccessGroupGrant is an access group with an access policy for the team.
+type teamAccessGroupGrant struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	AccessLevel    string `json:"access_level"`
+	EnvironmentIds []int  `json:"environment_ids"`
+}
+
+// teamEnvironmentAccess is the effective access of the team to an environment.
+type teamEnvironmentAccess struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	AccessLevel string `json:"access_level"`
+	// Source is "environment" when the environment has its own policy for the team, which
+	// takes precedence over the policy of its access group, or "access_group" when the
+	// access is inherited from the group.
+	Source          string `json:"source"`
+	AccessGroupID   int    `json:"access_group_id"`
+	AccessGroupName string `json:"access_group_name,omitempty"`
+	// OverriddenGroupAccess is the access granted by the access group when the
+	// environment policy takes precedence over it.
+	OverriddenGroupAccess string `json:"overridden_group_access,omitempty"`
+}
+
+// teamAccessAudit is the result of HandleAuditTeamAccess.
+type teamAccessAudit struct {
+	TeamID       int                     `json:"team_id"`
+	TeamName     string                  `json:"team_name"`
+	MemberIDs    []int                   `json:"member_ids"`
+	AccessGroups []teamAccessGroupGrant  `json:"access_groups"`
+	Environments []teamEnvironmentAccess `json:"environments"`
+	// AccessLevels counts the environments by effective access level.
+	AccessLevels map[string]int `json:"access_levels"`
+}
+
+// HandleAuditTeamAccess returns an MCP tool handler that reports every access group and
+// environment a team has access to, with the effective access level of the team on each
+// environment.
+func (s *PortainerMCPServer) HandleAuditTeamAccess() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		team, err := s.cli.GetTeam(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get team", err), nil
+		}
+		accessGroups, err := s.cli.GetAccessGroups()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get access groups", err), nil
+		}
+		environments, err := s.cli.GetEnvironments(models.EnvironmentListOptions{})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environments", err), nil
+		}
+
+		return jsonResult(buildTeamAccessAudit(team, accessGroups, environments), "failed to marshal team access audit")
+	}
+}
+
+// buildTeamAccessAudit collects the access policies of a team. The policy of an
+// environment takes precedence over the policy of its access group, as in Portainer.
+func buildTeamAccessAudit(team models.Team, accessGroups []models.AccessGroup, environments []models.Environment) teamAccessAudit {
+	audit := teamAccessAudit{
+		TeamID:       team.ID,
+		TeamName:     team.Name,
+		MemberIDs:    team.MemberIDs,
+		AccessGroups: []teamAccessGroupGrant{},
+		Environments: []teamEnvironmentAccess{},
+		AccessLevels: map[string]int{},
+	}
+	if audit.MemberIDs == nil {
+		audit.MemberIDs = []int{}
+	}
+
+	groups := make(map[int]models.AccessGroup, len(accessGroups))
+	for _, group := range accessGroups {
+		groups[group.ID] = group
+		if level, ok := group.TeamAccesses[team.ID]; ok {
+			audit.AccessGroups = append(audit.AccessGroups, teamAccessGroupGrant{
+				ID:             group.ID,
+				Name:           group.Name,
+				AccessLevel:    level,
+				EnvironmentIds: group.EnvironmentIds,
+			})
+		}
+	}
+
+	for _, env := range environments {
+		group := groups[env.GroupID]
+		groupLevel, inherited := group.TeamAccesses[team.ID]
+		access := teamEnvironmentAccess{
+			ID:              env.ID,
+			Name:            env.Name,
+			AccessGroupID:   env.GroupID,
+			AccessGroupName: group.Name,
+		}
+		if level, ok := env.TeamAccesses[team.ID]; ok {
+			access.AccessLevel = level
+			access.Source = teamAccessSourceEnvironment
+			if inherited && groupLevel != level {
+				access.OverriddenGroupAccess = groupLevel
+			}
+		} else if inherited {
+			access.AccessLevel = groupLevel
+			access.Source = teamAccessSourceAccessGroup
+		} else {
+			continue
+		}
+		audit.Environments = append(audit.Environments, access)
+		audit.AccessLevels[access.AccessLevel]++
+	}
+
+	slices.SortFunc(audit.AccessGroups, func(a, b teamAccessGroupGrant) int { return a.ID - b.ID })
+	slices.SortFunc(audit.Environments, func(a, b teamEnvironmentAccess) int { return a.ID - b.ID })
+	return audit
+}
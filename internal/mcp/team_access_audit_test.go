@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildTeamAccessAudit verifies the effective access of a team, with environment
+// policies taking precedence over access group policies.
+func TestBuildTeamAccessAudit(t *testing.T) {
+	team := models.Team{ID: 5, Name: "devs", MemberIDs: []int{2, 3}}
+	accessGroups := []models.AccessGroup{
+		{ID: 1, Name: "Unassigned", EnvironmentIds: []int{4}},
+		{ID: 2, Name: "production", EnvironmentIds: []int{1, 2}, TeamAccesses: map[int]string{5: "readonly_user", 6: "standard_user"}},
+		{ID: 3, Name: "staging", EnvironmentIds: []int{3}, TeamAccesses: map[int]string{6: "standard_user"}},
+	}
+	environments := []models.Environment{
+		{ID: 2, Name: "prod-db", GroupID: 2, TeamAccesses: map[int]string{5: "environment_administrator"}},
+		{ID: 1, Name: "prod-web", GroupID: 2},
+		{ID: 3, Name: "staging", GroupID: 3},
+		{ID: 4, Name: "sandbox", GroupID: 1, TeamAccesses: map[int]string{5: "standard_user"}},
+	}
+
+	audit := buildTeamAccessAudit(team, accessGroups, environments)
+
+	assert.Equal(t, []teamAccessGroupGrant{
+		{ID: 2, Name: "production", AccessLevel: "readonly_user", EnvironmentIds: []int{1, 2}},
+	}, audit.AccessGroups)
+	assert.Equal(t, []teamEnvironmentAccess{
+		{ID: 1, Name: "prod-web", AccessLevel: "readonly_user", Source: teamAccessSourceAccessGroup, AccessGroupID: 2, AccessGroupName: "production"},
+		{ID: 2, Name: "prod-db", AccessLevel: "environment_administrator", Source: teamAccessSourceEnvironment, AccessGroupID: 2, AccessGroupName: "production", OverriddenGroupAccess: "readonly_user"},
+		{ID: 4, Name: "sandbox", AccessLevel: "standard_user", Source: teamAccessSourceEnvironment, AccessGroupID: 1, AccessGroupName: "Unassigned"},
+	}, audit.Environments)
+	assert.Equal(t, map[string]int{"readonly_user": 1, "environment_administrator": 1, "standard_user": 1}, audit.AccessLevels)
+	assert.Equal(t, []int{2, 3}, audit.MemberIDs)
+
+	empty := buildTeamAccessAudit(models.Team{ID: 9, Name: "nobody"}, accessGroups, environments)
+	assert.Empty(t, empty.AccessGroups)
+	assert.Empty(t, empty.Environments)
+	assert.NotNil(t, empty.MemberIDs)
+}
+
+// TestHandleAuditTeamAccess verifies the parameters and the errors of the auditTeamAccess
+// tool.
+func TestHandleAuditTeamAccess(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        map[string]any
+		setupMock   func(m *MockPortainerClient)
+		expectError string
+	}{
+		{
+			name: "report",
+			args: map[string]any{"id": float64(5)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetTeam", 5).Return(models.Team{ID: 5, Name: "devs"}, nil)
+				m.On("GetAccessGroups").Return([]models.AccessGroup{{ID: 2, Name: "production", TeamAccesses: map[int]string{5: "standard_user"}}}, nil)
+				m.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{{ID: 1, Name: "prod", GroupID: 2}}, nil)
+			},
+		},
+		{name: "missing id", args: map[string]any{}, expectError: "invalid id parameter"},
+		{name: "invalid id", args: map[string]any{"id": float64(0)}, expectError: "id"},
+		{
+			name: "unknown team",
+			args: map[string]any{"id": float64(5)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetTeam", 5).Return(models.Team{}, errors.New("team not found"))
+			},
+			expectError: "failed to get team",
+		},
+		{
+			name: "access groups error",
+			args: map[string]any{"id": float64(5)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetTeam", 5).Return(models.Team{ID: 5}, nil)
+				m.On("GetAccessGroups").Return(nil, errors.New("forbidden"))
+			},
+			expectError: "failed to get access groups",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleAuditTeamAccess()(context.Background(), CreateMCPRequest(tt.args))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+
+			var audit teamAccessAudit
+			require.NoError(t, json.Unmarshal([]byte(text), &audit))
+			assert.Equal(t, "devs", audit.TeamName)
+			require.Len(t, audit.Environments, 1)
+			assert.Equal(t, "standard_user", audit.Environments[0].AccessLevel)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
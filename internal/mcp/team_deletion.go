@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+)
+
+// Outcomes of the references to a deleted team.
+const (
+	teamReferenceRemoved = "removed"
+	// teamReferenceLeft is a reference left in place by a deletion without cascade.
+	teamReferenceLeft = "left"
+)
+
+// teamReference is an access group, environment or registry giving access to a team.
+type teamReference struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Role is the role of the team on an access group or environment.
+	Role string `json:"role,omitempty"`
+	// EnvironmentIDs are the environments where the team may use a registry.
+	EnvironmentIDs []int `json:"environment_ids,omitempty"`
+	// Outcome is what happened to the reference when the team was deleted.
+	Outcome string `json:"outcome,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// teamDeletionImpact lists the access groups, environment policies and registries that
+// reference a team, and the result of its deletion.
+type teamDeletionImpact struct {
+	TeamID       int             `json:"team_id"`
+	TeamName     string          `json:"team_name"`
+	Members      int             `json:"members"`
+	Preview      bool            `json:"preview,omitempty"`
+	Cascade      bool            `json:"cascade"`
+	Deleted      bool            `json:"deleted"`
+	AccessGroups []teamReference `json:"access_groups"`
+	Environments []teamReference `json:"environments"`
+	Registries   []teamReference `json:"registries"`
+}
+
+// count returns the number of references to the team.
+func (impact *teamDeletionImpact) count() int {
+	return len(impact.AccessGroups) + len(impact.Environments) + len(impact.Registries)
+}
+
+// listTeamReferences lists the access groups and environments whose access policies
+// name a team, and the registries the team may use on some environments.
+func (s *PortainerMCPServer) listTeamReferences(team models.Team) (*teamDeletionImpact, error) {
+	impact := &teamDeletionImpact{
+		TeamID:       team.ID,
+		TeamName:     team.Name,
+		Members:      len(team.MemberIDs),
+		AccessGroups: []teamReference{},
+		Environments: []teamReference{},
+		Registries:   []teamReference{},
+	}
+
+	groups, err := s.cli.GetAccessGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access groups: %w", err)
+	}
+	for _, group := range groups {
+		if role, ok := group.TeamAccesses[team.ID]; ok {
+			impact.AccessGroups = append(impact.AccessGroups, teamReference{ID: group.ID, Name: group.Name, Role: role})
+		}
+	}
+
+	environments, err := s.cli.GetEnvironments(models.EnvironmentListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	for _, environment := range environments {
+		if role, ok := environment.TeamAccesses[team.ID]; ok {
+			impact.Environments = append(impact.Environments, teamReference{ID: environment.ID, Name: environment.Name, Role: role})
+		}
+	}
+
+	registries, err := s.cli.GetRegistries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registries: %w", err)
+	}
+	for _, registry := range registries {
+		var environmentIDs []int
+		for environmentID, teams := range registry.TeamAccesses {
+			if slices.Contains(teams, team.ID) {
+				environmentIDs = append(environmentIDs, environmentID)
+			}
+		}
+		if len(environmentIDs) > 0 {
+			slices.Sort(environmentIDs)
+			impact.Registries = append(impact.Registries, teamReference{ID: registry.ID, Name: registry.Name, EnvironmentIDs: environmentIDs})
+		}
+	}
+
+	return impact, nil
+}
+
+// removeTeamReferences removes a team from the access policies that reference it. It
+// stops at the first failure, which is returned.
+func (s *PortainerMCPServer) removeTeamReferences(impact *teamDeletionImpact) error {
+	for i := range impact.AccessGroups {
+		group := &impact.AccessGroups[i]
+		if _, err := s.cli.AssignAccessGroupRole(group.ID, models.RoleAssigneeTeam, impact.TeamID, 0); err != nil {
+			group.Error = err.Error()
+			return fmt.Errorf("failed to remove the team from access group %d: %w", group.ID, err)
+		}
+		group.Outcome = teamReferenceRemoved
+	}
+
+	for i := range impact.Environments {
+		environment := &impact.Environments[i]
+		if _, err := s.cli.AssignEnvironmentRole(environment.ID, models.RoleAssigneeTeam, impact.TeamID, 0); err != nil {
+			environment.Error = err.Error()
+			return fmt.Errorf("failed to remove the team from environment %d: %w", environment.ID, err)
+		}
+		environment.Outcome = teamReferenceRemoved
+	}
+
+	for i := range impact.Registries {
+		registry := &impact.Registries[i]
+		for _, environmentID := range registry.EnvironmentIDs {
+			if err := s.cli.RemoveRegistryTeamAccess(registry.ID, environmentID, impact.TeamID); err != nil {
+				registry.Error = err.Error()
+				return fmt.Errorf("failed to remove the team from registry %d on environment %d: %w", registry.ID, environmentID, err)
+			}
+		}
+		registry.Outcome = teamReferenceRemoved
+	}
+	return nil
+}
+
+// markTeamReferencesLeft records that the references to a team deleted without cascade
+// were left in place.
+func (impact *teamDeletionImpact) markTeamReferencesLeft() {
+	for _, references := range [][]teamReference{impact.AccessGroups, impact.Environments, impact.Registries} {
+		for i := range references {
+			references[i].Outcome = teamReferenceLeft
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// teamReferencesTestClient returns a client whose team 4 has a role on access group 2 and
+// environment 3, and may use registry 5 on environments 3 and 6.
+func teamReferencesTestClient() *MockPortainerClient {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetTeam", 4).Return(models.Team{ID: 4, Name: "devs", MemberIDs: []int{10, 11}}, nil)
+	mockClient.On("GetAccessGroups").Return([]models.AccessGroup{
+		{ID: 1, Name: "Unassigned", TeamAccesses: map[int]string{7: "Standard User"}},
+		{ID: 2, Name: "production", TeamAccesses: map[int]string{4: "Operator"}},
+	}, nil)
+	mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{
+		{ID: 3, Name: "edge-01", TeamAccesses: map[int]string{4: "Helpdesk"}},
+		{ID: 8, Name: "local"},
+	}, nil)
+	mockClient.On("GetRegistries").Return([]models.Registry{
+		{ID: 5, Name: "harbor", TeamAccesses: map[int][]int{6: {4, 7}, 3: {4}}},
+		{ID: 9, Name: "quay", TeamAccesses: map[int][]int{3: {7}}},
+	}, nil)
+	return mockClient
+}
+
+// deleteTeam calls deleteTeam for team 4.
+func deleteTeam(t *testing.T, mockClient *MockPortainerClient, args map[string]any) (*mcp.CallToolResult, string) {
+	t.Helper()
+	args["id"] = float64(4)
+	s := &PortainerMCPServer{cli: mockClient}
+	result, err := s.HandleDeleteTeam()(context.Background(), CreateMCPRequest(args))
+	require.NoError(t, err)
+	return result, result.Content[0].(mcp.TextContent).Text
+}
+
+// TestHandleDeleteTeamReferences verifies that the references to a team are previewed,
+// removed before its deletion, or reported as left behind.
+func TestHandleDeleteTeamReferences(t *testing.T) {
+	t.Run("preview", func(t *testing.T) {
+		mockClient := teamReferencesTestClient()
+		result, text := deleteTeam(t, mockClient, map[string]any{"preview": true})
+		require.False(t, result.IsError, text)
+		var impact teamDeletionImpact
+		require.NoError(t, json.Unmarshal([]byte(text), &impact))
+		assert.True(t, impact.Preview)
+		assert.False(t, impact.Deleted)
+		assert.Equal(t, 2, impact.Members)
+		assert.Equal(t, []teamReference{{ID: 2, Name: "production", Role: "Operator"}}, impact.AccessGroups)
+		assert.Equal(t, []teamReference{{ID: 3, Name: "edge-01", Role: "Helpdesk"}}, impact.Environments)
+		assert.Equal(t, []teamReference{{ID: 5, Name: "harbor", EnvironmentIDs: []int{3, 6}}}, impact.Registries)
+		mockClient.AssertNotCalled(t, "DeleteTeam", 4)
+	})
+
+	t.Run("cascade", func(t *testing.T) {
+		mockClient := teamReferencesTestClient()
+		mockClient.On("AssignAccessGroupRole", 2, models.RoleAssigneeTeam, 4, 0).Return(0, nil)
+		mockClient.On("AssignEnvironmentRole", 3, models.RoleAssigneeTeam, 4, 0).Return(0, nil)
+		mockClient.On("RemoveRegistryTeamAccess", 5, 3, 4).Return(nil)
+		mockClient.On("RemoveRegistryTeamAccess", 5, 6, 4).Return(nil)
+		mockClient.On("DeleteTeam", 4).Return(nil)
+
+		result, text := deleteTeam(t, mockClient, map[string]any{"cascade": true})
+		require.False(t, result.IsError, text)
+		var impact teamDeletionImpact
+		require.NoError(t, json.Unmarshal([]byte(text), &impact))
+		assert.True(t, impact.Deleted)
+		assert.Equal(t, teamReferenceRemoved, impact.AccessGroups[0].Outcome)
+		assert.Equal(t, teamReferenceRemoved, impact.Environments[0].Outcome)
+		assert.Equal(t, teamReferenceRemoved, impact.Registries[0].Outcome)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("cascade failure", func(t *testing.T) {
+		mockClient := teamReferencesTestClient()
+		mockClient.On("AssignAccessGroupRole", 2, models.RoleAssigneeTeam, 4, 0).Return(0, nil)
+		mockClient.On("AssignEnvironmentRole", 3, models.RoleAssigneeTeam, 4, 0).Return(0, fmt.Errorf("forbidden"))
+
+		result, text := deleteTeam(t, mockClient, map[string]any{"cascade": true})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "team 4 was not deleted: failed to remove the team from environment 3: forbidden")
+		assert.Contains(t, text, `"outcome":"removed"`, "the references already removed are reported")
+		mockClient.AssertNotCalled(t, "RemoveRegistryTeamAccess", 5, 3, 4)
+		mockClient.AssertNotCalled(t, "DeleteTeam", 4)
+	})
+
+	t.Run("without cascade", func(t *testing.T) {
+		mockClient := teamReferencesTestClient()
+		mockClient.On("DeleteTeam", 4).Return(nil)
+
+		result, text := deleteTeam(t, mockClient, map[string]any{})
+		require.False(t, result.IsError, text)
+		var impact teamDeletionImpact
+		require.NoError(t, json.Unmarshal([]byte(text), &impact))
+		assert.True(t, impact.Deleted)
+		assert.Equal(t, teamReferenceLeft, impact.AccessGroups[0].Outcome)
+		assert.Equal(t, teamReferenceLeft, impact.Registries[0].Outcome)
+		mockClient.AssertNotCalled(t, "AssignAccessGroupRole", 2, models.RoleAssigneeTeam, 4, 0)
+	})
+
+	t.Run("listing failure", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetTeam", 4).Return(models.Team{ID: 4}, nil)
+		mockClient.On("GetAccessGroups").Return(nil, fmt.Errorf("unavailable"))
+
+		result, text := deleteTeam(t, mockClient, map[string]any{})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "failed to list access groups: unavailable")
+		mockClient.AssertNotCalled(t, "DeleteTeam", 4)
+	})
+}
@@ -515,6 +515,10 @@ func TestHandleDeleteTeam(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockClient := &MockPortainerClient{}
 			if !tt.expectError || tt.mockError != nil {
+				mockClient.On("GetTeam", tt.inputID).Return(models.Team{ID: tt.inputID, Name: "devs"}, nil)
+				mockClient.On("GetAccessGroups").Return([]models.AccessGroup{}, nil)
+				mockClient.On("GetEnvironments", models.EnvironmentListOptions{}).Return([]models.Environment{}, nil)
+				mockClient.On("GetRegistries").Return([]models.Registry{}, nil)
 				mockClient.On("DeleteTeam", tt.inputID).Return(tt.mockError)
 			}
 
@@ -0,0 +1,368 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/scheduler"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultTimelineLimit and maxTimelineLimit bound the entries of a timeline.
+	defaultTimelineLimit = 200
+	maxTimelineLimit     = 1000
+)
+
+// Sources of the entries of a timeline.
+const (
+	timelineSourceDocker     = "docker"
+	timelineSourceKubernetes = "kubernetes"
+	timelineSourceEdgeJob    = "edge_job"
+)
+
+// timelineEntry is one event of a timeline.
+type timelineEntry struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	// Kind is the type of object the event is about: a Docker object type, a Kubernetes
+	// kind, or "edge_job".
+	Kind   string `json:"kind"`
+	Object string `json:"object"`
+	// Action is the Docker action, the Kubernetes reason, or "scheduled_run" for edge jobs.
+	Action  string `json:"action"`
+	Message string `json:"message,omitempty"`
+	// Count is the number of occurrences of a Kubernetes event.
+	Count int `json:"count,omitempty"`
+	// Warning flags the events that usually matter in an incident: Kubernetes warnings,
+	// containers dying with a non-zero exit code, killed or out of memory, and failing
+	// health checks.
+	Warning bool `json:"warning,omitempty"`
+}
+
+// timelineReport is the result of HandleBuildTimeline.
+type timelineReport struct {
+	EnvironmentID   int             `json:"environment_id"`
+	EnvironmentName string          `json:"environment_name"`
+	EnvironmentType string          `json:"environment_type"`
+	Since           time.Time       `json:"since"`
+	Until           time.Time       `json:"until"`
+	Entries         []timelineEntry `json:"entries"`
+	// Counts is the number of entries found per source, before the limit is applied.
+	Counts   map[string]int `json:"counts"`
+	Warnings int            `json:"warnings"`
+	// Truncated tells that the oldest entries were dropped to honor the limit.
+	Truncated bool `json:"truncated,omitempty"`
+	// Errors lists the sources that could not be read; the timeline holds the others.
+	Errors []string `json:"errors,omitempty"`
+	Notes  []string `json:"notes,omitempty"`
+}
+
+// kubernetesEvent is the part of a Kubernetes event used in a timeline.
+type kubernetesEvent struct {
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message"`
+	Count          int    `json:"count"`
+	FirstTimestamp string `json:"firstTimestamp"`
+	LastTimestamp  string `json:"lastTimestamp"`
+	EventTime      string `json:"eventTime"`
+	InvolvedObject struct {
+		Kind      string `json:"kind"`
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"involvedObject"`
+	Metadata struct {
+		CreationTimestamp string `json:"creationTimestamp"`
+	} `json:"metadata"`
+}
+
+// HandleBuildTimeline returns an MCP tool handler that merges the Docker events or the
+// Kubernetes events of an environment with the scheduled runs of the edge jobs targeting
+// it into one chronological timeline. A source that cannot be read is reported in the
+// errors of the timeline instead of failing the call.
+func (s *PortainerMCPServer) HandleBuildTimeline() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		environmentId, err := parser.GetInt("environmentId", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if err := validatePositiveID("environmentId", environmentId); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		since, until, err := parser.GetTimeRange()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid time range", err), nil
+		}
+		if until.IsZero() {
+			until = time.Now()
+		}
+		if since.IsZero() {
+			since = until.Add(-defaultDockerEventWindow)
+		}
+		if since.After(until) {
+			return mcp.NewToolResultError("since must not be after until"), nil
+		}
+
+		namespace, err := parser.GetString("namespace", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid namespace parameter", err), nil
+		}
+		if strings.Contains(namespace, "/") {
+			return mcp.NewToolResultError("namespace must not contain '/'"), nil
+		}
+
+		warningsOnly, err := parser.GetBoolean("warningsOnly", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid warningsOnly parameter", err), nil
+		}
+
+		limit, err := parser.GetInt("limit", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid limit parameter", err), nil
+		}
+		if limit == 0 {
+			limit = defaultTimelineLimit
+		}
+		if limit < 1 || limit > maxTimelineLimit {
+			return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d, got %d", maxTimelineLimit, limit)), nil
+		}
+
+		env, err := s.cli.GetEnvironment(environmentId)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get environment", err), nil
+		}
+
+		report := timelineReport{
+			EnvironmentID:   env.ID,
+			EnvironmentName: env.Name,
+			EnvironmentType: env.Type,
+			Since:           since.UTC(),
+			Until:           until.UTC(),
+			Counts:          map[string]int{},
+		}
+		var entries []timelineEntry
+
+		switch {
+		case isDockerEnvironment(env):
+			events, err := s.cli.GetDockerEvents(environmentId, models.DockerEventListOptions{Since: since, Until: until})
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("docker events: %v", err))
+			}
+			entries = append(entries, dockerTimelineEntries(events)...)
+			if namespace != "" {
+				report.Notes = append(report.Notes, "namespace only applies to Kubernetes environments and was ignored")
+			}
+		case isKubernetesEnvironment(env):
+			path := "/api/v1/events"
+			if namespace != "" {
+				path = "/api/v1/namespaces/" + namespace + "/events"
+			}
+			var events []kubernetesEvent
+			if err := s.getKubernetesList(environmentId, path, nil, &events); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("kubernetes events: %v", err))
+			}
+			entries = append(entries, kubernetesTimelineEntries(events, since, until)...)
+			report.Notes = append(report.Notes, "Kubernetes keeps events for a limited time (one hour by default), so older events may be missing")
+		default:
+			report.Notes = append(report.Notes, fmt.Sprintf("environment type %s has no event stream", env.Type))
+		}
+
+		if isEdgeEnvironment(env) {
+			jobEntries, err := s.edgeJobTimelineEntries(environmentId, since, until)
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("edge jobs: %v", err))
+			}
+			entries = append(entries, jobEntries...)
+			if len(jobEntries) > 0 {
+				report.Notes = append(report.Notes, "edge job runs are the times scheduled by the cron expression of each job, in UTC; the edge agent runs them when it is reachable")
+			}
+		}
+
+		for _, entry := range entries {
+			report.Counts[entry.Source]++
+			if entry.Warning {
+				report.Warnings++
+			}
+		}
+		if warningsOnly {
+			entries = slices.DeleteFunc(entries, func(entry timelineEntry) bool { return !entry.Warning })
+		}
+		slices.SortStableFunc(entries, func(a, b timelineEntry) int { return a.Time.Compare(b.Time) })
+		if len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+			report.Truncated = true
+			markTruncated(ctx)
+		}
+		report.Entries = entries
+		if report.Entries == nil {
+			report.Entries = []timelineEntry{}
+		}
+
+		return jsonResult(report, "failed to marshal timeline")
+	}
+}
+
+// isKubernetesEnvironment reports whether an environment is a Kubernetes cluster.
+func isKubernetesEnvironment(env models.Environment) bool {
+	switch env.Type {
+	case models.EnvironmentTypeKubernetesLocal, models.EnvironmentTypeKubernetesAgent, models.EnvironmentTypeKubernetesEdgeAgent:
+		return true
+	}
+	return false
+}
+
+// dockerTimelineEntries converts Docker events to timeline entries. Events with an
+// unreadable time are dropped.
+func dockerTimelineEntries(events []models.DockerEvent) []timelineEntry {
+	entries := make([]timelineEntry, 0, len(events))
+	for _, event := range events {
+		t, err := time.Parse(time.RFC3339Nano, event.Time)
+		if err != nil {
+			continue
+		}
+
+		object := event.Attributes["name"]
+		if object == "" {
+			object = shortID(event.ActorID)
+		}
+		entry := timelineEntry{Time: t, Source: timelineSourceDocker, Kind: event.Type, Object: object, Action: event.Action}
+
+		exitCode := event.Attributes["exitCode"]
+		switch {
+		case event.Action == "die" && exitCode != "" && exitCode != "0":
+			entry.Message = "exit code " + exitCode
+			entry.Warning = true
+		case event.Action == "oom", event.Action == "kill":
+			entry.Warning = true
+		case strings.HasPrefix(event.Action, "health_status") && strings.Contains(event.Action, "unhealthy"):
+			entry.Warning = true
+		}
+		if image := event.Attributes["image"]; image != "" && event.Type == "container" && entry.Message == "" {
+			entry.Message = "image " + image
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// kubernetesTimelineEntries converts the Kubernetes events last seen within the time
+// range to timeline entries.
+func kubernetesTimelineEntries(events []kubernetesEvent, since, until time.Time) []timelineEntry {
+	entries := make([]timelineEntry, 0, len(events))
+	for _, event := range events {
+		t, ok := kubernetesEventTime(event)
+		if !ok || t.Before(since) || !t.Before(until) {
+			continue
+		}
+
+		object := event.InvolvedObject.Name
+		if event.InvolvedObject.Namespace != "" {
+			object = event.InvolvedObject.Namespace + "/" + object
+		}
+		entries = append(entries, timelineEntry{
+			Time:    t,
+			Source:  timelineSourceKubernetes,
+			Kind:    event.InvolvedObject.Kind,
+			Object:  object,
+			Action:  event.Reason,
+			Message: event.Message,
+			Count:   event.Count,
+			Warning: event.Type == "Warning",
+		})
+	}
+	return entries
+}
+
+// kubernetesEventTime returns the time an event was last seen, from the first timestamp
+// set among its last timestamp, event time, first timestamp and creation time.
+func kubernetesEventTime(event kubernetesEvent) (time.Time, bool) {
+	for _, value := range []string{event.LastTimestamp, event.EventTime, event.FirstTimestamp, event.Metadata.CreationTimestamp} {
+		if value == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// edgeJobTimelineEntries lists the scheduled runs, within the time range, of the edge jobs
+// targeting an environment directly or through one of its environment groups. A
+// non-recurring job runs once, at the first time of its schedule after its creation.
+func (s *PortainerMCPServer) edgeJobTimelineEntries(environmentId int, since, until time.Time) ([]timelineEntry, error) {
+	jobs, err := s.cli.GetEdgeJobs()
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	groups, err := s.cli.GetEnvironmentGroups()
+	if err != nil {
+		return nil, err
+	}
+	var memberOf []int
+	for _, group := range groups {
+		if slices.Contains(group.EnvironmentIds, environmentId) {
+			memberOf = append(memberOf, group.ID)
+		}
+	}
+
+	var entries []timelineEntry
+	for _, job := range jobs {
+		targeted := slices.Contains(job.Endpoints, environmentId) ||
+			slices.ContainsFunc(job.EdgeGroups, func(id int) bool { return slices.Contains(memberOf, id) })
+		if !targeted {
+			continue
+		}
+
+		schedule, err := scheduler.Parse(job.CronExpression)
+		if err != nil {
+			continue
+		}
+
+		var runs []time.Time
+		if job.Recurring {
+			for t := schedule.Next(since.UTC().Add(-time.Nanosecond)); !t.IsZero() && t.Before(until) && len(runs) < maxTimelineLimit; t = schedule.Next(t) {
+				runs = append(runs, t)
+			}
+		} else {
+			created := time.Unix(job.Created, 0).UTC()
+			if t := schedule.Next(created); !t.IsZero() && !t.Before(since) && t.Before(until) {
+				runs = append(runs, t)
+			}
+		}
+
+		for _, t := range runs {
+			entries = append(entries, timelineEntry{
+				Time:    t,
+				Source:  timelineSourceEdgeJob,
+				Kind:    "edge_job",
+				Object:  job.Name,
+				Action:  "scheduled_run",
+				Message: fmt.Sprintf("edge job %d (%s)", job.ID, job.CronExpression),
+			})
+		}
+	}
+	return entries, nil
+}
+
+// shortID shortens a Docker object ID to its first 12 characters.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
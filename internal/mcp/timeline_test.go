@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// callTimeline calls the buildTimeline handler and decodes its report.
+func callTimeline(t *testing.T, server *PortainerMCPServer, input map[string]any) (timelineReport, string, bool) {
+	t.Helper()
+	result, err := server.HandleBuildTimeline()(context.Background(), CreateMCPRequest(input))
+	require.NoError(t, err)
+	text := result.Content[0].(mcp.TextContent).Text
+	var report timelineReport
+	if !result.IsError {
+		require.NoError(t, json.Unmarshal([]byte(text), &report))
+	}
+	return report, text, result.IsError
+}
+
+// TestHandleBuildTimelineDocker verifies that the Docker events and the scheduled edge job
+// runs of an edge environment are merged in chronological order.
+func TestHandleBuildTimelineDocker(t *testing.T) {
+	since := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	until := since.Add(2 * time.Hour)
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironment", 3).Return(models.Environment{ID: 3, Name: "edge-01", Type: models.EnvironmentTypeDockerEdgeAgent}, nil)
+	mockClient.On("GetDockerEvents", 3, models.DockerEventListOptions{Since: since, Until: until}).Return([]models.DockerEvent{
+		{Time: "2025-06-01T11:30:05Z", Type: "container", Action: "die", ActorID: "0123456789abcdef", Attributes: map[string]string{"name": "api", "exitCode": "137"}},
+		{Time: "2025-06-01T10:15:00Z", Type: "container", Action: "start", ActorID: "0123456789abcdef", Attributes: map[string]string{"name": "api", "image": "api:1.2"}},
+		{Time: "2025-06-01T10:45:00Z", Type: "image", Action: "pull", ActorID: "api:1.3"},
+		{Time: "not a time", Type: "container", Action: "start"},
+	}, nil)
+	mockClient.On("GetEdgeJobs").Return([]models.EdgeJob{
+		{ID: 1, Name: "cleanup", CronExpression: "0 * * * *", Recurring: true, EdgeGroups: []int{5}},
+		{ID: 2, Name: "once", CronExpression: "30 10 1 6 *", Created: since.Add(-24 * time.Hour).Unix(), Endpoints: []int{3}},
+		{ID: 3, Name: "other", CronExpression: "* * * * *", Recurring: true, Endpoints: []int{4}},
+	}, nil)
+	mockClient.On("GetEnvironmentGroups").Return([]models.Group{{ID: 5, EnvironmentIds: []int{3, 4}}, {ID: 6, EnvironmentIds: []int{4}}}, nil)
+
+	server := &PortainerMCPServer{cli: mockClient}
+	report, text, isError := callTimeline(t, server, map[string]any{
+		"environmentId": float64(3),
+		"since":         since.Format(time.RFC3339),
+		"until":         until.Format(time.RFC3339),
+	})
+	require.False(t, isError, text)
+
+	type row struct{ source, object, action string }
+	var rows []row
+	for _, entry := range report.Entries {
+		rows = append(rows, row{entry.Source, entry.Object, entry.Action})
+	}
+	assert.Equal(t, []row{
+		{timelineSourceEdgeJob, "cleanup", "scheduled_run"},
+		{timelineSourceDocker, "api", "start"},
+		{timelineSourceEdgeJob, "once", "scheduled_run"},
+		{timelineSourceDocker, "api:1.3", "pull"},
+		{timelineSourceEdgeJob, "cleanup", "scheduled_run"},
+		{timelineSourceDocker, "api", "die"},
+	}, rows)
+	assert.Equal(t, "image api:1.2", report.Entries[1].Message)
+	assert.True(t, report.Entries[5].Warning)
+	assert.Equal(t, "exit code 137", report.Entries[5].Message)
+	assert.Equal(t, map[string]int{timelineSourceDocker: 3, timelineSourceEdgeJob: 3}, report.Counts)
+	assert.Equal(t, 1, report.Warnings)
+	assert.Empty(t, report.Errors)
+
+	report, _, _ = callTimeline(t, server, map[string]any{
+		"environmentId": float64(3),
+		"since":         since.Format(time.RFC3339),
+		"until":         until.Format(time.RFC3339),
+		"warningsOnly":  true,
+	})
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "die", report.Entries[0].Action)
+
+	report, _, _ = callTimeline(t, server, map[string]any{
+		"environmentId": float64(3),
+		"since":         since.Format(time.RFC3339),
+		"until":         until.Format(time.RFC3339),
+		"limit":         float64(2),
+	})
+	assert.True(t, report.Truncated)
+	require.Len(t, report.Entries, 2)
+	assert.Equal(t, "die", report.Entries[1].Action, "the newest entries are kept")
+}
+
+// TestHandleBuildTimelineKubernetes verifies that the Kubernetes events within the time
+// range become timeline entries, and that a failing source is reported as an error.
+func TestHandleBuildTimelineKubernetes(t *testing.T) {
+	events := `{"items":[
+		{"type":"Warning","reason":"BackOff","message":"Back-off restarting failed container","count":12,"lastTimestamp":"2025-06-01T10:50:00Z","involvedObject":{"kind":"Pod","name":"web-1","namespace":"shop"}},
+		{"type":"Normal","reason":"Scheduled","eventTime":"2025-06-01T10:05:00.000000Z","involvedObject":{"kind":"Pod","name":"web-1","namespace":"shop"}},
+		{"type":"Normal","reason":"Pulled","lastTimestamp":"2025-06-01T08:00:00Z","involvedObject":{"kind":"Pod","name":"web-0","namespace":"shop"}}
+	]}`
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironment", 7).Return(models.Environment{ID: 7, Name: "k8s", Type: models.EnvironmentTypeKubernetesAgent}, nil)
+	mockKubernetesList(mockClient, "/api/v1/namespaces/shop/events", http.StatusOK, events)
+	mockKubernetesList(mockClient, "/api/v1/events", http.StatusForbidden, "forbidden")
+
+	server := &PortainerMCPServer{cli: mockClient}
+	input := map[string]any{"environmentId": float64(7), "since": "2025-06-01T10:00:00Z", "until": "2025-06-01T11:00:00Z", "namespace": "shop"}
+	report, text, isError := callTimeline(t, server, input)
+	require.False(t, isError, text)
+	require.Len(t, report.Entries, 2)
+	assert.Equal(t, "Scheduled", report.Entries[0].Action)
+	assert.Equal(t, timelineEntry{
+		Time: time.Date(2025, 6, 1, 10, 50, 0, 0, time.UTC), Source: timelineSourceKubernetes, Kind: "Pod", Object: "shop/web-1",
+		Action: "BackOff", Message: "Back-off restarting failed container", Count: 12, Warning: true,
+	}, report.Entries[1])
+
+	delete(input, "namespace")
+	report, text, isError = callTimeline(t, server, input)
+	require.False(t, isError, text)
+	assert.Empty(t, report.Entries)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "kubernetes events")
+	mockClient.AssertNotCalled(t, "GetEdgeJobs")
+}
+
+// TestHandleBuildTimelineErrors verifies parameter validation and source failures.
+func TestHandleBuildTimelineErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       map[string]any
+		setupMock   func(*MockPortainerClient)
+		expectError string
+	}{
+		{name: "invalid environment ID", input: map[string]any{"environmentId": float64(0)}, expectError: "environmentId"},
+		{name: "inverted range", input: map[string]any{"environmentId": float64(1), "since": "1h", "until": "2h"}, expectError: "invalid time range"},
+		{name: "invalid limit", input: map[string]any{"environmentId": float64(1), "limit": float64(5000)}, expectError: "limit must be between 1 and 1000"},
+		{name: "invalid namespace", input: map[string]any{"environmentId": float64(1), "namespace": "a/b"}, expectError: "namespace must not contain"},
+		{
+			name:  "environment error",
+			input: map[string]any{"environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEnvironment", 1).Return(models.Environment{}, fmt.Errorf("not found"))
+			},
+			expectError: "failed to get environment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+			_, text, isError := callTimeline(t, &PortainerMCPServer{cli: mockClient}, tt.input)
+			assert.True(t, isError)
+			assert.Contains(t, text, tt.expectError)
+			mockClient.AssertExpectations(t)
+		})
+	}
+
+	t.Run("failing sources", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetEnvironment", 2).Return(models.Environment{ID: 2, Type: models.EnvironmentTypeDockerEdgeAgent}, nil)
+		mockClient.On("GetDockerEvents", 2, mock.Anything).Return(nil, fmt.Errorf("agent unreachable"))
+		mockClient.On("GetEdgeJobs").Return(nil, fmt.Errorf("forbidden"))
+
+		report, text, isError := callTimeline(t, &PortainerMCPServer{cli: mockClient}, map[string]any{"environmentId": float64(2)})
+		require.False(t, isError, text)
+		assert.Equal(t, []string{"docker events: agent unreachable", "edge jobs: forbidden"}, report.Errors)
+		assert.Equal(t, []timelineEntry{}, report.Entries)
+	})
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// toolTimeout is the execution timeout of the tools matching a glob pattern.
+type toolTimeout struct {
+	pattern string
+	timeout time.Duration
+}
+
+// parseToolTimeouts parses a comma-separated list of pattern=duration entries, such as
+// "installHelmChart=5m,list*=15s,manage_helm.install_helm_chart=5m". Patterns are globs
+// matched against the tool name and, for meta-tools, against "<tool>.<action>".
+func parseToolTimeouts(spec string) ([]toolTimeout, error) {
+	var timeouts []toolTimeout
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, value, ok := strings.Cut(entry, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("invalid tool timeout %q: expected pattern=duration", entry)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool timeout pattern %q: %w", pattern, err)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tool timeout duration for %q: %w", pattern, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("tool timeout for %q must be positive, got %s", pattern, d)
+		}
+
+		timeouts = append(timeouts, toolTimeout{pattern: pattern, timeout: d})
+	}
+	return timeouts, nil
+}
+
+// timeoutFor returns the timeout of the first entry matching a tool call, or zero.
+func timeoutFor(timeouts []toolTimeout, tool, action string) time.Duration {
+	names := []string{tool}
+	if action != "" {
+		names = append(names, tool+"."+action)
+	}
+
+	for _, t := range timeouts {
+		for _, name := range names {
+			if ok, _ := path.Match(t.pattern, name); ok {
+				return t.timeout
+			}
+		}
+	}
+	return 0
+}
+
+// timeoutMiddleware returns a tool handler middleware that runs each handler with a
+// context deadline taken from the configured timeouts. When the deadline passes before
+// the handler returns, the call fails with a timeout error; handlers that honor the
+// context (such as the waiting tools) stop early, others finish in the background.
+func timeoutMiddleware(timeouts []toolTimeout) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			action, _ := request.GetArguments()["action"].(string)
+			timeout := timeoutFor(timeouts, request.Params.Name, action)
+			if timeout == 0 {
+				return next(ctx, request)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			type outcome struct {
+				result *mcp.CallToolResult
+				err    error
+			}
+			done := make(chan outcome, 1)
+			go func() {
+				result, err := next(ctx, request)
+				done <- outcome{result, err}
+			}()
+
+			select {
+			case o := <-done:
+				return o.result, o.err
+			case <-ctx.Done():
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return nil, ctx.Err()
+				}
+				name := request.Params.Name
+				if action != "" {
+					name += "." + action
+				}
+				log.Warn().Str("tool", name).Dur("timeout", timeout).Msg("tool call timed out")
+				return newToolResultErrorWithCode(ErrorCodeUpstreamUnavailable, fmt.Sprintf("tool %s timed out after %s", name, timeout)), nil
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseToolTimeouts verifies parsing of pattern=duration lists.
+func TestParseToolTimeouts(t *testing.T) {
+	timeouts, err := parseToolTimeouts(" installHelmChart=5m, list*=15s,,manage_helm.*=2m ")
+	require.NoError(t, err)
+	assert.Equal(t, []toolTimeout{
+		{pattern: "installHelmChart", timeout: 5 * time.Minute},
+		{pattern: "list*", timeout: 15 * time.Second},
+		{pattern: "manage_helm.*", timeout: 2 * time.Minute},
+	}, timeouts)
+
+	for _, spec := range []string{"listStacks", "=5m", "list*=soon", "list*=0s", "[=5m"} {
+		_, err := parseToolTimeouts(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// TestTimeoutFor verifies that the first matching entry applies to tools and meta-tool actions.
+func TestTimeoutFor(t *testing.T) {
+	timeouts := []toolTimeout{
+		{pattern: "manage_helm.install_helm_chart", timeout: 5 * time.Minute},
+		{pattern: "list*", timeout: 15 * time.Second},
+		{pattern: "manage_*", timeout: time.Minute},
+	}
+
+	assert.Equal(t, 15*time.Second, timeoutFor(timeouts, "listStacks", ""))
+	assert.Equal(t, 5*time.Minute, timeoutFor(timeouts, "manage_helm", "install_helm_chart"))
+	assert.Equal(t, time.Minute, timeoutFor(timeouts, "manage_helm", "list_helm_releases"))
+	assert.Zero(t, timeoutFor(timeouts, "installHelmChart", ""))
+}
+
+// TestTimeoutMiddleware verifies that slow handlers fail with a timeout error and
+// receive a context with the configured deadline.
+func TestTimeoutMiddleware(t *testing.T) {
+	middleware := timeoutMiddleware([]toolTimeout{{pattern: "slowTool", timeout: 20 * time.Millisecond}})
+
+	t.Run("handler exceeding timeout", func(t *testing.T) {
+		handler := middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return mcp.NewToolResultText("late"), nil
+		})
+		request := CreateMCPRequest(nil)
+		request.Params.Name = "slowTool"
+
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "tool slowTool timed out after 20ms", result.Content[0].(mcp.TextContent).Text)
+		assert.Equal(t, ErrorCodeUpstreamUnavailable, errorCode(result))
+	})
+
+	t.Run("handler within timeout gets deadline", func(t *testing.T) {
+		handler := middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return mcp.NewToolResultText("ok"), nil
+		})
+		request := CreateMCPRequest(nil)
+		request.Params.Name = "slowTool"
+
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+	})
+
+	t.Run("tool without timeout", func(t *testing.T) {
+		handler := middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			return mcp.NewToolResultText("ok"), nil
+		})
+		request := CreateMCPRequest(nil)
+		request.Params.Name = "otherTool"
+
+		result, err := handler(context.Background(), request)
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+	})
+
+	t.Run("caller cancellation", func(t *testing.T) {
+		handler := middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		request := CreateMCPRequest(nil)
+		request.Params.Name = "slowTool"
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := handler(ctx, request)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// TestNewPortainerMCPServerToolTimeouts verifies that invalid timeouts fail server creation.
+func TestNewPortainerMCPServerToolTimeouts(t *testing.T) {
+	_, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithToolTimeouts("list*=15s"))
+	assert.NoError(t, err)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithToolTimeouts("list*"))
+	assert.ErrorContains(t, err, "failed to parse tool timeouts")
+}
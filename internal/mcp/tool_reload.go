@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// RegisterTools registers the meta-tools, or every granular tool in granular tool mode.
+func (s *PortainerMCPServer) RegisterTools() {
+	for _, p := range s.principals {
+		p.server.RegisterTools()
+	}
+
+	if s.granularTools {
+		s.AddAllFeatures()
+		return
+	}
+	s.RegisterMetaTools()
+}
+
+// toolDefinition returns the definition of a granular tool loaded from the tools file.
+func (s *PortainerMCPServer) toolDefinition(name string) (mcp.Tool, bool) {
+	s.toolsMu.RLock()
+	defer s.toolsMu.RUnlock()
+	tool, ok := s.tools[name]
+	return tool, ok
+}
+
+// registerTool adds a tool to the MCP server and records it as registered. During a
+// reload, the tool is only collected, and ReloadTools replaces the registered tools once
+// they are all known.
+func (s *PortainerMCPServer) registerTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	entry := server.ServerTool{Tool: tool, Handler: handler}
+
+	s.toolsMu.Lock()
+	if s.pending != nil {
+		s.pending[tool.Name] = entry
+		s.toolsMu.Unlock()
+		return
+	}
+	if s.registered == nil {
+		s.registered = map[string]server.ServerTool{}
+	}
+	s.registered[tool.Name] = entry
+	s.toolsMu.Unlock()
+
+	s.srv.AddTool(tool, handler)
+}
+
+// ReloadTools reads the tool definitions file again and, when RBAC filtering is enabled,
+// the access of the API token, then registers the tools again. When the registered tools
+// change, they are replaced at once and the clients are sent a single
+// notifications/tools/list_changed notification so that they list them again. It reports
+// whether the tools changed; on error, the registered tools are kept.
+func (s *PortainerMCPServer) ReloadTools() (bool, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	defs, err := toolgen.LoadToolDefinitionsFromYAML(s.toolsPath, MinimumToolsVersion)
+	if err != nil {
+		return false, fmt.Errorf("failed to load tools: %w", err)
+	}
+	var access *tokenAccess
+	if s.rbacFilter {
+		access, err = loadTokenAccess(s.cli)
+		if err != nil {
+			return false, fmt.Errorf("failed to determine the API token access: %w", err)
+		}
+	}
+
+	s.toolsMu.Lock()
+	s.tools = toolgen.ConvertToolDefinitions(defs)
+	s.access = access
+	s.pending = map[string]server.ServerTool{}
+	s.toolsMu.Unlock()
+	s.argumentRules.Reload(defs)
+
+	s.RegisterTools()
+
+	s.toolsMu.Lock()
+	next := s.pending
+	s.pending = nil
+	changed := !sameTools(s.registered, next)
+	s.registered = next
+	s.toolsMu.Unlock()
+
+	if !changed {
+		return false, nil
+	}
+	names := slices.Sorted(maps.Keys(next))
+	tools := make([]server.ServerTool, 0, len(names))
+	for _, name := range names {
+		tools = append(tools, next[name])
+	}
+	// SetTools notifies the initialized sessions that the tool list changed.
+	s.srv.SetTools(tools...)
+	log.Info().Int("tools", len(tools)).Msg("registered tools changed, clients notified")
+	return true, nil
+}
+
+// sameTools reports whether two sets of registered tools have the same names and
+// definitions. The handlers are not compared.
+func sameTools(a, b map[string]server.ServerTool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, entry := range a {
+		other, ok := b[name]
+		if !ok {
+			return false
+		}
+		x, errX := json.Marshal(entry.Tool)
+		y, errY := json.Marshal(other.Tool)
+		if errX != nil || errY != nil || string(x) != string(y) {
+			return false
+		}
+	}
+	return true
+}
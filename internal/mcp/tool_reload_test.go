@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reloadTestTool is the definition of a tool in a tools file written by the reload tests.
+func reloadTestTool(name string) string {
+	return `  - name: ` + name + `
+    description: "Test tool"
+    annotations:
+      title: Test Tool
+      readOnlyHint: false
+      destructiveHint: false
+      idempotentHint: false
+      openWorldHint: false
+`
+}
+
+// writeReloadTools writes a tools file defining the given tools.
+func writeReloadTools(t *testing.T, path string, names ...string) {
+	t.Helper()
+	content := "version: v1.0\ntools:\n"
+	for _, name := range names {
+		content += reloadTestTool(name)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+// listChangedNotifications returns the number of tools/list_changed notifications received
+// by a session.
+func listChangedNotifications(session *logSession) int {
+	count := 0
+	for _, notification := range session.received() {
+		if notification.Method == mcp.MethodNotificationToolsListChanged {
+			count++
+		}
+	}
+	return count
+}
+
+// TestReloadTools verifies that reloading changed tool definitions replaces the registered
+// tools and notifies the clients once, and that an unchanged reload does not.
+func TestReloadTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	writeReloadTools(t, path, ToolListStacks)
+
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", path,
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithGranularTools(true))
+	require.NoError(t, err)
+	s.RegisterTools()
+	session, _ := initializeLogSession(t, s, "a")
+	session.received()
+	assert.Equal(t, []string{ToolListStacks}, listRegisteredTools(t, s.srv))
+
+	changed, err := s.ReloadTools()
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Zero(t, listChangedNotifications(session))
+
+	writeReloadTools(t, path, ToolListStacks, ToolCreateStack, ToolDeleteStack)
+	changed, err = s.ReloadTools()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, listChangedNotifications(session))
+	assert.Equal(t, []string{ToolCreateStack, ToolDeleteStack, ToolListStacks}, listRegisteredTools(t, s.srv))
+
+	require.NoError(t, os.WriteFile(path, []byte("tools: ["), 0o600))
+	_, err = s.ReloadTools()
+	assert.ErrorContains(t, err, "failed to load tools")
+	assert.Len(t, listRegisteredTools(t, s.srv), 3, "the registered tools are kept on error")
+}
+
+// TestReloadToolsTokenAccess verifies that the tools gated by the API token access follow
+// its changes, such as the token user becoming an administrator.
+func TestReloadToolsTokenAccess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.yaml")
+	writeReloadTools(t, path, ToolListRegularStacks, ToolCreateStack)
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetCurrentUser").Return(models.User{ID: 4, Username: "dev", Role: models.UserRoleUser}, nil).Once()
+	mockClient.On("GetUserMemberships", 4).Return([]models.TeamMembership{}, nil)
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", path,
+		WithClient(mockClient), WithDisableVersionCheck(true), WithGranularTools(true), WithRBACFilter(true))
+	require.NoError(t, err)
+	s.RegisterTools()
+	session, _ := initializeLogSession(t, s, "a")
+	session.received()
+	assert.Equal(t, []string{ToolListRegularStacks}, listRegisteredTools(t, s.srv))
+
+	mockClient.On("GetCurrentUser").Return(models.User{ID: 4, Username: "dev", Role: models.UserRoleAdmin}, nil)
+	changed, err := s.ReloadTools()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, listChangedNotifications(session))
+	assert.Equal(t, []string{ToolCreateStack, ToolListRegularStacks}, listRegisteredTools(t, s.srv))
+}
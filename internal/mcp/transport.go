@@ -0,0 +1,383 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/sessionstate"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+// Transports the server can speak the MCP protocol over
+const (
+	// TransportStdio serves a single client, the parent process, over standard
+	// input/output.
+	TransportStdio = "stdio"
+	// TransportSSE serves any number of remote clients over HTTP with Server-Sent Events.
+	TransportSSE = "sse"
+	// TransportStreamableHTTP serves any number of remote clients over the Streamable HTTP
+	// transport of the MCP specification.
+	TransportStreamableHTTP = "http"
+)
+
+const (
+	// DefaultListenAddr is the default address of the HTTP transports.
+	DefaultListenAddr = ":8084"
+	// httpShutdownTimeout bounds the wait for the running tool calls on shutdown.
+	httpShutdownTimeout = 5 * time.Second
+	// httpEndpointPath is the endpoint of the Streamable HTTP transport.
+	httpEndpointPath = "/mcp"
+	// httpSessionPrefix starts the IDs of the Streamable HTTP sessions.
+	httpSessionPrefix = "mcp-session-"
+)
+
+// validTransport reports whether a transport is supported.
+func validTransport(transport string) bool {
+	return transport == TransportStdio || transport == TransportSSE || transport == TransportStreamableHTTP
+}
+
+// serveStdio serves the MCP protocol over standard input/output until the input is
+// closed, the client stalls or ctx is canceled. When keep-alive is enabled, a silent
+// client is pinged.
+func (s *PortainerMCPServer) serveStdio(ctx context.Context) error {
+	// The listening context is also canceled when the client stalls.
+	listenCtx, cancelListen := context.WithCancel(ctx)
+	defer cancelListen()
+	stalled := make(chan struct{})
+
+	s.client = newClientConn(os.Stdout)
+	if s.keepAliveInterval > 0 {
+		go s.client.keepAlive(listenCtx, s.keepAliveInterval, s.stallTimeout, func() {
+			close(stalled)
+			cancelListen()
+		})
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.NewStdioServer(s.srv).Listen(listenCtx, s.client.input(os.Stdin), s.client)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-stalled:
+		return nil
+	case <-ctx.Done():
+		log.Info().Msg("Received shutdown signal, stopping server")
+		return nil
+	}
+}
+
+// serveSSE serves the MCP protocol over SSE on a listener until ctx is canceled. Clients
+// open an event stream at /sse and post their messages to the endpoint it announces.
+// When keep-alive is enabled, the open streams are pinged at its interval.
+func (s *PortainerMCPServer) serveSSE(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{ReadHeaderTimeout: 10 * time.Second}
+	handler, shutdown := s.httpTransports(func(srv *PortainerMCPServer) httpTransport {
+		opts := []server.SSEOption{server.WithHTTPServer(httpServer)}
+		if s.keepAliveInterval > 0 {
+			opts = append(opts, server.WithKeepAliveInterval(s.keepAliveInterval))
+		}
+		sseServer := server.NewSSEServer(srv.srv, opts...)
+		return httpTransport{handler: sseServer, shutdown: sseServer.Shutdown}
+	})
+	httpServer.Handler = handler
+
+	log.Info().Str("address", listener.Addr().String()).Msg("serving MCP over SSE at /sse")
+	return serveHTTP(ctx, httpServer, listener, "SSE", shutdown)
+}
+
+// serveStreamableHTTP serves the MCP protocol over Streamable HTTP on a listener until ctx
+// is canceled. Clients post their messages to /mcp and may open an event stream there
+// for the server notifications. When keep-alive is enabled, the open streams are pinged
+// at its interval. On shutdown, the event streams are closed at once and the running tool
+// calls are given httpShutdownTimeout to complete.
+func (s *PortainerMCPServer) serveStreamableHTTP(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{ReadHeaderTimeout: 10 * time.Second}
+	streams, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+	httpServer.RegisterOnShutdown(closeStreams)
+
+	handler, shutdown := s.httpTransports(func(srv *PortainerMCPServer) httpTransport {
+		opts := []server.StreamableHTTPOption{
+			server.WithStreamableHTTPServer(httpServer),
+			server.WithSessionIdManager(newHTTPSessions(sessionstate.DefaultTTL, srv.sessionState)),
+		}
+		if s.keepAliveInterval > 0 {
+			opts = append(opts, server.WithHeartbeatInterval(s.keepAliveInterval))
+		}
+		streamable := server.NewStreamableHTTPServer(srv.srv, opts...)
+		mux := http.NewServeMux()
+		mux.Handle(httpEndpointPath, closeStreamsOnShutdown(streams, streamable))
+		return httpTransport{handler: mux, shutdown: streamable.Shutdown}
+	})
+	httpServer.Handler = handler
+
+	log.Info().Str("address", listener.Addr().String()).Msg("serving MCP over Streamable HTTP at " + httpEndpointPath)
+	return serveHTTP(ctx, httpServer, listener, "Streamable HTTP", shutdown)
+}
+
+// httpTransport is the HTTP handler of the MCP protocol for one server, and the function
+// stopping it.
+type httpTransport struct {
+	handler  http.Handler
+	shutdown func(context.Context) error
+}
+
+// httpTransports creates with newTransport the transport of the server and that of each
+// principal. It returns the handler authenticating the requests and routing each to the
+// transport of its bearer token, and the function stopping every transport. The
+// transports stop together, as each ends its own event streams before waiting for the
+// HTTP server they share.
+func (s *PortainerMCPServer) httpTransports(newTransport func(*PortainerMCPServer) httpTransport) (http.Handler, func(context.Context) error) {
+	primary := newTransport(s)
+	transports := []httpTransport{primary}
+	principals := make(map[string]http.Handler, len(s.principals))
+	for _, p := range s.principals {
+		transport := newTransport(p.server)
+		transports = append(transports, transport)
+		principals[p.token] = transport.handler
+	}
+
+	shutdown := func(ctx context.Context) error {
+		errs := make([]error, len(transports))
+		var wg sync.WaitGroup
+		for i, transport := range transports {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = transport.shutdown(ctx)
+			}()
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	}
+	return s.authenticate(primary.handler, principals), shutdown
+}
+
+// serveHTTP runs an HTTP transport until ctx is canceled, then stops it with shutdown.
+func serveHTTP(ctx context.Context, httpServer *http.Server, listener net.Listener, name string, shutdown func(context.Context) error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("%s transport stopped: %w", name, err)
+	case <-ctx.Done():
+		log.Info().Msg("Received shutdown signal, stopping server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := shutdown(shutdownCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("failed to stop the %s transport: %w", name, err)
+		}
+		return nil
+	}
+}
+
+// closeStreamsOnShutdown ends the event streams opened with GET requests once streams is
+// canceled. Unlike tool calls, they never complete on their own and would hold the
+// shutdown until its timeout.
+func closeStreamsOnShutdown(streams context.Context, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			stop := context.AfterFunc(streams, cancel)
+			defer stop()
+			r = r.WithContext(ctx)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate routes the HTTP requests by bearer token: the token of a principal to the
+// handler of the principal, and the auth token of the server to next. Requests carrying
+// no known token are rejected. Without any token, every request is let through to next.
+func (s *PortainerMCPServer) authenticate(next http.Handler, principals map[string]http.Handler) http.Handler {
+	if s.httpAuthToken == "" && len(principals) == 0 {
+		return next
+	}
+
+	type route struct {
+		authorization []byte
+		handler       http.Handler
+	}
+	routes := make([]route, 0, len(principals)+1)
+	if s.httpAuthToken != "" {
+		routes = append(routes, route{authorization: []byte("Bearer " + s.httpAuthToken), handler: next})
+	}
+	for token, handler := range principals {
+		routes = append(routes, route{authorization: []byte("Bearer " + token), handler: handler})
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := []byte(r.Header.Get("Authorization"))
+		var handler http.Handler
+		// Every token is compared, so that the time taken does not tell which one matched.
+		for _, route := range routes {
+			if subtle.ConstantTimeCompare(authorization, route.authorization) == 1 {
+				handler = route.handler
+			}
+		}
+		if handler == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="portainer-mcp"`)
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// httpSessions tracks the Streamable HTTP sessions. A session ends when its client
+// deletes it or stays idle for the idle timeout; requests of an ended session, or of a
+// session the server does not know, are answered 404 Not Found, so that the client
+// initializes a new one. The only unknown IDs adopted are those of the persisted session
+// state, issued before a restart, so that their clients keep their budget and plans; the
+// state of a session is dropped when it ends, so that an ended session is never revived.
+type httpSessions struct {
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	now         func() time.Time
+	// state is the persisted session state (nil when session state is not persisted).
+	state *sessionstate.Store
+	// lastSeen is the time of the last request of each live session.
+	lastSeen map[string]time.Time
+	// ended is the time each ended session ended; it is forgotten after the idle timeout.
+	ended map[string]time.Time
+}
+
+// newHTTPSessions creates a session tracker ending the sessions idle for idleTimeout and
+// adopting the sessions of state, which may be nil.
+func newHTTPSessions(idleTimeout time.Duration, state *sessionstate.Store) *httpSessions {
+	return &httpSessions{
+		idleTimeout: idleTimeout,
+		now:         time.Now,
+		state:       state,
+		lastSeen:    map[string]time.Time{},
+		ended:       map[string]time.Time{},
+	}
+}
+
+// Generate returns the ID of a new session.
+func (h *httpSessions) Generate() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand does not fail on supported platforms.
+		panic(fmt.Sprintf("failed to generate session ID: %v", err))
+	}
+	id := httpSessionPrefix + hex.EncodeToString(buf)
+
+	h.mu.Lock()
+	now := h.now()
+	ended := h.prune(now)
+	h.lastSeen[id] = now
+	h.mu.Unlock()
+
+	h.dropState(ended...)
+	return id
+}
+
+// Validate reports whether a session has ended, and records the activity of a live one.
+func (h *httpSessions) Validate(sessionID string) (isTerminated bool, err error) {
+	if !validHTTPSessionID(sessionID) {
+		return false, fmt.Errorf("invalid session ID %q", sessionID)
+	}
+
+	h.mu.Lock()
+	now := h.now()
+	if _, ok := h.ended[sessionID]; ok {
+		h.mu.Unlock()
+		return true, nil
+	}
+	last, ok := h.lastSeen[sessionID]
+	switch {
+	case ok && now.Sub(last) >= h.idleTimeout:
+		delete(h.lastSeen, sessionID)
+		h.ended[sessionID] = now
+		h.mu.Unlock()
+		h.dropState(sessionID)
+		return true, nil
+	case !ok && (h.state == nil || !h.state.Has(sessionID)):
+		h.mu.Unlock()
+		return true, nil
+	}
+	h.lastSeen[sessionID] = now
+	h.mu.Unlock()
+	return false, nil
+}
+
+// Terminate ends a session at the request of its client.
+func (h *httpSessions) Terminate(sessionID string) (isNotAllowed bool, err error) {
+	if !validHTTPSessionID(sessionID) {
+		return false, fmt.Errorf("invalid session ID %q", sessionID)
+	}
+
+	h.mu.Lock()
+	delete(h.lastSeen, sessionID)
+	h.ended[sessionID] = h.now()
+	h.mu.Unlock()
+
+	h.dropState(sessionID)
+	log.Debug().Str("session", sessionID).Msg("HTTP session terminated by its client")
+	return false, nil
+}
+
+// prune ends the idle sessions, returning their IDs, and forgets the sessions ended for
+// the idle timeout, which can no longer be adopted since their state was dropped. The
+// caller must hold the lock.
+func (h *httpSessions) prune(now time.Time) []string {
+	var ended []string
+	for id, last := range h.lastSeen {
+		if now.Sub(last) >= h.idleTimeout {
+			delete(h.lastSeen, id)
+			h.ended[id] = now
+			ended = append(ended, id)
+		}
+	}
+	for id, at := range h.ended {
+		if now.Sub(at) >= h.idleTimeout {
+			delete(h.ended, id)
+		}
+	}
+	return ended
+}
+
+// dropState drops the persisted state of ended sessions, so that they are not adopted
+// after a restart. A failure is logged.
+func (h *httpSessions) dropState(ids ...string) {
+	if h.state == nil {
+		return
+	}
+	for _, id := range ids {
+		if err := h.state.Update(id, func(st *sessionstate.Session) { *st = sessionstate.Session{} }); err != nil {
+			log.Warn().Err(err).Str("session", id).Msg("failed to drop the state of an ended HTTP session")
+		}
+	}
+}
+
+// validHTTPSessionID reports whether a session ID has the format of the generated IDs.
+func validHTTPSessionID(id string) bool {
+	suffix, ok := strings.CutPrefix(id, httpSessionPrefix)
+	if !ok || len(suffix) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(suffix)
+	return err == nil
+}
@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/sessionstate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewPortainerMCPServerTransport verifies the transport defaults and validation.
+func TestNewPortainerMCPServerTransport(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true))
+	require.NoError(t, err)
+	assert.Equal(t, TransportStdio, s.transport)
+	assert.Equal(t, DefaultListenAddr, s.listenAddr)
+
+	s, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithTransport(TransportSSE, "127.0.0.1:9000"))
+	require.NoError(t, err)
+	assert.Equal(t, TransportSSE, s.transport)
+	assert.Equal(t, "127.0.0.1:9000", s.listenAddr)
+
+	_, err = NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithTransport("websocket", ""))
+	assert.ErrorContains(t, err, `invalid transport "websocket": must be stdio, sse or http`)
+}
+
+// TestServeSSE verifies that a client opening an event stream is told where to post its
+// messages and receives the responses on the stream, and that the transport stops with
+// its context.
+func TestServeSSE(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithTransport(TransportSSE, ""))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serveSSE(ctx, listener)
+	}()
+	baseURL := "http://" + listener.Addr().String()
+
+	stream, err := http.Get(baseURL + "/sse")
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	events := bufio.NewScanner(stream.Body)
+	readData := func() string {
+		t.Helper()
+		for events.Scan() {
+			if data, ok := strings.CutPrefix(events.Text(), "data: "); ok {
+				return data
+			}
+		}
+		t.Fatal("event stream closed")
+		return ""
+	}
+
+	endpoint := readData()
+	require.True(t, strings.HasPrefix(endpoint, "/message?sessionId="), endpoint)
+
+	response, err := http.Post(baseURL+endpoint, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, readData())
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * httpShutdownTimeout):
+		t.Fatal("SSE transport not stopped")
+	}
+}
+
+// TestServeStreamableHTTP verifies the bearer token check, the session lifecycle of the
+// Streamable HTTP transport, and that open event streams do not hold the shutdown.
+func TestServeStreamableHTTP(t *testing.T) {
+	s, err := NewPortainerMCPServer("https://portainer.example.com", "token", "testdata/valid_tools.yaml",
+		WithClient(&MockPortainerClient{}), WithDisableVersionCheck(true), WithTransport(TransportStreamableHTTP, ""), WithHTTPAuthToken("s3cret"))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serveStreamableHTTP(ctx, listener)
+	}()
+	endpoint := "http://" + listener.Addr().String() + httpEndpointPath
+
+	send := func(method, token, session, body string) (*http.Response, string) {
+		t.Helper()
+		request, err := http.NewRequest(method, endpoint, strings.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		if session != "" {
+			request.Header.Set("Mcp-Session-Id", session)
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		data, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response, string(data)
+	}
+	const initialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	const ping = `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	response, _ := send(http.MethodPost, "", "", initialize)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, `Bearer realm="portainer-mcp"`, response.Header.Get("WWW-Authenticate"))
+	response, _ = send(http.MethodPost, "wrong", "", initialize)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	response, body := send(http.MethodPost, "s3cret", "", initialize)
+	require.Equal(t, http.StatusOK, response.StatusCode, body)
+	session := response.Header.Get("Mcp-Session-Id")
+	require.True(t, validHTTPSessionID(session), session)
+	assert.Contains(t, body, `"serverInfo"`)
+
+	response, body = send(http.MethodPost, "s3cret", session, ping)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"result":{}}`, body)
+
+	response, _ = send(http.MethodPost, "s3cret", "not-a-session", ping)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, _ = send(http.MethodDelete, "s3cret", session, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	response, _ = send(http.MethodPost, "s3cret", session, ping)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode, "a terminated session must be initialized again")
+
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer s3cret")
+	stream, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer stream.Body.Close()
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	start := time.Now()
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), httpShutdownTimeout, "the open event stream is closed at once")
+	case <-time.After(2 * httpShutdownTimeout):
+		t.Fatal("Streamable HTTP transport not stopped")
+	}
+}
+
+// TestHTTPSessions verifies that sessions end when idle or terminated, that only the
+// unknown IDs of the persisted session state are adopted, and that ended sessions are
+// never revived.
+func TestHTTPSessions(t *testing.T) {
+	state, err := sessionstate.New("", sessionstate.DefaultTTL)
+	require.NoError(t, err)
+	restarted := httpSessionPrefix + strings.Repeat("ab", 16)
+	require.NoError(t, state.Update(restarted, func(st *sessionstate.Session) { st.Writes = 1 }))
+
+	sessions := newHTTPSessions(time.Hour, state)
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	sessions.now = func() time.Time { return now }
+
+	id := sessions.Generate()
+	assert.True(t, validHTTPSessionID(id))
+	idle := sessions.Generate()
+	assert.NotEqual(t, id, idle)
+
+	terminated, err := sessions.Validate(id)
+	require.NoError(t, err)
+	assert.False(t, terminated)
+
+	now = now.Add(59 * time.Minute)
+	terminated, _ = sessions.Validate(id)
+	assert.False(t, terminated, "activity keeps the session alive")
+	now = now.Add(time.Hour)
+	terminated, _ = sessions.Validate(id)
+	assert.True(t, terminated, "an idle session ends")
+
+	terminated, err = sessions.Validate(restarted)
+	require.NoError(t, err)
+	assert.False(t, terminated, "a session of the persisted state is adopted")
+	unknown := httpSessionPrefix + strings.Repeat("cd", 16)
+	terminated, err = sessions.Validate(unknown)
+	require.NoError(t, err)
+	assert.True(t, terminated, "an unknown session without persisted state is not adopted")
+
+	_, err = sessions.Terminate(restarted)
+	require.NoError(t, err)
+	terminated, _ = sessions.Validate(restarted)
+	assert.True(t, terminated)
+	assert.False(t, state.Has(restarted), "the state of an ended session is dropped")
+
+	_, err = sessions.Validate("mcp-session-xyz")
+	assert.ErrorContains(t, err, "invalid session ID")
+	_, err = sessions.Terminate("")
+	assert.Error(t, err)
+
+	now = now.Add(2 * time.Hour)
+	sessions.Generate()
+	assert.Len(t, sessions.lastSeen, 1, "idle sessions are pruned")
+	assert.Contains(t, sessions.ended, idle)
+	assert.NotContains(t, sessions.ended, id, "sessions ended for the idle timeout are forgotten")
+	assert.NotContains(t, sessions.ended, restarted)
+	terminated, _ = sessions.Validate(restarted)
+	assert.True(t, terminated, "a forgotten session is not revived")
+	terminated, _ = sessions.Validate(id)
+	assert.True(t, terminated)
+}
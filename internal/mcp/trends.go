@@ -0,0 +1,322 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/trends"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultTrendInterval is the default delay between two samples of the trend history.
+	DefaultTrendInterval = time.Hour
+	// DefaultTrendHistorySize is the default number of samples kept in the trend history,
+	// 30 days of hourly samples.
+	DefaultTrendHistorySize = 720
+	// defaultTrendWindow is the period covered by HandleGetTrends when since is not set.
+	defaultTrendWindow = 7 * 24 * time.Hour
+	// defaultTrendPoints and maxTrendPoints bound the series returned by HandleGetTrends.
+	defaultTrendPoints = 24
+	maxTrendPoints     = 500
+)
+
+// trendCounts holds the counts of an environment, or of the fleet, in a sample.
+type trendCounts struct {
+	Containers int `json:"containers"`
+	Running    int `json:"running"`
+	Stopped    int `json:"stopped"`
+	Unhealthy  int `json:"unhealthy"`
+	Stacks     int `json:"stacks"`
+	Images     int `json:"images"`
+	Volumes    int `json:"volumes"`
+}
+
+// trendDelta compares the counts of the first and the last sample of a trend report.
+type trendDelta struct {
+	From   trendCounts `json:"from"`
+	To     trendCounts `json:"to"`
+	Change trendCounts `json:"change"`
+}
+
+// environmentTrend describes how the counts of an environment changed.
+type environmentTrend struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// PreviousStatus is the status in the first sample, when it differs.
+	PreviousStatus string `json:"previous_status,omitempty"`
+	// Added and Removed tell that the environment is missing from the first or the last
+	// sample; the missing counts are then zero.
+	Added   bool `json:"added,omitempty"`
+	Removed bool `json:"removed,omitempty"`
+	trendDelta
+}
+
+// trendPoint is the fleet totals of one sample.
+type trendPoint struct {
+	TakenAt time.Time `json:"taken_at"`
+	trendCounts
+}
+
+// trendReport is the result of HandleGetTrends.
+type trendReport struct {
+	Since time.Time `json:"since"`
+	// From and To are the times of the first and the last sample of the period.
+	From    *time.Time `json:"from,omitempty"`
+	To      *time.Time `json:"to,omitempty"`
+	Samples int        `json:"samples"`
+	Totals  trendDelta `json:"totals"`
+	// Environments lists the environments whose counts or status changed, or the
+	// requested one.
+	Environments []environmentTrend `json:"environments"`
+	// Unchanged is the number of environments whose counts did not change.
+	Unchanged int `json:"unchanged"`
+	// Series is the fleet totals over the period, evenly picked among the samples.
+	Series []trendPoint `json:"series"`
+	Notes  []string     `json:"notes,omitempty"`
+}
+
+// HandleGetTrends returns an MCP tool handler that reports how the container, stack, image
+// and volume counts of the fleet, or of one environment, changed over a period, from the
+// samples of the local trend history.
+func (s *PortainerMCPServer) HandleGetTrends() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		since, err := parser.GetTime("since", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid since parameter", err), nil
+		}
+		if since.IsZero() {
+			since = time.Now().Add(-defaultTrendWindow)
+		}
+
+		environmentId, err := parser.GetInt("environmentId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if environmentId != 0 {
+			if err := validatePositiveID("environmentId", environmentId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		points, err := parser.GetInt("points", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid points parameter", err), nil
+		}
+		if points == 0 {
+			points = defaultTrendPoints
+		}
+		if points < 2 || points > maxTrendPoints {
+			return mcp.NewToolResultError(fmt.Sprintf("points must be between 2 and %d, got %d", maxTrendPoints, points)), nil
+		}
+
+		if s.trends == nil {
+			return mcp.NewToolResultError("the trend history is disabled (start the server with -trend-interval and -trend-history-size greater than 0)"), nil
+		}
+
+		samples := s.trends.Since(since)
+		if environmentId != 0 {
+			samples = environmentSamples(samples, environmentId)
+			if !slices.ContainsFunc(samples, func(sample trends.Sample) bool { return len(sample.Environments) > 0 }) {
+				return mcp.NewToolResultError(fmt.Sprintf("environment %d has no snapshot in the trend history since %s", environmentId, since.UTC().Format(time.RFC3339))), nil
+			}
+		}
+
+		report := buildTrendReport(samples, points, environmentId != 0)
+		report.Since = since.UTC()
+		switch {
+		case report.Samples == 0:
+			report.Notes = append(report.Notes, fmt.Sprintf("no sample was recorded since %s; samples are taken every %s", report.Since.Format(time.RFC3339), s.trendInterval))
+		case report.Samples == 1:
+			report.Notes = append(report.Notes, "only one sample was recorded in the period, so no change can be measured yet")
+		case report.From.Sub(report.Since) > s.trendInterval:
+			report.Notes = append(report.Notes, fmt.Sprintf("the history starts at %s, after the requested start", report.From.Format(time.RFC3339)))
+		}
+
+		return jsonResult(report, "failed to marshal trend report")
+	}
+}
+
+// environmentSamples keeps only one environment in every sample.
+func environmentSamples(samples []trends.Sample, environmentId int) []trends.Sample {
+	filtered := make([]trends.Sample, len(samples))
+	for i, sample := range samples {
+		filtered[i] = trends.Sample{TakenAt: sample.TakenAt, Environments: []trends.Environment{}}
+		if idx := slices.IndexFunc(sample.Environments, func(e trends.Environment) bool { return e.ID == environmentId }); idx >= 0 {
+			filtered[i].Environments = append(filtered[i].Environments, sample.Environments[idx])
+		}
+	}
+	return filtered
+}
+
+// buildTrendReport compares the first and the last of the samples, and picks up to
+// points samples for the series of fleet totals. Unchanged environments are only counted
+// unless includeUnchanged is set.
+func buildTrendReport(samples []trends.Sample, points int, includeUnchanged bool) trendReport {
+	report := trendReport{Samples: len(samples), Environments: []environmentTrend{}, Series: []trendPoint{}}
+	if len(samples) == 0 {
+		return report
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	report.From, report.To = &first.TakenAt, &last.TakenAt
+	report.Totals = newTrendDelta(sampleTotals(first), sampleTotals(last))
+
+	before := map[int]trends.Environment{}
+	for _, e := range first.Environments {
+		before[e.ID] = e
+	}
+	after := map[int]trends.Environment{}
+	for _, e := range last.Environments {
+		after[e.ID] = e
+	}
+
+	for _, e := range last.Environments {
+		old, existed := before[e.ID]
+		trend := environmentTrend{ID: e.ID, Name: e.Name, Status: e.Status, trendDelta: newTrendDelta(environmentCounts(old), environmentCounts(e))}
+		switch {
+		case !existed:
+			trend.Added = true
+		case old.Status != e.Status:
+			trend.PreviousStatus = old.Status
+		case trend.Change == (trendCounts{}) && !includeUnchanged:
+			report.Unchanged++
+			continue
+		}
+		report.Environments = append(report.Environments, trend)
+	}
+	for _, e := range first.Environments {
+		if _, exists := after[e.ID]; !exists {
+			report.Environments = append(report.Environments, environmentTrend{
+				ID: e.ID, Name: e.Name, Status: e.Status, Removed: true,
+				trendDelta: newTrendDelta(environmentCounts(e), trendCounts{}),
+			})
+		}
+	}
+	slices.SortFunc(report.Environments, func(a, b environmentTrend) int { return a.ID - b.ID })
+
+	for _, i := range seriesIndexes(len(samples), points) {
+		report.Series = append(report.Series, trendPoint{TakenAt: samples[i].TakenAt, trendCounts: sampleTotals(samples[i])})
+	}
+	return report
+}
+
+// seriesIndexes picks up to points indexes evenly spread over n samples, always keeping
+// the first and the last.
+func seriesIndexes(n, points int) []int {
+	if n <= points {
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes
+	}
+	indexes := make([]int, points)
+	for i := range indexes {
+		indexes[i] = i * (n - 1) / (points - 1)
+	}
+	return indexes
+}
+
+// newTrendDelta compares two sets of counts.
+func newTrendDelta(from, to trendCounts) trendDelta {
+	return trendDelta{
+		From: from,
+		To:   to,
+		Change: trendCounts{
+			Containers: to.Containers - from.Containers,
+			Running:    to.Running - from.Running,
+			Stopped:    to.Stopped - from.Stopped,
+			Unhealthy:  to.Unhealthy - from.Unhealthy,
+			Stacks:     to.Stacks - from.Stacks,
+			Images:     to.Images - from.Images,
+			Volumes:    to.Volumes - from.Volumes,
+		},
+	}
+}
+
+// environmentCounts returns the counts of an environment in a sample.
+func environmentCounts(e trends.Environment) trendCounts {
+	return trendCounts{
+		Containers: e.Containers,
+		Running:    e.Running,
+		Stopped:    e.Stopped,
+		Unhealthy:  e.Unhealthy,
+		Stacks:     e.Stacks,
+		Images:     e.Images,
+		Volumes:    e.Volumes,
+	}
+}
+
+// sampleTotals sums the counts of the environments of a sample.
+func sampleTotals(sample trends.Sample) trendCounts {
+	var totals trendCounts
+	for _, e := range sample.Environments {
+		c := environmentCounts(e)
+		totals.Containers += c.Containers
+		totals.Running += c.Running
+		totals.Stopped += c.Stopped
+		totals.Unhealthy += c.Unhealthy
+		totals.Stacks += c.Stacks
+		totals.Images += c.Images
+		totals.Volumes += c.Volumes
+	}
+	return totals
+}
+
+// recordTrendSample records the latest snapshot counts of every environment in the trend
+// history. Environments without a snapshot are left out.
+func (s *PortainerMCPServer) recordTrendSample() error {
+	snapshots, err := s.cli.GetEnvironmentSnapshots(models.EnvironmentListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get environment snapshots: %w", err)
+	}
+
+	sample := trends.Sample{Environments: make([]trends.Environment, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		if snapshot.SnapshotTime == 0 {
+			continue
+		}
+		sample.Environments = append(sample.Environments, trends.Environment{
+			ID:         snapshot.EnvironmentID,
+			Name:       snapshot.Name,
+			Status:     snapshot.Status,
+			Containers: snapshot.Containers,
+			Running:    snapshot.Running,
+			Stopped:    snapshot.Stopped,
+			Unhealthy:  snapshot.Unhealthy,
+			Stacks:     snapshot.Stacks,
+			Images:     snapshot.Images,
+			Volumes:    snapshot.Volumes,
+		})
+	}
+
+	_, err = s.trends.Record(sample)
+	return err
+}
+
+// runTrendSampler records a trend sample now and then every trend interval until ctx is
+// canceled. Failed samples are logged and skipped.
+func (s *PortainerMCPServer) runTrendSampler(ctx context.Context) {
+	ticker := time.NewTicker(s.trendInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.recordTrendSample(); err != nil {
+			log.Warn().Err(err).Msg("failed to record trend sample")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
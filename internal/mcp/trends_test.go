@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/trends"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTrendTestServer creates a server with a mock client and an in-memory trend history
+// holding the given samples.
+func newTrendTestServer(t *testing.T, samples ...trends.Sample) *PortainerMCPServer {
+	t.Helper()
+	store, err := trends.New("", DefaultTrendHistorySize)
+	require.NoError(t, err)
+	for _, sample := range samples {
+		_, err := store.Record(sample)
+		require.NoError(t, err)
+	}
+	return &PortainerMCPServer{cli: &MockPortainerClient{}, trends: store, trendInterval: time.Hour}
+}
+
+// TestHandleGetTrends verifies the HandleGetTrends MCP tool handler.
+func TestHandleGetTrends(t *testing.T) {
+	start := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Hour)
+	samples := []trends.Sample{
+		{TakenAt: start, Environments: []trends.Environment{
+			{ID: 1, Name: "prod", Status: "up", Containers: 10, Running: 9, Stopped: 1, Stacks: 3},
+			{ID: 2, Name: "staging", Status: "up", Containers: 4, Running: 4, Stacks: 1},
+			{ID: 3, Name: "old", Status: "down", Containers: 2, Stopped: 2},
+		}},
+		{TakenAt: start.Add(24 * time.Hour), Environments: []trends.Environment{
+			{ID: 1, Name: "prod", Status: "up", Containers: 12, Running: 11, Stopped: 1, Stacks: 4},
+			{ID: 2, Name: "staging", Status: "up", Containers: 4, Running: 4, Stacks: 1},
+		}},
+		{TakenAt: start.Add(47 * time.Hour), Environments: []trends.Environment{
+			{ID: 1, Name: "prod", Status: "up", Containers: 13, Running: 12, Stopped: 1, Stacks: 4},
+			{ID: 2, Name: "staging", Status: "up", Containers: 4, Running: 4, Stacks: 1},
+			{ID: 4, Name: "edge", Status: "up", Containers: 1, Running: 1},
+		}},
+	}
+
+	t.Run("fleet changes", func(t *testing.T) {
+		server := newTrendTestServer(t, samples...)
+		result, err := server.HandleGetTrends()(context.Background(), CreateMCPRequest(map[string]any{"since": start.Format(time.RFC3339), "points": float64(2)}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		var report trendReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Equal(t, 3, report.Samples)
+		assert.Equal(t, start, *report.From)
+		assert.Equal(t, trendCounts{Containers: 16, Running: 13, Stopped: 3, Stacks: 4}, report.Totals.From)
+		assert.Equal(t, trendCounts{Containers: 18, Running: 17, Stopped: 1, Stacks: 5}, report.Totals.To)
+		assert.Equal(t, trendCounts{Containers: 2, Running: 4, Stopped: -2, Stacks: 1}, report.Totals.Change)
+		assert.Equal(t, 1, report.Unchanged)
+
+		require.Len(t, report.Environments, 3)
+		assert.Equal(t, 1, report.Environments[0].ID)
+		assert.False(t, report.Environments[0].Added)
+		assert.Equal(t, trendCounts{Containers: 3, Running: 3, Stacks: 1}, report.Environments[0].Change)
+		assert.True(t, report.Environments[1].Removed)
+		assert.Equal(t, 3, report.Environments[1].ID)
+		assert.Equal(t, trendCounts{Containers: -2, Stopped: -2}, report.Environments[1].Change)
+		assert.True(t, report.Environments[2].Added)
+		assert.Equal(t, 4, report.Environments[2].ID)
+
+		require.Len(t, report.Series, 2)
+		assert.Equal(t, start, report.Series[0].TakenAt)
+		assert.Equal(t, start.Add(47*time.Hour), report.Series[1].TakenAt)
+		assert.Equal(t, 18, report.Series[1].Containers)
+		assert.Empty(t, report.Notes)
+	})
+
+	t.Run("one environment since a time", func(t *testing.T) {
+		server := newTrendTestServer(t, samples...)
+		since := start.Add(12 * time.Hour).Format(time.RFC3339)
+		result, err := server.HandleGetTrends()(context.Background(), CreateMCPRequest(map[string]any{"since": since, "environmentId": float64(2)}))
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+
+		var report trendReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		assert.Equal(t, 2, report.Samples)
+		require.Len(t, report.Environments, 1, "the requested environment is listed even unchanged")
+		assert.Equal(t, "staging", report.Environments[0].Name)
+		assert.Equal(t, trendCounts{}, report.Environments[0].Change)
+		assert.Equal(t, trendCounts{Containers: 4, Running: 4, Stacks: 1}, report.Totals.To)
+		require.Len(t, report.Notes, 1)
+		assert.Contains(t, report.Notes[0], "the history starts at")
+	})
+
+	t.Run("status change", func(t *testing.T) {
+		server := newTrendTestServer(t,
+			trends.Sample{TakenAt: start, Environments: []trends.Environment{{ID: 1, Name: "prod", Status: "up", Containers: 2}}},
+			trends.Sample{TakenAt: start.Add(time.Hour), Environments: []trends.Environment{{ID: 1, Name: "prod", Status: "down", Containers: 2}}},
+		)
+		result, err := server.HandleGetTrends()(context.Background(), CreateMCPRequest(map[string]any{}))
+		require.NoError(t, err)
+
+		var report trendReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &report))
+		require.Len(t, report.Environments, 1)
+		assert.Equal(t, "down", report.Environments[0].Status)
+		assert.Equal(t, "up", report.Environments[0].PreviousStatus)
+	})
+
+	tests := []struct {
+		name        string
+		server      *PortainerMCPServer
+		input       map[string]any
+		expectError string
+		expectNote  string
+	}{
+		{name: "disabled history", server: &PortainerMCPServer{}, input: map[string]any{}, expectError: "trend history is disabled"},
+		{name: "invalid since", server: newTrendTestServer(t), input: map[string]any{"since": "last week"}, expectError: "invalid since parameter"},
+		{name: "invalid points", server: newTrendTestServer(t), input: map[string]any{"points": float64(1)}, expectError: "points must be between 2 and 500"},
+		{name: "invalid environment", server: newTrendTestServer(t), input: map[string]any{"environmentId": float64(-1)}, expectError: "environmentId"},
+		{name: "unknown environment", server: newTrendTestServer(t, samples...), input: map[string]any{"environmentId": float64(9)}, expectError: "environment 9 has no snapshot"},
+		{name: "no sample", server: newTrendTestServer(t), input: map[string]any{}, expectNote: "no sample was recorded"},
+		{name: "one sample", server: newTrendTestServer(t, samples[2]), input: map[string]any{}, expectNote: "only one sample"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.server.HandleGetTrends()(context.Background(), CreateMCPRequest(tt.input))
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, text, tt.expectError)
+				return
+			}
+			require.False(t, result.IsError, text)
+			var report trendReport
+			require.NoError(t, json.Unmarshal([]byte(text), &report))
+			require.Len(t, report.Notes, 1)
+			assert.Contains(t, report.Notes[0], tt.expectNote)
+		})
+	}
+}
+
+// TestRecordTrendSample verifies that the snapshot counts of the environments are recorded,
+// leaving out the environments without a snapshot.
+func TestRecordTrendSample(t *testing.T) {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetEnvironmentSnapshots", models.EnvironmentListOptions{}).Return([]models.EnvironmentSnapshot{
+		{EnvironmentID: 1, Name: "prod", Status: "up", SnapshotTime: 1700000000, Containers: 5, Running: 4, Stopped: 1, Unhealthy: 1, Stacks: 2, Images: 7, Volumes: 3},
+		{EnvironmentID: 2, Name: "new", Status: "up"},
+	}, nil).Once()
+	mockClient.On("GetEnvironmentSnapshots", models.EnvironmentListOptions{}).Return(nil, fmt.Errorf("unauthorized")).Once()
+
+	server := newTrendTestServer(t)
+	server.cli = mockClient
+	require.NoError(t, server.recordTrendSample())
+
+	recorded := server.trends.Since(time.Time{})
+	require.Len(t, recorded, 1)
+	assert.Equal(t, []trends.Environment{
+		{ID: 1, Name: "prod", Status: "up", Containers: 5, Running: 4, Stopped: 1, Unhealthy: 1, Stacks: 2, Images: 7, Volumes: 3},
+	}, recorded[0].Environments)
+
+	assert.ErrorContains(t, server.recordTrendSample(), "unauthorized")
+	assert.Equal(t, 1, server.trends.Len())
+	mockClient.AssertExpectations(t)
+}
+
+// TestSeriesIndexes verifies that the series keeps the first and last samples.
+func TestSeriesIndexes(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 2}, seriesIndexes(3, 24))
+	assert.Equal(t, []int{0, 4, 9}, seriesIndexes(10, 3))
+	assert.Equal(t, []int{0, 9}, seriesIndexes(10, 2))
+}
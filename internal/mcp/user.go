@@ -6,6 +6,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -18,19 +19,72 @@ func (s *PortainerMCPServer) AddUserFeatures() {
 		s.addToolIfExists(ToolCreateUser, s.HandleCreateUser())
 		s.addToolIfExists(ToolDeleteUser, s.HandleDeleteUser())
 		s.addToolIfExists(ToolUpdateUserRole, s.HandleUpdateUserRole())
+		s.addToolIfExists(ToolImportUsers, s.HandleImportUsers())
+		s.addToolIfExists(ToolOffboardUser, s.HandleOffboardUser())
 	}
 }
 
 // HandleGetUsers returns an MCP tool handler that retrieves users.
 func (s *PortainerMCPServer) HandleGetUsers() server.ToolHandlerFunc {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		users, err := s.cli.GetUsers()
+		parser := toolgen.NewParameterParser(request)
+
+		name, err := parser.GetString("name", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid name parameter", err), nil
+		}
+
+		role, err := parser.GetString("role", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid role parameter", err), nil
+		}
+
+		teamId, err := parser.GetInt("teamId", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid teamId parameter", err), nil
+		}
+
+		includeApiTokens, err := parser.GetBoolean("includeApiTokens", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid includeApiTokens parameter", err), nil
+		}
+
+		users, err := s.cli.GetUsers(models.UserListOptions{Name: name, Role: role, TeamID: teamId})
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to get users", err), nil
 		}
 
-		return jsonResult(users, "failed to marshal users")
+		if includeApiTokens {
+			if err := s.lookupAPITokens(ctx, users); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get API tokens", err), nil
+			}
+		}
+
+		return listResult(request, users, "failed to marshal users")
+	}
+}
+
+// lookupAPITokens sets whether each user owns API tokens, looking the users up
+// concurrently. It returns the first lookup error.
+func (s *PortainerMCPServer) lookupAPITokens(ctx context.Context, users []models.User) error {
+	errs := make([]error, len(users))
+	runConcurrently(ctx, len(users), func(i int) {
+		hasTokens, err := s.cli.HasAPITokens(users[i].ID)
+		if err != nil {
+			errs[i] = fmt.Errorf("user %s: %w", users[i].Username, err)
+			return
+		}
+		users[i].HasAPITokens = &hasTokens
+	})
+	if err := ctx.Err(); err != nil {
+		return err
 	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // HandleUpdateUserRole returns an MCP tool handler that updates user role.
@@ -46,15 +100,11 @@ func (s *PortainerMCPServer) HandleUpdateUserRole() server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		role, err := parser.GetString("role", true)
+		role, err := parser.GetEnum("role", true, AllUserRoles...)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid role parameter", err), nil
 		}
 
-		if !isValidUserRole(role) {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid role %s: must be one of: %v", role, AllUserRoles)), nil
-		}
-
 		err = s.cli.UpdateUserRole(id, role)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to update user role", err), nil
@@ -79,15 +129,11 @@ func (s *PortainerMCPServer) HandleCreateUser() server.ToolHandlerFunc {
 			return mcp.NewToolResultErrorFromErr("invalid password parameter", err), nil
 		}
 
-		role, err := parser.GetString("role", true)
+		role, err := parser.GetEnum("role", true, AllUserRoles...)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("invalid role parameter", err), nil
 		}
 
-		if !isValidUserRole(role) {
-			return mcp.NewToolResultError(fmt.Sprintf("invalid role %s: must be one of: %v", role, AllUserRoles)), nil
-		}
-
 		id, err := s.cli.CreateUser(username, password, role)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to create user", err), nil
@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Outcomes of the steps of a user offboarding.
+const (
+	offboardingRemoved    = "removed"
+	offboardingRevoked    = "revoked"
+	offboardingReassigned = "reassigned"
+	// offboardingFlagged is a resource left as is, for an administrator to review, when no
+	// user to reassign it to is given.
+	offboardingFlagged = "flagged"
+)
+
+// offboardingItem is a team membership or an API token of an offboarded user.
+type offboardingItem struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Outcome string `json:"outcome,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// offboardedResource is a stack or custom template whose resource control gives access
+// to an offboarded user.
+type offboardedResource struct {
+	Type              string `json:"type"`
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	ResourceControlID int    `json:"resource_control_id"`
+	// SoleOwner is set when no other user or team may access the resource, so that only
+	// administrators can once the user is deleted.
+	SoleOwner bool   `json:"sole_owner,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	control models.ResourceControl
+}
+
+// userOffboarding lists what an offboarded user owns, and the result of each step of its
+// offboarding.
+type userOffboarding struct {
+	UserID     int                  `json:"user_id"`
+	Username   string               `json:"username"`
+	ReassignTo int                  `json:"reassign_to,omitempty"`
+	Deleted    bool                 `json:"deleted"`
+	Teams      []offboardingItem    `json:"teams"`
+	APITokens  []offboardingItem    `json:"api_tokens"`
+	Resources  []offboardedResource `json:"resources"`
+}
+
+// HandleOffboardUser returns an MCP tool handler that removes a user from its teams,
+// revokes its API tokens, reassigns or flags the stacks and custom templates it may
+// access through their resource controls, and finally deletes the user.
+func (s *PortainerMCPServer) HandleOffboardUser() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		reassignTo, err := parser.GetInt("reassignTo", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid reassignTo parameter", err), nil
+		}
+		if reassignTo < 0 {
+			return mcp.NewToolResultError("reassignTo must be a positive user ID"), nil
+		}
+		if reassignTo == id {
+			return mcp.NewToolResultError("reassignTo must be another user than the offboarded one"), nil
+		}
+
+		plan, err := isPlanRequest(request)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid plan parameter", err), nil
+		}
+
+		user, err := s.cli.GetUser(id)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get user", err), nil
+		}
+		current, err := s.cli.GetCurrentUser()
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to get the user of the API token", err), nil
+		}
+		if current.ID == id {
+			return mcp.NewToolResultError(fmt.Sprintf("user %d owns the API token used by this server and cannot be offboarded with it", id)), nil
+		}
+		if reassignTo != 0 {
+			if _, err := s.cli.GetUser(reassignTo); err != nil {
+				return mcp.NewToolResultErrorFromErr("failed to get the user to reassign resources to", err), nil
+			}
+		}
+
+		offboarding, err := s.listUserOffboarding(user, reassignTo)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to list what the user owns", err), nil
+		}
+
+		if plan {
+			steps, notes := offboardUserPlan(offboarding)
+			return s.previewPlan(ctx, request, steps, notes, s.HandleOffboardUser())
+		}
+
+		if err := s.offboardUser(offboarding); err != nil {
+			report, _ := json.Marshal(offboarding)
+			return mcp.NewToolResultError(fmt.Sprintf("user %d was not deleted: %v. Progress: %s", id, err, report)), nil
+		}
+		return jsonResult(offboarding, "failed to marshal user offboarding report")
+	}
+}
+
+// listUserOffboarding lists the teams and API tokens of a user, and the stacks and custom
+// templates whose resource controls give it access.
+func (s *PortainerMCPServer) listUserOffboarding(user models.User, reassignTo int) (*userOffboarding, error) {
+	offboarding := &userOffboarding{
+		UserID:     user.ID,
+		Username:   user.Username,
+		ReassignTo: reassignTo,
+		Teams:      []offboardingItem{},
+		APITokens:  []offboardingItem{},
+		Resources:  []offboardedResource{},
+	}
+
+	memberships, err := s.cli.GetUserMemberships(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) > 0 {
+		teams, err := s.cli.GetTeams()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list teams: %w", err)
+		}
+		names := make(map[int]string, len(teams))
+		for _, team := range teams {
+			names[team.ID] = team.Name
+		}
+		for _, membership := range memberships {
+			offboarding.Teams = append(offboarding.Teams, offboardingItem{ID: membership.TeamID, Name: names[membership.TeamID]})
+		}
+	}
+
+	tokens, err := s.cli.GetUserAPITokens(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		offboarding.APITokens = append(offboarding.APITokens, offboardingItem{ID: token.ID, Name: token.Description})
+	}
+
+	controls, err := s.cli.GetResourceControls()
+	if err != nil {
+		return nil, err
+	}
+	for _, control := range controls {
+		if !slices.Contains(control.UserIDs, user.ID) {
+			continue
+		}
+		offboarding.Resources = append(offboarding.Resources, offboardedResource{
+			Type:              control.ResourceType,
+			ID:                control.ResourceID,
+			Name:              control.ResourceName,
+			ResourceControlID: control.ID,
+			SoleOwner:         len(control.UserIDs) == 1 && len(control.TeamIDs) == 0 && !control.Public && !control.AdministratorsOnly,
+			control:           control,
+		})
+	}
+
+	return offboarding, nil
+}
+
+// offboardUser removes the user from its teams, revokes its API tokens, reassigns its
+// resources, or flags them when no user to reassign them to is given, and deletes the
+// user. It stops at the first failure, which is returned, leaving the user in place.
+func (s *PortainerMCPServer) offboardUser(offboarding *userOffboarding) error {
+	for i := range offboarding.Teams {
+		team := &offboarding.Teams[i]
+		if err := s.cli.RemoveTeamMember(team.ID, offboarding.UserID); err != nil {
+			team.Error = err.Error()
+			return fmt.Errorf("failed to remove the user from team %d: %w", team.ID, err)
+		}
+		team.Outcome = offboardingRemoved
+	}
+
+	for i := range offboarding.APITokens {
+		token := &offboarding.APITokens[i]
+		if err := s.cli.RevokeUserAPIToken(offboarding.UserID, token.ID); err != nil {
+			token.Error = err.Error()
+			return fmt.Errorf("failed to revoke API token %d: %w", token.ID, err)
+		}
+		token.Outcome = offboardingRevoked
+	}
+
+	for i := range offboarding.Resources {
+		resource := &offboarding.Resources[i]
+		if offboarding.ReassignTo == 0 {
+			resource.Outcome = offboardingFlagged
+			continue
+		}
+		control := resource.control
+		control.UserIDs = reassignedUserIDs(control.UserIDs, offboarding.UserID, offboarding.ReassignTo)
+		if err := s.cli.UpdateResourceControl(control); err != nil {
+			resource.Error = err.Error()
+			return fmt.Errorf("failed to reassign %s %d: %w", resource.Type, resource.ID, err)
+		}
+		resource.Outcome = offboardingReassigned
+	}
+
+	if err := s.cli.DeleteUser(offboarding.UserID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	offboarding.Deleted = true
+	return nil
+}
+
+// reassignedUserIDs returns the users of a resource control with from replaced by to.
+func reassignedUserIDs(userIDs []int, from, to int) []int {
+	reassigned := make([]int, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != from && id != to {
+			reassigned = append(reassigned, id)
+		}
+	}
+	return append(reassigned, to)
+}
+
+// offboardUserPlan returns the Portainer API calls made by HandleOffboardUser, and notes
+// on the resources that are flagged instead of reassigned.
+func offboardUserPlan(offboarding *userOffboarding) ([]planStep, []string) {
+	userPath := fmt.Sprintf("/api/users/%d", offboarding.UserID)
+	steps := []planStep{
+		{Method: http.MethodGet, Path: userPath, Description: fmt.Sprintf("Read user %q", offboarding.Username)},
+		{Method: http.MethodGet, Path: "/api/users/me", Description: "Check that the user does not own the API token of this server"},
+	}
+	if offboarding.ReassignTo != 0 {
+		steps = append(steps, planStep{Method: http.MethodGet, Path: fmt.Sprintf("/api/users/%d", offboarding.ReassignTo), Description: "Read the user to reassign resources to"})
+	}
+	steps = append(steps,
+		planStep{Method: http.MethodGet, Path: userPath + "/memberships", Description: "List the team memberships of the user"},
+		planStep{Method: http.MethodGet, Path: userPath + "/tokens", Description: "List the API tokens of the user"},
+		planStep{Method: http.MethodGet, Path: "/api/stacks", Description: "List the stacks and their resource controls"},
+		planStep{Method: http.MethodGet, Path: "/api/custom_templates", Description: "List the custom templates and their resource controls"},
+	)
+
+	for _, team := range offboarding.Teams {
+		steps = append(steps,
+			planStep{Method: http.MethodGet, Path: "/api/team_memberships", Description: fmt.Sprintf("Find the membership of the user in team %q", team.Name)},
+			planStep{Method: http.MethodDelete, Path: "/api/team_memberships/{id}", Description: fmt.Sprintf("Remove the user from team %q", team.Name)},
+		)
+	}
+	for _, token := range offboarding.APITokens {
+		steps = append(steps, planStep{
+			Method:      http.MethodDelete,
+			Path:        fmt.Sprintf("%s/tokens/%d", userPath, token.ID),
+			Description: fmt.Sprintf("Revoke API token %d (%s)", token.ID, token.Name),
+		})
+	}
+
+	var notes []string
+	for _, resource := range offboarding.Resources {
+		if offboarding.ReassignTo == 0 {
+			note := fmt.Sprintf("%s %d (%s) is flagged and left as is", resource.Type, resource.ID, resource.Name)
+			if resource.SoleOwner {
+				note += ": only administrators can access it once the user is deleted"
+			}
+			notes = append(notes, note)
+			continue
+		}
+		steps = append(steps, planStep{
+			Method:      http.MethodPut,
+			Path:        fmt.Sprintf("/api/resource_controls/%d", resource.ResourceControlID),
+			Description: fmt.Sprintf("Reassign %s %q to user %d", resource.Type, resource.Name, offboarding.ReassignTo),
+		})
+	}
+
+	steps = append(steps, planStep{Method: http.MethodDelete, Path: userPath, Description: fmt.Sprintf("Delete user %q", offboarding.Username)})
+	notes = append(notes, "teams, tokens and resources are listed again when the plan is applied")
+	return steps, notes
+}
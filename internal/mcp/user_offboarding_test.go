@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// offboardingTestClient returns a client whose user 10 belongs to team 4, owns API token 7,
+// is the only user of stack 1 and shares custom template 3 with team 2.
+func offboardingTestClient() *MockPortainerClient {
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetUser", 10).Return(models.User{ID: 10, Username: "alice"}, nil)
+	mockClient.On("GetUser", 12).Return(models.User{ID: 12, Username: "bob"}, nil)
+	mockClient.On("GetCurrentUser").Return(models.User{ID: 1, Username: "admin"}, nil)
+	mockClient.On("GetUserMemberships", 10).Return([]models.TeamMembership{{TeamID: 4, UserID: 10}}, nil)
+	mockClient.On("GetTeams").Return([]models.Team{{ID: 4, Name: "devs"}}, nil)
+	mockClient.On("GetUserAPITokens", 10).Return([]models.APIToken{{ID: 7, Description: "ci"}}, nil)
+	mockClient.On("GetResourceControls").Return([]models.ResourceControl{
+		{ID: 20, ResourceType: models.ResourceControlTypeStack, ResourceID: 1, ResourceName: "web", UserIDs: []int{10}, TeamIDs: []int{}},
+		{ID: 21, ResourceType: models.ResourceControlTypeStack, ResourceID: 2, ResourceName: "db", UserIDs: []int{11}, TeamIDs: []int{}},
+		{ID: 22, ResourceType: models.ResourceControlTypeCustomTemplate, ResourceID: 3, ResourceName: "nginx", UserIDs: []int{10}, TeamIDs: []int{2}},
+	}, nil)
+	return mockClient
+}
+
+// offboardUserCall calls offboardUser for user 10.
+func offboardUserCall(t *testing.T, mockClient *MockPortainerClient, args map[string]any) (*mcp.CallToolResult, string) {
+	t.Helper()
+	args["id"] = float64(10)
+	s := &PortainerMCPServer{cli: mockClient}
+	result, err := s.HandleOffboardUser()(context.Background(), CreateMCPRequest(args))
+	require.NoError(t, err)
+	return result, result.Content[0].(mcp.TextContent).Text
+}
+
+// TestHandleOffboardUser verifies the offboarding of a user and its plan.
+func TestHandleOffboardUser(t *testing.T) {
+	t.Run("reassign", func(t *testing.T) {
+		mockClient := offboardingTestClient()
+		mockClient.On("RemoveTeamMember", 4, 10).Return(nil)
+		mockClient.On("RevokeUserAPIToken", 10, 7).Return(nil)
+		mockClient.On("UpdateResourceControl", models.ResourceControl{
+			ID: 20, ResourceType: models.ResourceControlTypeStack, ResourceID: 1, ResourceName: "web", UserIDs: []int{12}, TeamIDs: []int{},
+		}).Return(nil)
+		mockClient.On("UpdateResourceControl", models.ResourceControl{
+			ID: 22, ResourceType: models.ResourceControlTypeCustomTemplate, ResourceID: 3, ResourceName: "nginx", UserIDs: []int{12}, TeamIDs: []int{2},
+		}).Return(nil)
+		mockClient.On("DeleteUser", 10).Return(nil)
+
+		result, text := offboardUserCall(t, mockClient, map[string]any{"reassignTo": float64(12)})
+		require.False(t, result.IsError, text)
+		var offboarding userOffboarding
+		require.NoError(t, json.Unmarshal([]byte(text), &offboarding))
+		assert.True(t, offboarding.Deleted)
+		assert.Equal(t, []offboardingItem{{ID: 4, Name: "devs", Outcome: offboardingRemoved}}, offboarding.Teams)
+		assert.Equal(t, []offboardingItem{{ID: 7, Name: "ci", Outcome: offboardingRevoked}}, offboarding.APITokens)
+		require.Len(t, offboarding.Resources, 2)
+		assert.True(t, offboarding.Resources[0].SoleOwner)
+		assert.Equal(t, offboardingReassigned, offboarding.Resources[0].Outcome)
+		assert.False(t, offboarding.Resources[1].SoleOwner)
+		assert.Equal(t, offboardingReassigned, offboarding.Resources[1].Outcome)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		mockClient := offboardingTestClient()
+		mockClient.On("RemoveTeamMember", 4, 10).Return(nil)
+		mockClient.On("RevokeUserAPIToken", 10, 7).Return(nil)
+		mockClient.On("DeleteUser", 10).Return(nil)
+
+		result, text := offboardUserCall(t, mockClient, map[string]any{})
+		require.False(t, result.IsError, text)
+		var offboarding userOffboarding
+		require.NoError(t, json.Unmarshal([]byte(text), &offboarding))
+		assert.True(t, offboarding.Deleted)
+		for _, resource := range offboarding.Resources {
+			assert.Equal(t, offboardingFlagged, resource.Outcome)
+		}
+		mockClient.AssertNotCalled(t, "UpdateResourceControl", mock.Anything)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockClient := offboardingTestClient()
+		mockClient.On("RemoveTeamMember", 4, 10).Return(nil)
+		mockClient.On("RevokeUserAPIToken", 10, 7).Return(fmt.Errorf("forbidden"))
+
+		result, text := offboardUserCall(t, mockClient, map[string]any{})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "user 10 was not deleted: failed to revoke API token 7: forbidden")
+		assert.Contains(t, text, `"outcome":"removed"`, "the steps already done are reported")
+		mockClient.AssertNotCalled(t, "DeleteUser", 10)
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		mockClient := offboardingTestClient()
+
+		result, text := offboardUserCall(t, mockClient, map[string]any{"reassignTo": float64(12), "plan": true})
+		require.False(t, result.IsError, text)
+		var plan executionPlan
+		require.NoError(t, json.Unmarshal([]byte(text), &plan))
+		assert.NotEmpty(t, plan.PlanID)
+		last := plan.Steps[len(plan.Steps)-1]
+		assert.Equal(t, planStep{Method: "DELETE", Path: "/api/users/10", Description: `Delete user "alice"`}, last)
+		assert.Contains(t, plan.Steps, planStep{Method: "DELETE", Path: "/api/users/10/tokens/7", Description: "Revoke API token 7 (ci)"})
+		assert.Contains(t, plan.Steps, planStep{Method: "PUT", Path: "/api/resource_controls/20", Description: `Reassign stack "web" to user 12`})
+		mockClient.AssertNotCalled(t, "DeleteUser", 10)
+		mockClient.AssertNotCalled(t, "RemoveTeamMember", 4, 10)
+	})
+
+	t.Run("plan notes flagged resources", func(t *testing.T) {
+		mockClient := offboardingTestClient()
+
+		_, text := offboardUserCall(t, mockClient, map[string]any{"plan": true})
+		var plan executionPlan
+		require.NoError(t, json.Unmarshal([]byte(text), &plan))
+		assert.Contains(t, plan.Notes, "stack 1 (web) is flagged and left as is: only administrators can access it once the user is deleted")
+		assert.Contains(t, plan.Notes, "custom_template 3 (nginx) is flagged and left as is")
+	})
+
+	t.Run("token owner", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetUser", 10).Return(models.User{ID: 10, Username: "alice"}, nil)
+		mockClient.On("GetCurrentUser").Return(models.User{ID: 10, Username: "alice"}, nil)
+
+		result, text := offboardUserCall(t, mockClient, map[string]any{})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "owns the API token used by this server")
+	})
+
+	t.Run("reassign to self", func(t *testing.T) {
+		result, text := offboardUserCall(t, &MockPortainerClient{}, map[string]any{"reassignTo": float64(10)})
+		assert.True(t, result.IsError)
+		assert.Contains(t, text, "reassignTo must be another user")
+	})
+}
+
+// TestReassignedUserIDs verifies the replacement of a user in a resource control.
+func TestReassignedUserIDs(t *testing.T) {
+	assert.Equal(t, []int{12}, reassignedUserIDs([]int{10}, 10, 12))
+	assert.Equal(t, []int{11, 12}, reassignedUserIDs([]int{10, 11, 12}, 10, 12))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // TestHandleGetUsers verifies the HandleGetUsers MCP tool handler.
@@ -40,7 +41,7 @@ func TestHandleGetUsers(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create mock client
 			mockClient := &MockPortainerClient{}
-			mockClient.On("GetUsers").Return(tt.mockUsers, tt.mockError)
+			mockClient.On("GetUsers", models.UserListOptions{}).Return(tt.mockUsers, tt.mockError)
 
 			// Create server with mock client
 			server := &PortainerMCPServer{
@@ -80,6 +81,87 @@ func TestHandleGetUsers(t *testing.T) {
 	}
 }
 
+// TestHandleGetUsers_Filters verifies that listUsers passes its filter parameters to the
+// client.
+func TestHandleGetUsers_Filters(t *testing.T) {
+	users := []models.User{{ID: 2, Username: "Alice", Role: models.UserRoleUser}}
+
+	tests := []struct {
+		name         string
+		args         map[string]any
+		expectedOpts models.UserListOptions
+		expectError  bool
+	}{
+		{name: "no filters", args: map[string]any{}},
+		{name: "name filter", args: map[string]any{"name": "alice"}, expectedOpts: models.UserListOptions{Name: "alice"}},
+		{name: "role filter", args: map[string]any{"role": "user"}, expectedOpts: models.UserListOptions{Role: "user"}},
+		{name: "team filter", args: map[string]any{"teamId": float64(7)}, expectedOpts: models.UserListOptions{TeamID: 7}},
+		{
+			name:         "combined filters",
+			args:         map[string]any{"name": "alice", "role": "user", "teamId": float64(7)},
+			expectedOpts: models.UserListOptions{Name: "alice", Role: "user", TeamID: 7},
+		},
+		{name: "invalid role type", args: map[string]any{"role": 1.0}, expectError: true},
+		{name: "invalid teamId", args: map[string]any{"teamId": "devs"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			mockClient.On("GetUsers", tt.expectedOpts).Return(users, nil).Maybe()
+
+			server := &PortainerMCPServer{cli: mockClient}
+
+			result, err := server.HandleGetUsers()(context.Background(), CreateMCPRequest(tt.args))
+			assert.NoError(t, err)
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			var got []models.User
+			err = json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &got)
+			assert.NoError(t, err)
+			assert.Equal(t, users, got)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHandleGetUsers_APITokens verifies that listUsers reports whether each user owns API
+// tokens only when asked to.
+func TestHandleGetUsers_APITokens(t *testing.T) {
+	users := func() []models.User {
+		return []models.User{{ID: 1, Username: "admin"}, {ID: 2, Username: "ci"}}
+	}
+
+	mockClient := &MockPortainerClient{}
+	mockClient.On("GetUsers", models.UserListOptions{}).Return(users(), nil).Once()
+	server := &PortainerMCPServer{cli: mockClient}
+
+	result, err := server.HandleGetUsers()(context.Background(), CreateMCPRequest(map[string]any{}))
+	assert.NoError(t, err)
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "hasApiTokens")
+	mockClient.AssertNotCalled(t, "HasAPITokens", mock.Anything)
+
+	mockClient.On("GetUsers", models.UserListOptions{}).Return(users(), nil).Once()
+	mockClient.On("HasAPITokens", 1).Return(false, nil).Once()
+	mockClient.On("HasAPITokens", 2).Return(true, nil).Once()
+	result, err = server.HandleGetUsers()(context.Background(), CreateMCPRequest(map[string]any{"includeApiTokens": true}))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"id":1,"username":"admin","role":"","hasApiTokens":false},{"id":2,"username":"ci","role":"","hasApiTokens":true}]`,
+		result.Content[0].(mcp.TextContent).Text)
+
+	mockClient.On("GetUsers", models.UserListOptions{}).Return(users(), nil).Once()
+	mockClient.On("HasAPITokens", 1).Return(false, nil).Once()
+	mockClient.On("HasAPITokens", 2).Return(false, fmt.Errorf("forbidden")).Once()
+	result, err = server.HandleGetUsers()(context.Background(), CreateMCPRequest(map[string]any{"includeApiTokens": true}))
+	assert.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "user ci: forbidden")
+	mockClient.AssertExpectations(t)
+}
+
 // TestHandleUpdateUserRole verifies the HandleUpdateUserRole MCP tool handler.
 func TestHandleUpdateUserRole(t *testing.T) {
 	tests := []struct {
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 	"github.com/mark3labs/mcp-go/mcp"
 	"gopkg.in/yaml.v3"
 )
@@ -19,6 +20,11 @@ func jsonResult(obj any, errMsg string) (*mcp.CallToolResult, error) {
 	return mcp.NewToolResultText(string(data)), nil
 }
 
+// containsFold reports whether substr is within s, ignoring case. An empty substr always matches.
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
 // validateName checks that a name string is non-empty after trimming whitespace.
 func validateName(name string) error {
 	if strings.TrimSpace(name) == "" {
@@ -64,62 +70,32 @@ func validateComposeYAML(content string) error {
 }
 
 // parseAccessMap parses access entries from an array of objects and returns a map of ID to access level
-func parseAccessMap(entries []any) (map[int]string, error) {
+func parseAccessMap(entries []map[string]any) (map[int]string, error) {
 	accessMap := map[int]string{}
 
 	for _, entry := range entries {
-		entryMap, ok := entry.(map[string]any)
-		if !ok {
-			return nil, fmt.Errorf("invalid access entry: %v", entry)
-		}
+		fields := toolgen.NewObjectParser(entry)
 
-		id, ok := entryMap["id"].(float64)
-		if !ok {
-			return nil, fmt.Errorf("invalid ID: %v", entryMap["id"])
+		id, err := fields.GetInt("id", true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID: %w", err)
 		}
 
-		access, ok := entryMap["access"].(string)
-		if !ok {
-			return nil, fmt.Errorf("invalid access: %v", entryMap["access"])
+		access, err := fields.GetString("access", true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access: %w", err)
 		}
 
 		if !isValidAccessLevel(access) {
 			return nil, fmt.Errorf("invalid access level: %s", access)
 		}
 
-		accessMap[int(id)] = access
+		accessMap[id] = access
 	}
 
 	return accessMap, nil
 }
 
-// parseKeyValueMap parses a slice of map[string]any into a map[string]string,
-// expecting each map to have "key" and "value" string fields.
-func parseKeyValueMap(items []any) (map[string]string, error) {
-	resultMap := map[string]string{}
-
-	for _, item := range items {
-		itemMap, ok := item.(map[string]any)
-		if !ok {
-			return nil, fmt.Errorf("invalid item: %v", item)
-		}
-
-		key, ok := itemMap["key"].(string)
-		if !ok {
-			return nil, fmt.Errorf("invalid key: %v", itemMap["key"])
-		}
-
-		value, ok := itemMap["value"].(string)
-		if !ok {
-			return nil, fmt.Errorf("invalid value: %v", itemMap["value"])
-		}
-
-		resultMap[key] = value
-	}
-
-	return resultMap, nil
-}
-
 // CreateMCPRequest creates a new MCP tool request with the given arguments.
 // Used by test code only.
 func CreateMCPRequest(args map[string]any) mcp.CallToolRequest {
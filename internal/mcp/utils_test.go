@@ -9,13 +9,13 @@ import (
 func TestParseAccessMap(t *testing.T) {
 	tests := []struct {
 		name    string
-		entries []any
+		entries []map[string]any
 		want    map[int]string
 		wantErr bool
 	}{
 		{
 			name: "Valid single entry",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"id":     float64(1),
 					"access": AccessLevelEnvironmentAdmin,
@@ -28,7 +28,7 @@ func TestParseAccessMap(t *testing.T) {
 		},
 		{
 			name: "Valid multiple entries",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"id":     float64(1),
 					"access": AccessLevelEnvironmentAdmin,
@@ -44,17 +44,9 @@ func TestParseAccessMap(t *testing.T) {
 			},
 			wantErr: false,
 		},
-		{
-			name: "Invalid entry type",
-			entries: []any{
-				"not a map",
-			},
-			want:    nil,
-			wantErr: true,
-		},
 		{
 			name: "Invalid ID type",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"id":     "string-id",
 					"access": AccessLevelEnvironmentAdmin,
@@ -65,7 +57,7 @@ func TestParseAccessMap(t *testing.T) {
 		},
 		{
 			name: "Invalid access type",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"id":     float64(1),
 					"access": 123,
@@ -76,7 +68,7 @@ func TestParseAccessMap(t *testing.T) {
 		},
 		{
 			name: "Invalid access level",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"id":     float64(1),
 					"access": "invalid_access_level",
@@ -87,13 +79,13 @@ func TestParseAccessMap(t *testing.T) {
 		},
 		{
 			name:    "Empty entries",
-			entries: []any{},
+			entries: []map[string]any{},
 			want:    map[int]string{},
 			wantErr: false,
 		},
 		{
 			name: "Missing ID field",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"access": AccessLevelEnvironmentAdmin,
 				},
@@ -103,7 +95,7 @@ func TestParseAccessMap(t *testing.T) {
 		},
 		{
 			name: "Missing access field",
-			entries: []any{
+			entries: []map[string]any{
 				map[string]any{
 					"id": float64(1),
 				},
@@ -155,95 +147,3 @@ func TestIsValidHTTPMethod(t *testing.T) {
 		})
 	}
 }
-
-// TestParseKeyValueMap verifies parse key value map behavior.
-func TestParseKeyValueMap(t *testing.T) {
-	tests := []struct {
-		name    string
-		items   []any
-		want    map[string]string
-		wantErr bool
-	}{
-		{
-			name: "Valid single entry",
-			items: []any{
-				map[string]any{"key": "k1", "value": "v1"},
-			},
-			want: map[string]string{
-				"k1": "v1",
-			},
-			wantErr: false,
-		},
-		{
-			name: "Valid multiple entries",
-			items: []any{
-				map[string]any{"key": "k1", "value": "v1"},
-				map[string]any{"key": "k2", "value": "v2"},
-			},
-			want: map[string]string{
-				"k1": "v1",
-				"k2": "v2",
-			},
-			wantErr: false,
-		},
-		{
-			name:    "Empty items",
-			items:   []any{},
-			want:    map[string]string{},
-			wantErr: false,
-		},
-		{
-			name: "Invalid item type",
-			items: []any{
-				"not a map",
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "Invalid key type",
-			items: []any{
-				map[string]any{"key": 123, "value": "v1"},
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "Invalid value type",
-			items: []any{
-				map[string]any{"key": "k1", "value": 123},
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "Missing key field",
-			items: []any{
-				map[string]any{"value": "v1"},
-			},
-			want:    nil,
-			wantErr: true,
-		},
-		{
-			name: "Missing value field",
-			items: []any{
-				map[string]any{"key": "k1"},
-			},
-			want:    nil,
-			wantErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseKeyValueMap(tt.items)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("parseKeyValueMap() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("parseKeyValueMap() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
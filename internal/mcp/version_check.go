@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/mod/semver"
+)
+
+// Modes of the Portainer server version check run at startup.
+const (
+	// VersionCheckStrict refuses to start against a Portainer version outside the
+	// supported range, or when the version cannot be read.
+	VersionCheckStrict = "strict"
+	// VersionCheckWarn logs a warning and starts anyway.
+	VersionCheckWarn = "warn"
+	// VersionCheckOff does not read the Portainer version at startup.
+	VersionCheckOff = "off"
+)
+
+// versionCheckStatus describes the version check of the server and how the Portainer
+// version compares to the supported range.
+type versionCheckStatus struct {
+	Mode           string `json:"mode"`
+	SupportedRange string `json:"supportedRange"`
+	// DetectedVersion is the Portainer version read at startup (empty when the check is off
+	// or the version could not be read).
+	DetectedVersion string `json:"detectedVersion,omitempty"`
+	// Supported reports whether the current Portainer version is in the supported range.
+	Supported bool `json:"supported"`
+}
+
+// validVersionCheckMode reports whether mode is a known version check mode.
+func validVersionCheckMode(mode string) bool {
+	switch mode {
+	case VersionCheckStrict, VersionCheckWarn, VersionCheckOff:
+		return true
+	}
+	return false
+}
+
+// supportedVersionRange returns the supported range of Portainer versions for display.
+func supportedVersionRange() string {
+	return fmt.Sprintf("%s - %s.x", MinimumPortainerVersion, strings.TrimPrefix(semver.MajorMinor("v"+SupportedPortainerVersion), "v"))
+}
+
+// isSupportedVersion reports whether a Portainer version is in the supported range: from
+// MinimumPortainerVersion up to any patch release of the minor version of
+// SupportedPortainerVersion. Versions that are not valid semantic versions are not supported.
+func isSupportedVersion(version string) bool {
+	v := "v" + strings.TrimPrefix(version, "v")
+	if !semver.IsValid(v) {
+		return false
+	}
+	return semver.Compare(v, "v"+MinimumPortainerVersion) >= 0 &&
+		semver.Compare(semver.MajorMinor(v), semver.MajorMinor("v"+SupportedPortainerVersion)) <= 0
+}
+
+// checkPortainerVersion reads the version of the Portainer server and applies the version
+// check mode. It returns the detected version, which is empty when the check is off or
+// when the version cannot be read in warn mode.
+func checkPortainerVersion(cli PortainerClient, mode string) (string, error) {
+	if mode == VersionCheckOff {
+		return "", nil
+	}
+
+	version, err := cli.GetVersion()
+	if err != nil {
+		if mode == VersionCheckStrict {
+			return "", fmt.Errorf("failed to get Portainer server version: %w", err)
+		}
+		log.Warn().Err(err).Msg("failed to get Portainer server version, starting anyway")
+		return "", nil
+	}
+
+	if !isSupportedVersion(version) {
+		if mode == VersionCheckStrict {
+			return "", fmt.Errorf("unsupported Portainer server version: %s, supported versions are %s (use -version-check=warn to start anyway)", version, supportedVersionRange())
+		}
+		log.Warn().Str("version", version).Str("supported", supportedVersionRange()).Msg("unsupported Portainer server version, some tools may fail")
+	}
+	return version, nil
+}
@@ -0,0 +1,588 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// defaultPollInterval is the delay between two status checks of waiting tools.
+	defaultPollInterval = 3 * time.Second
+	// defaultWaitTimeout is how long waiting tools poll when no timeout is given.
+	defaultWaitTimeout = 120 * time.Second
+	// maxWaitTimeout caps the timeout accepted by waiting tools.
+	maxWaitTimeout = 15 * time.Minute
+	// failingContainerLogTail is the number of log lines attached for each failing container.
+	failingContainerLogTail = 50
+)
+
+// Wait outcomes reported by waiting tools.
+const (
+	waitOutcomeReady   = "ready"
+	waitOutcomeMet     = "met"
+	waitOutcomeFailed  = "failed"
+	waitOutcomeTimeout = "timeout"
+)
+
+// Resource types accepted by HandleWaitFor.
+const (
+	waitResourceStack     = "stack"
+	waitResourceContainer = "container"
+	waitResourceEdgeStack = "edgeStack"
+)
+
+// waitForStates lists the desired states supported for each resource type of HandleWaitFor.
+var waitForStates = map[string][]string{
+	waitResourceStack:     {"active", "inactive", "healthy"},
+	waitResourceContainer: {"running", "healthy", "exited", "absent"},
+	waitResourceEdgeStack: {"deployed"},
+}
+
+// Container readiness states used when evaluating a group of containers.
+const (
+	containerReady   = "ready"
+	containerPending = "pending"
+	containerFailing = "failing"
+)
+
+// pollUntil calls check immediately and then every poll interval until it reports done,
+// returns an error, the timeout elapses, or ctx is cancelled. It returns false without
+// an error when the timeout elapses before check reports done.
+func (s *PortainerMCPServer) pollUntil(ctx context.Context, timeout time.Duration, check func() (bool, error)) (bool, error) {
+	interval := s.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := check()
+		if err != nil || done {
+			return done, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-deadline.C:
+			return false, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseWaitTimeout converts a timeoutSeconds parameter into a duration, applying the
+// default when zero and rejecting negative or too large values.
+func parseWaitTimeout(seconds int) (time.Duration, error) {
+	if seconds == 0 {
+		return defaultWaitTimeout, nil
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if seconds < 0 || timeout > maxWaitTimeout {
+		return 0, fmt.Errorf("timeoutSeconds must be between 1 and %d, got %d", int(maxWaitTimeout.Seconds()), seconds)
+	}
+	return timeout, nil
+}
+
+// containerReadiness classifies a container as ready, pending, or failing.
+// Running containers are ready unless their health check is still starting (pending)
+// or reports unhealthy (failing). Containers that exited with code 0 are ready, as they
+// are one-shot jobs that completed; other exited or dead containers are failing.
+func containerReadiness(c models.DockerContainer) string {
+	switch c.State {
+	case "running":
+		switch {
+		case strings.Contains(c.Status, "(unhealthy)"):
+			return containerFailing
+		case strings.Contains(c.Status, "(health: starting)"):
+			return containerPending
+		default:
+			return containerReady
+		}
+	case "exited":
+		if strings.HasPrefix(c.Status, "Exited (0)") {
+			return containerReady
+		}
+		return containerFailing
+	case "dead":
+		return containerFailing
+	default:
+		// created, restarting, paused, removing
+		return containerPending
+	}
+}
+
+// composeProjectLabel returns the Docker label filter matching the containers of a
+// Compose stack deployed by Portainer, which uses the lowercased stack name as project.
+func composeProjectLabel(stackName string) string {
+	return "com.docker.compose.project=" + strings.ToLower(stackName)
+}
+
+// evaluateContainers returns waitOutcomeReady when all containers are ready, waitOutcomeFailed
+// when one of them has stopped with an error, and an empty string while they are pending.
+// An empty container list is pending, as containers may not have been created yet.
+func evaluateContainers(containers []models.DockerContainer) string {
+	if len(containers) == 0 {
+		return ""
+	}
+
+	outcome := waitOutcomeReady
+	for _, c := range containers {
+		readiness := containerReadiness(c)
+		// Unhealthy containers may still recover; stopped ones will not.
+		if readiness == containerFailing && c.State != "running" {
+			return waitOutcomeFailed
+		}
+		if readiness != containerReady {
+			outcome = ""
+		}
+	}
+	return outcome
+}
+
+// stackContainersReport describes the outcome of waiting for the containers of a stack.
+type stackContainersReport struct {
+	Outcome     string                   `json:"outcome"`
+	Elapsed     string                   `json:"elapsed"`
+	Containers  []models.DockerContainer `json:"containers"`
+	FailingLogs map[string]string        `json:"failing_logs,omitempty"`
+}
+
+// waitForStackContainers polls the containers of a Compose stack until all of them are
+// ready, one of them has definitively failed (exited with a non-zero code or dead), or the
+// timeout elapses. Logs of the containers that are not ready are attached unless the
+// outcome is ready.
+func (s *PortainerMCPServer) waitForStackContainers(ctx context.Context, environmentId int, stackName string, timeout time.Duration) (stackContainersReport, error) {
+	start := time.Now()
+	var report stackContainersReport
+	opts := models.DockerContainerListOptions{All: true, Labels: []string{composeProjectLabel(stackName)}}
+
+	met, err := s.pollUntil(ctx, timeout, func() (bool, error) {
+		containers, err := s.cli.GetDockerContainers(environmentId, opts)
+		if err != nil {
+			return false, err
+		}
+		report.Containers = containers
+
+		report.Outcome = evaluateContainers(containers)
+		return report.Outcome != "", nil
+	})
+	if err != nil {
+		return report, err
+	}
+	if !met {
+		report.Outcome = waitOutcomeTimeout
+	}
+	report.Elapsed = time.Since(start).Round(time.Second).String()
+
+	if report.Outcome != waitOutcomeReady {
+		report.FailingLogs = s.collectContainerLogs(environmentId, report.Containers)
+	}
+
+	return report, nil
+}
+
+// collectContainerLogs returns the last log lines of every container that is not ready,
+// keyed by container name. Log retrieval errors are reported in place of the logs.
+func (s *PortainerMCPServer) collectContainerLogs(environmentId int, containers []models.DockerContainer) map[string]string {
+	logs := map[string]string{}
+	for _, c := range containers {
+		if containerReadiness(c) == containerReady {
+			continue
+		}
+		out, err := s.cli.GetDockerContainerLogs(environmentId, c.ID, models.DockerContainerLogOptions{Tail: failingContainerLogTail})
+		if err != nil {
+			out = fmt.Sprintf("failed to get logs: %v", err)
+		}
+		logs[c.Name] = out
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	return logs
+}
+
+// waitObservation is the result of checking a resource once while waiting for a condition.
+// Outcome is waitOutcomeMet or waitOutcomeFailed once the wait is over, and empty while pending.
+type waitObservation struct {
+	State   string
+	Outcome string
+	Details any
+}
+
+// waitForReport describes the outcome of HandleWaitFor.
+type waitForReport struct {
+	ResourceType string `json:"resource_type"`
+	ID           string `json:"id"`
+	DesiredState string `json:"desired_state"`
+	Outcome      string `json:"outcome"`
+	CurrentState string `json:"current_state"`
+	Elapsed      string `json:"elapsed"`
+	Details      any    `json:"details,omitempty"`
+}
+
+// HandleWaitFor returns an MCP tool handler that polls a regular stack, a container, or an
+// edge stack until it reaches the desired state, can no longer reach it, or the timeout elapses.
+func (s *PortainerMCPServer) HandleWaitFor() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		resourceType, err := parser.GetEnum("resourceType", true, waitResourceStack, waitResourceContainer, waitResourceEdgeStack)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid resourceType parameter", err), nil
+		}
+		states := waitForStates[resourceType]
+
+		id, err := parser.GetString("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+
+		state, err := parser.GetEnum("state", true, states...)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("invalid state parameter for %s", resourceType), err), nil
+		}
+
+		environmentId, err := parser.GetInt("environmentId", resourceType == waitResourceContainer)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid environmentId parameter", err), nil
+		}
+		if resourceType == waitResourceContainer {
+			if err := validatePositiveID("environmentId", environmentId); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		timeoutSeconds, err := parser.GetInt("timeoutSeconds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid timeoutSeconds parameter", err), nil
+		}
+		timeout, err := parseWaitTimeout(timeoutSeconds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var observe func() (waitObservation, error)
+		switch resourceType {
+		case waitResourceStack:
+			stackID, err := parseNumericID(id)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			observe = s.observeStack(stackID, state)
+		case waitResourceContainer:
+			observe = s.observeContainer(environmentId, id, state)
+		case waitResourceEdgeStack:
+			edgeStackID, err := parseNumericID(id)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			observe = s.observeEdgeStack(edgeStackID)
+		}
+
+		start := time.Now()
+		var last waitObservation
+		met, err := s.pollUntil(ctx, timeout, func() (bool, error) {
+			obs, err := observe()
+			if err != nil {
+				return false, err
+			}
+			last = obs
+			return obs.Outcome != "", nil
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("failed to check %s %s", resourceType, id), err), nil
+		}
+		if !met {
+			last.Outcome = waitOutcomeTimeout
+		}
+
+		report := waitForReport{
+			ResourceType: resourceType,
+			ID:           id,
+			DesiredState: state,
+			Outcome:      last.Outcome,
+			CurrentState: last.State,
+			Elapsed:      time.Since(start).Round(time.Second).String(),
+			Details:      last.Details,
+		}
+
+		return jsonResult(report, "failed to marshal wait report")
+	}
+}
+
+// parseNumericID parses the id parameter of a stack or edge stack.
+func parseNumericID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("id must be a numeric stack ID, got '%s'", id)
+	}
+	if err := validatePositiveID("id", n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// observeStack returns a check of a regular stack. The active and inactive states compare
+// the stack status; the healthy state evaluates the containers of the stack.
+func (s *PortainerMCPServer) observeStack(id int, state string) func() (waitObservation, error) {
+	return func() (waitObservation, error) {
+		stack, err := s.cli.InspectStack(id)
+		if err != nil {
+			return waitObservation{}, err
+		}
+		status := regularStackStatusName(stack.Status)
+
+		if state != "healthy" {
+			obs := waitObservation{State: status, Details: stack}
+			if status == state {
+				obs.Outcome = waitOutcomeMet
+			}
+			return obs, nil
+		}
+
+		containers, err := s.cli.GetDockerContainers(stack.EndpointID, models.DockerContainerListOptions{All: true, Labels: []string{composeProjectLabel(stack.Name)}})
+		if err != nil {
+			return waitObservation{}, err
+		}
+
+		counts := map[string]int{}
+		for _, c := range containers {
+			counts[containerReadiness(c)]++
+		}
+		obs := waitObservation{
+			State:   fmt.Sprintf("%s, containers %s", status, formatCounts(counts)),
+			Details: containers,
+		}
+		switch evaluateContainers(containers) {
+		case waitOutcomeReady:
+			obs.Outcome = waitOutcomeMet
+		case waitOutcomeFailed:
+			obs.Outcome = waitOutcomeFailed
+		}
+		return obs, nil
+	}
+}
+
+// observeContainer returns a check of a single container identified by ID or ID prefix.
+// Waiting for running or healthy fails early when the container stops with an error.
+func (s *PortainerMCPServer) observeContainer(environmentId int, id string, state string) func() (waitObservation, error) {
+	return func() (waitObservation, error) {
+		containers, err := s.cli.GetDockerContainers(environmentId, models.DockerContainerListOptions{All: true, ID: id})
+		if err != nil {
+			return waitObservation{}, err
+		}
+
+		if len(containers) == 0 {
+			obs := waitObservation{State: "absent"}
+			if state == "absent" {
+				obs.Outcome = waitOutcomeMet
+			}
+			return obs, nil
+		}
+		if len(containers) > 1 {
+			return waitObservation{}, fmt.Errorf("container ID '%s' is ambiguous: it matches %d containers", id, len(containers))
+		}
+
+		c := containers[0]
+		obs := waitObservation{State: c.State, Details: c}
+		if c.Status != "" {
+			obs.State = fmt.Sprintf("%s (%s)", c.State, c.Status)
+		}
+
+		readiness := containerReadiness(c)
+		switch state {
+		case "running":
+			if c.State == "running" {
+				obs.Outcome = waitOutcomeMet
+			}
+		case "healthy":
+			if c.State == "running" && readiness == containerReady {
+				obs.Outcome = waitOutcomeMet
+			}
+		case "exited":
+			if c.State == "exited" {
+				obs.Outcome = waitOutcomeMet
+			}
+		}
+		if obs.Outcome == "" && (state == "running" || state == "healthy") && readiness == containerFailing && c.State != "running" {
+			obs.Outcome = waitOutcomeFailed
+		}
+		return obs, nil
+	}
+}
+
+// observeEdgeStack returns a check of an edge stack rollout. The rollout is complete when
+// every target environment reports the stack as running or completed, and fails as soon as
+// one environment reports an error.
+func (s *PortainerMCPServer) observeEdgeStack(id int) func() (waitObservation, error) {
+	return func() (waitObservation, error) {
+		status, err := s.cli.GetEdgeStackStatus(id)
+		if err != nil {
+			return waitObservation{}, err
+		}
+
+		rollout := evaluateEdgeStackRollout(status, 0)
+		obs := waitObservation{State: "no environments", Details: status, Outcome: rollout.outcome}
+		if len(status.Environments) > 0 {
+			obs.State = formatCounts(rollout.counts)
+		}
+		return obs, nil
+	}
+}
+
+// edgeStackRollout is the evaluation of the deployment status of an edge stack.
+type edgeStackRollout struct {
+	// outcome is waitOutcomeMet, waitOutcomeFailed, or empty while the rollout is in progress.
+	outcome  string
+	counts   map[string]int
+	deployed int
+	pending  []int
+	failed   []models.EdgeStackEnvironmentStatus
+}
+
+// evaluateEdgeStackRollout evaluates an edge stack rollout. It fails when more than
+// maxFailures environments report an error, and is met once no environment is pending.
+// A stack without target environments is still in progress.
+func evaluateEdgeStackRollout(status models.EdgeStackStatus, maxFailures int) edgeStackRollout {
+	r := edgeStackRollout{counts: map[string]int{}}
+	for _, env := range status.Environments {
+		r.counts[env.Status]++
+		switch env.Status {
+		case models.EdgeStackStatusRunning, models.EdgeStackStatusCompleted:
+			r.deployed++
+		case models.EdgeStackStatusError:
+			r.failed = append(r.failed, env)
+		default:
+			r.pending = append(r.pending, env.EnvironmentID)
+		}
+	}
+
+	switch {
+	case len(r.failed) > maxFailures:
+		r.outcome = waitOutcomeFailed
+	case len(status.Environments) > 0 && len(r.pending) == 0:
+		r.outcome = waitOutcomeMet
+	}
+	return r
+}
+
+// edgeStackRolloutReport is the result of HandleWaitForEdgeStackRollout.
+type edgeStackRolloutReport struct {
+	EdgeStackID           int                           `json:"edge_stack_id"`
+	Name                  string                        `json:"name"`
+	Outcome               string                        `json:"outcome"`
+	Elapsed               string                        `json:"elapsed"`
+	Total                 int                           `json:"total"`
+	Deployed              int                           `json:"deployed"`
+	PendingEnvironmentIDs []int                         `json:"pending_environment_ids,omitempty"`
+	Failed                []edgeStackEnvironmentFailure `json:"failed,omitempty"`
+}
+
+// edgeStackEnvironmentFailure describes an environment where an edge stack failed to deploy.
+type edgeStackEnvironmentFailure struct {
+	EnvironmentID   int    `json:"environment_id"`
+	EnvironmentName string `json:"environment_name,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// HandleWaitForEdgeStackRollout returns an MCP tool handler that polls the per-environment
+// status of an edge stack until every target environment has deployed it, more environments
+// than tolerated fail, or the timeout elapses.
+func (s *PortainerMCPServer) HandleWaitForEdgeStackRollout() server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		parser := toolgen.NewParameterParser(request)
+
+		id, err := parser.GetInt("id", true)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid id parameter", err), nil
+		}
+		if err := validatePositiveID("id", id); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		maxFailures, err := parser.GetInt("maxFailures", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid maxFailures parameter", err), nil
+		}
+		if maxFailures < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("maxFailures must not be negative, got %d", maxFailures)), nil
+		}
+
+		timeoutSeconds, err := parser.GetInt("timeoutSeconds", false)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr("invalid timeoutSeconds parameter", err), nil
+		}
+		timeout, err := parseWaitTimeout(timeoutSeconds)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		start := time.Now()
+		status, rollout, err := s.waitForEdgeStackRollout(ctx, id, maxFailures, timeout)
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("failed to get status of edge stack %d", id), err), nil
+		}
+
+		report := s.newEdgeStackRolloutReport(id, status, rollout, start)
+		return jsonResult(report, "failed to marshal edge stack rollout report")
+	}
+}
+
+// waitForEdgeStackRollout polls the status of an edge stack until every target environment
+// has deployed it, more than maxFailures environments fail, or the timeout elapses, in
+// which case the outcome of the rollout is waitOutcomeTimeout.
+func (s *PortainerMCPServer) waitForEdgeStackRollout(ctx context.Context, id, maxFailures int, timeout time.Duration) (models.EdgeStackStatus, edgeStackRollout, error) {
+	var status models.EdgeStackStatus
+	var rollout edgeStackRollout
+	met, err := s.pollUntil(ctx, timeout, func() (bool, error) {
+		var err error
+		status, err = s.cli.GetEdgeStackStatus(id)
+		if err != nil {
+			return false, err
+		}
+		rollout = evaluateEdgeStackRollout(status, maxFailures)
+		return rollout.outcome != "", nil
+	})
+	if err != nil {
+		return status, rollout, err
+	}
+	if !met {
+		rollout.outcome = waitOutcomeTimeout
+	}
+	return status, rollout, nil
+}
+
+// newEdgeStackRolloutReport returns the report of an edge stack rollout evaluated since
+// start, with the names of the environments where it failed.
+func (s *PortainerMCPServer) newEdgeStackRolloutReport(id int, status models.EdgeStackStatus, rollout edgeStackRollout, start time.Time) edgeStackRolloutReport {
+	report := edgeStackRolloutReport{
+		EdgeStackID:           id,
+		Name:                  status.Name,
+		Outcome:               rollout.outcome,
+		Elapsed:               time.Since(start).Round(time.Second).String(),
+		Total:                 len(status.Environments),
+		Deployed:              rollout.deployed,
+		PendingEnvironmentIDs: rollout.pending,
+	}
+	for _, env := range rollout.failed {
+		failure := edgeStackEnvironmentFailure{EnvironmentID: env.EnvironmentID, Error: env.Error}
+		if e, err := s.cli.GetEnvironment(env.EnvironmentID); err == nil {
+			failure.EnvironmentName = e.Name
+		}
+		report.Failed = append(report.Failed, failure)
+	}
+	return report
+}
@@ -0,0 +1,461 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPollUntil verifies that pollUntil stops on success, error, timeout and cancellation.
+func TestPollUntil(t *testing.T) {
+	s := &PortainerMCPServer{pollInterval: time.Millisecond}
+
+	t.Run("done after retries", func(t *testing.T) {
+		calls := 0
+		done, err := s.pollUntil(context.Background(), time.Second, func() (bool, error) {
+			calls++
+			return calls == 3, nil
+		})
+		assert.NoError(t, err)
+		assert.True(t, done)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("check error", func(t *testing.T) {
+		done, err := s.pollUntil(context.Background(), time.Second, func() (bool, error) {
+			return false, fmt.Errorf("boom")
+		})
+		assert.EqualError(t, err, "boom")
+		assert.False(t, done)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		done, err := s.pollUntil(context.Background(), 10*time.Millisecond, func() (bool, error) {
+			return false, nil
+		})
+		assert.NoError(t, err)
+		assert.False(t, done)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		done, err := s.pollUntil(ctx, time.Second, func() (bool, error) {
+			return false, nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, done)
+	})
+}
+
+// TestParseWaitTimeout verifies the default and bounds of the timeoutSeconds parameter.
+func TestParseWaitTimeout(t *testing.T) {
+	timeout, err := parseWaitTimeout(0)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultWaitTimeout, timeout)
+
+	timeout, err = parseWaitTimeout(30)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	_, err = parseWaitTimeout(-1)
+	assert.Error(t, err)
+
+	_, err = parseWaitTimeout(int(maxWaitTimeout.Seconds()) + 1)
+	assert.Error(t, err)
+}
+
+// TestContainerReadiness verifies the classification of container states.
+func TestContainerReadiness(t *testing.T) {
+	tests := []struct {
+		state    string
+		status   string
+		expected string
+	}{
+		{state: "running", status: "Up 5 seconds", expected: containerReady},
+		{state: "running", status: "Up 5 seconds (healthy)", expected: containerReady},
+		{state: "running", status: "Up 2 seconds (health: starting)", expected: containerPending},
+		{state: "running", status: "Up 1 minute (unhealthy)", expected: containerFailing},
+		{state: "exited", status: "Exited (0) 3 seconds ago", expected: containerReady},
+		{state: "exited", status: "Exited (1) 3 seconds ago", expected: containerFailing},
+		{state: "dead", status: "Dead", expected: containerFailing},
+		{state: "restarting", status: "Restarting (1) 2 seconds ago", expected: containerPending},
+		{state: "created", status: "Created", expected: containerPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state+"/"+tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.expected, containerReadiness(models.DockerContainer{State: tt.state, Status: tt.status}))
+		})
+	}
+}
+
+// TestWaitForStackContainers verifies outcomes and log collection when waiting for stack containers.
+func TestWaitForStackContainers(t *testing.T) {
+	opts := models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=web"}}
+	running := models.DockerContainer{ID: "a1", Name: "web-app-1", State: "running", Status: "Up 3 seconds"}
+	starting := models.DockerContainer{ID: "a1", Name: "web-app-1", State: "running", Status: "Up 1 second (health: starting)"}
+	crashed := models.DockerContainer{ID: "b2", Name: "web-db-1", State: "exited", Status: "Exited (1) 1 second ago"}
+
+	t.Run("ready after health check passes", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetDockerContainers", 1, opts).Return([]models.DockerContainer{starting}, nil).Once()
+		mockClient.On("GetDockerContainers", 1, opts).Return([]models.DockerContainer{running}, nil)
+		s := &PortainerMCPServer{cli: mockClient, pollInterval: time.Millisecond}
+
+		report, err := s.waitForStackContainers(context.Background(), 1, "Web", time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, waitOutcomeReady, report.Outcome)
+		assert.Equal(t, []models.DockerContainer{running}, report.Containers)
+		assert.Nil(t, report.FailingLogs)
+		mockClient.AssertNotCalled(t, "GetDockerContainerLogs", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails fast with logs", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetDockerContainers", 1, opts).Return([]models.DockerContainer{running, crashed}, nil)
+		mockClient.On("GetDockerContainerLogs", 1, "b2", models.DockerContainerLogOptions{Tail: failingContainerLogTail}).Return("connection refused", nil)
+		s := &PortainerMCPServer{cli: mockClient, pollInterval: time.Millisecond}
+
+		report, err := s.waitForStackContainers(context.Background(), 1, "web", time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, waitOutcomeFailed, report.Outcome)
+		assert.Equal(t, map[string]string{"web-db-1": "connection refused"}, report.FailingLogs)
+	})
+
+	t.Run("timeout while starting", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetDockerContainers", 1, opts).Return([]models.DockerContainer{starting}, nil)
+		mockClient.On("GetDockerContainerLogs", 1, "a1", models.DockerContainerLogOptions{Tail: failingContainerLogTail}).Return("", fmt.Errorf("not found"))
+		s := &PortainerMCPServer{cli: mockClient, pollInterval: time.Millisecond}
+
+		report, err := s.waitForStackContainers(context.Background(), 1, "web", 10*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, waitOutcomeTimeout, report.Outcome)
+		assert.Equal(t, map[string]string{"web-app-1": "failed to get logs: not found"}, report.FailingLogs)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		mockClient := &MockPortainerClient{}
+		mockClient.On("GetDockerContainers", 1, opts).Return([]models.DockerContainer(nil), fmt.Errorf("api error"))
+		s := &PortainerMCPServer{cli: mockClient, pollInterval: time.Millisecond}
+
+		_, err := s.waitForStackContainers(context.Background(), 1, "web", time.Second)
+		assert.Error(t, err)
+	})
+}
+
+// TestHandleWaitFor verifies the wait for condition handler for each resource type.
+func TestHandleWaitFor(t *testing.T) {
+	webStack := models.RegularStack{ID: 4, Name: "Web", EndpointID: 1, Status: regularStackStatusActive}
+	stackOpts := models.DockerContainerListOptions{All: true, Labels: []string{"com.docker.compose.project=web"}}
+	containerOpts := models.DockerContainerListOptions{All: true, ID: "abc"}
+
+	tests := []struct {
+		name          string
+		params        map[string]any
+		setupMock     func(*MockPortainerClient)
+		expectError   bool
+		expectOutcome string
+		expectState   string
+	}{
+		{
+			name:   "stack becomes inactive",
+			params: map[string]any{"resourceType": "stack", "id": "4", "state": "inactive"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 4).Return(webStack, nil).Once()
+				m.On("InspectStack", 4).Return(models.RegularStack{ID: 4, Name: "Web", Status: regularStackStatusInactive}, nil)
+			},
+			expectOutcome: waitOutcomeMet,
+			expectState:   "inactive",
+		},
+		{
+			name:   "stack healthy",
+			params: map[string]any{"resourceType": "stack", "id": "4", "state": "healthy"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 4).Return(webStack, nil)
+				m.On("GetDockerContainers", 1, stackOpts).Return([]models.DockerContainer{{ID: "a", State: "running", Status: "Up 1 minute (healthy)"}}, nil)
+			},
+			expectOutcome: waitOutcomeMet,
+			expectState:   "active, containers ready: 1",
+		},
+		{
+			name:   "stack container crashed",
+			params: map[string]any{"resourceType": "stack", "id": "4", "state": "healthy"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 4).Return(webStack, nil)
+				m.On("GetDockerContainers", 1, stackOpts).Return([]models.DockerContainer{{ID: "a", State: "exited", Status: "Exited (137) 2 seconds ago"}}, nil)
+			},
+			expectOutcome: waitOutcomeFailed,
+			expectState:   "active, containers failing: 1",
+		},
+		{
+			name:   "stack timeout",
+			params: map[string]any{"resourceType": "stack", "id": "4", "state": "inactive", "timeoutSeconds": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("InspectStack", 4).Return(webStack, nil)
+			},
+			expectOutcome: waitOutcomeTimeout,
+			expectState:   "active",
+		},
+		{
+			name:   "container running",
+			params: map[string]any{"resourceType": "container", "id": "abc", "state": "running", "environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 1, containerOpts).Return([]models.DockerContainer{{ID: "abc", State: "running", Status: "Up 2 seconds"}}, nil)
+			},
+			expectOutcome: waitOutcomeMet,
+			expectState:   "running (Up 2 seconds)",
+		},
+		{
+			name:   "container exited while waiting for healthy",
+			params: map[string]any{"resourceType": "container", "id": "abc", "state": "healthy", "environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 1, containerOpts).Return([]models.DockerContainer{{ID: "abc", State: "exited", Status: "Exited (1) 1 second ago"}}, nil)
+			},
+			expectOutcome: waitOutcomeFailed,
+			expectState:   "exited (Exited (1) 1 second ago)",
+		},
+		{
+			name:   "container absent",
+			params: map[string]any{"resourceType": "container", "id": "abc", "state": "absent", "environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 1, containerOpts).Return([]models.DockerContainer{}, nil)
+			},
+			expectOutcome: waitOutcomeMet,
+			expectState:   "absent",
+		},
+		{
+			name:   "ambiguous container ID",
+			params: map[string]any{"resourceType": "container", "id": "abc", "state": "running", "environmentId": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetDockerContainers", 1, containerOpts).Return([]models.DockerContainer{{ID: "abc1"}, {ID: "abc2"}}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name:   "edge stack deployed",
+			params: map[string]any{"resourceType": "edgeStack", "id": "3", "state": "deployed"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Environments: []models.EdgeStackEnvironmentStatus{
+					{EnvironmentID: 1, Status: models.EdgeStackStatusRunning},
+					{EnvironmentID: 2, Status: models.EdgeStackStatusCompleted},
+				}}, nil)
+			},
+			expectOutcome: waitOutcomeMet,
+			expectState:   "completed: 1, running: 1",
+		},
+		{
+			name:   "edge stack deployment error",
+			params: map[string]any{"resourceType": "edgeStack", "id": "3", "state": "deployed"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Environments: []models.EdgeStackEnvironmentStatus{
+					{EnvironmentID: 1, Status: models.EdgeStackStatusRunning},
+					{EnvironmentID: 2, Status: models.EdgeStackStatusError, Error: "pull failed"},
+				}}, nil)
+			},
+			expectOutcome: waitOutcomeFailed,
+			expectState:   "error: 1, running: 1",
+		},
+		{
+			name:   "edge stack API error",
+			params: map[string]any{"resourceType": "edgeStack", "id": "3", "state": "deployed"},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{}, fmt.Errorf("not found"))
+			},
+			expectError: true,
+		},
+		{
+			name:        "invalid resource type",
+			params:      map[string]any{"resourceType": "volume", "id": "4", "state": "active"},
+			expectError: true,
+		},
+		{
+			name:        "state not supported for resource type",
+			params:      map[string]any{"resourceType": "edgeStack", "id": "3", "state": "running"},
+			expectError: true,
+		},
+		{
+			name:        "non-numeric stack ID",
+			params:      map[string]any{"resourceType": "stack", "id": "web", "state": "active"},
+			expectError: true,
+		},
+		{
+			name:        "container without environmentId",
+			params:      map[string]any{"resourceType": "container", "id": "abc", "state": "running"},
+			expectError: true,
+		},
+		{
+			name:        "missing state",
+			params:      map[string]any{"resourceType": "stack", "id": "4"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient, pollInterval: 100 * time.Millisecond}
+
+			result, err := server.HandleWaitFor()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			if tt.expectError {
+				assert.True(t, result.IsError)
+				return
+			}
+
+			require.False(t, result.IsError)
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var report waitForReport
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+			assert.Equal(t, tt.params["resourceType"], report.ResourceType)
+			assert.Equal(t, tt.params["state"], report.DesiredState)
+			assert.Equal(t, tt.expectOutcome, report.Outcome)
+			assert.Equal(t, tt.expectState, report.CurrentState)
+		})
+	}
+}
+
+// TestHandleWaitForEdgeStackRollout verifies the HandleWaitForEdgeStackRollout MCP tool handler.
+func TestHandleWaitForEdgeStackRollout(t *testing.T) {
+	env := func(id int, status, errMsg string) models.EdgeStackEnvironmentStatus {
+		return models.EdgeStackEnvironmentStatus{EnvironmentID: id, Status: status, Error: errMsg}
+	}
+
+	tests := []struct {
+		name           string
+		params         map[string]any
+		setupMock      func(*MockPortainerClient)
+		expectError    string
+		expectOutcome  string
+		expectDeployed int
+		expectPending  []int
+		expectFailed   []edgeStackEnvironmentFailure
+	}{
+		{
+			name:   "all environments deployed",
+			params: map[string]any{"id": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Name: "web", Environments: []models.EdgeStackEnvironmentStatus{
+					env(1, models.EdgeStackStatusRunning, ""), env(2, models.EdgeStackStatusPending, ""),
+				}}, nil).Once()
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Name: "web", Environments: []models.EdgeStackEnvironmentStatus{
+					env(1, models.EdgeStackStatusRunning, ""), env(2, models.EdgeStackStatusCompleted, ""),
+				}}, nil)
+			},
+			expectOutcome:  waitOutcomeMet,
+			expectDeployed: 2,
+		},
+		{
+			name:   "first failure stops the wait",
+			params: map[string]any{"id": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Environments: []models.EdgeStackEnvironmentStatus{
+					env(1, models.EdgeStackStatusRunning, ""), env(2, models.EdgeStackStatusError, "pull access denied"), env(4, models.EdgeStackStatusPending, ""),
+				}}, nil)
+				m.On("GetEnvironment", 2).Return(models.Environment{ID: 2, Name: "edge-02"}, nil)
+			},
+			expectOutcome:  waitOutcomeFailed,
+			expectDeployed: 1,
+			expectPending:  []int{4},
+			expectFailed:   []edgeStackEnvironmentFailure{{EnvironmentID: 2, EnvironmentName: "edge-02", Error: "pull access denied"}},
+		},
+		{
+			name:   "tolerated failure",
+			params: map[string]any{"id": float64(3), "maxFailures": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Environments: []models.EdgeStackEnvironmentStatus{
+					env(1, models.EdgeStackStatusRunning, ""), env(2, models.EdgeStackStatusError, "no space left on device"),
+				}}, nil)
+				m.On("GetEnvironment", 2).Return(models.Environment{}, fmt.Errorf("not found"))
+			},
+			expectOutcome:  waitOutcomeMet,
+			expectDeployed: 1,
+			expectFailed:   []edgeStackEnvironmentFailure{{EnvironmentID: 2, Error: "no space left on device"}},
+		},
+		{
+			name:   "timeout with pending environments",
+			params: map[string]any{"id": float64(3), "timeoutSeconds": float64(1)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{ID: 3, Environments: []models.EdgeStackEnvironmentStatus{
+					env(1, models.EdgeStackStatusPending, ""),
+				}}, nil)
+			},
+			expectOutcome: waitOutcomeTimeout,
+			expectPending: []int{1},
+		},
+		{
+			name:   "API error",
+			params: map[string]any{"id": float64(3)},
+			setupMock: func(m *MockPortainerClient) {
+				m.On("GetEdgeStackStatus", 3).Return(models.EdgeStackStatus{}, fmt.Errorf("not found"))
+			},
+			expectError: "failed to get status of edge stack 3",
+		},
+		{
+			name:        "missing id",
+			params:      map[string]any{},
+			expectError: "id is required",
+		},
+		{
+			name:        "negative maxFailures",
+			params:      map[string]any{"id": float64(3), "maxFailures": float64(-1)},
+			expectError: "maxFailures must not be negative",
+		},
+		{
+			name:        "timeout too large",
+			params:      map[string]any{"id": float64(3), "timeoutSeconds": float64(3600)},
+			expectError: "timeoutSeconds must be between 1 and 900",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockPortainerClient{}
+			if tt.setupMock != nil {
+				tt.setupMock(mockClient)
+			}
+
+			server := &PortainerMCPServer{cli: mockClient, pollInterval: 100 * time.Millisecond}
+
+			result, err := server.HandleWaitForEdgeStackRollout()(context.Background(), CreateMCPRequest(tt.params))
+			require.NoError(t, err)
+			require.NotNil(t, result)
+
+			textContent, ok := result.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			if tt.expectError != "" {
+				assert.True(t, result.IsError)
+				assert.Contains(t, textContent.Text, tt.expectError)
+				return
+			}
+
+			require.False(t, result.IsError)
+			var report edgeStackRolloutReport
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &report))
+			assert.Equal(t, 3, report.EdgeStackID)
+			assert.Equal(t, tt.expectOutcome, report.Outcome)
+			assert.Equal(t, tt.expectDeployed, report.Deployed)
+			assert.Equal(t, tt.expectPending, report.PendingEnvironmentIDs)
+			assert.Equal(t, tt.expectFailed, report.Failed)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
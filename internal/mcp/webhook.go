@@ -6,6 +6,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/journal"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/toolgen"
 )
 
@@ -79,11 +80,15 @@ func (s *PortainerMCPServer) HandleDeleteWebhook() server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		entry := s.captureDeletion(journal.KindWebhook, id, func() (journal.Entry, error) {
+			return s.captureWebhook(id)
+		})
+
 		err = s.cli.DeleteWebhook(id)
 		if err != nil {
 			return mcp.NewToolResultErrorFromErr("failed to delete webhook", err), nil
 		}
 
-		return mcp.NewToolResultText("Webhook deleted successfully"), nil
+		return s.deletionResult(ToolDeleteWebhook, "Webhook deleted successfully", entry), nil
 	}
 }
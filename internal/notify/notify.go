@@ -0,0 +1,136 @@
+// Package notify posts a message to an operator-configured webhook whenever a destructive
+// tool call succeeds, so that changes made through the MCP server are visible outside of
+// the client session.
+//
+// The JSON payload carries a human-readable "text" field, which Slack and Mattermost
+// incoming webhooks display as the message, alongside the structured fields of the event
+// for generic webhook receivers.
+//
+// Every payload is sent with a random delivery ID and, when a secret is configured, signed
+// so that the receiver can check that it comes from the MCP server unaltered. The headers
+// and the signature are defined by the public pkg/notify package, whose Verify receivers
+// written in Go can import.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	signature "github.com/jmrplens/portainer-mcp-enhanced/pkg/notify"
+)
+
+// DefaultTimeout bounds the time spent delivering one notification.
+const DefaultTimeout = 10 * time.Second
+
+// Event describes a destructive tool call that succeeded.
+type Event struct {
+	// Tool is the name of the called tool (granular tool or meta-tool).
+	Tool string `json:"tool"`
+	// Action is the meta-tool action, empty for granular tools.
+	Action string `json:"action,omitempty"`
+	// Resource identifies the affected resource, e.g. "environmentId=3 id=12".
+	Resource string `json:"resource,omitempty"`
+	// Session is the ID of the MCP client session that made the call.
+	Session string `json:"session,omitempty"`
+	// Summary is a short excerpt of the tool result.
+	Summary string `json:"summary,omitempty"`
+	// Time is when the call completed.
+	Time time.Time `json:"time"`
+}
+
+// Text returns a one-line, human-readable description of the event.
+func (e Event) Text() string {
+	var b strings.Builder
+	b.WriteString("Destructive action ")
+	if e.Action != "" {
+		fmt.Fprintf(&b, "`%s.%s`", e.Tool, e.Action)
+	} else {
+		fmt.Fprintf(&b, "`%s`", e.Tool)
+	}
+	b.WriteString(" succeeded")
+	if e.Resource != "" {
+		fmt.Fprintf(&b, " on %s", e.Resource)
+	}
+	if e.Session != "" {
+		fmt.Fprintf(&b, " (session %s)", e.Session)
+	}
+	if e.Summary != "" {
+		fmt.Fprintf(&b, ": %s", e.Summary)
+	}
+	return b.String()
+}
+
+// payload is the JSON document posted to the webhook.
+type payload struct {
+	Text string `json:"text"`
+	Event
+}
+
+// Notifier delivers events to a webhook. It is safe for concurrent use.
+type Notifier struct {
+	url    string
+	secret string
+	client *http.Client
+	now    func() time.Time
+}
+
+// New creates a notifier that posts to webhookURL, which must be an absolute HTTP or
+// HTTPS URL. When secret is not empty, the payloads are signed with it.
+func New(webhookURL, secret string) (*Notifier, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", webhookURL)
+	}
+
+	return &Notifier{
+		url:    webhookURL,
+		secret: secret,
+		client: &http.Client{Timeout: DefaultTimeout},
+		now:    time.Now,
+	}, nil
+}
+
+// Notify posts an event to the webhook. Any non-2xx response is reported as an error.
+func (n *Notifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(payload{Text: event.Text(), Event: event})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	delivery := rand.Text()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(signature.DeliveryHeader, delivery)
+	if n.secret != "" {
+		timestamp := n.now().Unix()
+		req.Header.Set(signature.TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(signature.SignatureHeader, signature.Sign(n.secret, timestamp, delivery, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	signature "github.com/jmrplens/portainer-mcp-enhanced/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventText verifies the human-readable message of an event.
+func TestEventText(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{
+			name:  "granular tool",
+			event: Event{Tool: "deleteStack", Resource: "id=5", Session: "abc", Summary: "Stack deleted successfully"},
+			want:  "Destructive action `deleteStack` succeeded on id=5 (session abc): Stack deleted successfully",
+		},
+		{
+			name:  "meta-tool action without details",
+			event: Event{Tool: "manage_stacks", Action: "delete_stack"},
+			want:  "Destructive action `manage_stacks.delete_stack` succeeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.event.Text())
+		})
+	}
+}
+
+// TestNotify verifies the payload posted to the webhook and the handling of its response.
+func TestNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			n, err := New(srv.URL, "")
+			require.NoError(t, err)
+
+			event := Event{Tool: "deleteRegistry", Resource: "id=2", Time: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+			err = n.Notify(context.Background(), event)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, event.Text(), received["text"])
+			assert.Equal(t, "deleteRegistry", received["tool"])
+			assert.Equal(t, "id=2", received["resource"])
+			assert.Equal(t, "2025-01-01T00:00:00Z", received["time"])
+		})
+	}
+}
+
+// TestNew verifies webhook URL validation.
+func TestNew(t *testing.T) {
+	for _, u := range []string{"https://hooks.slack.com/services/T/B/X", "http://localhost:8080/hook"} {
+		_, err := New(u, "")
+		assert.NoError(t, err, u)
+	}
+	for _, u := range []string{"", "hooks.slack.com/services", "ftp://example.com/hook", "https://"} {
+		_, err := New(u, "")
+		assert.Error(t, err, u)
+	}
+}
+
+// TestNotifySigned verifies that the payloads are signed when a secret is configured, and
+// that the receiver can verify them.
+func TestNotifySigned(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	n, err := New(srv.URL, "s3cret")
+	require.NoError(t, err)
+	n.now = func() time.Time { return now }
+	require.NoError(t, n.Notify(context.Background(), Event{Tool: "deleteStack", Time: now}))
+
+	delivery := header.Get(signature.DeliveryHeader)
+	assert.NotEmpty(t, delivery)
+	assert.Equal(t, "1748779200", header.Get(signature.TimestampHeader))
+	assert.Equal(t, signature.Sign("s3cret", now.Unix(), delivery, body), header.Get(signature.SignatureHeader))
+	assert.NoError(t, signature.Verify("s3cret", header, body, now.Add(time.Minute)))
+
+	unsigned, err := New(srv.URL, "")
+	require.NoError(t, err)
+	require.NoError(t, unsigned.Notify(context.Background(), Event{Tool: "deleteStack"}))
+	assert.Empty(t, header.Get(signature.SignatureHeader))
+	assert.Empty(t, header.Get(signature.TimestampHeader))
+	assert.NotEmpty(t, header.Get(signature.DeliveryHeader), "unsigned payloads carry a delivery ID")
+	assert.NotEqual(t, delivery, header.Get(signature.DeliveryHeader), "each delivery has its own ID")
+}
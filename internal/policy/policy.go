@@ -0,0 +1,167 @@
+// Package policy decides whether write tool calls may run, from operator-defined rules
+// loaded from a YAML or JSON file, such as "deny deleteEnvironment where environmentName
+// matches prod-*".
+//
+// A rule selects tools by name and, optionally, the calls whose attributes (the call
+// arguments and the attributes resolved by the server, such as the name of the targeted
+// environment) match glob patterns. The first matching rule decides; a call matching no
+// rule is allowed.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule effects
+const (
+	EffectDeny  = "deny"
+	EffectAllow = "allow"
+)
+
+// Rule is one policy rule as written in the policy file.
+type Rule struct {
+	// Name identifies the rule in decisions and error messages.
+	Name string `yaml:"name" json:"name"`
+	// Effect is deny (the default) or allow. Allow rules placed before a deny rule make
+	// exceptions to it.
+	Effect string `yaml:"effect" json:"effect"`
+	// Tools lists the tools the rule applies to, as glob patterns matched against the
+	// granular tool name (e.g. "deleteEnvironment"), the meta-tool action
+	// (e.g. "delete_environment") and both joined by a dot
+	// (e.g. "manage_environments.delete_environment"). Empty means all write tools.
+	Tools []string `yaml:"tools" json:"tools"`
+	// Where maps call attributes to glob patterns that they must all match. A missing
+	// attribute does not match.
+	Where map[string]string `yaml:"where" json:"where"`
+	// Reason explains the decision to the caller.
+	Reason string `yaml:"reason" json:"reason"`
+}
+
+// Config is the content of a policy file.
+type Config struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Call describes a write tool call to evaluate.
+type Call struct {
+	// Names are the names the call is known by: its granular tool name and, when the tool
+	// is also a meta-tool action, the action and the meta-tool action joined by a dot.
+	Names []string
+	// Attributes are the call arguments and the attributes resolved by the server, as text.
+	Attributes map[string]string
+}
+
+// Decision is the outcome of the evaluation of a call.
+type Decision struct {
+	Allowed bool
+	// Rule is the name of the deciding rule, empty when no rule matched.
+	Rule string
+	// Reason is the reason of the deciding rule.
+	Reason string
+}
+
+// Engine evaluates a set of validated policy rules. It is safe for concurrent use.
+type Engine struct {
+	rules []Rule
+}
+
+// Load reads a policy file (YAML or JSON) and validates its rules.
+func Load(file string) (*Engine, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+	}
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("policy file %s defines no rule", file)
+	}
+
+	return New(cfg.Rules)
+}
+
+// New validates the rules and returns an engine evaluating them in order.
+func New(rules []Rule) (*Engine, error) {
+	validated := make([]Rule, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		switch rule.Effect {
+		case "":
+			rule.Effect = EffectDeny
+		case EffectDeny, EffectAllow:
+		default:
+			return nil, fmt.Errorf("%s: invalid effect %q (expected %s or %s)", rule.Name, rule.Effect, EffectDeny, EffectAllow)
+		}
+		for _, pattern := range rule.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid tool pattern %q: %w", rule.Name, pattern, err)
+			}
+		}
+		for attribute, pattern := range rule.Where {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid pattern %q for %s: %w", rule.Name, pattern, attribute, err)
+			}
+		}
+		validated[i] = rule
+	}
+	return &Engine{rules: validated}, nil
+}
+
+// Len returns the number of rules of the engine.
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// UsesAttribute reports whether a rule matches on an attribute, so that the caller only
+// resolves the attributes that cost an API call when needed.
+func (e *Engine) UsesAttribute(attribute string) bool {
+	return slices.ContainsFunc(e.rules, func(rule Rule) bool {
+		_, ok := rule.Where[attribute]
+		return ok
+	})
+}
+
+// Evaluate returns the decision of the first rule matching the call, or allows the call
+// when no rule matches.
+func (e *Engine) Evaluate(call Call) Decision {
+	for _, rule := range e.rules {
+		if rule.matches(call) {
+			return Decision{Allowed: rule.Effect == EffectAllow, Rule: rule.Name, Reason: rule.Reason}
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+// matches reports whether a rule applies to a call.
+func (r Rule) matches(call Call) bool {
+	if len(r.Tools) > 0 && !slices.ContainsFunc(r.Tools, func(pattern string) bool {
+		return slices.ContainsFunc(call.Names, func(name string) bool { return match(pattern, name) })
+	}) {
+		return false
+	}
+
+	for attribute, pattern := range r.Where {
+		value, ok := call.Attributes[attribute]
+		if !ok || !match(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether a value matches a glob pattern. Invalid patterns are rejected
+// by New.
+func match(pattern, value string) bool {
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
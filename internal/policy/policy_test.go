@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluate verifies that the first matching rule decides.
+func TestEvaluate(t *testing.T) {
+	engine, err := New([]Rule{
+		{Name: "sandbox", Effect: EffectAllow, Tools: []string{"delete_environment"}, Where: map[string]string{"environmentName": "prod-sandbox"}},
+		{Name: "protect-prod", Tools: []string{"delete_environment", "deleteStack"}, Where: map[string]string{"environmentName": "prod-*"}, Reason: "production is protected"},
+		{Tools: []string{"manage_docker.*"}, Where: map[string]string{"method": "DELETE", "path": "/volumes/*"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, engine.Len())
+	assert.True(t, engine.UsesAttribute("environmentName"))
+	assert.False(t, engine.UsesAttribute("name"))
+
+	deleteEnvironment := []string{"deleteEnvironment", "delete_environment", "manage_environments.delete_environment"}
+	tests := []struct {
+		name string
+		call Call
+		want Decision
+	}{
+		{
+			name: "denied",
+			call: Call{Names: deleteEnvironment, Attributes: map[string]string{"id": "3", "environmentName": "prod-eu"}},
+			want: Decision{Rule: "protect-prod", Reason: "production is protected"},
+		},
+		{
+			name: "allowed by an earlier rule",
+			call: Call{Names: deleteEnvironment, Attributes: map[string]string{"environmentName": "prod-sandbox"}},
+			want: Decision{Allowed: true, Rule: "sandbox"},
+		},
+		{
+			name: "attribute not matching",
+			call: Call{Names: deleteEnvironment, Attributes: map[string]string{"environmentName": "staging"}},
+			want: Decision{Allowed: true},
+		},
+		{
+			name: "missing attribute",
+			call: Call{Names: deleteEnvironment, Attributes: map[string]string{"id": "3"}},
+			want: Decision{Allowed: true},
+		},
+		{
+			name: "all attributes must match",
+			call: Call{Names: []string{"dockerProxy", "docker_proxy", "manage_docker.docker_proxy"}, Attributes: map[string]string{"method": "DELETE", "path": "/volumes/data"}},
+			want: Decision{Rule: "rule 3"},
+		},
+		{
+			name: "one attribute not matching",
+			call: Call{Names: []string{"dockerProxy", "docker_proxy", "manage_docker.docker_proxy"}, Attributes: map[string]string{"method": "POST", "path": "/volumes/data"}},
+			want: Decision{Allowed: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, engine.Evaluate(tt.call))
+		})
+	}
+}
+
+// TestRuleWithoutTools verifies that a rule without tools applies to every call.
+func TestRuleWithoutTools(t *testing.T) {
+	engine, err := New([]Rule{{Name: "freeze", Reason: "change freeze"}})
+	require.NoError(t, err)
+	assert.Equal(t, Decision{Rule: "freeze", Reason: "change freeze"}, engine.Evaluate(Call{Names: []string{"createTag"}}))
+}
+
+// TestNewInvalidRules verifies the validation of the rules.
+func TestNewInvalidRules(t *testing.T) {
+	_, err := New([]Rule{{Name: "r", Effect: "audit"}})
+	assert.ErrorContains(t, err, `r: invalid effect "audit"`)
+
+	_, err = New([]Rule{{Tools: []string{"delete["}}})
+	assert.ErrorContains(t, err, `rule 1: invalid tool pattern "delete["`)
+
+	_, err = New([]Rule{{Where: map[string]string{"name": "[a-"}}})
+	assert.ErrorContains(t, err, `invalid pattern "[a-" for name`)
+}
+
+// TestLoad verifies loading a policy file.
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("rules:\n  - name: protect-prod\n    tools: [delete_environment]\n    where:\n      environmentName: prod-*\n"), 0o600))
+
+	engine, err := Load(file)
+	require.NoError(t, err)
+	assert.False(t, engine.Evaluate(Call{Names: []string{"delete_environment"}, Attributes: map[string]string{"environmentName": "prod-1"}}).Allowed)
+
+	empty := filepath.Join(dir, "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, []byte("rules: []\n"), 0o600))
+	_, err = Load(empty)
+	assert.ErrorContains(t, err, "defines no rule")
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read policy file")
+}
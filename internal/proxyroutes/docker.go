@@ -0,0 +1,145 @@
+package proxyroutes
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+//go:embed docker_routes.txt
+var dockerRoutesFile string
+
+// dockerRoute is one entry of the Docker route table.
+type dockerRoute struct {
+	method   string
+	segments []string
+	// literals is the number of fixed segments; when several routes match a path,
+	// only the most specific ones are considered.
+	literals int
+}
+
+var (
+	dockerRoutes = parseDockerRoutes(dockerRoutesFile)
+	// dockerVersionPrefix matches the optional API version prefix, such as v1.41.
+	dockerVersionPrefix = regexp.MustCompile(`^v[0-9]+\.[0-9]+$`)
+)
+
+// parseDockerRoutes parses the embedded route table. It panics on malformed lines,
+// which can only come from a broken build.
+func parseDockerRoutes(data string) []dockerRoute {
+	var routes []dockerRoute
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		method, p, ok := strings.Cut(line, " ")
+		if !ok {
+			panic(fmt.Sprintf("proxyroutes: malformed Docker route %q", line))
+		}
+		r := dockerRoute{method: method, segments: splitPath(p)}
+		for _, seg := range r.segments {
+			if !isParam(seg) {
+				r.literals++
+			}
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// ValidateDocker checks that method and path form a known Docker Engine API request.
+// The path may carry an API version prefix (/v1.41/...) and a query string.
+func ValidateDocker(method, path string) error {
+	method = strings.ToUpper(method)
+	segs := splitPath(path)
+	if len(segs) > 0 && dockerVersionPrefix.MatchString(segs[0]) {
+		segs = segs[1:]
+	}
+
+	best := -1
+	var allowed []string
+	for _, r := range dockerRoutes {
+		if !matchSegments(r.segments, segs) {
+			continue
+		}
+		switch {
+		case r.literals > best:
+			best, allowed = r.literals, []string{r.method}
+		case r.literals == best:
+			allowed = append(allowed, r.method)
+		}
+	}
+
+	if best < 0 {
+		if s := suggestDockerPath(segs); s != "" {
+			return fmt.Errorf("unknown Docker API path %q, did you mean %q?", path, s)
+		}
+		return fmt.Errorf("unknown Docker API path %q", path)
+	}
+
+	if slices.Contains(allowed, method) {
+		return nil
+	}
+	return methodError("Docker", method, path, allowed)
+}
+
+// matchSegments reports whether path segments match a route pattern.
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if len(segs) == 0 {
+		return false
+	}
+
+	if isMultiParam(pattern[0]) {
+		// Consume at least one segment, leaving enough for the rest of the pattern.
+		for n := 1; n <= len(segs)-len(pattern)+1; n++ {
+			if matchSegments(pattern[1:], segs[n:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !isParam(pattern[0]) && pattern[0] != segs[0] {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
+
+// suggestDockerPath returns the known path closest to segs, keeping the parameter
+// values of the request, or an empty string when nothing is close enough.
+func suggestDockerPath(segs []string) string {
+	best, bestDist := "", maxSuggestDistance+1
+	for _, r := range dockerRoutes {
+		if len(r.segments) != len(segs) {
+			continue
+		}
+		dist := 0
+		out := make([]string, len(segs))
+		for i, seg := range r.segments {
+			if isParam(seg) {
+				out[i] = segs[i]
+				continue
+			}
+			dist += levenshtein(segs[i], seg)
+			out[i] = seg
+		}
+		if dist > 0 && dist < bestDist {
+			best, bestDist = "/"+strings.Join(out, "/"), dist
+		}
+	}
+	return best
+}
+
+func isParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+func isMultiParam(seg string) bool {
+	return isParam(seg) && strings.HasSuffix(seg, "*}")
+}
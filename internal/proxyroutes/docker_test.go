@@ -0,0 +1,105 @@
+package proxyroutes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateDocker verifies Docker paths and methods against the route table.
+func TestValidateDocker(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		expectedErr string
+	}{
+		{name: "list containers", method: "GET", path: "/containers/json"},
+		{name: "query string", method: "GET", path: "/containers/json?all=true"},
+		{name: "version prefix", method: "GET", path: "/v1.41/containers/json"},
+		{name: "lowercase method", method: "get", path: "/info"},
+		{name: "inspect container", method: "GET", path: "/containers/abc/json"},
+		{name: "create container", method: "POST", path: "/containers/create"},
+		{name: "delete container", method: "DELETE", path: "/containers/abc"},
+		{name: "archive head", method: "HEAD", path: "/containers/abc/archive"},
+		{name: "image with registry", method: "GET", path: "/images/registry.local:5000/team/app:1.0/json"},
+		{name: "delete image with slash", method: "DELETE", path: "/images/library/nginx"},
+		{name: "list images", method: "GET", path: "/images/json"},
+		{name: "volume", method: "GET", path: "/volumes/data"},
+		{name: "exec", method: "POST", path: "/exec/123/start"},
+		{name: "ping", method: "HEAD", path: "/_ping"},
+		{
+			name:        "typo with suggestion",
+			method:      "GET",
+			path:        "/container/json",
+			expectedErr: `unknown Docker API path "/container/json", did you mean "/containers/json"?`,
+		},
+		{
+			name:        "typo keeps parameters",
+			method:      "GET",
+			path:        "/containers/abc/jsn",
+			expectedErr: `did you mean "/containers/abc/json"?`,
+		},
+		{
+			name:        "unknown path",
+			method:      "GET",
+			path:        "/foo/bar/baz/qux",
+			expectedErr: `unknown Docker API path "/foo/bar/baz/qux"`,
+		},
+		{
+			name:        "root",
+			method:      "GET",
+			path:        "/",
+			expectedErr: `unknown Docker API path "/"`,
+		},
+		{
+			name:        "wrong method",
+			method:      "POST",
+			path:        "/containers/json",
+			expectedErr: `method POST is not allowed for Docker API path "/containers/json" (allowed: GET)`,
+		},
+		{
+			name:        "static route wins over parameter",
+			method:      "DELETE",
+			path:        "/containers/create",
+			expectedErr: "(allowed: POST)",
+		},
+		{
+			name:        "several methods",
+			method:      "POST",
+			path:        "/volumes/data",
+			expectedErr: "(allowed: DELETE, GET, PUT)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDocker(tt.method, tt.path)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
+
+// TestDockerRoutesParsed verifies the embedded route table is loaded.
+func TestDockerRoutesParsed(t *testing.T) {
+	assert.Greater(t, len(dockerRoutes), 100)
+	for _, r := range dockerRoutes {
+		assert.Contains(t, []string{"GET", "POST", "PUT", "DELETE", "HEAD"}, r.method)
+		assert.NotEmpty(t, r.segments)
+	}
+}
+
+// TestLevenshtein verifies the edit distance used for suggestions.
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, levenshtein("pods", "pods"))
+	assert.Equal(t, 1, levenshtein("pod", "pods"))
+	assert.Equal(t, 1, levenshtein("container", "containers"))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+	assert.Equal(t, 2, levenshtein("jsno", "json"))
+}
@@ -0,0 +1,207 @@
+package proxyroutes
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	methodsRead       = []string{"GET"}
+	methodsCreate     = []string{"POST"}
+	methodsCollection = []string{"GET", "POST", "DELETE"}
+	methodsItem       = []string{"GET", "PUT", "PATCH", "DELETE"}
+	methodsUpdate     = []string{"GET", "PUT", "PATCH"}
+	methodsStream     = []string{"GET", "POST"}
+	methodsAny        = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+)
+
+// kubernetesRoots are the top-level paths served by the API server besides /api and /apis.
+// They only support reads.
+var kubernetesRoots = []string{"version", "healthz", "livez", "readyz", "metrics", "openapi", ".well-known", "openid"}
+
+// kubernetesVersion matches API versions such as v1, v2 or v1beta1.
+var kubernetesVersion = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+// coreResources are the resources of the core API group (/api/v1).
+var coreResources = []string{
+	"bindings", "componentstatuses", "configmaps", "endpoints", "events", "limitranges",
+	"namespaces", "nodes", "persistentvolumeclaims", "persistentvolumes", "pods",
+	"podtemplates", "replicationcontrollers", "resourcequotas", "secrets",
+	"serviceaccounts", "services",
+}
+
+// groupResources are the resources of the built-in API groups. Requests to other
+// groups, such as those of custom resources, are accepted without resource checks.
+var groupResources = map[string][]string{
+	"admissionregistration.k8s.io": {"mutatingwebhookconfigurations", "validatingadmissionpolicies", "validatingadmissionpolicybindings", "validatingwebhookconfigurations"},
+	"apiextensions.k8s.io":         {"customresourcedefinitions"},
+	"apiregistration.k8s.io":       {"apiservices"},
+	"apps":                         {"controllerrevisions", "daemonsets", "deployments", "replicasets", "statefulsets"},
+	"authentication.k8s.io":        {"selfsubjectreviews", "tokenreviews"},
+	"authorization.k8s.io":         {"localsubjectaccessreviews", "selfsubjectaccessreviews", "selfsubjectrulesreviews", "subjectaccessreviews"},
+	"autoscaling":                  {"horizontalpodautoscalers"},
+	"batch":                        {"cronjobs", "jobs"},
+	"certificates.k8s.io":          {"certificatesigningrequests"},
+	"coordination.k8s.io":          {"leases"},
+	"discovery.k8s.io":             {"endpointslices"},
+	"events.k8s.io":                {"events"},
+	"flowcontrol.apiserver.k8s.io": {"flowschemas", "prioritylevelconfigurations"},
+	"metrics.k8s.io":               {"nodes", "pods"},
+	"networking.k8s.io":            {"ingressclasses", "ingresses", "networkpolicies"},
+	"node.k8s.io":                  {"runtimeclasses"},
+	"policy":                       {"poddisruptionbudgets"},
+	"rbac.authorization.k8s.io":    {"clusterrolebindings", "clusterroles", "rolebindings", "roles"},
+	"scheduling.k8s.io":            {"priorityclasses"},
+	"storage.k8s.io":               {"csidrivers", "csinodes", "csistoragecapacities", "storageclasses", "volumeattachments"},
+}
+
+// createOnlyResources can only be created; they are never stored or listed.
+var createOnlyResources = map[string]bool{
+	"bindings": true, "localsubjectaccessreviews": true, "selfsubjectaccessreviews": true,
+	"selfsubjectreviews": true, "selfsubjectrulesreviews": true, "subjectaccessreviews": true,
+	"tokenreviews": true,
+}
+
+// subresources maps the built-in subresources to the methods they support.
+var subresources = map[string][]string{
+	"approval":            methodsUpdate,
+	"attach":              methodsStream,
+	"binding":             methodsCreate,
+	"ephemeralcontainers": methodsUpdate,
+	"eviction":            methodsCreate,
+	"exec":                methodsStream,
+	"finalize":            {"PUT"},
+	"log":                 methodsRead,
+	"portforward":         methodsStream,
+	"proxy":               methodsAny,
+	"resize":              methodsUpdate,
+	"scale":               methodsUpdate,
+	"status":              methodsUpdate,
+	"token":               methodsCreate,
+}
+
+// ValidateKubernetes checks that method and path form a well-formed Kubernetes API
+// request. The path may carry a query string. HEAD is accepted wherever GET is.
+func ValidateKubernetes(method, path string) error {
+	method = strings.ToUpper(method)
+	segs := splitPath(path)
+	if len(segs) == 0 {
+		return fmt.Errorf("unknown Kubernetes API path %q", path)
+	}
+
+	switch segs[0] {
+	case "api":
+		if len(segs) >= 2 && segs[1] != "v1" {
+			return fmt.Errorf("unknown core API version %q in Kubernetes API path %q (only v1 exists)", segs[1], path)
+		}
+		if len(segs) <= 2 {
+			return checkMethod(method, path, methodsRead)
+		}
+		return validateResource(method, path, "core API v1", coreResources, segs[2:])
+
+	case "apis":
+		if len(segs) == 1 {
+			return checkMethod(method, path, methodsRead)
+		}
+		group := segs[1]
+		resources, known := groupResources[group]
+		if !known && !strings.Contains(group, ".") {
+			// Only built-in groups have no dot; custom resource groups must be domains.
+			return unknownError("API group", group, path, slices.Sorted(maps.Keys(groupResources)))
+		}
+		if len(segs) >= 3 && !kubernetesVersion.MatchString(segs[2]) {
+			return fmt.Errorf("invalid API version %q in Kubernetes API path %q", segs[2], path)
+		}
+		if len(segs) <= 3 {
+			return checkMethod(method, path, methodsRead)
+		}
+		return validateResource(method, path, group+"/"+segs[2], resources, segs[3:])
+
+	default:
+		if slices.Contains(kubernetesRoots, segs[0]) {
+			return checkMethod(method, path, methodsRead)
+		}
+		return unknownError("top-level path", segs[0], path, append([]string{"api", "apis"}, kubernetesRoots...))
+	}
+}
+
+// validateResource checks the resource part of a path, that is what follows the
+// group version: [watch/][namespaces/{ns}/]{resource}[/{name}[/{subresource}]].
+// resources is nil for groups whose resources are not known.
+func validateResource(method, path, api string, resources []string, rest []string) error {
+	watch := false
+	if rest[0] == "watch" {
+		watch = true
+		rest = rest[1:]
+		if len(rest) == 0 {
+			return fmt.Errorf("missing resource after watch in Kubernetes API path %q", path)
+		}
+	}
+
+	if rest[0] == "namespaces" && len(rest) >= 3 {
+		if _, ok := subresources[rest[2]]; !ok {
+			rest = rest[2:]
+		}
+	}
+
+	resource := rest[0]
+	if resources != nil && !slices.Contains(resources, resource) {
+		return unknownError("resource", resource, path, resources, "in "+api)
+	}
+
+	var allowed []string
+	switch {
+	case len(rest) == 1 && createOnlyResources[resource]:
+		allowed = methodsCreate
+	case len(rest) == 1:
+		allowed = methodsCollection
+	case len(rest) == 2:
+		allowed = methodsItem
+	default:
+		sub := rest[2]
+		methods, ok := subresources[sub]
+		switch {
+		case !ok && resources != nil:
+			return unknownError("subresource", sub, path, slices.Sorted(maps.Keys(subresources)), "of "+resource)
+		case !ok:
+			allowed = methodsAny
+		case len(rest) > 3 && sub != "proxy":
+			return fmt.Errorf("unexpected segments after subresource %q in Kubernetes API path %q", sub, path)
+		default:
+			allowed = methods
+		}
+	}
+
+	if watch {
+		if len(rest) > 2 {
+			return fmt.Errorf("subresources cannot be watched in Kubernetes API path %q", path)
+		}
+		allowed = methodsRead
+	}
+	return checkMethod(method, path, allowed)
+}
+
+// checkMethod returns an error when method is not one of allowed. HEAD is allowed
+// wherever GET is.
+func checkMethod(method, path string, allowed []string) error {
+	if slices.Contains(allowed, method) || (method == "HEAD" && slices.Contains(allowed, "GET")) {
+		return nil
+	}
+	return methodError("Kubernetes", method, path, allowed)
+}
+
+// unknownError reports an unknown path element with the closest known one, if any.
+func unknownError(kind, value, path string, known []string, where ...string) error {
+	msg := fmt.Sprintf("unknown %s %q", kind, value)
+	if len(where) > 0 {
+		msg += " " + strings.Join(where, " ")
+	}
+	msg += fmt.Sprintf(" in Kubernetes API path %q", path)
+	if s := closest(value, known); s != "" {
+		msg += fmt.Sprintf(", did you mean %q?", s)
+	}
+	return fmt.Errorf("%s", msg)
+}
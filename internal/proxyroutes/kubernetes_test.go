@@ -0,0 +1,160 @@
+package proxyroutes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateKubernetes verifies structural validation of Kubernetes paths and methods.
+func TestValidateKubernetes(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		expectedErr string
+	}{
+		{name: "core discovery", method: "GET", path: "/api"},
+		{name: "core version discovery", method: "GET", path: "/api/v1"},
+		{name: "list pods", method: "GET", path: "/api/v1/pods"},
+		{name: "create pod", method: "POST", path: "/api/v1/pods?dryRun=All"},
+		{name: "namespaced list", method: "GET", path: "/api/v1/namespaces/default/pods"},
+		{name: "namespaced create", method: "POST", path: "/api/v1/namespaces/test/services"},
+		{name: "namespaced item", method: "PATCH", path: "/api/v1/namespaces/default/pods/web"},
+		{name: "namespace item", method: "GET", path: "/api/v1/namespaces/default"},
+		{name: "namespace finalize", method: "PUT", path: "/api/v1/namespaces/default/finalize"},
+		{name: "pod log", method: "GET", path: "/api/v1/namespaces/default/pods/web/log"},
+		{name: "pod exec", method: "POST", path: "/api/v1/namespaces/default/pods/web/exec"},
+		{name: "service proxy", method: "GET", path: "/api/v1/namespaces/default/services/web/proxy/metrics"},
+		{name: "watch", method: "GET", path: "/api/v1/watch/namespaces/default/pods"},
+		{name: "head", method: "HEAD", path: "/api/v1/pods"},
+		{name: "groups discovery", method: "GET", path: "/apis"},
+		{name: "group discovery", method: "GET", path: "/apis/apps"},
+		{name: "group version discovery", method: "GET", path: "/apis/apps/v1"},
+		{name: "deployments", method: "GET", path: "/apis/apps/v1/namespaces/default/deployments"},
+		{name: "deployment scale", method: "PATCH", path: "/apis/apps/v1/namespaces/default/deployments/web/scale"},
+		{name: "beta version", method: "GET", path: "/apis/metrics.k8s.io/v1beta1/nodes"},
+		{name: "custom resource", method: "GET", path: "/apis/cert-manager.io/v1/namespaces/default/certificates"},
+		{name: "custom subresource", method: "PUT", path: "/apis/example.com/v1/widgets/a/custom"},
+		{name: "review", method: "POST", path: "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews"},
+		{name: "version", method: "GET", path: "/version"},
+		{name: "healthz", method: "GET", path: "/healthz"},
+		{name: "readyz check", method: "GET", path: "/readyz/etcd"},
+		{name: "openapi", method: "GET", path: "/openapi/v3/apis/apps/v1"},
+		{
+			name:        "resource typo",
+			method:      "GET",
+			path:        "/api/v1/namespaces/default/pod",
+			expectedErr: `unknown resource "pod" in core API v1 in Kubernetes API path "/api/v1/namespaces/default/pod", did you mean "pods"?`,
+		},
+		{
+			name:        "group resource typo",
+			method:      "GET",
+			path:        "/apis/apps/v1/deployment",
+			expectedErr: `unknown resource "deployment" in apps/v1`,
+		},
+		{
+			name:        "core resource in group",
+			method:      "GET",
+			path:        "/apis/apps/v1/namespaces/default/pods",
+			expectedErr: `unknown resource "pods" in apps/v1`,
+		},
+		{
+			name:        "unknown group",
+			method:      "GET",
+			path:        "/apis/app/v1/deployments",
+			expectedErr: `unknown API group "app" in Kubernetes API path "/apis/app/v1/deployments", did you mean "apps"?`,
+		},
+		{
+			name:        "core version",
+			method:      "GET",
+			path:        "/api/v2/pods",
+			expectedErr: `unknown core API version "v2"`,
+		},
+		{
+			name:        "invalid version",
+			method:      "GET",
+			path:        "/apis/apps/deployments",
+			expectedErr: `invalid API version "deployments"`,
+		},
+		{
+			name:        "unknown top-level path",
+			method:      "GET",
+			path:        "/helthz",
+			expectedErr: `unknown top-level path "helthz" in Kubernetes API path "/helthz", did you mean "healthz"?`,
+		},
+		{
+			name:        "unknown subresource",
+			method:      "GET",
+			path:        "/api/v1/namespaces/default/pods/web/logs",
+			expectedErr: `unknown subresource "logs" of pods`,
+		},
+		{
+			name:        "segments after subresource",
+			method:      "GET",
+			path:        "/api/v1/namespaces/default/pods/web/log/extra",
+			expectedErr: `unexpected segments after subresource "log"`,
+		},
+		{
+			name:        "patch collection",
+			method:      "PATCH",
+			path:        "/api/v1/pods",
+			expectedErr: `method PATCH is not allowed for Kubernetes API path "/api/v1/pods" (allowed: DELETE, GET, POST)`,
+		},
+		{
+			name:        "post item",
+			method:      "POST",
+			path:        "/api/v1/namespaces/default/pods/web",
+			expectedErr: "(allowed: DELETE, GET, PATCH, PUT)",
+		},
+		{
+			name:        "post log",
+			method:      "POST",
+			path:        "/api/v1/namespaces/default/pods/web/log",
+			expectedErr: "(allowed: GET)",
+		},
+		{
+			name:        "list review",
+			method:      "GET",
+			path:        "/apis/authentication.k8s.io/v1/tokenreviews",
+			expectedErr: "(allowed: POST)",
+		},
+		{
+			name:        "write to discovery",
+			method:      "POST",
+			path:        "/version",
+			expectedErr: "(allowed: GET)",
+		},
+		{
+			name:        "delete watch",
+			method:      "DELETE",
+			path:        "/api/v1/watch/pods",
+			expectedErr: "(allowed: GET)",
+		},
+		{
+			name:        "empty watch",
+			method:      "GET",
+			path:        "/api/v1/watch",
+			expectedErr: "missing resource after watch",
+		},
+		{
+			name:        "root",
+			method:      "GET",
+			path:        "/",
+			expectedErr: `unknown Kubernetes API path "/"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKubernetes(tt.method, tt.path)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
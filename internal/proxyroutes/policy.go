@@ -0,0 +1,218 @@
+package proxyroutes
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rules restricts the requests of one proxy. Each rule is written "[METHODS] PATTERN",
+// where METHODS is a comma-separated list of HTTP methods or "*" (the default), and
+// PATTERN is a path whose segments are glob patterns; "**" matches any number of
+// segments. Examples: "POST /containers/*/exec", "/secrets/**", "GET,HEAD /images/**".
+type Rules struct {
+	// Allow lists the permitted requests. When empty, every request not denied is permitted.
+	Allow []string `yaml:"allow" json:"allow"`
+	// Deny lists the rejected requests. Deny rules take precedence over allow rules.
+	Deny []string `yaml:"deny" json:"deny"`
+}
+
+// PolicyConfig is the content of a proxy rules file.
+type PolicyConfig struct {
+	Docker     Rules `yaml:"docker" json:"docker"`
+	Kubernetes Rules `yaml:"kubernetes" json:"kubernetes"`
+}
+
+// Policy enforces allow and deny rules on proxied requests. It is safe for concurrent use.
+type Policy struct {
+	docker     ruleSet
+	kubernetes ruleSet
+}
+
+type ruleSet struct {
+	allow []accessRule
+	deny  []accessRule
+}
+
+// accessRule is a compiled allow or deny rule.
+type accessRule struct {
+	text     string
+	methods  []string
+	segments []string
+}
+
+// LoadPolicy reads a proxy rules file (YAML or JSON) and compiles its rules.
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proxy rules: %w", err)
+	}
+
+	var cfg PolicyConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse proxy rules %s: %w", file, err)
+	}
+
+	return NewPolicy(cfg)
+}
+
+// NewPolicy compiles the rules of cfg into a Policy.
+func NewPolicy(cfg PolicyConfig) (*Policy, error) {
+	docker, err := compileRuleSet("docker", cfg.Docker)
+	if err != nil {
+		return nil, err
+	}
+	kubernetes, err := compileRuleSet("kubernetes", cfg.Kubernetes)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{docker: docker, kubernetes: kubernetes}, nil
+}
+
+// Len returns the number of rules in the policy.
+func (p *Policy) Len() int {
+	return len(p.docker.allow) + len(p.docker.deny) + len(p.kubernetes.allow) + len(p.kubernetes.deny)
+}
+
+// CheckDocker returns an error when the rules do not permit a Docker API request.
+// An API version prefix (/v1.41/...) is ignored.
+func (p *Policy) CheckDocker(method, apiPath string) error {
+	return p.docker.check("Docker", method, apiPath, true)
+}
+
+// CheckKubernetes returns an error when the rules do not permit a Kubernetes API request.
+func (p *Policy) CheckKubernetes(method, apiPath string) error {
+	return p.kubernetes.check("Kubernetes", method, apiPath, false)
+}
+
+func compileRuleSet(api string, rules Rules) (ruleSet, error) {
+	var set ruleSet
+	for _, text := range rules.Allow {
+		r, err := compileRule(text)
+		if err != nil {
+			return ruleSet{}, fmt.Errorf("invalid %s allow rule: %w", api, err)
+		}
+		set.allow = append(set.allow, r)
+	}
+	for _, text := range rules.Deny {
+		r, err := compileRule(text)
+		if err != nil {
+			return ruleSet{}, fmt.Errorf("invalid %s deny rule: %w", api, err)
+		}
+		set.deny = append(set.deny, r)
+	}
+	return set, nil
+}
+
+func compileRule(text string) (accessRule, error) {
+	fields := strings.Fields(text)
+	r := accessRule{text: strings.Join(fields, " ")}
+
+	var pattern string
+	switch len(fields) {
+	case 1:
+		pattern = fields[0]
+	case 2:
+		pattern = fields[1]
+		if fields[0] != "*" {
+			for _, m := range strings.Split(strings.ToUpper(fields[0]), ",") {
+				if m == "" {
+					return accessRule{}, fmt.Errorf("%q: empty method", text)
+				}
+				r.methods = append(r.methods, m)
+			}
+		}
+	default:
+		return accessRule{}, fmt.Errorf("%q: expected \"[METHODS] PATTERN\"", text)
+	}
+
+	if !strings.HasPrefix(pattern, "/") {
+		return accessRule{}, fmt.Errorf("%q: pattern must start with a leading slash", text)
+	}
+	r.segments = splitPath(pattern)
+	for _, seg := range r.segments {
+		if _, err := path.Match(seg, ""); err != nil {
+			return accessRule{}, fmt.Errorf("%q: invalid pattern: %w", text, err)
+		}
+	}
+	return r, nil
+}
+
+// check applies the rule set to a request. The path is unescaped and cleaned first so
+// that encoded characters, duplicate slashes or dot segments cannot bypass a rule.
+func (s ruleSet) check(api, method, apiPath string, stripVersion bool) error {
+	if len(s.allow) == 0 && len(s.deny) == 0 {
+		return nil
+	}
+
+	method = strings.ToUpper(method)
+	segs, err := normalizePath(apiPath)
+	if err != nil {
+		return fmt.Errorf("%s API request %s %s is denied: %w", api, method, apiPath, err)
+	}
+	if stripVersion && len(segs) > 0 && dockerVersionPrefix.MatchString(segs[0]) {
+		segs = segs[1:]
+	}
+
+	for _, r := range s.deny {
+		if r.matches(method, segs) {
+			return fmt.Errorf("%s API request %s %s is denied by proxy rule %q", api, method, apiPath, r.text)
+		}
+	}
+	if len(s.allow) == 0 {
+		return nil
+	}
+	for _, r := range s.allow {
+		if r.matches(method, segs) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s API request %s %s is not permitted by the proxy allow rules", api, method, apiPath)
+}
+
+func (r accessRule) matches(method string, segs []string) bool {
+	if len(r.methods) > 0 && !slices.Contains(r.methods, method) {
+		return false
+	}
+	return matchGlobSegments(r.segments, segs)
+}
+
+// matchGlobSegments matches path segments against glob segments, where "**" matches
+// zero or more segments.
+func matchGlobSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for n := 0; n <= len(segs); n++ {
+			if matchGlobSegments(pattern[1:], segs[n:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], segs[1:])
+}
+
+// normalizePath returns the segments of an unescaped, cleaned API path.
+func normalizePath(apiPath string) ([]string, error) {
+	if i := strings.IndexByte(apiPath, '?'); i >= 0 {
+		apiPath = apiPath[:i]
+	}
+	unescaped, err := url.PathUnescape(apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path escaping: %w", err)
+	}
+	return splitPath(path.Clean("/" + unescaped)), nil
+}
@@ -0,0 +1,119 @@
+package proxyroutes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolicyDocker verifies allow and deny rules on Docker requests.
+func TestPolicyDocker(t *testing.T) {
+	policy, err := NewPolicy(PolicyConfig{
+		Docker: Rules{
+			Deny: []string{"POST /containers/*/exec", "/exec/**", "* /secrets/**"},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		expectedErr string
+	}{
+		{name: "permitted", method: "GET", path: "/containers/json"},
+		{name: "other method on denied path", method: "GET", path: "/containers/abc/json"},
+		{name: "deny exec create", method: "POST", path: "/containers/abc/exec", expectedErr: `Docker API request POST /containers/abc/exec is denied by proxy rule "POST /containers/*/exec"`},
+		{name: "deny exec start", method: "post", path: "/exec/123/start", expectedErr: `denied by proxy rule "/exec/**"`},
+		{name: "deny collection", method: "GET", path: "/secrets", expectedErr: `denied by proxy rule "* /secrets/**"`},
+		{name: "deny item", method: "DELETE", path: "/secrets/abc", expectedErr: "denied by proxy rule"},
+		{name: "version prefix", method: "GET", path: "/v1.41/secrets", expectedErr: "denied by proxy rule"},
+		{name: "query string", method: "GET", path: "/secrets?filters=x", expectedErr: "denied by proxy rule"},
+		{name: "dot segments", method: "GET", path: "/containers/../secrets", expectedErr: "denied by proxy rule"},
+		{name: "duplicate slashes", method: "GET", path: "//secrets", expectedErr: "denied by proxy rule"},
+		{name: "escaped path", method: "GET", path: "/%73ecrets", expectedErr: "denied by proxy rule"},
+		{name: "invalid escaping", method: "GET", path: "/secrets%zz", expectedErr: "invalid path escaping"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.CheckDocker(tt.method, tt.path)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
+
+// TestPolicyKubernetesAllow verifies that allow rules restrict requests to the listed ones.
+func TestPolicyKubernetesAllow(t *testing.T) {
+	policy, err := NewPolicy(PolicyConfig{
+		Kubernetes: Rules{
+			Allow: []string{"GET /api/**", "GET,PATCH /apis/apps/v1/namespaces/*/deployments/**"},
+			Deny:  []string{"/api/v1/namespaces/*/secrets/**"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, policy.CheckKubernetes("GET", "/api/v1/pods"))
+	assert.NoError(t, policy.CheckKubernetes("PATCH", "/apis/apps/v1/namespaces/default/deployments/web"))
+	assert.ErrorContains(t, policy.CheckKubernetes("DELETE", "/api/v1/pods"), "Kubernetes API request DELETE /api/v1/pods is not permitted by the proxy allow rules")
+	assert.ErrorContains(t, policy.CheckKubernetes("GET", "/apis/batch/v1/jobs"), "not permitted")
+	assert.ErrorContains(t, policy.CheckKubernetes("GET", "/api/v1/namespaces/default/secrets"), "denied by proxy rule")
+	// The Docker rules are empty, so Docker requests are unrestricted.
+	assert.NoError(t, policy.CheckDocker("DELETE", "/containers/abc"))
+}
+
+// TestNewPolicyErrors verifies that malformed rules are rejected.
+func TestNewPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         PolicyConfig
+		expectedErr string
+	}{
+		{name: "too many fields", cfg: PolicyConfig{Docker: Rules{Deny: []string{"POST /exec extra"}}}, expectedErr: "invalid docker deny rule"},
+		{name: "relative pattern", cfg: PolicyConfig{Kubernetes: Rules{Allow: []string{"GET api/**"}}}, expectedErr: "pattern must start with a leading slash"},
+		{name: "bad glob", cfg: PolicyConfig{Docker: Rules{Allow: []string{"/containers/[abc"}}}, expectedErr: "invalid pattern"},
+		{name: "empty method", cfg: PolicyConfig{Docker: Rules{Deny: []string{"GET, /exec/**"}}}, expectedErr: "invalid docker deny rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPolicy(tt.cfg)
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+// TestLoadPolicy verifies loading rules from a YAML file.
+func TestLoadPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "proxy-rules.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+docker:
+  deny:
+    - POST /containers/*/exec
+kubernetes:
+  allow:
+    - GET /**
+`), 0o600))
+
+	policy, err := LoadPolicy(file)
+	require.NoError(t, err)
+	assert.Equal(t, 2, policy.Len())
+	assert.Error(t, policy.CheckDocker("POST", "/containers/abc/exec"))
+	assert.Error(t, policy.CheckKubernetes("DELETE", "/api/v1/pods"))
+
+	_, err = LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read proxy rules")
+
+	require.NoError(t, os.WriteFile(file, []byte("docker: ["), 0o600))
+	_, err = LoadPolicy(file)
+	assert.ErrorContains(t, err, "failed to parse proxy rules")
+}
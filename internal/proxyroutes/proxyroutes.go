@@ -0,0 +1,74 @@
+// Package proxyroutes validates the paths and methods of raw Docker and Kubernetes
+// API requests before they are proxied through Portainer. Typos in a path otherwise
+// surface as an opaque upstream 404; this package rejects them early with a precise
+// error and, when possible, suggests the path that was most likely intended.
+//
+// Docker requests are checked against an embedded route table derived from the
+// Docker Engine API specification. Kubernetes requests are checked structurally
+// (API group, version, resource and subresource), with resource names validated for
+// the built-in API groups; custom resources in other groups are accepted.
+//
+// A [Policy] additionally restricts which proxied requests are permitted, using
+// operator-defined allow and deny rules on methods and path patterns.
+package proxyroutes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxSuggestDistance is the largest edit distance for which a suggestion is offered.
+const maxSuggestDistance = 3
+
+// splitPath returns the segments of an API path, ignoring any query string and
+// leading or trailing slashes.
+func splitPath(p string) []string {
+	if i := strings.IndexByte(p, '?'); i >= 0 {
+		p = p[:i]
+	}
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// methodError reports a method that is not allowed for an existing path.
+func methodError(api, method, path string, allowed []string) error {
+	allowed = slices.Sorted(slices.Values(allowed))
+	return fmt.Errorf("method %s is not allowed for %s API path %q (allowed: %s)", method, api, path, strings.Join(allowed, ", "))
+}
+
+// closest returns the candidate closest to s, or an empty string when none is within
+// maxSuggestDistance.
+func closest(s string, candidates []string) string {
+	best, bestDist := "", maxSuggestDistance+1
+	for _, c := range candidates {
+		if d := levenshtein(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
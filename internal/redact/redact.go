@@ -0,0 +1,384 @@
+// Package redact masks sensitive data in tool results before they are returned to the
+// MCP client. Redaction is driven by operator-defined rules loaded from a YAML or JSON
+// file, so organization-specific fields can be protected without code changes.
+//
+// A rule selects the tools it applies to and what to mask:
+//   - paths: JSON paths (e.g. "$.Env[*]", "$..Password") whose values are replaced
+//   - pattern: a regular expression whose matches inside string values are replaced
+//
+// When both are set, the pattern is only applied below the selected paths. Results
+// that are not JSON are only processed by rules without paths.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultReplacement is the text that replaces redacted values when a rule does not set one.
+const DefaultReplacement = "[REDACTED]"
+
+// Rule is one redaction rule as written in the rules file.
+type Rule struct {
+	// Name identifies the rule in error messages.
+	Name string `yaml:"name" json:"name"`
+	// Tools lists the tools the rule applies to, as glob patterns. Granular tools are
+	// matched by name (e.g. "getStackFile"); meta-tool actions by "<tool>.<action>"
+	// (e.g. "manage_stacks.get_stack_file") or by the meta-tool name alone. Empty means all tools.
+	Tools []string `yaml:"tools" json:"tools"`
+	// Paths are JSON paths of the values to redact. Supported syntax: $, .key, ['key'],
+	// [n], wildcards (.* and [*]) and recursive descent (..key). Keys match case-insensitively.
+	Paths []string `yaml:"paths" json:"paths"`
+	// Pattern is a regular expression replaced inside string values. The replacement may
+	// reference capture groups ($1).
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Replacement replaces redacted values or pattern matches (DefaultReplacement when empty).
+	Replacement string `yaml:"replacement" json:"replacement"`
+}
+
+// Config is the content of a redaction rules file.
+type Config struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+// Engine applies a set of compiled redaction rules. It is safe for concurrent use.
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	name        string
+	tools       []string
+	paths       [][]segment
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// segment is one step of a parsed JSON path.
+type segment struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// Load reads a rules file (YAML or JSON) and compiles its rules.
+func Load(file string) (*Engine, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse redaction rules %s: %w", file, err)
+	}
+
+	return New(cfg.Rules)
+}
+
+// New compiles the given rules into an Engine.
+func New(rules []Rule) (*Engine, error) {
+	e := &Engine{}
+	for i, r := range rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		if len(r.Paths) == 0 && r.Pattern == "" {
+			return nil, fmt.Errorf("redaction rule %s: at least one of paths or pattern is required", name)
+		}
+
+		for _, t := range r.Tools {
+			if _, err := path.Match(t, ""); err != nil {
+				return nil, fmt.Errorf("redaction rule %s: invalid tool pattern %q: %w", name, t, err)
+			}
+		}
+
+		c := compiledRule{name: name, tools: r.Tools, replacement: r.Replacement}
+		if c.replacement == "" {
+			c.replacement = DefaultReplacement
+		}
+
+		for _, p := range r.Paths {
+			segs, err := parsePath(p)
+			if err != nil {
+				return nil, fmt.Errorf("redaction rule %s: %w", name, err)
+			}
+			c.paths = append(c.paths, segs)
+		}
+
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("redaction rule %s: invalid pattern: %w", name, err)
+			}
+			c.pattern = re
+		}
+
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// Len returns the number of rules in the engine.
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// Apply redacts text returned by a tool. action is the meta-tool action, or empty for
+// granular tools. The text is returned unchanged when no rule matches.
+func (e *Engine) Apply(tool, action, text string) string {
+	var rules []compiledRule
+	for _, r := range e.rules {
+		if r.appliesTo(tool, action) {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == 0 {
+		return text
+	}
+
+	doc, ok := decodeJSON(text)
+	if !ok {
+		for _, r := range rules {
+			if len(r.paths) == 0 {
+				text = r.pattern.ReplaceAllString(text, r.replacement)
+			}
+		}
+		return text
+	}
+
+	changed := false
+	for _, r := range rules {
+		var c bool
+		doc, c = r.apply(doc)
+		changed = changed || c
+	}
+	if !changed {
+		return text
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return text
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// appliesTo reports whether the rule selects the given tool call.
+func (r compiledRule) appliesTo(tool, action string) bool {
+	if len(r.tools) == 0 {
+		return true
+	}
+
+	names := []string{tool}
+	if action != "" {
+		names = append(names, tool+"."+action)
+	}
+	for _, pattern := range r.tools {
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apply redacts a decoded JSON document and reports whether it changed.
+func (r compiledRule) apply(doc any) (any, bool) {
+	if len(r.paths) == 0 {
+		return r.redactStrings(doc)
+	}
+
+	changed := false
+	replace := func(v any) (any, bool) {
+		if r.pattern != nil {
+			return r.redactStrings(v)
+		}
+		return r.replacement, true
+	}
+	for _, segs := range r.paths {
+		var c bool
+		doc, c = walk(doc, segs, replace)
+		changed = changed || c
+	}
+	return doc, changed
+}
+
+// redactStrings replaces pattern matches in every string below v.
+func (r compiledRule) redactStrings(v any) (any, bool) {
+	switch val := v.(type) {
+	case string:
+		out := r.pattern.ReplaceAllString(val, r.replacement)
+		return out, out != val
+	case map[string]any:
+		changed := false
+		for k, child := range val {
+			if out, c := r.redactStrings(child); c {
+				val[k] = out
+				changed = true
+			}
+		}
+		return val, changed
+	case []any:
+		changed := false
+		for i, child := range val {
+			if out, c := r.redactStrings(child); c {
+				val[i] = out
+				changed = true
+			}
+		}
+		return val, changed
+	default:
+		return v, false
+	}
+}
+
+// walk applies replace to every value selected by segs below v.
+func walk(v any, segs []segment, replace func(any) (any, bool)) (any, bool) {
+	if len(segs) == 0 {
+		return replace(v)
+	}
+
+	seg, rest := segs[0], segs[1:]
+	changed := false
+
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if seg.matchesKey(k) {
+				if out, c := walk(child, rest, replace); c {
+					val[k] = out
+					child = out
+					changed = true
+				}
+			}
+			if seg.recursive {
+				if out, c := walk(child, segs, replace); c {
+					val[k] = out
+					changed = true
+				}
+			}
+		}
+	case []any:
+		for i, child := range val {
+			if seg.matchesIndex(i) {
+				if out, c := walk(child, rest, replace); c {
+					val[i] = out
+					child = out
+					changed = true
+				}
+			}
+			if seg.recursive {
+				if out, c := walk(child, segs, replace); c {
+					val[i] = out
+					changed = true
+				}
+			}
+		}
+	}
+	return v, changed
+}
+
+func (s segment) matchesKey(k string) bool {
+	return !s.isIndex && (s.wildcard || strings.EqualFold(s.key, k))
+}
+
+func (s segment) matchesIndex(i int) bool {
+	return s.wildcard || (s.isIndex && s.index == i)
+}
+
+// parsePath parses a JSON path into segments.
+func parsePath(p string) ([]segment, error) {
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("invalid path %q: must start with $", p)
+	}
+
+	var segs []segment
+	rest := p[1:]
+	for rest != "" {
+		var seg segment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			seg.recursive = true
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "["):
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", p, rest)
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", p)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				seg.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				seg.key = inner[1 : len(inner)-1]
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid path %q: bad index [%s]", p, inner)
+				}
+				seg.index, seg.isIndex = n, true
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", p)
+			}
+			if name == "*" {
+				seg.wildcard = true
+			} else {
+				seg.key = name
+			}
+		}
+		segs = append(segs, seg)
+	}
+
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("invalid path %q: selects the whole document", p)
+	}
+	return segs, nil
+}
+
+// decodeJSON decodes text as a JSON object or array, keeping numbers intact.
+func decodeJSON(text string) (any, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return nil, false
+	}
+	return doc, true
+}
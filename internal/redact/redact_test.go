@@ -0,0 +1,172 @@
+package redact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApply verifies path and pattern rules against JSON and plain-text results.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  []Rule
+		tool   string
+		action string
+		input  string
+		want   string
+	}{
+		{
+			name:  "path replaces value",
+			rules: []Rule{{Paths: []string{"$.settings.password"}}},
+			tool:  "getSettings",
+			input: `{"settings":{"password":"hunter2","user":"admin"}}`,
+			want:  `{"settings":{"password":"[REDACTED]","user":"admin"}}`,
+		},
+		{
+			name:  "keys match case-insensitively",
+			rules: []Rule{{Paths: []string{"$.Password"}}},
+			tool:  "getSettings",
+			input: `{"password":"hunter2"}`,
+			want:  `{"password":"[REDACTED]"}`,
+		},
+		{
+			name:  "recursive descent and wildcard",
+			rules: []Rule{{Paths: []string{"$..secret", "$.items[*].token"}, Replacement: "***"}},
+			tool:  "listThings",
+			input: `{"a":{"b":{"secret":1}},"items":[{"token":"x","id":1},{"token":"y","id":2}]}`,
+			want:  `{"a":{"b":{"secret":"***"}},"items":[{"id":1,"token":"***"},{"id":2,"token":"***"}]}`,
+		},
+		{
+			name:  "index and quoted key",
+			rules: []Rule{{Paths: []string{"$[1]['api key']"}}},
+			tool:  "listThings",
+			input: `[{"api key":"a"},{"api key":"b"}]`,
+			want:  `[{"api key":"a"},{"api key":"[REDACTED]"}]`,
+		},
+		{
+			name:  "pattern below path",
+			rules: []Rule{{Paths: []string{"$.Env[*]"}, Pattern: `^(\w*(?:PASSWORD|SECRET)\w*)=.*$`, Replacement: "$1=[REDACTED]"}},
+			tool:  "getContainer",
+			input: `{"Env":["DB_PASSWORD=x","MODE=prod"],"Name":"DB_PASSWORD=y"}`,
+			want:  `{"Env":["DB_PASSWORD=[REDACTED]","MODE=prod"],"Name":"DB_PASSWORD=y"}`,
+		},
+		{
+			name:  "pattern on every JSON string",
+			rules: []Rule{{Pattern: `acme-[0-9]+`}},
+			tool:  "listThings",
+			input: `{"a":"id acme-42","b":["acme-7"],"n":12.50}`,
+			want:  `{"a":"id [REDACTED]","b":["[REDACTED]"],"n":12.50}`,
+		},
+		{
+			name:  "pattern on plain text",
+			rules: []Rule{{Pattern: `token=\S+`, Replacement: "token=***"}},
+			tool:  "getContainerLogs",
+			input: "login token=abc ok",
+			want:  "login token=*** ok",
+		},
+		{
+			name:  "path rules skip plain text",
+			rules: []Rule{{Paths: []string{"$.password"}}},
+			tool:  "getContainerLogs",
+			input: "password: x",
+			want:  "password: x",
+		},
+		{
+			name:  "unchanged JSON is returned verbatim",
+			rules: []Rule{{Paths: []string{"$.missing"}}},
+			tool:  "listThings",
+			input: `{"b":1, "a":2}`,
+			want:  `{"b":1, "a":2}`,
+		},
+		{
+			name:  "rule for other tool is ignored",
+			rules: []Rule{{Tools: []string{"getSettings"}, Paths: []string{"$.password"}}},
+			tool:  "listUsers",
+			input: `{"password":"x"}`,
+			want:  `{"password":"x"}`,
+		},
+		{
+			name:   "meta-tool action glob",
+			rules:  []Rule{{Tools: []string{"manage_settings.get_*"}, Paths: []string{"$.password"}}},
+			tool:   "manage_settings",
+			action: "get_settings",
+			input:  `{"password":"x"}`,
+			want:   `{"password":"[REDACTED]"}`,
+		},
+		{
+			name:   "meta-tool name selects all actions",
+			rules:  []Rule{{Tools: []string{"manage_settings"}, Paths: []string{"$.password"}}},
+			tool:   "manage_settings",
+			action: "update_settings",
+			input:  `{"password":"x"}`,
+			want:   `{"password":"[REDACTED]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.rules)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, e.Apply(tt.tool, tt.action, tt.input))
+		})
+	}
+}
+
+// TestNewInvalidRules verifies rule validation errors.
+func TestNewInvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr string
+	}{
+		{name: "empty rule", rule: Rule{Name: "empty"}, wantErr: "empty: at least one of paths or pattern"},
+		{name: "bad pattern", rule: Rule{Pattern: "("}, wantErr: "invalid pattern"},
+		{name: "path without root", rule: Rule{Paths: []string{"password"}}, wantErr: "must start with $"},
+		{name: "whole document", rule: Rule{Paths: []string{"$"}}, wantErr: "selects the whole document"},
+		{name: "bad index", rule: Rule{Paths: []string{"$.a[x]"}}, wantErr: "bad index"},
+		{name: "unclosed bracket", rule: Rule{Paths: []string{"$.a[1"}}, wantErr: "missing ]"},
+		{name: "bad tool glob", rule: Rule{Tools: []string{"["}, Pattern: "x"}, wantErr: "invalid tool pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([]Rule{tt.rule})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestLoad verifies loading rules from YAML and JSON files.
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlFile := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(yamlFile, []byte(`rules:
+  - name: ldap
+    tools: ["getSettings"]
+    paths: ["$..Password"]
+`), 0o600))
+	e, err := Load(yamlFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.Len())
+	assert.Equal(t, `{"LDAP":{"Password":"[REDACTED]"}}`, e.Apply("getSettings", "", `{"LDAP":{"Password":"x"}}`))
+
+	jsonFile := filepath.Join(dir, "rules.json")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"rules":[{"pattern":"s3cr3t"}]}`), 0o600))
+	e, err = Load(jsonFile)
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED]", e.Apply("any", "", "s3cr3t"))
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read redaction rules")
+
+	badFile := filepath.Join(dir, "bad.yaml")
+	require.NoError(t, os.WriteFile(badFile, []byte("rules: ["), 0o600))
+	_, err = Load(badFile)
+	assert.ErrorContains(t, err, "failed to parse redaction rules")
+}
@@ -0,0 +1,70 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseErrors verifies that invalid schedules are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expectError string
+	}{
+		{spec: "* * * *", expectError: "expected 5 fields"},
+		{spec: "60 * * * *", expectError: "out of range 0-59 in minute field"},
+		{spec: "* 5-2 * * *", expectError: "invalid range"},
+		{spec: "*/0 * * * *", expectError: "invalid step"},
+		{spec: "* * 0 * *", expectError: "day of month"},
+		{spec: "* * * jan *", expectError: "invalid value \"jan\""},
+		{spec: "@every 30s", expectError: "at least 1m"},
+		{spec: "@every soon", expectError: "invalid schedule"},
+		{spec: "@often", expectError: "expected 5 fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+// TestScheduleNext verifies the next run time of cron expressions and descriptors.
+func TestScheduleNext(t *testing.T) {
+	// Wednesday.
+	from := time.Date(2025, 6, 4, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{spec: "* * * * *", expected: time.Date(2025, 6, 4, 10, 18, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", expected: time.Date(2025, 6, 4, 10, 30, 0, 0, time.UTC)},
+		{spec: "0 * * * *", expected: time.Date(2025, 6, 4, 11, 0, 0, 0, time.UTC)},
+		{spec: "5,40 9-11 * * *", expected: time.Date(2025, 6, 4, 10, 40, 0, 0, time.UTC)},
+		{spec: "30 2 * * *", expected: time.Date(2025, 6, 5, 2, 30, 0, 0, time.UTC)},
+		{spec: "0 8 * * 1-5", expected: time.Date(2025, 6, 5, 8, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", expected: time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", expected: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both days restricted: either of them matches.
+		{spec: "0 0 10 * 5", expected: time.Date(2025, 6, 6, 0, 0, 0, 0, time.UTC)},
+		{spec: "@weekly", expected: time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC)},
+		{spec: "@yearly", expected: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 90m", expected: time.Date(2025, 6, 4, 11, 47, 30, 0, time.UTC)},
+		{spec: "0 0 30 2 *", expected: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(from))
+		})
+	}
+}
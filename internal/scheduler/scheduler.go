@@ -0,0 +1,167 @@
+// Package scheduler runs tasks of the MCP server on cron schedules. The tasks are loaded
+// from a YAML or JSON file written by the operator; each task names a tool, its arguments
+// and a cron schedule, and the server decides what a run does with the result.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTimeout bounds a task run when the task does not set a timeout.
+const DefaultTimeout = 5 * time.Minute
+
+// taskName is the pattern of task names, which appear in resource URIs.
+var taskName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Task is one scheduled task as written in the tasks file.
+type Task struct {
+	// Name identifies the task; it is unique and made of letters, digits, '.', '_' and '-'.
+	Name string `yaml:"name" json:"name"`
+	// Tool is the name of the tool the task calls.
+	Tool string `yaml:"tool" json:"tool"`
+	// Schedule is the cron schedule of the task, as accepted by Parse.
+	Schedule string `yaml:"schedule" json:"schedule"`
+	// Arguments are the arguments of the tool call.
+	Arguments map[string]any `yaml:"arguments" json:"arguments,omitempty"`
+	// Timeout bounds a run of the task (DefaultTimeout when empty).
+	Timeout string `yaml:"timeout" json:"timeout,omitempty"`
+	// RunAtStart runs the task once when the scheduler starts, before its first scheduled time.
+	RunAtStart bool `yaml:"runAtStart" json:"run_at_start,omitempty"`
+
+	schedule Schedule
+	timeout  time.Duration
+}
+
+// Config is the content of a tasks file.
+type Config struct {
+	Tasks []Task `yaml:"tasks" json:"tasks"`
+}
+
+// Load reads a tasks file (YAML or JSON) and validates its tasks.
+func Load(file string) ([]Task, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled tasks: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduled tasks %s: %w", file, err)
+	}
+
+	return New(cfg.Tasks)
+}
+
+// New validates tasks, parsing their schedules and timeouts. The arguments are normalized
+// to the types of JSON values, as the tool handlers receive them from MCP clients.
+func New(tasks []Task) ([]Task, error) {
+	seen := map[string]bool{}
+	for i := range tasks {
+		t := &tasks[i]
+		if !taskName.MatchString(t.Name) {
+			return nil, fmt.Errorf("scheduled task #%d: invalid name %q: use letters, digits, '.', '_' and '-'", i+1, t.Name)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("scheduled task %s: duplicate name", t.Name)
+		}
+		seen[t.Name] = true
+
+		if t.Tool == "" {
+			return nil, fmt.Errorf("scheduled task %s: tool is required", t.Name)
+		}
+
+		schedule, err := Parse(t.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled task %s: %w", t.Name, err)
+		}
+		t.schedule = schedule
+
+		t.timeout = DefaultTimeout
+		if t.Timeout != "" {
+			t.timeout, err = time.ParseDuration(t.Timeout)
+			if err != nil || t.timeout <= 0 {
+				return nil, fmt.Errorf("scheduled task %s: invalid timeout %q", t.Name, t.Timeout)
+			}
+		}
+
+		if t.Arguments, err = normalizeArguments(t.Arguments); err != nil {
+			return nil, fmt.Errorf("scheduled task %s: %w", t.Name, err)
+		}
+	}
+	return tasks, nil
+}
+
+// normalizeArguments converts arguments decoded from YAML, such as integers, to the types
+// of the same arguments decoded from JSON.
+func normalizeArguments(args map[string]any) (map[string]any, error) {
+	if args == nil {
+		return map[string]any{}, nil
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	normalized := map[string]any{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	return normalized, nil
+}
+
+// Next returns the next time the task is due after t, or the zero time when its schedule
+// never matches.
+func (t Task) Next(after time.Time) time.Time {
+	return t.schedule.Next(after)
+}
+
+// Run calls run for every task at its scheduled times until ctx is canceled, with a
+// context bounded by the timeout of the task. Runs of a task never overlap: a run still
+// going when the task is due again delays the next run to the following scheduled time.
+// Run returns once every run in progress has returned.
+func Run(ctx context.Context, tasks []Task, run func(context.Context, Task)) {
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runTask(ctx, t, run)
+		}()
+	}
+	wg.Wait()
+}
+
+// runTask calls run for one task at its scheduled times until ctx is canceled.
+func runTask(ctx context.Context, t Task, run func(context.Context, Task)) {
+	call := func() {
+		runCtx, cancel := context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+		run(runCtx, t)
+	}
+
+	if t.RunAtStart {
+		call()
+	}
+	for {
+		next := t.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		call()
+	}
+}
@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoad verifies loading a tasks file and the normalization of the task arguments.
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tasks.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`tasks:
+  - name: drift
+    tool: detectDrift
+    schedule: "0 * * * *"
+  - name: cleanup
+    tool: suggestCleanup
+    schedule: "@daily"
+    timeout: 10m
+    runAtStart: true
+    arguments:
+      environmentIds: [1, 2]
+      olderThanDays: 7
+`), 0o600))
+
+	tasks, err := Load(file)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	assert.Equal(t, "drift", tasks[0].Name)
+	assert.Equal(t, map[string]any{}, tasks[0].Arguments)
+	assert.Equal(t, DefaultTimeout, tasks[0].timeout)
+
+	assert.True(t, tasks[1].RunAtStart)
+	assert.Equal(t, 10*time.Minute, tasks[1].timeout)
+	assert.Equal(t, map[string]any{"environmentIds": []any{float64(1), float64(2)}, "olderThanDays": float64(7)}, tasks[1].Arguments)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+// TestNewErrors verifies the validation of tasks.
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		tasks       []Task
+		expectError string
+	}{
+		{
+			name:        "invalid name",
+			tasks:       []Task{{Name: "fleet overview", Tool: "listEnvironments", Schedule: "@hourly"}},
+			expectError: "invalid name",
+		},
+		{
+			name:        "duplicate name",
+			tasks:       []Task{{Name: "a", Tool: "listEnvironments", Schedule: "@hourly"}, {Name: "a", Tool: "listStacks", Schedule: "@hourly"}},
+			expectError: "duplicate name",
+		},
+		{
+			name:        "missing tool",
+			tasks:       []Task{{Name: "a", Schedule: "@hourly"}},
+			expectError: "tool is required",
+		},
+		{
+			name:        "invalid schedule",
+			tasks:       []Task{{Name: "a", Tool: "listEnvironments", Schedule: "hourly"}},
+			expectError: "invalid schedule",
+		},
+		{
+			name:        "invalid timeout",
+			tasks:       []Task{{Name: "a", Tool: "listEnvironments", Schedule: "@hourly", Timeout: "-1m"}},
+			expectError: "invalid timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.tasks)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+// TestRun verifies that tasks run at start when asked and stop with the context.
+func TestRun(t *testing.T) {
+	tasks, err := New([]Task{
+		{Name: "start", Tool: "listEnvironments", Schedule: "@yearly", RunAtStart: true, Timeout: "1m"},
+		{Name: "later", Tool: "listStacks", Schedule: "@yearly"},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var started, later atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, tasks, func(runCtx context.Context, task Task) {
+			deadline, ok := runCtx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+			if task.Name == "start" {
+				started.Add(1)
+				cancel()
+			} else {
+				later.Add(1)
+			}
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was canceled")
+	}
+	assert.Equal(t, int32(1), started.Load())
+	assert.Equal(t, int32(0), later.Load())
+}
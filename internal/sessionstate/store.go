@@ -0,0 +1,195 @@
+// Package sessionstate persists the state of the MCP client sessions: the operations
+// consumed from the tool budget and the execution plans waiting for applyPlan. A client
+// that reconnects with the same session ID, or talks to a restarted server, resumes
+// where it left off instead of losing its pending confirmations. The state lives in
+// memory and can optionally be persisted as a JSON file in a directory.
+//
+// The environment restrictions of a session are not part of its state: the scope of the
+// API token is resolved from Portainer at startup, and the client roots are listed again
+// when the client reconnects, so that neither can outlive a change made meanwhile.
+package sessionstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/atomicfile"
+)
+
+// DefaultTTL is how long the state of an idle session is kept.
+const DefaultTTL = 24 * time.Hour
+
+// fileName is the name of the state file in the state directory.
+const fileName = "sessions.json"
+
+// now returns the current time. It is a variable so tests can pin the clock.
+var now = time.Now
+
+// Plan is an execution plan previewed by a session and waiting for applyPlan.
+type Plan struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	ExpiresAt time.Time      `json:"expires_at"`
+}
+
+// Session is the state of one client session.
+type Session struct {
+	Writes      int       `json:"writes,omitempty"`
+	Destructive int       `json:"destructive,omitempty"`
+	Plans       []Plan    `json:"plans,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// empty reports whether the session has no state worth keeping.
+func (s Session) empty() bool {
+	return s.Writes == 0 && s.Destructive == 0 && len(s.Plans) == 0
+}
+
+// Store is a concurrency-safe set of session states, by session ID. The states of the
+// sessions idle for longer than the TTL are dropped, and expired plans are never returned.
+type Store struct {
+	mu       sync.Mutex
+	path     string
+	ttl      time.Duration
+	sessions map[string]Session
+}
+
+// New creates a Store keeping idle sessions for ttl. When dir is not empty, the state is
+// loaded from and persisted to that directory, which is created if needed.
+func New(dir string, ttl time.Duration) (*Store, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("session state TTL must be positive, got %s", ttl)
+	}
+
+	s := &Store{ttl: ttl, sessions: map[string]Session{}}
+	if dir == "" {
+		return s, nil
+	}
+
+	// The plans hold the arguments of the previewed calls, which may include credentials.
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session state directory: %w", err)
+	}
+	s.path = filepath.Join(dir, fileName)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session state: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse session state %s: %w", s.path, err)
+	}
+	s.prune()
+	return s, nil
+}
+
+// Sessions returns the state of every session, by session ID.
+func (s *Store) Sessions() map[string]Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	return maps.Clone(s.sessions)
+}
+
+// Has reports whether the state of a session is kept.
+func (s *Store) Has(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	_, ok := s.sessions[id]
+	return ok
+}
+
+// Update changes the state of a session with fn and persists the result. A session left
+// without state is removed.
+func (s *Store) Update(id string, fn func(*Session)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := maps.Clone(s.sessions)
+	session := sessions[id]
+	fn(&session)
+	session.UpdatedAt = now().UTC()
+	if session.empty() {
+		delete(sessions, id)
+	} else {
+		sessions[id] = session
+	}
+
+	if err := s.save(sessions); err != nil {
+		return err
+	}
+	s.sessions = sessions
+	return nil
+}
+
+// ResetBudgets clears the operations consumed by every session, keeping their plans.
+func (s *Store) ResetBudgets() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := make(map[string]Session, len(s.sessions))
+	for id, session := range s.sessions {
+		session.Writes, session.Destructive = 0, 0
+		if !session.empty() {
+			sessions[id] = session
+		}
+	}
+
+	if err := s.save(sessions); err != nil {
+		return err
+	}
+	s.sessions = sessions
+	return nil
+}
+
+// prune drops the expired plans and the sessions idle for longer than the TTL. The
+// caller must hold s.mu.
+func (s *Store) prune() {
+	current := now()
+	for id, session := range s.sessions {
+		plans := session.Plans[:0:0]
+		for _, plan := range session.Plans {
+			if current.Before(plan.ExpiresAt) {
+				plans = append(plans, plan)
+			}
+		}
+		session.Plans = plans
+
+		if session.empty() || current.Sub(session.UpdatedAt) > s.ttl {
+			delete(s.sessions, id)
+		} else {
+			s.sessions[id] = session
+		}
+	}
+}
+
+// save persists the sessions when a directory is configured, replacing the previous
+// file atomically. The caller must hold s.mu.
+func (s *Store) save(sessions map[string]Session) error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session state: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.path, data); err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	return nil
+}
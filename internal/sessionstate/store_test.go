@@ -0,0 +1,98 @@
+package sessionstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew verifies store creation, TTL validation and corrupt files.
+func TestNew(t *testing.T) {
+	_, err := New("", 0)
+	assert.Error(t, err)
+
+	dir := filepath.Join(t.TempDir(), "nested", "sessions")
+	s, err := New(dir, DefaultTTL)
+	require.NoError(t, err)
+	assert.Empty(t, s.Sessions())
+	assert.DirExists(t, dir)
+
+	corrupt := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(corrupt, fileName), []byte("not json"), 0o600))
+	_, err = New(corrupt, DefaultTTL)
+	assert.Error(t, err)
+}
+
+// TestUpdate verifies session updates, removal of empty sessions and budget resets.
+func TestUpdate(t *testing.T) {
+	s, err := New("", DefaultTTL)
+	require.NoError(t, err)
+
+	plan := Plan{ID: "abc", Tool: "deployStackAndWait", Arguments: map[string]any{"name": "web"}, ExpiresAt: time.Now().Add(time.Minute)}
+	require.NoError(t, s.Update("a", func(session *Session) {
+		session.Writes = 2
+		session.Destructive = 1
+	}))
+	require.NoError(t, s.Update("b", func(session *Session) {
+		session.Writes = 1
+		session.Plans = append(session.Plans, plan)
+	}))
+
+	sessions := s.Sessions()
+	require.Len(t, sessions, 2)
+	assert.Equal(t, 2, sessions["a"].Writes)
+	assert.Equal(t, []Plan{plan}, sessions["b"].Plans)
+	assert.True(t, s.Has("a"))
+	assert.False(t, s.Has("c"))
+
+	require.NoError(t, s.ResetBudgets())
+	sessions = s.Sessions()
+	require.Len(t, sessions, 1, "sessions left without state are removed")
+	assert.Zero(t, sessions["b"].Writes)
+	assert.Equal(t, []Plan{plan}, sessions["b"].Plans)
+
+	require.NoError(t, s.Update("b", func(session *Session) { session.Plans = nil }))
+	assert.Empty(t, s.Sessions())
+}
+
+// TestPersistence verifies that the state survives a restart, without expired plans and
+// idle sessions.
+func TestPersistence(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	current := start
+	original := now
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = original })
+
+	dir := t.TempDir()
+	s, err := New(dir, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Update("idle", func(session *Session) { session.Writes = 1 }))
+	current = start.Add(50 * time.Minute)
+	require.NoError(t, s.Update("active", func(session *Session) {
+		session.Writes = 3
+		session.Plans = []Plan{
+			{ID: "pending", Tool: "onboardEnvironment", ExpiresAt: start.Add(time.Hour + 10*time.Minute)},
+			{ID: "expired", Tool: "onboardEnvironment", ExpiresAt: start.Add(time.Hour)},
+		}
+	}))
+
+	info, err := os.Stat(filepath.Join(dir, fileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	current = start.Add(time.Hour + 5*time.Minute)
+	restarted, err := New(dir, time.Hour)
+	require.NoError(t, err)
+
+	sessions := restarted.Sessions()
+	require.Len(t, sessions, 1, "sessions idle for longer than the TTL are dropped")
+	assert.Equal(t, 3, sessions["active"].Writes)
+	require.Len(t, sessions["active"].Plans, 1)
+	assert.Equal(t, "pending", sessions["active"].Plans[0].ID)
+}
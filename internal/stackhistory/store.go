@@ -0,0 +1,230 @@
+// Package stackhistory keeps a bounded local history of stack file contents.
+// Versions are captured by the MCP server whenever it creates or updates a stack,
+// so earlier revisions remain available for change forensics even though Portainer
+// only stores the current file. The history lives in memory and can optionally be
+// persisted as one JSON file per stack in a directory.
+package stackhistory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/atomicfile"
+)
+
+// Stack kinds. Edge stacks and regular stacks use separate ID spaces in Portainer.
+const (
+	KindEdge    = "edge"
+	KindRegular = "regular"
+)
+
+// now returns the current time. It is a variable so tests can pin the clock.
+var now = time.Now
+
+// Version is one captured revision of a stack file.
+type Version struct {
+	Version    int       `json:"version"`
+	CapturedAt time.Time `json:"captured_at"`
+	Source     string    `json:"source"`
+	SHA256     string    `json:"sha256"`
+	Size       int       `json:"size"`
+	Content    string    `json:"content,omitempty"`
+}
+
+// Store is a concurrency-safe history of stack file versions, keyed by stack kind and ID.
+// Each stack keeps at most limit versions; the oldest versions are dropped first.
+type Store struct {
+	mu     sync.Mutex
+	dir    string
+	limit  int
+	stacks map[string][]Version
+}
+
+// New creates a Store keeping up to limit versions per stack. When dir is not empty,
+// the history is loaded from and persisted to that directory, which is created if needed.
+func New(dir string, limit int) (*Store, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("stack history limit must be at least 1, got %d", limit)
+	}
+
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create stack history directory: %w", err)
+		}
+	}
+
+	return &Store{dir: dir, limit: limit, stacks: map[string][]Version{}}, nil
+}
+
+// Record captures content as the newest version of a stack. The source describes
+// the operation that produced it (e.g. the tool name). Content identical to the
+// latest version is not recorded again. It returns the recorded (or latest) version.
+func (s *Store) Record(kind string, id int, source, content string) (Version, error) {
+	if err := validateKind(kind); err != nil {
+		return Version{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(kind, id)
+	if err != nil {
+		return Version{}, err
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	if n := len(versions); n > 0 && versions[n-1].SHA256 == hash {
+		return versions[n-1], nil
+	}
+
+	next := 1
+	if n := len(versions); n > 0 {
+		next = versions[n-1].Version + 1
+	}
+	v := Version{
+		Version:    next,
+		CapturedAt: now().UTC(),
+		Source:     source,
+		SHA256:     hash,
+		Size:       len(content),
+		Content:    content,
+	}
+
+	versions = append(versions, v)
+	if len(versions) > s.limit {
+		versions = versions[len(versions)-s.limit:]
+	}
+
+	if err := s.save(kind, id, versions); err != nil {
+		return Version{}, err
+	}
+	s.stacks[key(kind, id)] = versions
+
+	return v, nil
+}
+
+// Has reports whether at least one version of a stack has been captured.
+func (s *Store) Has(kind string, id int) (bool, error) {
+	versions, err := s.List(kind, id)
+	return len(versions) > 0, err
+}
+
+// List returns the captured versions of a stack, oldest first, without their content.
+func (s *Store) List(kind string, id int) ([]Version, error) {
+	if err := validateKind(kind); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Version, len(versions))
+	for i, v := range versions {
+		v.Content = ""
+		list[i] = v
+	}
+	return list, nil
+}
+
+// Get returns a single version of a stack, including its content.
+// The boolean is false when the version is unknown or no longer retained.
+func (s *Store) Get(kind string, id int, version int) (Version, bool, error) {
+	if err := validateKind(kind); err != nil {
+		return Version{}, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions, err := s.load(kind, id)
+	if err != nil {
+		return Version{}, false, err
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			return v, true, nil
+		}
+	}
+	return Version{}, false, nil
+}
+
+// load returns the versions of a stack, reading them from disk on first access.
+// The caller must hold s.mu.
+func (s *Store) load(kind string, id int) ([]Version, error) {
+	k := key(kind, id)
+	if versions, ok := s.stacks[k]; ok {
+		return versions, nil
+	}
+	if s.dir == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(s.path(kind, id))
+	if errors.Is(err, os.ErrNotExist) {
+		s.stacks[k] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack history: %w", err)
+	}
+
+	var versions []Version
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("failed to parse stack history %s: %w", s.path(kind, id), err)
+	}
+	if len(versions) > s.limit {
+		versions = versions[len(versions)-s.limit:]
+	}
+	s.stacks[k] = versions
+	return versions, nil
+}
+
+// save persists the versions of a stack when a directory is configured, replacing the
+// previous file atomically. The caller must hold s.mu.
+func (s *Store) save(kind string, id int, versions []Version) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stack history: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(s.path(kind, id), data); err != nil {
+		return fmt.Errorf("failed to write stack history: %w", err)
+	}
+	return nil
+}
+
+// path returns the history file of a stack.
+func (s *Store) path(kind string, id int) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%d.json", kind, id))
+}
+
+// key returns the in-memory key of a stack.
+func key(kind string, id int) string {
+	return fmt.Sprintf("%s/%d", kind, id)
+}
+
+// validateKind checks that kind is a known stack kind.
+func validateKind(kind string) error {
+	if kind != KindEdge && kind != KindRegular {
+		return fmt.Errorf("invalid stack kind: %s (must be %s or %s)", kind, KindEdge, KindRegular)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package stackhistory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew verifies store creation and limit validation.
+func TestNew(t *testing.T) {
+	_, err := New("", 0)
+	assert.Error(t, err)
+
+	dir := filepath.Join(t.TempDir(), "nested", "history")
+	store, err := New(dir, 5)
+	require.NoError(t, err)
+	assert.NotNil(t, store)
+	assert.DirExists(t, dir)
+}
+
+// TestRecord verifies versioning, deduplication and retention.
+func TestRecord(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	original := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = original })
+
+	store, err := New("", 2)
+	require.NoError(t, err)
+
+	v1, err := store.Record(KindRegular, 1, "baseline", "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, v1.Version)
+	assert.Equal(t, fixed, v1.CapturedAt)
+	assert.Equal(t, 1, v1.Size)
+
+	same, err := store.Record(KindRegular, 1, "updateStack", "a")
+	require.NoError(t, err)
+	assert.Equal(t, v1, same, "identical content must not create a new version")
+
+	_, err = store.Record(KindRegular, 1, "updateStack", "b")
+	require.NoError(t, err)
+	v3, err := store.Record(KindRegular, 1, "updateStack", "c")
+	require.NoError(t, err)
+	assert.Equal(t, 3, v3.Version)
+
+	versions, err := store.List(KindRegular, 1)
+	require.NoError(t, err)
+	require.Len(t, versions, 2, "oldest versions beyond the limit are dropped")
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, 3, versions[1].Version)
+	assert.Empty(t, versions[0].Content, "List must not include content")
+
+	_, found, err := store.Get(KindRegular, 1, 1)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	got, found, err := store.Get(KindRegular, 1, 3)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "c", got.Content)
+
+	has, err := store.Has(KindEdge, 1)
+	require.NoError(t, err)
+	assert.False(t, has, "edge and regular stacks use separate histories")
+
+	_, err = store.Record("swarm", 1, "x", "a")
+	assert.Error(t, err)
+	_, err = store.List("swarm", 1)
+	assert.Error(t, err)
+}
+
+// TestPersistence verifies that the history survives a new store on the same directory.
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := New(dir, 10)
+	require.NoError(t, err)
+	_, err = store.Record(KindEdge, 4, "createStack", "services: {}")
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "edge-4.json"))
+
+	reopened, err := New(dir, 10)
+	require.NoError(t, err)
+	got, found, err := reopened.Get(KindEdge, 4, 1)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "services: {}", got.Content)
+	assert.Equal(t, "createStack", got.Source)
+
+	v2, err := reopened.Record(KindEdge, 4, "updateStack", "services: {web: {}}")
+	require.NoError(t, err)
+	assert.Equal(t, 2, v2.Version)
+}
+
+// TestCorruptHistoryFile verifies that an unreadable history file is reported.
+func TestCorruptHistoryFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "regular-2.json"), []byte("not json"), 0o600))
+
+	store, err := New(dir, 10)
+	require.NoError(t, err)
+	_, err = store.List(KindRegular, 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,223 @@
+// Package trends keeps a bounded local history of fleet samples: the container, stack,
+// image and volume counts of every environment, as reported by the Portainer snapshots
+// at the time of the sample. The server records a sample periodically so that changes
+// over time can be reported. The history lives in memory and can optionally be persisted
+// in a directory, as a JSON Lines file with one sample per line: each sample is appended
+// to the file, which is only rewritten once it holds twice the limit.
+package trends
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/internal/atomicfile"
+)
+
+// fileName is the name of the history file in the trends directory.
+const fileName = "trends.jsonl"
+
+// now is the clock stamping samples recorded without a time; tests replace it.
+var now = time.Now
+
+// Environment holds the counts of one environment in a sample.
+type Environment struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Containers int    `json:"containers"`
+	Running    int    `json:"running"`
+	Stopped    int    `json:"stopped"`
+	Unhealthy  int    `json:"unhealthy"`
+	Stacks     int    `json:"stacks"`
+	Images     int    `json:"images"`
+	Volumes    int    `json:"volumes"`
+}
+
+// Sample is the state of the fleet at one point in time.
+type Sample struct {
+	TakenAt      time.Time     `json:"taken_at"`
+	Environments []Environment `json:"environments"`
+}
+
+// Store is a concurrency-safe list of samples, oldest first. It keeps at most limit
+// samples; the oldest samples are dropped first.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	limit   int
+	samples []Sample
+	// lines is the number of samples in the file, including those trimmed from samples.
+	lines int
+}
+
+// New creates a Store keeping up to limit samples. When dir is not empty, the history is
+// loaded from and persisted to that directory, which is created if needed. A malformed
+// last line, left by an interrupted write, is dropped; a malformed line before it is an
+// error.
+func New(dir string, limit int) (*Store, error) {
+	if limit < 1 {
+		return nil, fmt.Errorf("trend history limit must be at least 1, got %d", limit)
+	}
+
+	s := &Store{limit: limit}
+	if dir == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create trend history directory: %w", err)
+	}
+	s.path = filepath.Join(dir, fileName)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trend history: %w", err)
+	}
+	// A malformed line is only an error when another sample follows it: the last line
+	// may have been cut short, or padded with zeros, by a crash during its append.
+	complete := bytes.HasSuffix(data, []byte("\n"))
+	var parseErr error
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		var sample Sample
+		if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil {
+			parseErr = fmt.Errorf("failed to parse trend history %s, line %d: %w", s.path, line, err)
+			continue
+		}
+		s.samples = append(s.samples, sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trend history: %w", err)
+	}
+	slices.SortStableFunc(s.samples, func(a, b Sample) int { return a.TakenAt.Compare(b.TakenAt) })
+	s.samples = trim(s.samples, s.limit)
+
+	// Rewrite the file when it ends with a partial or malformed line, which the next
+	// sample would otherwise be appended to, or holds more samples than the limit.
+	s.lines = bytes.Count(data, []byte("\n"))
+	if !complete || parseErr != nil || s.lines > len(s.samples) {
+		if err := s.compact(s.samples); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Record adds a sample to the history. Its time is set when the sample has none. It
+// returns the recorded sample.
+func (s *Store) Record(sample Sample) (Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sample.TakenAt.IsZero() {
+		sample.TakenAt = now().UTC()
+	}
+	if sample.Environments == nil {
+		sample.Environments = []Environment{}
+	}
+
+	samples := trim(append(slices.Clip(s.samples), sample), s.limit)
+	if err := s.save(sample, samples); err != nil {
+		return Sample{}, err
+	}
+	s.samples = samples
+	return sample, nil
+}
+
+// Since returns the samples taken at or after t, oldest first.
+func (s *Store) Since(t time.Time) []Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, _ := slices.BinarySearchFunc(s.samples, t, func(sample Sample, t time.Time) int {
+		return sample.TakenAt.Compare(t)
+	})
+	return slices.Clone(s.samples[i:])
+}
+
+// Len returns the number of samples in the history.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.samples)
+}
+
+// trim drops the oldest samples beyond limit.
+func trim(samples []Sample, limit int) []Sample {
+	if len(samples) > limit {
+		return samples[len(samples)-limit:]
+	}
+	return samples
+}
+
+// save appends a sample to the file when a directory is configured. Once the file holds
+// twice the limit, it is rewritten with the kept samples instead. The caller must hold
+// s.mu.
+func (s *Store) save(sample Sample, samples []Sample) error {
+	if s.path == "" {
+		return nil
+	}
+	if s.lines+1 >= 2*s.limit {
+		return s.compact(samples)
+	}
+
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trend history: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write trend history: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write trend history: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write trend history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write trend history: %w", err)
+	}
+	s.lines++
+	return nil
+}
+
+// compact replaces the file with one holding only the given samples. The caller must hold
+// s.mu.
+func (s *Store) compact(samples []Sample) error {
+	var buf bytes.Buffer
+	for _, sample := range samples {
+		data, err := json.Marshal(sample)
+		if err != nil {
+			return fmt.Errorf("failed to marshal trend history: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	if err := atomicfile.WriteFile(s.path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write trend history: %w", err)
+	}
+	s.lines = len(samples)
+	return nil
+}
@@ -0,0 +1,156 @@
+package trends
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew verifies store creation, invalid limits and corrupt files.
+func TestNew(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "trends")
+	s, err := New(dir, 10)
+	require.NoError(t, err)
+	assert.Zero(t, s.Len())
+	assert.DirExists(t, dir)
+
+	_, err = New("", 0)
+	assert.Error(t, err)
+
+	corrupt := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(corrupt, fileName), []byte("not json\n{}\n"), 0o600))
+	_, err = New(corrupt, 10)
+	assert.ErrorContains(t, err, "line 1")
+}
+
+// TestRecordSince verifies that samples are timed, bounded and selected by time.
+func TestRecordSince(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	original := now
+	t.Cleanup(func() { now = original })
+
+	s, err := New("", 3)
+	require.NoError(t, err)
+
+	for i := range 4 {
+		now = func() time.Time { return fixed.Add(time.Duration(i) * time.Hour) }
+		sample, err := s.Record(Sample{Environments: []Environment{{ID: 1, Name: "prod", Containers: i}}})
+		require.NoError(t, err)
+		assert.Equal(t, fixed.Add(time.Duration(i)*time.Hour), sample.TakenAt)
+	}
+	assert.Equal(t, 3, s.Len(), "the oldest sample is dropped")
+
+	all := s.Since(time.Time{})
+	require.Len(t, all, 3)
+	assert.Equal(t, 1, all[0].Environments[0].Containers)
+
+	recent := s.Since(fixed.Add(2 * time.Hour))
+	require.Len(t, recent, 2)
+	assert.Equal(t, fixed.Add(2*time.Hour), recent[0].TakenAt)
+	assert.Empty(t, s.Since(fixed.Add(4*time.Hour)))
+
+	empty, err := s.Record(Sample{})
+	require.NoError(t, err)
+	assert.Equal(t, []Environment{}, empty.Environments)
+}
+
+// TestPersistence verifies that the samples survive a new store on the same directory,
+// bounded by the limit of the new store.
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	taken := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	s, err := New(dir, 10)
+	require.NoError(t, err)
+	for i := range 3 {
+		_, err := s.Record(Sample{TakenAt: taken.Add(time.Duration(i) * time.Hour), Environments: []Environment{{ID: 2, Name: "edge", Stacks: i}}})
+		require.NoError(t, err)
+	}
+	assert.FileExists(t, filepath.Join(dir, fileName))
+
+	reopened, err := New(dir, 2)
+	require.NoError(t, err)
+	samples := reopened.Since(time.Time{})
+	require.Len(t, samples, 2)
+	assert.Equal(t, taken.Add(time.Hour), samples[0].TakenAt)
+	assert.Equal(t, 2, samples[1].Environments[0].Stacks)
+}
+
+// TestAppendAndCompact verifies that samples are appended to the file, which is rewritten
+// once it holds twice the limit, and that a last line cut short is dropped on load.
+func TestAppendAndCompact(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, fileName)
+	taken := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lines := func() int {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return strings.Count(string(data), "\n")
+	}
+
+	s, err := New(dir, 3)
+	require.NoError(t, err)
+	for i := range 5 {
+		_, err := s.Record(Sample{TakenAt: taken.Add(time.Duration(i) * time.Hour)})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 5, lines(), "samples are appended up to twice the limit")
+
+	_, err = s.Record(Sample{TakenAt: taken.Add(5 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, lines(), "the file is compacted to the limit")
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"taken_at":"2025-06`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reopened, err := New(dir, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reopened.Len())
+	assert.Equal(t, 3, lines(), "the partial line is removed")
+	samples := reopened.Since(time.Time{})
+	assert.Equal(t, taken.Add(5*time.Hour), samples[2].TakenAt)
+}
+
+// TestMalformedLines verifies that a malformed last line is dropped on load, whether or
+// not it ends with a newline, while a malformed line followed by samples is an error.
+func TestMalformedLines(t *testing.T) {
+	sample := `{"taken_at":"2025-06-01T12:00:00Z","environments":null}` + "\n"
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr string
+	}{
+		{name: "cut short", content: sample + `{"taken_at":"2025-06`, want: 1},
+		{name: "zero padded", content: sample + "\x00\x00\x00\x00\n", want: 1},
+		{name: "only line", content: "not json\n", want: 0},
+		{name: "followed by a sample", content: "not json\n" + sample, wantErr: "line 1"},
+		{name: "followed by a partial line", content: sample + "not json\n" + `{"taken_at":`, wantErr: "line 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, fileName)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			s, err := New(dir, 10)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Len())
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, strings.Repeat(sample, tt.want), string(data), "the malformed line is removed")
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package notify implements the signature of the destructive action notifications that
+// the MCP server posts to its webhook, for the receivers to check them.
+//
+// Every payload is sent with a random delivery ID in the DeliveryHeader. When a secret is
+// configured, the payload is also signed with HMAC-SHA256, keyed with the secret, over
+// "<timestamp>.<delivery>.<body>": the Unix timestamp of the TimestampHeader, the delivery
+// ID and the raw request body. The signature is sent as "sha256=<hex>" in the
+// SignatureHeader. Verify implements the check made by the receiver. The signature alone
+// does not prevent replays: it bounds them to SignatureTolerance, within which a receiver
+// rejects replayed payloads by remembering the delivery IDs it has seen.
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the HMAC-SHA256 signature of a signed payload.
+	SignatureHeader = "X-Portainer-MCP-Signature"
+	// TimestampHeader carries the Unix time at which a payload was signed.
+	TimestampHeader = "X-Portainer-MCP-Timestamp"
+	// DeliveryHeader carries the random ID of a delivery, unique to each notification.
+	DeliveryHeader = "X-Portainer-MCP-Delivery"
+	// SignatureTolerance is how far the timestamp of a payload may be from the time of
+	// its verification; receivers deduplicate the delivery IDs seen within it.
+	SignatureTolerance = 5 * time.Minute
+	// signaturePrefix names the hash function of the signature.
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature of a delivery signed at the given Unix time, as sent in the
+// SignatureHeader.
+func Sign(secret string, timestamp int64, delivery string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(delivery))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a payload received with the given headers: the
+// signature must match the timestamp, the delivery ID and the body, and the timestamp
+// must be within SignatureTolerance of now. It does not detect replays within the
+// tolerance, which requires remembering the delivery IDs.
+func Verify(secret string, header http.Header, body []byte, now time.Time) error {
+	signature := header.Get(SignatureHeader)
+	if signature == "" {
+		return errors.New("missing " + SignatureHeader + " header")
+	}
+	delivery := header.Get(DeliveryHeader)
+	if delivery == "" {
+		return errors.New("missing " + DeliveryHeader + " header")
+	}
+	timestamp, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header: %w", TimestampHeader, err)
+	}
+	if age := now.Sub(time.Unix(timestamp, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("payload signed at %s is outside the %s tolerance", time.Unix(timestamp, 0).UTC().Format(time.RFC3339), SignatureTolerance)
+	}
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, delivery, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSign verifies the signature against a value computed independently, so that
+// receivers written in other languages can check their implementation against it.
+func TestSign(t *testing.T) {
+	assert.Equal(t, "sha256=10fe9643146016991f65051914096c5e7f6ae2cf2f0520e75b116591b016c9df", Sign("s3cret", 1748779200, "d1", []byte(`{"tool":"deleteStack"}`)))
+}
+
+// TestVerify verifies that tampered, replayed and unsigned payloads are rejected.
+func TestVerify(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"tool":"deleteStack"}`)
+	signed := func(secret string, at time.Time) http.Header {
+		header := http.Header{}
+		header.Set(TimestampHeader, strconv.FormatInt(at.Unix(), 10))
+		header.Set(DeliveryHeader, "d1")
+		header.Set(SignatureHeader, Sign(secret, at.Unix(), "d1", body))
+		return header
+	}
+
+	assert.NoError(t, Verify("s3cret", signed("s3cret", now), body, now))
+	assert.ErrorContains(t, Verify("other", signed("s3cret", now), body, now), "signature mismatch")
+	assert.ErrorContains(t, Verify("s3cret", signed("s3cret", now), []byte(`{"tool":"deleteUser"}`), now), "signature mismatch")
+	assert.ErrorContains(t, Verify("s3cret", signed("s3cret", now.Add(-10*time.Minute)), body, now), "tolerance")
+	assert.ErrorContains(t, Verify("s3cret", signed("s3cret", now.Add(10*time.Minute)), body, now), "tolerance")
+	assert.ErrorContains(t, Verify("s3cret", http.Header{}, body, now), "missing")
+
+	forged := signed("s3cret", now)
+	forged.Set(TimestampHeader, strconv.FormatInt(now.Add(time.Second).Unix(), 10))
+	assert.ErrorContains(t, Verify("s3cret", forged, body, now), "signature mismatch", "the timestamp is signed")
+
+	forged = signed("s3cret", now)
+	forged.Set(DeliveryHeader, "d2")
+	assert.ErrorContains(t, Verify("s3cret", forged, body, now), "signature mismatch", "the delivery ID is signed")
+	forged.Del(DeliveryHeader)
+	assert.ErrorContains(t, Verify("s3cret", forged, body, now), "missing "+DeliveryHeader)
+}
@@ -19,7 +19,7 @@ func (c *PortainerClient) GetAccessGroups() ([]models.AccessGroup, error) {
 		return nil, fmt.Errorf("failed to list endpoint groups: %w", err)
 	}
 
-	endpoints, err := c.cli.ListEndpoints()
+	endpoints, err := c.cli.ListEndpoints(nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list endpoints: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,7 @@ func TestGetAccessGroups(t *testing.T) {
 					ID:             1,
 					Name:           "group1",
 					EnvironmentIds: []int{1, 2},
+					TagIds:         []int{},
 					UserAccesses: map[int]string{
 						1: "environment_administrator",
 						2: "helpdesk_user",
@@ -109,6 +111,7 @@ func TestGetAccessGroups(t *testing.T) {
 					ID:             1,
 					Name:           "group1",
 					EnvironmentIds: []int{},
+					TagIds:         []int{},
 					UserAccesses: map[int]string{
 						1: "environment_administrator",
 					},
@@ -128,7 +131,7 @@ func TestGetAccessGroups(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAPI := new(MockPortainerAPI)
 			mockAPI.On("ListEndpointGroups").Return(tt.mockEndpointGroups, tt.mockEndpointGroupsErr)
-			mockAPI.On("ListEndpoints").Return(tt.mockEndpoints, tt.mockEndpointsErr)
+			mockAPI.On("ListEndpoints", (*endpoints.EndpointListParams)(nil)).Return(tt.mockEndpoints, tt.mockEndpointsErr)
 
 			client := &PortainerClient{cli: mockAPI}
 
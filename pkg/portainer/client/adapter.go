@@ -3,7 +3,9 @@ package client
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,11 +18,14 @@ import (
 	"github.com/portainer/client-api-go/v2/pkg/client/backup"
 	"github.com/portainer/client-api-go/v2/pkg/client/custom_templates"
 	"github.com/portainer/client-api-go/v2/pkg/client/edge_jobs"
+	"github.com/portainer/client-api-go/v2/pkg/client/edge_stacks"
 	"github.com/portainer/client-api-go/v2/pkg/client/edge_update_schedules"
 	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
+	"github.com/portainer/client-api-go/v2/pkg/client/gitops"
 	"github.com/portainer/client-api-go/v2/pkg/client/helm"
 	"github.com/portainer/client-api-go/v2/pkg/client/kubernetes"
 	"github.com/portainer/client-api-go/v2/pkg/client/registries"
+	"github.com/portainer/client-api-go/v2/pkg/client/resource_controls"
 	"github.com/portainer/client-api-go/v2/pkg/client/roles"
 	"github.com/portainer/client-api-go/v2/pkg/client/settings"
 	"github.com/portainer/client-api-go/v2/pkg/client/ssl"
@@ -38,11 +43,11 @@ const (
 	defaultHTTPTimeout = 30 * time.Second
 )
 
-// portainerAPIAdapter wraps the SDK PortainerClient and adds methods
-// that are available in the Swagger-generated client but not exposed
-// by the SDK's high-level client (e.g., delete operations).
+// portainerAPIAdapter implements PortainerAPIClient over the Swagger-generated
+// client, covering both the operations of the SDK's high-level client and the ones
+// it does not expose (e.g., delete operations). All requests go through a single
+// HTTP client.
 type portainerAPIAdapter struct {
-	*sdkclient.PortainerClient
 	swagger       *swaggerclient.PortainerClientAPI
 	httpTransport *httptransport.Runtime
 	scheme        string
@@ -74,19 +79,18 @@ func parseHostScheme(host string) (scheme, cleanHost string) {
 	return "https", host
 }
 
-// newPortainerAPIAdapter creates a new adapter that embeds the SDK high-level
-// client and also holds a reference to the low-level Swagger client for
-// operations not exposed by the SDK.
-func newPortainerAPIAdapter(host, apiKey string, skipTLSVerify bool) *portainerAPIAdapter {
+// newPortainerAPIAdapter creates a new adapter over the low-level Swagger client.
+// When inst is not nil, every request of the adapter is reported to it.
+func newPortainerAPIAdapter(host, apiKey string, skipTLSVerify bool, inst Instrumentation) *portainerAPIAdapter {
 	scheme, cleanHost := parseHostScheme(host)
-	sdkCli := sdkclient.NewPortainerClient(cleanHost, apiKey,
-		sdkclient.WithSkipTLSVerify(skipTLSVerify),
-		sdkclient.WithScheme(scheme),
-	)
 
+	var roundTripper http.RoundTripper = newHTTPTransport(skipTLSVerify)
+	if inst != nil {
+		roundTripper = &instrumentedTransport{next: roundTripper, inst: inst}
+	}
 	httpClient := &http.Client{
 		Timeout:   defaultHTTPTimeout,
-		Transport: newHTTPTransport(skipTLSVerify),
+		Transport: roundTripper,
 	}
 	transport := httptransport.NewWithClient(cleanHost, "/api", []string{scheme}, httpClient)
 	apiKeyAuth := runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
@@ -95,25 +99,24 @@ func newPortainerAPIAdapter(host, apiKey string, skipTLSVerify bool) *portainerA
 	transport.DefaultAuthentication = apiKeyAuth
 
 	return &portainerAPIAdapter{
-		PortainerClient: sdkCli,
-		swagger:         swaggerclient.New(transport, nil),
-		httpTransport:   transport,
-		scheme:          scheme,
-		cleanHost:       cleanHost,
-		apiKey:          apiKey,
-		proxyClient:     httpClient,
+		swagger:       swaggerclient.New(transport, nil),
+		httpTransport: transport,
+		scheme:        scheme,
+		cleanHost:     cleanHost,
+		apiKey:        apiKey,
+		proxyClient:   httpClient,
 	}
 }
 
-// ProxyDockerRequest overrides the SDK method to use the correct scheme
-// instead of the hardcoded "https://" in the upstream SDK.
+// ProxyDockerRequest sends a request to the Docker API of an environment through
+// the Portainer proxy, using the scheme of the server URL.
 func (a *portainerAPIAdapter) ProxyDockerRequest(environmentId int, opts sdkclient.ProxyRequestOptions) (*http.Response, error) {
 	baseURL := fmt.Sprintf("%s://%s/api/endpoints/%d/docker%s", a.scheme, a.cleanHost, environmentId, opts.APIPath)
 	return a.proxyRequest(baseURL, opts)
 }
 
-// ProxyKubernetesRequest overrides the SDK method to use the correct scheme
-// instead of the hardcoded "https://" in the upstream SDK.
+// ProxyKubernetesRequest sends a request to the Kubernetes API of an environment
+// through the Portainer proxy, using the scheme of the server URL.
 func (a *portainerAPIAdapter) ProxyKubernetesRequest(environmentId int, opts sdkclient.ProxyRequestOptions) (*http.Response, error) {
 	baseURL := fmt.Sprintf("%s://%s/api/endpoints/%d/kubernetes%s", a.scheme, a.cleanHost, environmentId, opts.APIPath)
 	return a.proxyRequest(baseURL, opts)
@@ -172,6 +175,110 @@ func (a *portainerAPIAdapter) DeleteUser(id int64) error {
 	return nil
 }
 
+// GetCurrentUser retrieves the user that owns the API token using the low-level Swagger client.
+func (a *portainerAPIAdapter) GetCurrentUser() (*apimodels.PortainereeUser, error) {
+	params := users.NewCurrentUserInspectParams()
+	resp, err := a.swagger.Users.CurrentUserInspect(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// ListUserAPIKeys lists the API tokens of a user using the low-level Swagger client.
+func (a *portainerAPIAdapter) ListUserAPIKeys(id int64) ([]*apimodels.PortainerAPIKey, error) {
+	params := users.NewUserGetAPIKeysParams().WithID(id)
+	resp, err := a.swagger.Users.UserGetAPIKeys(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user API keys: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// DeleteUserAPIKey revokes an API token of a user using the low-level Swagger client.
+func (a *portainerAPIAdapter) DeleteUserAPIKey(userID, keyID int64) error {
+	params := users.NewUserRemoveAPIKeyParams().WithID(userID).WithKeyID(keyID)
+	_, err := a.swagger.Users.UserRemoveAPIKey(params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete user API key: %w", err)
+	}
+	return nil
+}
+
+// UpdateResourceControl replaces the users and teams allowed by a resource control using
+// the low-level Swagger client.
+func (a *portainerAPIAdapter) UpdateResourceControl(id int64, payload *apimodels.ResourcecontrolsResourceControlUpdatePayload) error {
+	params := resource_controls.NewResourceControlUpdateParams().WithID(id).WithBody(payload)
+	_, err := a.swagger.ResourceControls.ResourceControlUpdate(params, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update resource control: %w", err)
+	}
+	return nil
+}
+
+// ListUserMemberships lists the team memberships of a user.
+func (a *portainerAPIAdapter) ListUserMemberships(id int64) ([]*apimodels.PortainerTeamMembership, error) {
+	// Use raw HTTP because the SDK declares a single membership as the response,
+	// while the API returns a list.
+	op := &runtime.ClientOperation{
+		ID:                 "UserMembershipsInspect",
+		Method:             "GET",
+		PathPattern:        "/users/{id}/memberships",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{a.scheme},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+			return req.SetPathParam("id", strconv.FormatInt(id, 10))
+		}),
+		AuthInfo: a.httpTransport.DefaultAuthentication,
+		Reader: runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (any, error) {
+			if resp.Code() >= 300 {
+				msg, _ := io.ReadAll(resp.Body())
+				return nil, fmt.Errorf("unexpected status %d: %s", resp.Code(), strings.TrimSpace(string(msg)))
+			}
+			var result []*apimodels.PortainerTeamMembership
+			if err := consumer.Consume(resp.Body(), &result); err != nil {
+				return nil, err
+			}
+			return result, nil
+		}),
+	}
+	res, err := a.httpTransport.Submit(op)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user memberships: %w", err)
+	}
+	return res.([]*apimodels.PortainerTeamMembership), nil
+}
+
+// GetResponseSize sends a GET request to an API path and returns the size of the response
+// body, which is read and discarded.
+func (a *portainerAPIAdapter) GetResponseSize(path string) (int64, error) {
+	op := &runtime.ClientOperation{
+		ID:                 "ResponseSize",
+		Method:             "GET",
+		PathPattern:        path,
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{a.scheme},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+			return nil
+		}),
+		AuthInfo: a.httpTransport.DefaultAuthentication,
+		Reader: runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (any, error) {
+			if resp.Code() >= 300 {
+				msg, _ := io.ReadAll(resp.Body())
+				return nil, fmt.Errorf("unexpected status %d: %s", resp.Code(), strings.TrimSpace(string(msg)))
+			}
+			return io.Copy(io.Discard, resp.Body())
+		}),
+	}
+	res, err := a.httpTransport.Submit(op)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	return res.(int64), nil
+}
+
 // DeleteEndpoint deletes an endpoint by ID using the low-level Swagger client.
 func (a *portainerAPIAdapter) DeleteEndpoint(id int64) error {
 	params := endpoints.NewEndpointDeleteParams().WithID(id)
@@ -182,6 +289,115 @@ func (a *portainerAPIAdapter) DeleteEndpoint(id int64) error {
 	return nil
 }
 
+// CreateEndpoint creates an endpoint using the low-level Swagger client, which sends the
+// multipart form expected by the Portainer API.
+func (a *portainerAPIAdapter) CreateEndpoint(params *endpoints.EndpointCreateParams) (*apimodels.PortainereeEndpoint, error) {
+	resp, err := a.swagger.Endpoints.EndpointCreate(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create endpoint: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// UpdateEndpointSettings updates fields of an endpoint from a JSON map.
+func (a *portainerAPIAdapter) UpdateEndpointSettings(id int64, body map[string]any) error {
+	// Use raw HTTP because the SDK payload drops zero values (omitempty), which makes it
+	// impossible to reset an interval to the global default.
+	op := &runtime.ClientOperation{
+		ID:                 "EndpointUpdate",
+		Method:             "PUT",
+		PathPattern:        "/endpoints/{id}",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{a.scheme},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+			if err := req.SetPathParam("id", strconv.FormatInt(id, 10)); err != nil {
+				return err
+			}
+			return req.SetBodyParam(body)
+		}),
+		AuthInfo: a.httpTransport.DefaultAuthentication,
+		Reader: runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (any, error) {
+			if resp.Code() >= 300 {
+				msg, _ := io.ReadAll(resp.Body())
+				return nil, fmt.Errorf("unexpected status %d: %s", resp.Code(), strings.TrimSpace(string(msg)))
+			}
+			return nil, nil
+		}),
+	}
+	if _, err := a.httpTransport.Submit(op); err != nil {
+		return fmt.Errorf("failed to update endpoint: %w", err)
+	}
+	return nil
+}
+
+// UpdateEndpointRegistryAccess replaces the accesses of a registry on an endpoint.
+func (a *portainerAPIAdapter) UpdateEndpointRegistryAccess(endpointID, registryID int64, body map[string]any) error {
+	// Use raw HTTP because the SDK payload drops empty access policies (omitempty), which
+	// makes it impossible to remove the last user or team of a registry.
+	op := &runtime.ClientOperation{
+		ID:                 "EndpointRegistryAccess",
+		Method:             "PUT",
+		PathPattern:        "/endpoints/{id}/registries/{registryId}",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{a.scheme},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+			if err := req.SetPathParam("id", strconv.FormatInt(endpointID, 10)); err != nil {
+				return err
+			}
+			if err := req.SetPathParam("registryId", strconv.FormatInt(registryID, 10)); err != nil {
+				return err
+			}
+			return req.SetBodyParam(body)
+		}),
+		AuthInfo: a.httpTransport.DefaultAuthentication,
+		Reader: runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (any, error) {
+			if resp.Code() >= 300 {
+				msg, _ := io.ReadAll(resp.Body())
+				return nil, fmt.Errorf("unexpected status %d: %s", resp.Code(), strings.TrimSpace(string(msg)))
+			}
+			return nil, nil
+		}),
+	}
+	if _, err := a.httpTransport.Submit(op); err != nil {
+		return fmt.Errorf("failed to update endpoint registry access: %w", err)
+	}
+	return nil
+}
+
+// UpdateEndpointGroupSettings updates fields of an endpoint group from a JSON map.
+func (a *portainerAPIAdapter) UpdateEndpointGroupSettings(id int64, body map[string]any) error {
+	// Use raw HTTP because the SDK payload drops empty access policies (omitempty), which
+	// makes it impossible to remove the last user or team of a group.
+	op := &runtime.ClientOperation{
+		ID:                 "EndpointGroupUpdate",
+		Method:             "PUT",
+		PathPattern:        "/endpoint_groups/{id}",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{a.scheme},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+			if err := req.SetPathParam("id", strconv.FormatInt(id, 10)); err != nil {
+				return err
+			}
+			return req.SetBodyParam(body)
+		}),
+		AuthInfo: a.httpTransport.DefaultAuthentication,
+		Reader: runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (any, error) {
+			if resp.Code() >= 300 {
+				msg, _ := io.ReadAll(resp.Body())
+				return nil, fmt.Errorf("unexpected status %d: %s", resp.Code(), strings.TrimSpace(string(msg)))
+			}
+			return nil, nil
+		}),
+	}
+	if _, err := a.httpTransport.Submit(op); err != nil {
+		return fmt.Errorf("failed to update endpoint group: %w", err)
+	}
+	return nil
+}
+
 // SnapshotEndpoint triggers a snapshot for a single endpoint.
 func (a *portainerAPIAdapter) SnapshotEndpoint(id int64) error {
 	params := endpoints.NewEndpointSnapshotParams().WithID(id)
@@ -237,6 +453,16 @@ func (a *portainerAPIAdapter) DeleteWebhook(id int64) error {
 	return nil
 }
 
+// ExecuteWebhook triggers a webhook by token using the low-level Swagger client.
+func (a *portainerAPIAdapter) ExecuteWebhook(token string) error {
+	params := webhooks.NewPostWebhooksIDParams().WithID(token)
+	_, err := a.swagger.Webhooks.PostWebhooksID(params)
+	if err != nil {
+		return fmt.Errorf("failed to execute webhook: %w", err)
+	}
+	return nil
+}
+
 // ListCustomTemplates lists all custom templates.
 func (a *portainerAPIAdapter) ListCustomTemplates() ([]*apimodels.PortainereeCustomTemplate, error) {
 	params := custom_templates.NewCustomTemplateListParams()
@@ -480,11 +706,30 @@ func (a *portainerAPIAdapter) DeleteEdgeJob(id int64) error {
 	return nil
 }
 
-// UpdateSettings updates the Portainer settings using the provided payload.
-func (a *portainerAPIAdapter) UpdateSettings(payload *apimodels.SettingsSettingsUpdatePayload) error {
-	params := settings.NewSettingsUpdateParams().WithBody(payload)
-	_, err := a.swagger.Settings.SettingsUpdate(params, nil)
-	if err != nil {
+// UpdateSettings updates the Portainer settings with a partial JSON body.
+func (a *portainerAPIAdapter) UpdateSettings(body map[string]any) error {
+	// Use raw HTTP because the SDK payload drops false and zero values (omitempty),
+	// which makes it impossible to turn a setting off.
+	op := &runtime.ClientOperation{
+		ID:                 "SettingsUpdate",
+		Method:             "PUT",
+		PathPattern:        "/settings",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{a.scheme},
+		Params: runtime.ClientRequestWriterFunc(func(req runtime.ClientRequest, reg strfmt.Registry) error {
+			return req.SetBodyParam(body)
+		}),
+		AuthInfo: a.httpTransport.DefaultAuthentication,
+		Reader: runtime.ClientResponseReaderFunc(func(resp runtime.ClientResponse, consumer runtime.Consumer) (any, error) {
+			if resp.Code() >= 300 {
+				msg, _ := io.ReadAll(resp.Body())
+				return nil, fmt.Errorf("unexpected status %d: %s", resp.Code(), strings.TrimSpace(string(msg)))
+			}
+			return nil, nil
+		}),
+	}
+	if _, err := a.httpTransport.Submit(op); err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
 	}
 	return nil
@@ -550,6 +795,16 @@ func (a *portainerAPIAdapter) ListEdgeUpdateSchedules() ([]*apimodels.Edgeupdate
 	return resp.Payload, nil
 }
 
+// CreateEdgeUpdateSchedule creates an edge update schedule.
+func (a *portainerAPIAdapter) CreateEdgeUpdateSchedule(payload *apimodels.EdgeupdateschedulesCreatePayload) (int64, error) {
+	params := edge_update_schedules.NewEdgeUpdateScheduleCreateParams().WithBody(payload)
+	resp, err := a.swagger.EdgeUpdateSchedules.EdgeUpdateScheduleCreate(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create edge update schedule: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
 // AuthenticateUser authenticates a user using the Swagger client.
 func (a *portainerAPIAdapter) AuthenticateUser(username, password string) (*apimodels.AuthAuthenticateResponse, error) {
 	params := auth.NewAuthenticateUserParams()
@@ -672,6 +927,19 @@ func (a *portainerAPIAdapter) GetHelmReleaseHistory(environmentId int64, name st
 	return resp.Payload, nil
 }
 
+// GetHelmRelease gets a helm release with its deployment information.
+func (a *portainerAPIAdapter) GetHelmRelease(environmentId int64, name string, namespace *string) (*apimodels.ReleaseRelease, error) {
+	params := helm.NewHelmGetParams().WithID(environmentId).WithName(name)
+	if namespace != nil {
+		params = params.WithNamespace(namespace)
+	}
+	resp, err := a.swagger.Helm.HelmGet(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get helm release: %w", err)
+	}
+	return resp.Payload, nil
+}
+
 // GetDockerDashboard retrieves the Docker dashboard data for a specific environment.
 // Uses raw HTTP GET because the SDK sends POST but newer Portainer versions require GET.
 func (a *portainerAPIAdapter) GetDockerDashboard(environmentId int64) (*apimodels.DockerDashboardResponse, error) {
@@ -842,3 +1110,47 @@ func (a *portainerAPIAdapter) StackMigrate(id int64, endpointID int64, body *api
 	}
 	return resp.Payload, nil
 }
+
+// StackCreateStandalone creates a Docker Compose (standalone) stack from file content.
+func (a *portainerAPIAdapter) StackCreateStandalone(endpointID int64, body *apimodels.StacksComposeStackFromFileContentPayload) (*apimodels.PortainereeStack, error) {
+	params := stacks.NewStackCreateDockerStandaloneStringParams().WithEndpointID(endpointID).WithBody(body)
+	resp, err := a.swagger.Stacks.StackCreateDockerStandaloneString(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stack: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// StackUpdate updates the file content and environment variables of a stack and redeploys it.
+func (a *portainerAPIAdapter) StackUpdate(id int64, endpointID int64, body *apimodels.StacksUpdateStackPayload) (*apimodels.PortainereeStack, error) {
+	params := stacks.NewStackUpdateParams().WithID(id).WithEndpointID(endpointID).WithBody(body)
+	resp, err := a.swagger.Stacks.StackUpdate(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update stack: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// EdgeStackCreateString creates an edge stack from file content, with the options of the
+// payload that the SDK's high-level client does not set, such as environment variables.
+func (a *portainerAPIAdapter) EdgeStackCreateString(body *apimodels.EdgestacksEdgeStackFromStringPayload) (int64, error) {
+	params := edge_stacks.NewEdgeStackCreateStringParams().WithBody(body)
+	resp, err := a.swagger.EdgeStacks.EdgeStackCreateString(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create edge stack: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// GitRepoFilePreview retrieves the content of a file from a git repository at a given reference.
+func (a *portainerAPIAdapter) GitRepoFilePreview(body *apimodels.GitopsRepositoryFilePreviewPayload) (string, error) {
+	params := gitops.NewGitOperationRepoFilePreviewParams().WithBody(body)
+	resp, err := a.swagger.Gitops.GitOperationRepoFilePreview(params, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to preview git repository file: %w", err)
+	}
+	if resp.Payload == nil {
+		return "", nil
+	}
+	return resp.Payload.FileContent, nil
+}
@@ -0,0 +1,365 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/portainer/client-api-go/v2/client/utils"
+	"github.com/portainer/client-api-go/v2/pkg/client/edge_groups"
+	"github.com/portainer/client-api-go/v2/pkg/client/edge_stacks"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoint_groups"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
+	"github.com/portainer/client-api-go/v2/pkg/client/settings"
+	"github.com/portainer/client-api-go/v2/pkg/client/system"
+	"github.com/portainer/client-api-go/v2/pkg/client/tags"
+	"github.com/portainer/client-api-go/v2/pkg/client/team_memberships"
+	"github.com/portainer/client-api-go/v2/pkg/client/teams"
+	"github.com/portainer/client-api-go/v2/pkg/client/users"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// The operations below mirror the SDK's high-level client. They are implemented over
+// the adapter's Swagger client because the SDK builds its own HTTP transport, which
+// cannot be configured with the adapter's timeout or instrumentation.
+
+// ListEdgeGroups lists all edge groups.
+func (a *portainerAPIAdapter) ListEdgeGroups() ([]*apimodels.EdgegroupsDecoratedEdgeGroup, error) {
+	resp, err := a.swagger.EdgeGroups.EdgeGroupList(edge_groups.NewEdgeGroupListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge groups: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateEdgeGroup creates a static edge group with the given environments.
+func (a *portainerAPIAdapter) CreateEdgeGroup(name string, environmentIds []int64) (int64, error) {
+	params := edge_groups.NewEdgeGroupCreateParams().WithBody(&apimodels.EdgegroupsEdgeGroupCreatePayload{
+		Name:      name,
+		Endpoints: environmentIds,
+	})
+	resp, err := a.swagger.EdgeGroups.EdgeGroupCreate(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create edge group: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// UpdateEdgeGroup updates an edge group. Nil arguments keep the current value; setting
+// tags makes the group dynamic.
+func (a *portainerAPIAdapter) UpdateEdgeGroup(id int64, name *string, environmentIds *[]int64, tagIds *[]int64) error {
+	params := edge_groups.NewEdgeGroupUpdateParams().WithID(id).WithBody(&apimodels.EdgegroupsEdgeGroupUpdatePayload{})
+	if name != nil {
+		params.Body.Name = *name
+	}
+	if environmentIds != nil {
+		params.Body.Endpoints = *environmentIds
+	}
+	if tagIds != nil {
+		params.Body.TagIDs = *tagIds
+		params.Body.Dynamic = true
+	}
+	if _, err := a.swagger.EdgeGroups.EdgeGroupUpdate(params, nil); err != nil {
+		return fmt.Errorf("failed to update edge group: %w", err)
+	}
+	return nil
+}
+
+// ListEdgeStacks lists all edge stacks.
+func (a *portainerAPIAdapter) ListEdgeStacks() ([]*apimodels.PortainereeEdgeStack, error) {
+	resp, err := a.swagger.EdgeStacks.EdgeStackList(edge_stacks.NewEdgeStackListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list edge stacks: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// GetEdgeStack retrieves an edge stack by ID.
+func (a *portainerAPIAdapter) GetEdgeStack(id int64) (*apimodels.PortainereeEdgeStack, error) {
+	resp, err := a.swagger.EdgeStacks.EdgeStackInspect(edge_stacks.NewEdgeStackInspectParams().WithID(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get edge stack: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateEdgeStack creates an edge stack from a compose file, deployed to edge groups.
+func (a *portainerAPIAdapter) CreateEdgeStack(name string, file string, environmentGroupIds []int64) (int64, error) {
+	params := edge_stacks.NewEdgeStackCreateStringParams().WithBody(&apimodels.EdgestacksEdgeStackFromStringPayload{
+		Name:             &name,
+		StackFileContent: &file,
+		EdgeGroups:       environmentGroupIds,
+	})
+	resp, err := a.swagger.EdgeStacks.EdgeStackCreateString(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create edge stack: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// UpdateEdgeStack replaces the compose file and the edge groups of an edge stack.
+func (a *portainerAPIAdapter) UpdateEdgeStack(id int64, file string, environmentGroupIds []int64) error {
+	params := edge_stacks.NewEdgeStackUpdateParams().WithID(id).WithBody(&apimodels.EdgestacksUpdateEdgeStackPayload{
+		StackFileContent: file,
+		EdgeGroups:       environmentGroupIds,
+		UpdateVersion:    true,
+	})
+	if _, err := a.swagger.EdgeStacks.EdgeStackUpdate(params, nil); err != nil {
+		return fmt.Errorf("failed to update edge stack: %w", err)
+	}
+	return nil
+}
+
+// GetEdgeStackFile retrieves the compose file of an edge stack.
+func (a *portainerAPIAdapter) GetEdgeStackFile(id int64) (string, error) {
+	resp, err := a.swagger.EdgeStacks.EdgeStackFile(edge_stacks.NewEdgeStackFileParams().WithID(id), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get edge stack file: %w", err)
+	}
+	return resp.Payload.StackFileContent, nil
+}
+
+// ListEndpointGroups lists all environment groups.
+func (a *portainerAPIAdapter) ListEndpointGroups() ([]*apimodels.PortainerEndpointGroup, error) {
+	resp, err := a.swagger.EndpointGroups.EndpointGroupList(endpoint_groups.NewEndpointGroupListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint groups: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateEndpointGroup creates an environment group with the given environments.
+func (a *portainerAPIAdapter) CreateEndpointGroup(name string, associatedEndpoints []int64) (int64, error) {
+	params := endpoint_groups.NewPostEndpointGroupsParams().WithBody(&apimodels.EndpointgroupsEndpointGroupCreatePayload{
+		Name:                &name,
+		AssociatedEndpoints: associatedEndpoints,
+	})
+	resp, err := a.swagger.EndpointGroups.PostEndpointGroups(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create endpoint group: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// UpdateEndpointGroup updates an environment group. Nil arguments keep the current
+// value; the access maps go from user or team ID to role name.
+func (a *portainerAPIAdapter) UpdateEndpointGroup(id int64, name *string, userAccesses *map[int64]string, teamAccesses *map[int64]string) error {
+	params := endpoint_groups.NewEndpointGroupUpdateParams().WithID(id).WithBody(&apimodels.EndpointgroupsEndpointGroupUpdatePayload{})
+	if name != nil {
+		params.Body.Name = *name
+	}
+	if userAccesses != nil {
+		params.Body.UserAccessPolicies = utils.BuildAccessPolicies[apimodels.PortainerUserAccessPolicies](*userAccesses)
+	}
+	if teamAccesses != nil {
+		params.Body.TeamAccessPolicies = utils.BuildAccessPolicies[apimodels.PortainerTeamAccessPolicies](*teamAccesses)
+	}
+	if _, err := a.swagger.EndpointGroups.EndpointGroupUpdate(params, nil); err != nil {
+		return fmt.Errorf("failed to update endpoint group: %w", err)
+	}
+	return nil
+}
+
+// AddEnvironmentToEndpointGroup adds an environment to an environment group.
+func (a *portainerAPIAdapter) AddEnvironmentToEndpointGroup(groupId int64, environmentId int64) error {
+	params := endpoint_groups.NewEndpointGroupAddEndpointParams().WithID(groupId).WithEndpointID(environmentId)
+	if _, err := a.swagger.EndpointGroups.EndpointGroupAddEndpoint(params, nil); err != nil {
+		return fmt.Errorf("failed to add environment to endpoint group: %w", err)
+	}
+	return nil
+}
+
+// RemoveEnvironmentFromEndpointGroup removes an environment from an environment group.
+func (a *portainerAPIAdapter) RemoveEnvironmentFromEndpointGroup(groupId int64, environmentId int64) error {
+	params := endpoint_groups.NewEndpointGroupDeleteEndpointParams().WithID(groupId).WithEndpointID(environmentId)
+	if _, err := a.swagger.EndpointGroups.EndpointGroupDeleteEndpoint(params, nil); err != nil {
+		return fmt.Errorf("failed to remove environment from endpoint group: %w", err)
+	}
+	return nil
+}
+
+// ListEndpoints lists the environments matching the query parameters, or all of them
+// when params is nil.
+func (a *portainerAPIAdapter) ListEndpoints(params *endpoints.EndpointListParams) ([]*apimodels.PortainereeEndpoint, error) {
+	if params == nil {
+		params = endpoints.NewEndpointListParams()
+	}
+	resp, err := a.swagger.Endpoints.EndpointList(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// GetEndpoint retrieves an environment by ID.
+func (a *portainerAPIAdapter) GetEndpoint(id int64) (*apimodels.PortainereeEndpoint, error) {
+	resp, err := a.swagger.Endpoints.EndpointInspect(endpoints.NewEndpointInspectParams().WithID(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoint: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// UpdateEndpoint updates the tags and the access policies of an environment. Nil
+// arguments keep the current value; the access maps go from user or team ID to role name.
+func (a *portainerAPIAdapter) UpdateEndpoint(id int64, tagIds *[]int64, userAccesses *map[int64]string, teamAccesses *map[int64]string) error {
+	params := endpoints.NewEndpointUpdateParams().WithID(id).WithBody(&apimodels.EndpointsEndpointUpdatePayload{})
+	if tagIds != nil {
+		params.Body.TagIDs = *tagIds
+	}
+	if userAccesses != nil {
+		params.Body.UserAccessPolicies = utils.BuildAccessPolicies[apimodels.PortainerUserAccessPolicies](*userAccesses)
+	}
+	if teamAccesses != nil {
+		params.Body.TeamAccessPolicies = utils.BuildAccessPolicies[apimodels.PortainerTeamAccessPolicies](*teamAccesses)
+	}
+	_, err := a.swagger.Endpoints.EndpointUpdate(params, nil)
+	return err
+}
+
+// GetSettings retrieves the Portainer settings.
+func (a *portainerAPIAdapter) GetSettings() (*apimodels.PortainereeSettings, error) {
+	resp, err := a.swagger.Settings.SettingsInspect(settings.NewSettingsInspectParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// GetSystemStatus retrieves the status of the Portainer server.
+func (a *portainerAPIAdapter) GetSystemStatus() (*apimodels.GithubComPortainerPortainerEeAPIHTTPHandlerSystemStatus, error) {
+	resp, err := a.swagger.System.SystemStatus(system.NewSystemStatusParams())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system status: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// GetVersion retrieves the version of the Portainer server.
+func (a *portainerAPIAdapter) GetVersion() (string, error) {
+	resp, err := a.swagger.System.SystemStatus(system.NewSystemStatusParams())
+	if err != nil {
+		return "", fmt.Errorf("failed to get version: %w", err)
+	}
+	return resp.Payload.Version, nil
+}
+
+// ListTags lists all tags.
+func (a *portainerAPIAdapter) ListTags() ([]*apimodels.PortainerTag, error) {
+	resp, err := a.swagger.Tags.TagList(tags.NewTagListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateTag creates a tag.
+func (a *portainerAPIAdapter) CreateTag(name string) (int64, error) {
+	params := tags.NewTagCreateParams().WithBody(&apimodels.TagsTagCreatePayload{Name: &name})
+	resp, err := a.swagger.Tags.TagCreate(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// ListTeams lists all teams.
+func (a *portainerAPIAdapter) ListTeams() ([]*apimodels.PortainerTeam, error) {
+	resp, err := a.swagger.Teams.TeamList(teams.NewTeamListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// GetTeam retrieves a team by ID.
+func (a *portainerAPIAdapter) GetTeam(id int64) (*apimodels.PortainerTeam, error) {
+	resp, err := a.swagger.Teams.TeamInspect(teams.NewTeamInspectParams().WithID(id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateTeam creates a team.
+func (a *portainerAPIAdapter) CreateTeam(name string) (int64, error) {
+	params := teams.NewTeamCreateParams().WithBody(&apimodels.TeamsTeamCreatePayload{Name: &name})
+	resp, err := a.swagger.Teams.TeamCreate(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create team: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// UpdateTeamName renames a team.
+func (a *portainerAPIAdapter) UpdateTeamName(id int, name string) error {
+	params := teams.NewTeamUpdateParams().WithID(int64(id)).WithBody(&apimodels.TeamsTeamUpdatePayload{Name: name})
+	_, err := a.swagger.Teams.TeamUpdate(params, nil)
+	return err
+}
+
+// ListTeamMemberships lists the memberships of all teams.
+func (a *portainerAPIAdapter) ListTeamMemberships() ([]*apimodels.PortainerTeamMembership, error) {
+	resp, err := a.swagger.TeamMemberships.TeamMembershipList(team_memberships.NewTeamMembershipListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list team memberships: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateTeamMembership adds a user to a team as a regular member.
+func (a *portainerAPIAdapter) CreateTeamMembership(teamId int, userId int) error {
+	teamID, userID := int64(teamId), int64(userId)
+	role := int64(2) // team member
+	params := team_memberships.NewTeamMembershipCreateParams().WithBody(&apimodels.TeammembershipsTeamMembershipCreatePayload{
+		Role:   &role,
+		TeamID: &teamID,
+		UserID: &userID,
+	})
+	_, err := a.swagger.TeamMemberships.TeamMembershipCreate(params, nil)
+	return err
+}
+
+// DeleteTeamMembership deletes a team membership by ID.
+func (a *portainerAPIAdapter) DeleteTeamMembership(id int) error {
+	params := team_memberships.NewTeamMembershipDeleteParams().WithID(int64(id))
+	_, err := a.swagger.TeamMemberships.TeamMembershipDelete(params, nil)
+	return err
+}
+
+// ListUsers lists all users.
+func (a *portainerAPIAdapter) ListUsers() ([]*apimodels.PortainereeUser, error) {
+	resp, err := a.swagger.Users.UserList(users.NewUserListParams(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// GetUser retrieves a user by ID.
+func (a *portainerAPIAdapter) GetUser(id int) (*apimodels.PortainereeUser, error) {
+	resp, err := a.swagger.Users.UserInspect(users.NewUserInspectParams().WithID(int64(id)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return resp.Payload, nil
+}
+
+// CreateUser creates a user with the given role.
+func (a *portainerAPIAdapter) CreateUser(username, password string, role int64) (int64, error) {
+	params := users.NewUserCreateParams().WithBody(&apimodels.UsersUserCreatePayload{
+		Username: &username,
+		Password: &password,
+		Role:     &role,
+	})
+	resp, err := a.swagger.Users.UserCreate(params, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %w", err)
+	}
+	return resp.Payload.ID, nil
+}
+
+// UpdateUserRole changes the role of a user.
+func (a *portainerAPIAdapter) UpdateUserRole(id int, role int64) error {
+	params := users.NewUserUpdateParams().WithID(int64(id)).WithBody(&apimodels.UsersUserUpdatePayload{Role: &role})
+	_, err := a.swagger.Users.UserUpdate(params, nil)
+	return err
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdapterSDKOperations verifies the method and path of the operations mirrored from
+// the SDK's high-level client, and that transport errors are returned.
+func TestAdapterSDKOperations(t *testing.T) {
+	name := "web"
+	ids := []int64{1}
+	accesses := map[int64]string{1: "standard_user"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		call   func(a *portainerAPIAdapter) error
+	}{
+		{"ListEdgeGroups", "GET", "/api/edge_groups", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListEdgeGroups(); return err }},
+		{"CreateEdgeGroup", "POST", "/api/edge_groups", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.CreateEdgeGroup(name, ids); return err }},
+		{"UpdateEdgeGroup", "PUT", "/api/edge_groups/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.UpdateEdgeGroup(1, &name, &ids, &ids) }},
+		{"ListEdgeStacks", "GET", "/api/edge_stacks", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListEdgeStacks(); return err }},
+		{"GetEdgeStack", "GET", "/api/edge_stacks/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.GetEdgeStack(1); return err }},
+		{"CreateEdgeStack", "POST", "/api/edge_stacks/create/string", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.CreateEdgeStack(name, "services: {}", ids); return err }},
+		{"UpdateEdgeStack", "PUT", "/api/edge_stacks/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.UpdateEdgeStack(1, "services: {}", ids) }},
+		{"GetEdgeStackFile", "GET", "/api/edge_stacks/1/file", `{"StackFileContent":"x"}`, func(a *portainerAPIAdapter) error { _, err := a.GetEdgeStackFile(1); return err }},
+		{"ListEndpointGroups", "GET", "/api/endpoint_groups", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListEndpointGroups(); return err }},
+		{"CreateEndpointGroup", "POST", "/api/endpoint_groups", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.CreateEndpointGroup(name, ids); return err }},
+		{"UpdateEndpointGroup", "PUT", "/api/endpoint_groups/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.UpdateEndpointGroup(1, &name, &accesses, &accesses) }},
+		{"AddEnvironmentToEndpointGroup", "PUT", "/api/endpoint_groups/1/endpoints/2", ``, func(a *portainerAPIAdapter) error { return a.AddEnvironmentToEndpointGroup(1, 2) }},
+		{"RemoveEnvironmentFromEndpointGroup", "DELETE", "/api/endpoint_groups/1/endpoints/2", ``, func(a *portainerAPIAdapter) error { return a.RemoveEnvironmentFromEndpointGroup(1, 2) }},
+		{"ListEndpoints", "GET", "/api/endpoints", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListEndpoints(nil); return err }},
+		{"GetEndpoint", "GET", "/api/endpoints/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.GetEndpoint(1); return err }},
+		{"UpdateEndpoint", "PUT", "/api/endpoints/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.UpdateEndpoint(1, &ids, &accesses, &accesses) }},
+		{"GetSettings", "GET", "/api/settings", `{}`, func(a *portainerAPIAdapter) error { _, err := a.GetSettings(); return err }},
+		{"GetSystemStatus", "GET", "/api/system/status", `{"Version":"2.31.2"}`, func(a *portainerAPIAdapter) error { _, err := a.GetSystemStatus(); return err }},
+		{"GetVersion", "GET", "/api/system/status", `{"Version":"2.31.2"}`, func(a *portainerAPIAdapter) error { _, err := a.GetVersion(); return err }},
+		{"ListTags", "GET", "/api/tags", `[{"ID":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListTags(); return err }},
+		{"CreateTag", "POST", "/api/tags", `{"ID":1}`, func(a *portainerAPIAdapter) error { _, err := a.CreateTag(name); return err }},
+		{"ListTeams", "GET", "/api/teams", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListTeams(); return err }},
+		{"GetTeam", "GET", "/api/teams/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.GetTeam(1); return err }},
+		{"CreateTeam", "POST", "/api/teams", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.CreateTeam(name); return err }},
+		{"UpdateTeamName", "PUT", "/api/teams/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.UpdateTeamName(1, name) }},
+		{"ListTeamMemberships", "GET", "/api/team_memberships", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListTeamMemberships(); return err }},
+		{"CreateTeamMembership", "POST", "/api/team_memberships", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.CreateTeamMembership(1, 2) }},
+		{"DeleteTeamMembership", "DELETE", "/api/team_memberships/1", ``, func(a *portainerAPIAdapter) error { return a.DeleteTeamMembership(1) }},
+		{"ListUsers", "GET", "/api/users", `[{"Id":1}]`, func(a *portainerAPIAdapter) error { _, err := a.ListUsers(); return err }},
+		{"GetUser", "GET", "/api/users/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.GetUser(1); return err }},
+		{"CreateUser", "POST", "/api/users", `{"Id":1}`, func(a *portainerAPIAdapter) error { _, err := a.CreateUser(name, "secret", 2); return err }},
+		{"UpdateUserRole", "PUT", "/api/users/1", `{"Id":1}`, func(a *portainerAPIAdapter) error { return a.UpdateUserRole(1, 1) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := http.StatusOK
+			if tt.body == "" {
+				status = http.StatusNoContent
+			}
+			rt := &mockRoundTripper{statusCode: status, body: tt.body}
+			require.NoError(t, tt.call(newTestAdapter(rt)))
+			assert.Equal(t, tt.method, rt.lastReq.Method)
+			assert.Equal(t, tt.path, rt.lastReq.URL.Path)
+
+			assert.Error(t, tt.call(newTestAdapter(&mockRoundTripper{err: errTransport})))
+		})
+	}
+}
+
+// TestAdapterUpdateEndpointGroupBody verifies the access policies sent by
+// UpdateEndpointGroup.
+func TestAdapterUpdateEndpointGroupBody(t *testing.T) {
+	rt := &mockRoundTripper{statusCode: http.StatusOK, body: `{"Id":1}`}
+	accesses := map[int64]string{3: "environment_administrator"}
+	require.NoError(t, newTestAdapter(rt).UpdateEndpointGroup(1, nil, &accesses, nil))
+
+	data, err := io.ReadAll(rt.lastReq.Body)
+	require.NoError(t, err)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, map[string]any{"3": map[string]any{"RoleId": float64(1)}}, body["userAccessPolicies"])
+	assert.NotContains(t, body, "teamAccessPolicies")
+}
@@ -96,14 +96,14 @@ func TestNewHTTPTransport(t *testing.T) {
 
 func TestNewPortainerAPIAdapter(t *testing.T) {
 	t.Run("https host", func(t *testing.T) {
-		a := newPortainerAPIAdapter("portainer.example.com", "test-key", false)
+		a := newPortainerAPIAdapter("portainer.example.com", "test-key", false, nil)
 		require.NotNil(t, a)
 		assert.NotNil(t, a.swagger)
 		assert.NotNil(t, a.httpTransport)
-		assert.NotNil(t, a.PortainerClient)
+		assert.NotNil(t, a.proxyClient)
 	})
 	t.Run("http host", func(t *testing.T) {
-		a := newPortainerAPIAdapter("http://portainer.local", "test-key", true)
+		a := newPortainerAPIAdapter("http://portainer.local", "test-key", true, nil)
 		require.NotNil(t, a)
 		assert.NotNil(t, a.swagger)
 	})
@@ -163,6 +163,86 @@ func TestAdapterDeleteUser(t *testing.T) {
 	})
 }
 
+func TestAdapterGetCurrentUser(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `{"Id":3,"Username":"operator","Role":2}`}
+		a := newTestAdapter(rt)
+		result, err := a.GetCurrentUser()
+		assert.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, "operator", result.Username)
+		assert.Equal(t, "/api/users/me", rt.lastReq.URL.Path)
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		result, err := a.GetCurrentUser()
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "failed to get current user")
+	})
+}
+
+func TestAdapterDeleteUserAPIKey(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 204, body: ""}
+		a := newTestAdapter(rt)
+		err := a.DeleteUserAPIKey(3, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, rt.lastReq.Method)
+		assert.Equal(t, "/api/users/3/tokens/7", rt.lastReq.URL.Path)
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		err := a.DeleteUserAPIKey(3, 7)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to delete user API key")
+	})
+}
+
+func TestAdapterUpdateResourceControl(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `{"Id":5}`}
+		a := newTestAdapter(rt)
+		err := a.UpdateResourceControl(5, &apimodels.ResourcecontrolsResourceControlUpdatePayload{Users: []int64{4}, Teams: []int64{}})
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPut, rt.lastReq.Method)
+		assert.Equal(t, "/api/resource_controls/5", rt.lastReq.URL.Path)
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		err := a.UpdateResourceControl(5, &apimodels.ResourcecontrolsResourceControlUpdatePayload{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update resource control")
+	})
+}
+
+func TestAdapterListUserMemberships(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `[{"Id":1,"TeamID":2,"UserID":3,"Role":1}]`}
+		a := newTestAdapter(rt)
+		result, err := a.ListUserMemberships(3)
+		assert.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, int64(2), result[0].TeamID)
+		assert.Equal(t, int64(1), result[0].Role)
+		assert.Equal(t, "/api/users/3/memberships", rt.lastReq.URL.Path)
+	})
+	t.Run("API error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{statusCode: 403, body: `{"message":"Access denied"}`})
+		result, err := a.ListUserMemberships(3)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "unexpected status 403")
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		result, err := a.ListUserMemberships(3)
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "failed to list user memberships")
+	})
+}
+
 // ---------------------------------------------------------------------------
 // Endpoint operations
 // ---------------------------------------------------------------------------
@@ -181,6 +261,79 @@ func TestAdapterDeleteEndpoint(t *testing.T) {
 	})
 }
 
+func TestAdapterUpdateEndpointSettings(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `{}`}
+		a := newTestAdapter(rt)
+		err := a.UpdateEndpointSettings(7, map[string]any{"edgeCheckinInterval": 0})
+		assert.NoError(t, err)
+		require.NotNil(t, rt.lastReq)
+		assert.Equal(t, http.MethodPut, rt.lastReq.Method)
+		assert.Equal(t, "/api/endpoints/7", rt.lastReq.URL.Path)
+		body, err := io.ReadAll(rt.lastReq.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"edgeCheckinInterval":0}`, string(body), "zero values must be sent")
+	})
+	t.Run("API error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{statusCode: 404, body: `{"message":"not found"}`})
+		err := a.UpdateEndpointSettings(7, map[string]any{})
+		assert.ErrorContains(t, err, "404")
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		err := a.UpdateEndpointSettings(7, map[string]any{})
+		assert.Error(t, err)
+	})
+}
+
+func TestAdapterUpdateEndpointGroupSettings(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `{}`}
+		a := newTestAdapter(rt)
+		err := a.UpdateEndpointGroupSettings(4, map[string]any{"teamAccessPolicies": map[string]any{}})
+		assert.NoError(t, err)
+		require.NotNil(t, rt.lastReq)
+		assert.Equal(t, http.MethodPut, rt.lastReq.Method)
+		assert.Equal(t, "/api/endpoint_groups/4", rt.lastReq.URL.Path)
+		body, err := io.ReadAll(rt.lastReq.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"teamAccessPolicies":{}}`, string(body), "empty policies must be sent")
+	})
+	t.Run("API error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{statusCode: 404, body: `{"message":"not found"}`})
+		err := a.UpdateEndpointGroupSettings(4, map[string]any{})
+		assert.ErrorContains(t, err, "404")
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		err := a.UpdateEndpointGroupSettings(4, map[string]any{})
+		assert.Error(t, err)
+	})
+}
+
+func TestAdapterGetResponseSize(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `[{"Id":1},{"Id":2}]`}
+		a := newTestAdapter(rt)
+		size, err := a.GetResponseSize("/endpoints")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(`[{"Id":1},{"Id":2}]`)), size)
+		require.NotNil(t, rt.lastReq)
+		assert.Equal(t, http.MethodGet, rt.lastReq.Method)
+		assert.Equal(t, "/api/endpoints", rt.lastReq.URL.Path)
+	})
+	t.Run("API error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{statusCode: 403, body: `{"message":"forbidden"}`})
+		_, err := a.GetResponseSize("/settings")
+		assert.ErrorContains(t, err, "403")
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		_, err := a.GetResponseSize("/settings")
+		assert.Error(t, err)
+	})
+}
+
 func TestAdapterSnapshotEndpoint(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		a := newTestAdapter(&mockRoundTripper{statusCode: 204, body: ""})
@@ -259,6 +412,23 @@ func TestAdapterDeleteWebhook(t *testing.T) {
 	})
 }
 
+func TestAdapterExecuteWebhook(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 202, body: ""}
+		a := newTestAdapter(rt)
+		err := a.ExecuteWebhook("token-1")
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPost, rt.lastReq.Method)
+		assert.True(t, strings.HasSuffix(rt.lastReq.URL.Path, "/webhooks/token-1"))
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		err := a.ExecuteWebhook("token-1")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to execute webhook")
+	})
+}
+
 // ---------------------------------------------------------------------------
 // Custom Template operations
 // ---------------------------------------------------------------------------
@@ -613,13 +783,25 @@ func TestAdapterDeleteEdgeJob(t *testing.T) {
 
 func TestAdapterUpdateSettingsAdapter(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		a := newTestAdapter(&mockRoundTripper{statusCode: 200, body: `{}`})
-		err := a.UpdateSettings(&apimodels.SettingsSettingsUpdatePayload{})
-		assert.NoError(t, err)
+		rt := &mockRoundTripper{statusCode: 200, body: `{}`}
+		a := newTestAdapter(rt)
+		err := a.UpdateSettings(map[string]any{"enableTelemetry": false})
+		assert.NoError(t, err)
+		require.NotNil(t, rt.lastReq)
+		assert.Equal(t, http.MethodPut, rt.lastReq.Method)
+		assert.Equal(t, "/api/settings", rt.lastReq.URL.Path)
+		body, err := io.ReadAll(rt.lastReq.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"enableTelemetry":false}`, string(body), "false values must be sent")
+	})
+	t.Run("API error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{statusCode: 400, body: `{"message":"invalid"}`})
+		err := a.UpdateSettings(map[string]any{})
+		assert.ErrorContains(t, err, "400")
 	})
 	t.Run("transport error", func(t *testing.T) {
 		a := newTestAdapter(&mockRoundTripper{err: errTransport})
-		err := a.UpdateSettings(&apimodels.SettingsSettingsUpdatePayload{})
+		err := a.UpdateSettings(map[string]any{})
 		assert.Error(t, err)
 	})
 }
@@ -724,6 +906,21 @@ func TestAdapterListEdgeUpdateSchedules(t *testing.T) {
 	})
 }
 
+func TestAdapterCreateEdgeUpdateSchedule(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{statusCode: 200, body: `{"id":4}`})
+		id, err := a.CreateEdgeUpdateSchedule(&apimodels.EdgeupdateschedulesCreatePayload{GroupIDs: []int64{1}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), id)
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		id, err := a.CreateEdgeUpdateSchedule(&apimodels.EdgeupdateschedulesCreatePayload{GroupIDs: []int64{1}})
+		assert.Error(t, err)
+		assert.Equal(t, int64(0), id)
+	})
+}
+
 // ---------------------------------------------------------------------------
 // Auth operations
 // ---------------------------------------------------------------------------
@@ -1128,3 +1325,21 @@ func TestAdapterStackMigrate(t *testing.T) {
 		assert.Nil(t, result)
 	})
 }
+
+func TestAdapterEdgeStackCreateString(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		rt := &mockRoundTripper{statusCode: 200, body: `{"Id":9}`}
+		a := newTestAdapter(rt)
+		id, err := a.EdgeStackCreateString(&apimodels.EdgestacksEdgeStackFromStringPayload{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9), id)
+		assert.Equal(t, http.MethodPost, rt.lastReq.Method)
+		assert.Equal(t, "/api/edge_stacks/create/string", rt.lastReq.URL.Path)
+	})
+	t.Run("transport error", func(t *testing.T) {
+		a := newTestAdapter(&mockRoundTripper{err: errTransport})
+		_, err := a.EdgeStackCreateString(&apimodels.EdgestacksEdgeStackFromStringPayload{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create edge stack")
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/portainer/client-api-go/v2/client"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
@@ -18,6 +19,7 @@ type PortainerAPIClient interface {
 	CreateEdgeGroup(name string, environmentIds []int64) (int64, error)
 	UpdateEdgeGroup(id int64, name *string, environmentIds *[]int64, tagIds *[]int64) error
 	ListEdgeStacks() ([]*apimodels.PortainereeEdgeStack, error)
+	GetEdgeStack(id int64) (*apimodels.PortainereeEdgeStack, error)
 	ListRegularStacks() ([]*apimodels.PortainereeStack, error)
 	CreateEdgeStack(name string, file string, environmentGroupIds []int64) (int64, error)
 	UpdateEdgeStack(id int64, file string, environmentGroupIds []int64) error
@@ -25,16 +27,19 @@ type PortainerAPIClient interface {
 	ListEndpointGroups() ([]*apimodels.PortainerEndpointGroup, error)
 	CreateEndpointGroup(name string, associatedEndpoints []int64) (int64, error)
 	UpdateEndpointGroup(id int64, name *string, userAccesses *map[int64]string, teamAccesses *map[int64]string) error
+	UpdateEndpointGroupSettings(id int64, body map[string]any) error
 	AddEnvironmentToEndpointGroup(groupId int64, environmentId int64) error
 	RemoveEnvironmentFromEndpointGroup(groupId int64, environmentId int64) error
-	ListEndpoints() ([]*apimodels.PortainereeEndpoint, error)
+	ListEndpoints(params *endpoints.EndpointListParams) ([]*apimodels.PortainereeEndpoint, error)
 	GetEndpoint(id int64) (*apimodels.PortainereeEndpoint, error)
 	UpdateEndpoint(id int64, tagIds *[]int64, userAccesses *map[int64]string, teamAccesses *map[int64]string) error
+	CreateEndpoint(params *endpoints.EndpointCreateParams) (*apimodels.PortainereeEndpoint, error)
+	UpdateEndpointSettings(id int64, body map[string]any) error
 	DeleteEndpoint(id int64) error
 	SnapshotEndpoint(id int64) error
 	SnapshotAllEndpoints() error
 	GetSettings() (*apimodels.PortainereeSettings, error)
-	UpdateSettings(payload *apimodels.SettingsSettingsUpdatePayload) error
+	UpdateSettings(body map[string]any) error
 	GetPublicSettings() (*apimodels.SettingsPublicSettingsResponse, error)
 	GetSSLSettings() (*apimodels.PortainereeSSLSettings, error)
 	UpdateSSLSettings(payload *apimodels.SslSslUpdatePayload) error
@@ -55,14 +60,20 @@ type PortainerAPIClient interface {
 	CreateUser(username, password string, role int64) (int64, error)
 	GetUser(id int) (*apimodels.PortainereeUser, error)
 	DeleteUser(id int64) error
+	GetCurrentUser() (*apimodels.PortainereeUser, error)
+	ListUserMemberships(id int64) ([]*apimodels.PortainerTeamMembership, error)
+	ListUserAPIKeys(id int64) ([]*apimodels.PortainerAPIKey, error)
+	DeleteUserAPIKey(userID, keyID int64) error
 	UpdateUserRole(id int, role int64) error
 	GetVersion() (string, error)
 	GetSystemStatus() (*apimodels.GithubComPortainerPortainerEeAPIHTTPHandlerSystemStatus, error)
+	GetResponseSize(path string) (int64, error)
 	ListRegistries() ([]*apimodels.PortainereeRegistry, error)
 	GetRegistryByID(id int64) (*apimodels.PortainereeRegistry, error)
 	CreateRegistry(body *apimodels.RegistriesRegistryCreatePayload) (int64, error)
 	UpdateRegistry(id int64, body *apimodels.RegistriesRegistryUpdatePayload) error
 	DeleteRegistry(id int64) error
+	UpdateEndpointRegistryAccess(endpointID, registryID int64, body map[string]any) error
 	ProxyDockerRequest(environmentId int, opts client.ProxyRequestOptions) (*http.Response, error)
 	ProxyKubernetesRequest(environmentId int, opts client.ProxyRequestOptions) (*http.Response, error)
 	ListCustomTemplates() ([]*apimodels.PortainereeCustomTemplate, error)
@@ -70,6 +81,7 @@ type PortainerAPIClient interface {
 	GetCustomTemplateFile(id int64) (string, error)
 	CreateCustomTemplate(payload *apimodels.CustomtemplatesCustomTemplateFromFileContentPayload) (*apimodels.PortainereeCustomTemplate, error)
 	DeleteCustomTemplate(id int64) error
+	UpdateResourceControl(id int64, payload *apimodels.ResourcecontrolsResourceControlUpdatePayload) error
 	GetBackupStatus() (*apimodels.BackupBackupStatus, error)
 	GetBackupSettings() (*apimodels.PortainereeS3BackupSettings, error)
 	CreateBackup(password string) error
@@ -82,12 +94,14 @@ type PortainerAPIClient interface {
 	ListWebhooks() ([]*apimodels.PortainerWebhook, error)
 	CreateWebhook(resourceId string, endpointId int64, webhookType int64) (int64, error)
 	DeleteWebhook(id int64) error
+	ExecuteWebhook(token string) error
 	ListEdgeJobs() ([]*apimodels.PortainerEdgeJob, error)
 	GetEdgeJob(id int64) (*apimodels.PortainerEdgeJob, error)
 	GetEdgeJobFile(id int64) (string, error)
 	CreateEdgeJob(payload *apimodels.EdgejobsEdgeJobCreateFromFileContentPayload) (int64, error)
 	DeleteEdgeJob(id int64) error
 	ListEdgeUpdateSchedules() ([]*apimodels.EdgeupdateschedulesDecoratedUpdateSchedule, error)
+	CreateEdgeUpdateSchedule(payload *apimodels.EdgeupdateschedulesCreatePayload) (int64, error)
 	ListHelmRepositories(userId int64) (*apimodels.UsersHelmUserRepositoryResponse, error)
 	CreateHelmRepository(userId int64, url string) (*apimodels.PortainerHelmUserRepository, error)
 	DeleteHelmRepository(userId int64, repositoryId int64) error
@@ -96,6 +110,7 @@ type PortainerAPIClient interface {
 	ListHelmReleases(environmentId int64, namespace *string, filter *string, selector *string) ([]*apimodels.ReleaseReleaseElement, error)
 	DeleteHelmRelease(environmentId int64, release string, namespace *string) error
 	GetHelmReleaseHistory(environmentId int64, name string, namespace *string) ([]*apimodels.ReleaseRelease, error)
+	GetHelmRelease(environmentId int64, name string, namespace *string) (*apimodels.ReleaseRelease, error)
 	GetDockerDashboard(environmentId int64) (*apimodels.DockerDashboardResponse, error)
 	GetKubernetesDashboard(environmentId int64) (*apimodels.KubernetesK8sDashboard, error)
 	GetKubernetesNamespaces(environmentId int64) ([]*apimodels.PortainerK8sNamespaceInfo, error)
@@ -108,6 +123,10 @@ type PortainerAPIClient interface {
 	StackStart(id int64, endpointID int64) (*apimodels.PortainereeStack, error)
 	StackStop(id int64, endpointID int64) (*apimodels.PortainereeStack, error)
 	StackMigrate(id int64, endpointID int64, body *apimodels.StacksStackMigratePayload) (*apimodels.PortainereeStack, error)
+	StackCreateStandalone(endpointID int64, body *apimodels.StacksComposeStackFromFileContentPayload) (*apimodels.PortainereeStack, error)
+	StackUpdate(id int64, endpointID int64, body *apimodels.StacksUpdateStackPayload) (*apimodels.PortainereeStack, error)
+	EdgeStackCreateString(body *apimodels.EdgestacksEdgeStackFromStringPayload) (int64, error)
+	GitRepoFilePreview(body *apimodels.GitopsRepositoryFilePreviewPayload) (string, error)
 }
 
 // PortainerClient is a wrapper around the Portainer SDK client
@@ -121,7 +140,8 @@ type ClientOption func(*clientOptions)
 
 // clientOptions holds configuration options for the PortainerClient.
 type clientOptions struct {
-	skipTLSVerify bool
+	skipTLSVerify   bool
+	instrumentation Instrumentation
 }
 
 // WithSkipTLSVerify configures whether to skip TLS certificate verification.
@@ -152,6 +172,6 @@ func NewPortainerClient(serverURL string, token string, opts ...ClientOption) *P
 	}
 
 	return &PortainerClient{
-		cli: newPortainerAPIAdapter(serverURL, token, options.skipTLSVerify),
+		cli: newPortainerAPIAdapter(serverURL, token, options.skipTLSVerify, options.instrumentation),
 	}
 }
@@ -1,9 +1,28 @@
 package client
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"time"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/portainer/client-api-go/v2/client"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 )
@@ -50,3 +69,631 @@ func (c *PortainerClient) ProxyDockerRequest(opts models.DockerProxyRequestOptio
 
 	return c.cli.ProxyDockerRequest(opts.EnvironmentID, proxyOpts)
 }
+
+// maxDockerLogSize caps the amount of container log output read from the Docker API (1 MiB).
+const maxDockerLogSize = 1 << 20
+
+// maxDockerEvents caps the number of events decoded from a single Docker events request.
+const maxDockerEvents = 1000
+
+// maxDockerFileSize caps the size of the files read from or written to a container (1 MiB).
+const maxDockerFileSize = 1 << 20
+
+// GetDockerContainers lists the containers of a Docker environment through the Docker API proxy.
+// Filters are translated to Docker API query filters so that only matching containers are returned.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - opts: Filters to apply to the container list
+//
+// Returns:
+//   - A slice of DockerContainer objects
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainers(environmentId int, opts models.DockerContainerListOptions) ([]models.DockerContainer, error) {
+	args := filters.NewArgs()
+	if opts.ID != "" {
+		args.Add("id", opts.ID)
+	}
+	if opts.Name != "" {
+		args.Add("name", "(?i)"+regexp.QuoteMeta(opts.Name))
+	}
+	if opts.Status != "" {
+		args.Add("status", opts.Status)
+	}
+	for _, label := range opts.Labels {
+		args.Add("label", label)
+	}
+
+	queryParams := map[string]string{"all": strconv.FormatBool(opts.All)}
+	if args.Len() > 0 {
+		encoded, err := filters.ToJSON(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode container filters: %w", err)
+		}
+		queryParams["filters"] = encoded
+	}
+
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:      http.MethodGet,
+		APIPath:     "/containers/json",
+		QueryParams: queryParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker containers: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to list docker containers: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw []container.Summary
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode docker containers: %w", err)
+	}
+
+	containers := make([]models.DockerContainer, len(raw))
+	for i, rc := range raw {
+		containers[i] = models.ConvertDockerContainer(rc)
+	}
+
+	return containers, nil
+}
+
+// GetDockerContainerLogs reads the stdout and stderr logs of a container through the Docker API proxy.
+// Multiplexed log streams (containers without a TTY) are demultiplexed into plain text.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//   - opts: Time range, tail, and timestamp options
+//
+// Returns:
+//   - The log output as text, truncated to 1 MiB
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainerLogs(environmentId int, containerId string, opts models.DockerContainerLogOptions) (string, error) {
+	queryParams := map[string]string{
+		"stdout":     "true",
+		"stderr":     "true",
+		"timestamps": strconv.FormatBool(opts.Timestamps),
+		"tail":       "all",
+	}
+	if opts.Tail > 0 {
+		queryParams["tail"] = strconv.Itoa(opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		queryParams["since"] = strconv.FormatInt(opts.Since.Unix(), 10)
+	}
+	if !opts.Until.IsZero() {
+		queryParams["until"] = strconv.FormatInt(opts.Until.Unix(), 10)
+	}
+
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:      http.MethodGet,
+		APIPath:     "/containers/" + url.PathEscape(containerId) + "/logs",
+		QueryParams: queryParams,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get container logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to get container logs: status %d: %s", resp.StatusCode, body)
+	}
+
+	return readDockerLogStream(io.LimitReader(resp.Body, maxDockerLogSize))
+}
+
+// GetDockerContainerStats takes a CPU and memory usage sample of a container through the
+// Docker API proxy. The Docker engine samples the container twice, about one second apart,
+// to compute the CPU usage, so the call takes at least that long.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//
+// Returns:
+//   - A DockerContainerUsage object
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainerStats(environmentId int, containerId string) (models.DockerContainerUsage, error) {
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:      http.MethodGet,
+		APIPath:     "/containers/" + url.PathEscape(containerId) + "/stats",
+		QueryParams: map[string]string{"stream": "false"},
+	})
+	if err != nil {
+		return models.DockerContainerUsage{}, fmt.Errorf("failed to get container stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return models.DockerContainerUsage{}, fmt.Errorf("failed to get container stats: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw container.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return models.DockerContainerUsage{}, fmt.Errorf("failed to decode container stats: %w", err)
+	}
+
+	return models.ConvertDockerContainerStats(raw), nil
+}
+
+// GetDockerContainerTop lists the processes running in a container through the Docker API proxy.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//   - psArgs: The arguments passed to ps on the Docker host (empty for the Docker default, "-ef")
+//
+// Returns:
+//   - A DockerContainerTop object
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainerTop(environmentId int, containerId, psArgs string) (models.DockerContainerTop, error) {
+	opts := client.ProxyRequestOptions{
+		Method:  http.MethodGet,
+		APIPath: "/containers/" + url.PathEscape(containerId) + "/top",
+	}
+	if psArgs != "" {
+		opts.QueryParams = map[string]string{"ps_args": psArgs}
+	}
+
+	resp, err := c.cli.ProxyDockerRequest(environmentId, opts)
+	if err != nil {
+		return models.DockerContainerTop{}, fmt.Errorf("failed to list container processes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return models.DockerContainerTop{}, fmt.Errorf("failed to list container processes: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw container.TopResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return models.DockerContainerTop{}, fmt.Errorf("failed to decode container processes: %w", err)
+	}
+
+	return models.ConvertDockerContainerTop(raw), nil
+}
+
+// GetDockerContainerRestartState returns the restart policy and restart count of a container
+// by inspecting it through the Docker API proxy.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//
+// Returns:
+//   - A DockerContainerRestartState object
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainerRestartState(environmentId int, containerId string) (models.DockerContainerRestartState, error) {
+	raw, err := c.inspectDockerContainer(environmentId, containerId)
+	if err != nil {
+		return models.DockerContainerRestartState{}, err
+	}
+
+	return models.ConvertDockerContainerRestartState(raw), nil
+}
+
+// GetDockerContainerPortBindings returns the host port bindings of a container by inspecting
+// it through the Docker API proxy. Unlike the ports listed with the container, the bindings
+// are returned whether the container runs or not.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//
+// Returns:
+//   - A list of DockerContainerPort objects
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainerPortBindings(environmentId int, containerId string) ([]models.DockerContainerPort, error) {
+	raw, err := c.inspectDockerContainer(environmentId, containerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.ConvertDockerContainerPortBindings(raw), nil
+}
+
+// GetDockerContainerImage returns the image reference a container was created from and the
+// ID of its image by inspecting it through the Docker API proxy. Unlike the image listed
+// with the container, which Docker replaces with the image ID once the reference points to
+// another image, the reference is the one given when the container was created.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//
+// Returns:
+//   - A DockerContainerImage object
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerContainerImage(environmentId int, containerId string) (models.DockerContainerImage, error) {
+	raw, err := c.inspectDockerContainer(environmentId, containerId)
+	if err != nil {
+		return models.DockerContainerImage{}, err
+	}
+
+	return models.ConvertDockerContainerImage(raw), nil
+}
+
+// inspectDockerContainer returns the raw inspect response of a container through the Docker
+// API proxy.
+func (c *PortainerClient) inspectDockerContainer(environmentId int, containerId string) (container.InspectResponse, error) {
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:  http.MethodGet,
+		APIPath: "/containers/" + url.PathEscape(containerId) + "/json",
+	})
+	if err != nil {
+		return container.InspectResponse{}, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return container.InspectResponse{}, fmt.Errorf("failed to inspect container: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw container.InspectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return container.InspectResponse{}, fmt.Errorf("failed to decode container inspect response: %w", err)
+	}
+	return raw, nil
+}
+
+// GetDockerImageDigests returns the repository digests of a local image, such as
+// "nginx@sha256:...", through the Docker API proxy. They identify the manifest the image was
+// pulled from; images that were built locally and never pushed or pulled have none.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - imageId: The ID or reference of the image
+//
+// Returns:
+//   - The repository digests of the image
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerImageDigests(environmentId int, imageId string) ([]string, error) {
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:  http.MethodGet,
+		APIPath: "/images/" + url.PathEscape(imageId) + "/json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to inspect image: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw image.InspectResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode image inspect response: %w", err)
+	}
+	if raw.RepoDigests == nil {
+		return []string{}, nil
+	}
+	return raw.RepoDigests, nil
+}
+
+// GetDockerDistributionDigest returns the digest of the manifest an image reference points to
+// in its registry. The Docker engine of the environment queries the registry, through the
+// Docker API proxy, without pulling the image. Private registries require the engine to be
+// able to authenticate to them.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment whose engine queries the registry
+//   - imageRef: The image reference (e.g. "nginx:1.27")
+//
+// Returns:
+//   - The manifest digest (e.g. "sha256:...")
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerDistributionDigest(environmentId int, imageRef string) (string, error) {
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:  http.MethodGet,
+		APIPath: "/distribution/" + imageRef + "/json",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query the registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to query the registry: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw registry.DistributionInspect
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return "", fmt.Errorf("failed to decode distribution inspect response: %w", err)
+	}
+	return raw.Descriptor.Digest.String(), nil
+}
+
+// GetDockerServices lists the Swarm services of a Docker environment through the Docker API
+// proxy. The environment must be a Swarm manager.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//
+// Returns:
+//   - A slice of DockerService objects
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerServices(environmentId int) ([]models.DockerService, error) {
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:  http.MethodGet,
+		APIPath: "/services",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to list services: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw []swarm.Service
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode service list: %w", err)
+	}
+
+	services := make([]models.DockerService, len(raw))
+	for i, svc := range raw {
+		services[i] = models.ConvertDockerService(svc)
+	}
+	return services, nil
+}
+
+// GetDockerDiskUsage returns the disk usage of images, containers, volumes and build cache
+// of a Docker environment through the Docker API proxy, like docker system df. The Docker
+// engine computes the size of every container and volume, so the call can be slow on hosts
+// with many of them.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//
+// Returns:
+//   - A DockerDiskUsage object
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerDiskUsage(environmentId int) (models.DockerDiskUsage, error) {
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:  http.MethodGet,
+		APIPath: "/system/df",
+	})
+	if err != nil {
+		return models.DockerDiskUsage{}, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return models.DockerDiskUsage{}, fmt.Errorf("failed to get disk usage: status %d: %s", resp.StatusCode, body)
+	}
+
+	var raw types.DiskUsage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return models.DockerDiskUsage{}, fmt.Errorf("failed to decode disk usage: %w", err)
+	}
+
+	return models.ConvertDockerDiskUsage(raw), nil
+}
+
+// readDockerLogStream returns the content of a Docker log stream, demultiplexing
+// it when it carries the 8-byte stdcopy frame headers.
+func readDockerLogStream(r io.Reader) (string, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(8)
+
+	if !isMultiplexedLogHeader(header) {
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return "", fmt.Errorf("failed to read container logs: %w", err)
+		}
+		return string(data), nil
+	}
+
+	var out bytes.Buffer
+	if _, err := stdcopy.StdCopy(&out, &out, br); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", fmt.Errorf("failed to demultiplex container logs: %w", err)
+	}
+	return out.String(), nil
+}
+
+// isMultiplexedLogHeader reports whether header looks like a stdcopy frame header:
+// a stream type byte (stdin, stdout, stderr) followed by three zero bytes.
+func isMultiplexedLogHeader(header []byte) bool {
+	if len(header) < 8 {
+		return false
+	}
+	return header[0] <= byte(stdcopy.Stderr) && header[1] == 0 && header[2] == 0 && header[3] == 0
+}
+
+// GetDockerEvents lists the Docker events of an environment within a time range through the Docker API proxy.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - opts: Time range and type filter; Until must be set to bound the event stream
+//
+// Returns:
+//   - A slice of DockerEvent objects, capped at 1000 events
+//   - An error if the operation fails
+func (c *PortainerClient) GetDockerEvents(environmentId int, opts models.DockerEventListOptions) ([]models.DockerEvent, error) {
+	if opts.Until.IsZero() {
+		return nil, fmt.Errorf("an until time is required to list docker events")
+	}
+
+	queryParams := map[string]string{
+		"until": strconv.FormatInt(opts.Until.Unix(), 10),
+	}
+	if !opts.Since.IsZero() {
+		queryParams["since"] = strconv.FormatInt(opts.Since.Unix(), 10)
+	}
+	if opts.Type != "" {
+		encoded, err := filters.ToJSON(filters.NewArgs(filters.Arg("type", opts.Type)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event filters: %w", err)
+		}
+		queryParams["filters"] = encoded
+	}
+
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:      http.MethodGet,
+		APIPath:     "/events",
+		QueryParams: queryParams,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list docker events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to list docker events: status %d: %s", resp.StatusCode, body)
+	}
+
+	result := make([]models.DockerEvent, 0)
+	decoder := json.NewDecoder(resp.Body)
+	for len(result) < maxDockerEvents {
+		var raw events.Message
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode docker events: %w", err)
+		}
+		result = append(result, models.ConvertDockerEvent(raw))
+	}
+
+	return result, nil
+}
+
+// GetDockerContainerFile reads a regular file from the filesystem of a container through the
+// archive endpoint of the Docker API proxy, which returns the file in a tar archive.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//   - filePath: The absolute path of the file in the container
+//
+// Returns:
+//   - A DockerContainerFile object with the content of the file
+//   - An error if the operation fails, the path is not a regular file, or the file is larger than 1 MiB
+func (c *PortainerClient) GetDockerContainerFile(environmentId int, containerId, filePath string) (models.DockerContainerFile, error) {
+	if !path.IsAbs(filePath) {
+		return models.DockerContainerFile{}, fmt.Errorf("file path must be absolute, got %q", filePath)
+	}
+
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:      http.MethodGet,
+		APIPath:     "/containers/" + url.PathEscape(containerId) + "/archive",
+		QueryParams: map[string]string{"path": filePath},
+	})
+	if err != nil {
+		return models.DockerContainerFile{}, fmt.Errorf("failed to read container file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return models.DockerContainerFile{}, fmt.Errorf("failed to read container file: status %d: %s", resp.StatusCode, body)
+	}
+
+	tr := tar.NewReader(resp.Body)
+	header, err := tr.Next()
+	if err != nil {
+		return models.DockerContainerFile{}, fmt.Errorf("failed to read container file archive: %w", err)
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg:
+	case tar.TypeDir:
+		return models.DockerContainerFile{}, fmt.Errorf("%s is a directory", filePath)
+	case tar.TypeSymlink:
+		return models.DockerContainerFile{}, fmt.Errorf("%s is a symbolic link to %s", filePath, header.Linkname)
+	default:
+		return models.DockerContainerFile{}, fmt.Errorf("%s is not a regular file", filePath)
+	}
+	if header.Size > maxDockerFileSize {
+		return models.DockerContainerFile{}, fmt.Errorf("%s is %d bytes, larger than the %d bytes limit", filePath, header.Size, maxDockerFileSize)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(tr, header.Size))
+	if err != nil {
+		return models.DockerContainerFile{}, fmt.Errorf("failed to read container file content: %w", err)
+	}
+
+	file := models.DockerContainerFile{
+		Path:    filePath,
+		Size:    header.Size,
+		Mode:    fmt.Sprintf("%04o", header.FileInfo().Mode().Perm()),
+		Content: content,
+	}
+	if !header.ModTime.IsZero() {
+		file.ModTime = header.ModTime.UTC().Format(time.RFC3339)
+	}
+	return file, nil
+}
+
+// PutDockerContainerFile writes a regular file to the filesystem of a container through the
+// archive endpoint of the Docker API proxy, replacing the file if it exists. The parent
+// directory must exist in the container. The file is owned by root.
+//
+// Parameters:
+//   - environmentId: The ID of the Docker environment
+//   - containerId: The ID or name of the container
+//   - filePath: The absolute path of the file in the container
+//   - content: The content of the file, at most 1 MiB
+//   - mode: The permission bits of the file (e.g. 0644)
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) PutDockerContainerFile(environmentId int, containerId, filePath string, content []byte, mode int64) error {
+	if !path.IsAbs(filePath) || path.Base(filePath) == "/" {
+		return fmt.Errorf("file path must be an absolute file path, got %q", filePath)
+	}
+	if len(content) > maxDockerFileSize {
+		return fmt.Errorf("file content is %d bytes, larger than the %d bytes limit", len(content), maxDockerFileSize)
+	}
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Base(filePath),
+		Mode:     mode,
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to build container file archive: %w", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return fmt.Errorf("failed to build container file archive: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to build container file archive: %w", err)
+	}
+
+	resp, err := c.cli.ProxyDockerRequest(environmentId, client.ProxyRequestOptions{
+		Method:      http.MethodPut,
+		APIPath:     "/containers/" + url.PathEscape(containerId) + "/archive",
+		QueryParams: map[string]string{"path": path.Dir(filePath)},
+		Headers:     map[string]string{"Content-Type": "application/x-tar"},
+		Body:        &archive,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write container file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to write container file: status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
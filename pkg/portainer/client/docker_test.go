@@ -1,17 +1,21 @@
 package client
 
 import (
+	"archive/tar"
 	"bytes"
 	"errors"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/portainer/client-api-go/v2/client"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // TestProxyDockerRequest verifies proxy docker request behavior.
@@ -160,3 +164,893 @@ func TestGetDockerDashboard(t *testing.T) {
 		})
 	}
 }
+
+// TestGetDockerContainers verifies container listing through the Docker proxy, including filter encoding.
+func TestGetDockerContainers(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          models.DockerContainerListOptions
+		expectedQuery map[string]string
+		mockResponse  *http.Response
+		mockError     error
+		expected      []models.DockerContainer
+		expectedError bool
+	}{
+		{
+			name:          "running containers without filters",
+			opts:          models.DockerContainerListOptions{},
+			expectedQuery: map[string]string{"all": "false"},
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`[{"Id":"abc","Names":["/web"],"Image":"nginx","State":"running","Status":"Up"}]`)),
+			},
+			expected: []models.DockerContainer{
+				{ID: "abc", Name: "web", Image: "nginx", State: "running", Status: "Up"},
+			},
+		},
+		{
+			name: "all filters encoded",
+			opts: models.DockerContainerListOptions{All: true, ID: "abc", Name: "web.1", Status: "exited", Labels: []string{"app=web", "tier"}},
+			expectedQuery: map[string]string{
+				"all":     "true",
+				"filters": `{"id":{"abc":true},"label":{"app=web":true,"tier":true},"name":{"(?i)web\\.1":true},"status":{"exited":true}}`,
+			},
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`[]`)),
+			},
+			expected: []models.DockerContainer{},
+		},
+		{
+			name:          "non-200 response",
+			opts:          models.DockerContainerListOptions{Status: "bogus"},
+			expectedQuery: map[string]string{"all": "false", "filters": `{"status":{"bogus":true}}`},
+			mockResponse: &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       io.NopCloser(strings.NewReader(`{"message":"invalid filter"}`)),
+			},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			expectedQuery: map[string]string{"all": "false"},
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:      http.MethodGet,
+				APIPath:     "/containers/json",
+				QueryParams: tt.expectedQuery,
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			containers, err := c.GetDockerContainers(1, tt.opts)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, containers)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// multiplexedLogs builds a stdcopy-framed log stream with one stdout and one stderr frame.
+func multiplexedLogs(t *testing.T, stdout, stderr string) io.ReadCloser {
+	t.Helper()
+	var buf bytes.Buffer
+	_, err := stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(stdout))
+	assert.NoError(t, err)
+	_, err = stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(stderr))
+	assert.NoError(t, err)
+	return io.NopCloser(&buf)
+}
+
+// TestGetDockerContainerLogs verifies container log retrieval, query encoding, and demultiplexing.
+func TestGetDockerContainerLogs(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	until := time.Unix(1700003600, 0)
+
+	tests := []struct {
+		name          string
+		opts          models.DockerContainerLogOptions
+		expectedQuery map[string]string
+		mockResponse  *http.Response
+		mockError     error
+		expected      string
+		expectedError bool
+	}{
+		{
+			name: "multiplexed stream with time range",
+			opts: models.DockerContainerLogOptions{Since: since, Until: until, Tail: 50},
+			expectedQuery: map[string]string{
+				"stdout": "true", "stderr": "true", "timestamps": "false", "tail": "50",
+				"since": "1700000000", "until": "1700003600",
+			},
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: multiplexedLogs(t, "hello\n", "oops\n")},
+			expected:     "hello\noops\n",
+		},
+		{
+			name:          "raw TTY stream",
+			opts:          models.DockerContainerLogOptions{Timestamps: true},
+			expectedQuery: map[string]string{"stdout": "true", "stderr": "true", "timestamps": "true", "tail": "all"},
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("plain output\n"))},
+			expected:      "plain output\n",
+		},
+		{
+			name:          "container not found",
+			expectedQuery: map[string]string{"stdout": "true", "stderr": "true", "timestamps": "false", "tail": "all"},
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"No such container"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			expectedQuery: map[string]string{"stdout": "true", "stderr": "true", "timestamps": "false", "tail": "all"},
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:      http.MethodGet,
+				APIPath:     "/containers/web/logs",
+				QueryParams: tt.expectedQuery,
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			logs, err := c.GetDockerContainerLogs(1, "web", tt.opts)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, logs)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerContainerStats verifies one-shot container stats retrieval through the Docker proxy.
+func TestGetDockerContainerStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      models.DockerContainerUsage
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"id":"abc","name":"/web","memory_stats":{"usage":512,"limit":1024}}`))},
+			expected: models.DockerContainerUsage{ContainerID: "abc", Name: "web", MemoryUsage: 512, MemoryLimit: 1024, MemoryPercent: 50},
+		},
+		{
+			name:          "container not found",
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"No such container"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:      http.MethodGet,
+				APIPath:     "/containers/web/stats",
+				QueryParams: map[string]string{"stream": "false"},
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			usage, err := c.GetDockerContainerStats(1, "web")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, usage)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerContainerTop verifies container process listing through the Docker proxy.
+func TestGetDockerContainerTop(t *testing.T) {
+	tests := []struct {
+		name          string
+		psArgs        string
+		queryParams   map[string]string
+		mockResponse  *http.Response
+		mockError     error
+		expected      models.DockerContainerTop
+		expectedError bool
+	}{
+		{
+			name:        "successful retrieval with ps args",
+			psArgs:      "aux",
+			queryParams: map[string]string{"ps_args": "aux"},
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"Titles":["PID","%CPU","COMMAND"],"Processes":[["1","2.5","nginx"]]}`))},
+			expected: models.DockerContainerTop{
+				Titles:    []string{"PID", "%CPU", "COMMAND"},
+				Processes: []map[string]string{{"PID": "1", "%CPU": "2.5", "COMMAND": "nginx"}},
+			},
+		},
+		{
+			name:          "container not running",
+			mockResponse:  &http.Response{StatusCode: http.StatusConflict, Body: io.NopCloser(strings.NewReader(`{"message":"container is not running"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:      http.MethodGet,
+				APIPath:     "/containers/web/top",
+				QueryParams: tt.queryParams,
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			top, err := c.GetDockerContainerTop(1, "web", tt.psArgs)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, top)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerContainerRestartState verifies container restart policy retrieval through the Docker proxy.
+func TestGetDockerContainerRestartState(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      models.DockerContainerRestartState
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"Id":"abc","Name":"/web","RestartCount":3,"State":{"Status":"running"},"HostConfig":{"RestartPolicy":{"Name":"unless-stopped"}}}`))},
+			expected: models.DockerContainerRestartState{
+				ContainerID:  "abc",
+				Name:         "web",
+				State:        "running",
+				Policy:       "unless-stopped",
+				RestartCount: 3,
+			},
+		},
+		{
+			name:          "container not found",
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"No such container: web"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/containers/web/json",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			restart, err := c.GetDockerContainerRestartState(1, "web")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, restart)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerContainerPortBindings verifies container port binding retrieval through the Docker proxy.
+func TestGetDockerContainerPortBindings(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      []models.DockerContainerPort
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"Id":"abc","State":{"Status":"exited"},"HostConfig":{"PortBindings":{"6379/tcp":[{"HostIp":"","HostPort":"6379"}]}}}`))},
+			expected: []models.DockerContainerPort{{PrivatePort: 6379, PublicPort: 6379, Protocol: "tcp"}},
+		},
+		{
+			name:          "container not found",
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"No such container: web"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/containers/web/json",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			ports, err := c.GetDockerContainerPortBindings(1, "web")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, ports)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerContainerImage verifies container image reference retrieval through the Docker proxy.
+func TestGetDockerContainerImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      models.DockerContainerImage
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"Id":"abc","Name":"/web","Image":"sha256:111","Config":{"Image":"nginx:1.27"}}`))},
+			expected: models.DockerContainerImage{ContainerID: "abc", Name: "web", Image: "nginx:1.27", ImageID: "sha256:111"},
+		},
+		{
+			name:          "container not found",
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"No such container: web"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/containers/web/json",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			img, err := c.GetDockerContainerImage(1, "web")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, img)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerImageDigests verifies image repository digest retrieval through the Docker proxy.
+func TestGetDockerImageDigests(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name: "pulled image",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"Id":"sha256:111","RepoDigests":["nginx@sha256:aaa"]}`))},
+			expected: []string{"nginx@sha256:aaa"},
+		},
+		{
+			name:         "locally built image",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"Id":"sha256:111"}`))},
+			expected:     []string{},
+		},
+		{
+			name:          "image not found",
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"No such image"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/images/sha256:111/json",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			digests, err := c.GetDockerImageDigests(1, "sha256:111")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, digests)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerDistributionDigest verifies registry manifest digest retrieval through the Docker proxy.
+func TestGetDockerDistributionDigest(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      string
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`{"Descriptor":{"mediaType":"application/vnd.oci.image.index.v1+json","digest":"sha256:bbb","size":1024},"Platforms":[{"architecture":"amd64","os":"linux"}]}`))},
+			expected: "sha256:bbb",
+		},
+		{
+			name:          "registry unauthorized",
+			mockResponse:  &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader(`{"message":"unauthorized"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/distribution/ghcr.io/acme/api:1.2/json",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			digest, err := c.GetDockerDistributionDigest(1, "ghcr.io/acme/api:1.2")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, digest)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerServices verifies Swarm service listing through the Docker proxy.
+func TestGetDockerServices(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      []models.DockerService
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(
+				`[{"ID":"svc1","Spec":{"Name":"shop_api","Labels":{"com.docker.stack.namespace":"shop"},"TaskTemplate":{"ContainerSpec":{"Image":"acme/api:1.4@sha256:abc"}}}}]`))},
+			expected: []models.DockerService{{ID: "svc1", Name: "shop_api", Image: "acme/api:1.4@sha256:abc", Stack: "shop"}},
+		},
+		{
+			name:          "not a swarm manager",
+			mockResponse:  &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader(`{"message":"This node is not a swarm manager."}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`[`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/services",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			services, err := c.GetDockerServices(1)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, services)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerDiskUsage verifies disk usage retrieval through the Docker proxy.
+func TestGetDockerDiskUsage(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      models.DockerDiskUsage
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{
+				"LayersSize": 300,
+				"Images": [{"Id":"sha256:a","RepoTags":["<none>:<none>"],"Created":100,"Size":120,"SharedSize":20,"Containers":0}],
+				"Containers": [{"Id":"c1","Names":["/old"],"Image":"nginx","State":"exited","Created":200,"SizeRw":50}],
+				"Volumes": [{"Name":"data","Driver":"local","UsageData":{"RefCount":0,"Size":70}},{"Name":"nfs","Driver":"nfs"}],
+				"BuildCache": [{"ID":"b1","Size":10,"InUse":true},{"ID":"b2","Size":30}]
+			}`))},
+			expected: models.DockerDiskUsage{
+				LayersSize: 300,
+				Images:     []models.DockerDiskUsageImage{{ID: "sha256:a", RepoTags: []string{"<none>:<none>"}, Created: 100, Size: 120, SharedSize: 20}},
+				Containers: []models.DockerDiskUsageContainer{{ID: "c1", Name: "old", Image: "nginx", State: "exited", Created: 200, SizeRw: 50}},
+				Volumes: []models.DockerDiskUsageVolume{
+					{Name: "data", Driver: "local", RefCount: 0, Size: 70},
+					{Name: "nfs", Driver: "nfs", RefCount: -1, Size: -1},
+				},
+				BuildCacheSize:        40,
+				BuildCacheReclaimable: 30,
+			},
+		},
+		{
+			name:          "server error",
+			mockResponse:  &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(strings.NewReader(`{"message":"a disk usage operation is already running"}`))},
+			expectedError: true,
+		},
+		{
+			name:          "invalid JSON",
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{`))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:  http.MethodGet,
+				APIPath: "/system/df",
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			usage, err := c.GetDockerDiskUsage(1)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, usage)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetDockerEvents verifies event listing through the Docker proxy.
+func TestGetDockerEvents(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	until := time.Unix(1700003600, 0)
+
+	tests := []struct {
+		name          string
+		opts          models.DockerEventListOptions
+		expectedQuery map[string]string
+		mockResponse  *http.Response
+		mockError     error
+		expected      []models.DockerEvent
+		expectedError bool
+		skipProxy     bool
+	}{
+		{
+			name:          "stream of events with type filter",
+			opts:          models.DockerEventListOptions{Since: since, Until: until, Type: "container"},
+			expectedQuery: map[string]string{"since": "1700000000", "until": "1700003600", "filters": `{"type":{"container":true}}`},
+			mockResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(strings.NewReader(
+					`{"Type":"container","Action":"start","Actor":{"ID":"abc","Attributes":{"name":"web"}},"time":1700000100}` + "\n" +
+						`{"Type":"container","Action":"die","Actor":{"ID":"abc"},"time":1700000200}` + "\n")),
+			},
+			expected: []models.DockerEvent{
+				{Time: "2023-11-14T22:15:00Z", Type: "container", Action: "start", ActorID: "abc", Attributes: map[string]string{"name": "web"}},
+				{Time: "2023-11-14T22:16:40Z", Type: "container", Action: "die", ActorID: "abc"},
+			},
+		},
+		{
+			name:          "no events",
+			opts:          models.DockerEventListOptions{Until: until},
+			expectedQuery: map[string]string{"until": "1700003600"},
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))},
+			expected:      []models.DockerEvent{},
+		},
+		{
+			name:          "missing until",
+			opts:          models.DockerEventListOptions{Since: since},
+			expectedError: true,
+			skipProxy:     true,
+		},
+		{
+			name:          "malformed event",
+			opts:          models.DockerEventListOptions{Until: until},
+			expectedQuery: map[string]string{"until": "1700003600"},
+			mockResponse:  &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{not json"))},
+			expectedError: true,
+		},
+		{
+			name:          "proxy error",
+			opts:          models.DockerEventListOptions{Until: until},
+			expectedQuery: map[string]string{"until": "1700003600"},
+			mockError:     errors.New("connection refused"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			if !tt.skipProxy {
+				mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+					Method:      http.MethodGet,
+					APIPath:     "/events",
+					QueryParams: tt.expectedQuery,
+				}).Return(tt.mockResponse, tt.mockError)
+			}
+
+			c := &PortainerClient{cli: mockAPI}
+			events, err := c.GetDockerEvents(1, tt.opts)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, events)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// tarArchive builds a single-entry tar archive response body.
+func tarArchive(t *testing.T, header *tar.Header, content string) io.ReadCloser {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	header.Size = int64(len(content))
+	assert.NoError(t, tw.WriteHeader(header))
+	_, err := tw.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	return io.NopCloser(&buf)
+}
+
+// TestGetDockerContainerFile verifies reading a file from a container through the archive endpoint.
+func TestGetDockerContainerFile(t *testing.T) {
+	modTime := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		mockResponse  *http.Response
+		mockError     error
+		expected      models.DockerContainerFile
+		expectedError string
+	}{
+		{
+			name: "regular file",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: tarArchive(t,
+				&tar.Header{Typeflag: tar.TypeReg, Name: "nginx.conf", Mode: 0o640, ModTime: modTime}, "worker_processes 1;\n")},
+			expected: models.DockerContainerFile{
+				Path: "/etc/nginx/nginx.conf", Size: 20, Mode: "0640", ModTime: "2025-01-02T15:04:05Z", Content: []byte("worker_processes 1;\n"),
+			},
+		},
+		{
+			name: "directory",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: tarArchive(t,
+				&tar.Header{Typeflag: tar.TypeDir, Name: "nginx.conf/", Mode: 0o755}, "")},
+			expectedError: "is a directory",
+		},
+		{
+			name: "symbolic link",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: tarArchive(t,
+				&tar.Header{Typeflag: tar.TypeSymlink, Name: "nginx.conf", Linkname: "/config/nginx.conf"}, "")},
+			expectedError: "symbolic link to /config/nginx.conf",
+		},
+		{
+			name: "file too large",
+			mockResponse: &http.Response{StatusCode: http.StatusOK, Body: tarArchive(t,
+				&tar.Header{Typeflag: tar.TypeReg, Name: "nginx.conf", Mode: 0o644}, strings.Repeat("x", maxDockerFileSize+1))},
+			expectedError: "larger than the 1048576 bytes limit",
+		},
+		{
+			name:          "file not found",
+			mockResponse:  &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"Could not find the file"}`))},
+			expectedError: "status 404",
+		},
+		{
+			name:          "proxy error",
+			mockError:     errors.New("connection refused"),
+			expectedError: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ProxyDockerRequest", 1, client.ProxyRequestOptions{
+				Method:      http.MethodGet,
+				APIPath:     "/containers/web/archive",
+				QueryParams: map[string]string{"path": "/etc/nginx/nginx.conf"},
+			}).Return(tt.mockResponse, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			file, err := c.GetDockerContainerFile(1, "web", "/etc/nginx/nginx.conf")
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, file)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+
+	t.Run("relative path", func(t *testing.T) {
+		c := &PortainerClient{cli: new(MockPortainerAPI)}
+		_, err := c.GetDockerContainerFile(1, "web", "etc/nginx.conf")
+		assert.ErrorContains(t, err, "must be absolute")
+	})
+}
+
+// TestPutDockerContainerFile verifies writing a file to a container through the archive endpoint.
+func TestPutDockerContainerFile(t *testing.T) {
+	t.Run("successful write", func(t *testing.T) {
+		var opts client.ProxyRequestOptions
+		var archive []byte
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ProxyDockerRequest", 1, mock.Anything).Run(func(args mock.Arguments) {
+			opts = args.Get(1).(client.ProxyRequestOptions)
+			archive, _ = io.ReadAll(opts.Body)
+		}).Return(&http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil)
+
+		c := &PortainerClient{cli: mockAPI}
+		err := c.PutDockerContainerFile(1, "web", "/etc/nginx/nginx.conf", []byte("worker_processes 2;\n"), 0o600)
+		assert.NoError(t, err)
+		mockAPI.AssertExpectations(t)
+		assert.Equal(t, http.MethodPut, opts.Method)
+		assert.Equal(t, "/containers/web/archive", opts.APIPath)
+		assert.Equal(t, map[string]string{"path": "/etc/nginx"}, opts.QueryParams)
+		assert.Equal(t, map[string]string{"Content-Type": "application/x-tar"}, opts.Headers)
+
+		tr := tar.NewReader(bytes.NewReader(archive))
+		header, err := tr.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, "nginx.conf", header.Name)
+		assert.Equal(t, int64(0o600), header.Mode)
+		content, _ := io.ReadAll(tr)
+		assert.Equal(t, "worker_processes 2;\n", string(content))
+	})
+
+	t.Run("docker error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ProxyDockerRequest", 1, mock.Anything).Return(&http.Response{
+			StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"message":"Could not find the file /etc/nginx"}`)),
+		}, nil)
+
+		c := &PortainerClient{cli: mockAPI}
+		err := c.PutDockerContainerFile(1, "web", "/etc/nginx/nginx.conf", []byte("x"), 0o644)
+		assert.ErrorContains(t, err, "status 404")
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		c := &PortainerClient{cli: new(MockPortainerAPI)}
+		assert.ErrorContains(t, c.PutDockerContainerFile(1, "web", "nginx.conf", []byte("x"), 0o644), "absolute file path")
+		assert.ErrorContains(t, c.PutDockerContainerFile(1, "web", "/", []byte("x"), 0o644), "absolute file path")
+		assert.ErrorContains(t, c.PutDockerContainerFile(1, "web", "/big", make([]byte, maxDockerFileSize+1), 0o644), "bytes limit")
+	})
+}
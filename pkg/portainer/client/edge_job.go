@@ -135,3 +135,35 @@ func (c *PortainerClient) GetEdgeUpdateSchedules() ([]models.EdgeUpdateSchedule,
 
 	return schedules, nil
 }
+
+// CreateEdgeUpdateSchedule creates a schedule that updates the agents of the edge
+// environments of the given edge groups to the version of the Portainer server.
+//
+// Parameters:
+//   - name: The name of the schedule
+//   - edgeGroupIds: The edge groups whose environments to update
+//   - scheduledTime: The time of the update, as "YYYY-MM-DD HH:MM:SS"; empty to update immediately
+//
+// Returns:
+//   - The ID of the created schedule
+//   - An error if the operation fails
+func (c *PortainerClient) CreateEdgeUpdateSchedule(name string, edgeGroupIds []int, scheduledTime string) (int, error) {
+	groupIds := make([]int64, len(edgeGroupIds))
+	for i, g := range edgeGroupIds {
+		groupIds[i] = int64(g)
+	}
+
+	payload := &apimodels.EdgeupdateschedulesCreatePayload{
+		Name:          name,
+		GroupIDs:      groupIds,
+		ScheduledTime: scheduledTime,
+		Type:          models.EdgeUpdateScheduleTypeUpdate,
+	}
+
+	id, err := c.cli.CreateEdgeUpdateSchedule(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create edge update schedule: %w", err)
+	}
+
+	return int(id), nil
+}
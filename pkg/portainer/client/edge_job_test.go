@@ -326,3 +326,25 @@ func TestGetEdgeUpdateSchedules(t *testing.T) {
 		})
 	}
 }
+
+// TestCreateEdgeUpdateSchedule verifies create edge update schedule behavior.
+func TestCreateEdgeUpdateSchedule(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("CreateEdgeUpdateSchedule", &apimodels.EdgeupdateschedulesCreatePayload{
+		Name:          "update",
+		GroupIDs:      []int64{1, 3},
+		ScheduledTime: "2026-01-02 03:04:05",
+		Type:          models.EdgeUpdateScheduleTypeUpdate,
+	}).Return(7, nil)
+
+	client := &PortainerClient{cli: mockAPI}
+	id, err := client.CreateEdgeUpdateSchedule("update", []int{1, 3}, "2026-01-02 03:04:05")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+
+	mockAPI = new(MockPortainerAPI)
+	mockAPI.On("CreateEdgeUpdateSchedule", mock.AnythingOfType("*models.EdgeupdateschedulesCreatePayload")).Return(0, fmt.Errorf("api error"))
+	client = &PortainerClient{cli: mockAPI}
+	_, err = client.CreateEdgeUpdateSchedule("update", []int{1}, "")
+	assert.Error(t, err)
+}
@@ -2,30 +2,108 @@ package client
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/utils"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
 )
 
-// GetEnvironments retrieves all environments from the Portainer server.
+// environmentTypeCodes maps the environment type names to the Portainer endpoint types.
+var environmentTypeCodes = map[string]int64{
+	models.EnvironmentTypeDockerLocal:         1,
+	models.EnvironmentTypeDockerAgent:         2,
+	models.EnvironmentTypeAzureACI:            3,
+	models.EnvironmentTypeDockerEdgeAgent:     4,
+	models.EnvironmentTypeKubernetesLocal:     5,
+	models.EnvironmentTypeKubernetesAgent:     6,
+	models.EnvironmentTypeKubernetesEdgeAgent: 7,
+}
+
+// environmentStatusCodes maps the environment status names to the Portainer endpoint
+// statuses. The unknown status has no code and is filtered by the client.
+var environmentStatusCodes = map[string]int64{
+	models.EnvironmentStatusActive:   1,
+	models.EnvironmentStatusInactive: 2,
+}
+
+// GetEnvironments retrieves the environments matching the given filters from the
+// Portainer server. The filters are sent as query parameters of the environment list,
+// and checked again on the result because the Portainer search is broader than the
+// name filter and older servers ignore some parameters.
+//
+// Parameters:
+//   - opts: Filters on the name, tag, group, status and type of the environments
 //
 // Returns:
 //   - A slice of Environment objects
 //   - An error if the operation fails
-func (c *PortainerClient) GetEnvironments() ([]models.Environment, error) {
-	endpoints, err := c.cli.ListEndpoints()
+func (c *PortainerClient) GetEnvironments(opts models.EnvironmentListOptions) ([]models.Environment, error) {
+	rawEndpoints, err := c.cli.ListEndpoints(environmentListParams(opts))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list endpoints: %w", err)
 	}
 
-	environments := make([]models.Environment, len(endpoints))
-	for i, endpoint := range endpoints {
-		environments[i] = models.ConvertEndpointToEnvironment(endpoint)
+	environments := make([]models.Environment, 0, len(rawEndpoints))
+	for _, endpoint := range rawEndpoints {
+		env := models.ConvertEndpointToEnvironment(endpoint)
+		if matchesEnvironment(env, opts) {
+			environments = append(environments, env)
+		}
 	}
 
 	return environments, nil
 }
 
+// environmentListParams builds the query parameters of the environment list. The
+// snapshots are excluded since the Environment model does not use them.
+func environmentListParams(opts models.EnvironmentListOptions) *endpoints.EndpointListParams {
+	excludeSnapshots := true
+	params := endpoints.NewEndpointListParams().WithExcludeSnapshots(&excludeSnapshots)
+
+	// The search also matches the group, the tags and the URL; the name filter is
+	// checked again on the result.
+	search := opts.Search
+	if search == "" {
+		search = opts.Name
+	}
+	if search != "" {
+		params.SetSearch(&search)
+	}
+	if opts.TagID != 0 {
+		params.SetTagIds([]int64{int64(opts.TagID)})
+	}
+	if opts.GroupID != 0 {
+		params.SetGroupIds([]int64{int64(opts.GroupID)})
+	}
+	if code, ok := environmentStatusCodes[opts.Status]; ok {
+		params.SetStatus([]int64{code})
+	}
+	if code, ok := environmentTypeCodes[opts.Type]; ok {
+		params.SetTypes([]int64{code})
+	}
+	return params
+}
+
+// matchesEnvironment reports whether an environment matches the filters, except for the
+// search, which only the Portainer server evaluates.
+func matchesEnvironment(env models.Environment, opts models.EnvironmentListOptions) bool {
+	switch {
+	case !strings.Contains(strings.ToLower(env.Name), strings.ToLower(opts.Name)):
+		return false
+	case opts.TagID != 0 && !slices.Contains(env.TagIds, opts.TagID):
+		return false
+	case opts.GroupID != 0 && env.GroupID != opts.GroupID:
+		return false
+	case opts.Status != "" && env.Status != opts.Status:
+		return false
+	case opts.Type != "" && env.Type != opts.Type:
+		return false
+	}
+	return true
+}
+
 // GetEnvironment retrieves a single environment by ID from the Portainer server.
 //
 // Parameters:
@@ -43,6 +121,46 @@ func (c *PortainerClient) GetEnvironment(id int) (models.Environment, error) {
 	return models.ConvertEndpointToEnvironment(endpoint), nil
 }
 
+// CreateEnvironment creates a Docker API, agent or edge agent environment.
+//
+// Parameters:
+//   - opts: The settings of the new environment
+//
+// Returns:
+//   - The created Environment
+//   - The edge key used to deploy the edge agent (empty for other environment types)
+//   - An error if the operation fails
+func (c *PortainerClient) CreateEnvironment(opts models.EnvironmentCreateOptions) (models.Environment, string, error) {
+	params := endpoints.NewEndpointCreateParams().
+		WithName(opts.Name).
+		WithEndpointCreationType(int64(opts.CreationType)).
+		WithURL(&opts.URL)
+	if opts.PublicURL != "" {
+		params = params.WithPublicURL(&opts.PublicURL)
+	}
+
+	switch opts.CreationType {
+	case models.EnvironmentCreationTypeAgent:
+		// The Portainer API requires agents to use TLS without certificate verification.
+		enabled := true
+		params = params.WithTLS(&enabled).WithTLSSkipVerify(&enabled).WithTLSSkipClientVerify(&enabled)
+	case models.EnvironmentCreationTypeDockerAPI:
+		if opts.TLS {
+			params = params.WithTLS(&opts.TLS).WithTLSSkipVerify(&opts.TLSSkipVerify)
+		}
+	}
+
+	endpoint, err := c.cli.CreateEndpoint(params)
+	if err != nil {
+		return models.Environment{}, "", fmt.Errorf("failed to create environment: %w", err)
+	}
+	if endpoint == nil {
+		return models.Environment{}, "", fmt.Errorf("failed to create environment: empty response")
+	}
+
+	return models.ConvertEndpointToEnvironment(endpoint), endpoint.EdgeKey, nil
+}
+
 // DeleteEnvironment deletes an environment by ID.
 //
 // Parameters:
@@ -85,6 +203,89 @@ func (c *PortainerClient) SnapshotAllEnvironments() error {
 	return nil
 }
 
+// GetEnvironmentSnapshots retrieves the latest snapshot data of the environments matching
+// the filters. The raw snapshot data is requested as it holds the image sizes.
+//
+// Parameters:
+//   - opts: The filters applied to the environments
+//
+// Returns:
+//   - A slice of EnvironmentSnapshot objects
+//   - An error if the operation fails
+func (c *PortainerClient) GetEnvironmentSnapshots(opts models.EnvironmentListOptions) ([]models.EnvironmentSnapshot, error) {
+	params := environmentListParams(opts)
+	excludeSnapshots := false
+	params.SetExcludeSnapshots(&excludeSnapshots)
+
+	rawEndpoints, err := c.cli.ListEndpoints(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoints: %w", err)
+	}
+
+	snapshots := make([]models.EnvironmentSnapshot, 0, len(rawEndpoints))
+	for _, endpoint := range rawEndpoints {
+		if matchesEnvironment(models.ConvertEndpointToEnvironment(endpoint), opts) {
+			snapshots = append(snapshots, models.ConvertToEnvironmentSnapshot(endpoint))
+		}
+	}
+
+	return snapshots, nil
+}
+
+// GetEnvironmentSnapshotSettings retrieves the snapshot behavior of an environment.
+//
+// Parameters:
+//   - id: The ID of the environment
+//
+// Returns:
+//   - The snapshot settings of the environment
+//   - An error if the operation fails
+func (c *PortainerClient) GetEnvironmentSnapshotSettings(id int) (models.EnvironmentSnapshotSettings, error) {
+	endpoint, err := c.cli.GetEndpoint(int64(id))
+	if err != nil {
+		return models.EnvironmentSnapshotSettings{}, fmt.Errorf("failed to get endpoint: %w", err)
+	}
+
+	return models.ConvertToEnvironmentSnapshotSettings(endpoint), nil
+}
+
+// UpdateEnvironmentSnapshotSettings updates the snapshot behavior of an edge environment.
+// Nil values are left unchanged. The other async mode intervals are sent with their current
+// value, as Portainer replaces them together with the snapshot interval.
+//
+// Parameters:
+//   - id: The ID of the environment to update
+//   - edgeCheckinInterval: The check-in interval in seconds, 0 to use the global default
+//   - edgeSnapshotInterval: The async mode snapshot interval in seconds, -1 to use the global default
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) UpdateEnvironmentSnapshotSettings(id int, edgeCheckinInterval, edgeSnapshotInterval *int) error {
+	body := map[string]any{}
+	if edgeCheckinInterval != nil {
+		body["edgeCheckinInterval"] = *edgeCheckinInterval
+	}
+	if edgeSnapshotInterval != nil {
+		current, err := c.GetEnvironmentSnapshotSettings(id)
+		if err != nil {
+			return err
+		}
+		body["edge"] = map[string]any{
+			"PingInterval":     current.EdgePingInterval,
+			"SnapshotInterval": *edgeSnapshotInterval,
+			"CommandInterval":  current.EdgeCommandInterval,
+		}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	if err := c.cli.UpdateEndpointSettings(int64(id), body); err != nil {
+		return fmt.Errorf("failed to update environment snapshot settings: %w", err)
+	}
+	return nil
+}
+
 // UpdateEnvironmentTags updates the tags associated with an environment.
 //
 // Parameters:
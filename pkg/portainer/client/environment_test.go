@@ -6,6 +6,7 @@ import (
 
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -61,11 +62,12 @@ func TestGetEnvironments(t *testing.T) {
 			},
 			expected: []models.Environment{
 				{
-					ID:     1,
-					Name:   "env1",
-					Status: "active",
-					Type:   "docker-local",
-					TagIds: []int{1, 2},
+					ID:      1,
+					Name:    "env1",
+					Status:  "active",
+					Type:    "docker-local",
+					GroupID: 1,
+					TagIds:  []int{1, 2},
 					UserAccesses: map[int]string{
 						1: "environment_administrator",
 						2: "helpdesk_user",
@@ -86,6 +88,7 @@ func TestGetEnvironments(t *testing.T) {
 					Name:         "env2",
 					Status:       "inactive",
 					Type:         "docker-agent",
+					GroupID:      1,
 					TagIds:       []int{3},
 					UserAccesses: map[int]string{},
 					TeamAccesses: map[int]string{},
@@ -116,11 +119,11 @@ func TestGetEnvironments(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAPI := new(MockPortainerAPI)
-			mockAPI.On("ListEndpoints").Return(tt.mockEndpoints, tt.mockError)
+			mockAPI.On("ListEndpoints", mock.AnythingOfType("*endpoints.EndpointListParams")).Return(tt.mockEndpoints, tt.mockError)
 
 			client := &PortainerClient{cli: mockAPI}
 
-			environments, err := client.GetEnvironments()
+			environments, err := client.GetEnvironments(models.EnvironmentListOptions{})
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -133,6 +136,86 @@ func TestGetEnvironments(t *testing.T) {
 	}
 }
 
+// TestGetEnvironmentsFilters verifies the query parameters sent for the filters of
+// GetEnvironments and that the filters are checked again on the result.
+func TestGetEnvironmentsFilters(t *testing.T) {
+	rawEndpoints := []*apimodels.PortainereeEndpoint{
+		{ID: 1, Name: "prod-web", Type: 1, Status: 1, GroupID: 2, TagIds: []int64{5}},
+		{ID: 2, Name: "staging", Type: 2, Status: 1, GroupID: 2, TagIds: []int64{5}},
+		{ID: 3, Name: "prod-db", Type: 2, Status: 3, GroupID: 1},
+	}
+
+	tests := []struct {
+		name        string
+		opts        models.EnvironmentListOptions
+		check       func(t *testing.T, params *endpoints.EndpointListParams)
+		expectedIDs []int
+	}{
+		{
+			name: "no filters",
+			check: func(t *testing.T, params *endpoints.EndpointListParams) {
+				assert.Nil(t, params.Search)
+				assert.Empty(t, params.Types)
+				assert.True(t, *params.ExcludeSnapshots)
+			},
+			expectedIDs: []int{1, 2, 3},
+		},
+		{
+			name: "name is sent as the search query",
+			opts: models.EnvironmentListOptions{Name: "PROD"},
+			check: func(t *testing.T, params *endpoints.EndpointListParams) {
+				assert.Equal(t, "PROD", *params.Search)
+			},
+			expectedIDs: []int{1, 3},
+		},
+		{
+			name: "search and name",
+			opts: models.EnvironmentListOptions{Name: "web", Search: "prod"},
+			check: func(t *testing.T, params *endpoints.EndpointListParams) {
+				assert.Equal(t, "prod", *params.Search)
+			},
+			expectedIDs: []int{1},
+		},
+		{
+			name: "tag, group, status and type",
+			opts: models.EnvironmentListOptions{TagID: 5, GroupID: 2, Status: models.EnvironmentStatusActive, Type: models.EnvironmentTypeDockerAgent},
+			check: func(t *testing.T, params *endpoints.EndpointListParams) {
+				assert.Equal(t, []int64{5}, params.TagIds)
+				assert.Equal(t, []int64{2}, params.GroupIds)
+				assert.Equal(t, []int64{1}, params.Status)
+				assert.Equal(t, []int64{2}, params.Types)
+			},
+			expectedIDs: []int{2},
+		},
+		{
+			name: "unknown status is filtered by the client",
+			opts: models.EnvironmentListOptions{Status: models.EnvironmentStatusUnknown},
+			check: func(t *testing.T, params *endpoints.EndpointListParams) {
+				assert.Empty(t, params.Status)
+			},
+			expectedIDs: []int{3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ListEndpoints", mock.AnythingOfType("*endpoints.EndpointListParams")).Return(rawEndpoints, nil)
+
+			client := &PortainerClient{cli: mockAPI}
+			environments, err := client.GetEnvironments(tt.opts)
+			assert.NoError(t, err)
+
+			tt.check(t, mockAPI.Calls[0].Arguments.Get(0).(*endpoints.EndpointListParams))
+			ids := make([]int, 0, len(environments))
+			for _, env := range environments {
+				ids = append(ids, env.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
 // TestGetEnvironment verifies get environment behavior.
 func TestGetEnvironment(t *testing.T) {
 	tests := []struct {
@@ -191,6 +274,140 @@ func TestGetEnvironment(t *testing.T) {
 	}
 }
 
+// TestGetEnvironmentSnapshotSettings verifies retrieval of the snapshot behavior of an environment.
+func TestGetEnvironmentSnapshotSettings(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("GetEndpoint", int64(5)).Return(&apimodels.PortainereeEndpoint{ID: 5, Name: "edge", Type: 4, EdgeCheckinInterval: 30}, nil)
+
+	client := &PortainerClient{cli: mockAPI}
+	settings, err := client.GetEnvironmentSnapshotSettings(5)
+	assert.NoError(t, err)
+	assert.Equal(t, models.EnvironmentSnapshotSettings{EnvironmentID: 5, Name: "edge", Type: models.EnvironmentTypeDockerEdgeAgent, EdgeCheckinInterval: 30}, settings)
+
+	mockAPI.On("GetEndpoint", int64(6)).Return(nil, errors.New("not found"))
+	_, err = client.GetEnvironmentSnapshotSettings(6)
+	assert.Error(t, err)
+}
+
+// TestGetEnvironmentSnapshots verifies that snapshots are requested and filtered like
+// the environment list.
+func TestGetEnvironmentSnapshots(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListEndpoints", mock.AnythingOfType("*endpoints.EndpointListParams")).Return([]*apimodels.PortainereeEndpoint{
+		{ID: 1, Name: "prod-web", Type: 2, Status: 1, GroupID: 2, Snapshots: []*apimodels.PortainerDockerSnapshot{{Time: 100, ContainerCount: 3}}},
+		{ID: 2, Name: "staging", Type: 2, Status: 1, GroupID: 2},
+	}, nil)
+
+	client := &PortainerClient{cli: mockAPI}
+	snapshots, err := client.GetEnvironmentSnapshots(models.EnvironmentListOptions{Name: "prod", GroupID: 2})
+	assert.NoError(t, err)
+
+	params := mockAPI.Calls[0].Arguments.Get(0).(*endpoints.EndpointListParams)
+	assert.False(t, *params.ExcludeSnapshots)
+	assert.Equal(t, []int64{2}, params.GroupIds)
+	assert.Len(t, snapshots, 1)
+	assert.Equal(t, 1, snapshots[0].EnvironmentID)
+	assert.Equal(t, 3, snapshots[0].Containers)
+
+	mockAPI = new(MockPortainerAPI)
+	mockAPI.On("ListEndpoints", mock.AnythingOfType("*endpoints.EndpointListParams")).Return(nil, errors.New("forbidden"))
+	client = &PortainerClient{cli: mockAPI}
+	_, err = client.GetEnvironmentSnapshots(models.EnvironmentListOptions{})
+	assert.Error(t, err)
+}
+
+// TestCreateEnvironment verifies create environment behavior.
+func TestCreateEnvironment(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              models.EnvironmentCreateOptions
+		mockEndpoint      *apimodels.PortainereeEndpoint
+		mockError         error
+		expectedTLS       bool
+		expectedSkipTLS   bool
+		expectedPublicURL *string
+		expectedEdgeKey   string
+		expectedError     bool
+	}{
+		{
+			name: "agent environment always uses TLS",
+			opts: models.EnvironmentCreateOptions{
+				Name:         "prod",
+				CreationType: models.EnvironmentCreationTypeAgent,
+				URL:          "10.0.0.5:9001",
+			},
+			mockEndpoint:    &apimodels.PortainereeEndpoint{ID: 7, Name: "prod", Type: 2},
+			expectedTLS:     true,
+			expectedSkipTLS: true,
+		},
+		{
+			name: "docker api environment with TLS",
+			opts: models.EnvironmentCreateOptions{
+				Name:          "lab",
+				CreationType:  models.EnvironmentCreationTypeDockerAPI,
+				URL:           "tcp://10.0.0.6:2376",
+				PublicURL:     "10.0.0.6",
+				TLS:           true,
+				TLSSkipVerify: true,
+			},
+			mockEndpoint:      &apimodels.PortainereeEndpoint{ID: 8, Name: "lab", Type: 1},
+			expectedTLS:       true,
+			expectedSkipTLS:   true,
+			expectedPublicURL: strPtr("10.0.0.6"),
+		},
+		{
+			name: "edge agent environment returns the edge key",
+			opts: models.EnvironmentCreateOptions{
+				Name:         "edge",
+				CreationType: models.EnvironmentCreationTypeEdgeAgent,
+				URL:          "https://portainer.example.com",
+			},
+			mockEndpoint:    &apimodels.PortainereeEndpoint{ID: 9, Name: "edge", Type: 4, EdgeKey: "edge-key"},
+			expectedEdgeKey: "edge-key",
+		},
+		{
+			name: "create error",
+			opts: models.EnvironmentCreateOptions{
+				Name:         "prod",
+				CreationType: models.EnvironmentCreationTypeAgent,
+				URL:          "10.0.0.5:9001",
+			},
+			mockError:     errors.New("failed to create endpoint"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			var captured *endpoints.EndpointCreateParams
+			mockAPI.On("CreateEndpoint", mock.Anything).Return(tt.mockEndpoint, tt.mockError).Run(func(args mock.Arguments) {
+				captured = args.Get(0).(*endpoints.EndpointCreateParams)
+			})
+
+			client := &PortainerClient{cli: mockAPI}
+
+			environment, edgeKey, err := client.CreateEnvironment(tt.opts)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int(tt.mockEndpoint.ID), environment.ID)
+			assert.Equal(t, tt.expectedEdgeKey, edgeKey)
+
+			assert.Equal(t, tt.opts.Name, captured.Name)
+			assert.Equal(t, int64(tt.opts.CreationType), captured.EndpointCreationType)
+			assert.Equal(t, tt.opts.URL, *captured.URL)
+			assert.Equal(t, tt.expectedPublicURL, captured.PublicURL)
+			assert.Equal(t, tt.expectedTLS, captured.TLS != nil && *captured.TLS)
+			assert.Equal(t, tt.expectedSkipTLS, captured.TLSSkipVerify != nil && *captured.TLSSkipVerify)
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
 // TestDeleteEnvironment verifies delete environment behavior.
 func TestDeleteEnvironment(t *testing.T) {
 	tests := []struct {
@@ -305,6 +522,80 @@ func TestSnapshotAllEnvironments(t *testing.T) {
 	}
 }
 
+// TestUpdateEnvironmentSnapshotSettings verifies that only the given intervals are sent and
+// that the other async mode intervals keep their current value.
+func TestUpdateEnvironmentSnapshotSettings(t *testing.T) {
+	checkin, snapshot := 0, 300
+	endpoint := &apimodels.PortainereeEndpoint{
+		ID:   5,
+		Type: 4,
+		Edge: &apimodels.PortainerEnvironmentEdgeSettings{AsyncMode: true, PingInterval: 60, SnapshotInterval: 120, CommandInterval: -1},
+	}
+
+	tests := []struct {
+		name                 string
+		edgeCheckinInterval  *int
+		edgeSnapshotInterval *int
+		setupMock            func(*MockPortainerAPI)
+		expectedError        bool
+	}{
+		{
+			name:                "check-in interval only",
+			edgeCheckinInterval: &checkin,
+			setupMock: func(m *MockPortainerAPI) {
+				m.On("UpdateEndpointSettings", int64(5), map[string]any{"edgeCheckinInterval": 0}).Return(nil)
+			},
+		},
+		{
+			name:                 "snapshot interval keeps the other intervals",
+			edgeSnapshotInterval: &snapshot,
+			setupMock: func(m *MockPortainerAPI) {
+				m.On("GetEndpoint", int64(5)).Return(endpoint, nil)
+				m.On("UpdateEndpointSettings", int64(5), map[string]any{
+					"edge": map[string]any{"PingInterval": 60, "SnapshotInterval": 300, "CommandInterval": -1},
+				}).Return(nil)
+			},
+		},
+		{
+			name:      "nothing to update",
+			setupMock: func(m *MockPortainerAPI) {},
+		},
+		{
+			name:                 "get endpoint error",
+			edgeSnapshotInterval: &snapshot,
+			setupMock: func(m *MockPortainerAPI) {
+				m.On("GetEndpoint", int64(5)).Return(nil, errors.New("not found"))
+			},
+			expectedError: true,
+		},
+		{
+			name:                "update error",
+			edgeCheckinInterval: &checkin,
+			setupMock: func(m *MockPortainerAPI) {
+				m.On("UpdateEndpointSettings", int64(5), map[string]any{"edgeCheckinInterval": 0}).Return(errors.New("forbidden"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			tt.setupMock(mockAPI)
+
+			client := &PortainerClient{cli: mockAPI}
+			err := client.UpdateEnvironmentSnapshotSettings(5, tt.edgeCheckinInterval, tt.edgeSnapshotInterval)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
 // TestUpdateEnvironmentTags verifies update environment tags behavior.
 func TestUpdateEnvironmentTags(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+)
+
+// GetGitRepositoryFile retrieves the current content of a stack's compose file from its
+// git repository, at the reference the stack tracks. Portainer fetches the file using the
+// stored git credential when the stack uses one.
+//
+// Parameters:
+//   - git: The git configuration of the stack
+//
+// Returns:
+//   - The file content at the head of the reference
+//   - An error if the operation fails
+func (c *PortainerClient) GetGitRepositoryFile(git models.StackGitConfig) (string, error) {
+	body := &apimodels.GitopsRepositoryFilePreviewPayload{
+		Repository:      &git.URL,
+		Reference:       git.ReferenceName,
+		TargetFile:      git.ConfigFilePath,
+		GitCredentialID: int64(git.CredentialID),
+		Username:        git.Username,
+		TlsskipVerify:   git.TLSSkipVerify,
+	}
+
+	content, err := c.cli.GitRepoFilePreview(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to get git repository file: %w", err)
+	}
+
+	return content, nil
+}
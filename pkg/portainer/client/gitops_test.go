@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetGitRepositoryFile verifies the GetGitRepositoryFile client method.
+func TestGetGitRepositoryFile(t *testing.T) {
+	git := models.StackGitConfig{
+		URL:            "https://github.com/acme/deploy.git",
+		ReferenceName:  "refs/heads/main",
+		ConfigFilePath: "compose.yml",
+		CredentialID:   3,
+		Username:       "bot",
+	}
+	url := git.URL
+	expectedBody := &apimodels.GitopsRepositoryFilePreviewPayload{
+		Repository:      &url,
+		Reference:       "refs/heads/main",
+		TargetFile:      "compose.yml",
+		GitCredentialID: 3,
+		Username:        "bot",
+	}
+
+	tests := []struct {
+		name          string
+		mockContent   string
+		mockError     error
+		expectedError bool
+	}{
+		{name: "successful retrieval", mockContent: "services: {}"},
+		{name: "API error", mockError: errors.New("authentication required"), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("GitRepoFilePreview", expectedBody).Return(tt.mockContent, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			content, err := c.GetGitRepositoryFile(git)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.mockContent, content)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
@@ -131,3 +131,18 @@ func (c *PortainerClient) GetHelmReleaseHistory(environmentId int, name, namespa
 
 	return details, nil
 }
+
+// GetHelmRelease retrieves a Helm release with its deployment status and notes.
+func (c *PortainerClient) GetHelmRelease(environmentId int, name, namespace string) (models.HelmReleaseInfo, error) {
+	var nsPtr *string
+	if namespace != "" {
+		nsPtr = &namespace
+	}
+
+	raw, err := c.cli.GetHelmRelease(int64(environmentId), name, nsPtr)
+	if err != nil {
+		return models.HelmReleaseInfo{}, fmt.Errorf("failed to get helm release: %w", err)
+	}
+
+	return models.ConvertToHelmReleaseInfo(raw), nil
+}
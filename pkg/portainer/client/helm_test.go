@@ -1,4 +1,4 @@
-// Tests for Helm release management client methods covering all 8 helm operations.
+// Tests for Helm release management client methods covering all 9 helm operations.
 // Run: go test ./pkg/portainer/client/ -run TestHelm -v
 package client
 
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -445,3 +446,67 @@ func TestGetHelmReleaseHistory(t *testing.T) {
 		})
 	}
 }
+
+// TestGetHelmRelease verifies retrieval of a Helm release with its deployment information.
+func TestGetHelmRelease(t *testing.T) {
+	tests := []struct {
+		name          string
+		envId         int
+		releaseName   string
+		namespace     string
+		mockResult    *apimodels.ReleaseRelease
+		mockError     error
+		expected      models.HelmReleaseInfo
+		expectedError bool
+	}{
+		{
+			name:        "with namespace",
+			envId:       1,
+			releaseName: "my-nginx",
+			namespace:   "web",
+			mockResult: &apimodels.ReleaseRelease{
+				Name:      "my-nginx",
+				Namespace: "web",
+				Version:   2,
+				Info:      &apimodels.ReleaseInfo{Status: "deployed", Notes: "Get the URL"},
+			},
+			expected: models.HelmReleaseInfo{Name: "my-nginx", Namespace: "web", Revision: 2, Status: "deployed", Notes: "Get the URL"},
+		},
+		{
+			name:        "without namespace",
+			envId:       1,
+			releaseName: "my-redis",
+			mockResult:  &apimodels.ReleaseRelease{Name: "my-redis", Version: 1},
+			expected:    models.HelmReleaseInfo{Name: "my-redis", Revision: 1},
+		},
+		{
+			name:          "API error",
+			envId:         1,
+			releaseName:   "nonexistent",
+			mockError:     errors.New("release not found"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			var nsPtr *string
+			if tt.namespace != "" {
+				nsPtr = &tt.namespace
+			}
+			mockAPI.On("GetHelmRelease", int64(tt.envId), tt.releaseName, nsPtr).Return(tt.mockResult, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			result, err := c.GetHelmRelease(tt.envId, tt.releaseName, tt.namespace)
+
+			if tt.expectedError {
+				assert.ErrorContains(t, err, "failed to get helm release")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
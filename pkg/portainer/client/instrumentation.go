@@ -0,0 +1,109 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Instrumentation receives a callback around every HTTP request that the client sends
+// to the Portainer API, including the requests proxied to the Docker and Kubernetes
+// APIs of the environments, so that embedders can record their own metrics.
+// Implementations must be safe for concurrent use and should return quickly, since
+// they run on the request path.
+type Instrumentation interface {
+	// CallStarted is called before a request is sent.
+	CallStarted(method, path string)
+	// CallFinished is called once per request, when its response body has been read
+	// to the end or closed, or when the request failed without a response.
+	CallFinished(call CallInfo)
+}
+
+// CallInfo describes a finished request to the Portainer API.
+type CallInfo struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the URL path of the request, without the query string. Proxied requests
+	// keep the path of the proxied API, such as /api/endpoints/1/docker/containers/json.
+	Path string
+	// Status is the HTTP status code of the response, or 0 when there was no response.
+	Status int
+	// Bytes is the number of bytes of the response body read by the client.
+	Bytes int64
+	// Duration is the time from sending the request to finishing with its response.
+	Duration time.Duration
+	// Err is the error that prevented the request from getting a response, or that
+	// interrupted the reading of its body.
+	Err error
+}
+
+// WithInstrumentation reports every request of the client to inst.
+func WithInstrumentation(inst Instrumentation) ClientOption {
+	return func(o *clientOptions) {
+		o.instrumentation = inst
+	}
+}
+
+// instrumentedTransport is an http.RoundTripper that reports the requests it sends to
+// an Instrumentation.
+type instrumentedTransport struct {
+	next http.RoundTripper
+	inst Instrumentation
+}
+
+// RoundTrip sends the request and reports it, deferring the report of a response
+// until its body is consumed.
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.inst.CallStarted(req.Method, req.URL.Path)
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.inst.CallFinished(CallInfo{Method: req.Method, Path: req.URL.Path, Duration: time.Since(start), Err: err})
+		return nil, err
+	}
+
+	body := &countingBody{ReadCloser: resp.Body}
+	body.finish = func(err error) {
+		t.inst.CallFinished(CallInfo{
+			Method:   req.Method,
+			Path:     req.URL.Path,
+			Status:   resp.StatusCode,
+			Bytes:    body.bytes,
+			Duration: time.Since(start),
+			Err:      err,
+		})
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body and calls finish once, at
+// the end of the body, on a read error or when it is closed.
+type countingBody struct {
+	io.ReadCloser
+	bytes  int64
+	once   sync.Once
+	finish func(err error)
+}
+
+// Read reads from the body, finishing the call at the end of the body or on error.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	switch {
+	case err == io.EOF:
+		b.once.Do(func() { b.finish(nil) })
+	case err != nil:
+		b.once.Do(func() { b.finish(err) })
+	}
+	return n, err
+}
+
+// Close closes the body, finishing the call if it was not already finished.
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.finish(nil) })
+	return err
+}
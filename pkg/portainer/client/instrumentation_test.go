@@ -0,0 +1,117 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingInstrumentation records the calls reported by the client.
+type recordingInstrumentation struct {
+	mu       sync.Mutex
+	started  []string
+	finished []CallInfo
+}
+
+func (r *recordingInstrumentation) CallStarted(method, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, method+" "+path)
+}
+
+func (r *recordingInstrumentation) CallFinished(call CallInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished = append(r.finished, call)
+}
+
+// TestWithInstrumentation verifies that the API and proxied requests of the client are
+// reported with their status and response size.
+func TestWithInstrumentation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/system/status":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"Version":"2.31.2"}`)
+		case "/api/endpoints/1/docker/version":
+			_, _ = io.WriteString(w, `{"ApiVersion":"1.47"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	inst := &recordingInstrumentation{}
+	c := NewPortainerClient(srv.URL, "token", WithInstrumentation(inst))
+
+	version, err := c.GetVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "2.31.2", version)
+
+	resp, err := c.ProxyDockerRequest(models.DockerProxyRequestOptions{EnvironmentID: 1, Method: http.MethodGet, Path: "/version"})
+	require.NoError(t, err)
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, []string{"GET /api/system/status", "GET /api/endpoints/1/docker/version"}, inst.started)
+	require.Len(t, inst.finished, 2, "each call is finished once")
+	assert.Equal(t, "/api/system/status", inst.finished[0].Path)
+	assert.Equal(t, http.StatusOK, inst.finished[0].Status)
+	assert.Equal(t, int64(len(`{"Version":"2.31.2"}`)), inst.finished[0].Bytes)
+	assert.NoError(t, inst.finished[0].Err)
+	assert.Equal(t, int64(len(`{"ApiVersion":"1.47"}`)), inst.finished[1].Bytes)
+	assert.Positive(t, inst.finished[1].Duration)
+}
+
+// TestInstrumentedTransportErrors verifies the reports of failed requests and of bodies
+// closed before the end.
+func TestInstrumentedTransportErrors(t *testing.T) {
+	inst := &recordingInstrumentation{}
+
+	transport := &instrumentedTransport{next: &mockRoundTripper{err: errTransport}, inst: inst}
+	req := httptest.NewRequest(http.MethodDelete, "http://portainer/api/tags/1?force=true", nil)
+	_, err := transport.RoundTrip(req)
+	require.ErrorIs(t, err, errTransport)
+
+	transport = &instrumentedTransport{next: &mockRoundTripper{statusCode: http.StatusNotFound, body: "not found"}, inst: inst}
+	resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://portainer/api/tags/2", nil))
+	require.NoError(t, err)
+	buf := make([]byte, 3)
+	_, err = resp.Body.Read(buf)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, inst.finished, 2)
+	assert.Equal(t, CallInfo{Method: http.MethodDelete, Path: "/api/tags/1", Duration: inst.finished[0].Duration, Err: errTransport}, inst.finished[0])
+	assert.Equal(t, http.StatusNotFound, inst.finished[1].Status)
+	assert.Equal(t, int64(3), inst.finished[1].Bytes, "only the bytes read are counted")
+
+	readErr := errors.New("connection reset")
+	body := &countingBody{ReadCloser: io.NopCloser(io.MultiReader(strings.NewReader("ab"), &failingReader{err: readErr}))}
+	var got []error
+	body.finish = func(err error) { got = append(got, err) }
+	_, err = io.ReadAll(body)
+	require.ErrorIs(t, err, readErr)
+	require.NoError(t, body.Close())
+	assert.Equal(t, []error{readErr}, got)
+	assert.Equal(t, int64(2), body.bytes)
+}
+
+// failingReader is an io.Reader that always fails.
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/portainer/client-api-go/v2/client"
+	"github.com/portainer/client-api-go/v2/pkg/client/endpoints"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -83,6 +84,15 @@ func (m *MockPortainerAPI) ListEdgeStacks() ([]*apimodels.PortainereeEdgeStack,
 	return args.Get(0).([]*apimodels.PortainereeEdgeStack), args.Error(1)
 }
 
+// GetEdgeStack mocks the GetEdgeStack method
+func (m *MockPortainerAPI) GetEdgeStack(id int64) (*apimodels.PortainereeEdgeStack, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apimodels.PortainereeEdgeStack), args.Error(1)
+}
+
 // ListRegularStacks mocks the ListRegularStacks method
 func (m *MockPortainerAPI) ListRegularStacks() ([]*apimodels.PortainereeStack, error) {
 	args := m.Called()
@@ -144,8 +154,8 @@ func (m *MockPortainerAPI) RemoveEnvironmentFromEndpointGroup(groupId int64, env
 }
 
 // ListEndpoints mocks the ListEndpoints method
-func (m *MockPortainerAPI) ListEndpoints() ([]*apimodels.PortainereeEndpoint, error) {
-	args := m.Called()
+func (m *MockPortainerAPI) ListEndpoints(params *endpoints.EndpointListParams) ([]*apimodels.PortainereeEndpoint, error) {
+	args := m.Called(params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -167,12 +177,45 @@ func (m *MockPortainerAPI) UpdateEndpoint(id int64, tagIds *[]int64, userAccesse
 	return args.Error(0)
 }
 
+// UpdateEndpointGroupSettings mocks the UpdateEndpointGroupSettings method
+func (m *MockPortainerAPI) UpdateEndpointGroupSettings(id int64, body map[string]any) error {
+	args := m.Called(id, body)
+	return args.Error(0)
+}
+
+// UpdateEndpointRegistryAccess mocks the UpdateEndpointRegistryAccess method
+func (m *MockPortainerAPI) UpdateEndpointRegistryAccess(endpointID, registryID int64, body map[string]any) error {
+	args := m.Called(endpointID, registryID, body)
+	return args.Error(0)
+}
+
+// UpdateEndpointSettings mocks the UpdateEndpointSettings method
+func (m *MockPortainerAPI) UpdateEndpointSettings(id int64, body map[string]any) error {
+	args := m.Called(id, body)
+	return args.Error(0)
+}
+
+// GetResponseSize mocks the GetResponseSize method
+func (m *MockPortainerAPI) GetResponseSize(path string) (int64, error) {
+	args := m.Called(path)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // DeleteEndpoint mocks the DeleteEndpoint method
 func (m *MockPortainerAPI) DeleteEndpoint(id int64) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+// CreateEndpoint mocks the CreateEndpoint method
+func (m *MockPortainerAPI) CreateEndpoint(params *endpoints.EndpointCreateParams) (*apimodels.PortainereeEndpoint, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apimodels.PortainereeEndpoint), args.Error(1)
+}
+
 // SnapshotEndpoint mocks the SnapshotEndpoint method
 func (m *MockPortainerAPI) SnapshotEndpoint(id int64) error {
 	args := m.Called(id)
@@ -194,8 +237,8 @@ func (m *MockPortainerAPI) GetSettings() (*apimodels.PortainereeSettings, error)
 	return args.Get(0).(*apimodels.PortainereeSettings), args.Error(1)
 }
 
-func (m *MockPortainerAPI) UpdateSettings(payload *apimodels.SettingsSettingsUpdatePayload) error {
-	args := m.Called(payload)
+func (m *MockPortainerAPI) UpdateSettings(body map[string]any) error {
+	args := m.Called(body)
 	return args.Error(0)
 }
 
@@ -347,6 +390,45 @@ func (m *MockPortainerAPI) DeleteUser(id int64) error {
 	return args.Error(0)
 }
 
+// GetCurrentUser mocks the GetCurrentUser method
+func (m *MockPortainerAPI) GetCurrentUser() (*apimodels.PortainereeUser, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apimodels.PortainereeUser), args.Error(1)
+}
+
+// ListUserMemberships mocks the ListUserMemberships method
+func (m *MockPortainerAPI) ListUserMemberships(id int64) ([]*apimodels.PortainerTeamMembership, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*apimodels.PortainerTeamMembership), args.Error(1)
+}
+
+// ListUserAPIKeys mocks the ListUserAPIKeys method
+func (m *MockPortainerAPI) ListUserAPIKeys(id int64) ([]*apimodels.PortainerAPIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*apimodels.PortainerAPIKey), args.Error(1)
+}
+
+// DeleteUserAPIKey mocks the DeleteUserAPIKey method
+func (m *MockPortainerAPI) DeleteUserAPIKey(userID, keyID int64) error {
+	args := m.Called(userID, keyID)
+	return args.Error(0)
+}
+
+// UpdateResourceControl mocks the UpdateResourceControl method
+func (m *MockPortainerAPI) UpdateResourceControl(id int64, payload *apimodels.ResourcecontrolsResourceControlUpdatePayload) error {
+	args := m.Called(id, payload)
+	return args.Error(0)
+}
+
 // GetSystemStatus mocks the GetSystemStatus method
 func (m *MockPortainerAPI) GetSystemStatus() (*apimodels.GithubComPortainerPortainerEeAPIHTTPHandlerSystemStatus, error) {
 	args := m.Called()
@@ -440,6 +522,12 @@ func (m *MockPortainerAPI) DeleteWebhook(id int64) error {
 	return args.Error(0)
 }
 
+// ExecuteWebhook mocks the ExecuteWebhook method
+func (m *MockPortainerAPI) ExecuteWebhook(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
 // ListRegistries mocks the ListRegistries method
 func (m *MockPortainerAPI) ListRegistries() ([]*apimodels.PortainereeRegistry, error) {
 	args := m.Called()
@@ -575,6 +663,12 @@ func (m *MockPortainerAPI) ListEdgeUpdateSchedules() ([]*apimodels.Edgeupdatesch
 	return args.Get(0).([]*apimodels.EdgeupdateschedulesDecoratedUpdateSchedule), args.Error(1)
 }
 
+// CreateEdgeUpdateSchedule mocks the CreateEdgeUpdateSchedule method
+func (m *MockPortainerAPI) CreateEdgeUpdateSchedule(payload *apimodels.EdgeupdateschedulesCreatePayload) (int64, error) {
+	args := m.Called(payload)
+	return int64(args.Int(0)), args.Error(1)
+}
+
 // AuthenticateUser mocks the AuthenticateUser method
 func (m *MockPortainerAPI) AuthenticateUser(username, password string) (*apimodels.AuthAuthenticateResponse, error) {
 	args := m.Called(username, password)
@@ -647,6 +741,14 @@ func (m *MockPortainerAPI) GetHelmReleaseHistory(environmentId int64, name strin
 	return args.Get(0).([]*apimodels.ReleaseRelease), args.Error(1)
 }
 
+func (m *MockPortainerAPI) GetHelmRelease(environmentId int64, name string, namespace *string) (*apimodels.ReleaseRelease, error) {
+	args := m.Called(environmentId, name, namespace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apimodels.ReleaseRelease), args.Error(1)
+}
+
 func (m *MockPortainerAPI) GetDockerDashboard(environmentId int64) (*apimodels.DockerDashboardResponse, error) {
 	args := m.Called(environmentId)
 	if args.Get(0) == nil {
@@ -697,6 +799,11 @@ func (m *MockPortainerAPI) StackFileInspect(id int64) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockPortainerAPI) GitRepoFilePreview(body *apimodels.GitopsRepositoryFilePreviewPayload) (string, error) {
+	args := m.Called(body)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockPortainerAPI) StackUpdateGit(id int64, endpointID int64, body *apimodels.StacksStackGitUpdatePayload) (*apimodels.PortainereeStack, error) {
 	args := m.Called(id, endpointID, body)
 	if args.Get(0) == nil {
@@ -736,3 +843,24 @@ func (m *MockPortainerAPI) StackMigrate(id int64, endpointID int64, body *apimod
 	}
 	return args.Get(0).(*apimodels.PortainereeStack), args.Error(1)
 }
+
+func (m *MockPortainerAPI) StackCreateStandalone(endpointID int64, body *apimodels.StacksComposeStackFromFileContentPayload) (*apimodels.PortainereeStack, error) {
+	args := m.Called(endpointID, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apimodels.PortainereeStack), args.Error(1)
+}
+
+func (m *MockPortainerAPI) StackUpdate(id int64, endpointID int64, body *apimodels.StacksUpdateStackPayload) (*apimodels.PortainereeStack, error) {
+	args := m.Called(id, endpointID, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apimodels.PortainereeStack), args.Error(1)
+}
+
+func (m *MockPortainerAPI) EdgeStackCreateString(body *apimodels.EdgestacksEdgeStackFromStringPayload) (int64, error) {
+	args := m.Called(body)
+	return args.Get(0).(int64), args.Error(1)
+}
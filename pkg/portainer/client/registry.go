@@ -2,6 +2,8 @@ package client
 
 import (
 	"fmt"
+	"maps"
+	"strconv"
 
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
@@ -160,3 +162,42 @@ func (c *PortainerClient) DeleteRegistry(id int) error {
 
 	return nil
 }
+
+// RemoveRegistryTeamAccess removes the access of a team to a registry on an environment,
+// keeping the other teams, users and namespaces allowed to use it there.
+//
+// Parameters:
+//   - id: The ID of the registry
+//   - environmentId: The ID of the environment
+//   - teamId: The ID of the team
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) RemoveRegistryTeamAccess(id int, environmentId int, teamId int) error {
+	rawRegistry, err := c.cli.GetRegistryByID(int64(id))
+	if err != nil {
+		return fmt.Errorf("failed to get registry: %w", err)
+	}
+
+	access := rawRegistry.RegistryAccesses[strconv.Itoa(environmentId)]
+	teams := maps.Clone(map[string]apimodels.PortainerAccessPolicy(access.TeamAccessPolicies))
+	if _, ok := teams[strconv.Itoa(teamId)]; !ok {
+		return nil
+	}
+	delete(teams, strconv.Itoa(teamId))
+
+	users := map[string]apimodels.PortainerAccessPolicy(access.UserAccessPolicies)
+	if users == nil {
+		users = map[string]apimodels.PortainerAccessPolicy{}
+	}
+	namespaces := access.Namespaces
+	if namespaces == nil {
+		namespaces = []string{}
+	}
+
+	body := map[string]any{"namespaces": namespaces, "teamAccessPolicies": teams, "userAccessPolicies": users}
+	if err := c.cli.UpdateEndpointRegistryAccess(int64(environmentId), int64(id), body); err != nil {
+		return fmt.Errorf("failed to update registry access: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // TestGetRegistries verifies get registries behavior.
@@ -305,6 +306,53 @@ func TestDeleteRegistry(t *testing.T) {
 	}
 }
 
+// TestRemoveRegistryTeamAccess verifies that removing a team from a registry keeps the
+// other accesses of the registry on the environment.
+func TestRemoveRegistryTeamAccess(t *testing.T) {
+	raw := &apimodels.PortainereeRegistry{
+		ID: 1,
+		RegistryAccesses: apimodels.PortainerRegistryAccesses{
+			"3": {
+				Namespaces:         []string{"default"},
+				TeamAccessPolicies: apimodels.PortainerTeamAccessPolicies{"7": {}, "8": {}},
+				UserAccessPolicies: apimodels.PortainerUserAccessPolicies{"5": {}},
+			},
+		},
+	}
+
+	t.Run("removes the team", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("GetRegistryByID", int64(1)).Return(raw, nil)
+		mockAPI.On("UpdateEndpointRegistryAccess", int64(3), int64(1), map[string]any{
+			"namespaces":         []string{"default"},
+			"teamAccessPolicies": map[string]apimodels.PortainerAccessPolicy{"8": {}},
+			"userAccessPolicies": map[string]apimodels.PortainerAccessPolicy{"5": {}},
+		}).Return(nil)
+
+		client := &PortainerClient{cli: mockAPI}
+		require.NoError(t, client.RemoveRegistryTeamAccess(1, 3, 7))
+		mockAPI.AssertExpectations(t)
+		assert.Len(t, raw.RegistryAccesses["3"].TeamAccessPolicies, 2, "the registry is not modified")
+	})
+
+	t.Run("team without access", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("GetRegistryByID", int64(1)).Return(raw, nil)
+
+		client := &PortainerClient{cli: mockAPI}
+		require.NoError(t, client.RemoveRegistryTeamAccess(1, 4, 7))
+		mockAPI.AssertNotCalled(t, "UpdateEndpointRegistryAccess")
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("GetRegistryByID", int64(1)).Return(nil, errors.New("not found"))
+
+		client := &PortainerClient{cli: mockAPI}
+		assert.ErrorContains(t, client.RemoveRegistryTeamAccess(1, 3, 7), "failed to get registry")
+	})
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// GetResourceControls retrieves the resource controls of the regular stacks and custom
+// templates. Resources without a resource control are skipped.
+//
+// Returns:
+//   - A slice of ResourceControl objects, stacks first
+//   - An error if the operation fails
+func (c *PortainerClient) GetResourceControls() ([]models.ResourceControl, error) {
+	rawStacks, err := c.cli.ListRegularStacks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regular stacks: %w", err)
+	}
+	rawTemplates, err := c.cli.ListCustomTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom templates: %w", err)
+	}
+
+	var controls []models.ResourceControl
+	for _, stack := range rawStacks {
+		if stack != nil && stack.ResourceControl != nil {
+			controls = append(controls, models.ConvertToResourceControl(stack.ResourceControl, models.ResourceControlTypeStack, int(stack.ID), stack.Name))
+		}
+	}
+	for _, template := range rawTemplates {
+		if template != nil && template.ResourceControl != nil {
+			controls = append(controls, models.ConvertToResourceControl(template.ResourceControl, models.ResourceControlTypeCustomTemplate, int(template.ID), template.Title))
+		}
+	}
+
+	return controls, nil
+}
+
+// UpdateResourceControl replaces the users and teams allowed by a resource control, and
+// its public and administrators-only flags.
+//
+// Parameters:
+//   - control: The resource control, with the users and teams to allow
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) UpdateResourceControl(control models.ResourceControl) error {
+	users := make([]int64, len(control.UserIDs))
+	for i, id := range control.UserIDs {
+		users[i] = int64(id)
+	}
+	teams := make([]int64, len(control.TeamIDs))
+	for i, id := range control.TeamIDs {
+		teams[i] = int64(id)
+	}
+
+	err := c.cli.UpdateResourceControl(int64(control.ID), &apimodels.ResourcecontrolsResourceControlUpdatePayload{
+		Users:              users,
+		Teams:              teams,
+		Public:             control.Public,
+		AdministratorsOnly: control.AdministratorsOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update resource control %d: %w", control.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetResourceControls verifies get resource controls behavior.
+func TestGetResourceControls(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListRegularStacks").Return([]*apimodels.PortainereeStack{
+			{ID: 1, Name: "web", ResourceControl: &apimodels.PortainerResourceControl{
+				ID:           10,
+				UserAccesses: []*apimodels.PortainerUserResourceAccess{{UserID: 4}},
+			}},
+			{ID: 2, Name: "public"},
+		}, nil)
+		mockAPI.On("ListCustomTemplates").Return([]*apimodels.PortainereeCustomTemplate{
+			{ID: 3, Title: "nginx", ResourceControl: &apimodels.PortainerResourceControl{
+				ID:           11,
+				TeamAccesses: []*apimodels.PortainerTeamResourceAccess{{TeamID: 2}},
+			}},
+		}, nil)
+
+		controls, err := (&PortainerClient{cli: mockAPI}).GetResourceControls()
+		require.NoError(t, err)
+		assert.Equal(t, []models.ResourceControl{
+			{ID: 10, ResourceType: models.ResourceControlTypeStack, ResourceID: 1, ResourceName: "web", UserIDs: []int{4}, TeamIDs: []int{}},
+			{ID: 11, ResourceType: models.ResourceControlTypeCustomTemplate, ResourceID: 3, ResourceName: "nginx", UserIDs: []int{}, TeamIDs: []int{2}},
+		}, controls)
+	})
+
+	t.Run("stack list error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListRegularStacks").Return(nil, errors.New("unavailable"))
+
+		_, err := (&PortainerClient{cli: mockAPI}).GetResourceControls()
+		assert.ErrorContains(t, err, "failed to list regular stacks: unavailable")
+	})
+
+	t.Run("template list error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListRegularStacks").Return([]*apimodels.PortainereeStack{}, nil)
+		mockAPI.On("ListCustomTemplates").Return(nil, errors.New("unavailable"))
+
+		_, err := (&PortainerClient{cli: mockAPI}).GetResourceControls()
+		assert.ErrorContains(t, err, "failed to list custom templates: unavailable")
+	})
+}
+
+// TestUpdateResourceControl verifies update resource control behavior.
+func TestUpdateResourceControl(t *testing.T) {
+	control := models.ResourceControl{ID: 10, UserIDs: []int{4, 9}, AdministratorsOnly: false}
+
+	t.Run("success", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("UpdateResourceControl", int64(10), &apimodels.ResourcecontrolsResourceControlUpdatePayload{
+			Users: []int64{4, 9},
+			Teams: []int64{},
+		}).Return(nil)
+
+		err := (&PortainerClient{cli: mockAPI}).UpdateResourceControl(control)
+		assert.NoError(t, err)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("update error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("UpdateResourceControl", int64(10), &apimodels.ResourcecontrolsResourceControlUpdatePayload{
+			Users: []int64{4, 9},
+			Teams: []int64{},
+		}).Return(errors.New("forbidden"))
+
+		err := (&PortainerClient{cli: mockAPI}).UpdateResourceControl(control)
+		assert.ErrorContains(t, err, "failed to update resource control 10: forbidden")
+	})
+}
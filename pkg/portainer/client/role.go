@@ -2,8 +2,12 @@ package client
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
 
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
 // GetRoles retrieves all roles from the Portainer server.
@@ -20,3 +24,101 @@ func (c *PortainerClient) GetRoles() ([]models.Role, error) {
 
 	return roles, nil
 }
+
+// AssignEnvironmentRole sets the role of a user or team on an environment. Unlike
+// UpdateEnvironmentUserAccesses, it accepts any role ID, including custom roles, and keeps
+// the other access policies of the environment. A role ID of 0 removes the assignment.
+//
+// Parameters:
+//   - id: The ID of the environment
+//   - assignee: models.RoleAssigneeUser or models.RoleAssigneeTeam
+//   - assigneeID: The ID of the user or team
+//   - roleID: The ID of the role to assign, or 0 to remove the assignment
+//
+// Returns:
+//   - The ID of the role previously assigned, 0 when there was none
+//   - An error if the operation fails
+func (c *PortainerClient) AssignEnvironmentRole(id int, assignee string, assigneeID int, roleID int) (int, error) {
+	raw, err := c.cli.GetEndpoint(int64(id))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get environment: %w", err)
+	}
+
+	field, policies, err := accessPoliciesOf(assignee, raw.UserAccessPolicies, raw.TeamAccessPolicies)
+	if err != nil {
+		return 0, err
+	}
+	previous := setAccessPolicyRole(policies, assigneeID, roleID)
+
+	if err := c.cli.UpdateEndpointSettings(int64(id), map[string]any{field: policies}); err != nil {
+		return 0, fmt.Errorf("failed to update environment access policies: %w", err)
+	}
+	return previous, nil
+}
+
+// AssignAccessGroupRole sets the role of a user or team on an access group, which applies
+// to the environments of the group. It accepts any role ID and keeps the other access
+// policies of the group. A role ID of 0 removes the assignment.
+//
+// Parameters:
+//   - id: The ID of the access group
+//   - assignee: models.RoleAssigneeUser or models.RoleAssigneeTeam
+//   - assigneeID: The ID of the user or team
+//   - roleID: The ID of the role to assign, or 0 to remove the assignment
+//
+// Returns:
+//   - The ID of the role previously assigned, 0 when there was none
+//   - An error if the operation fails
+func (c *PortainerClient) AssignAccessGroupRole(id int, assignee string, assigneeID int, roleID int) (int, error) {
+	rawGroups, err := c.cli.ListEndpointGroups()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list access groups: %w", err)
+	}
+	idx := slices.IndexFunc(rawGroups, func(g *apimodels.PortainerEndpointGroup) bool { return g != nil && g.ID == int64(id) })
+	if idx < 0 {
+		return 0, fmt.Errorf("access group %d not found", id)
+	}
+
+	field, policies, err := accessPoliciesOf(assignee, rawGroups[idx].UserAccessPolicies, rawGroups[idx].TeamAccessPolicies)
+	if err != nil {
+		return 0, err
+	}
+	previous := setAccessPolicyRole(policies, assigneeID, roleID)
+
+	if err := c.cli.UpdateEndpointGroupSettings(int64(id), map[string]any{field: policies}); err != nil {
+		return 0, fmt.Errorf("failed to update access group access policies: %w", err)
+	}
+	return previous, nil
+}
+
+// accessPoliciesOf returns the update field and a copy of the access policies of the
+// given kind of assignee.
+func accessPoliciesOf(assignee string, users apimodels.PortainerUserAccessPolicies, teams apimodels.PortainerTeamAccessPolicies) (string, map[string]apimodels.PortainerAccessPolicy, error) {
+	var field string
+	var policies map[string]apimodels.PortainerAccessPolicy
+	switch assignee {
+	case models.RoleAssigneeUser:
+		field, policies = "userAccessPolicies", maps.Clone(map[string]apimodels.PortainerAccessPolicy(users))
+	case models.RoleAssigneeTeam:
+		field, policies = "teamAccessPolicies", maps.Clone(map[string]apimodels.PortainerAccessPolicy(teams))
+	default:
+		return "", nil, fmt.Errorf("invalid assignee %q: expected %s or %s", assignee, models.RoleAssigneeUser, models.RoleAssigneeTeam)
+	}
+	if policies == nil {
+		policies = map[string]apimodels.PortainerAccessPolicy{}
+	}
+	return field, policies, nil
+}
+
+// setAccessPolicyRole sets the role of an assignee in a set of access policies, removing
+// the policy when roleID is 0, and returns the previous role ID.
+func setAccessPolicyRole(policies map[string]apimodels.PortainerAccessPolicy, assigneeID int, roleID int) int {
+	key := strconv.Itoa(assigneeID)
+	previous := int(policies[key].RoleID)
+	if roleID == 0 {
+		delete(policies, key)
+	} else {
+		policies[key] = apimodels.PortainerAccessPolicy{RoleID: int64(roleID)}
+	}
+	return previous
+}
@@ -89,3 +89,155 @@ func TestGetRoles(t *testing.T) {
 		})
 	}
 }
+
+// TestAssignEnvironmentRole verifies that assigning a role on an environment keeps the
+// other access policies, custom roles included.
+func TestAssignEnvironmentRole(t *testing.T) {
+	endpoint := &apimodels.PortainereeEndpoint{
+		ID: 3,
+		UserAccessPolicies: apimodels.PortainerUserAccessPolicies{
+			"2": {RoleID: 3},
+			"5": {RoleID: 7},
+		},
+		TeamAccessPolicies: apimodels.PortainerTeamAccessPolicies{
+			"1": {RoleID: 4},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		assignee         string
+		assigneeID       int
+		roleID           int
+		getError         error
+		updateError      error
+		expectedBody     map[string]any
+		expectedPrevious int
+		expectedError    string
+	}{
+		{
+			name:       "assign a custom role to a new user",
+			assignee:   models.RoleAssigneeUser,
+			assigneeID: 9,
+			roleID:     8,
+			expectedBody: map[string]any{"userAccessPolicies": map[string]apimodels.PortainerAccessPolicy{
+				"2": {RoleID: 3}, "5": {RoleID: 7}, "9": {RoleID: 8},
+			}},
+		},
+		{
+			name:       "replace the role of a team",
+			assignee:   models.RoleAssigneeTeam,
+			assigneeID: 1,
+			roleID:     1,
+			expectedBody: map[string]any{"teamAccessPolicies": map[string]apimodels.PortainerAccessPolicy{
+				"1": {RoleID: 1},
+			}},
+			expectedPrevious: 4,
+		},
+		{
+			name:       "remove the last team",
+			assignee:   models.RoleAssigneeTeam,
+			assigneeID: 1,
+			expectedBody: map[string]any{
+				"teamAccessPolicies": map[string]apimodels.PortainerAccessPolicy{},
+			},
+			expectedPrevious: 4,
+		},
+		{
+			name:          "invalid assignee",
+			assignee:      "group",
+			assigneeID:    1,
+			roleID:        1,
+			expectedError: "invalid assignee",
+		},
+		{
+			name:          "get error",
+			assignee:      models.RoleAssigneeUser,
+			assigneeID:    1,
+			roleID:        1,
+			getError:      errors.New("not found"),
+			expectedError: "failed to get environment",
+		},
+		{
+			name:       "update error",
+			assignee:   models.RoleAssigneeUser,
+			assigneeID: 2,
+			expectedBody: map[string]any{"userAccessPolicies": map[string]apimodels.PortainerAccessPolicy{
+				"5": {RoleID: 7},
+			}},
+			updateError:   errors.New("forbidden"),
+			expectedError: "failed to update environment access policies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			if tt.getError != nil {
+				mockAPI.On("GetEndpoint", int64(3)).Return(nil, tt.getError)
+			} else {
+				mockAPI.On("GetEndpoint", int64(3)).Return(endpoint, nil)
+			}
+			if tt.expectedBody != nil {
+				mockAPI.On("UpdateEndpointSettings", int64(3), tt.expectedBody).Return(tt.updateError)
+			}
+
+			client := &PortainerClient{cli: mockAPI}
+			previous, err := client.AssignEnvironmentRole(3, tt.assignee, tt.assigneeID, tt.roleID)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrevious, previous)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+	assert.Len(t, endpoint.UserAccessPolicies, 2, "the policies of the environment must not be modified")
+}
+
+// TestAssignAccessGroupRole verifies role assignments on access groups.
+func TestAssignAccessGroupRole(t *testing.T) {
+	groups := []*apimodels.PortainerEndpointGroup{
+		{ID: 1, Name: "Unassigned"},
+		{ID: 2, Name: "Production", UserAccessPolicies: apimodels.PortainerUserAccessPolicies{"4": {RoleID: 2}}},
+	}
+
+	t.Run("assign a role to a user of a group without policies", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListEndpointGroups").Return(groups, nil)
+		mockAPI.On("UpdateEndpointGroupSettings", int64(1), map[string]any{
+			"userAccessPolicies": map[string]apimodels.PortainerAccessPolicy{"4": {RoleID: 6}},
+		}).Return(nil)
+
+		client := &PortainerClient{cli: mockAPI}
+		previous, err := client.AssignAccessGroupRole(1, models.RoleAssigneeUser, 4, 6)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, previous)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("remove a role", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListEndpointGroups").Return(groups, nil)
+		mockAPI.On("UpdateEndpointGroupSettings", int64(2), map[string]any{
+			"userAccessPolicies": map[string]apimodels.PortainerAccessPolicy{},
+		}).Return(nil)
+
+		client := &PortainerClient{cli: mockAPI}
+		previous, err := client.AssignAccessGroupRole(2, models.RoleAssigneeUser, 4, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, previous)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListEndpointGroups").Return(groups, nil)
+
+		client := &PortainerClient{cli: mockAPI}
+		_, err := client.AssignAccessGroupRole(5, models.RoleAssigneeUser, 4, 1)
+		assert.ErrorContains(t, err, "access group 5 not found")
+	})
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
@@ -18,7 +20,19 @@ func (c *PortainerClient) GetSettings() (models.PortainerSettings, error) {
 	return models.ConvertSettingsToPortainerSettings(settings), nil
 }
 
-// UpdateSettings updates the Portainer settings from a JSON map.
+// GetSnapshotSettings retrieves the global schedule of environment snapshots.
+func (c *PortainerClient) GetSnapshotSettings() (models.SnapshotSettings, error) {
+	settings, err := c.cli.GetSettings()
+	if err != nil {
+		return models.SnapshotSettings{}, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	return models.ConvertToSnapshotSettings(settings), nil
+}
+
+// UpdateSettings updates the Portainer settings from a JSON map. The map is validated
+// against the settings update payload and sent as given, so that false and zero values
+// are applied.
 func (c *PortainerClient) UpdateSettings(settingsJSON map[string]interface{}) error {
 	data, err := json.Marshal(settingsJSON)
 	if err != nil {
@@ -30,7 +44,7 @@ func (c *PortainerClient) UpdateSettings(settingsJSON map[string]interface{}) er
 		return fmt.Errorf("failed to unmarshal settings payload: %w", err)
 	}
 
-	if err := c.cli.UpdateSettings(&payload); err != nil {
+	if err := c.cli.UpdateSettings(settingsJSON); err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
 	}
 
@@ -46,3 +60,70 @@ func (c *PortainerClient) GetPublicSettings() (models.PublicSettings, error) {
 
 	return models.ConvertToPublicSettings(raw), nil
 }
+
+// GetSettingsValues returns the current value of the given settings update fields, keyed
+// as given. Fields are matched case-insensitively, as Portainer does when it decodes an
+// update, and unset fields are returned with the zero value of their update payload type,
+// so that applying the result as an update restores them. Keys that are not settings
+// update fields are left out.
+func (c *PortainerClient) GetSettingsValues(keys []string) (map[string]any, error) {
+	raw, err := c.cli.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	var current map[string]any
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	values := make(map[string]any, len(keys))
+	for _, key := range keys {
+		fieldType, ok := settingsUpdateFieldType(key)
+		if !ok {
+			continue
+		}
+
+		values[key] = zeroJSONValue(fieldType)
+		for name, value := range current {
+			if strings.EqualFold(name, key) {
+				values[key] = value
+				break
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// settingsUpdateFieldType returns the type of the settings update payload field whose
+// JSON name matches key case-insensitively.
+func settingsUpdateFieldType(key string) (reflect.Type, bool) {
+	payload := reflect.TypeOf(apimodels.SettingsSettingsUpdatePayload{})
+	for i := range payload.NumField() {
+		field := payload.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && strings.EqualFold(name, key) {
+			return field.Type, true
+		}
+	}
+	return nil, false
+}
+
+// zeroJSONValue returns the JSON representation of the zero value of t, decoded as a
+// generic value (false, 0, "", nil or an object).
+func zeroJSONValue(t reflect.Type) any {
+	data, err := json.Marshal(reflect.Zero(t).Interface())
+	if err != nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return value
+}
@@ -7,7 +7,7 @@ import (
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // TestGetSettings verifies get settings behavior.
@@ -169,7 +169,7 @@ func TestUpdateSettings(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAPI := new(MockPortainerAPI)
-			mockAPI.On("UpdateSettings", mock.AnythingOfType("*models.SettingsSettingsUpdatePayload")).Return(tt.mockError)
+			mockAPI.On("UpdateSettings", tt.settings).Return(tt.mockError)
 
 			c := &PortainerClient{cli: mockAPI}
 			err := c.UpdateSettings(tt.settings)
@@ -184,6 +184,53 @@ func TestUpdateSettings(t *testing.T) {
 	}
 }
 
+// TestGetSettingsValues verifies that current values are returned for settings update
+// fields, with zero values for unset fields.
+func TestGetSettingsValues(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("GetSettings").Return(&apimodels.PortainereeSettings{
+		EnableEdgeComputeFeatures: true,
+		LogoURL:                   "https://example.com/logo.png",
+		LDAPSettings:              &apimodels.PortainereeLDAPSettings{URL: "ldap.example.com:389"},
+	}, nil)
+
+	c := &PortainerClient{cli: mockAPI}
+	values, err := c.GetSettingsValues([]string{"enableEdgeComputeFeatures", "enableTelemetry", "logoURL", "snapshotInterval", "ldapsettings", "unknownField"})
+	require.NoError(t, err)
+
+	ldap, ok := values["ldapsettings"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "ldap.example.com:389", ldap["URL"])
+	delete(values, "ldapsettings")
+	assert.Equal(t, map[string]any{
+		"enableEdgeComputeFeatures": true,
+		"enableTelemetry":           false,
+		"logoURL":                   "https://example.com/logo.png",
+		"snapshotInterval":          "",
+	}, values)
+
+	mockAPI.On("GetSettings").Unset()
+	mockAPI.On("GetSettings").Return(nil, errors.New("forbidden"))
+	_, err = c.GetSettingsValues([]string{"enableTelemetry"})
+	assert.Error(t, err)
+}
+
+// TestGetSnapshotSettings verifies retrieval of the global snapshot schedule.
+func TestGetSnapshotSettings(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("GetSettings").Return(&apimodels.PortainereeSettings{SnapshotInterval: "5m", EdgeAgentCheckinInterval: 5}, nil)
+
+	c := &PortainerClient{cli: mockAPI}
+	settings, err := c.GetSnapshotSettings()
+	require.NoError(t, err)
+	assert.Equal(t, models.SnapshotSettings{SnapshotInterval: "5m", EdgeAgentCheckinInterval: 5}, settings)
+
+	mockAPI.On("GetSettings").Unset()
+	mockAPI.On("GetSettings").Return(nil, errors.New("forbidden"))
+	_, err = c.GetSnapshotSettings()
+	assert.Error(t, err)
+}
+
 // TestGetPublicSettings verifies retrieval of public settings.
 func TestGetPublicSettings(t *testing.T) {
 	tests := []struct {
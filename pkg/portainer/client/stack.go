@@ -2,32 +2,67 @@ package client
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/utils"
 )
 
-// GetStacks retrieves all stacks from the Portainer server.
-// Stacks are the equivalent of Edge Stacks in Portainer.
+// GetStacks retrieves the stacks matching the given filters from the Portainer server.
+// Stacks are the equivalent of Edge Stacks in Portainer. The edge stack API has no
+// query parameters, so the filters are applied to the full list.
+//
+// Parameters:
+//   - opts: Filters on the name, the edge group and the overall status of the stacks
 //
 // Returns:
 //   - A slice of Stack objects
 //   - An error if the operation fails
-func (c *PortainerClient) GetStacks() ([]models.Stack, error) {
+func (c *PortainerClient) GetStacks(opts models.EdgeStackListOptions) ([]models.Stack, error) {
 	edgeStacks, err := c.cli.ListEdgeStacks()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list edge stacks: %w", err)
 	}
 
-	stacks := make([]models.Stack, len(edgeStacks))
-	for i, es := range edgeStacks {
-		stacks[i] = models.ConvertEdgeStackToStack(es)
+	name := strings.ToLower(opts.Name)
+	stacks := make([]models.Stack, 0, len(edgeStacks))
+	for _, es := range edgeStacks {
+		stack := models.ConvertEdgeStackToStack(es)
+		if !strings.Contains(strings.ToLower(stack.Name), name) {
+			continue
+		}
+		if opts.EnvironmentGroupID != 0 && !slices.Contains(stack.EnvironmentGroupIds, opts.EnvironmentGroupID) {
+			continue
+		}
+		if opts.Status != "" && stack.Status != opts.Status {
+			continue
+		}
+		stacks = append(stacks, stack)
 	}
 
 	return stacks, nil
 }
 
+// GetEdgeStackStatus retrieves the deployment status of an edge stack on each of its environments.
+//
+// Parameters:
+//   - id: The ID of the edge stack
+//
+// Returns:
+//   - The EdgeStackStatus of the edge stack
+//   - An error if the operation fails
+func (c *PortainerClient) GetEdgeStackStatus(id int) (models.EdgeStackStatus, error) {
+	raw, err := c.cli.GetEdgeStack(int64(id))
+	if err != nil {
+		return models.EdgeStackStatus{}, fmt.Errorf("failed to get edge stack: %w", err)
+	}
+
+	return models.ConvertEdgeStackStatus(raw), nil
+}
+
 // GetRegularStacks retrieves all regular (non-edge) stacks from the Portainer server.
 // Regular stacks are Docker Compose or Swarm stacks deployed to specific environments.
 //
@@ -87,6 +122,33 @@ func (c *PortainerClient) CreateStack(name, file string, environmentGroupIds []i
 	return int(id), nil
 }
 
+// CreateStackWithEnv creates an edge stack from a Docker Compose file like CreateStack,
+// with environment variables used during its deployment.
+//
+// Parameters:
+//   - name: The name of the stack
+//   - file: The file content of the stack (Compose file)
+//   - environmentGroupIds: A slice of environment group IDs to include in the stack
+//   - env: Environment variables used during deployment
+//
+// Returns:
+//   - The ID of the created stack
+//   - An error if the operation fails
+func (c *PortainerClient) CreateStackWithEnv(name, file string, environmentGroupIds []int, env map[string]string) (int, error) {
+	id, err := c.cli.EdgeStackCreateString(&apimodels.EdgestacksEdgeStackFromStringPayload{
+		Name:             &name,
+		StackFileContent: &file,
+		EdgeGroups:       utils.IntToInt64Slice(environmentGroupIds),
+		EnvVars:          toPortainerPairs(env),
+		Registries:       []int64{},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create edge stack: %w", err)
+	}
+
+	return int(id), nil
+}
+
 // UpdateStack updates an existing stack on the Portainer server.
 // This function specifically updates a Docker Compose stack.
 // Stacks are the equivalent of Edge Stacks in Portainer.
@@ -270,3 +332,133 @@ func (c *PortainerClient) MigrateStack(id int, endpointID int, targetEndpointID
 
 	return models.ConvertRegularStack(raw), nil
 }
+
+// CreateRegularStack creates and deploys a Docker Compose (standalone) stack on an environment.
+//
+// Parameters:
+//   - endpointID: The environment ID to deploy the stack to
+//   - name: The name of the stack
+//   - file: The Compose file content
+//   - env: Environment variables used during deployment
+//
+// Returns:
+//   - The created RegularStack
+//   - An error if the operation fails
+func (c *PortainerClient) CreateRegularStack(endpointID int, name string, file string, env map[string]string) (models.RegularStack, error) {
+	body := &apimodels.StacksComposeStackFromFileContentPayload{
+		Name:             &name,
+		StackFileContent: &file,
+		Env:              toPortainerPairs(env),
+	}
+
+	raw, err := c.cli.StackCreateStandalone(int64(endpointID), body)
+	if err != nil {
+		return models.RegularStack{}, fmt.Errorf("failed to create stack: %w", err)
+	}
+
+	return models.ConvertRegularStack(raw), nil
+}
+
+// UpdateRegularStack replaces the Compose file and environment variables of a regular stack and redeploys it.
+//
+// Parameters:
+//   - id: The ID of the stack to update
+//   - endpointID: The environment ID where the stack is deployed
+//   - file: The new Compose file content
+//   - env: Environment variables used during deployment
+//   - pullImage: Whether to pull the latest images before redeploying
+//   - prune: Whether to remove services no longer defined in the file
+//
+// Returns:
+//   - The updated RegularStack
+//   - An error if the operation fails
+func (c *PortainerClient) UpdateRegularStack(id int, endpointID int, file string, env map[string]string, pullImage bool, prune bool) (models.RegularStack, error) {
+	body := &apimodels.StacksUpdateStackPayload{
+		StackFileContent: file,
+		Env:              toPortainerPairs(env),
+		PullImage:        pullImage,
+		Prune:            prune,
+	}
+
+	raw, err := c.cli.StackUpdate(int64(id), int64(endpointID), body)
+	if err != nil {
+		return models.RegularStack{}, fmt.Errorf("failed to update stack: %w", err)
+	}
+
+	return models.ConvertRegularStack(raw), nil
+}
+
+// RedeployRegularStack redeploys a regular stack with its current Compose file and
+// environment variables, optionally pulling the latest version of its images first.
+//
+// Parameters:
+//   - id: The ID of the stack to redeploy
+//   - endpointID: The environment ID where the stack is deployed
+//   - pullImage: Whether to pull the latest images before redeploying
+//
+// Returns:
+//   - The redeployed RegularStack
+//   - An error if the operation fails
+func (c *PortainerClient) RedeployRegularStack(id int, endpointID int, pullImage bool) (models.RegularStack, error) {
+	raw, err := c.cli.StackInspect(int64(id))
+	if err != nil {
+		return models.RegularStack{}, fmt.Errorf("failed to inspect stack: %w", err)
+	}
+
+	file, err := c.cli.StackFileInspect(int64(id))
+	if err != nil {
+		return models.RegularStack{}, fmt.Errorf("failed to inspect stack file: %w", err)
+	}
+
+	body := &apimodels.StacksUpdateStackPayload{
+		StackFileContent: file,
+		Env:              raw.Env,
+		PullImage:        pullImage,
+	}
+
+	updated, err := c.cli.StackUpdate(int64(id), int64(endpointID), body)
+	if err != nil {
+		return models.RegularStack{}, fmt.Errorf("failed to redeploy stack: %w", err)
+	}
+
+	return models.ConvertRegularStack(updated), nil
+}
+
+// GetRegularStackEnv retrieves the environment variables of a regular stack.
+//
+// Parameters:
+//   - id: The ID of the stack
+//
+// Returns:
+//   - The environment variables of the stack, by name
+//   - An error if the operation fails
+func (c *PortainerClient) GetRegularStackEnv(id int) (map[string]string, error) {
+	raw, err := c.cli.StackInspect(int64(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect stack: %w", err)
+	}
+
+	env := make(map[string]string, len(raw.Env))
+	for _, pair := range raw.Env {
+		if pair != nil {
+			env[pair.Name] = pair.Value
+		}
+	}
+	return env, nil
+}
+
+// toPortainerPairs converts a map of environment variables to Portainer name/value pairs,
+// sorted by name so that requests are deterministic.
+func toPortainerPairs(env map[string]string) []*apimodels.PortainerPair {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]*apimodels.PortainerPair, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, &apimodels.PortainerPair{Name: name, Value: env[name]})
+	}
+	return pairs
+}
@@ -72,7 +72,7 @@ func TestGetStacks(t *testing.T) {
 
 			client := &PortainerClient{cli: mockAPI}
 
-			stacks, err := client.GetStacks()
+			stacks, err := client.GetStacks(models.EdgeStackListOptions{})
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -85,6 +85,50 @@ func TestGetStacks(t *testing.T) {
 	}
 }
 
+// TestGetStacksFilters verifies the name, edge group and status filters of GetStacks.
+func TestGetStacksFilters(t *testing.T) {
+	deployed := map[string]apimodels.PortainerEdgeStackStatus{
+		"1": {EndpointID: 1, Status: []*apimodels.PortainerEdgeStackDeploymentStatus{{Type: 7}}},
+	}
+	failed := map[string]apimodels.PortainerEdgeStackStatus{
+		"1": {EndpointID: 1, Status: []*apimodels.PortainerEdgeStackDeploymentStatus{{Type: 2, Error: "pull failed"}}},
+	}
+	rawStacks := []*apimodels.PortainereeEdgeStack{
+		{ID: 1, Name: "Web-Frontend", EdgeGroups: []int64{1, 2}, Status: deployed},
+		{ID: 2, Name: "web-backend", EdgeGroups: []int64{2}, Status: failed},
+		{ID: 3, Name: "monitoring", EdgeGroups: []int64{3}},
+	}
+
+	tests := []struct {
+		name        string
+		opts        models.EdgeStackListOptions
+		expectedIDs []int
+	}{
+		{name: "no filters", expectedIDs: []int{1, 2, 3}},
+		{name: "name", opts: models.EdgeStackListOptions{Name: "WEB"}, expectedIDs: []int{1, 2}},
+		{name: "edge group", opts: models.EdgeStackListOptions{EnvironmentGroupID: 2}, expectedIDs: []int{1, 2}},
+		{name: "status", opts: models.EdgeStackListOptions{Status: models.StackStatusFailed}, expectedIDs: []int{2}},
+		{name: "combined", opts: models.EdgeStackListOptions{Name: "web", Status: models.StackStatusDeployed}, expectedIDs: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ListEdgeStacks").Return(rawStacks, nil)
+
+			client := &PortainerClient{cli: mockAPI}
+			stacks, err := client.GetStacks(tt.opts)
+			assert.NoError(t, err)
+
+			ids := make([]int, 0, len(stacks))
+			for _, stack := range stacks {
+				ids = append(ids, stack.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
 // TestGetStackFile verifies get stack file behavior.
 func TestGetStackFile(t *testing.T) {
 	tests := []struct {
@@ -179,6 +223,34 @@ func TestCreateStack(t *testing.T) {
 	}
 }
 
+// TestCreateStackWithEnv verifies that the environment variables of an edge stack are sent
+// sorted by name.
+func TestCreateStackWithEnv(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("EdgeStackCreateString", mock.MatchedBy(func(body *apimodels.EdgestacksEdgeStackFromStringPayload) bool {
+			return *body.Name == "web" && *body.StackFileContent == "services: {}" &&
+				assert.ObjectsAreEqual([]int64{2, 3}, body.EdgeGroups) &&
+				assert.ObjectsAreEqual([]*apimodels.PortainerPair{{Name: "PORT", Value: "80"}, {Name: "TAG", Value: "1"}}, body.EnvVars)
+		})).Return(int64(9), nil)
+
+		c := &PortainerClient{cli: mockAPI}
+		id, err := c.CreateStackWithEnv("web", "services: {}", []int{2, 3}, map[string]string{"TAG": "1", "PORT": "80"})
+		assert.NoError(t, err)
+		assert.Equal(t, 9, id)
+		mockAPI.AssertExpectations(t)
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("EdgeStackCreateString", mock.Anything).Return(int64(0), errors.New("name already used"))
+
+		c := &PortainerClient{cli: mockAPI}
+		_, err := c.CreateStackWithEnv("web", "services: {}", []int{2}, nil)
+		assert.ErrorContains(t, err, "failed to create edge stack: name already used")
+	})
+}
+
 // TestUpdateStack verifies update stack behavior.
 func TestUpdateStack(t *testing.T) {
 	tests := []struct {
@@ -651,3 +723,218 @@ func TestMigrateStack(t *testing.T) {
 		})
 	}
 }
+
+// TestCreateRegularStack verifies the CreateRegularStack client method.
+func TestCreateRegularStack(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		mockResult    *apimodels.PortainereeStack
+		mockError     error
+		expectedError bool
+		expectedEnv   []*apimodels.PortainerPair
+	}{
+		{
+			name:        "successful creation with sorted env",
+			env:         map[string]string{"TAG": "1", "PORT": "80"},
+			mockResult:  &apimodels.PortainereeStack{ID: 7, Name: "web", EndpointID: 1},
+			expectedEnv: []*apimodels.PortainerPair{{Name: "PORT", Value: "80"}, {Name: "TAG", Value: "1"}},
+		},
+		{
+			name:          "API error",
+			mockError:     errors.New("name already used"),
+			expectedError: true,
+			expectedEnv:   []*apimodels.PortainerPair{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("StackCreateStandalone", int64(1), mock.MatchedBy(func(body *apimodels.StacksComposeStackFromFileContentPayload) bool {
+				return *body.Name == "web" && *body.StackFileContent == "services: {}" && assert.ObjectsAreEqual(tt.expectedEnv, body.Env)
+			})).Return(tt.mockResult, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			result, err := c.CreateRegularStack(1, "web", "services: {}", tt.env)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 7, result.ID)
+				assert.Equal(t, "web", result.Name)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestUpdateRegularStack verifies the UpdateRegularStack client method.
+func TestUpdateRegularStack(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResult    *apimodels.PortainereeStack
+		mockError     error
+		expectedError bool
+	}{
+		{
+			name:       "successful update",
+			mockResult: &apimodels.PortainereeStack{ID: 7, Name: "web", EndpointID: 1},
+		},
+		{
+			name:          "API error",
+			mockError:     errors.New("stack not found"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("StackUpdate", int64(7), int64(1), &apimodels.StacksUpdateStackPayload{
+				StackFileContent: "services: {}",
+				Env:              []*apimodels.PortainerPair{{Name: "TAG", Value: "2"}},
+				PullImage:        true,
+			}).Return(tt.mockResult, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			result, err := c.UpdateRegularStack(7, 1, "services: {}", map[string]string{"TAG": "2"}, true, false)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 7, result.ID)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRedeployRegularStack verifies the RedeployRegularStack client method.
+func TestRedeployRegularStack(t *testing.T) {
+	tests := []struct {
+		name          string
+		inspectError  error
+		fileError     error
+		updateError   error
+		expectUpdate  bool
+		expectedError bool
+	}{
+		{
+			name:         "redeploys with current file and environment",
+			expectUpdate: true,
+		},
+		{
+			name:          "inspect error",
+			inspectError:  errors.New("stack not found"),
+			expectedError: true,
+		},
+		{
+			name:          "file error",
+			fileError:     errors.New("file not found"),
+			expectedError: true,
+		},
+		{
+			name:          "update error",
+			updateError:   errors.New("pull failed"),
+			expectUpdate:  true,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := []*apimodels.PortainerPair{{Name: "TAG", Value: "2"}}
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("StackInspect", int64(7)).Return(&apimodels.PortainereeStack{ID: 7, EndpointID: 1, Env: env}, tt.inspectError)
+			if tt.inspectError == nil {
+				mockAPI.On("StackFileInspect", int64(7)).Return("services: {}", tt.fileError)
+			}
+			if tt.expectUpdate {
+				mockAPI.On("StackUpdate", int64(7), int64(1), &apimodels.StacksUpdateStackPayload{
+					StackFileContent: "services: {}",
+					Env:              env,
+					PullImage:        true,
+				}).Return(&apimodels.PortainereeStack{ID: 7, Name: "web", EndpointID: 1}, tt.updateError)
+			}
+
+			c := &PortainerClient{cli: mockAPI}
+			result, err := c.RedeployRegularStack(7, 1, true)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "web", result.Name)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetRegularStackEnv verifies the environment variables read from a regular stack.
+func TestGetRegularStackEnv(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("StackInspect", int64(7)).Return(&apimodels.PortainereeStack{ID: 7, Env: []*apimodels.PortainerPair{{Name: "TAG", Value: "1"}, nil}}, nil)
+	mockAPI.On("StackInspect", int64(8)).Return(nil, errors.New("stack not found"))
+
+	c := &PortainerClient{cli: mockAPI}
+	env, err := c.GetRegularStackEnv(7)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"TAG": "1"}, env)
+
+	_, err = c.GetRegularStackEnv(8)
+	assert.ErrorContains(t, err, "failed to inspect stack")
+}
+
+// TestGetEdgeStackStatus verifies the GetEdgeStackStatus client method.
+func TestGetEdgeStackStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResult    *apimodels.PortainereeEdgeStack
+		mockError     error
+		expected      models.EdgeStackStatus
+		expectedError bool
+	}{
+		{
+			name: "successful retrieval",
+			mockResult: &apimodels.PortainereeEdgeStack{
+				ID:   3,
+				Name: "edge-web",
+				Status: map[string]apimodels.PortainerEdgeStackStatus{
+					"1": {EndpointID: 1, Status: []*apimodels.PortainerEdgeStackDeploymentStatus{{Type: 7, Time: 10}}},
+				},
+			},
+			expected: models.EdgeStackStatus{
+				ID:           3,
+				Name:         "edge-web",
+				Environments: []models.EdgeStackEnvironmentStatus{{EnvironmentID: 1, Status: models.EdgeStackStatusRunning}},
+			},
+		},
+		{
+			name:          "API error",
+			mockError:     errors.New("edge stack not found"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("GetEdgeStack", int64(3)).Return(tt.mockResult, tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			result, err := c.GetEdgeStackStatus(3)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
@@ -19,3 +19,18 @@ func (c *PortainerClient) GetSystemStatus() (models.SystemStatus, error) {
 
 	return models.ConvertToSystemStatus(rawStatus), nil
 }
+
+// ProbeAPI sends a GET request to a Portainer API path, relative to /api, and returns the
+// size of the response body. It is used to measure the latency of the API.
+//
+// Returns:
+//   - The size of the response body in bytes
+//   - An error if the request fails or the API returns an error status
+func (c *PortainerClient) ProbeAPI(path string) (int64, error) {
+	size, err := c.cli.GetResponseSize(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe %s: %w", path, err)
+	}
+
+	return size, nil
+}
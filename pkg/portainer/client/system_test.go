@@ -55,3 +55,26 @@ func TestGetSystemStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestProbeAPI(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("GetResponseSize", "/endpoints").Return(int64(2048), nil)
+
+		client := &PortainerClient{cli: mockAPI}
+
+		size, err := client.ProbeAPI("/endpoints")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2048), size)
+		mockAPI.AssertExpectations(t)
+	})
+	t.Run("error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("GetResponseSize", "/settings").Return(int64(0), errors.New("unexpected status 403"))
+
+		client := &PortainerClient{cli: mockAPI}
+
+		_, err := client.ProbeAPI("/settings")
+		assert.ErrorContains(t, err, "failed to probe /settings")
+	})
+}
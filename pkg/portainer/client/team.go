@@ -148,3 +148,29 @@ func (c *PortainerClient) UpdateTeamMembers(teamId int, userIds []int) error {
 
 	return nil
 }
+
+// RemoveTeamMember removes a user from a team, leaving the other members untouched.
+//
+// Parameters:
+//   - teamID: The ID of the team
+//   - userID: The ID of the user to remove from the team
+//
+// Returns:
+//   - An error if the operation fails or the user is not a member of the team
+func (c *PortainerClient) RemoveTeamMember(teamID, userID int) error {
+	memberships, err := c.cli.ListTeamMemberships()
+	if err != nil {
+		return fmt.Errorf("failed to list team memberships: %w", err)
+	}
+
+	for _, membership := range memberships {
+		if membership != nil && membership.TeamID == int64(teamID) && membership.UserID == int64(userID) {
+			if err := c.cli.DeleteTeamMembership(int(membership.ID)); err != nil {
+				return fmt.Errorf("failed to delete team membership for user %d: %w", userID, err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("user %d is not a member of team %d", userID, teamID)
+}
@@ -452,3 +452,41 @@ func TestDeleteTeam(t *testing.T) {
 		})
 	}
 }
+
+// TestRemoveTeamMember verifies remove team member behavior.
+func TestRemoveTeamMember(t *testing.T) {
+	memberships := []*apimodels.PortainerTeamMembership{
+		{ID: 1, TeamID: 4, UserID: 10},
+		{ID: 2, TeamID: 4, UserID: 11},
+		{ID: 3, TeamID: 5, UserID: 10},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListTeamMemberships").Return(memberships, nil)
+		mockAPI.On("DeleteTeamMembership", 3).Return(nil)
+
+		err := (&PortainerClient{cli: mockAPI}).RemoveTeamMember(5, 10)
+		assert.NoError(t, err)
+		mockAPI.AssertExpectations(t)
+		mockAPI.AssertNumberOfCalls(t, "DeleteTeamMembership", 1)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListTeamMemberships").Return(memberships, nil)
+
+		err := (&PortainerClient{cli: mockAPI}).RemoveTeamMember(5, 11)
+		assert.ErrorContains(t, err, "user 11 is not a member of team 5")
+		mockAPI.AssertNotCalled(t, "DeleteTeamMembership", 2)
+	})
+
+	t.Run("delete error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListTeamMemberships").Return(memberships, nil)
+		mockAPI.On("DeleteTeamMembership", 1).Return(errors.New("forbidden"))
+
+		err := (&PortainerClient{cli: mockAPI}).RemoveTeamMember(4, 10)
+		assert.ErrorContains(t, err, "failed to delete team membership for user 10: forbidden")
+	})
+}
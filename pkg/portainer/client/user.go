@@ -2,29 +2,115 @@ package client
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
 )
 
-// GetUsers retrieves all users from the Portainer server.
+// GetUsers retrieves the users matching the given filters from the Portainer server.
+// The team filter is resolved from the team memberships, fetched only when it is set.
+//
+// Parameters:
+//   - opts: Filters on the username, role and team of the users
 //
 // Returns:
 //   - A slice of User objects containing user information
 //   - An error if the operation fails
-func (c *PortainerClient) GetUsers() ([]models.User, error) {
+func (c *PortainerClient) GetUsers(opts models.UserListOptions) ([]models.User, error) {
 	portainerUsers, err := c.cli.ListUsers()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
-	users := make([]models.User, len(portainerUsers))
-	for i, user := range portainerUsers {
-		users[i] = models.ConvertToUser(user)
+	var members map[int]bool
+	if opts.TeamID != 0 {
+		memberships, err := c.cli.ListTeamMemberships()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list team memberships: %w", err)
+		}
+		members = map[int]bool{}
+		for _, membership := range memberships {
+			if membership != nil && int(membership.TeamID) == opts.TeamID {
+				members[int(membership.UserID)] = true
+			}
+		}
+	}
+
+	name := strings.ToLower(opts.Name)
+	users := make([]models.User, 0, len(portainerUsers))
+	for _, raw := range portainerUsers {
+		user := models.ConvertToUser(raw)
+		if !strings.Contains(strings.ToLower(user.Username), name) {
+			continue
+		}
+		if opts.Role != "" && user.Role != opts.Role {
+			continue
+		}
+		if members != nil && !members[user.ID] {
+			continue
+		}
+		users = append(users, user)
 	}
 
 	return users, nil
 }
 
+// HasAPITokens reports whether a user owns API tokens.
+//
+// Parameters:
+//   - id: The ID of the user
+//
+// Returns:
+//   - True when the user has at least one API token
+//   - An error if the operation fails
+func (c *PortainerClient) HasAPITokens(id int) (bool, error) {
+	keys, err := c.cli.ListUserAPIKeys(int64(id))
+	if err != nil {
+		return false, fmt.Errorf("failed to list user API keys: %w", err)
+	}
+	return len(keys) > 0, nil
+}
+
+// GetUserAPITokens retrieves the API tokens of a user.
+//
+// Parameters:
+//   - id: The ID of the user
+//
+// Returns:
+//   - A slice of APIToken objects, without the token values
+//   - An error if the operation fails
+func (c *PortainerClient) GetUserAPITokens(id int) ([]models.APIToken, error) {
+	keys, err := c.cli.ListUserAPIKeys(int64(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user API keys: %w", err)
+	}
+
+	tokens := make([]models.APIToken, 0, len(keys))
+	for _, key := range keys {
+		if key != nil {
+			tokens = append(tokens, models.ConvertToAPIToken(key))
+		}
+	}
+
+	return tokens, nil
+}
+
+// RevokeUserAPIToken revokes an API token of a user.
+//
+// Parameters:
+//   - userID: The ID of the user owning the token
+//   - tokenID: The ID of the token to revoke
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) RevokeUserAPIToken(userID, tokenID int) error {
+	if err := c.cli.DeleteUserAPIKey(int64(userID), int64(tokenID)); err != nil {
+		return fmt.Errorf("failed to revoke API token %d of user %d: %w", tokenID, userID, err)
+	}
+
+	return nil
+}
+
 // CreateUser creates a new user on the Portainer server.
 //
 // Parameters:
@@ -66,6 +152,42 @@ func (c *PortainerClient) GetUser(id int) (models.User, error) {
 	return models.ConvertToUser(portainerUser), nil
 }
 
+// GetCurrentUser retrieves the user that owns the API token used by the client.
+//
+// Returns:
+//   - A User object containing user information
+//   - An error if the operation fails
+func (c *PortainerClient) GetCurrentUser() (models.User, error) {
+	portainerUser, err := c.cli.GetCurrentUser()
+	if err != nil {
+		return models.User{}, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return models.ConvertToUser(portainerUser), nil
+}
+
+// GetUserMemberships retrieves the team memberships of a user.
+//
+// Parameters:
+//   - id: The ID of the user
+//
+// Returns:
+//   - A slice of TeamMembership objects, one per team the user belongs to
+//   - An error if the operation fails
+func (c *PortainerClient) GetUserMemberships(id int) ([]models.TeamMembership, error) {
+	portainerMemberships, err := c.cli.ListUserMemberships(int64(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user memberships: %w", err)
+	}
+
+	memberships := make([]models.TeamMembership, len(portainerMemberships))
+	for i, membership := range portainerMemberships {
+		memberships[i] = models.ConvertToTeamMembership(membership)
+	}
+
+	return memberships, nil
+}
+
 // DeleteUser deletes a user from the Portainer server.
 //
 // Parameters:
@@ -84,7 +84,7 @@ func TestGetUsers(t *testing.T) {
 
 			client := &PortainerClient{cli: mockAPI}
 
-			users, err := client.GetUsers()
+			users, err := client.GetUsers(models.UserListOptions{})
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -97,6 +97,121 @@ func TestGetUsers(t *testing.T) {
 	}
 }
 
+// TestGetUsersFilters verifies the name, role and team filters of GetUsers.
+func TestGetUsersFilters(t *testing.T) {
+	rawUsers := []*apimodels.PortainereeUser{
+		{ID: 1, Username: "admin", Role: 1},
+		{ID: 2, Username: "Alice", Role: 2},
+		{ID: 3, Username: "bob", Role: 2},
+	}
+	memberships := []*apimodels.PortainerTeamMembership{
+		{ID: 1, TeamID: 7, UserID: 2},
+		{ID: 2, TeamID: 7, UserID: 1},
+		{ID: 3, TeamID: 8, UserID: 3},
+	}
+
+	tests := []struct {
+		name        string
+		opts        models.UserListOptions
+		expectedIDs []int
+	}{
+		{name: "name is case-insensitive", opts: models.UserListOptions{Name: "ALI"}, expectedIDs: []int{2}},
+		{name: "role", opts: models.UserListOptions{Role: models.UserRoleUser}, expectedIDs: []int{2, 3}},
+		{name: "team", opts: models.UserListOptions{TeamID: 7}, expectedIDs: []int{1, 2}},
+		{name: "team and role", opts: models.UserListOptions{TeamID: 7, Role: models.UserRoleUser}, expectedIDs: []int{2}},
+		{name: "team without members", opts: models.UserListOptions{TeamID: 9}, expectedIDs: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ListUsers").Return(rawUsers, nil)
+			if tt.opts.TeamID != 0 {
+				mockAPI.On("ListTeamMemberships").Return(memberships, nil)
+			}
+
+			client := &PortainerClient{cli: mockAPI}
+			users, err := client.GetUsers(tt.opts)
+			assert.NoError(t, err)
+
+			ids := make([]int, 0, len(users))
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			mockAPI.AssertExpectations(t)
+		})
+	}
+
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("ListUsers").Return(rawUsers, nil)
+	mockAPI.On("ListTeamMemberships").Return(nil, errors.New("forbidden"))
+	_, err := (&PortainerClient{cli: mockAPI}).GetUsers(models.UserListOptions{TeamID: 7})
+	assert.ErrorContains(t, err, "failed to list team memberships")
+}
+
+// TestHasAPITokens verifies the API token lookup of a user.
+func TestHasAPITokens(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockKeys      []*apimodels.PortainerAPIKey
+		mockError     error
+		expected      bool
+		expectedError bool
+	}{
+		{name: "with tokens", mockKeys: []*apimodels.PortainerAPIKey{{ID: 1, UserID: 2}}, expected: true},
+		{name: "without tokens", mockKeys: []*apimodels.PortainerAPIKey{}},
+		{name: "list error", mockError: errors.New("forbidden"), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ListUserAPIKeys", int64(2)).Return(tt.mockKeys, tt.mockError)
+
+			hasTokens, err := (&PortainerClient{cli: mockAPI}).HasAPITokens(2)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, hasTokens)
+		})
+	}
+}
+
+// TestGetUserAPITokens verifies get user API tokens behavior.
+func TestGetUserAPITokens(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListUserAPIKeys", int64(2)).Return([]*apimodels.PortainerAPIKey{{ID: 7, UserID: 2, Description: "ci", Prefix: "ptr_abc"}, nil}, nil)
+
+		tokens, err := (&PortainerClient{cli: mockAPI}).GetUserAPITokens(2)
+		assert.NoError(t, err)
+		assert.Equal(t, []models.APIToken{{ID: 7, Description: "ci", Prefix: "ptr_abc"}}, tokens)
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		mockAPI := new(MockPortainerAPI)
+		mockAPI.On("ListUserAPIKeys", int64(2)).Return(nil, errors.New("forbidden"))
+
+		_, err := (&PortainerClient{cli: mockAPI}).GetUserAPITokens(2)
+		assert.ErrorContains(t, err, "failed to list user API keys: forbidden")
+	})
+}
+
+// TestRevokeUserAPIToken verifies revoke user API token behavior.
+func TestRevokeUserAPIToken(t *testing.T) {
+	mockAPI := new(MockPortainerAPI)
+	mockAPI.On("DeleteUserAPIKey", int64(2), int64(7)).Return(nil)
+	mockAPI.On("DeleteUserAPIKey", int64(2), int64(8)).Return(errors.New("not found"))
+	c := &PortainerClient{cli: mockAPI}
+
+	assert.NoError(t, c.RevokeUserAPIToken(2, 7))
+	assert.ErrorContains(t, c.RevokeUserAPIToken(2, 8), "failed to revoke API token 8 of user 2: not found")
+	mockAPI.AssertExpectations(t)
+}
+
 // TestUpdateUserRole verifies update user role behavior.
 func TestUpdateUserRole(t *testing.T) {
 	tests := []struct {
@@ -323,3 +438,100 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+// TestGetCurrentUser verifies get current user behavior.
+func TestGetCurrentUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockUser      *apimodels.PortainereeUser
+		mockError     error
+		expected      models.User
+		expectedError bool
+	}{
+		{
+			name:     "successful retrieval",
+			mockUser: &apimodels.PortainereeUser{ID: 3, Username: "operator", Role: 1},
+			expected: models.User{ID: 3, Username: "operator", Role: "admin"},
+		},
+		{
+			name:          "get current user error",
+			mockError:     errors.New("unauthorized"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("GetCurrentUser").Return(tt.mockUser, tt.mockError)
+
+			client := &PortainerClient{cli: mockAPI}
+
+			user, err := client.GetCurrentUser()
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, user)
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
+
+// TestGetUserMemberships verifies get user memberships behavior.
+func TestGetUserMemberships(t *testing.T) {
+	tests := []struct {
+		name            string
+		userID          int
+		mockMemberships []*apimodels.PortainerTeamMembership
+		mockError       error
+		expected        []models.TeamMembership
+		expectedError   bool
+	}{
+		{
+			name:   "successful retrieval",
+			userID: 3,
+			mockMemberships: []*apimodels.PortainerTeamMembership{
+				{ID: 1, TeamID: 1, UserID: 3, Role: 1},
+				{ID: 2, TeamID: 4, UserID: 3, Role: 2},
+			},
+			expected: []models.TeamMembership{
+				{TeamID: 1, UserID: 3, Role: models.TeamMembershipRoleLeader},
+				{TeamID: 4, UserID: 3, Role: models.TeamMembershipRoleMember},
+			},
+		},
+		{
+			name:            "no memberships",
+			userID:          3,
+			mockMemberships: []*apimodels.PortainerTeamMembership{},
+			expected:        []models.TeamMembership{},
+		},
+		{
+			name:          "list memberships error",
+			userID:        3,
+			mockError:     errors.New("forbidden"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ListUserMemberships", int64(tt.userID)).Return(tt.mockMemberships, tt.mockError)
+
+			client := &PortainerClient{cli: mockAPI}
+
+			memberships, err := client.GetUserMemberships(tt.userID)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, memberships)
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
@@ -59,3 +59,19 @@ func (c *PortainerClient) DeleteWebhook(id int) error {
 
 	return nil
 }
+
+// TriggerWebhook triggers a webhook. A service webhook pulls the image of its Swarm service
+// and force-updates the service; a container webhook recreates its container.
+//
+// Parameters:
+//   - token: The token of the webhook
+//
+// Returns:
+//   - An error if the operation fails
+func (c *PortainerClient) TriggerWebhook(token string) error {
+	if err := c.cli.ExecuteWebhook(token); err != nil {
+		return fmt.Errorf("failed to trigger webhook: %w", err)
+	}
+
+	return nil
+}
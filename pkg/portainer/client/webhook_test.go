@@ -1,4 +1,4 @@
-// Tests for webhook management client methods (GetWebhooks, CreateWebhook, DeleteWebhook).
+// Tests for webhook management client methods (GetWebhooks, CreateWebhook, DeleteWebhook, TriggerWebhook).
 // Run: go test ./pkg/portainer/client/ -run TestWebhook -v
 package client
 
@@ -146,3 +146,38 @@ func TestDeleteWebhook(t *testing.T) {
 		})
 	}
 }
+
+// TestTriggerWebhook verifies triggering a webhook by token.
+func TestTriggerWebhook(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockError     error
+		expectedError bool
+	}{
+		{
+			name: "successful trigger",
+		},
+		{
+			name:          "API error",
+			mockError:     errors.New("webhook not found"),
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAPI := new(MockPortainerAPI)
+			mockAPI.On("ExecuteWebhook", "token-1").Return(tt.mockError)
+
+			c := &PortainerClient{cli: mockAPI}
+			err := c.TriggerWebhook("token-1")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockAPI.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Portainer environment types, set from the creation type of a created environment
+var endpointTypeByCreationType = map[int]int{
+	1: 1, // local Docker
+	2: 2, // agent
+	4: 4, // edge agent
+	5: 5, // local Kubernetes
+}
+
+// Portainer regular stack statuses and types
+const (
+	stackStatusActive   = 1
+	stackStatusInactive = 2
+	stackTypeCompose    = 2
+)
+
+// registerRoutes registers the built-in routes
+func (s *Server) registerRoutes() {
+	for name, def := range collectionDefs {
+		base := apiPrefix + "/" + name
+		s.routes.HandleFunc("GET "+base, s.handleList(name))
+		s.routes.HandleFunc("GET "+base+"/{id}", s.handleGet(name))
+		s.routes.HandleFunc("PUT "+base+"/{id}", s.handleUpdate(name))
+		s.routes.HandleFunc("DELETE "+base+"/{id}", s.handleDelete(name))
+		if def.createPath != "-" {
+			s.routes.HandleFunc("POST "+base+def.createPath, s.handleCreate(name, nil))
+		}
+		if def.fileKey != "" {
+			s.routes.HandleFunc("GET "+base+"/{id}/file", s.handleFile(name))
+		}
+	}
+
+	s.routes.HandleFunc("POST /api/endpoints", s.handleCreateEndpoint)
+	s.routes.HandleFunc("POST /api/endpoints/snapshot", noContent)
+	s.routes.HandleFunc("POST /api/endpoints/{id}/snapshot", s.handleExists("endpoints", noContent))
+	s.routes.HandleFunc("PUT /api/endpoint_groups/{id}/endpoints/{endpointId}", s.handleEndpointGroupMember(true))
+	s.routes.HandleFunc("DELETE /api/endpoint_groups/{id}/endpoints/{endpointId}", s.handleEndpointGroupMember(false))
+
+	s.routes.HandleFunc("POST /api/stacks/create/standalone/string", s.handleCreate("stacks", func(r *http.Request, stack map[string]any) {
+		stack["EndpointId"], _ = strconv.Atoi(r.URL.Query().Get("endpointId"))
+		stack["Type"] = stackTypeCompose
+		stack["Status"] = stackStatusActive
+	}))
+	s.routes.HandleFunc("POST /api/stacks/{id}/start", s.handleStackStatus(stackStatusActive))
+	s.routes.HandleFunc("POST /api/stacks/{id}/stop", s.handleStackStatus(stackStatusInactive))
+
+	s.routes.HandleFunc("GET /api/users/me", s.handleCurrentUser)
+	s.routes.HandleFunc("GET /api/users/{id}/memberships", s.handleMemberships("UserID"))
+	s.routes.HandleFunc("GET /api/teams/{id}/memberships", s.handleMemberships("TeamID"))
+
+	s.routes.HandleFunc("GET /api/system/status", s.handleValue(func(st *state) any { return st.status }))
+	s.routes.HandleFunc("GET /api/system/version", s.handleValue(func(st *state) any {
+		return map[string]any{"ServerVersion": st.status["Version"], "LatestVersion": st.status["Version"], "UpdateAvailable": false}
+	}))
+	s.routes.HandleFunc("GET /api/settings", s.handleValue(func(st *state) any { return st.settings }))
+	s.routes.HandleFunc("PUT /api/settings", s.handleUpdateSettings)
+	s.routes.HandleFunc("GET /api/settings/public", s.handleValue(func(st *state) any { return st.publicSettings() }))
+	s.routes.HandleFunc("GET /api/motd", s.handleValue(func(st *state) any { return st.motd }))
+	s.routes.HandleFunc("GET /api/roles", s.handleValue(func(st *state) any { return st.roles }))
+}
+
+func (s *Server) handleList(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		items := s.state.collections[name].list()
+		s.mu.Unlock()
+
+		if name == "endpoints" {
+			w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+			w.Header().Set("X-Total-Available", strconv.Itoa(len(items)))
+		}
+		writeJSON(w, http.StatusOK, items)
+	}
+}
+
+func (s *Server) handleGet(name string) http.HandlerFunc {
+	return s.withID(name, func(w http.ResponseWriter, r *http.Request, c *collection, id int) {
+		item, _ := c.get(id)
+		writeJSON(w, http.StatusOK, item)
+	})
+}
+
+func (s *Server) handleUpdate(name string) http.HandlerFunc {
+	return s.withID(name, func(w http.ResponseWriter, r *http.Request, c *collection, id int) {
+		var fields map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		item, _ := c.update(id, fields)
+		writeJSON(w, http.StatusOK, item)
+	})
+}
+
+func (s *Server) handleDelete(name string) http.HandlerFunc {
+	return s.withID(name, func(w http.ResponseWriter, r *http.Request, c *collection, id int) {
+		c.remove(id)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) handleFile(name string) http.HandlerFunc {
+	return s.withID(name, func(w http.ResponseWriter, r *http.Request, c *collection, id int) {
+		writeJSON(w, http.StatusOK, map[string]string{c.fileKey: c.files[id]})
+	})
+}
+
+// handleCreate creates a resource from a JSON payload, after an optional hook that
+// completes the fields the API sets itself
+func (s *Server) handleCreate(name string, hook func(*http.Request, map[string]any)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request payload")
+			return
+		}
+		if hook != nil {
+			hook(r, payload)
+		}
+
+		s.mu.Lock()
+		item := s.state.collections[name].add(payload)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, item)
+	}
+}
+
+// handleCreateEndpoint creates an environment from the multipart form of the API
+func (s *Server) handleCreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	name := r.FormValue("Name")
+	creationType, _ := strconv.Atoi(r.FormValue("EndpointCreationType"))
+	endpointType, ok := endpointTypeByCreationType[creationType]
+	if name == "" || !ok {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	groupID, err := strconv.Atoi(r.FormValue("GroupID"))
+	if err != nil {
+		groupID = 1
+	}
+
+	endpoint := map[string]any{
+		"Name":      name,
+		"Type":      endpointType,
+		"URL":       r.FormValue("URL"),
+		"PublicURL": r.FormValue("PublicURL"),
+		"GroupId":   groupID,
+		"TagIds":    []int{},
+		"Status":    1,
+	}
+	if endpointType == 4 {
+		endpoint["EdgeKey"] = "fake-edge-key"
+	}
+
+	s.mu.Lock()
+	item := s.state.collections["endpoints"].add(endpoint)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, item)
+}
+
+// handleEndpointGroupMember adds an environment to an access group, or moves it back to
+// the Unassigned group
+func (s *Server) handleEndpointGroupMember(add bool) http.HandlerFunc {
+	return s.withID("endpoint_groups", func(w http.ResponseWriter, r *http.Request, _ *collection, id int) {
+		endpoints := s.state.collections["endpoints"]
+		endpointID, err := strconv.Atoi(r.PathValue("endpointId"))
+		if _, ok := endpoints.items[endpointID]; err != nil || !ok {
+			writeError(w, http.StatusNotFound, "Unable to find an environment with the specified identifier inside the database")
+			return
+		}
+		groupID := 1
+		if add {
+			groupID = id
+		}
+		endpoints.update(endpointID, map[string]any{"GroupId": groupID})
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) handleStackStatus(status int) http.HandlerFunc {
+	return s.withID("stacks", func(w http.ResponseWriter, r *http.Request, c *collection, id int) {
+		item, _ := c.update(id, map[string]any{"Status": status})
+		writeJSON(w, http.StatusOK, item)
+	})
+}
+
+func (s *Server) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.state.collections["users"].get(s.state.currentUserID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unable to find a user with the specified identifier inside the database")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// handleMemberships lists the team memberships of a user or a team
+func (s *Server) handleMemberships(field string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid identifier route variable")
+			return
+		}
+
+		s.mu.Lock()
+		memberships := s.state.collections["team_memberships"].filter(field, id)
+		s.mu.Unlock()
+		if memberships == nil {
+			memberships = []map[string]any{}
+		}
+		writeJSON(w, http.StatusOK, memberships)
+	}
+}
+
+func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, value := range normalize(fields) {
+		setField(s.state.settings, key, value)
+	}
+	writeJSON(w, http.StatusOK, s.state.settings)
+}
+
+// handleValue writes a value of the state
+func (s *Server) handleValue(value func(*state) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, value(s.state))
+	}
+}
+
+// handleExists calls a handler when the resource of the {id} path value exists
+func (s *Server) handleExists(name string, handler http.HandlerFunc) http.HandlerFunc {
+	return s.withID(name, func(w http.ResponseWriter, r *http.Request, _ *collection, _ int) {
+		handler(w, r)
+	})
+}
+
+// withID resolves the {id} path value of a resource route, answering 404 like the API
+// when the resource does not exist, and calls the handler with the lock held
+func (s *Server) withID(name string, handler func(http.ResponseWriter, *http.Request, *collection, int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid identifier route variable")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		c := s.state.collections[name]
+		if _, ok := c.items[id]; !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Unable to find an object with the specified identifier inside the database (%s %d)", name, id))
+			return
+		}
+		handler(w, r, c, id)
+	}
+}
+
+func noContent(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package fake
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed scenarios/*.yaml
+var scenarioFS embed.FS
+
+// Scenario describes the initial state of a server. Resources use the field names of the
+// Portainer API responses, so that a fixture can be written from captured API output.
+// Scenario files are YAML, or JSON, which is a subset of YAML.
+type Scenario struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// Status is merged into the /api/system/status response
+	Status map[string]any `yaml:"status" json:"status"`
+	// Settings are merged into the default /api/settings response
+	Settings map[string]any `yaml:"settings" json:"settings"`
+	// MOTD is merged into the /api/motd response
+	MOTD map[string]any `yaml:"motd" json:"motd"`
+	// Roles replace the default /api/roles response
+	Roles []map[string]any `yaml:"roles" json:"roles"`
+	// CurrentUserID is the user returned by /api/users/me, the lowest user ID when zero.
+	// An administrator with ID 1 is created when the scenario has no users.
+	CurrentUserID int `yaml:"currentUserId" json:"currentUserId"`
+	// Resources are the resources of each collection, keyed by the collection name used
+	// in the API path, such as "endpoints" or "edge_stacks". The content of a stack or
+	// template file is given in the field of its /file response, such as
+	// StackFileContent.
+	Resources map[string][]map[string]any `yaml:"resources" json:"resources"`
+	// Faults are injected when the server starts
+	Faults []Fault `yaml:"faults" json:"faults"`
+}
+
+// ParseScenario parses a YAML or JSON scenario
+func ParseScenario(data []byte) (Scenario, error) {
+	var scenario Scenario
+	if err := yaml.Unmarshal(data, &scenario); err != nil {
+		return Scenario{}, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	for name := range scenario.Resources {
+		if _, ok := collectionDefs[name]; !ok {
+			return Scenario{}, fmt.Errorf("unknown collection %q in scenario, expected one of %s", name, strings.Join(Collections(), ", "))
+		}
+	}
+	return scenario, nil
+}
+
+// LoadScenario reads a scenario file
+func LoadScenario(filename string) (Scenario, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// BuiltinScenario returns a scenario shipped with the package, by name
+func BuiltinScenario(name string) (Scenario, error) {
+	data, err := scenarioFS.ReadFile(path.Join("scenarios", name+".yaml"))
+	if err != nil {
+		return Scenario{}, fmt.Errorf("unknown scenario %q, expected one of %s", name, strings.Join(BuiltinScenarios(), ", "))
+	}
+	return ParseScenario(data)
+}
+
+// MustScenario is like BuiltinScenario but panics when the scenario does not exist
+func MustScenario(name string) Scenario {
+	scenario, err := BuiltinScenario(name)
+	if err != nil {
+		panic(err)
+	}
+	return scenario
+}
+
+// BuiltinScenarios returns the names of the scenarios shipped with the package
+func BuiltinScenarios() []string {
+	entries, _ := fs.ReadDir(scenarioFS, "scenarios")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	slices.Sort(names)
+	return names
+}
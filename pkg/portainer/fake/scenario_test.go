@@ -0,0 +1,67 @@
+package fake_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuiltinScenarios verifies that every built-in scenario parses and seeds a server.
+func TestBuiltinScenarios(t *testing.T) {
+	require.Equal(t, []string{"basic", "edge"}, fake.BuiltinScenarios())
+
+	for _, name := range fake.BuiltinScenarios() {
+		t.Run(name, func(t *testing.T) {
+			scenario, err := fake.BuiltinScenario(name)
+			require.NoError(t, err)
+			assert.Equal(t, name, scenario.Name)
+
+			srv := fake.New(fake.WithScenario(scenario))
+			defer srv.Close()
+			assert.NotEmpty(t, srv.Resources("endpoints"))
+		})
+	}
+
+	_, err := fake.BuiltinScenario("swarm")
+	assert.ErrorContains(t, err, `unknown scenario "swarm"`)
+}
+
+// TestLoadScenario verifies JSON scenario files, scenario faults and the validation of
+// collection names.
+func TestLoadScenario(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "scenario.json")
+	content := `{
+		"name": "degraded",
+		"status": {"Version": "2.27.0"},
+		"resources": {"endpoints": [{"Name": "remote", "Type": 2}]},
+		"faults": [{"method": "GET", "path": "/api/settings", "status": 503}]
+	}`
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+
+	scenario, err := fake.LoadScenario(filename)
+	require.NoError(t, err)
+	require.Len(t, scenario.Faults, 1)
+
+	_, cli := newClient(t, fake.WithScenario(scenario))
+	version, err := cli.GetVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "2.27.0", version)
+
+	environment, err := cli.GetEnvironment(1)
+	require.NoError(t, err, "Resources without an ID are numbered from 1")
+	assert.Equal(t, "remote", environment.Name)
+
+	_, err = cli.GetSettings()
+	assert.Error(t, err, "The scenario fault applies")
+
+	_, err = fake.ParseScenario([]byte("resources:\n  containers: []\n"))
+	assert.ErrorContains(t, err, `unknown collection "containers"`)
+
+	_, err = fake.LoadScenario(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,294 @@
+// Package fake provides an in-memory Portainer API server for tests. It implements the
+// subset of the Portainer API used by the client package, records every request it
+// receives, and starts from scenario fixtures that describe the resources of a Portainer
+// instance. Routes it does not implement can be added with Server.Handle, and failures
+// can be injected per route with Server.Fail.
+//
+// Example:
+//
+//	srv := fake.New(fake.WithScenario(fake.MustScenario("basic")))
+//	defer srv.Close()
+//	cli := client.NewPortainerClient(srv.URL(), srv.Token())
+package fake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultToken is the API token accepted by a server created without WithToken
+	DefaultToken = "ptr_fake_token"
+	// DefaultVersion is the Portainer version reported by a scenario without a status
+	DefaultVersion = "2.31.2"
+
+	apiPrefix = "/api"
+)
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	// Path is the URL path of the request, including the /api prefix
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the body of the request into v
+func (r Request) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Fault makes the requests matching a method and a path fail with an HTTP status
+type Fault struct {
+	// Method of the failing requests, any method when empty
+	Method string `yaml:"method" json:"method"`
+	// Path of the failing requests, including the /api prefix, as a path.Match pattern
+	// such as /api/endpoints/*
+	Path string `yaml:"path" json:"path"`
+	// Status is the HTTP status returned, 500 when zero
+	Status int `yaml:"status" json:"status"`
+	// Message is the Portainer error message returned
+	Message string `yaml:"message" json:"message"`
+	// Times is the number of requests that fail before the route recovers, every
+	// request when zero
+	Times int `yaml:"times" json:"times"`
+}
+
+func (f Fault) matches(method, urlPath string) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, method) {
+		return false
+	}
+	matched, err := path.Match(f.Path, urlPath)
+	return err == nil && matched
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithToken sets the API token accepted by the server
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithScenario seeds the server with the resources of a scenario
+func WithScenario(scenario Scenario) Option {
+	return func(s *Server) {
+		s.scenario = scenario
+	}
+}
+
+// Server is an in-memory Portainer API server
+type Server struct {
+	httpServer *httptest.Server
+	token      string
+	scenario   Scenario
+	routes     *http.ServeMux
+	custom     *http.ServeMux
+
+	mu       sync.Mutex
+	state    *state
+	requests []Request
+	faults   []Fault
+}
+
+// New starts a server listening on a local HTTP address. It must be closed with Close.
+func New(opts ...Option) *Server {
+	s := &Server{
+		token:  DefaultToken,
+		routes: http.NewServeMux(),
+		custom: http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.state = newState(s.scenario)
+	s.faults = append(s.faults, s.scenario.Faults...)
+	s.registerRoutes()
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the base URL of the server, with its http scheme, to pass to
+// client.NewPortainerClient
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// Token returns the API token accepted by the server
+func (s *Server) Token() string {
+	return s.token
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Requests returns the requests received by the server, oldest first
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo returns the requests received for a method and a path, oldest first.
+// The path includes the /api prefix and may be a path.Match pattern.
+func (s *Server) RequestsTo(method, pattern string) []Request {
+	var matching []Request
+	for _, r := range s.Requests() {
+		if (Fault{Method: method, Path: pattern}).matches(r.Method, r.Path) {
+			matching = append(matching, r)
+		}
+	}
+	return matching
+}
+
+// ResetRequests forgets the recorded requests
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+// Fail injects a fault. Faults are matched in the order they were added, after the
+// faults of the scenario.
+func (s *Server) Fail(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, fault)
+}
+
+// ClearFaults removes every injected fault, including those of the scenario
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Handle registers a handler for a route the server does not implement, or replaces a
+// built-in route. The pattern follows http.ServeMux, with the /api prefix, for example
+// "GET /api/endpoints/{id}/docker/containers/json". Recording, authentication and
+// faults apply to the handler as to built-in routes.
+func (s *Server) Handle(pattern string, handler http.HandlerFunc) {
+	s.custom.HandleFunc(pattern, handler)
+}
+
+// Resources returns a copy of the resources of a collection, such as "endpoints" or
+// "tags", in the form returned by the API
+func (s *Server) Resources(collection string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.state.collections[collection]
+	if !ok {
+		return nil
+	}
+	return c.list()
+}
+
+// serveHTTP records the request, then applies authentication, faults, custom routes and
+// built-in routes in that order
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "unable to read request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	s.mu.Unlock()
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	s.mu.Lock()
+	fault, failing := s.takeFault(r.Method, r.URL.Path)
+	s.mu.Unlock()
+	if failing {
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		message := fault.Message
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		writeError(w, status, message)
+		return
+	}
+
+	if _, pattern := s.custom.Handler(r); pattern != "" {
+		s.custom.ServeHTTP(w, r)
+		return
+	}
+	if _, pattern := s.routes.Handler(r); pattern != "" {
+		s.routes.ServeHTTP(w, r)
+		return
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("fake: no handler for %s %s, register one with Server.Handle", r.Method, r.URL.Path))
+}
+
+// takeFault returns the first fault matching a request and consumes one of its
+// occurrences. It must be called with the lock held.
+func (s *Server) takeFault(method, urlPath string) (Fault, bool) {
+	for i, fault := range s.faults {
+		if !fault.matches(method, urlPath) {
+			continue
+		}
+		if fault.Times > 0 {
+			s.faults[i].Times--
+			if s.faults[i].Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault, true
+	}
+	return Fault{}, false
+}
+
+// authorized tells whether the request carries the API token of the server
+func (s *Server) authorized(r *http.Request) bool {
+	if r.Header.Get("X-API-Key") == s.token {
+		return true
+	}
+	return r.Header.Get("Authorization") == "Bearer "+s.token
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format of the Portainer API
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message, "details": message})
+}
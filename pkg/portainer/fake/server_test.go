@@ -0,0 +1,148 @@
+package fake_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/client"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/fake"
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient starts a server and returns a client connected to it
+func newClient(t *testing.T, opts ...fake.Option) (*fake.Server, *client.PortainerClient) {
+	t.Helper()
+	srv := fake.New(opts...)
+	t.Cleanup(srv.Close)
+	return srv, client.NewPortainerClient(srv.URL(), srv.Token())
+}
+
+// TestServerBasicScenario verifies that the client reads the resources of the basic
+// scenario through the fake server.
+func TestServerBasicScenario(t *testing.T) {
+	_, cli := newClient(t, fake.WithScenario(fake.MustScenario("basic")))
+
+	version, err := cli.GetVersion()
+	require.NoError(t, err)
+	assert.Equal(t, fake.DefaultVersion, version)
+
+	environments, err := cli.GetEnvironments(models.EnvironmentListOptions{})
+	require.NoError(t, err)
+	require.Len(t, environments, 2)
+	assert.Equal(t, "local", environments[0].Name)
+	assert.Equal(t, []int{1}, environments[0].TagIds)
+	assert.Equal(t, map[int]string{2: "standard_user"}, environments[0].UserAccesses)
+
+	team, err := cli.GetTeam(1)
+	require.NoError(t, err)
+	assert.Equal(t, "developers", team.Name)
+	assert.ElementsMatch(t, []int{2, 3}, team.MemberIDs)
+
+	file, err := cli.InspectStackFile(1)
+	require.NoError(t, err)
+	assert.Contains(t, file, "registry.example.com/web:1.4.2")
+
+	current, err := cli.GetCurrentUser()
+	require.NoError(t, err)
+	assert.Equal(t, "admin", current.Username)
+}
+
+// TestServerEdgeScenario verifies the edge resources of the edge scenario.
+func TestServerEdgeScenario(t *testing.T) {
+	_, cli := newClient(t, fake.WithScenario(fake.MustScenario("edge")))
+
+	stacks, err := cli.GetStacks(models.EdgeStackListOptions{})
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	assert.Equal(t, []int{1}, stacks[0].EnvironmentGroupIds)
+
+	status, err := cli.GetEdgeStackStatus(1)
+	require.NoError(t, err)
+	require.Len(t, status.Environments, 2)
+	assert.Equal(t, models.EdgeStackStatusRunning, status.Environments[0].Status)
+	assert.Equal(t, models.EdgeStackStatusError, status.Environments[1].Status)
+	assert.Equal(t, "image pull failed", status.Environments[1].Error)
+
+	settings, err := cli.GetSettings()
+	require.NoError(t, err)
+	assert.True(t, settings.Edge.Enabled)
+}
+
+// TestServerWrites verifies that created and updated resources are visible to later
+// reads, and that the requests are recorded.
+func TestServerWrites(t *testing.T) {
+	srv, cli := newClient(t)
+
+	tagID, err := cli.CreateEnvironmentTag("production")
+	require.NoError(t, err)
+	teamID, err := cli.CreateTeam("ops")
+	require.NoError(t, err)
+	userID, err := cli.CreateUser("carol", "a-long-password", "user")
+	require.NoError(t, err)
+	require.NoError(t, cli.UpdateTeamMembers(teamID, []int{userID}))
+
+	tags, err := cli.GetEnvironmentTags()
+	require.NoError(t, err)
+	assert.Equal(t, []models.EnvironmentTag{{ID: tagID, Name: "production", EnvironmentIds: []int{}}}, tags)
+
+	team, err := cli.GetTeam(teamID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{userID}, team.MemberIDs)
+
+	users := srv.Resources("users")
+	require.Len(t, users, 2, "The default administrator and the created user")
+	assert.NotContains(t, users[1], "Password", "Passwords are never returned")
+
+	created := srv.RequestsTo(http.MethodPost, "/api/users")
+	require.Len(t, created, 1)
+	var payload map[string]any
+	require.NoError(t, created[0].JSON(&payload))
+	assert.Equal(t, "carol", payload["username"])
+	assert.Equal(t, srv.Token(), created[0].Header.Get("X-API-Key"))
+
+	require.NoError(t, cli.DeleteEnvironmentTag(tagID))
+	assert.Len(t, srv.RequestsTo(http.MethodDelete, "/api/tags/*"), 1)
+
+	srv.ResetRequests()
+	assert.Empty(t, srv.Requests())
+}
+
+// TestServerFaults verifies injected faults, authentication, missing resources and
+// custom routes.
+func TestServerFaults(t *testing.T) {
+	srv, cli := newClient(t, fake.WithScenario(fake.MustScenario("basic")))
+
+	srv.Fail(fake.Fault{Method: http.MethodGet, Path: "/api/registries", Status: http.StatusForbidden, Message: "Access denied", Times: 1})
+	_, err := cli.GetRegistries()
+	require.Error(t, err, "The first request fails")
+	_, err = cli.GetRegistries()
+	require.NoError(t, err, "The fault is consumed")
+
+	srv.Fail(fake.Fault{Path: "/api/endpoints/*"})
+	_, err = cli.GetEnvironment(1)
+	require.Error(t, err)
+	srv.ClearFaults()
+	_, err = cli.GetEnvironment(1)
+	require.NoError(t, err)
+
+	_, err = cli.GetEnvironment(99)
+	require.Error(t, err, "Missing resources answer 404")
+
+	unauthorized := client.NewPortainerClient(srv.URL(), "wrong-token")
+	_, err = unauthorized.GetEnvironments(models.EnvironmentListOptions{})
+	require.Error(t, err)
+
+	err = cli.UpdateEnvironmentSnapshotSettings(1, nil, nil)
+	require.NoError(t, err, "Environment updates are built in")
+
+	srv.Handle("GET /api/endpoints/{id}/docker/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Containers": 3}`))
+	})
+	resp, err := cli.ProxyDockerRequest(models.DockerProxyRequestOptions{EnvironmentID: 1, Method: http.MethodGet, Path: "/info"})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,276 @@
+package fake
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// collectionDef describes a collection of resources served under /api/{name}
+type collectionDef struct {
+	// idKey is the field holding the resource ID in API responses
+	idKey string
+	// fileKey is the field of the /api/{name}/{id}/file response, for resources created
+	// from a file such as stacks. The file is not part of the resource itself.
+	fileKey string
+	// createPath is the path, relative to /api/{name}, of the JSON create route. The
+	// collection has no JSON create route when it is "-".
+	createPath string
+}
+
+// collectionDefs are the collections served by the fake server
+var collectionDefs = map[string]collectionDef{
+	"endpoints":        {idKey: "Id", createPath: "-"},
+	"endpoint_groups":  {idKey: "Id"},
+	"edge_groups":      {idKey: "Id"},
+	"edge_stacks":      {idKey: "Id", fileKey: "StackFileContent", createPath: "/create/string"},
+	"edge_jobs":        {idKey: "Id", fileKey: "FileContent", createPath: "/create/string"},
+	"stacks":           {idKey: "Id", fileKey: "StackFileContent", createPath: "-"},
+	"custom_templates": {idKey: "Id", fileKey: "FileContent", createPath: "/create/string"},
+	"tags":             {idKey: "ID"},
+	"users":            {idKey: "Id"},
+	"teams":            {idKey: "Id"},
+	"team_memberships": {idKey: "Id"},
+	"registries":       {idKey: "Id"},
+	"webhooks":         {idKey: "Id"},
+}
+
+// Collections returns the names of the collections a scenario can seed, sorted
+func Collections() []string {
+	return slices.Sorted(maps.Keys(collectionDefs))
+}
+
+// collection is the in-memory store of a collection
+type collection struct {
+	collectionDef
+	nextID int
+	items  map[int]map[string]any
+	files  map[int]string
+}
+
+func newCollection(def collectionDef) *collection {
+	return &collection{collectionDef: def, nextID: 1, items: map[int]map[string]any{}, files: map[int]string{}}
+}
+
+// add stores a resource, with a new ID unless it has one, and returns the stored copy
+func (c *collection) add(resource map[string]any) map[string]any {
+	item := normalize(resource)
+	id, ok := toInt(item[c.idKey])
+	if !ok || id <= 0 {
+		id = c.nextID
+	}
+	item[c.idKey] = id
+	if id >= c.nextID {
+		c.nextID = id + 1
+	}
+	if c.fileKey != "" {
+		if file, ok := item[c.fileKey].(string); ok {
+			c.files[id] = file
+			delete(item, c.fileKey)
+		}
+	}
+	c.items[id] = item
+	return clone(item)
+}
+
+// update merges fields into a resource and returns the stored copy
+func (c *collection) update(id int, fields map[string]any) (map[string]any, bool) {
+	item, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+	for key, value := range normalize(fields) {
+		if strings.EqualFold(key, c.idKey) {
+			continue
+		}
+		if key == c.fileKey {
+			if file, ok := value.(string); ok {
+				c.files[id] = file
+			}
+			continue
+		}
+		setField(item, key, value)
+	}
+	return clone(item), true
+}
+
+func (c *collection) get(id int) (map[string]any, bool) {
+	item, ok := c.items[id]
+	if !ok {
+		return nil, false
+	}
+	return clone(item), true
+}
+
+func (c *collection) remove(id int) bool {
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	delete(c.files, id)
+	return true
+}
+
+// list returns the resources sorted by ID
+func (c *collection) list() []map[string]any {
+	items := make([]map[string]any, 0, len(c.items))
+	for _, id := range slices.Sorted(maps.Keys(c.items)) {
+		items = append(items, clone(c.items[id]))
+	}
+	return items
+}
+
+// filter returns the resources whose field equals an integer value
+func (c *collection) filter(field string, value int) []map[string]any {
+	var items []map[string]any
+	for _, item := range c.list() {
+		if v, ok := toInt(item[field]); ok && v == value {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// state is the in-memory state of a server
+type state struct {
+	status        map[string]any
+	settings      map[string]any
+	motd          map[string]any
+	roles         []map[string]any
+	currentUserID int
+	collections   map[string]*collection
+}
+
+func newState(scenario Scenario) *state {
+	st := &state{
+		status:        map[string]any{"Version": DefaultVersion, "InstanceID": "fake-instance"},
+		settings:      defaultSettings(),
+		motd:          map[string]any{"Title": "", "Message": "", "Hash": ""},
+		roles:         defaultRoles(),
+		currentUserID: scenario.CurrentUserID,
+		collections:   map[string]*collection{},
+	}
+	for name, def := range collectionDefs {
+		st.collections[name] = newCollection(def)
+	}
+
+	maps.Copy(st.status, scenario.Status)
+	for key, value := range normalize(scenario.Settings) {
+		setField(st.settings, key, value)
+	}
+	maps.Copy(st.motd, scenario.MOTD)
+	if len(scenario.Roles) > 0 {
+		st.roles = scenario.Roles
+	}
+	for name, resources := range scenario.Resources {
+		if c, ok := st.collections[name]; ok {
+			for _, resource := range resources {
+				c.add(resource)
+			}
+		}
+	}
+
+	users := st.collections["users"]
+	if len(users.items) == 0 {
+		users.add(map[string]any{"Id": 1, "Username": "admin", "Role": 1})
+	}
+	if st.currentUserID == 0 {
+		st.currentUserID = slices.Min(slices.Collect(maps.Keys(users.items)))
+	}
+	return st
+}
+
+// publicSettings returns the settings exposed by /api/settings/public
+func (st *state) publicSettings() map[string]any {
+	public := map[string]any{}
+	for _, key := range []string{"AuthenticationMethod", "EnableEdgeComputeFeatures", "EnableTelemetry", "LogoURL", "RequiredPasswordLength", "ShowKomposeBuildOption"} {
+		if value, ok := st.settings[key]; ok {
+			public[key] = value
+		}
+	}
+	return public
+}
+
+func defaultSettings() map[string]any {
+	return map[string]any{
+		"AuthenticationMethod":      1,
+		"EnableEdgeComputeFeatures": false,
+		"EnableTelemetry":           false,
+		"EdgeAgentCheckinInterval":  5,
+		"SnapshotInterval":          "5m",
+		"TemplatesURL":              "",
+		"LogoURL":                   "",
+		"UserSessionTimeout":        "8h",
+		"RequiredPasswordLength":    12,
+	}
+}
+
+func defaultRoles() []map[string]any {
+	names := []string{"Environment administrator", "Helpdesk", "Standard user", "Read-only user", "Operator"}
+	roles := make([]map[string]any, len(names))
+	for i, name := range names {
+		roles[i] = map[string]any{"Id": i + 1, "Name": name, "Description": name, "Priority": i + 1, "Authorizations": map[string]bool{}}
+	}
+	return roles
+}
+
+// normalize converts a request payload to the field names of API responses, which are
+// the payload names with an upper-case first letter, and drops passwords, which the API
+// never returns
+func normalize(payload map[string]any) map[string]any {
+	fields := make(map[string]any, len(payload))
+	for key, value := range payload {
+		r, size := utf8.DecodeRuneInString(key)
+		key = string(unicode.ToUpper(r)) + key[size:]
+		if key == "Password" {
+			continue
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+// setField sets a field, replacing a field whose name differs only in case, because the
+// API decodes payloads case-insensitively
+func setField(item map[string]any, key string, value any) {
+	for existing := range item {
+		if strings.EqualFold(existing, key) {
+			delete(item, existing)
+		}
+	}
+	item[key] = value
+}
+
+// clone returns a deep copy of a resource through its JSON form, so that callers
+// cannot modify the stored resource
+func clone(item map[string]any) map[string]any {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return maps.Clone(item)
+	}
+	var copied map[string]any
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return maps.Clone(item)
+	}
+	return copied
+}
+
+// toInt converts a decoded JSON or YAML number to an int
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), v == float64(int(v))
+	case json.Number:
+		n, err := v.Int64()
+		return int(n), err == nil
+	default:
+		return 0, false
+	}
+}
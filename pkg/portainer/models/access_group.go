@@ -5,6 +5,7 @@
 package models
 
 import (
+	"github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/utils"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
@@ -13,6 +14,7 @@ type AccessGroup struct {
 	ID             int            `json:"id"`
 	Name           string         `json:"name"`
 	EnvironmentIds []int          `json:"environment_ids"`
+	TagIds         []int          `json:"tag_ids"`
 	UserAccesses   map[int]string `json:"user_accesses"`
 	TeamAccesses   map[int]string `json:"team_accesses"`
 }
@@ -38,6 +40,7 @@ func ConvertEndpointGroupToAccessGroup(rawGroup *apimodels.PortainerEndpointGrou
 		ID:             int(rawGroup.ID),
 		Name:           rawGroup.Name,
 		EnvironmentIds: environmentIds,
+		TagIds:         utils.Int64ToIntSlice(rawGroup.TagIds),
 		UserAccesses:   convertAccesses(rawGroup.UserAccessPolicies),
 		TeamAccesses:   convertAccesses(rawGroup.TeamAccessPolicies),
 	}
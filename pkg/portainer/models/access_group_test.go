@@ -18,8 +18,9 @@ func TestConvertEndpointGroupToAccessGroup(t *testing.T) {
 		{
 			name: "group with multiple environments and accesses",
 			group: &models.PortainerEndpointGroup{
-				ID:   1,
-				Name: "Production",
+				ID:     1,
+				Name:   "Production",
+				TagIds: []int64{3, 4},
 				UserAccessPolicies: map[string]models.PortainerAccessPolicy{
 					"1": {RoleID: 1},
 					"2": {RoleID: 2},
@@ -38,6 +39,7 @@ func TestConvertEndpointGroupToAccessGroup(t *testing.T) {
 				ID:             1,
 				Name:           "Production",
 				EnvironmentIds: []int{100, 101},
+				TagIds:         []int{3, 4},
 				UserAccesses: map[int]string{
 					1: "environment_administrator",
 					2: "helpdesk_user",
@@ -65,6 +67,7 @@ func TestConvertEndpointGroupToAccessGroup(t *testing.T) {
 				ID:             2,
 				Name:           "Empty",
 				EnvironmentIds: []int{},
+				TagIds:         []int{},
 				UserAccesses: map[int]string{
 					1: "operator_user",
 				},
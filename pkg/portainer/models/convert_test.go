@@ -5,6 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-connections/nat"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 	"github.com/stretchr/testify/assert"
 )
@@ -171,6 +175,203 @@ func TestConvertDockerDashboardResponse(t *testing.T) {
 	}
 }
 
+// TestConvertDockerContainer verifies the ConvertDockerContainer model conversion function.
+func TestConvertDockerContainer(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      container.Summary
+		expected DockerContainer
+	}{
+		{
+			name: "full container summary",
+			raw: container.Summary{
+				ID:      "abc123",
+				Names:   []string{"/web-1", "/alias"},
+				Image:   "nginx:1.27",
+				State:   "running",
+				Status:  "Up 2 hours",
+				Created: 1700000000,
+				Labels:  map[string]string{"com.docker.compose.project": "web"},
+				Ports:   []container.Port{{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"}, {PrivatePort: 443, Type: "tcp"}},
+			},
+			expected: DockerContainer{
+				ID:      "abc123",
+				Name:    "web-1",
+				Image:   "nginx:1.27",
+				State:   "running",
+				Status:  "Up 2 hours",
+				Created: "2023-11-14T22:13:20Z",
+				Labels:  map[string]string{"com.docker.compose.project": "web"},
+				Ports:   []DockerContainerPort{{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Protocol: "tcp"}, {PrivatePort: 443, Protocol: "tcp"}},
+			},
+		},
+		{
+			name:     "no names and no creation date",
+			raw:      container.Summary{ID: "def456", State: "exited"},
+			expected: DockerContainer{ID: "def456", State: "exited"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConvertDockerContainer(tt.raw))
+		})
+	}
+}
+
+// TestConvertDockerEvent verifies the ConvertDockerEvent model conversion function.
+func TestConvertDockerEvent(t *testing.T) {
+	raw := events.Message{
+		Type:     events.ContainerEventType,
+		Action:   events.ActionDie,
+		Actor:    events.Actor{ID: "abc", Attributes: map[string]string{"exitCode": "1"}},
+		Time:     1700000000,
+		TimeNano: 1700000000123456789,
+	}
+
+	assert.Equal(t, DockerEvent{
+		Time:       "2023-11-14T22:13:20.123456789Z",
+		Type:       "container",
+		Action:     "die",
+		ActorID:    "abc",
+		Attributes: map[string]string{"exitCode": "1"},
+	}, ConvertDockerEvent(raw))
+}
+
+// TestConvertDockerContainerTop verifies the ConvertDockerContainerTop model conversion function.
+func TestConvertDockerContainerTop(t *testing.T) {
+	raw := container.TopResponse{
+		Titles: []string{"USER", "PID", "%CPU", "COMMAND"},
+		Processes: [][]string{
+			{"root", "1", "0.0", "nginx: master process"},
+			{"nginx", "29", "12.5"},
+		},
+	}
+
+	assert.Equal(t, DockerContainerTop{
+		Titles: []string{"USER", "PID", "%CPU", "COMMAND"},
+		Processes: []map[string]string{
+			{"USER": "root", "PID": "1", "%CPU": "0.0", "COMMAND": "nginx: master process"},
+			{"USER": "nginx", "PID": "29", "%CPU": "12.5"},
+		},
+	}, ConvertDockerContainerTop(raw))
+
+	assert.Equal(t, DockerContainerTop{Titles: []string{}, Processes: []map[string]string{}}, ConvertDockerContainerTop(container.TopResponse{}))
+}
+
+// TestConvertDockerContainerRestartState verifies the ConvertDockerContainerRestartState model conversion function.
+func TestConvertDockerContainerRestartState(t *testing.T) {
+	raw := container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{
+		ID:           "abc",
+		Name:         "/worker",
+		RestartCount: 7,
+		State:        &container.State{Status: "restarting"},
+		HostConfig:   &container.HostConfig{RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 10}},
+	}}
+
+	assert.Equal(t, DockerContainerRestartState{
+		ContainerID:       "abc",
+		Name:              "worker",
+		State:             "restarting",
+		Policy:            "on-failure",
+		MaximumRetryCount: 10,
+		RestartCount:      7,
+	}, ConvertDockerContainerRestartState(raw))
+
+	assert.Equal(t, DockerContainerRestartState{Policy: "no"}, ConvertDockerContainerRestartState(container.InspectResponse{}))
+	assert.Equal(t, "no", ConvertDockerContainerRestartState(container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{ID: "abc"}}).Policy)
+}
+
+// TestConvertDockerContainerPortBindings verifies the ConvertDockerContainerPortBindings model conversion function.
+func TestConvertDockerContainerPortBindings(t *testing.T) {
+	raw := container.InspectResponse{ContainerJSONBase: &container.ContainerJSONBase{
+		HostConfig: &container.HostConfig{PortBindings: nat.PortMap{
+			"5432/tcp": {{HostIP: "0.0.0.0", HostPort: "5432"}, {HostIP: "::", HostPort: "5432"}},
+			"53/udp":   {{HostIP: "127.0.0.1", HostPort: "1053"}},
+			"80/tcp":   {{HostPort: ""}},
+		}},
+	}}
+
+	assert.Equal(t, []DockerContainerPort{
+		{PrivatePort: 80, Protocol: "tcp"},
+		{IP: "127.0.0.1", PrivatePort: 53, PublicPort: 1053, Protocol: "udp"},
+		{IP: "0.0.0.0", PrivatePort: 5432, PublicPort: 5432, Protocol: "tcp"},
+		{IP: "::", PrivatePort: 5432, PublicPort: 5432, Protocol: "tcp"},
+	}, ConvertDockerContainerPortBindings(raw))
+
+	assert.Equal(t, []DockerContainerPort{}, ConvertDockerContainerPortBindings(container.InspectResponse{}))
+}
+
+// TestConvertDockerContainerImage verifies the ConvertDockerContainerImage model conversion function.
+func TestConvertDockerContainerImage(t *testing.T) {
+	raw := container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{ID: "abc", Name: "/web", Image: "sha256:111"},
+		Config:            &container.Config{Image: "nginx:1.27"},
+	}
+
+	assert.Equal(t, DockerContainerImage{ContainerID: "abc", Name: "web", Image: "nginx:1.27", ImageID: "sha256:111"}, ConvertDockerContainerImage(raw))
+	assert.Equal(t, DockerContainerImage{}, ConvertDockerContainerImage(container.InspectResponse{}))
+}
+
+// TestConvertDockerService verifies the ConvertDockerService model conversion function.
+func TestConvertDockerService(t *testing.T) {
+	raw := swarm.Service{
+		ID: "svc1",
+		Spec: swarm.ServiceSpec{
+			Annotations:  swarm.Annotations{Name: "shop_api", Labels: map[string]string{"com.docker.stack.namespace": "shop"}},
+			TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "acme/api:1.4@sha256:abc"}},
+		},
+	}
+
+	assert.Equal(t, DockerService{ID: "svc1", Name: "shop_api", Image: "acme/api:1.4@sha256:abc", Stack: "shop"}, ConvertDockerService(raw))
+	assert.Equal(t, DockerService{ID: "svc2"}, ConvertDockerService(swarm.Service{ID: "svc2"}))
+}
+
+// TestConvertDockerContainerStats verifies the ConvertDockerContainerStats model conversion function.
+func TestConvertDockerContainerStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      container.StatsResponse
+		expected DockerContainerUsage
+	}{
+		{
+			name: "cgroup v2 sample",
+			raw: container.StatsResponse{
+				ID:          "abc",
+				Name:        "/web-1",
+				CPUStats:    container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 3_000_000}, SystemUsage: 20_000_000, OnlineCPUs: 4},
+				PreCPUStats: container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 1_000_000}, SystemUsage: 10_000_000},
+				MemoryStats: container.MemoryStats{Usage: 300, Limit: 1000, Stats: map[string]uint64{"inactive_file": 100}},
+			},
+			expected: DockerContainerUsage{ContainerID: "abc", Name: "web-1", CPUPercent: 80, MemoryUsage: 200, MemoryLimit: 1000, MemoryPercent: 20},
+		},
+		{
+			name: "cgroup v1 sample with per-CPU usage",
+			raw: container.StatsResponse{
+				ID:          "def",
+				CPUStats:    container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 2_000, PercpuUsage: []uint64{1_000, 1_000}}, SystemUsage: 4_000},
+				PreCPUStats: container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 1_000}, SystemUsage: 2_000},
+				MemoryStats: container.MemoryStats{Usage: 500, Limit: 1000, Stats: map[string]uint64{"total_inactive_file": 250, "inactive_file": 1}},
+			},
+			expected: DockerContainerUsage{ContainerID: "def", CPUPercent: 100, MemoryUsage: 250, MemoryLimit: 1000, MemoryPercent: 25},
+		},
+		{
+			name:     "no previous sample",
+			raw:      container.StatsResponse{ID: "ghi", CPUStats: container.CPUStats{CPUUsage: container.CPUUsage{TotalUsage: 5}, SystemUsage: 10, OnlineCPUs: 1}},
+			expected: DockerContainerUsage{ContainerID: "ghi", CPUPercent: 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := ConvertDockerContainerStats(tt.raw)
+			assert.InDelta(t, tt.expected.CPUPercent, actual.CPUPercent, 0.001)
+			actual.CPUPercent = tt.expected.CPUPercent
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
 // --- Edge Job ---
 
 // TestConvertEdgeJobToLocal verifies the ConvertEdgeJobToLocal model conversion function.
@@ -183,6 +384,10 @@ func TestConvertEdgeJobToLocal(t *testing.T) {
 		Created:        1700000000,
 		Version:        3,
 		EdgeGroups:     []int64{1, 2, 5},
+		Endpoints: map[string]apimodels.PortainerEdgeJobEndpointMeta{
+			"12": {},
+			"4":  {},
+		},
 	}
 
 	result := ConvertEdgeJobToLocal(raw)
@@ -194,6 +399,7 @@ func TestConvertEdgeJobToLocal(t *testing.T) {
 	assert.Equal(t, int64(1700000000), result.Created)
 	assert.Equal(t, 3, result.Version)
 	assert.Equal(t, []int{1, 2, 5}, result.EdgeGroups)
+	assert.Equal(t, []int{4, 12}, result.Endpoints)
 }
 
 // TestConvertEdgeJobToLocal_EmptyEdgeGroups verifies the ConvertEdgeJobToLocal_EmptyEdgeGroups model conversion function.
@@ -354,6 +560,68 @@ func TestConvertToHelmReleaseDetails(t *testing.T) {
 	}
 }
 
+// TestConvertToHelmReleaseInfo verifies the ConvertToHelmReleaseInfo model conversion function.
+func TestConvertToHelmReleaseInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      *apimodels.ReleaseRelease
+		expected HelmReleaseInfo
+	}{
+		{
+			name: "with chart and info",
+			raw: &apimodels.ReleaseRelease{
+				Name:      "my-app",
+				Namespace: "production",
+				Version:   3,
+				Chart: &apimodels.ReleaseChart{
+					Metadata: &apimodels.ReleaseMetadata{Name: "nginx", Version: "15.1.0", AppVersion: "1.25.3"},
+				},
+				Info: &apimodels.ReleaseInfo{
+					Status:        "deployed",
+					Description:   "Upgrade complete",
+					FirstDeployed: "2024-01-10T10:00:00Z",
+					LastDeployed:  "2024-01-15T10:00:00Z",
+					Notes:         "Visit http://my-app.local",
+				},
+			},
+			expected: HelmReleaseInfo{
+				Name:          "my-app",
+				Namespace:     "production",
+				Revision:      3,
+				Chart:         "nginx",
+				ChartVersion:  "15.1.0",
+				AppVersion:    "1.25.3",
+				Status:        "deployed",
+				Description:   "Upgrade complete",
+				FirstDeployed: "2024-01-10T10:00:00Z",
+				LastDeployed:  "2024-01-15T10:00:00Z",
+				Notes:         "Visit http://my-app.local",
+			},
+		},
+		{
+			name: "release app version takes precedence",
+			raw: &apimodels.ReleaseRelease{
+				Name:       "my-app",
+				AppVersion: "2.0.0",
+				Chart:      &apimodels.ReleaseChart{Metadata: &apimodels.ReleaseMetadata{AppVersion: "1.0.0"}},
+			},
+			expected: HelmReleaseInfo{Name: "my-app", AppVersion: "2.0.0"},
+		},
+		{
+			name:     "nil chart and info",
+			raw:      &apimodels.ReleaseRelease{Name: "orphan", Version: 1},
+			expected: HelmReleaseInfo{Name: "orphan", Revision: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ConvertToHelmReleaseInfo(tt.raw)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 // --- Kubernetes ---
 
 // TestConvertK8sDashboard verifies the ConvertK8sDashboard model conversion function.
@@ -509,6 +777,13 @@ func TestConvertRawRegistryToRegistry(t *testing.T) {
 	assert.Equal(t, "https://hub.docker.com", result.BaseURL)
 	assert.True(t, result.Authentication)
 	assert.Equal(t, "myuser", result.Username)
+	assert.Nil(t, result.TeamAccesses)
+
+	raw.RegistryAccesses = apimodels.PortainerRegistryAccesses{
+		"1": {TeamAccessPolicies: apimodels.PortainerTeamAccessPolicies{"7": {}, "2": {}}},
+		"4": {UserAccessPolicies: apimodels.PortainerUserAccessPolicies{"5": {}}},
+	}
+	assert.Equal(t, map[int][]int{1: {2, 7}}, ConvertRawRegistryToRegistry(raw).TeamAccesses)
 }
 
 // --- Role ---
@@ -652,6 +927,31 @@ func TestConvertRegularStack(t *testing.T) {
 				assert.Equal(t, 3, result.ID)
 				assert.Equal(t, "old-stack", result.Name)
 				assert.Empty(t, result.CreatedAt)
+				assert.Nil(t, result.Git)
+			},
+		},
+		{
+			name: "git-backed stack",
+			raw: &apimodels.PortainereeStack{
+				ID:   8,
+				Name: "gitops",
+				GitConfig: &apimodels.GittypesRepoConfig{
+					URL:            "https://github.com/acme/deploy.git",
+					ReferenceName:  "refs/heads/main",
+					ConfigFilePath: "compose.yml",
+					ConfigHash:     "abc123",
+					Authentication: &apimodels.GittypesGitAuthentication{Username: "bot", Password: "secret", GitCredentialID: 2},
+				},
+			},
+			validate: func(t *testing.T, result RegularStack) {
+				assert.Equal(t, &StackGitConfig{
+					URL:            "https://github.com/acme/deploy.git",
+					ReferenceName:  "refs/heads/main",
+					ConfigFilePath: "compose.yml",
+					ConfigHash:     "abc123",
+					CredentialID:   2,
+					Username:       "bot",
+				}, result.Git)
 			},
 		},
 	}
@@ -716,6 +1016,7 @@ func TestConvertToPublicSettings(t *testing.T) {
 		OAuthHideInternalAuth:     true,
 		RequiredPasswordLength:    12,
 		Features:                  map[string]bool{"feature1": true},
+		CustomLoginBanner:         "Authorized use only",
 	}
 
 	result := ConvertToPublicSettings(raw)
@@ -729,4 +1030,5 @@ func TestConvertToPublicSettings(t *testing.T) {
 	assert.True(t, result.OAuthHideInternalAuth)
 	assert.Equal(t, 12, result.RequiredPasswordLength)
 	assert.Equal(t, map[string]bool{"feature1": true}, result.Features)
+	assert.Equal(t, "Authorized use only", result.CustomLoginBanner)
 }
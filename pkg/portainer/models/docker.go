@@ -2,7 +2,15 @@ package models
 
 import (
 	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/swarm"
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
@@ -79,3 +87,462 @@ type DockerProxyRequestOptions struct {
 	// Body is the request body to send (set it to nil for requests that don't have a body).
 	Body io.Reader
 }
+
+// DockerContainer represents a simplified container entry from the Docker container list API.
+type DockerContainer struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	State   string            `json:"state"`
+	Status  string            `json:"status"`
+	Created string            `json:"created"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	// Ports lists the exposed ports of the container. Docker only reports the ports of
+	// running containers.
+	Ports []DockerContainerPort `json:"ports,omitempty"`
+}
+
+// DockerContainerPort is a port of a container, published on the host when PublicPort is set.
+type DockerContainerPort struct {
+	// IP is the host address the port is published on ("0.0.0.0" or "::" for all addresses).
+	IP          string `json:"ip,omitempty"`
+	PrivatePort int    `json:"private_port"`
+	PublicPort  int    `json:"public_port,omitempty"`
+	// Protocol is "tcp", "udp" or "sctp".
+	Protocol string `json:"protocol"`
+}
+
+// DockerContainerListOptions holds the filters applied when listing containers on a Docker environment.
+type DockerContainerListOptions struct {
+	// All includes stopped containers when true; only running containers are listed otherwise.
+	All bool
+	// ID matches containers whose ID starts with the given value.
+	ID string
+	// Name matches containers whose name contains the given substring (case-insensitive).
+	Name string
+	// Status matches containers in the given state (created, restarting, running, removing, paused, exited, dead).
+	Status string
+	// Labels matches containers carrying all the given labels, each either "key" or "key=value".
+	Labels []string
+}
+
+// ConvertDockerContainer converts a raw Docker container summary to a local DockerContainer model.
+func ConvertDockerContainer(raw container.Summary) DockerContainer {
+	name := ""
+	if len(raw.Names) > 0 {
+		name = strings.TrimPrefix(raw.Names[0], "/")
+	}
+
+	created := ""
+	if raw.Created > 0 {
+		created = time.Unix(raw.Created, 0).UTC().Format(time.RFC3339)
+	}
+
+	var ports []DockerContainerPort
+	for _, port := range raw.Ports {
+		ports = append(ports, DockerContainerPort{
+			IP:          port.IP,
+			PrivatePort: int(port.PrivatePort),
+			PublicPort:  int(port.PublicPort),
+			Protocol:    port.Type,
+		})
+	}
+
+	return DockerContainer{
+		ID:      raw.ID,
+		Name:    name,
+		Image:   raw.Image,
+		State:   raw.State,
+		Status:  raw.Status,
+		Created: created,
+		Labels:  raw.Labels,
+		Ports:   ports,
+	}
+}
+
+// ConvertDockerContainerPortBindings converts the port bindings of a raw Docker container
+// inspect response to a list of published ports, sorted by public port. Unlike the ports of
+// a container summary, the bindings are set whether the container runs or not. Bindings
+// whose host port is chosen by Docker when the container starts have no PublicPort.
+func ConvertDockerContainerPortBindings(raw container.InspectResponse) []DockerContainerPort {
+	ports := []DockerContainerPort{}
+	if raw.ContainerJSONBase == nil || raw.HostConfig == nil {
+		return ports
+	}
+
+	for port, bindings := range raw.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			publicPort, _ := strconv.Atoi(binding.HostPort)
+			ports = append(ports, DockerContainerPort{
+				IP:          binding.HostIP,
+				PrivatePort: port.Int(),
+				PublicPort:  publicPort,
+				Protocol:    port.Proto(),
+			})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		a, b := ports[i], ports[j]
+		if a.PublicPort != b.PublicPort {
+			return a.PublicPort < b.PublicPort
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.PrivatePort != b.PrivatePort {
+			return a.PrivatePort < b.PrivatePort
+		}
+		return a.IP < b.IP
+	})
+	return ports
+}
+
+// DockerContainerUsage is a point-in-time CPU and memory usage sample of a container.
+type DockerContainerUsage struct {
+	ContainerID string `json:"container_id"`
+	Name        string `json:"name"`
+	// CPUPercent is the CPU usage relative to one core, as reported by docker stats
+	// (a container using two full cores reports 200).
+	CPUPercent float64 `json:"cpu_percent"`
+	// MemoryUsage is the memory used by the container in bytes, excluding the page cache.
+	MemoryUsage uint64 `json:"memory_usage_bytes"`
+	// MemoryLimit is the memory limit of the container in bytes (the host memory when unlimited).
+	MemoryLimit   uint64  `json:"memory_limit_bytes"`
+	MemoryPercent float64 `json:"memory_percent"`
+}
+
+// ConvertDockerContainerStats converts a raw Docker stats response to a local
+// DockerContainerUsage model, computing the percentages the same way as docker stats.
+func ConvertDockerContainerStats(raw container.StatsResponse) DockerContainerUsage {
+	usage := DockerContainerUsage{
+		ContainerID: raw.ID,
+		Name:        strings.TrimPrefix(raw.Name, "/"),
+		MemoryLimit: raw.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(raw.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		usage.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	// The page cache is reclaimable, so it is excluded like docker stats does:
+	// "total_inactive_file" on cgroup v1, "inactive_file" on cgroup v2.
+	usage.MemoryUsage = raw.MemoryStats.Usage
+	cache, ok := raw.MemoryStats.Stats["total_inactive_file"]
+	if !ok {
+		cache = raw.MemoryStats.Stats["inactive_file"]
+	}
+	if cache < usage.MemoryUsage {
+		usage.MemoryUsage -= cache
+	}
+	if raw.MemoryStats.Limit > 0 {
+		usage.MemoryPercent = float64(usage.MemoryUsage) / float64(raw.MemoryStats.Limit) * 100
+	}
+
+	return usage
+}
+
+// DockerContainerLogOptions holds the options used when reading container logs.
+type DockerContainerLogOptions struct {
+	// Since only returns log lines written at or after this time (zero means no lower bound).
+	Since time.Time
+	// Until only returns log lines written before this time (zero means no upper bound).
+	Until time.Time
+	// Tail limits the output to the last N lines (0 returns all lines).
+	Tail int
+	// Timestamps prefixes every log line with its RFC3339Nano timestamp.
+	Timestamps bool
+}
+
+// DockerEvent represents a simplified event from the Docker events API.
+type DockerEvent struct {
+	Time       string            `json:"time"`
+	Type       string            `json:"type"`
+	Action     string            `json:"action"`
+	ActorID    string            `json:"actor_id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// DockerEventListOptions holds the time range and filters used when listing Docker events.
+type DockerEventListOptions struct {
+	// Since only returns events that occurred at or after this time.
+	Since time.Time
+	// Until only returns events that occurred before this time. It must be set,
+	// otherwise the Docker API streams events indefinitely.
+	Until time.Time
+	// Type restricts events to one object type (container, image, volume, network, ...).
+	Type string
+}
+
+// ConvertDockerEvent converts a raw Docker event message to a local DockerEvent model.
+func ConvertDockerEvent(raw events.Message) DockerEvent {
+	ts := time.Unix(raw.Time, 0)
+	if raw.TimeNano > 0 {
+		ts = time.Unix(0, raw.TimeNano)
+	}
+
+	return DockerEvent{
+		Time:       ts.UTC().Format(time.RFC3339Nano),
+		Type:       string(raw.Type),
+		Action:     string(raw.Action),
+		ActorID:    raw.Actor.ID,
+		Attributes: raw.Actor.Attributes,
+	}
+}
+
+// DockerContainerFile is a regular file read from the filesystem of a container.
+type DockerContainerFile struct {
+	// Path is the absolute path of the file in the container.
+	Path string `json:"path"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// Mode is the permission bits of the file in octal notation (e.g. "0644").
+	Mode string `json:"mode"`
+	// ModTime is the last modification time of the file in RFC3339 format.
+	ModTime string `json:"mod_time,omitempty"`
+	// Content is the raw content of the file.
+	Content []byte `json:"-"`
+}
+
+// DockerContainerTop is the list of processes running in a container, as reported by ps
+// on the Docker host.
+type DockerContainerTop struct {
+	// Titles is the list of ps column titles (e.g. "PID", "USER", "%CPU", "COMMAND").
+	Titles []string `json:"titles"`
+	// Processes is the list of processes, each mapping a column title to its value.
+	Processes []map[string]string `json:"processes"`
+}
+
+// ConvertDockerContainerTop converts a raw Docker top response to a local DockerContainerTop
+// model. Values without a matching title are ignored.
+func ConvertDockerContainerTop(raw container.TopResponse) DockerContainerTop {
+	top := DockerContainerTop{
+		Titles:    raw.Titles,
+		Processes: make([]map[string]string, 0, len(raw.Processes)),
+	}
+	if top.Titles == nil {
+		top.Titles = []string{}
+	}
+
+	for _, values := range raw.Processes {
+		process := make(map[string]string, len(raw.Titles))
+		for i, title := range raw.Titles {
+			if i < len(values) {
+				process[title] = values[i]
+			}
+		}
+		top.Processes = append(top.Processes, process)
+	}
+	return top
+}
+
+// DockerContainerRestartState is the restart policy of a container and the number of
+// times the Docker engine restarted it.
+type DockerContainerRestartState struct {
+	ContainerID string `json:"container_id"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	// Policy is the restart policy: "no", "always", "unless-stopped" or "on-failure".
+	Policy string `json:"policy"`
+	// MaximumRetryCount is the retry limit of the "on-failure" policy (0 for unlimited).
+	MaximumRetryCount int `json:"maximum_retry_count,omitempty"`
+	// RestartCount is the number of restarts since the container was last started by a user.
+	RestartCount int `json:"restart_count"`
+}
+
+// ConvertDockerContainerRestartState converts a raw Docker container inspect response to a
+// local DockerContainerRestartState model. A container without a restart policy reports "no",
+// as docker inspect does for containers created before restart policies were recorded.
+func ConvertDockerContainerRestartState(raw container.InspectResponse) DockerContainerRestartState {
+	restart := DockerContainerRestartState{Policy: string(container.RestartPolicyDisabled)}
+	if raw.ContainerJSONBase == nil {
+		return restart
+	}
+
+	restart.ContainerID = raw.ID
+	restart.Name = strings.TrimPrefix(raw.Name, "/")
+	restart.RestartCount = raw.RestartCount
+	if raw.State != nil {
+		restart.State = raw.State.Status
+	}
+	if raw.HostConfig != nil && raw.HostConfig.RestartPolicy.Name != "" {
+		restart.Policy = string(raw.HostConfig.RestartPolicy.Name)
+		restart.MaximumRetryCount = raw.HostConfig.RestartPolicy.MaximumRetryCount
+	}
+	return restart
+}
+
+// DockerContainerImage is the image a container was created from.
+type DockerContainerImage struct {
+	ContainerID string `json:"container_id"`
+	Name        string `json:"name"`
+	// Image is the image reference given when the container was created (e.g. "nginx:1.27").
+	Image string `json:"image"`
+	// ImageID is the ID of the local image the container runs.
+	ImageID string `json:"image_id"`
+}
+
+// ConvertDockerContainerImage converts a raw Docker container inspect response to a local
+// DockerContainerImage model.
+func ConvertDockerContainerImage(raw container.InspectResponse) DockerContainerImage {
+	var img DockerContainerImage
+	if raw.ContainerJSONBase == nil {
+		return img
+	}
+
+	img.ContainerID = raw.ID
+	img.Name = strings.TrimPrefix(raw.Name, "/")
+	img.ImageID = raw.Image
+	if raw.Config != nil {
+		img.Image = raw.Config.Image
+	}
+	return img
+}
+
+// dockerStackNamespaceLabel is the label Docker sets on the services of a Swarm stack.
+const dockerStackNamespaceLabel = "com.docker.stack.namespace"
+
+// DockerService is a Swarm service of a Docker environment.
+type DockerService struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Image is the image of the service tasks, usually pinned to a digest by Docker
+	// (e.g. "nginx:1.27@sha256:...").
+	Image string `json:"image"`
+	// Stack is the name of the Swarm stack the service belongs to, if any.
+	Stack string `json:"stack,omitempty"`
+}
+
+// ConvertDockerService converts a raw Docker Swarm service to a local DockerService model.
+func ConvertDockerService(raw swarm.Service) DockerService {
+	service := DockerService{
+		ID:    raw.ID,
+		Name:  raw.Spec.Name,
+		Stack: raw.Spec.Labels[dockerStackNamespaceLabel],
+	}
+	if raw.Spec.TaskTemplate.ContainerSpec != nil {
+		service.Image = raw.Spec.TaskTemplate.ContainerSpec.Image
+	}
+	return service
+}
+
+// DockerDiskUsage is the disk usage of a Docker environment, as reported by docker system df.
+type DockerDiskUsage struct {
+	// LayersSize is the total size of the image layers, shared layers being counted once.
+	LayersSize int64                      `json:"layers_size"`
+	Images     []DockerDiskUsageImage     `json:"images"`
+	Containers []DockerDiskUsageContainer `json:"containers"`
+	Volumes    []DockerDiskUsageVolume    `json:"volumes"`
+	// BuildCacheSize is the total size of the build cache records.
+	BuildCacheSize int64 `json:"build_cache_size"`
+	// BuildCacheReclaimable is the size of the build cache records that are neither in
+	// use nor shared, which docker builder prune removes.
+	BuildCacheReclaimable int64 `json:"build_cache_reclaimable"`
+}
+
+// DockerDiskUsageImage is the disk usage of an image.
+type DockerDiskUsageImage struct {
+	ID       string   `json:"id"`
+	RepoTags []string `json:"repo_tags"`
+	// Created is the creation time of the image as a Unix timestamp.
+	Created int64 `json:"created"`
+	Size    int64 `json:"size"`
+	// SharedSize is the size of the layers shared with other images, -1 when not computed.
+	SharedSize int64 `json:"shared_size"`
+	// Containers is the number of containers using the image, -1 when not computed.
+	Containers int64 `json:"containers"`
+}
+
+// DockerDiskUsageContainer is the disk usage of a container.
+type DockerDiskUsageContainer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	State string `json:"state"`
+	// Created is the creation time of the container as a Unix timestamp.
+	Created int64 `json:"created"`
+	// SizeRw is the size of the files written to the writable layer of the container.
+	SizeRw int64 `json:"size_rw"`
+}
+
+// DockerDiskUsageVolume is the disk usage of a volume.
+type DockerDiskUsageVolume struct {
+	Name   string `json:"name"`
+	Driver string `json:"driver"`
+	// RefCount is the number of containers referencing the volume, -1 when not available.
+	RefCount int64 `json:"ref_count"`
+	// Size is the size of the volume in bytes, -1 when not available (non-local drivers).
+	Size int64 `json:"size"`
+}
+
+// ConvertDockerDiskUsage converts a raw Docker system df response to a local
+// DockerDiskUsage model.
+func ConvertDockerDiskUsage(raw types.DiskUsage) DockerDiskUsage {
+	usage := DockerDiskUsage{
+		LayersSize: raw.LayersSize,
+		Images:     make([]DockerDiskUsageImage, 0, len(raw.Images)),
+		Containers: make([]DockerDiskUsageContainer, 0, len(raw.Containers)),
+		Volumes:    make([]DockerDiskUsageVolume, 0, len(raw.Volumes)),
+	}
+
+	for _, img := range raw.Images {
+		if img == nil {
+			continue
+		}
+		usage.Images = append(usage.Images, DockerDiskUsageImage{
+			ID:         img.ID,
+			RepoTags:   img.RepoTags,
+			Created:    img.Created,
+			Size:       img.Size,
+			SharedSize: img.SharedSize,
+			Containers: img.Containers,
+		})
+	}
+
+	for _, ctr := range raw.Containers {
+		if ctr == nil {
+			continue
+		}
+		var name string
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		usage.Containers = append(usage.Containers, DockerDiskUsageContainer{
+			ID:      ctr.ID,
+			Name:    name,
+			Image:   ctr.Image,
+			State:   ctr.State,
+			Created: ctr.Created,
+			SizeRw:  ctr.SizeRw,
+		})
+	}
+
+	for _, vol := range raw.Volumes {
+		if vol == nil {
+			continue
+		}
+		volume := DockerDiskUsageVolume{Name: vol.Name, Driver: vol.Driver, RefCount: -1, Size: -1}
+		if vol.UsageData != nil {
+			volume.RefCount = vol.UsageData.RefCount
+			volume.Size = vol.UsageData.Size
+		}
+		usage.Volumes = append(usage.Volumes, volume)
+	}
+
+	for _, record := range raw.BuildCache {
+		if record == nil {
+			continue
+		}
+		usage.BuildCacheSize += record.Size
+		if !record.InUse && !record.Shared {
+			usage.BuildCacheReclaimable += record.Size
+		}
+	}
+
+	return usage
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"slices"
+	"strconv"
+
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
@@ -13,6 +16,8 @@ type EdgeJob struct {
 	Created        int64  `json:"created,omitempty"`
 	Version        int    `json:"version,omitempty"`
 	EdgeGroups     []int  `json:"edgeGroups,omitempty"`
+	// Endpoints are the environments targeted directly by the job, outside its edge groups.
+	Endpoints []int `json:"endpoints,omitempty"`
 }
 
 // ConvertEdgeJobToLocal converts a raw SDK edge job to a local EdgeJob model.
@@ -32,6 +37,14 @@ func ConvertEdgeJobToLocal(raw *apimodels.PortainerEdgeJob) EdgeJob {
 		edgeGroups[i] = int(g)
 	}
 
+	var endpoints []int
+	for key := range raw.Endpoints {
+		if id, err := strconv.Atoi(key); err == nil {
+			endpoints = append(endpoints, id)
+		}
+	}
+	slices.Sort(endpoints)
+
 	return EdgeJob{
 		ID:             int(raw.ID),
 		Name:           raw.Name,
@@ -40,9 +53,16 @@ func ConvertEdgeJobToLocal(raw *apimodels.PortainerEdgeJob) EdgeJob {
 		Created:        raw.Created,
 		Version:        int(raw.Version),
 		EdgeGroups:     edgeGroups,
+		Endpoints:      endpoints,
 	}
 }
 
+// Edge update schedule types as used by the Portainer API
+const (
+	EdgeUpdateScheduleTypeUpdate   = 1
+	EdgeUpdateScheduleTypeRollback = 2
+)
+
 // EdgeUpdateSchedule represents a simplified edge update schedule for the MCP application.
 type EdgeUpdateSchedule struct {
 	ID            int    `json:"id"`
@@ -11,9 +11,31 @@ type Environment struct {
 	Name         string         `json:"name"`
 	Status       string         `json:"status"`
 	Type         string         `json:"type"`
+	GroupID      int            `json:"group_id"`
 	TagIds       []int          `json:"tag_ids"`
 	UserAccesses map[int]string `json:"user_accesses"`
 	TeamAccesses map[int]string `json:"team_accesses"`
+	// AgentVersion is the version of the Portainer agent of the environment, empty for
+	// environments reached without an agent or whose agent has not reported its version.
+	AgentVersion string `json:"agent_version,omitempty"`
+}
+
+// EnvironmentListOptions holds the filters applied when listing environments. They are
+// sent to the Portainer API, so that only the matching environments are transferred.
+type EnvironmentListOptions struct {
+	// Name matches environments whose name contains the given substring (case-insensitive).
+	Name string
+	// Search is a Portainer search query, matched against the name, the group, the tags
+	// and the URL of the environments.
+	Search string
+	// TagID matches environments carrying the given tag.
+	TagID int
+	// GroupID matches environments in the given access group.
+	GroupID int
+	// Status matches environments with the given status (one of the EnvironmentStatus values).
+	Status string
+	// Type matches environments of the given type (one of the EnvironmentType values).
+	Type string
 }
 
 // Environment status constants
@@ -35,21 +57,50 @@ const (
 	EnvironmentTypeUnknown             = "unknown"
 )
 
+// Environment creation type constants, as expected by the Portainer API when creating an environment.
+const (
+	EnvironmentCreationTypeDockerAPI = 1 // Docker API reached over TCP
+	EnvironmentCreationTypeAgent     = 2 // Portainer agent, Docker or Kubernetes
+	EnvironmentCreationTypeEdgeAgent = 4 // Portainer edge agent
+)
+
+// EnvironmentCreateOptions holds the settings of a new environment.
+type EnvironmentCreateOptions struct {
+	// Name identifies the environment.
+	Name string
+	// CreationType is one of the EnvironmentCreationType constants.
+	CreationType int
+	// URL is the address of the Docker API or agent (e.g. "tcp://10.0.0.5:2375", "10.0.0.5:9001"),
+	// or the Portainer URL used by edge agents.
+	URL string
+	// PublicURL is the address where exposed containers are reachable (defaults to URL).
+	PublicURL string
+	// TLS connects to a Docker API environment over TLS. Agents always use TLS.
+	TLS bool
+	// TLSSkipVerify skips the verification of the Docker API TLS certificate.
+	TLSSkipVerify bool
+}
+
 // ConvertEndpointToEnvironment converts a raw Portainer endpoint into a simplified Environment model.
 func ConvertEndpointToEnvironment(rawEndpoint *apimodels.PortainereeEndpoint) Environment {
 	if rawEndpoint == nil {
 		return Environment{}
 	}
 
-	return Environment{
+	env := Environment{
 		ID:           int(rawEndpoint.ID),
 		Name:         rawEndpoint.Name,
 		Status:       convertEnvironmentStatus(rawEndpoint),
 		Type:         convertEnvironmentType(rawEndpoint),
+		GroupID:      int(rawEndpoint.GroupID),
 		TagIds:       utils.Int64ToIntSlice(rawEndpoint.TagIds),
 		UserAccesses: convertAccesses(rawEndpoint.UserAccessPolicies),
 		TeamAccesses: convertAccesses(rawEndpoint.TeamAccessPolicies),
 	}
+	if rawEndpoint.Agent != nil {
+		env.AgentVersion = rawEndpoint.Agent.Version
+	}
+	return env
 }
 
 func convertEnvironmentStatus(rawEndpoint *apimodels.PortainereeEndpoint) string {
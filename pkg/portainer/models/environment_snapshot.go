@@ -0,0 +1,122 @@
+package models
+
+import apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+
+// EnvironmentSnapshot holds the latest snapshot data of an environment: the resource
+// counts and engine versions recorded by Portainer, and the version of its agent.
+type EnvironmentSnapshot struct {
+	EnvironmentID int    `json:"environment_id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Status        string `json:"status"`
+	GroupID       int    `json:"group_id"`
+	// AgentVersion is the version of the Portainer agent, empty for environments reached
+	// without an agent or whose agent has not reported its version.
+	AgentVersion string `json:"agent_version,omitempty"`
+	// SnapshotTime is the Unix time of the snapshot, 0 when the environment has none.
+	SnapshotTime      int64  `json:"snapshot_time,omitempty"`
+	DockerVersion     string `json:"docker_version,omitempty"`
+	KubernetesVersion string `json:"kubernetes_version,omitempty"`
+	Containers        int    `json:"containers"`
+	Running           int    `json:"running"`
+	Stopped           int    `json:"stopped"`
+	Healthy           int    `json:"healthy"`
+	Unhealthy         int    `json:"unhealthy"`
+	Images            int    `json:"images"`
+	// ImagesSize is the total size of the images in bytes, -1 when the snapshot does not
+	// include the image list. Layers shared by several images are counted for each of them.
+	ImagesSize  int64 `json:"images_size_bytes"`
+	Volumes     int   `json:"volumes"`
+	Stacks      int   `json:"stacks"`
+	Services    int   `json:"services"`
+	Nodes       int   `json:"nodes"`
+	TotalCPU    int   `json:"total_cpu"`
+	TotalMemory int64 `json:"total_memory_bytes"`
+}
+
+// ConvertToEnvironmentSnapshot extracts the latest Docker or Kubernetes snapshot of a raw
+// Portainer endpoint. Environments without a snapshot only have their identity set.
+func ConvertToEnvironmentSnapshot(rawEndpoint *apimodels.PortainereeEndpoint) EnvironmentSnapshot {
+	if rawEndpoint == nil {
+		return EnvironmentSnapshot{}
+	}
+
+	s := EnvironmentSnapshot{
+		EnvironmentID: int(rawEndpoint.ID),
+		Name:          rawEndpoint.Name,
+		Type:          convertEnvironmentType(rawEndpoint),
+		Status:        convertEnvironmentStatus(rawEndpoint),
+		GroupID:       int(rawEndpoint.GroupID),
+		ImagesSize:    -1,
+	}
+	if rawEndpoint.Agent != nil {
+		s.AgentVersion = rawEndpoint.Agent.Version
+	}
+
+	var docker *apimodels.PortainerDockerSnapshot
+	for _, snapshot := range rawEndpoint.Snapshots {
+		if snapshot != nil && (docker == nil || snapshot.Time > docker.Time) {
+			docker = snapshot
+		}
+	}
+	if docker != nil {
+		s.SnapshotTime = docker.Time
+		s.DockerVersion = docker.DockerVersion
+		s.Containers = int(docker.ContainerCount)
+		s.Running = int(docker.RunningContainerCount)
+		s.Stopped = int(docker.StoppedContainerCount)
+		s.Healthy = int(docker.HealthyContainerCount)
+		s.Unhealthy = int(docker.UnhealthyContainerCount)
+		s.Images = int(docker.ImageCount)
+		s.Volumes = int(docker.VolumeCount)
+		s.Stacks = int(docker.StackCount)
+		s.Services = int(docker.ServiceCount)
+		s.Nodes = int(docker.NodeCount)
+		s.TotalCPU = int(docker.TotalCPU)
+		s.TotalMemory = docker.TotalMemory
+		if size, ok := snapshotImagesSize(docker.DockerSnapshotRaw); ok {
+			s.ImagesSize = size
+		}
+	}
+
+	if rawEndpoint.Kubernetes != nil {
+		var kubernetes *apimodels.PortainerKubernetesSnapshot
+		for _, snapshot := range rawEndpoint.Kubernetes.Snapshots {
+			if snapshot != nil && (kubernetes == nil || snapshot.Time > kubernetes.Time) {
+				kubernetes = snapshot
+			}
+		}
+		if kubernetes != nil && kubernetes.Time > s.SnapshotTime {
+			s.SnapshotTime = kubernetes.Time
+			s.KubernetesVersion = kubernetes.KubernetesVersion
+			s.Nodes = int(kubernetes.NodeCount)
+			s.TotalCPU = int(kubernetes.TotalCPU)
+			s.TotalMemory = kubernetes.TotalMemory
+		}
+	}
+
+	return s
+}
+
+// snapshotImagesSize returns the total size of the images listed in the raw data of a
+// Docker snapshot, and false when the raw data has no image list.
+func snapshotImagesSize(raw apimodels.PortainerDockerSnapshotRaw) (int64, bool) {
+	data, ok := raw.(map[string]any)
+	if !ok {
+		return 0, false
+	}
+	images, ok := data["Images"].([]any)
+	if !ok {
+		return 0, false
+	}
+
+	var total int64
+	for _, image := range images {
+		if fields, ok := image.(map[string]any); ok {
+			if size, ok := fields["Size"].(float64); ok {
+				total += int64(size)
+			}
+		}
+	}
+	return total, true
+}
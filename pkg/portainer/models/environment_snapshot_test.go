@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConvertToEnvironmentSnapshot verifies the extraction of the latest Docker or
+// Kubernetes snapshot of an environment and of the version of its agent.
+func TestConvertToEnvironmentSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *apimodels.PortainereeEndpoint
+		expected EnvironmentSnapshot
+	}{
+		{
+			name: "docker agent with image list",
+			endpoint: &apimodels.PortainereeEndpoint{
+				ID:      2,
+				Name:    "prod",
+				Type:    2,
+				Status:  1,
+				GroupID: 3,
+				Agent:   &apimodels.PortainereeEnvironmentAgentData{Version: "2.19.4"},
+				Snapshots: []*apimodels.PortainerDockerSnapshot{
+					{Time: 100, DockerVersion: "24.0.0", ContainerCount: 1},
+					nil,
+					{
+						Time: 200, DockerVersion: "25.0.3", ContainerCount: 5, RunningContainerCount: 3, StoppedContainerCount: 2,
+						HealthyContainerCount: 1, UnhealthyContainerCount: 1, ImageCount: 2, VolumeCount: 4, StackCount: 1,
+						ServiceCount: 0, NodeCount: 1, TotalCPU: 8, TotalMemory: 1024,
+						DockerSnapshotRaw: map[string]any{"Images": []any{map[string]any{"Size": float64(300)}, map[string]any{"Size": float64(200)}, "invalid"}},
+					},
+				},
+			},
+			expected: EnvironmentSnapshot{
+				EnvironmentID: 2, Name: "prod", Type: EnvironmentTypeDockerAgent, Status: EnvironmentStatusActive, GroupID: 3,
+				AgentVersion: "2.19.4", SnapshotTime: 200, DockerVersion: "25.0.3",
+				Containers: 5, Running: 3, Stopped: 2, Healthy: 1, Unhealthy: 1, Images: 2, ImagesSize: 500,
+				Volumes: 4, Stacks: 1, Nodes: 1, TotalCPU: 8, TotalMemory: 1024,
+			},
+		},
+		{
+			name: "kubernetes snapshot newer than the docker one",
+			endpoint: &apimodels.PortainereeEndpoint{
+				ID:         4,
+				Name:       "k8s",
+				Type:       6,
+				Snapshots:  []*apimodels.PortainerDockerSnapshot{{Time: 100, DockerVersion: "25.0.3", TotalCPU: 2}},
+				Kubernetes: &apimodels.PortainereeKubernetesData{Snapshots: []*apimodels.PortainerKubernetesSnapshot{{Time: 300, KubernetesVersion: "v1.30.1", NodeCount: 3, TotalCPU: 12, TotalMemory: 4096}}},
+			},
+			expected: EnvironmentSnapshot{
+				EnvironmentID: 4, Name: "k8s", Type: EnvironmentTypeKubernetesAgent, Status: EnvironmentStatusUnknown,
+				SnapshotTime: 300, DockerVersion: "25.0.3", KubernetesVersion: "v1.30.1", ImagesSize: -1,
+				Nodes: 3, TotalCPU: 12, TotalMemory: 4096,
+			},
+		},
+		{
+			name:     "no snapshot",
+			endpoint: &apimodels.PortainereeEndpoint{ID: 5, Name: "new", Type: 4},
+			expected: EnvironmentSnapshot{EnvironmentID: 5, Name: "new", Type: EnvironmentTypeDockerEdgeAgent, Status: EnvironmentStatusInactive, ImagesSize: -1},
+		},
+		{
+			name:     "nil endpoint",
+			expected: EnvironmentSnapshot{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConvertToEnvironmentSnapshot(tt.endpoint))
+		})
+	}
+}
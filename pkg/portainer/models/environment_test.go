@@ -48,7 +48,7 @@ func TestConvertEndpointToEnvironment(t *testing.T) {
 			},
 		},
 		{
-			name: "inactive kubernetes-agent environment with empty accesses",
+			name: "inactive kubernetes-agent environment with empty accesses and agent version",
 			endpoint: &models.PortainereeEndpoint{
 				ID:                 2,
 				Name:               "k8s-agent",
@@ -57,6 +57,7 @@ func TestConvertEndpointToEnvironment(t *testing.T) {
 				TagIds:             []int64{1},
 				UserAccessPolicies: models.PortainerUserAccessPolicies{},
 				TeamAccessPolicies: models.PortainerTeamAccessPolicies{},
+				Agent:              &models.PortainereeEnvironmentAgentData{Version: "2.19.4"},
 			},
 			want: Environment{
 				ID:           2,
@@ -66,6 +67,7 @@ func TestConvertEndpointToEnvironment(t *testing.T) {
 				TagIds:       []int{1},
 				UserAccesses: map[int]string{},
 				TeamAccesses: map[int]string{},
+				AgentVersion: "2.19.4",
 			},
 		},
 		{
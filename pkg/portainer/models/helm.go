@@ -37,6 +37,21 @@ type HelmReleaseDetails struct {
 	Status     string `json:"status"`
 }
 
+// HelmReleaseInfo represents a Helm release with its last deployment information.
+type HelmReleaseInfo struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Revision      int    `json:"revision"`
+	Chart         string `json:"chart"`
+	ChartVersion  string `json:"chartVersion"`
+	AppVersion    string `json:"appVersion"`
+	Status        string `json:"status"`
+	Description   string `json:"description"`
+	FirstDeployed string `json:"firstDeployed"`
+	LastDeployed  string `json:"lastDeployed"`
+	Notes         string `json:"notes"`
+}
+
 // ConvertToHelmRepository converts a raw PortainerHelmUserRepository to a local HelmRepository.
 func ConvertToHelmRepository(raw *apimodels.PortainerHelmUserRepository) HelmRepository {
 	if raw == nil {
@@ -103,3 +118,35 @@ func ConvertToHelmReleaseDetails(raw *apimodels.ReleaseRelease) HelmReleaseDetai
 		Status:     status,
 	}
 }
+
+// ConvertToHelmReleaseInfo converts a raw ReleaseRelease to a local HelmReleaseInfo.
+func ConvertToHelmReleaseInfo(raw *apimodels.ReleaseRelease) HelmReleaseInfo {
+	if raw == nil {
+		return HelmReleaseInfo{}
+	}
+
+	info := HelmReleaseInfo{
+		Name:       raw.Name,
+		Namespace:  raw.Namespace,
+		Revision:   int(raw.Version),
+		AppVersion: raw.AppVersion,
+	}
+
+	if raw.Chart != nil && raw.Chart.Metadata != nil {
+		info.Chart = raw.Chart.Metadata.Name
+		info.ChartVersion = raw.Chart.Metadata.Version
+		if info.AppVersion == "" {
+			info.AppVersion = raw.Chart.Metadata.AppVersion
+		}
+	}
+
+	if raw.Info != nil {
+		info.Status = raw.Info.Status
+		info.Description = raw.Info.Description
+		info.FirstDeployed = raw.Info.FirstDeployed
+		info.LastDeployed = raw.Info.LastDeployed
+		info.Notes = raw.Info.Notes
+	}
+
+	return info
+}
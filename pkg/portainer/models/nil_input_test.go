@@ -114,6 +114,13 @@ func TestConvertFunctionsNilInput(t *testing.T) {
 		}
 	})
 
+	t.Run("ConvertToHelmReleaseInfo", func(t *testing.T) {
+		result := ConvertToHelmReleaseInfo(nil)
+		if result.Name != "" {
+			t.Error("expected empty Name")
+		}
+	})
+
 	t.Run("ConvertK8sDashboard", func(t *testing.T) {
 		result := ConvertK8sDashboard(nil)
 		if result.NamespacesCount != 0 {
@@ -220,6 +227,20 @@ func TestConvertFunctionsNilInput(t *testing.T) {
 	})
 
 	// Verify nil elements within slices are handled
+	t.Run("ConvertToSnapshotSettings", func(t *testing.T) {
+		result := ConvertToSnapshotSettings(nil)
+		if result.SnapshotInterval != "" {
+			t.Error("expected empty SnapshotInterval")
+		}
+	})
+
+	t.Run("ConvertToEnvironmentSnapshotSettings", func(t *testing.T) {
+		result := ConvertToEnvironmentSnapshotSettings(nil)
+		if result.EnvironmentID != 0 {
+			t.Error("expected zero EnvironmentID")
+		}
+	})
+
 	t.Run("ConvertToAppTemplates_with_nil_element", func(t *testing.T) {
 		result := ConvertToAppTemplates([]*apimodels.PortainerTemplate{nil})
 		if len(result) != 1 {
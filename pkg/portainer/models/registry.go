@@ -1,6 +1,9 @@
 package models
 
 import (
+	"slices"
+	"strconv"
+
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
@@ -13,6 +16,8 @@ type Registry struct {
 	BaseURL        string `json:"base_url"`
 	Authentication bool   `json:"authentication"`
 	Username       string `json:"username"`
+	// TeamAccesses are the IDs of the teams allowed to use the registry, by environment ID.
+	TeamAccesses map[int][]int `json:"team_accesses,omitempty"`
 }
 
 // ConvertRawRegistryToRegistry converts a raw Portainer registry into a simplified Registry model.
@@ -29,5 +34,33 @@ func ConvertRawRegistryToRegistry(rawRegistry *apimodels.PortainereeRegistry) Re
 		BaseURL:        rawRegistry.BaseURL,
 		Authentication: rawRegistry.Authentication,
 		Username:       rawRegistry.Username,
+		TeamAccesses:   convertRegistryTeamAccesses(rawRegistry.RegistryAccesses),
+	}
+}
+
+// convertRegistryTeamAccesses returns the sorted team IDs of the registry accesses of each
+// environment, or nil when no team has access.
+func convertRegistryTeamAccesses(rawAccesses apimodels.PortainerRegistryAccesses) map[int][]int {
+	var accesses map[int][]int
+	for environmentID, policies := range rawAccesses {
+		id, err := strconv.Atoi(environmentID)
+		if err != nil {
+			continue
+		}
+		teams := make([]int, 0, len(policies.TeamAccessPolicies))
+		for teamID := range policies.TeamAccessPolicies {
+			if team, err := strconv.Atoi(teamID); err == nil {
+				teams = append(teams, team)
+			}
+		}
+		if len(teams) == 0 {
+			continue
+		}
+		slices.Sort(teams)
+		if accesses == nil {
+			accesses = map[int][]int{}
+		}
+		accesses[id] = teams
 	}
+	return accesses
 }
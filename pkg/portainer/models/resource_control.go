@@ -0,0 +1,58 @@
+package models
+
+import (
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// Resource types of the resource controls returned by GetResourceControls
+const (
+	ResourceControlTypeStack          = "stack"
+	ResourceControlTypeCustomTemplate = "custom_template"
+)
+
+// ResourceControl restricts the access to a Portainer resource, such as a stack or a
+// custom template, to the listed users and teams.
+type ResourceControl struct {
+	ID           int    `json:"id"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   int    `json:"resource_id"`
+	ResourceName string `json:"resource_name"`
+	UserIDs      []int  `json:"user_ids"`
+	TeamIDs      []int  `json:"team_ids"`
+	// Public resources are accessible to every user, and AdministratorsOnly resources
+	// only to administrators, whatever the listed users and teams.
+	Public             bool `json:"public"`
+	AdministratorsOnly bool `json:"administrators_only"`
+}
+
+// ConvertToResourceControl converts the raw resource control of a resource into a
+// simplified ResourceControl model.
+func ConvertToResourceControl(rawControl *apimodels.PortainerResourceControl, resourceType string, resourceID int, resourceName string) ResourceControl {
+	if rawControl == nil {
+		return ResourceControl{}
+	}
+
+	userIDs := make([]int, 0, len(rawControl.UserAccesses))
+	for _, access := range rawControl.UserAccesses {
+		if access != nil {
+			userIDs = append(userIDs, int(access.UserID))
+		}
+	}
+	teamIDs := make([]int, 0, len(rawControl.TeamAccesses))
+	for _, access := range rawControl.TeamAccesses {
+		if access != nil {
+			teamIDs = append(teamIDs, int(access.TeamID))
+		}
+	}
+
+	return ResourceControl{
+		ID:                 int(rawControl.ID),
+		ResourceType:       resourceType,
+		ResourceID:         resourceID,
+		ResourceName:       resourceName,
+		UserIDs:            userIDs,
+		TeamIDs:            teamIDs,
+		Public:             rawControl.Public,
+		AdministratorsOnly: rawControl.AdministratorsOnly,
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+)
+
+// TestConvertToResourceControl verifies the ConvertToResourceControl model conversion function.
+func TestConvertToResourceControl(t *testing.T) {
+	raw := &apimodels.PortainerResourceControl{
+		ID:           5,
+		ResourceID:   "3_local",
+		UserAccesses: []*apimodels.PortainerUserResourceAccess{{UserID: 4, AccessLevel: 1}, nil, {UserID: 9, AccessLevel: 1}},
+		TeamAccesses: []*apimodels.PortainerTeamResourceAccess{{TeamID: 2, AccessLevel: 1}},
+		Public:       true,
+	}
+	expected := ResourceControl{
+		ID:           5,
+		ResourceType: ResourceControlTypeStack,
+		ResourceID:   3,
+		ResourceName: "web",
+		UserIDs:      []int{4, 9},
+		TeamIDs:      []int{2},
+		Public:       true,
+	}
+
+	result := ConvertToResourceControl(raw, ResourceControlTypeStack, 3, "web")
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("ConvertToResourceControl() = %+v, want %+v", result, expected)
+	}
+
+	if result := ConvertToResourceControl(nil, ResourceControlTypeStack, 3, "web"); !reflect.DeepEqual(result, ResourceControl{}) {
+		t.Errorf("ConvertToResourceControl(nil) = %+v, want zero value", result)
+	}
+}
@@ -4,6 +4,12 @@ import (
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
+// Kinds of principal that a role can be assigned to on an environment or access group.
+const (
+	RoleAssigneeUser = "user"
+	RoleAssigneeTeam = "team"
+)
+
 // Role represents a Portainer role
 type Role struct {
 	ID             int             `json:"id"`
@@ -56,6 +56,8 @@ type PublicSettings struct {
 	OAuthHideInternalAuth     bool            `json:"oauth_hide_internal_auth"`
 	RequiredPasswordLength    int             `json:"required_password_length"`
 	Features                  map[string]bool `json:"features,omitempty"`
+	// CustomLoginBanner is the banner shown on the login page, only set by Business Edition servers.
+	CustomLoginBanner string `json:"custom_login_banner,omitempty"`
 }
 
 // ConvertToPublicSettings converts a raw SDK public settings response to the local PublicSettings model.
@@ -74,6 +76,7 @@ func ConvertToPublicSettings(raw *apimodels.SettingsPublicSettingsResponse) Publ
 		OAuthHideInternalAuth:     raw.OAuthHideInternalAuth,
 		RequiredPasswordLength:    int(raw.RequiredPasswordLength),
 		Features:                  raw.Features,
+		CustomLoginBanner:         raw.CustomLoginBanner,
 	}
 }
 
@@ -0,0 +1,88 @@
+package models
+
+import apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+
+// SnapshotSettings holds the global schedule of environment snapshots.
+type SnapshotSettings struct {
+	// SnapshotInterval is the interval between snapshots of the non-edge environments,
+	// as a Go duration (e.g. "5m").
+	SnapshotInterval string `json:"snapshot_interval"`
+	// EdgeAgentCheckinInterval is the default check-in interval of edge agents, in seconds.
+	EdgeAgentCheckinInterval int `json:"edge_agent_checkin_interval"`
+	// EdgeAsyncSnapshotInterval is the default snapshot interval of edge agents in async
+	// mode, in seconds.
+	EdgeAsyncSnapshotInterval int `json:"edge_async_snapshot_interval"`
+}
+
+// EnvironmentSnapshotSettings holds the snapshot behavior of a single environment.
+type EnvironmentSnapshotSettings struct {
+	EnvironmentID int    `json:"environment_id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	// EdgeCheckinInterval is the check-in interval of an edge agent, in seconds. 0 uses the
+	// global default.
+	EdgeCheckinInterval int `json:"edge_checkin_interval"`
+	// EdgeAsyncMode tells whether an edge agent runs in async mode, where snapshots are sent
+	// every EdgeSnapshotInterval instead of being taken at check-in.
+	EdgeAsyncMode bool `json:"edge_async_mode"`
+	// EdgeSnapshotInterval is the snapshot interval of an edge agent in async mode, in
+	// seconds. -1 uses the global default.
+	EdgeSnapshotInterval int `json:"edge_snapshot_interval"`
+	// EdgePingInterval and EdgeCommandInterval are the other async mode intervals, kept
+	// so that updating the snapshot interval leaves them unchanged.
+	EdgePingInterval    int `json:"edge_ping_interval"`
+	EdgeCommandInterval int `json:"edge_command_interval"`
+	// LastSnapshotTime is the Unix time of the latest snapshot, 0 when none was taken.
+	LastSnapshotTime int64 `json:"last_snapshot_time,omitempty"`
+}
+
+// ConvertToSnapshotSettings extracts the snapshot schedule from raw Portainer settings.
+func ConvertToSnapshotSettings(rawSettings *apimodels.PortainereeSettings) SnapshotSettings {
+	if rawSettings == nil {
+		return SnapshotSettings{}
+	}
+
+	s := SnapshotSettings{
+		SnapshotInterval:         rawSettings.SnapshotInterval,
+		EdgeAgentCheckinInterval: int(rawSettings.EdgeAgentCheckinInterval),
+	}
+	if rawSettings.Edge != nil {
+		s.EdgeAsyncSnapshotInterval = int(rawSettings.Edge.SnapshotInterval)
+	}
+	return s
+}
+
+// ConvertToEnvironmentSnapshotSettings extracts the snapshot behavior of a raw Portainer endpoint.
+func ConvertToEnvironmentSnapshotSettings(rawEndpoint *apimodels.PortainereeEndpoint) EnvironmentSnapshotSettings {
+	if rawEndpoint == nil {
+		return EnvironmentSnapshotSettings{}
+	}
+
+	s := EnvironmentSnapshotSettings{
+		EnvironmentID:       int(rawEndpoint.ID),
+		Name:                rawEndpoint.Name,
+		Type:                convertEnvironmentType(rawEndpoint),
+		EdgeCheckinInterval: int(rawEndpoint.EdgeCheckinInterval),
+	}
+	if rawEndpoint.Edge != nil {
+		s.EdgeAsyncMode = rawEndpoint.Edge.AsyncMode
+		s.EdgeSnapshotInterval = int(rawEndpoint.Edge.SnapshotInterval)
+		s.EdgePingInterval = int(rawEndpoint.Edge.PingInterval)
+		s.EdgeCommandInterval = int(rawEndpoint.Edge.CommandInterval)
+	}
+
+	for _, snapshot := range rawEndpoint.Snapshots {
+		if snapshot != nil && snapshot.Time > s.LastSnapshotTime {
+			s.LastSnapshotTime = snapshot.Time
+		}
+	}
+	if rawEndpoint.Kubernetes != nil {
+		for _, snapshot := range rawEndpoint.Kubernetes.Snapshots {
+			if snapshot != nil && snapshot.Time > s.LastSnapshotTime {
+				s.LastSnapshotTime = snapshot.Time
+			}
+		}
+	}
+
+	return s
+}
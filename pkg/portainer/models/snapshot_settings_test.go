@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConvertToSnapshotSettings verifies the extraction of the global snapshot schedule.
+func TestConvertToSnapshotSettings(t *testing.T) {
+	result := ConvertToSnapshotSettings(&apimodels.PortainereeSettings{
+		SnapshotInterval:         "5m",
+		EdgeAgentCheckinInterval: 10,
+		Edge:                     &apimodels.PortainereeEdge{SnapshotInterval: 60},
+	})
+
+	assert.Equal(t, SnapshotSettings{SnapshotInterval: "5m", EdgeAgentCheckinInterval: 10, EdgeAsyncSnapshotInterval: 60}, result)
+}
+
+// TestConvertToEnvironmentSnapshotSettings verifies the extraction of the snapshot behavior
+// of an environment, including the time of its latest Docker or Kubernetes snapshot.
+func TestConvertToEnvironmentSnapshotSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint *apimodels.PortainereeEndpoint
+		expected EnvironmentSnapshotSettings
+	}{
+		{
+			name: "edge agent in async mode",
+			endpoint: &apimodels.PortainereeEndpoint{
+				ID:                  3,
+				Name:                "edge",
+				Type:                4,
+				EdgeCheckinInterval: 30,
+				Edge:                &apimodels.PortainerEnvironmentEdgeSettings{AsyncMode: true, SnapshotInterval: 120, PingInterval: 60, CommandInterval: -1},
+				Snapshots:           []*apimodels.PortainerDockerSnapshot{{Time: 100}, nil, {Time: 200}},
+			},
+			expected: EnvironmentSnapshotSettings{
+				EnvironmentID:        3,
+				Name:                 "edge",
+				Type:                 EnvironmentTypeDockerEdgeAgent,
+				EdgeCheckinInterval:  30,
+				EdgeAsyncMode:        true,
+				EdgeSnapshotInterval: 120,
+				EdgePingInterval:     60,
+				EdgeCommandInterval:  -1,
+				LastSnapshotTime:     200,
+			},
+		},
+		{
+			name: "kubernetes agent",
+			endpoint: &apimodels.PortainereeEndpoint{
+				ID:         4,
+				Name:       "k8s",
+				Type:       6,
+				Kubernetes: &apimodels.PortainereeKubernetesData{Snapshots: []*apimodels.PortainerKubernetesSnapshot{{Time: 300}}},
+			},
+			expected: EnvironmentSnapshotSettings{
+				EnvironmentID:    4,
+				Name:             "k8s",
+				Type:             EnvironmentTypeKubernetesAgent,
+				LastSnapshotTime: 300,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConvertToEnvironmentSnapshotSettings(tt.endpoint))
+		})
+	}
+}
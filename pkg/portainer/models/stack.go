@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"time"
 
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
@@ -13,6 +14,29 @@ type Stack struct {
 	Name                string `json:"name"`
 	CreatedAt           string `json:"created_at"`
 	EnvironmentGroupIds []int  `json:"group_ids"`
+	// Status is the overall deployment status of the stack on its environments, one of
+	// the StackStatus values, and empty when no environment has reported yet.
+	Status string `json:"status,omitempty"`
+}
+
+// Overall deployment status values of an edge stack.
+const (
+	// StackStatusFailed means that the deployment failed on at least one environment.
+	StackStatusFailed = "failed"
+	// StackStatusDeploying means that at least one environment has not deployed the stack yet.
+	StackStatusDeploying = "deploying"
+	// StackStatusDeployed means that every environment runs the stack.
+	StackStatusDeployed = "deployed"
+)
+
+// EdgeStackListOptions holds the filters applied when listing edge stacks.
+type EdgeStackListOptions struct {
+	// Name matches stacks whose name contains the given substring (case-insensitive).
+	Name string
+	// EnvironmentGroupID matches stacks deployed to the given edge group.
+	EnvironmentGroupID int
+	// Status matches stacks with the given overall status (one of the StackStatus values).
+	Status string
 }
 
 // ConvertEdgeStackToStack converts a raw Portainer edge stack into a simplified Stack model.
@@ -28,21 +52,36 @@ func ConvertEdgeStackToStack(rawEdgeStack *apimodels.PortainereeEdgeStack) Stack
 		Name:                rawEdgeStack.Name,
 		CreatedAt:           createdAt,
 		EnvironmentGroupIds: utils.Int64ToIntSlice(rawEdgeStack.EdgeGroups),
+		Status:              ConvertEdgeStackStatus(rawEdgeStack).Overall(),
 	}
 }
 
 // RegularStack represents a regular (non-edge) stack in Portainer
 type RegularStack struct {
-	ID             int    `json:"id"`
-	Name           string `json:"name"`
-	Type           int    `json:"type"`
-	Status         int    `json:"status"`
-	EndpointID     int    `json:"endpoint_id"`
-	EntryPoint     string `json:"entry_point,omitempty"`
-	SwarmID        string `json:"swarm_id,omitempty"`
-	CreatedBy      string `json:"created_by,omitempty"`
-	CreatedAt      string `json:"created_at,omitempty"`
-	FilesystemPath string `json:"filesystem_path,omitempty"`
+	ID             int             `json:"id"`
+	Name           string          `json:"name"`
+	Type           int             `json:"type"`
+	Status         int             `json:"status"`
+	EndpointID     int             `json:"endpoint_id"`
+	EntryPoint     string          `json:"entry_point,omitempty"`
+	SwarmID        string          `json:"swarm_id,omitempty"`
+	CreatedBy      string          `json:"created_by,omitempty"`
+	CreatedAt      string          `json:"created_at,omitempty"`
+	FilesystemPath string          `json:"filesystem_path,omitempty"`
+	Git            *StackGitConfig `json:"git,omitempty"`
+}
+
+// StackGitConfig describes the git repository a stack is deployed from.
+// Credentials other than the username and the stored credential ID are never exposed.
+type StackGitConfig struct {
+	URL            string `json:"url"`
+	ReferenceName  string `json:"reference_name,omitempty"`
+	ConfigFilePath string `json:"config_file_path,omitempty"`
+	// ConfigHash is the commit hash that is currently deployed.
+	ConfigHash    string `json:"config_hash,omitempty"`
+	CredentialID  int    `json:"credential_id,omitempty"`
+	Username      string `json:"username,omitempty"`
+	TLSSkipVerify bool   `json:"tls_skip_verify,omitempty"`
 }
 
 // ConvertRegularStack converts a raw PortainereeStack to a RegularStack
@@ -67,5 +106,136 @@ func ConvertRegularStack(raw *apimodels.PortainereeStack) RegularStack {
 		CreatedBy:      raw.CreatedBy,
 		CreatedAt:      createdAt,
 		FilesystemPath: raw.FilesystemPath,
+		Git:            convertStackGitConfig(raw.GitConfig),
+	}
+}
+
+// convertStackGitConfig converts a raw git repository configuration, returning nil when absent.
+func convertStackGitConfig(raw *apimodels.GittypesRepoConfig) *StackGitConfig {
+	if raw == nil || raw.URL == "" {
+		return nil
+	}
+
+	cfg := &StackGitConfig{
+		URL:            raw.URL,
+		ReferenceName:  raw.ReferenceName,
+		ConfigFilePath: raw.ConfigFilePath,
+		ConfigHash:     raw.ConfigHash,
+		TLSSkipVerify:  raw.TlsskipVerify,
+	}
+	if raw.Authentication != nil {
+		cfg.CredentialID = int(raw.Authentication.GitCredentialID)
+		cfg.Username = raw.Authentication.Username
+	}
+	return cfg
+}
+
+// Edge stack deployment status names, indexed by the Portainer EdgeStackStatusType value.
+var edgeStackStatusNames = []string{
+	"pending",
+	"deployment_received",
+	"error",
+	"acknowledged",
+	"removed",
+	"remote_update_success",
+	"images_pulled",
+	"running",
+	"deploying",
+	"removing",
+	"paused_deploying",
+	"rolling_back",
+	"rolled_back",
+	"completed",
+}
+
+// Edge stack deployment status names used to evaluate a rollout.
+const (
+	EdgeStackStatusPending   = "pending"
+	EdgeStackStatusError     = "error"
+	EdgeStackStatusRunning   = "running"
+	EdgeStackStatusCompleted = "completed"
+)
+
+// EdgeStackStatus is the deployment status of an edge stack on each of its environments.
+type EdgeStackStatus struct {
+	ID           int                          `json:"id"`
+	Name         string                       `json:"name"`
+	Environments []EdgeStackEnvironmentStatus `json:"environments"`
+}
+
+// EdgeStackEnvironmentStatus is the latest deployment status of an edge stack on one environment.
+type EdgeStackEnvironmentStatus struct {
+	EnvironmentID int    `json:"environment_id"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ConvertEdgeStackStatus converts a raw Portainer edge stack into an EdgeStackStatus, keeping
+// only the most recent deployment status of each environment. Environments are sorted by ID.
+func ConvertEdgeStackStatus(raw *apimodels.PortainereeEdgeStack) EdgeStackStatus {
+	if raw == nil {
+		return EdgeStackStatus{}
+	}
+
+	environments := make([]EdgeStackEnvironmentStatus, 0, len(raw.Status))
+	for _, envStatus := range raw.Status {
+		status := EdgeStackEnvironmentStatus{
+			EnvironmentID: int(envStatus.EndpointID),
+			Status:        EdgeStackStatusPending,
+			Error:         envStatus.Error,
+		}
+
+		var latest *apimodels.PortainerEdgeStackDeploymentStatus
+		for _, s := range envStatus.Status {
+			if s != nil && (latest == nil || s.Time >= latest.Time) {
+				latest = s
+			}
+		}
+		if latest != nil {
+			status.Status = edgeStackStatusName(latest.Type)
+			if latest.Error != "" {
+				status.Error = latest.Error
+			}
+		}
+
+		environments = append(environments, status)
+	}
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].EnvironmentID < environments[j].EnvironmentID
+	})
+
+	return EdgeStackStatus{
+		ID:           int(raw.ID),
+		Name:         raw.Name,
+		Environments: environments,
+	}
+}
+
+// Overall returns the overall deployment status of the edge stack: failed when an
+// environment reports an error, deploying while an environment has not deployed it, and
+// deployed once every environment runs it. It is empty when no environment has reported.
+func (s EdgeStackStatus) Overall() string {
+	if len(s.Environments) == 0 {
+		return ""
+	}
+
+	overall := StackStatusDeployed
+	for _, env := range s.Environments {
+		switch env.Status {
+		case EdgeStackStatusError:
+			return StackStatusFailed
+		case EdgeStackStatusRunning, EdgeStackStatusCompleted:
+		default:
+			overall = StackStatusDeploying
+		}
+	}
+	return overall
+}
+
+// edgeStackStatusName returns the name of a Portainer EdgeStackStatusType value.
+func edgeStackStatusName(statusType int64) string {
+	if statusType < 0 || statusType >= int64(len(edgeStackStatusNames)) {
+		return "unknown"
 	}
+	return edgeStackStatusNames[statusType]
 }
@@ -87,3 +87,89 @@ func TestConvertEdgeStackToStack(t *testing.T) {
 		})
 	}
 }
+
+// TestConvertEdgeStackStatus verifies the ConvertEdgeStackStatus model conversion function.
+func TestConvertEdgeStackStatus(t *testing.T) {
+	raw := &models.PortainereeEdgeStack{
+		ID:   3,
+		Name: "edge-web",
+		Status: map[string]models.PortainerEdgeStackStatus{
+			"7": {
+				EndpointID: 7,
+				Status: []*models.PortainerEdgeStackDeploymentStatus{
+					{Type: 2, Time: 200, Error: "image not found"},
+					{Type: 8, Time: 100},
+				},
+			},
+			"2": {
+				EndpointID: 2,
+				Status: []*models.PortainerEdgeStackDeploymentStatus{
+					{Type: 1, Time: 100},
+					{Type: 7, Time: 150},
+				},
+			},
+			"5": {EndpointID: 5},
+			"9": {
+				EndpointID: 9,
+				Status:     []*models.PortainerEdgeStackDeploymentStatus{{Type: 42, Time: 100}},
+			},
+		},
+	}
+
+	expected := EdgeStackStatus{
+		ID:   3,
+		Name: "edge-web",
+		Environments: []EdgeStackEnvironmentStatus{
+			{EnvironmentID: 2, Status: EdgeStackStatusRunning},
+			{EnvironmentID: 5, Status: EdgeStackStatusPending},
+			{EnvironmentID: 7, Status: EdgeStackStatusError, Error: "image not found"},
+			{EnvironmentID: 9, Status: "unknown"},
+		},
+	}
+
+	got := ConvertEdgeStackStatus(raw)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ConvertEdgeStackStatus() = %v, want %v", got, expected)
+	}
+
+	if got := ConvertEdgeStackStatus(nil); !reflect.DeepEqual(got, EdgeStackStatus{}) {
+		t.Errorf("ConvertEdgeStackStatus(nil) = %v, want zero value", got)
+	}
+}
+
+// TestEdgeStackStatusOverall verifies the overall status of an edge stack.
+func TestEdgeStackStatusOverall(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{name: "no environments", want: ""},
+		{name: "all deployed", statuses: []string{EdgeStackStatusRunning, EdgeStackStatusCompleted}, want: StackStatusDeployed},
+		{name: "pending environment", statuses: []string{EdgeStackStatusRunning, EdgeStackStatusPending}, want: StackStatusDeploying},
+		{name: "unknown status", statuses: []string{"unknown"}, want: StackStatusDeploying},
+		{name: "failed environment", statuses: []string{EdgeStackStatusPending, EdgeStackStatusError, EdgeStackStatusRunning}, want: StackStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var status EdgeStackStatus
+			for i, s := range tt.statuses {
+				status.Environments = append(status.Environments, EdgeStackEnvironmentStatus{EnvironmentID: i + 1, Status: s})
+			}
+			if got := status.Overall(); got != tt.want {
+				t.Errorf("Overall() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	stack := ConvertEdgeStackToStack(&models.PortainereeEdgeStack{
+		ID: 1,
+		Status: map[string]models.PortainerEdgeStackStatus{
+			"1": {EndpointID: 1, Status: []*models.PortainerEdgeStackDeploymentStatus{{Type: 7}}},
+		},
+	})
+	if stack.Status != StackStatusDeployed {
+		t.Errorf("ConvertEdgeStackToStack() status = %q, want %q", stack.Status, StackStatusDeployed)
+	}
+}
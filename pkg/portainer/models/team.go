@@ -33,3 +33,44 @@ func ConvertToTeam(rawTeam *apimodels.PortainerTeam, rawMemberships []*apimodels
 		MemberIDs: memberIDs,
 	}
 }
+
+// TeamMembership represents the membership of a user in a team.
+type TeamMembership struct {
+	TeamID int    `json:"teamId"`
+	UserID int    `json:"userId"`
+	Role   string `json:"role"`
+}
+
+// Team membership role string constants
+const (
+	TeamMembershipRoleLeader  = "leader"
+	TeamMembershipRoleMember  = "member"
+	TeamMembershipRoleUnknown = "unknown"
+)
+
+// Team membership role ID constants as used by the Portainer API
+const (
+	TeamMembershipRoleIDLeader int64 = 1
+	TeamMembershipRoleIDMember int64 = 2
+)
+
+// ConvertToTeamMembership converts a raw Portainer team membership into a simplified TeamMembership model.
+func ConvertToTeamMembership(rawMembership *apimodels.PortainerTeamMembership) TeamMembership {
+	if rawMembership == nil {
+		return TeamMembership{}
+	}
+
+	role := TeamMembershipRoleUnknown
+	switch rawMembership.Role {
+	case TeamMembershipRoleIDLeader:
+		role = TeamMembershipRoleLeader
+	case TeamMembershipRoleIDMember:
+		role = TeamMembershipRoleMember
+	}
+
+	return TeamMembership{
+		TeamID: int(rawMembership.TeamID),
+		UserID: int(rawMembership.UserID),
+		Role:   role,
+	}
+}
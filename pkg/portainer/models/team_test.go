@@ -97,3 +97,42 @@ func TestConvertToTeam(t *testing.T) {
 		})
 	}
 }
+
+// TestConvertToTeamMembership verifies the ConvertToTeamMembership model conversion function.
+func TestConvertToTeamMembership(t *testing.T) {
+	tests := []struct {
+		name       string
+		membership *models.PortainerTeamMembership
+		expected   TeamMembership
+	}{
+		{
+			name:       "team leader",
+			membership: &models.PortainerTeamMembership{ID: 1, TeamID: 2, UserID: 3, Role: 1},
+			expected:   TeamMembership{TeamID: 2, UserID: 3, Role: TeamMembershipRoleLeader},
+		},
+		{
+			name:       "team member",
+			membership: &models.PortainerTeamMembership{ID: 4, TeamID: 5, UserID: 6, Role: 2},
+			expected:   TeamMembership{TeamID: 5, UserID: 6, Role: TeamMembershipRoleMember},
+		},
+		{
+			name:       "unknown role",
+			membership: &models.PortainerTeamMembership{TeamID: 7, UserID: 8, Role: 9},
+			expected:   TeamMembership{TeamID: 7, UserID: 8, Role: TeamMembershipRoleUnknown},
+		},
+		{
+			name:       "nil membership",
+			membership: nil,
+			expected:   TeamMembership{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ConvertToTeamMembership(tt.membership)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ConvertToTeamMembership() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	apimodels "github.com/portainer/client-api-go/v2/pkg/models"
 )
 
@@ -9,6 +11,19 @@ type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// HasAPITokens reports whether the user owns API tokens. It is only set when
+	// the tokens were looked up, which costs one request per user.
+	HasAPITokens *bool `json:"hasApiTokens,omitempty"`
+}
+
+// UserListOptions filters the users returned by GetUsers. Zero values do not filter.
+type UserListOptions struct {
+	// Name matches the usernames containing it, case-insensitively.
+	Name string
+	// Role matches the users with this role (admin, user or edge_admin).
+	Role string
+	// TeamID matches the members of this team, resolved from the team memberships.
+	TeamID int
 }
 
 // User role string constants (used in MCP tool parameters)
@@ -51,3 +66,38 @@ func convertUserRole(rawUser *apimodels.PortainereeUser) string {
 		return UserRoleUnknown
 	}
 }
+
+// APIToken is an API token owned by a user. The token value itself is never returned by
+// Portainer, only its prefix.
+type APIToken struct {
+	ID          int    `json:"id"`
+	Description string `json:"description"`
+	Prefix      string `json:"prefix"`
+	// DateCreated and LastUsed are RFC3339 timestamps. LastUsed is empty when the token
+	// was never used.
+	DateCreated string `json:"date_created"`
+	LastUsed    string `json:"last_used,omitempty"`
+}
+
+// ConvertToAPIToken converts a raw Portainer API key into a simplified APIToken model.
+func ConvertToAPIToken(rawKey *apimodels.PortainerAPIKey) APIToken {
+	if rawKey == nil {
+		return APIToken{}
+	}
+
+	return APIToken{
+		ID:          int(rawKey.ID),
+		Description: rawKey.Description,
+		Prefix:      rawKey.Prefix,
+		DateCreated: formatUnixTime(rawKey.DateCreated),
+		LastUsed:    formatUnixTime(rawKey.LastUsed),
+	}
+}
+
+// formatUnixTime formats a Unix timestamp as RFC3339, or returns an empty string for 0.
+func formatUnixTime(seconds int64) string {
+	if seconds == 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
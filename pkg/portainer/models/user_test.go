@@ -102,3 +102,22 @@ func TestConvertUserRole(t *testing.T) {
 		})
 	}
 }
+
+// TestConvertToAPIToken verifies the ConvertToAPIToken model conversion function.
+func TestConvertToAPIToken(t *testing.T) {
+	result := ConvertToAPIToken(&models.PortainerAPIKey{
+		ID:          7,
+		Description: "ci",
+		Prefix:      "ptr_abc",
+		DateCreated: 1700000000,
+		Digest:      "secret",
+	})
+	expected := APIToken{ID: 7, Description: "ci", Prefix: "ptr_abc", DateCreated: "2023-11-14T22:13:20Z"}
+	if result != expected {
+		t.Errorf("ConvertToAPIToken() = %v, want %v", result, expected)
+	}
+
+	if result := ConvertToAPIToken(nil); result != (APIToken{}) {
+		t.Errorf("ConvertToAPIToken(nil) = %v, want zero value", result)
+	}
+}
@@ -0,0 +1,60 @@
+package toolgen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaBase struct {
+	ID int `json:"id"`
+}
+
+type schemaNode struct {
+	schemaBase
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels"`
+	Tags     []int             `json:"tags,omitempty"`
+	Parent   *schemaNode       `json:"parent,omitempty"`
+	Updated  time.Time         `json:"updated"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Ignored  string            `json:"-"`
+	Untagged bool
+	internal string
+}
+
+// TestTypeSchema verifies the schema generated from a struct.
+func TestTypeSchema(t *testing.T) {
+	schema := TypeSchema[schemaNode]()
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"name": {"type": "string"},
+			"labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+			"tags": {"type": ["array", "null"], "items": {"type": "integer"}},
+			"parent": {},
+			"updated": {"type": "string", "format": "date-time"},
+			"raw": {},
+			"Untagged": {"type": "boolean"}
+		},
+		"required": ["id", "name", "labels", "updated", "Untagged"]
+	}`, string(data))
+}
+
+// TestTypeSchemaNullableStruct verifies that pointers to structs may be null.
+func TestTypeSchemaNullableStruct(t *testing.T) {
+	type wrapper struct {
+		Base *schemaBase `json:"base"`
+	}
+	schema := TypeSchema[wrapper]()
+	base := schema["properties"].(map[string]any)["base"].(map[string]any)
+	assert.Equal(t, []string{"object", "null"}, base["type"])
+	assert.Equal(t, []string{"id"}, base["required"])
+}
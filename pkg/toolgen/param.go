@@ -6,6 +6,8 @@ package toolgen
 import (
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -22,6 +24,20 @@ func NewParameterParser(request mcp.CallToolRequest) *ParameterParser {
 	}
 }
 
+// NewObjectParser creates a parameter parser for the fields of a nested object,
+// such as an item returned by GetArrayOfObjectMaps
+func NewObjectParser(object map[string]any) *ParameterParser {
+	return &ParameterParser{
+		args: object,
+	}
+}
+
+// Has reports whether the parameter is present with a non-null value
+func (p *ParameterParser) Has(name string) bool {
+	value, ok := p.args[name]
+	return ok && value != nil
+}
+
 // GetString extracts a string parameter from the request
 func (p *ParameterParser) GetString(name string, required bool) (string, error) {
 	value, ok := p.args[name]
@@ -126,6 +142,143 @@ func (p *ParameterParser) GetArrayOfObjects(name string, required bool) ([]any,
 	return arrayValue, nil
 }
 
+// GetArrayOfObjectMaps extracts an array of objects parameter from the request.
+// Every item must be a JSON object; use NewObjectParser to read its fields.
+func (p *ParameterParser) GetArrayOfObjectMaps(name string, required bool) ([]map[string]any, error) {
+	value, ok := p.args[name]
+	if !ok || value == nil {
+		if required {
+			return nil, fmt.Errorf("%s is required", name)
+		}
+		return []map[string]any{}, nil
+	}
+
+	arrayValue, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", name)
+	}
+
+	result := make([]map[string]any, 0, len(arrayValue))
+	for _, item := range arrayValue {
+		object, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid item: %v", item)
+		}
+		result = append(result, object)
+	}
+
+	return result, nil
+}
+
+// GetObject extracts an object parameter from the request. A missing optional
+// parameter yields nil; use NewObjectParser to read its fields.
+func (p *ParameterParser) GetObject(name string, required bool) (map[string]any, error) {
+	value, ok := p.args[name]
+	if !ok || value == nil {
+		if required {
+			return nil, fmt.Errorf("%s is required", name)
+		}
+		return nil, nil
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object", name)
+	}
+
+	return object, nil
+}
+
+// GetKeyValueMap extracts an array of {key, value} string objects, such as query
+// parameters, headers or environment variables, into a map
+func (p *ParameterParser) GetKeyValueMap(name string, required bool) (map[string]string, error) {
+	items, err := p.GetArrayOfObjectMaps(name, required)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(items))
+	for _, item := range items {
+		key, ok := item["key"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid key: %v", item["key"])
+		}
+
+		value, ok := item["value"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid value: %v", item["value"])
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// GetEnum extracts a string parameter that must be one of the allowed values.
+// A missing optional parameter yields the empty string.
+func (p *ParameterParser) GetEnum(name string, required bool, allowed ...string) (string, error) {
+	value, err := p.GetString(name, required)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		if required {
+			return "", fmt.Errorf("%s is required", name)
+		}
+		return "", nil
+	}
+
+	if !slices.Contains(allowed, value) {
+		return "", fmt.Errorf("%s must be one of: %s, got '%s'", name, strings.Join(allowed, ", "), value)
+	}
+
+	return value, nil
+}
+
+// GetArrayOfEnums extracts an array of strings that must each be one of the allowed values
+func (p *ParameterParser) GetArrayOfEnums(name string, required bool, allowed ...string) ([]string, error) {
+	values, err := p.GetArrayOfStrings(name, required)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		if !slices.Contains(allowed, value) {
+			return nil, fmt.Errorf("%s items must be one of: %s, got '%s'", name, strings.Join(allowed, ", "), value)
+		}
+	}
+
+	return values, nil
+}
+
+// GetArrayOfStrings extracts an array of strings parameter from the request
+func (p *ParameterParser) GetArrayOfStrings(name string, required bool) ([]string, error) {
+	value, ok := p.args[name]
+	if !ok || value == nil {
+		if required {
+			return nil, fmt.Errorf("%s is required", name)
+		}
+		return []string{}, nil
+	}
+
+	arrayValue, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array", name)
+	}
+
+	result := make([]string, 0, len(arrayValue))
+	for _, item := range arrayValue {
+		str, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array of strings, got '%v'", name, item)
+		}
+		result = append(result, str)
+	}
+
+	return result, nil
+}
+
 // parseArrayOfIntegers converts a slice of any type to a slice of integers.
 // Returns an error if any value cannot be parsed as an integer.
 //
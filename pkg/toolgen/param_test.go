@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
 )
 
 // Helper function to create a ParameterParser with given arguments
@@ -298,6 +299,143 @@ func TestGetArrayOfObjects(t *testing.T) {
 	}
 }
 
+// TestGetArrayOfObjectMaps verifies get array of object maps behavior.
+func TestGetArrayOfObjectMaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]any
+		param    string
+		required bool
+		want     []map[string]any
+		wantErr  bool
+	}{
+		{
+			name: "valid array of objects",
+			args: map[string]any{"objects": []any{
+				map[string]any{"id": 1},
+				map[string]any{"id": 2},
+			}},
+			param:    "objects",
+			required: true,
+			want: []map[string]any{
+				{"id": 1},
+				{"id": 2},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "missing required param",
+			args:     map[string]any{},
+			param:    "objects",
+			required: true,
+			want:     nil,
+			wantErr:  true,
+		},
+		{
+			name:     "missing optional param",
+			args:     map[string]any{},
+			param:    "objects",
+			required: false,
+			want:     []map[string]any{},
+			wantErr:  false,
+		},
+		{
+			name:     "wrong type",
+			args:     map[string]any{"objects": "not an array"},
+			param:    "objects",
+			required: true,
+			want:     nil,
+			wantErr:  true,
+		},
+		{
+			name:     "item is not an object",
+			args:     map[string]any{"objects": []any{map[string]any{"id": 1}, "not a map"}},
+			param:    "objects",
+			required: true,
+			want:     nil,
+			wantErr:  true,
+		},
+		{
+			name:     "nil value",
+			args:     map[string]any{"objects": nil},
+			param:    "objects",
+			required: true,
+			want:     nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser(tt.args)
+			got, err := p.GetArrayOfObjectMaps(tt.param, tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetArrayOfObjectMaps() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetArrayOfObjectMaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetArrayOfStrings verifies get array of strings behavior.
+func TestGetArrayOfStrings(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]any
+		required bool
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "valid array of strings",
+			args:     map[string]any{"fields": []any{"status", "metadata.annotations"}},
+			required: true,
+			want:     []string{"status", "metadata.annotations"},
+		},
+		{
+			name:     "missing required param",
+			args:     map[string]any{},
+			required: true,
+			wantErr:  true,
+		},
+		{
+			name:     "missing optional param",
+			args:     map[string]any{},
+			required: false,
+			want:     []string{},
+		},
+		{
+			name:     "wrong type",
+			args:     map[string]any{"fields": "status"},
+			required: true,
+			wantErr:  true,
+		},
+		{
+			name:     "non-string item",
+			args:     map[string]any{"fields": []any{"status", float64(1)}},
+			required: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestParser(tt.args)
+			got, err := p.GetArrayOfStrings("fields", tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetArrayOfStrings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetArrayOfStrings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestParseArrayOfIntegers verifies parse array of integers behavior.
 func TestParseArrayOfIntegers(t *testing.T) {
 	tests := []struct {
@@ -622,3 +760,133 @@ func TestGetArrayOfIntegers(t *testing.T) {
 		})
 	}
 }
+
+// TestGetObject verifies get object behavior and the parsing of nested fields.
+func TestGetObject(t *testing.T) {
+	p := newTestParser(map[string]any{
+		"options": map[string]any{"replicas": float64(3), "name": "web"},
+		"wrong":   []any{"a"},
+	})
+
+	options, err := p.GetObject("options", true)
+	assert.NoError(t, err)
+	fields := NewObjectParser(options)
+	replicas, err := fields.GetInt("replicas", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, replicas)
+	assert.True(t, fields.Has("name"))
+	assert.False(t, fields.Has("image"))
+
+	missing, err := p.GetObject("missing", false)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = p.GetObject("missing", true)
+	assert.EqualError(t, err, "missing is required")
+
+	_, err = p.GetObject("wrong", true)
+	assert.EqualError(t, err, "wrong must be an object")
+}
+
+// TestHas verifies that Has ignores missing and null parameters.
+func TestHas(t *testing.T) {
+	p := newTestParser(map[string]any{"set": false, "null": nil})
+
+	assert.True(t, p.Has("set"))
+	assert.False(t, p.Has("null"))
+	assert.False(t, p.Has("missing"))
+}
+
+// TestGetKeyValueMap verifies get key value map behavior.
+func TestGetKeyValueMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   any
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:  "valid entries",
+			items: []any{map[string]any{"key": "k1", "value": "v1"}, map[string]any{"key": "k2", "value": "v2"}},
+			want:  map[string]string{"k1": "v1", "k2": "v2"},
+		},
+		{name: "empty items", items: []any{}, want: map[string]string{}},
+		{name: "missing optional", want: map[string]string{}},
+		{name: "not an array", items: "k1=v1", wantErr: "headers must be an array"},
+		{name: "invalid item type", items: []any{"not a map"}, wantErr: "invalid item: not a map"},
+		{name: "invalid key type", items: []any{map[string]any{"key": 123, "value": "v1"}}, wantErr: "invalid key: 123"},
+		{name: "invalid value type", items: []any{map[string]any{"key": "k1", "value": 123}}, wantErr: "invalid value: 123"},
+		{name: "missing key field", items: []any{map[string]any{"value": "v1"}}, wantErr: "invalid key: <nil>"},
+		{name: "missing value field", items: []any{map[string]any{"key": "k1"}}, wantErr: "invalid value: <nil>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			if tt.items != nil {
+				args["headers"] = tt.items
+			}
+			got, err := newTestParser(args).GetKeyValueMap("headers", false)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestGetEnum verifies get enum behavior.
+func TestGetEnum(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]any
+		required bool
+		want     string
+		wantErr  string
+	}{
+		{name: "allowed value", args: map[string]any{"sortBy": "cpu"}, want: "cpu"},
+		{name: "missing optional", args: map[string]any{}, want: ""},
+		{name: "missing required", args: map[string]any{}, required: true, wantErr: "sortBy is required"},
+		{name: "empty required", args: map[string]any{"sortBy": ""}, required: true, wantErr: "sortBy is required"},
+		{name: "wrong type", args: map[string]any{"sortBy": float64(1)}, wantErr: "sortBy must be a string"},
+		{name: "value not allowed", args: map[string]any{"sortBy": "disk"}, wantErr: "sortBy must be one of: cpu, memory, got 'disk'"},
+		{name: "case sensitive", args: map[string]any{"sortBy": "CPU"}, wantErr: "sortBy must be one of: cpu, memory, got 'CPU'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestParser(tt.args).GetEnum("sortBy", tt.required, "cpu", "memory")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestGetArrayOfEnums verifies get array of enums behavior.
+func TestGetArrayOfEnums(t *testing.T) {
+	p := newTestParser(map[string]any{
+		"valid":   []any{"tags", "teams"},
+		"invalid": []any{"tags", "users"},
+		"mixed":   []any{"tags", float64(1)},
+	})
+
+	got, err := p.GetArrayOfEnums("valid", true, "tags", "teams", "settings")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tags", "teams"}, got)
+
+	got, err = p.GetArrayOfEnums("missing", false, "tags")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = p.GetArrayOfEnums("invalid", true, "tags", "teams")
+	assert.EqualError(t, err, "invalid items must be one of: tags, teams, got 'users'")
+
+	_, err = p.GetArrayOfEnums("mixed", true, "tags")
+	assert.ErrorContains(t, err, "mixed must be an array of strings")
+}
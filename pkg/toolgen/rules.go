@@ -0,0 +1,269 @@
+package toolgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ArgumentRules applies the parameter defaults and coercion rules declared in tool
+// definitions to the arguments of tool calls, before the handlers parse them.
+//
+// LLM clients often send numbers and booleans as strings, such as "5" or "true", or an
+// array as its JSON encoding. The typed getters of ParameterParser reject those values,
+// so the rules convert them to the declared type first.
+//
+// The rules are safe for concurrent use, and Reload replaces them while calls are served.
+type ArgumentRules struct {
+	mu    sync.RWMutex
+	tools map[string][]ParameterDefinition
+}
+
+// NewArgumentRules builds the argument rules of a set of tool definitions
+func NewArgumentRules(defs []ToolDefinition) *ArgumentRules {
+	return &ArgumentRules{tools: argumentRules(defs)}
+}
+
+// Reload replaces the rules with those of a new set of tool definitions.
+func (r *ArgumentRules) Reload(defs []ToolDefinition) {
+	tools := argumentRules(defs)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools = tools
+}
+
+// argumentRules returns the parameters of each tool definition, with their default and
+// example values converted to JSON values.
+func argumentRules(defs []ToolDefinition) map[string][]ParameterDefinition {
+	rules := make(map[string][]ParameterDefinition, len(defs))
+	for _, def := range defs {
+		params := make([]ParameterDefinition, 0, len(def.Parameters))
+		for _, param := range def.Parameters {
+			if param.Default != nil {
+				param.Default = jsonValue(param.Default)
+			}
+			if param.Example != nil {
+				param.Example = jsonValue(param.Example)
+			}
+			params = append(params, param)
+		}
+		rules[def.Name] = params
+	}
+	return rules
+}
+
+// Apply returns the arguments of a call to a tool with the defaults of the missing
+// parameters added and the coercible values converted to their declared type. Values
+// that cannot be converted are kept as they are, so that the getters report the type
+// error. The given arguments are not modified; they are returned as is when the tool
+// has no definition.
+func (r *ArgumentRules) Apply(toolName string, args map[string]any) map[string]any {
+	r.mu.RLock()
+	params, ok := r.tools[toolName]
+	r.mu.RUnlock()
+	if !ok {
+		return args
+	}
+
+	result := maps.Clone(args)
+	if result == nil {
+		result = map[string]any{}
+	}
+	for _, param := range params {
+		value, present := result[param.Name]
+		if !present || value == nil {
+			if param.Default != nil {
+				result[param.Name] = param.Default
+			}
+			continue
+		}
+		if param.coerces() {
+			result[param.Name] = coerceValue(param.Type, param.Items, value)
+		}
+	}
+	return result
+}
+
+// Describe returns the expected value of a parameter of a tool, with its type, whether it
+// is required, its description and an example, so that the error of a call that omits or
+// mistypes the parameter tells the caller how to fix it. It returns false when the tool
+// has no such parameter.
+func (r *ArgumentRules) Describe(toolName, paramName string) (string, bool) {
+	r.mu.RLock()
+	params := r.tools[toolName]
+	r.mu.RUnlock()
+
+	for _, param := range params {
+		if param.Name != paramName {
+			continue
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Expected %s: %s", param.Name, typeName(param))
+		if param.Required {
+			b.WriteString(", required")
+		} else {
+			b.WriteString(", optional")
+		}
+		if len(param.Enum) > 0 {
+			fmt.Fprintf(&b, ", one of: %s", strings.Join(param.Enum, ", "))
+		}
+		if param.Description != "" {
+			fmt.Fprintf(&b, ". %s", strings.TrimSuffix(param.Description, "."))
+		}
+		if example, ok := exampleValue(param); ok {
+			data, err := json.Marshal(example)
+			if err == nil {
+				fmt.Fprintf(&b, ". Example: %s", data)
+			}
+		}
+		return b.String(), true
+	}
+	return "", false
+}
+
+// typeName returns the JSON type of a parameter, with the type of its items for arrays
+func typeName(param ParameterDefinition) string {
+	if itemType, ok := param.Items["type"].(string); ok && param.Type == "array" {
+		return "array of " + itemType
+	}
+	return param.Type
+}
+
+// exampleValue returns a valid value of a parameter: its declared example, its default,
+// its first allowed value, or a value built from its type. Free strings and objects have
+// no built example, and neither do parameters whose description already gives one.
+func exampleValue(param ParameterDefinition) (any, bool) {
+	switch {
+	case param.Example != nil:
+		return param.Example, true
+	case param.Default != nil:
+		return param.Default, true
+	case len(param.Enum) > 0:
+		return param.Enum[0], true
+	case strings.Contains(param.Description, "Example"):
+		return nil, false
+	}
+
+	switch param.Type {
+	case "number":
+		return 1, true
+	case "boolean":
+		return true, true
+	case "array":
+		if allowed, ok := param.Items["enum"].([]any); ok && len(allowed) > 0 {
+			return allowed[:1], true
+		}
+		switch typeName(param) {
+		case "array of number":
+			return []int{1, 2}, true
+		case "array of string":
+			return []string{"value"}, true
+		}
+	}
+	return nil, false
+}
+
+// coerces tells whether the string values of the parameter are converted to its type
+func (p ParameterDefinition) coerces() bool {
+	return p.Coerce == nil || *p.Coerce
+}
+
+// coerceValue converts a value to a JSON type, or returns it unchanged when it cannot
+func coerceValue(paramType string, items map[string]any, value any) any {
+	switch paramType {
+	case "number":
+		if s, ok := value.(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+				return n
+			}
+		}
+	case "boolean":
+		if s, ok := value.(string); ok {
+			switch strings.ToLower(strings.TrimSpace(s)) {
+			case "true":
+				return true
+			case "false":
+				return false
+			}
+		}
+	case "array":
+		if s, ok := value.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "[") {
+			var decoded []any
+			if err := json.Unmarshal([]byte(s), &decoded); err == nil {
+				value = decoded
+			}
+		}
+		array, ok := value.([]any)
+		if !ok {
+			return value
+		}
+		itemType, _ := items["type"].(string)
+		if itemType != "number" && itemType != "boolean" {
+			return array
+		}
+		coerced := make([]any, len(array))
+		for i, item := range array {
+			coerced[i] = coerceValue(itemType, nil, item)
+		}
+		return coerced
+	}
+	return value
+}
+
+// validateDefault checks that the default of a parameter matches its type and enum
+func validateDefault(param ParameterDefinition) error {
+	if param.Default == nil {
+		return nil
+	}
+	if param.Required {
+		return fmt.Errorf("a required parameter cannot have a default")
+	}
+	return validateValue(param, param.Default)
+}
+
+// validateValue checks that a value declared in tools.yaml matches the type and enum
+// of a parameter
+func validateValue(param ParameterDefinition, declared any) error {
+	value := jsonValue(declared)
+	var valid bool
+	switch param.Type {
+	case "string":
+		s, ok := value.(string)
+		if ok && param.Enum != nil && !slices.Contains(param.Enum, s) {
+			return fmt.Errorf("%s is not one of: %s", s, strings.Join(param.Enum, ", "))
+		}
+		valid = ok
+	case "number":
+		_, valid = value.(float64)
+	case "boolean":
+		_, valid = value.(bool)
+	case "array":
+		_, valid = value.([]any)
+	case "object":
+		_, valid = value.(map[string]any)
+	}
+	if !valid {
+		return fmt.Errorf("%v does not match the %s type", declared, param.Type)
+	}
+	return nil
+}
+
+// jsonValue converts a value decoded from YAML to the types of a decoded JSON argument,
+// such as float64 for numbers, as the handlers expect
+func jsonValue(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var converted any
+	if err := json.Unmarshal(data, &converted); err != nil {
+		return value
+	}
+	return converted
+}
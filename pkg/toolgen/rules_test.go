@@ -0,0 +1,205 @@
+package toolgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRules returns argument rules for a tool with parameters of every coercible type.
+func testRules() *ArgumentRules {
+	disabled := false
+	return NewArgumentRules([]ToolDefinition{
+		{
+			Name: "listThings",
+			Parameters: []ParameterDefinition{
+				{Name: "id", Type: "number", Required: true},
+				{Name: "limit", Type: "number", Default: 50},
+				{Name: "all", Type: "boolean", Default: false},
+				{Name: "sortBy", Type: "string", Enum: []string{"cpu", "memory"}, Default: "cpu"},
+				{Name: "ids", Type: "array", Items: map[string]any{"type": "number"}},
+				{Name: "names", Type: "array", Items: map[string]any{"type": "string"}},
+				{Name: "raw", Type: "number", Coerce: &disabled},
+			},
+		},
+	})
+}
+
+// TestArgumentRulesApply verifies that defaults are added and string values are coerced
+// to the declared types.
+func TestArgumentRulesApply(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want map[string]any
+	}{
+		{
+			name: "defaults for missing parameters",
+			tool: "listThings",
+			args: map[string]any{"id": float64(1)},
+			want: map[string]any{"id": float64(1), "limit": float64(50), "all": false, "sortBy": "cpu"},
+		},
+		{
+			name: "defaults for null parameters",
+			tool: "listThings",
+			args: map[string]any{"id": float64(1), "limit": nil},
+			want: map[string]any{"id": float64(1), "limit": float64(50), "all": false, "sortBy": "cpu"},
+		},
+		{
+			name: "nil arguments",
+			tool: "listThings",
+			args: nil,
+			want: map[string]any{"limit": float64(50), "all": false, "sortBy": "cpu"},
+		},
+		{
+			name: "numbers and booleans given as strings",
+			tool: "listThings",
+			args: map[string]any{"id": "5", "limit": " 10 ", "all": "TRUE", "sortBy": "memory"},
+			want: map[string]any{"id": float64(5), "limit": float64(10), "all": true, "sortBy": "memory"},
+		},
+		{
+			name: "array items and JSON encoded arrays",
+			tool: "listThings",
+			args: map[string]any{"id": float64(1), "ids": []any{"1", float64(2)}, "names": `["a", "b"]`},
+			want: map[string]any{"id": float64(1), "limit": float64(50), "all": false, "sortBy": "cpu", "ids": []any{float64(1), float64(2)}, "names": []any{"a", "b"}},
+		},
+		{
+			name: "uncoercible values are kept",
+			tool: "listThings",
+			args: map[string]any{"id": "five", "all": "yes", "ids": "1,2", "limit": "Inf"},
+			want: map[string]any{"id": "five", "limit": "Inf", "all": "yes", "sortBy": "cpu", "ids": "1,2"},
+		},
+		{
+			name: "coercion disabled",
+			tool: "listThings",
+			args: map[string]any{"id": float64(1), "raw": "5"},
+			want: map[string]any{"id": float64(1), "limit": float64(50), "all": false, "sortBy": "cpu", "raw": "5"},
+		},
+		{
+			name: "unknown tool",
+			tool: "otherTool",
+			args: map[string]any{"id": "5"},
+			want: map[string]any{"id": "5"},
+		},
+	}
+
+	rules := testRules()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rules.Apply(tt.tool, tt.args))
+		})
+	}
+}
+
+// TestArgumentRulesApplyDoesNotModifyArguments verifies that Apply returns a new map.
+func TestArgumentRulesApplyDoesNotModifyArguments(t *testing.T) {
+	args := map[string]any{"id": "5"}
+	got := testRules().Apply("listThings", args)
+
+	assert.Equal(t, map[string]any{"id": "5"}, args)
+	assert.Equal(t, float64(5), got["id"])
+}
+
+// TestArgumentRulesReload verifies that reloaded rules replace those of the previous tool
+// definitions.
+func TestArgumentRulesReload(t *testing.T) {
+	rules := testRules()
+	rules.Reload([]ToolDefinition{
+		{Name: "listOthers", Parameters: []ParameterDefinition{{Name: "limit", Type: "number", Default: 10}}},
+	})
+
+	assert.Equal(t, map[string]any{"id": "1"}, rules.Apply("listThings", map[string]any{"id": "1"}))
+	assert.Equal(t, map[string]any{"limit": float64(10)}, rules.Apply("listOthers", nil))
+}
+
+// TestArgumentRulesWithParser verifies that the parser accepts coerced arguments.
+func TestArgumentRulesWithParser(t *testing.T) {
+	args := testRules().Apply("listThings", map[string]any{"id": "7", "all": "true"})
+	parser := newTestParser(args)
+
+	id, err := parser.GetInt("id", true)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+
+	limit, err := parser.GetInt("limit", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, limit)
+
+	all, err := parser.GetBoolean("all", false)
+	assert.NoError(t, err)
+	assert.True(t, all)
+}
+
+// TestValidateDefault verifies the validation of parameter defaults.
+func TestValidateDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   ParameterDefinition
+		wantErr string
+	}{
+		{name: "no default", param: ParameterDefinition{Type: "number"}},
+		{name: "number", param: ParameterDefinition{Type: "number", Default: 10}},
+		{name: "boolean", param: ParameterDefinition{Type: "boolean", Default: true}},
+		{name: "string in enum", param: ParameterDefinition{Type: "string", Enum: []string{"a", "b"}, Default: "b"}},
+		{name: "array", param: ParameterDefinition{Type: "array", Default: []any{"a"}}},
+		{name: "object", param: ParameterDefinition{Type: "object", Default: map[string]any{"a": 1}}},
+		{name: "string not in enum", param: ParameterDefinition{Type: "string", Enum: []string{"a", "b"}, Default: "c"}, wantErr: "c is not one of: a, b"},
+		{name: "number given as string", param: ParameterDefinition{Type: "number", Default: "10"}, wantErr: "10 does not match the number type"},
+		{name: "required", param: ParameterDefinition{Type: "number", Required: true, Default: 1}, wantErr: "a required parameter cannot have a default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDefault(tt.param)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestArgumentRulesDescribe verifies the expected value of parameters given in errors.
+func TestArgumentRulesDescribe(t *testing.T) {
+	rules := NewArgumentRules([]ToolDefinition{
+		{
+			Name: "listThings",
+			Parameters: []ParameterDefinition{
+				{Name: "id", Type: "number", Required: true, Description: "Thing ID."},
+				{Name: "limit", Type: "number", Description: "Maximum things", Default: 50},
+				{Name: "sortBy", Type: "string", Enum: []string{"cpu", "memory"}, Description: "Sort key"},
+				{Name: "name", Type: "string", Description: "Thing name", Example: "web"},
+				{Name: "filter", Type: "string", Description: "Name filter"},
+				{Name: "ids", Type: "array", Items: map[string]any{"type": "number"}, Description: "Thing IDs"},
+				{Name: "labels", Type: "array", Items: map[string]any{"type": "string"}, Description: "Labels. Example: ['a']"},
+			},
+		},
+	})
+
+	tests := []struct {
+		param string
+		want  string
+	}{
+		{param: "id", want: "Expected id: number, required. Thing ID. Example: 1"},
+		{param: "limit", want: "Expected limit: number, optional. Maximum things. Example: 50"},
+		{param: "sortBy", want: `Expected sortBy: string, optional, one of: cpu, memory. Sort key. Example: "cpu"`},
+		{param: "name", want: `Expected name: string, optional. Thing name. Example: "web"`},
+		{param: "filter", want: "Expected filter: string, optional. Name filter"},
+		{param: "ids", want: "Expected ids: array of number, optional. Thing IDs. Example: [1,2]"},
+		{param: "labels", want: "Expected labels: array of string, optional. Labels. Example: ['a']"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			got, ok := rules.Describe("listThings", tt.param)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, ok := rules.Describe("listThings", "missing")
+	assert.False(t, ok)
+	_, ok = rules.Describe("otherTool", "id")
+	assert.False(t, ok)
+}
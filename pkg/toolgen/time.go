@@ -0,0 +1,140 @@
+package toolgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now returns the current time. It is a variable so tests can pin the clock.
+var now = time.Now
+
+// ParseTime parses a point in time given either as an RFC3339 timestamp
+// (e.g. "2025-01-02T15:04:05Z") or as a duration relative to now
+// (e.g. "30m", "2h", "7d"), which is interpreted as that long ago.
+//
+// Relative values accept the units supported by time.ParseDuration plus "d" for days.
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("time value cannot be empty")
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	d, err := parseRelativeDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is neither an RFC3339 timestamp nor a relative duration like '2h' or '7d'", value)
+	}
+	if d < 0 {
+		return time.Time{}, fmt.Errorf("relative duration '%s' must not be negative", value)
+	}
+
+	return now().Add(-d), nil
+}
+
+// parseRelativeDuration parses a Go duration string, additionally accepting a
+// whole number of days with the "d" suffix.
+func parseRelativeDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// GetTime extracts a time parameter from the request. The value must be a string
+// in one of the formats accepted by ParseTime. A missing optional parameter
+// yields the zero time.
+func (p *ParameterParser) GetTime(name string, required bool) (time.Time, error) {
+	value, err := p.GetString(name, required)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if value == "" {
+		if required {
+			return time.Time{}, fmt.Errorf("%s is required", name)
+		}
+		return time.Time{}, nil
+	}
+
+	t, err := ParseTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return t, nil
+}
+
+// GetTimestamp extracts a time parameter that must be an RFC3339 timestamp, for
+// parameters where a relative value would be ambiguous. A missing optional parameter
+// yields the zero time.
+func (p *ParameterParser) GetTimestamp(name string, required bool) (time.Time, error) {
+	value, err := p.GetString(name, required)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if value == "" {
+		if required {
+			return time.Time{}, fmt.Errorf("%s is required", name)
+		}
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 timestamp such as 2025-01-02T15:04:05Z, got '%s'", name, value)
+	}
+	return t, nil
+}
+
+// GetDuration extracts a duration parameter given as a string in the format of
+// time.ParseDuration, plus "d" for days (e.g. "90s", "15m", "7d"). Negative durations
+// are rejected. A missing optional parameter yields zero.
+func (p *ParameterParser) GetDuration(name string, required bool) (time.Duration, error) {
+	value, err := p.GetString(name, required)
+	if err != nil {
+		return 0, err
+	}
+	if value == "" {
+		if required {
+			return 0, fmt.Errorf("%s is required", name)
+		}
+		return 0, nil
+	}
+
+	d, err := parseRelativeDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration such as 90s, 15m, 2h or 7d, got '%s'", name, value)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s must not be negative, got '%s'", name, value)
+	}
+	return d, nil
+}
+
+// GetTimeRange extracts the optional "since" and "until" parameters from the
+// request and validates that since is not after until when both are present.
+// Missing bounds are returned as the zero time.
+func (p *ParameterParser) GetTimeRange() (since time.Time, until time.Time, err error) {
+	since, err = p.GetTime("since", false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	until, err = p.GetTime("until", false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since (%s) must not be after until (%s)", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	return since, until, nil
+}
@@ -0,0 +1,185 @@
+package toolgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pinNow fixes the package clock for the duration of a test.
+func pinNow(t *testing.T, fixed time.Time) {
+	t.Helper()
+	original := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = original })
+}
+
+// TestParseTime verifies parsing of RFC3339 and relative time values.
+func TestParseTime(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pinNow(t, fixed)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "RFC3339 UTC", value: "2025-05-31T10:00:00Z", want: time.Date(2025, 5, 31, 10, 0, 0, 0, time.UTC)},
+		{name: "RFC3339 with offset", value: "2025-05-31T10:00:00+02:00", want: time.Date(2025, 5, 31, 8, 0, 0, 0, time.UTC)},
+		{name: "relative hours", value: "2h", want: fixed.Add(-2 * time.Hour)},
+		{name: "relative minutes", value: "90m", want: fixed.Add(-90 * time.Minute)},
+		{name: "relative days", value: "7d", want: fixed.Add(-7 * 24 * time.Hour)},
+		{name: "surrounding whitespace", value: " 1h ", want: fixed.Add(-time.Hour)},
+		{name: "empty", value: "", wantErr: true},
+		{name: "garbage", value: "yesterday", wantErr: true},
+		{name: "negative duration", value: "-2h", wantErr: true},
+		{name: "fractional days", value: "1.5d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+// TestGetTime verifies get time behavior.
+func TestGetTime(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pinNow(t, fixed)
+
+	tests := []struct {
+		name     string
+		args     map[string]any
+		required bool
+		want     time.Time
+		wantErr  bool
+	}{
+		{name: "valid relative", args: map[string]any{"since": "1h"}, want: fixed.Add(-time.Hour)},
+		{name: "missing optional", args: map[string]any{}, want: time.Time{}},
+		{name: "missing required", args: map[string]any{}, required: true, wantErr: true},
+		{name: "empty required", args: map[string]any{"since": ""}, required: true, wantErr: true},
+		{name: "wrong type", args: map[string]any{"since": float64(3600)}, wantErr: true},
+		{name: "invalid format", args: map[string]any{"since": "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestParser(tt.args).GetTime("since", tt.required)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+// TestGetTimestamp verifies that only RFC3339 timestamps are accepted.
+func TestGetTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]any
+		required bool
+		want     time.Time
+		wantErr  string
+	}{
+		{name: "valid", args: map[string]any{"at": "2025-01-02T15:04:05Z"}, want: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{name: "valid with offset", args: map[string]any{"at": "2025-01-02T17:04:05+02:00"}, want: time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{name: "missing optional", args: map[string]any{}, want: time.Time{}},
+		{name: "missing required", args: map[string]any{}, required: true, wantErr: "at is required"},
+		{name: "relative value", args: map[string]any{"at": "2h"}, wantErr: "at must be an RFC3339 timestamp such as 2025-01-02T15:04:05Z, got '2h'"},
+		{name: "date only", args: map[string]any{"at": "2025-01-02"}, wantErr: "at must be an RFC3339 timestamp such as 2025-01-02T15:04:05Z, got '2025-01-02'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestParser(tt.args).GetTimestamp("at", tt.required)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+// TestGetDuration verifies Go durations, day durations and their validation.
+func TestGetDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     map[string]any
+		required bool
+		want     time.Duration
+		wantErr  string
+	}{
+		{name: "seconds", args: map[string]any{"interval": "90s"}, want: 90 * time.Second},
+		{name: "composite", args: map[string]any{"interval": "1h30m"}, want: 90 * time.Minute},
+		{name: "days", args: map[string]any{"interval": "7d"}, want: 7 * 24 * time.Hour},
+		{name: "missing optional", args: map[string]any{}, want: 0},
+		{name: "missing required", args: map[string]any{}, required: true, wantErr: "interval is required"},
+		{name: "wrong type", args: map[string]any{"interval": float64(60)}, wantErr: "interval must be a string"},
+		{name: "no unit", args: map[string]any{"interval": "60"}, wantErr: "interval must be a duration such as 90s, 15m, 2h or 7d, got '60'"},
+		{name: "negative", args: map[string]any{"interval": "-5m"}, wantErr: "interval must not be negative, got '-5m'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestParser(tt.args).GetDuration("interval", tt.required)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestGetTimeRange verifies get time range behavior.
+func TestGetTimeRange(t *testing.T) {
+	fixed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pinNow(t, fixed)
+
+	tests := []struct {
+		name      string
+		args      map[string]any
+		wantSince time.Time
+		wantUntil time.Time
+		wantErr   bool
+	}{
+		{name: "no bounds", args: map[string]any{}},
+		{name: "since only", args: map[string]any{"since": "2h"}, wantSince: fixed.Add(-2 * time.Hour)},
+		{
+			name:      "both bounds",
+			args:      map[string]any{"since": "2h", "until": "1h"},
+			wantSince: fixed.Add(-2 * time.Hour),
+			wantUntil: fixed.Add(-time.Hour),
+		},
+		{name: "since after until", args: map[string]any{"since": "1h", "until": "2h"}, wantErr: true},
+		{name: "invalid until", args: map[string]any{"until": "later"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, until, err := newTestParser(tt.args).GetTimeRange()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.wantSince.Equal(since), "since: want %s, got %s", tt.wantSince, since)
+			assert.True(t, tt.wantUntil.Equal(until), "until: want %s, got %s", tt.wantUntil, until)
+		})
+	}
+}
@@ -32,6 +32,15 @@ type ParameterDefinition struct {
 	Enum        []string       `yaml:"enum,omitempty"`
 	Description string         `yaml:"description"`
 	Items       map[string]any `yaml:"items,omitempty"`
+	// Default is the value used when a call omits the parameter. It is advertised in
+	// the input schema and must match the type and enum of the parameter.
+	Default any `yaml:"default,omitempty"`
+	// Example is a valid value shown in the input schema and in the errors of calls
+	// that omit or mistype the parameter. It must match the type and enum of the parameter.
+	Example any `yaml:"example,omitempty"`
+	// Coerce converts string values of number, boolean and array parameters to the
+	// declared type, such as "5" to 5 or "true" to true. It is enabled when omitted.
+	Coerce *bool `yaml:"coerce,omitempty"`
 }
 
 // Annotations represents a tool annotations in the YAML config
@@ -46,6 +55,18 @@ type Annotations struct {
 // LoadToolsFromYAML loads tool definitions from a YAML file
 // It returns the tools and the version of the tools.yaml file
 func LoadToolsFromYAML(filePath string, minimumVersion string) (map[string]mcp.Tool, error) {
+	defs, err := LoadToolDefinitionsFromYAML(filePath, minimumVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return ConvertToolDefinitions(defs), nil
+}
+
+// LoadToolDefinitionsFromYAML loads the raw tool definitions from a YAML file, after
+// checking its version. The definitions are converted with ConvertToolDefinitions, and
+// their argument rules are built with NewArgumentRules.
+func LoadToolDefinitionsFromYAML(filePath string, minimumVersion string) ([]ToolDefinition, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read tools file: %w", err)
@@ -68,11 +89,11 @@ func LoadToolsFromYAML(filePath string, minimumVersion string) (map[string]mcp.T
 		return nil, fmt.Errorf("tools.yaml version %s is below the minimum required version %s", config.Version, minimumVersion)
 	}
 
-	return convertToolDefinitions(config.Tools), nil
+	return config.Tools, nil
 }
 
-// convertToolDefinitions converts YAML tool definitions to mcp.Tool objects
-func convertToolDefinitions(defs []ToolDefinition) map[string]mcp.Tool {
+// ConvertToolDefinitions converts YAML tool definitions to mcp.Tool objects
+func ConvertToolDefinitions(defs []ToolDefinition) map[string]mcp.Tool {
 	tools := make(map[string]mcp.Tool, len(defs))
 
 	for _, def := range defs {
@@ -107,6 +128,14 @@ func convertToolDefinition(def ToolDefinition) (mcp.Tool, error) {
 	}
 
 	for _, param := range def.Parameters {
+		if err := validateDefault(param); err != nil {
+			return mcp.Tool{}, fmt.Errorf("invalid default for parameter '%s' of tool '%s': %w", param.Name, def.Name, err)
+		}
+		if param.Example != nil {
+			if err := validateValue(param, param.Example); err != nil {
+				return mcp.Tool{}, fmt.Errorf("invalid example for parameter '%s' of tool '%s': %w", param.Name, def.Name, err)
+			}
+		}
 		options = append(options, convertParameter(param))
 	}
 
@@ -144,6 +173,14 @@ func convertParameter(param ParameterDefinition) mcp.ToolOption {
 		options = append(options, mcp.Items(param.Items))
 	}
 
+	if param.Default != nil {
+		options = append(options, withDefault(jsonValue(param.Default)))
+	}
+
+	if param.Example != nil {
+		options = append(options, withExamples(jsonValue(param.Example)))
+	}
+
 	switch param.Type {
 	case "string":
 		return mcp.WithString(param.Name, options...)
@@ -160,3 +197,17 @@ func convertParameter(param ParameterDefinition) mcp.ToolOption {
 		return mcp.WithString(param.Name, options...)
 	}
 }
+
+// withDefault sets the default value of a parameter in the input schema
+func withDefault(value any) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["default"] = value
+	}
+}
+
+// withExamples sets the example values of a parameter in the input schema
+func withExamples(values ...any) mcp.PropertyOption {
+	return func(schema map[string]any) {
+		schema["examples"] = values
+	}
+}
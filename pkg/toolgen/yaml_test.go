@@ -327,6 +327,45 @@ func TestConvertToolDefinition(t *testing.T) {
 			wantErr:       true,
 			wantErrSubstr: "annotations title is required",
 		},
+		{
+			name: "default not matching the parameter type",
+			def: ToolDefinition{
+				Name:        "badDefaultTool",
+				Description: "Tool with an invalid default",
+				Parameters: []ParameterDefinition{
+					{Name: "limit", Type: "number", Description: "A limit", Default: "ten"},
+				},
+				Annotations: validAnnotations,
+			},
+			wantErr:       true,
+			wantErrSubstr: "invalid default for parameter 'limit' of tool 'badDefaultTool'",
+		},
+		{
+			name: "example not in the parameter enum",
+			def: ToolDefinition{
+				Name:        "badExampleTool",
+				Description: "Tool with an invalid example",
+				Parameters: []ParameterDefinition{
+					{Name: "sortBy", Type: "string", Enum: []string{"cpu"}, Description: "Sort", Example: "disk"},
+				},
+				Annotations: validAnnotations,
+			},
+			wantErr:       true,
+			wantErrSubstr: "invalid example for parameter 'sortBy' of tool 'badExampleTool': disk is not one of: cpu",
+		},
+		{
+			name: "default of a required parameter",
+			def: ToolDefinition{
+				Name:        "requiredDefaultTool",
+				Description: "Tool with a default on a required parameter",
+				Parameters: []ParameterDefinition{
+					{Name: "id", Type: "number", Required: true, Description: "An ID", Default: 1},
+				},
+				Annotations: validAnnotations,
+			},
+			wantErr:       true,
+			wantErrSubstr: "a required parameter cannot have a default",
+		},
 		{
 			name: "with parameters",
 			def: ToolDefinition{
@@ -463,7 +502,7 @@ func TestConvertToolDefinitions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := convertToolDefinitions(tt.defs)
+			got := ConvertToolDefinitions(tt.defs)
 			assert.Len(t, got, tt.want)
 
 			// Verify each tool expected to be converted exists and is valid
@@ -587,6 +626,22 @@ func TestConvertParameter(t *testing.T) {
 	}
 }
 
+// TestConvertParameterDefault verifies that parameter defaults and examples are
+// advertised in the input schema with JSON types.
+func TestConvertParameterDefault(t *testing.T) {
+	tool := mcp.NewTool("tool",
+		convertParameter(ParameterDefinition{Name: "tail", Type: "number", Description: "Lines", Default: 100}),
+		convertParameter(ParameterDefinition{Name: "sortBy", Type: "string", Description: "Sort", Enum: []string{"cpu", "memory"}, Default: "cpu"}),
+		convertParameter(ParameterDefinition{Name: "all", Type: "boolean", Description: "All"}),
+		convertParameter(ParameterDefinition{Name: "name", Type: "string", Description: "Name", Example: "web"}),
+	)
+
+	assert.Equal(t, float64(100), tool.InputSchema.Properties["tail"].(map[string]any)["default"])
+	assert.Equal(t, "cpu", tool.InputSchema.Properties["sortBy"].(map[string]any)["default"])
+	assert.NotContains(t, tool.InputSchema.Properties["all"], "default")
+	assert.Equal(t, []any{"web"}, tool.InputSchema.Properties["name"].(map[string]any)["examples"])
+}
+
 // Optional: Add a specific test for convertAnnotation if desired, though it's simple
 func TestConvertAnnotation(t *testing.T) {
 	input := Annotations{
@@ -26,11 +26,17 @@ import (
 const (
 	defaultPortainerImage = "portainer/portainer-ee:" + mcp.SupportedPortainerVersion
 	defaultAPIPortTCP     = "9443/tcp"
-	adminPassword         = "$2y$05$CiHrhW6R6whDVlu7Wdgl0eccb3rg1NWl/mMiO93vQiRIF1SHNFRsS" // Bcrypt hash of "adminpassword123"
+	adminPassword         = "$2y$05$CiHrhW6R6whDVlu7Wdgl0eccb3rg1NWl/mMiO93vQiRIF1SHNFRsS" // Bcrypt hash of AdminPassword
 	// Timeout for the container to start and be ready to use
 	startupTimeout = time.Second * 5
 )
 
+// Credentials of the administrator created when the container starts
+const (
+	AdminUsername = "admin"
+	AdminPassword = "adminpassword123"
+)
+
 // PortainerContainer represents a Portainer container for testing
 type PortainerContainer struct {
 	testcontainers.Container
@@ -176,8 +182,8 @@ func (pc *PortainerContainer) registerAPIToken() error {
 
 	portainerClient := client.New(transport, strfmt.Default)
 
-	username := "admin"
-	password := "adminpassword123"
+	username := AdminUsername
+	password := AdminPassword
 	params := auth.NewAuthenticateUserParams().WithBody(&models.AuthAuthenticatePayload{
 		Username: &username,
 		Password: &password,
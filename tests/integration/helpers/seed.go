@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"fmt"
+	"testing"
+
+	mcpclient "github.com/jmrplens/portainer-mcp-enhanced/pkg/portainer/client"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	seedStackFile    = "version: '3'\nservices:\n  web:\n    image: nginx:alpine"
+	seedUserPassword = "seedpassword123"
+	seedUserRole     = 2 // Portainer API role ID for Standard User
+
+	seedRegistryTypeCustom  = 3
+	seedPlatformLinux       = 1
+	seedTemplateTypeCompose = 2
+)
+
+// SeedData holds the IDs of the resources created by Seed
+type SeedData struct {
+	// LocalEnvironmentID is a Docker environment reaching the host Docker socket, which
+	// requires a container started with containers.WithDockerSocketBind(true)
+	LocalEnvironmentID int
+	// ContainerID is the Portainer container itself, running on the local environment
+	ContainerID        string
+	TagID              int
+	AccessGroupID      int
+	EnvironmentGroupID int
+	EdgeStackID        int
+	UserID             int
+	TeamID             int
+	RegistryID         int
+	CustomTemplateID   int
+	EdgeJobID          int
+}
+
+// Seed enables the Edge features and creates one resource of each kind the read-only tools
+// inspect, directly through the Portainer API rather than through the tools under test
+func (e *TestEnv) Seed(t *testing.T) SeedData {
+	t.Helper()
+
+	host, port := e.Portainer.GetHostAndPort()
+	serverAddr := fmt.Sprintf("%s:%s", host, port)
+	err := e.RawClient.UpdateSettings(true, serverAddr, fmt.Sprintf("%s:8000", host))
+	require.NoError(t, err, "Failed to enable Edge features")
+
+	var data SeedData
+	data.ContainerID = e.Portainer.GetContainerID()
+
+	tagID, err := e.RawClient.CreateTag("seed-tag")
+	require.NoError(t, err, "Failed to create seed tag")
+	data.TagID = int(tagID)
+
+	environmentID, err := e.RawClient.CreateLocalDockerEndpoint("seed-local")
+	require.NoError(t, err, "Failed to create seed local Docker environment")
+	data.LocalEnvironmentID = int(environmentID)
+	err = e.RawClient.UpdateEndpoint(environmentID, &[]int64{tagID}, nil, nil)
+	require.NoError(t, err, "Failed to tag seed environment")
+
+	accessGroupID, err := e.RawClient.CreateEndpointGroup("seed-access-group", []int64{})
+	require.NoError(t, err, "Failed to create seed access group")
+	data.AccessGroupID = int(accessGroupID)
+
+	groupID, err := e.RawClient.CreateEdgeGroup("seed-environment-group", []int64{})
+	require.NoError(t, err, "Failed to create seed environment group")
+	data.EnvironmentGroupID = int(groupID)
+
+	stackID, err := e.RawClient.CreateEdgeStack("seed-edge-stack", seedStackFile, []int64{groupID})
+	require.NoError(t, err, "Failed to create seed edge stack")
+	data.EdgeStackID = int(stackID)
+
+	userID, err := e.RawClient.CreateUser("seed-user", seedUserPassword, seedUserRole)
+	require.NoError(t, err, "Failed to create seed user")
+	data.UserID = int(userID)
+
+	teamID, err := e.RawClient.CreateTeam("seed-team")
+	require.NoError(t, err, "Failed to create seed team")
+	data.TeamID = int(teamID)
+	err = e.RawClient.CreateTeamMembership(data.TeamID, data.UserID)
+	require.NoError(t, err, "Failed to add seed user to seed team")
+
+	// Registries, custom templates and edge jobs are not covered by the raw client
+	cli := mcpclient.NewPortainerClient(serverAddr, e.Portainer.GetAPIToken(), mcpclient.WithSkipTLSVerify(true))
+
+	data.RegistryID, err = cli.CreateRegistry("seed-registry", seedRegistryTypeCustom, "registry.example.com", false, "", "", "")
+	require.NoError(t, err, "Failed to create seed registry")
+
+	data.CustomTemplateID, err = cli.CreateCustomTemplate("seed-template", "Seed template", "", "", seedStackFile, seedPlatformLinux, seedTemplateTypeCompose)
+	require.NoError(t, err, "Failed to create seed custom template")
+
+	data.EdgeJobID, err = cli.CreateEdgeJob("seed-edge-job", "0 0 * * *", "echo seed", nil, []int{data.EnvironmentGroupID}, true)
+	require.NoError(t, err, "Failed to create seed edge job")
+
+	return data
+}
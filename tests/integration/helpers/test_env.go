@@ -44,6 +44,8 @@ func NewTestEnv(t *testing.T, opts ...containers.PortainerContainerOption) *Test
 
 	mcpServer, err := mcp.NewPortainerMCPServer(serverURL, portainer.GetAPIToken(), ToolsPath, mcp.WithSkipTLSVerify(true))
 	require.NoError(t, err, "Failed to create MCP server")
+	// Register every tool so that tests can also call them by name with CallTool
+	mcpServer.AddAllFeatures()
 
 	return &TestEnv{
 		Ctx:       ctx,
@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"encoding/json"
+	"testing"
+
+	mcpmodels "github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+// ToolInfo is a tool advertised by the MCP server in its tools/list response
+type ToolInfo struct {
+	Name        string                   `json:"name"`
+	Annotations mcpmodels.ToolAnnotation `json:"annotations"`
+}
+
+// rpcResponse is a JSON-RPC response of the MCP server
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ListTools returns the tools advertised by the MCP server, as an MCP client sees them
+func (e *TestEnv) ListTools(t *testing.T) []ToolInfo {
+	t.Helper()
+
+	result := e.request(t, "tools/list", map[string]any{})
+	var list struct {
+		Tools []ToolInfo `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(result, &list), "Failed to parse tools/list result")
+	return list.Tools
+}
+
+// CallTool calls a tool by name through a JSON-RPC tools/call request, so that the call goes
+// through the tool schema and the tool middlewares as it would from an MCP client
+func (e *TestEnv) CallTool(t *testing.T, name string, args map[string]any) *mcpmodels.CallToolResult {
+	t.Helper()
+
+	result := e.request(t, "tools/call", map[string]any{"name": name, "arguments": args})
+	callResult, err := mcpmodels.ParseCallToolResult(&result)
+	require.NoError(t, err, "Failed to parse tools/call result of %s", name)
+	return callResult
+}
+
+// request sends a JSON-RPC request to the MCP server and returns its result
+func (e *TestEnv) request(t *testing.T, method string, params any) json.RawMessage {
+	t.Helper()
+
+	message, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	require.NoError(t, err, "Failed to marshal %s request", method)
+
+	data, err := json.Marshal(e.MCPServer.HandleMessage(e.Ctx, message))
+	require.NoError(t, err, "Failed to marshal %s response", method)
+
+	var resp rpcResponse
+	require.NoError(t, json.Unmarshal(data, &resp), "Failed to parse %s response", method)
+	require.Nil(t, resp.Error, "%s request failed: %s", method, data)
+	return resp.Result
+}
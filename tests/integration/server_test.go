@@ -14,7 +14,7 @@ import (
 
 const (
 	toolsPath        = "../../internal/tooldef/tools.yaml"
-	unsupportedImage = "portainer/portainer-ee:2.29.1" // Older version than SupportedPortainerVersion
+	unsupportedImage = "portainer/portainer-ee:2.21.5" // Older version than MinimumPortainerVersion
 )
 
 // TestServerInitialization verifies that the Portainer MCP server
@@ -97,8 +97,8 @@ func TestServerInitializationDisabledVersionCheck(t *testing.T) {
 	serverURL := fmt.Sprintf("%s:%s", host, port)
 	apiToken := portainer.GetAPIToken()
 
-	// Create the MCP server with disabled version check - should succeed despite unsupported version
-	mcpServer, err := mcp.NewPortainerMCPServer(serverURL, apiToken, toolsPath, mcp.WithDisableVersionCheck(true), mcp.WithSkipTLSVerify(true))
+	// Create the MCP server with the version check off - should succeed despite unsupported version
+	mcpServer, err := mcp.NewPortainerMCPServer(serverURL, apiToken, toolsPath, mcp.WithVersionCheck(mcp.VersionCheckOff), mcp.WithSkipTLSVerify(true))
 
 	// Assert the server was created successfully
 	require.NoError(t, err, "Failed to create MCP server with disabled version check")
@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jmrplens/portainer-mcp-enhanced/tests/integration/containers"
+	"github.com/jmrplens/portainer-mcp-enhanced/tests/integration/helpers"
+	mcpmodels "github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readOnlyToolArgs builds the arguments of every read-only tool from the seeded resources.
+// A read-only tool must appear here or in skippedReadOnlyTools, so that a new tool cannot be
+// added without an end-to-end call.
+var readOnlyToolArgs = map[string]func(helpers.SeedData) map[string]any{
+	"listAccessGroups": noArgs,
+	"listEnvironments": noArgs,
+	"getEnvironment":   func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.LocalEnvironmentID} },
+	"whoCanAccessEnvironment": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"id": d.LocalEnvironmentID}
+	},
+	"getSnapshotSettings": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentId": d.LocalEnvironmentID}
+	},
+	"getGroupCapacity":      noArgs,
+	"listEnvironmentGroups": noArgs,
+	"previewEnvironmentGroupMembers": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"tagIds": []int{d.TagID}, "id": d.EnvironmentGroupID}
+	},
+	"getSettings":       noArgs,
+	"listStacks":        noArgs,
+	"listRegularStacks": noArgs,
+	"getStackFile":      func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.EdgeStackID} },
+	"waitForEdgeStackRollout": func(d helpers.SeedData) map[string]any {
+		// The seeded edge stack has no target environment, so the wait times out
+		return map[string]any{"id": d.EdgeStackID, "timeoutSeconds": 1}
+	},
+	"waitFor": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"resourceType": "container", "id": d.ContainerID, "state": "running", "environmentId": d.LocalEnvironmentID}
+	},
+	"getStackFileHistory": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"id": d.EdgeStackID, "kind": "edge"}
+	},
+	"detectDrift":         noArgs,
+	"listEnvironmentTags": noArgs,
+	"listTeams":           noArgs,
+	"getTeam":             func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.TeamID} },
+	"auditTeamAccess":     func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.TeamID} },
+	"listUsers":           noArgs,
+	"getUser":             func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.UserID} },
+	"getSystemStatus":     noArgs,
+	"benchmarkLatency":    func(d helpers.SeedData) map[string]any { return map[string]any{"iterations": 1} },
+	"getServerStats":      noArgs,
+	"getDeleteJournal":    noArgs,
+	"dockerProxyGet": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentId": d.LocalEnvironmentID, "dockerAPIPath": "/version"}
+	},
+	"getDockerDashboard": localEnvironmentArgs,
+	"listContainers":     localEnvironmentArgs,
+	"getContainerLogs": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentId": d.LocalEnvironmentID, "containerId": d.ContainerID, "tail": 10}
+	},
+	"getContainerTop": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentId": d.LocalEnvironmentID, "containerId": d.ContainerID}
+	},
+	"listDockerEvents": localEnvironmentArgs,
+	"getFleetContainerUsage": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentIds": []int{d.LocalEnvironmentID}}
+	},
+	"suggestCleanup": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentIds": []int{d.LocalEnvironmentID}}
+	},
+	"auditRestartPolicies": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentIds": []int{d.LocalEnvironmentID}}
+	},
+	"getPortReport": localEnvironmentArgs,
+	"checkImageUpdates": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"environmentIds": []int{d.LocalEnvironmentID}}
+	},
+	"listCustomTemplates":   noArgs,
+	"getCustomTemplate":     func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.CustomTemplateID} },
+	"getCustomTemplateFile": func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.CustomTemplateID} },
+	"listWebhooks":          noArgs,
+	"listRegistries":        noArgs,
+	"getRegistry":           func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.RegistryID} },
+	"getBackupStatus":       noArgs,
+	"getBackupS3Settings":   noArgs,
+	"listRoles":             noArgs,
+	"getRole":               func(d helpers.SeedData) map[string]any { return map[string]any{"id": 1} },
+	"compareRoles": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"roleId": 1, "otherRoleId": 2}
+	},
+	"getMOTD":                 noArgs,
+	"getLoginBanner":          noArgs,
+	"getPublicSettings":       noArgs,
+	"getSSLSettings":          noArgs,
+	"exportSettings":          noArgs,
+	"listAppTemplates":        noArgs,
+	"getAppTemplateFile":      func(d helpers.SeedData) map[string]any { return map[string]any{"id": 1} },
+	"listEdgeJobs":            noArgs,
+	"getEdgeJob":              func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.EdgeJobID} },
+	"getEdgeJobFile":          func(d helpers.SeedData) map[string]any { return map[string]any{"id": d.EdgeJobID} },
+	"listEdgeUpdateSchedules": noArgs,
+	"getAgentVersionReport":   noArgs,
+	"authenticate": func(d helpers.SeedData) map[string]any {
+		return map[string]any{"username": containers.AdminUsername, "password": containers.AdminPassword}
+	},
+	"listHelmRepositories": func(d helpers.SeedData) map[string]any { return map[string]any{"userId": 1} },
+}
+
+// skippedReadOnlyTools are the read-only tools that the suite cannot call, with the reason.
+var skippedReadOnlyTools = map[string]string{
+	"getStack":                      "requires a regular stack, whose deployment would pull an image",
+	"inspectStackFile":              "requires a regular stack, whose deployment would pull an image",
+	"getStackResources":             "requires a regular stack, whose deployment would pull an image",
+	"readContainerFile":             "requires a small regular file at a known path in a running container",
+	"verifyBackup":                  "requires a backup archive on the machine running the tests",
+	"compareInstances":              "requires a second Portainer server configured with -instances",
+	"searchHelmCharts":              "requires access to a public Helm repository",
+	"getKubernetesResourceStripped": "requires a Kubernetes environment",
+	"getKubernetesDashboard":        "requires a Kubernetes environment",
+	"listKubernetesNamespaces":      "requires a Kubernetes environment",
+	"getKubernetesConfig":           "requires a Kubernetes environment",
+	"describeKubernetesResource":    "requires a Kubernetes environment",
+	"listKubernetesAPIResources":    "requires a Kubernetes environment",
+	"topKubernetesNodes":            "requires a Kubernetes environment",
+	"topKubernetesPods":             "requires a Kubernetes environment",
+	"listHelmReleases":              "requires a Kubernetes environment",
+	"getHelmReleaseHistory":         "requires a Kubernetes environment",
+	"getHelmReleaseStatus":          "requires a Kubernetes environment",
+}
+
+func noArgs(helpers.SeedData) map[string]any { return map[string]any{} }
+
+func localEnvironmentArgs(d helpers.SeedData) map[string]any {
+	return map[string]any{"environmentId": d.LocalEnvironmentID}
+}
+
+// TestReadOnlyToolSuite is an end-to-end test that calls every read-only tool advertised by
+// the MCP server against a seeded Portainer instance, through JSON-RPC tools/call requests.
+// It catches contract drift between the tools and the Portainer API, such as a field whose
+// type changed, that the transport-level mocks of the unit tests cannot detect.
+func TestReadOnlyToolSuite(t *testing.T) {
+	env := helpers.NewTestEnv(t, containers.WithDockerSocketBind(true))
+	defer env.Cleanup(t)
+
+	seed := env.Seed(t)
+
+	var readOnly []string
+	for _, tool := range env.ListTools(t) {
+		if tool.Annotations.ReadOnlyHint != nil && *tool.Annotations.ReadOnlyHint {
+			readOnly = append(readOnly, tool.Name)
+		}
+	}
+	slices.Sort(readOnly)
+	require.NotEmpty(t, readOnly, "The server should advertise read-only tools")
+
+	// Subtest: Suite Coverage
+	// Verifies that:
+	// - Every read-only tool has arguments in readOnlyToolArgs or a reason in skippedReadOnlyTools.
+	// - Neither table refers to a tool that is no longer advertised.
+	t.Run("Suite Coverage", func(t *testing.T) {
+		for _, name := range readOnly {
+			_, called := readOnlyToolArgs[name]
+			_, skipped := skippedReadOnlyTools[name]
+			assert.True(t, called || skipped, "Read-only tool %s is neither called nor skipped by the suite", name)
+			assert.False(t, called && skipped, "Read-only tool %s is both called and skipped by the suite", name)
+		}
+		for name := range readOnlyToolArgs {
+			assert.Contains(t, readOnly, name, "readOnlyToolArgs refers to an unknown read-only tool")
+		}
+		for name := range skippedReadOnlyTools {
+			assert.Contains(t, readOnly, name, "skippedReadOnlyTools refers to an unknown read-only tool")
+		}
+	})
+
+	// Subtest: each read-only tool
+	// Verifies that:
+	// - The tool call succeeds against the seeded instance.
+	// - The result has text content, and JSON content that can be parsed.
+	for _, name := range readOnly {
+		t.Run(name, func(t *testing.T) {
+			if reason, skipped := skippedReadOnlyTools[name]; skipped {
+				t.Skip(reason)
+			}
+			args, ok := readOnlyToolArgs[name]
+			if !ok {
+				t.Skip("not covered, see Suite Coverage")
+			}
+
+			result := env.CallTool(t, name, args(seed))
+			require.NotEmpty(t, result.Content, "Expected content in the result of %s", name)
+			textContent, ok := result.Content[0].(mcpmodels.TextContent)
+			require.True(t, ok, "Expected text content in the result of %s", name)
+			require.False(t, result.IsError, "Tool %s failed: %s", name, textContent.Text)
+			require.NotEmpty(t, textContent.Text, "Expected a non-empty result from %s", name)
+
+			text := strings.TrimSpace(textContent.Text)
+			if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+				assert.True(t, json.Valid([]byte(text)), "Tool %s returned invalid JSON: %s", name, text)
+			}
+		})
+	}
+}